@@ -0,0 +1,3 @@
+-- Rollback per-tenant fraud rules
+
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS fraud_rules;
@@ -0,0 +1,8 @@
+-- Per-tenant fraud rules evaluated when commissions are created
+
+-- ============================================================================
+-- Tenant Connections: Fraud Rules
+-- ============================================================================
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS fraud_rules JSONB;
+
+COMMENT ON COLUMN tenant_connections.fraud_rules IS 'Commission fraud rule configuration (NULL uses application defaults)';
@@ -0,0 +1,3 @@
+-- Rollback address validations
+
+DROP TABLE IF EXISTS address_validations;
@@ -0,0 +1,26 @@
+-- Standardized address validations for client and property addresses
+
+-- ============================================================================
+-- Address Validations Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS address_validations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    entity_type VARCHAR(20),
+    entity_id UUID,
+    original JSONB NOT NULL,
+    standardized JSONB,
+    deliverable BOOLEAN NOT NULL DEFAULT false,
+    provider VARCHAR(50) NOT NULL,
+    footnotes TEXT[],
+    validated_by UUID REFERENCES employees(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_address_entity_type CHECK (entity_type IS NULL OR entity_type IN ('CLIENT', 'PROPERTY'))
+);
+
+CREATE INDEX idx_address_validations_entity ON address_validations(tenant_id, entity_type, entity_id, created_at DESC);
+CREATE INDEX idx_address_validations_undeliverable ON address_validations(tenant_id, deliverable) WHERE deliverable = false;
+
+COMMENT ON TABLE address_validations IS 'Original and standardized addresses returned by the address validation provider';
+COMMENT ON COLUMN address_validations.deliverable IS 'False when the provider flagged the address as undeliverable';
@@ -0,0 +1,4 @@
+-- Rollback notification preferences and digest queue
+
+DROP TABLE IF EXISTS notification_events;
+DROP TABLE IF EXISTS notification_preferences;
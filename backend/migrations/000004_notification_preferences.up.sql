@@ -0,0 +1,34 @@
+-- Per-employee notification preferences and the daily digest queue
+
+-- ============================================================================
+-- Notification Preferences Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS notification_preferences (
+    employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    category VARCHAR(50) NOT NULL,
+    mode VARCHAR(20) NOT NULL DEFAULT 'immediate',
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (employee_id, category),
+    CONSTRAINT chk_notification_mode CHECK (mode IN ('immediate', 'digest', 'off'))
+);
+
+COMMENT ON TABLE notification_preferences IS 'Delivery mode per employee and event category (missing rows use immediate)';
+
+-- ============================================================================
+-- Notification Events Table (digest queue)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS notification_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(100),
+    category VARCHAR(50) NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    digested_at TIMESTAMP
+);
+
+CREATE INDEX idx_notification_events_pending ON notification_events(employee_id, created_at) WHERE digested_at IS NULL;
+
+COMMENT ON TABLE notification_events IS 'Events held for the daily digest email';
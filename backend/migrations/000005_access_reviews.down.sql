@@ -0,0 +1,4 @@
+-- Rollback access review campaigns
+
+DROP TABLE IF EXISTS access_review_items;
+DROP TABLE IF EXISTS access_reviews;
@@ -0,0 +1,40 @@
+-- Periodic access review campaigns (SOC 2 user access reviews)
+
+-- ============================================================================
+-- Access Reviews Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS access_reviews (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'OPEN',
+    created_by UUID NOT NULL REFERENCES employees(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    completed_at TIMESTAMP,
+
+    CONSTRAINT chk_access_review_status CHECK (status IN ('OPEN', 'COMPLETED'))
+);
+
+COMMENT ON TABLE access_reviews IS 'Access review campaigns; line items are snapshotted from employee_tenant_access at start';
+
+-- ============================================================================
+-- Access Review Items Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS access_review_items (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    review_id UUID NOT NULL REFERENCES access_reviews(id) ON DELETE CASCADE,
+    access_id UUID REFERENCES employee_tenant_access(id) ON DELETE SET NULL,
+    employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    employee_email VARCHAR(255) NOT NULL,
+    tenant_id VARCHAR(100) NOT NULL,
+    role VARCHAR(50) NOT NULL,
+    decision VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    reviewed_by UUID REFERENCES employees(id),
+    reviewed_at TIMESTAMP,
+    comment TEXT,
+
+    CONSTRAINT chk_access_review_decision CHECK (decision IN ('PENDING', 'ATTESTED', 'REVOKED'))
+);
+
+CREATE INDEX idx_access_review_items_review ON access_review_items(review_id);
+
+COMMENT ON COLUMN access_review_items.employee_email IS 'Snapshot of the employee email when the review started, kept for the report';
@@ -0,0 +1,3 @@
+-- Rollback break-glass grants
+
+DROP TABLE IF EXISTS break_glass_grants;
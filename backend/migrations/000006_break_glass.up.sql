@@ -0,0 +1,25 @@
+-- Break-glass emergency tenant access with automatic expiry
+
+-- ============================================================================
+-- Break-Glass Grants Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS break_glass_grants (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    access_id UUID REFERENCES employee_tenant_access(id) ON DELETE SET NULL,
+    created_access BOOLEAN NOT NULL,
+    previous_role VARCHAR(50),
+    granted_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    revoked_by UUID REFERENCES employees(id)
+);
+
+CREATE INDEX idx_break_glass_open ON break_glass_grants(expires_at) WHERE revoked_at IS NULL;
+CREATE UNIQUE INDEX uq_break_glass_open_grant ON break_glass_grants(employee_id, tenant_id) WHERE revoked_at IS NULL;
+
+COMMENT ON TABLE break_glass_grants IS 'Time-boxed emergency tenant access; revocation restores the prior employee_tenant_access state';
+COMMENT ON COLUMN break_glass_grants.created_access IS 'True when the grant inserted the access row (deleted on revoke) rather than reactivating one';
+COMMENT ON COLUMN break_glass_grants.revoked_by IS 'Employee who ended the grant early; NULL when it expired automatically';
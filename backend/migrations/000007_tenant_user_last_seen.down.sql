@@ -0,0 +1,3 @@
+-- Rollback tenant user last seen tracking
+
+ALTER TABLE tenant_users DROP COLUMN IF EXISTS last_seen_at;
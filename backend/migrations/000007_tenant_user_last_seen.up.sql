@@ -0,0 +1,5 @@
+-- Track when portal users last opened their full profile (drives unread counts)
+
+ALTER TABLE tenant_users ADD COLUMN IF NOT EXISTS last_seen_at TIMESTAMP;
+
+COMMENT ON COLUMN tenant_users.last_seen_at IS 'Last time the portal user loaded their full profile; newer documents count as unread';
@@ -0,0 +1,4 @@
+-- Rollback push notification devices
+
+ALTER TABLE tenant_users DROP COLUMN IF EXISTS push_enabled;
+DROP TABLE IF EXISTS tenant_user_devices;
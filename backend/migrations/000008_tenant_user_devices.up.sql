@@ -0,0 +1,24 @@
+-- Push notification device tokens for portal (tenant) users
+
+-- ============================================================================
+-- Tenant User Devices Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS tenant_user_devices (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_user_id UUID NOT NULL REFERENCES tenant_users(id) ON DELETE CASCADE,
+    token TEXT NOT NULL UNIQUE,
+    platform VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_device_platform CHECK (platform IN ('ios', 'android', 'web'))
+);
+
+CREATE INDEX idx_tenant_user_devices_user ON tenant_user_devices(tenant_user_id);
+
+COMMENT ON TABLE tenant_user_devices IS 'FCM registration tokens (APNs tokens are delivered through FCM)';
+
+-- Per-user push opt-out
+ALTER TABLE tenant_users ADD COLUMN IF NOT EXISTS push_enabled BOOLEAN NOT NULL DEFAULT true;
+
+COMMENT ON COLUMN tenant_users.push_enabled IS 'False when the portal user has opted out of push notifications';
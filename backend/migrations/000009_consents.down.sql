@@ -0,0 +1,4 @@
+-- Rollback consent management
+
+DROP TABLE IF EXISTS client_consents;
+DROP TABLE IF EXISTS consent_templates;
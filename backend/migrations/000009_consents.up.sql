@@ -0,0 +1,44 @@
+-- Taxpayer consent management (IRC section 7216 disclosures and uses)
+
+-- ============================================================================
+-- Consent Templates Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS consent_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    purpose VARCHAR(50) NOT NULL,
+    version INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES employees(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_consent_template_version UNIQUE (tenant_id, purpose, version)
+);
+
+CREATE INDEX idx_consent_templates_active ON consent_templates(tenant_id, purpose) WHERE is_active = true;
+
+COMMENT ON TABLE consent_templates IS 'Versioned consent wording per tenant and purpose; publishing a new version retires the previous one';
+
+-- ============================================================================
+-- Client Consents Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS client_consents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    client_id UUID NOT NULL,
+    template_id UUID NOT NULL REFERENCES consent_templates(id),
+    purpose VARCHAR(50) NOT NULL,
+    tenant_user_id UUID REFERENCES tenant_users(id) ON DELETE SET NULL,
+    granted_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    granted_ip INET,
+    granted_user_agent TEXT,
+    revoked_at TIMESTAMP,
+    revoked_ip INET
+);
+
+CREATE INDEX idx_client_consents_lookup ON client_consents(tenant_id, client_id, purpose);
+CREATE UNIQUE INDEX uq_client_consent_active ON client_consents(tenant_id, client_id, purpose) WHERE revoked_at IS NULL;
+
+COMMENT ON TABLE client_consents IS 'Taxpayer consent records; rows are never deleted so grant/revoke history is preserved';
@@ -0,0 +1,3 @@
+-- Rollback fillable PDF form templates
+
+DROP TABLE IF EXISTS form_templates;
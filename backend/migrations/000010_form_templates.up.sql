@@ -0,0 +1,20 @@
+-- Fillable PDF form templates (cover sheets, organizers, Form 8879) per tenant
+
+-- ============================================================================
+-- Form Templates Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS form_templates (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    template_path TEXT NOT NULL,
+    field_map JSONB NOT NULL DEFAULT '{}'::jsonb,
+    updated_by UUID REFERENCES employees(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_form_template_kind UNIQUE (tenant_id, kind)
+);
+
+COMMENT ON TABLE form_templates IS 'Fillable PDF templates per tenant; field_map maps PDF field names to form data keys';
+COMMENT ON COLUMN form_templates.template_path IS 'gs:// or https://storage.googleapis.com/ URL of the fillable PDF';
@@ -0,0 +1,4 @@
+-- Rollback request signing
+
+DROP TABLE IF EXISTS request_nonces;
+DROP TABLE IF EXISTS request_signing_keys;
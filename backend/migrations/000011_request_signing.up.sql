@@ -0,0 +1,36 @@
+-- HMAC request signing secrets per tenant and the nonces used to reject replays
+
+-- ============================================================================
+-- Request Signing Keys Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS request_signing_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    key_id VARCHAR(64) NOT NULL UNIQUE,
+    secret TEXT NOT NULL,
+    label VARCHAR(255) NOT NULL,
+    created_by UUID REFERENCES employees(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_request_signing_keys_active ON request_signing_keys(tenant_id) WHERE revoked_at IS NULL;
+
+COMMENT ON TABLE request_signing_keys IS 'Per-tenant HMAC secrets for signed public requests; any active key makes signatures mandatory for the tenant';
+COMMENT ON COLUMN request_signing_keys.key_id IS 'Public identifier sent in the X-WTP-Key-Id header';
+COMMENT ON COLUMN request_signing_keys.secret IS 'HMAC secret, AES-256-GCM encrypted like tenant database passwords';
+
+-- ============================================================================
+-- Request Nonces Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS request_nonces (
+    key_id VARCHAR(64) NOT NULL REFERENCES request_signing_keys(key_id) ON DELETE CASCADE,
+    nonce VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (key_id, nonce)
+);
+
+CREATE INDEX idx_request_nonces_expires ON request_nonces(expires_at);
+
+COMMENT ON TABLE request_nonces IS 'Nonces of accepted signed requests, kept until they fall outside the replay window';
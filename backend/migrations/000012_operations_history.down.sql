@@ -0,0 +1,4 @@
+-- Rollback operations history
+
+DROP TABLE IF EXISTS signature_envelopes;
+DROP TABLE IF EXISTS job_runs;
@@ -0,0 +1,41 @@
+-- Background job run history and signature envelope send outcomes for the platform overview
+
+-- ============================================================================
+-- Job Runs Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS job_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_name VARCHAR(100) NOT NULL,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    status VARCHAR(20) NOT NULL,
+    items INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+
+    CONSTRAINT chk_job_run_status CHECK (status IN ('SUCCEEDED', 'FAILED'))
+);
+
+CREATE INDEX idx_job_runs_job_started ON job_runs(job_name, started_at DESC);
+
+COMMENT ON TABLE job_runs IS 'One row per background job run; rows older than 7 days are pruned as new runs are recorded';
+COMMENT ON COLUMN job_runs.items IS 'Units of work the run processed (digests sent, grants expired, nonces purged, ...)';
+
+-- ============================================================================
+-- Signature Envelopes Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS signature_envelopes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    filing_id UUID,
+    status VARCHAR(20) NOT NULL,
+    error TEXT,
+    sent_by UUID REFERENCES employees(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_signature_envelope_status CHECK (status IN ('SENT', 'FAILED'))
+);
+
+CREATE INDEX idx_signature_envelopes_created ON signature_envelopes(created_at DESC);
+
+COMMENT ON TABLE signature_envelopes IS 'Outcome of every signature request sent to DocuSign';
+COMMENT ON COLUMN signature_envelopes.filing_id IS 'Filing in the tenant database, when the request named one';
@@ -0,0 +1,3 @@
+-- Rollback tenant schema checks
+
+DROP TABLE IF EXISTS tenant_schema_checks;
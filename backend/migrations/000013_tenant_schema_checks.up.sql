@@ -0,0 +1,18 @@
+-- Latest tenant schema validation result per tenant
+
+-- ============================================================================
+-- Tenant Schema Checks Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS tenant_schema_checks (
+    tenant_id VARCHAR(100) PRIMARY KEY REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    adapter_type VARCHAR(50) NOT NULL,
+    schema_prefix VARCHAR(100) NOT NULL,
+    healthy BOOLEAN NOT NULL,
+    issues JSONB NOT NULL DEFAULT '[]',
+    error TEXT,
+    checked_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE tenant_schema_checks IS 'Most recent comparison of each tenant schema against the tables and columns its adapter expects';
+COMMENT ON COLUMN tenant_schema_checks.issues IS 'Missing tables, missing columns and type mismatches, each with a suggested fix';
+COMMENT ON COLUMN tenant_schema_checks.error IS 'Set when the check could not run (connection or introspection failure)';
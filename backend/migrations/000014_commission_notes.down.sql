@@ -0,0 +1,4 @@
+-- Rollback commission notes and tags
+
+DROP TABLE IF EXISTS commission_tags;
+DROP TABLE IF EXISTS commission_notes;
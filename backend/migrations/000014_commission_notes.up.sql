@@ -0,0 +1,34 @@
+-- Append-only admin notes and tags on affiliate commissions
+
+-- ============================================================================
+-- Commission Notes Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS commission_notes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    commission_id UUID NOT NULL,
+    author_id UUID REFERENCES employees(id) ON DELETE SET NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_commission_notes_commission ON commission_notes(tenant_id, commission_id, created_at);
+
+COMMENT ON TABLE commission_notes IS 'Admin annotations on tenant commissions; rows are never updated or deleted';
+
+-- ============================================================================
+-- Commission Tags Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS commission_tags (
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    commission_id UUID NOT NULL,
+    tag VARCHAR(50) NOT NULL,
+    created_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, commission_id, tag)
+);
+
+CREATE INDEX idx_commission_tags_tag ON commission_tags(tenant_id, tag);
+
+COMMENT ON TABLE commission_tags IS 'Admin tags on tenant commissions (e.g. HOLD, VIP) used to filter commission lists';
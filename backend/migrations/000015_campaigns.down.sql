@@ -0,0 +1,3 @@
+-- Rollback marketing campaigns
+
+DROP TABLE IF EXISTS campaigns;
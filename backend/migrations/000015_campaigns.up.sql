@@ -0,0 +1,23 @@
+-- Marketing campaigns grouping discount codes and tracking links for attribution reporting
+
+-- ============================================================================
+-- Campaigns Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS campaigns (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    key VARCHAR(100) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    channel VARCHAR(30) NOT NULL,
+    description TEXT,
+    starts_on DATE,
+    ends_on DATE,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX uq_campaigns_key ON campaigns(tenant_id, LOWER(key));
+
+COMMENT ON TABLE campaigns IS 'Tenant marketing campaigns; key matches discount_codes.campaign and affiliate click utm_campaign in the tenant database';
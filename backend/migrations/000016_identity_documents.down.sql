@@ -0,0 +1,3 @@
+-- Rollback identity documents
+
+DROP TABLE IF EXISTS identity_documents;
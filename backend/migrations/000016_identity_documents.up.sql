@@ -0,0 +1,36 @@
+-- Photo IDs uploaded by clients during portal onboarding, with automated check results and admin review
+
+-- ============================================================================
+-- Identity Documents Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS identity_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    tenant_user_id UUID NOT NULL REFERENCES tenant_users(id) ON DELETE CASCADE,
+    client_id UUID,
+    kind VARCHAR(30) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    storage_path TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    expires_on DATE,
+    name_matched BOOLEAN,
+    flags TEXT[] NOT NULL DEFAULT '{}',
+    provider VARCHAR(50) NOT NULL,
+    reviewed_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+    review_note TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_identity_documents_kind CHECK (kind IN ('DRIVERS_LICENSE', 'STATE_ID', 'PASSPORT')),
+    CONSTRAINT chk_identity_documents_status CHECK (status IN ('VERIFIED', 'NEEDS_REVIEW', 'REJECTED'))
+);
+
+CREATE INDEX idx_identity_documents_user ON identity_documents(tenant_id, tenant_user_id, created_at DESC);
+CREATE INDEX idx_identity_documents_client ON identity_documents(tenant_id, client_id, created_at DESC) WHERE client_id IS NOT NULL;
+CREATE INDEX idx_identity_documents_review ON identity_documents(tenant_id, created_at) WHERE status = 'NEEDS_REVIEW';
+
+COMMENT ON TABLE identity_documents IS 'Client photo IDs; files are stored under restricted/ in tenant storage and are only downloadable by admins';
+COMMENT ON COLUMN identity_documents.client_id IS 'Client record in the tenant database; NULL when uploaded before the client record existed';
+COMMENT ON COLUMN identity_documents.flags IS 'Automated check findings (EXPIRED, NAME_MISMATCH, ...); any flag sends the document to review';
@@ -0,0 +1,3 @@
+-- Rollback job leases
+
+DROP TABLE IF EXISTS job_leases;
@@ -0,0 +1,14 @@
+-- Job leases so API and worker processes can run background jobs without running them twice
+
+-- ============================================================================
+-- Job Leases Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS job_leases (
+    job_name VARCHAR(100) PRIMARY KEY,
+    holder VARCHAR(255) NOT NULL,
+    acquired_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL
+);
+
+COMMENT ON TABLE job_leases IS 'Process currently running each exclusive background job; renewed on every run and taken over once expired';
+COMMENT ON COLUMN job_leases.holder IS 'Process identifier (host and pid) of the lease holder';
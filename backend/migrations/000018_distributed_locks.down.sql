@@ -0,0 +1,3 @@
+-- Rollback distributed lock statistics
+
+DROP TABLE IF EXISTS distributed_locks;
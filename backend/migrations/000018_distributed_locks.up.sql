@@ -0,0 +1,22 @@
+-- Usage statistics and current holders of Postgres advisory locks taken by API and worker processes
+
+-- ============================================================================
+-- Distributed Locks Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS distributed_locks (
+    name VARCHAR(200) PRIMARY KEY,
+    holder VARCHAR(255),
+    holder_pid INTEGER,
+    acquired_at TIMESTAMP,
+    acquisitions BIGINT NOT NULL DEFAULT 0,
+    contentions BIGINT NOT NULL DEFAULT 0,
+    total_hold_ms BIGINT NOT NULL DEFAULT 0,
+    max_hold_ms BIGINT NOT NULL DEFAULT 0,
+    last_released_at TIMESTAMP,
+    stuck_alerted_at TIMESTAMP
+);
+
+COMMENT ON TABLE distributed_locks IS 'One row per advisory lock name; holder columns are set while the lock is held';
+COMMENT ON COLUMN distributed_locks.holder_pid IS 'Backend pid of the session holding the advisory lock, used to tell live holders from crashed ones';
+COMMENT ON COLUMN distributed_locks.contentions IS 'Attempts that found the lock already held and skipped the operation';
+COMMENT ON COLUMN distributed_locks.stuck_alerted_at IS 'When admins were alerted that the current hold is stuck; cleared on the next acquisition';
@@ -0,0 +1,11 @@
+-- Rollback built-in smoke tenant
+
+DELETE FROM tenant_connections WHERE tenant_id = 'smoke';
+
+ALTER TABLE tenant_connections DROP CONSTRAINT IF EXISTS chk_storage_provider;
+ALTER TABLE tenant_connections ADD CONSTRAINT chk_storage_provider
+    CHECK (storage_provider IS NULL OR storage_provider IN ('gcs', 's3', 'azure'));
+
+ALTER TABLE tenant_connections DROP CONSTRAINT IF EXISTS chk_adapter_type;
+ALTER TABLE tenant_connections ADD CONSTRAINT chk_adapter_type
+    CHECK (adapter_type IN ('mywelltax', 'drake', 'lacerte', 'proseries', 'ultratax'));
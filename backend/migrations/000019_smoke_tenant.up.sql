@@ -0,0 +1,22 @@
+-- Built-in smoke tenant for post-deploy verification.
+-- The API serves its settings and data from memory; this row exists so audit logs and other
+-- tenant-scoped records written by smoke tests satisfy their foreign keys. It stays inactive so
+-- cross-tenant jobs and reports skip it.
+
+ALTER TABLE tenant_connections DROP CONSTRAINT IF EXISTS chk_adapter_type;
+ALTER TABLE tenant_connections ADD CONSTRAINT chk_adapter_type
+    CHECK (adapter_type IN ('mywelltax', 'drake', 'lacerte', 'proseries', 'ultratax', 'smoke'));
+
+ALTER TABLE tenant_connections DROP CONSTRAINT IF EXISTS chk_storage_provider;
+ALTER TABLE tenant_connections ADD CONSTRAINT chk_storage_provider
+    CHECK (storage_provider IS NULL OR storage_provider IN ('gcs', 's3', 'azure', 'memory'));
+
+INSERT INTO tenant_connections (
+    tenant_id, tenant_name, db_host, db_port, db_user, db_password, db_name, db_sslmode,
+    schema_prefix, adapter_type, storage_provider, storage_bucket, is_active, created_by, notes
+) VALUES (
+    'smoke', 'Smoke Test', 'memory', 0, 'none', '', 'none', 'disable',
+    'smoke', 'smoke', 'memory', 'smoke', false, 'migration',
+    'Built-in synthetic tenant for POST /api/v1/admin/smoke-test. Served from memory; never holds real data.'
+)
+ON CONFLICT (tenant_id) DO NOTHING;
@@ -0,0 +1,3 @@
+-- Rollback tenant configuration history
+
+DROP TABLE IF EXISTS tenant_config_history;
@@ -0,0 +1,20 @@
+-- Changelog of tenant configuration changes
+
+-- ============================================================================
+-- Tenant Config History Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS tenant_config_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    action VARCHAR(20) NOT NULL,
+    changes JSONB NOT NULL,
+    changed_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_tenant_config_action CHECK (action IN ('CREATE', 'UPDATE', 'DEACTIVATE'))
+);
+
+CREATE INDEX idx_tenant_config_history_tenant ON tenant_config_history(tenant_id, changed_at DESC);
+
+COMMENT ON TABLE tenant_config_history IS 'One row per change to a tenant_connections row, with the fields that changed';
+COMMENT ON COLUMN tenant_config_history.changes IS 'Array of {field, before, after, redacted}; secret fields carry no values';
@@ -0,0 +1,4 @@
+-- Rollback legal documents
+
+DROP TABLE IF EXISTS legal_acceptances;
+DROP TABLE IF EXISTS legal_documents;
@@ -0,0 +1,44 @@
+-- Per-tenant terms of service and privacy policy with portal acceptance records
+
+-- ============================================================================
+-- Legal Documents Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS legal_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    version INTEGER NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES employees(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_legal_document_version UNIQUE (tenant_id, kind, version),
+    CONSTRAINT chk_legal_document_kind CHECK (kind IN ('TERMS_OF_SERVICE', 'PRIVACY_POLICY'))
+);
+
+CREATE UNIQUE INDEX uq_legal_documents_active ON legal_documents(tenant_id, kind) WHERE is_active = true;
+
+COMMENT ON TABLE legal_documents IS 'Versioned legal documents per tenant and kind; publishing a new version retires the previous one';
+
+-- ============================================================================
+-- Legal Acceptances Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS legal_acceptances (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    tenant_user_id UUID NOT NULL REFERENCES tenant_users(id) ON DELETE CASCADE,
+    document_id UUID NOT NULL REFERENCES legal_documents(id),
+    kind VARCHAR(50) NOT NULL,
+    version INTEGER NOT NULL,
+    accepted_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    accepted_ip INET,
+    accepted_user_agent TEXT,
+
+    CONSTRAINT uq_legal_acceptance UNIQUE (tenant_user_id, document_id)
+);
+
+CREATE INDEX idx_legal_acceptances_user ON legal_acceptances(tenant_id, tenant_user_id, kind);
+
+COMMENT ON TABLE legal_acceptances IS 'One row per portal user and document version accepted; the first acceptance of a version is kept';
@@ -0,0 +1,4 @@
+-- Rollback document drops
+
+DROP TABLE IF EXISTS document_drop_files;
+DROP TABLE IF EXISTS document_drops;
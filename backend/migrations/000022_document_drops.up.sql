@@ -0,0 +1,52 @@
+-- Bulk document delivery from partners (payroll providers) through GCS or SFTP drop locations
+
+-- ============================================================================
+-- Document Drops Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS document_drops (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    source_type VARCHAR(20) NOT NULL,
+    location VARCHAR(500) NOT NULL,
+    prefix VARCHAR(500) NOT NULL DEFAULT '',
+    document_type VARCHAR(50) NOT NULL DEFAULT 'W2',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    last_scanned_at TIMESTAMP,
+    last_error TEXT,
+    created_by UUID REFERENCES employees(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_document_drop_name UNIQUE (tenant_id, name),
+    CONSTRAINT chk_document_drop_source CHECK (source_type IN ('GCS', 'SFTP'))
+);
+
+COMMENT ON TABLE document_drops IS 'Per-tenant locations partners deliver document batches to; scanned by the document_drop_scan job';
+COMMENT ON COLUMN document_drops.location IS 'GCS bucket, or SFTP directory relative to the configured ingest.sftpRoot';
+
+-- ============================================================================
+-- Document Drop Files Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS document_drop_files (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    drop_id UUID NOT NULL REFERENCES document_drops(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    path VARCHAR(1000) NOT NULL,
+    batch VARCHAR(500) NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    reason TEXT,
+    client_id UUID,
+    filing_id UUID,
+    document_id UUID,
+    received_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    resolved_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+
+    CONSTRAINT uq_document_drop_file UNIQUE (drop_id, path),
+    CONSTRAINT chk_document_drop_file_status CHECK (status IN ('IMPORTED', 'UNMATCHED', 'RESOLVED', 'IGNORED'))
+);
+
+CREATE INDEX idx_document_drop_files_status ON document_drop_files(tenant_id, status, received_at DESC);
+
+COMMENT ON TABLE document_drop_files IS 'Every file taken from a drop and how it was matched; unmatched files wait for manual resolution';
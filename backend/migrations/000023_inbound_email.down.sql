@@ -0,0 +1,5 @@
+-- Rollback inbound email ingestion
+
+DROP TABLE IF EXISTS inbound_email_attachments;
+DROP TABLE IF EXISTS inbound_emails;
+DROP TABLE IF EXISTS inbound_email_addresses;
@@ -0,0 +1,57 @@
+-- Documents clients email to a per-tenant inbound address (SendGrid Inbound Parse)
+
+-- ============================================================================
+-- Inbound Email Addresses Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS inbound_email_addresses (
+    tenant_id VARCHAR(100) PRIMARY KEY REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    local_part VARCHAR(100) NOT NULL UNIQUE,
+    created_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE inbound_email_addresses IS 'Each tenant''s inbound address local part; the domain comes from the inbound.domain setting';
+
+-- ============================================================================
+-- Inbound Emails Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS inbound_emails (
+    id UUID PRIMARY KEY,
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    from_email VARCHAR(255) NOT NULL,
+    from_name VARCHAR(255),
+    subject TEXT,
+    client_id UUID,
+    skipped_attachments INTEGER NOT NULL DEFAULT 0,
+    acknowledged_at TIMESTAMP,
+    received_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_inbound_emails_tenant ON inbound_emails(tenant_id, received_at DESC);
+
+COMMENT ON COLUMN inbound_emails.client_id IS 'Client whose email matched the sender; NULL when none or several matched';
+
+-- ============================================================================
+-- Inbound Email Attachments Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS inbound_email_attachments (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    email_id UUID NOT NULL REFERENCES inbound_emails(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    storage_path VARCHAR(1000) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    client_id UUID,
+    filing_id UUID,
+    document_id UUID,
+    reviewed_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    reviewed_at TIMESTAMP,
+
+    CONSTRAINT chk_inbound_attachment_status CHECK (status IN ('PENDING', 'CONFIRMED', 'REJECTED'))
+);
+
+CREATE INDEX idx_inbound_attachments_status ON inbound_email_attachments(tenant_id, status);
+
+COMMENT ON TABLE inbound_email_attachments IS 'Emailed files held in tenant storage until an admin confirms them as documents or rejects them';
@@ -0,0 +1,5 @@
+-- Rollback print and mail fulfillment
+
+DROP TABLE IF EXISTS mail_pieces;
+DROP TABLE IF EXISTS mailings;
+DROP TABLE IF EXISTS tenant_mail_settings;
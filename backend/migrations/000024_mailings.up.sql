@@ -0,0 +1,60 @@
+-- Printed and mailed copies of client documents sent through a print-and-mail provider (Lob)
+
+-- ============================================================================
+-- Tenant Mail Settings Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS tenant_mail_settings (
+    tenant_id VARCHAR(100) PRIMARY KEY REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    return_name VARCHAR(255) NOT NULL,
+    return_address JSONB NOT NULL,
+    updated_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE tenant_mail_settings IS 'Return address printed on each tenant''s mailings';
+
+-- ============================================================================
+-- Mailings Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS mailings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    client_id UUID NOT NULL,
+    recipient_name VARCHAR(255) NOT NULL,
+    address JSONB NOT NULL,
+    description TEXT,
+    color BOOLEAN NOT NULL DEFAULT false,
+    requested_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_mailings_tenant ON mailings(tenant_id, created_at DESC);
+CREATE INDEX idx_mailings_client ON mailings(tenant_id, client_id);
+
+COMMENT ON COLUMN mailings.address IS 'Standardized address from the client''s latest deliverable address validation';
+
+-- ============================================================================
+-- Mail Pieces Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS mail_pieces (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    mailing_id UUID NOT NULL REFERENCES mailings(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    document_id UUID NOT NULL,
+    document_name VARCHAR(255) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    provider_id VARCHAR(255),
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    cost_cents BIGINT NOT NULL DEFAULT 0,
+    expected_delivery DATE,
+    error TEXT,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_mail_piece_status CHECK (status IN ('PENDING', 'SUBMITTED', 'MAILED', 'IN_TRANSIT', 'DELIVERED', 'RETURNED', 'CANCELLED', 'FAILED'))
+);
+
+CREATE UNIQUE INDEX idx_mail_pieces_provider_id ON mail_pieces(provider, provider_id) WHERE provider_id IS NOT NULL;
+CREATE INDEX idx_mail_pieces_mailing ON mail_pieces(mailing_id);
+
+COMMENT ON TABLE mail_pieces IS 'One letter per document; the provider accepts a single file per letter';
+COMMENT ON COLUMN mail_pieces.cost_cents IS 'Price charged for the letter, recorded when it was submitted';
@@ -0,0 +1,9 @@
+-- Rollback affiliate manager role (fails while any employee or grant still has the role)
+
+ALTER TABLE employee_tenant_access DROP CONSTRAINT IF EXISTS chk_tenant_role;
+ALTER TABLE employee_tenant_access ADD CONSTRAINT chk_tenant_role
+    CHECK (role IN ('admin', 'accountant', 'viewer'));
+
+ALTER TABLE employees DROP CONSTRAINT IF EXISTS chk_employee_role;
+ALTER TABLE employees ADD CONSTRAINT chk_employee_role
+    CHECK (role IN ('admin', 'accountant', 'viewer'));
@@ -0,0 +1,10 @@
+-- Affiliate manager role: marketing staff who manage affiliates, commissions, discount codes and
+-- campaigns without access to client data
+
+ALTER TABLE employees DROP CONSTRAINT IF EXISTS chk_employee_role;
+ALTER TABLE employees ADD CONSTRAINT chk_employee_role
+    CHECK (role IN ('admin', 'accountant', 'viewer', 'affiliate_manager'));
+
+ALTER TABLE employee_tenant_access DROP CONSTRAINT IF EXISTS chk_tenant_role;
+ALTER TABLE employee_tenant_access ADD CONSTRAINT chk_tenant_role
+    CHECK (role IN ('admin', 'accountant', 'viewer', 'affiliate_manager'));
@@ -0,0 +1,4 @@
+-- Rollback document expiry and document requests
+
+DROP TABLE IF EXISTS document_requests;
+DROP TABLE IF EXISTS document_expirations;
@@ -0,0 +1,55 @@
+-- Expiry dates on client documents and the document requests raised when they run out
+
+-- ============================================================================
+-- Document Expirations Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS document_expirations (
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    document_id UUID NOT NULL,
+    client_id UUID NOT NULL,
+    document_type VARCHAR(100) NOT NULL,
+    document_name VARCHAR(255) NOT NULL,
+    expires_on DATE NOT NULL,
+    set_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, document_id)
+);
+
+CREATE INDEX idx_document_expirations_expires ON document_expirations(expires_on);
+
+COMMENT ON TABLE document_expirations IS 'Expiry dates of documents in tenant databases (IDs, POAs); identity documents keep theirs in identity_documents.expires_on';
+
+-- ============================================================================
+-- Document Requests Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS document_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    client_id UUID NOT NULL,
+    document_type VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL,
+    reason VARCHAR(20) NOT NULL,
+    source_document_id UUID,
+    due_on DATE,
+    status VARCHAR(20) NOT NULL DEFAULT 'OPEN',
+    requested_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    reminder_count INTEGER NOT NULL DEFAULT 0,
+    last_reminded_at TIMESTAMP,
+    fulfilled_document_id UUID,
+    closed_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    closed_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_document_requests_reason CHECK (reason IN ('EXPIRING', 'MANUAL')),
+    CONSTRAINT chk_document_requests_status CHECK (status IN ('OPEN', 'FULFILLED', 'CANCELLED'))
+);
+
+CREATE INDEX idx_document_requests_client ON document_requests(tenant_id, client_id, created_at DESC);
+CREATE INDEX idx_document_requests_open ON document_requests(last_reminded_at NULLS FIRST) WHERE status = 'OPEN';
+CREATE UNIQUE INDEX idx_document_requests_source ON document_requests(tenant_id, source_document_id) WHERE source_document_id IS NOT NULL;
+
+COMMENT ON COLUMN document_requests.source_document_id IS 'Expiring document this request replaces; at most one request is raised per document';
+COMMENT ON COLUMN document_requests.requested_by IS 'Employee who asked for the document; NULL when raised by the document expiry job';
+COMMENT ON COLUMN document_requests.fulfilled_document_id IS 'Document (or identity document) whose upload fulfilled the request';
@@ -0,0 +1,11 @@
+-- Rollback audit hash chain and anchors
+
+DROP TABLE IF EXISTS audit_anchors;
+
+DROP TRIGGER IF EXISTS trg_audit_logs_append_only ON audit_logs;
+DROP FUNCTION IF EXISTS audit_logs_append_only();
+
+DROP INDEX IF EXISTS idx_audit_chain_seq;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS entry_hash;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS prev_hash;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS chain_seq;
@@ -0,0 +1,43 @@
+-- Tamper-evident audit log: entries are hash-chained, append-only, and the chain head is anchored in WORM storage
+
+-- ============================================================================
+-- Audit Log Hash Chain
+-- ============================================================================
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS chain_seq BIGINT;
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS prev_hash CHAR(64);
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS entry_hash CHAR(64);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_audit_chain_seq ON audit_logs(chain_seq) WHERE chain_seq IS NOT NULL;
+
+COMMENT ON COLUMN audit_logs.chain_seq IS 'Position in the audit hash chain; NULL for entries written before the chain was introduced';
+COMMENT ON COLUMN audit_logs.prev_hash IS 'entry_hash of the previous entry in the chain (64 zeros for the first)';
+COMMENT ON COLUMN audit_logs.entry_hash IS 'SHA-256 of prev_hash and the entry''s canonical fields, hex encoded';
+
+-- Audit entries are never changed or removed; employees and tenants are deactivated, not deleted
+CREATE OR REPLACE FUNCTION audit_logs_append_only() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_logs is append-only (% rejected)', TG_OP;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS trg_audit_logs_append_only ON audit_logs;
+CREATE TRIGGER trg_audit_logs_append_only
+    BEFORE UPDATE OR DELETE ON audit_logs
+    FOR EACH ROW EXECUTE FUNCTION audit_logs_append_only();
+
+-- ============================================================================
+-- Audit Anchors Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS audit_anchors (
+    chain_seq BIGINT PRIMARY KEY,
+    entry_hash CHAR(64) NOT NULL,
+    bucket VARCHAR(255) NOT NULL,
+    object_path TEXT NOT NULL,
+    anchored_by VARCHAR(255) NOT NULL,
+    anchored_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_audit_anchors_time ON audit_anchors(anchored_at DESC);
+
+COMMENT ON TABLE audit_anchors IS 'Chain heads written to the WORM anchor bucket; verification compares them with the bucket copy and the chain';
+COMMENT ON COLUMN audit_anchors.anchored_by IS 'Process identifier (host and pid) that wrote the anchor';
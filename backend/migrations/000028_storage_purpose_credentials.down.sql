@@ -0,0 +1,8 @@
+-- Rollback per-purpose storage credentials
+
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS storage_delete_credentials_path;
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS storage_delete_credentials_secret;
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS storage_read_credentials_path;
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS storage_read_credentials_secret;
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS storage_upload_credentials_path;
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS storage_upload_credentials_secret;
@@ -0,0 +1,14 @@
+-- Separate storage credentials for uploading, reading and deleting tenant documents.
+-- Each pair is optional; an operation without its own credentials uses the shared
+-- storage_credentials_secret/storage_credentials_path, then ADC.
+
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS storage_upload_credentials_secret VARCHAR(500);
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS storage_upload_credentials_path VARCHAR(500);
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS storage_read_credentials_secret VARCHAR(500);
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS storage_read_credentials_path VARCHAR(500);
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS storage_delete_credentials_secret VARCHAR(500);
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS storage_delete_credentials_path VARCHAR(500);
+
+COMMENT ON COLUMN tenant_connections.storage_upload_credentials_secret IS 'Secret Manager path of credentials used only to upload documents';
+COMMENT ON COLUMN tenant_connections.storage_read_credentials_secret IS 'Secret Manager path of credentials used only to download, list and sign URLs';
+COMMENT ON COLUMN tenant_connections.storage_delete_credentials_secret IS 'Secret Manager path of credentials used only to delete documents';
@@ -0,0 +1,4 @@
+-- Rollback tenant offboarding workflow
+
+DROP TABLE IF EXISTS tenant_offboarding_steps;
+DROP TABLE IF EXISTS tenant_offboardings;
@@ -0,0 +1,52 @@
+-- Tenant offboarding workflow: the steps that wind down a departing tenant and their report
+
+-- ============================================================================
+-- Tenant Offboardings Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS tenant_offboardings (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    status VARCHAR(30) NOT NULL DEFAULT 'STARTED',
+    reason TEXT,
+    started_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    started_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    export_requested_at TIMESTAMP,
+    export_completed_at TIMESTAMP,
+    export_path VARCHAR(500),
+    export_error TEXT,
+    retention_until TIMESTAMP,
+    retention_alerted_at TIMESTAMP,
+    report JSONB,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_tenant_offboardings_status CHECK (status IN (
+        'STARTED', 'PORTAL_BLOCKED', 'EXPORT_SCHEDULED', 'ACCESS_REVOKED',
+        'CACHES_PURGED', 'RETENTION_SET', 'COMPLETED', 'CANCELLED'
+    ))
+);
+
+-- A tenant has at most one offboarding in progress
+CREATE UNIQUE INDEX idx_tenant_offboardings_open ON tenant_offboardings(tenant_id) WHERE status NOT IN ('COMPLETED', 'CANCELLED');
+CREATE INDEX idx_tenant_offboardings_tenant ON tenant_offboardings(tenant_id, started_at DESC);
+CREATE INDEX idx_tenant_offboardings_export ON tenant_offboardings(export_requested_at) WHERE export_requested_at IS NOT NULL AND export_completed_at IS NULL;
+
+COMMENT ON COLUMN tenant_offboardings.status IS 'Last step completed; portal access is blocked from PORTAL_BLOCKED on';
+COMMENT ON COLUMN tenant_offboardings.export_path IS 'Object in the tenant bucket holding the platform data export';
+COMMENT ON COLUMN tenant_offboardings.retention_until IS 'When the tenant bucket may be purged; admins are alerted once it passes';
+COMMENT ON COLUMN tenant_offboardings.report IS 'Offboarding report produced by the complete step';
+
+-- ============================================================================
+-- Tenant Offboarding Steps Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS tenant_offboarding_steps (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    offboarding_id UUID NOT NULL REFERENCES tenant_offboardings(id) ON DELETE CASCADE,
+    step VARCHAR(30) NOT NULL,
+    performed_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    details JSONB,
+    performed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_tenant_offboarding_step UNIQUE (offboarding_id, step)
+);
+
+COMMENT ON TABLE tenant_offboarding_steps IS 'Who ran each offboarding step, when, and what it changed';
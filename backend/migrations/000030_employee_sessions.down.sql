@@ -0,0 +1,4 @@
+-- Rollback employee sessions
+
+DROP TABLE IF EXISTS employee_session_tokens;
+DROP TABLE IF EXISTS employee_sessions;
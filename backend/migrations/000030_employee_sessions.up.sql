@@ -0,0 +1,42 @@
+-- Server-side employee sessions with rotating refresh tokens
+
+-- ============================================================================
+-- Employee Sessions Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS employee_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    firebase_uid VARCHAR(128) NOT NULL,
+    auth_time BIGINT NOT NULL,
+    firebase_refresh_token TEXT,
+    ip_address INET,
+    user_agent TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_refreshed_at TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    revoked_by UUID REFERENCES employees(id),
+    revoke_reason VARCHAR(50)
+);
+
+CREATE INDEX idx_employee_sessions_employee ON employee_sessions(employee_id, created_at DESC);
+CREATE INDEX idx_employee_sessions_sign_in ON employee_sessions(firebase_uid, auth_time);
+
+COMMENT ON TABLE employee_sessions IS 'Employee sign-ins made through the API; ID tokens are matched to their session by Firebase UID and auth_time';
+COMMENT ON COLUMN employee_sessions.auth_time IS 'auth_time claim of the sign-in, carried by every ID token refreshed from it';
+COMMENT ON COLUMN employee_sessions.firebase_refresh_token IS 'Firebase refresh token, AES-256-GCM encrypted like tenant database passwords; cleared on revoke';
+COMMENT ON COLUMN employee_sessions.revoked_by IS 'Admin who revoked the session; NULL for logout and refresh token reuse';
+
+-- ============================================================================
+-- Employee Session Tokens Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS employee_session_tokens (
+    token_hash VARCHAR(64) PRIMARY KEY,
+    session_id UUID NOT NULL REFERENCES employee_sessions(id) ON DELETE CASCADE,
+    issued_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    rotated_at TIMESTAMP
+);
+
+CREATE INDEX idx_employee_session_tokens_session ON employee_session_tokens(session_id);
+
+COMMENT ON TABLE employee_session_tokens IS 'Refresh tokens issued to sessions, by SHA-256 hash; rotated tokens are kept so their reuse revokes the session';
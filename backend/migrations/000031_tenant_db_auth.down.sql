@@ -0,0 +1,6 @@
+-- Rollback tenant database auth types; IAM tenants must be given a password first
+
+ALTER TABLE tenant_connections DROP CONSTRAINT IF EXISTS chk_tenant_connections_db_auth;
+ALTER TABLE tenant_connections ALTER COLUMN db_password SET NOT NULL;
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS db_instance_connection_name;
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS db_auth_type;
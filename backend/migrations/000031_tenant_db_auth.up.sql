@@ -0,0 +1,18 @@
+-- How tenant databases are authenticated and dialed. 'password' uses db_password over TCP;
+-- 'iam' sends a Cloud SQL IAM token instead of a password over TCP; 'connector' sends an IAM
+-- token through the Cloud SQL instance socket named by db_instance_connection_name.
+-- Neither IAM type stores a password.
+
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS db_auth_type VARCHAR(20) NOT NULL DEFAULT 'password'
+    CHECK (db_auth_type IN ('password', 'iam', 'connector'));
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS db_instance_connection_name VARCHAR(255);
+ALTER TABLE tenant_connections ALTER COLUMN db_password DROP NOT NULL;
+
+ALTER TABLE tenant_connections ADD CONSTRAINT chk_tenant_connections_db_auth CHECK (
+    (db_auth_type = 'password' AND db_password IS NOT NULL)
+    OR (db_auth_type = 'iam')
+    OR (db_auth_type = 'connector' AND db_instance_connection_name IS NOT NULL)
+);
+
+COMMENT ON COLUMN tenant_connections.db_auth_type IS 'password, iam (IAM token over TCP) or connector (IAM token through the Cloud SQL instance socket)';
+COMMENT ON COLUMN tenant_connections.db_instance_connection_name IS 'Cloud SQL instance connection name (PROJECT:REGION:INSTANCE) dialed by the connector auth type';
@@ -0,0 +1,4 @@
+-- Rollback affiliate notifications and preferences
+
+DROP TABLE IF EXISTS affiliate_notification_preferences;
+DROP TABLE IF EXISTS affiliate_notifications;
@@ -0,0 +1,40 @@
+-- Commission lifecycle notifications for affiliates and their email preferences
+
+-- ============================================================================
+-- Affiliate Notifications Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS affiliate_notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    affiliate_id UUID NOT NULL,
+    commission_id UUID NOT NULL,
+    event VARCHAR(30) NOT NULL,
+    commission_amount NUMERIC(12, 2) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMP,
+    email_pending BOOLEAN NOT NULL,
+    emailed_at TIMESTAMP,
+
+    CONSTRAINT chk_affiliate_notification_event CHECK (event IN ('COMMISSION_CREATED', 'COMMISSION_APPROVED', 'COMMISSION_PAID', 'COMMISSION_CANCELLED'))
+);
+
+CREATE INDEX idx_affiliate_notifications_affiliate ON affiliate_notifications(tenant_id, affiliate_id, created_at DESC);
+CREATE INDEX idx_affiliate_notifications_email ON affiliate_notifications(tenant_id, affiliate_id) WHERE email_pending;
+
+COMMENT ON TABLE affiliate_notifications IS 'Commission events shown on affiliate dashboards; pending ones are emailed in one batch per affiliate';
+
+-- ============================================================================
+-- Affiliate Notification Preferences Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS affiliate_notification_preferences (
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    affiliate_id UUID NOT NULL,
+    event VARCHAR(30) NOT NULL,
+    email BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, affiliate_id, event),
+    CONSTRAINT chk_affiliate_notification_preference_event CHECK (event IN ('COMMISSION_CREATED', 'COMMISSION_APPROVED', 'COMMISSION_PAID', 'COMMISSION_CANCELLED'))
+);
+
+COMMENT ON TABLE affiliate_notification_preferences IS 'Whether an affiliate is emailed about each commission event (missing rows are emailed)';
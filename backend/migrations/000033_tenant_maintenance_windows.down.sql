@@ -0,0 +1,3 @@
+-- Rollback tenant maintenance windows
+
+DROP TABLE IF EXISTS tenant_maintenance_windows;
@@ -0,0 +1,23 @@
+-- Scheduled maintenance windows shown on tenant status pages
+
+-- ============================================================================
+-- Tenant Maintenance Windows Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS tenant_maintenance_windows (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    message VARCHAR(500) NOT NULL,
+    affects_portal BOOLEAN NOT NULL DEFAULT true,
+    affects_uploads BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_tenant_maintenance_window_range CHECK (ends_at > starts_at),
+    CONSTRAINT chk_tenant_maintenance_window_affects CHECK (affects_portal OR affects_uploads)
+);
+
+CREATE INDEX idx_tenant_maintenance_windows_tenant ON tenant_maintenance_windows(tenant_id, ends_at);
+
+COMMENT ON TABLE tenant_maintenance_windows IS 'Planned downtime per tenant; the public status page reports the services a current window takes down';
@@ -0,0 +1,3 @@
+-- Rollback tenant ID version
+
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS id_version;
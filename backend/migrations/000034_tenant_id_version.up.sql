@@ -0,0 +1,8 @@
+-- UUID version of the records adapters create in each tenant database. 'v7' IDs are
+-- time-ordered, so inserts stay together in primary key indexes; 'v4' keeps random IDs for
+-- tenant applications that expect them.
+
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS id_version VARCHAR(2) NOT NULL DEFAULT 'v7'
+    CHECK (id_version IN ('v7', 'v4'));
+
+COMMENT ON COLUMN tenant_connections.id_version IS 'v7 (time-ordered, default) or v4 (random) UUIDs for new tenant records';
@@ -0,0 +1,48 @@
+-- Tenant webhooks for commission and filing lifecycle events and their delivery log
+
+-- ============================================================================
+-- Webhooks Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    url VARCHAR(2000) NOT NULL,
+    description VARCHAR(200),
+    events TEXT[] NOT NULL,
+    secret TEXT NOT NULL, -- Encrypted HMAC secret
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_webhook_events CHECK (cardinality(events) > 0)
+);
+
+CREATE INDEX idx_webhooks_tenant ON webhooks(tenant_id) WHERE active;
+
+COMMENT ON TABLE webhooks IS 'Tenant endpoints that receive HMAC-signed POSTs for the events they subscribe to';
+
+-- ============================================================================
+-- Webhook Deliveries Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS webhook_deliveries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    webhook_id UUID NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    event VARCHAR(50) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP DEFAULT NOW(),
+    last_status_code INTEGER,
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP,
+
+    CONSTRAINT chk_webhook_delivery_status CHECK (status IN ('PENDING', 'DELIVERED', 'FAILED'))
+);
+
+CREATE INDEX idx_webhook_deliveries_webhook ON webhook_deliveries(webhook_id, created_at DESC);
+CREATE INDEX idx_webhook_deliveries_due ON webhook_deliveries(next_attempt_at) WHERE status = 'PENDING';
+
+COMMENT ON TABLE webhook_deliveries IS 'One row per event sent to a webhook; pending rows are retried with exponential backoff';
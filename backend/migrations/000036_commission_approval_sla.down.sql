@@ -0,0 +1,4 @@
+-- Rollback commission approval SLA
+
+DROP TABLE IF EXISTS commission_sla_alerts;
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS commission_sla;
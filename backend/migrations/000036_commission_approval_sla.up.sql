@@ -0,0 +1,22 @@
+-- Per-tenant commission approval SLA and the overdue commissions admins were alerted about
+
+-- ============================================================================
+-- Tenant Connections: Commission SLA
+-- ============================================================================
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS commission_sla JSONB;
+
+COMMENT ON COLUMN tenant_connections.commission_sla IS 'Commission approval SLA configuration (NULL uses application defaults)';
+
+-- ============================================================================
+-- Commission SLA Alerts Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS commission_sla_alerts (
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    commission_id UUID NOT NULL, -- Commission in the tenant database
+    first_alerted_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_alerted_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, commission_id)
+);
+
+COMMENT ON TABLE commission_sla_alerts IS 'Overdue PENDING commissions admins have been alerted about; rows are removed once a commission is no longer overdue';
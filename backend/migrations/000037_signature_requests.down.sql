@@ -0,0 +1,4 @@
+-- Rollback signature requests
+
+DROP TABLE IF EXISTS signature_requests;
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS docusign_connect_secret;
@@ -0,0 +1,37 @@
+-- DocuSign envelopes sent for signature and the status DocuSign Connect last reported for them
+
+-- ============================================================================
+-- Tenant Connections: DocuSign Connect HMAC Secret
+-- ============================================================================
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS docusign_connect_secret TEXT;
+
+COMMENT ON COLUMN tenant_connections.docusign_connect_secret IS 'Encrypted HMAC key DocuSign Connect signs its events with (NULL rejects Connect events)';
+
+-- ============================================================================
+-- Signature Requests Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS signature_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    envelope_id VARCHAR(100) NOT NULL,
+    filing_id UUID, -- Filing in the tenant database, when the request named one
+    taxpayer_name VARCHAR(255) NOT NULL,
+    taxpayer_email VARCHAR(255) NOT NULL,
+    spouse_signature BOOLEAN NOT NULL DEFAULT FALSE,
+    status VARCHAR(20) NOT NULL DEFAULT 'SENT',
+    sent_by UUID REFERENCES employees(id),
+    sent_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMP,
+    completed_at TIMESTAMP,
+    declined_at TIMESTAMP,
+    voided_at TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT uq_signature_request_envelope UNIQUE (tenant_id, envelope_id),
+    CONSTRAINT chk_signature_request_status CHECK (status IN ('SENT', 'DELIVERED', 'COMPLETED', 'DECLINED', 'VOIDED'))
+);
+
+CREATE INDEX idx_signature_requests_tenant_status ON signature_requests(tenant_id, status, sent_at);
+
+COMMENT ON TABLE signature_requests IS 'Form 8879 envelopes sent through DocuSign, advanced by DocuSign Connect events';
+COMMENT ON COLUMN signature_requests.status IS 'Latest status reported by DocuSign; it never moves backwards';
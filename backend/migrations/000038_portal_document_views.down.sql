@@ -0,0 +1,3 @@
+-- Rollback portal document views
+
+DROP TABLE IF EXISTS portal_document_views;
@@ -0,0 +1,20 @@
+-- Documents portal users opened in the browser
+
+-- ============================================================================
+-- Portal Document Views Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS portal_document_views (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    tenant_user_id UUID NOT NULL REFERENCES tenant_users(id) ON DELETE CASCADE,
+    client_id UUID NOT NULL, -- Client in the tenant database
+    document_id UUID NOT NULL, -- Document in the tenant database
+    content_type VARCHAR(100) NOT NULL,
+    ip_address INET,
+    user_agent TEXT,
+    viewed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_portal_document_views_client ON portal_document_views(tenant_id, client_id, viewed_at DESC);
+
+COMMENT ON TABLE portal_document_views IS 'Audit trail of documents portal users viewed inline; audit_logs only records employee actions';
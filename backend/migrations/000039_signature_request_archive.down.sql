@@ -0,0 +1,5 @@
+-- Rollback signature request archive
+
+ALTER TABLE signature_requests DROP COLUMN IF EXISTS archive_error;
+ALTER TABLE signature_requests DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE signature_requests DROP COLUMN IF EXISTS document_id;
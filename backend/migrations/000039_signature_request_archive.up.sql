@@ -0,0 +1,11 @@
+-- Signed documents archived from completed DocuSign envelopes
+
+-- ============================================================================
+-- Signature Requests: Archived Document
+-- ============================================================================
+ALTER TABLE signature_requests ADD COLUMN IF NOT EXISTS document_id UUID;
+ALTER TABLE signature_requests ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP;
+ALTER TABLE signature_requests ADD COLUMN IF NOT EXISTS archive_error TEXT;
+
+COMMENT ON COLUMN signature_requests.document_id IS 'Document in the tenant database holding the signed PDF';
+COMMENT ON COLUMN signature_requests.archive_error IS 'Why the last attempt to archive the signed PDF failed (NULL once archived)';
@@ -0,0 +1,3 @@
+-- Rollback employee tenant contexts
+
+DROP TABLE IF EXISTS employee_tenant_contexts;
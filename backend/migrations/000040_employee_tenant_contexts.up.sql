@@ -0,0 +1,22 @@
+-- Short-lived tenant contexts employees select for tenant-scoped API calls
+
+-- ============================================================================
+-- Employee Tenant Contexts Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS employee_tenant_contexts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    role VARCHAR(50) NOT NULL,
+    auth_time BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP
+);
+
+CREATE INDEX idx_employee_tenant_contexts_employee ON employee_tenant_contexts(employee_id, created_at DESC);
+
+COMMENT ON TABLE employee_tenant_contexts IS 'Tenant contexts minted for employees, by SHA-256 hash of their token';
+COMMENT ON COLUMN employee_tenant_contexts.role IS 'Role the context grants in the tenant; never broader than the employee role';
+COMMENT ON COLUMN employee_tenant_contexts.auth_time IS 'auth_time claim of the sign-in the context was minted from; other sign-ins cannot use it';
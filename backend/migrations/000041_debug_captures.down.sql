@@ -0,0 +1,4 @@
+-- Rollback debug captures
+
+DROP TABLE IF EXISTS debug_capture_entries;
+DROP TABLE IF EXISTS debug_captures;
@@ -0,0 +1,45 @@
+-- Opt-in request/response capture for debugging tenant issues
+
+-- ============================================================================
+-- Debug Captures Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS debug_captures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    reason TEXT NOT NULL,
+    enabled_by UUID NOT NULL REFERENCES employees(id),
+    enabled_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    disabled_at TIMESTAMP,
+    disabled_by UUID REFERENCES employees(id)
+);
+
+CREATE INDEX idx_debug_captures_tenant ON debug_captures(tenant_id, expires_at DESC) WHERE disabled_at IS NULL;
+
+COMMENT ON TABLE debug_captures IS 'Periods during which a tenant''s API requests and responses are captured';
+COMMENT ON COLUMN debug_captures.expires_at IS 'Capture stops at this time even if nobody disables it';
+
+-- ============================================================================
+-- Debug Capture Entries Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS debug_capture_entries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    capture_id UUID NOT NULL REFERENCES debug_captures(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(100) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    query TEXT,
+    status INT NOT NULL,
+    duration_ms INT NOT NULL,
+    request_body TEXT,
+    response_body TEXT,
+    captured_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_debug_capture_entries_tenant ON debug_capture_entries(tenant_id, captured_at DESC);
+CREATE INDEX idx_debug_capture_entries_captured ON debug_capture_entries(captured_at);
+
+COMMENT ON TABLE debug_capture_entries IS 'Captured requests; purged after the retention period';
+COMMENT ON COLUMN debug_capture_entries.query IS 'Query string with configured fields redacted';
+COMMENT ON COLUMN debug_capture_entries.request_body IS 'Encrypted JSON body with configured fields redacted';
+COMMENT ON COLUMN debug_capture_entries.response_body IS 'Encrypted JSON body with configured fields redacted';
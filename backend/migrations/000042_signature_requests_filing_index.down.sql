@@ -0,0 +1,3 @@
+-- Rollback signature requests filing index
+
+DROP INDEX IF EXISTS idx_signature_requests_filing;
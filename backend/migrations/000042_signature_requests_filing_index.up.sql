@@ -0,0 +1,8 @@
+-- Look up signature requests by filing, for the completeness score in filing lists
+
+-- ============================================================================
+-- Signature Requests: Filing Index
+-- ============================================================================
+CREATE INDEX IF NOT EXISTS idx_signature_requests_filing
+    ON signature_requests(tenant_id, filing_id, sent_at DESC)
+    WHERE filing_id IS NOT NULL;
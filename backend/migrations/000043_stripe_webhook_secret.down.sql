@@ -0,0 +1,3 @@
+-- Rollback Stripe webhook secret
+
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS stripe_webhook_secret;
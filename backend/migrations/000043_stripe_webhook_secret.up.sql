@@ -0,0 +1,8 @@
+-- Stripe webhook signing secret, for recording checkout payments and creating affiliate commissions
+
+-- ============================================================================
+-- Tenant Connections: Stripe Webhook Secret
+-- ============================================================================
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS stripe_webhook_secret TEXT;
+
+COMMENT ON COLUMN tenant_connections.stripe_webhook_secret IS 'Encrypted signing secret of the tenant''s Stripe webhook endpoint (NULL rejects Stripe events)';
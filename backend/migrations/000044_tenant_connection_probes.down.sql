@@ -0,0 +1,3 @@
+-- Rollback tenant connection probes
+
+DROP TABLE IF EXISTS tenant_connection_probes;
@@ -0,0 +1,18 @@
+-- Scheduled tenant database probes, kept for uptime history and outage alerts
+
+-- ============================================================================
+-- Tenant Connection Probes Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS tenant_connection_probes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    checked_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    healthy BOOLEAN NOT NULL,
+    latency_ms INTEGER NOT NULL DEFAULT 0,
+    error TEXT
+);
+
+CREATE INDEX idx_tenant_connection_probes_tenant_checked ON tenant_connection_probes(tenant_id, checked_at DESC);
+
+COMMENT ON TABLE tenant_connection_probes IS 'One row per scheduled ping of a tenant database; rows older than 30 days are pruned as new probes are recorded';
+COMMENT ON COLUMN tenant_connection_probes.error IS 'Why the ping failed (NULL when healthy)';
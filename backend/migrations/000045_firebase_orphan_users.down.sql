@@ -0,0 +1,3 @@
+-- Rollback Firebase orphan users
+
+DROP TABLE IF EXISTS firebase_orphan_users;
@@ -0,0 +1,18 @@
+-- Firebase accounts with no employee or portal user, found by the nightly reconciliation job
+
+-- ============================================================================
+-- Firebase Orphan Users Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS firebase_orphan_users (
+    uid VARCHAR(128) PRIMARY KEY,
+    email VARCHAR(255),
+    disabled BOOLEAN NOT NULL DEFAULT false,
+    created_at TIMESTAMP,
+    last_sign_in_at TIMESTAMP,
+    first_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE firebase_orphan_users IS 'Firebase accounts whose UID matches no employee or tenant user as of the last reconciliation run; rows are removed once an account is matched or deleted';
+COMMENT ON COLUMN firebase_orphan_users.created_at IS 'When the account was created in Firebase';
+COMMENT ON COLUMN firebase_orphan_users.first_seen_at IS 'The reconciliation run that first reported the account';
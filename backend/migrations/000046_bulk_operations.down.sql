@@ -0,0 +1,5 @@
+-- Rollback bulk operations and filing assignments
+
+DROP TABLE IF EXISTS bulk_operation_items;
+DROP TABLE IF EXISTS bulk_operations;
+DROP TABLE IF EXISTS filing_assignments;
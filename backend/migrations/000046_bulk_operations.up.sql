@@ -0,0 +1,65 @@
+-- Bulk operations over a tenant's clients or filings, run by the worker, and filing assignments
+
+-- ============================================================================
+-- Filing Assignments Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS filing_assignments (
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    filing_id UUID NOT NULL,
+    employee_id UUID NOT NULL REFERENCES employees(id),
+    assigned_by UUID REFERENCES employees(id),
+    assigned_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tenant_id, filing_id)
+);
+
+CREATE INDEX idx_filing_assignments_employee ON filing_assignments(tenant_id, employee_id);
+
+COMMENT ON TABLE filing_assignments IS 'The accountant working each filing; filings live in the tenant database, so filing_id is not a foreign key';
+COMMENT ON COLUMN filing_assignments.assigned_by IS 'Employee who made the assignment, directly or through a bulk operation';
+
+-- ============================================================================
+-- Bulk Operations Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS bulk_operations (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    action VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'QUEUED',
+    selection JSONB NOT NULL,
+    params JSONB NOT NULL DEFAULT '{}',
+    total_items INTEGER NOT NULL DEFAULT 0,
+    succeeded_items INTEGER NOT NULL DEFAULT 0,
+    failed_items INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    created_by UUID NOT NULL REFERENCES employees(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    selected_at TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX idx_bulk_operations_tenant_created ON bulk_operations(tenant_id, created_at DESC);
+CREATE INDEX idx_bulk_operations_unfinished ON bulk_operations(created_at) WHERE status IN ('QUEUED', 'RUNNING');
+
+COMMENT ON TABLE bulk_operations IS 'One action applied to many clients or filings of a tenant, queued by staff and run by the bulk_operations worker job';
+COMMENT ON COLUMN bulk_operations.selection IS 'The IDs or the filter the items were chosen by';
+COMMENT ON COLUMN bulk_operations.params IS 'Inputs of the action, such as the document requested or the new assignee';
+COMMENT ON COLUMN bulk_operations.error IS 'Why the operation failed as a whole (NULL when its items were processed)';
+COMMENT ON COLUMN bulk_operations.selected_at IS 'When the selection was resolved into items; a run interrupted after this resumes with the pending items';
+
+-- ============================================================================
+-- Bulk Operation Items Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS bulk_operation_items (
+    operation_id UUID NOT NULL REFERENCES bulk_operations(id) ON DELETE CASCADE,
+    item_id UUID NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    result TEXT,
+    error TEXT,
+    processed_at TIMESTAMP,
+    PRIMARY KEY (operation_id, item_id)
+);
+
+COMMENT ON TABLE bulk_operation_items IS 'The outcome of a bulk operation for each client or filing it selected';
+COMMENT ON COLUMN bulk_operation_items.item_id IS 'Client or filing ID in the tenant database, depending on the action';
+COMMENT ON COLUMN bulk_operation_items.result IS 'What the action did, e.g. the document request raised';
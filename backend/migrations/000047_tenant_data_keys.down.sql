@@ -0,0 +1,5 @@
+-- Rollback tenant data keys
+-- SSNs written under a tenant data key cannot be decrypted once its key is dropped
+
+DROP TABLE IF EXISTS tenant_ssn_rekeys;
+DROP TABLE IF EXISTS tenant_data_keys;
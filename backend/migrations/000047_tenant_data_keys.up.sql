@@ -0,0 +1,41 @@
+-- Per-tenant SSN data encryption keys, wrapped by the master key, and the runs that rekey a tenant
+
+-- ============================================================================
+-- Tenant Data Keys Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS tenant_data_keys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    wrapped_key BYTEA NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'ACTIVE',
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    retired_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_tenant_data_keys_active ON tenant_data_keys(tenant_id) WHERE status = 'ACTIVE';
+
+COMMENT ON TABLE tenant_data_keys IS 'AES-256 keys that encrypt each tenant''s SSNs; SSN ciphertexts name the key they were written under';
+COMMENT ON COLUMN tenant_data_keys.wrapped_key IS 'The data key encrypted with the master key (SSN_ENCRYPTION_KEY); never stored in the clear';
+COMMENT ON COLUMN tenant_data_keys.status IS 'ACTIVE encrypts new SSNs (one per tenant); RETIRED keys only decrypt SSNs not yet rekeyed';
+
+-- ============================================================================
+-- SSN Rekeys Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS tenant_ssn_rekeys (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    key_id UUID NOT NULL REFERENCES tenant_data_keys(id),
+    status VARCHAR(20) NOT NULL DEFAULT 'QUEUED',
+    reencrypted INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    requested_by UUID NOT NULL REFERENCES employees(id),
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX idx_tenant_ssn_rekeys_tenant_created ON tenant_ssn_rekeys(tenant_id, created_at DESC);
+CREATE UNIQUE INDEX idx_tenant_ssn_rekeys_unfinished ON tenant_ssn_rekeys(tenant_id) WHERE status IN ('QUEUED', 'RUNNING');
+
+COMMENT ON TABLE tenant_ssn_rekeys IS 'Admin-triggered runs of the ssn_rekey worker job, which re-encrypt every SSN of a tenant under key_id';
+COMMENT ON COLUMN tenant_ssn_rekeys.reencrypted IS 'SSNs rewritten; values already under key_id are left alone';
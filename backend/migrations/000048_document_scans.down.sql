@@ -0,0 +1,3 @@
+-- Rollback document scans
+
+DROP TABLE IF EXISTS document_scans;
@@ -0,0 +1,28 @@
+-- Malware scan results of uploaded documents, kept beside the documents in tenant databases
+
+-- ============================================================================
+-- Document Scans Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS document_scans (
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    document_id UUID NOT NULL, -- Document in the tenant database
+    status VARCHAR(20) NOT NULL,
+    scanner VARCHAR(50) NOT NULL,
+    threat TEXT,
+    error TEXT,
+    quarantine_path TEXT,
+    attempts INTEGER NOT NULL DEFAULT 1,
+    scanned_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, document_id),
+    CONSTRAINT chk_document_scans_status CHECK (status IN ('PENDING', 'CLEAN', 'INFECTED'))
+);
+
+CREATE INDEX idx_document_scans_pending ON document_scans(updated_at) WHERE status = 'PENDING';
+
+COMMENT ON TABLE document_scans IS 'Malware scans of documents uploaded by admins or imported from document drops; documents without a row were never scanned and stay downloadable';
+COMMENT ON COLUMN document_scans.status IS 'PENDING until the scanner gives a verdict; PENDING and INFECTED documents cannot be downloaded';
+COMMENT ON COLUMN document_scans.quarantine_path IS 'Where an infected file was moved in the tenant bucket, under the quarantine/ prefix';
+COMMENT ON COLUMN document_scans.attempts IS 'Scans tried, including the one at upload; the document_scan_retry job stops retrying after a limit';
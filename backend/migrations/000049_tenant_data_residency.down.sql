@@ -0,0 +1,6 @@
+-- Rollback tenant data residency
+
+ALTER TABLE tenant_connections DROP CONSTRAINT IF EXISTS chk_tenant_connections_residency;
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS db_region;
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS storage_region;
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS data_residency;
@@ -0,0 +1,22 @@
+-- Data residency of tenants that must keep their data in specific regions. data_residency is a
+-- label for compliance documentation (such as EU or CA); storage_region and db_region are the
+-- regions the tenant's bucket and database must be in. Uploads to a bucket outside
+-- storage_region are refused, and a Cloud SQL instance outside db_region cannot be configured.
+
+-- ============================================================================
+-- Tenant Connection Regions
+-- ============================================================================
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS data_residency VARCHAR(50);
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS storage_region VARCHAR(50);
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS db_region VARCHAR(50);
+
+-- A residency needs both regions, and the instance named for the connector must be in db_region
+ALTER TABLE tenant_connections ADD CONSTRAINT chk_tenant_connections_residency CHECK (
+    (data_residency IS NULL OR (storage_region IS NOT NULL AND db_region IS NOT NULL))
+    AND (db_region IS NULL OR db_instance_connection_name IS NULL
+         OR lower(split_part(db_instance_connection_name, ':', 2)) = lower(db_region))
+);
+
+COMMENT ON COLUMN tenant_connections.data_residency IS 'Residency requirement of the tenant''s data for compliance documentation, such as EU; NULL when none';
+COMMENT ON COLUMN tenant_connections.storage_region IS 'Region or multi-region the storage bucket must be in (us-east1, EU, eu-west-1); uploads elsewhere are refused';
+COMMENT ON COLUMN tenant_connections.db_region IS 'Region the tenant database must be in; checked against the Cloud SQL instance connection name';
@@ -0,0 +1,4 @@
+-- Rollback portal security policies
+
+DROP TABLE IF EXISTS portal_sessions;
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS portal_security_policy;
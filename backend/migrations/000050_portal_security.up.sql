@@ -0,0 +1,39 @@
+-- Per-tenant security policy for the client portal: how long portal sign-ins last, how they are
+-- verified (SSN last 4, date of birth or an emailed one-time code) and how many a user may have
+-- at once. Sign-ins are only recorded in portal_sessions while a tenant's policy needs them.
+
+-- ============================================================================
+-- Tenant Connection Policy
+-- ============================================================================
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS portal_security_policy JSONB;
+
+COMMENT ON COLUMN tenant_connections.portal_security_policy IS 'Portal session length, verification method, verification reuse window and concurrent session limit; NULL uses defaults';
+
+-- ============================================================================
+-- Portal Sessions Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS portal_sessions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    firebase_uid VARCHAR(128) NOT NULL,
+    auth_time BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    verified_at TIMESTAMP,
+    verification_method VARCHAR(20),
+    otp_hash VARCHAR(64),
+    otp_expires_at TIMESTAMP,
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    ended_at TIMESTAMP,
+    end_reason VARCHAR(30),
+    UNIQUE (tenant_id, firebase_uid, auth_time)
+);
+
+CREATE INDEX idx_portal_sessions_active ON portal_sessions(tenant_id, firebase_uid, auth_time) WHERE ended_at IS NULL;
+CREATE INDEX idx_portal_sessions_last_seen ON portal_sessions(last_seen_at);
+
+COMMENT ON TABLE portal_sessions IS 'Client portal sign-ins of tenants whose portal security policy limits or verifies them; ID tokens are matched by Firebase UID and auth_time';
+COMMENT ON COLUMN portal_sessions.auth_time IS 'auth_time claim of the sign-in, carried by every ID token refreshed from it';
+COMMENT ON COLUMN portal_sessions.verification_method IS 'Method the sign-in was verified with; the method of an earlier sign-in when verification was reused';
+COMMENT ON COLUMN portal_sessions.otp_hash IS 'SHA-256 of the one-time code last emailed for the sign-in; cleared once used';
+COMMENT ON COLUMN portal_sessions.end_reason IS 'EXPIRED, SESSION_LIMIT or VERIFICATION_FAILED';
@@ -0,0 +1,4 @@
+-- Rollback tenant archives
+
+DROP TABLE IF EXISTS archived_documents;
+DROP TABLE IF EXISTS tenant_archived_years;
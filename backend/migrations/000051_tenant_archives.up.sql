@@ -0,0 +1,35 @@
+-- End-of-season archive mode: a tenant closes a tax year, making its filings and their documents
+-- read-only, and the worker moves the year's document files to a cheaper storage class. Unarchiving
+-- the year lifts the read-only mode and moves the files back.
+
+-- ============================================================================
+-- Archived Years Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS tenant_archived_years (
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    tax_year INTEGER NOT NULL,
+    archived_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    archived_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+
+    PRIMARY KEY (tenant_id, tax_year)
+);
+
+COMMENT ON TABLE tenant_archived_years IS 'Tax years whose filings and documents are read-only; deleting the row unarchives the year';
+
+-- ============================================================================
+-- Archived Documents Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS archived_documents (
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    document_id UUID NOT NULL, -- Document in the tenant database
+    tax_year INTEGER NOT NULL,
+    file_path TEXT NOT NULL,
+    archived_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (tenant_id, document_id)
+);
+
+CREATE INDEX idx_archived_documents_year ON archived_documents(tenant_id, tax_year);
+
+COMMENT ON TABLE archived_documents IS 'Document files the archive_storage job moved to the archive storage class (GCS COLDLINE, S3 GLACIER_IR); rows of unarchived years are moved back and removed';
+COMMENT ON COLUMN archived_documents.file_path IS 'Path of the file in the tenant bucket when it was moved, so it is moved back without reading the tenant database';
@@ -0,0 +1,3 @@
+-- Rollback filing status history
+
+DROP TABLE IF EXISTS filing_status_history;
@@ -0,0 +1,27 @@
+-- Filing status workflow: every move of a filing through IN_PROGRESS, REVIEW, SIGNED, FILED and
+-- ACCEPTED or REJECTED, with who made it. The status itself stays in the tenant database.
+
+-- ============================================================================
+-- Filing Status History Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS filing_status_history (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    filing_id UUID NOT NULL, -- Filing in the tenant database
+    client_id UUID NOT NULL,
+    tax_year INTEGER NOT NULL,
+    from_status VARCHAR(50) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    note TEXT,
+    changed_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    changed_by_email VARCHAR(255) NOT NULL,
+    changed_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_filing_status_history_to CHECK (to_status IN ('IN_PROGRESS', 'REVIEW', 'SIGNED', 'FILED', 'ACCEPTED', 'REJECTED'))
+);
+
+CREATE INDEX idx_filing_status_history_filing ON filing_status_history(tenant_id, filing_id, changed_at);
+
+COMMENT ON TABLE filing_status_history IS 'Status changes made through PUT /filings/{filingId}/status, oldest first per filing';
+COMMENT ON COLUMN filing_status_history.from_status IS 'Status stored by the tax platform before the change, which may be outside the workflow';
+COMMENT ON COLUMN filing_status_history.changed_by_email IS 'Kept so the history still names the employee after they are deleted';
@@ -0,0 +1,4 @@
+-- Rollback announcements
+
+DROP TABLE IF EXISTS announcement_acknowledgments;
+DROP TABLE IF EXISTS announcements;
@@ -0,0 +1,38 @@
+-- Platform announcements: banners operators show to tenant admins, such as notice of maintenance,
+-- and which employees acknowledged them
+
+-- ============================================================================
+-- Announcements Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS announcements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    message TEXT NOT NULL,
+    severity VARCHAR(20) NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    ends_at TIMESTAMP NOT NULL,
+    tenant_ids TEXT[] NOT NULL DEFAULT '{}',
+    created_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_announcement_severity CHECK (severity IN ('INFO', 'WARNING', 'CRITICAL')),
+    CONSTRAINT chk_announcement_window CHECK (ends_at > starts_at)
+);
+
+CREATE INDEX idx_announcements_window ON announcements(ends_at, starts_at);
+
+COMMENT ON TABLE announcements IS 'Banners shown to platform admins and tenant admins between starts_at and ends_at';
+COMMENT ON COLUMN announcements.tenant_ids IS 'Tenants whose admins see the announcement; empty for every tenant';
+
+-- ============================================================================
+-- Announcement Acknowledgments Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS announcement_acknowledgments (
+    announcement_id UUID NOT NULL REFERENCES announcements(id) ON DELETE CASCADE,
+    employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    acknowledged_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    PRIMARY KEY (announcement_id, employee_id)
+);
+
+COMMENT ON TABLE announcement_acknowledgments IS 'Employees who dismissed an announcement; the first acknowledgment is kept';
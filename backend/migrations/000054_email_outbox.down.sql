@@ -0,0 +1,4 @@
+-- Rollback the outbound email queue and suppression list
+
+DROP TABLE IF EXISTS email_suppressions;
+DROP TABLE IF EXISTS email_outbox;
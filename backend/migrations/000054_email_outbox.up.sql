@@ -0,0 +1,47 @@
+-- Outbound email queue: client emails are stored and sent by a worker with retries, and
+-- addresses that bounced or reported spam are suppressed
+
+-- ============================================================================
+-- Email Outbox Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    kind VARCHAR(50) NOT NULL,
+    priority VARCHAR(20) NOT NULL DEFAULT 'transactional',
+    to_email VARCHAR(255) NOT NULL,
+    to_name VARCHAR(255),
+    subject VARCHAR(500) NOT NULL,
+    html_body TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMP DEFAULT NOW(),
+    last_error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    sent_at TIMESTAMP,
+
+    CONSTRAINT chk_email_outbox_status CHECK (status IN ('PENDING', 'SENT', 'FAILED', 'SUPPRESSED', 'BOUNCED'))
+);
+
+CREATE INDEX idx_email_outbox_created ON email_outbox(created_at DESC);
+CREATE INDEX idx_email_outbox_tenant ON email_outbox(tenant_id, created_at DESC);
+CREATE INDEX idx_email_outbox_due ON email_outbox(next_attempt_at) WHERE status = 'PENDING';
+
+COMMENT ON TABLE email_outbox IS 'Client emails waiting to be sent or already sent; pending rows are retried with exponential backoff';
+COMMENT ON COLUMN email_outbox.tenant_id IS 'Tenant the email is sent for; NULL for platform mail';
+
+-- ============================================================================
+-- Email Suppressions Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS email_suppressions (
+    email VARCHAR(255) PRIMARY KEY, -- Lowercase
+    reason VARCHAR(20) NOT NULL,
+    detail TEXT,
+    created_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_email_suppression_reason CHECK (reason IN ('BOUNCE', 'SPAM_REPORT', 'MANUAL'))
+);
+
+COMMENT ON TABLE email_suppressions IS 'Addresses queued email is not sent to: hard bounces and spam reports from SendGrid, and ones admins added';
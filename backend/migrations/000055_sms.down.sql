@@ -0,0 +1,4 @@
+-- Rollback text messages
+
+DROP TABLE IF EXISTS sms_messages;
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS sms_settings;
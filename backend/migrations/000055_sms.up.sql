@@ -0,0 +1,34 @@
+-- Text messages to clients: per-tenant SMS settings and a log of each message with the delivery
+-- status the provider reports
+
+-- ============================================================================
+-- Tenant Connection Settings
+-- ============================================================================
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS sms_settings JSONB;
+
+COMMENT ON COLUMN tenant_connections.sms_settings IS 'Whether portal links are texted to clients and how many texts a client may get per day; NULL texts nothing';
+
+-- ============================================================================
+-- SMS Messages Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS sms_messages (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    client_id UUID NOT NULL,
+    kind VARCHAR(50) NOT NULL,
+    to_phone VARCHAR(20) NOT NULL,
+    provider VARCHAR(50) NOT NULL,
+    provider_id VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    error TEXT,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT chk_sms_message_status CHECK (status IN ('PENDING', 'SENT', 'DELIVERED', 'UNDELIVERED', 'FAILED'))
+);
+
+CREATE INDEX idx_sms_messages_client ON sms_messages(tenant_id, client_id, created_at DESC);
+CREATE INDEX idx_sms_messages_tenant ON sms_messages(tenant_id, created_at DESC);
+CREATE UNIQUE INDEX idx_sms_messages_provider ON sms_messages(provider, provider_id) WHERE provider_id IS NOT NULL;
+
+COMMENT ON TABLE sms_messages IS 'Text messages sent to clients, without their bodies; status follows the provider''s delivery callbacks';
@@ -0,0 +1,6 @@
+-- Rollback in-app staff notifications and Slack pings
+
+DROP TABLE IF EXISTS employee_slack_accounts;
+DROP TABLE IF EXISTS employee_notifications;
+ALTER TABLE notification_preferences DROP COLUMN IF EXISTS slack;
+ALTER TABLE notification_preferences DROP COLUMN IF EXISTS email;
@@ -0,0 +1,41 @@
+-- In-app staff notifications, per-channel delivery preferences and Slack accounts for direct
+-- message pings
+
+-- ============================================================================
+-- Notification Preferences Channels
+-- ============================================================================
+ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS email BOOLEAN NOT NULL DEFAULT true;
+ALTER TABLE notification_preferences ADD COLUMN IF NOT EXISTS slack BOOLEAN NOT NULL DEFAULT false;
+
+COMMENT ON COLUMN notification_preferences.email IS 'Whether the category is emailed (immediately or in the digest, per mode)';
+COMMENT ON COLUMN notification_preferences.slack IS 'Whether immediate notifications of the category are sent as Slack direct messages';
+
+-- ============================================================================
+-- Employee Notifications Table (in-app inbox)
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS employee_notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    employee_id UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    tenant_id VARCHAR(100),
+    category VARCHAR(50) NOT NULL,
+    subject TEXT NOT NULL,
+    body TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    read_at TIMESTAMP
+);
+
+CREATE INDEX idx_employee_notifications_employee ON employee_notifications(employee_id, created_at DESC);
+CREATE INDEX idx_employee_notifications_unread ON employee_notifications(employee_id) WHERE read_at IS NULL;
+
+COMMENT ON TABLE employee_notifications IS 'Notifications shown in the staff console for every category an employee has not turned off';
+
+-- ============================================================================
+-- Employee Slack Accounts Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS employee_slack_accounts (
+    employee_id UUID PRIMARY KEY REFERENCES employees(id) ON DELETE CASCADE,
+    slack_user_id VARCHAR(20) NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE employee_slack_accounts IS 'Slack member ID each employee is sent direct messages at';
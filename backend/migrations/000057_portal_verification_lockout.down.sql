@@ -0,0 +1,6 @@
+-- Rollback portal verification lockout
+
+DROP TABLE IF EXISTS portal_verification_failures;
+
+ALTER TABLE tenant_users DROP COLUMN IF EXISTS verification_locked_at;
+ALTER TABLE tenant_users DROP COLUMN IF EXISTS verification_failures;
@@ -0,0 +1,28 @@
+-- Lock portal users out of sign-in verification after repeated wrong answers
+
+ALTER TABLE tenant_users ADD COLUMN IF NOT EXISTS verification_failures INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE tenant_users ADD COLUMN IF NOT EXISTS verification_locked_at TIMESTAMP;
+
+COMMENT ON COLUMN tenant_users.verification_failures IS 'Wrong verification answers since the last correct one, across every sign-in';
+COMMENT ON COLUMN tenant_users.verification_locked_at IS 'When too many wrong answers locked verification; cleared by sending the client a new portal link';
+
+-- ============================================================================
+-- Portal Verification Failures Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS portal_verification_failures (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    tenant_user_id UUID NOT NULL REFERENCES tenant_users(id) ON DELETE CASCADE,
+    client_id UUID NOT NULL, -- Client in the tenant database
+    session_id UUID REFERENCES portal_sessions(id) ON DELETE SET NULL,
+    method VARCHAR(20) NOT NULL, -- SSN_LAST4, DOB or OTP
+    failures INTEGER NOT NULL, -- The user's failure count after this attempt
+    locked BOOLEAN NOT NULL DEFAULT false, -- This attempt locked verification
+    ip_address INET,
+    user_agent TEXT,
+    failed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_portal_verification_failures_client ON portal_verification_failures(tenant_id, client_id, failed_at DESC);
+
+COMMENT ON TABLE portal_verification_failures IS 'Audit trail of wrong portal verification answers; audit_logs only records employee actions';
@@ -0,0 +1,3 @@
+-- Rollback filing status texts
+
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS filing_status_texts;
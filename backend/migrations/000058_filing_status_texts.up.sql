@@ -0,0 +1,8 @@
+-- Plain-language filing status descriptions clients see in the portal
+
+-- ============================================================================
+-- Tenant Connection Settings
+-- ============================================================================
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS filing_status_texts JSONB;
+
+COMMENT ON COLUMN tenant_connections.filing_status_texts IS 'Client-facing filing status titles, descriptions, next steps and expected days by locale and status, overriding the built-in texts; NULL uses the built-in texts';
@@ -0,0 +1,5 @@
+-- Rollback last activity
+
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS affiliate_token_policy;
+DROP INDEX IF EXISTS idx_tenant_users_last_login;
+ALTER TABLE tenant_users DROP COLUMN IF EXISTS last_login_at;
@@ -0,0 +1,18 @@
+-- Last activity: when each tenant user last signed in to the portal, and a per-tenant policy
+-- deactivating affiliate tokens that have gone unused
+
+-- ============================================================================
+-- Tenant Users
+-- ============================================================================
+ALTER TABLE tenant_users ADD COLUMN IF NOT EXISTS last_login_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_tenant_users_last_login ON tenant_users(tenant_id, last_login_at);
+
+COMMENT ON COLUMN tenant_users.last_login_at IS 'When the user last signed in to the portal; NULL if they have not since this was recorded';
+
+-- ============================================================================
+-- Tenant Connection Settings
+-- ============================================================================
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS affiliate_token_policy JSONB;
+
+COMMENT ON COLUMN tenant_connections.affiliate_token_policy IS 'After how many days unused affiliate dashboard tokens are deactivated; NULL deactivates none';
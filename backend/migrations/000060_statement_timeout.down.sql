@@ -0,0 +1,4 @@
+-- Rollback statement timeout
+
+ALTER TABLE tenant_connections DROP CONSTRAINT IF EXISTS chk_statement_timeout_ms;
+ALTER TABLE tenant_connections DROP COLUMN IF EXISTS statement_timeout_ms;
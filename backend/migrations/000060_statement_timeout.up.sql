@@ -0,0 +1,12 @@
+-- Statement timeout: how long calls to a tenant database may run before they are cancelled
+
+-- ============================================================================
+-- Tenant Connection Settings
+-- ============================================================================
+ALTER TABLE tenant_connections ADD COLUMN IF NOT EXISTS statement_timeout_ms INTEGER;
+
+ALTER TABLE tenant_connections DROP CONSTRAINT IF EXISTS chk_statement_timeout_ms;
+ALTER TABLE tenant_connections ADD CONSTRAINT chk_statement_timeout_ms
+    CHECK (statement_timeout_ms IS NULL OR statement_timeout_ms BETWEEN 1000 AND 600000);
+
+COMMENT ON COLUMN tenant_connections.statement_timeout_ms IS 'Milliseconds a call to the tenant database may run before it is cancelled; NULL uses the 30 second default';
@@ -0,0 +1,4 @@
+-- Rollback tenant deletion
+
+COMMENT ON COLUMN portal_sessions.end_reason IS 'EXPIRED, SESSION_LIMIT or VERIFICATION_FAILED';
+DROP TABLE IF EXISTS tenant_deletion_requests;
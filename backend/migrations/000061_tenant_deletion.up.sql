@@ -0,0 +1,24 @@
+-- Tenant deletion: deactivating a tenant is requested first, which summarizes its impact and
+-- issues a short-lived token, and then confirmed with that token
+
+-- ============================================================================
+-- Tenant Deletion Requests Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS tenant_deletion_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    impact JSONB NOT NULL,
+    requested_by UUID NOT NULL REFERENCES employees(id) ON DELETE CASCADE,
+    requested_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    confirmed_at TIMESTAMP
+);
+
+CREATE INDEX idx_tenant_deletion_requests_tenant ON tenant_deletion_requests(tenant_id, requested_at DESC);
+
+COMMENT ON TABLE tenant_deletion_requests IS 'Requests to deactivate a tenant; each is confirmed at most once, by its requester, before expires_at';
+COMMENT ON COLUMN tenant_deletion_requests.token_hash IS 'SHA-256 of the confirmation token; the token itself is only returned to the requester';
+COMMENT ON COLUMN tenant_deletion_requests.impact IS 'Employees, portal users and sessions, open filings and storage affected, as counted when requested';
+
+COMMENT ON COLUMN portal_sessions.end_reason IS 'EXPIRED, SESSION_LIMIT, VERIFICATION_FAILED or TENANT_DEACTIVATED';
@@ -0,0 +1,9 @@
+-- Rollback storage consistency
+
+DROP TABLE IF EXISTS storage_issues;
+DROP TABLE IF EXISTS tenant_storage_checks;
+
+COMMENT ON COLUMN document_requests.source_document_id IS 'Expiring document this request replaces; at most one request is raised per document';
+DELETE FROM document_requests WHERE reason = 'MISSING_FILE';
+ALTER TABLE document_requests DROP CONSTRAINT chk_document_requests_reason;
+ALTER TABLE document_requests ADD CONSTRAINT chk_document_requests_reason CHECK (reason IN ('EXPIRING', 'MANUAL'));
@@ -0,0 +1,61 @@
+-- Storage consistency: document records whose file is missing from the tenant bucket and files
+-- under client document paths that no record points at, found by a nightly check
+
+-- ============================================================================
+-- Tenant Storage Checks Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS tenant_storage_checks (
+    tenant_id VARCHAR(100) PRIMARY KEY REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    documents INTEGER NOT NULL DEFAULT 0,
+    objects INTEGER NOT NULL DEFAULT 0,
+    missing_files INTEGER NOT NULL DEFAULT 0,
+    orphaned_files INTEGER NOT NULL DEFAULT 0,
+    error TEXT,
+    checked_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE tenant_storage_checks IS 'Most recent comparison of each tenant''s document records with the files in its bucket';
+COMMENT ON COLUMN tenant_storage_checks.error IS 'Set when the check could not run (no bucket, listing or database failure)';
+
+-- ============================================================================
+-- Storage Issues Table
+-- ============================================================================
+CREATE TABLE IF NOT EXISTS storage_issues (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    tenant_id VARCHAR(100) NOT NULL REFERENCES tenant_connections(tenant_id) ON DELETE CASCADE,
+    type VARCHAR(20) NOT NULL,
+    path TEXT NOT NULL,
+    document_id UUID,
+    client_id UUID,
+    filing_id UUID,
+    document_name VARCHAR(255),
+    document_type VARCHAR(100),
+    candidates TEXT[] NOT NULL DEFAULT '{}',
+    status VARCHAR(20) NOT NULL DEFAULT 'OPEN',
+    first_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    last_seen_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    resolution VARCHAR(20),
+    resolved_by UUID REFERENCES employees(id) ON DELETE SET NULL,
+    resolved_at TIMESTAMP,
+    document_request_id UUID REFERENCES document_requests(id) ON DELETE SET NULL,
+
+    CONSTRAINT chk_storage_issues_type CHECK (type IN ('MISSING_FILE', 'ORPHANED_FILE')),
+    CONSTRAINT chk_storage_issues_status CHECK (status IN ('OPEN', 'RESOLVED', 'CLEARED')),
+    CONSTRAINT chk_storage_issues_resolution CHECK (resolution IS NULL OR resolution IN ('RELINK', 'DELETE', 'REREQUEST')),
+    CONSTRAINT chk_storage_issues_document CHECK (type <> 'MISSING_FILE' OR document_id IS NOT NULL)
+);
+
+CREATE INDEX idx_storage_issues_tenant ON storage_issues(tenant_id, status, first_seen_at);
+CREATE UNIQUE INDEX idx_storage_issues_open_document ON storage_issues(tenant_id, document_id) WHERE type = 'MISSING_FILE' AND status = 'OPEN';
+CREATE UNIQUE INDEX idx_storage_issues_open_path ON storage_issues(tenant_id, path) WHERE type = 'ORPHANED_FILE' AND status = 'OPEN';
+
+COMMENT ON TABLE storage_issues IS 'Missing and orphaned document files; an issue stays OPEN while checks find it, and is RESOLVED by an admin or CLEARED once no longer found';
+COMMENT ON COLUMN storage_issues.path IS 'File path of the document whose file is missing, or the orphaned file';
+COMMENT ON COLUMN storage_issues.candidates IS 'Orphaned files of the same client and document type a missing file could be relinked to';
+COMMENT ON COLUMN storage_issues.document_request_id IS 'Request raised when the client was asked to upload the document again';
+
+-- Documents whose file was lost can be requested from the client again
+ALTER TABLE document_requests DROP CONSTRAINT chk_document_requests_reason;
+ALTER TABLE document_requests ADD CONSTRAINT chk_document_requests_reason CHECK (reason IN ('EXPIRING', 'MANUAL', 'MISSING_FILE'));
+
+COMMENT ON COLUMN document_requests.source_document_id IS 'Expiring document, or document whose file was lost, this request replaces; at most one request is raised per document';
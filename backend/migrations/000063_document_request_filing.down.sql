@@ -0,0 +1,4 @@
+-- Rollback document request filing
+
+DROP INDEX IF EXISTS idx_document_requests_filing;
+ALTER TABLE document_requests DROP COLUMN IF EXISTS filing_id;
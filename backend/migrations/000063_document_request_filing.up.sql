@@ -0,0 +1,11 @@
+-- Document request filing: which filing a requested document is for, so completeness scores
+-- count each filing's own requests
+
+-- ============================================================================
+-- Document Requests Table
+-- ============================================================================
+ALTER TABLE document_requests ADD COLUMN IF NOT EXISTS filing_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_document_requests_filing ON document_requests(tenant_id, filing_id) WHERE filing_id IS NOT NULL;
+
+COMMENT ON COLUMN document_requests.filing_id IS 'Filing in the tenant database the document is for; NULL for requests about the client as a whole, such as a replacement photo ID';
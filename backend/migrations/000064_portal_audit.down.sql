@@ -0,0 +1,10 @@
+-- Rollback portal audit
+-- Fails while portal entries exist: audit_logs is append-only, so they cannot be removed
+
+DROP INDEX IF EXISTS idx_audit_tenant_user_time;
+ALTER TABLE audit_logs DROP CONSTRAINT IF EXISTS chk_audit_actor;
+ALTER TABLE audit_logs ALTER COLUMN employee_id SET NOT NULL;
+ALTER TABLE audit_logs DROP COLUMN IF EXISTS tenant_user_id;
+
+COMMENT ON TABLE audit_logs IS 'Audit trail of all employee actions for IRS compliance';
+COMMENT ON TABLE portal_verification_failures IS 'Audit trail of wrong portal verification answers; audit_logs only records employee actions';
@@ -0,0 +1,15 @@
+-- Portal audit: portal users' security events join employee actions in the audit hash chain
+
+-- ============================================================================
+-- Audit Logs Table
+-- ============================================================================
+ALTER TABLE audit_logs ALTER COLUMN employee_id DROP NOT NULL;
+-- No foreign key: audit entries are append-only, so they cannot cascade with the portal user
+ALTER TABLE audit_logs ADD COLUMN IF NOT EXISTS tenant_user_id UUID;
+ALTER TABLE audit_logs ADD CONSTRAINT chk_audit_actor CHECK (employee_id IS NOT NULL OR tenant_user_id IS NOT NULL);
+
+CREATE INDEX IF NOT EXISTS idx_audit_tenant_user_time ON audit_logs(tenant_user_id, created_at DESC) WHERE tenant_user_id IS NOT NULL;
+
+COMMENT ON TABLE audit_logs IS 'Audit trail of employee actions and portal user security events for IRS compliance';
+COMMENT ON COLUMN audit_logs.tenant_user_id IS 'Portal user who acted; set instead of employee_id for portal events such as wrong verification answers';
+COMMENT ON TABLE portal_verification_failures IS 'Wrong portal verification answers with the failure count and lock they led to; each is also in audit_logs';
@@ -0,0 +1,52 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// getAccessMatrix returns employees × tenants with roles and last activity per tenant (admin only)
+// Optional query: ?search=&tenantId=&role=&includeInactive=true&staleDays=N
+func (api *API) getAccessMatrix(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	filter := types.AccessMatrixFilter{
+		Search:          strings.TrimSpace(q.Get("search")),
+		TenantID:        q.Get("tenantId"),
+		Role:            q.Get("role"),
+		IncludeInactive: q.Get("includeInactive") == "true",
+	}
+
+	if filter.Role != "" && filter.Role != "admin" && filter.Role != "accountant" && filter.Role != "viewer" && filter.Role != "affiliate_manager" {
+		http.Error(w, "role must be admin, accountant, viewer or affiliate_manager", http.StatusBadRequest)
+		return
+	}
+
+	if staleStr := q.Get("staleDays"); staleStr != "" {
+		staleDays, err := strconv.Atoi(staleStr)
+		if err != nil || staleDays < 1 {
+			http.Error(w, "Invalid staleDays", http.StatusBadRequest)
+			return
+		}
+		filter.StaleDays = staleDays
+	}
+
+	matrix, err := api.store.GetAccessMatrix(filter)
+	if err != nil {
+		logger.Errorf("Failed to get access matrix: %v", err)
+		writeError(w, err, "Failed to fetch access matrix")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(matrix); err != nil {
+		logger.Errorf("Failed to encode access matrix response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,249 @@
+package webapi
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/report"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// createAccessReview starts an access review campaign from current tenant grants (admin only)
+func (api *API) createAccessReview(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		req.Name = fmt.Sprintf("Access review %s", time.Now().Format("2006-01-02"))
+	}
+
+	review, err := api.store.CreateAccessReview(req.Name, employee.ID)
+	if err != nil {
+		logger.Errorf("Failed to create access review: %v", err)
+		writeError(w, err, "Failed to create access review")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		logger.Errorf("Failed to encode access review response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// getAccessReviews lists access review campaigns (admin only)
+func (api *API) getAccessReviews(w http.ResponseWriter, r *http.Request) {
+	reviews, err := api.store.GetAccessReviews()
+	if err != nil {
+		logger.Errorf("Failed to get access reviews: %v", err)
+		writeError(w, err, "Failed to fetch access reviews")
+		return
+	}
+
+	if reviews == nil {
+		reviews = []*types.AccessReview{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(reviews); err != nil {
+		logger.Errorf("Failed to encode access reviews response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// getAccessReview returns a campaign with its line items (admin only)
+func (api *API) getAccessReview(w http.ResponseWriter, r *http.Request) {
+	reviewID, err := uuid.Parse(mux.Vars(r)["reviewId"])
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+
+	review, err := api.store.GetAccessReview(reviewID)
+	if err != nil {
+		logger.Errorf("Failed to get access review %s: %v", reviewID, err)
+		writeError(w, err, "Failed to fetch access review")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(review); err != nil {
+		logger.Errorf("Failed to encode access review response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// decideAccessReviewItem attests or revokes a single pending grant (admin only)
+// Revocations deactivate the employee's tenant access immediately; decided items cannot be changed
+func (api *API) decideAccessReviewItem(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	reviewID, err := uuid.Parse(vars["reviewId"])
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+	itemID, err := uuid.Parse(vars["itemId"])
+	if err != nil {
+		http.Error(w, "Invalid item ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Decision string  `json:"decision"` // ATTESTED or REVOKED
+		Comment  *string `json:"comment,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Decision != types.AccessReviewDecisionAttested && req.Decision != types.AccessReviewDecisionRevoked {
+		http.Error(w, "decision must be ATTESTED or REVOKED", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.store.DecideAccessReviewItem(reviewID, itemID, req.Decision, employee.ID, req.Comment); err != nil {
+		logger.Errorf("Failed to record access review decision: %v", err)
+		writeError(w, err, "Failed to record decision")
+		return
+	}
+
+	logger.Infof("Employee %s marked access review item %s as %s", employee.Email, itemID, req.Decision)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// completeAccessReview closes a campaign once every item has a decision (admin only)
+func (api *API) completeAccessReview(w http.ResponseWriter, r *http.Request) {
+	reviewID, err := uuid.Parse(mux.Vars(r)["reviewId"])
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.store.CompleteAccessReview(reviewID); err != nil {
+		logger.Errorf("Failed to complete access review: %v", err)
+		writeError(w, err, "Failed to complete access review")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// exportAccessReview returns the completion report for a campaign (admin only)
+// Query: ?format=csv|pdf|json (default json)
+func (api *API) exportAccessReview(w http.ResponseWriter, r *http.Request) {
+	reviewID, err := uuid.Parse(mux.Vars(r)["reviewId"])
+	if err != nil {
+		http.Error(w, "Invalid review ID", http.StatusBadRequest)
+		return
+	}
+
+	review, err := api.store.GetAccessReview(reviewID)
+	if err != nil {
+		logger.Errorf("Failed to get access review %s: %v", reviewID, err)
+		writeError(w, err, "Failed to fetch access review")
+		return
+	}
+
+	fileName := fmt.Sprintf("access-review-%s", review.CreatedAt.Format("2006-01-02"))
+
+	switch r.URL.Query().Get("format") {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, fileName))
+
+		cw := csv.NewWriter(w)
+		cw.Write([]string{"employee_email", "tenant_id", "role", "decision", "reviewed_by", "reviewed_at", "comment"})
+		for _, item := range review.Items {
+			cw.Write(accessReviewItemFields(item))
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			logger.Errorf("Failed to write access review CSV: %v", err)
+		}
+
+	case "pdf":
+		lines := []string{
+			fmt.Sprintf("Review: %s (%s)", review.Name, review.Status),
+			fmt.Sprintf("Started: %s", review.CreatedAt.Format(time.RFC3339)),
+		}
+		if review.CompletedAt != nil {
+			lines = append(lines, fmt.Sprintf("Completed: %s", review.CompletedAt.Format(time.RFC3339)))
+		}
+		lines = append(lines,
+			fmt.Sprintf("Items: %d total, %d attested, %d revoked, %d pending",
+				review.Summary.Total, review.Summary.Attested, review.Summary.Revoked, review.Summary.Pending),
+			"",
+			fmt.Sprintf("%-32s %-20s %-10s %-9s %-20s", "EMPLOYEE", "TENANT", "ROLE", "DECISION", "REVIEWED AT"),
+		)
+		for _, item := range review.Items {
+			f := accessReviewItemFields(item)
+			lines = append(lines, fmt.Sprintf("%-32.32s %-20.20s %-10s %-9s %-20.20s", f[0], f[1], f[2], f[3], f[5]))
+			if f[6] != "" {
+				lines = append(lines, "    "+f[6])
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, fileName))
+		if _, err := w.Write(report.TextPDF("Access Review Report", lines)); err != nil {
+			logger.Errorf("Failed to write access review PDF: %v", err)
+		}
+
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(review); err != nil {
+			logger.Errorf("Failed to encode access review response: %v", err)
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+			return
+		}
+	}
+}
+
+// accessReviewItemFields flattens a line item into report columns
+func accessReviewItemFields(item *types.AccessReviewItem) []string {
+	reviewedBy, reviewedAt, comment := "", "", ""
+	if item.ReviewedBy != nil {
+		reviewedBy = item.ReviewedBy.String()
+	}
+	if item.ReviewedAt != nil {
+		reviewedAt = item.ReviewedAt.Format(time.RFC3339)
+	}
+	if item.Comment != nil {
+		comment = *item.Comment
+	}
+	return []string{item.EmployeeEmail, item.TenantID, item.Role, item.Decision, reviewedBy, reviewedAt, comment}
+}
@@ -0,0 +1,96 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// validateAddress standardizes an address through the configured provider
+// When entityType and entityId are supplied the original and standardized
+// address are stored so undeliverable addresses can be reviewed later
+func (api *API) validateAddress(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	var req struct {
+		Address    types.Address `json:"address"`
+		EntityType string        `json:"entityType,omitempty"` // CLIENT or PROPERTY
+		EntityID   *uuid.UUID    `json:"entityId,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Address.Line1 == "" {
+		http.Error(w, "Address line1 is required", http.StatusBadRequest)
+		return
+	}
+	if req.EntityID != nil && req.EntityType != types.AddressEntityClient && req.EntityType != types.AddressEntityProperty {
+		http.Error(w, "entityType must be CLIENT or PROPERTY", http.StatusBadRequest)
+		return
+	}
+
+	result, err := api.addressValidator.Validate(r.Context(), req.Address)
+	if err != nil {
+		logger.Errorf("Address validation failed for tenant %s: %v", tenantID, err)
+		http.Error(w, "Address validation service unavailable", http.StatusBadGateway)
+		return
+	}
+	result.TenantID = tenantID
+
+	if req.EntityID != nil {
+		result.EntityType = req.EntityType
+		result.EntityID = req.EntityID
+		if employee, ok := middleware.GetEmployeeFromContext(r.Context()); ok {
+			result.ValidatedBy = &employee.ID
+		}
+
+		if err := api.store.SaveAddressValidation(result); err != nil {
+			logger.Errorf("Failed to save address validation: %v", err)
+			writeError(w, err, "Failed to save address validation")
+			return
+		}
+	}
+
+	if !result.Deliverable {
+		logger.Warningf("Undeliverable address flagged for tenant %s (provider=%s, footnotes=%v)", tenantID, result.Provider, result.Footnotes)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Errorf("Failed to encode address validation response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// getUndeliverableAddresses returns client and property addresses flagged as undeliverable
+func (api *API) getUndeliverableAddresses(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	validations, err := api.store.GetUndeliverableAddresses(tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get undeliverable addresses: %v", err)
+		writeError(w, err, "Failed to fetch undeliverable addresses")
+		return
+	}
+
+	if validations == nil {
+		validations = []*types.AddressValidation{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(validations); err != nil {
+		logger.Errorf("Failed to encode address validations response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,181 @@
+package webapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+const (
+	// filingCountTTL is how long cross-tenant filing counts are reused before tenants are queried again
+	filingCountTTL = 10 * time.Minute
+	// envelopeWindow and jobWindow bound the recent activity shown on the overview
+	envelopeWindow = 7 * 24 * time.Hour
+	jobWindow      = 24 * time.Hour
+	// recentEnvelopeFailures caps the failed envelopes listed on the overview
+	recentEnvelopeFailures = 10
+)
+
+// filingCountCache holds the last cross-tenant filing counts per tax year.
+// Counting queries every tenant database, so results are reused for filingCountTTL.
+type filingCountCache struct {
+	mu     sync.Mutex
+	byYear map[int]*types.OverviewFilings
+}
+
+// getAdminOverview returns the cross-tenant operations dashboard (admin only)
+// Query params: year (tax season, defaults to last calendar year), refresh=true to recount filings
+func (api *API) getAdminOverview(w http.ResponseWriter, r *http.Request) {
+	year := time.Now().Year() - 1
+	if yearStr := r.URL.Query().Get("year"); yearStr != "" {
+		parsed, err := strconv.Atoi(yearStr)
+		if err != nil {
+			http.Error(w, "Invalid year parameter", http.StatusBadRequest)
+			return
+		}
+		year = parsed
+	}
+	refresh := r.URL.Query().Get("refresh") == "true"
+
+	logger.Infof("Getting admin overview for tax year %d", year)
+
+	tenantIDs, err := api.store.GetActiveTenantIDs()
+	if err != nil {
+		writeError(w, err, "Failed to get tenants")
+		return
+	}
+
+	overview := &types.AdminOverview{}
+
+	tenants, err := api.store.GetTenantCounts()
+	if err != nil {
+		writeError(w, err, "Failed to count tenants")
+		return
+	}
+	tenants.RefreshedAt = time.Now()
+	overview.Tenants = *tenants
+
+	overview.Connections = api.connectionOverview(tenantIDs)
+	overview.Filings = api.filingOverview(r.Context(), tenantIDs, year, refresh)
+
+	since := time.Now().Add(-envelopeWindow)
+	envelopes, err := api.store.GetEnvelopeSummary(since, recentEnvelopeFailures)
+	if err != nil {
+		writeError(w, err, "Failed to get signature envelopes")
+		return
+	}
+	envelopes.RefreshedAt = time.Now()
+	overview.Envelopes = *envelopes
+
+	since = time.Now().Add(-jobWindow)
+	jobs, err := api.store.GetJobSummaries(since)
+	if err != nil {
+		writeError(w, err, "Failed to get job history")
+		return
+	}
+	backlog, err := api.store.GetJobBacklog()
+	if err != nil {
+		writeError(w, err, "Failed to get job backlog")
+		return
+	}
+	if jobs == nil {
+		jobs = []*types.JobSummary{}
+	}
+	overview.Jobs = types.OverviewJobs{
+		Since:       since,
+		Jobs:        jobs,
+		Backlog:     *backlog,
+		RefreshedAt: time.Now(),
+	}
+
+	locks, err := api.store.GetDistributedLocks(types.LockStuckAfter)
+	if err != nil {
+		writeError(w, err, "Failed to get distributed locks")
+		return
+	}
+	overview.Locks = types.OverviewLocks{Locks: locks, RefreshedAt: time.Now()}
+	for _, lock := range locks {
+		if lock.Stuck {
+			overview.Locks.Stuck++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(overview); err != nil {
+		logger.Errorf("Failed to encode admin overview response: %v", err)
+	}
+}
+
+// connectionOverview summarizes the cached connection health of the given tenants
+func (api *API) connectionOverview(tenantIDs []string) types.OverviewConnections {
+	section := types.OverviewConnections{FailingTenants: []*types.TenantConnectionHealth{}}
+
+	for _, tenantID := range tenantIDs {
+		health, ok := api.store.GetConnectionHealth(tenantID)
+		if !ok {
+			section.Unchecked++
+			continue
+		}
+
+		if section.RefreshedAt == nil || health.CheckedAt.Before(*section.RefreshedAt) {
+			checkedAt := health.CheckedAt
+			section.RefreshedAt = &checkedAt
+		}
+
+		if health.Healthy {
+			section.Healthy++
+		} else {
+			section.Failing++
+			section.FailingTenants = append(section.FailingTenants, health)
+		}
+	}
+
+	return section
+}
+
+// filingOverview counts filings for a tax year across tenants, reusing recent counts unless refresh is set
+func (api *API) filingOverview(ctx context.Context, tenantIDs []string, year int, refresh bool) types.OverviewFilings {
+	api.filingCounts.mu.Lock()
+	defer api.filingCounts.mu.Unlock()
+
+	if cached, ok := api.filingCounts.byYear[year]; ok && !refresh && time.Since(cached.RefreshedAt) < filingCountTTL {
+		return *cached
+	}
+
+	section := &types.OverviewFilings{Year: year, ByTenant: []*types.TenantFilingCount{}}
+	for _, tenantID := range tenantIDs {
+		count := &types.TenantFilingCount{TenantID: tenantID}
+		section.ByTenant = append(section.ByTenant, count)
+
+		// Skip databases known to be down rather than waiting on their connection timeouts
+		if health, ok := api.store.GetConnectionHealth(tenantID); ok && !health.Healthy {
+			count.Error = "connection failing"
+			continue
+		}
+
+		total, completed, err := api.store.CountFilings(ctx, tenantID, year)
+		if err != nil {
+			logger.Errorf("Failed to count filings for tenant %s: %v", tenantID, err)
+			count.Error = "failed to count filings"
+			continue
+		}
+		count.Total = total
+		count.Completed = completed
+		section.Total += total
+		section.Completed += completed
+	}
+	section.RefreshedAt = time.Now()
+
+	if api.filingCounts.byYear == nil {
+		api.filingCounts.byYear = map[int]*types.OverviewFilings{}
+	}
+	api.filingCounts.byYear[year] = section
+
+	return *section
+}
@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"strconv"
 	"time"
+	"welltaxpro/src/internal/middleware"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
@@ -13,21 +14,37 @@ import (
 	"github.com/gorilla/mux"
 )
 
-// getAffiliates returns all affiliates for a tenant (admin only)
+// getAffiliates returns all affiliates for a tenant with when they last used the dashboard (admin only)
+// Query params: active=true for active affiliates only, dormantDays=N for affiliates who have not
+// used the dashboard for N days (counted from creation for affiliates who never did)
 func (api *API) getAffiliates(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	tenantID := vars["tenantId"]
 
 	activeOnly := r.URL.Query().Get("active") == "true"
+	since, msg := dormantSince(r)
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
 
 	logger.Infof("Fetching affiliates for tenant: %s", tenantID)
 
-	affiliates, err := api.store.GetAffiliates(tenantID, activeOnly)
+	affiliates, err := api.store.GetAffiliates(r.Context(), tenantID, activeOnly)
 	if err != nil {
 		logger.Errorf("Failed to get affiliates: %v", err)
-		http.Error(w, "Failed to fetch affiliates", http.StatusInternalServerError)
+		writeError(w, err, "Failed to fetch affiliates")
 		return
 	}
+	if since != nil {
+		dormant := []*types.Affiliate{}
+		for _, affiliate := range affiliates {
+			if affiliate.IsDormant(*since) {
+				dormant = append(dormant, affiliate)
+			}
+		}
+		affiliates = dormant
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(affiliates); err != nil {
@@ -45,10 +62,10 @@ func (api *API) getAffiliate(w http.ResponseWriter, r *http.Request) {
 
 	logger.Infof("Fetching affiliate %s for tenant %s", affiliateID, tenantID)
 
-	affiliate, err := api.store.GetAffiliateByID(tenantID, affiliateID)
+	affiliate, err := api.store.GetAffiliateByID(r.Context(), tenantID, affiliateID)
 	if err != nil {
 		logger.Errorf("Failed to get affiliate: %v", err)
-		http.Error(w, "Affiliate not found", http.StatusNotFound)
+		writeError(w, err, "Failed to fetch affiliate")
 		return
 	}
 
@@ -78,17 +95,17 @@ func (api *API) createAffiliate(w http.ResponseWriter, r *http.Request) {
 		input.PayoutMethod = types.PayoutMethodManual
 	}
 	if input.PayoutThreshold == 0 {
-		input.PayoutThreshold = 100.00
+		input.PayoutThreshold = types.Cents(100_00) // $100.00
 	}
 	if input.DefaultCommissionRate == 0 {
-		input.DefaultCommissionRate = 15.00
+		input.DefaultCommissionRate = types.Rate(15_00) // 15%
 	}
 	input.IsActive = true
 
-	affiliate, err := api.store.CreateAffiliate(tenantID, &input)
+	affiliate, err := api.store.CreateAffiliate(r.Context(), tenantID, &input)
 	if err != nil {
 		logger.Errorf("Failed to create affiliate: %v", err)
-		http.Error(w, "Failed to create affiliate", http.StatusInternalServerError)
+		writeError(w, err, "Failed to create affiliate")
 		return
 	}
 
@@ -115,10 +132,47 @@ func (api *API) updateAffiliate(w http.ResponseWriter, r *http.Request) {
 
 	logger.Infof("Updating affiliate %s for tenant %s", affiliateID, tenantID)
 
-	affiliate, err := api.store.UpdateAffiliate(tenantID, affiliateID, &input)
+	affiliate, err := api.store.UpdateAffiliate(r.Context(), tenantID, affiliateID, &input)
 	if err != nil {
 		logger.Errorf("Failed to update affiliate: %v", err)
-		http.Error(w, "Failed to update affiliate", http.StatusInternalServerError)
+		writeError(w, err, "Failed to update affiliate")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(affiliate); err != nil {
+		logger.Errorf("Failed to encode affiliate response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// patchAffiliate changes only the affiliate fields present in the request body (admin only)
+func (api *API) patchAffiliate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	affiliateID := vars["affiliateId"]
+
+	var patch types.AffiliatePatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if patch.IsEmpty() {
+		http.Error(w, "No fields to update", http.StatusBadRequest)
+		return
+	}
+	if msg := patch.Validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Patching affiliate %s for tenant %s", affiliateID, tenantID)
+
+	affiliate, err := api.store.PatchAffiliate(r.Context(), tenantID, affiliateID, &patch)
+	if err != nil {
+		logger.Errorf("Failed to patch affiliate: %v", err)
+		writeError(w, err, "Failed to update affiliate")
 		return
 	}
 
@@ -155,10 +209,10 @@ func (api *API) generateAffiliateToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	plainToken, token, err := api.store.GenerateAffiliateToken(tenantID, affiliateUUID, input.ExpiresAt, input.Notes)
+	plainToken, token, err := api.store.GenerateAffiliateToken(r.Context(), tenantID, affiliateUUID, input.ExpiresAt, input.Notes)
 	if err != nil {
 		logger.Errorf("Failed to generate token: %v", err)
-		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		writeError(w, err, "Failed to generate token")
 		return
 	}
 
@@ -194,10 +248,10 @@ func (api *API) getAffiliateTokens(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	tokens, err := api.store.GetAffiliateTokens(tenantID, affiliateUUID, activeOnly)
+	tokens, err := api.store.GetAffiliateTokens(r.Context(), tenantID, affiliateUUID, activeOnly)
 	if err != nil {
 		logger.Errorf("Failed to get tokens: %v", err)
-		http.Error(w, "Failed to fetch tokens", http.StatusInternalServerError)
+		writeError(w, err, "Failed to fetch tokens")
 		return
 	}
 
@@ -223,9 +277,9 @@ func (api *API) revokeAffiliateToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := api.store.RevokeAffiliateToken(tenantID, tokenUUID); err != nil {
+	if err := api.store.RevokeAffiliateToken(r.Context(), tenantID, tokenUUID); err != nil {
 		logger.Errorf("Failed to revoke token: %v", err)
-		http.Error(w, "Failed to revoke token", http.StatusInternalServerError)
+		writeError(w, err, "Failed to revoke token")
 		return
 	}
 
@@ -233,6 +287,7 @@ func (api *API) revokeAffiliateToken(w http.ResponseWriter, r *http.Request) {
 }
 
 // getCommissions returns commissions with optional filters (admin only)
+// Query params: affiliateId, status, tag, limit, cursor (returns one page when present)
 func (api *API) getCommissions(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	tenantID := vars["tenantId"]
@@ -241,6 +296,16 @@ func (api *API) getCommissions(w http.ResponseWriter, r *http.Request) {
 	status := r.URL.Query().Get("status")
 	limitStr := r.URL.Query().Get("limit")
 
+	var tagPtr *string
+	if tag := r.URL.Query().Get("tag"); tag != "" {
+		normalized, ok := types.NormalizeCommissionTag(tag)
+		if !ok {
+			http.Error(w, "Invalid tag", http.StatusBadRequest)
+			return
+		}
+		tagPtr = &normalized
+	}
+
 	limit := 100 // default
 	if limitStr != "" {
 		if parsed, err := strconv.Atoi(limitStr); err == nil {
@@ -262,10 +327,39 @@ func (api *API) getCommissions(w http.ResponseWriter, r *http.Request) {
 		statusPtr = &status
 	}
 
-	commissions, err := api.store.GetCommissionsByAffiliate(tenantID, affiliateIDPtr, statusPtr, limit)
+	page, paged, err := parsePageRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if paged {
+		commissionPage, err := api.store.GetCommissionPage(r.Context(), tenantID, affiliateIDPtr, statusPtr, tagPtr, page)
+		if err != nil {
+			logger.Errorf("Failed to get commissions: %v", err)
+			writeError(w, err, "Failed to fetch commissions")
+			return
+		}
+		if err := api.store.AttachCommissionTags(tenantID, commissionPage.Items); err != nil {
+			writeError(w, err, "Failed to fetch commission tags")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(commissionPage); err != nil {
+			logger.Errorf("Failed to encode commissions response: %v", err)
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	commissions, err := api.store.GetCommissionsByAffiliate(r.Context(), tenantID, affiliateIDPtr, statusPtr, tagPtr, limit)
 	if err != nil {
 		logger.Errorf("Failed to get commissions: %v", err)
-		http.Error(w, "Failed to fetch commissions", http.StatusInternalServerError)
+		writeError(w, err, "Failed to fetch commissions")
+		return
+	}
+	if err := api.store.AttachCommissionTags(tenantID, commissions); err != nil {
+		writeError(w, err, "Failed to fetch commission tags")
 		return
 	}
 
@@ -285,10 +379,10 @@ func (api *API) approveCommission(w http.ResponseWriter, r *http.Request) {
 
 	logger.Infof("Approving commission %s in tenant %s", commissionID, tenantID)
 
-	commission, err := api.store.ApproveCommission(tenantID, commissionID)
+	commission, err := api.store.ApproveCommission(r.Context(), tenantID, commissionID)
 	if err != nil {
 		logger.Errorf("Failed to approve commission: %v", err)
-		http.Error(w, "Failed to approve commission", http.StatusInternalServerError)
+		writeError(w, err, "Failed to approve commission")
 		return
 	}
 
@@ -300,18 +394,137 @@ func (api *API) approveCommission(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// markCommissionPaid marks an approved commission as paid (admin only)
+// bulkApproveCommissions approves the listed commissionIds, or the commissions matching filter,
+// in one transaction and returns a per-commission report (admin only)
+// The filter's status defaults to PENDING; from and to are inclusive creation dates (YYYY-MM-DD)
+func (api *API) bulkApproveCommissions(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	var input struct {
+		CommissionIDs []string `json:"commissionIds"`
+		Filter        *struct {
+			Status      *string `json:"status"`
+			AffiliateID *string `json:"affiliateId"`
+			From        *string `json:"from"`
+			To          *string `json:"to"`
+		} `json:"filter"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if (len(input.CommissionIDs) > 0) == (input.Filter != nil) {
+		http.Error(w, "Provide either commissionIds or filter", http.StatusBadRequest)
+		return
+	}
+
+	var commissionIDs []string
+	var filter *types.CommissionFilter
+	if input.Filter == nil {
+		if len(input.CommissionIDs) > types.MaxBulkCommissions {
+			http.Error(w, fmt.Sprintf("At most %d commissionIds may be approved at once", types.MaxBulkCommissions), http.StatusBadRequest)
+			return
+		}
+		seen := map[string]bool{}
+		for _, v := range input.CommissionIDs {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid commission ID: %s", v), http.StatusBadRequest)
+				return
+			}
+			if !seen[id.String()] {
+				seen[id.String()] = true
+				commissionIDs = append(commissionIDs, id.String())
+			}
+		}
+	} else {
+		filter = &types.CommissionFilter{Status: input.Filter.Status}
+		if filter.Status != nil && *filter.Status != types.CommissionStatusPending && *filter.Status != types.CommissionStatusReview {
+			http.Error(w, "filter.status must be PENDING or REVIEW", http.StatusBadRequest)
+			return
+		}
+		if v := input.Filter.AffiliateID; v != nil {
+			id, err := uuid.Parse(*v)
+			if err != nil {
+				http.Error(w, "Invalid affiliate ID", http.StatusBadRequest)
+				return
+			}
+			affiliateID := id.String()
+			filter.AffiliateID = &affiliateID
+		}
+		if v := input.Filter.From; v != nil {
+			from, err := time.Parse("2006-01-02", *v)
+			if err != nil {
+				http.Error(w, "filter.from must be YYYY-MM-DD", http.StatusBadRequest)
+				return
+			}
+			filter.CreatedFrom = &from
+		}
+		if v := input.Filter.To; v != nil {
+			to, err := time.Parse("2006-01-02", *v)
+			if err != nil {
+				http.Error(w, "filter.to must be YYYY-MM-DD", http.StatusBadRequest)
+				return
+			}
+			to = to.AddDate(0, 0, 1)
+			filter.CreatedTo = &to
+		}
+		if filter.CreatedFrom != nil && filter.CreatedTo != nil && !filter.CreatedFrom.Before(*filter.CreatedTo) {
+			http.Error(w, "filter.from must not be after filter.to", http.StatusBadRequest)
+			return
+		}
+	}
+
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	logger.Infof("%s bulk approving commissions in tenant %s", employee.Email, tenantID)
+
+	report, err := api.store.ApproveCommissions(r.Context(), tenantID, commissionIDs, filter)
+	if err != nil {
+		logger.Errorf("Failed to bulk approve commissions: %v", err)
+		writeError(w, err, "Failed to approve commissions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.Errorf("Failed to encode bulk approval response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// markCommissionPaid records a manual payment of an approved commission that is not in a payout
+// batch (admin only). The payment is kept on a payout of its own, returned in the commission's
+// payout; a partial payment leaves the commission APPROVED until the payout is paid in full.
 func (api *API) markCommissionPaid(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	vars := mux.Vars(r)
 	tenantID := vars["tenantId"]
 	commissionID := vars["commissionId"]
 
-	logger.Infof("Marking commission %s as paid in tenant %s", commissionID, tenantID)
+	payment, ok := decodeManualPayment(w, r, employee.Email)
+	if !ok {
+		return
+	}
+
+	logger.Infof("Recording %s payment of commission %s in tenant %s", payment.Method, commissionID, tenantID)
 
-	commission, err := api.store.MarkCommissionPaid(tenantID, commissionID)
+	commission, err := api.store.MarkCommissionPaid(r.Context(), tenantID, commissionID, payment)
 	if err != nil {
 		logger.Errorf("Failed to mark commission as paid: %v", err)
-		http.Error(w, "Failed to mark commission as paid", http.StatusInternalServerError)
+		writeError(w, err, "Failed to mark commission as paid")
 		return
 	}
 
@@ -324,7 +537,15 @@ func (api *API) markCommissionPaid(w http.ResponseWriter, r *http.Request) {
 }
 
 // cancelCommission cancels a commission with a reason (admin only)
+// The reason is appended to the commission's notes rather than replacing them
 func (api *API) cancelCommission(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	vars := mux.Vars(r)
 	tenantID := vars["tenantId"]
 	commissionID := vars["commissionId"]
@@ -346,10 +567,10 @@ func (api *API) cancelCommission(w http.ResponseWriter, r *http.Request) {
 
 	logger.Infof("Cancelling commission %s in tenant %s with reason: %s", commissionID, tenantID, req.Reason)
 
-	commission, err := api.store.CancelCommission(tenantID, commissionID, req.Reason)
+	commission, err := api.store.CancelCommission(r.Context(), tenantID, commissionID, req.Reason, employee.ID)
 	if err != nil {
 		logger.Errorf("Failed to cancel commission: %v", err)
-		http.Error(w, "Failed to cancel commission", http.StatusInternalServerError)
+		writeError(w, err, "Failed to cancel commission")
 		return
 	}
 
@@ -1,23 +1,30 @@
 package webapi
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"strconv"
+	"time"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
+// affiliateDashboardPayments is how many recent payments the affiliate dashboard lists
+const affiliateDashboardPayments = 20
+
 // validateAffiliateToken validates the token and verifies it matches the affiliate ID
-func (api *API) validateAffiliateToken(tenantID, affiliateID, token string) (bool, error) {
+func (api *API) validateAffiliateToken(ctx context.Context, tenantID, affiliateID, token string) (bool, error) {
 	if token == "" {
 		return false, nil
 	}
 
 	// Validate token and get affiliate ID
-	tokenAffiliateID, err := api.store.ValidateAffiliateToken(tenantID, token)
+	tokenAffiliateID, err := api.store.ValidateAffiliateToken(ctx, tenantID, token)
 	if err != nil {
 		return false, err
 	}
@@ -41,7 +48,7 @@ func (api *API) getAffiliateDashboard(w http.ResponseWriter, r *http.Request) {
 	logger.Infof("Fetching affiliate dashboard for %s in tenant %s", affiliateID, tenantID)
 
 	// Validate token
-	valid, err := api.validateAffiliateToken(tenantID, affiliateID, token)
+	valid, err := api.validateAffiliateToken(r.Context(), tenantID, affiliateID, token)
 	if err != nil {
 		logger.Errorf("Failed to validate token: %v", err)
 		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
@@ -53,34 +60,68 @@ func (api *API) getAffiliateDashboard(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Get affiliate info
-	affiliate, err := api.store.GetAffiliateByID(tenantID, affiliateID)
+	affiliate, err := api.store.GetAffiliateByID(r.Context(), tenantID, affiliateID)
 	if err != nil {
 		logger.Errorf("Failed to get affiliate: %v", err)
-		http.Error(w, "Affiliate not found", http.StatusNotFound)
+		writeError(w, err, "Failed to fetch affiliate")
 		return
 	}
 
 	// Get affiliate stats
-	stats, err := api.store.GetAffiliateStats(tenantID, affiliateID)
+	stats, err := api.store.GetAffiliateStats(r.Context(), tenantID, affiliateID)
 	if err != nil {
 		logger.Errorf("Failed to get affiliate stats: %v", err)
-		http.Error(w, "Failed to fetch stats", http.StatusInternalServerError)
+		writeError(w, err, "Failed to fetch stats")
 		return
 	}
 
 	// Get recent commissions (last 20)
-	commissions, err := api.store.GetCommissionsByAffiliate(tenantID, &affiliateID, nil, 20)
+	commissions, err := api.store.GetCommissionsByAffiliate(r.Context(), tenantID, &affiliateID, nil, nil, 20)
 	if err != nil {
 		logger.Errorf("Failed to get commissions: %v", err)
-		http.Error(w, "Failed to fetch commissions", http.StatusInternalServerError)
+		writeError(w, err, "Failed to fetch commissions")
+		return
+	}
+
+	// Section 7216: only show customers who consented to disclosure to their affiliate
+	api.redactUndisclosedCustomers(tenantID, commissions)
+
+	// Get recent notifications (last 10)
+	notifications, unread, err := api.store.GetAffiliateNotifications(tenantID, affiliate.ID, 10)
+	if err != nil {
+		logger.Errorf("Failed to get notifications: %v", err)
+		writeError(w, err, "Failed to fetch notifications")
+		return
+	}
+
+	// Get recent payments, manual and Stripe
+	payments, err := api.store.GetAffiliatePayments(r.Context(), tenantID, affiliateID, affiliateDashboardPayments)
+	if err != nil {
+		logger.Errorf("Failed to get payments: %v", err)
+		writeError(w, err, "Failed to fetch payments")
+		return
+	}
+	for _, p := range payments {
+		p.PaidBy = ""
+	}
+
+	// What the affiliate has been paid this year, toward their 1099
+	statement, err := api.store.GetAffiliateStatement(r.Context(), tenantID, affiliateID, time.Now().Year())
+	if err != nil {
+		logger.Errorf("Failed to get affiliate statement: %v", err)
+		writeError(w, err, "Failed to fetch payments")
 		return
 	}
 
 	// Build dashboard response
 	dashboard := map[string]interface{}{
-		"affiliate":   affiliate,
-		"stats":       stats,
-		"commissions": commissions,
+		"affiliate":           affiliate,
+		"stats":               stats,
+		"commissions":         commissions,
+		"payments":            payments,
+		"paidThisYear":        statement.Total,
+		"notifications":       notifications,
+		"unreadNotifications": unread,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -101,7 +142,7 @@ func (api *API) getAffiliateStatsPublic(w http.ResponseWriter, r *http.Request)
 	logger.Infof("Fetching affiliate stats for %s in tenant %s", affiliateID, tenantID)
 
 	// Validate token
-	valid, err := api.validateAffiliateToken(tenantID, affiliateID, token)
+	valid, err := api.validateAffiliateToken(r.Context(), tenantID, affiliateID, token)
 	if err != nil {
 		logger.Errorf("Failed to validate token: %v", err)
 		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
@@ -113,10 +154,10 @@ func (api *API) getAffiliateStatsPublic(w http.ResponseWriter, r *http.Request)
 	}
 
 	// Get affiliate stats
-	stats, err := api.store.GetAffiliateStats(tenantID, affiliateID)
+	stats, err := api.store.GetAffiliateStats(r.Context(), tenantID, affiliateID)
 	if err != nil {
 		logger.Errorf("Failed to get affiliate stats: %v", err)
-		http.Error(w, "Failed to fetch stats", http.StatusInternalServerError)
+		writeError(w, err, "Failed to fetch stats")
 		return
 	}
 
@@ -148,7 +189,7 @@ func (api *API) getAffiliateCommissionsPublic(w http.ResponseWriter, r *http.Req
 	logger.Infof("Fetching affiliate commissions for %s in tenant %s", affiliateID, tenantID)
 
 	// Validate token
-	valid, err := api.validateAffiliateToken(tenantID, affiliateID, token)
+	valid, err := api.validateAffiliateToken(r.Context(), tenantID, affiliateID, token)
 	if err != nil {
 		logger.Errorf("Failed to validate token: %v", err)
 		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
@@ -165,13 +206,16 @@ func (api *API) getAffiliateCommissionsPublic(w http.ResponseWriter, r *http.Req
 	}
 
 	// Get commissions
-	commissions, err := api.store.GetCommissionsByAffiliate(tenantID, &affiliateID, statusPtr, limit)
+	commissions, err := api.store.GetCommissionsByAffiliate(r.Context(), tenantID, &affiliateID, statusPtr, nil, limit)
 	if err != nil {
 		logger.Errorf("Failed to get commissions: %v", err)
-		http.Error(w, "Failed to fetch commissions", http.StatusInternalServerError)
+		writeError(w, err, "Failed to fetch commissions")
 		return
 	}
 
+	// Section 7216: only show customers who consented to disclosure to their affiliate
+	api.redactUndisclosedCustomers(tenantID, commissions)
+
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(commissions); err != nil {
 		logger.Errorf("Failed to encode commissions response: %v", err)
@@ -179,3 +223,194 @@ func (api *API) getAffiliateCommissionsPublic(w http.ResponseWriter, r *http.Req
 		return
 	}
 }
+
+// trackAffiliateClick records a visit through an affiliate's tracking link (public)
+// Requests from official snippets are signed; see SignatureMiddleware for when signatures are required
+func (api *API) trackAffiliateClick(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	affiliateID, err := uuid.Parse(vars["affiliateId"])
+	if err != nil {
+		http.Error(w, "Invalid affiliate ID", http.StatusBadRequest)
+		return
+	}
+
+	var click types.AffiliateClick
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&click); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	affiliate, err := api.store.GetAffiliateByID(r.Context(), tenantID, affiliateID.String())
+	if err != nil || !affiliate.IsActive {
+		http.Error(w, "Affiliate not found", http.StatusNotFound)
+		return
+	}
+
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	click.AffiliateID = affiliateID
+	click.IPAddress = &ipAddress
+	click.UserAgent = &userAgent
+	_, click.Signed = middleware.GetSigningKeyFromContext(r.Context())
+	click.FillUTMFromLandingURL()
+
+	if err := api.store.RecordAffiliateClick(r.Context(), tenantID, &click); err != nil {
+		logger.Errorf("Failed to record click for affiliate %s: %v", affiliateID, err)
+		writeError(w, err, "Failed to record click")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// authorizeAffiliate validates the request's affiliate token, writing the error response if it
+// is missing or does not belong to the affiliate in the URL
+func (api *API) authorizeAffiliate(w http.ResponseWriter, r *http.Request) (string, uuid.UUID, bool) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	affiliateID := vars["affiliateId"]
+
+	valid, err := api.validateAffiliateToken(r.Context(), tenantID, affiliateID, r.URL.Query().Get("token"))
+	if err != nil {
+		logger.Errorf("Failed to validate token: %v", err)
+	}
+	if err != nil || !valid {
+		http.Error(w, "Invalid or expired token", http.StatusUnauthorized)
+		return "", uuid.Nil, false
+	}
+
+	return tenantID, uuid.MustParse(affiliateID), true
+}
+
+// getAffiliateStatementPublic returns an affiliate's payments of a calendar year with their 1099
+// total (token-based, public)
+// Query params: year (defaults to the current year)
+func (api *API) getAffiliateStatementPublic(w http.ResponseWriter, r *http.Request) {
+	tenantID, affiliateID, ok := api.authorizeAffiliate(w, r)
+	if !ok {
+		return
+	}
+
+	year, ok := paymentYear(w, r, time.Now().Year())
+	if !ok {
+		return
+	}
+
+	statement, err := api.store.GetAffiliateStatement(r.Context(), tenantID, affiliateID.String(), year)
+	if err != nil {
+		writeError(w, err, "Failed to fetch statement")
+		return
+	}
+	for _, p := range statement.Payments {
+		p.PaidBy = ""
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statement)
+}
+
+// getAffiliateNotifications returns an affiliate's latest commission notifications and unread
+// count (token-based, public)
+func (api *API) getAffiliateNotifications(w http.ResponseWriter, r *http.Request) {
+	tenantID, affiliateID, ok := api.authorizeAffiliate(w, r)
+	if !ok {
+		return
+	}
+
+	limit := 50 // default
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 200 {
+			http.Error(w, "limit must be between 1 and 200", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	notifications, unread, err := api.store.GetAffiliateNotifications(tenantID, affiliateID, limit)
+	if err != nil {
+		writeError(w, err, "Failed to fetch notifications")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"notifications": notifications,
+		"unread":        unread,
+	})
+}
+
+// markAffiliateNotificationsRead marks all of an affiliate's notifications read (token-based, public)
+func (api *API) markAffiliateNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	tenantID, affiliateID, ok := api.authorizeAffiliate(w, r)
+	if !ok {
+		return
+	}
+
+	marked, err := api.store.MarkAffiliateNotificationsRead(tenantID, affiliateID)
+	if err != nil {
+		writeError(w, err, "Failed to mark notifications read")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"marked": marked,
+	})
+}
+
+// getAffiliateNotificationPreferences returns which commission events an affiliate is emailed
+// about (token-based, public)
+func (api *API) getAffiliateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	tenantID, affiliateID, ok := api.authorizeAffiliate(w, r)
+	if !ok {
+		return
+	}
+
+	prefs, err := api.store.GetAffiliateNotificationPreferences(tenantID, affiliateID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch notification preferences")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prefs)
+}
+
+// updateAffiliateNotificationPreferences sets which commission events an affiliate is emailed
+// about; events left out keep their setting (token-based, public)
+func (api *API) updateAffiliateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	tenantID, affiliateID, ok := api.authorizeAffiliate(w, r)
+	if !ok {
+		return
+	}
+
+	var prefs []*types.AffiliateNotificationPreference
+	if err := json.NewDecoder(r.Body).Decode(&prefs); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	for _, p := range prefs {
+		if p == nil || !types.IsValidAffiliateNotificationEvent(p.Event) {
+			http.Error(w, "Unknown notification event", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := api.store.SetAffiliateNotificationPreferences(tenantID, affiliateID, prefs); err != nil {
+		writeError(w, err, "Failed to save notification preferences")
+		return
+	}
+
+	saved, err := api.store.GetAffiliateNotificationPreferences(tenantID, affiliateID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch notification preferences")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
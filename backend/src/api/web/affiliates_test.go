@@ -0,0 +1,106 @@
+package webapi
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"welltaxpro/src/internal/types"
+)
+
+func TestPatchAffiliate(t *testing.T) {
+	phone := "555-0100"
+	original := types.Affiliate{
+		FirstName:             "Ada",
+		LastName:              "Byron",
+		Email:                 "ada@example.com",
+		Phone:                 &phone,
+		DefaultCommissionRate: types.Rate(15_00),
+		PayoutMethod:          types.PayoutMethodStripe,
+		PayoutThreshold:       types.Cents(50_00),
+		IsActive:              true,
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		change     func(a *types.Affiliate) // applied to the stored affiliate to get the expected one
+	}{
+		{
+			name:       "one field",
+			body:       `{"firstName":"Augusta"}`,
+			wantStatus: http.StatusOK,
+			change:     func(a *types.Affiliate) { a.FirstName = "Augusta" },
+		},
+		{
+			name:       "rate only",
+			body:       `{"defaultCommissionRate":"12.5"}`,
+			wantStatus: http.StatusOK,
+			change:     func(a *types.Affiliate) { a.DefaultCommissionRate = types.Rate(12_50) },
+		},
+		{
+			name:       "deactivate",
+			body:       `{"isActive":false}`,
+			wantStatus: http.StatusOK,
+			change:     func(a *types.Affiliate) { a.IsActive = false },
+		},
+		{
+			name:       "null is the same as omitted",
+			body:       `{"lastName":"King","phone":null,"defaultCommissionRate":null,"isActive":null}`,
+			wantStatus: http.StatusOK,
+			change:     func(a *types.Affiliate) { a.LastName = "King" },
+		},
+		{
+			name:       "empty phone clears it",
+			body:       `{"phone":""}`,
+			wantStatus: http.StatusOK,
+			change:     func(a *types.Affiliate) { a.Phone = nil },
+		},
+		{
+			name:       "only nulls",
+			body:       `{"phone":null,"isActive":null}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "no fields",
+			body:       `{}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid rate",
+			body:       `{"defaultCommissionRate":101}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			_, admin := env.signIn(types.RoleAdmin)
+			stored := original
+			affiliate := env.store.AddAffiliate(testTenantID, &stored)
+
+			rec := env.do(t, http.MethodPatch, "/api/v1/"+testTenantID+"/affiliates/"+affiliate.ID.String(), admin, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+
+			got, err := env.store.GetAffiliateByID(context.Background(), testTenantID, affiliate.ID.String())
+			if err != nil {
+				t.Fatalf("GetAffiliateByID: %v", err)
+			}
+			want := *affiliate
+			if tt.change != nil {
+				tt.change(&want)
+				if got.UpdatedAt == nil {
+					t.Error("UpdatedAt was not set")
+				}
+				want.UpdatedAt = got.UpdatedAt
+			}
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("affiliate = %+v, want %+v", *got, want)
+			}
+		})
+	}
+}
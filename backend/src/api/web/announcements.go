@@ -0,0 +1,174 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// getAnnouncements lists announcements with their acknowledgment counts (admin only)
+// Optional query: ?includeEnded=true
+func (api *API) getAnnouncements(w http.ResponseWriter, r *http.Request) {
+	announcements, err := api.store.GetAnnouncements(r.URL.Query().Get("includeEnded") == "true")
+	if err != nil {
+		writeError(w, err, "Failed to fetch announcements")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(announcements); err != nil {
+		logger.Errorf("Failed to encode announcements response: %v", err)
+	}
+}
+
+// createAnnouncement schedules a banner for the admins of some or all tenants (admin only)
+func (api *API) createAnnouncement(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input types.Announcement
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := input.Validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	input.CreatedBy = &employee.ID
+
+	announcement, err := api.store.CreateAnnouncement(&input)
+	if err != nil {
+		writeError(w, err, "Failed to create announcement")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(announcement); err != nil {
+		logger.Errorf("Failed to encode announcement response: %v", err)
+	}
+}
+
+// updateAnnouncement replaces an announcement's message, severity, time window and tenants (admin only)
+func (api *API) updateAnnouncement(w http.ResponseWriter, r *http.Request) {
+	announcementID, err := uuid.Parse(mux.Vars(r)["announcementId"])
+	if err != nil {
+		http.Error(w, "Invalid announcement ID", http.StatusBadRequest)
+		return
+	}
+
+	var input types.Announcement
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := input.Validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	announcement, err := api.store.UpdateAnnouncement(announcementID, &input)
+	if err != nil {
+		writeError(w, err, "Failed to update announcement")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(announcement); err != nil {
+		logger.Errorf("Failed to encode announcement response: %v", err)
+	}
+}
+
+// deleteAnnouncement removes an announcement (admin only)
+func (api *API) deleteAnnouncement(w http.ResponseWriter, r *http.Request) {
+	announcementID, err := uuid.Parse(mux.Vars(r)["announcementId"])
+	if err != nil {
+		http.Error(w, "Invalid announcement ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.store.DeleteAnnouncement(announcementID); err != nil {
+		writeError(w, err, "Failed to delete announcement")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getAnnouncementAcknowledgments lists the employees who acknowledged an announcement (admin only)
+func (api *API) getAnnouncementAcknowledgments(w http.ResponseWriter, r *http.Request) {
+	announcementID, err := uuid.Parse(mux.Vars(r)["announcementId"])
+	if err != nil {
+		http.Error(w, "Invalid announcement ID", http.StatusBadRequest)
+		return
+	}
+
+	acks, err := api.store.GetAnnouncementAcknowledgments(announcementID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch acknowledgments")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(acks); err != nil {
+		logger.Errorf("Failed to encode acknowledgments response: %v", err)
+	}
+}
+
+// getActiveAnnouncements returns the banners showing now to the current employee, marked with
+// whether they acknowledged each; frontends poll it (requires auth)
+func (api *API) getActiveAnnouncements(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	announcements, err := api.store.GetActiveAnnouncements(employee)
+	if err != nil {
+		writeError(w, err, "Failed to fetch announcements")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(announcements); err != nil {
+		logger.Errorf("Failed to encode announcements response: %v", err)
+	}
+}
+
+// acknowledgeAnnouncement records that the current employee dismissed an announcement (requires auth)
+func (api *API) acknowledgeAnnouncement(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	announcementID, err := uuid.Parse(mux.Vars(r)["announcementId"])
+	if err != nil {
+		http.Error(w, "Invalid announcement ID", http.StatusBadRequest)
+		return
+	}
+
+	ack, err := api.store.AcknowledgeAnnouncement(announcementID, employee)
+	if err != nil {
+		writeError(w, err, "Failed to acknowledge announcement")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(ack); err != nil {
+		logger.Errorf("Failed to encode acknowledgment response: %v", err)
+	}
+}
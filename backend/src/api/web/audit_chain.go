@@ -0,0 +1,52 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/logger"
+)
+
+// maxAuditVerifyDays bounds the date range of one audit chain verification
+const maxAuditVerifyDays = 366
+
+// verifyAuditChain re-validates the audit hash chain for entries created between ?from= and
+// ?to= (YYYY-MM-DD, both inclusive; to defaults to today) against its hashes and WORM anchors (admin only)
+func (api *API) verifyAuditChain(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	from, err := time.Parse("2006-01-02", query.Get("from"))
+	if err != nil {
+		http.Error(w, "from must be a YYYY-MM-DD date", http.StatusBadRequest)
+		return
+	}
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	if v := query.Get("to"); v != "" {
+		if to, err = time.Parse("2006-01-02", v); err != nil {
+			http.Error(w, "to must be a YYYY-MM-DD date", http.StatusBadRequest)
+			return
+		}
+	}
+	if to.Before(from) {
+		http.Error(w, "to must not be before from", http.StatusBadRequest)
+		return
+	}
+	if to.Sub(from) > maxAuditVerifyDays*24*time.Hour {
+		http.Error(w, "The range cannot exceed 366 days", http.StatusBadRequest)
+		return
+	}
+
+	verification, err := api.anchorer.Verify(r.Context(), from, to.AddDate(0, 0, 1))
+	if err != nil {
+		writeError(w, err, "Failed to verify audit chain")
+		return
+	}
+	if !verification.Valid {
+		logger.Warningf("Audit chain verification for %s to %s found %d problems", from.Format("2006-01-02"), to.Format("2006-01-02"), len(verification.Problems))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(verification); err != nil {
+		logger.Errorf("Failed to encode audit chain verification response: %v", err)
+	}
+}
@@ -0,0 +1,155 @@
+package webapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// requestBreakGlass grants the caller time-boxed admin access to a tenant they are not assigned to (admin only)
+// Every grant is audited and announced to all admins
+func (api *API) requestBreakGlass(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var req struct {
+		Reason          string `json:"reason"`
+		DurationMinutes int    `json:"durationMinutes,omitempty"` // Defaults to 60, capped at 240
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if len(req.Reason) < 10 {
+		http.Error(w, "A reason of at least 10 characters is required", http.StatusBadRequest)
+		return
+	}
+
+	duration := types.BreakGlassDefaultDuration
+	if req.DurationMinutes > 0 {
+		duration = time.Duration(req.DurationMinutes) * time.Minute
+	}
+	if duration > types.BreakGlassMaxDuration {
+		http.Error(w, fmt.Sprintf("durationMinutes cannot exceed %d", int(types.BreakGlassMaxDuration.Minutes())), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := api.store.GetTenantConfig(tenantID); err != nil {
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	grant, err := api.store.CreateBreakGlassGrant(employee.ID, tenantID, req.Reason, duration)
+	if err != nil {
+		logger.Errorf("Failed to create break-glass grant for %s on tenant %s: %v", employee.Email, tenantID, err)
+		writeError(w, err, "Failed to grant break-glass access")
+		return
+	}
+	grant.EmployeeEmail = employee.Email
+
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	details := map[string]interface{}{
+		"reason":    req.Reason,
+		"expiresAt": grant.ExpiresAt,
+	}
+	if err := api.store.CreateAuditLog(employee.ID, tenantID, nil, types.AuditActionElevate, types.AuditResourceBreakGlass, &grant.ID, details, &ipAddress, &userAgent); err != nil {
+		logger.Errorf("Failed to audit break-glass grant %s: %v", grant.ID, err)
+	}
+
+	if api.notifier != nil {
+		go api.notifier.AlertAdmins(
+			fmt.Sprintf("Break-glass access granted on %s", tenantID),
+			fmt.Sprintf("%s elevated to admin on tenant %s until %s. Reason: %s",
+				employee.Email, tenantID, grant.ExpiresAt.UTC().Format(time.RFC1123), req.Reason),
+		)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(grant); err != nil {
+		logger.Errorf("Failed to encode break-glass grant response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// getBreakGlassGrants lists break-glass grants (admin only)
+// Optional query: ?active=true
+func (api *API) getBreakGlassGrants(w http.ResponseWriter, r *http.Request) {
+	grants, err := api.store.GetBreakGlassGrants(r.URL.Query().Get("active") == "true")
+	if err != nil {
+		logger.Errorf("Failed to get break-glass grants: %v", err)
+		writeError(w, err, "Failed to fetch break-glass grants")
+		return
+	}
+
+	if grants == nil {
+		grants = []*types.BreakGlassGrant{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(grants); err != nil {
+		logger.Errorf("Failed to encode break-glass grants response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// revokeBreakGlass ends a break-glass grant before it expires (admin only)
+func (api *API) revokeBreakGlass(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	grantID, err := uuid.Parse(mux.Vars(r)["grantId"])
+	if err != nil {
+		http.Error(w, "Invalid grant ID", http.StatusBadRequest)
+		return
+	}
+
+	grant, err := api.store.RevokeBreakGlassGrant(grantID, &employee.ID)
+	if err != nil {
+		logger.Errorf("Failed to revoke break-glass grant %s: %v", grantID, err)
+		writeError(w, err, "Failed to revoke break-glass grant")
+		return
+	}
+
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	details := map[string]interface{}{
+		"grantee": grant.EmployeeID,
+		"expired": false,
+	}
+	if err := api.store.CreateAuditLog(employee.ID, grant.TenantID, nil, types.AuditActionRevoke, types.AuditResourceBreakGlass, &grant.ID, details, &ipAddress, &userAgent); err != nil {
+		logger.Errorf("Failed to audit break-glass revocation %s: %v", grant.ID, err)
+	}
+
+	if api.notifier != nil {
+		go api.notifier.AlertAdmins(
+			fmt.Sprintf("Break-glass access revoked on %s", grant.TenantID),
+			fmt.Sprintf("%s revoked break-glass grant %s on tenant %s.", employee.Email, grant.ID, grant.TenantID),
+		)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
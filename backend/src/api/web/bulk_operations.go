@@ -0,0 +1,105 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"welltaxpro/src/internal/bulk"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// createBulkOperation queues an action over many clients or filings of a tenant, chosen by ID or
+// by filter (admin only). The worker runs it; poll the operation for per-item results.
+func (api *API) createBulkOperation(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Action string            `json:"action"`
+		IDs    []uuid.UUID       `json:"ids"`
+		Filter *types.BulkFilter `json:"filter"`
+		Params json.RawMessage   `json:"params"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	op := &types.BulkOperation{
+		TenantID:  mux.Vars(r)["tenantId"],
+		Action:    req.Action,
+		Selection: &types.BulkSelection{IDs: req.IDs, Filter: req.Filter},
+		Params:    req.Params,
+		CreatedBy: employee.ID,
+	}
+	if err := bulk.Validate(api.store, op); err != nil {
+		writeError(w, err, "Failed to validate bulk operation")
+		return
+	}
+
+	created, err := api.store.CreateBulkOperation(op)
+	if err != nil {
+		writeError(w, err, "Failed to queue bulk operation")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(created); err != nil {
+		logger.Errorf("Failed to encode bulk operation response: %v", err)
+	}
+}
+
+// getBulkOperations returns a tenant's latest bulk operations without their items (admin only)
+func (api *API) getBulkOperations(w http.ResponseWriter, r *http.Request) {
+	limit := 50
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, 200)
+	}
+
+	ops, err := api.store.GetBulkOperations(mux.Vars(r)["tenantId"], limit)
+	if err != nil {
+		writeError(w, err, "Failed to fetch bulk operations")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(ops); err != nil {
+		logger.Errorf("Failed to encode bulk operations response: %v", err)
+	}
+}
+
+// getBulkOperation returns a bulk operation with the result of each item (admin only)
+func (api *API) getBulkOperation(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	operationID, err := uuid.Parse(vars["operationId"])
+	if err != nil {
+		http.Error(w, "Invalid operation ID", http.StatusBadRequest)
+		return
+	}
+
+	op, err := api.store.GetBulkOperation(vars["tenantId"], operationID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch bulk operation")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(op); err != nil {
+		logger.Errorf("Failed to encode bulk operation response: %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// campaignRequest is the body of campaign create and update requests
+type campaignRequest struct {
+	Key         string  `json:"key"` // Discount code campaign label and utm_campaign value
+	Name        string  `json:"name"`
+	Channel     string  `json:"channel"`
+	Description *string `json:"description"`
+	StartsOn    *string `json:"startsOn"` // YYYY-MM-DD
+	EndsOn      *string `json:"endsOn"`   // YYYY-MM-DD
+	IsActive    *bool   `json:"isActive"`
+}
+
+// validate normalizes the request and returns a client-facing message when it is invalid
+func (req *campaignRequest) validate() string {
+	req.Key = strings.TrimSpace(req.Key)
+	req.Name = strings.TrimSpace(req.Name)
+	req.Channel = strings.ToUpper(strings.TrimSpace(req.Channel))
+
+	if !discountCampaignPattern.MatchString(req.Key) {
+		return "key is required (letters, digits, spaces, '_', '.', '-'; max 100)"
+	}
+	if req.Name == "" {
+		req.Name = req.Key
+	}
+	if !types.IsValidCampaignChannel(req.Channel) {
+		return "channel must be one of " + strings.Join(types.ValidCampaignChannels, ", ")
+	}
+
+	var starts, ends time.Time
+	var err error
+	if req.StartsOn != nil {
+		if starts, err = time.Parse("2006-01-02", *req.StartsOn); err != nil {
+			return "startsOn must be YYYY-MM-DD"
+		}
+	}
+	if req.EndsOn != nil {
+		if ends, err = time.Parse("2006-01-02", *req.EndsOn); err != nil {
+			return "endsOn must be YYYY-MM-DD"
+		}
+	}
+	if req.StartsOn != nil && req.EndsOn != nil && ends.Before(starts) {
+		return "endsOn must not be before startsOn"
+	}
+	return ""
+}
+
+// getCampaigns lists a tenant's marketing campaigns (admin only)
+// Query params: active=true to hide inactive campaigns
+func (api *API) getCampaigns(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+	activeOnly := r.URL.Query().Get("active") == "true"
+
+	campaigns, err := api.store.GetCampaigns(tenantID, activeOnly)
+	if err != nil {
+		writeError(w, err, "Failed to fetch campaigns")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(campaigns); err != nil {
+		logger.Errorf("Failed to encode campaigns response: %v", err)
+	}
+}
+
+// createCampaign adds a marketing campaign (admin only)
+func (api *API) createCampaign(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req campaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	campaign := &types.Campaign{
+		TenantID:    mux.Vars(r)["tenantId"],
+		Key:         req.Key,
+		Name:        req.Name,
+		Channel:     req.Channel,
+		Description: req.Description,
+		StartsOn:    req.StartsOn,
+		EndsOn:      req.EndsOn,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+		CreatedBy:   &employee.ID,
+	}
+	if err := api.store.CreateCampaign(campaign); err != nil {
+		writeError(w, err, "Failed to create campaign")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(campaign)
+}
+
+// updateCampaign replaces a campaign's details (admin only)
+func (api *API) updateCampaign(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	campaignID, err := uuid.Parse(vars["campaignId"])
+	if err != nil {
+		http.Error(w, "Invalid campaign ID", http.StatusBadRequest)
+		return
+	}
+
+	var req campaignRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	campaign := &types.Campaign{
+		ID:          campaignID,
+		TenantID:    vars["tenantId"],
+		Key:         req.Key,
+		Name:        req.Name,
+		Channel:     req.Channel,
+		Description: req.Description,
+		StartsOn:    req.StartsOn,
+		EndsOn:      req.EndsOn,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+	if err := api.store.UpdateCampaign(campaign); err != nil {
+		writeError(w, err, "Failed to update campaign")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(campaign); err != nil {
+		logger.Errorf("Failed to encode campaign response: %v", err)
+	}
+}
+
+// getCampaignReport compares revenue, conversions and commission cost across campaigns and channels (admin only)
+// Query params: active=true to leave out inactive campaigns
+func (api *API) getCampaignReport(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+	activeOnly := r.URL.Query().Get("active") == "true"
+
+	logger.Infof("Building campaign report for tenant %s", tenantID)
+
+	report, err := api.store.GetCampaignReport(r.Context(), tenantID, activeOnly)
+	if err != nil {
+		logger.Errorf("Failed to build campaign report for tenant %s: %v", tenantID, err)
+		writeError(w, err, "Failed to build campaign report")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.Errorf("Failed to encode campaign report response: %v", err)
+	}
+}
@@ -2,13 +2,21 @@ package webapi
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
 	"github.com/gorilla/mux"
 )
 
-// getClients returns all clients for a tenant
+// getClients returns all clients for a tenant, or one page of them when ?cursor= is present
+// ?stream=ndjson or ?stream=json streams them instead for large exports
+// Archived clients are excluded unless ?includeArchived=true
 func (api *API) getClients(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	tenantID := vars["tenantId"]
@@ -21,10 +29,38 @@ func (api *API) getClients(w http.ResponseWriter, r *http.Request) {
 
 	logger.Infof("[getClients] Starting request - TenantID: %s, Method: %s, Path: %s", tenantID, r.Method, r.URL.Path)
 
-	clients, err := api.store.GetClients(tenantID)
+	includeArchived := r.URL.Query().Get("includeArchived") == "true"
+
+	if format := r.URL.Query().Get("stream"); format != "" {
+		api.streamClients(w, r, tenantID, includeArchived, format)
+		return
+	}
+
+	page, paged, err := parsePageRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if paged {
+		clientPage, err := api.store.GetClientPage(r.Context(), tenantID, includeArchived, page)
+		if err != nil {
+			logger.Errorf("[getClients] FAILED - TenantID: %s, Error: %v", tenantID, err)
+			writeError(w, err, "failed to fetch clients")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(clientPage); err != nil {
+			logger.Errorf("[getClients] Failed to encode response - TenantID: %s, Error: %v", tenantID, err)
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	clients, err := api.store.GetClients(r.Context(), tenantID, includeArchived)
 	if err != nil {
 		logger.Errorf("[getClients] FAILED - TenantID: %s, Error: %v", tenantID, err)
-		http.Error(w, "failed to fetch clients", http.StatusInternalServerError)
+		writeError(w, err, "failed to fetch clients")
 		return
 	}
 
@@ -38,6 +74,60 @@ func (api *API) getClients(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// searchClients returns the tenant's clients best matching ?q=, best first, with their filing years
+// ?q= is matched against names, email and phone, or against the SSN's last four for four digits
+// or a masked SSN such as ***-**-1234. Archived clients are excluded unless ?includeArchived=true
+func (api *API) searchClients(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+	params := r.URL.Query()
+
+	q := strings.TrimSpace(params.Get("q"))
+	if utf8.RuneCountInString(q) < types.MinClientSearchLength {
+		http.Error(w, fmt.Sprintf("q must be at least %d characters", types.MinClientSearchLength), http.StatusBadRequest)
+		return
+	}
+
+	limit := 0
+	if raw := params.Get("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil || limit < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	query := types.NewClientSearchQuery(q, params.Get("includeArchived") == "true", limit)
+	results, err := api.store.SearchClients(r.Context(), tenantID, query)
+	if err != nil {
+		logger.Errorf("[searchClients] FAILED - TenantID: %s, Error: %v", tenantID, err)
+		writeError(w, err, "failed to search clients")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(results); err != nil {
+		logger.Errorf("[searchClients] Failed to encode response - TenantID: %s, Error: %v", tenantID, err)
+	}
+}
+
+// streamClients writes a tenant's clients as they are read from the database
+func (api *API) streamClients(w http.ResponseWriter, r *http.Request, tenantID string, includeArchived bool, format string) {
+	sw, ok := newStreamWriter(w, format)
+	if !ok {
+		http.Error(w, "stream must be ndjson or json", http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("[getClients] Streaming clients as %s - TenantID: %s", format, tenantID)
+
+	err := api.store.StreamClients(r.Context(), tenantID, includeArchived, func(client *types.Client) error {
+		return sw.write(client)
+	})
+	sw.finish(err, "failed to fetch clients")
+
+	logger.Infof("[getClients] Streamed %d clients - TenantID: %s", sw.count, tenantID)
+}
+
 // getClient returns a specific client by ID for a tenant
 func (api *API) getClient(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -51,10 +141,70 @@ func (api *API) getClient(w http.ResponseWriter, r *http.Request) {
 
 	logger.Infof("Fetching client %s for tenant: %s", clientID, tenantID)
 
-	client, err := api.store.GetClientByID(tenantID, clientID)
+	client, err := api.store.GetClientByID(r.Context(), tenantID, clientID)
 	if err != nil {
 		logger.Errorf("Failed to get client %s for tenant %s: %v", clientID, tenantID, err)
-		http.Error(w, "client not found", http.StatusNotFound)
+		writeError(w, err, "failed to fetch client")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(client); err != nil {
+		logger.Errorf("Failed to encode client response: %v", err)
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// archiveClient marks a client inactive (moved firms, deceased, etc.)
+// Archived clients are hidden from default listings and lose portal access
+func (api *API) archiveClient(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	clientID := vars["clientId"]
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Reason == "" {
+		http.Error(w, "Archive reason is required", http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Archiving client %s in tenant %s with reason: %s", clientID, tenantID, req.Reason)
+
+	client, err := api.store.ArchiveClient(r.Context(), tenantID, clientID, req.Reason)
+	if err != nil {
+		logger.Errorf("Failed to archive client %s for tenant %s: %v", clientID, tenantID, err)
+		writeError(w, err, "Failed to archive client")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(client); err != nil {
+		logger.Errorf("Failed to encode client response: %v", err)
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// unarchiveClient restores an archived client
+func (api *API) unarchiveClient(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	clientID := vars["clientId"]
+
+	logger.Infof("Unarchiving client %s in tenant %s", clientID, tenantID)
+
+	client, err := api.store.UnarchiveClient(r.Context(), tenantID, clientID)
+	if err != nil {
+		logger.Errorf("Failed to unarchive client %s for tenant %s: %v", clientID, tenantID, err)
+		writeError(w, err, "Failed to unarchive client")
 		return
 	}
 
@@ -67,6 +217,7 @@ func (api *API) getClient(w http.ResponseWriter, r *http.Request) {
 }
 
 // getClientComprehensive returns all data for a specific client (filings, dependents, etc.)
+// Optional query: ?fields=client,filings.year,filings.status to trim the payload
 func (api *API) getClientComprehensive(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	tenantID := vars["tenantId"]
@@ -79,22 +230,24 @@ func (api *API) getClientComprehensive(w http.ResponseWriter, r *http.Request) {
 
 	logger.Infof("Fetching comprehensive data for client %s (tenant: %s)", clientID, tenantID)
 
-	clientData, err := api.store.GetClientComprehensive(tenantID, clientID)
+	clientData, err := api.store.GetClientComprehensive(r.Context(), tenantID, clientID)
 	if err != nil {
 		logger.Errorf("Failed to get comprehensive data for client %s (tenant %s): %v", clientID, tenantID, err)
-		http.Error(w, "failed to fetch client data", http.StatusInternalServerError)
+		writeError(w, err, "failed to fetch client data")
 		return
 	}
 
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(clientData); err != nil {
+	if err := writeSlimJSON(w, r, clientData); err != nil {
 		logger.Errorf("Failed to encode comprehensive client response: %v", err)
 		http.Error(w, "failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
 
-// getFilings returns clients with their filings (paginated, no filtering)
+// getFilings returns clients with their filings (paginated, no filtering), each filing scored
+// for completeness
+// ?cursor= switches from limit/offset to cursor pagination; ?stream= streams every client instead
+// ?sort=completeness orders every client and their filings closest-to-ready first, unpaged
 func (api *API) getFilings(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	tenantID := vars["tenantId"]
@@ -104,6 +257,43 @@ func (api *API) getFilings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	sortBy := r.URL.Query().Get("sort")
+	if sortBy != "" && sortBy != "completeness" {
+		http.Error(w, "sort must be completeness", http.StatusBadRequest)
+		return
+	}
+	if sortBy == "completeness" {
+		api.getFilingsByCompleteness(w, r, tenantID)
+		return
+	}
+
+	if format := r.URL.Query().Get("stream"); format != "" {
+		api.streamFilings(w, r, tenantID, format)
+		return
+	}
+
+	page, paged, err := parsePageRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if paged {
+		filingPage, err := api.store.GetClientsByFilingsPage(r.Context(), tenantID, page)
+		if err != nil {
+			logger.Errorf("Failed to get filings for tenant %s: %v", tenantID, err)
+			writeError(w, err, "failed to fetch filings")
+			return
+		}
+		api.scoreFilings(tenantID, filingPage.Items)
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(filingPage); err != nil {
+			logger.Errorf("Failed to encode filings response: %v", err)
+			http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		}
+		return
+	}
+
 	// Get pagination parameters (default: limit=100, offset=0)
 	limit := 100
 	offset := 0
@@ -122,14 +312,15 @@ func (api *API) getFilings(w http.ResponseWriter, r *http.Request) {
 
 	logger.Infof("Fetching filings for tenant %s with pagination - limit: %d, offset: %d", tenantID, limit, offset)
 
-	clientsData, err := api.store.GetClientsByFilings(tenantID, limit, offset)
+	clientsData, err := api.store.GetClientsByFilings(r.Context(), tenantID, limit, offset)
 	if err != nil {
 		logger.Errorf("Failed to get filings for tenant %s: %v", tenantID, err)
-		http.Error(w, "failed to fetch filings", http.StatusInternalServerError)
+		writeError(w, err, "failed to fetch filings")
 		return
 	}
 
 	logger.Infof("Successfully fetched %d clients with their filings", len(clientsData))
+	api.scoreFilings(tenantID, clientsData)
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(clientsData); err != nil {
@@ -138,3 +329,94 @@ func (api *API) getFilings(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+// maxCompletenessSortClients bounds the clients ?sort=completeness loads to rank them
+const maxCompletenessSortClients = 5000
+
+// getFilingsByCompleteness ranks every client with filings by completeness. Scores come from the
+// central database, so the tenant's paged query cannot order by them; instead every client is
+// loaded and scored, and paging parameters are refused rather than sorting one page.
+func (api *API) getFilingsByCompleteness(w http.ResponseWriter, r *http.Request, tenantID string) {
+	query := r.URL.Query()
+	for _, param := range []string{"limit", "offset", "cursor", "stream"} {
+		if query.Has(param) {
+			http.Error(w, "sort=completeness ranks every filing and cannot be combined with "+param, http.StatusBadRequest)
+			return
+		}
+	}
+
+	clients := []*types.ClientComprehensive{}
+	err := api.store.StreamClientsByFilings(r.Context(), tenantID, func(client *types.ClientComprehensive) error {
+		if len(clients) == maxCompletenessSortClients {
+			return apperr.Validation("tenant has more than %d clients with filings; page through them without sort=completeness", maxCompletenessSortClients)
+		}
+		clients = append(clients, client)
+		return nil
+	})
+	if err != nil {
+		logger.Errorf("Failed to get filings for tenant %s: %v", tenantID, err)
+		writeError(w, err, "failed to fetch filings")
+		return
+	}
+
+	if err := api.store.ApplyFilingCompleteness(tenantID, clients); err != nil {
+		logger.Errorf("Failed to score filings for tenant %s: %v", tenantID, err)
+		writeError(w, err, "failed to score filings")
+		return
+	}
+	types.SortByCompleteness(clients)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(clients); err != nil {
+		logger.Errorf("Failed to encode filings response: %v", err)
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+	}
+}
+
+// scoreFilings sets the completeness of each client's filings. The list is still served if
+// scoring fails, just without scores.
+func (api *API) scoreFilings(tenantID string, clients []*types.ClientComprehensive) {
+	if err := api.store.ApplyFilingCompleteness(tenantID, clients); err != nil {
+		logger.Warningf("Serving filings for tenant %s without completeness scores: %v", tenantID, err)
+	}
+}
+
+// streamScoreBatch is how many streamed clients are scored together
+const streamScoreBatch = 200
+
+// streamFilings writes every client with filings, loading one client at a time and scoring them
+// a batch at a time
+func (api *API) streamFilings(w http.ResponseWriter, r *http.Request, tenantID string, format string) {
+	sw, ok := newStreamWriter(w, format)
+	if !ok {
+		http.Error(w, "stream must be ndjson or json", http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Streaming filings as %s for tenant %s", format, tenantID)
+
+	batch := make([]*types.ClientComprehensive, 0, streamScoreBatch)
+	writeBatch := func() error {
+		api.scoreFilings(tenantID, batch)
+		for _, client := range batch {
+			if err := sw.write(client); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+	err := api.store.StreamClientsByFilings(r.Context(), tenantID, func(client *types.ClientComprehensive) error {
+		batch = append(batch, client)
+		if len(batch) < streamScoreBatch {
+			return nil
+		}
+		return writeBatch()
+	})
+	if err == nil {
+		err = writeBatch()
+	}
+	sw.finish(err, "failed to fetch filings")
+
+	logger.Infof("Streamed %d clients with their filings for tenant %s", sw.count, tenantID)
+}
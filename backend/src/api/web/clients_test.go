@@ -0,0 +1,100 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/uuid"
+)
+
+func TestGetFilingsSortByCompleteness(t *testing.T) {
+	env := newTestEnv(t)
+	employee, token := env.signIn(types.RoleAccountant)
+	env.store.GrantTenantRole(employee.ID, testTenantID, types.RoleViewer)
+
+	// Listed newest first, as the tenant query returns them; the best filing is on the last client
+	scores := []int{10, 40, 95, 70}
+	for _, score := range scores {
+		filing := &types.Filing{ID: uuid.New()}
+		env.store.scores[filing.ID] = score
+		env.store.filings = append(env.store.filings, &types.ClientComprehensive{
+			Client:  &types.Client{ID: uuid.New()},
+			Filings: []*types.Filing{filing},
+		})
+	}
+
+	rec := env.do(t, http.MethodGet, "/api/v1/"+testTenantID+"/filings?sort=completeness", token, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var clients []*types.ClientComprehensive
+	if err := json.Unmarshal(rec.Body.Bytes(), &clients); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	var got []int
+	for _, client := range clients {
+		got = append(got, client.Filings[0].Completeness.Score)
+	}
+	want := []int{95, 70, 40, 10}
+	if len(got) != len(want) {
+		t.Fatalf("scores = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("scores = %v, want %v", got, want)
+		}
+	}
+}
+
+func TestGetFilingsSortByCompletenessRejectsPaging(t *testing.T) {
+	env := newTestEnv(t)
+	employee, token := env.signIn(types.RoleAccountant)
+	env.store.GrantTenantRole(employee.ID, testTenantID, types.RoleViewer)
+
+	for _, query := range []string{"limit=50", "offset=100", "cursor=", "stream=ndjson"} {
+		t.Run(query, func(t *testing.T) {
+			rec := env.do(t, http.MethodGet, "/api/v1/"+testTenantID+"/filings?sort=completeness&"+query, token, "")
+			if rec.Code != http.StatusBadRequest {
+				t.Errorf("status = %d, want %d (body %q)", rec.Code, http.StatusBadRequest, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestStreamFilingsScoresInBatches(t *testing.T) {
+	env := newTestEnv(t)
+	employee, token := env.signIn(types.RoleAccountant)
+	env.store.GrantTenantRole(employee.ID, testTenantID, types.RoleViewer)
+
+	clients := 2*streamScoreBatch + 50
+	for i := 0; i < clients; i++ {
+		filing := &types.Filing{ID: uuid.New()}
+		env.store.scores[filing.ID] = i % 100
+		env.store.filings = append(env.store.filings, &types.ClientComprehensive{
+			Client:  &types.Client{ID: uuid.New()},
+			Filings: []*types.Filing{filing},
+		})
+	}
+
+	rec := env.do(t, http.MethodGet, "/api/v1/"+testTenantID+"/filings?stream=json", token, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body %q)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var streamed []*types.ClientComprehensive
+	if err := json.Unmarshal(rec.Body.Bytes(), &streamed); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(streamed) != clients {
+		t.Fatalf("streamed %d clients, want %d", len(streamed), clients)
+	}
+	for i, client := range streamed {
+		if client.Filings[0].Completeness == nil || client.Filings[0].Completeness.Score != i%100 {
+			t.Fatalf("client %d: completeness = %+v, want score %d", i, client.Filings[0].Completeness, i%100)
+		}
+	}
+	if want := 3; env.store.scoreCalls != want {
+		t.Errorf("scored %d times, want %d", env.store.scoreCalls, want)
+	}
+}
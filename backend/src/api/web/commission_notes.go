@@ -0,0 +1,162 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// maxCommissionNoteLength bounds a single commission note
+const maxCommissionNoteLength = 4000
+
+// commissionFromRequest parses the commissionId path parameter and checks the commission exists.
+// It writes the error response and returns false when the commission cannot be used.
+func (api *API) commissionFromRequest(w http.ResponseWriter, r *http.Request) (string, uuid.UUID, bool) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	commissionID, err := uuid.Parse(vars["commissionId"])
+	if err != nil {
+		http.Error(w, "Invalid commission ID", http.StatusBadRequest)
+		return "", uuid.Nil, false
+	}
+
+	if _, err := api.store.GetCommission(r.Context(), tenantID, commissionID.String()); err != nil {
+		logger.Errorf("Failed to get commission %s: %v", commissionID, err)
+		writeError(w, err, "Failed to fetch commission")
+		return "", uuid.Nil, false
+	}
+
+	return tenantID, commissionID, true
+}
+
+// getCommissionNotes returns the notes of a commission, oldest first (admin only)
+func (api *API) getCommissionNotes(w http.ResponseWriter, r *http.Request) {
+	tenantID, commissionID, ok := api.commissionFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	notes, err := api.store.GetCommissionNotes(tenantID, commissionID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch commission notes")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(notes); err != nil {
+		logger.Errorf("Failed to encode commission notes response: %v", err)
+	}
+}
+
+// addCommissionNote appends a note to a commission (admin only)
+func (api *API) addCommissionNote(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Body string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" {
+		http.Error(w, "Note body is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Body) > maxCommissionNoteLength {
+		http.Error(w, "Note body is too long", http.StatusBadRequest)
+		return
+	}
+
+	tenantID, commissionID, ok := api.commissionFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	note := &types.CommissionNote{
+		TenantID:     tenantID,
+		CommissionID: commissionID,
+		AuthorID:     &employee.ID,
+		AuthorEmail:  &employee.Email,
+		Body:         req.Body,
+	}
+	if err := api.store.AddCommissionNote(note); err != nil {
+		writeError(w, err, "Failed to add commission note")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(note)
+}
+
+// addCommissionTag tags a commission, e.g. HOLD or VIP (admin only)
+func (api *API) addCommissionTag(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Tag string `json:"tag"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	tag, valid := types.NormalizeCommissionTag(req.Tag)
+	if !valid {
+		http.Error(w, "Tag must be 1-50 letters, digits, '_' or '-'", http.StatusBadRequest)
+		return
+	}
+
+	tenantID, commissionID, ok := api.commissionFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := api.store.AddCommissionTag(tenantID, commissionID, tag, employee.ID); err != nil {
+		writeError(w, err, "Failed to tag commission")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// removeCommissionTag removes a tag from a commission (admin only)
+func (api *API) removeCommissionTag(w http.ResponseWriter, r *http.Request) {
+	tag, valid := types.NormalizeCommissionTag(mux.Vars(r)["tag"])
+	if !valid {
+		http.Error(w, "Invalid tag", http.StatusBadRequest)
+		return
+	}
+
+	tenantID, commissionID, ok := api.commissionFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	if err := api.store.RemoveCommissionTag(tenantID, commissionID, tag); err != nil {
+		writeError(w, err, "Failed to remove commission tag")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
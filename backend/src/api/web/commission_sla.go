@@ -0,0 +1,95 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// getOverdueCommissions lists PENDING commissions older than the tenant's approval SLA, oldest first
+// Query params: limit (1-1000, default 100)
+func (api *API) getOverdueCommissions(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	limit := 100 // default
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 1000 {
+			http.Error(w, "limit must be between 1 and 1000", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	report, err := api.store.GetOverdueCommissions(r.Context(), tenantID, limit)
+	if err != nil {
+		logger.Errorf("Failed to get overdue commissions for tenant %s: %v", tenantID, err)
+		writeError(w, err, "Failed to fetch overdue commissions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.Errorf("Failed to encode overdue commissions response: %v", err)
+	}
+}
+
+// getCommissionSLA returns the effective commission approval SLA for a tenant (admin only)
+func (api *API) getCommissionSLA(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	sla, err := api.store.GetCommissionSLA(tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get commission SLA: %v", err)
+		writeError(w, err, "Failed to fetch commission SLA")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sla); err != nil {
+		logger.Errorf("Failed to encode commission SLA response: %v", err)
+	}
+}
+
+// updateCommissionSLA replaces the commission approval SLA for a tenant (admin only)
+func (api *API) updateCommissionSLA(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var sla types.CommissionSLA
+	if err := json.NewDecoder(r.Body).Decode(&sla); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if sla.PendingDays < 1 || sla.PendingDays > 365 {
+		http.Error(w, "pendingDays must be between 1 and 365", http.StatusBadRequest)
+		return
+	}
+	if sla.ReminderDays < 0 || sla.ReminderDays > 365 {
+		http.Error(w, "reminderDays must be between 0 and 365", http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Updating commission SLA for tenant %s", tenantID)
+
+	if err := api.store.UpdateCommissionSLA(tenantID, &sla, employee.ID); err != nil {
+		writeError(w, err, "Failed to update commission SLA")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sla); err != nil {
+		logger.Errorf("Failed to encode commission SLA response: %v", err)
+	}
+}
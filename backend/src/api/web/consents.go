@@ -0,0 +1,256 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// getConsentTemplates lists a tenant's consent templates
+// Optional query: ?active=true
+func (api *API) getConsentTemplates(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	templates, err := api.store.GetConsentTemplates(tenantID, r.URL.Query().Get("active") == "true")
+	if err != nil {
+		logger.Errorf("Failed to get consent templates for tenant %s: %v", tenantID, err)
+		writeError(w, err, "Failed to fetch consent templates")
+		return
+	}
+
+	if templates == nil {
+		templates = []*types.ConsentTemplate{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(templates); err != nil {
+		logger.Errorf("Failed to encode consent templates response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// createConsentTemplate publishes a new version of the consent wording for a purpose (admin only)
+// Taxpayers who consented to an earlier version keep their consent until they revoke it
+func (api *API) createConsentTemplate(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var req struct {
+		Purpose string `json:"purpose"`
+		Title   string `json:"title"`
+		Body    string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !types.IsValidConsentPurpose(req.Purpose) {
+		http.Error(w, "Invalid purpose. Must be one of: "+strings.Join(types.ConsentPurposes, ", "), http.StatusBadRequest)
+		return
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Title == "" || req.Body == "" {
+		http.Error(w, "title and body are required", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := api.store.GetTenantConfig(tenantID); err != nil {
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	template := &types.ConsentTemplate{
+		TenantID:  tenantID,
+		Purpose:   req.Purpose,
+		Title:     req.Title,
+		Body:      req.Body,
+		CreatedBy: &employee.ID,
+	}
+	if err := api.store.CreateConsentTemplate(template); err != nil {
+		logger.Errorf("Failed to create consent template: %v", err)
+		writeError(w, err, "Failed to create consent template")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(template); err != nil {
+		logger.Errorf("Failed to encode consent template response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// getClientConsents returns a client's consent history, including revoked consents
+func (api *API) getClientConsents(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	clientID, err := uuid.Parse(vars["clientId"])
+	if err != nil {
+		http.Error(w, "Invalid client ID", http.StatusBadRequest)
+		return
+	}
+
+	consents, err := api.store.GetClientConsents(tenantID, clientID)
+	if err != nil {
+		logger.Errorf("Failed to get consents for client %s: %v", clientID, err)
+		writeError(w, err, "Failed to fetch consents")
+		return
+	}
+
+	if consents == nil {
+		consents = []*types.ClientConsent{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(consents); err != nil {
+		logger.Errorf("Failed to encode client consents response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// getPortalConsents returns every active consent template with the user's current decision (tenant user only)
+func (api *API) getPortalConsents(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	templates, err := api.store.GetConsentTemplates(tenantUser.TenantID, true)
+	if err != nil {
+		logger.Errorf("Failed to get consent templates for tenant %s: %v", tenantUser.TenantID, err)
+		writeError(w, err, "Failed to fetch consents")
+		return
+	}
+
+	active := map[string]*types.ClientConsent{}
+	if tenantUser.ClientID != NewClientUUID {
+		consents, err := api.store.GetClientConsents(tenantUser.TenantID, tenantUser.ClientID)
+		if err != nil {
+			logger.Errorf("Failed to get consents for client %s: %v", tenantUser.ClientID, err)
+			writeError(w, err, "Failed to fetch consents")
+			return
+		}
+		for _, c := range consents {
+			if c.IsActive() {
+				active[c.Purpose] = c
+			}
+		}
+	}
+
+	statuses := make([]*types.ConsentStatus, 0, len(templates))
+	for _, t := range templates {
+		consent := active[t.Purpose]
+		statuses = append(statuses, &types.ConsentStatus{
+			Template: t,
+			Consent:  consent,
+			Granted:  consent != nil,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		logger.Errorf("Failed to encode portal consents response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// grantPortalConsent records the user's consent to an active template (tenant user only)
+func (api *API) grantPortalConsent(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	templateID, err := uuid.Parse(mux.Vars(r)["templateId"])
+	if err != nil {
+		http.Error(w, "Invalid template ID", http.StatusBadRequest)
+		return
+	}
+
+	if tenantUser.ClientID == NewClientUUID {
+		http.Error(w, "Complete your profile before granting consent", http.StatusConflict)
+		return
+	}
+
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	consent, err := api.store.GrantConsent(tenantUser.TenantID, tenantUser.ClientID, templateID, &tenantUser.ID, &ipAddress, &userAgent)
+	if err != nil {
+		logger.Errorf("Failed to grant consent %s for client %s: %v", templateID, tenantUser.ClientID, err)
+		writeError(w, err, "Failed to grant consent")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(consent); err != nil {
+		logger.Errorf("Failed to encode consent response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// revokePortalConsent withdraws the user's consent for a purpose (tenant user only)
+func (api *API) revokePortalConsent(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	purpose := mux.Vars(r)["purpose"]
+	if !types.IsValidConsentPurpose(purpose) {
+		http.Error(w, "Invalid purpose", http.StatusBadRequest)
+		return
+	}
+
+	ipAddress := middleware.ClientIP(r)
+	if err := api.store.RevokeConsent(tenantUser.TenantID, tenantUser.ClientID, purpose, &ipAddress); err != nil {
+		logger.Warningf("Failed to revoke %s consent for client %s: %v", purpose, tenantUser.ClientID, err)
+		writeError(w, err, "Failed to revoke consent")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// redactUndisclosedCustomers strips customer identity from commissions whose client has not
+// consented to disclosure to the affiliate. A failed lookup redacts everything.
+func (api *API) redactUndisclosedCustomers(tenantID string, commissions []*types.Commission) {
+	clientIDs := make([]uuid.UUID, 0, len(commissions))
+	for _, c := range commissions {
+		if c.Customer != nil {
+			clientIDs = append(clientIDs, c.UserID)
+		}
+	}
+
+	consenting, err := api.store.GetConsentingClients(tenantID, types.ConsentPurposeAffiliateDisclosure, clientIDs)
+	if err != nil {
+		logger.Errorf("Consent lookup failed, redacting all customers for tenant %s: %v", tenantID, err)
+		consenting = nil
+	}
+
+	for _, c := range commissions {
+		if c.Customer != nil && !consenting[c.UserID] {
+			c.Customer = nil
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package webapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// getDebugCapture returns the debug capture running for a tenant (admin only)
+func (api *API) getDebugCapture(w http.ResponseWriter, r *http.Request) {
+	capture, err := api.store.GetActiveDebugCapture(mux.Vars(r)["tenantId"])
+	if err != nil {
+		writeError(w, err, "Failed to fetch debug capture")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(capture); err != nil {
+		logger.Errorf("Failed to encode debug capture response: %v", err)
+	}
+}
+
+// enableDebugCapture starts capturing a tenant's API requests and responses, with sensitive
+// fields redacted, until it expires (admin only). Every change is audited.
+// Body: {"reason": "...", "durationMinutes": 60}
+func (api *API) enableDebugCapture(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var req struct {
+		Reason          string `json:"reason"`
+		DurationMinutes int    `json:"durationMinutes,omitempty"` // Defaults to 60, capped at 1440
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if len(req.Reason) < 10 {
+		http.Error(w, "A reason of at least 10 characters is required", http.StatusBadRequest)
+		return
+	}
+
+	duration := types.DebugCaptureDefaultDuration
+	if req.DurationMinutes > 0 {
+		duration = time.Duration(req.DurationMinutes) * time.Minute
+	}
+	if req.DurationMinutes < 0 || duration > types.DebugCaptureMaxDuration {
+		http.Error(w, fmt.Sprintf("durationMinutes must be between 1 and %d", int(types.DebugCaptureMaxDuration.Minutes())), http.StatusBadRequest)
+		return
+	}
+
+	if _, err := api.store.GetTenantConfig(tenantID); err != nil {
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	capture, err := api.store.EnableDebugCapture(tenantID, req.Reason, duration, employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to enable debug capture")
+		return
+	}
+	api.debugCaptureMiddleware.Forget(tenantID)
+
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	details := map[string]interface{}{
+		"reason":    req.Reason,
+		"expiresAt": capture.ExpiresAt,
+	}
+	if err := api.store.CreateAuditLog(employee.ID, tenantID, nil, types.AuditActionCreate, types.AuditResourceDebugCapture, &capture.ID, details, &ipAddress, &userAgent); err != nil {
+		logger.Errorf("Failed to audit debug capture %s: %v", capture.ID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(capture); err != nil {
+		logger.Errorf("Failed to encode debug capture response: %v", err)
+	}
+}
+
+// disableDebugCapture stops a tenant's debug capture before it expires (admin only)
+func (api *API) disableDebugCapture(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	capture, err := api.store.DisableDebugCapture(tenantID, employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to disable debug capture")
+		return
+	}
+	api.debugCaptureMiddleware.Forget(tenantID)
+
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	if err := api.store.CreateAuditLog(employee.ID, tenantID, nil, types.AuditActionRevoke, types.AuditResourceDebugCapture, &capture.ID, nil, &ipAddress, &userAgent); err != nil {
+		logger.Errorf("Failed to audit debug capture %s: %v", capture.ID, err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getDebugCaptureEntries lists a tenant's captured requests, newest first (admin only). Reads
+// are audited because the redacted bodies still hold client data.
+// Query params: limit (1-500, default 100)
+func (api *API) getDebugCaptureEntries(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	limit := 100 // default
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 500 {
+			http.Error(w, "limit must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	entries, err := api.store.GetDebugCaptureEntries(tenantID, limit)
+	if err != nil {
+		writeError(w, err, "Failed to fetch debug capture entries")
+		return
+	}
+
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	details := map[string]interface{}{"entries": len(entries)}
+	if err := api.store.CreateAuditLog(employee.ID, tenantID, nil, types.AuditActionView, types.AuditResourceDebugCapture, nil, details, &ipAddress, &userAgent); err != nil {
+		logger.Errorf("Failed to audit debug capture read on tenant %s: %v", tenantID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		logger.Errorf("Failed to encode debug capture entries response: %v", err)
+	}
+}
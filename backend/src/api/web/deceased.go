@@ -0,0 +1,120 @@
+package webapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// markClientDeceased records the death of the taxpayer or spouse and returns the workflow status
+// Body: {"person": "taxpayer"|"spouse", "deathDate": "YYYY-MM-DD"}
+func (api *API) markClientDeceased(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	clientID := vars["clientId"]
+
+	var req struct {
+		Person    string `json:"person"`
+		DeathDate string `json:"deathDate"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.Person != types.DeceasedPersonTaxpayer && req.Person != types.DeceasedPersonSpouse {
+		http.Error(w, "person must be 'taxpayer' or 'spouse'", http.StatusBadRequest)
+		return
+	}
+
+	if req.DeathDate == "" {
+		http.Error(w, "Date of death is required", http.StatusBadRequest)
+		return
+	}
+	deathDate, err := types.ParseDeathDate(req.DeathDate)
+	if err != nil {
+		http.Error(w, "Date of death must be in YYYY-MM-DD format", http.StatusBadRequest)
+		return
+	}
+	if deathDate.After(time.Now()) {
+		http.Error(w, "Date of death cannot be in the future", http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Marking %s of client %s in tenant %s as deceased (%s)", req.Person, clientID, tenantID, req.DeathDate)
+
+	if err := api.store.MarkClientDeceased(r.Context(), tenantID, clientID, req.Person, deathDate.Format("2006-01-02")); err != nil {
+		logger.Errorf("Failed to mark %s of client %s as deceased: %v", req.Person, clientID, err)
+		writeError(w, err, "Failed to mark deceased")
+		return
+	}
+
+	api.writeDeceasedStatus(r.Context(), w, tenantID, clientID)
+}
+
+// getDeceasedStatus returns the death-of-taxpayer workflow status and document checklist
+func (api *API) getDeceasedStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	clientID := vars["clientId"]
+
+	api.writeDeceasedStatus(r.Context(), w, tenantID, clientID)
+}
+
+// checkFilingRollover validates whether a client's filing can roll over into a later tax year
+// Query: ?year=YYYY
+func (api *API) checkFilingRollover(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	clientID := vars["clientId"]
+
+	year, err := strconv.Atoi(r.URL.Query().Get("year"))
+	if err != nil || year < 2000 {
+		http.Error(w, "A valid year query parameter is required", http.StatusBadRequest)
+		return
+	}
+
+	clientData, err := api.store.GetClientComprehensive(r.Context(), tenantID, clientID)
+	if err != nil {
+		logger.Errorf("Failed to get comprehensive data for client %s (tenant %s): %v", clientID, tenantID, err)
+		writeError(w, err, "failed to fetch client data")
+		return
+	}
+
+	check := clientData.CheckRollover(year)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(check); err != nil {
+		logger.Errorf("Failed to encode rollover check response: %v", err)
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// writeDeceasedStatus loads the client and writes the deceased workflow statuses
+func (api *API) writeDeceasedStatus(ctx context.Context, w http.ResponseWriter, tenantID, clientID string) {
+	clientData, err := api.store.GetClientComprehensive(ctx, tenantID, clientID)
+	if err != nil {
+		logger.Errorf("Failed to get comprehensive data for client %s (tenant %s): %v", clientID, tenantID, err)
+		writeError(w, err, "failed to fetch client data")
+		return
+	}
+
+	statuses := clientData.DeceasedStatuses()
+	if statuses == nil {
+		statuses = []*types.DeceasedStatus{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		logger.Errorf("Failed to encode deceased status response: %v", err)
+		http.Error(w, "failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
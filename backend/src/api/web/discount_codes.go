@@ -1,8 +1,15 @@
 package webapi
 
 import (
+	"encoding/csv"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"welltaxpro/src/internal/apperr"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
@@ -10,12 +17,14 @@ import (
 	"github.com/gorilla/mux"
 )
 
-// getDiscountCodes returns all discount codes for a tenant, optionally filtered by affiliate (admin only)
+// getDiscountCodes returns all discount codes for a tenant, optionally filtered by affiliate or campaign (admin only)
+// ?cursor= returns one page of them instead
 func (api *API) getDiscountCodes(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	tenantID := vars["tenantId"]
 
 	affiliateID := r.URL.Query().Get("affiliateId")
+	campaign := r.URL.Query().Get("campaign")
 	activeOnly := r.URL.Query().Get("active") == "true"
 
 	var affiliateIDPtr *string
@@ -23,12 +32,38 @@ func (api *API) getDiscountCodes(w http.ResponseWriter, r *http.Request) {
 		affiliateIDPtr = &affiliateID
 	}
 
-	logger.Infof("Fetching discount codes for tenant: %s (affiliateId=%v, activeOnly=%v)", tenantID, affiliateID, activeOnly)
+	var campaignPtr *string
+	if campaign != "" {
+		campaignPtr = &campaign
+	}
+
+	logger.Infof("Fetching discount codes for tenant: %s (affiliateId=%v, campaign=%v, activeOnly=%v)", tenantID, affiliateID, campaign, activeOnly)
 
-	codes, err := api.store.GetDiscountCodes(tenantID, affiliateIDPtr, activeOnly)
+	page, paged, err := parsePageRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if paged {
+		codePage, err := api.store.GetDiscountCodePage(r.Context(), tenantID, affiliateIDPtr, campaignPtr, activeOnly, page)
+		if err != nil {
+			logger.Errorf("Failed to get discount codes: %v", err)
+			writeError(w, err, "Failed to fetch discount codes")
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(codePage); err != nil {
+			logger.Errorf("Failed to encode discount codes response: %v", err)
+			http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	codes, err := api.store.GetDiscountCodes(r.Context(), tenantID, affiliateIDPtr, campaignPtr, activeOnly)
 	if err != nil {
 		logger.Errorf("Failed to get discount codes: %v", err)
-		http.Error(w, "Failed to fetch discount codes", http.StatusInternalServerError)
+		writeError(w, err, "Failed to fetch discount codes")
 		return
 	}
 
@@ -48,10 +83,10 @@ func (api *API) getDiscountCode(w http.ResponseWriter, r *http.Request) {
 
 	logger.Infof("Fetching discount code %s for tenant %s", codeID, tenantID)
 
-	code, err := api.store.GetDiscountCodeByID(tenantID, codeID)
+	code, err := api.store.GetDiscountCodeByID(r.Context(), tenantID, codeID)
 	if err != nil {
 		logger.Errorf("Failed to get discount code: %v", err)
-		http.Error(w, "Discount code not found", http.StatusNotFound)
+		writeError(w, err, "Failed to fetch discount code")
 		return
 	}
 
@@ -76,10 +111,10 @@ func (api *API) validateDiscountCode(w http.ResponseWriter, r *http.Request) {
 
 	logger.Infof("Validating discount code %s for tenant %s", codeStr, tenantID)
 
-	code, err := api.store.GetDiscountCodeByCode(tenantID, codeStr)
+	code, err := api.store.GetDiscountCodeByCode(r.Context(), tenantID, codeStr)
 	if err != nil {
 		logger.Errorf("Failed to validate discount code: %v", err)
-		http.Error(w, "Discount code not found", http.StatusNotFound)
+		writeError(w, err, "Failed to fetch discount code")
 		return
 	}
 
@@ -98,21 +133,69 @@ func (api *API) validateDiscountCode(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// publicDiscountCodeMaxLen bounds the codes the public check will look up
+const publicDiscountCodeMaxLen = 64
+
+// PublicDiscountCodeResult is what a checkout widget learns about a discount code. Usage
+// counts, limits and affiliate linkage stay private.
+type PublicDiscountCodeResult struct {
+	Valid         bool     `json:"valid"`
+	DiscountType  string   `json:"discountType,omitempty"`
+	DiscountValue *float64 `json:"discountValue,omitempty"`
+}
+
+// validateDiscountCodePublic checks a discount code for a tenant's checkout widget (no auth,
+// rate limited per caller and per tenant). Unknown, inactive, expired and used-up codes all
+// answer {"valid": false} so callers cannot tell them apart.
+func (api *API) validateDiscountCodePublic(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	codeStr := strings.TrimSpace(r.URL.Query().Get("code"))
+
+	if codeStr == "" {
+		http.Error(w, "code query parameter required", http.StatusBadRequest)
+		return
+	}
+
+	result := PublicDiscountCodeResult{}
+	if len(codeStr) <= publicDiscountCodeMaxLen {
+		code, err := api.store.GetDiscountCodeByCode(r.Context(), tenantID, codeStr)
+		if err != nil && !errors.Is(err, apperr.ErrNotFound) {
+			logger.Errorf("Failed to validate discount code for tenant %s: %v", tenantID, err)
+			writeError(w, err, "Failed to validate discount code")
+			return
+		}
+		if err == nil && code.IsValid() {
+			result.Valid = true
+			result.DiscountType = code.DiscountType
+			result.DiscountValue = &code.DiscountValue
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Errorf("Failed to encode discount code response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
 // createDiscountCode creates a new discount code for an affiliate (admin only)
 func (api *API) createDiscountCode(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	tenantID := vars["tenantId"]
 
 	type CreateDiscountCodeRequest struct {
-		Code            string   `json:"code"`
-		Description     *string  `json:"description"`
-		DiscountType    string   `json:"discountType"` // PERCENTAGE or FIXED_AMOUNT
-		DiscountValue   float64  `json:"discountValue"`
-		MaxUses         *int     `json:"maxUses"`
-		ValidFrom       *string  `json:"validFrom"`
-		ValidUntil      *string  `json:"validUntil"`
-		AffiliateID     string   `json:"affiliateId"`
-		CommissionRate  *float64 `json:"commissionRate"`
+		Code           string      `json:"code"`
+		Description    *string     `json:"description"`
+		DiscountType   string      `json:"discountType"` // PERCENTAGE or FIXED_AMOUNT
+		DiscountValue  float64     `json:"discountValue"`
+		MaxUses        *int        `json:"maxUses"`
+		ValidFrom      *string     `json:"validFrom"`
+		ValidUntil     *string     `json:"validUntil"`
+		AffiliateID    string      `json:"affiliateId"`
+		CommissionRate *types.Rate `json:"commissionRate"`
 	}
 
 	var input CreateDiscountCodeRequest
@@ -164,19 +247,19 @@ func (api *API) createDiscountCode(w http.ResponseWriter, r *http.Request) {
 
 	// Use affiliate's default commission rate if not specified
 	if discountCode.CommissionRate == nil {
-		affiliate, err := api.store.GetAffiliateByID(tenantID, input.AffiliateID)
+		affiliate, err := api.store.GetAffiliateByID(r.Context(), tenantID, input.AffiliateID)
 		if err != nil {
 			logger.Errorf("Failed to get affiliate: %v", err)
-			http.Error(w, "Affiliate not found", http.StatusNotFound)
+			writeError(w, err, "Failed to fetch affiliate")
 			return
 		}
 		discountCode.CommissionRate = &affiliate.DefaultCommissionRate
 	}
 
-	created, err := api.store.CreateDiscountCode(tenantID, discountCode)
+	created, err := api.store.CreateDiscountCode(r.Context(), tenantID, discountCode)
 	if err != nil {
 		logger.Errorf("Failed to create discount code: %v", err)
-		http.Error(w, "Failed to create discount code", http.StatusInternalServerError)
+		writeError(w, err, "Failed to create discount code")
 		return
 	}
 
@@ -196,15 +279,15 @@ func (api *API) updateDiscountCode(w http.ResponseWriter, r *http.Request) {
 	codeID := vars["codeId"]
 
 	type UpdateDiscountCodeRequest struct {
-		Code           string   `json:"code"`
-		Description    *string  `json:"description"`
-		DiscountType   string   `json:"discountType"`
-		DiscountValue  float64  `json:"discountValue"`
-		MaxUses        *int     `json:"maxUses"`
-		ValidFrom      *string  `json:"validFrom"`
-		ValidUntil     *string  `json:"validUntil"`
-		IsActive       bool     `json:"isActive"`
-		CommissionRate *float64 `json:"commissionRate"`
+		Code           string      `json:"code"`
+		Description    *string     `json:"description"`
+		DiscountType   string      `json:"discountType"`
+		DiscountValue  float64     `json:"discountValue"`
+		MaxUses        *int        `json:"maxUses"`
+		ValidFrom      *string     `json:"validFrom"`
+		ValidUntil     *string     `json:"validUntil"`
+		IsActive       bool        `json:"isActive"`
+		CommissionRate *types.Rate `json:"commissionRate"`
 	}
 
 	var input UpdateDiscountCodeRequest
@@ -227,10 +310,47 @@ func (api *API) updateDiscountCode(w http.ResponseWriter, r *http.Request) {
 		CommissionRate: input.CommissionRate,
 	}
 
-	updated, err := api.store.UpdateDiscountCode(tenantID, codeID, discountCode)
+	updated, err := api.store.UpdateDiscountCode(r.Context(), tenantID, codeID, discountCode)
 	if err != nil {
 		logger.Errorf("Failed to update discount code: %v", err)
-		http.Error(w, "Failed to update discount code", http.StatusInternalServerError)
+		writeError(w, err, "Failed to update discount code")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(updated); err != nil {
+		logger.Errorf("Failed to encode discount code response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// patchDiscountCode changes only the discount code fields present in the request body (admin only)
+func (api *API) patchDiscountCode(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	codeID := vars["codeId"]
+
+	var patch types.DiscountCodePatch
+	if err := json.NewDecoder(r.Body).Decode(&patch); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if patch.IsEmpty() {
+		http.Error(w, "No fields to update", http.StatusBadRequest)
+		return
+	}
+	if msg := patch.Validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Patching discount code %s for tenant %s", codeID, tenantID)
+
+	updated, err := api.store.PatchDiscountCode(r.Context(), tenantID, codeID, &patch)
+	if err != nil {
+		logger.Errorf("Failed to patch discount code: %v", err)
+		writeError(w, err, "Failed to update discount code")
 		return
 	}
 
@@ -250,11 +370,197 @@ func (api *API) deactivateDiscountCode(w http.ResponseWriter, r *http.Request) {
 
 	logger.Infof("Deactivating discount code %s for tenant %s", codeID, tenantID)
 
-	if err := api.store.DeactivateDiscountCode(tenantID, codeID); err != nil {
+	if err := api.store.DeactivateDiscountCode(r.Context(), tenantID, codeID); err != nil {
 		logger.Errorf("Failed to deactivate discount code: %v", err)
-		http.Error(w, "Failed to deactivate discount code", http.StatusInternalServerError)
+		writeError(w, err, "Failed to deactivate discount code")
 		return
 	}
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+const (
+	// maxBulkDiscountCodes caps one bulk generation request
+	maxBulkDiscountCodes = 5000
+	// defaultDiscountSuffixLength gives about 850 billion suffixes per prefix
+	defaultDiscountSuffixLength = 8
+)
+
+var (
+	discountPrefixPattern   = regexp.MustCompile(`^[A-Z0-9-]{0,20}$`)
+	discountCampaignPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9 _.-]{0,99}$`)
+)
+
+// bulkCreateDiscountCodes generates unique codes for a campaign and returns them as CSV (admin only)
+// Codes are single-use unless maxUses is given; affiliateId is optional
+func (api *API) bulkCreateDiscountCodes(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	var input struct {
+		Count          int         `json:"count"`
+		Prefix         string      `json:"prefix"`
+		SuffixLength   int         `json:"suffixLength"`
+		Campaign       string      `json:"campaign"`
+		Description    *string     `json:"description"`
+		DiscountType   string      `json:"discountType"` // PERCENTAGE or FIXED_AMOUNT
+		DiscountValue  float64     `json:"discountValue"`
+		MaxUses        *int        `json:"maxUses"`
+		ValidFrom      *string     `json:"validFrom"`
+		ValidUntil     *string     `json:"validUntil"`
+		AffiliateID    string      `json:"affiliateId"`
+		CommissionRate *types.Rate `json:"commissionRate"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	input.Prefix = strings.ToUpper(strings.TrimSpace(input.Prefix))
+	input.Campaign = strings.TrimSpace(input.Campaign)
+	if input.SuffixLength == 0 {
+		input.SuffixLength = defaultDiscountSuffixLength
+	}
+	if input.MaxUses == nil {
+		singleUse := 1
+		input.MaxUses = &singleUse
+	}
+
+	// Validate required fields
+	if input.Count < 1 || input.Count > maxBulkDiscountCodes {
+		http.Error(w, fmt.Sprintf("count must be between 1 and %d", maxBulkDiscountCodes), http.StatusBadRequest)
+		return
+	}
+	if !discountPrefixPattern.MatchString(input.Prefix) {
+		http.Error(w, "prefix may only contain letters, digits and '-' (max 20)", http.StatusBadRequest)
+		return
+	}
+	if input.SuffixLength < 6 || input.SuffixLength > 16 {
+		http.Error(w, "suffixLength must be between 6 and 16", http.StatusBadRequest)
+		return
+	}
+	if !discountCampaignPattern.MatchString(input.Campaign) {
+		http.Error(w, "campaign is required (letters, digits, spaces, '_', '.', '-'; max 100)", http.StatusBadRequest)
+		return
+	}
+	if input.DiscountType != types.DiscountTypePercentage && input.DiscountType != types.DiscountTypeFixedAmount {
+		http.Error(w, "discountType must be PERCENTAGE or FIXED_AMOUNT", http.StatusBadRequest)
+		return
+	}
+	if input.DiscountValue <= 0 {
+		http.Error(w, "discountValue must be greater than 0", http.StatusBadRequest)
+		return
+	}
+	if *input.MaxUses < 1 {
+		http.Error(w, "maxUses must be at least 1", http.StatusBadRequest)
+		return
+	}
+
+	template := &types.DiscountCode{
+		Description:   input.Description,
+		DiscountType:  input.DiscountType,
+		DiscountValue: input.DiscountValue,
+		MaxUses:       input.MaxUses,
+		ValidFrom:     input.ValidFrom,
+		ValidUntil:    input.ValidUntil,
+		IsActive:      true,
+		Campaign:      &input.Campaign,
+	}
+
+	if input.AffiliateID != "" {
+		affiliateUUID, err := uuid.Parse(input.AffiliateID)
+		if err != nil {
+			http.Error(w, "Invalid affiliate ID", http.StatusBadRequest)
+			return
+		}
+
+		template.IsAffiliateCode = true
+		template.AffiliateID = &affiliateUUID
+		template.CommissionRate = input.CommissionRate
+
+		// Use affiliate's default commission rate if not specified
+		if template.CommissionRate == nil {
+			affiliate, err := api.store.GetAffiliateByID(r.Context(), tenantID, input.AffiliateID)
+			if err != nil {
+				logger.Errorf("Failed to get affiliate: %v", err)
+				writeError(w, err, "Failed to fetch affiliate")
+				return
+			}
+			template.CommissionRate = &affiliate.DefaultCommissionRate
+		}
+	}
+
+	logger.Infof("Generating %d discount codes for campaign %q in tenant %s", input.Count, input.Campaign, tenantID)
+
+	codes, err := api.store.GenerateDiscountCodes(r.Context(), tenantID, template, input.Prefix, input.SuffixLength, input.Count)
+	if err != nil {
+		logger.Errorf("Failed to generate discount codes: %v", err)
+		writeError(w, err, "Failed to generate discount codes")
+		return
+	}
+
+	fileName := fmt.Sprintf("discount-codes-%s", campaignFileName(input.Campaign))
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, fileName))
+	w.WriteHeader(http.StatusCreated)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"code", "campaign", "discount_type", "discount_value", "max_uses", "valid_from", "valid_until"})
+	for _, code := range codes {
+		cw.Write([]string{
+			code.Code,
+			input.Campaign,
+			code.DiscountType,
+			strconv.FormatFloat(code.DiscountValue, 'f', -1, 64),
+			strconv.Itoa(*code.MaxUses),
+			stringOrEmpty(code.ValidFrom),
+			stringOrEmpty(code.ValidUntil),
+		})
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		logger.Errorf("Failed to write discount code CSV: %v", err)
+	}
+}
+
+// getDiscountCampaigns returns usage reports for discount code campaigns (admin only)
+// Query params: campaign (optional, one campaign)
+func (api *API) getDiscountCampaigns(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	var campaignPtr *string
+	if campaign := r.URL.Query().Get("campaign"); campaign != "" {
+		campaignPtr = &campaign
+	}
+
+	reports, err := api.store.GetDiscountCampaignReports(r.Context(), tenantID, campaignPtr)
+	if err != nil {
+		logger.Errorf("Failed to get discount campaign reports: %v", err)
+		writeError(w, err, "Failed to fetch discount campaigns")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(reports); err != nil {
+		logger.Errorf("Failed to encode discount campaigns response: %v", err)
+	}
+}
+
+// campaignFileName turns a campaign label into a safe file name fragment
+func campaignFileName(campaign string) string {
+	return strings.Map(func(r rune) rune {
+		if r == ' ' || r == '.' {
+			return '-'
+		}
+		return r
+	}, strings.ToLower(campaign))
+}
+
+// stringOrEmpty returns the pointed-to string or "" when nil
+func stringOrEmpty(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,128 @@
+package webapi
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/uuid"
+)
+
+func TestPatchDiscountCode(t *testing.T) {
+	description := "Spring promotion"
+	validFrom := "2026-03-01"
+	validUntil := "2026-05-31"
+	maxUses := 100
+	rate := types.Rate(10_00)
+	affiliateID := uuid.New()
+	original := types.DiscountCode{
+		Code:            "SPRING",
+		Description:     &description,
+		DiscountType:    types.DiscountTypePercentage,
+		DiscountValue:   20,
+		MaxUses:         &maxUses,
+		CurrentUses:     7,
+		ValidFrom:       &validFrom,
+		ValidUntil:      &validUntil,
+		IsActive:        true,
+		IsAffiliateCode: true,
+		AffiliateID:     &affiliateID,
+		CommissionRate:  &rate,
+		CreatedAt:       "2026-02-01 10:00:00",
+	}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		change     func(c *types.DiscountCode) // applied to the stored code to get the expected one
+	}{
+		{
+			name:       "one field",
+			body:       `{"description":"Spring sale"}`,
+			wantStatus: http.StatusOK,
+			change: func(c *types.DiscountCode) {
+				d := "Spring sale"
+				c.Description = &d
+			},
+		},
+		{
+			name:       "value only",
+			body:       `{"discountValue":25}`,
+			wantStatus: http.StatusOK,
+			change:     func(c *types.DiscountCode) { c.DiscountValue = 25 },
+		},
+		{
+			name:       "code is upper-cased",
+			body:       `{"code":"spring26"}`,
+			wantStatus: http.StatusOK,
+			change:     func(c *types.DiscountCode) { c.Code = "SPRING26" },
+		},
+		{
+			name:       "deactivate",
+			body:       `{"isActive":false}`,
+			wantStatus: http.StatusOK,
+			change:     func(c *types.DiscountCode) { c.IsActive = false },
+		},
+		{
+			name:       "null is the same as omitted",
+			body:       `{"maxUses":250,"validUntil":null,"commissionRate":null,"isActive":null,"discountValue":null}`,
+			wantStatus: http.StatusOK,
+			change: func(c *types.DiscountCode) {
+				m := 250
+				c.MaxUses = &m
+			},
+		},
+		{
+			name:       "empty strings clear the optional fields",
+			body:       `{"description":"","validUntil":""}`,
+			wantStatus: http.StatusOK,
+			change: func(c *types.DiscountCode) {
+				c.Description = nil
+				c.ValidUntil = nil
+			},
+		},
+		{
+			name:       "only nulls",
+			body:       `{"validUntil":null}`,
+			wantStatus: http.StatusBadRequest,
+		},
+		{
+			name:       "invalid type",
+			body:       `{"discountType":"BOGO"}`,
+			wantStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			_, admin := env.signIn(types.RoleAdmin)
+			stored := original
+			code := env.store.AddDiscountCode(testTenantID, &stored)
+
+			rec := env.do(t, http.MethodPatch, "/api/v1/"+testTenantID+"/discount-codes/"+code.ID.String(), admin, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+
+			got, err := env.store.GetDiscountCodeByID(context.Background(), testTenantID, code.ID.String())
+			if err != nil {
+				t.Fatalf("GetDiscountCodeByID: %v", err)
+			}
+			want := *code
+			if tt.change != nil {
+				tt.change(&want)
+				if got.UpdatedAt == nil {
+					t.Error("UpdatedAt was not set")
+				}
+				want.UpdatedAt = got.UpdatedAt
+			}
+			if !reflect.DeepEqual(*got, want) {
+				t.Errorf("discount code = %+v, want %+v", *got, want)
+			}
+		})
+	}
+}
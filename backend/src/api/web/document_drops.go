@@ -0,0 +1,241 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// getDocumentDrops lists a tenant's document drop locations (admin only)
+func (api *API) getDocumentDrops(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	drops, err := api.store.GetDocumentDrops(tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch document drops")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(drops); err != nil {
+		logger.Errorf("Failed to encode document drops response: %v", err)
+	}
+}
+
+// createDocumentDrop registers a GCS bucket or SFTP directory a partner delivers batches to (admin only)
+func (api *API) createDocumentDrop(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var req struct {
+		Name         string `json:"name"`
+		SourceType   string `json:"sourceType"`
+		Location     string `json:"location"`
+		Prefix       string `json:"prefix"`
+		DocumentType string `json:"documentType"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := api.store.GetTenantConfig(tenantID); err != nil {
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	drop := &types.DocumentDrop{
+		TenantID:     tenantID,
+		Name:         req.Name,
+		SourceType:   req.SourceType,
+		Location:     req.Location,
+		Prefix:       req.Prefix,
+		DocumentType: req.DocumentType,
+		CreatedBy:    &employee.ID,
+	}
+	if err := api.ingester.ValidateDrop(drop); err != nil {
+		writeError(w, err, "Invalid document drop")
+		return
+	}
+	if err := api.store.CreateDocumentDrop(drop); err != nil {
+		writeError(w, err, "Failed to create document drop")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(drop); err != nil {
+		logger.Errorf("Failed to encode document drop response: %v", err)
+	}
+}
+
+// deactivateDocumentDrop stops scanning a drop (admin only)
+func (api *API) deactivateDocumentDrop(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	dropID, err := uuid.Parse(vars["dropId"])
+	if err != nil {
+		http.Error(w, "Invalid drop ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.store.DeactivateDocumentDrop(vars["tenantId"], dropID); err != nil {
+		writeError(w, err, "Failed to deactivate document drop")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// scanDocumentDrop scans a drop now instead of waiting for the document_drop_scan job (admin only)
+func (api *API) scanDocumentDrop(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	dropID, err := uuid.Parse(vars["dropId"])
+	if err != nil {
+		http.Error(w, "Invalid drop ID", http.StatusBadRequest)
+		return
+	}
+
+	drop, err := api.store.GetDocumentDrop(vars["tenantId"], dropID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch document drop")
+		return
+	}
+
+	scan, err := api.ingester.Scan(r.Context(), drop)
+	if err != nil {
+		logger.Errorf("Failed to scan document drop %s: %v", dropID, err)
+		writeError(w, err, "Failed to scan document drop")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(scan); err != nil {
+		logger.Errorf("Failed to encode document drop scan response: %v", err)
+	}
+}
+
+// getDocumentDropFiles lists files taken from a tenant's drops (admin only)
+// Optional query: ?status=UNMATCHED&limit=100
+func (api *API) getDocumentDropFiles(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	status := r.URL.Query().Get("status")
+	switch status {
+	case "", types.DropFileStatusImported, types.DropFileStatusUnmatched, types.DropFileStatusResolved, types.DropFileStatusIgnored:
+	default:
+		http.Error(w, "Invalid status parameter", http.StatusBadRequest)
+		return
+	}
+
+	limit := 100
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, 500)
+	}
+
+	files, err := api.store.GetDocumentDropFiles(tenantID, status, limit)
+	if err != nil {
+		writeError(w, err, "Failed to fetch document drop files")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(files); err != nil {
+		logger.Errorf("Failed to encode document drop files response: %v", err)
+	}
+}
+
+// resolveDocumentDropFile imports an unmatched file for the client and filing the admin picked (admin only)
+func (api *API) resolveDocumentDropFile(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	fileID, err := uuid.Parse(vars["fileId"])
+	if err != nil {
+		http.Error(w, "Invalid file ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		ClientID     uuid.UUID `json:"clientId"`
+		FilingID     uuid.UUID `json:"filingId"`
+		DocumentType string    `json:"documentType"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.ClientID == uuid.Nil || req.FilingID == uuid.Nil {
+		http.Error(w, "clientId and filingId are required", http.StatusBadRequest)
+		return
+	}
+
+	file, err := api.ingester.Resolve(r.Context(), vars["tenantId"], fileID, req.ClientID, req.FilingID, req.DocumentType, employee.ID)
+	if err != nil {
+		logger.Errorf("Failed to resolve document drop file %s: %v", fileID, err)
+		writeError(w, err, "Failed to resolve document drop file")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(file); err != nil {
+		logger.Errorf("Failed to encode document drop file response: %v", err)
+	}
+}
+
+// ignoreDocumentDropFile dismisses an unmatched file without importing it (admin only)
+func (api *API) ignoreDocumentDropFile(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	fileID, err := uuid.Parse(vars["fileId"])
+	if err != nil {
+		http.Error(w, "Invalid file ID", http.StatusBadRequest)
+		return
+	}
+
+	file, err := api.store.GetDocumentDropFile(vars["tenantId"], fileID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch document drop file")
+		return
+	}
+
+	file.Status = types.DropFileStatusIgnored
+	if err := api.store.ResolveDocumentDropFile(file, employee.ID); err != nil {
+		writeError(w, err, "Failed to ignore document drop file")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(file); err != nil {
+		logger.Errorf("Failed to encode document drop file response: %v", err)
+	}
+}
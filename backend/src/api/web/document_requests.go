@@ -0,0 +1,252 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// defaultExpiringDays is the window the expiring documents list uses when days is not given
+const defaultExpiringDays = 30
+
+// setDocumentExpiry sets or clears the expiry date of a filing document (admin only)
+func (api *API) setDocumentExpiry(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		ExpiresOn *string `json:"expiresOn"` // YYYY-MM-DD, or null to clear
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	vars := mux.Vars(r)
+	document, err := api.store.GetDocumentByID(r.Context(), vars["tenantId"], vars["documentId"])
+	if err != nil {
+		writeError(w, err, "Failed to fetch document")
+		return
+	}
+
+	expiration, err := api.store.SetDocumentExpiry(vars["tenantId"], document, req.ExpiresOn, employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to set document expiry")
+		return
+	}
+	if expiration == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(expiration); err != nil {
+		logger.Errorf("Failed to encode document expiry response: %v", err)
+	}
+}
+
+// getExpiringDocuments lists documents expiring within ?days= days, including expired ones (admin only)
+func (api *API) getExpiringDocuments(w http.ResponseWriter, r *http.Request) {
+	days := defaultExpiringDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 366 {
+			http.Error(w, "days must be between 0 and 366", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+
+	expiring, err := api.store.GetExpiringDocuments(mux.Vars(r)["tenantId"], days)
+	if err != nil {
+		writeError(w, err, "Failed to fetch expiring documents")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(expiring); err != nil {
+		logger.Errorf("Failed to encode expiring documents response: %v", err)
+	}
+}
+
+// getClientDocumentRequests returns a client's document requests as a checklist (admin only)
+func (api *API) getClientDocumentRequests(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clientID, err := uuid.Parse(vars["clientId"])
+	if err != nil {
+		http.Error(w, "Invalid client ID", http.StatusBadRequest)
+		return
+	}
+
+	requests, err := api.store.GetClientDocumentRequests(vars["tenantId"], clientID, false)
+	if err != nil {
+		writeError(w, err, "Failed to fetch document requests")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(types.NewDocumentChecklist(requests)); err != nil {
+		logger.Errorf("Failed to encode document requests response: %v", err)
+	}
+}
+
+// createDocumentRequest asks a client for a document (admin only); the client is reminded by
+// the document expiry job until it is uploaded
+func (api *API) createDocumentRequest(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	clientID, err := uuid.Parse(vars["clientId"])
+	if err != nil {
+		http.Error(w, "Invalid client ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		DocumentType string     `json:"documentType"`
+		Description  string     `json:"description"`
+		DueOn        *string    `json:"dueOn"`    // YYYY-MM-DD
+		FilingID     *uuid.UUID `json:"filingId"` // Filing the document is for, if any
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.DocumentType = strings.TrimSpace(req.DocumentType)
+	req.Description = strings.TrimSpace(req.Description)
+	if req.DocumentType == "" || req.Description == "" {
+		http.Error(w, "documentType and description are required", http.StatusBadRequest)
+		return
+	}
+	if req.DueOn != nil {
+		if _, err := time.Parse("2006-01-02", *req.DueOn); err != nil {
+			http.Error(w, "dueOn must be a YYYY-MM-DD date", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// The client must exist in the tenant database before they can be asked for anything
+	if _, err := api.store.GetClientByID(r.Context(), vars["tenantId"], clientID.String()); err != nil {
+		writeError(w, err, "Failed to fetch client")
+		return
+	}
+	if req.FilingID != nil {
+		filing, err := api.store.GetFilingWorkflow(r.Context(), vars["tenantId"], req.FilingID.String())
+		if err != nil {
+			writeError(w, err, "Failed to fetch filing")
+			return
+		}
+		if filing.ClientID != clientID {
+			http.Error(w, "filingId must be a filing of the client", http.StatusBadRequest)
+			return
+		}
+	}
+
+	request, err := api.store.CreateDocumentRequest(&types.DocumentRequest{
+		TenantID:     vars["tenantId"],
+		ClientID:     clientID,
+		FilingID:     req.FilingID,
+		DocumentType: req.DocumentType,
+		Description:  req.Description,
+		DueOn:        req.DueOn,
+		RequestedBy:  &employee.ID,
+	})
+	if err != nil {
+		writeError(w, err, "Failed to create document request")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(request); err != nil {
+		logger.Errorf("Failed to encode document request response: %v", err)
+	}
+}
+
+// cancelDocumentRequest withdraws an open document request (admin only)
+func (api *API) cancelDocumentRequest(w http.ResponseWriter, r *http.Request) {
+	api.closeDocumentRequest(w, r, types.DocumentRequestCancelled)
+}
+
+// fulfillDocumentRequest marks an open document request fulfilled by hand, e.g. when the
+// document arrived by mail (admin only)
+func (api *API) fulfillDocumentRequest(w http.ResponseWriter, r *http.Request) {
+	api.closeDocumentRequest(w, r, types.DocumentRequestFulfilled)
+}
+
+func (api *API) closeDocumentRequest(w http.ResponseWriter, r *http.Request, status string) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	requestID, err := uuid.Parse(vars["requestId"])
+	if err != nil {
+		http.Error(w, "Invalid request ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		DocumentID *uuid.UUID `json:"documentId"` // Document that fulfilled the request, if any
+	}
+	if status == types.DocumentRequestFulfilled && r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	request, err := api.store.CloseDocumentRequest(vars["tenantId"], requestID, status, req.DocumentID, employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to update document request")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(request); err != nil {
+		logger.Errorf("Failed to encode document request response: %v", err)
+	}
+}
+
+// getPortalDocumentRequests lists the documents the firm is waiting for from the user (tenant user only)
+func (api *API) getPortalDocumentRequests(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	requests := []*types.DocumentRequest{}
+	if tenantUser.ClientID != NewClientUUID {
+		var err error
+		requests, err = api.store.GetClientDocumentRequests(tenantUser.TenantID, tenantUser.ClientID, true)
+		if err != nil {
+			writeError(w, err, "Failed to fetch document requests")
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(types.NewDocumentChecklist(requests)); err != nil {
+		logger.Errorf("Failed to encode document requests response: %v", err)
+	}
+}
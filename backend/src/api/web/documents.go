@@ -5,12 +5,14 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"path/filepath"
 	"strings"
 	"time"
+	"welltaxpro/src/internal/scan"
 	"welltaxpro/src/internal/storage"
 	"welltaxpro/src/internal/types"
 
@@ -34,6 +36,11 @@ func (api *API) uploadDocument(w http.ResponseWriter, r *http.Request) {
 	// Parse multipart form with max size
 	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
 		logger.Errorf("Failed to parse multipart form: %v", err)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "File too large", http.StatusRequestEntityTooLarge)
+			return
+		}
 		http.Error(w, "File too large or invalid form data", http.StatusBadRequest)
 		return
 	}
@@ -75,12 +82,12 @@ func (api *API) uploadDocument(w http.ResponseWriter, r *http.Request) {
 	tc, err := api.store.GetTenantConfig(tenantID)
 	if err != nil {
 		logger.Errorf("Failed to get tenant config: %v", err)
-		http.Error(w, "Failed to get tenant configuration", http.StatusInternalServerError)
+		writeError(w, err, "Failed to get tenant configuration")
 		return
 	}
 
 	// Create storage provider using factory (handles Secret Manager, file, or ADC)
-	storageProvider, err := storage.NewStorageProviderForTenant(context.Background(), tc)
+	storageProvider, err := api.storageForTenant(context.Background(), tc)
 	if err != nil {
 		logger.Errorf("Failed to create storage provider: %v", err)
 		http.Error(w, "Failed to initialize storage", http.StatusInternalServerError)
@@ -104,8 +111,7 @@ func (api *API) uploadDocument(w http.ResponseWriter, r *http.Request) {
 	baseName := strings.TrimSuffix(header.Filename, ext)
 	storagePath := fmt.Sprintf("%s/%s/%s_%s%s", userID, documentType, baseName, fileHash, ext)
 
-	// Upload to GCS
-	fileReader := strings.NewReader(string(fileBytes))
+	// Scan and upload; infected files go to the quarantine prefix
 	metadata := map[string]string{
 		"tenant_id":     tenantID,
 		"filing_id":     filingID,
@@ -114,28 +120,16 @@ func (api *API) uploadDocument(w http.ResponseWriter, r *http.Request) {
 		"original_name": header.Filename,
 	}
 
-	if err := storageProvider.Upload(context.Background(), tc.StorageBucket, storagePath, fileReader, metadata); err != nil {
-		logger.Errorf("Failed to upload to storage: %v", err)
-		http.Error(w, "Failed to upload file", http.StatusInternalServerError)
-		return
-	}
-
-	// Create document record in database
-	document := &types.Document{
+	createdDoc, err := api.createScannedDocument(r.Context(), tc, storageProvider, &types.Document{
 		ID:       uuid.New(),
 		UserID:   userUUID,
 		FilingID: &filingUUID,
 		Name:     header.Filename,
 		FilePath: storagePath,
 		Type:     documentType,
-	}
-
-	createdDoc, err := api.store.CreateDocument(tenantID, document)
+	}, fileBytes, metadata)
 	if err != nil {
-		logger.Errorf("Failed to create document record: %v", err)
-		// Try to clean up uploaded file
-		storageProvider.Delete(context.Background(), tc.StorageBucket, storagePath)
-		http.Error(w, "Failed to create document record", http.StatusInternalServerError)
+		http.Error(w, "Failed to upload document", http.StatusInternalServerError)
 		return
 	}
 
@@ -148,6 +142,36 @@ func (api *API) uploadDocument(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// createScannedDocument scans a file, stores it at the document's path (or under the quarantine
+// prefix when it is infected) and creates the document record with its scan
+func (api *API) createScannedDocument(ctx context.Context, tc *types.TenantConnection, provider storage.StorageProvider, document *types.Document, data []byte, metadata map[string]string) (*types.Document, error) {
+	storedPath, scanResult, err := scan.Upload(ctx, api.scanner, provider, tc.StorageBucket, document.FilePath, data, metadata)
+	if err != nil {
+		logger.Errorf("Failed to upload to storage: %v", err)
+		return nil, err
+	}
+	document.FilePath = storedPath
+
+	created, err := api.store.CreateDocument(ctx, tc.TenantID, document)
+	if err != nil {
+		logger.Errorf("Failed to create document record: %v", err)
+		// Try to clean up uploaded file
+		provider.Delete(context.Background(), tc.StorageBucket, storedPath)
+		return nil, err
+	}
+
+	if scanResult != nil {
+		if _, err := api.store.RecordDocumentScan(tc.TenantID, created.ID, scanResult); err != nil {
+			// An unrecorded scan would leave the document downloadable
+			api.store.DeleteDocument(ctx, tc.TenantID, created.ID.String())
+			provider.Delete(context.Background(), tc.StorageBucket, storedPath)
+			return nil, err
+		}
+		created.ScanStatus = &scanResult.Status
+	}
+	return created, nil
+}
+
 // getDocuments returns all documents for a filing (admin only)
 func (api *API) getDocuments(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -156,10 +180,10 @@ func (api *API) getDocuments(w http.ResponseWriter, r *http.Request) {
 
 	logger.Infof("Fetching documents for filing %s in tenant %s", filingID, tenantID)
 
-	documents, err := api.store.GetDocumentsByFilingID(tenantID, filingID)
+	documents, err := api.store.GetDocumentsByFilingID(r.Context(), tenantID, filingID)
 	if err != nil {
 		logger.Errorf("Failed to get documents: %v", err)
-		http.Error(w, "Failed to fetch documents", http.StatusInternalServerError)
+		writeError(w, err, "Failed to fetch documents")
 		return
 	}
 
@@ -179,10 +203,16 @@ func (api *API) downloadDocument(w http.ResponseWriter, r *http.Request) {
 	logger.Infof("Download request for document %s in tenant %s", documentID, tenantID)
 
 	// Get document record
-	document, err := api.store.GetDocumentByID(tenantID, documentID)
+	document, err := api.store.GetDocumentByID(r.Context(), tenantID, documentID)
 	if err != nil {
 		logger.Errorf("Failed to get document: %v", err)
-		http.Error(w, "Document not found", http.StatusNotFound)
+		writeError(w, err, "Failed to fetch document")
+		return
+	}
+
+	// Infected and not yet scanned files are not served
+	if err := api.store.CheckDocumentScan(tenantID, documentID); err != nil {
+		writeError(w, err, "Failed to check document scan")
 		return
 	}
 
@@ -190,12 +220,12 @@ func (api *API) downloadDocument(w http.ResponseWriter, r *http.Request) {
 	tc, err := api.store.GetTenantConfig(tenantID)
 	if err != nil {
 		logger.Errorf("Failed to get tenant config: %v", err)
-		http.Error(w, "Failed to get tenant configuration", http.StatusInternalServerError)
+		writeError(w, err, "Failed to get tenant configuration")
 		return
 	}
 
 	// Create storage provider using factory (handles Secret Manager, file, or ADC)
-	storageProvider, err := storage.NewStorageProviderForTenant(context.Background(), tc)
+	storageProvider, err := api.storageForTenant(context.Background(), tc)
 	if err != nil {
 		logger.Errorf("Failed to create storage provider: %v", err)
 		http.Error(w, "Failed to initialize storage", http.StatusInternalServerError)
@@ -230,10 +260,10 @@ func (api *API) deleteDocument(w http.ResponseWriter, r *http.Request) {
 	logger.Infof("Delete request for document %s in tenant %s", documentID, tenantID)
 
 	// Get document record first (need file path for storage deletion)
-	document, err := api.store.GetDocumentByID(tenantID, documentID)
+	document, err := api.store.GetDocumentByID(r.Context(), tenantID, documentID)
 	if err != nil {
 		logger.Errorf("Failed to get document: %v", err)
-		http.Error(w, "Document not found", http.StatusNotFound)
+		writeError(w, err, "Failed to fetch document")
 		return
 	}
 
@@ -241,12 +271,12 @@ func (api *API) deleteDocument(w http.ResponseWriter, r *http.Request) {
 	tc, err := api.store.GetTenantConfig(tenantID)
 	if err != nil {
 		logger.Errorf("Failed to get tenant config: %v", err)
-		http.Error(w, "Failed to get tenant configuration", http.StatusInternalServerError)
+		writeError(w, err, "Failed to get tenant configuration")
 		return
 	}
 
 	// Create storage provider using factory (handles Secret Manager, file, or ADC)
-	storageProvider, err := storage.NewStorageProviderForTenant(context.Background(), tc)
+	storageProvider, err := api.storageForTenant(context.Background(), tc)
 	if err != nil {
 		logger.Errorf("Failed to create storage provider: %v", err)
 		http.Error(w, "Failed to initialize storage", http.StatusInternalServerError)
@@ -259,10 +289,17 @@ func (api *API) deleteDocument(w http.ResponseWriter, r *http.Request) {
 		// Continue anyway - database record is more important
 	}
 
+	// A file found infected after upload was moved to quarantine
+	if scanned, err := api.store.GetDocumentScan(tenantID, documentID); err == nil && scanned.QuarantinePath != nil && *scanned.QuarantinePath != document.FilePath {
+		if err := storageProvider.Delete(context.Background(), tc.StorageBucket, *scanned.QuarantinePath); err != nil {
+			logger.Errorf("Failed to delete quarantined file from storage: %v", err)
+		}
+	}
+
 	// Delete database record
-	if err := api.store.DeleteDocument(tenantID, documentID); err != nil {
+	if err := api.store.DeleteDocument(r.Context(), tenantID, documentID); err != nil {
 		logger.Errorf("Failed to delete document record: %v", err)
-		http.Error(w, "Failed to delete document", http.StatusInternalServerError)
+		writeError(w, err, "Failed to delete document")
 		return
 	}
 
@@ -0,0 +1,23 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/logger"
+)
+
+// getEmailQueueStats returns this API process's email send queue depth and counters (admin only)
+func (api *API) getEmailQueueStats(w http.ResponseWriter, r *http.Request) {
+	if api.emailService == nil {
+		http.Error(w, "Email service is not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(api.emailService.QueueStats()); err != nil {
+		logger.Errorf("Failed to encode email queue stats: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
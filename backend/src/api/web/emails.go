@@ -0,0 +1,195 @@
+package webapi
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/notification"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// sendGridEvent is the part of a SendGrid event webhook event used to suppress addresses
+type sendGridEvent struct {
+	Email    string `json:"email"`
+	Event    string `json:"event"`  // bounce, spamreport, delivered, ...
+	Type     string `json:"type"`   // For bounce: bounce (hard) or blocked (temporary)
+	Reason   string `json:"reason"` // For bounce: the receiving server's response
+	OutboxID string `json:"outbox_id"`
+}
+
+// getOutboxEmails returns the latest queued and sent client emails, newest first (admin only)
+// Query params: tenantId, kind (PORTAL_LINK, FILING_COMPLETED or FILING_STATUS),
+// status (PENDING, SENT, FAILED, SUPPRESSED or BOUNCED), to, limit (1-500, default 100)
+func (api *API) getOutboxEmails(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &types.OutboxEmailFilter{Limit: 100}
+
+	if tenantID := query.Get("tenantId"); tenantID != "" {
+		filter.TenantID = &tenantID
+	}
+	if kind := strings.ToUpper(query.Get("kind")); kind != "" {
+		switch kind {
+		case types.OutboxEmailPortalLink, types.OutboxEmailFilingCompleted, types.OutboxEmailFilingStatus:
+		default:
+			http.Error(w, "kind must be PORTAL_LINK, FILING_COMPLETED or FILING_STATUS", http.StatusBadRequest)
+			return
+		}
+		filter.Kind = &kind
+	}
+	if status := strings.ToUpper(query.Get("status")); status != "" {
+		switch status {
+		case types.OutboxEmailPending, types.OutboxEmailSent, types.OutboxEmailFailed, types.OutboxEmailSuppressed, types.OutboxEmailBounced:
+		default:
+			http.Error(w, "status must be PENDING, SENT, FAILED, SUPPRESSED or BOUNCED", http.StatusBadRequest)
+			return
+		}
+		filter.Status = &status
+	}
+	if to := strings.TrimSpace(query.Get("to")); to != "" {
+		filter.ToEmail = &to
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 500 {
+			http.Error(w, "limit must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	emails, err := api.store.GetOutboxEmails(filter)
+	if err != nil {
+		writeError(w, err, "Failed to fetch emails")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(emails); err != nil {
+		logger.Errorf("Failed to encode emails response: %v", err)
+	}
+}
+
+// getEmailSuppressions lists the addresses queued email is not sent to (admin only)
+func (api *API) getEmailSuppressions(w http.ResponseWriter, r *http.Request) {
+	suppressions, err := api.store.GetEmailSuppressions()
+	if err != nil {
+		writeError(w, err, "Failed to fetch email suppressions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(suppressions); err != nil {
+		logger.Errorf("Failed to encode email suppressions response: %v", err)
+	}
+}
+
+// addEmailSuppression stops queued email to an address, such as one a client asked not to be
+// emailed at (admin only)
+func (api *API) addEmailSuppression(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var input types.EmailSuppression
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := input.Validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	input.CreatedBy = &employee.ID
+
+	suppression, err := api.store.AddEmailSuppression(&input)
+	if err != nil {
+		writeError(w, err, "Failed to suppress email")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(suppression); err != nil {
+		logger.Errorf("Failed to encode email suppression response: %v", err)
+	}
+}
+
+// deleteEmailSuppression lets queued email reach an address again (admin only)
+func (api *API) deleteEmailSuppression(w http.ResponseWriter, r *http.Request) {
+	if err := api.store.DeleteEmailSuppression(mux.Vars(r)["email"]); err != nil {
+		writeError(w, err, "Failed to remove email suppression")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// receiveEmailEvents accepts a SendGrid Event Webhook post. Hard bounces and spam reports add the
+// address to the suppression list, and a bounce marks the outbox email it answers as BOUNCED.
+// Other events are ignored.
+func (api *API) receiveEmailEvents(w http.ResponseWriter, r *http.Request) {
+	if api.inbound.EventWebhookKey == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("key")), []byte(api.inbound.EventWebhookKey)) != 1 {
+		logger.Warningf("Rejected email event post with invalid key from %s", r.RemoteAddr)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var events []sendGridEvent
+	if err := json.NewDecoder(r.Body).Decode(&events); err != nil {
+		http.Error(w, "Invalid events", http.StatusBadRequest)
+		return
+	}
+
+	for _, event := range events {
+		var reason string
+		switch {
+		case event.Event == "bounce" && event.Type != "blocked":
+			reason = types.EmailSuppressionBounce
+		case event.Event == "spamreport":
+			reason = types.EmailSuppressionSpamReport
+		default:
+			continue
+		}
+		if event.Email == "" {
+			continue
+		}
+
+		suppression := &types.EmailSuppression{Email: event.Email, Reason: reason}
+		if event.Reason != "" {
+			suppression.Detail = &event.Reason
+		}
+		// A failure is answered with 500 so SendGrid posts the batch again
+		if _, err := api.store.AddEmailSuppression(suppression); err != nil {
+			http.Error(w, "Failed to record email events", http.StatusInternalServerError)
+			return
+		}
+
+		if reason != types.EmailSuppressionBounce || event.OutboxID == "" {
+			continue
+		}
+		outboxID, err := uuid.Parse(event.OutboxID)
+		if err != nil {
+			logger.Warningf("Ignoring bounce with invalid %s %q", notification.OutboxIDArg, event.OutboxID)
+			continue
+		}
+		if err := api.store.MarkEmailBounced(outboxID, event.Reason); err != nil {
+			http.Error(w, "Failed to record email events", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -3,7 +3,10 @@ package webapi
 import (
 	"encoding/json"
 	"net/http"
+	"strings"
+	"time"
 	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
 	"github.com/google/uuid"
@@ -55,7 +58,7 @@ func (api *API) getAllEmployees(w http.ResponseWriter, r *http.Request) {
 	employees, err := api.store.GetAllEmployees(includeInactive)
 	if err != nil {
 		logger.Errorf("Failed to get employees: %v", err)
-		http.Error(w, "Failed to fetch employees", http.StatusInternalServerError)
+		writeError(w, err, "Failed to fetch employees")
 		return
 	}
 
@@ -84,7 +87,7 @@ func (api *API) getEmployeeByID(w http.ResponseWriter, r *http.Request) {
 	employee, err := api.store.GetEmployeeByID(employeeID)
 	if err != nil {
 		logger.Errorf("Failed to get employee: %v", err)
-		http.Error(w, "Employee not found", http.StatusNotFound)
+		writeError(w, err, "Failed to fetch employee")
 		return
 	}
 
@@ -122,12 +125,13 @@ func (api *API) createEmployee(w http.ResponseWriter, r *http.Request) {
 
 	// Validate role
 	validRoles := map[string]bool{
-		"admin":      true,
-		"accountant": true,
-		"support":    true,
+		"admin":             true,
+		"accountant":        true,
+		"support":           true,
+		"affiliate_manager": true,
 	}
 	if !validRoles[req.Role] {
-		http.Error(w, "Invalid role. Must be one of: admin, accountant, support", http.StatusBadRequest)
+		http.Error(w, "Invalid role. Must be one of: admin, accountant, support, affiliate_manager", http.StatusBadRequest)
 		return
 	}
 
@@ -157,7 +161,7 @@ func (api *API) createEmployee(w http.ResponseWriter, r *http.Request) {
 	employee, err := api.store.CreateEmployee(req.FirebaseUID, req.Email, req.FirstName, req.LastName, req.Role)
 	if err != nil {
 		logger.Errorf("Failed to create employee: %v", err)
-		http.Error(w, "Failed to create employee", http.StatusInternalServerError)
+		writeError(w, err, "Failed to create employee")
 		return
 	}
 
@@ -198,7 +202,8 @@ func (api *API) getMe(w http.ResponseWriter, r *http.Request) {
 }
 
 // updateEmployee handles PUT /api/v1/employees/me
-// This endpoint allows an employee to update their own information
+// This endpoint allows an employee to update their own name. updatedAt must be the value last
+// read; the update is refused with 409 when the profile changed since.
 func (api *API) updateEmployee(w http.ResponseWriter, r *http.Request) {
 	// Get employee from context
 	employee, ok := middleware.GetEmployeeFromContext(r.Context())
@@ -210,8 +215,9 @@ func (api *API) updateEmployee(w http.ResponseWriter, r *http.Request) {
 
 	// Parse request body
 	var updateReq struct {
-		FirstName *string `json:"firstName,omitempty"`
-		LastName  *string `json:"lastName,omitempty"`
+		FirstName *string   `json:"firstName,omitempty"`
+		LastName  *string   `json:"lastName,omitempty"`
+		UpdatedAt time.Time `json:"updatedAt"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&updateReq); err != nil {
@@ -219,19 +225,207 @@ func (api *API) updateEmployee(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
 		return
 	}
+	if updateReq.UpdatedAt.IsZero() {
+		http.Error(w, "updatedAt is required", http.StatusBadRequest)
+		return
+	}
+
+	// The context employee carries the tenant context's role, so the stored record is the
+	// source for the role and for names left out of the request
+	current, err := api.store.GetEmployeeByID(employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch employee")
+		return
+	}
+
+	firstName, lastName := current.FirstName, current.LastName
+	if updateReq.FirstName != nil {
+		firstName = trimmedOrNil(*updateReq.FirstName)
+	}
+	if updateReq.LastName != nil {
+		lastName = trimmedOrNil(*updateReq.LastName)
+	}
 
-	// For now, we'll just return the current employee
-	// TODO: Implement employee update functionality in the store
-	logger.Infof("Employee %s requested profile update", employee.Email)
+	updated, err := api.store.UpdateEmployee(current.ID, firstName, lastName, current.Role, updateReq.UpdatedAt)
+	if err != nil {
+		writeError(w, err, "Failed to update employee")
+		return
+	}
+	logger.Infof("Employee %s updated their profile", updated.Email)
 
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(employee); err != nil {
+	if err := json.NewEncoder(w).Encode(updated); err != nil {
 		logger.Errorf("Failed to encode employee response: %v", err)
 		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
 		return
 	}
 }
 
+// updateEmployeeRole handles PUT /api/v1/employees/{employeeId}
+// Changes an employee's role (admin only). updatedAt must be the value last read; the change is
+// refused with 409 when the employee changed since. The change is audited in every tenant the
+// employee has access to.
+func (api *API) updateEmployeeRole(w http.ResponseWriter, r *http.Request) {
+	currentEmployee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	employeeID, err := uuid.Parse(mux.Vars(r)["employeeId"])
+	if err != nil {
+		http.Error(w, "Invalid employee ID format", http.StatusBadRequest)
+		return
+	}
+	if employeeID == currentEmployee.ID {
+		http.Error(w, "Admins cannot change their own role", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Role      string    `json:"role"`
+		UpdatedAt time.Time `json:"updatedAt"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.UpdatedAt.IsZero() {
+		http.Error(w, "updatedAt is required", http.StatusBadRequest)
+		return
+	}
+
+	// Validate role
+	validRoles := map[string]bool{
+		"admin":             true,
+		"accountant":        true,
+		"viewer":            true,
+		"affiliate_manager": true,
+	}
+	if !validRoles[req.Role] {
+		http.Error(w, "Invalid role. Must be one of: admin, accountant, viewer, affiliate_manager", http.StatusBadRequest)
+		return
+	}
+
+	target, err := api.store.GetEmployeeByID(employeeID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch employee")
+		return
+	}
+
+	updated, err := api.store.UpdateEmployee(target.ID, target.FirstName, target.LastName, req.Role, req.UpdatedAt)
+	if err != nil {
+		writeError(w, err, "Failed to update employee")
+		return
+	}
+	logger.Infof("Admin %s changed role of employee %s from %s to %s",
+		currentEmployee.Email, employeeID, target.Role, updated.Role)
+
+	if target.Role != updated.Role {
+		tenantIDs, err := api.store.GetEmployeeTenantIDs(employeeID)
+		if err != nil {
+			logger.Errorf("Failed to list tenants to audit role change of employee %s: %v", employeeID, err)
+		}
+		ipAddress := middleware.ClientIP(r)
+		userAgent := r.UserAgent()
+		details := map[string]interface{}{
+			"previousRole": target.Role,
+			"role":         updated.Role,
+		}
+		for _, tenantID := range tenantIDs {
+			if err := api.store.CreateAuditLog(currentEmployee.ID, tenantID, nil, types.AuditActionEdit, types.AuditResourceEmployee, &updated.ID, details, &ipAddress, &userAgent); err != nil {
+				logger.Errorf("Failed to audit role change of employee %s in tenant %s: %v", employeeID, tenantID, err)
+			}
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(updated); err != nil {
+		logger.Errorf("Failed to encode employee response: %v", err)
+	}
+}
+
+// deactivateEmployee handles DELETE /api/v1/employees/{employeeId}
+// Deactivates an employee (admin only): their sessions are revoked, their Firebase account is
+// disabled and its refresh tokens revoked, so they are signed out everywhere. Answers 502 when
+// Firebase could not be updated; the employee stays deactivated and the call can be retried.
+func (api *API) deactivateEmployee(w http.ResponseWriter, r *http.Request) {
+	currentEmployee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	employeeID, err := uuid.Parse(mux.Vars(r)["employeeId"])
+	if err != nil {
+		http.Error(w, "Invalid employee ID format", http.StatusBadRequest)
+		return
+	}
+	if employeeID == currentEmployee.ID {
+		http.Error(w, "Admins cannot deactivate themselves", http.StatusBadRequest)
+		return
+	}
+
+	target, err := api.store.GetEmployeeByID(employeeID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch employee")
+		return
+	}
+
+	if err := api.store.DeactivateEmployee(employeeID); err != nil {
+		writeError(w, err, "Failed to deactivate employee")
+		return
+	}
+	revoked, err := api.store.RevokeEmployeeSessions(employeeID, &currentEmployee.ID, types.SessionRevokeAdmin)
+	if err != nil {
+		writeError(w, err, "Failed to revoke employee sessions")
+		return
+	}
+
+	firebaseErr := api.firebaseUsers.DisableUser(r.Context(), target.FirebaseUID)
+	if firebaseErr == nil {
+		firebaseErr = api.firebaseUsers.RevokeRefreshTokens(r.Context(), target.FirebaseUID)
+	}
+	if firebaseErr != nil {
+		logger.Errorf("Failed to sign out deactivated employee %s from Firebase: %v", employeeID, firebaseErr)
+	}
+	logger.Infof("Admin %s deactivated employee %s (%d sessions revoked)", currentEmployee.Email, target.Email, len(revoked))
+
+	tenantIDs, err := api.store.GetEmployeeTenantIDs(employeeID)
+	if err != nil {
+		logger.Errorf("Failed to list tenants to audit deactivation of employee %s: %v", employeeID, err)
+	}
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	details := map[string]interface{}{
+		"wasActive":        target.IsActive,
+		"sessionsRevoked":  len(revoked),
+		"firebaseDisabled": firebaseErr == nil,
+	}
+	for _, tenantID := range tenantIDs {
+		if err := api.store.CreateAuditLog(currentEmployee.ID, tenantID, nil, types.AuditActionRevoke, types.AuditResourceEmployee, &target.ID, details, &ipAddress, &userAgent); err != nil {
+			logger.Errorf("Failed to audit deactivation of employee %s in tenant %s: %v", employeeID, tenantID, err)
+		}
+	}
+
+	if firebaseErr != nil {
+		http.Error(w, "Employee deactivated, but their Firebase account could not be disabled; retry to finish", http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// trimmedOrNil returns a trimmed copy of value, or nil when nothing is left
+func trimmedOrNil(value string) *string {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	return &value
+}
+
 // getEmployeeTenants handles GET /api/v1/employees/me/tenants
 // Returns the list of tenants the current employee has access to
 func (api *API) getEmployeeTenants(w http.ResponseWriter, r *http.Request) {
@@ -245,41 +439,12 @@ func (api *API) getEmployeeTenants(w http.ResponseWriter, r *http.Request) {
 
 	logger.Infof("Getting tenant access for employee: %s", employee.Email)
 
-	// Query employee's tenant access from database
-	query := `
-		SELECT eta.tenant_id, tc.tenant_name, eta.role, eta.is_active
-		FROM employee_tenant_access eta
-		JOIN tenant_connections tc ON eta.tenant_id = tc.tenant_id
-		WHERE eta.employee_id = $1
-		ORDER BY tc.tenant_name
-	`
-
-	rows, err := api.store.DB.Query(query, employee.ID)
+	tenantAccess, err := api.store.GetEmployeeTenantAccess(employee.ID)
 	if err != nil {
 		logger.Errorf("Failed to query tenant access: %v", err)
 		http.Error(w, "Failed to fetch tenant access", http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
-
-	tenantAccess := []map[string]interface{}{}
-	for rows.Next() {
-		var tenantID, tenantName, role string
-		var isActive bool
-
-		err := rows.Scan(&tenantID, &tenantName, &role, &isActive)
-		if err != nil {
-			logger.Errorf("Failed to scan tenant access row: %v", err)
-			continue
-		}
-
-		tenantAccess = append(tenantAccess, map[string]interface{}{
-			"tenantId":   tenantID,
-			"tenantName": tenantName,
-			"role":       role,
-			"isActive":   isActive,
-		})
-	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(tenantAccess); err != nil {
@@ -290,7 +455,7 @@ func (api *API) getEmployeeTenants(w http.ResponseWriter, r *http.Request) {
 }
 
 // assignEmployeeToTenant handles POST /api/v1/employees/{employeeId}/tenants
-// Assigns an employee to a tenant (admin only)
+// Assigns an employee to a tenant, or changes their role in it, and returns the access (admin only)
 func (api *API) assignEmployeeToTenant(w http.ResponseWriter, r *http.Request) {
 	// Get employee from context
 	currentEmployee, ok := middleware.GetEmployeeFromContext(r.Context())
@@ -306,6 +471,12 @@ func (api *API) assignEmployeeToTenant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	employeeID, err := uuid.Parse(mux.Vars(r)["employeeId"])
+	if err != nil {
+		http.Error(w, "Invalid employee ID format", http.StatusBadRequest)
+		return
+	}
+
 	// Parse request body
 	var req AssignTenantRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -315,6 +486,10 @@ func (api *API) assignEmployeeToTenant(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate required fields
+	if req.EmployeeID != uuid.Nil && req.EmployeeID != employeeID {
+		http.Error(w, "employeeId in the body does not match the URL", http.StatusBadRequest)
+		return
+	}
 	if req.TenantID == "" {
 		http.Error(w, "Tenant ID is required", http.StatusBadRequest)
 		return
@@ -325,30 +500,30 @@ func (api *API) assignEmployeeToTenant(w http.ResponseWriter, r *http.Request) {
 
 	// Validate role
 	validRoles := map[string]bool{
-		"admin":      true,
-		"accountant": true,
-		"viewer":     true,
+		"admin":             true,
+		"accountant":        true,
+		"viewer":            true,
+		"affiliate_manager": true,
 	}
 	if !validRoles[req.Role] {
-		http.Error(w, "Invalid role. Must be one of: admin, accountant, viewer", http.StatusBadRequest)
+		http.Error(w, "Invalid role. Must be one of: admin, accountant, viewer, affiliate_manager", http.StatusBadRequest)
 		return
 	}
 
-	// TODO: Implement store method to assign employee to tenant
-	logger.Infof("Admin %s assigned employee %s to tenant %s with role %s",
-		currentEmployee.Email, req.EmployeeID, req.TenantID, req.Role)
-
-	response := map[string]interface{}{
-		"success": true,
-		"message": "Employee assigned to tenant successfully",
+	access, created, err := api.store.AssignEmployeeToTenant(employeeID, req.TenantID, req.Role, currentEmployee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to assign employee to tenant")
+		return
 	}
+	logger.Infof("Admin %s assigned employee %s to tenant %s with role %s",
+		currentEmployee.Email, employeeID, req.TenantID, req.Role)
 
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
-	if err := json.NewEncoder(w).Encode(response); err != nil {
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	if err := json.NewEncoder(w).Encode(access); err != nil {
 		logger.Errorf("Failed to encode response: %v", err)
-		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
-		return
 	}
 }
 
@@ -385,7 +560,10 @@ func (api *API) removeEmployeeFromTenant(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// TODO: Implement store method to remove employee from tenant
+	if err := api.store.RemoveEmployeeFromTenant(employeeID, tenantID); err != nil {
+		writeError(w, err, "Failed to remove employee from tenant")
+		return
+	}
 	logger.Infof("Admin %s removed employee %s from tenant %s",
 		currentEmployee.Email, employeeID, tenantID)
 
@@ -401,3 +579,20 @@ func (api *API) removeEmployeeFromTenant(w http.ResponseWriter, r *http.Request)
 		return
 	}
 }
+
+// getTenantEmployees handles GET /api/v1/admin/tenants/{tenantId}/employees
+// Lists the employees with access to a tenant and their roles in it (admin only)
+func (api *API) getTenantEmployees(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	employees, err := api.store.GetTenantEmployees(tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch tenant employees")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(employees); err != nil {
+		logger.Errorf("Failed to encode tenant employees response: %v", err)
+	}
+}
@@ -0,0 +1,28 @@
+package webapi
+
+import (
+	"net/http"
+	"unicode"
+	"unicode/utf8"
+	"welltaxpro/src/internal/apperr"
+)
+
+// writeError responds to a failed store or adapter call. Typed errors (not found, conflict,
+// permission, validation) get their status code and message; anything else is a 500 with
+// message, so database and connection details stay out of responses.
+func writeError(w http.ResponseWriter, err error, message string) {
+	status := apperr.Status(err)
+	if msg, ok := apperr.Message(err); ok && msg != "" && status != http.StatusInternalServerError {
+		message = capitalize(msg)
+	}
+	http.Error(w, message, status)
+}
+
+// capitalize upper-cases the first rune of s
+func capitalize(s string) string {
+	r, size := utf8.DecodeRuneInString(s)
+	if r == utf8.RuneError {
+		return s
+	}
+	return string(unicode.ToUpper(r)) + s[size:]
+}
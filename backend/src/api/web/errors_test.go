@@ -0,0 +1,44 @@
+package webapi
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"welltaxpro/src/internal/apperr"
+)
+
+func TestWriteError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{"not found", apperr.NotFound("client not found: %s", "abc"), http.StatusNotFound, "Client not found: abc"},
+		{"conflict", apperr.Conflict("filing is locked"), http.StatusConflict, "Filing is locked"},
+		{"permission", apperr.Permission("not allowed"), http.StatusForbidden, "Not allowed"},
+		{"validation", apperr.Validation("invalid year"), http.StatusBadRequest, "Invalid year"},
+		{"wrapped", fmt.Errorf("update: %w", apperr.Conflict("already paid")), http.StatusConflict, "Already paid"},
+		{"empty message", apperr.Validation(""), http.StatusBadRequest, "Failed"},
+		{"multi-byte first rune", apperr.Validation("évaluation failed"), http.StatusBadRequest, "Évaluation failed"},
+		{"already capitalized", apperr.NotFound("W-2 not found"), http.StatusNotFound, "W-2 not found"},
+		{"untyped", errors.New("pq: connection refused"), http.StatusInternalServerError, "Failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			writeError(rec, tt.err, "Failed")
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if body := strings.TrimSpace(rec.Body.String()); body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package webapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/middleware"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// getFilingAssignments returns a tenant's filing assignments, only one accountant's with
+// ?employeeId= (admin only)
+func (api *API) getFilingAssignments(w http.ResponseWriter, r *http.Request) {
+	var employeeID *uuid.UUID
+	if param := r.URL.Query().Get("employeeId"); param != "" {
+		parsed, err := uuid.Parse(param)
+		if err != nil {
+			http.Error(w, "Invalid employeeId parameter", http.StatusBadRequest)
+			return
+		}
+		employeeID = &parsed
+	}
+
+	assignments, err := api.store.GetFilingAssignments(mux.Vars(r)["tenantId"], employeeID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch filing assignments")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(assignments); err != nil {
+		logger.Errorf("Failed to encode filing assignments response: %v", err)
+	}
+}
+
+// assignFiling makes an accountant with access to the tenant the one working a filing (admin only).
+// Use a REASSIGN_FILINGS bulk operation to move many filings at once.
+func (api *API) assignFiling(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	filingID, err := uuid.Parse(vars["filingId"])
+	if err != nil {
+		http.Error(w, "Invalid filing ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		EmployeeID uuid.UUID `json:"employeeId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.EmployeeID == uuid.Nil {
+		http.Error(w, "employeeId is required", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := api.store.GetEmployeeTenantRole(req.EmployeeID, tenantID); err != nil {
+		if errors.Is(err, apperr.ErrNotFound) {
+			http.Error(w, "Employee has no access to this tenant", http.StatusBadRequest)
+			return
+		}
+		writeError(w, err, "Failed to check employee access")
+		return
+	}
+	if _, err := api.store.GetFilingClientID(r.Context(), tenantID, filingID); err != nil {
+		writeError(w, err, "Failed to fetch filing")
+		return
+	}
+
+	assignment, err := api.store.AssignFiling(tenantID, filingID, req.EmployeeID, &employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to assign filing")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(assignment); err != nil {
+		logger.Errorf("Failed to encode filing assignment response: %v", err)
+	}
+}
@@ -0,0 +1,35 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// getFilingChecklist returns the documents a filing needs, worked out from the client's income
+// sources, deductions, dependents and marketplace insurance, and which of them were uploaded
+func (api *API) getFilingChecklist(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	filingID, err := uuid.Parse(vars["filingId"])
+	if err != nil {
+		http.Error(w, "Invalid filing ID", http.StatusBadRequest)
+		return
+	}
+
+	checklist, err := api.store.GetFilingChecklist(r.Context(), tenantID, filingID)
+	if err != nil {
+		logger.Errorf("Failed to get checklist of filing %s: %v", filingID, err)
+		writeError(w, err, "Failed to fetch filing checklist")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(checklist); err != nil {
+		logger.Errorf("Failed to encode filing checklist response: %v", err)
+	}
+}
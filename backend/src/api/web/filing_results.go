@@ -0,0 +1,126 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// getFilingResult returns the recorded outcome of a filing
+func (api *API) getFilingResult(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	filingID := vars["filingId"]
+
+	result, err := api.store.GetFilingResult(r.Context(), tenantID, filingID)
+	if err != nil {
+		logger.Warningf("No result for filing %s: %v", filingID, err)
+		writeError(w, err, "Failed to fetch filing result")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Errorf("Failed to encode filing result response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// recordFilingResult records or replaces the outcome of a prepared return
+func (api *API) recordFilingResult(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	filingID, err := uuid.Parse(vars["filingId"])
+	if err != nil {
+		http.Error(w, "Invalid filing ID", http.StatusBadRequest)
+		return
+	}
+
+	var input types.FilingResult
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if msg := input.Validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	input.FilingID = filingID
+	input.RecordedBy = &employee.Email
+
+	logger.Infof("Recording result for filing %s in tenant %s", filingID, tenantID)
+
+	result, err := api.store.UpsertFilingResult(r.Context(), tenantID, &input)
+	if err != nil {
+		logger.Errorf("Failed to record filing result: %v", err)
+		writeError(w, err, "Failed to record filing result")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Errorf("Failed to encode filing result response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// getClientYearOverYear compares a client's filing results across tax years
+func (api *API) getClientYearOverYear(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	clientID := vars["clientId"]
+
+	clientData, err := api.store.GetClientComprehensive(r.Context(), tenantID, clientID)
+	if err != nil {
+		logger.Errorf("Failed to get client data for year-over-year comparison: %v", err)
+		writeError(w, err, "Failed to fetch client data")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(clientData.YearOverYear()); err != nil {
+		logger.Errorf("Failed to encode year-over-year response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// getPortalYearOverYear compares the authenticated user's filing results across tax years (tenant user only)
+func (api *API) getPortalYearOverYear(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	comparisons := []*types.FilingYearComparison{}
+	if tenantUser.ClientID != NewClientUUID {
+		clientData, err := api.store.GetClientComprehensive(r.Context(), tenantUser.TenantID, tenantUser.ClientID.String())
+		if err != nil {
+			logger.Errorf("Failed to get client data for year-over-year comparison: %v", err)
+			writeError(w, err, "Failed to fetch comparison")
+			return
+		}
+		comparisons = clientData.YearOverYear()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(comparisons); err != nil {
+		logger.Errorf("Failed to encode year-over-year response: %v", err)
+	}
+}
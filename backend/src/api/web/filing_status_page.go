@@ -0,0 +1,156 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// getFilingStatusTexts returns a tenant's own filing status texts (admin only)
+// Query params: effective=true returns them merged with the built-in texts instead
+func (api *API) getFilingStatusTexts(w http.ResponseWriter, r *http.Request) {
+	texts, err := api.store.GetFilingStatusTexts(mux.Vars(r)["tenantId"])
+	if err != nil {
+		writeError(w, err, "Failed to fetch filing status texts")
+		return
+	}
+	if r.URL.Query().Get("effective") == "true" {
+		texts = texts.Merge()
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(texts); err != nil {
+		logger.Errorf("Failed to encode filing status texts response: %v", err)
+	}
+}
+
+// updateFilingStatusTexts replaces a tenant's filing status texts (admin only). The body maps
+// locales to statuses to texts; an empty object goes back to the built-in texts.
+func (api *API) updateFilingStatusTexts(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var texts types.FilingStatusTexts
+	if err := json.NewDecoder(r.Body).Decode(&texts); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := texts.Validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Updating filing status texts for tenant %s", tenantID)
+
+	if err := api.store.UpdateFilingStatusTexts(tenantID, texts, employee.ID); err != nil {
+		writeError(w, err, "Failed to update filing status texts")
+		return
+	}
+
+	if texts == nil {
+		texts = types.FilingStatusTexts{}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(texts); err != nil {
+		logger.Errorf("Failed to encode filing status texts response: %v", err)
+	}
+}
+
+// getPortalFilingStatus describes where the client's own filing is in plain language, with the
+// steps ahead and when it usually moves on (tenant user only)
+// Query params: locale (e.g. es); otherwise the Accept-Language header picks the language
+func (api *API) getPortalFilingStatus(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	filingID := mux.Vars(r)["filingId"]
+	if tenantUser.ClientID == NewClientUUID {
+		http.Error(w, "Filing not found", http.StatusNotFound)
+		return
+	}
+
+	workflow, err := api.store.GetFilingWorkflow(r.Context(), tenantUser.TenantID, filingID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch filing")
+		return
+	}
+	// Only filings belonging to the authenticated client are visible
+	if workflow.ClientID != tenantUser.ClientID {
+		http.Error(w, "Filing not found", http.StatusNotFound)
+		return
+	}
+
+	texts, err := api.store.GetFilingStatusTexts(tenantUser.TenantID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch filing status texts")
+		return
+	}
+	texts = texts.Merge()
+	locale := texts.Locale(requestedLocales(r))
+
+	// The filing reached its status with the latest change, unless the tax platform set it since
+	changes, err := api.store.GetFilingStatusHistory(tenantUser.TenantID, filingID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch filing status history")
+		return
+	}
+	status := types.WorkflowFilingStatus(workflow.Status)
+	if workflow.IsCompleted && status != types.FilingStatusAccepted {
+		status = types.FilingStatusCompleted
+	}
+	workflow.Status = status
+	var since *time.Time
+	if n := len(changes); n > 0 && changes[n-1].ToStatus == status {
+		since = &changes[n-1].ChangedAt
+	}
+
+	page := types.BuildFilingStatusPage(workflow, texts, locale, since)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Language", locale)
+	if err := json.NewEncoder(w).Encode(page); err != nil {
+		logger.Errorf("Failed to encode filing status response: %v", err)
+	}
+}
+
+// requestedLocales returns the locale query parameter, or else the Accept-Language header's
+// languages in the order given, as language codes with an optional upper-case region
+func requestedLocales(r *http.Request) []string {
+	var raw []string
+	if locale := r.URL.Query().Get("locale"); locale != "" {
+		raw = []string{locale}
+	} else {
+		for _, part := range strings.Split(r.Header.Get("Accept-Language"), ",") {
+			tag, _, _ := strings.Cut(part, ";")
+			raw = append(raw, tag)
+		}
+	}
+
+	locales := make([]string, 0, len(raw))
+	for _, tag := range raw {
+		tag = strings.TrimSpace(strings.ReplaceAll(tag, "_", "-"))
+		if tag == "" || tag == "*" {
+			continue
+		}
+		language, region, hasRegion := strings.Cut(tag, "-")
+		locale := strings.ToLower(language)
+		if hasRegion {
+			locale += "-" + strings.ToUpper(region)
+		}
+		locales = append(locales, locale)
+	}
+	return locales
+}
@@ -1,10 +1,15 @@
 package webapi
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/middleware"
 	"welltaxpro/src/internal/notification"
+	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
 	"github.com/gorilla/mux"
@@ -18,75 +23,34 @@ func (api *API) markFilingCompleted(w http.ResponseWriter, r *http.Request) {
 
 	logger.Infof("Mark filing %s as completed for tenant %s", filingID, tenantID)
 
-	// Get tenant database connection
-	tenantDB, tc, err := api.store.GetTenantDB(tenantID)
+	_, tc, err := api.store.GetTenantSQLDB(r.Context(), tenantID)
 	if err != nil {
 		logger.Errorf("Failed to get tenant database: %v", err)
-		http.Error(w, "Failed to connect to tenant database", http.StatusInternalServerError)
+		writeError(w, err, "Failed to connect to tenant database")
 		return
 	}
 
-	// Update filing_status to mark as completed
-	updateQuery := `
-		UPDATE ` + tc.SchemaPrefix + `.filing_status
-		SET is_completed = true, status = 'COMPLETED'
-		WHERE filing_id = $1
-	`
-
-	result, err := tenantDB.Exec(updateQuery, filingID)
-	if err != nil {
-		logger.Errorf("Failed to update filing status: %v", err)
-		http.Error(w, "Failed to update filing status", http.StatusInternalServerError)
-		return
-	}
-
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		logger.Errorf("Failed to get rows affected: %v", err)
-		http.Error(w, "Failed to verify update", http.StatusInternalServerError)
-		return
-	}
-
-	if rowsAffected == 0 {
-		http.Error(w, "Filing status not found", http.StatusNotFound)
+	if err := api.store.MarkFilingCompleted(r.Context(), tenantID, filingID); err != nil {
+		writeError(w, err, "Failed to update filing status")
 		return
 	}
 
 	logger.Infof("Successfully marked filing %s as completed", filingID)
 
 	// Get filing and client information for email notification
-	var clientEmail, clientFirstName, clientLastName string
-	var taxYear int
-	var filingType string
-
-	filingQuery := `
-		SELECT
-			u.email,
-			COALESCE(u.first_name, ''),
-			COALESCE(u.last_name, ''),
-			f.year,
-			'Tax Return'
-		FROM ` + tc.SchemaPrefix + `.filing f
-		JOIN ` + tc.SchemaPrefix + `.user u ON f.user_id = u.id
-		WHERE f.id = $1
-	`
-
-	err = tenantDB.QueryRow(filingQuery, filingID).Scan(
-		&clientEmail,
-		&clientFirstName,
-		&clientLastName,
-		&taxYear,
-		&filingType,
-	)
-
+	contact, err := api.store.GetFilingContact(r.Context(), tenantID, filingID)
+	completed := types.WebhookFilingCompleted{FilingID: filingID}
 	if err != nil {
 		logger.Warningf("Failed to get client info for email notification: %v", err)
 		// Don't fail the request, just skip the email
 	} else {
+		completed.ClientID = &contact.ClientID
+		completed.TaxYear = contact.TaxYear
+
 		// Send email notification
-		clientName := clientFirstName
-		if clientLastName != "" {
-			clientName = fmt.Sprintf("%s %s", clientFirstName, clientLastName)
+		clientName := contact.FirstName
+		if contact.LastName != "" {
+			clientName = fmt.Sprintf("%s %s", contact.FirstName, contact.LastName)
 		}
 		if clientName == "" {
 			clientName = "Valued Client"
@@ -95,20 +59,40 @@ func (api *API) markFilingCompleted(w http.ResponseWriter, r *http.Request) {
 		// Generate email content
 		subject, htmlBody, textBody := notification.GenerateFilingCompletedEmail(notification.FilingCompletedEmail{
 			ClientName: clientName,
-			TaxYear:    taxYear,
-			FilingType: filingType,
+			TaxYear:    contact.TaxYear,
+			FilingType: "Tax Return",
 			TenantName: tc.TenantName,
-			LoginURL:   fmt.Sprintf("https://app.welltaxpro.com/%s/clients", tenantID),
+			LoginURL:   api.links.Login(tenantID),
+			Deceased:   contact.Deceased,
 		})
 
-		// Send email
-		err = api.emailService.SendEmail(clientEmail, clientName, subject, htmlBody, textBody)
+		// Queue the email for the email delivery job, which retries it if SendGrid fails
+		queued, err := api.store.QueueEmail(&types.OutboxEmail{
+			TenantID: &tenantID,
+			Kind:     types.OutboxEmailFilingCompleted,
+			Priority: notification.PriorityTransactional,
+			ToEmail:  contact.Email,
+			ToName:   &clientName,
+			Subject:  subject,
+			HTMLBody: htmlBody,
+			TextBody: textBody,
+		})
 		if err != nil {
-			logger.Errorf("Failed to send filing completed email to %s: %v", clientEmail, err)
+			logger.Errorf("Failed to queue filing completed email to %s: %v", contact.Email, err)
 			// Don't fail the request, email is not critical
 		} else {
-			logger.Infof("Filing completed email sent to %s", clientEmail)
+			logger.Infof("Filing completed email %s queued for %s", queued.ID, contact.Email)
 		}
+
+		// Push to the client's portal devices as an additional channel
+		pushTitle, pushBody := notification.FilingCompletedPush(contact.TaxYear)
+		go api.pushService.NotifyClient(context.Background(), tenantID, contact.ClientID, types.PushCategoryFilingMilestone,
+			pushTitle, pushBody, map[string]string{"filingId": filingID, "status": "COMPLETED"})
+	}
+
+	// Tell the tenant's webhooks; the filing is already completed, so a failure is only logged
+	if err := api.store.EnqueueWebhookEvent(tenantID, types.WebhookEventFilingCompleted, &completed); err != nil {
+		logger.Errorf("Filing %s completed without notifying webhooks: %v", filingID, err)
 	}
 
 	// Return success response
@@ -124,3 +108,179 @@ func (api *API) markFilingCompleted(w http.ResponseWriter, r *http.Request) {
 		logger.Errorf("Failed to encode response: %v", err)
 	}
 }
+
+// getFilingStatus returns a filing's workflow status and the statuses it may move to
+func (api *API) getFilingStatus(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	workflow, err := api.store.GetFilingWorkflow(r.Context(), vars["tenantId"], vars["filingId"])
+	if err != nil {
+		writeError(w, err, "Failed to fetch filing status")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(workflow); err != nil {
+		logger.Errorf("Failed to encode filing status response: %v", err)
+	}
+}
+
+// getFilingStatusHistory returns a filing's status changes, oldest first
+func (api *API) getFilingStatusHistory(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	changes, err := api.store.GetFilingStatusHistory(vars["tenantId"], vars["filingId"])
+	if err != nil {
+		writeError(w, err, "Failed to fetch filing status history")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(changes); err != nil {
+		logger.Errorf("Failed to encode filing status history response: %v", err)
+	}
+}
+
+// putFilingStatus moves a filing through the workflow:
+// IN_PROGRESS → REVIEW → SIGNED → FILED → ACCEPTED or REJECTED, with REVIEW, SIGNED and REJECTED
+// able to go back to IN_PROGRESS. Moves the workflow does not allow get 409.
+func (api *API) putFilingStatus(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	var update types.FilingStatusUpdate
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	change, err := api.store.ChangeFilingStatus(r.Context(), tenantID, vars["filingId"], &update, employee)
+	if err != nil {
+		writeError(w, err, "Failed to change filing status")
+		return
+	}
+
+	api.announceFilingStatus(r.Context(), change)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(change); err != nil {
+		logger.Errorf("Failed to encode filing status change response: %v", err)
+	}
+}
+
+// announceFilingStatus tells the tenant's webhooks about a status change and notifies whoever
+// acts next: admins when a filing is ready for review, the filing's accountant when the IRS
+// rejects it, and the client when it is filed or accepted. The change is already made, so
+// failures are only logged.
+func (api *API) announceFilingStatus(ctx context.Context, change *types.FilingStatusChange) {
+	tenantID := change.TenantID
+	filingID := change.FilingID.String()
+
+	if err := api.store.EnqueueWebhookEvent(tenantID, types.WebhookEventFilingStatus, change); err != nil {
+		logger.Errorf("Filing %s moved to %s without notifying webhooks: %v", filingID, change.ToStatus, err)
+	}
+	if change.ToStatus == types.FilingStatusAccepted {
+		completed := &types.WebhookFilingCompleted{FilingID: filingID, ClientID: &change.ClientID, TaxYear: change.TaxYear}
+		if err := api.store.EnqueueWebhookEvent(tenantID, types.WebhookEventFilingCompleted, completed); err != nil {
+			logger.Errorf("Filing %s accepted without notifying webhooks: %v", filingID, err)
+		}
+	}
+
+	switch change.ToStatus {
+	case types.FilingStatusReview:
+		if api.notifier != nil {
+			go api.notifier.NotifyTenantAdmins(types.NotificationCategoryFiling, tenantID, "Filing ready for review",
+				fmt.Sprintf("%s sent the %d filing %s in tenant %s for review.", change.ChangedByEmail, change.TaxYear, filingID, tenantID))
+		}
+	case types.FilingStatusRejected:
+		if api.notifier != nil {
+			go api.notifyFilingRejected(change)
+		}
+	case types.FilingStatusFiled, types.FilingStatusAccepted:
+		go api.notifyClientFilingStatus(context.WithoutCancel(ctx), change)
+	}
+}
+
+// notifyFilingRejected tells the accountant assigned to a rejected filing, or the tenant's admins
+// when no active accountant is assigned
+func (api *API) notifyFilingRejected(change *types.FilingStatusChange) {
+	subject := "Filing rejected"
+	body := fmt.Sprintf("%s recorded that the IRS rejected the %d filing %s in tenant %s.",
+		change.ChangedByEmail, change.TaxYear, change.FilingID, change.TenantID)
+	if change.Note != nil && *change.Note != "" {
+		body += " Note: " + *change.Note
+	}
+
+	assignment, err := api.store.GetFilingAssignment(change.TenantID, change.FilingID)
+	if err == nil {
+		employee, err := api.store.GetEmployeeByID(assignment.EmployeeID)
+		if err == nil && employee.IsActive {
+			api.notifier.Notify([]*types.Employee{employee}, types.NotificationCategoryFiling, &change.TenantID, subject, body)
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to load accountant %s of filing %s: %v", assignment.EmployeeID, change.FilingID, err)
+		}
+	} else if !errors.Is(err, apperr.ErrNotFound) {
+		logger.Errorf("Failed to get assignment of filing %s: %v", change.FilingID, err)
+	}
+
+	api.notifier.NotifyTenantAdmins(types.NotificationCategoryFiling, change.TenantID, subject, body)
+}
+
+// notifyClientFilingStatus emails and pushes to the client of a filing that was filed or accepted.
+// Deceased clients are not notified.
+func (api *API) notifyClientFilingStatus(ctx context.Context, change *types.FilingStatusChange) {
+	client, err := api.store.GetClientByID(ctx, change.TenantID, change.ClientID.String())
+	if err != nil {
+		logger.Errorf("Failed to get client %s to notify about filing %s: %v", change.ClientID, change.FilingID, err)
+		return
+	}
+	if client.DeathDate != nil {
+		return
+	}
+
+	_, tc, err := api.store.GetTenantSQLDB(ctx, change.TenantID)
+	if err != nil {
+		logger.Errorf("Failed to get tenant %s to notify about filing %s: %v", change.TenantID, change.FilingID, err)
+		return
+	}
+	clientName := "there"
+	if client.FirstName != nil && *client.FirstName != "" {
+		clientName = *client.FirstName
+	}
+
+	if client.Email != "" {
+		subject, htmlBody, textBody := notification.GenerateFilingStatusEmail(notification.FilingStatusEmail{
+			ClientName: clientName,
+			TenantName: tc.TenantName,
+			TaxYear:    change.TaxYear,
+			Status:     change.ToStatus,
+			LoginURL:   api.links.Login(change.TenantID),
+		})
+		_, err := api.store.QueueEmail(&types.OutboxEmail{
+			TenantID: &change.TenantID,
+			Kind:     types.OutboxEmailFilingStatus,
+			Priority: notification.PriorityTransactional,
+			ToEmail:  client.Email,
+			ToName:   &clientName,
+			Subject:  subject,
+			HTMLBody: htmlBody,
+			TextBody: textBody,
+		})
+		if err != nil {
+			logger.Errorf("Failed to queue filing %s email to %s: %v", change.ToStatus, client.Email, err)
+		}
+	}
+
+	pushTitle, pushBody := notification.FilingStatusPush(change.TaxYear, change.ToStatus)
+	api.pushService.NotifyClient(context.Background(), change.TenantID, change.ClientID, types.PushCategoryFilingMilestone,
+		pushTitle, pushBody, map[string]string{"filingId": change.FilingID.String(), "status": change.ToStatus})
+}
@@ -0,0 +1,35 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// getFirebaseOrphans returns the Firebase accounts the last reconciliation run found with no
+// employee or portal user, oldest reported first (admin only)
+func (api *API) getFirebaseOrphans(w http.ResponseWriter, r *http.Request) {
+	orphans, err := api.store.GetFirebaseOrphans()
+	if err != nil {
+		writeError(w, err, "Failed to get orphaned Firebase users")
+		return
+	}
+	lastRun, err := api.store.GetLastJobRun(types.JobFirebaseReconcile)
+	if err != nil {
+		writeError(w, err, "Failed to get orphaned Firebase users")
+		return
+	}
+
+	response := struct {
+		LastRunAt *time.Time                  `json:"lastRunAt"` // Null until the reconciliation job has run
+		Users     []*types.FirebaseOrphanUser `json:"users"`
+	}{lastRun, orphans}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Errorf("Failed to encode Firebase orphans response: %v", err)
+	}
+}
@@ -0,0 +1,293 @@
+package webapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/pdfform"
+	"welltaxpro/src/internal/storage"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// getFormTemplates lists the tenant's fillable form templates
+func (api *API) getFormTemplates(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	templates, err := api.store.GetFormTemplates(tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get form templates for tenant %s: %v", tenantID, err)
+		writeError(w, err, "Failed to fetch form templates")
+		return
+	}
+
+	if templates == nil {
+		templates = []*types.FormTemplate{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(templates); err != nil {
+		logger.Errorf("Failed to encode form templates response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// putFormTemplate sets the fillable PDF and field map for a form kind (admin only)
+// Every mapped field must exist in the PDF and map to a known form data key
+func (api *API) putFormTemplate(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	kind := vars["kind"]
+	if !types.IsValidFormKind(kind) {
+		http.Error(w, "Invalid form kind. Must be one of: "+strings.Join(types.FormKinds, ", "), http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		TemplatePath string            `json:"templatePath"`
+		FieldMap     map[string]string `json:"fieldMap"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !storage.IsStorageURL(req.TemplatePath) {
+		http.Error(w, "templatePath must be a gs://, s3:// or https://storage.googleapis.com/ URL", http.StatusBadRequest)
+		return
+	}
+	if len(req.FieldMap) == 0 {
+		http.Error(w, "fieldMap is required", http.StatusBadRequest)
+		return
+	}
+
+	tc, err := api.store.GetTenantConfig(tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	fields, err := api.loadTemplateFields(r.Context(), tc, req.TemplatePath)
+	if err != nil {
+		logger.Errorf("Failed to read form template %s: %v", req.TemplatePath, err)
+		http.Error(w, fmt.Sprintf("Template is not a usable fillable PDF: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	for pdfField, dataKey := range req.FieldMap {
+		if _, ok := fields[pdfField]; !ok {
+			http.Error(w, fmt.Sprintf("Field %q does not exist in the template", pdfField), http.StatusBadRequest)
+			return
+		}
+		if !types.IsValidFormDataKey(dataKey) {
+			http.Error(w, fmt.Sprintf("Unknown data key %q for field %q", dataKey, pdfField), http.StatusBadRequest)
+			return
+		}
+	}
+
+	template := &types.FormTemplate{
+		TenantID:     tenantID,
+		Kind:         kind,
+		TemplatePath: req.TemplatePath,
+		FieldMap:     req.FieldMap,
+		UpdatedBy:    &employee.ID,
+	}
+	if err := api.store.UpsertFormTemplate(template); err != nil {
+		writeError(w, err, "Failed to save form template")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(template); err != nil {
+		logger.Errorf("Failed to encode form template response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// deleteFormTemplate removes the template for a form kind (admin only)
+func (api *API) deleteFormTemplate(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	if err := api.store.DeleteFormTemplate(vars["tenantId"], vars["kind"]); err != nil {
+		writeError(w, err, "Failed to delete form template")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getFormTemplateFields lists the fillable fields of a PDF and the available data keys (admin only)
+// Optional query: ?path=gs://... inspects a PDF before it is saved as the template
+func (api *API) getFormTemplateFields(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	tc, err := api.store.GetTenantConfig(tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	path := r.URL.Query().Get("path")
+	if path == "" {
+		template, err := api.store.GetFormTemplate(tenantID, vars["kind"])
+		if err != nil {
+			writeError(w, err, "Failed to fetch form template")
+			return
+		}
+		path = template.TemplatePath
+	} else if !storage.IsStorageURL(path) {
+		http.Error(w, "path must be a gs://, s3:// or https://storage.googleapis.com/ URL", http.StatusBadRequest)
+		return
+	}
+
+	data, err := storage.ReadFile(r.Context(), tc, path)
+	if err != nil {
+		logger.Errorf("Failed to read form template %s: %v", path, err)
+		http.Error(w, "Failed to read template", http.StatusBadGateway)
+		return
+	}
+
+	fields, err := pdfform.Fields(data)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("Template is not a usable fillable PDF: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	response := map[string]interface{}{
+		"fields":   fields,
+		"dataKeys": types.FormDataKeys,
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Errorf("Failed to encode form fields response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// generateFilingForm renders a tenant form template pre-filled with the client's filing data
+// Optional body: {"values": {"amounts.refund": "1200.00", ...}} supplies or overrides data keys
+func (api *API) generateFilingForm(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	clientID := vars["clientId"]
+	filingID := vars["filingId"]
+	kind := vars["kind"]
+
+	var req struct {
+		Values map[string]string `json:"values"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	for key := range req.Values {
+		if !types.IsValidFormDataKey(key) {
+			http.Error(w, fmt.Sprintf("Unknown data key %q", key), http.StatusBadRequest)
+			return
+		}
+	}
+
+	template, err := api.store.GetFormTemplate(tenantID, kind)
+	if err != nil {
+		writeError(w, err, "Failed to fetch form template")
+		return
+	}
+
+	tc, err := api.store.GetTenantConfig(tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	clientData, err := api.store.GetClientComprehensive(r.Context(), tenantID, clientID)
+	if err != nil {
+		logger.Errorf("Failed to get client data for form generation: %v", err)
+		writeError(w, err, "Failed to fetch client data")
+		return
+	}
+
+	var filing *types.Filing
+	for _, f := range clientData.Filings {
+		if f.ID.String() == filingID {
+			filing = f
+			break
+		}
+	}
+	if filing == nil {
+		http.Error(w, "Filing not found", http.StatusNotFound)
+		return
+	}
+
+	data := clientData.FormData(filing)
+	for key, value := range req.Values {
+		data[key] = value
+	}
+
+	pdf, err := api.fillFormTemplate(r.Context(), tc, template, data)
+	if err != nil {
+		logger.Errorf("Failed to fill %s form for filing %s: %v", kind, filingID, err)
+		http.Error(w, "Failed to generate form", http.StatusInternalServerError)
+		return
+	}
+
+	fileName := fmt.Sprintf("%s-%d-%s.pdf", strings.ToLower(kind), filing.Year, filing.ID.String()[:8])
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	if _, err := w.Write(pdf); err != nil {
+		logger.Errorf("Failed to write generated form: %v", err)
+	}
+}
+
+// fillFormTemplate loads a template PDF and fills each mapped field from the form data
+func (api *API) fillFormTemplate(ctx context.Context, tc *types.TenantConnection, template *types.FormTemplate, data map[string]string) ([]byte, error) {
+	pdf, err := storage.ReadFile(ctx, tc, template.TemplatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string, len(template.FieldMap))
+	for pdfField, dataKey := range template.FieldMap {
+		if value, ok := data[dataKey]; ok {
+			values[pdfField] = value
+		}
+	}
+
+	return pdfform.Fill(pdf, values)
+}
+
+// loadTemplateFields returns the fillable fields of a PDF keyed by name
+func (api *API) loadTemplateFields(ctx context.Context, tc *types.TenantConnection, path string) (map[string]pdfform.Field, error) {
+	data, err := storage.ReadFile(ctx, tc, path)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := pdfform.Fields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]pdfform.Field, len(fields))
+	for _, f := range fields {
+		byName[f.Name] = f
+	}
+	return byName, nil
+}
@@ -0,0 +1,181 @@
+package webapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// createCommission records a commission after running the tenant's fraud rules (admin only)
+// Commissions matching a rule are created in REVIEW status
+func (api *API) createCommission(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	var req struct {
+		AffiliateID    uuid.UUID   `json:"affiliateId"`
+		FilingID       uuid.UUID   `json:"filingId"`
+		UserID         uuid.UUID   `json:"userId"`
+		DiscountCodeID uuid.UUID   `json:"discountCodeId"`
+		PaymentID      *uuid.UUID  `json:"paymentId,omitempty"`
+		OrderAmount    types.Cents `json:"orderAmount"`
+		DiscountAmount types.Cents `json:"discountAmount"`
+		CommissionRate types.Rate  `json:"commissionRate"`
+		IPAddress      string      `json:"ipAddress"` // Customer IP at purchase time
+		Notes          *string     `json:"notes,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.AffiliateID == uuid.Nil || req.FilingID == uuid.Nil || req.UserID == uuid.Nil || req.DiscountCodeID == uuid.Nil {
+		http.Error(w, "affiliateId, filingId, userId and discountCodeId are required", http.StatusBadRequest)
+		return
+	}
+	if req.OrderAmount <= 0 || !req.CommissionRate.Valid() {
+		http.Error(w, "Invalid order amount or commission rate", http.StatusBadRequest)
+		return
+	}
+
+	netAmount := req.OrderAmount - req.DiscountAmount
+	commission := &types.Commission{
+		AffiliateID:      req.AffiliateID,
+		FilingID:         req.FilingID,
+		UserID:           req.UserID,
+		DiscountCodeID:   req.DiscountCodeID,
+		PaymentID:        req.PaymentID,
+		OrderAmount:      req.OrderAmount,
+		DiscountAmount:   req.DiscountAmount,
+		NetAmount:        netAmount,
+		CommissionRate:   req.CommissionRate,
+		CommissionAmount: netAmount.Percent(req.CommissionRate),
+		Notes:            req.Notes,
+	}
+
+	logger.Infof("Creating commission for affiliate %s in tenant %s", req.AffiliateID, tenantID)
+
+	created, err := api.store.CreateCommission(r.Context(), tenantID, commission, req.IPAddress)
+	if err != nil {
+		logger.Errorf("Failed to create commission: %v", err)
+		writeError(w, err, "Failed to create commission")
+		return
+	}
+
+	if created.Status == types.CommissionStatusReview && api.notifier != nil {
+		go api.notifier.NotifyAdmins(
+			types.NotificationCategoryCommission,
+			&tenantID,
+			"Commission flagged for review",
+			fmt.Sprintf("Commission %s for affiliate %s in tenant %s matched %d fraud rule(s) and needs review.", created.ID, created.AffiliateID, tenantID, len(created.FraudFlags)),
+		)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(created); err != nil {
+		logger.Errorf("Failed to encode commission response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// getCommissionReviewQueue returns commissions flagged by fraud rules (admin only)
+// Reviewed commissions are resolved through the existing approve/cancel endpoints
+func (api *API) getCommissionReviewQueue(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	limit := 100 // default
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if parsed, err := strconv.Atoi(limitStr); err == nil {
+			limit = parsed
+		}
+	}
+
+	logger.Infof("Fetching commission review queue for tenant %s", tenantID)
+
+	status := types.CommissionStatusReview
+	commissions, err := api.store.GetCommissionsByAffiliate(r.Context(), tenantID, nil, &status, nil, limit)
+	if err != nil {
+		logger.Errorf("Failed to get commission review queue: %v", err)
+		writeError(w, err, "Failed to fetch commission review queue")
+		return
+	}
+	if err := api.store.AttachCommissionTags(tenantID, commissions); err != nil {
+		writeError(w, err, "Failed to fetch commission tags")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(commissions); err != nil {
+		logger.Errorf("Failed to encode commissions response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// getFraudRules returns the effective commission fraud rules for a tenant (admin only)
+func (api *API) getFraudRules(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	rules, err := api.store.GetFraudRules(tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get fraud rules: %v", err)
+		writeError(w, err, "Failed to fetch fraud rules")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(rules); err != nil {
+		logger.Errorf("Failed to encode fraud rules response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// updateFraudRules replaces the commission fraud rules for a tenant (admin only)
+func (api *API) updateFraudRules(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	var rules types.FraudRules
+	if err := json.NewDecoder(r.Body).Decode(&rules); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if rules.MaxClicksPerIP < 0 || rules.VelocityMaxPerCode < 0 || rules.VelocityWindowHours < 0 {
+		http.Error(w, "Rule limits must not be negative", http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Updating fraud rules for tenant %s", tenantID)
+
+	if err := api.store.UpdateFraudRules(tenantID, &rules, employee.ID); err != nil {
+		logger.Errorf("Failed to update fraud rules: %v", err)
+		writeError(w, err, "Failed to update fraud rules")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(rules); err != nil {
+		logger.Errorf("Failed to encode fraud rules response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
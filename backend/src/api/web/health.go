@@ -0,0 +1,69 @@
+package webapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/google/logger"
+)
+
+// healthPingTimeout bounds the database ping of the liveness and readiness probes
+const healthPingTimeout = 2 * time.Second
+
+// Drain fails readiness probes from now on, so load balancers stop routing requests to this
+// process before its listener closes. Liveness probes keep passing while requests drain.
+func (api *API) Drain() {
+	api.draining.Store(true)
+}
+
+// healthCheck returns 200 OK if service is running
+func (api *API) healthCheck(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(`{"status":"ok"}`))
+}
+
+// liveness returns 200 while the process can reach WellTaxPro's database, including while it
+// drains before shutting down
+func (api *API) liveness(w http.ResponseWriter, r *http.Request) {
+	if !api.pingDatabase(r.Context()) {
+		writeHealth(w, http.StatusServiceUnavailable, "database unreachable")
+		return
+	}
+	writeHealth(w, http.StatusOK, "ok")
+}
+
+// readiness returns 200 while the process should receive traffic: it is not draining and can
+// reach WellTaxPro's database
+func (api *API) readiness(w http.ResponseWriter, r *http.Request) {
+	if api.draining.Load() {
+		writeHealth(w, http.StatusServiceUnavailable, "draining")
+		return
+	}
+	if !api.pingDatabase(r.Context()) {
+		writeHealth(w, http.StatusServiceUnavailable, "database unreachable")
+		return
+	}
+	writeHealth(w, http.StatusOK, "ok")
+}
+
+// pingDatabase reports whether WellTaxPro's database answers within healthPingTimeout
+func (api *API) pingDatabase(ctx context.Context) bool {
+	ctx, cancel := context.WithTimeout(ctx, healthPingTimeout)
+	defer cancel()
+	if err := api.store.Ping(ctx); err != nil {
+		logger.Warningf("Health probe could not reach the database: %v", err)
+		return false
+	}
+	return true
+}
+
+// writeHealth writes a probe response
+func writeHealth(w http.ResponseWriter, code int, status string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(map[string]string{"status": status}); err != nil {
+		logger.Errorf("Failed to encode health response: %v", err)
+	}
+}
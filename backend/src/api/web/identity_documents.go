@@ -0,0 +1,335 @@
+package webapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/idcheck"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// identityImageTypes maps the accepted ID image content types to their file extension
+var identityImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+}
+
+// uploadPortalIdentityDocument accepts a photo ID during onboarding, runs the automated
+// expiry and name checks and stores the image under the tenant's restricted storage path (tenant user only)
+func (api *API) uploadPortalIdentityDocument(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		logger.Errorf("Failed to parse identity document form: %v", err)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "File too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "File too large or invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	kind := strings.ToUpper(strings.TrimSpace(r.FormValue("kind")))
+	if kind == "" {
+		kind = types.IdentityDocumentDriversLicense
+	}
+	if !types.IsValidIdentityDocumentKind(kind) {
+		http.Error(w, "kind must be DRIVERS_LICENSE, STATE_ID or PASSPORT", http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "File is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	fileBytes, err := io.ReadAll(file)
+	if err != nil {
+		logger.Errorf("Failed to read identity document: %v", err)
+		http.Error(w, "Failed to read file", http.StatusInternalServerError)
+		return
+	}
+
+	// Trust the bytes, not the client-supplied header
+	contentType := http.DetectContentType(fileBytes)
+	ext, ok := identityImageTypes[contentType]
+	if !ok {
+		http.Error(w, "ID must be a JPEG or PNG photo", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	// Name on file, when the client record exists
+	var firstName, lastName string
+	var clientID *uuid.UUID
+	if tenantUser.ClientID != NewClientUUID {
+		client, err := api.store.GetClientByID(r.Context(), tenantUser.TenantID, tenantUser.ClientID.String())
+		if err != nil {
+			logger.Errorf("Failed to get client %s for ID check: %v", tenantUser.ClientID, err)
+			writeError(w, err, "Failed to fetch client")
+			return
+		}
+		id := tenantUser.ClientID
+		clientID = &id
+		if client.FirstName != nil {
+			firstName = *client.FirstName
+		}
+		if client.LastName != nil {
+			lastName = *client.LastName
+		}
+	}
+
+	// A recognition outage should not block onboarding; the document goes to review instead
+	text, err := api.idExtractor.Extract(r.Context(), fileBytes)
+	if err != nil {
+		logger.Warningf("Text recognition failed for tenant user %s, sending ID to review: %v", tenantUser.ID, err)
+		text = ""
+	}
+	result := idcheck.Check(text, firstName, lastName, time.Now())
+
+	doc := &types.IdentityDocument{
+		ID:           uuid.New(),
+		TenantID:     tenantUser.TenantID,
+		TenantUserID: tenantUser.ID,
+		ClientID:     clientID,
+		Kind:         kind,
+		FileName:     header.Filename,
+		ContentType:  contentType,
+		SizeBytes:    int64(len(fileBytes)),
+		Status:       types.IdentityStatusVerified,
+		NameMatched:  result.NameMatched,
+		Flags:        result.Flags,
+		Provider:     api.idExtractor.Name(),
+	}
+	if len(doc.Flags) > 0 {
+		doc.Status = types.IdentityStatusNeedsReview
+	}
+	if result.ExpiresOn != nil {
+		expires := result.ExpiresOn.Format("2006-01-02")
+		doc.ExpiresOn = &expires
+	}
+	doc.StoragePath = fmt.Sprintf("restricted/identity/%s/%s%s", tenantUser.ID, doc.ID, ext)
+
+	tc, err := api.store.GetTenantConfig(tenantUser.TenantID)
+	if err != nil {
+		logger.Errorf("Failed to get tenant config: %v", err)
+		writeError(w, err, "Failed to get tenant configuration")
+		return
+	}
+
+	storageProvider, err := api.storageForTenant(context.Background(), tc)
+	if err != nil {
+		logger.Errorf("Failed to create storage provider: %v", err)
+		http.Error(w, "Failed to initialize storage", http.StatusInternalServerError)
+		return
+	}
+
+	metadata := map[string]string{
+		"tenant_id":      tenantUser.TenantID,
+		"tenant_user_id": tenantUser.ID.String(),
+		"document_type":  "IDENTITY_" + kind,
+		"visibility":     "restricted",
+	}
+	if err := storageProvider.Upload(context.Background(), tc.StorageBucket, doc.StoragePath, bytes.NewReader(fileBytes), metadata); err != nil {
+		logger.Errorf("Failed to upload identity document: %v", err)
+		http.Error(w, "Failed to upload file", http.StatusInternalServerError)
+		return
+	}
+
+	if err := api.store.CreateIdentityDocument(doc); err != nil {
+		storageProvider.Delete(context.Background(), tc.StorageBucket, doc.StoragePath)
+		writeError(w, err, "Failed to record identity document")
+		return
+	}
+
+	if doc.Status == types.IdentityStatusNeedsReview && api.notifier != nil {
+		go api.notifier.NotifyAdmins(
+			types.NotificationCategoryUpload,
+			&doc.TenantID,
+			"Identity document needs review",
+			fmt.Sprintf("Identity document %s uploaded by portal user %s in tenant %s was flagged: %s.", doc.ID, tenantUser.Email, doc.TenantID, strings.Join(doc.Flags, ", ")),
+		)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(doc); err != nil {
+		logger.Errorf("Failed to encode identity document response: %v", err)
+	}
+}
+
+// getPortalIdentityDocuments lists the user's uploaded IDs with their check status (tenant user only)
+// The images themselves are never served back to the portal
+func (api *API) getPortalIdentityDocuments(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	documents, err := api.store.GetTenantUserIdentityDocuments(tenantUser.ID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch identity documents")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(documents); err != nil {
+		logger.Errorf("Failed to encode identity documents response: %v", err)
+	}
+}
+
+// getIdentityDocuments lists a tenant's identity documents (admin only)
+// Query params: status=NEEDS_REVIEW for the review queue
+func (api *API) getIdentityDocuments(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var status *string
+	if s := strings.ToUpper(r.URL.Query().Get("status")); s != "" {
+		if !types.IsValidIdentityStatus(s) {
+			http.Error(w, "status must be VERIFIED, NEEDS_REVIEW or REJECTED", http.StatusBadRequest)
+			return
+		}
+		status = &s
+	}
+
+	documents, err := api.store.GetIdentityDocuments(tenantID, status)
+	if err != nil {
+		writeError(w, err, "Failed to fetch identity documents")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(documents); err != nil {
+		logger.Errorf("Failed to encode identity documents response: %v", err)
+	}
+}
+
+// getClientIdentityDocuments lists a client's identity documents (admin only)
+func (api *API) getClientIdentityDocuments(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	clientID, err := uuid.Parse(vars["clientId"])
+	if err != nil {
+		http.Error(w, "Invalid client ID", http.StatusBadRequest)
+		return
+	}
+
+	documents, err := api.store.GetClientIdentityDocuments(vars["tenantId"], clientID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch identity documents")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(documents); err != nil {
+		logger.Errorf("Failed to encode identity documents response: %v", err)
+	}
+}
+
+// downloadIdentityDocument streams an identity document image (admin only)
+// Unlike filing documents no signed URL is issued, so every view goes through the audit log
+func (api *API) downloadIdentityDocument(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	documentID, err := uuid.Parse(vars["documentId"])
+	if err != nil {
+		http.Error(w, "Invalid document ID", http.StatusBadRequest)
+		return
+	}
+
+	doc, err := api.store.GetIdentityDocument(tenantID, documentID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch identity document")
+		return
+	}
+
+	tc, err := api.store.GetTenantConfig(tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get tenant config: %v", err)
+		writeError(w, err, "Failed to get tenant configuration")
+		return
+	}
+
+	storageProvider, err := api.storageForTenant(context.Background(), tc)
+	if err != nil {
+		logger.Errorf("Failed to create storage provider: %v", err)
+		http.Error(w, "Failed to initialize storage", http.StatusInternalServerError)
+		return
+	}
+
+	reader, err := storageProvider.Download(context.Background(), tc.StorageBucket, doc.StoragePath)
+	if err != nil {
+		logger.Errorf("Failed to download identity document %s: %v", doc.ID, err)
+		http.Error(w, "Failed to download file", http.StatusInternalServerError)
+		return
+	}
+	defer reader.Close()
+
+	w.Header().Set("Content-Type", doc.ContentType)
+	w.Header().Set("Content-Disposition", "inline")
+	w.Header().Set("Cache-Control", "no-store")
+	if _, err := io.Copy(w, reader); err != nil {
+		logger.Errorf("Failed to stream identity document %s: %v", doc.ID, err)
+	}
+}
+
+// reviewIdentityDocument records an admin decision on a flagged identity document (admin only)
+func (api *API) reviewIdentityDocument(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	documentID, err := uuid.Parse(vars["documentId"])
+	if err != nil {
+		http.Error(w, "Invalid document ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		Status string  `json:"status"` // VERIFIED or REJECTED
+		Note   *string `json:"note"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Status = strings.ToUpper(strings.TrimSpace(req.Status))
+	if req.Status != types.IdentityStatusVerified && req.Status != types.IdentityStatusRejected {
+		http.Error(w, "status must be VERIFIED or REJECTED", http.StatusBadRequest)
+		return
+	}
+	if req.Status == types.IdentityStatusRejected && (req.Note == nil || strings.TrimSpace(*req.Note) == "") {
+		http.Error(w, "A note is required when rejecting", http.StatusBadRequest)
+		return
+	}
+
+	doc, err := api.store.ReviewIdentityDocument(vars["tenantId"], documentID, req.Status, req.Note, employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to review identity document")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(doc); err != nil {
+		logger.Errorf("Failed to encode identity document response: %v", err)
+	}
+}
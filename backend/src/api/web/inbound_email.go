@@ -0,0 +1,530 @@
+package webapi
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/mail"
+	"path"
+	"strconv"
+	"strings"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/notification"
+	"welltaxpro/src/internal/storage"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// InboundEmailConfig configures the SendGrid webhooks: inbound parse, which clients' emailed
+// documents arrive through, and the event webhook, which reports bounces and spam reports
+type InboundEmailConfig struct {
+	Domain          string // Lowercase domain of tenant inbound addresses; empty disables inbound email
+	WebhookKey      string // Secret the inbound parse URL must carry in its key parameter
+	EventWebhookKey string // Secret the event webhook URL must carry in its key parameter; empty disables it
+}
+
+// address returns the full inbound address for a local part
+func (c InboundEmailConfig) address(localPart string) string {
+	return localPart + "@" + c.Domain
+}
+
+// receiveInboundEmail accepts a SendGrid Inbound Parse post. The sender is matched to a client by
+// email and supported attachments are held in tenant storage until an admin confirms them.
+// Mail that cannot be routed is dropped with 200 so the provider does not retry it.
+func (api *API) receiveInboundEmail(w http.ResponseWriter, r *http.Request) {
+	if api.inbound.Domain == "" || api.inbound.WebhookKey == "" {
+		http.NotFound(w, r)
+		return
+	}
+	if subtle.ConstantTimeCompare([]byte(r.URL.Query().Get("key")), []byte(api.inbound.WebhookKey)) != 1 {
+		logger.Warningf("Rejected inbound email post with invalid key from %s", r.RemoteAddr)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		logger.Errorf("Failed to parse inbound email: %v", err)
+		http.Error(w, "Invalid inbound email", http.StatusBadRequest)
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	localPart := api.inboundLocalPart(r)
+	if localPart == "" {
+		logger.Warningf("Dropping inbound email not addressed to %s", api.inbound.Domain)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	tenantID, err := api.store.GetTenantByInboundLocalPart(localPart)
+	if err != nil {
+		logger.Warningf("Dropping inbound email to %s: %v", api.inbound.address(localPart), err)
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	from, err := mail.ParseAddress(r.FormValue("from"))
+	if err != nil {
+		logger.Warningf("Dropping inbound email to tenant %s with unparseable sender %q", tenantID, r.FormValue("from"))
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	email := &types.InboundEmail{
+		ID:        uuid.New(),
+		TenantID:  tenantID,
+		FromEmail: strings.ToLower(from.Address),
+	}
+	if from.Name != "" {
+		email.FromName = &from.Name
+	}
+	if subject := strings.TrimSpace(r.FormValue("subject")); subject != "" {
+		email.Subject = &subject
+	}
+
+	client, err := api.inboundSender(r.Context(), tenantID, email.FromEmail)
+	if err != nil {
+		logger.Errorf("Failed to match inbound email sender for tenant %s: %v", tenantID, err)
+		http.Error(w, "Failed to process inbound email", http.StatusInternalServerError)
+		return
+	}
+	if client != nil {
+		email.ClientID = &client.ID
+	}
+
+	tc, err := api.store.GetTenantConfig(tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get tenant config for inbound email: %v", err)
+		http.Error(w, "Failed to process inbound email", http.StatusInternalServerError)
+		return
+	}
+	provider, err := api.storageForTenant(context.Background(), tc)
+	if err != nil {
+		logger.Errorf("Failed to create storage provider for inbound email: %v", err)
+		http.Error(w, "Failed to process inbound email", http.StatusInternalServerError)
+		return
+	}
+
+	cleanup := func() {
+		for _, a := range email.Attachments {
+			provider.Delete(context.Background(), tc.StorageBucket, a.StoragePath)
+		}
+	}
+	for _, headers := range r.MultipartForm.File {
+		for _, header := range headers {
+			attachment, err := storeInboundAttachment(provider, tc.StorageBucket, email, header)
+			if err != nil {
+				logger.Errorf("Failed to store inbound attachment %s: %v", header.Filename, err)
+				cleanup()
+				http.Error(w, "Failed to store attachment", http.StatusInternalServerError)
+				return
+			}
+			if attachment == nil {
+				email.SkippedAttachments++
+				continue
+			}
+			email.Attachments = append(email.Attachments, attachment)
+		}
+	}
+
+	if err := api.store.CreateInboundEmail(email); err != nil {
+		cleanup()
+		http.Error(w, "Failed to record inbound email", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Infof("Inbound email %s for tenant %s from %s: %d attachments kept, %d skipped",
+		email.ID, tenantID, email.FromEmail, len(email.Attachments), email.SkippedAttachments)
+
+	if len(email.Attachments) > 0 {
+		sender := email.FromEmail
+		if client == nil {
+			sender += " (no matching client)"
+		}
+		api.notifier.NotifyAdmins(types.NotificationCategoryUpload, &tenantID,
+			fmt.Sprintf("%d emailed documents to review", len(email.Attachments)),
+			fmt.Sprintf("%s emailed %d documents to %s. Confirm or reject them under the tenant's inbound documents.",
+				sender, len(email.Attachments), tc.TenantName),
+		)
+	}
+
+	// Only acknowledge known clients, so the inbound address cannot be used to send mail to strangers
+	if client != nil && len(email.Attachments) > 0 && api.emailService != nil {
+		api.acknowledgeInboundEmail(tc, client, email)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// inboundLocalPart returns the local part of the first recipient at the inbound domain,
+// preferring the SMTP envelope over the To header
+func (api *API) inboundLocalPart(r *http.Request) string {
+	var recipients []string
+	var envelope struct {
+		To []string `json:"to"`
+	}
+	if err := json.Unmarshal([]byte(r.FormValue("envelope")), &envelope); err == nil {
+		recipients = envelope.To
+	}
+	if list, err := mail.ParseAddressList(r.FormValue("to")); err == nil {
+		for _, a := range list {
+			recipients = append(recipients, a.Address)
+		}
+	}
+
+	for _, recipient := range recipients {
+		at := strings.LastIndex(recipient, "@")
+		if at > 0 && strings.EqualFold(recipient[at+1:], api.inbound.Domain) {
+			return strings.ToLower(recipient[:at])
+		}
+	}
+	return ""
+}
+
+// inboundSender returns the one client with the sender's email, or nil when none or several have it
+func (api *API) inboundSender(ctx context.Context, tenantID, fromEmail string) (*types.Client, error) {
+	clients, err := api.store.GetClients(ctx, tenantID, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var match *types.Client
+	for _, c := range clients {
+		if strings.EqualFold(c.Email, fromEmail) {
+			if match != nil {
+				logger.Warningf("Several clients in tenant %s have email %s; leaving inbound email unmatched", tenantID, fromEmail)
+				return nil, nil
+			}
+			match = c
+		}
+	}
+	return match, nil
+}
+
+// storeInboundAttachment saves a supported attachment under inbound/{emailId}/ in tenant storage.
+// It returns nil without an error for attachments that are too large or of an unsupported type.
+func storeInboundAttachment(provider storage.StorageProvider, bucket string, email *types.InboundEmail, header *multipart.FileHeader) (*types.InboundAttachment, error) {
+	if header.Size > maxUploadSize {
+		return nil, nil
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+
+	// Trust the content, not the type the sender's mail client declared
+	contentType := http.DetectContentType(data)
+	if !types.InboundAttachmentTypes[contentType] {
+		return nil, nil
+	}
+
+	name := path.Base(strings.ReplaceAll(header.Filename, "\\", "/"))
+	if name == "." || name == "/" {
+		name = "attachment"
+	}
+	sum := sha256.Sum256(data)
+	ext := path.Ext(name)
+	storagePath := fmt.Sprintf("inbound/%s/%s_%s%s", email.ID, strings.TrimSuffix(name, ext), hex.EncodeToString(sum[:])[:16], ext)
+
+	metadata := map[string]string{
+		"tenant_id":     email.TenantID,
+		"original_name": name,
+		"source":        "inbound_email",
+		"from_email":    email.FromEmail,
+	}
+	if err := provider.Upload(context.Background(), bucket, storagePath, strings.NewReader(string(data)), metadata); err != nil {
+		return nil, err
+	}
+
+	return &types.InboundAttachment{
+		FileName:    name,
+		ContentType: contentType,
+		SizeBytes:   int64(len(data)),
+		StoragePath: storagePath,
+	}, nil
+}
+
+// acknowledgeInboundEmail tells a client which of their emailed files were received
+func (api *API) acknowledgeInboundEmail(tc *types.TenantConnection, client *types.Client, email *types.InboundEmail) {
+	name := "there"
+	if client.FirstName != nil && *client.FirstName != "" {
+		name = *client.FirstName
+	}
+	files := make([]string, len(email.Attachments))
+	for i, a := range email.Attachments {
+		files[i] = a.FileName
+	}
+
+	subject, htmlBody, textBody := notification.GenerateInboundAckEmail(notification.InboundAckEmail{
+		ClientName: name,
+		TenantName: tc.TenantName,
+		Files:      files,
+	})
+	err := api.emailService.Send(&notification.Email{
+		TenantID: tc.TenantID,
+		To:       client.Email,
+		ToName:   name,
+		Subject:  subject,
+		HTMLBody: htmlBody,
+		TextBody: textBody,
+	})
+	if err != nil {
+		logger.Errorf("Failed to acknowledge inbound email %s: %v", email.ID, err)
+		return
+	}
+	if err := api.store.MarkInboundEmailAcknowledged(email.ID); err != nil {
+		logger.Errorf("Failed to record acknowledgment of inbound email %s: %v", email.ID, err)
+	}
+}
+
+// getInboundAddress returns the address a tenant's clients email documents to (admin only)
+func (api *API) getInboundAddress(w http.ResponseWriter, r *http.Request) {
+	if api.inbound.Domain == "" {
+		http.Error(w, "Inbound email is not configured", http.StatusNotFound)
+		return
+	}
+
+	address, err := api.store.GetInboundAddress(mux.Vars(r)["tenantId"])
+	if err != nil {
+		writeError(w, err, "Failed to fetch inbound address")
+		return
+	}
+	address.Address = api.inbound.address(address.LocalPart)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(address); err != nil {
+		logger.Errorf("Failed to encode inbound address response: %v", err)
+	}
+}
+
+// rotateInboundAddress gives a tenant a new random inbound address; mail to the old one is dropped (admin only)
+func (api *API) rotateInboundAddress(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if api.inbound.Domain == "" {
+		http.Error(w, "Inbound email is not configured", http.StatusNotFound)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+	if _, err := api.store.GetTenantConfig(tenantID); err != nil {
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	// The random suffix keeps addresses from being guessed from the tenant ID
+	suffix := make([]byte, 5)
+	if _, err := rand.Read(suffix); err != nil {
+		logger.Errorf("Failed to generate inbound address: %v", err)
+		http.Error(w, "Failed to generate inbound address", http.StatusInternalServerError)
+		return
+	}
+	localPart := strings.ToLower(tenantID) + "-" + hex.EncodeToString(suffix)
+
+	address, err := api.store.SetInboundAddress(tenantID, localPart, employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to set inbound address")
+		return
+	}
+	address.Address = api.inbound.address(address.LocalPart)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(address); err != nil {
+		logger.Errorf("Failed to encode inbound address response: %v", err)
+	}
+}
+
+// getInboundDocuments lists emailed attachments, pending ones by default (admin only)
+func (api *API) getInboundDocuments(w http.ResponseWriter, r *http.Request) {
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		status = types.InboundAttachmentPending
+	}
+	if status == "ALL" {
+		status = ""
+	} else if status != types.InboundAttachmentPending && status != types.InboundAttachmentConfirmed && status != types.InboundAttachmentRejected {
+		http.Error(w, "Invalid status", http.StatusBadRequest)
+		return
+	}
+
+	limit := 100
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 || parsed > 500 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	attachments, err := api.store.GetInboundAttachments(mux.Vars(r)["tenantId"], status, limit)
+	if err != nil {
+		writeError(w, err, "Failed to fetch inbound documents")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(attachments); err != nil {
+		logger.Errorf("Failed to encode inbound documents response: %v", err)
+	}
+}
+
+// confirmInboundDocument files a pending emailed attachment as a document of a client's filing (admin only)
+func (api *API) confirmInboundDocument(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	attachmentID, err := uuid.Parse(vars["attachmentId"])
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		ClientID     *uuid.UUID `json:"clientId"` // Defaults to the client matched from the sender
+		FilingID     uuid.UUID  `json:"filingId"`
+		DocumentType string     `json:"documentType"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.FilingID == uuid.Nil || req.DocumentType == "" {
+		http.Error(w, "filingId and documentType are required", http.StatusBadRequest)
+		return
+	}
+
+	attachment, err := api.store.GetInboundAttachment(tenantID, attachmentID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch inbound document")
+		return
+	}
+	if attachment.Status != types.InboundAttachmentPending {
+		http.Error(w, "Inbound document has already been reviewed", http.StatusConflict)
+		return
+	}
+	clientID := req.ClientID
+	if clientID == nil {
+		clientID = attachment.ClientID
+	}
+	if clientID == nil {
+		http.Error(w, "clientId is required; the sender did not match a client", http.StatusBadRequest)
+		return
+	}
+
+	comprehensive, err := api.store.GetClientComprehensive(r.Context(), tenantID, clientID.String())
+	if err != nil {
+		writeError(w, err, "Failed to fetch client")
+		return
+	}
+	found := false
+	for _, f := range comprehensive.Filings {
+		found = found || f.ID == req.FilingID
+	}
+	if !found {
+		http.Error(w, fmt.Sprintf("Filing %s does not belong to client %s", req.FilingID, clientID), http.StatusBadRequest)
+		return
+	}
+
+	// The document keeps the file where the email left it
+	document, err := api.store.CreateDocument(r.Context(), tenantID, &types.Document{
+		ID:       uuid.New(),
+		UserID:   *clientID,
+		FilingID: &req.FilingID,
+		Name:     attachment.FileName,
+		FilePath: attachment.StoragePath,
+		Type:     req.DocumentType,
+	})
+	if err != nil {
+		writeError(w, err, "Failed to create document")
+		return
+	}
+
+	attachment.Status = types.InboundAttachmentConfirmed
+	attachment.ClientID, attachment.FilingID, attachment.DocumentID = clientID, &req.FilingID, &document.ID
+	if err := api.store.ReviewInboundAttachment(attachment, employee.ID); err != nil {
+		// Another admin reviewed it first; drop the duplicate record but not the shared file
+		if delErr := api.store.DeleteDocument(r.Context(), tenantID, document.ID.String()); delErr != nil {
+			logger.Errorf("Failed to remove unrecorded inbound document %s: %v", document.ID, delErr)
+		}
+		writeError(w, err, "Failed to confirm inbound document")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(attachment); err != nil {
+		logger.Errorf("Failed to encode inbound document response: %v", err)
+	}
+}
+
+// rejectInboundDocument discards a pending emailed attachment and its stored file (admin only)
+func (api *API) rejectInboundDocument(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	attachmentID, err := uuid.Parse(vars["attachmentId"])
+	if err != nil {
+		http.Error(w, "Invalid attachment ID", http.StatusBadRequest)
+		return
+	}
+
+	attachment, err := api.store.GetInboundAttachment(tenantID, attachmentID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch inbound document")
+		return
+	}
+
+	attachment.Status = types.InboundAttachmentRejected
+	if err := api.store.ReviewInboundAttachment(attachment, employee.ID); err != nil {
+		writeError(w, err, "Failed to reject inbound document")
+		return
+	}
+
+	tc, err := api.store.GetTenantConfig(tenantID)
+	if err == nil {
+		var provider storage.StorageProvider
+		if provider, err = api.storageForTenant(context.Background(), tc); err == nil {
+			err = provider.Delete(context.Background(), tc.StorageBucket, attachment.StoragePath)
+		}
+	}
+	if err != nil {
+		logger.Errorf("Failed to delete rejected inbound document %s from storage: %v", attachmentID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(attachment); err != nil {
+		logger.Errorf("Failed to encode inbound document response: %v", err)
+	}
+}
@@ -0,0 +1,37 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// getIntegrityChecks flags SSNs shared across client records within a tenant (admin only)
+// Each issue includes remediation links (review, merge, archive)
+func (api *API) getIntegrityChecks(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	logger.Infof("Running integrity checks for tenant %s", tenantID)
+
+	issues, err := api.store.RunIntegrityChecks(r.Context(), tenantID)
+	if err != nil {
+		logger.Errorf("Failed to run integrity checks for tenant %s: %v", tenantID, err)
+		writeError(w, err, "Failed to run integrity checks")
+		return
+	}
+
+	if issues == nil {
+		issues = []*types.IntegrityIssue{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(issues); err != nil {
+		logger.Errorf("Failed to encode integrity checks response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
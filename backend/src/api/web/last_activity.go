@@ -0,0 +1,108 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// maxDormantDays bounds the dormantDays query parameter
+const maxDormantDays = 3650
+
+// dormantSince reads the dormantDays query parameter as the time before which activity counts
+// as dormant. It returns nil when the parameter is absent and a message when it is invalid.
+func dormantSince(r *http.Request) (*time.Time, string) {
+	daysStr := r.URL.Query().Get("dormantDays")
+	if daysStr == "" {
+		return nil, ""
+	}
+	days, err := strconv.Atoi(daysStr)
+	if err != nil || days < 1 || days > maxDormantDays {
+		return nil, "dormantDays must be between 1 and 3650"
+	}
+	since := time.Now().AddDate(0, 0, -days)
+	return &since, ""
+}
+
+// getPortalUsers returns a tenant's portal users with their last sign-in, newest first (admin only)
+// Query params: active=true for active users only, dormantDays=N for users who have not signed in
+// for N days (counted from registration for users who never signed in)
+func (api *API) getPortalUsers(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	filter := &types.TenantUserFilter{ActiveOnly: r.URL.Query().Get("active") == "true"}
+	since, msg := dormantSince(r)
+	if msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	filter.DormantSince = since
+
+	users, err := api.store.GetTenantUsersByTenant(tenantID, filter)
+	if err != nil {
+		writeError(w, err, "Failed to fetch portal users")
+		return
+	}
+	if users == nil {
+		users = []*types.TenantUser{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(users); err != nil {
+		logger.Errorf("Failed to encode portal users response: %v", err)
+	}
+}
+
+// getAffiliateTokenPolicy returns the effective affiliate token policy for a tenant (admin only)
+func (api *API) getAffiliateTokenPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, err := api.store.GetAffiliateTokenPolicy(mux.Vars(r)["tenantId"])
+	if err != nil {
+		writeError(w, err, "Failed to fetch affiliate token policy")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(policy); err != nil {
+		logger.Errorf("Failed to encode affiliate token policy response: %v", err)
+	}
+}
+
+// updateAffiliateTokenPolicy replaces the affiliate token policy for a tenant (admin only). The
+// worker applies it on its next daily run.
+func (api *API) updateAffiliateTokenPolicy(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var policy types.AffiliateTokenPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := policy.Validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Updating affiliate token policy for tenant %s", tenantID)
+
+	if err := api.store.UpdateAffiliateTokenPolicy(tenantID, &policy, employee.ID); err != nil {
+		writeError(w, err, "Failed to update affiliate token policy")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(policy); err != nil {
+		logger.Errorf("Failed to encode affiliate token policy response: %v", err)
+	}
+}
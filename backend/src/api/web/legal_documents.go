@@ -0,0 +1,176 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// getLegalDocuments lists a tenant's legal document versions
+// Optional query: ?active=true
+func (api *API) getLegalDocuments(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	documents, err := api.store.GetLegalDocuments(tenantID, r.URL.Query().Get("active") == "true")
+	if err != nil {
+		logger.Errorf("Failed to get legal documents for tenant %s: %v", tenantID, err)
+		writeError(w, err, "Failed to fetch legal documents")
+		return
+	}
+
+	if documents == nil {
+		documents = []*types.LegalDocument{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(documents); err != nil {
+		logger.Errorf("Failed to encode legal documents response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// createLegalDocument publishes a new version of the terms of service or privacy policy (admin only)
+// Portal users are blocked from their data until they accept the new version
+func (api *API) createLegalDocument(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var req struct {
+		Kind  string `json:"kind"`
+		Title string `json:"title"`
+		Body  string `json:"body"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !types.IsValidLegalDocumentKind(req.Kind) {
+		http.Error(w, "Invalid kind. Must be one of: "+strings.Join(types.LegalDocumentKinds, ", "), http.StatusBadRequest)
+		return
+	}
+	req.Title = strings.TrimSpace(req.Title)
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Title == "" || req.Body == "" {
+		http.Error(w, "title and body are required", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := api.store.GetTenantConfig(tenantID); err != nil {
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	document := &types.LegalDocument{
+		TenantID:  tenantID,
+		Kind:      req.Kind,
+		Title:     req.Title,
+		Body:      req.Body,
+		CreatedBy: &employee.ID,
+	}
+	if err := api.store.CreateLegalDocument(document); err != nil {
+		logger.Errorf("Failed to create legal document: %v", err)
+		writeError(w, err, "Failed to create legal document")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(document); err != nil {
+		logger.Errorf("Failed to encode legal document response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// getPortalLegalDocuments returns every active legal document with whether the user accepted
+// its current version (tenant user only)
+func (api *API) getPortalLegalDocuments(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	documents, err := api.store.GetLegalDocuments(tenantUser.TenantID, true)
+	if err != nil {
+		logger.Errorf("Failed to get legal documents for tenant %s: %v", tenantUser.TenantID, err)
+		writeError(w, err, "Failed to fetch legal documents")
+		return
+	}
+
+	acceptances, err := api.store.GetLegalAcceptances(tenantUser.TenantID, tenantUser.ID)
+	if err != nil {
+		logger.Errorf("Failed to get legal acceptances for tenant user %s: %v", tenantUser.ID, err)
+		writeError(w, err, "Failed to fetch legal documents")
+		return
+	}
+
+	// Acceptances are newest first, so the first per kind is the latest
+	latest := map[string]*types.LegalAcceptance{}
+	for _, a := range acceptances {
+		if latest[a.Kind] == nil {
+			latest[a.Kind] = a
+		}
+	}
+
+	statuses := make([]*types.LegalDocumentStatus, 0, len(documents))
+	for _, d := range documents {
+		acceptance := latest[d.Kind]
+		statuses = append(statuses, &types.LegalDocumentStatus{
+			Document:   d,
+			Acceptance: acceptance,
+			Accepted:   acceptance != nil && acceptance.DocumentID == d.ID,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(statuses); err != nil {
+		logger.Errorf("Failed to encode portal legal documents response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// acceptPortalLegalDocument records the user's acceptance of a current legal document (tenant user only)
+func (api *API) acceptPortalLegalDocument(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	documentID, err := uuid.Parse(mux.Vars(r)["documentId"])
+	if err != nil {
+		http.Error(w, "Invalid document ID", http.StatusBadRequest)
+		return
+	}
+
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	acceptance, err := api.store.AcceptLegalDocument(tenantUser.TenantID, tenantUser.ID, documentID, &ipAddress, &userAgent)
+	if err != nil {
+		logger.Errorf("Failed to accept legal document %s for tenant user %s: %v", documentID, tenantUser.ID, err)
+		writeError(w, err, "Failed to accept legal document")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(acceptance); err != nil {
+		logger.Errorf("Failed to encode legal acceptance response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,447 @@
+package webapi
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/mailing"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/storage"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// maxMailingDocuments caps the letters one mailing request sends
+const maxMailingDocuments = 20
+
+// getMailSettings returns the return address printed on a tenant's mailings (admin only)
+func (api *API) getMailSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := api.store.GetMailSettings(mux.Vars(r)["tenantId"])
+	if err != nil {
+		writeError(w, err, "Failed to fetch mail settings")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(settings); err != nil {
+		logger.Errorf("Failed to encode mail settings response: %v", err)
+	}
+}
+
+// setMailSettings sets the return address printed on a tenant's mailings (admin only)
+func (api *API) setMailSettings(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var req struct {
+		ReturnName    string        `json:"returnName"`
+		ReturnAddress types.Address `json:"returnAddress"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.ReturnName = strings.TrimSpace(req.ReturnName)
+	if req.ReturnName == "" {
+		http.Error(w, "returnName is required", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := api.store.GetTenantConfig(tenantID); err != nil {
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	// The provider refuses letters whose return address it cannot deliver to
+	validation, err := api.addressValidator.Validate(r.Context(), req.ReturnAddress)
+	if err != nil {
+		logger.Errorf("Return address validation failed for tenant %s: %v", tenantID, err)
+		http.Error(w, "Address validation service unavailable", http.StatusBadGateway)
+		return
+	}
+	if !validation.Deliverable || validation.Standardized == nil {
+		http.Error(w, fmt.Sprintf("Return address is not deliverable (%s)", strings.Join(validation.Footnotes, ", ")), http.StatusBadRequest)
+		return
+	}
+
+	settings := &types.MailSettings{
+		TenantID:      tenantID,
+		ReturnName:    req.ReturnName,
+		ReturnAddress: *validation.Standardized,
+		UpdatedBy:     &employee.ID,
+	}
+	if err := api.store.SetMailSettings(settings); err != nil {
+		writeError(w, err, "Failed to save mail settings")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(settings); err != nil {
+		logger.Errorf("Failed to encode mail settings response: %v", err)
+	}
+}
+
+// createMailing prints and mails a set of a client's PDF documents to the client's validated
+// address, one letter per document (admin only)
+func (api *API) createMailing(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if api.mailer.Name() == mailing.ProviderNone {
+		http.Error(w, "Print and mail is not configured", http.StatusNotFound)
+		return
+	}
+
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	clientID, err := uuid.Parse(vars["clientId"])
+	if err != nil {
+		http.Error(w, "Invalid client ID", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		DocumentIDs []uuid.UUID `json:"documentIds"`
+		Description string      `json:"description"`
+		Color       bool        `json:"color"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if len(req.DocumentIDs) == 0 || len(req.DocumentIDs) > maxMailingDocuments {
+		http.Error(w, fmt.Sprintf("documentIds must list 1 to %d documents", maxMailingDocuments), http.StatusBadRequest)
+		return
+	}
+
+	settings, err := api.store.GetMailSettings(tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch mail settings")
+		return
+	}
+
+	client, err := api.store.GetClientByID(r.Context(), tenantID, clientID.String())
+	if err != nil {
+		writeError(w, err, "Failed to fetch client")
+		return
+	}
+	address, err := api.mailingAddress(tenantID, client)
+	if err != nil {
+		writeError(w, err, "Failed to fetch client address")
+		return
+	}
+
+	// Check every document before anything is sent
+	documents := make([]*types.Document, 0, len(req.DocumentIDs))
+	seen := map[uuid.UUID]bool{}
+	for _, id := range req.DocumentIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		document, err := api.store.GetDocumentByID(r.Context(), tenantID, id.String())
+		if err != nil {
+			writeError(w, err, "Failed to fetch document")
+			return
+		}
+		if document.UserID != clientID {
+			http.Error(w, fmt.Sprintf("Document %s does not belong to the client", id), http.StatusBadRequest)
+			return
+		}
+		if err := api.store.CheckDocumentScan(tenantID, id.String()); err != nil {
+			writeError(w, err, "Failed to check document scan")
+			return
+		}
+		documents = append(documents, document)
+	}
+
+	tc, err := api.store.GetTenantConfig(tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to get tenant configuration")
+		return
+	}
+	provider, err := api.storageForTenant(context.Background(), tc)
+	if err != nil {
+		logger.Errorf("Failed to create storage provider: %v", err)
+		http.Error(w, "Failed to initialize storage", http.StatusInternalServerError)
+		return
+	}
+
+	files := make([][]byte, len(documents))
+	for i, document := range documents {
+		data, err := downloadFile(r.Context(), provider, tc.StorageBucket, document.FilePath)
+		if err != nil {
+			logger.Errorf("Failed to read document %s for mailing: %v", document.ID, err)
+			http.Error(w, "Failed to read document", http.StatusInternalServerError)
+			return
+		}
+		if http.DetectContentType(data) != "application/pdf" {
+			http.Error(w, fmt.Sprintf("Document %s is not a PDF", document.Name), http.StatusBadRequest)
+			return
+		}
+		files[i] = data
+	}
+
+	m := &types.Mailing{
+		TenantID:      tenantID,
+		ClientID:      clientID,
+		RecipientName: clientName(client),
+		Address:       *address,
+		Color:         req.Color,
+		RequestedBy:   &employee.ID,
+	}
+	if d := strings.TrimSpace(req.Description); d != "" {
+		m.Description = &d
+	}
+	for _, document := range documents {
+		m.Pieces = append(m.Pieces, &types.MailPiece{
+			DocumentID:   document.ID,
+			DocumentName: document.Name,
+			Provider:     api.mailer.Name(),
+		})
+	}
+	if err := api.store.CreateMailing(m); err != nil {
+		writeError(w, err, "Failed to create mailing")
+		return
+	}
+
+	// Pieces are recorded first so each letter is sent under its piece ID as the idempotency key
+	for i, piece := range m.Pieces {
+		description := piece.DocumentName
+		if m.Description != nil {
+			description = *m.Description + ": " + piece.DocumentName
+		}
+		receipt, err := api.mailer.Send(r.Context(), &mailing.Letter{
+			Description:    description,
+			To:             mailing.Recipient{Name: m.RecipientName, Address: m.Address},
+			From:           mailing.Recipient{Name: settings.ReturnName, Address: settings.ReturnAddress},
+			File:           files[i],
+			Color:          m.Color,
+			IdempotencyKey: piece.ID.String(),
+		})
+		if err != nil {
+			logger.Errorf("Failed to send mail piece %s: %v", piece.ID, err)
+			message := err.Error()
+			piece.Status, piece.Error = types.MailStatusFailed, &message
+		} else {
+			piece.Status, piece.ProviderID = types.MailStatusSubmitted, &receipt.ProviderID
+			piece.CostCents, piece.ExpectedDelivery = receipt.CostCents, receipt.ExpectedDelivery
+			m.CostCents += receipt.CostCents
+		}
+		if err := api.store.RecordMailPieceSent(piece); err != nil {
+			writeError(w, err, "Failed to record mail piece")
+			return
+		}
+	}
+
+	logger.Infof("Mailing %s for client %s sent by %s", m.ID, clientID, employee.Email)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(m); err != nil {
+		logger.Errorf("Failed to encode mailing response: %v", err)
+	}
+}
+
+// mailingAddress returns the standardized address from the client's latest validation. The
+// validation must be deliverable and of the client's current street address and ZIP.
+func (api *API) mailingAddress(tenantID string, client *types.Client) (*types.Address, error) {
+	validation, err := api.store.GetLatestAddressValidation(tenantID, types.AddressEntityClient, client.ID)
+	if err != nil {
+		return nil, err
+	}
+	if !validation.Deliverable || validation.Standardized == nil {
+		return nil, apperr.Conflict("the client's address was validated as undeliverable")
+	}
+
+	line1, zip := "", ""
+	if client.Address1 != nil {
+		line1 = *client.Address1
+	}
+	if client.Zipcode != nil {
+		zip = fmt.Sprintf("%05d", *client.Zipcode)
+	}
+	original := validation.Original
+	if !strings.EqualFold(strings.Join(strings.Fields(original.Line1), " "), strings.Join(strings.Fields(line1), " ")) ||
+		!strings.HasPrefix(strings.TrimSpace(original.Zipcode), zip) {
+		return nil, apperr.Conflict("the client's address changed since it was validated; validate it again")
+	}
+	return validation.Standardized, nil
+}
+
+// clientName returns a client's full name, or their email when no name is on file
+func clientName(c *types.Client) string {
+	var parts []string
+	for _, p := range []*string{c.FirstName, c.LastName} {
+		if p != nil && strings.TrimSpace(*p) != "" {
+			parts = append(parts, strings.TrimSpace(*p))
+		}
+	}
+	if len(parts) == 0 {
+		return c.Email
+	}
+	return strings.Join(parts, " ")
+}
+
+// downloadFile reads a stored file into memory
+func downloadFile(ctx context.Context, provider storage.StorageProvider, bucket, path string) ([]byte, error) {
+	rc, err := provider.Download(ctx, bucket, path)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	buf := &bytes.Buffer{}
+	if _, err := io.Copy(buf, rc); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// getMailings lists a tenant's mailings with their pieces' statuses and costs (admin only)
+func (api *API) getMailings(w http.ResponseWriter, r *http.Request) {
+	var clientID *uuid.UUID
+	if c := r.URL.Query().Get("clientId"); c != "" {
+		parsed, err := uuid.Parse(c)
+		if err != nil {
+			http.Error(w, "Invalid client ID", http.StatusBadRequest)
+			return
+		}
+		clientID = &parsed
+	}
+
+	limit := 100
+	if l := r.URL.Query().Get("limit"); l != "" {
+		parsed, err := strconv.Atoi(l)
+		if err != nil || parsed < 1 || parsed > 500 {
+			http.Error(w, "Invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	mailings, err := api.store.GetMailings(mux.Vars(r)["tenantId"], clientID, limit)
+	if err != nil {
+		writeError(w, err, "Failed to fetch mailings")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(mailings); err != nil {
+		logger.Errorf("Failed to encode mailings response: %v", err)
+	}
+}
+
+// getMailCosts totals a tenant's mailing costs per month for billing (admin only).
+// from and to are months (YYYY-MM), both included; the default is the last 12 months.
+func (api *API) getMailCosts(w http.ResponseWriter, r *http.Request) {
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), now.Month()-11, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	for param, month := range map[string]*time.Time{"from": &from, "to": &to} {
+		if v := r.URL.Query().Get(param); v != "" {
+			parsed, err := time.Parse("2006-01", v)
+			if err != nil {
+				http.Error(w, fmt.Sprintf("Invalid %s; use YYYY-MM", param), http.StatusBadRequest)
+				return
+			}
+			*month = parsed
+		}
+	}
+	if to.Before(from) {
+		http.Error(w, "to must not be before from", http.StatusBadRequest)
+		return
+	}
+
+	costs, err := api.store.GetMailCosts(mux.Vars(r)["tenantId"], from, to.AddDate(0, 1, 0))
+	if err != nil {
+		writeError(w, err, "Failed to fetch mailing costs")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(costs); err != nil {
+		logger.Errorf("Failed to encode mailing costs response: %v", err)
+	}
+}
+
+// receiveMailingWebhook applies a print-and-mail provider's status event to its piece.
+// Events for unknown letters are acknowledged so the provider stops retrying them.
+func (api *API) receiveMailingWebhook(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	event, err := api.mailer.ParseWebhook(r.Header, body)
+	switch {
+	case errors.Is(err, mailing.ErrDisabled):
+		http.NotFound(w, r)
+		return
+	case errors.Is(err, mailing.ErrInvalidSignature):
+		logger.Warningf("Rejected mailing webhook with invalid signature from %s", r.RemoteAddr)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	case err != nil:
+		logger.Warningf("Rejected mailing webhook: %v", err)
+		http.Error(w, "Invalid webhook", http.StatusBadRequest)
+		return
+	}
+
+	if event.Status == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	piece, err := api.store.GetMailPieceByProviderID(api.mailer.Name(), event.ProviderID)
+	if err != nil {
+		if apperr.Status(err) == http.StatusNotFound {
+			logger.Warningf("Ignoring mailing webhook: %v", err)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		http.Error(w, "Failed to process webhook", http.StatusInternalServerError)
+		return
+	}
+	if !types.MailStatusAdvances(piece.Status, event.Status) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	advanced, err := api.store.AdvanceMailPiece(piece, event.Status)
+	if err != nil {
+		http.Error(w, "Failed to process webhook", http.StatusInternalServerError)
+		return
+	}
+
+	if advanced && (event.Status == types.MailStatusReturned || event.Status == types.MailStatusFailed) {
+		tenantID := piece.TenantID
+		api.notifier.NotifyAdmins(types.NotificationCategoryMailing, &tenantID,
+			fmt.Sprintf("Mailed document %s was %s", piece.DocumentName, strings.ToLower(event.Status)),
+			fmt.Sprintf("The letter carrying %s in mailing %s on %s was reported %s by %s. Check the client's address before mailing it again.",
+				piece.DocumentName, piece.MailingID, tenantID, strings.ToLower(event.Status), piece.Provider),
+		)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
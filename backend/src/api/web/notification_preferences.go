@@ -0,0 +1,236 @@
+package webapi
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// getNotificationPreferences handles GET /api/v1/employees/me/notification-preferences
+// Returns the delivery mode and channels for every notification category
+func (api *API) getNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	prefs, err := api.store.GetNotificationPreferences(employee.ID)
+	if err != nil {
+		logger.Errorf("Failed to get notification preferences for %s: %v", employee.Email, err)
+		writeError(w, err, "Failed to fetch notification preferences")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(prefs); err != nil {
+		logger.Errorf("Failed to encode notification preferences response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// updateNotificationPreferences handles PUT /api/v1/employees/me/notification-preferences
+// Categories omitted from the request keep their current settings, as do email and slack when a
+// category leaves them out
+func (api *API) updateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req []*struct {
+		Category string `json:"category"`
+		Mode     string `json:"mode"`
+		Email    *bool  `json:"email"`
+		Slack    *bool  `json:"slack"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	current, err := api.store.GetNotificationPreferences(employee.ID)
+	if err != nil {
+		logger.Errorf("Failed to get notification preferences for %s: %v", employee.Email, err)
+		writeError(w, err, "Failed to fetch notification preferences")
+		return
+	}
+	byCategory := map[string]*types.NotificationPreference{}
+	for _, p := range current {
+		byCategory[p.Category] = p
+	}
+
+	prefs := make([]*types.NotificationPreference, 0, len(req))
+	for _, p := range req {
+		if p == nil || !types.IsValidNotificationCategory(p.Category) {
+			http.Error(w, "Invalid notification category", http.StatusBadRequest)
+			return
+		}
+		if !types.IsValidNotificationMode(p.Mode) {
+			http.Error(w, "mode must be immediate, digest or off", http.StatusBadRequest)
+			return
+		}
+		pref := *byCategory[p.Category]
+		pref.Mode = p.Mode
+		if p.Email != nil {
+			pref.Email = *p.Email
+		}
+		if p.Slack != nil {
+			pref.Slack = *p.Slack
+		}
+		prefs = append(prefs, &pref)
+	}
+
+	logger.Infof("Updating notification preferences for %s", employee.Email)
+
+	if err := api.store.SetNotificationPreferences(employee.ID, prefs); err != nil {
+		logger.Errorf("Failed to update notification preferences for %s: %v", employee.Email, err)
+		writeError(w, err, "Failed to update notification preferences")
+		return
+	}
+
+	updated, err := api.store.GetNotificationPreferences(employee.ID)
+	if err != nil {
+		logger.Errorf("Failed to get notification preferences for %s: %v", employee.Email, err)
+		writeError(w, err, "Failed to fetch notification preferences")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(updated); err != nil {
+		logger.Errorf("Failed to encode notification preferences response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// getNotificationChannels handles GET /api/v1/employees/me/notification-channels
+// Returns the Slack member ID notifications are sent to
+func (api *API) getNotificationChannels(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	channels, err := api.store.GetNotificationChannels(employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch notification channels")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(channels); err != nil {
+		logger.Errorf("Failed to encode notification channels response: %v", err)
+	}
+}
+
+// updateNotificationChannels handles PUT /api/v1/employees/me/notification-channels
+// A null slackUserId stops Slack messages
+func (api *API) updateNotificationChannels(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var channels types.NotificationChannels
+	if err := json.NewDecoder(r.Body).Decode(&channels); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := channels.Validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Updating notification channels for %s", employee.Email)
+
+	if err := api.store.SetNotificationChannels(employee.ID, &channels); err != nil {
+		writeError(w, err, "Failed to update notification channels")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(channels); err != nil {
+		logger.Errorf("Failed to encode notification channels response: %v", err)
+	}
+}
+
+// getEmployeeNotifications handles GET /api/v1/employees/me/notifications
+// Returns the employee's latest staff console notifications, newest first, and the unread count
+// Query params: unread (true for unread only), limit (1-200, default 50)
+func (api *API) getEmployeeNotifications(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	unreadOnly := query.Get("unread") == "true"
+	limit := 50 // default
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 200 {
+			http.Error(w, "limit must be between 1 and 200", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	notifications, unread, err := api.store.GetEmployeeNotifications(employee.ID, unreadOnly, limit)
+	if err != nil {
+		writeError(w, err, "Failed to fetch notifications")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"notifications": notifications,
+		"unread":        unread,
+	})
+}
+
+// markEmployeeNotificationsRead handles POST /api/v1/employees/me/notifications/read
+// Marks the notifications in {"ids": [...]} read, or all of them when ids is empty or there is no body
+func (api *API) markEmployeeNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		IDs []uuid.UUID `json:"ids"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	marked, err := api.store.MarkEmployeeNotificationsRead(employee.ID, req.IDs)
+	if err != nil {
+		writeError(w, err, "Failed to mark notifications read")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"marked": marked,
+	})
+}
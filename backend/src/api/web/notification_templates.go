@@ -0,0 +1,122 @@
+package webapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/notification"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// TestSendRequest selects a template and the variables to render it with
+type TestSendRequest struct {
+	Template  string                 `json:"template"`
+	Variables map[string]interface{} `json:"variables"` // Omitted variables use the tenant's values or samples
+	DryRun    bool                   `json:"dryRun"`    // Render only, without sending
+}
+
+// TestSendResponse is the rendered email and where it was sent
+type TestSendResponse struct {
+	*notification.RenderedEmail
+	SentTo string `json:"sentTo,omitempty"`
+}
+
+// getNotificationTemplates handles GET /api/v1/{tenantId}/notifications/templates
+// Lists the email templates with their variables and sample values (admin only)
+func (api *API) getNotificationTemplates(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(notification.Templates()); err != nil {
+		logger.Errorf("Failed to encode notification templates: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// testSendNotification handles POST /api/v1/{tenantId}/notifications/test-send
+// Renders a template and emails it to the requesting admin only. Templates that fail to
+// render return 422 with the problem variables instead of sending.
+func (api *API) testSendNotification(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req TestSendRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Template == "" {
+		http.Error(w, "template is required", http.StatusBadRequest)
+		return
+	}
+
+	tc, err := api.store.GetTenantConfig(tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get tenant %s for test send: %v", tenantID, err)
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	// Variables the tenant and admin supply unless the request overrides them
+	defaults := map[string]interface{}{
+		"tenantName":    tc.TenantName,
+		"loginUrl":      api.links.Login(tenantID),
+		"recipientName": employee.FullName(),
+	}
+
+	rendered, renderErrors := notification.RenderTemplate(req.Template, req.Variables, defaults)
+	if len(renderErrors) > 0 {
+		logger.Warningf("Template %s failed to render for %s: %v", req.Template, employee.Email, renderErrors)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnprocessableEntity)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"error":        "Template failed to render",
+			"renderErrors": renderErrors,
+		})
+		return
+	}
+
+	response := TestSendResponse{RenderedEmail: rendered}
+	if !req.DryRun {
+		if api.emailService == nil {
+			http.Error(w, "Email service is not configured", http.StatusServiceUnavailable)
+			return
+		}
+
+		err := api.emailService.Send(&notification.Email{
+			TenantID: tenantID,
+			To:       employee.Email,
+			ToName:   employee.FullName(),
+			Subject:  "[Test] " + rendered.Subject,
+			HTMLBody: rendered.HTMLBody,
+			TextBody: rendered.TextBody,
+		})
+		if errors.Is(err, notification.ErrQueueFull) {
+			http.Error(w, "Email queue is full, try again shortly", http.StatusServiceUnavailable)
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to test-send template %s to %s: %v", req.Template, employee.Email, err)
+			http.Error(w, "Failed to send test email", http.StatusBadGateway)
+			return
+		}
+
+		response.SentTo = employee.Email
+		logger.Infof("Test-sent template %s for tenant %s to %s", req.Template, tenantID, employee.Email)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Errorf("Failed to encode test send response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
@@ -0,0 +1,37 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"welltaxpro/src/internal/types"
+)
+
+// parsePageRequest reads cursor pagination from ?cursor= and ?limit=. Listings that predate
+// pagination return a bare array, so paging only applies when the cursor parameter is present;
+// an empty ?cursor= requests the first page. The second result reports whether it is present.
+func parsePageRequest(r *http.Request) (types.PageRequest, bool, error) {
+	query := r.URL.Query()
+	if !query.Has("cursor") {
+		return types.PageRequest{}, false, nil
+	}
+
+	var page types.PageRequest
+	if limit := query.Get("limit"); limit != "" {
+		parsed, err := strconv.Atoi(limit)
+		if err != nil || parsed <= 0 {
+			return page, true, fmt.Errorf("limit must be a positive integer")
+		}
+		page.Limit = parsed
+	}
+
+	if encoded := query.Get("cursor"); encoded != "" {
+		cursor, ok := types.DecodePageCursor(encoded)
+		if !ok {
+			return page, true, fmt.Errorf("invalid cursor")
+		}
+		page.After = cursor
+	}
+
+	return page, true, nil
+}
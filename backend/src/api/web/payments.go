@@ -0,0 +1,117 @@
+package webapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/checkout"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// receivePaymentWebhook records the payment of a completed Stripe Checkout session and creates
+// the commission of the affiliate whose discount code it used. Events are authenticated by the
+// webhook signing secret configured for the tenant. Other event types, and sessions for filings
+// that do not exist, are acknowledged so Stripe stops retrying them.
+func (api *API) receivePaymentWebhook(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	secret, err := api.store.GetStripeWebhookSecret(tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to process webhook")
+		return
+	}
+	if secret == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := checkout.VerifySignature(r.Header.Get(checkout.SignatureHeader), body, secret, time.Now()); err != nil {
+		logger.Warningf("Rejected Stripe event for tenant %s from %s: %v", tenantID, r.RemoteAddr, err)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	session, err := checkout.ParseEvent(body)
+	if err != nil {
+		logger.Warningf("Rejected Stripe event for tenant %s: %v", tenantID, err)
+		http.Error(w, "Invalid webhook", http.StatusBadRequest)
+		return
+	}
+	if session == nil {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	logger.Infof("Stripe %s event %s for session %s in tenant %s", session.EventType, session.EventID, session.SessionID, tenantID)
+
+	_, commission, err := api.store.RecordCheckoutSession(r.Context(), tenantID, session)
+	if err != nil {
+		if apperr.Status(err) == http.StatusNotFound {
+			logger.Warningf("Ignoring Stripe event %s: %v", session.EventID, err)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		logger.Errorf("Failed to record checkout session %s for tenant %s: %v", session.SessionID, tenantID, err)
+		http.Error(w, "Failed to process webhook", http.StatusInternalServerError)
+		return
+	}
+
+	if commission != nil && commission.Status == types.CommissionStatusReview && api.notifier != nil {
+		go api.notifier.NotifyAdmins(
+			types.NotificationCategoryCommission,
+			&tenantID,
+			"Commission flagged for review",
+			fmt.Sprintf("Commission %s for affiliate %s in tenant %s matched %d fraud rule(s) and needs review.", commission.ID, commission.AffiliateID, tenantID, len(commission.FraudFlags)),
+		)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// updateStripeWebhookSecret sets or clears the signing secret of a tenant's Stripe webhook
+// endpoint (admin only)
+func (api *API) updateStripeWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var req struct {
+		Secret string `json:"secret"` // Empty clears the secret and rejects Stripe events
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Secret = strings.TrimSpace(req.Secret)
+	if len(req.Secret) > 500 {
+		http.Error(w, "secret must be at most 500 characters", http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Updating Stripe webhook secret for tenant %s", tenantID)
+
+	if err := api.store.UpdateStripeWebhookSecret(tenantID, req.Secret, employee.ID); err != nil {
+		writeError(w, err, "Failed to update Stripe webhook secret")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
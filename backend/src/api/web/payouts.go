@@ -0,0 +1,346 @@
+package webapi
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/payout"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// maxTransfersPerRequest caps the Stripe transfers one execute request sends, keeping it well
+// inside the API timeout; the response's hasMore says to call again
+const maxTransfersPerRequest = 20
+
+// payoutTransferResult reports the outcome of one payout's transfer
+type payoutTransferResult struct {
+	PayoutID uuid.UUID `json:"payoutId"`
+	Success  bool      `json:"success"`
+	Error    *string   `json:"error,omitempty"`
+}
+
+// getPayoutBatches returns the tenant's latest payout batches
+func (api *API) getPayoutBatches(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	batches, err := api.store.GetPayoutBatches(r.Context(), tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch payout batches")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(batches)
+}
+
+// createPayoutBatch groups approved commissions into one payout per affiliate whose total
+// reaches their payout threshold
+func (api *API) createPayoutBatch(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	tenantID := mux.Vars(r)["tenantId"]
+
+	batch, err := api.store.CreatePayoutBatch(r.Context(), tenantID, employee.Email)
+	if err != nil {
+		writeError(w, err, "Failed to create payout batch")
+		return
+	}
+
+	logger.Infof("Payout batch %s created in tenant %s by %s", batch.ID, tenantID, employee.Email)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(batch)
+}
+
+// getPayoutBatch returns a payout batch with its payouts
+func (api *API) getPayoutBatch(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	batchID, err := uuid.Parse(vars["batchId"])
+	if err != nil {
+		http.Error(w, "Invalid batch ID", http.StatusBadRequest)
+		return
+	}
+
+	batch, err := api.store.GetPayoutBatch(r.Context(), tenantID, batchID.String())
+	if err != nil {
+		writeError(w, err, "Failed to fetch payout batch")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(batch)
+}
+
+// executePayoutBatch sends Stripe transfers for the batch's unpaid STRIPE payouts (admin only).
+// Manual and PayPal payouts are paid by staff and recorded with markPayoutPaid.
+func (api *API) executePayoutBatch(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	if api.payouts.Name() == payout.ProviderNone {
+		http.Error(w, "Payout transfers are not configured", http.StatusNotFound)
+		return
+	}
+
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	batchID, err := uuid.Parse(vars["batchId"])
+	if err != nil {
+		http.Error(w, "Invalid batch ID", http.StatusBadRequest)
+		return
+	}
+
+	batch, err := api.store.GetPayoutBatch(r.Context(), tenantID, batchID.String())
+	if err != nil {
+		writeError(w, err, "Failed to fetch payout batch")
+		return
+	}
+
+	results := []*payoutTransferResult{}
+	hasMore := false
+	for _, p := range batch.Payouts {
+		if p.PayoutMethod != types.PayoutMethodStripe {
+			continue
+		}
+		if p.Status != types.PayoutStatusPending && p.Status != types.PayoutStatusProcessing && p.Status != types.PayoutStatusFailed {
+			continue
+		}
+		if len(results) == maxTransfersPerRequest || r.Context().Err() != nil {
+			hasMore = true
+			break
+		}
+
+		result := &payoutTransferResult{PayoutID: p.ID}
+		if err := api.transferPayout(r, tenantID, p.ID.String(), employee.Email); err != nil {
+			message := err.Error()
+			result.Error = &message
+		} else {
+			result.Success = true
+		}
+		results = append(results, result)
+	}
+
+	batch, err = api.store.GetPayoutBatch(r.Context(), tenantID, batchID.String())
+	if err != nil {
+		writeError(w, err, "Failed to fetch payout batch")
+		return
+	}
+
+	logger.Infof("Payout batch %s in tenant %s executed by %s: %d transfers attempted", batchID, tenantID, employee.Email, len(results))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"batch":   batch,
+		"results": results,
+		"hasMore": hasMore,
+	})
+}
+
+// transferPayout sends one payout through the payout provider and records the outcome. The payout
+// is marked PROCESSING first, so a transfer whose outcome is unknown is retried under the same
+// idempotency key instead of paying twice.
+func (api *API) transferPayout(r *http.Request, tenantID, payoutID, paidBy string) error {
+	p, err := api.store.StartPayoutTransfer(r.Context(), tenantID, payoutID)
+	if err != nil {
+		return err
+	}
+
+	if p.StripeAccountID == nil || *p.StripeAccountID == "" {
+		err := errors.New("affiliate has no Stripe Connect account")
+		if recErr := api.store.RecordPayoutFailure(r.Context(), tenantID, payoutID, err.Error(), true); recErr != nil {
+			logger.Errorf("Failed to record failure of payout %s: %v", payoutID, recErr)
+		}
+		return err
+	}
+
+	receipt, err := api.payouts.Transfer(r.Context(), &payout.Transfer{
+		Destination: *p.StripeAccountID,
+		AmountCents: int64(p.Amount),
+		Description: fmt.Sprintf("Affiliate commissions (%d)", p.CommissionCount),
+		Metadata: map[string]string{
+			"tenant_id": tenantID,
+			"batch_id":  p.BatchID.String(),
+			"payout_id": payoutID,
+		},
+		IdempotencyKey: p.TransferKey(),
+	})
+	if err != nil {
+		var declined *payout.DeclinedError
+		logger.Errorf("Transfer of payout %s failed: %v", payoutID, err)
+		if recErr := api.store.RecordPayoutFailure(r.Context(), tenantID, payoutID, err.Error(), errors.As(err, &declined)); recErr != nil {
+			logger.Errorf("Failed to record failure of payout %s: %v", payoutID, recErr)
+		}
+		return err
+	}
+
+	if _, err := api.store.MarkPayoutPaid(r.Context(), tenantID, payoutID, &types.PayoutPayment{TransferID: &receipt.TransferID, PaidBy: paidBy}); err != nil {
+		// The money moved; executing the batch again finds the transfer under the same key
+		logger.Errorf("Payout %s was transferred as %s but could not be recorded: %v", payoutID, receipt.TransferID, err)
+		return err
+	}
+	return nil
+}
+
+// markPayoutPaid records a payment of a payout made outside of Stripe (Zelle, check, bank
+// transfer). Once its payments cover the payout, it and its commissions are marked PAID.
+func (api *API) markPayoutPaid(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	payoutID, err := uuid.Parse(vars["payoutId"])
+	if err != nil {
+		http.Error(w, "Invalid payout ID", http.StatusBadRequest)
+		return
+	}
+
+	payment, ok := decodeManualPayment(w, r, employee.Email)
+	if !ok {
+		return
+	}
+
+	p, err := api.store.MarkPayoutPaid(r.Context(), tenantID, payoutID.String(), payment)
+	if err != nil {
+		writeError(w, err, "Failed to mark payout paid")
+		return
+	}
+
+	logger.Infof("Payout %s in tenant %s: %s payment recorded by %s, $%s of $%s paid",
+		payoutID, tenantID, payment.Method, employee.Email, p.PaidAmount, p.Amount)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// decodeManualPayment reads the body of a manual payment: method and reference are required, and
+// amount, in dollars, defaults to what is still owed. It writes a 400 and returns false when the
+// body is invalid.
+func decodeManualPayment(w http.ResponseWriter, r *http.Request, paidBy string) (*types.PayoutPayment, bool) {
+	var req struct {
+		Method    string       `json:"method"`
+		Reference string       `json:"reference"`
+		Amount    *types.Cents `json:"amount"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return nil, false
+	}
+
+	method := strings.ToUpper(strings.TrimSpace(req.Method))
+	if !types.IsValidManualPaymentMethod(method) {
+		http.Error(w, "method must be one of: "+strings.Join(types.ManualPaymentMethods, ", "), http.StatusBadRequest)
+		return nil, false
+	}
+	reference := strings.TrimSpace(req.Reference)
+	if reference == "" {
+		http.Error(w, "reference is required", http.StatusBadRequest)
+		return nil, false
+	}
+
+	return &types.PayoutPayment{Method: method, Reference: &reference, Amount: req.Amount, PaidBy: paidBy}, true
+}
+
+// cancelPayout cancels an unpaid payout; its commissions are picked up by the next batch
+func (api *API) cancelPayout(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	payoutID, err := uuid.Parse(vars["payoutId"])
+	if err != nil {
+		http.Error(w, "Invalid payout ID", http.StatusBadRequest)
+		return
+	}
+
+	p, err := api.store.CancelPayout(r.Context(), tenantID, payoutID.String())
+	if err != nil {
+		writeError(w, err, "Failed to cancel payout")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(p)
+}
+
+// getAffiliatePaymentTotals returns what the tenant paid each affiliate in a calendar year by
+// payment date, the amounts for their 1099s
+// Query params: year (defaults to last calendar year)
+func (api *API) getAffiliatePaymentTotals(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	year, ok := paymentYear(w, r, time.Now().Year()-1)
+	if !ok {
+		return
+	}
+
+	totals, err := api.store.GetAffiliatePaymentTotals(r.Context(), tenantID, year)
+	if err != nil {
+		writeError(w, err, "Failed to total affiliate payments")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(totals)
+}
+
+// getAffiliateStatement returns an affiliate's payments of a calendar year with their 1099 total
+// Query params: year (defaults to last calendar year)
+func (api *API) getAffiliateStatement(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	affiliateID, err := uuid.Parse(vars["affiliateId"])
+	if err != nil {
+		http.Error(w, "Invalid affiliate ID", http.StatusBadRequest)
+		return
+	}
+
+	year, ok := paymentYear(w, r, time.Now().Year()-1)
+	if !ok {
+		return
+	}
+
+	statement, err := api.store.GetAffiliateStatement(r.Context(), tenantID, affiliateID.String(), year)
+	if err != nil {
+		writeError(w, err, "Failed to fetch affiliate statement")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statement)
+}
+
+// paymentYear reads the calendar year of the year query parameter, writing the error response
+// when it is not a year
+func paymentYear(w http.ResponseWriter, r *http.Request, defaultYear int) (int, bool) {
+	yearStr := r.URL.Query().Get("year")
+	if yearStr == "" {
+		return defaultYear, true
+	}
+	year, err := strconv.Atoi(yearStr)
+	if err != nil || year < 2000 || year > 9999 {
+		http.Error(w, "Invalid year parameter", http.StatusBadRequest)
+		return 0, false
+	}
+	return year, true
+}
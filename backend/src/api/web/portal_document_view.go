@@ -0,0 +1,133 @@
+package webapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// portalViewableTypes are the sniffed content types browsers can render inline safely.
+// Anything else, such as HTML or SVG that could run script on our origin, must be downloaded.
+var portalViewableTypes = map[string]bool{
+	"application/pdf": true,
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+}
+
+// viewTenantUserDocument streams a tenant user's own PDF or image document for display in the
+// browser rather than download. The type is sniffed from the content, not taken from the file
+// name, and every view is recorded.
+func (api *API) viewTenantUserDocument(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	documentID, err := uuid.Parse(mux.Vars(r)["documentId"])
+	if err != nil {
+		http.Error(w, "Invalid document ID", http.StatusBadRequest)
+		return
+	}
+
+	tc, filePath, fileName, ok := api.portalDocument(r.Context(), w, tenantUser, documentID.String())
+	if !ok {
+		return
+	}
+
+	reader, ok := api.openPortalDocument(w, tc, filePath)
+	if !ok {
+		return
+	}
+	defer reader.Close()
+
+	// http.DetectContentType considers at most the first 512 bytes
+	head := make([]byte, 512)
+	n, err := io.ReadFull(reader, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		logger.Errorf("Failed to read document %s: %v", documentID, err)
+		http.Error(w, "Failed to read document", http.StatusInternalServerError)
+		return
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if !portalViewableTypes[contentType] {
+		http.Error(w, "Only PDF and image documents can be viewed; download this document instead", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	// Views are audited before anything is served
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	view := &types.PortalDocumentView{
+		TenantID:     tenantUser.TenantID,
+		TenantUserID: tenantUser.ID,
+		ClientID:     tenantUser.ClientID,
+		DocumentID:   documentID,
+		ContentType:  contentType,
+		IPAddress:    &ipAddress,
+		UserAgent:    &userAgent,
+	}
+	if err := api.store.RecordPortalDocumentView(view); err != nil {
+		http.Error(w, "Failed to view document", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": fileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "private, no-store")
+
+	if _, err := io.Copy(w, io.MultiReader(bytes.NewReader(head), reader)); err != nil {
+		logger.Errorf("Failed to stream document %s: %v", documentID, err)
+		return
+	}
+
+	logger.Infof("Tenant user %s viewed document %s", tenantUser.ID, documentID)
+}
+
+// getPortalDocumentViews lists the documents a client viewed in the portal, newest first
+// Query params: limit (1-500, default 100)
+func (api *API) getPortalDocumentViews(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	clientID, err := uuid.Parse(vars["clientId"])
+	if err != nil {
+		http.Error(w, "Invalid client ID", http.StatusBadRequest)
+		return
+	}
+
+	limit := 100 // default
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 500 {
+			http.Error(w, "limit must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	views, err := api.store.GetPortalDocumentViews(tenantID, clientID, limit)
+	if err != nil {
+		writeError(w, err, "Failed to fetch portal document views")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(views); err != nil {
+		logger.Errorf("Failed to encode portal document views response: %v", err)
+	}
+}
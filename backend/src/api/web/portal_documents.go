@@ -0,0 +1,176 @@
+package webapi
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"regexp"
+	"strings"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// portalDocumentTypes maps the content types clients may upload to the extension they are stored with
+var portalDocumentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+// portalDocumentTypePattern limits document types to names safe in a storage path, such as W2 or 1099-INT
+var portalDocumentTypePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,49}$`)
+
+// uploadTenantUserDocument lets a portal user upload a tax document, such as a W-2 or 1099, into
+// one of their own filings (tenant user only). The file is stored under the client's ID and the
+// filing's assigned accountant is notified.
+func (api *API) uploadTenantUserDocument(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	filingID, err := uuid.Parse(mux.Vars(r)["filingId"])
+	if err != nil {
+		http.Error(w, "Invalid filing ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := r.ParseMultipartForm(maxUploadSize); err != nil {
+		logger.Errorf("Failed to parse portal document form: %v", err)
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "File too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		http.Error(w, "File too large or invalid form data", http.StatusBadRequest)
+		return
+	}
+
+	documentType := strings.TrimSpace(r.FormValue("type"))
+	if !portalDocumentTypePattern.MatchString(documentType) {
+		http.Error(w, "type is required and may only contain letters, digits, - and _", http.StatusBadRequest)
+		return
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		http.Error(w, "File is required", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	fileBytes, err := io.ReadAll(file)
+	if err != nil {
+		logger.Errorf("Failed to read portal document: %v", err)
+		http.Error(w, "Failed to read file", http.StatusInternalServerError)
+		return
+	}
+	if len(fileBytes) == 0 {
+		http.Error(w, "File is empty", http.StatusBadRequest)
+		return
+	}
+
+	// Trust the bytes, not the client-supplied header
+	contentType := http.DetectContentType(fileBytes)
+	ext, ok := portalDocumentTypes[contentType]
+	if !ok {
+		http.Error(w, "File must be a PDF, JPEG or PNG", http.StatusUnsupportedMediaType)
+		return
+	}
+
+	// Clients may only add to their own filings
+	ownerID, err := api.store.GetFilingClientID(r.Context(), tenantUser.TenantID, filingID)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNotFound) {
+			http.Error(w, "Filing not found", http.StatusNotFound)
+			return
+		}
+		writeError(w, err, "Failed to fetch filing")
+		return
+	}
+	if ownerID != tenantUser.ClientID {
+		logger.Warningf("Client %s attempted to upload a document to filing %s owned by %s", tenantUser.ClientID, filingID, ownerID)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	// The client's name for the file is kept on the record; the stored name is derived from it
+	name := path.Base(strings.ReplaceAll(header.Filename, "\\", "/"))
+	if name == "." || name == "/" {
+		name = "document" + ext
+	}
+	sum := sha256.Sum256(fileBytes)
+	baseName := strings.TrimSuffix(name, path.Ext(name))
+	storagePath := fmt.Sprintf("%s/%s/%s_%s%s", tenantUser.ClientID, documentType, baseName, hex.EncodeToString(sum[:])[:16], ext)
+
+	tc, err := api.store.GetTenantConfig(tenantUser.TenantID)
+	if err != nil {
+		logger.Errorf("Failed to get tenant config: %v", err)
+		writeError(w, err, "Failed to get tenant configuration")
+		return
+	}
+
+	storageProvider, err := api.storageForTenant(context.Background(), tc)
+	if err != nil {
+		logger.Errorf("Failed to create storage provider: %v", err)
+		http.Error(w, "Failed to initialize storage", http.StatusInternalServerError)
+		return
+	}
+
+	metadata := map[string]string{
+		"tenant_id":      tenantUser.TenantID,
+		"filing_id":      filingID.String(),
+		"user_id":        tenantUser.ClientID.String(),
+		"tenant_user_id": tenantUser.ID.String(),
+		"document_type":  documentType,
+		"original_name":  name,
+		"source":         "portal",
+	}
+	document, err := api.createScannedDocument(r.Context(), tc, storageProvider, &types.Document{
+		ID:       uuid.New(),
+		UserID:   tenantUser.ClientID,
+		FilingID: &filingID,
+		Name:     name,
+		FilePath: storagePath,
+		Type:     documentType,
+	}, fileBytes, metadata)
+	if err != nil {
+		http.Error(w, "Failed to upload document", http.StatusInternalServerError)
+		return
+	}
+
+	logger.Infof("Tenant user %s uploaded document %s to filing %s", tenantUser.ID, document.ID, filingID)
+
+	if api.notifier != nil {
+		go api.notifyPortalUpload(tenantUser, document)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(document); err != nil {
+		logger.Errorf("Failed to encode document response: %v", err)
+	}
+}
+
+// notifyPortalUpload tells the accountant assigned to the filing about a document the client
+// uploaded, or the tenant's admins when no active accountant is assigned
+func (api *API) notifyPortalUpload(tenantUser *types.TenantUser, document *types.Document) {
+	subject := "Client uploaded a document"
+	body := fmt.Sprintf("Portal user %s uploaded %s (%s) to filing %s in tenant %s.",
+		tenantUser.Email, document.Name, document.Type, document.FilingID, tenantUser.TenantID)
+	if document.ScanStatus != nil && *document.ScanStatus != types.DocumentScanClean {
+		body += fmt.Sprintf(" Its malware scan is %s, so it cannot be downloaded yet.", *document.ScanStatus)
+	}
+
+	api.notifier.NotifyFilingAssignee(types.NotificationCategoryUpload, tenantUser.TenantID, *document.FilingID, subject, body)
+}
@@ -0,0 +1,443 @@
+package webapi
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/notification"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// getPortalSecurityPolicy returns the effective portal security policy for a tenant (admin only)
+func (api *API) getPortalSecurityPolicy(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	policy, err := api.store.GetPortalSecurityPolicy(tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch portal security policy")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(policy); err != nil {
+		logger.Errorf("Failed to encode portal security policy response: %v", err)
+	}
+}
+
+// updatePortalSecurityPolicy replaces the portal security policy for a tenant (admin only).
+// Signed-in portal users are held to the new policy from their next request.
+func (api *API) updatePortalSecurityPolicy(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var policy types.PortalSecurityPolicy
+	if err := json.NewDecoder(r.Body).Decode(&policy); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if policy.VerificationMethod == "" {
+		policy.VerificationMethod = types.PortalVerifyNone
+	}
+	if !types.IsValidPortalVerificationMethod(policy.VerificationMethod) {
+		http.Error(w, "verificationMethod must be NONE, SSN_LAST4, DOB or OTP", http.StatusBadRequest)
+		return
+	}
+	if policy.SessionMinutes != 0 && (policy.SessionMinutes < 5 || policy.SessionMinutes > 43200) {
+		http.Error(w, "sessionMinutes must be 0 or between 5 and 43200", http.StatusBadRequest)
+		return
+	}
+	if policy.VerificationReuseMinutes < 0 || policy.VerificationReuseMinutes > 10080 {
+		http.Error(w, "verificationReuseMinutes must be between 0 and 10080", http.StatusBadRequest)
+		return
+	}
+	if policy.VerificationReuseMinutes > 0 && !policy.RequiresVerification() {
+		http.Error(w, "verificationReuseMinutes requires a verificationMethod", http.StatusBadRequest)
+		return
+	}
+	if policy.MaxConcurrentSessions < 0 || policy.MaxConcurrentSessions > 20 {
+		http.Error(w, "maxConcurrentSessions must be between 0 and 20", http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Updating portal security policy for tenant %s", tenantID)
+
+	if err := api.store.UpdatePortalSecurityPolicy(tenantID, &policy, employee.ID); err != nil {
+		writeError(w, err, "Failed to update portal security policy")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(policy); err != nil {
+		logger.Errorf("Failed to encode portal security policy response: %v", err)
+	}
+}
+
+// getPortalSession tells the portal how its sign-in is verified and when it ends (tenant user
+// only, before verification)
+func (api *API) getPortalSession(w http.ResponseWriter, r *http.Request) {
+	policy, err := api.store.GetPortalSecurityPolicy(mux.Vars(r)["tenantId"])
+	if err != nil {
+		writeError(w, err, "Failed to fetch portal security policy")
+		return
+	}
+
+	status := &types.PortalSessionStatus{
+		VerificationMethod: policy.VerificationMethod,
+		Verified:           !policy.RequiresVerification(),
+		SessionMinutes:     policy.SessionMinutes,
+	}
+	if session, ok := middleware.GetPortalSessionFromContext(r.Context()); ok {
+		status.Session = session
+		status.Verified = status.Verified || session.VerifiedAt != nil
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		logger.Errorf("Failed to encode portal session response: %v", err)
+	}
+}
+
+// startPortalVerification begins verifying the caller's sign-in (tenant user only, before
+// verification). For OTP a code is emailed to the portal user; clients without the SSN or date
+// of birth the policy asks for are sent a code instead.
+func (api *API) startPortalVerification(w http.ResponseWriter, r *http.Request) {
+	target, ok := api.portalVerification(w, r)
+	if !ok {
+		return
+	}
+	tenantUser, session := target.tenantUser, target.session
+
+	challenge := &types.PortalVerificationChallenge{Method: target.method}
+	if target.method == types.PortalVerifyOTP {
+		code, err := portalVerificationCode()
+		if err != nil {
+			logger.Errorf("Failed to generate portal verification code: %v", err)
+			http.Error(w, "Failed to start verification", http.StatusInternalServerError)
+			return
+		}
+		expiresAt := time.Now().Add(types.PortalOTPLifetime)
+		if err := api.store.SetPortalSessionOTP(session.ID, hashPortalVerificationCode(code), expiresAt); err != nil {
+			writeError(w, err, "Failed to start verification")
+			return
+		}
+
+		tenantName := tenantUser.TenantID
+		if tc, err := api.store.GetTenantConfig(tenantUser.TenantID); err == nil {
+			tenantName = tc.TenantName
+		}
+		clientName := "Valued Client"
+		if target.client != nil && target.client.FirstName != nil && *target.client.FirstName != "" {
+			clientName = *target.client.FirstName
+		}
+		subject, htmlBody, textBody := notification.GeneratePortalVerificationEmail(notification.PortalVerificationEmail{
+			ClientName: clientName,
+			TenantName: tenantName,
+			Code:       code,
+			Minutes:    int(types.PortalOTPLifetime.Minutes()),
+		})
+		err = api.emailService.Send(&notification.Email{
+			TenantID: tenantUser.TenantID,
+			To:       tenantUser.Email,
+			ToName:   clientName,
+			Subject:  subject,
+			HTMLBody: htmlBody,
+			TextBody: textBody,
+		})
+		if err != nil {
+			logger.Errorf("Failed to send portal verification code to tenant user %s: %v", tenantUser.ID, err)
+			http.Error(w, "Failed to send verification code", http.StatusBadGateway)
+			return
+		}
+		challenge.SentTo = maskEmailAddress(tenantUser.Email)
+		challenge.ExpiresAt = &expiresAt
+	}
+
+	logger.Infof("Started %s verification of portal session %s", target.method, session.ID)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(challenge); err != nil {
+		logger.Errorf("Failed to encode portal verification response: %v", err)
+	}
+}
+
+// verifyPortalSession checks the caller's answer to its sign-in's verification (tenant user only,
+// before verification). Too many wrong answers end the session, and too many in a row across
+// sign-ins lock the user out until the firm sends a new portal link. Every wrong answer and lockout
+// is recorded and audited.
+func (api *API) verifyPortalSession(w http.ResponseWriter, r *http.Request) {
+	target, ok := api.portalVerification(w, r)
+	if !ok {
+		return
+	}
+	tenantUser, session, method := target.tenantUser, target.session, target.method
+
+	var req struct {
+		Answer string `json:"answer"` // Last 4 SSN digits, date of birth (YYYY-MM-DD) or emailed code
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	answer := strings.TrimSpace(req.Answer)
+	if answer == "" {
+		http.Error(w, "answer is required", http.StatusBadRequest)
+		return
+	}
+
+	var expected string
+	switch method {
+	case types.PortalVerifySSNLast4, types.PortalVerifyDOB:
+		expected = portalVerificationValue(target.client, method)
+		if method == types.PortalVerifyDOB && len(answer) > 10 {
+			answer = answer[:10]
+		}
+	case types.PortalVerifyOTP:
+		codeHash, err := api.store.GetPortalSessionOTP(session.ID)
+		if err != nil {
+			writeError(w, err, "Failed to check verification code")
+			return
+		}
+		if codeHash == "" {
+			http.Error(w, "No verification code is active; request a new one", http.StatusConflict)
+			return
+		}
+		expected, answer = codeHash, hashPortalVerificationCode(answer)
+	}
+	verified := expected != "" && subtle.ConstantTimeCompare([]byte(answer), []byte(expected)) == 1
+
+	session, err := api.store.RecordPortalVerification(session.ID, method, verified)
+	if err != nil {
+		writeError(w, err, "Failed to record verification")
+		return
+	}
+
+	if !verified {
+		logger.Warningf("Wrong %s verification answer for portal session %s (%d failed)", method, session.ID, session.FailedAttempts)
+		ipAddress := middleware.ClientIP(r)
+		userAgent := r.UserAgent()
+		failure := &types.PortalVerificationFailure{
+			TenantID:     tenantUser.TenantID,
+			TenantUserID: tenantUser.ID,
+			ClientID:     tenantUser.ClientID,
+			SessionID:    &session.ID,
+			Method:       method,
+			IPAddress:    &ipAddress,
+			UserAgent:    &userAgent,
+		}
+		if err := api.store.RecordPortalVerificationFailure(failure); err != nil {
+			writeError(w, err, "Failed to record verification")
+			return
+		}
+		details := map[string]interface{}{
+			"method":    method,
+			"sessionId": session.ID,
+			"failures":  failure.Failures,
+		}
+		if err := api.store.CreatePortalAuditLog(tenantUser.ID, tenantUser.TenantID, &tenantUser.ClientID, types.AuditActionVerifyFailed, types.AuditResourcePortalUser, &tenantUser.ID, details, &ipAddress, &userAgent); err != nil {
+			logger.Errorf("Failed to audit wrong verification answer of tenant user %s: %v", tenantUser.ID, err)
+		}
+		if failure.Locked {
+			logger.Warningf("Locked tenant user %s out of portal verification after %d wrong answers", tenantUser.ID, failure.Failures)
+			if err := api.store.CreatePortalAuditLog(tenantUser.ID, tenantUser.TenantID, &tenantUser.ClientID, types.AuditActionLock, types.AuditResourcePortalUser, &tenantUser.ID, details, &ipAddress, &userAgent); err != nil {
+				logger.Errorf("Failed to audit verification lockout of tenant user %s: %v", tenantUser.ID, err)
+			}
+			if api.notifier != nil {
+				go api.notifier.NotifyTenantAdmins(types.NotificationCategorySecurity, tenantUser.TenantID, "Portal sign-in verification locked",
+					fmt.Sprintf("The portal user %s (client %s) in tenant %s gave %d wrong %s verification answers in a row and can no longer sign in to the portal. Send the client a new portal link once you have confirmed it is them.",
+						tenantUser.Email, tenantUser.ClientID, tenantUser.TenantID, failure.Failures, method))
+			}
+		}
+		if failure.Failures >= types.PortalVerificationLockAttempts {
+			http.Error(w, portalVerificationLockedMessage, http.StatusLocked)
+			return
+		}
+		if session.EndedAt != nil {
+			http.Error(w, "Too many failed verification attempts; sign in again", http.StatusUnauthorized)
+			return
+		}
+		http.Error(w, fmt.Sprintf("Verification failed; %d attempts left", types.PortalVerificationMaxAttempts-session.FailedAttempts), http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := api.store.ResetPortalVerificationFailures(tenantUser.ID); err != nil {
+		logger.Errorf("Tenant user %s verified without resetting failed attempts: %v", tenantUser.ID, err)
+	}
+
+	logger.Infof("Tenant user %s verified portal session %s with %s", tenantUser.ID, session.ID, method)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(session); err != nil {
+		logger.Errorf("Failed to encode portal session response: %v", err)
+	}
+}
+
+// portalVerificationLockedMessage answers portal users locked out of verification
+const portalVerificationLockedMessage = "Too many failed verification attempts; ask your firm to send you a new portal link"
+
+// portalVerificationTarget is the sign-in a verification request is for
+type portalVerificationTarget struct {
+	tenantUser *types.TenantUser
+	session    *types.PortalSession
+	method     string        // The policy's method, or OTP when the client lacks its value
+	client     *types.Client // Nil for users without a client record
+}
+
+// portalVerification resolves the tenant user and unverified session of a verification request
+// and the method it is verified with, writing the error response itself when there is nothing
+// to verify or the user is locked out
+func (api *API) portalVerification(w http.ResponseWriter, r *http.Request) (*portalVerificationTarget, bool) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	session, ok := middleware.GetPortalSessionFromContext(r.Context())
+	if !ok {
+		http.Error(w, "This firm does not require sign-in verification", http.StatusConflict)
+		return nil, false
+	}
+	if session.VerifiedAt != nil {
+		http.Error(w, "This sign-in is already verified", http.StatusConflict)
+		return nil, false
+	}
+
+	policy, err := api.store.GetPortalSecurityPolicy(tenantUser.TenantID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch portal security policy")
+		return nil, false
+	}
+	if !policy.RequiresVerification() {
+		http.Error(w, "This firm does not require sign-in verification", http.StatusConflict)
+		return nil, false
+	}
+
+	lockedAt, err := api.store.GetPortalVerificationLock(tenantUser.ID)
+	if err != nil {
+		writeError(w, err, "Failed to check verification lock")
+		return nil, false
+	}
+	if lockedAt != nil {
+		http.Error(w, portalVerificationLockedMessage, http.StatusLocked)
+		return nil, false
+	}
+
+	target := &portalVerificationTarget{tenantUser: tenantUser, session: session, method: policy.VerificationMethod}
+	if tenantUser.ClientID != NewClientUUID {
+		target.client, err = api.store.GetClientByID(r.Context(), tenantUser.TenantID, tenantUser.ClientID.String())
+		if err != nil && !errors.Is(err, apperr.ErrNotFound) {
+			writeError(w, err, "Failed to fetch client")
+			return nil, false
+		}
+	}
+	// Clients without the value on record can only be sent a code
+	if target.method != types.PortalVerifyOTP && portalVerificationValue(target.client, target.method) == "" {
+		target.method = types.PortalVerifyOTP
+	}
+
+	return target, true
+}
+
+// portalVerificationValue returns the client's SSN last 4 or date of birth (YYYY-MM-DD), or ""
+// when it is not on record
+func portalVerificationValue(client *types.Client, method string) string {
+	if client == nil {
+		return ""
+	}
+	switch method {
+	case types.PortalVerifySSNLast4:
+		// Client SSNs come masked as ***-**-1234, or ***-**-**** when unreadable
+		if client.Ssn == nil || len(*client.Ssn) < 4 {
+			return ""
+		}
+		last4 := (*client.Ssn)[len(*client.Ssn)-4:]
+		if strings.Contains(last4, "*") {
+			return ""
+		}
+		return last4
+	case types.PortalVerifyDOB:
+		if client.Dob == nil || len(*client.Dob) < 10 {
+			return ""
+		}
+		return (*client.Dob)[:10]
+	}
+	return ""
+}
+
+// portalVerificationCode returns a random six-digit code
+func portalVerificationCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1000000))
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
+
+// hashPortalVerificationCode returns the SHA-256 of a code as stored in portal_sessions
+func hashPortalVerificationCode(code string) string {
+	sum := sha256.Sum256([]byte(code))
+	return hex.EncodeToString(sum[:])
+}
+
+// maskEmailAddress hides all but the first character of an address's local part
+func maskEmailAddress(email string) string {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return "***"
+	}
+	return email[:1] + "***" + email[at:]
+}
+
+// getPortalVerificationFailures lists a client's wrong portal verification answers, newest first
+// Query params: limit (1-500, default 100)
+func (api *API) getPortalVerificationFailures(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	clientID, err := uuid.Parse(vars["clientId"])
+	if err != nil {
+		http.Error(w, "Invalid client ID", http.StatusBadRequest)
+		return
+	}
+
+	limit := 100 // default
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 500 {
+			http.Error(w, "limit must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	failures, err := api.store.GetPortalVerificationFailures(tenantID, clientID, limit)
+	if err != nil {
+		writeError(w, err, "Failed to fetch portal verification failures")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(failures); err != nil {
+		logger.Errorf("Failed to encode portal verification failures response: %v", err)
+	}
+}
@@ -0,0 +1,147 @@
+package webapi
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+	"welltaxpro/src/internal/bulk"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/uuid"
+)
+
+const portalClientDOB = "1980-04-02"
+
+// newPortalVerificationEnv returns a test env whose tenant verifies portal sign-ins with the
+// client's date of birth, and the client's portal user
+func newPortalVerificationEnv(t *testing.T) (*testEnv, *types.TenantUser) {
+	t.Helper()
+	env := newTestEnv(t)
+	env.store.SetPortalSecurityPolicy(testTenantID, &types.PortalSecurityPolicy{VerificationMethod: types.PortalVerifyDOB})
+
+	dob := portalClientDOB
+	client := &types.Client{ID: uuid.New(), Email: "client@example.com", Dob: &dob}
+	env.store.clients = append(env.store.clients, client)
+	user := &types.TenantUser{
+		ID:          uuid.New(),
+		TenantID:    testTenantID,
+		ClientID:    client.ID,
+		FirebaseUID: "uid-portal",
+		Email:       client.Email,
+		IsActive:    true,
+	}
+	env.store.tenantUsers = append(env.store.tenantUsers, user)
+	return env, user
+}
+
+// portalSignIn returns a token of a new sign-in of the portal user, each with its own session
+func portalSignIn(env *testEnv, user *types.TenantUser, n int) string {
+	token := fmt.Sprintf("portal-%d", n)
+	env.auth.AddToken(token, user.FirebaseUID, time.Now().Unix()-int64(n))
+	return token
+}
+
+func verifyPortalAnswer(t *testing.T, env *testEnv, token, answer string) int {
+	t.Helper()
+	rec := env.do(t, http.MethodPost, "/api/v1/"+testTenantID+"/user/session/verify", token, `{"answer":"`+answer+`"}`)
+	return rec.Code
+}
+
+func TestVerifyPortalSessionLockout(t *testing.T) {
+	env, user := newPortalVerificationEnv(t)
+
+	// Sessions end after PortalVerificationMaxAttempts wrong answers, so locking takes new sign-ins
+	var token string
+	for i := 1; i <= types.PortalVerificationLockAttempts; i++ {
+		if (i-1)%types.PortalVerificationMaxAttempts == 0 {
+			token = portalSignIn(env, user, i)
+		}
+		want := http.StatusUnprocessableEntity
+		switch {
+		case i == types.PortalVerificationLockAttempts:
+			want = http.StatusLocked
+		case i%types.PortalVerificationMaxAttempts == 0:
+			want = http.StatusUnauthorized
+		}
+		if code := verifyPortalAnswer(t, env, token, "1900-01-01"); code != want {
+			t.Fatalf("wrong answer %d: status = %d, want %d", i, code, want)
+		}
+	}
+
+	failures, err := env.store.GetPortalVerificationFailures(testTenantID, user.ClientID, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != types.PortalVerificationLockAttempts {
+		t.Fatalf("recorded %d failures, want %d", len(failures), types.PortalVerificationLockAttempts)
+	}
+	for i, failure := range failures {
+		wantCount := types.PortalVerificationLockAttempts - i
+		if failure.Failures != wantCount || failure.Locked != (i == 0) {
+			t.Errorf("failure %d: count %d locked %t, want %d locked %t", i, failure.Failures, failure.Locked, wantCount, i == 0)
+		}
+	}
+
+	actions := map[string]int{}
+	for _, entry := range env.store.AuditLogs() {
+		if entry.TenantUserID == nil || *entry.TenantUserID != user.ID || entry.EmployeeID != uuid.Nil {
+			t.Errorf("audit entry %s is not the portal user's: %+v", entry.Action, entry)
+			continue
+		}
+		actions[entry.Action]++
+	}
+	if actions[types.AuditActionVerifyFailed] != types.PortalVerificationLockAttempts || actions[types.AuditActionLock] != 1 {
+		t.Errorf("audited %v, want %d %s and 1 %s", actions, types.PortalVerificationLockAttempts, types.AuditActionVerifyFailed, types.AuditActionLock)
+	}
+
+	// Locked out users are refused on new sign-ins, even with the right answer
+	token = portalSignIn(env, user, types.PortalVerificationLockAttempts+1)
+	if code := verifyPortalAnswer(t, env, token, portalClientDOB); code != http.StatusLocked {
+		t.Fatalf("right answer while locked: status = %d, want %d", code, http.StatusLocked)
+	}
+	rec := env.do(t, http.MethodPost, "/api/v1/"+testTenantID+"/user/session/verify/start", token, "")
+	if rec.Code != http.StatusLocked {
+		t.Fatalf("start while locked: status = %d, want %d", rec.Code, http.StatusLocked)
+	}
+
+	// Sending a new portal link lifts the lock
+	result := bulk.LiftVerificationLock(env.store, testTenantID, user.ClientID, "Emailed portal link")
+	if !strings.HasSuffix(result, "; lifted verification lockout") {
+		t.Errorf("bulk result = %q, want the lockout lifted", result)
+	}
+	if code := verifyPortalAnswer(t, env, token, portalClientDOB); code != http.StatusOK {
+		t.Fatalf("right answer after new link: status = %d, want %d", code, http.StatusOK)
+	}
+	if result := bulk.LiftVerificationLock(env.store, testTenantID, user.ClientID, "Emailed portal link"); result != "Emailed portal link" {
+		t.Errorf("bulk result without a lock = %q", result)
+	}
+}
+
+func TestVerifyPortalSessionResetsFailures(t *testing.T) {
+	env, user := newPortalVerificationEnv(t)
+
+	token := portalSignIn(env, user, 1)
+	for i := 0; i < types.PortalVerificationMaxAttempts-1; i++ {
+		if code := verifyPortalAnswer(t, env, token, "1900-01-01"); code != http.StatusUnprocessableEntity {
+			t.Fatalf("wrong answer: status = %d, want %d", code, http.StatusUnprocessableEntity)
+		}
+	}
+	if code := verifyPortalAnswer(t, env, token, portalClientDOB); code != http.StatusOK {
+		t.Fatalf("right answer: status = %d, want %d", code, http.StatusOK)
+	}
+
+	// The count starts over after a correct answer
+	token = portalSignIn(env, user, 2)
+	if code := verifyPortalAnswer(t, env, token, "1900-01-01"); code != http.StatusUnprocessableEntity {
+		t.Fatalf("wrong answer after reset: status = %d, want %d", code, http.StatusUnprocessableEntity)
+	}
+	failures, err := env.store.GetPortalVerificationFailures(testTenantID, user.ClientID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(failures) != 1 || failures[0].Failures != 1 {
+		t.Fatalf("latest failure = %+v, want the count back at 1", failures)
+	}
+}
@@ -0,0 +1,122 @@
+package webapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// portalTenantUser resolves the authenticated tenant user for the URL's tenant and
+// writes the error response itself when access is not allowed
+func (api *API) portalTenantUser(w http.ResponseWriter, r *http.Request) (*types.TenantUser, bool) {
+	firebaseUID, err := middleware.GetFirebaseUIDFromContext(r.Context())
+	if err != nil {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return nil, false
+	}
+
+	tenantUser, err := api.store.GetTenantUserByFirebaseUID(firebaseUID)
+	if err != nil {
+		logger.Errorf("Failed to get tenant user for firebase uid %s: %v", firebaseUID, err)
+		if errors.Is(err, apperr.ErrNotFound) {
+			http.Error(w, "User not registered for portal access", http.StatusNotFound)
+			return nil, false
+		}
+		writeError(w, err, "Failed to fetch user")
+		return nil, false
+	}
+
+	if requestedTenantID := mux.Vars(r)["tenantId"]; tenantUser.TenantID != requestedTenantID {
+		logger.Warningf("Tenant mismatch: user belongs to %s but requested %s", tenantUser.TenantID, requestedTenantID)
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return nil, false
+	}
+
+	if api.isArchivedPortalClient(r.Context(), tenantUser) {
+		http.Error(w, "Portal access is disabled for this account", http.StatusForbidden)
+		return nil, false
+	}
+
+	return tenantUser, true
+}
+
+// getPortalSummary returns the portal home-screen summary: current filing, next action,
+// balance due and unread counts (tenant user only)
+func (api *API) getPortalSummary(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	// New users have no client record yet, so their only action is to start a filing
+	if tenantUser.ClientID == NewClientUUID {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(&types.PortalSummary{NextAction: types.PortalActionStartFiling})
+		return
+	}
+
+	clientData, err := api.store.GetClientComprehensive(r.Context(), tenantUser.TenantID, tenantUser.ClientID.String())
+	if err != nil {
+		logger.Errorf("Failed to get client data for portal summary: %v", err)
+		writeError(w, err, "Failed to fetch summary")
+		return
+	}
+
+	// Unread counts are best effort; a lookup failure must not block the summary
+	lastSeen, err := api.store.GetTenantUserLastSeen(tenantUser.ID)
+	if err != nil {
+		logger.Warningf("Unread counts unavailable for tenant user %s: %v", tenantUser.ID, err)
+	}
+
+	summary := clientData.PortalSummary(lastSeen)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(summary); err != nil {
+		logger.Errorf("Failed to encode portal summary response: %v", err)
+	}
+}
+
+// getPortalFilingSummary returns the compact summary of one of the user's filings (tenant user only)
+func (api *API) getPortalFilingSummary(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	filingID := mux.Vars(r)["filingId"]
+	if tenantUser.ClientID == NewClientUUID {
+		http.Error(w, "Filing not found", http.StatusNotFound)
+		return
+	}
+
+	clientData, err := api.store.GetClientComprehensive(r.Context(), tenantUser.TenantID, tenantUser.ClientID.String())
+	if err != nil {
+		logger.Errorf("Failed to get client data for filing summary: %v", err)
+		writeError(w, err, "Failed to fetch summary")
+		return
+	}
+
+	lastSeen, _ := api.store.GetTenantUserLastSeen(tenantUser.ID)
+
+	// Only filings belonging to the authenticated client are visible
+	for _, f := range clientData.Filings {
+		if f.ID.String() != filingID {
+			continue
+		}
+
+		summary, _ := f.Summary(lastSeen)
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(summary); err != nil {
+			logger.Errorf("Failed to encode filing summary response: %v", err)
+		}
+		return
+	}
+
+	http.Error(w, "Filing not found", http.StatusNotFound)
+}
@@ -0,0 +1,143 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// registerDevice registers an FCM token for push notifications (tenant user only)
+func (api *API) registerDevice(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Token    string `json:"token"`
+		Platform string `json:"platform"` // ios, android, web
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	req.Token = strings.TrimSpace(req.Token)
+	req.Platform = strings.ToLower(req.Platform)
+	if req.Token == "" || len(req.Token) > 4096 {
+		http.Error(w, "A valid device token is required", http.StatusBadRequest)
+		return
+	}
+	if !types.IsValidDevicePlatform(req.Platform) {
+		http.Error(w, "platform must be ios, android or web", http.StatusBadRequest)
+		return
+	}
+
+	device := &types.TenantUserDevice{
+		TenantUserID: tenantUser.ID,
+		Token:        req.Token,
+		Platform:     req.Platform,
+	}
+	if err := api.store.RegisterTenantUserDevice(device); err != nil {
+		writeError(w, err, "Failed to register device")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(device); err != nil {
+		logger.Errorf("Failed to encode device response: %v", err)
+	}
+}
+
+// getDevices lists the tenant user's registered devices (tenant user only)
+func (api *API) getDevices(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	devices, err := api.store.GetTenantUserDevices(tenantUser.ID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch devices")
+		return
+	}
+
+	if devices == nil {
+		devices = []*types.TenantUserDevice{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(devices); err != nil {
+		logger.Errorf("Failed to encode devices response: %v", err)
+	}
+}
+
+// deleteDevice unregisters one of the tenant user's devices, e.g. on sign-out (tenant user only)
+func (api *API) deleteDevice(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	deviceID, err := uuid.Parse(mux.Vars(r)["deviceId"])
+	if err != nil {
+		http.Error(w, "Invalid device ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.store.DeleteTenantUserDevice(tenantUser.ID, deviceID); err != nil {
+		writeError(w, err, "Failed to delete device")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getPushPreferences returns whether the tenant user receives push notifications (tenant user only)
+func (api *API) getPushPreferences(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	enabled, err := api.store.GetTenantUserPushEnabled(tenantUser.ID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch push preferences")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"enabled": enabled})
+}
+
+// updatePushPreferences opts the tenant user in or out of push notifications (tenant user only)
+func (api *API) updatePushPreferences(w http.ResponseWriter, r *http.Request) {
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+		http.Error(w, "enabled is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.store.SetTenantUserPushEnabled(tenantUser.ID, *req.Enabled); err != nil {
+		writeError(w, err, "Failed to update push preferences")
+		return
+	}
+
+	logger.Infof("Tenant user %s set push notifications enabled=%t", tenantUser.ID, *req.Enabled)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]bool{"enabled": *req.Enabled})
+}
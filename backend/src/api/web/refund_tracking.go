@@ -0,0 +1,117 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// getRefundTrackings returns where each of a filing's refunds stands
+func (api *API) getRefundTrackings(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	filingID := vars["filingId"]
+
+	trackings, err := api.store.GetRefundTrackings(r.Context(), tenantID, filingID)
+	if err != nil {
+		logger.Errorf("Failed to get refund tracking for filing %s: %v", filingID, err)
+		writeError(w, err, "Failed to fetch refund tracking")
+		return
+	}
+
+	if trackings == nil {
+		trackings = []*types.RefundTracking{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(trackings); err != nil {
+		logger.Errorf("Failed to encode refund tracking response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// putRefundTracking records the refund status for one jurisdiction (FEDERAL or a state code) of a filing
+// An accepted federal refund without a deposit window gets the IRS's typical window
+func (api *API) putRefundTracking(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	filingID, err := uuid.Parse(vars["filingId"])
+	if err != nil {
+		http.Error(w, "Invalid filing ID", http.StatusBadRequest)
+		return
+	}
+
+	var input types.RefundTracking
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	input.FilingID = filingID
+	input.Jurisdiction = strings.ToUpper(strings.TrimSpace(vars["jurisdiction"]))
+	if input.Source == "" {
+		input.Source = types.RefundSourceManual
+	}
+	input.UpdatedBy = &employee.Email
+
+	if msg := input.Validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	// Default the federal amount to the recorded return outcome
+	if input.ExpectedAmount == 0 && input.Jurisdiction == types.RefundJurisdictionFederal {
+		if result, err := api.store.GetFilingResult(r.Context(), tenantID, filingID.String()); err == nil {
+			input.ExpectedAmount = result.FederalRefund
+		}
+	}
+	input.EstimateDepositWindow()
+
+	logger.Infof("Recording %s refund status %s for filing %s in tenant %s", input.Jurisdiction, input.Status, filingID, tenantID)
+
+	tracking, err := api.store.UpsertRefundTracking(r.Context(), tenantID, &input)
+	if err != nil {
+		logger.Errorf("Failed to record refund tracking: %v", err)
+		writeError(w, err, "Failed to record refund tracking")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(tracking); err != nil {
+		logger.Errorf("Failed to encode refund tracking response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// deleteRefundTracking removes the refund record for one jurisdiction of a filing
+func (api *API) deleteRefundTracking(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	filingID := vars["filingId"]
+	jurisdiction := strings.ToUpper(vars["jurisdiction"])
+
+	logger.Infof("Deleting %s refund tracking for filing %s in tenant %s", jurisdiction, filingID, tenantID)
+
+	if err := api.store.DeleteRefundTracking(r.Context(), tenantID, filingID, jurisdiction); err != nil {
+		logger.Errorf("Failed to delete refund tracking: %v", err)
+		writeError(w, err, "Failed to delete refund tracking")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
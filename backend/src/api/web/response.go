@@ -0,0 +1,115 @@
+package webapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// writeSlimJSON encodes v with empty arrays removed and, when ?fields= is present,
+// only the requested fields kept. Fields are comma separated and may use dotted
+// paths that apply to every element of an array, e.g. ?fields=client,filings.year,filings.status
+func writeSlimJSON(w http.ResponseWriter, r *http.Request, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	// Numbers stay json.Number so int64 values above 2^53 keep their precision
+	var tree interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&tree); err != nil {
+		return err
+	}
+
+	if fields := r.URL.Query().Get("fields"); fields != "" {
+		tree = selectFields(tree, parseFieldPaths(fields))
+	}
+	tree = pruneEmptyArrays(tree)
+
+	w.Header().Set("Content-Type", "application/json")
+	return json.NewEncoder(w).Encode(tree)
+}
+
+// fieldTree maps a field name to the nested fields selected beneath it (nil selects everything)
+type fieldTree map[string]fieldTree
+
+// parseFieldPaths turns "a,b.c,b.d" into {a: nil, b: {c: nil, d: nil}}
+func parseFieldPaths(fields string) fieldTree {
+	root := fieldTree{}
+	for _, path := range strings.Split(fields, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+
+		node := root
+		parts := strings.Split(path, ".")
+		for i, part := range parts {
+			child, exists := node[part]
+			if i == len(parts)-1 {
+				// A shorter path selects the whole subtree
+				node[part] = nil
+				break
+			}
+			if exists && child == nil {
+				break
+			}
+			if !exists {
+				child = fieldTree{}
+				node[part] = child
+			}
+			node = child
+		}
+	}
+	return root
+}
+
+// selectFields keeps only the selected keys of objects, descending into arrays
+func selectFields(v interface{}, selected fieldTree) interface{} {
+	if selected == nil {
+		return v
+	}
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(selected))
+		for key, sub := range selected {
+			if val, ok := t[key]; ok {
+				out[key] = selectFields(val, sub)
+			}
+		}
+		return out
+	case []interface{}:
+		for i := range t {
+			t[i] = selectFields(t[i], selected)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+// pruneEmptyArrays removes object keys whose value is an empty array
+func pruneEmptyArrays(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for key, val := range t {
+			if arr, ok := val.([]interface{}); ok && len(arr) == 0 {
+				delete(t, key)
+				continue
+			}
+			t[key] = pruneEmptyArrays(val)
+		}
+		return t
+	case []interface{}:
+		for i := range t {
+			t[i] = pruneEmptyArrays(t[i])
+		}
+		return t
+	default:
+		return v
+	}
+}
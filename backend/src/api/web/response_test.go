@@ -0,0 +1,122 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseFieldPaths(t *testing.T) {
+	tests := []struct {
+		name   string
+		fields string
+		want   fieldTree
+	}{
+		{"single", "a", fieldTree{"a": nil}},
+		{"nested", "a.b.c", fieldTree{"a": {"b": {"c": nil}}}},
+		{"siblings", "a.b,a.c", fieldTree{"a": {"b": nil, "c": nil}}},
+		{"whole then part", "a,a.b", fieldTree{"a": nil}},
+		{"part then whole", "a.b,a", fieldTree{"a": nil}},
+		{"blanks and spaces", " a , ,b.c ", fieldTree{"a": nil, "b": {"c": nil}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseFieldPaths(tt.fields); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFieldPaths(%q) = %#v, want %#v", tt.fields, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteSlimJSON(t *testing.T) {
+	type filing struct {
+		Year   int      `json:"year"`
+		Status string   `json:"status"`
+		Forms  []string `json:"forms"`
+	}
+	type payload struct {
+		ID      int64    `json:"id"`
+		Name    string   `json:"name"`
+		Tags    []string `json:"tags"`
+		Filings []filing `json:"filings"`
+	}
+	v := payload{
+		ID:   9007199254740993, // 2^53 + 1
+		Name: "Ada",
+		Tags: []string{},
+		Filings: []filing{
+			{Year: 2024, Status: "FILED", Forms: []string{"W2"}},
+			{Year: 2025, Status: "DRAFT", Forms: []string{}},
+		},
+	}
+
+	tests := []struct {
+		name   string
+		fields string
+		want   string
+	}{
+		{
+			name: "all fields with empty arrays pruned",
+			want: `{"filings":[{"forms":["W2"],"status":"FILED","year":2024},{"status":"DRAFT","year":2025}],"id":9007199254740993,"name":"Ada"}`,
+		},
+		{
+			name:   "nested path inside an array",
+			fields: "id,filings.year",
+			want:   `{"filings":[{"year":2024},{"year":2025}],"id":9007199254740993}`,
+		},
+		{
+			name:   "overlapping paths select the whole subtree",
+			fields: "filings,filings.year",
+			want:   `{"filings":[{"forms":["W2"],"status":"FILED","year":2024},{"status":"DRAFT","year":2025}]}`,
+		},
+		{
+			name:   "selected empty array is pruned",
+			fields: "name,tags",
+			want:   `{"name":"Ada"}`,
+		},
+		{
+			name:   "unknown field",
+			fields: "missing",
+			want:   `{}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			url := "/"
+			if tt.fields != "" {
+				url += "?fields=" + tt.fields
+			}
+			rec := httptest.NewRecorder()
+			if err := writeSlimJSON(rec, httptest.NewRequest("GET", url, nil), v); err != nil {
+				t.Fatalf("writeSlimJSON: %v", err)
+			}
+
+			if got := strings.TrimSpace(rec.Body.String()); got != tt.want {
+				t.Errorf("body = %s, want %s", got, tt.want)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Errorf("Content-Type = %q, want application/json", ct)
+			}
+		})
+	}
+}
+
+func TestPruneEmptyArrays(t *testing.T) {
+	var v interface{}
+	if err := json.Unmarshal([]byte(`{"a":[],"b":{"c":[],"d":[{"e":[]}]},"f":[[]]}`), &v); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := json.Marshal(pruneEmptyArrays(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Empty arrays inside arrays are elements, not keys, and stay
+	if want := `{"b":{"d":[{}]},"f":[[]]}`; string(got) != want {
+		t.Errorf("pruneEmptyArrays = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,88 @@
+package webapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// runSchemaCheck validates a tenant schema against its adapter now (admin only)
+func (api *API) runSchemaCheck(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	logger.Infof("Running schema check for tenant %s", tenantID)
+
+	check, err := api.store.RunSchemaCheck(r.Context(), tenantID)
+	if err != nil {
+		logger.Errorf("Failed to run schema check for tenant %s: %v", tenantID, err)
+		writeError(w, err, "Failed to run schema check")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(check); err != nil {
+		logger.Errorf("Failed to encode schema check response: %v", err)
+	}
+}
+
+// runSchemaChecks validates every active tenant's adapter type and schema now and returns the
+// results, failing tenants first (admin only)
+func (api *API) runSchemaChecks(w http.ResponseWriter, r *http.Request) {
+	logger.Info("Running schema checks for all active tenants")
+
+	checks, err := api.store.RunSchemaChecks(r.Context())
+	if err != nil {
+		writeError(w, err, "Failed to run schema checks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(checks); err != nil {
+		logger.Errorf("Failed to encode schema checks response: %v", err)
+	}
+}
+
+// getSchemaCheck returns the latest schema check of a tenant (admin only)
+func (api *API) getSchemaCheck(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	check, err := api.store.GetSchemaCheck(tenantID)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNotFound) {
+			http.Error(w, "Schema has not been checked yet", http.StatusNotFound)
+			return
+		}
+		writeError(w, err, "Failed to get schema check")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(check); err != nil {
+		logger.Errorf("Failed to encode schema check response: %v", err)
+	}
+}
+
+// getSchemaChecks returns the latest schema check of every tenant, failing tenants first (admin only)
+func (api *API) getSchemaChecks(w http.ResponseWriter, r *http.Request) {
+	checks, err := api.store.GetSchemaChecks()
+	if err != nil {
+		writeError(w, err, "Failed to get schema checks")
+		return
+	}
+
+	if checks == nil {
+		checks = []*types.SchemaCheck{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(checks); err != nil {
+		logger.Errorf("Failed to encode schema checks response: %v", err)
+	}
+}
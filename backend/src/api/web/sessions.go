@@ -0,0 +1,252 @@
+package webapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/auth"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// sessionTokens is returned when a session is opened or refreshed. RefreshToken is issued by the
+// API and replaced on every refresh; the Firebase refresh token stays on the server.
+type sessionTokens struct {
+	SessionID        uuid.UUID `json:"sessionId"`
+	IDToken          string    `json:"idToken"`
+	RefreshToken     string    `json:"refreshToken"`
+	ExpiresIn        string    `json:"expiresIn"` // Seconds until the ID token expires
+	SessionExpiresAt time.Time `json:"sessionExpiresAt"`
+}
+
+// refreshTokenRequest is the body of the refresh and logout endpoints
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// login signs an employee in with email and password and opens a session (public)
+func (api *API) login(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Email    string `json:"email"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" || req.Password == "" {
+		http.Error(w, "email and password are required", http.StatusBadRequest)
+		return
+	}
+
+	signIn, err := api.auth.SignInWithEmailAndPassword(req.Email, req.Password)
+	if err != nil {
+		http.Error(w, "Invalid email or password", http.StatusUnauthorized)
+		return
+	}
+	decodedToken, err := api.auth.VerifyToken(r.Context(), signIn.IDToken)
+	if err != nil {
+		logger.Errorf("Failed to verify ID token from sign-in of %s: %v", req.Email, err)
+		http.Error(w, "Failed to sign in", http.StatusInternalServerError)
+		return
+	}
+
+	// Only active employees get a session
+	employee, err := api.store.GetEmployeeByFirebaseUID(decodedToken.UID)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNotFound) {
+			logger.Warningf("Sign-in of %s has no active employee", req.Email)
+			http.Error(w, "Unauthorized: Employee not found", http.StatusUnauthorized)
+			return
+		}
+		writeError(w, err, "Failed to sign in")
+		return
+	}
+
+	token, tokenHash, err := auth.NewSessionToken()
+	if err != nil {
+		logger.Errorf("Failed to generate session token: %v", err)
+		http.Error(w, "Failed to sign in", http.StatusInternalServerError)
+		return
+	}
+
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	session := &types.EmployeeSession{
+		EmployeeID:  employee.ID,
+		FirebaseUID: decodedToken.UID,
+		AuthTime:    decodedToken.AuthTime,
+		IPAddress:   &ipAddress,
+		UserAgent:   &userAgent,
+	}
+	if err := api.store.CreateEmployeeSession(session, signIn.RefreshToken, tokenHash); err != nil {
+		writeError(w, err, "Failed to sign in")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(sessionTokens{
+		SessionID:        session.ID,
+		IDToken:          signIn.IDToken,
+		RefreshToken:     token,
+		ExpiresIn:        signIn.ExpiresIn,
+		SessionExpiresAt: session.ExpiresAt,
+	}); err != nil {
+		logger.Errorf("Failed to encode login response: %v", err)
+	}
+}
+
+// refreshSession exchanges a session's refresh token for a new ID token and refresh token (public)
+func (api *API) refreshSession(w http.ResponseWriter, r *http.Request) {
+	var req refreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, "refreshToken is required", http.StatusBadRequest)
+		return
+	}
+
+	token, tokenHash, err := auth.NewSessionToken()
+	if err != nil {
+		logger.Errorf("Failed to generate session token: %v", err)
+		http.Error(w, "Failed to refresh session", http.StatusInternalServerError)
+		return
+	}
+
+	var refreshed *auth.RefreshTokenResponse
+	session, err := api.store.RotateEmployeeSession(auth.HashSessionToken(req.RefreshToken), tokenHash, func(firebaseRefreshToken string) (string, error) {
+		var err error
+		if refreshed, err = api.auth.RefreshToken(firebaseRefreshToken); err != nil {
+			return "", err
+		}
+		return refreshed.RefreshToken, nil
+	})
+	if err != nil {
+		writeSessionError(w, err, "Failed to refresh session")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sessionTokens{
+		SessionID:        session.ID,
+		IDToken:          refreshed.IDToken,
+		RefreshToken:     token,
+		ExpiresIn:        refreshed.ExpiresIn,
+		SessionExpiresAt: session.ExpiresAt,
+	}); err != nil {
+		logger.Errorf("Failed to encode refresh response: %v", err)
+	}
+}
+
+// logout revokes the session a refresh token belongs to, which also rejects its ID tokens (public)
+func (api *API) logout(w http.ResponseWriter, r *http.Request) {
+	var req refreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, "refreshToken is required", http.StatusBadRequest)
+		return
+	}
+
+	// Logging out of a session that was already revoked succeeds
+	_, err := api.store.RevokeEmployeeSessionByToken(auth.HashSessionToken(req.RefreshToken), types.SessionRevokeLogout)
+	if err != nil && !errors.Is(err, apperr.ErrConflict) {
+		writeSessionError(w, err, "Failed to log out")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getEmployeeSessions lists sessions, optionally for one ?employeeId=; ?all=true includes
+// revoked and expired sessions (admin only)
+func (api *API) getEmployeeSessions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	var employeeID *uuid.UUID
+	if v := query.Get("employeeId"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			http.Error(w, "Invalid employee ID", http.StatusBadRequest)
+			return
+		}
+		employeeID = &id
+	}
+
+	sessions, err := api.store.GetEmployeeSessions(employeeID, query.Get("all") != "true")
+	if err != nil {
+		writeError(w, err, "Failed to fetch sessions")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(sessions); err != nil {
+		logger.Errorf("Failed to encode sessions response: %v", err)
+	}
+}
+
+// revokeEmployeeSession revokes one session (admin only)
+func (api *API) revokeEmployeeSession(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	sessionID, err := uuid.Parse(mux.Vars(r)["sessionId"])
+	if err != nil {
+		http.Error(w, "Invalid session ID", http.StatusBadRequest)
+		return
+	}
+
+	session, err := api.store.RevokeEmployeeSession(sessionID, &employee.ID, types.SessionRevokeAdmin)
+	if err != nil {
+		writeError(w, err, "Failed to revoke session")
+		return
+	}
+	logger.Warningf("%s revoked session %s of employee %s", employee.Email, session.ID, session.EmployeeID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// revokeEmployeeSessions revokes every session of ?employeeId= (admin only)
+func (api *API) revokeEmployeeSessions(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	employeeID, err := uuid.Parse(r.URL.Query().Get("employeeId"))
+	if err != nil {
+		http.Error(w, "employeeId is required", http.StatusBadRequest)
+		return
+	}
+
+	sessions, err := api.store.RevokeEmployeeSessions(employeeID, &employee.ID, types.SessionRevokeAdmin)
+	if err != nil {
+		writeError(w, err, "Failed to revoke sessions")
+		return
+	}
+	logger.Warningf("%s revoked %d sessions of employee %s", employee.Email, len(sessions), employeeID)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(map[string]int{"revoked": len(sessions)}); err != nil {
+		logger.Errorf("Failed to encode revoke sessions response: %v", err)
+	}
+}
+
+// writeSessionError answers a refused refresh token with 401, so clients know to sign in again
+func writeSessionError(w http.ResponseWriter, err error, message string) {
+	if msg, ok := apperr.Message(err); ok && errors.Is(err, apperr.ErrPermission) {
+		http.Error(w, "Unauthorized: "+msg, http.StatusUnauthorized)
+		return
+	}
+	writeError(w, err, message)
+}
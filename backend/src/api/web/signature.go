@@ -1,18 +1,31 @@
 package webapi
 
 import (
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/middleware"
 	"welltaxpro/src/internal/signature"
+	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
+	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
 // SignatureRequest represents the request body for signature endpoint
 type SignatureRequest struct {
-	PDFPath            string   `json:"pdfPath"`
+	PDFPath            string   `json:"pdfPath,omitempty"` // Only used when the tenant has no Form 8879 template
+	FilingID           string   `json:"filingId,omitempty"` // When set, amounts come from the filing's recorded result
 	TaxPayerEmail      string   `json:"taxPayerEmail"`
 	TaxPayerName       string   `json:"taxPayerName"`
 	TaxPayerSsn        string   `json:"taxPayerSsn"`
@@ -29,6 +42,38 @@ type SignatureRequest struct {
 	SpouseSignature    bool     `json:"spouseSignature"`
 }
 
+// formData maps the request onto form data keys for filling a Form 8879 template
+func (req *SignatureRequest) formData() map[string]string {
+	data := map[string]string{
+		types.FormKeyTaxpayerFullName: req.TaxPayerName,
+		types.FormKeyTaxpayerSSN:      req.TaxPayerSsn,
+		types.FormKeyTaxpayerEmail:    req.TaxPayerEmail,
+		types.FormKeyGrossIncome:      types.FormatFormAmount(req.GrossIncome),
+		types.FormKeyTotalTax:         types.FormatFormAmount(req.TotalTax),
+		types.FormKeyTaxWithheld:      types.FormatFormAmount(req.TaxWithHeld),
+		types.FormKeyRefund:           types.FormatFormAmount(req.Refund),
+		types.FormKeyOwed:             types.FormatFormAmount(req.Owed),
+		types.FormKeyPreparedDate:     time.Now().Format("01/02/2006"),
+	}
+
+	if req.TaxPayerSpouseName != nil {
+		data[types.FormKeySpouseFullName] = *req.TaxPayerSpouseName
+	} else if req.SpouseName != "" {
+		data[types.FormKeySpouseFullName] = req.SpouseName
+	}
+	if req.TaxPayerSpouseSsn != nil {
+		data[types.FormKeySpouseSSN] = *req.TaxPayerSpouseSsn
+	}
+	if req.SpouseEmail != "" {
+		data[types.FormKeySpouseEmail] = req.SpouseEmail
+	}
+	if req.SignatureDate != nil {
+		data[types.FormKeyPreparedDate] = *req.SignatureDate
+	}
+
+	return data
+}
+
 // sendSignatureRequest sends a document to DocuSign for signature (admin only)
 func (api *API) sendSignatureRequest(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
@@ -45,10 +90,6 @@ func (api *API) sendSignatureRequest(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Validate required fields
-	if req.PDFPath == "" {
-		http.Error(w, "PDF path is required", http.StatusBadRequest)
-		return
-	}
 	if req.TaxPayerEmail == "" || req.TaxPayerName == "" || req.TaxPayerSsn == "" {
 		http.Error(w, "Taxpayer information is required", http.StatusBadRequest)
 		return
@@ -58,11 +99,26 @@ func (api *API) sendSignatureRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Amounts recorded by the accountant take precedence over ones typed into the request
+	if req.FilingID != "" {
+		result, err := api.store.GetFilingResult(r.Context(), tenantID, req.FilingID)
+		if err != nil {
+			logger.Errorf("Failed to get result for filing %s: %v", req.FilingID, err)
+			http.Error(w, "No result has been recorded for this filing", http.StatusBadRequest)
+			return
+		}
+		req.GrossIncome = result.AGI
+		req.TotalTax = result.TotalTax
+		req.TaxWithHeld = result.FederalWithholding
+		req.Refund = result.FederalRefund
+		req.Owed = result.FederalOwed
+	}
+
 	// Get tenant config for DocuSign settings
 	tc, err := api.store.GetTenantConfig(tenantID)
 	if err != nil {
 		logger.Errorf("Failed to get tenant config: %v", err)
-		http.Error(w, "Failed to get tenant configuration", http.StatusInternalServerError)
+		writeError(w, err, "Failed to get tenant configuration")
 		return
 	}
 
@@ -84,19 +140,38 @@ func (api *API) sendSignatureRequest(w http.ResponseWriter, r *http.Request) {
 		SpouseSignature:    req.SpouseSignature,
 	}
 
-	// Send to DocuSign
-	if err := signature.SignDocument(context.Background(), tc, req.PDFPath, sig); err != nil {
+	// Prefer the tenant's fillable 8879; the PDF path with positioned text tabs is the legacy fallback
+	if template, err := api.store.GetFormTemplate(tenantID, types.FormKind8879); err == nil {
+		document, err := api.fillFormTemplate(r.Context(), tc, template, req.formData())
+		if err != nil {
+			logger.Errorf("Failed to fill Form 8879 template for tenant %s: %v", tenantID, err)
+			http.Error(w, "Failed to prepare Form 8879", http.StatusInternalServerError)
+			return
+		}
+		sig.Document = document
+	} else if req.PDFPath == "" {
+		http.Error(w, "PDF path is required when no Form 8879 template is configured", http.StatusBadRequest)
+		return
+	}
+
+	// Send to DocuSign in the request's trace, but finish even if the client goes away, since
+	// the envelope may already be sent
+	envelopeID, err := signature.SignDocument(context.WithoutCancel(r.Context()), tc, req.PDFPath, sig)
+	api.recordSignatureEnvelope(r, tenantID, req.FilingID, err)
+	if err != nil {
 		logger.Errorf("Failed to send signature request: %v", err)
 		http.Error(w, "Failed to send signature request", http.StatusInternalServerError)
 		return
 	}
+	api.recordSignatureRequest(r, tenantID, envelopeID, &req)
 
 	logger.Infof("Successfully sent signature request for tenant %s", tenantID)
 
 	// Return success response
 	response := map[string]string{
-		"status":  "sent",
-		"message": "Signature request sent successfully",
+		"status":     "sent",
+		"message":    "Signature request sent successfully",
+		"envelopeId": envelopeID,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -105,3 +180,318 @@ func (api *API) sendSignatureRequest(w http.ResponseWriter, r *http.Request) {
 		logger.Errorf("Failed to encode response: %v", err)
 	}
 }
+
+// recordSignatureEnvelope stores the outcome of a DocuSign request for the admin overview
+func (api *API) recordSignatureEnvelope(r *http.Request, tenantID, filingID string, sendErr error) {
+	envelope := &types.SignatureEnvelope{
+		TenantID: tenantID,
+		Status:   types.EnvelopeStatusSent,
+	}
+	if sendErr != nil {
+		msg := sendErr.Error()
+		envelope.Status = types.EnvelopeStatusFailed
+		envelope.Error = &msg
+	}
+	if id, err := uuid.Parse(filingID); err == nil {
+		envelope.FilingID = &id
+	}
+	if employee, ok := middleware.GetEmployeeFromContext(r.Context()); ok {
+		envelope.SentBy = &employee.ID
+	}
+
+	if err := api.store.RecordSignatureEnvelope(envelope); err != nil {
+		logger.Errorf("Failed to record signature envelope for tenant %s: %v", tenantID, err)
+	}
+}
+
+// recordSignatureRequest tracks a sent envelope so DocuSign Connect events can advance its status.
+// The envelope is already with the signers, so a failure here is only logged.
+func (api *API) recordSignatureRequest(r *http.Request, tenantID, envelopeID string, req *SignatureRequest) {
+	request := &types.SignatureRequest{
+		TenantID:        tenantID,
+		EnvelopeID:      envelopeID,
+		TaxpayerName:    req.TaxPayerName,
+		TaxpayerEmail:   req.TaxPayerEmail,
+		SpouseSignature: req.SpouseSignature,
+	}
+	if id, err := uuid.Parse(req.FilingID); err == nil {
+		request.FilingID = &id
+	}
+	if employee, ok := middleware.GetEmployeeFromContext(r.Context()); ok {
+		request.SentBy = &employee.ID
+	}
+
+	if err := api.store.CreateSignatureRequest(request); err != nil {
+		logger.Errorf("Failed to track envelope %s for tenant %s: %v", envelopeID, tenantID, err)
+	}
+}
+
+// getSignatureRequests lists a tenant's DocuSign envelopes with their latest status, newest first
+// Query params: status (SENT, DELIVERED, COMPLETED, DECLINED or VOIDED), unsigned (true for SENT
+// and DELIVERED), limit (1-500, default 100)
+func (api *API) getSignatureRequests(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+	query := r.URL.Query()
+
+	var statuses []string
+	if status := strings.ToUpper(query.Get("status")); status != "" {
+		if !types.IsValidSignatureStatus(status) {
+			http.Error(w, "status must be SENT, DELIVERED, COMPLETED, DECLINED or VOIDED", http.StatusBadRequest)
+			return
+		}
+		statuses = []string{status}
+	}
+	if unsigned := query.Get("unsigned"); unsigned != "" {
+		parsed, err := strconv.ParseBool(unsigned)
+		if err != nil {
+			http.Error(w, "unsigned must be true or false", http.StatusBadRequest)
+			return
+		}
+		if parsed {
+			if statuses != nil {
+				http.Error(w, "status and unsigned cannot be combined", http.StatusBadRequest)
+				return
+			}
+			statuses = types.SignatureStatusesUnsigned
+		}
+	}
+
+	limit := 100 // default
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 500 {
+			http.Error(w, "limit must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	requests, err := api.store.GetSignatureRequests(tenantID, statuses, limit)
+	if err != nil {
+		writeError(w, err, "Failed to fetch signature requests")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(requests); err != nil {
+		logger.Errorf("Failed to encode signature requests response: %v", err)
+	}
+}
+
+// receiveSignatureWebhook applies a DocuSign Connect envelope event to its signature request.
+// Events are authenticated by the HMAC key configured for the tenant; events for envelopes this
+// service did not send, and events that do not change the status, are acknowledged so DocuSign
+// stops retrying them.
+func (api *API) receiveSignatureWebhook(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "Failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	secret, err := api.store.GetDocuSignConnectSecret(tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to process webhook")
+		return
+	}
+	if secret == "" {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := signature.VerifyConnectSignature(r.Header, body, secret); err != nil {
+		logger.Warningf("Rejected DocuSign Connect event for tenant %s with invalid signature from %s", tenantID, r.RemoteAddr)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	event, err := signature.ParseConnectEvent(body)
+	if err != nil {
+		logger.Warningf("Rejected DocuSign Connect event for tenant %s: %v", tenantID, err)
+		http.Error(w, "Invalid webhook", http.StatusBadRequest)
+		return
+	}
+	if event.Status == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	request, err := api.store.GetSignatureRequestByEnvelope(tenantID, event.EnvelopeID)
+	if err != nil {
+		if apperr.Status(err) == http.StatusNotFound {
+			logger.Warningf("Ignoring DocuSign Connect %s event: %v", event.Event, err)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		http.Error(w, "Failed to process webhook", http.StatusInternalServerError)
+		return
+	}
+	if !types.SignatureStatusAdvances(request.Status, event.Status) {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	advanced, err := api.store.AdvanceSignatureRequest(request, event.Status)
+	if err != nil {
+		http.Error(w, "Failed to process webhook", http.StatusInternalServerError)
+		return
+	}
+
+	// Archive the signed PDF without holding up DocuSign, which expects a quick acknowledgement
+	if advanced && request.Status == types.SignatureStatusCompleted && request.FilingID != nil {
+		go api.archiveSignedDocument(context.WithoutCancel(r.Context()), tenantID, request)
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// updateDocuSignConnectSecret sets or clears the HMAC key a tenant's DocuSign Connect
+// configuration signs events with (admin only)
+func (api *API) updateDocuSignConnectSecret(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var req struct {
+		Secret string `json:"secret"` // Empty clears the key and rejects Connect events
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Secret = strings.TrimSpace(req.Secret)
+	if len(req.Secret) > 500 {
+		http.Error(w, "secret must be at most 500 characters", http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Updating DocuSign Connect secret for tenant %s", tenantID)
+
+	if err := api.store.UpdateDocuSignConnectSecret(tenantID, req.Secret, employee.ID); err != nil {
+		writeError(w, err, "Failed to update DocuSign Connect secret")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// archiveSignedDocument downloads a completed envelope's signed PDF from DocuSign, stores it in the
+// tenant's bucket and records it as a document of the request's filing. The outcome is recorded on
+// the request, so failed attempts can be seen and retried from the admin endpoint.
+func (api *API) archiveSignedDocument(ctx context.Context, tenantID string, request *types.SignatureRequest) (*types.Document, error) {
+	document, archiveErr := api.storeSignedDocument(ctx, tenantID, request)
+	var documentID *uuid.UUID
+	if archiveErr == nil {
+		documentID = &document.ID
+	} else {
+		logger.Errorf("Failed to archive signed document of envelope %s: %v", request.EnvelopeID, archiveErr)
+	}
+
+	if err := api.store.RecordSignatureArchive(request, documentID, archiveErr); err != nil && archiveErr == nil {
+		return document, err
+	}
+	return document, archiveErr
+}
+
+// storeSignedDocument does the work of archiveSignedDocument
+func (api *API) storeSignedDocument(ctx context.Context, tenantID string, request *types.SignatureRequest) (*types.Document, error) {
+	if request.Status != types.SignatureStatusCompleted {
+		return nil, apperr.Conflict("envelope %s is %s, not %s", request.EnvelopeID, request.Status, types.SignatureStatusCompleted)
+	}
+	if request.FilingID == nil {
+		return nil, apperr.Validation("signature request %s was sent without a filing to attach the signed document to", request.ID)
+	}
+
+	clientID, err := api.store.GetFilingClientID(ctx, tenantID, *request.FilingID)
+	if err != nil {
+		return nil, err
+	}
+
+	tc, err := api.store.GetTenantConfig(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf, err := signature.DownloadSignedDocument(context.Background(), tc, request.EnvelopeID)
+	if err != nil {
+		return nil, err
+	}
+
+	storageProvider, err := api.storageForTenant(context.Background(), tc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	// Same layout as uploaded documents: {userId}/{type}/{name}_{hash}.pdf
+	sum := sha256.Sum256(pdf)
+	storagePath := fmt.Sprintf("%s/%s/form_8879_signed_%s.pdf", clientID, types.DocumentTypeSignedForm8879, hex.EncodeToString(sum[:])[:16])
+	metadata := map[string]string{
+		"tenant_id":     tenantID,
+		"filing_id":     request.FilingID.String(),
+		"user_id":       clientID.String(),
+		"document_type": types.DocumentTypeSignedForm8879,
+		"source":        "docusign",
+		"envelope_id":   request.EnvelopeID,
+	}
+	if err := storageProvider.Upload(context.Background(), tc.StorageBucket, storagePath, bytes.NewReader(pdf), metadata); err != nil {
+		return nil, fmt.Errorf("failed to upload signed document: %w", err)
+	}
+
+	document, err := api.store.CreateDocument(ctx, tenantID, &types.Document{
+		ID:       uuid.New(),
+		UserID:   clientID,
+		FilingID: request.FilingID,
+		Name:     "Form 8879 (signed).pdf",
+		FilePath: storagePath,
+		Type:     types.DocumentTypeSignedForm8879,
+	})
+	if err != nil {
+		// Try to clean up uploaded file
+		storageProvider.Delete(context.Background(), tc.StorageBucket, storagePath)
+		return nil, fmt.Errorf("failed to create document record: %w", err)
+	}
+
+	logger.Infof("Archived signed document of envelope %s as document %s", request.EnvelopeID, document.ID)
+	return document, nil
+}
+
+// archiveSignatureRequest archives the signed PDF of a completed envelope now (admin only).
+// Completed envelopes are archived automatically when DocuSign Connect reports them; this
+// retries a failed archive or archives envelopes completed before Connect was set up.
+func (api *API) archiveSignatureRequest(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	requestID, err := uuid.Parse(vars["requestId"])
+	if err != nil {
+		http.Error(w, "Invalid signature request ID", http.StatusBadRequest)
+		return
+	}
+
+	request, err := api.store.GetSignatureRequest(tenantID, requestID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch signature request")
+		return
+	}
+	if request.DocumentID != nil {
+		http.Error(w, fmt.Sprintf("Signed document is already archived as document %s", request.DocumentID), http.StatusConflict)
+		return
+	}
+
+	document, err := api.archiveSignedDocument(r.Context(), tenantID, request)
+	if err != nil {
+		writeError(w, err, "Failed to archive signed document")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(document); err != nil {
+		logger.Errorf("Failed to encode document response: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// getSigningKeys lists a tenant's request signing keys without their secrets (admin only)
+func (api *API) getSigningKeys(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	keys, err := api.store.GetSigningKeys(tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch signing keys")
+		return
+	}
+
+	if keys == nil {
+		keys = []*types.SigningKey{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(keys); err != nil {
+		logger.Errorf("Failed to encode signing keys response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// createSigningKey issues a new request signing key for a tenant (admin only)
+// The secret is only returned in this response. A tenant's first key makes signatures mandatory
+// on its public tracking endpoints, so install it in the official snippets before relying on it.
+func (api *API) createSigningKey(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var req struct {
+		Label string `json:"label"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.Label = strings.TrimSpace(req.Label)
+	if req.Label == "" {
+		http.Error(w, "label is required", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := api.store.GetTenantConfig(tenantID); err != nil {
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	key, err := api.store.CreateSigningKey(tenantID, req.Label, employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to create signing key")
+		return
+	}
+
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	details := map[string]interface{}{
+		"keyId": key.KeyID,
+		"label": key.Label,
+	}
+	if err := api.store.CreateAuditLog(employee.ID, tenantID, nil, types.AuditActionCreate, types.AuditResourceSigningKey, &key.ID, details, &ipAddress, &userAgent); err != nil {
+		logger.Errorf("Failed to audit signing key %s: %v", key.KeyID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(key); err != nil {
+		logger.Errorf("Failed to encode signing key response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// revokeSigningKey stops a signing key from verifying requests (admin only)
+func (api *API) revokeSigningKey(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	keyID := vars["keyId"]
+
+	if err := api.store.RevokeSigningKey(tenantID, keyID); err != nil {
+		writeError(w, err, "Failed to revoke signing key")
+		return
+	}
+
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	details := map[string]interface{}{"keyId": keyID}
+	if err := api.store.CreateAuditLog(employee.ID, tenantID, nil, types.AuditActionRevoke, types.AuditResourceSigningKey, nil, details, &ipAddress, &userAgent); err != nil {
+		logger.Errorf("Failed to audit signing key revocation %s: %v", keyID, err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
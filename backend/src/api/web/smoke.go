@@ -0,0 +1,191 @@
+package webapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/notification"
+	"welltaxpro/src/internal/storage"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// runSmokeTest verifies a deployment end to end against the built-in smoke tenant (admin only).
+// Each step replays the caller's credentials through the router, so requests pass through the
+// same auth, audit and limits middleware, handlers, store and adapter as real traffic, while the
+// smoke tenant's adapter, storage and email stay in memory.
+func (api *API) runSmokeTest(w http.ResponseWriter, r *http.Request) {
+	authorization := r.Header.Get("Authorization")
+	runID := uuid.New().String()[:8]
+
+	logger.Infof("Running smoke test %s", runID)
+
+	result := &types.SmokeTestResult{StartedAt: time.Now(), Steps: []*types.SmokeStep{}}
+	step := func(name string, check func() error) bool {
+		start := time.Now()
+		s := &types.SmokeStep{Name: name, Passed: true}
+		if err := check(); err != nil {
+			s.Passed = false
+			s.Error = err.Error()
+			logger.Warningf("Smoke test %s failed at %s: %v", runID, name, err)
+		}
+		s.DurationMs = time.Since(start).Milliseconds()
+		result.Steps = append(result.Steps, s)
+		return s.Passed
+	}
+
+	tenantPath := "/api/v1/" + types.SmokeTenantID
+
+	// Auth middleware, handler, store and adapter
+	step("list_clients", func() error {
+		var clients []*types.Client
+		if err := api.smokeRequest(authorization, http.MethodGet, tenantPath+"/clients", "", nil, http.StatusOK, &clients); err != nil {
+			return err
+		}
+		for _, client := range clients {
+			if client.ID == types.SmokeClientID {
+				return nil
+			}
+		}
+		return fmt.Errorf("seeded client %s missing from %d clients", types.SmokeClientID, len(clients))
+	})
+
+	// Storage upload, download and delete through the document endpoints
+	content := []byte("WellTaxPro smoke test " + runID)
+	var document types.Document
+	uploaded := step("upload_document", func() error {
+		body, contentType, err := smokeUploadForm("smoke-"+runID+".txt", content)
+		if err != nil {
+			return err
+		}
+		path := fmt.Sprintf("%s/filings/%s/documents", tenantPath, types.SmokeFilingID)
+		if err := api.smokeRequest(authorization, http.MethodPost, path, contentType, body, http.StatusCreated, &document); err != nil {
+			return err
+		}
+
+		rc, err := storage.Memory.Download(r.Context(), types.SmokeStorageBucket, document.FilePath)
+		if err != nil {
+			return fmt.Errorf("uploaded file not in storage: %w", err)
+		}
+		defer rc.Close()
+		stored, err := io.ReadAll(rc)
+		if err != nil {
+			return fmt.Errorf("failed to read stored file: %w", err)
+		}
+		if !bytes.Equal(stored, content) {
+			return fmt.Errorf("stored file does not match the upload")
+		}
+		return nil
+	})
+
+	if uploaded {
+		step("download_document", func() error {
+			var download map[string]string
+			path := fmt.Sprintf("%s/documents/%s/download", tenantPath, document.ID)
+			if err := api.smokeRequest(authorization, http.MethodGet, path, "", nil, http.StatusOK, &download); err != nil {
+				return err
+			}
+			if download["url"] == "" {
+				return fmt.Errorf("download response has no URL")
+			}
+			return nil
+		})
+
+		step("delete_document", func() error {
+			path := fmt.Sprintf("%s/documents/%s", tenantPath, document.ID)
+			if err := api.smokeRequest(authorization, http.MethodDelete, path, "", nil, http.StatusNoContent, nil); err != nil {
+				return err
+			}
+			if _, err := api.store.GetDocumentByID(r.Context(), types.SmokeTenantID, document.ID.String()); err == nil {
+				return fmt.Errorf("document %s still exists after delete", document.ID)
+			}
+			return nil
+		})
+	}
+
+	// Email is captured for the smoke domain instead of being sent
+	step("send_notification", func() error {
+		if api.emailService == nil {
+			return fmt.Errorf("email service is not configured")
+		}
+		to := fmt.Sprintf("smoke-%s@%s", runID, types.SmokeEmailDomain)
+		subject, htmlBody, textBody := notification.GenerateAlertEmail(notification.AlertEmail{
+			RecipientName: "Smoke Test",
+			Subject:       "Smoke test " + runID,
+			Body:          "This message was captured by the WellTaxPro smoke test and was not delivered.",
+		})
+		if err := api.emailService.SendEmail(to, "Smoke Test", subject, htmlBody, textBody); err != nil {
+			return err
+		}
+		if captured := api.emailService.CapturedEmails(to); len(captured) != 1 || captured[0].Subject != subject {
+			return fmt.Errorf("notification to %s was not captured", to)
+		}
+		return nil
+	})
+
+	result.Passed = true
+	for _, s := range result.Steps {
+		result.Passed = result.Passed && s.Passed
+	}
+	result.DurationMs = time.Since(result.StartedAt).Milliseconds()
+
+	logger.Infof("Smoke test %s finished: passed=%v in %dms", runID, result.Passed, result.DurationMs)
+
+	status := http.StatusOK
+	if !result.Passed {
+		status = http.StatusServiceUnavailable
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(result); err != nil {
+		logger.Errorf("Failed to encode smoke test response: %v", err)
+	}
+}
+
+// smokeRequest serves one request through the router with the caller's credentials and decodes
+// the JSON response into out when out is non-nil
+func (api *API) smokeRequest(authorization, method, path, contentType string, body io.Reader, wantStatus int, out interface{}) error {
+	req := httptest.NewRequest(method, path, body)
+	req.Header.Set("Authorization", authorization)
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	rec := httptest.NewRecorder()
+	api.Router.ServeHTTP(rec, req)
+
+	if rec.Code != wantStatus {
+		return fmt.Errorf("%s %s returned %d, want %d: %s", method, path, rec.Code, wantStatus, strings.TrimSpace(rec.Body.String()))
+	}
+	if out != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), out); err != nil {
+			return fmt.Errorf("%s %s returned invalid JSON: %w", method, path, err)
+		}
+	}
+	return nil
+}
+
+// smokeUploadForm builds the multipart body of a document upload for the smoke client
+func smokeUploadForm(filename string, content []byte) (io.Reader, string, error) {
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	form.WriteField("type", "SMOKE_TEST")
+	form.WriteField("userId", types.SmokeClientID.String())
+	part, err := form.CreateFormFile("file", filename)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to build upload: %w", err)
+	}
+	part.Write(content)
+	if err := form.Close(); err != nil {
+		return nil, "", fmt.Errorf("failed to build upload: %w", err)
+	}
+	return body, form.FormDataContentType(), nil
+}
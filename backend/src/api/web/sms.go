@@ -0,0 +1,151 @@
+package webapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/sms"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// getSMSSettings returns the effective SMS settings for a tenant (admin only)
+func (api *API) getSMSSettings(w http.ResponseWriter, r *http.Request) {
+	settings, err := api.store.GetSMSSettings(mux.Vars(r)["tenantId"])
+	if err != nil {
+		writeError(w, err, "Failed to fetch SMS settings")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(settings); err != nil {
+		logger.Errorf("Failed to encode SMS settings response: %v", err)
+	}
+}
+
+// updateSMSSettings replaces the SMS settings for a tenant (admin only). Bulk operations already
+// running keep the settings they started with.
+func (api *API) updateSMSSettings(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var settings types.SMSSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := settings.Validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Updating SMS settings for tenant %s", tenantID)
+
+	if err := api.store.UpdateSMSSettings(tenantID, &settings, employee.ID); err != nil {
+		writeError(w, err, "Failed to update SMS settings")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(settings); err != nil {
+		logger.Errorf("Failed to encode SMS settings response: %v", err)
+	}
+}
+
+// getSMSMessages returns a tenant's latest text messages with their delivery status, newest
+// first (admin only)
+// Query params: clientId, status (PENDING, SENT, DELIVERED, UNDELIVERED or FAILED), limit (1-500, default 100)
+func (api *API) getSMSMessages(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &types.SMSMessageFilter{Limit: 100}
+
+	if clientIDStr := query.Get("clientId"); clientIDStr != "" {
+		clientID, err := uuid.Parse(clientIDStr)
+		if err != nil {
+			http.Error(w, "Invalid client ID", http.StatusBadRequest)
+			return
+		}
+		filter.ClientID = &clientID
+	}
+	if status := strings.ToUpper(query.Get("status")); status != "" {
+		switch status {
+		case types.SMSStatusPending, types.SMSStatusSent, types.SMSStatusDelivered, types.SMSStatusUndelivered, types.SMSStatusFailed:
+		default:
+			http.Error(w, "status must be PENDING, SENT, DELIVERED, UNDELIVERED or FAILED", http.StatusBadRequest)
+			return
+		}
+		filter.Status = &status
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 500 {
+			http.Error(w, "limit must be between 1 and 500", http.StatusBadRequest)
+			return
+		}
+		filter.Limit = parsed
+	}
+
+	messages, err := api.store.GetSMSMessages(mux.Vars(r)["tenantId"], filter)
+	if err != nil {
+		writeError(w, err, "Failed to fetch text messages")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(messages); err != nil {
+		logger.Errorf("Failed to encode text messages response: %v", err)
+	}
+}
+
+// receiveSMSStatus applies a text message provider's delivery status callback to its message.
+// Callbacks for unknown messages are acknowledged so the provider stops retrying them.
+func (api *API) receiveSMSStatus(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid callback", http.StatusBadRequest)
+		return
+	}
+
+	event, err := api.texter.ParseStatusCallback(r.Header, r.PostForm)
+	switch {
+	case errors.Is(err, sms.ErrDisabled):
+		http.NotFound(w, r)
+		return
+	case errors.Is(err, sms.ErrInvalidSignature):
+		logger.Warningf("Rejected SMS status callback with invalid signature from %s", r.RemoteAddr)
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	case err != nil:
+		logger.Warningf("Rejected SMS status callback: %v", err)
+		http.Error(w, "Invalid callback", http.StatusBadRequest)
+		return
+	}
+
+	if event.Status == "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if err := api.store.ApplySMSEvent(api.texter.Name(), event.ProviderID, event.Status, event.Error); err != nil {
+		if apperr.Status(err) == http.StatusNotFound {
+			logger.Warningf("Ignoring SMS status callback: %v", err)
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+		http.Error(w, "Failed to process callback", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
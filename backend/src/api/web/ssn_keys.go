@@ -0,0 +1,76 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// getSSNKeys lists a tenant's SSN data keys and latest rekeys, without the keys themselves (admin only)
+func (api *API) getSSNKeys(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	keys, err := api.store.GetTenantDataKeys(tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch SSN data keys")
+		return
+	}
+	rekeys, err := api.store.GetSSNRekeys(tenantID, 20)
+	if err != nil {
+		writeError(w, err, "Failed to fetch SSN rekeys")
+		return
+	}
+
+	response := struct {
+		Keys   []*types.TenantDataKey `json:"keys"`
+		Rekeys []*types.SSNRekey      `json:"rekeys"`
+	}{keys, rekeys}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Errorf("Failed to encode SSN data keys response: %v", err)
+	}
+}
+
+// rotateSSNKey gives a tenant a new SSN data key and queues a rekey of its SSNs (admin only).
+// New SSNs use the new key at once; the ssn_rekey worker job moves the existing ones.
+func (api *API) rotateSSNKey(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+	if _, err := api.store.GetTenantConfig(tenantID); err != nil {
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	rekey, err := api.store.RotateTenantDataKey(tenantID, employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to rotate SSN data key")
+		return
+	}
+
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	details := map[string]interface{}{
+		"keyId":   rekey.KeyID,
+		"rekeyId": rekey.ID,
+	}
+	if err := api.store.CreateAuditLog(employee.ID, tenantID, nil, types.AuditActionCreate, types.AuditResourceSSNDataKey, &rekey.KeyID, details, &ipAddress, &userAgent); err != nil {
+		logger.Errorf("Failed to audit SSN data key rotation of tenant %s: %v", tenantID, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	if err := json.NewEncoder(w).Encode(rekey); err != nil {
+		logger.Errorf("Failed to encode SSN rekey response: %v", err)
+	}
+}
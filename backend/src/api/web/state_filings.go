@@ -0,0 +1,181 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// getStateFilings returns the state returns attached to a filing
+func (api *API) getStateFilings(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	filingID := vars["filingId"]
+
+	stateFilings, err := api.store.GetStateFilings(r.Context(), tenantID, filingID)
+	if err != nil {
+		logger.Errorf("Failed to get state filings for filing %s: %v", filingID, err)
+		writeError(w, err, "Failed to fetch state filings")
+		return
+	}
+
+	if stateFilings == nil {
+		stateFilings = []*types.StateFiling{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stateFilings); err != nil {
+		logger.Errorf("Failed to encode state filings response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// createStateFiling adds a state return to a filing
+func (api *API) createStateFiling(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	filingID, err := uuid.Parse(vars["filingId"])
+	if err != nil {
+		http.Error(w, "Invalid filing ID", http.StatusBadRequest)
+		return
+	}
+
+	var input types.StateFiling
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	input.FilingID = filingID
+	input.State = strings.ToUpper(strings.TrimSpace(input.State))
+	if input.Status == "" {
+		input.Status = types.StateFilingStatusNotStarted
+	}
+
+	if len(input.State) != 2 {
+		http.Error(w, "State must be a two-letter code", http.StatusBadRequest)
+		return
+	}
+	if !types.IsValidResidencyType(input.ResidencyType) {
+		http.Error(w, "residencyType must be RESIDENT, PART_YEAR or NONRESIDENT", http.StatusBadRequest)
+		return
+	}
+	if !types.IsValidStateFilingStatus(input.Status) || input.Fee < 0 {
+		http.Error(w, "Invalid status or fee", http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Creating %s state filing for filing %s in tenant %s", input.State, filingID, tenantID)
+
+	stateFiling, err := api.store.CreateStateFiling(r.Context(), tenantID, &input)
+	if err != nil {
+		logger.Errorf("Failed to create state filing: %v", err)
+		writeError(w, err, "Failed to create state filing")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(stateFiling); err != nil {
+		logger.Errorf("Failed to encode state filing response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// updateStateFiling updates residency, status and fee of a state return
+func (api *API) updateStateFiling(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	stateFilingID := vars["stateFilingId"]
+
+	var input types.StateFiling
+	if err := json.NewDecoder(r.Body).Decode(&input); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if !types.IsValidResidencyType(input.ResidencyType) {
+		http.Error(w, "residencyType must be RESIDENT, PART_YEAR or NONRESIDENT", http.StatusBadRequest)
+		return
+	}
+	if !types.IsValidStateFilingStatus(input.Status) || input.Fee < 0 {
+		http.Error(w, "Invalid status or fee", http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Updating state filing %s in tenant %s", stateFilingID, tenantID)
+
+	stateFiling, err := api.store.UpdateStateFiling(r.Context(), tenantID, stateFilingID, &input)
+	if err != nil {
+		logger.Errorf("Failed to update state filing: %v", err)
+		writeError(w, err, "Failed to update state filing")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(stateFiling); err != nil {
+		logger.Errorf("Failed to encode state filing response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
+
+// deleteStateFiling removes a state return from a filing
+func (api *API) deleteStateFiling(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	stateFilingID := vars["stateFilingId"]
+
+	logger.Infof("Deleting state filing %s in tenant %s", stateFilingID, tenantID)
+
+	if err := api.store.DeleteStateFiling(r.Context(), tenantID, stateFilingID); err != nil {
+		logger.Errorf("Failed to delete state filing: %v", err)
+		writeError(w, err, "Failed to delete state filing")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// getStateFilingReport returns state return volume per state for a tenant (admin only)
+// Optional query: ?year=YYYY
+func (api *API) getStateFilingReport(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	var year *int
+	if yearStr := r.URL.Query().Get("year"); yearStr != "" {
+		parsed, err := strconv.Atoi(yearStr)
+		if err != nil {
+			http.Error(w, "Invalid year", http.StatusBadRequest)
+			return
+		}
+		year = &parsed
+	}
+
+	report, err := api.store.GetStateFilingReport(r.Context(), tenantID, year)
+	if err != nil {
+		logger.Errorf("Failed to get state filing report for tenant %s: %v", tenantID, err)
+		writeError(w, err, "Failed to fetch state filing report")
+		return
+	}
+
+	if report == nil {
+		report = []*types.StateFilingReport{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.Errorf("Failed to encode state filing report response: %v", err)
+		http.Error(w, "Failed to encode response", http.StatusInternalServerError)
+		return
+	}
+}
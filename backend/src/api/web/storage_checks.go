@@ -0,0 +1,105 @@
+package webapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// runStorageCheck compares a tenant's document records with its bucket now and returns the
+// report (admin only). Large buckets may outlast the request; the nightly job checks them too.
+func (api *API) runStorageCheck(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	logger.Infof("Running storage check for tenant %s", tenantID)
+
+	if _, err := api.storageChecker.Check(r.Context(), tenantID); err != nil {
+		logger.Errorf("Failed to run storage check for tenant %s: %v", tenantID, err)
+		writeError(w, err, "Failed to run storage check")
+		return
+	}
+	api.writeStorageReport(w, tenantID)
+}
+
+// getStorageCheck returns the latest storage check of a tenant with its open issues (admin only)
+func (api *API) getStorageCheck(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+	api.writeStorageReport(w, tenantID)
+}
+
+// writeStorageReport writes a tenant's latest storage check with its open issues
+func (api *API) writeStorageReport(w http.ResponseWriter, tenantID string) {
+	report, err := api.store.GetStorageConsistencyReport(tenantID)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNotFound) {
+			http.Error(w, "Storage has not been checked yet", http.StatusNotFound)
+			return
+		}
+		writeError(w, err, "Failed to get storage check")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.Errorf("Failed to encode storage check response: %v", err)
+	}
+}
+
+// getStorageChecks returns the latest storage check of every tenant, tenants with issues first
+// (admin only)
+func (api *API) getStorageChecks(w http.ResponseWriter, r *http.Request) {
+	checks, err := api.store.GetStorageConsistencyChecks()
+	if err != nil {
+		writeError(w, err, "Failed to get storage checks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(checks); err != nil {
+		logger.Errorf("Failed to encode storage checks response: %v", err)
+	}
+}
+
+// resolveStorageIssue remediates an open storage issue (admin only)
+// Body: {"action": "RELINK"|"DELETE"|"REREQUEST", "path": "...", "documentId": "...", "description": "..."}
+func (api *API) resolveStorageIssue(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	issueID, err := uuid.Parse(vars["issueId"])
+	if err != nil {
+		http.Error(w, "Invalid issue ID", http.StatusBadRequest)
+		return
+	}
+
+	var remedy types.StorageRemediation
+	if err := json.NewDecoder(r.Body).Decode(&remedy); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Resolving storage issue %s of tenant %s with %s", issueID, tenantID, remedy.Action)
+
+	issue, err := api.storageChecker.Resolve(r.Context(), tenantID, issueID, &remedy, employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to resolve storage issue")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(issue); err != nil {
+		logger.Errorf("Failed to encode storage issue response: %v", err)
+	}
+}
@@ -0,0 +1,459 @@
+package webapi
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"welltaxpro/src/internal/bulk"
+	"welltaxpro/src/internal/consistency"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/notification"
+	"welltaxpro/src/internal/offboarding"
+	"welltaxpro/src/internal/store"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// Store is the part of the store the API uses, including what its middleware, offboarder and
+// storage checker need; *store.Store implements it and tests serve the API from fakes
+type Store interface {
+	middleware.AuthStore
+	middleware.TenantUserAuthStore
+	middleware.AuditStore
+	middleware.SignatureStore
+	middleware.LegalStore
+	middleware.ArchiveStore
+	middleware.DebugCaptureStore
+	notification.PushStore
+	offboarding.Store
+	consistency.Store
+	bulk.RoleStore
+
+	// Access matrix
+	GetAccessMatrix(filter types.AccessMatrixFilter) (*types.AccessMatrix, error)
+
+	// Access review
+	CompleteAccessReview(reviewID uuid.UUID) error
+	CreateAccessReview(name string, createdBy uuid.UUID) (*types.AccessReview, error)
+	DecideAccessReviewItem(reviewID, itemID uuid.UUID, decision string, reviewerID uuid.UUID, comment *string) error
+	GetAccessReview(reviewID uuid.UUID) (*types.AccessReview, error)
+	GetAccessReviews() ([]*types.AccessReview, error)
+
+	// Address
+	GetLatestAddressValidation(tenantID, entityType string, entityID uuid.UUID) (*types.AddressValidation, error)
+	GetUndeliverableAddresses(tenantID string) ([]*types.AddressValidation, error)
+	SaveAddressValidation(v *types.AddressValidation) error
+
+	// Affiliate
+	ApproveCommission(ctx context.Context, tenantID string, commissionID string) (*types.Commission, error)
+	ApproveCommissions(ctx context.Context, tenantID string, commissionIDs []string, filter *types.CommissionFilter) (*types.BulkCommissionReport, error)
+	CancelCommission(ctx context.Context, tenantID string, commissionID string, reason string, cancelledBy uuid.UUID) (*types.Commission, error)
+	CreateAffiliate(ctx context.Context, tenantID string, affiliate *types.Affiliate) (*types.Affiliate, error)
+	GenerateAffiliateToken(ctx context.Context, tenantID string, affiliateID uuid.UUID, expiresAt *time.Time, notes *string) (string, *types.AffiliateToken, error)
+	GetAffiliateByID(ctx context.Context, tenantID string, affiliateID string) (*types.Affiliate, error)
+	GetAffiliateStats(ctx context.Context, tenantID string, affiliateID string) (*types.AffiliateStats, error)
+	GetAffiliateTokens(ctx context.Context, tenantID string, affiliateID uuid.UUID, activeOnly bool) ([]*types.AffiliateToken, error)
+	GetAffiliates(ctx context.Context, tenantID string, activeOnly bool) ([]*types.Affiliate, error)
+	GetCommission(ctx context.Context, tenantID string, commissionID string) (*types.Commission, error)
+	GetCommissionPage(ctx context.Context, tenantID string, affiliateID *string, status *string, tag *string, page types.PageRequest) (*types.CommissionPage, error)
+	GetCommissionsByAffiliate(ctx context.Context, tenantID string, affiliateID *string, status *string, tag *string, limit int) ([]*types.Commission, error)
+	PatchAffiliate(ctx context.Context, tenantID string, affiliateID string, patch *types.AffiliatePatch) (*types.Affiliate, error)
+	RecordAffiliateClick(ctx context.Context, tenantID string, click *types.AffiliateClick) error
+	RevokeAffiliateToken(ctx context.Context, tenantID string, tokenID uuid.UUID) error
+	UpdateAffiliate(ctx context.Context, tenantID string, affiliateID string, affiliate *types.Affiliate) (*types.Affiliate, error)
+	ValidateAffiliateToken(ctx context.Context, tenantID string, plainToken string) (uuid.UUID, error)
+
+	// Affiliate notification
+	GetAffiliateNotificationPreferences(tenantID string, affiliateID uuid.UUID) ([]*types.AffiliateNotificationPreference, error)
+	GetAffiliateNotifications(tenantID string, affiliateID uuid.UUID, limit int) ([]*types.AffiliateNotification, int, error)
+	MarkAffiliateNotificationsRead(tenantID string, affiliateID uuid.UUID) (int64, error)
+	SetAffiliateNotificationPreferences(tenantID string, affiliateID uuid.UUID, prefs []*types.AffiliateNotificationPreference) error
+
+	// Affiliate payout
+	CancelPayout(ctx context.Context, tenantID string, payoutID string) (*types.AffiliatePayout, error)
+	CreatePayoutBatch(ctx context.Context, tenantID string, createdBy string) (*types.PayoutBatch, error)
+	GetAffiliatePaymentTotals(ctx context.Context, tenantID string, year int) ([]*types.AffiliatePaymentTotal, error)
+	GetAffiliatePayments(ctx context.Context, tenantID string, affiliateID string, limit int) ([]*types.AffiliatePayoutPayment, error)
+	GetAffiliateStatement(ctx context.Context, tenantID string, affiliateID string, year int) (*types.AffiliateStatement, error)
+	GetPayoutBatch(ctx context.Context, tenantID string, batchID string) (*types.PayoutBatch, error)
+	GetPayoutBatches(ctx context.Context, tenantID string) ([]*types.PayoutBatch, error)
+	MarkCommissionPaid(ctx context.Context, tenantID string, commissionID string, payment *types.PayoutPayment) (*types.Commission, error)
+	MarkPayoutPaid(ctx context.Context, tenantID string, payoutID string, payment *types.PayoutPayment) (*types.AffiliatePayout, error)
+	RecordPayoutFailure(ctx context.Context, tenantID string, payoutID string, message string, declined bool) error
+	StartPayoutTransfer(ctx context.Context, tenantID string, payoutID string) (*types.AffiliatePayout, error)
+
+	// Affiliate token policy
+	GetAffiliateTokenPolicy(tenantID string) (*types.AffiliateTokenPolicy, error)
+	UpdateAffiliateTokenPolicy(tenantID string, policy *types.AffiliateTokenPolicy, employeeID uuid.UUID) error
+
+	// Announcement
+	AcknowledgeAnnouncement(announcementID uuid.UUID, employee *types.Employee) (*types.AnnouncementAcknowledgment, error)
+	CreateAnnouncement(a *types.Announcement) (*types.Announcement, error)
+	DeleteAnnouncement(announcementID uuid.UUID) error
+	GetActiveAnnouncements(employee *types.Employee) ([]*types.Announcement, error)
+	GetAnnouncementAcknowledgments(announcementID uuid.UUID) ([]*types.AnnouncementAcknowledgment, error)
+	GetAnnouncements(includeEnded bool) ([]*types.Announcement, error)
+	UpdateAnnouncement(announcementID uuid.UUID, a *types.Announcement) (*types.Announcement, error)
+
+	// Break glass
+	CreateBreakGlassGrant(employeeID uuid.UUID, tenantID, reason string, duration time.Duration) (*types.BreakGlassGrant, error)
+	GetBreakGlassGrants(activeOnly bool) ([]*types.BreakGlassGrant, error)
+	RevokeBreakGlassGrant(grantID uuid.UUID, revokedBy *uuid.UUID) (*types.BreakGlassGrant, error)
+
+	// Bulk operation
+	CreateBulkOperation(op *types.BulkOperation) (*types.BulkOperation, error)
+	GetBulkOperation(tenantID string, id uuid.UUID) (*types.BulkOperation, error)
+	GetBulkOperations(tenantID string, limit int) ([]*types.BulkOperation, error)
+
+	// Campaign
+	CreateCampaign(c *types.Campaign) error
+	GetCampaignReport(ctx context.Context, tenantID string, activeOnly bool) (*types.CampaignReport, error)
+	GetCampaigns(tenantID string, activeOnly bool) ([]*types.Campaign, error)
+	UpdateCampaign(c *types.Campaign) error
+
+	// Checkout
+	GetStripeWebhookSecret(tenantID string) (string, error)
+	RecordCheckoutSession(ctx context.Context, tenantID string, session *types.CheckoutSession) (*types.CheckoutPayment, *types.Commission, error)
+	UpdateStripeWebhookSecret(tenantID, secret string, employeeID uuid.UUID) error
+
+	// Client
+	ArchiveClient(ctx context.Context, tenantID string, clientID string, reason string) (*types.Client, error)
+	FindClientIDByEmail(ctx context.Context, tenantID string, email string) (uuid.UUID, error)
+	GetClientByID(ctx context.Context, tenantID string, clientID string) (*types.Client, error)
+	GetClientComprehensive(ctx context.Context, tenantID string, clientID string) (*types.ClientComprehensive, error)
+	GetClientPage(ctx context.Context, tenantID string, includeArchived bool, page types.PageRequest) (*types.ClientPage, error)
+	GetClients(ctx context.Context, tenantID string, includeArchived bool) ([]*types.Client, error)
+	GetClientsByFilings(ctx context.Context, tenantID string, limit int, offset int) ([]*types.ClientComprehensive, error)
+	GetClientsByFilingsPage(ctx context.Context, tenantID string, page types.PageRequest) (*types.FilingPage, error)
+	IsClientArchived(ctx context.Context, tenantID string, clientID string) (bool, error)
+	MarkClientDeceased(ctx context.Context, tenantID string, clientID string, person string, deathDate string) error
+	SearchClients(ctx context.Context, tenantID string, query *types.ClientSearchQuery) ([]*types.ClientSearchResult, error)
+	StreamClients(ctx context.Context, tenantID string, includeArchived bool, fn func(*types.Client) error) error
+	StreamClientsByFilings(ctx context.Context, tenantID string, fn func(*types.ClientComprehensive) error) error
+	UnarchiveClient(ctx context.Context, tenantID string, clientID string) (*types.Client, error)
+
+	// Commission note
+	AddCommissionNote(note *types.CommissionNote) error
+	AddCommissionTag(tenantID string, commissionID uuid.UUID, tag string, createdBy uuid.UUID) error
+	AttachCommissionTags(tenantID string, commissions []*types.Commission) error
+	GetCommissionNotes(tenantID string, commissionID uuid.UUID) ([]*types.CommissionNote, error)
+	RemoveCommissionTag(tenantID string, commissionID uuid.UUID, tag string) error
+
+	// Commission sla
+	GetCommissionSLA(tenantID string) (*types.CommissionSLA, error)
+	GetOverdueCommissions(ctx context.Context, tenantID string, limit int) (*types.OverdueCommissionReport, error)
+	UpdateCommissionSLA(tenantID string, sla *types.CommissionSLA, employeeID uuid.UUID) error
+
+	// Consent
+	CreateConsentTemplate(t *types.ConsentTemplate) error
+	GetClientConsents(tenantID string, clientID uuid.UUID) ([]*types.ClientConsent, error)
+	GetConsentTemplates(tenantID string, activeOnly bool) ([]*types.ConsentTemplate, error)
+	GetConsentingClients(tenantID, purpose string, clientIDs []uuid.UUID) (map[uuid.UUID]bool, error)
+	GrantConsent(tenantID string, clientID, templateID uuid.UUID, tenantUserID *uuid.UUID, ipAddress, userAgent *string) (*types.ClientConsent, error)
+	RevokeConsent(tenantID string, clientID uuid.UUID, purpose string, ipAddress *string) error
+
+	// Debug capture
+	DisableDebugCapture(tenantID string, employeeID uuid.UUID) (*types.DebugCapture, error)
+	EnableDebugCapture(tenantID, reason string, duration time.Duration, employeeID uuid.UUID) (*types.DebugCapture, error)
+	GetDebugCaptureEntries(tenantID string, limit int) ([]*types.DebugCaptureEntry, error)
+
+	// Discount
+	CreateDiscountCode(ctx context.Context, tenantID string, discountCode *types.DiscountCode) (*types.DiscountCode, error)
+	DeactivateDiscountCode(ctx context.Context, tenantID string, codeID string) error
+	GenerateDiscountCodes(ctx context.Context, tenantID string, template *types.DiscountCode, prefix string, suffixLength int, count int) ([]*types.DiscountCode, error)
+	GetDiscountCampaignReports(ctx context.Context, tenantID string, campaign *string) ([]*types.DiscountCampaignReport, error)
+	GetDiscountCodeByCode(ctx context.Context, tenantID string, code string) (*types.DiscountCode, error)
+	GetDiscountCodeByID(ctx context.Context, tenantID string, codeID string) (*types.DiscountCode, error)
+	GetDiscountCodePage(ctx context.Context, tenantID string, affiliateID *string, campaign *string, activeOnly bool, page types.PageRequest) (*types.DiscountCodePage, error)
+	GetDiscountCodes(ctx context.Context, tenantID string, affiliateID *string, campaign *string, activeOnly bool) ([]*types.DiscountCode, error)
+	PatchDiscountCode(ctx context.Context, tenantID string, codeID string, patch *types.DiscountCodePatch) (*types.DiscountCode, error)
+	UpdateDiscountCode(ctx context.Context, tenantID string, codeID string, discountCode *types.DiscountCode) (*types.DiscountCode, error)
+
+	// Document
+	CreateDocument(ctx context.Context, tenantID string, document *types.Document) (*types.Document, error)
+	GetDocumentsByFilingID(ctx context.Context, tenantID string, filingID string) ([]*types.Document, error)
+	GetFilingClientID(ctx context.Context, tenantID string, filingID uuid.UUID) (uuid.UUID, error)
+
+	// Document drop
+	CreateDocumentDrop(d *types.DocumentDrop) error
+	DeactivateDocumentDrop(tenantID string, dropID uuid.UUID) error
+	GetDocumentDrop(tenantID string, dropID uuid.UUID) (*types.DocumentDrop, error)
+	GetDocumentDropFile(tenantID string, fileID uuid.UUID) (*types.DocumentDropFile, error)
+	GetDocumentDropFiles(tenantID, status string, limit int) ([]*types.DocumentDropFile, error)
+	GetDocumentDrops(tenantID string) ([]*types.DocumentDrop, error)
+	ResolveDocumentDropFile(f *types.DocumentDropFile, employeeID uuid.UUID) error
+
+	// Document request
+	CloseDocumentRequest(tenantID string, id uuid.UUID, status string, documentID *uuid.UUID, closedBy uuid.UUID) (*types.DocumentRequest, error)
+	CreateDocumentRequest(request *types.DocumentRequest) (*types.DocumentRequest, error)
+	GetClientDocumentRequests(tenantID string, clientID uuid.UUID, openOnly bool) ([]*types.DocumentRequest, error)
+	GetExpiringDocuments(tenantID string, days int) ([]*types.DocumentExpiration, error)
+	SetDocumentExpiry(tenantID string, document *types.Document, expiresOn *string, setBy uuid.UUID) (*types.DocumentExpiration, error)
+
+	// Document scan
+	CheckDocumentScan(tenantID string, documentID string) error
+	GetDocumentScan(tenantID string, documentID string) (*types.DocumentScan, error)
+	RecordDocumentScan(tenantID string, documentID uuid.UUID, scan *types.DocumentScan) (*types.DocumentScan, error)
+
+	// Email outbox
+	AddEmailSuppression(suppression *types.EmailSuppression) (*types.EmailSuppression, error)
+	DeleteEmailSuppression(email string) error
+	GetEmailSuppressions() ([]*types.EmailSuppression, error)
+	GetOutboxEmails(filter *types.OutboxEmailFilter) ([]*types.OutboxEmail, error)
+	MarkEmailBounced(emailID uuid.UUID, detail string) error
+	QueueEmail(email *types.OutboxEmail) (*types.OutboxEmail, error)
+
+	// Employee
+	CreateEmployee(firebaseUID, email string, firstName, lastName *string, role string) (*types.Employee, error)
+	DeactivateEmployee(employeeID uuid.UUID) error
+	GetAllEmployees(includeInactive bool) ([]*types.Employee, error)
+	GetEmployeeByID(employeeID uuid.UUID) (*types.Employee, error)
+	UpdateEmployee(employeeID uuid.UUID, firstName, lastName *string, role string, expectedUpdatedAt time.Time) (*types.Employee, error)
+
+	// Employee notification
+	GetEmployeeNotifications(employeeID uuid.UUID, unreadOnly bool, limit int) ([]*types.EmployeeNotification, int, error)
+	GetNotificationChannels(employeeID uuid.UUID) (*types.NotificationChannels, error)
+	MarkEmployeeNotificationsRead(employeeID uuid.UUID, ids []uuid.UUID) (int64, error)
+	SetNotificationChannels(employeeID uuid.UUID, channels *types.NotificationChannels) error
+
+	// Employee session
+	CreateEmployeeSession(session *types.EmployeeSession, firebaseRefreshToken, tokenHash string) error
+	GetEmployeeSessions(employeeID *uuid.UUID, activeOnly bool) ([]*types.EmployeeSession, error)
+	RevokeEmployeeSession(sessionID uuid.UUID, revokedBy *uuid.UUID, reason string) (*types.EmployeeSession, error)
+	RevokeEmployeeSessionByToken(tokenHash, reason string) (*types.EmployeeSession, error)
+	RevokeEmployeeSessions(employeeID uuid.UUID, revokedBy *uuid.UUID, reason string) ([]*types.EmployeeSession, error)
+	RotateEmployeeSession(tokenHash, newTokenHash string, refresh func(firebaseRefreshToken string) (string, error)) (*types.EmployeeSession, error)
+
+	// Employee tenant
+	AssignEmployeeToTenant(employeeID uuid.UUID, tenantID, role string, assignedBy uuid.UUID) (*types.EmployeeTenantAssociation, bool, error)
+	GetEmployeeTenantAccess(employeeID uuid.UUID) ([]*types.TenantAccess, error)
+	GetEmployeeTenantIDs(employeeID uuid.UUID) ([]string, error)
+	GetTenantEmployees(tenantID string) ([]*types.TenantEmployee, error)
+	RemoveEmployeeFromTenant(employeeID uuid.UUID, tenantID string) error
+
+	// Filing assignment
+	AssignFiling(tenantID string, filingID, employeeID uuid.UUID, assignedBy *uuid.UUID) (*types.FilingAssignment, error)
+	GetFilingAssignment(tenantID string, filingID uuid.UUID) (*types.FilingAssignment, error)
+	GetFilingAssignments(tenantID string, employeeID *uuid.UUID) ([]*types.FilingAssignment, error)
+
+	// Filing checklist
+	ApplyFilingChecklists(tenantID string, client *types.ClientComprehensive) error
+	GetFilingChecklist(ctx context.Context, tenantID string, filingID uuid.UUID) (*types.FilingChecklist, error)
+
+	// Filing completeness
+	ApplyFilingCompleteness(tenantID string, clients []*types.ClientComprehensive) error
+
+	// Filing result
+	GetFilingResult(ctx context.Context, tenantID string, filingID string) (*types.FilingResult, error)
+	UpsertFilingResult(ctx context.Context, tenantID string, result *types.FilingResult) (*types.FilingResult, error)
+
+	// Filing status texts
+	GetFilingStatusTexts(tenantID string) (types.FilingStatusTexts, error)
+	UpdateFilingStatusTexts(tenantID string, texts types.FilingStatusTexts, employeeID uuid.UUID) error
+
+	// Filing workflow
+	ChangeFilingStatus(ctx context.Context, tenantID string, filingID string, update *types.FilingStatusUpdate, employee *types.Employee) (*types.FilingStatusChange, error)
+	GetFilingContact(ctx context.Context, tenantID string, filingID string) (*types.FilingContact, error)
+	GetFilingStatusHistory(tenantID string, filingID string) ([]*types.FilingStatusChange, error)
+	GetFilingWorkflow(ctx context.Context, tenantID string, filingID string) (*types.FilingWorkflow, error)
+	MarkFilingCompleted(ctx context.Context, tenantID string, filingID string) error
+
+	// Firebase users
+	GetFirebaseOrphans() ([]*types.FirebaseOrphanUser, error)
+
+	// Form template
+	DeleteFormTemplate(tenantID, kind string) error
+	GetFormTemplate(tenantID, kind string) (*types.FormTemplate, error)
+	GetFormTemplates(tenantID string) ([]*types.FormTemplate, error)
+	UpsertFormTemplate(t *types.FormTemplate) error
+
+	// Fraud
+	CreateCommission(ctx context.Context, tenantID string, commission *types.Commission, ipAddress string) (*types.Commission, error)
+	GetFraudRules(tenantID string) (*types.FraudRules, error)
+	UpdateFraudRules(tenantID string, rules *types.FraudRules, employeeID uuid.UUID) error
+
+	// Identity document
+	CreateIdentityDocument(d *types.IdentityDocument) error
+	GetClientIdentityDocuments(tenantID string, clientID uuid.UUID) ([]*types.IdentityDocument, error)
+	GetIdentityDocument(tenantID string, id uuid.UUID) (*types.IdentityDocument, error)
+	GetIdentityDocuments(tenantID string, status *string) ([]*types.IdentityDocument, error)
+	GetTenantUserIdentityDocuments(tenantUserID uuid.UUID) ([]*types.IdentityDocument, error)
+	ReviewIdentityDocument(tenantID string, id uuid.UUID, status string, note *string, reviewedBy uuid.UUID) (*types.IdentityDocument, error)
+
+	// Inbound email
+	CreateInboundEmail(e *types.InboundEmail) error
+	GetInboundAddress(tenantID string) (*types.InboundAddress, error)
+	GetInboundAttachment(tenantID string, attachmentID uuid.UUID) (*types.InboundAttachment, error)
+	GetInboundAttachments(tenantID, status string, limit int) ([]*types.InboundAttachment, error)
+	GetTenantByInboundLocalPart(localPart string) (string, error)
+	MarkInboundEmailAcknowledged(emailID uuid.UUID) error
+	ReviewInboundAttachment(a *types.InboundAttachment, employeeID uuid.UUID) error
+	SetInboundAddress(tenantID, localPart string, createdBy uuid.UUID) (*types.InboundAddress, error)
+
+	// Integrity
+	RunIntegrityChecks(ctx context.Context, tenantID string) ([]*types.IntegrityIssue, error)
+
+	// Jobs
+	GetLastJobRun(jobName string) (*time.Time, error)
+
+	// Legal document
+	AcceptLegalDocument(tenantID string, tenantUserID, documentID uuid.UUID, ipAddress, userAgent *string) (*types.LegalAcceptance, error)
+	CreateLegalDocument(d *types.LegalDocument) error
+	GetLegalAcceptances(tenantID string, tenantUserID uuid.UUID) ([]*types.LegalAcceptance, error)
+	GetLegalDocuments(tenantID string, activeOnly bool) ([]*types.LegalDocument, error)
+
+	// Locks
+	GetDistributedLocks(stuckAfter time.Duration) ([]*types.DistributedLock, error)
+
+	// Mailing
+	AdvanceMailPiece(p *types.MailPiece, status string) (bool, error)
+	CreateMailing(m *types.Mailing) error
+	GetMailCosts(tenantID string, from, to time.Time) ([]*types.MailCostSummary, error)
+	GetMailPieceByProviderID(provider, providerID string) (*types.MailPiece, error)
+	GetMailSettings(tenantID string) (*types.MailSettings, error)
+	GetMailings(tenantID string, clientID *uuid.UUID, limit int) ([]*types.Mailing, error)
+	RecordMailPieceSent(p *types.MailPiece) error
+	SetMailSettings(m *types.MailSettings) error
+
+	// Notification
+	GetNotificationPreferences(employeeID uuid.UUID) ([]*types.NotificationPreference, error)
+	SetNotificationPreferences(employeeID uuid.UUID, prefs []*types.NotificationPreference) error
+
+	// Operations
+	CheckTenantConnection(ctx context.Context, tenantID string) *types.TenantConnectionHealth
+	CountFilings(ctx context.Context, tenantID string, year int) (total int, completed int, err error)
+	GetConnectionHealth(tenantID string) (*types.TenantConnectionHealth, bool)
+	GetEnvelopeSummary(since time.Time, failureLimit int) (*types.OverviewEnvelopes, error)
+	GetJobBacklog() (*types.JobBacklog, error)
+	GetJobSummaries(since time.Time) ([]*types.JobSummary, error)
+	GetTenantCounts() (*types.OverviewTenants, error)
+	RecordSignatureEnvelope(envelope *types.SignatureEnvelope) error
+
+	// Portal session
+	CreatePortalAuditLog(tenantUserID uuid.UUID, tenantID string, clientID *uuid.UUID, action, resourceType string, resourceID *uuid.UUID, details interface{}, ipAddress, userAgent *string) error
+	GetPortalSessionOTP(sessionID uuid.UUID) (string, error)
+	GetPortalVerificationFailures(tenantID string, clientID uuid.UUID, limit int) ([]*types.PortalVerificationFailure, error)
+	GetPortalVerificationLock(tenantUserID uuid.UUID) (*time.Time, error)
+	RecordPortalVerification(sessionID uuid.UUID, method string, verified bool) (*types.PortalSession, error)
+	RecordPortalVerificationFailure(failure *types.PortalVerificationFailure) error
+	ResetPortalVerificationFailures(tenantUserID uuid.UUID) error
+	SetPortalSessionOTP(sessionID uuid.UUID, codeHash string, expiresAt time.Time) error
+	UpdatePortalSecurityPolicy(tenantID string, policy *types.PortalSecurityPolicy, employeeID uuid.UUID) error
+
+	// Push
+	DeleteTenantUserDevice(tenantUserID, deviceID uuid.UUID) error
+	GetTenantUserDevices(tenantUserID uuid.UUID) ([]*types.TenantUserDevice, error)
+	GetTenantUserPushEnabled(tenantUserID uuid.UUID) (bool, error)
+	RegisterTenantUserDevice(device *types.TenantUserDevice) error
+	SetTenantUserPushEnabled(tenantUserID uuid.UUID, enabled bool) error
+
+	// Refund tracking
+	DeleteRefundTracking(ctx context.Context, tenantID string, filingID string, jurisdiction string) error
+	GetRefundTrackings(ctx context.Context, tenantID string, filingID string) ([]*types.RefundTracking, error)
+	UpsertRefundTracking(ctx context.Context, tenantID string, tracking *types.RefundTracking) (*types.RefundTracking, error)
+
+	// Request signing
+	CreateSigningKey(tenantID, label string, createdBy uuid.UUID) (*types.SigningKey, error)
+	GetSigningKeys(tenantID string) ([]*types.SigningKey, error)
+	RevokeSigningKey(tenantID, keyID string) error
+
+	// Schema check
+	CompareTenantSchema(ctx context.Context, tenantID string) ([]*types.SchemaIssue, error)
+	GetSchemaCheck(tenantID string) (*types.SchemaCheck, error)
+	GetSchemaChecks() ([]*types.SchemaCheck, error)
+	RunSchemaCheck(ctx context.Context, tenantID string) (*types.SchemaCheck, error)
+	RunSchemaChecks(ctx context.Context) ([]*types.SchemaCheck, error)
+
+	// Signature request
+	AdvanceSignatureRequest(req *types.SignatureRequest, status string) (bool, error)
+	CreateSignatureRequest(req *types.SignatureRequest) error
+	GetDocuSignConnectSecret(tenantID string) (string, error)
+	GetSignatureRequest(tenantID string, id uuid.UUID) (*types.SignatureRequest, error)
+	GetSignatureRequestByEnvelope(tenantID, envelopeID string) (*types.SignatureRequest, error)
+	GetSignatureRequests(tenantID string, statuses []string, limit int) ([]*types.SignatureRequest, error)
+	RecordSignatureArchive(req *types.SignatureRequest, documentID *uuid.UUID, archiveErr error) error
+	UpdateDocuSignConnectSecret(tenantID, secret string, employeeID uuid.UUID) error
+
+	// SMS
+	ApplySMSEvent(provider, providerID, status, errorCode string) error
+	GetSMSMessages(tenantID string, filter *types.SMSMessageFilter) ([]*types.SMSMessage, error)
+	GetSMSSettings(tenantID string) (*types.SMSSettings, error)
+	UpdateSMSSettings(tenantID string, settings *types.SMSSettings, employeeID uuid.UUID) error
+
+	// State filing
+	CreateStateFiling(ctx context.Context, tenantID string, stateFiling *types.StateFiling) (*types.StateFiling, error)
+	DeleteStateFiling(ctx context.Context, tenantID string, stateFilingID string) error
+	GetStateFilingReport(ctx context.Context, tenantID string, year *int) ([]*types.StateFilingReport, error)
+	GetStateFilings(ctx context.Context, tenantID string, filingID string) ([]*types.StateFiling, error)
+	UpdateStateFiling(ctx context.Context, tenantID string, stateFilingID string, stateFiling *types.StateFiling) (*types.StateFiling, error)
+
+	// Storage consistency
+	GetStorageConsistencyChecks() ([]*types.StorageConsistencyCheck, error)
+	GetStorageConsistencyReport(tenantID string) (*types.StorageConsistencyReport, error)
+
+	// Connections
+	GetTenantPoolStats() []*types.TenantPoolStats
+	Ping(ctx context.Context) error
+	TenantPoolIdleTimeout() time.Duration
+
+	// Tenant
+	GetTenantConnections() ([]types.TenantConnection, error)
+	GetTenantDB(ctx context.Context, tenantID string) (*sql.DB, *types.TenantConnection, error)
+	GetTenantSQLDB(ctx context.Context, tenantID string) (*sql.DB, *types.TenantConnection, error)
+
+	// Tenant archive
+	ArchiveYear(tenantID string, year int, employeeID uuid.UUID) (*types.ArchivedYear, error)
+	GetArchivedYears(tenantID string) ([]*types.ArchivedYear, error)
+	UnarchiveYear(tenantID string, year int, employeeID uuid.UUID) error
+
+	// Tenant context
+	CreateTenantContext(employee *types.Employee, tenantID string, authTime int64, tokenHash string) (*types.TenantContext, error)
+	RevokeTenantContext(employeeID uuid.UUID, tokenHash string) error
+
+	// Tenant data keys
+	GetSSNRekeys(tenantID string, limit int) ([]*types.SSNRekey, error)
+	GetTenantDataKeys(tenantID string) ([]*types.TenantDataKey, error)
+	RotateTenantDataKey(tenantID string, requestedBy uuid.UUID) (*types.SSNRekey, error)
+
+	// Tenant deletion
+	CountTenantDeletionImpact(ctx context.Context, tenantID string) (*types.TenantDeletionImpact, error)
+	CreateTenantDeletionRequest(tenantID string, impact *types.TenantDeletionImpact, requestedBy uuid.UUID) (*types.TenantDeletionRequest, error)
+	DeactivateTenant(tenantID, token string, employeeID uuid.UUID) (*types.TenantDeactivation, error)
+
+	// Tenant offboarding
+	CancelTenantOffboarding(tenantID string, performedBy uuid.UUID) (*types.TenantOffboarding, error)
+	GetTenantOffboardingReport(tenantID string) (*types.TenantOffboardingReport, error)
+	StartTenantOffboarding(tenantID string, reason *string, startedBy uuid.UUID) (*types.TenantOffboarding, error)
+
+	// Tenant probe
+	GetTenantUptime(tenantID string, recent int) (*types.TenantUptime, error)
+
+	// Tenant status
+	CreateMaintenanceWindow(m *types.MaintenanceWindow) error
+	DeleteMaintenanceWindow(tenantID string, windowID string) error
+	GetMaintenanceWindows(tenantID string, upcomingOnly bool) ([]*types.MaintenanceWindow, error)
+	GetTenantStatus(ctx context.Context, tenantID string) (*types.TenantStatus, error)
+
+	// Tenant user
+	CreateTenantUser(tu *types.TenantUser) error
+	DeactivateTenantUser(id uuid.UUID) error
+	GetPortalDocumentViews(tenantID string, clientID uuid.UUID, limit int) ([]*types.PortalDocumentView, error)
+	GetTenantUser(id uuid.UUID) (*types.TenantUser, error)
+	GetTenantUserLastSeen(id uuid.UUID) (*time.Time, error)
+	GetTenantUsersByTenant(tenantID string, filter *types.TenantUserFilter) ([]*types.TenantUser, error)
+	MarkTenantUserSeen(id uuid.UUID) error
+	RecordPortalDocumentView(view *types.PortalDocumentView) error
+
+	// Webhook
+	CreateWebhook(hook *types.Webhook) (*types.Webhook, error)
+	DeleteWebhook(tenantID string, webhookID uuid.UUID) error
+	EnqueueWebhookEvent(tenantID string, event string, data interface{}) error
+	GetWebhook(tenantID string, webhookID uuid.UUID) (*types.Webhook, error)
+	GetWebhookDeliveries(tenantID string, webhookID uuid.UUID, status *string, limit int) ([]*types.WebhookDelivery, error)
+	GetWebhooks(tenantID string) ([]*types.Webhook, error)
+	RetryWebhookDelivery(tenantID string, webhookID uuid.UUID, deliveryID uuid.UUID) (*types.WebhookDelivery, error)
+	UpdateWebhook(hook *types.Webhook) (*types.Webhook, error)
+}
+
+var _ Store = (*store.Store)(nil)
@@ -0,0 +1,96 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/google/logger"
+)
+
+// Formats accepted by ?stream= on listings that can be streamed
+const (
+	streamFormatNDJSON = "ndjson" // One JSON record per line
+	streamFormatJSON   = "json"   // One JSON array sent in chunks
+)
+
+// streamFlushEvery is how many records are written between flushes to the client
+const streamFlushEvery = 100
+
+// streamWriter writes records to the client as they are produced instead of encoding a whole slice
+type streamWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	format  string
+	count   int
+}
+
+// newStreamWriter prepares a streamed response, returning false for an unknown format
+func newStreamWriter(w http.ResponseWriter, format string) (*streamWriter, bool) {
+	switch format {
+	case streamFormatNDJSON:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+	case streamFormatJSON:
+		w.Header().Set("Content-Type", "application/json")
+	default:
+		return nil, false
+	}
+	flusher, _ := w.(http.Flusher)
+	return &streamWriter{w: w, flusher: flusher, format: format}, true
+}
+
+// write sends one record
+func (s *streamWriter) write(record interface{}) error {
+	raw, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	if s.format == streamFormatJSON {
+		separator := ","
+		if s.count == 0 {
+			separator = "["
+		}
+		raw = append([]byte(separator), raw...)
+	}
+	if _, err := s.w.Write(append(raw, '\n')); err != nil {
+		return err
+	}
+
+	s.count++
+	if s.count%streamFlushEvery == 0 {
+		s.flush()
+	}
+	return nil
+}
+
+// finish completes the stream. A failure before the first record is reported as an ordinary
+// error response; once records have been sent the connection is aborted instead, so the client
+// sees a truncated response rather than a complete one.
+func (s *streamWriter) finish(err error, message string) {
+	if err != nil {
+		if s.count == 0 {
+			writeError(s.w, err, message)
+			return
+		}
+		logger.Errorf("Aborting stream after %d records: %v", s.count, err)
+		panic(http.ErrAbortHandler)
+	}
+
+	if s.format == streamFormatJSON {
+		closing := "]\n"
+		if s.count == 0 {
+			closing = "[]\n"
+		}
+		if _, err := s.w.Write([]byte(closing)); err != nil {
+			logger.Errorf("Failed to finish stream: %v", err)
+			return
+		}
+	}
+	s.flush()
+}
+
+func (s *streamWriter) flush() {
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+}
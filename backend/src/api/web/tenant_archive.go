@@ -0,0 +1,79 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// getArchivedYears returns the tenant's archived tax years (admin only)
+func (api *API) getArchivedYears(w http.ResponseWriter, r *http.Request) {
+	years, err := api.store.GetArchivedYears(mux.Vars(r)["tenantId"])
+	if err != nil {
+		writeError(w, err, "Failed to fetch archived years")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(years); err != nil {
+		logger.Errorf("Failed to encode archived years response: %v", err)
+	}
+}
+
+// archiveYear makes the tenant's filings of a tax year and their documents read-only (admin only).
+// The worker moves the year's documents to the archive storage class afterwards.
+func (api *API) archiveYear(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req types.ArchiveYearRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Year == 0 {
+		http.Error(w, "year is required", http.StatusBadRequest)
+		return
+	}
+
+	archived, err := api.store.ArchiveYear(mux.Vars(r)["tenantId"], req.Year, employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to archive tax year")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(archived); err != nil {
+		logger.Errorf("Failed to encode archived year response: %v", err)
+	}
+}
+
+// unarchiveYear makes the tenant's filings of a tax year writable again (admin only). The worker
+// moves the year's documents back to the standard storage class afterwards.
+func (api *API) unarchiveYear(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	year, err := strconv.Atoi(vars["year"])
+	if err != nil {
+		http.Error(w, "Invalid year", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.store.UnarchiveYear(vars["tenantId"], year, employee.ID); err != nil {
+		writeError(w, err, "Failed to unarchive tax year")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,146 @@
+package webapi
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/auth"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// tenantContextResponse is returned when an employee selects a tenant
+type tenantContextResponse struct {
+	Token     string    `json:"token"` // Sent back in the X-WTP-Tenant-Context header
+	TenantID  string    `json:"tenantId"`
+	Role      string    `json:"role"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// createTenantContext selects the tenant the current employee works in for this sign-in
+// Body: {"tenantId": "..."}
+func (api *API) createTenantContext(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		TenantID string `json:"tenantId"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	req.TenantID = strings.TrimSpace(req.TenantID)
+	if req.TenantID == "" {
+		http.Error(w, "tenantId is required", http.StatusBadRequest)
+		return
+	}
+
+	// The context is bound to the sign-in of the ID token it is minted with
+	decodedToken, err := api.auth.VerifyToken(r.Context(), strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if err != nil {
+		http.Error(w, "Unauthorized: Invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	token, tokenHash, err := auth.NewTenantContextToken()
+	if err != nil {
+		logger.Errorf("Failed to generate tenant context token: %v", err)
+		http.Error(w, "Failed to select tenant", http.StatusInternalServerError)
+		return
+	}
+
+	tc, err := api.store.CreateTenantContext(employee, req.TenantID, decodedToken.AuthTime, tokenHash)
+	if err != nil {
+		writeError(w, err, "Failed to select tenant")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(tenantContextResponse{
+		Token:     token,
+		TenantID:  tc.TenantID,
+		Role:      tc.Role,
+		ExpiresAt: tc.ExpiresAt,
+	}); err != nil {
+		logger.Errorf("Failed to encode tenant context response: %v", err)
+	}
+}
+
+// getTenantContext returns the tenant context the request was sent with
+func (api *API) getTenantContext(w http.ResponseWriter, r *http.Request) {
+	tc, ok := middleware.GetTenantContextFromContext(r.Context())
+	if !ok {
+		http.Error(w, "No tenant context selected", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(tc); err != nil {
+		logger.Errorf("Failed to encode tenant context: %v", err)
+	}
+}
+
+// deleteTenantContext ends the tenant context sent in the X-WTP-Tenant-Context header
+func (api *API) deleteTenantContext(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	token := r.Header.Get(types.TenantContextHeader)
+	if token == "" {
+		http.Error(w, types.TenantContextHeader+" header is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.store.RevokeTenantContext(employee.ID, auth.HashSessionToken(token)); err != nil {
+		writeError(w, err, "Failed to end tenant context")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// currentTenantHandler serves requests under /api/v1/current/ as requests for the tenant of
+// their tenant context, so the UI can link to pages like /api/v1/current/clients without
+// knowing the tenant. Authentication still checks the context against the caller.
+func (api *API) currentTenantHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, types.TenantContextPathPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token := r.Header.Get(types.TenantContextHeader)
+		if token == "" {
+			http.Error(w, types.TenantContextHeader+" header is required", http.StatusBadRequest)
+			return
+		}
+
+		tc, err := api.store.GetTenantContext(auth.HashSessionToken(token))
+		if err != nil {
+			if errors.Is(err, apperr.ErrNotFound) {
+				http.Error(w, "Unauthorized: Tenant context expired", http.StatusUnauthorized)
+				return
+			}
+			http.Error(w, "Failed to check tenant context", http.StatusInternalServerError)
+			return
+		}
+
+		rewritten := r.Clone(r.Context())
+		rewritten.URL.Path = "/api/v1/" + tc.TenantID + "/" + strings.TrimPrefix(r.URL.Path, types.TenantContextPathPrefix)
+		rewritten.URL.RawPath = ""
+		next.ServeHTTP(w, rewritten)
+	})
+}
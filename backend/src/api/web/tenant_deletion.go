@@ -0,0 +1,128 @@
+package webapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/storage"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// tenantStorageMeasureTimeout bounds the listing of a tenant's bucket for a deletion request
+const tenantStorageMeasureTimeout = 30 * time.Second
+
+// requestTenantDeletion summarizes what deactivating a tenant affects and returns the token that
+// confirms it through deleteTenant within types.TenantDeletionWindow (admin only)
+func (api *API) requestTenantDeletion(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+	impact, err := api.store.CountTenantDeletionImpact(r.Context(), tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to count tenant deletion impact")
+		return
+	}
+	api.measureTenantStorage(r.Context(), tenantID, impact)
+
+	request, err := api.store.CreateTenantDeletionRequest(tenantID, impact, employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to request tenant deletion")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(request); err != nil {
+		logger.Errorf("Failed to encode tenant deletion request response: %v", err)
+	}
+}
+
+// measureTenantStorage adds the size of the tenant's bucket to impact, or why it was not measured
+func (api *API) measureTenantStorage(ctx context.Context, tenantID string, impact *types.TenantDeletionImpact) {
+	tc, err := api.store.GetTenantConfig(tenantID)
+	if err != nil {
+		logger.Warningf("Failed to get config of tenant %s to measure its storage: %v", tenantID, err)
+		impact.StorageError = "failed to get tenant configuration"
+		return
+	}
+	if tc.StorageBucket == "" {
+		impact.StorageError = "tenant has no storage bucket"
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, tenantStorageMeasureTimeout)
+	defer cancel()
+
+	provider, err := api.storageForTenant(ctx, tc)
+	if err != nil {
+		logger.Warningf("Failed to initialize storage of tenant %s to measure it: %v", tenantID, err)
+		impact.StorageError = "failed to initialize storage"
+		return
+	}
+	usage, err := storage.BucketUsage(ctx, provider, tc.StorageBucket, "")
+	if err != nil {
+		logger.Warningf("Failed to measure storage of tenant %s: %v", tenantID, err)
+		impact.StorageError = "failed to measure storage"
+		return
+	}
+	impact.StorageObjects = &usage.Objects
+	impact.StorageBytes = &usage.Bytes
+}
+
+// deleteTenant deactivates a tenant with the confirmation token from requestTenantDeletion. The
+// tenant's portal is closed, its open portal sessions are ended and its pooled connection is
+// dropped (admin only).
+func (api *API) deleteTenant(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		ConfirmationToken string `json:"confirmationToken"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+	if req.ConfirmationToken == "" {
+		http.Error(w, "confirmationToken is required; request one with POST /api/v1/admin/tenants/"+tenantID+"/deletion-request", http.StatusBadRequest)
+		return
+	}
+
+	logger.Infof("Deactivating tenant: %s", tenantID)
+
+	deactivation, err := api.store.DeactivateTenant(tenantID, req.ConfirmationToken, employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to deactivate tenant")
+		return
+	}
+
+	if api.notifier != nil {
+		go api.notifier.AlertAdmins(
+			fmt.Sprintf("Tenant %s deactivated", tenantID),
+			fmt.Sprintf("%s deactivated tenant %s. Its portal is closed and %d open portal sessions were ended.",
+				employee.Email, tenantID, deactivation.PortalSessionsEnded),
+		)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(deactivation); err != nil {
+		logger.Errorf("Failed to encode tenant deactivation response: %v", err)
+	}
+}
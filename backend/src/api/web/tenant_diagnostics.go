@@ -0,0 +1,188 @@
+package webapi
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/signature"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// diagnosticsStoragePrefix is where the storage check writes its test object
+const diagnosticsStoragePrefix = "welltaxpro-diagnostics/"
+
+// testTenantConnection checks a tenant's configuration against the services it points to
+// (admin only): the database connection, the schema prefix and the tables its adapter expects,
+// the storage bucket, the regions the tenant's data must stay in and the DocuSign credentials.
+// It returns a report of every check rather than stopping at the first failure, so one call
+// shows everything that needs fixing.
+func (api *API) testTenantConnection(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	tc, err := api.store.GetTenantConfig(tenantID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			http.Error(w, "Tenant not found", http.StatusNotFound)
+		} else {
+			logger.Errorf("Failed to get tenant: %v", err)
+			http.Error(w, "Failed to fetch tenant", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	logger.Infof("Testing connection of tenant %s", tenantID)
+
+	report := &types.TenantDiagnostics{
+		TenantID:    tenantID,
+		AdapterType: tc.AdapterType,
+		Checks:      []*types.TenantDiagnosticCheck{},
+		CheckedAt:   time.Now(),
+	}
+	check := func(name string, run func(c *types.TenantDiagnosticCheck) error) bool {
+		start := time.Now()
+		c := &types.TenantDiagnosticCheck{Name: name, Status: types.DiagnosticPassed}
+		if err := run(c); err != nil {
+			c.Status = types.DiagnosticFailed
+			c.Detail = err.Error()
+			logger.Warningf("Tenant %s failed the %s check: %v", tenantID, name, err)
+		}
+		c.DurationMs = time.Since(start).Milliseconds()
+		report.Checks = append(report.Checks, c)
+		return c.Status != types.DiagnosticFailed
+	}
+	skip := func(name, reason string) {
+		report.Checks = append(report.Checks, &types.TenantDiagnosticCheck{Name: name, Status: types.DiagnosticSkipped, Detail: reason})
+	}
+
+	connected := check(types.DiagnosticDatabase, func(c *types.TenantDiagnosticCheck) error {
+		if tc.AdapterType == types.SmokeAdapterType {
+			c.Status = types.DiagnosticSkipped
+			c.Detail = "the smoke tenant has no database"
+			return nil
+		}
+		if health := api.store.CheckTenantConnection(r.Context(), tenantID); !health.Healthy {
+			return fmt.Errorf("%s", health.Error)
+		}
+		return nil
+	})
+
+	switch {
+	case tc.AdapterType == types.SmokeAdapterType:
+		skip(types.DiagnosticSchema, "the smoke tenant has no database")
+		skip(types.DiagnosticTables, "the smoke tenant has no database")
+	case !connected:
+		skip(types.DiagnosticSchema, "the database check failed")
+		skip(types.DiagnosticTables, "the database check failed")
+	default:
+		var issues []*types.SchemaIssue
+		schemaFound := check(types.DiagnosticSchema, func(c *types.TenantDiagnosticCheck) error {
+			var err error
+			if issues, err = api.store.CompareTenantSchema(r.Context(), tenantID); err != nil {
+				return err
+			}
+			if len(issues) == 1 && issues[0].Type == types.SchemaMissingSchema {
+				return fmt.Errorf("schema %s has no tables; check the tenant's schema prefix and database", tc.SchemaPrefix)
+			}
+			return nil
+		})
+		if schemaFound {
+			check(types.DiagnosticTables, func(c *types.TenantDiagnosticCheck) error {
+				if len(issues) > 0 {
+					c.Issues = issues
+					return fmt.Errorf("%d differences from what the %s adapter expects", len(issues), tc.AdapterType)
+				}
+				return nil
+			})
+		} else {
+			skip(types.DiagnosticTables, "the schema check failed")
+		}
+	}
+
+	if tc.StorageBucket == "" {
+		skip(types.DiagnosticStorage, "no storage bucket is configured")
+	} else {
+		check(types.DiagnosticStorage, func(c *types.TenantDiagnosticCheck) error {
+			return api.checkTenantStorage(r, tc)
+		})
+	}
+
+	if tc.StorageRegion == "" && tc.DBRegion == "" {
+		skip(types.DiagnosticResidency, "no storage or database region is set")
+	} else {
+		check(types.DiagnosticResidency, func(c *types.TenantDiagnosticCheck) error {
+			if residency := api.checkTenantResidency(r.Context(), tc); !residency.Compliant {
+				return fmt.Errorf("%s", strings.Join(residency.Issues, "; "))
+			}
+			return nil
+		})
+	}
+
+	if tc.DocuSignIntegrationKey == "" || tc.DocuSignClientID == "" || tc.DocuSignPrivateKeySecret == "" {
+		skip(types.DiagnosticDocuSign, "DocuSign is not configured")
+	} else {
+		check(types.DiagnosticDocuSign, func(c *types.TenantDiagnosticCheck) error {
+			return signature.CheckCredentials(r.Context(), tc)
+		})
+	}
+
+	report.Passed = true
+	for _, c := range report.Checks {
+		report.Passed = report.Passed && c.Status != types.DiagnosticFailed
+	}
+	report.DurationMs = time.Since(report.CheckedAt).Milliseconds()
+
+	logger.Infof("Connection test of tenant %s finished: passed=%v in %dms", tenantID, report.Passed, report.DurationMs)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.Errorf("Failed to encode connection test response: %v", err)
+	}
+}
+
+// checkTenantStorage writes a test object to a tenant's bucket, reads it back and deletes it,
+// exercising the upload, read and delete credentials in turn
+func (api *API) checkTenantStorage(r *http.Request, tc *types.TenantConnection) error {
+	ctx := r.Context()
+	provider, err := api.storageForTenant(ctx, tc)
+	if err != nil {
+		return fmt.Errorf("failed to create storage client: %w", err)
+	}
+
+	path := diagnosticsStoragePrefix + uuid.New().String() + ".txt"
+	content := []byte("WellTaxPro connection test for tenant " + tc.TenantID)
+	if err := provider.Upload(ctx, tc.StorageBucket, path, bytes.NewReader(content), map[string]string{"purpose": "connection-test"}); err != nil {
+		return fmt.Errorf("upload failed: %w", err)
+	}
+
+	var problems []string
+	if rc, err := provider.Download(ctx, tc.StorageBucket, path); err != nil {
+		problems = append(problems, fmt.Sprintf("download failed: %v", err))
+	} else {
+		stored, err := io.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("download failed: %v", err))
+		} else if !bytes.Equal(stored, content) {
+			problems = append(problems, "downloaded object does not match the upload")
+		}
+	}
+
+	if err := provider.Delete(ctx, tc.StorageBucket, path); err != nil {
+		problems = append(problems, fmt.Sprintf("delete failed, remove %s by hand: %v", path, err))
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%s", strings.Join(problems, "; "))
+	}
+	return nil
+}
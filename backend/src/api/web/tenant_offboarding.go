@@ -0,0 +1,161 @@
+package webapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/offboarding"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// getTenantOffboarding returns the tenant's most recent offboarding and its next step (admin only)
+func (api *API) getTenantOffboarding(w http.ResponseWriter, r *http.Request) {
+	o, err := api.store.GetTenantOffboarding(mux.Vars(r)["tenantId"])
+	if err != nil {
+		writeError(w, err, "Failed to fetch tenant offboarding")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(o); err != nil {
+		logger.Errorf("Failed to encode tenant offboarding response: %v", err)
+	}
+}
+
+// startTenantOffboarding opens an offboarding for the tenant; its steps are then run in order
+// through runTenantOffboardingStep (admin only)
+func (api *API) startTenantOffboarding(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	var reason *string
+	if trimmed := strings.TrimSpace(req.Reason); trimmed != "" {
+		reason = &trimmed
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+	o, err := api.store.StartTenantOffboarding(tenantID, reason, employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to start tenant offboarding")
+		return
+	}
+	api.auditOffboarding(r, employee, o, "start")
+
+	if api.notifier != nil {
+		go api.notifier.AlertAdmins(
+			fmt.Sprintf("Offboarding started for %s", tenantID),
+			fmt.Sprintf("%s started offboarding tenant %s. No changes are made until its steps are run.", employee.Email, tenantID),
+		)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(o); err != nil {
+		logger.Errorf("Failed to encode tenant offboarding response: %v", err)
+	}
+}
+
+// runTenantOffboardingStep runs the next step of the tenant's offboarding; the step in the path
+// must be the offboarding's nextStep (admin only). set_retention takes an optional retentionDays.
+func (api *API) runTenantOffboardingStep(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	vars := mux.Vars(r)
+	step := vars["step"]
+	if !types.IsValidOffboardingStep(step) {
+		http.Error(w, "Unknown offboarding step", http.StatusBadRequest)
+		return
+	}
+
+	var req struct {
+		RetentionDays int `json:"retentionDays,omitempty"`
+	}
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	o, err := api.offboarder.Advance(r.Context(), vars["tenantId"], step, employee.ID, offboarding.Options{RetentionDays: req.RetentionDays})
+	if err != nil {
+		writeError(w, err, "Failed to run offboarding step")
+		return
+	}
+	api.auditOffboarding(r, employee, o, step)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(o); err != nil {
+		logger.Errorf("Failed to encode tenant offboarding response: %v", err)
+	}
+}
+
+// cancelTenantOffboarding calls off an offboarding before employee access is revoked, which
+// reopens the portal (admin only)
+func (api *API) cancelTenantOffboarding(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	o, err := api.store.CancelTenantOffboarding(mux.Vars(r)["tenantId"], employee.ID)
+	if err != nil {
+		writeError(w, err, "Failed to cancel tenant offboarding")
+		return
+	}
+	api.auditOffboarding(r, employee, o, types.OffboardingStepCancel)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(o); err != nil {
+		logger.Errorf("Failed to encode tenant offboarding response: %v", err)
+	}
+}
+
+// getTenantOffboardingReport returns the report of the tenant's completed offboarding (admin only)
+func (api *API) getTenantOffboardingReport(w http.ResponseWriter, r *http.Request) {
+	report, err := api.store.GetTenantOffboardingReport(mux.Vars(r)["tenantId"])
+	if err != nil {
+		writeError(w, err, "Failed to fetch offboarding report")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		logger.Errorf("Failed to encode offboarding report response: %v", err)
+	}
+}
+
+// auditOffboarding records an offboarding step in the audit log
+func (api *API) auditOffboarding(r *http.Request, employee *types.Employee, o *types.TenantOffboarding, step string) {
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	details := map[string]interface{}{"step": step, "status": o.Status}
+	if err := api.store.CreateAuditLog(employee.ID, o.TenantID, nil, types.AuditActionEdit, types.AuditResourceOffboarding, &o.ID, details, &ipAddress, &userAgent); err != nil {
+		logger.Errorf("Failed to audit offboarding step %s of tenant %s: %v", step, o.TenantID, err)
+	}
+}
@@ -0,0 +1,41 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"welltaxpro/src/internal/middleware"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// getTenantPools lists the tenant database connection pools this process has open (admin only).
+// Each API process keeps its own pools, so the list covers only the process that served the request.
+func (api *API) getTenantPools(w http.ResponseWriter, r *http.Request) {
+	response := map[string]interface{}{
+		"idleTimeoutSeconds": int64(api.store.TenantPoolIdleTimeout().Seconds()),
+		"pools":              api.store.GetTenantPoolStats(),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		logger.Errorf("Failed to encode tenant pools response: %v", err)
+	}
+}
+
+// closeTenantPool closes a tenant's connection pool in this process (admin only); the next
+// request for the tenant opens a new one
+func (api *API) closeTenantPool(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	if !api.store.EvictTenantConnection(tenantID) {
+		http.Error(w, "No open connection pool for tenant", http.StatusNotFound)
+		return
+	}
+
+	if employee, ok := middleware.GetEmployeeFromContext(r.Context()); ok {
+		logger.Infof("Employee %s closed the connection pool of tenant %s", employee.ID, tenantID)
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,84 @@
+package webapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+	"welltaxpro/src/internal/storage"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// getTenantResidency reports where a tenant's data is kept, for compliance documentation (admin
+// only). The bucket's location is asked of the storage provider, so the report shows where the
+// data is rather than only where it should be.
+func (api *API) getTenantResidency(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	tc, err := api.store.GetTenantConfig(tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	residency := api.checkTenantResidency(r.Context(), tc)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(residency); err != nil {
+		logger.Errorf("Failed to encode residency of tenant %s: %v", tenantID, err)
+	}
+}
+
+// checkTenantResidency compares a tenant's storage and database regions with where its bucket
+// and Cloud SQL instance are. A location that must be in a region but cannot be determined is an
+// issue too, since compliance cannot be shown for it.
+func (api *API) checkTenantResidency(ctx context.Context, tc *types.TenantConnection) *types.TenantResidency {
+	residency := &types.TenantResidency{
+		TenantID:         tc.TenantID,
+		DataResidency:    tc.DataResidency,
+		StorageProvider:  tc.StorageProvider,
+		StorageBucket:    tc.StorageBucket,
+		StorageRegion:    tc.StorageRegion,
+		DBAuthType:       tc.DBAuthType,
+		DBRegion:         tc.DBRegion,
+		DBInstanceRegion: tc.DBInstanceRegion(),
+		Enforced:         tc.StorageRegion != "" || tc.DBRegion != "",
+		CheckedAt:        time.Now(),
+	}
+
+	if tc.StorageBucket != "" {
+		provider, err := api.storageForTenant(ctx, tc)
+		if err == nil {
+			residency.BucketLocation, err = storage.BucketLocation(ctx, provider, tc.StorageBucket)
+		}
+		if err != nil {
+			logger.Warningf("Failed to get bucket location of tenant %s: %v", tc.TenantID, err)
+			if tc.StorageRegion != "" {
+				residency.Issues = append(residency.Issues, fmt.Sprintf("bucket location unknown: %v", err))
+			}
+		}
+	}
+
+	if tc.StorageRegion != "" {
+		switch {
+		case tc.StorageBucket == "":
+			residency.Issues = append(residency.Issues, "no storage bucket is configured")
+		case residency.BucketLocation != "" && !types.SameRegion(residency.BucketLocation, tc.StorageRegion):
+			residency.Issues = append(residency.Issues, fmt.Sprintf("bucket %s is in %s, not %s", tc.StorageBucket, residency.BucketLocation, tc.StorageRegion))
+		}
+	}
+	// Databases reached by host have no region the platform can see, so their region is a label
+	if tc.DBRegion != "" && residency.DBInstanceRegion != "" {
+		residency.DBRegionVerified = true
+		if !types.SameRegion(residency.DBInstanceRegion, tc.DBRegion) {
+			residency.Issues = append(residency.Issues, fmt.Sprintf("database instance is in %s, not %s", residency.DBInstanceRegion, tc.DBRegion))
+		}
+	}
+
+	residency.Compliant = len(residency.Issues) == 0
+	return residency
+}
@@ -0,0 +1,188 @@
+package webapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// tenantStatusTTL is how long a public tenant status is served from cache
+const tenantStatusTTL = time.Minute
+
+// tenantStatusCache holds the last public status per tenant.
+// Status pages are polled by every client, so results are reused for tenantStatusTTL.
+type tenantStatusCache struct {
+	mu       sync.Mutex
+	byTenant map[string]*types.TenantStatus
+}
+
+// get returns the cached status of a tenant while it is fresh
+func (c *tenantStatusCache) get(tenantID string) (*types.TenantStatus, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	status, ok := c.byTenant[tenantID]
+	if !ok || time.Since(status.CheckedAt) >= tenantStatusTTL {
+		return nil, false
+	}
+	return status, true
+}
+
+// put caches the status of a tenant
+func (c *tenantStatusCache) put(status *types.TenantStatus) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.byTenant == nil {
+		c.byTenant = map[string]*types.TenantStatus{}
+	}
+	c.byTenant[status.TenantID] = status
+}
+
+// forget drops the cached status of a tenant, so a maintenance change shows on the next request
+func (c *tenantStatusCache) forget(tenantID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.byTenant, tenantID)
+}
+
+// getTenantStatus returns the public status of a tenant's portal and document uploads with its
+// current and upcoming maintenance windows (no auth required, cached for tenantStatusTTL)
+func (api *API) getTenantStatus(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	status, ok := api.tenantStatuses.get(tenantID)
+	if !ok {
+		var err error
+		status, err = api.store.GetTenantStatus(r.Context(), tenantID)
+		if err != nil {
+			writeError(w, err, "Failed to get tenant status")
+			return
+		}
+		api.tenantStatuses.put(status)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(tenantStatusTTL.Seconds())))
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		logger.Errorf("Failed to encode tenant status response: %v", err)
+	}
+}
+
+// getMaintenanceWindows lists a tenant's maintenance windows (admin only)
+// Optional query: ?upcoming=true leaves out windows that have ended
+func (api *API) getMaintenanceWindows(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	windows, err := api.store.GetMaintenanceWindows(tenantID, r.URL.Query().Get("upcoming") == "true")
+	if err != nil {
+		writeError(w, err, "Failed to fetch maintenance windows")
+		return
+	}
+	if windows == nil {
+		windows = []*types.MaintenanceWindow{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(windows); err != nil {
+		logger.Errorf("Failed to encode maintenance windows response: %v", err)
+	}
+}
+
+// createMaintenanceWindow schedules a maintenance window shown on the tenant's status page (admin only)
+// affectsPortal and affectsUploads default to true
+func (api *API) createMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var req struct {
+		StartsAt       time.Time `json:"startsAt"`
+		EndsAt         time.Time `json:"endsAt"`
+		Message        string    `json:"message"`
+		AffectsPortal  *bool     `json:"affectsPortal"`
+		AffectsUploads *bool     `json:"affectsUploads"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	m := &types.MaintenanceWindow{
+		TenantID:       tenantID,
+		StartsAt:       req.StartsAt.UTC(),
+		EndsAt:         req.EndsAt.UTC(),
+		Message:        strings.TrimSpace(req.Message),
+		AffectsPortal:  req.AffectsPortal == nil || *req.AffectsPortal,
+		AffectsUploads: req.AffectsUploads == nil || *req.AffectsUploads,
+		CreatedBy:      &employee.ID,
+	}
+	switch {
+	case req.StartsAt.IsZero() || req.EndsAt.IsZero():
+		http.Error(w, "startsAt and endsAt are required", http.StatusBadRequest)
+		return
+	case !m.EndsAt.After(m.StartsAt):
+		http.Error(w, "endsAt must be after startsAt", http.StatusBadRequest)
+		return
+	case !m.EndsAt.After(time.Now()):
+		http.Error(w, "endsAt must be in the future", http.StatusBadRequest)
+		return
+	case m.Message == "":
+		http.Error(w, "message is required", http.StatusBadRequest)
+		return
+	case len(m.Message) > types.MaxMaintenanceMessageLength:
+		http.Error(w, fmt.Sprintf("message must be at most %d characters", types.MaxMaintenanceMessageLength), http.StatusBadRequest)
+		return
+	case !m.AffectsPortal && !m.AffectsUploads:
+		http.Error(w, "a maintenance window must affect the portal or uploads", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := api.store.GetTenantConfig(tenantID); err != nil {
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	if err := api.store.CreateMaintenanceWindow(m); err != nil {
+		writeError(w, err, "Failed to create maintenance window")
+		return
+	}
+	api.tenantStatuses.forget(tenantID)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(m); err != nil {
+		logger.Errorf("Failed to encode maintenance window response: %v", err)
+	}
+}
+
+// deleteMaintenanceWindow cancels a maintenance window (admin only)
+func (api *API) deleteMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+	windowID, err := uuid.Parse(vars["windowId"])
+	if err != nil {
+		http.Error(w, "Invalid maintenance window ID", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.store.DeleteMaintenanceWindow(tenantID, windowID.String()); err != nil {
+		writeError(w, err, "Failed to delete maintenance window")
+		return
+	}
+	api.tenantStatuses.forget(tenantID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,13 +2,13 @@ package webapi
 
 import (
 	"context"
-	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"welltaxpro/src/internal/apperr"
 	"welltaxpro/src/internal/middleware"
-	"welltaxpro/src/internal/storage"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
@@ -57,36 +57,33 @@ func (api *API) autoRegisterTenantUser(w http.ResponseWriter, r *http.Request) {
 		json.NewEncoder(w).Encode(existingUser)
 		return
 	}
+	if !errors.Is(err, apperr.ErrNotFound) {
+		logger.Errorf("Failed to look up tenant user: %v", err)
+		writeError(w, err, "Failed to register user")
+		return
+	}
 
 	// Try to find existing client in tenant database by email
 	clientID := NewClientUUID // Default to "new client"
 
-	tenantDB, tc, err := api.store.GetTenantDB(tenantID)
-	if err != nil {
-		logger.Errorf("Failed to get tenant database: %v", err)
-		// Continue with NewClientUUID
-	} else {
-		// Query for existing client by email
-		query := fmt.Sprintf(`
-			SELECT id FROM %s.user
-			WHERE email = $1
-			LIMIT 1
-		`, tc.SchemaPrefix)
-
-		var foundClientID string
-		err = tenantDB.QueryRow(query, req.Email).Scan(&foundClientID)
-		if err == nil {
-			// Client exists, use their ID
-			parsedClientID, parseErr := uuid.Parse(foundClientID)
-			if parseErr == nil {
-				clientID = parsedClientID
-				logger.Infof("Found existing client: %s for email: %s", clientID.String(), req.Email)
-			}
-		} else if err != sql.ErrNoRows {
-			logger.Errorf("Error querying for client: %v", err)
-		} else {
-			logger.Infof("No existing client found for email: %s, using NewClientUUID", req.Email)
+	foundClientID, err := api.store.FindClientIDByEmail(r.Context(), tenantID, req.Email)
+	switch {
+	case err == nil:
+		// Client exists, use their ID
+		clientID = foundClientID
+		logger.Infof("Found existing client: %s for email: %s", clientID.String(), req.Email)
+
+		archived, archErr := api.store.IsClientArchived(r.Context(), tenantID, clientID.String())
+		if archErr == nil && archived {
+			logger.Warningf("Refusing portal registration for archived client %s", clientID.String())
+			http.Error(w, "Portal access is disabled for this account", http.StatusForbidden)
+			return
 		}
+	case errors.Is(err, apperr.ErrNotFound):
+		logger.Infof("No existing client found for email: %s, using NewClientUUID", req.Email)
+	default:
+		// Continue with NewClientUUID
+		logger.Errorf("Error querying for client: %v", err)
 	}
 
 	// Create new tenant user
@@ -100,7 +97,7 @@ func (api *API) autoRegisterTenantUser(w http.ResponseWriter, r *http.Request) {
 
 	if err := api.store.CreateTenantUser(tenantUser); err != nil {
 		logger.Errorf("Failed to create tenant user: %v", err)
-		http.Error(w, "Failed to register user", http.StatusInternalServerError)
+		writeError(w, err, "Failed to register user")
 		return
 	}
 
@@ -140,6 +137,17 @@ func (api *API) registerTenantUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	archived, err := api.store.IsClientArchived(r.Context(), tenantID, clientUUID.String())
+	if err != nil {
+		logger.Errorf("Failed to check archive status for client %s: %v", req.ClientID, err)
+		writeError(w, err, "Failed to check client")
+		return
+	}
+	if archived {
+		http.Error(w, "Cannot grant portal access to an archived client", http.StatusConflict)
+		return
+	}
+
 	// Create tenant user
 	tenantUser := &types.TenantUser{
 		TenantID:    tenantID,
@@ -151,7 +159,7 @@ func (api *API) registerTenantUser(w http.ResponseWriter, r *http.Request) {
 
 	if err := api.store.CreateTenantUser(tenantUser); err != nil {
 		logger.Errorf("Failed to create tenant user: %v", err)
-		http.Error(w, "Failed to register user", http.StatusInternalServerError)
+		writeError(w, err, "Failed to register user")
 		return
 	}
 
@@ -162,7 +170,67 @@ func (api *API) registerTenantUser(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(tenantUser)
 }
 
+// deleteTenantUser handles DELETE /api/v1/{tenantId}/users/{tenantUserId}
+// Removes a client's portal access (admin only): the tenant user is deactivated and the refresh
+// tokens of their Firebase account revoked, so the portal signs them out when their ID token
+// expires. Answers 502 when Firebase could not be updated; the call can be retried.
+func (api *API) deleteTenantUser(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	tenantID := vars["tenantId"]
+
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantUserID, err := uuid.Parse(vars["tenantUserId"])
+	if err != nil {
+		http.Error(w, "Invalid tenant user ID format", http.StatusBadRequest)
+		return
+	}
+
+	tenantUser, err := api.store.GetTenantUser(tenantUserID)
+	if err == nil && tenantUser.TenantID != tenantID {
+		err = apperr.NotFound("tenant user not found: %s", tenantUserID.String())
+	}
+	if err != nil {
+		writeError(w, err, "Failed to fetch tenant user")
+		return
+	}
+
+	if err := api.store.DeactivateTenantUser(tenantUserID); err != nil {
+		writeError(w, err, "Failed to deactivate tenant user")
+		return
+	}
+
+	firebaseErr := api.firebaseUsers.RevokeRefreshTokens(r.Context(), tenantUser.FirebaseUID)
+	if firebaseErr != nil {
+		logger.Errorf("Failed to revoke Firebase tokens of tenant user %s: %v", tenantUserID, firebaseErr)
+	}
+	logger.Infof("Employee %s removed portal access of tenant user %s in tenant %s", employee.Email, tenantUserID, tenantID)
+
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	details := map[string]interface{}{
+		"email":         tenantUser.Email,
+		"wasActive":     tenantUser.IsActive,
+		"tokensRevoked": firebaseErr == nil,
+	}
+	if err := api.store.CreateAuditLog(employee.ID, tenantID, &tenantUser.ClientID, types.AuditActionRevoke, types.AuditResourcePortalUser, &tenantUser.ID, details, &ipAddress, &userAgent); err != nil {
+		logger.Errorf("Failed to audit removal of tenant user %s: %v", tenantUserID, err)
+	}
+
+	if firebaseErr != nil {
+		http.Error(w, "Portal access removed, but the user's Firebase sessions could not be revoked; retry to finish", http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
 // getTenantUserProfile returns the authenticated tenant user's profile and comprehensive data
+// Optional query: ?fields= selects a subset of the payload (see writeSlimJSON)
 func (api *API) getTenantUserProfile(w http.ResponseWriter, r *http.Request) {
 	// Get Firebase UID from context (set by TenantUserAuthMiddleware)
 	firebaseUID, err := middleware.GetFirebaseUIDFromContext(r.Context())
@@ -174,8 +242,12 @@ func (api *API) getTenantUserProfile(w http.ResponseWriter, r *http.Request) {
 	// Get tenant user record
 	tenantUser, err := api.store.GetTenantUserByFirebaseUID(firebaseUID)
 	if err != nil {
-		logger.Errorf("Tenant user not found for firebase uid %s: %v", firebaseUID, err)
-		http.Error(w, "User not registered for portal access", http.StatusNotFound)
+		logger.Errorf("Failed to get tenant user for firebase uid %s: %v", firebaseUID, err)
+		if errors.Is(err, apperr.ErrNotFound) {
+			http.Error(w, "User not registered for portal access", http.StatusNotFound)
+			return
+		}
+		writeError(w, err, "Failed to fetch user")
 		return
 	}
 
@@ -209,90 +281,120 @@ func (api *API) getTenantUserProfile(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Archived clients no longer have portal access
+	if api.isArchivedPortalClient(r.Context(), tenantUser) {
+		http.Error(w, "Portal access is disabled for this account", http.StatusForbidden)
+		return
+	}
+
 	// Get comprehensive client data from tenant database
-	clientData, err := api.store.GetClientComprehensive(tenantUser.TenantID, tenantUser.ClientID.String())
+	clientData, err := api.store.GetClientComprehensive(r.Context(), tenantUser.TenantID, tenantUser.ClientID.String())
 	if err != nil {
 		logger.Errorf("Failed to get client data: %v", err)
-		http.Error(w, "Failed to fetch user data", http.StatusInternalServerError)
+		writeError(w, err, "Failed to fetch user data")
 		return
 	}
 
+	// The portal shows each filing's missing documents; a failure only leaves them out
+	if err := api.store.ApplyFilingChecklists(tenantUser.TenantID, clientData); err != nil {
+		logger.Warningf("Failed to build filing checklists for tenant user %s: %v", tenantUser.ID, err)
+	}
+
 	logger.Infof("Tenant user %s accessed their profile (client: %s, tenant: %s)",
 		firebaseUID, tenantUser.ClientID.String(), tenantUser.TenantID)
 
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(clientData)
+	// Opening the full profile clears the portal's unread counts
+	if err := api.store.MarkTenantUserSeen(tenantUser.ID); err != nil {
+		logger.Warningf("Failed to update last seen for tenant user %s: %v", tenantUser.ID, err)
+	}
+
+	if err := writeSlimJSON(w, r, clientData); err != nil {
+		logger.Errorf("Failed to encode tenant user profile response: %v", err)
+	}
 }
 
 // downloadTenantUserDocument allows authenticated tenant users to download their own documents
 func (api *API) downloadTenantUserDocument(w http.ResponseWriter, r *http.Request) {
-	// Get Firebase UID from context (set by TenantUserAuthMiddleware)
-	firebaseUID, err := middleware.GetFirebaseUIDFromContext(r.Context())
-	if err != nil {
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+	tenantUser, ok := api.portalTenantUser(w, r)
+	if !ok {
 		return
 	}
 
-	// Get tenant user record
-	tenantUser, err := api.store.GetTenantUserByFirebaseUID(firebaseUID)
-	if err != nil {
-		logger.Errorf("Tenant user not found for firebase uid %s: %v", firebaseUID, err)
-		http.Error(w, "User not registered for portal access", http.StatusNotFound)
+	documentID := mux.Vars(r)["documentId"]
+	logger.Infof("Tenant user %s downloading document %s", tenantUser.FirebaseUID, documentID)
+
+	tc, filePath, fileName, ok := api.portalDocument(r.Context(), w, tenantUser, documentID)
+	if !ok {
 		return
 	}
 
-	vars := mux.Vars(r)
-	documentID := vars["documentId"]
-	requestedTenantID := vars["tenantId"]
+	// Stream the file directly from storage
+	logger.Infof("Streaming document %s to tenant user %s", documentID, tenantUser.ClientID.String())
 
-	// Verify tenant ID matches
-	if tenantUser.TenantID != requestedTenantID {
-		logger.Warningf("Tenant mismatch for document download: user belongs to %s but requested %s", tenantUser.TenantID, requestedTenantID)
-		http.Error(w, "Forbidden", http.StatusForbidden)
+	reader, ok := api.openPortalDocument(w, tc, filePath)
+	if !ok {
+		return
+	}
+	defer reader.Close()
+
+	// Set response headers for file download
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+	w.Header().Set("Content-Type", "application/octet-stream")
+
+	// Stream the file to the response
+	if _, err := io.Copy(w, reader); err != nil {
+		logger.Errorf("Failed to stream document: %v", err)
 		return
 	}
 
-	logger.Infof("Tenant user %s downloading document %s", firebaseUID, documentID)
+	logger.Infof("Successfully streamed document %s", documentID)
+}
 
+// portalDocument looks up a document in the tenant database and checks it belongs to the tenant
+// user's client, writing the error response when it does not. It returns the document's storage
+// path and name.
+func (api *API) portalDocument(ctx context.Context, w http.ResponseWriter, tenantUser *types.TenantUser, documentID string) (*types.TenantConnection, string, string, bool) {
 	// Get tenant database connection
-	tenantDB, tc, err := api.store.GetTenantDB(tenantUser.TenantID)
+	_, tc, err := api.store.GetTenantDB(ctx, tenantUser.TenantID)
 	if err != nil {
 		logger.Errorf("Failed to get tenant database: %v", err)
-		http.Error(w, "Failed to connect to tenant database", http.StatusInternalServerError)
-		return
+		writeError(w, err, "Failed to connect to tenant database")
+		return nil, "", "", false
 	}
 
 	// Verify document belongs to this client
-	var filePath, fileName, ownerID string
-	query := `
-		SELECT d.file_path, d.name, d.user_id
-		FROM ` + tc.SchemaPrefix + `.document d
-		WHERE d.id = $1
-	`
-	err = tenantDB.QueryRow(query, documentID).Scan(&filePath, &fileName, &ownerID)
+	document, err := api.store.GetDocumentByID(ctx, tenantUser.TenantID, documentID)
 	if err != nil {
 		logger.Errorf("Failed to get document: %v", err)
 		http.Error(w, "Document not found", http.StatusNotFound)
-		return
+		return nil, "", "", false
 	}
 
 	// Check ownership
-	if ownerID != tenantUser.ClientID.String() {
-		logger.Warningf("Client %s attempted to download document %s owned by %s",
-			tenantUser.ClientID.String(), documentID, ownerID)
+	if document.UserID != tenantUser.ClientID {
+		logger.Warningf("Client %s attempted to access document %s owned by %s",
+			tenantUser.ClientID.String(), documentID, document.UserID)
 		http.Error(w, "Forbidden", http.StatusForbidden)
-		return
+		return nil, "", "", false
 	}
 
-	// Stream the file directly from storage
-	logger.Infof("Streaming document %s to tenant user %s", documentID, tenantUser.ClientID.String())
+	// Infected and not yet scanned files are not served
+	if err := api.store.CheckDocumentScan(tenantUser.TenantID, documentID); err != nil {
+		writeError(w, err, "Failed to check document scan")
+		return nil, "", "", false
+	}
 
+	return tc, document.FilePath, document.Name, true
+}
+
+// openPortalDocument opens a document in the tenant's storage, writing the error response on failure
+func (api *API) openPortalDocument(w http.ResponseWriter, tc *types.TenantConnection, filePath string) (io.ReadCloser, bool) {
 	// Create storage provider
-	storageProvider, err := storage.NewStorageProviderForTenant(context.Background(), tc)
+	storageProvider, err := api.storageForTenant(context.Background(), tc)
 	if err != nil {
 		logger.Errorf("Failed to create storage provider: %v", err)
 		http.Error(w, "Failed to initialize storage", http.StatusInternalServerError)
-		return
+		return nil, false
 	}
 
 	// Download file from storage
@@ -300,19 +402,22 @@ func (api *API) downloadTenantUserDocument(w http.ResponseWriter, r *http.Reques
 	if err != nil {
 		logger.Errorf("Failed to download document from storage: %v", err)
 		http.Error(w, "Failed to download document", http.StatusInternalServerError)
-		return
+		return nil, false
 	}
-	defer reader.Close()
-
-	// Set response headers for file download
-	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
-	w.Header().Set("Content-Type", "application/octet-stream")
+	return reader, true
+}
 
-	// Stream the file to the response
-	if _, err := io.Copy(w, reader); err != nil {
-		logger.Errorf("Failed to stream document: %v", err)
-		return
+// isArchivedPortalClient reports whether the tenant user's linked client is archived
+// Lookup failures are treated as archived so portal access fails closed
+func (api *API) isArchivedPortalClient(ctx context.Context, tenantUser *types.TenantUser) bool {
+	if tenantUser.ClientID == NewClientUUID {
+		return false
 	}
 
-	logger.Infof("Successfully streamed document %s", documentID)
+	archived, err := api.store.IsClientArchived(ctx, tenantUser.TenantID, tenantUser.ClientID.String())
+	if err != nil {
+		logger.Errorf("Failed to check archive status for client %s: %v", tenantUser.ClientID.String(), err)
+		return true
+	}
+	return archived
 }
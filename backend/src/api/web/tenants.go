@@ -5,8 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"welltaxpro/src/internal/adapter"
 	"welltaxpro/src/internal/crypto"
 	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/sqlident"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
@@ -18,56 +22,12 @@ import (
 func (api *API) getAllTenants(w http.ResponseWriter, r *http.Request) {
 	logger.Info("Getting all tenants")
 
-	query := `
-		SELECT id, tenant_id, tenant_name, db_host, db_port, db_user,
-		       db_name, db_sslmode, schema_prefix, adapter_type,
-		       COALESCE(storage_provider, ''), COALESCE(storage_bucket, ''),
-		       COALESCE(docusign_integration_key, ''), COALESCE(docusign_client_id, ''),
-		       COALESCE(docusign_api_url, ''),
-		       is_active, created_at, updated_at, created_by, notes
-		FROM tenant_connections
-		ORDER BY created_at DESC
-	`
-
-	rows, err := api.store.DB.Query(query)
+	tenants, err := api.store.GetTenantConnections()
 	if err != nil {
 		logger.Errorf("Failed to query tenants: %v", err)
 		http.Error(w, "Failed to fetch tenants", http.StatusInternalServerError)
 		return
 	}
-	defer rows.Close()
-
-	var tenants []types.TenantConnection
-	for rows.Next() {
-		var tc types.TenantConnection
-		err := rows.Scan(
-			&tc.ID,
-			&tc.TenantID,
-			&tc.TenantName,
-			&tc.DBHost,
-			&tc.DBPort,
-			&tc.DBUser,
-			&tc.DBName,
-			&tc.DBSslMode,
-			&tc.SchemaPrefix,
-			&tc.AdapterType,
-			&tc.StorageProvider,
-			&tc.StorageBucket,
-			&tc.DocuSignIntegrationKey,
-			&tc.DocuSignClientID,
-			&tc.DocuSignAPIURL,
-			&tc.IsActive,
-			&tc.CreatedAt,
-			&tc.UpdatedAt,
-			&tc.CreatedBy,
-			&tc.Notes,
-		)
-		if err != nil {
-			logger.Errorf("Failed to scan tenant: %v", err)
-			continue
-		}
-		tenants = append(tenants, tc)
-	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(tenants); err != nil {
@@ -75,7 +35,10 @@ func (api *API) getAllTenants(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// getTenant returns a single tenant by ID (admin only)
+// tenantUptimeRecentProbes is how many of a tenant's latest database probes its detail lists
+const tenantUptimeRecentProbes = 60
+
+// getTenant returns a single tenant by ID with its database uptime history (admin only)
 func (api *API) getTenant(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	tenantID := vars["tenantId"]
@@ -93,12 +56,25 @@ func (api *API) getTenant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	detail := &types.TenantDetail{TenantConnection: tc}
+	uptime, err := api.store.GetTenantUptime(tenantID, tenantUptimeRecentProbes)
+	if err != nil {
+		// The tenant is still served; its uptime is left out
+		logger.Warningf("Failed to get uptime of tenant %s: %v", tenantID, err)
+	} else {
+		detail.ConnectionUptime = uptime
+	}
+
 	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(tc); err != nil {
+	if err := json.NewEncoder(w).Encode(detail); err != nil {
 		logger.Errorf("Failed to encode tenant: %v", err)
 	}
 }
 
+// statementTimeoutMessage rejects a tenant statement timeout outside the allowed range
+var statementTimeoutMessage = fmt.Sprintf("statementTimeoutMs must be between %d and %d",
+	types.MinStatementTimeoutMs, types.MaxStatementTimeoutMs)
+
 // createTenant creates a new tenant connection (admin only)
 func (api *API) createTenant(w http.ResponseWriter, r *http.Request) {
 	// Get employee from context
@@ -109,25 +85,38 @@ func (api *API) createTenant(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var req struct {
-		TenantID                 string  `json:"tenantId"`
-		TenantName               string  `json:"tenantName"`
-		DBHost                   string  `json:"dbHost"`
-		DBPort                   int     `json:"dbPort"`
-		DBUser                   string  `json:"dbUser"`
-		DBPassword               string  `json:"dbPassword"`
-		DBName                   string  `json:"dbName"`
-		DBSslMode                string  `json:"dbSslMode"`
-		SchemaPrefix             string  `json:"schemaPrefix"`
-		AdapterType              string  `json:"adapterType"`
-		StorageProvider          string  `json:"storageProvider"`
-		StorageBucket            string  `json:"storageBucket"`
-		StorageCredentialsSecret string  `json:"storageCredentialsSecret"`
-		StorageCredentialsPath   string  `json:"storageCredentialsPath"`
-		DocuSignIntegrationKey   string  `json:"docusignIntegrationKey"`
-		DocuSignClientID         string  `json:"docusignClientId"`
-		DocuSignPrivateKeySecret string  `json:"docusignPrivateKeySecret"`
-		DocuSignAPIURL           string  `json:"docusignApiUrl"`
-		Notes                    *string `json:"notes"`
+		TenantID                       string  `json:"tenantId"`
+		TenantName                     string  `json:"tenantName"`
+		DBHost                         string  `json:"dbHost"`
+		DBPort                         int     `json:"dbPort"`
+		DBUser                         string  `json:"dbUser"`
+		DBPassword                     string  `json:"dbPassword"`
+		DBName                         string  `json:"dbName"`
+		DBSslMode                      string  `json:"dbSslMode"`
+		DBAuthType                     string  `json:"dbAuthType"`               // password (default), iam or connector
+		DBInstanceConnectionName       string  `json:"dbInstanceConnectionName"` // Required for connector auth
+		SchemaPrefix                   string  `json:"schemaPrefix"`
+		AdapterType                    string  `json:"adapterType"`
+		IDVersion                      string  `json:"idVersion"` // v7 (default) or v4
+		StorageProvider                string  `json:"storageProvider"`
+		StorageBucket                  string  `json:"storageBucket"`
+		StorageRegion                  string  `json:"storageRegion"`
+		DBRegion                       string  `json:"dbRegion"`
+		DataResidency                  string  `json:"dataResidency"`
+		StorageCredentialsSecret       string  `json:"storageCredentialsSecret"`
+		StorageCredentialsPath         string  `json:"storageCredentialsPath"`
+		StorageUploadCredentialsSecret string  `json:"storageUploadCredentialsSecret"`
+		StorageUploadCredentialsPath   string  `json:"storageUploadCredentialsPath"`
+		StorageReadCredentialsSecret   string  `json:"storageReadCredentialsSecret"`
+		StorageReadCredentialsPath     string  `json:"storageReadCredentialsPath"`
+		StorageDeleteCredentialsSecret string  `json:"storageDeleteCredentialsSecret"`
+		StorageDeleteCredentialsPath   string  `json:"storageDeleteCredentialsPath"`
+		DocuSignIntegrationKey         string  `json:"docusignIntegrationKey"`
+		DocuSignClientID               string  `json:"docusignClientId"`
+		DocuSignPrivateKeySecret       string  `json:"docusignPrivateKeySecret"`
+		DocuSignAPIURL                 string  `json:"docusignApiUrl"`
+		StatementTimeoutMs             *int    `json:"statementTimeoutMs"` // Optional - defaults to 30s
+		Notes                          *string `json:"notes"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -136,13 +125,48 @@ func (api *API) createTenant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Validate required fields
-	if req.TenantID == "" || req.TenantName == "" || req.DBHost == "" ||
-		req.DBUser == "" || req.DBPassword == "" || req.DBName == "" ||
+	if req.DBAuthType == "" {
+		req.DBAuthType = types.DBAuthPassword
+	}
+	if !types.IsValidDBAuthType(req.DBAuthType) {
+		http.Error(w, "dbAuthType must be password, iam or connector", http.StatusBadRequest)
+		return
+	}
+	if req.IDVersion == "" {
+		req.IDVersion = types.IDVersionV7
+	}
+	if !types.IsValidIDVersion(req.IDVersion) {
+		http.Error(w, "idVersion must be v7 or v4", http.StatusBadRequest)
+		return
+	}
+	if msg := validateResidency(req.StorageRegion, req.DBRegion, req.DataResidency); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	if req.StatementTimeoutMs != nil && !types.IsValidStatementTimeoutMs(*req.StatementTimeoutMs) {
+		http.Error(w, statementTimeoutMessage, http.StatusBadRequest)
+		return
+	}
+
+	// Validate required fields; IAM auth types have no password, and the connector dials the
+	// instance instead of a host
+	if req.TenantID == "" || req.TenantName == "" ||
+		(req.DBHost == "" && req.DBAuthType != types.DBAuthConnector) ||
+		(req.DBPassword == "" && req.DBAuthType == types.DBAuthPassword) ||
+		(req.DBInstanceConnectionName == "" && req.DBAuthType == types.DBAuthConnector) ||
+		req.DBUser == "" || req.DBName == "" ||
 		req.SchemaPrefix == "" || req.AdapterType == "" {
 		http.Error(w, "Missing required fields", http.StatusBadRequest)
 		return
 	}
+	if !adapter.IsRegistered(req.AdapterType) {
+		http.Error(w, "adapterType must be one of: "+strings.Join(adapter.AdapterTypes, ", "), http.StatusBadRequest)
+		return
+	}
+	if err := sqlident.ValidateSchema(req.SchemaPrefix); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	// Set defaults
 	if req.DBPort == 0 {
@@ -156,11 +180,15 @@ func (api *API) createTenant(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Encrypt password before storing
-	encryptedPassword, err := crypto.EncryptPassword(req.DBPassword)
-	if err != nil {
-		logger.Errorf("Failed to encrypt password: %v", err)
-		http.Error(w, "Failed to encrypt credentials", http.StatusInternalServerError)
-		return
+	var encryptedPassword *string
+	if req.DBAuthType == types.DBAuthPassword {
+		encrypted, err := crypto.EncryptPassword(req.DBPassword)
+		if err != nil {
+			logger.Errorf("Failed to encrypt password: %v", err)
+			http.Error(w, "Failed to encrypt credentials", http.StatusInternalServerError)
+			return
+		}
+		encryptedPassword = &encrypted
 	}
 
 	// Insert tenant connection
@@ -169,52 +197,69 @@ func (api *API) createTenant(w http.ResponseWriter, r *http.Request) {
 			tenant_id, tenant_name, db_host, db_port, db_user, db_password,
 			db_name, db_sslmode, schema_prefix, adapter_type,
 			storage_provider, storage_bucket, storage_credentials_secret, storage_credentials_path,
+			storage_upload_credentials_secret, storage_upload_credentials_path,
+			storage_read_credentials_secret, storage_read_credentials_path,
+			storage_delete_credentials_secret, storage_delete_credentials_path,
 			docusign_integration_key, docusign_client_id, docusign_private_key_secret, docusign_api_url,
-			created_by, notes
+			created_by, notes, db_auth_type, db_instance_connection_name, id_version,
+			storage_region, db_region, data_residency, statement_timeout_ms
 		) VALUES (
-			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20
+			$1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20,
+			$21, $22, $23, $24, $25, $26, $27, $28, $29, $30, $31, $32, $33
 		) RETURNING id, created_at, updated_at
 	`
 
 	var tenantID uuid.UUID
 	var createdAt, updatedAt string
-	err = api.store.DB.QueryRow(
-		query,
-		req.TenantID,
-		req.TenantName,
-		req.DBHost,
-		req.DBPort,
-		req.DBUser,
-		encryptedPassword,
-		req.DBName,
-		req.DBSslMode,
-		req.SchemaPrefix,
-		req.AdapterType,
-		nullIfEmpty(req.StorageProvider),
-		nullIfEmpty(req.StorageBucket),
-		nullIfEmpty(req.StorageCredentialsSecret),
-		nullIfEmpty(req.StorageCredentialsPath),
-		nullIfEmpty(req.DocuSignIntegrationKey),
-		nullIfEmpty(req.DocuSignClientID),
-		nullIfEmpty(req.DocuSignPrivateKeySecret),
-		req.DocuSignAPIURL,
-		employee.Email,
-		req.Notes,
-	).Scan(&tenantID, &createdAt, &updatedAt)
+	err := api.store.ChangeTenantConfig(req.TenantID, types.TenantConfigActionCreate, &employee.ID, func(tx *sql.Tx) error {
+		return tx.QueryRow(
+			query,
+			req.TenantID,
+			req.TenantName,
+			req.DBHost,
+			req.DBPort,
+			req.DBUser,
+			encryptedPassword,
+			req.DBName,
+			req.DBSslMode,
+			req.SchemaPrefix,
+			req.AdapterType,
+			nullIfEmpty(req.StorageProvider),
+			nullIfEmpty(req.StorageBucket),
+			nullIfEmpty(req.StorageCredentialsSecret),
+			nullIfEmpty(req.StorageCredentialsPath),
+			nullIfEmpty(req.StorageUploadCredentialsSecret),
+			nullIfEmpty(req.StorageUploadCredentialsPath),
+			nullIfEmpty(req.StorageReadCredentialsSecret),
+			nullIfEmpty(req.StorageReadCredentialsPath),
+			nullIfEmpty(req.StorageDeleteCredentialsSecret),
+			nullIfEmpty(req.StorageDeleteCredentialsPath),
+			nullIfEmpty(req.DocuSignIntegrationKey),
+			nullIfEmpty(req.DocuSignClientID),
+			nullIfEmpty(req.DocuSignPrivateKeySecret),
+			req.DocuSignAPIURL,
+			employee.Email,
+			req.Notes,
+			req.DBAuthType,
+			nullIfEmpty(req.DBInstanceConnectionName),
+			req.IDVersion,
+			nullIfEmpty(req.StorageRegion),
+			nullIfEmpty(req.DBRegion),
+			nullIfEmpty(req.DataResidency),
+			req.StatementTimeoutMs,
+		).Scan(&tenantID, &createdAt, &updatedAt)
+	})
 
 	if err != nil {
 		logger.Errorf("Failed to create tenant: %v", err)
-		http.Error(w, "Failed to create tenant", http.StatusInternalServerError)
+		writeError(w, err, "Failed to create tenant")
 		return
 	}
 
 	logger.Infof("Created tenant %s (ID: %s) by %s", req.TenantID, tenantID, employee.Email)
 
 	// Automatically grant the creating employee admin access to this tenant
-	_, err = api.store.DB.Exec(`
-		INSERT INTO employee_tenant_access (employee_id, tenant_id, role, created_by)
-		VALUES ($1, $2, 'admin', $3)
-	`, employee.ID, req.TenantID, employee.ID)
+	_, _, err = api.store.AssignEmployeeToTenant(employee.ID, req.TenantID, types.RoleAdmin, employee.ID)
 
 	if err != nil {
 		logger.Errorf("Failed to grant tenant access to employee: %v", err)
@@ -241,29 +286,48 @@ func (api *API) createTenant(w http.ResponseWriter, r *http.Request) {
 
 // updateTenant updates an existing tenant connection (admin only)
 func (api *API) updateTenant(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
 	vars := mux.Vars(r)
 	tenantID := vars["tenantId"]
 
 	var req struct {
-		TenantName               string  `json:"tenantName"`
-		DBHost                   string  `json:"dbHost"`
-		DBPort                   int     `json:"dbPort"`
-		DBUser                   string  `json:"dbUser"`
-		DBPassword               *string `json:"dbPassword"` // Optional - only update if provided
-		DBName                   string  `json:"dbName"`
-		DBSslMode                string  `json:"dbSslMode"`
-		SchemaPrefix             string  `json:"schemaPrefix"`
-		AdapterType              string  `json:"adapterType"`
-		StorageProvider          string  `json:"storageProvider"`
-		StorageBucket            string  `json:"storageBucket"`
-		StorageCredentialsSecret string  `json:"storageCredentialsSecret"`
-		StorageCredentialsPath   string  `json:"storageCredentialsPath"`
-		DocuSignIntegrationKey   string  `json:"docusignIntegrationKey"`
-		DocuSignClientID         string  `json:"docusignClientId"`
-		DocuSignPrivateKeySecret string  `json:"docusignPrivateKeySecret"`
-		DocuSignAPIURL           string  `json:"docusignApiUrl"`
-		IsActive                 *bool   `json:"isActive"`
-		Notes                    *string `json:"notes"`
+		TenantName                     string  `json:"tenantName"`
+		DBHost                         string  `json:"dbHost"`
+		DBPort                         int     `json:"dbPort"`
+		DBUser                         string  `json:"dbUser"`
+		DBPassword                     *string `json:"dbPassword"` // Optional - only update if provided
+		DBName                         string  `json:"dbName"`
+		DBSslMode                      string  `json:"dbSslMode"`
+		DBAuthType                     string  `json:"dbAuthType"`
+		DBInstanceConnectionName       string  `json:"dbInstanceConnectionName"`
+		SchemaPrefix                   string  `json:"schemaPrefix"`
+		AdapterType                    string  `json:"adapterType"`
+		IDVersion                      string  `json:"idVersion"`
+		StorageProvider                string  `json:"storageProvider"`
+		StorageBucket                  string  `json:"storageBucket"`
+		StorageRegion                  string  `json:"storageRegion"`
+		DBRegion                       string  `json:"dbRegion"`
+		DataResidency                  string  `json:"dataResidency"`
+		StorageCredentialsSecret       string  `json:"storageCredentialsSecret"`
+		StorageCredentialsPath         string  `json:"storageCredentialsPath"`
+		StorageUploadCredentialsSecret string  `json:"storageUploadCredentialsSecret"`
+		StorageUploadCredentialsPath   string  `json:"storageUploadCredentialsPath"`
+		StorageReadCredentialsSecret   string  `json:"storageReadCredentialsSecret"`
+		StorageReadCredentialsPath     string  `json:"storageReadCredentialsPath"`
+		StorageDeleteCredentialsSecret string  `json:"storageDeleteCredentialsSecret"`
+		StorageDeleteCredentialsPath   string  `json:"storageDeleteCredentialsPath"`
+		DocuSignIntegrationKey         string  `json:"docusignIntegrationKey"`
+		DocuSignClientID               string  `json:"docusignClientId"`
+		DocuSignPrivateKeySecret       string  `json:"docusignPrivateKeySecret"`
+		DocuSignAPIURL                 string  `json:"docusignApiUrl"`
+		StatementTimeoutMs             *int    `json:"statementTimeoutMs"` // Optional - 0 restores the default
+		IsActive                       *bool   `json:"isActive"`
+		Notes                          *string `json:"notes"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -272,6 +336,33 @@ func (api *API) updateTenant(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.DBAuthType != "" && !types.IsValidDBAuthType(req.DBAuthType) {
+		http.Error(w, "dbAuthType must be password, iam or connector", http.StatusBadRequest)
+		return
+	}
+	if req.IDVersion != "" && !types.IsValidIDVersion(req.IDVersion) {
+		http.Error(w, "idVersion must be v7 or v4", http.StatusBadRequest)
+		return
+	}
+	if req.AdapterType != "" && !adapter.IsRegistered(req.AdapterType) {
+		http.Error(w, "adapterType must be one of: "+strings.Join(adapter.AdapterTypes, ", "), http.StatusBadRequest)
+		return
+	}
+	if req.SchemaPrefix != "" {
+		if err := sqlident.ValidateSchema(req.SchemaPrefix); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if msg := validateResidency(req.StorageRegion, req.DBRegion, req.DataResidency); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+	if req.StatementTimeoutMs != nil && *req.StatementTimeoutMs != 0 && !types.IsValidStatementTimeoutMs(*req.StatementTimeoutMs) {
+		http.Error(w, statementTimeoutMessage, http.StatusBadRequest)
+		return
+	}
+
 	// Build update query dynamically based on provided fields
 	query := `UPDATE tenant_connections SET updated_at = NOW()`
 	args := []interface{}{}
@@ -319,6 +410,29 @@ func (api *API) updateTenant(w http.ResponseWriter, r *http.Request) {
 		args = append(args, req.DBSslMode)
 		argIdx++
 	}
+	if req.DBAuthType != "" {
+		query += `, db_auth_type = $` + formatArgIdx(argIdx)
+		args = append(args, req.DBAuthType)
+		argIdx++
+		// Tenants moved to IAM auth no longer keep a password
+		if req.DBAuthType != types.DBAuthPassword && req.DBPassword == nil {
+			query += `, db_password = NULL`
+		}
+	}
+	if req.DBInstanceConnectionName != "" {
+		query += `, db_instance_connection_name = $` + formatArgIdx(argIdx)
+		args = append(args, req.DBInstanceConnectionName)
+		argIdx++
+	}
+	if req.StatementTimeoutMs != nil {
+		if *req.StatementTimeoutMs == 0 {
+			query += `, statement_timeout_ms = NULL`
+		} else {
+			query += `, statement_timeout_ms = $` + formatArgIdx(argIdx)
+			args = append(args, *req.StatementTimeoutMs)
+			argIdx++
+		}
+	}
 	if req.SchemaPrefix != "" {
 		query += `, schema_prefix = $` + formatArgIdx(argIdx)
 		args = append(args, req.SchemaPrefix)
@@ -329,6 +443,11 @@ func (api *API) updateTenant(w http.ResponseWriter, r *http.Request) {
 		args = append(args, req.AdapterType)
 		argIdx++
 	}
+	if req.IDVersion != "" {
+		query += `, id_version = $` + formatArgIdx(argIdx)
+		args = append(args, req.IDVersion)
+		argIdx++
+	}
 	if req.StorageProvider != "" {
 		query += `, storage_provider = $` + formatArgIdx(argIdx)
 		args = append(args, nullIfEmpty(req.StorageProvider))
@@ -339,6 +458,21 @@ func (api *API) updateTenant(w http.ResponseWriter, r *http.Request) {
 		args = append(args, nullIfEmpty(req.StorageBucket))
 		argIdx++
 	}
+	if req.StorageRegion != "" {
+		query += `, storage_region = $` + formatArgIdx(argIdx)
+		args = append(args, req.StorageRegion)
+		argIdx++
+	}
+	if req.DBRegion != "" {
+		query += `, db_region = $` + formatArgIdx(argIdx)
+		args = append(args, req.DBRegion)
+		argIdx++
+	}
+	if req.DataResidency != "" {
+		query += `, data_residency = $` + formatArgIdx(argIdx)
+		args = append(args, req.DataResidency)
+		argIdx++
+	}
 	if req.StorageCredentialsSecret != "" {
 		query += `, storage_credentials_secret = $` + formatArgIdx(argIdx)
 		args = append(args, nullIfEmpty(req.StorageCredentialsSecret))
@@ -349,6 +483,36 @@ func (api *API) updateTenant(w http.ResponseWriter, r *http.Request) {
 		args = append(args, nullIfEmpty(req.StorageCredentialsPath))
 		argIdx++
 	}
+	if req.StorageUploadCredentialsSecret != "" {
+		query += `, storage_upload_credentials_secret = $` + formatArgIdx(argIdx)
+		args = append(args, nullIfEmpty(req.StorageUploadCredentialsSecret))
+		argIdx++
+	}
+	if req.StorageUploadCredentialsPath != "" {
+		query += `, storage_upload_credentials_path = $` + formatArgIdx(argIdx)
+		args = append(args, nullIfEmpty(req.StorageUploadCredentialsPath))
+		argIdx++
+	}
+	if req.StorageReadCredentialsSecret != "" {
+		query += `, storage_read_credentials_secret = $` + formatArgIdx(argIdx)
+		args = append(args, nullIfEmpty(req.StorageReadCredentialsSecret))
+		argIdx++
+	}
+	if req.StorageReadCredentialsPath != "" {
+		query += `, storage_read_credentials_path = $` + formatArgIdx(argIdx)
+		args = append(args, nullIfEmpty(req.StorageReadCredentialsPath))
+		argIdx++
+	}
+	if req.StorageDeleteCredentialsSecret != "" {
+		query += `, storage_delete_credentials_secret = $` + formatArgIdx(argIdx)
+		args = append(args, nullIfEmpty(req.StorageDeleteCredentialsSecret))
+		argIdx++
+	}
+	if req.StorageDeleteCredentialsPath != "" {
+		query += `, storage_delete_credentials_path = $` + formatArgIdx(argIdx)
+		args = append(args, nullIfEmpty(req.StorageDeleteCredentialsPath))
+		argIdx++
+	}
 	if req.DocuSignIntegrationKey != "" {
 		query += `, docusign_integration_key = $` + formatArgIdx(argIdx)
 		args = append(args, nullIfEmpty(req.DocuSignIntegrationKey))
@@ -383,16 +547,13 @@ func (api *API) updateTenant(w http.ResponseWriter, r *http.Request) {
 	query += ` WHERE tenant_id = $` + formatArgIdx(argIdx)
 	args = append(args, tenantID)
 
-	result, err := api.store.DB.Exec(query, args...)
+	err := api.store.ChangeTenantConfig(tenantID, types.TenantConfigActionUpdate, &employee.ID, func(tx *sql.Tx) error {
+		_, err := tx.Exec(query, args...)
+		return err
+	})
 	if err != nil {
 		logger.Errorf("Failed to update tenant: %v", err)
-		http.Error(w, "Failed to update tenant", http.StatusInternalServerError)
-		return
-	}
-
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		http.Error(w, "Tenant not found", http.StatusNotFound)
+		writeError(w, err, "Failed to update tenant")
 		return
 	}
 
@@ -409,38 +570,49 @@ func (api *API) updateTenant(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// deleteTenant deactivates a tenant (admin only)
-func (api *API) deleteTenant(w http.ResponseWriter, r *http.Request) {
+// getTenantHistory returns a tenant's configuration changes, newest first (admin only)
+// Query params: limit (default 100, max 500)
+func (api *API) getTenantHistory(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	tenantID := vars["tenantId"]
 
-	logger.Infof("Deactivating tenant: %s", tenantID)
+	limit := 100
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed <= 0 {
+			http.Error(w, "Invalid limit parameter", http.StatusBadRequest)
+			return
+		}
+		limit = min(parsed, 500)
+	}
 
-	query := `UPDATE tenant_connections SET is_active = false, updated_at = NOW() WHERE tenant_id = $1`
-	result, err := api.store.DB.Exec(query, tenantID)
+	logger.Infof("Getting config history for tenant %s", tenantID)
+
+	history, err := api.store.GetTenantConfigHistory(tenantID, limit)
 	if err != nil {
-		logger.Errorf("Failed to deactivate tenant: %v", err)
-		http.Error(w, "Failed to deactivate tenant", http.StatusInternalServerError)
+		writeError(w, err, "Failed to get tenant history")
 		return
 	}
 
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		http.Error(w, "Tenant not found", http.StatusNotFound)
-		return
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(history); err != nil {
+		logger.Errorf("Failed to encode tenant history: %v", err)
 	}
+}
 
-	logger.Infof("Deactivated tenant: %s", tenantID)
-
-	response := map[string]interface{}{
-		"message":  "Tenant deactivated successfully",
-		"tenantId": tenantID,
+// validateResidency checks the region and residency fields of a tenant request, returning
+// what is wrong or "" when they are valid or not given
+func validateResidency(storageRegion, dbRegion, dataResidency string) string {
+	if storageRegion != "" && !types.IsValidRegion(storageRegion) {
+		return "storageRegion must be a region name such as us-east1, EU or eu-west-1"
 	}
-
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(response); err != nil {
-		logger.Errorf("Failed to encode response: %v", err)
+	if dbRegion != "" && !types.IsValidRegion(dbRegion) {
+		return "dbRegion must be a region name such as us-east1 or europe-west1"
+	}
+	if len(dataResidency) > 50 {
+		return "dataResidency must be at most 50 characters"
 	}
+	return ""
 }
 
 // Helper functions
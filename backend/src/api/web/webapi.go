@@ -3,10 +3,21 @@ package webapi
 import (
 	"context"
 	"net/http"
+	"sync/atomic"
+	"welltaxpro/src/internal/address"
+	"welltaxpro/src/internal/auditchain"
 	"welltaxpro/src/internal/auth"
+	"welltaxpro/src/internal/consistency"
+	"welltaxpro/src/internal/idcheck"
+	"welltaxpro/src/internal/ingest"
+	"welltaxpro/src/internal/mailing"
 	"welltaxpro/src/internal/middleware"
 	"welltaxpro/src/internal/notification"
-	"welltaxpro/src/internal/store"
+	"welltaxpro/src/internal/offboarding"
+	"welltaxpro/src/internal/payout"
+	"welltaxpro/src/internal/scan"
+	"welltaxpro/src/internal/sms"
+	"welltaxpro/src/internal/storage"
 	"welltaxpro/src/internal/types"
 
 	"github.com/gorilla/handlers"
@@ -23,28 +34,161 @@ type CORSConfig struct {
 type API struct {
 	context              context.Context
 	Router               *mux.Router
-	store                *store.Store
+	store                Store
+	auth                 *auth.Auth
+	firebaseUsers        auth.UserAdmin // auth unless replaced by a fake
 	authMiddleware       *middleware.AuthMiddleware
 	tenantUserAuthMiddleware *middleware.TenantUserAuthMiddleware
 	auditMiddleware      *middleware.AuditMiddleware
+	limitsMiddleware     *middleware.LimitsMiddleware
+	discountCheckCallerLimit *middleware.RateLimiter
+	discountCheckTenantLimit *middleware.RateLimiter
+	signatureMiddleware  *middleware.SignatureMiddleware
+	legalMiddleware      *middleware.LegalMiddleware
+	archiveMiddleware    *middleware.ArchiveMiddleware
+	debugCaptureMiddleware *middleware.DebugCaptureMiddleware
 	emailService         *notification.EmailService
+	addressValidator     address.Validator
+	idExtractor          idcheck.Extractor
+	scanner              scan.Scanner
+	mailer               mailing.Provider
+	texter               sms.Provider
+	payouts              payout.Provider
+	ingester             *ingest.Ingester
+	anchorer             *auditchain.Anchorer
+	offboarder           *offboarding.Offboarder
+	storageChecker       *consistency.Checker
+	inbound              InboundEmailConfig
+	links                notification.Links // web app URLs put in emails
+	notifier             *notification.Dispatcher
+	pushService          *notification.PushService
+	storageForTenant     func(context.Context, *types.TenantConnection) (storage.StorageProvider, error) // storage.NewStorageProviderForTenant unless replaced by a fake
+	filingCounts         filingCountCache
+	tenantStatuses       tenantStatusCache
+	draining             atomic.Bool // Set by Drain once the process is shutting down
 }
 
 // NewAPI creates and returns a new API instance
-func NewAPI(ctx context.Context, s *store.Store, authClient *auth.Auth, emailService *notification.EmailService) *API {
-	authMw := middleware.NewAuthMiddleware(authClient, s)
-	tenantUserAuthMw := middleware.NewTenantUserAuthMiddleware(authClient)
-	auditMw := middleware.NewAuditMiddleware(s)
+func NewAPI(ctx context.Context, s Store, authClient *auth.Auth, emailService *notification.EmailService, addressValidator address.Validator, idExtractor idcheck.Extractor, scanner scan.Scanner, mailer mailing.Provider, texter sms.Provider, payouts payout.Provider, notifier *notification.Dispatcher, ingester *ingest.Ingester, anchorer *auditchain.Anchorer, inbound InboundEmailConfig, links notification.Links, routeLimits middleware.RouteLimits, debugRedactFields []string) *API {
+	api := newAPI(ctx, s, authClient, authClient, routeLimits, debugRedactFields)
+	api.auth = authClient
+	api.emailService = emailService
+	api.addressValidator = addressValidator
+	api.idExtractor = idExtractor
+	api.scanner = scanner
+	api.mailer = mailer
+	api.texter = texter
+	api.payouts = payouts
+	api.ingester = ingester
+	api.anchorer = anchorer
+	api.inbound = inbound
+	api.links = links
+	api.notifier = notifier
+	api.pushService = notification.NewPushService(ctx, authClient.App, s)
 
-	return &API{
+	return api
+}
+
+// newAPI creates an API whose middleware reads s and verifies tokens with verifier, without the
+// outside services NewAPI adds; tests build the API from fakes with it
+func newAPI(ctx context.Context, s Store, verifier middleware.TokenVerifier, users auth.UserAdmin, routeLimits middleware.RouteLimits, debugRedactFields []string) *API {
+	api := &API{
 		context:              ctx,
 		Router:               mux.NewRouter(),
 		store:                s,
-		authMiddleware:       authMw,
-		tenantUserAuthMiddleware: tenantUserAuthMw,
-		auditMiddleware:      auditMw,
-		emailService:         emailService,
+		firebaseUsers:        users,
+		authMiddleware:       middleware.NewAuthMiddleware(verifier, s),
+		tenantUserAuthMiddleware: middleware.NewTenantUserAuthMiddleware(verifier, s),
+		auditMiddleware:      middleware.NewAuditMiddleware(s),
+		signatureMiddleware:  middleware.NewSignatureMiddleware(s),
+		legalMiddleware:      middleware.NewLegalMiddleware(s),
+		archiveMiddleware:    middleware.NewArchiveMiddleware(s),
+		debugCaptureMiddleware: middleware.NewDebugCaptureMiddleware(s, debugRedactFields),
+		offboarder:           offboarding.New(s),
+		storageForTenant:     storage.NewStorageProviderForTenant,
+	}
+	api.storageChecker = consistency.New(s, api.storageForTenant)
+	api.limitsMiddleware = middleware.NewLimitsMiddleware(routeLimits, routeClass)
+	api.discountCheckCallerLimit = middleware.NewRateLimiter(discountCheckCallerRate, middleware.TenantCallerKey)
+	api.discountCheckTenantLimit = middleware.NewRateLimiter(discountCheckTenantRate, middleware.TenantKey)
+
+	return api
+}
+
+// The public discount code check is limited per caller, to slow code guessing, and per
+// tenant, to cap the lookups one widget can cause
+var (
+	discountCheckCallerRate = middleware.RateLimit{PerMinute: 20, Burst: 10}
+	discountCheckTenantRate = middleware.RateLimit{PerMinute: 600, Burst: 100}
+)
+
+// uploadRoutes accept multipart file uploads ("METHOD path template")
+var uploadRoutes = map[string]bool{
+	http.MethodPost + " /api/v1/{tenantId}/filings/{filingId}/documents":      true,
+	http.MethodPost + " /api/v1/{tenantId}/user/identity-documents":           true,
+	http.MethodPost + " /api/v1/{tenantId}/user/filings/{filingId}/documents": true,
+	http.MethodPost + " /api/v1/inbound/email":                                true, // inbound parse posts attachments as multipart
+}
+
+// streamRoutes can stream their response with ?stream=, which lifts the buffered API deadline
+var streamRoutes = map[string]bool{
+	http.MethodGet + " /api/v1/{tenantId}/clients": true,
+	http.MethodGet + " /api/v1/{tenantId}/filings": true,
+}
+
+// fileRoutes write a stored file straight to the client, so they always take the stream class
+var fileRoutes = map[string]bool{
+	http.MethodGet + " /api/v1/{tenantId}/user/documents/{documentId}/view": true,
+}
+
+// publicRoutes are served without authentication
+var publicRoutes = map[string]bool{
+	http.MethodGet + " /health":                                                              true,
+	http.MethodGet + " /health/live":                                                         true,
+	http.MethodGet + " /health/ready":                                                        true,
+	http.MethodGet + " /api/v1/{tenantId}/status":                                            true,
+	http.MethodGet + " /api/v1/{tenantId}/affiliates/{affiliateId}/dashboard":                true,
+	http.MethodGet + " /api/v1/{tenantId}/affiliates/{affiliateId}/stats":                    true,
+	http.MethodGet + " /api/v1/{tenantId}/affiliates/{affiliateId}/commissions":              true,
+	http.MethodGet + " /api/v1/{tenantId}/affiliates/{affiliateId}/statement":                true,
+	http.MethodPost + " /api/v1/{tenantId}/affiliates/{affiliateId}/clicks":                  true,
+	http.MethodGet + " /api/v1/{tenantId}/affiliates/{affiliateId}/notifications":            true,
+	http.MethodPost + " /api/v1/{tenantId}/affiliates/{affiliateId}/notifications/read":      true,
+	http.MethodGet + " /api/v1/{tenantId}/affiliates/{affiliateId}/notification-preferences": true,
+	http.MethodPut + " /api/v1/{tenantId}/affiliates/{affiliateId}/notification-preferences": true,
+	http.MethodPost + " /api/v1/email/events":                                                true,
+	http.MethodPost + " /api/v1/sms/status":                                                  true,
+	http.MethodPost + " /api/v1/mailing/webhook":                                             true,
+	http.MethodPost + " /api/v1/{tenantId}/signature/webhook":                                true,
+	http.MethodPost + " /api/v1/{tenantId}/payments/webhook":                                 true,
+	http.MethodGet + " /api/v1/{tenantId}/checkout/discount-codes/validate":                  true,
+	http.MethodPost + " /api/v1/auth/login":                                                  true,
+	http.MethodPost + " /api/v1/auth/refresh":                                                true,
+	http.MethodPost + " /api/v1/auth/logout":                                                 true,
+	http.MethodPost + " /api/v1/employees":                                                   true,
+}
+
+// routeClass picks the body size and timeout class of the matched route
+func routeClass(r *http.Request) middleware.RouteClass {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return middleware.RouteClassAPI
 	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return middleware.RouteClassAPI
+	}
+
+	key := r.Method + " " + template
+	switch {
+	case uploadRoutes[key]:
+		return middleware.RouteClassUpload
+	case publicRoutes[key]:
+		return middleware.RouteClassPublic
+	case fileRoutes[key], streamRoutes[key] && r.URL.Query().Get("stream") != "":
+		return middleware.RouteClassStream
+	}
+	return middleware.RouteClassAPI
 }
 
 // CORSHandler wraps the router with CORS middleware
@@ -57,12 +201,13 @@ func (api *API) CORSHandler(corsConfig CORSConfig) http.Handler {
 
 	allowedMethods := corsConfig.AllowedMethods
 	if len(allowedMethods) == 0 {
-		allowedMethods = []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"}
+		allowedMethods = []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
 	}
 
 	allowedHeaders := corsConfig.AllowedHeaders
 	if len(allowedHeaders) == 0 {
-		allowedHeaders = []string{"Content-Type", "Authorization"}
+		allowedHeaders = []string{"Content-Type", "Authorization", types.TenantContextHeader,
+			types.SignatureHeaderKeyID, types.SignatureHeaderTimestamp, types.SignatureHeaderNonce, types.SignatureHeaderSignature}
 	}
 
 	corsOptions := []handlers.CORSOption{
@@ -86,16 +231,30 @@ func (api *API) CORSHandler(corsConfig CORSConfig) http.Handler {
 		w.Header().Set("Referrer-Policy", "strict-origin-when-cross-origin")
 		w.Header().Set("Content-Security-Policy", "default-src 'self'")
 
-		// Apply CORS handler
-		corsHandler(api.Router).ServeHTTP(w, r)
+		// Apply CORS handler; responses are gzip/deflate compressed when the client accepts it.
+		// /api/v1/current/ requests are routed to the tenant of their tenant context.
+		handlers.CompressHandler(corsHandler(api.currentTenantHandler(api.Router))).ServeHTTP(w, r)
 	})
 }
 
 // InitRoutes initializes the routes and handlers
 func (api *API) InitRoutes() {
+	// Request tracing, first so requests the limits reject are traced too
+	api.Router.Use(middleware.Trace)
+
+	// Body size limits and handler deadlines per route class
+	api.Router.Use(api.limitsMiddleware.Enforce)
+
+	// Request/response capture for tenants with a running debug capture
+	api.Router.Use(api.debugCaptureMiddleware.Capture)
+
 	// Health check (no auth required)
 	api.Router.HandleFunc("/health", api.healthCheck).Methods(http.MethodGet)
 
+	// Kubernetes liveness and readiness probes (no auth required)
+	api.Router.HandleFunc("/health/live", api.liveness).Methods(http.MethodGet)
+	api.Router.HandleFunc("/health/ready", api.readiness).Methods(http.MethodGet)
+
 	// Tenant management endpoints (admin only)
 	api.Router.Handle("/api/v1/admin/tenants",
 		api.authMiddleware.Authenticate(
@@ -113,6 +272,24 @@ func (api *API) InitRoutes() {
 		),
 	).Methods(http.MethodPost)
 
+	// Tenant connection pools of this process; registered before /admin/tenants/{tenantId}
+	// so "connections" is not taken for a tenant ID (admin only)
+	api.Router.Handle("/api/v1/admin/tenants/connections",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getTenantPools),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/admin/tenants/connections/{tenantId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.closeTenantPool),
+			),
+		),
+	).Methods(http.MethodDelete)
+
 	api.Router.Handle("/api/v1/admin/tenants/{tenantId}",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
@@ -129,6 +306,15 @@ func (api *API) InitRoutes() {
 		),
 	).Methods(http.MethodPut)
 
+	// Deactivating a tenant takes a confirmation token from its deletion request (admin only)
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/deletion-request",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.requestTenantDeletion),
+			),
+		),
+	).Methods(http.MethodPost)
+
 	api.Router.Handle("/api/v1/admin/tenants/{tenantId}",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
@@ -137,337 +323,2363 @@ func (api *API) InitRoutes() {
 		),
 	).Methods(http.MethodDelete)
 
-	// Employee management endpoints
-	// Create employee (public endpoint for user signup)
-	api.Router.HandleFunc("/api/v1/employees", api.createEmployee).Methods(http.MethodPost)
+	// Public tenant status page data (no auth required, cached)
+	api.Router.HandleFunc("/api/v1/{tenantId}/status", api.getTenantStatus).Methods(http.MethodGet)
 
-	// Get all employees (admin only)
-	api.Router.Handle("/api/v1/employees",
+	// Maintenance windows shown on the tenant status page (admin only)
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/maintenance-windows",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.getAllEmployees),
+				http.HandlerFunc(api.getMaintenanceWindows),
 			),
 		),
 	).Methods(http.MethodGet)
 
-	// Get current employee info (requires auth)
-	api.Router.Handle("/api/v1/employees/me",
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/maintenance-windows",
 		api.authMiddleware.Authenticate(
-			http.HandlerFunc(api.getMe),
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.createMaintenanceWindow),
+			),
 		),
-	).Methods(http.MethodGet)
+	).Methods(http.MethodPost)
 
-	// Update current employee info (requires auth)
-	api.Router.Handle("/api/v1/employees/me",
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/maintenance-windows/{windowId}",
 		api.authMiddleware.Authenticate(
-			http.HandlerFunc(api.updateEmployee),
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.deleteMaintenanceWindow),
+			),
 		),
-	).Methods(http.MethodPut)
+	).Methods(http.MethodDelete)
 
-	// Get current employee's tenant access (requires auth)
-	api.Router.Handle("/api/v1/employees/me/tenants",
+	// Tenant offboarding workflow, driven one step at a time (admin only)
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/offboarding",
 		api.authMiddleware.Authenticate(
-			http.HandlerFunc(api.getEmployeeTenants),
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getTenantOffboarding),
+			),
 		),
 	).Methods(http.MethodGet)
 
-	// Get employee by ID (admin only)
-	api.Router.Handle("/api/v1/employees/{employeeId}",
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/offboarding",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.getEmployeeByID),
+				http.HandlerFunc(api.startTenantOffboarding),
 			),
 		),
-	).Methods(http.MethodGet)
+	).Methods(http.MethodPost)
 
-	// Assign employee to tenant (admin only)
-	api.Router.Handle("/api/v1/employees/{employeeId}/tenants",
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/offboarding/steps/{step}",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.assignEmployeeToTenant),
+				http.HandlerFunc(api.runTenantOffboardingStep),
 			),
 		),
 	).Methods(http.MethodPost)
 
-	// Remove employee from tenant (admin only)
-	api.Router.Handle("/api/v1/employees/{employeeId}/tenants/{tenantId}",
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/offboarding/cancel",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.removeEmployeeFromTenant),
+				http.HandlerFunc(api.cancelTenantOffboarding),
 			),
 		),
-	).Methods(http.MethodDelete)
+	).Methods(http.MethodPost)
 
-	// Admin API for tenant clients (auth + audit required)
-	api.Router.Handle("/api/v1/{tenantId}/clients",
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/offboarding/report",
 		api.authMiddleware.Authenticate(
-			api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceClient)(
-				http.HandlerFunc(api.getClients),
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getTenantOffboardingReport),
 			),
 		),
 	).Methods(http.MethodGet)
 
-	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}",
+	// Debug capture of a tenant's API traffic (admin only)
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/debug-capture",
 		api.authMiddleware.Authenticate(
-			api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceClient)(
-				http.HandlerFunc(api.getClient),
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getDebugCapture),
 			),
 		),
 	).Methods(http.MethodGet)
 
-	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/comprehensive",
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/debug-capture",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.enableDebugCapture),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/debug-capture",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.disableDebugCapture),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/debug-capture/entries",
 		api.authMiddleware.Authenticate(
-			api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceClient)(
-				http.HandlerFunc(api.getClientComprehensive),
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getDebugCaptureEntries),
 			),
 		),
 	).Methods(http.MethodGet)
 
-	// Filings endpoint (filtered by status/year)
-	api.Router.Handle("/api/v1/{tenantId}/filings",
+	// Employees with access to a tenant (admin only)
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/employees",
 		api.authMiddleware.Authenticate(
-			api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceClient)(
-				http.HandlerFunc(api.getFilings),
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getTenantEmployees),
 			),
 		),
 	).Methods(http.MethodGet)
 
-	// Admin affiliate management (auth + admin required)
-	api.Router.Handle("/api/v1/{tenantId}/affiliates",
+	// Tenant configuration changelog (admin only)
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/history",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.getAffiliates),
+				http.HandlerFunc(api.getTenantHistory),
 			),
 		),
 	).Methods(http.MethodGet)
 
-	api.Router.Handle("/api/v1/{tenantId}/affiliates",
+	// Tenant inbound email address (admin only)
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/inbound-address",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getInboundAddress),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/inbound-address",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.createAffiliate),
+				http.HandlerFunc(api.rotateInboundAddress),
 			),
 		),
 	).Methods(http.MethodPost)
 
-	api.Router.Handle("/api/v1/{tenantId}/affiliates/{affiliateId}",
+	// Tenant mailing return address (admin only)
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/mail-settings",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.getAffiliate),
+				http.HandlerFunc(api.getMailSettings),
 			),
 		),
 	).Methods(http.MethodGet)
 
-	api.Router.Handle("/api/v1/{tenantId}/affiliates/{affiliateId}",
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/mail-settings",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.updateAffiliate),
+				http.HandlerFunc(api.setMailSettings),
 			),
 		),
 	).Methods(http.MethodPut)
 
-	api.Router.Handle("/api/v1/{tenantId}/affiliates/{affiliateId}/generate-token",
+	// Cross-tenant operations dashboard (admin only)
+	api.Router.Handle("/api/v1/admin/overview",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.generateAffiliateToken),
+				http.HandlerFunc(api.getAdminOverview),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Post-deploy smoke test against the built-in smoke tenant (admin only)
+	api.Router.Handle("/api/v1/admin/smoke-test",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.runSmokeTest),
 			),
 		),
 	).Methods(http.MethodPost)
 
-	api.Router.Handle("/api/v1/{tenantId}/affiliates/{affiliateId}/tokens",
+	// Email send queue depth and counters (admin only)
+	api.Router.Handle("/api/v1/admin/email-queue",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.getAffiliateTokens),
+				http.HandlerFunc(api.getEmailQueueStats),
 			),
 		),
 	).Methods(http.MethodGet)
 
-	api.Router.Handle("/api/v1/{tenantId}/affiliates/{affiliateId}/tokens/{tokenId}",
+	// Queued and sent client emails with their delivery status (admin only)
+	api.Router.Handle("/api/v1/admin/emails",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.revokeAffiliateToken),
+				http.HandlerFunc(api.getOutboxEmails),
 			),
 		),
-	).Methods(http.MethodDelete)
+	).Methods(http.MethodGet)
 
-	api.Router.Handle("/api/v1/{tenantId}/commissions",
+	// Addresses queued email is not sent to (admin only)
+	api.Router.Handle("/api/v1/admin/email-suppressions",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.getCommissions),
+				http.HandlerFunc(api.getEmailSuppressions),
 			),
 		),
 	).Methods(http.MethodGet)
 
-	api.Router.Handle("/api/v1/{tenantId}/commissions/{commissionId}/approve",
+	api.Router.Handle("/api/v1/admin/email-suppressions",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.approveCommission),
+				http.HandlerFunc(api.addEmailSuppression),
 			),
 		),
-	).Methods(http.MethodPut)
+	).Methods(http.MethodPost)
 
-	api.Router.Handle("/api/v1/{tenantId}/commissions/{commissionId}/mark-paid",
+	api.Router.Handle("/api/v1/admin/email-suppressions/{email}",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.markCommissionPaid),
+				http.HandlerFunc(api.deleteEmailSuppression),
 			),
 		),
-	).Methods(http.MethodPut)
+	).Methods(http.MethodDelete)
 
-	api.Router.Handle("/api/v1/{tenantId}/commissions/{commissionId}/cancel",
+	// Firebase accounts with no employee or portal user, from the nightly reconciliation (admin only)
+	api.Router.Handle("/api/v1/admin/firebase-users/orphans",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.cancelCommission),
+				http.HandlerFunc(api.getFirebaseOrphans),
 			),
 		),
-	).Methods(http.MethodPut)
+	).Methods(http.MethodGet)
 
-	// Discount code management (admin only)
-	api.Router.Handle("/api/v1/{tenantId}/discount-codes",
+	// Tenant schema validation against adapter expectations (admin only)
+	api.Router.Handle("/api/v1/admin/schema-checks",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.getDiscountCodes),
+				http.HandlerFunc(api.getSchemaChecks),
 			),
 		),
 	).Methods(http.MethodGet)
 
-	api.Router.Handle("/api/v1/{tenantId}/discount-codes",
+	api.Router.Handle("/api/v1/admin/schema-checks",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.createDiscountCode),
+				http.HandlerFunc(api.runSchemaChecks),
 			),
 		),
 	).Methods(http.MethodPost)
 
-	api.Router.Handle("/api/v1/{tenantId}/discount-codes/validate",
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/schema-check",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.validateDiscountCode),
+				http.HandlerFunc(api.getSchemaCheck),
 			),
 		),
 	).Methods(http.MethodGet)
 
-	api.Router.Handle("/api/v1/{tenantId}/discount-codes/{codeId}",
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/schema-check",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.getDiscountCode),
+				http.HandlerFunc(api.runSchemaCheck),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	// Document records checked against the files in tenant buckets (admin only)
+	api.Router.Handle("/api/v1/admin/storage-checks",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getStorageChecks),
 			),
 		),
 	).Methods(http.MethodGet)
 
-	api.Router.Handle("/api/v1/{tenantId}/discount-codes/{codeId}",
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/storage-check",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.updateDiscountCode),
+				http.HandlerFunc(api.getStorageCheck),
 			),
 		),
-	).Methods(http.MethodPut)
+	).Methods(http.MethodGet)
 
-	api.Router.Handle("/api/v1/{tenantId}/discount-codes/{codeId}/deactivate",
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/storage-check",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.deactivateDiscountCode),
+				http.HandlerFunc(api.runStorageCheck),
 			),
 		),
-	).Methods(http.MethodPut)
+	).Methods(http.MethodPost)
 
-	// Document management endpoints (admin only with audit)
-	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/documents",
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/storage-issues/{issueId}/resolve",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				api.auditMiddleware.LogAccess(types.AuditActionUpload, types.AuditResourceDocument)(
-					http.HandlerFunc(api.uploadDocument),
+				api.auditMiddleware.LogAccess(types.AuditActionEdit, types.AuditResourceDocument)(
+					http.HandlerFunc(api.resolveStorageIssue),
 				),
 			),
 		),
 	).Methods(http.MethodPost)
 
-	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/documents",
+	// Tenant connection diagnostics: database, schema, storage and DocuSign (admin only)
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/test-connection",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceDocument)(
-					http.HandlerFunc(api.getDocuments),
-				),
+				http.HandlerFunc(api.testTenantConnection),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	// Data residency of a tenant for compliance documentation (admin only)
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/residency",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getTenantResidency),
 			),
 		),
 	).Methods(http.MethodGet)
 
-	api.Router.Handle("/api/v1/{tenantId}/documents/{documentId}/download",
+	// Audit log hash chain verification against WORM anchors (admin only)
+	api.Router.Handle("/api/v1/admin/audit-chain/verify",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				api.auditMiddleware.LogAccess(types.AuditActionDownload, types.AuditResourceDocument)(
-					http.HandlerFunc(api.downloadDocument),
-				),
+				http.HandlerFunc(api.verifyAuditChain),
 			),
 		),
 	).Methods(http.MethodGet)
 
-	api.Router.Handle("/api/v1/{tenantId}/documents/{documentId}",
+	// Employee × tenant access overview for access reviews (admin only)
+	api.Router.Handle("/api/v1/admin/access-matrix",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				api.auditMiddleware.LogAccess(types.AuditActionDelete, types.AuditResourceDocument)(
-					http.HandlerFunc(api.deleteDocument),
-				),
+				http.HandlerFunc(api.getAccessMatrix),
 			),
 		),
-	).Methods(http.MethodDelete)
+	).Methods(http.MethodGet)
 
-	// Signature endpoints (admin only)
-	api.Router.Handle("/api/v1/{tenantId}/signature/send",
+	// Access review campaigns (admin only)
+	api.Router.Handle("/api/v1/admin/access-reviews",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.sendSignatureRequest),
+				http.HandlerFunc(api.getAccessReviews),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/admin/access-reviews",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.createAccessReview),
 			),
 		),
 	).Methods(http.MethodPost)
 
-	// Filing management endpoints (admin only)
-	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/complete",
+	api.Router.Handle("/api/v1/admin/access-reviews/{reviewId}",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.markFilingCompleted),
+				http.HandlerFunc(api.getAccessReview),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/admin/access-reviews/{reviewId}/items/{itemId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.decideAccessReviewItem),
 			),
 		),
 	).Methods(http.MethodPut)
 
-	// Tenant User Portal endpoints (Firebase-authenticated client access)
-	// Auto-register tenant user on first sign-in (requires Firebase auth)
-	api.Router.Handle("/api/v1/{tenantId}/user/register",
-		api.tenantUserAuthMiddleware.Authenticate(
-			http.HandlerFunc(api.autoRegisterTenantUser),
+	api.Router.Handle("/api/v1/admin/access-reviews/{reviewId}/complete",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.completeAccessReview),
+			),
 		),
 	).Methods(http.MethodPost)
 
-	// Manual registration by admin (admin only) - links Firebase UID to client record
-	api.Router.Handle("/api/v1/{tenantId}/users/register",
+	api.Router.Handle("/api/v1/admin/access-reviews/{reviewId}/report",
 		api.authMiddleware.Authenticate(
 			api.authMiddleware.RequireAdmin(
-				http.HandlerFunc(api.registerTenantUser),
+				http.HandlerFunc(api.exportAccessReview),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Platform announcements shown as banners to tenant admins (admin only)
+	api.Router.Handle("/api/v1/admin/announcements",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getAnnouncements),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/admin/announcements",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.createAnnouncement),
 			),
 		),
 	).Methods(http.MethodPost)
 
-	// Get tenant user's own profile and data (requires Firebase auth, tenant user only)
-	api.Router.Handle("/api/v1/{tenantId}/user/profile",
-		api.tenantUserAuthMiddleware.Authenticate(
-			http.HandlerFunc(api.getTenantUserProfile),
+	api.Router.Handle("/api/v1/admin/announcements/{announcementId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.updateAnnouncement),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/admin/announcements/{announcementId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.deleteAnnouncement),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	api.Router.Handle("/api/v1/admin/announcements/{announcementId}/acknowledgments",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getAnnouncementAcknowledgments),
+			),
 		),
 	).Methods(http.MethodGet)
 
-	// Download tenant user's own document (requires Firebase auth, tenant user only)
-	api.Router.Handle("/api/v1/{tenantId}/user/documents/{documentId}/download",
-		api.tenantUserAuthMiddleware.Authenticate(
-			http.HandlerFunc(api.downloadTenantUserDocument),
+	// Break-glass emergency tenant access (admin only)
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/break-glass",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.requestBreakGlass),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/admin/break-glass",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getBreakGlassGrants),
+			),
 		),
 	).Methods(http.MethodGet)
 
-	// Public affiliate endpoints (token-based, no Firebase auth)
-	api.Router.HandleFunc("/api/v1/{tenantId}/affiliates/{affiliateId}/dashboard", api.getAffiliateDashboard).Methods(http.MethodGet)
-	api.Router.HandleFunc("/api/v1/{tenantId}/affiliates/{affiliateId}/stats", api.getAffiliateStatsPublic).Methods(http.MethodGet)
-	api.Router.HandleFunc("/api/v1/{tenantId}/affiliates/{affiliateId}/commissions", api.getAffiliateCommissionsPublic).Methods(http.MethodGet)
-}
+	api.Router.Handle("/api/v1/admin/break-glass/{grantId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.revokeBreakGlass),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/fraud-rules",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getFraudRules),
+			),
+		),
+	).Methods(http.MethodGet)
 
-// healthCheck returns 200 OK if service is running
-func (api *API) healthCheck(w http.ResponseWriter, r *http.Request) {
-	w.WriteHeader(http.StatusOK)
-	w.Write([]byte(`{"status":"ok"}`))
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/fraud-rules",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.updateFraudRules),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/commission-sla",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getCommissionSLA),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/commission-sla",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.updateCommissionSLA),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	// Portal session length, sign-in verification and concurrent session limits
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/portal-security",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getPortalSecurityPolicy),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/portal-security",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.updatePortalSecurityPolicy),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	// Texting portal links to clients and the daily limit per client
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/sms",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getSMSSettings),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/sms",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.updateSMSSettings),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	// Plain-language filing status texts clients see in the portal
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/filing-status-texts",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getFilingStatusTexts),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/filing-status-texts",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.updateFilingStatusTexts),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	// Deactivating affiliate dashboard tokens that have gone unused
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/affiliate-token-policy",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getAffiliateTokenPolicy),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/affiliate-token-policy",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.updateAffiliateTokenPolicy),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	// DocuSign Connect HMAC key for signature status events
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/docusign-connect-secret",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.updateDocuSignConnectSecret),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	// Stripe webhook signing secret for checkout payments
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/stripe-webhook-secret",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.updateStripeWebhookSecret),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	// Request signing keys for public tracking endpoints
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/signing-keys",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getSigningKeys),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/signing-keys",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.createSigningKey),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/signing-keys/{keyId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.revokeSigningKey),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	// Per-tenant SSN data keys
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/ssn-keys",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getSSNKeys),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/admin/tenants/{tenantId}/ssn-keys/rotate",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.rotateSSNKey),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	// Employee sessions; login, refresh and logout are public
+	api.Router.HandleFunc("/api/v1/auth/login", api.login).Methods(http.MethodPost)
+	api.Router.HandleFunc("/api/v1/auth/refresh", api.refreshSession).Methods(http.MethodPost)
+	api.Router.HandleFunc("/api/v1/auth/logout", api.logout).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/auth/sessions",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getEmployeeSessions),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/auth/sessions",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.revokeEmployeeSessions),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	api.Router.Handle("/api/v1/auth/sessions/{sessionId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.revokeEmployeeSession),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	// Employee management endpoints
+	// Create employee (public endpoint for user signup)
+	api.Router.HandleFunc("/api/v1/employees", api.createEmployee).Methods(http.MethodPost)
+
+	// Get all employees (admin only)
+	api.Router.Handle("/api/v1/employees",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getAllEmployees),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Get current employee info (requires auth)
+	api.Router.Handle("/api/v1/employees/me",
+		api.authMiddleware.Authenticate(
+			http.HandlerFunc(api.getMe),
+		),
+	).Methods(http.MethodGet)
+
+	// Update current employee info (requires auth)
+	api.Router.Handle("/api/v1/employees/me",
+		api.authMiddleware.Authenticate(
+			http.HandlerFunc(api.updateEmployee),
+		),
+	).Methods(http.MethodPut)
+
+	// Get current employee's tenant access (requires auth)
+	api.Router.Handle("/api/v1/employees/me/tenants",
+		api.authMiddleware.Authenticate(
+			http.HandlerFunc(api.getEmployeeTenants),
+		),
+	).Methods(http.MethodGet)
+
+	// Select the tenant the current employee works in (requires auth)
+	api.Router.Handle("/api/v1/employees/me/context",
+		api.authMiddleware.Authenticate(
+			http.HandlerFunc(api.createTenantContext),
+		),
+	).Methods(http.MethodPost)
+
+	// Get the current employee's selected tenant context (requires auth)
+	api.Router.Handle("/api/v1/employees/me/context",
+		api.authMiddleware.Authenticate(
+			http.HandlerFunc(api.getTenantContext),
+		),
+	).Methods(http.MethodGet)
+
+	// End the current employee's selected tenant context (requires auth)
+	api.Router.Handle("/api/v1/employees/me/context",
+		api.authMiddleware.Authenticate(
+			http.HandlerFunc(api.deleteTenantContext),
+		),
+	).Methods(http.MethodDelete)
+
+	// Announcements showing now to the current employee, polled by the frontends (requires auth)
+	api.Router.Handle("/api/v1/announcements",
+		api.authMiddleware.Authenticate(
+			http.HandlerFunc(api.getActiveAnnouncements),
+		),
+	).Methods(http.MethodGet)
+
+	// Dismiss an announcement for the current employee (requires auth)
+	api.Router.Handle("/api/v1/announcements/{announcementId}/acknowledgments",
+		api.authMiddleware.Authenticate(
+			http.HandlerFunc(api.acknowledgeAnnouncement),
+		),
+	).Methods(http.MethodPost)
+
+	// Get current employee's notification preferences (requires auth)
+	api.Router.Handle("/api/v1/employees/me/notification-preferences",
+		api.authMiddleware.Authenticate(
+			http.HandlerFunc(api.getNotificationPreferences),
+		),
+	).Methods(http.MethodGet)
+
+	// Update current employee's notification preferences (requires auth)
+	api.Router.Handle("/api/v1/employees/me/notification-preferences",
+		api.authMiddleware.Authenticate(
+			http.HandlerFunc(api.updateNotificationPreferences),
+		),
+	).Methods(http.MethodPut)
+
+	// Current employee's Slack member ID for notification direct messages (requires auth)
+	api.Router.Handle("/api/v1/employees/me/notification-channels",
+		api.authMiddleware.Authenticate(
+			http.HandlerFunc(api.getNotificationChannels),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/employees/me/notification-channels",
+		api.authMiddleware.Authenticate(
+			http.HandlerFunc(api.updateNotificationChannels),
+		),
+	).Methods(http.MethodPut)
+
+	// Current employee's staff console notifications (requires auth)
+	api.Router.Handle("/api/v1/employees/me/notifications",
+		api.authMiddleware.Authenticate(
+			http.HandlerFunc(api.getEmployeeNotifications),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/employees/me/notifications/read",
+		api.authMiddleware.Authenticate(
+			http.HandlerFunc(api.markEmployeeNotificationsRead),
+		),
+	).Methods(http.MethodPost)
+
+	// Get employee by ID (admin only)
+	api.Router.Handle("/api/v1/employees/{employeeId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getEmployeeByID),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Change employee role (admin only)
+	api.Router.Handle("/api/v1/employees/{employeeId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.updateEmployeeRole),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	// Deactivate employee and sign them out of Firebase (admin only)
+	api.Router.Handle("/api/v1/employees/{employeeId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.deactivateEmployee),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	// Assign employee to tenant (admin only)
+	api.Router.Handle("/api/v1/employees/{employeeId}/tenants",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.assignEmployeeToTenant),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	// Remove employee from tenant (admin only)
+	api.Router.Handle("/api/v1/employees/{employeeId}/tenants/{tenantId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.removeEmployeeFromTenant),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	// Admin API for tenant clients (auth + audit required)
+	api.Router.Handle("/api/v1/{tenantId}/clients",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceClient)(
+						http.HandlerFunc(api.getClients),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Registered before /clients/{clientId} so "search" is not taken for a client ID
+	api.Router.Handle("/api/v1/{tenantId}/clients/search",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceClient)(
+						http.HandlerFunc(api.searchClients),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceClient)(
+						http.HandlerFunc(api.getClient),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/archive",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionEdit, types.AuditResourceClient)(
+					http.HandlerFunc(api.archiveClient),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/unarchive",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionEdit, types.AuditResourceClient)(
+					http.HandlerFunc(api.unarchiveClient),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/deceased",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceClient)(
+						http.HandlerFunc(api.getDeceasedStatus),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/deceased",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionEdit, types.AuditResourceClient)(
+					http.HandlerFunc(api.markClientDeceased),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/rollover-check",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceClient)(
+						http.HandlerFunc(api.checkFilingRollover),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/comprehensive",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceClient)(
+						http.HandlerFunc(api.getClientComprehensive),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Taxpayer consent (Section 7216) endpoints
+	api.Router.Handle("/api/v1/{tenantId}/consent-templates",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getConsentTemplates),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/consent-templates",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.createConsentTemplate),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/consents",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceClient)(
+						http.HandlerFunc(api.getClientConsents),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Documents the client viewed inline in the portal
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/portal-document-views",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceClient)(
+						http.HandlerFunc(api.getPortalDocumentViews),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Wrong answers the client's portal sign-ins gave to verification
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/portal-verification-failures",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceClient)(
+						http.HandlerFunc(api.getPortalVerificationFailures),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Terms of service and privacy policy versions shown to portal users
+	api.Router.Handle("/api/v1/{tenantId}/legal-documents",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getLegalDocuments),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/legal-documents",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.createLegalDocument),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	// Partner document drops (GCS or SFTP batch delivery) and unmatched file resolution (admin only)
+	api.Router.Handle("/api/v1/{tenantId}/document-drops",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getDocumentDrops),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/document-drops",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.createDocumentDrop),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/document-drops/{dropId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.deactivateDocumentDrop),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	api.Router.Handle("/api/v1/{tenantId}/document-drops/{dropId}/scan",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.scanDocumentDrop),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/document-drops/files",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getDocumentDropFiles),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/document-drops/files/{fileId}/resolve",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.resolveDocumentDropFile),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/document-drops/files/{fileId}/ignore",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.ignoreDocumentDropFile),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	// Emailed documents held for review (admin only)
+	api.Router.Handle("/api/v1/{tenantId}/inbound-documents",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getInboundDocuments),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/inbound-documents/{attachmentId}/confirm",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.confirmInboundDocument),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/inbound-documents/{attachmentId}/reject",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.rejectInboundDocument),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	// Printed and mailed copies of client documents (admin only)
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/mailings",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.createMailing),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/mailings",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getMailings),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/mailings/costs",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getMailCosts),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Notification email templates: variables, previews and test sends to the requesting admin
+	api.Router.Handle("/api/v1/{tenantId}/notifications/templates",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getNotificationTemplates),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/notifications/test-send",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.testSendNotification),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	// Filings endpoint (filtered by status/year)
+	api.Router.Handle("/api/v1/{tenantId}/filings",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceClient)(
+						http.HandlerFunc(api.getFilings),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Affiliate management (admins and affiliate managers)
+	api.Router.Handle("/api/v1/{tenantId}/affiliates",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityAffiliates)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getAffiliates),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/affiliates",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityAffiliates)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.createAffiliate),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/affiliates/{affiliateId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityAffiliates)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getAffiliate),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/affiliates/{affiliateId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityAffiliates)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.updateAffiliate),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/affiliates/{affiliateId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityAffiliates)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.patchAffiliate),
+				),
+			),
+		),
+	).Methods(http.MethodPatch)
+
+	api.Router.Handle("/api/v1/{tenantId}/affiliates/{affiliateId}/generate-token",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityAffiliates)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.generateAffiliateToken),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/affiliates/{affiliateId}/tokens",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityAffiliates)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getAffiliateTokens),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/affiliates/{affiliateId}/tokens/{tokenId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityAffiliates)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.revokeAffiliateToken),
+				),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	api.Router.Handle("/api/v1/{tenantId}/commissions",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getCommissions),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/commissions",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.createCommission),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/commissions/review",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getCommissionReviewQueue),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/commissions/overdue",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getOverdueCommissions),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/commissions/bulk-approve",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.bulkApproveCommissions),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/commissions/{commissionId}/approve",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.approveCommission),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/commissions/{commissionId}/mark-paid",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.markCommissionPaid),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/commissions/{commissionId}/cancel",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.cancelCommission),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/commissions/{commissionId}/notes",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getCommissionNotes),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/commissions/{commissionId}/notes",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.addCommissionNote),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/commissions/{commissionId}/tags",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.addCommissionTag),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/commissions/{commissionId}/tags/{tag}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.removeCommissionTag),
+				),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	// Affiliate payouts (transfers are admin only)
+	api.Router.Handle("/api/v1/{tenantId}/payouts/batches",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getPayoutBatches),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/payouts/batches",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.createPayoutBatch),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/payouts/batches/{batchId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getPayoutBatch),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/payouts/1099",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getAffiliatePaymentTotals),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/payouts/1099/{affiliateId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getAffiliateStatement),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/payouts/batches/{batchId}/execute",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.executePayoutBatch),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/payouts/{payoutId}/mark-paid",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.markPayoutPaid),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/payouts/{payoutId}/cancel",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCommissions)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.cancelPayout),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	// Discount code management (admins and affiliate managers)
+	api.Router.Handle("/api/v1/{tenantId}/discount-codes",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityDiscounts)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getDiscountCodes),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/discount-codes",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityDiscounts)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.createDiscountCode),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/discount-codes/bulk",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityDiscounts)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.bulkCreateDiscountCodes),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/discount-codes/campaigns",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityDiscounts)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getDiscountCampaigns),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/discount-codes/validate",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityDiscounts)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.validateDiscountCode),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/discount-codes/{codeId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityDiscounts)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getDiscountCode),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/discount-codes/{codeId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityDiscounts)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.updateDiscountCode),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/discount-codes/{codeId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityDiscounts)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.patchDiscountCode),
+				),
+			),
+		),
+	).Methods(http.MethodPatch)
+
+	api.Router.Handle("/api/v1/{tenantId}/discount-codes/{codeId}/deactivate",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityDiscounts)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.deactivateDiscountCode),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	// Marketing campaigns and attribution (admins and affiliate managers)
+	api.Router.Handle("/api/v1/{tenantId}/campaigns",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCampaigns)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getCampaigns),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/campaigns",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCampaigns)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.createCampaign),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/campaigns/report",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCampaigns)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getCampaignReport),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/campaigns/{campaignId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityCampaigns)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.updateCampaign),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	// Tenant webhooks for commission and filing events (admin only)
+	api.Router.Handle("/api/v1/{tenantId}/webhooks",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getWebhooks),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/webhooks",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.createWebhook),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/webhooks/{webhookId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getWebhook),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/webhooks/{webhookId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.updateWebhook),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/webhooks/{webhookId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.deleteWebhook),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	api.Router.Handle("/api/v1/{tenantId}/webhooks/{webhookId}/deliveries",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getWebhookDeliveries),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/webhooks/{webhookId}/deliveries/{deliveryId}/retry",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.retryWebhookDelivery),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	// Tenant integrity checks (admin only, reads SSNs)
+	api.Router.Handle("/api/v1/{tenantId}/integrity-checks",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceSSN)(
+					http.HandlerFunc(api.getIntegrityChecks),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// State filing tracking
+	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/state-filings",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceFiling)(
+						http.HandlerFunc(api.getStateFilings),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/state-filings",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					api.auditMiddleware.LogAccess(types.AuditActionCreate, types.AuditResourceFiling)(
+						api.archiveMiddleware.RequireWritable(http.HandlerFunc(api.createStateFiling)),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/state-filings/{stateFilingId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					api.auditMiddleware.LogAccess(types.AuditActionEdit, types.AuditResourceFiling)(
+						http.HandlerFunc(api.updateStateFiling),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/state-filings/{stateFilingId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionDelete, types.AuditResourceFiling)(
+					http.HandlerFunc(api.deleteStateFiling),
+				),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	// Filing results (AGI, tax, refund/owed) recorded by accountants
+	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/result",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceFiling)(
+						http.HandlerFunc(api.getFilingResult),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/result",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					api.auditMiddleware.LogAccess(types.AuditActionEdit, types.AuditResourceFiling)(
+						api.archiveMiddleware.RequireWritable(http.HandlerFunc(api.recordFilingResult)),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	// Documents a filing still needs, from what the client reported
+	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/checklist",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceFiling)(
+						http.HandlerFunc(api.getFilingChecklist),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/filings/comparison",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceClient)(
+						http.HandlerFunc(api.getClientYearOverYear),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Refund tracking per jurisdiction (FEDERAL or state code), shown in portal summaries
+	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/refunds",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceFiling)(
+						http.HandlerFunc(api.getRefundTrackings),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/refunds/{jurisdiction}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					api.auditMiddleware.LogAccess(types.AuditActionEdit, types.AuditResourceFiling)(
+						api.archiveMiddleware.RequireWritable(http.HandlerFunc(api.putRefundTracking)),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/refunds/{jurisdiction}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionDelete, types.AuditResourceFiling)(
+					api.archiveMiddleware.RequireWritable(http.HandlerFunc(api.deleteRefundTracking)),
+				),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	api.Router.Handle("/api/v1/{tenantId}/reports/state-filings",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getStateFilingReport),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Address validation
+	api.Router.Handle("/api/v1/{tenantId}/addresses/validate",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					http.HandlerFunc(api.validateAddress),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/addresses/undeliverable",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getUndeliverableAddresses),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Document management endpoints (audited; viewers in the tenant read, accountants upload, admins delete)
+	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/documents",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					api.auditMiddleware.LogAccess(types.AuditActionUpload, types.AuditResourceDocument)(
+						api.archiveMiddleware.RequireWritable(http.HandlerFunc(api.uploadDocument)),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/documents",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceDocument)(
+						http.HandlerFunc(api.getDocuments),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/documents/{documentId}/download",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionDownload, types.AuditResourceDocument)(
+						http.HandlerFunc(api.downloadDocument),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/documents/{documentId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionDelete, types.AuditResourceDocument)(
+					api.archiveMiddleware.RequireWritable(http.HandlerFunc(api.deleteDocument)),
+				),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	// Document expiry dates and document requests (admin only with audit)
+	api.Router.Handle("/api/v1/{tenantId}/documents/expiring",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceDocument)(
+					http.HandlerFunc(api.getExpiringDocuments),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/documents/{documentId}/expiry",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionEdit, types.AuditResourceDocument)(
+					api.archiveMiddleware.RequireWritable(http.HandlerFunc(api.setDocumentExpiry)),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/document-requests",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceDocumentRequest)(
+					http.HandlerFunc(api.getClientDocumentRequests),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/document-requests",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionCreate, types.AuditResourceDocumentRequest)(
+					http.HandlerFunc(api.createDocumentRequest),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/document-requests/{requestId}/cancel",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionEdit, types.AuditResourceDocumentRequest)(
+					http.HandlerFunc(api.cancelDocumentRequest),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/document-requests/{requestId}/fulfill",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionEdit, types.AuditResourceDocumentRequest)(
+					http.HandlerFunc(api.fulfillDocumentRequest),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	// Identity documents (admin only; every list, view and review is audited)
+	api.Router.Handle("/api/v1/{tenantId}/identity-documents",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceIdentityDocument)(
+					http.HandlerFunc(api.getIdentityDocuments),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/identity-documents",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceIdentityDocument)(
+					http.HandlerFunc(api.getClientIdentityDocuments),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/identity-documents/{documentId}/download",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionDownload, types.AuditResourceIdentityDocument)(
+					http.HandlerFunc(api.downloadIdentityDocument),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/identity-documents/{documentId}/review",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionEdit, types.AuditResourceIdentityDocument)(
+					http.HandlerFunc(api.reviewIdentityDocument),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	// Signature endpoints (admin only)
+	api.Router.Handle("/api/v1/{tenantId}/signature/send",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.sendSignatureRequest),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	// Sent envelopes and their DocuSign status, e.g. 8879s still waiting on a signature
+	api.Router.Handle("/api/v1/{tenantId}/signature/requests",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getSignatureRequests),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Archive a completed envelope's signed PDF as a filing document (admin only)
+	api.Router.Handle("/api/v1/{tenantId}/signature/requests/{requestId}/archive",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.archiveSignatureRequest),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	// Fillable form templates (cover sheets, organizers, Form 8879)
+	api.Router.Handle("/api/v1/{tenantId}/form-templates",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					http.HandlerFunc(api.getFormTemplates),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/form-templates/{kind}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.putFormTemplate),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/form-templates/{kind}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.deleteFormTemplate),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	api.Router.Handle("/api/v1/{tenantId}/form-templates/{kind}/fields",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getFormTemplateFields),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/clients/{clientId}/filings/{filingId}/forms/{kind}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					api.auditMiddleware.LogAccess(types.AuditActionDownload, types.AuditResourceFiling)(
+						http.HandlerFunc(api.generateFilingForm),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	// Filing management endpoints (admin only)
+	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/complete",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.archiveMiddleware.RequireWritable(http.HandlerFunc(api.markFilingCompleted)),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	// Filing status workflow: IN_PROGRESS → REVIEW → SIGNED → FILED → ACCEPTED or REJECTED
+	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/status",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceFiling)(
+						http.HandlerFunc(api.getFilingStatus),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/status",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleAccountant)(
+					api.auditMiddleware.LogAccess(types.AuditActionEdit, types.AuditResourceFiling)(
+						api.archiveMiddleware.RequireWritable(http.HandlerFunc(api.putFilingStatus)),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/status/history",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireCapability(types.CapabilityClientData)(
+				api.authMiddleware.RequireTenantRole(types.RoleViewer)(
+					api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourceFiling)(
+						http.HandlerFunc(api.getFilingStatusHistory),
+					),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// End-of-season archive: filings and documents of archived tax years are read-only
+	api.Router.Handle("/api/v1/{tenantId}/archived-years",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getArchivedYears),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/archived-years",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionEdit, types.AuditResourceFiling)(
+					http.HandlerFunc(api.archiveYear),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/archived-years/{year}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionEdit, types.AuditResourceFiling)(
+					http.HandlerFunc(api.unarchiveYear),
+				),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	// The accountant working each filing
+	api.Router.Handle("/api/v1/{tenantId}/filing-assignments",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getFilingAssignments),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/filings/{filingId}/assignee",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionEdit, types.AuditResourceFiling)(
+					http.HandlerFunc(api.assignFiling),
+				),
+			),
+		),
+	).Methods(http.MethodPut)
+
+	// Text messages sent to the tenant's clients and their delivery status (admin only)
+	api.Router.Handle("/api/v1/{tenantId}/sms-messages",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getSMSMessages),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Portal users with their last sign-in, for spotting clients who stopped using the portal (admin only)
+	api.Router.Handle("/api/v1/{tenantId}/portal-users",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionView, types.AuditResourcePortalUser)(
+					http.HandlerFunc(api.getPortalUsers),
+				),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Bulk operations over many clients or filings, run by the worker with per-item results
+	api.Router.Handle("/api/v1/{tenantId}/bulk-operations",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				api.auditMiddleware.LogAccess(types.AuditActionCreate, types.AuditResourceBulkOperation)(
+					http.HandlerFunc(api.createBulkOperation),
+				),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/bulk-operations",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getBulkOperations),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/bulk-operations/{operationId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.getBulkOperation),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Tenant User Portal endpoints (Firebase-authenticated client access)
+	// Routes before sign-in verification use AuthenticateUnverified; all others answer 403
+	// VERIFICATION_REQUIRED until the sign-in is verified under the tenant's portal security policy.
+	// Auto-register tenant user on first sign-in (requires Firebase auth)
+	api.Router.Handle("/api/v1/{tenantId}/user/register",
+		api.tenantUserAuthMiddleware.AuthenticateUnverified(
+			http.HandlerFunc(api.autoRegisterTenantUser),
+		),
+	).Methods(http.MethodPost)
+
+	// Manual registration by admin (admin only) - links Firebase UID to client record
+	api.Router.Handle("/api/v1/{tenantId}/users/register",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.registerTenantUser),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	// Remove a client's portal access and revoke their Firebase tokens (admin only)
+	api.Router.Handle("/api/v1/{tenantId}/users/{tenantUserId}",
+		api.authMiddleware.Authenticate(
+			api.authMiddleware.RequireAdmin(
+				http.HandlerFunc(api.deleteTenantUser),
+			),
+		),
+	).Methods(http.MethodDelete)
+
+	// Current legal documents and acceptance (requires Firebase auth, tenant user only).
+	// Routes wrapped in legalMiddleware.RequireAcceptance answer 428 until these are accepted.
+	api.Router.Handle("/api/v1/{tenantId}/user/legal",
+		api.tenantUserAuthMiddleware.AuthenticateUnverified(
+			http.HandlerFunc(api.getPortalLegalDocuments),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/user/legal/{documentId}/accept",
+		api.tenantUserAuthMiddleware.AuthenticateUnverified(
+			http.HandlerFunc(api.acceptPortalLegalDocument),
+		),
+	).Methods(http.MethodPost)
+
+	// Sign-in session and its verification (requires Firebase auth, tenant user only)
+	api.Router.Handle("/api/v1/{tenantId}/user/session",
+		api.tenantUserAuthMiddleware.AuthenticateUnverified(
+			http.HandlerFunc(api.getPortalSession),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/user/session/verify/start",
+		api.tenantUserAuthMiddleware.AuthenticateUnverified(
+			http.HandlerFunc(api.startPortalVerification),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/user/session/verify",
+		api.tenantUserAuthMiddleware.AuthenticateUnverified(
+			http.HandlerFunc(api.verifyPortalSession),
+		),
+	).Methods(http.MethodPost)
+
+	// Get tenant user's own profile and data (requires Firebase auth, tenant user only)
+	api.Router.Handle("/api/v1/{tenantId}/user/profile",
+		api.tenantUserAuthMiddleware.Authenticate(
+			api.legalMiddleware.RequireAcceptance(
+				http.HandlerFunc(api.getTenantUserProfile),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Lightweight portal summaries for the mobile app (requires Firebase auth, tenant user only)
+	api.Router.Handle("/api/v1/{tenantId}/user/summary",
+		api.tenantUserAuthMiddleware.Authenticate(
+			api.legalMiddleware.RequireAcceptance(
+				http.HandlerFunc(api.getPortalSummary),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/user/filings/comparison",
+		api.tenantUserAuthMiddleware.Authenticate(
+			api.legalMiddleware.RequireAcceptance(
+				http.HandlerFunc(api.getPortalYearOverYear),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/user/filings/{filingId}/summary",
+		api.tenantUserAuthMiddleware.Authenticate(
+			api.legalMiddleware.RequireAcceptance(
+				http.HandlerFunc(api.getPortalFilingSummary),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/user/filings/{filingId}/status",
+		api.tenantUserAuthMiddleware.Authenticate(
+			api.legalMiddleware.RequireAcceptance(
+				http.HandlerFunc(api.getPortalFilingStatus),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Push notification devices and opt-out (requires Firebase auth, tenant user only)
+	api.Router.Handle("/api/v1/{tenantId}/user/devices",
+		api.tenantUserAuthMiddleware.Authenticate(
+			http.HandlerFunc(api.registerDevice),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/user/devices",
+		api.tenantUserAuthMiddleware.Authenticate(
+			http.HandlerFunc(api.getDevices),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/user/devices/{deviceId}",
+		api.tenantUserAuthMiddleware.Authenticate(
+			http.HandlerFunc(api.deleteDevice),
+		),
+	).Methods(http.MethodDelete)
+
+	api.Router.Handle("/api/v1/{tenantId}/user/push-preferences",
+		api.tenantUserAuthMiddleware.Authenticate(
+			http.HandlerFunc(api.getPushPreferences),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/user/push-preferences",
+		api.tenantUserAuthMiddleware.Authenticate(
+			http.HandlerFunc(api.updatePushPreferences),
+		),
+	).Methods(http.MethodPut)
+
+	api.Router.Handle("/api/v1/{tenantId}/user/consents",
+		api.tenantUserAuthMiddleware.Authenticate(
+			http.HandlerFunc(api.getPortalConsents),
+		),
+	).Methods(http.MethodGet)
+
+	api.Router.Handle("/api/v1/{tenantId}/user/consents/{templateId}/grant",
+		api.tenantUserAuthMiddleware.Authenticate(
+			http.HandlerFunc(api.grantPortalConsent),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/user/consents/{purpose}/revoke",
+		api.tenantUserAuthMiddleware.Authenticate(
+			http.HandlerFunc(api.revokePortalConsent),
+		),
+	).Methods(http.MethodPost)
+
+	// Download tenant user's own document (requires Firebase auth, tenant user only)
+	api.Router.Handle("/api/v1/{tenantId}/user/documents/{documentId}/download",
+		api.tenantUserAuthMiddleware.Authenticate(
+			api.legalMiddleware.RequireAcceptance(
+				http.HandlerFunc(api.downloadTenantUserDocument),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Upload a tax document into the tenant user's own filing (tenant user only)
+	api.Router.Handle("/api/v1/{tenantId}/user/filings/{filingId}/documents",
+		api.tenantUserAuthMiddleware.Authenticate(
+			api.legalMiddleware.RequireAcceptance(
+				api.archiveMiddleware.RequireWritable(http.HandlerFunc(api.uploadTenantUserDocument)),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	// View tenant user's own PDF or image document inline (tenant user only)
+	api.Router.Handle("/api/v1/{tenantId}/user/documents/{documentId}/view",
+		api.tenantUserAuthMiddleware.Authenticate(
+			api.legalMiddleware.RequireAcceptance(
+				http.HandlerFunc(api.viewTenantUserDocument),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Documents the firm is waiting for, as a checklist (tenant user only)
+	api.Router.Handle("/api/v1/{tenantId}/user/document-requests",
+		api.tenantUserAuthMiddleware.Authenticate(
+			api.legalMiddleware.RequireAcceptance(
+				http.HandlerFunc(api.getPortalDocumentRequests),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Onboarding photo ID upload and check status (tenant user only; images are never served back)
+	api.Router.Handle("/api/v1/{tenantId}/user/identity-documents",
+		api.tenantUserAuthMiddleware.Authenticate(
+			api.legalMiddleware.RequireAcceptance(
+				http.HandlerFunc(api.uploadPortalIdentityDocument),
+			),
+		),
+	).Methods(http.MethodPost)
+
+	api.Router.Handle("/api/v1/{tenantId}/user/identity-documents",
+		api.tenantUserAuthMiddleware.Authenticate(
+			api.legalMiddleware.RequireAcceptance(
+				http.HandlerFunc(api.getPortalIdentityDocuments),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Public affiliate endpoints (token-based, no Firebase auth)
+	api.Router.HandleFunc("/api/v1/{tenantId}/affiliates/{affiliateId}/dashboard", api.getAffiliateDashboard).Methods(http.MethodGet)
+	api.Router.HandleFunc("/api/v1/{tenantId}/affiliates/{affiliateId}/stats", api.getAffiliateStatsPublic).Methods(http.MethodGet)
+	api.Router.HandleFunc("/api/v1/{tenantId}/affiliates/{affiliateId}/commissions", api.getAffiliateCommissionsPublic).Methods(http.MethodGet)
+	api.Router.HandleFunc("/api/v1/{tenantId}/affiliates/{affiliateId}/statement", api.getAffiliateStatementPublic).Methods(http.MethodGet)
+	api.Router.HandleFunc("/api/v1/{tenantId}/affiliates/{affiliateId}/notifications", api.getAffiliateNotifications).Methods(http.MethodGet)
+	api.Router.HandleFunc("/api/v1/{tenantId}/affiliates/{affiliateId}/notifications/read", api.markAffiliateNotificationsRead).Methods(http.MethodPost)
+	api.Router.HandleFunc("/api/v1/{tenantId}/affiliates/{affiliateId}/notification-preferences", api.getAffiliateNotificationPreferences).Methods(http.MethodGet)
+	api.Router.HandleFunc("/api/v1/{tenantId}/affiliates/{affiliateId}/notification-preferences", api.updateAffiliateNotificationPreferences).Methods(http.MethodPut)
+
+	// SendGrid Inbound Parse webhook (authenticated by the key in its URL)
+	api.Router.HandleFunc("/api/v1/inbound/email", api.receiveInboundEmail).Methods(http.MethodPost)
+
+	// SendGrid Event Webhook for bounces and spam reports (authenticated by the key in its URL)
+	api.Router.HandleFunc("/api/v1/email/events", api.receiveEmailEvents).Methods(http.MethodPost)
+
+	// Text message provider delivery status callback (authenticated by its signature)
+	api.Router.HandleFunc("/api/v1/sms/status", api.receiveSMSStatus).Methods(http.MethodPost)
+
+	// Print and mail provider status webhook (authenticated by its signature)
+	api.Router.HandleFunc("/api/v1/mailing/webhook", api.receiveMailingWebhook).Methods(http.MethodPost)
+
+	// DocuSign Connect envelope status webhook (authenticated by its HMAC signature)
+	api.Router.HandleFunc("/api/v1/{tenantId}/signature/webhook", api.receiveSignatureWebhook).Methods(http.MethodPost)
+
+	// Stripe Checkout payment webhook (authenticated by its signing secret)
+	api.Router.HandleFunc("/api/v1/{tenantId}/payments/webhook", api.receivePaymentWebhook).Methods(http.MethodPost)
+
+	// Discount code check for checkout widgets (rate limited; callers are checked first so a
+	// flood from one address does not spend the tenant's allowance)
+	api.Router.Handle("/api/v1/{tenantId}/checkout/discount-codes/validate",
+		api.discountCheckCallerLimit.Limit(
+			api.discountCheckTenantLimit.Limit(
+				http.HandlerFunc(api.validateDiscountCodePublic),
+			),
+		),
+	).Methods(http.MethodGet)
+
+	// Public click tracking (HMAC-signed once the tenant has a signing key)
+	api.Router.Handle("/api/v1/{tenantId}/affiliates/{affiliateId}/clicks",
+		api.signatureMiddleware.Verify(
+			http.HandlerFunc(api.trackAffiliateClick),
+		),
+	).Methods(http.MethodPost)
 }
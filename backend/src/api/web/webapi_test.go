@@ -0,0 +1,507 @@
+package webapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/auth"
+	"welltaxpro/src/internal/consistency"
+	"welltaxpro/src/internal/fake"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+const testTenantID = "acme"
+
+// unimplementedStore makes the store methods neither the fake nor testStore provides panic, so a
+// test reaching one fails loudly instead of passing on a zero value
+type unimplementedStore struct{ Store }
+
+// testStore serves the API from a fake.Store plus the canned records below. Methods named in
+// errs fail with their error.
+type testStore struct {
+	*fake.Store
+	unimplementedStore
+
+	tenants       []types.TenantConnection
+	tenantAccess  []*types.TenantAccess
+	announcements []*types.Announcement
+	clients       []*types.Client
+	tenantUsers   []*types.TenantUser
+	filings       []*types.ClientComprehensive     // clients with their filings, in listing order
+	scores        map[uuid.UUID]int                // completeness score by filing ID
+	scoreCalls    int                              // calls to ApplyFilingCompleteness
+	workflows     map[string]*types.FilingWorkflow // by filing ID
+	documents     map[string][]*types.Document     // by filing ID
+	commissions   []*types.Commission
+	payoutBatches []*types.PayoutBatch
+	campaigns     []*types.Campaign
+	webhooks      []*types.Webhook
+	errs          map[string]error
+}
+
+func newTestStore() *testStore {
+	return &testStore{
+		Store:     fake.NewStore(),
+		workflows: map[string]*types.FilingWorkflow{},
+		documents: map[string][]*types.Document{},
+		scores:    map[uuid.UUID]int{},
+		errs:      map[string]error{},
+	}
+}
+
+func (s *testStore) GetTenantConnections() ([]types.TenantConnection, error) {
+	return s.tenants, s.errs["GetTenantConnections"]
+}
+
+func (s *testStore) GetEmployeeTenantAccess(employeeID uuid.UUID) ([]*types.TenantAccess, error) {
+	return s.tenantAccess, s.errs["GetEmployeeTenantAccess"]
+}
+
+func (s *testStore) GetActiveAnnouncements(employee *types.Employee) ([]*types.Announcement, error) {
+	return s.announcements, s.errs["GetActiveAnnouncements"]
+}
+
+func (s *testStore) GetClients(ctx context.Context, tenantID string, includeArchived bool) ([]*types.Client, error) {
+	return s.clients, s.errs["GetClients"]
+}
+
+func (s *testStore) GetClientByID(ctx context.Context, tenantID string, clientID string) (*types.Client, error) {
+	if err := s.errs["GetClientByID"]; err != nil {
+		return nil, err
+	}
+	for _, client := range s.clients {
+		if client.ID.String() == clientID {
+			return client, nil
+		}
+	}
+	return nil, apperr.NotFound("client not found")
+}
+
+func (s *testStore) IsClientArchived(ctx context.Context, tenantID string, clientID string) (bool, error) {
+	return false, s.errs["IsClientArchived"]
+}
+
+func (s *testStore) GetTenantUserByFirebaseUID(firebaseUID string) (*types.TenantUser, error) {
+	if err := s.errs["GetTenantUserByFirebaseUID"]; err != nil {
+		return nil, err
+	}
+	for _, user := range s.tenantUsers {
+		if user.FirebaseUID == firebaseUID {
+			return user, nil
+		}
+	}
+	return nil, apperr.NotFound("tenant user not found")
+}
+
+func (s *testStore) StreamClientsByFilings(ctx context.Context, tenantID string, fn func(*types.ClientComprehensive) error) error {
+	if err := s.errs["StreamClientsByFilings"]; err != nil {
+		return err
+	}
+	for _, client := range s.filings {
+		if err := fn(client); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *testStore) ApplyFilingCompleteness(tenantID string, clients []*types.ClientComprehensive) error {
+	s.scoreCalls++
+	if err := s.errs["ApplyFilingCompleteness"]; err != nil {
+		return err
+	}
+	for _, client := range clients {
+		for _, filing := range client.Filings {
+			filing.Completeness = &types.FilingCompleteness{Score: s.scores[filing.ID]}
+		}
+	}
+	return nil
+}
+
+func (s *testStore) GetFilingWorkflow(ctx context.Context, tenantID string, filingID string) (*types.FilingWorkflow, error) {
+	if err := s.errs["GetFilingWorkflow"]; err != nil {
+		return nil, err
+	}
+	workflow, ok := s.workflows[filingID]
+	if !ok {
+		return nil, apperr.NotFound("filing not found")
+	}
+	return workflow, nil
+}
+
+func (s *testStore) GetDocumentsByFilingID(ctx context.Context, tenantID string, filingID string) ([]*types.Document, error) {
+	return s.documents[filingID], s.errs["GetDocumentsByFilingID"]
+}
+
+func (s *testStore) GetCommissionsByAffiliate(ctx context.Context, tenantID string, affiliateID *string, status *string, tag *string, limit int) ([]*types.Commission, error) {
+	return s.commissions, s.errs["GetCommissionsByAffiliate"]
+}
+
+func (s *testStore) AttachCommissionTags(tenantID string, commissions []*types.Commission) error {
+	return s.errs["AttachCommissionTags"]
+}
+
+func (s *testStore) GetPayoutBatches(ctx context.Context, tenantID string) ([]*types.PayoutBatch, error) {
+	return s.payoutBatches, s.errs["GetPayoutBatches"]
+}
+
+func (s *testStore) GetCampaigns(tenantID string, activeOnly bool) ([]*types.Campaign, error) {
+	return s.campaigns, s.errs["GetCampaigns"]
+}
+
+func (s *testStore) GetWebhooks(tenantID string) ([]*types.Webhook, error) {
+	return s.webhooks, s.errs["GetWebhooks"]
+}
+
+// testEnv is an API with its routes served from fakes
+type testEnv struct {
+	api   *API
+	store *testStore
+	auth  *fake.Auth
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+	s := newTestStore()
+	verifier := fake.NewAuth()
+	api := newAPI(context.Background(), s, verifier, verifier, nil, nil)
+	api.storageForTenant = fake.NewStorage().ForTenant
+	api.storageChecker = consistency.New(s, api.storageForTenant)
+	api.InitRoutes()
+	return &testEnv{api: api, store: s, auth: verifier}
+}
+
+// signIn adds an active employee with role and returns them with an ID token of theirs
+func (e *testEnv) signIn(role string) (*types.Employee, string) {
+	employee := &types.Employee{
+		ID:          uuid.New(),
+		FirebaseUID: "uid-" + uuid.NewString(),
+		Email:       role + "@example.com",
+		Role:        role,
+		IsActive:    true,
+	}
+	e.store.AddEmployee(employee)
+	token := "token-" + employee.FirebaseUID
+	e.auth.AddToken(token, employee.FirebaseUID, time.Now().Unix())
+	return employee, token
+}
+
+// do serves a request with token as its bearer token and body, when given, as its JSON body
+func (e *testEnv) do(t *testing.T, method, path, token, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	var reader io.Reader
+	if body != "" {
+		reader = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, path, reader)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	e.api.Router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuthenticate(t *testing.T) {
+	env := newTestEnv(t)
+	_, valid := env.signIn(types.RoleAccountant)
+
+	revokedEmployee, revoked := env.signIn(types.RoleAccountant)
+	decoded, _ := env.auth.VerifyToken(context.Background(), revoked)
+	env.store.RevokeSession(revokedEmployee.FirebaseUID, decoded.AuthTime)
+
+	inactiveEmployee, inactive := env.signIn(types.RoleAccountant)
+	inactiveEmployee.IsActive = false
+	env.store.AddEmployee(inactiveEmployee)
+
+	env.auth.AddToken("stranger", "uid-stranger", time.Now().Unix())
+
+	tests := []struct {
+		name       string
+		token      string
+		wantStatus int
+	}{
+		{"missing token", "", http.StatusUnauthorized},
+		{"invalid token", "forged", http.StatusUnauthorized},
+		{"revoked session", revoked, http.StatusUnauthorized},
+		{"not an employee", "stranger", http.StatusUnauthorized},
+		{"inactive employee", inactive, http.StatusUnauthorized},
+		{"valid token", valid, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := env.do(t, http.MethodGet, "/api/v1/employees/me/tenants", tt.token, "")
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %q)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequireTenantRole(t *testing.T) {
+	env := newTestEnv(t)
+	affiliate := env.store.AddAffiliate(testTenantID, &types.Affiliate{FirstName: "Ada", LastName: "Byron", Email: "ada@example.com", DefaultCommissionRate: types.Rate(15_00), IsActive: true})
+	affiliatePath := "/api/v1/" + testTenantID + "/affiliates/" + affiliate.ID.String()
+	codesPath := "/api/v1/" + testTenantID + "/discount-codes"
+
+	_, admin := env.signIn(types.RoleAdmin)
+	_, ungranted := env.signIn(types.RoleAffiliateManager)
+	viewerEmployee, viewer := env.signIn(types.RoleAffiliateManager)
+	env.store.GrantTenantRole(viewerEmployee.ID, testTenantID, types.RoleViewer)
+	accountantEmployee, accountant := env.signIn(types.RoleAffiliateManager)
+	env.store.GrantTenantRole(accountantEmployee.ID, testTenantID, types.RoleAccountant)
+	elsewhereEmployee, elsewhere := env.signIn(types.RoleAffiliateManager)
+	env.store.GrantTenantRole(elsewhereEmployee.ID, "other", types.RoleAdmin)
+	// Accountants hold the tenant role but not the affiliates capability
+	clientStaffEmployee, clientStaff := env.signIn(types.RoleAccountant)
+	env.store.GrantTenantRole(clientStaffEmployee.ID, testTenantID, types.RoleAccountant)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		body       string
+		wantStatus int
+	}{
+		{"no tenant access", http.MethodGet, affiliatePath, ungranted, "", http.StatusForbidden},
+		{"access to another tenant", http.MethodGet, affiliatePath, elsewhere, "", http.StatusForbidden},
+		{"viewer reads", http.MethodGet, affiliatePath, viewer, "", http.StatusOK},
+		{"viewer writes", http.MethodPost, codesPath, viewer, `{"code":"SPRING","discountType":"PERCENTAGE","discountValue":10,"affiliateId":"` + affiliate.ID.String() + `"}`, http.StatusForbidden},
+		{"accountant writes", http.MethodPost, codesPath, accountant, `{"code":"SUMMER","discountType":"PERCENTAGE","discountValue":10,"affiliateId":"` + affiliate.ID.String() + `"}`, http.StatusCreated},
+		{"missing capability", http.MethodGet, affiliatePath, clientStaff, "", http.StatusForbidden},
+		{"admin without tenant access", http.MethodGet, affiliatePath, admin, "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := env.do(t, tt.method, tt.path, tt.token, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %q)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestAuthenticateTenantContext(t *testing.T) {
+	env := newTestEnv(t)
+	affiliate := env.store.AddAffiliate(testTenantID, &types.Affiliate{FirstName: "Ada", LastName: "Byron", IsActive: true})
+	employee, token := env.signIn(types.RoleAdmin)
+	decoded, _ := env.auth.VerifyToken(context.Background(), token)
+	env.store.AddTenantContext(auth.HashSessionToken("other-context"), &types.TenantContext{
+		ID:         uuid.New(),
+		EmployeeID: employee.ID,
+		TenantID:   "other",
+		Role:       types.RoleViewer,
+		AuthTime:   decoded.AuthTime,
+	})
+
+	tests := []struct {
+		name       string
+		context    string
+		wantStatus int
+	}{
+		{"unknown context", "expired-context", http.StatusUnauthorized},
+		{"context for another tenant", "other-context", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/"+testTenantID+"/affiliates/"+affiliate.ID.String(), nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			req.Header.Set(types.TenantContextHeader, tt.context)
+			rec := httptest.NewRecorder()
+			env.api.Router.ServeHTTP(rec, req)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d (body %q)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+		})
+	}
+}
+
+func TestPortalAuthenticate(t *testing.T) {
+	env := newTestEnv(t)
+	env.auth.AddToken("portal-user", "uid-portal", time.Now().Unix())
+	path := "/api/v1/" + testTenantID + "/user/session"
+
+	if rec := env.do(t, http.MethodGet, path, "", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("missing token: status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	if rec := env.do(t, http.MethodGet, path, "forged", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("invalid token: status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	env.store.BlockPortal(testTenantID)
+	if rec := env.do(t, http.MethodGet, path, "portal-user", ""); rec.Code != http.StatusForbidden {
+		t.Errorf("closed portal: status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	env := newTestEnv(t)
+	affiliate := env.store.AddAffiliate(testTenantID, &types.Affiliate{FirstName: "Ada", LastName: "Byron", DefaultCommissionRate: types.Rate(15_00), IsActive: true})
+	env.store.AddDiscountCode(testTenantID, &types.DiscountCode{Code: "TAKEN", DiscountType: types.DiscountTypePercentage, DiscountValue: 10, IsActive: true})
+	_, admin := env.signIn(types.RoleAdmin)
+	campaignsPath := "/api/v1/" + testTenantID + "/campaigns"
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		storeErr   error // returned by GetCampaigns
+		wantStatus int
+		wantBody   string
+	}{
+		{"not found", http.MethodGet, "/api/v1/" + testTenantID + "/affiliates/" + uuid.NewString(), "", nil, http.StatusNotFound, "Affiliate not found"},
+		{"conflict", http.MethodPost, "/api/v1/" + testTenantID + "/discount-codes", `{"code":"taken","discountType":"PERCENTAGE","discountValue":10,"affiliateId":"` + affiliate.ID.String() + `"}`, nil, http.StatusConflict, "Discount code TAKEN already exists"},
+		{"validation", http.MethodGet, campaignsPath, "", apperr.Validation("tenant %s has no database for this operation", testTenantID), http.StatusBadRequest, "Tenant acme has no database for this operation"},
+		{"permission", http.MethodGet, campaignsPath, "", apperr.Permission("service worker is not permitted"), http.StatusForbidden, "Service worker is not permitted"},
+		{"internal", http.MethodGet, campaignsPath, "", errors.New("pq: connection refused"), http.StatusInternalServerError, "Failed to fetch campaigns"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env.store.errs["GetCampaigns"] = tt.storeErr
+			rec := env.do(t, tt.method, tt.path, admin, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if body := strings.TrimSpace(rec.Body.String()); body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandlers(t *testing.T) {
+	env := newTestEnv(t)
+	filingID := uuid.New()
+	affiliate := env.store.AddAffiliate(testTenantID, &types.Affiliate{FirstName: "Ada", LastName: "Byron", Email: "ada@example.com", DefaultCommissionRate: types.Rate(12_50), IsActive: true})
+	code := env.store.AddDiscountCode(testTenantID, &types.DiscountCode{Code: "WELCOME", DiscountType: types.DiscountTypePercentage, DiscountValue: 10, IsActive: true})
+	env.store.tenants = []types.TenantConnection{{ID: uuid.New(), TenantID: testTenantID, TenantName: "Acme Tax"}}
+	env.store.tenantAccess = []*types.TenantAccess{{TenantID: testTenantID, TenantName: "Acme Tax", Role: types.RoleViewer, IsActive: true}}
+	env.store.announcements = []*types.Announcement{{ID: uuid.New(), Message: "Maintenance tonight", Severity: "info"}}
+	env.store.clients = []*types.Client{{ID: uuid.New(), Email: "client@example.com"}}
+	env.store.workflows[filingID.String()] = &types.FilingWorkflow{FilingID: filingID, Year: 2025, Status: "IN_PROGRESS"}
+	env.store.documents[filingID.String()] = []*types.Document{{ID: uuid.New(), FilingID: &filingID, Name: "w2.pdf"}}
+	env.store.commissions = []*types.Commission{{ID: uuid.New(), AffiliateID: affiliate.ID}}
+	env.store.payoutBatches = []*types.PayoutBatch{{ID: uuid.New(), TotalAmount: types.Cents(250_00)}}
+	env.store.campaigns = []*types.Campaign{{ID: uuid.New(), TenantID: testTenantID, Key: "spring", Name: "Spring"}}
+	env.store.webhooks = []*types.Webhook{{ID: uuid.New(), TenantID: testTenantID, URL: "https://hooks.example.com"}}
+	env.auth.AddToken("portal-user", "uid-portal", time.Now().Unix())
+
+	_, admin := env.signIn(types.RoleAdmin)
+	clientStaffEmployee, clientStaff := env.signIn(types.RoleAccountant)
+	env.store.GrantTenantRole(clientStaffEmployee.ID, testTenantID, types.RoleViewer)
+	marketingEmployee, marketing := env.signIn(types.RoleAffiliateManager)
+	env.store.GrantTenantRole(marketingEmployee.ID, testTenantID, types.RoleAccountant)
+
+	tenantPath := "/api/v1/" + testTenantID
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		body       string
+		wantStatus int
+		wantBody   string // contained in the response
+	}{
+		{"tenants", http.MethodGet, "/api/v1/admin/tenants", admin, "", http.StatusOK, `"tenantName":"Acme Tax"`},
+		{"employee tenants", http.MethodGet, "/api/v1/employees/me/tenants", clientStaff, "", http.StatusOK, `"tenantId":"acme"`},
+		{"announcements", http.MethodGet, "/api/v1/announcements", clientStaff, "", http.StatusOK, `"message":"Maintenance tonight"`},
+		{"clients", http.MethodGet, tenantPath + "/clients", clientStaff, "", http.StatusOK, `"email":"client@example.com"`},
+		{"filing status", http.MethodGet, tenantPath + "/filings/" + filingID.String() + "/status", clientStaff, "", http.StatusOK, `"status":"IN_PROGRESS"`},
+		{"documents", http.MethodGet, tenantPath + "/filings/" + filingID.String() + "/documents", clientStaff, "", http.StatusOK, `"name":"w2.pdf"`},
+		{"affiliates", http.MethodGet, tenantPath + "/affiliates", marketing, "", http.StatusOK, `"email":"ada@example.com"`},
+		{"affiliate", http.MethodGet, tenantPath + "/affiliates/" + affiliate.ID.String(), marketing, "", http.StatusOK, `"defaultCommissionRate":12.5`},
+		{"discount code", http.MethodGet, tenantPath + "/discount-codes/" + code.ID.String(), marketing, "", http.StatusOK, `"code":"WELCOME"`},
+		{"new discount code", http.MethodPost, tenantPath + "/discount-codes", marketing, `{"code":"fall","discountType":"FIXED_AMOUNT","discountValue":25,"affiliateId":"` + affiliate.ID.String() + `"}`, http.StatusCreated, `"commissionRate":12.5`},
+		{"commissions", http.MethodGet, tenantPath + "/commissions", marketing, "", http.StatusOK, affiliate.ID.String()},
+		{"payout batches", http.MethodGet, tenantPath + "/payouts/batches", marketing, "", http.StatusOK, `"totalAmount":250.00`},
+		{"campaigns", http.MethodGet, tenantPath + "/campaigns", marketing, "", http.StatusOK, `"key":"spring"`},
+		{"webhooks", http.MethodGet, tenantPath + "/webhooks", admin, "", http.StatusOK, `"url":"https://hooks.example.com"`},
+		{"portal session", http.MethodGet, tenantPath + "/user/session", "portal-user", "", http.StatusOK, `"verified":true`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := env.do(t, tt.method, tt.path, tt.token, tt.body)
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d (body %q)", rec.Code, tt.wantStatus, rec.Body.String())
+			}
+			if !json.Valid(rec.Body.Bytes()) {
+				t.Errorf("body is not JSON: %q", rec.Body.String())
+			}
+			if !strings.Contains(rec.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want it to contain %q", rec.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestAuditedRoutesLogAccess(t *testing.T) {
+	env := newTestEnv(t)
+	filingID := uuid.NewString()
+	employee, token := env.signIn(types.RoleAccountant)
+	env.store.GrantTenantRole(employee.ID, testTenantID, types.RoleViewer)
+
+	rec := env.do(t, http.MethodGet, "/api/v1/"+testTenantID+"/filings/"+filingID+"/documents", token, "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	logs := env.store.AuditLogs()
+	if len(logs) != 1 {
+		t.Fatalf("got %d audit log entries, want 1", len(logs))
+	}
+	entry := logs[0]
+	if entry.EmployeeID != employee.ID || entry.TenantID != testTenantID || entry.Action != types.AuditActionView || entry.ResourceType != types.AuditResourceDocument {
+		t.Errorf("audit entry = %+v, want a document view of %s by %s", entry, testTenantID, employee.ID)
+	}
+}
+
+func TestRouteClass(t *testing.T) {
+	tests := []struct {
+		method   string
+		template string
+		url      string
+		want     middleware.RouteClass
+	}{
+		{http.MethodGet, "/api/v1/{tenantId}/user/documents/{documentId}/view", "/api/v1/acme/user/documents/d1/view", middleware.RouteClassStream},
+		{http.MethodGet, "/api/v1/{tenantId}/clients", "/api/v1/acme/clients?stream=ndjson", middleware.RouteClassStream},
+		{http.MethodGet, "/api/v1/{tenantId}/clients", "/api/v1/acme/clients", middleware.RouteClassAPI},
+		{http.MethodPost, "/api/v1/{tenantId}/filings/{filingId}/documents", "/api/v1/acme/filings/f1/documents", middleware.RouteClassUpload},
+		{http.MethodGet, "/health", "/health", middleware.RouteClassPublic},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			var got middleware.RouteClass
+			router := mux.NewRouter()
+			router.HandleFunc(tt.template, func(w http.ResponseWriter, r *http.Request) {
+				got = routeClass(r)
+			}).Methods(tt.method)
+
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.url, nil))
+			if got != tt.want {
+				t.Errorf("routeClass = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package webapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// webhookRequest is the body of webhook create and update requests
+type webhookRequest struct {
+	URL         string   `json:"url"` // https endpoint that receives the POSTs
+	Description *string  `json:"description"`
+	Events      []string `json:"events"`
+	Active      *bool    `json:"active"`
+}
+
+// validate normalizes the request and returns a client-facing message when it is invalid
+func (req *webhookRequest) validate() string {
+	req.URL = strings.TrimSpace(req.URL)
+	parsed, err := url.Parse(req.URL)
+	if err != nil || parsed.Scheme != "https" || parsed.Host == "" || len(req.URL) > 2000 {
+		return "url must be an https URL of at most 2000 characters"
+	}
+
+	if req.Description != nil {
+		description := strings.TrimSpace(*req.Description)
+		if len(description) > 200 {
+			return "description must be at most 200 characters"
+		}
+		req.Description = &description
+		if description == "" {
+			req.Description = nil
+		}
+	}
+
+	seen := map[string]bool{}
+	events := make([]string, 0, len(req.Events))
+	for _, event := range req.Events {
+		event = strings.ToUpper(strings.TrimSpace(event))
+		if !types.IsValidWebhookEvent(event) {
+			return "events must be from " + strings.Join(types.WebhookEvents, ", ")
+		}
+		if !seen[event] {
+			seen[event] = true
+			events = append(events, event)
+		}
+	}
+	if len(events) == 0 {
+		return "at least one event is required"
+	}
+	req.Events = events
+	return ""
+}
+
+// webhookIDFromRequest parses the webhookId path variable, writing a 400 when it is malformed
+func webhookIDFromRequest(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	webhookID, err := uuid.Parse(mux.Vars(r)["webhookId"])
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", http.StatusBadRequest)
+		return uuid.Nil, false
+	}
+	return webhookID, true
+}
+
+// getWebhooks lists a tenant's webhooks without their secrets (admin only)
+func (api *API) getWebhooks(w http.ResponseWriter, r *http.Request) {
+	tenantID := mux.Vars(r)["tenantId"]
+
+	hooks, err := api.store.GetWebhooks(tenantID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch webhooks")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(hooks); err != nil {
+		logger.Errorf("Failed to encode webhooks response: %v", err)
+	}
+}
+
+// getWebhook returns one webhook without its secret (admin only)
+func (api *API) getWebhook(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+
+	hook, err := api.store.GetWebhook(mux.Vars(r)["tenantId"], webhookID)
+	if err != nil {
+		writeError(w, err, "Failed to fetch webhook")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(hook); err != nil {
+		logger.Errorf("Failed to encode webhook response: %v", err)
+	}
+}
+
+// createWebhook registers a webhook for a tenant (admin only)
+// The signing secret is only returned in this response.
+func (api *API) createWebhook(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	if _, err := api.store.GetTenantConfig(tenantID); err != nil {
+		writeError(w, err, "Failed to fetch tenant")
+		return
+	}
+
+	hook, err := api.store.CreateWebhook(&types.Webhook{
+		TenantID:    tenantID,
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      req.Events,
+		Active:      req.Active == nil || *req.Active,
+		CreatedBy:   &employee.ID,
+	})
+	if err != nil {
+		writeError(w, err, "Failed to create webhook")
+		return
+	}
+
+	api.auditWebhook(r, employee.ID, tenantID, types.AuditActionCreate, hook)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	if err := json.NewEncoder(w).Encode(hook); err != nil {
+		logger.Errorf("Failed to encode webhook response: %v", err)
+	}
+}
+
+// updateWebhook replaces a webhook's URL, description, events and active flag (admin only)
+// The signing secret is kept.
+func (api *API) updateWebhook(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	webhookID, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var req webhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if msg := req.validate(); msg != "" {
+		http.Error(w, msg, http.StatusBadRequest)
+		return
+	}
+
+	hook, err := api.store.UpdateWebhook(&types.Webhook{
+		ID:          webhookID,
+		TenantID:    tenantID,
+		URL:         req.URL,
+		Description: req.Description,
+		Events:      req.Events,
+		Active:      req.Active == nil || *req.Active,
+	})
+	if err != nil {
+		writeError(w, err, "Failed to update webhook")
+		return
+	}
+
+	api.auditWebhook(r, employee.ID, tenantID, types.AuditActionEdit, hook)
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(hook); err != nil {
+		logger.Errorf("Failed to encode webhook response: %v", err)
+	}
+}
+
+// deleteWebhook removes a webhook and its delivery log (admin only)
+func (api *API) deleteWebhook(w http.ResponseWriter, r *http.Request) {
+	employee, ok := middleware.GetEmployeeFromContext(r.Context())
+	if !ok {
+		logger.Error("Employee not found in context")
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	webhookID, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	tenantID := mux.Vars(r)["tenantId"]
+
+	if err := api.store.DeleteWebhook(tenantID, webhookID); err != nil {
+		writeError(w, err, "Failed to delete webhook")
+		return
+	}
+
+	api.auditWebhook(r, employee.ID, tenantID, types.AuditActionDelete, &types.Webhook{ID: webhookID})
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// auditWebhook records a change to a webhook
+func (api *API) auditWebhook(r *http.Request, employeeID uuid.UUID, tenantID, action string, hook *types.Webhook) {
+	ipAddress := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	details := map[string]interface{}{}
+	if hook.URL != "" {
+		details["url"] = hook.URL
+		details["events"] = hook.Events
+		details["active"] = hook.Active
+	}
+	if err := api.store.CreateAuditLog(employeeID, tenantID, nil, action, types.AuditResourceWebhook, &hook.ID, details, &ipAddress, &userAgent); err != nil {
+		logger.Errorf("Failed to audit webhook %s: %v", hook.ID, err)
+	}
+}
+
+// getWebhookDeliveries returns a webhook's delivery log, newest first (admin only)
+// Query params: status (PENDING, DELIVERED or FAILED), limit (1-200, default 50)
+func (api *API) getWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	tenantID := mux.Vars(r)["tenantId"]
+
+	var status *string
+	if s := strings.ToUpper(r.URL.Query().Get("status")); s != "" {
+		if s != types.WebhookDeliveryPending && s != types.WebhookDeliveryDelivered && s != types.WebhookDeliveryFailed {
+			http.Error(w, "status must be PENDING, DELIVERED or FAILED", http.StatusBadRequest)
+			return
+		}
+		status = &s
+	}
+
+	limit := 50 // default
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		parsed, err := strconv.Atoi(limitStr)
+		if err != nil || parsed < 1 || parsed > 200 {
+			http.Error(w, "limit must be between 1 and 200", http.StatusBadRequest)
+			return
+		}
+		limit = parsed
+	}
+
+	// 404 for another tenant's webhook rather than an empty log
+	if _, err := api.store.GetWebhook(tenantID, webhookID); err != nil {
+		writeError(w, err, "Failed to fetch webhook")
+		return
+	}
+
+	deliveries, err := api.store.GetWebhookDeliveries(tenantID, webhookID, status, limit)
+	if err != nil {
+		writeError(w, err, "Failed to fetch webhook deliveries")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(deliveries); err != nil {
+		logger.Errorf("Failed to encode webhook deliveries response: %v", err)
+	}
+}
+
+// retryWebhookDelivery queues a delivery to be sent again on the next worker run (admin only)
+func (api *API) retryWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	webhookID, ok := webhookIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	vars := mux.Vars(r)
+	deliveryID, err := uuid.Parse(vars["deliveryId"])
+	if err != nil {
+		http.Error(w, "Invalid delivery ID", http.StatusBadRequest)
+		return
+	}
+
+	delivery, err := api.store.RetryWebhookDelivery(vars["tenantId"], webhookID, deliveryID)
+	if err != nil {
+		writeError(w, err, "Failed to retry webhook delivery")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(delivery); err != nil {
+		logger.Errorf("Failed to encode webhook delivery response: %v", err)
+	}
+}
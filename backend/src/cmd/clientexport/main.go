@@ -0,0 +1,17 @@
+package main
+
+import (
+	"context"
+	"io"
+	"welltaxpro/src/cmd/server"
+
+	"github.com/google/logger"
+)
+
+func main() {
+	// start Logger Settings
+	logger.Init("WellTaxPro", true, false, io.Discard)
+	ctx := context.Background()
+
+	server.RunClientExport(ctx)
+}
@@ -13,6 +13,7 @@ import (
 	"path"
 	"strings"
 	"syscall"
+	"welltaxpro/src/internal/dlock"
 
 	"embed"
 
@@ -129,11 +130,24 @@ func main() {
 		os.Exit(1)
 	}
 
+	// Hold a lock while applying so provisioners started by concurrent deploys run one at a time;
+	// a waiting provisioner then sees the migrations the first one applied
+	err = dlock.New(db, "provisioner", nil).Run(ctx, "provisioner:migrations", func() error {
+		return applyMigrations(ctx, db, migrations)
+	})
+	if err != nil {
+		logger.Errorf("Failed to apply migrations, error: %v", err)
+		os.Exit(1)
+	}
+	logger.Info("All migrations applied successfully")
+}
+
+// applyMigrations applies every migration not yet recorded in schema_migrations in a single transaction
+func applyMigrations(ctx context.Context, db *sql.DB, migrations []migration) error {
 	// Get already applied migrations
 	appliedMigrations, err := getAppliedMigrations(ctx, db)
 	if err != nil {
-		logger.Errorf("Failed to get applied migrations, error: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to get applied migrations: %w", err)
 	}
 
 	tx, err := db.BeginTx(ctx, &sql.TxOptions{
@@ -141,8 +155,7 @@ func main() {
 		ReadOnly:  false,
 	})
 	if err != nil {
-		logger.Errorf("Failed to start transaction, error: %v", err)
-		os.Exit(1)
+		return fmt.Errorf("failed to start transaction: %w", err)
 	}
 
 	for _, migration := range migrations {
@@ -156,9 +169,8 @@ func main() {
 
 		_, err := tx.ExecContext(ctx, migration.contents)
 		if err != nil {
-			logger.Errorf("Failed to apply migration, error: %v", err)
 			tx.Rollback()
-			os.Exit(1)
+			return fmt.Errorf("failed to apply migration %s: %w", migration.id, err)
 		}
 
 		// Record migration as applied
@@ -166,14 +178,12 @@ func main() {
 			"INSERT INTO schema_migrations (id, description, checksum, applied_at) VALUES ($1, $2, $3, NOW())",
 			migration.id, migration.description, migration.sha256)
 		if err != nil {
-			logger.Errorf("Failed to record migration, error: %v", err)
 			tx.Rollback()
-			os.Exit(1)
+			return fmt.Errorf("failed to record migration %s: %w", migration.id, err)
 		}
 	}
 
-	tx.Commit()
-	logger.Info("All migrations applied successfully")
+	return tx.Commit()
 }
 
 func initialize(ctx context.Context) []migration {
@@ -0,0 +1,133 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"welltaxpro/src/internal/anonymize"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/store"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// clientExportArguments are the clientexport command's flags
+type clientExportArguments struct {
+	ConfigPath string
+	Mode       string
+	TenantID   string
+	ClientID   string
+	File       string
+	Employee   string
+	Salt       string
+}
+
+func parseClientExportArguments() *clientExportArguments {
+	args := &clientExportArguments{}
+	flag.StringVar(&args.ConfigPath, "config", "config.yaml", "Path to configuration file")
+	flag.StringVar(&args.Mode, "mode", "export", "export (from a tenant) or import (into a local tenant)")
+	flag.StringVar(&args.TenantID, "tenant", "", "Tenant to export from or import into")
+	flag.StringVar(&args.ClientID, "client", "", "Client to export")
+	flag.StringVar(&args.File, "file", "", "Export file to write or read")
+	flag.StringVar(&args.Employee, "employee", "", "Email of the employee requesting the export, for the audit log")
+	flag.StringVar(&args.Salt, "salt", os.Getenv("CLIENT_EXPORT_SALT"), "Anonymization salt (default $CLIENT_EXPORT_SALT)")
+	flag.Parse()
+	return args
+}
+
+// RunClientExport exports one client with PII anonymized so support can reproduce a tenant bug,
+// or imports such an export into a local tenant. Exports are written against production
+// configuration and imports against a local one; neither serves the API.
+func RunClientExport(ctx context.Context) {
+	args := parseClientExportArguments()
+	if args.TenantID == "" || args.File == "" {
+		logger.Fatalf("-tenant and -file are required")
+	}
+
+	config, err := getConfiguration(&Arguments{ConfigPath: args.ConfigPath})
+	if err != nil {
+		logger.Fatalf("Failed getting configuration: %v", err)
+	}
+
+	// Tenant database passwords and SSNs are encrypted
+	if err := crypto.InitEncryption(); err != nil {
+		logger.Fatalf("Failed to initialize encryption: %v", err)
+	}
+
+	db := connectDatabase(config.Database)
+	defer db.Close()
+
+	s := store.NewStore(ctx, db, 0)
+	defer s.Close()
+	crypto.SetDataKeySource(s)
+	support := s.ForService(types.ServiceSupport)
+
+	switch args.Mode {
+	case "export":
+		if err := exportClient(ctx, support, args); err != nil {
+			logger.Fatalf("Client export failed: %v", err)
+		}
+	case "import":
+		if err := importClient(ctx, support, args); err != nil {
+			logger.Fatalf("Client import failed: %v", err)
+		}
+	default:
+		logger.Fatalf("Unknown -mode %q (expected export or import)", args.Mode)
+	}
+}
+
+// exportClient writes the anonymized export of one client to args.File
+func exportClient(ctx context.Context, s *store.Store, args *clientExportArguments) error {
+	if args.ClientID == "" || args.Employee == "" {
+		return fmt.Errorf("-client and -employee are required for export")
+	}
+	// Without a secret salt anyone could confirm a guessed name or SSN against the export
+	if args.Salt == "" {
+		return fmt.Errorf("-salt or CLIENT_EXPORT_SALT is required for export")
+	}
+
+	employee, err := s.GetEmployeeByEmail(args.Employee)
+	if err != nil {
+		return err
+	}
+
+	export, err := s.ExportClient(ctx, args.TenantID, args.ClientID, employee, anonymize.New(args.Salt))
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode export: %w", err)
+	}
+	if err := os.WriteFile(args.File, data, 0600); err != nil {
+		return fmt.Errorf("failed to write %s: %w", args.File, err)
+	}
+
+	fmt.Printf("Exported client %s of tenant %s to %s\n", args.ClientID, args.TenantID, args.File)
+	return nil
+}
+
+// importClient loads an export from args.File into a local tenant
+func importClient(ctx context.Context, s *store.Store, args *clientExportArguments) error {
+	data, err := os.ReadFile(args.File)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", args.File, err)
+	}
+
+	export := &types.ClientExport{}
+	if err := json.Unmarshal(data, export); err != nil {
+		return fmt.Errorf("failed to decode %s: %w", args.File, err)
+	}
+
+	inserted, err := s.ImportClient(ctx, args.TenantID, export)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Imported client %s into tenant %s (%d rows inserted)\n", export.ClientID, args.TenantID, inserted)
+	return nil
+}
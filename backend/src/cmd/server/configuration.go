@@ -3,6 +3,17 @@ package server
 import (
 	"fmt"
 	"os"
+	"strings"
+	"time"
+	webapi "welltaxpro/src/api/web"
+	"welltaxpro/src/internal/auditchain"
+	"welltaxpro/src/internal/ingest"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/notification"
+	"welltaxpro/src/internal/scan"
+	"welltaxpro/src/internal/sms"
+	"welltaxpro/src/internal/tracing"
+	"welltaxpro/src/internal/worker"
 
 	"gopkg.in/yaml.v2"
 )
@@ -14,6 +25,8 @@ type DatabaseConfig struct {
 	Password string `yaml:"password"`
 	DBName   string `yaml:"dbname"`
 	SslMode  string `yaml:"sslmode"`
+
+	TenantPoolIdleMinutes int `yaml:"tenantPoolIdleMinutes"` // minutes a tenant connection pool may go unused before it is closed (default 5)
 }
 
 type CORSConfig struct {
@@ -24,7 +37,23 @@ type CORSConfig struct {
 }
 
 type ServerConfig struct {
-	Port int `yaml:"port"`
+	Port              int                         `yaml:"port"`
+	Limits            map[string]RouteLimitConfig `yaml:"limits"`            // keyed by route class: api, upload, public, stream
+	DebugRedactFields []string                    `yaml:"debugRedactFields"` // JSON fields masked in debug captures; empty uses ssn, dob, dateOfBirth, password, token and secret
+	StrictTenants     bool                        `yaml:"strictTenants"`     // check every active tenant's adapter type and schema before serving, alerting admins about misconfigured tenants
+	AppURL            string                      `yaml:"appUrl"`            // web app that emailed sign-in and portal links point to (default https://app.welltaxpro.com)
+	Shutdown          ShutdownConfig              `yaml:"shutdown"`
+}
+
+// ShutdownConfig times the drain on SIGTERM; values left out keep their defaults
+type ShutdownConfig struct {
+	DrainSeconds   int `yaml:"drainSeconds"`   // how long /health/ready fails before the listener closes; default 5
+	TimeoutSeconds int `yaml:"timeoutSeconds"` // how long in-flight requests may take to finish once it has; default 20
+}
+
+type RouteLimitConfig struct {
+	MaxBodyBytes   int64 `yaml:"maxBodyBytes"`
+	TimeoutSeconds int   `yaml:"timeoutSeconds"`
 }
 
 type FirebaseConfig struct {
@@ -33,17 +62,123 @@ type FirebaseConfig struct {
 }
 
 type SendGridConfig struct {
-	APIKey           string `yaml:"apiKey"`
-	DefaultFromEmail string `yaml:"defaultFromEmail"`
-	DefaultFromName  string `yaml:"defaultFromName"`
+	APIKey           string           `yaml:"apiKey"`
+	DefaultFromEmail string           `yaml:"defaultFromEmail"`
+	DefaultFromName  string           `yaml:"defaultFromName"`
+	Queue            EmailQueueConfig `yaml:"queue"`
+	EventWebhookKey  string           `yaml:"eventWebhookKey"` // secret expected in the event webhook URL's key parameter; empty ignores bounce and spam reports
+}
+
+type EmailQueueConfig struct {
+	GlobalPerSecond    float64 `yaml:"globalPerSecond"`    // sends per second across all tenants
+	TenantPerMinute    int     `yaml:"tenantPerMinute"`    // sends per minute for any one tenant
+	MaxDepth           int     `yaml:"maxDepth"`           // queued messages per priority class before sends are rejected
+	BulkDeferPerMinute int     `yaml:"bulkDeferPerMinute"` // transactional sends per minute at which bulk sends pause
+	Workers            int     `yaml:"workers"`            // concurrent SendGrid requests
+}
+
+type AddressConfig struct {
+	Provider  string `yaml:"provider"` // smarty, or empty for basic normalization
+	AuthID    string `yaml:"authId"`
+	AuthToken string `yaml:"authToken"`
+}
+
+type IDCheckConfig struct {
+	Provider string `yaml:"provider"` // vision, or empty to send every uploaded ID to review
+	APIKey   string `yaml:"apiKey"`
+}
+
+type ScanConfig struct {
+	Provider string `yaml:"provider"` // clamav or http, or empty to leave uploads unscanned
+	Address  string `yaml:"address"`  // clamav: clamd host:port or unix:/path/to/clamd.sock
+	URL      string `yaml:"url"`      // http: scanning endpoint
+	APIKey   string `yaml:"apiKey"`   // http: bearer token
+}
+
+type WorkerConfig struct {
+	Jobs       *[]string `yaml:"jobs"`       // background jobs this process runs; omitted runs all, [] runs none
+	HealthPort int       `yaml:"healthPort"` // cmd/worker only: serve GET /health on this port when set
+}
+
+type IngestConfig struct {
+	SFTPRoot string `yaml:"sftpRoot"` // directory the SFTP server stores partner uploads under; empty disables SFTP drops
+}
+
+type InboundConfig struct {
+	Domain     string `yaml:"domain"`     // domain whose mail the inbound parse webhook receives; empty disables inbound email
+	WebhookKey string `yaml:"webhookKey"` // secret expected in the webhook URL's key parameter
+}
+
+type MailingConfig struct {
+	Provider           string `yaml:"provider"` // lob, or empty to disable print and mail
+	APIKey             string `yaml:"apiKey"`
+	WebhookSecret      string `yaml:"webhookSecret"`
+	CostPerLetterCents int64  `yaml:"costPerLetterCents"` // price recorded per letter for tenant billing
+}
+
+type SMSConfig struct {
+	Provider          string `yaml:"provider"` // twilio, or empty to disable text messages
+	AccountSID        string `yaml:"accountSid"`
+	AuthToken         string `yaml:"authToken"`
+	FromNumber        string `yaml:"fromNumber"`        // E.164 number texts are sent from
+	StatusCallbackURL string `yaml:"statusCallbackUrl"` // public URL of POST /api/v1/sms/status; empty leaves delivery untracked
+}
+
+type PayoutsConfig struct {
+	Provider  string `yaml:"provider"`  // stripe, or empty to disable affiliate payout transfers
+	SecretKey string `yaml:"secretKey"` // platform account secret key with Connect enabled
+}
+
+type NotificationsConfig struct {
+	DigestHourUTC int    `yaml:"digestHourUtc"` // hour (0-23) daily digests are sent
+	SlackBotToken string `yaml:"slackBotToken"` // bot token (xoxb-) with chat:write for staff direct messages; empty disables Slack
+}
+
+type DocumentsConfig struct {
+	ExpiryWarningDays    int `yaml:"expiryWarningDays"`    // request a replacement this many days before a document expires (default 30)
+	ReminderIntervalDays int `yaml:"reminderIntervalDays"` // days between reminders about an open document request (default 7)
+	MaxReminders         int `yaml:"maxReminders"`         // reminders sent per request (default 4)
+}
+
+type AuditConfig struct {
+	AnchorProvider        string `yaml:"anchorProvider"`        // gcs or s3 (or memory locally); empty disables anchoring
+	AnchorBucket          string `yaml:"anchorBucket"`          // bucket with a locked retention policy
+	AnchorCredentialsPath string `yaml:"anchorCredentialsPath"` // service account file; empty uses ADC
+	AnchorIntervalMinutes int    `yaml:"anchorIntervalMinutes"` // minutes between anchors (default 60)
+}
+
+type AffiliatesConfig struct {
+	ClickRetentionDays int `yaml:"clickRetentionDays"` // days raw clicks are kept before being rolled up into daily counts (default 90)
+}
+
+type TracingConfig struct {
+	Endpoint          string             `yaml:"endpoint"`          // OTLP/HTTP collector URL, such as http://otel-collector:4318; empty disables tracing
+	Headers           map[string]string  `yaml:"headers"`           // sent with every export, such as a collector API key
+	SampleRatio       *float64           `yaml:"sampleRatio"`       // share of new traces kept, 0 to 1 (default 0.1)
+	RouteSampleRatios map[string]float64 `yaml:"routeSampleRatios"` // share kept by "METHOD /route/template" or "/route/template", overriding sampleRatio
+	SlowQueryMs       int                `yaml:"slowQueryMs"`       // statements at least this slow are traced even outside a kept trace (default 500; negative disables)
 }
 
 type Config struct {
-	Server   ServerConfig   `yaml:"server"`
-	Database DatabaseConfig `yaml:"database"`
-	Cors     CORSConfig     `yaml:"cors"`
-	Firebase FirebaseConfig `yaml:"firebase"`
-	SendGrid SendGridConfig `yaml:"sendgrid"`
+	Server        ServerConfig        `yaml:"server"`
+	Database      DatabaseConfig      `yaml:"database"`
+	Cors          CORSConfig          `yaml:"cors"`
+	Firebase      FirebaseConfig      `yaml:"firebase"`
+	SendGrid      SendGridConfig      `yaml:"sendgrid"`
+	Address       AddressConfig       `yaml:"address"`
+	IDCheck       IDCheckConfig       `yaml:"idCheck"`
+	Scan          ScanConfig          `yaml:"scan"`
+	Notifications NotificationsConfig `yaml:"notifications"`
+	Worker        WorkerConfig        `yaml:"worker"`
+	Ingest        IngestConfig        `yaml:"ingest"`
+	Inbound       InboundConfig       `yaml:"inbound"`
+	Mailing       MailingConfig       `yaml:"mailing"`
+	Payouts       PayoutsConfig       `yaml:"payouts"`
+	Documents     DocumentsConfig     `yaml:"documents"`
+	Audit         AuditConfig         `yaml:"audit"`
+	Affiliates    AffiliatesConfig    `yaml:"affiliates"`
+	SMS           SMSConfig           `yaml:"sms"`
+	Tracing       TracingConfig       `yaml:"tracing"`
 }
 
 func getConfiguration(args *Arguments) (*Config, error) {
@@ -65,3 +200,166 @@ func getConfiguration(args *Arguments) (*Config, error) {
 
 	return &config, nil
 }
+
+// routeLimits converts configured limits; classes and values left out keep their defaults
+func (c ServerConfig) routeLimits() (middleware.RouteLimits, error) {
+	limits := middleware.RouteLimits{}
+	for name, l := range c.Limits {
+		class := middleware.RouteClass(name)
+		limit, ok := middleware.DefaultRouteLimits[class]
+		if !ok {
+			return nil, fmt.Errorf("unknown route class %q in server.limits", name)
+		}
+		if l.MaxBodyBytes < 0 || l.TimeoutSeconds < 0 {
+			return nil, fmt.Errorf("server.limits.%s values cannot be negative", name)
+		}
+		if l.MaxBodyBytes > 0 {
+			limit.MaxBodyBytes = l.MaxBodyBytes
+		}
+		if l.TimeoutSeconds > 0 {
+			limit.Timeout = time.Duration(l.TimeoutSeconds) * time.Second
+		}
+		limits[class] = limit
+	}
+	return limits, nil
+}
+
+// Shutdown defaults. Together they fit Kubernetes' default 30 second termination grace period.
+const (
+	defaultShutdownDrain   = 5 * time.Second
+	defaultShutdownTimeout = 20 * time.Second
+)
+
+// timings converts the shutdown settings
+func (c ShutdownConfig) timings() (drain, timeout time.Duration, err error) {
+	if c.DrainSeconds < 0 || c.TimeoutSeconds < 0 {
+		return 0, 0, fmt.Errorf("server.shutdown values cannot be negative")
+	}
+	drain, timeout = defaultShutdownDrain, defaultShutdownTimeout
+	if c.DrainSeconds > 0 {
+		drain = time.Duration(c.DrainSeconds) * time.Second
+	}
+	if c.TimeoutSeconds > 0 {
+		timeout = time.Duration(c.TimeoutSeconds) * time.Second
+	}
+	return drain, timeout, nil
+}
+
+// queueConfig converts the email send queue limits; values left out keep their defaults
+func (c EmailQueueConfig) queueConfig() (notification.QueueConfig, error) {
+	if c.GlobalPerSecond < 0 || c.TenantPerMinute < 0 || c.MaxDepth < 0 || c.BulkDeferPerMinute < 0 || c.Workers < 0 {
+		return notification.QueueConfig{}, fmt.Errorf("sendgrid.queue values cannot be negative")
+	}
+	return notification.QueueConfig{
+		GlobalPerSecond:    c.GlobalPerSecond,
+		TenantPerMinute:    c.TenantPerMinute,
+		MaxDepth:           c.MaxDepth,
+		BulkDeferPerMinute: c.BulkDeferPerMinute,
+		Workers:            c.Workers,
+	}, nil
+}
+
+// inboundEmailConfig converts the inbound email settings and the SendGrid event webhook key
+func (c InboundConfig) inboundEmailConfig(eventWebhookKey string) webapi.InboundEmailConfig {
+	return webapi.InboundEmailConfig{Domain: strings.ToLower(strings.TrimSpace(c.Domain)), WebhookKey: c.WebhookKey, EventWebhookKey: eventWebhookKey}
+}
+
+// expiryConfig converts the document expiry settings; values left out keep their defaults
+func (c DocumentsConfig) expiryConfig() (worker.ExpiryConfig, error) {
+	if c.ExpiryWarningDays < 0 || c.ReminderIntervalDays < 0 || c.MaxReminders < 0 {
+		return worker.ExpiryConfig{}, fmt.Errorf("documents values cannot be negative")
+	}
+	config := worker.ExpiryConfig{WarningDays: 30, ReminderInterval: 7 * 24 * time.Hour, MaxReminders: 4}
+	if c.ExpiryWarningDays > 0 {
+		config.WarningDays = c.ExpiryWarningDays
+	}
+	if c.ReminderIntervalDays > 0 {
+		config.ReminderInterval = time.Duration(c.ReminderIntervalDays) * 24 * time.Hour
+	}
+	if c.MaxReminders > 0 {
+		config.MaxReminders = c.MaxReminders
+	}
+	return config, nil
+}
+
+// anchorConfig converts the audit anchoring settings and returns the anchor interval
+func (c AuditConfig) anchorConfig() (auditchain.Config, time.Duration, error) {
+	if c.AnchorIntervalMinutes < 0 {
+		return auditchain.Config{}, 0, fmt.Errorf("audit.anchorIntervalMinutes cannot be negative")
+	}
+	interval := time.Hour
+	if c.AnchorIntervalMinutes > 0 {
+		interval = time.Duration(c.AnchorIntervalMinutes) * time.Minute
+	}
+	return auditchain.Config{
+		Provider:        c.AnchorProvider,
+		Bucket:          c.AnchorBucket,
+		CredentialsPath: c.AnchorCredentialsPath,
+	}, interval, nil
+}
+
+// tenantPoolIdleTimeout returns how long a tenant connection pool may go unused; 0 keeps the store default
+func (c DatabaseConfig) tenantPoolIdleTimeout() (time.Duration, error) {
+	if c.TenantPoolIdleMinutes < 0 {
+		return 0, fmt.Errorf("database.tenantPoolIdleMinutes cannot be negative")
+	}
+	return time.Duration(c.TenantPoolIdleMinutes) * time.Minute, nil
+}
+
+// ingestConfig converts the document drop settings
+func (c IngestConfig) ingestConfig(scanner scan.Scanner) ingest.Config {
+	return ingest.Config{SFTPRoot: c.SFTPRoot, Scanner: scanner}
+}
+
+// scanner creates the configured malware scanner for uploaded documents
+func (c ScanConfig) scanner() scan.Scanner {
+	return scan.NewScanner(scan.Config{
+		Provider: c.Provider,
+		Address:  c.Address,
+		URL:      c.URL,
+		APIKey:   c.APIKey,
+	})
+}
+
+// provider creates the configured text message provider
+func (c SMSConfig) provider() sms.Provider {
+	return sms.NewProvider(sms.Config{
+		Provider:          c.Provider,
+		AccountSID:        c.AccountSID,
+		AuthToken:         c.AuthToken,
+		FromNumber:        c.FromNumber,
+		StatusCallbackURL: c.StatusCallbackURL,
+	})
+}
+
+// clickRetentionDays returns the days raw affiliate clicks are kept
+func (c AffiliatesConfig) clickRetentionDays() (int, error) {
+	if c.ClickRetentionDays < 0 {
+		return 0, fmt.Errorf("affiliates.clickRetentionDays cannot be negative")
+	}
+	if c.ClickRetentionDays == 0 {
+		return 90, nil
+	}
+	return c.ClickRetentionDays, nil
+}
+
+// tracingConfig converts the tracing settings, applying defaults
+func (c TracingConfig) tracingConfig() tracing.Config {
+	cfg := tracing.Config{
+		Endpoint:          c.Endpoint,
+		Headers:           c.Headers,
+		SampleRatio:       0.1,
+		RouteSampleRatios: c.RouteSampleRatios,
+		SlowQuery:         500 * time.Millisecond,
+	}
+	if c.SampleRatio != nil {
+		cfg.SampleRatio = *c.SampleRatio
+	}
+	switch {
+	case c.SlowQueryMs < 0:
+		cfg.SlowQuery = 0
+	case c.SlowQueryMs > 0:
+		cfg.SlowQuery = time.Duration(c.SlowQueryMs) * time.Millisecond
+	}
+	return cfg
+}
@@ -2,21 +2,31 @@ package server
 
 import (
 	webapi "welltaxpro/src/api/web"
+	"welltaxpro/src/internal/address"
+	"welltaxpro/src/internal/auditchain"
 	"welltaxpro/src/internal/auth"
 	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/idcheck"
+	"welltaxpro/src/internal/ingest"
+	"welltaxpro/src/internal/mailing"
 	"welltaxpro/src/internal/notification"
+	"welltaxpro/src/internal/payout"
 	"welltaxpro/src/internal/store"
+	"welltaxpro/src/internal/tracing"
+	"welltaxpro/src/internal/types"
+	"welltaxpro/src/internal/worker"
 	"context"
 	"database/sql"
 	"fmt"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/google/logger"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 func Run(ctx context.Context) {
@@ -35,39 +45,24 @@ func Run(ctx context.Context) {
 		logger.Fatalf("Failed to initialize encryption: %v", err)
 	}
 
-	// Connect to WellTaxPro database
-	dbConnection := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s binary_parameters=yes",
-		config.Database.Host,
-		config.Database.Port,
-		config.Database.User,
-		config.Database.Password,
-		config.Database.DBName,
-		config.Database.SslMode,
-	)
-
-	logger.Info("Connecting to WellTaxPro database")
-
-	db, err := sql.Open("postgres", dbConnection)
+	// Export traces when a collector is configured
+	shutdownTracing, err := tracing.Setup(ctx, "welltaxpro-api", config.Tracing.tracingConfig())
 	if err != nil {
-		logger.Fatalf("Failed connecting to database: %v", err)
+		logger.Fatalf("Failed to set up tracing: %v", err)
 	}
-	defer db.Close()
+	defer flushTraces(shutdownTracing)
 
-	// Set up database connection pool
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(60 * time.Second)
-
-	// Test connection
-	if err := db.Ping(); err != nil {
-		logger.Fatalf("Failed to ping database: %v", err)
-	}
-
-	logger.Info("Successfully connected to WellTaxPro database")
+	// Connect to WellTaxPro database
+	db := connectDatabase(config.Database)
+	defer db.Close()
 
 	// Initialize store
-	store := store.NewStore(ctx, db)
-	defer store.Close()
+	poolIdleTimeout, err := config.Database.tenantPoolIdleTimeout()
+	if err != nil {
+		logger.Fatalf("Invalid database settings: %v", err)
+	}
+	store := store.NewStore(ctx, db, poolIdleTimeout)
+	crypto.SetDataKeySource(store)
 
 	// Initialize Firebase Auth
 	logger.Info("Initializing Firebase authentication")
@@ -78,21 +73,99 @@ func Run(ctx context.Context) {
 
 	// Initialize Email Service
 	logger.Info("Initializing email service")
+	emailQueue, err := config.SendGrid.Queue.queueConfig()
+	if err != nil {
+		logger.Fatalf("Invalid email queue limits: %v", err)
+	}
 	emailService := notification.NewEmailService(
 		config.SendGrid.APIKey,
 		config.SendGrid.DefaultFromEmail,
 		config.SendGrid.DefaultFromName,
+		emailQueue,
 	)
 
+	// Initialize address validation
+	addressValidator := address.NewValidator(address.Config{
+		Provider:  config.Address.Provider,
+		AuthID:    config.Address.AuthID,
+		AuthToken: config.Address.AuthToken,
+	})
+	logger.Infof("Using %s address validator", addressValidator.Name())
+
+	// Initialize identity document text recognition
+	idExtractor := idcheck.NewExtractor(idcheck.Config{
+		Provider: config.IDCheck.Provider,
+		APIKey:   config.IDCheck.APIKey,
+	})
+	logger.Infof("Using %s text recognition for identity documents", idExtractor.Name())
+
+	// Initialize malware scanning of uploaded documents
+	scanner := config.Scan.scanner()
+	logger.Infof("Using %s malware scanner for uploaded documents", scanner.Name())
+
+	// Initialize print and mail fulfillment
+	mailer := mailing.NewProvider(mailing.Config{
+		Provider:           config.Mailing.Provider,
+		APIKey:             config.Mailing.APIKey,
+		WebhookSecret:      config.Mailing.WebhookSecret,
+		CostPerLetterCents: config.Mailing.CostPerLetterCents,
+	})
+	logger.Infof("Using %s print and mail provider", mailer.Name())
+
+	// Initialize text messages to clients
+	texter := config.SMS.provider()
+	logger.Infof("Using %s text message provider", texter.Name())
+
+	// Initialize affiliate payout transfers
+	payouts := payout.NewProvider(payout.Config{
+		Provider:  config.Payouts.Provider,
+		SecretKey: config.Payouts.SecretKey,
+	})
+	logger.Infof("Using %s payout provider", payouts.Name())
+
+	// Initialize staff notifications
+	notifier := notification.NewDispatcher(store.ForService(types.ServiceNotifier), emailService,
+		notification.NewSlackService(config.Notifications.SlackBotToken))
+
+	routeLimits, err := config.Server.routeLimits()
+	if err != nil {
+		logger.Fatalf("Invalid server limits: %v", err)
+	}
+	drainDelay, shutdownTimeout, err := config.Server.Shutdown.timings()
+	if err != nil {
+		logger.Fatalf("Invalid server shutdown settings: %v", err)
+	}
+	links, err := notification.NewLinks(config.Server.AppURL)
+	if err != nil {
+		logger.Fatalf("Invalid server.appUrl: %v", err)
+	}
+
 	// Initialize API
 	logger.Info("Starting API")
-	api := webapi.NewAPI(ctx, store, authClient, emailService)
+	ingester := ingest.New(store, config.Ingest.ingestConfig(scanner), worker.InstanceName())
+	anchorer := newAnchorer(ctx, store, config)
+	api := webapi.NewAPI(ctx, store, authClient, emailService, addressValidator, idExtractor, scanner, mailer, texter, payouts, notifier, ingester, anchorer, config.Inbound.inboundEmailConfig(config.SendGrid.EventWebhookKey), links, routeLimits, config.Server.DebugRedactFields)
 	api.InitRoutes()
 
+	// Find misconfigured tenants now rather than at their first request
+	if config.Server.StrictTenants {
+		validateTenants(ctx, store, notifier)
+	}
+
+	// Background jobs selected for this process (all of them unless worker.jobs says otherwise)
+	jobs := selectJobs(ctx, store, notifier, emailService, notification.NewPushService(ctx, authClient.App, store), authClient, config)
+	jobsCtx, stopJobs := context.WithCancel(ctx)
+	jobsDone := make(chan struct{})
+	go func() {
+		worker.NewRunner(store.ForService(types.ServiceWorker), worker.InstanceName()).Run(jobsCtx, jobs)
+		close(jobsDone)
+	}()
+
 	// Setup HTTP server with graceful shutdown
 	addr := fmt.Sprintf(":%d", config.Server.Port)
 	srv := &http.Server{
-		Addr: addr,
+		Addr:              addr,
+		ReadHeaderTimeout: 10 * time.Second,
 		Handler: api.CORSHandler(webapi.CORSConfig{
 			AllowedOrigins:   config.Cors.AllowedOrigins,
 			AllowedMethods:   config.Cors.AllowedMethods,
@@ -116,13 +189,164 @@ func Run(ctx context.Context) {
 	<-stop
 	logger.Info("Shutting down server...")
 
-	// Context for shutdown with timeout
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	// Fail readiness first and keep serving while load balancers stop routing here; a second
+	// signal skips the wait
+	api.Drain()
+	logger.Infof("Draining for %s before closing the listener", drainDelay)
+	select {
+	case <-time.After(drainDelay):
+	case <-stop:
+		logger.Warning("Second signal received; closing the listener now")
+	}
+
+	// Stop accepting connections and wait for in-flight requests, then cut off any still running
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(shutdownCtx); err != nil {
-		logger.Fatalf("Server forced to shutdown: %v", err)
+		logger.Errorf("Requests still running after %s were cut off: %v", shutdownTimeout, err)
+		srv.Close()
+	}
+
+	// Let running jobs finish and hand their leases to other processes
+	stopJobs()
+	<-jobsDone
+
+	// Close tenant connection pools and the WellTaxPro database
+	if err := store.Close(); err != nil {
+		logger.Errorf("Failed to close database connections: %v", err)
 	}
 
 	logger.Info("Server exiting")
 }
+
+// flushTraces exports the spans still buffered before the process exits
+func flushTraces(shutdown func(context.Context) error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := shutdown(ctx); err != nil {
+		logger.Errorf("Failed to flush traces: %v", err)
+	}
+}
+
+// connectDatabase opens and verifies the connection pool to the WellTaxPro database
+func connectDatabase(cfg DatabaseConfig) *sql.DB {
+	dbConnection := fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s binary_parameters=yes",
+		cfg.Host,
+		cfg.Port,
+		cfg.User,
+		cfg.Password,
+		cfg.DBName,
+		cfg.SslMode,
+	)
+
+	logger.Info("Connecting to WellTaxPro database")
+
+	connector, err := pq.NewConnector(dbConnection)
+	if err != nil {
+		logger.Fatalf("Failed connecting to database: %v", err)
+	}
+	db := sql.OpenDB(tracing.WrapConnector(connector))
+
+	// Set up database connection pool
+	db.SetMaxOpenConns(10)
+	db.SetMaxIdleConns(5)
+	db.SetConnMaxLifetime(60 * time.Second)
+
+	// Test connection
+	if err := db.Ping(); err != nil {
+		logger.Fatalf("Failed to ping database: %v", err)
+	}
+
+	logger.Info("Successfully connected to WellTaxPro database")
+	return db
+}
+
+// validateTenants runs the schema check of every active tenant, which also flags adapter types
+// with no adapter, logs each misconfigured tenant and notifies admins. Results are saved to the
+// schema check report; nothing here stops the server.
+func validateTenants(ctx context.Context, s *store.Store, notifier *notification.Dispatcher) {
+	logger.Info("Validating tenant adapters and schemas")
+
+	checks, err := s.RunSchemaChecks(ctx)
+	if err != nil {
+		logger.Errorf("Failed to validate tenants: %v", err)
+		return
+	}
+
+	var failing []string
+	for _, check := range checks {
+		if check.Healthy {
+			continue
+		}
+		failing = append(failing, check.TenantID)
+		if check.Error != nil {
+			logger.Errorf("Tenant %s could not be checked: %s", check.TenantID, *check.Error)
+			continue
+		}
+		for _, issue := range check.Issues {
+			if issue.Type == types.SchemaUnknownAdapter {
+				logger.Errorf("Tenant %s has unknown adapter type %q and is served by the mywelltax adapter", check.TenantID, issue.Actual)
+			}
+		}
+		logger.Errorf("Tenant %s is misconfigured: %d schema issues", check.TenantID, len(check.Issues))
+	}
+
+	if len(failing) == 0 {
+		logger.Infof("All %d active tenants passed validation", len(checks))
+		return
+	}
+	logger.Errorf("%d of %d active tenants failed validation: %s", len(failing), len(checks), strings.Join(failing, ", "))
+	notifier.NotifyAdmins(types.NotificationCategoryConnection, nil,
+		fmt.Sprintf("%d tenants failed startup validation", len(failing)),
+		fmt.Sprintf("These tenants have an unknown adapter type, an invalid schema prefix, schema issues or an unreachable database: %s. See the schema check report for details.", strings.Join(failing, ", ")),
+	)
+}
+
+// newAnchorer creates the audit chain anchorer; anchoring stays off without an anchor bucket
+func newAnchorer(ctx context.Context, s *store.Store, config *Config) *auditchain.Anchorer {
+	anchorConfig, _, err := config.Audit.anchorConfig()
+	if err != nil {
+		logger.Fatalf("Invalid audit settings: %v", err)
+	}
+	anchorer, err := auditchain.New(ctx, s, anchorConfig, worker.InstanceName())
+	if err != nil {
+		logger.Fatalf("Failed to initialize audit anchoring: %v", err)
+	}
+	if !anchorer.Enabled() {
+		logger.Warning("audit.anchorProvider is not set; audit chain heads are not anchored to WORM storage")
+	}
+	return anchorer
+}
+
+// selectJobs builds the background jobs configured for this process; users is nil when Firebase
+// is unavailable
+func selectJobs(ctx context.Context, s *store.Store, notifier *notification.Dispatcher, emailService *notification.EmailService, push *notification.PushService, users auth.UserAdmin, config *Config) []*worker.Job {
+	expiryConfig, err := config.Documents.expiryConfig()
+	if err != nil {
+		logger.Fatalf("Invalid document settings: %v", err)
+	}
+	_, anchorInterval, err := config.Audit.anchorConfig()
+	if err != nil {
+		logger.Fatalf("Invalid audit settings: %v", err)
+	}
+	clickRetentionDays, err := config.Affiliates.clickRetentionDays()
+	if err != nil {
+		logger.Fatalf("Invalid affiliate settings: %v", err)
+	}
+	links, err := notification.NewLinks(config.Server.AppURL)
+	if err != nil {
+		logger.Fatalf("Invalid server.appUrl: %v", err)
+	}
+
+	scanner := config.Scan.scanner()
+	workerStore := s.ForService(types.ServiceWorker)
+	all := worker.Jobs(workerStore, notifier, config.Notifications.DigestHourUTC, config.Ingest.ingestConfig(scanner),
+		expiryConfig, emailService, push, newAnchorer(ctx, workerStore, config), anchorInterval, clickRetentionDays, users, scanner,
+		config.SMS.provider(), links)
+	jobs, err := worker.Select(all, config.Worker.Jobs)
+	if err != nil {
+		logger.Fatalf("Invalid worker.jobs: %v", err)
+	}
+	return jobs
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+	"welltaxpro/src/internal/auth"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/notification"
+	"welltaxpro/src/internal/store"
+	"welltaxpro/src/internal/tracing"
+	"welltaxpro/src/internal/types"
+	"welltaxpro/src/internal/worker"
+
+	firebase "firebase.google.com/go/v4"
+	"github.com/google/logger"
+)
+
+// RunWorker runs the configured background jobs without serving the API.
+// It reads the same configuration file as the API server; worker.jobs selects its jobs.
+func RunWorker(ctx context.Context) {
+	args, err := parseArguments()
+	if err != nil {
+		logger.Fatalf("Failed parsing arguments: %v", err)
+	}
+
+	config, err := getConfiguration(args)
+	if err != nil {
+		logger.Fatalf("Failed getting configuration: %v", err)
+	}
+
+	// Initialize encryption system (tenant database passwords)
+	if err := crypto.InitEncryption(); err != nil {
+		logger.Fatalf("Failed to initialize encryption: %v", err)
+	}
+
+	shutdownTracing, err := tracing.Setup(ctx, "welltaxpro-worker", config.Tracing.tracingConfig())
+	if err != nil {
+		logger.Fatalf("Failed to set up tracing: %v", err)
+	}
+	defer flushTraces(shutdownTracing)
+
+	db := connectDatabase(config.Database)
+	defer db.Close()
+
+	poolIdleTimeout, err := config.Database.tenantPoolIdleTimeout()
+	if err != nil {
+		logger.Fatalf("Invalid database settings: %v", err)
+	}
+	s := store.NewStore(ctx, db, poolIdleTimeout)
+	defer s.Close()
+	crypto.SetDataKeySource(s)
+
+	emailQueue, err := config.SendGrid.Queue.queueConfig()
+	if err != nil {
+		logger.Fatalf("Invalid email queue limits: %v", err)
+	}
+	emailService := notification.NewEmailService(
+		config.SendGrid.APIKey,
+		config.SendGrid.DefaultFromEmail,
+		config.SendGrid.DefaultFromName,
+		emailQueue,
+	)
+	notifier := notification.NewDispatcher(s.ForService(types.ServiceNotifier), emailService,
+		notification.NewSlackService(config.Notifications.SlackBotToken))
+
+	// Firebase is only needed for portal push reminders and user reconciliation; without it the
+	// worker still runs
+	var app *firebase.App
+	var users auth.UserAdmin
+	if authClient, err := auth.InitAuth(config.Firebase.APIKey, config.Firebase.ServiceAccountPath); err != nil {
+		logger.Warningf("Failed to initialize Firebase, portal push reminders and user reconciliation disabled: %v", err)
+	} else {
+		app = authClient.App
+		users = authClient
+	}
+
+	jobs := selectJobs(ctx, s, notifier, emailService, notification.NewPushService(ctx, app, s), users, config)
+	if len(jobs) == 0 {
+		logger.Fatalf("worker.jobs selects no jobs; nothing to run")
+	}
+
+	// Liveness endpoint for platforms that expect workers to listen on a port
+	if config.Worker.HealthPort > 0 {
+		mux := http.NewServeMux()
+		mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"status":"ok"}`))
+		})
+		srv := &http.Server{
+			Addr:              fmt.Sprintf(":%d", config.Worker.HealthPort),
+			Handler:           mux,
+			ReadHeaderTimeout: 10 * time.Second,
+		}
+		go func() {
+			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				logger.Fatalf("Failed to start worker health endpoint: %v", err)
+			}
+		}()
+		defer srv.Close()
+	}
+
+	runCtx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	worker.NewRunner(s.ForService(types.ServiceWorker), worker.InstanceName()).Run(runCtx, jobs)
+	logger.Info("Worker exiting")
+}
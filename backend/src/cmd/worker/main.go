@@ -0,0 +1,20 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"welltaxpro/src/cmd/server"
+
+	"github.com/google/logger"
+)
+
+func main() {
+	fmt.Printf("Starting WellTaxPro worker\n")
+
+	// start Logger Settings
+	logger.Init("WellTaxPro", true, false, io.Discard)
+	ctx := context.Background()
+
+	server.RunWorker(ctx)
+}
@@ -1,7 +1,10 @@
 package adapter
 
 import (
+	"context"
 	"database/sql"
+	"time"
+	"welltaxpro/src/internal/idgen"
 	"welltaxpro/src/internal/types"
 )
 
@@ -9,87 +12,305 @@ import (
 // Each tax platform (MyWellTax, Drake, Lacerte, etc.) implements this interface
 type ClientAdapter interface {
 	// GetClients retrieves all clients from the tenant's database
-	GetClients(db *sql.DB, schemaPrefix string) ([]*types.Client, error)
+	// Archived clients are excluded unless includeArchived is true
+	GetClients(ctx context.Context, db *sql.DB, schemaPrefix string, includeArchived bool) ([]*types.Client, error)
+
+	// GetClientPage retrieves one page of clients, newest first, with the total count
+	GetClientPage(ctx context.Context, db *sql.DB, schemaPrefix string, includeArchived bool, page types.PageRequest) (*types.ClientPage, error)
+
+	// StreamClients calls fn with each client as it is read, newest first, stopping when ctx is done
+	// Archived clients are excluded unless includeArchived is true
+	StreamClients(ctx context.Context, db *sql.DB, schemaPrefix string, includeArchived bool, fn func(*types.Client) error) error
+
+	// SearchClients returns the clients best matching a search, best first, each with a summary
+	// of its filings. Names, email and phone match in part; an SSN matches on its last four.
+	SearchClients(ctx context.Context, db *sql.DB, schemaPrefix string, query *types.ClientSearchQuery) ([]*types.ClientSearchResult, error)
 
 	// GetClientByID retrieves a specific client by ID from the tenant's database
-	GetClientByID(db *sql.DB, schemaPrefix string, clientID string) (*types.Client, error)
+	GetClientByID(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (*types.Client, error)
+
+	// ArchiveClient marks a client as archived (moved firms, deceased, etc.)
+	ArchiveClient(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string, reason string) (*types.Client, error)
+
+	// UnarchiveClient restores an archived client
+	UnarchiveClient(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (*types.Client, error)
+
+	// IsClientArchived reports whether a client is archived
+	IsClientArchived(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (bool, error)
+
+	// MarkClientDeceased records the death date of the taxpayer or spouse
+	MarkClientDeceased(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string, person string, deathDate string) error
+
+	// GetSSNRecords returns encrypted taxpayer and spouse SSNs for tenant integrity checks
+	GetSSNRecords(ctx context.Context, db *sql.DB, schemaPrefix string) ([]*types.SSNRecord, error)
+
+	// ReencryptSSNs rewrites every stored SSN that reencrypt changes and returns how many it rewrote
+	ReencryptSSNs(ctx context.Context, db *sql.DB, schemaPrefix string, reencrypt ReencryptSSN) (int, error)
 
 	// GetClientComprehensive retrieves all data related to a client (filings, dependents, etc.)
-	GetClientComprehensive(db *sql.DB, schemaPrefix string, clientID string) (*types.ClientComprehensive, error)
+	GetClientComprehensive(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (*types.ClientComprehensive, error)
 
 	// GetClientsByFilings retrieves clients with their filings (paginated)
 	// Returns ClientComprehensive for each client with all their filings
 	// Filtering should be done on the frontend
-	GetClientsByFilings(db *sql.DB, schemaPrefix string, limit int, offset int) ([]*types.ClientComprehensive, error)
+	GetClientsByFilings(ctx context.Context, db *sql.DB, schemaPrefix string, limit int, offset int) ([]*types.ClientComprehensive, error)
+
+	// GetClientsByFilingsPage retrieves one page of clients with filings, most recent filing first
+	GetClientsByFilingsPage(ctx context.Context, db *sql.DB, schemaPrefix string, page types.PageRequest) (*types.FilingPage, error)
+
+	// StreamClientsByFilings calls fn with each client with filings, loaded one at a time,
+	// most recent filing first, stopping when ctx is done
+	StreamClientsByFilings(ctx context.Context, db *sql.DB, schemaPrefix string, fn func(*types.ClientComprehensive) error) error
+
+	// CountFilings counts the filings for a tax year (total, completed)
+	CountFilings(ctx context.Context, db *sql.DB, schemaPrefix string, year int) (int, int, error)
+
+	// CountOpenFilings counts the filings of every tax year that are not completed
+	CountOpenFilings(ctx context.Context, db *sql.DB, schemaPrefix string) (int, error)
+
+	// GetFilingYear returns the tax year of a filing
+	GetFilingYear(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) (int, error)
+
+	// GetFilingWorkflow returns the client, tax year and stored status of a filing
+	GetFilingWorkflow(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) (*types.FilingWorkflow, error)
+
+	// SetFilingStatus moves a filing from status from to status to, failing with a conflict when
+	// its stored status is no longer from. ACCEPTED also marks the filing completed.
+	SetFilingStatus(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string, from string, to string) error
 
 	// GetAffiliates retrieves all affiliates from the tenant's database
-	GetAffiliates(db *sql.DB, schemaPrefix string, activeOnly bool) ([]*types.Affiliate, error)
+	GetAffiliates(ctx context.Context, db *sql.DB, schemaPrefix string, activeOnly bool) ([]*types.Affiliate, error)
 
 	// GetAffiliateByID retrieves a specific affiliate by ID from the tenant's database
-	GetAffiliateByID(db *sql.DB, schemaPrefix string, affiliateID string) (*types.Affiliate, error)
+	GetAffiliateByID(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string) (*types.Affiliate, error)
 
 	// CreateAffiliate creates a new affiliate in the tenant's database
-	CreateAffiliate(db *sql.DB, schemaPrefix string, affiliate *types.Affiliate) (*types.Affiliate, error)
+	CreateAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliate *types.Affiliate) (*types.Affiliate, error)
 
 	// UpdateAffiliate updates an existing affiliate in the tenant's database
-	UpdateAffiliate(db *sql.DB, schemaPrefix string, affiliateID string, affiliate *types.Affiliate) (*types.Affiliate, error)
+	UpdateAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, affiliate *types.Affiliate) (*types.Affiliate, error)
+
+	// PatchAffiliate sets only the affiliate columns the patch provides
+	PatchAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, patch *types.AffiliatePatch) (*types.Affiliate, error)
 
 	// GetCommissionsByAffiliate retrieves commissions for a specific affiliate (or all if affiliateID is nil)
-	GetCommissionsByAffiliate(db *sql.DB, schemaPrefix string, affiliateID *string, status *string, limit int) ([]*types.Commission, error)
+	// A non-nil commissionIDs restricts the result to those commissions
+	GetCommissionsByAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, status *string, commissionIDs []string, limit int) ([]*types.Commission, error)
+
+	// GetCommissionPage retrieves one page of commissions, newest first, with the same filters
+	GetCommissionPage(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, status *string, commissionIDs []string, page types.PageRequest) (*types.CommissionPage, error)
+
+	// GetOverdueCommissions retrieves up to limit PENDING commissions created before createdBefore,
+	// oldest first, with how many there are in total
+	GetOverdueCommissions(ctx context.Context, db *sql.DB, schemaPrefix string, createdBefore time.Time, limit int) ([]*types.Commission, int, error)
 
 	// GetAffiliateStats calculates aggregate statistics for an affiliate
-	GetAffiliateStats(db *sql.DB, schemaPrefix string, affiliateID string) (*types.AffiliateStats, error)
+	GetAffiliateStats(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string) (*types.AffiliateStats, error)
+
+	// RecordAffiliateClick stores a visit through an affiliate's tracking link
+	RecordAffiliateClick(ctx context.Context, db *sql.DB, schemaPrefix string, click *types.AffiliateClick) error
+
+	// RollupAffiliateClicks aggregates clicks made before cutoff into daily per-affiliate buckets
+	// and deletes the raw rows, returning the number of clicks rolled up
+	RollupAffiliateClicks(ctx context.Context, db *sql.DB, schemaPrefix string, cutoff time.Time) (int, error)
+
+	// RecordCheckoutPayment records the payment of a checkout session for a filing, or updates the
+	// status of the payment already recorded for the session, and reports any commission created
+	// for the payment
+	RecordCheckoutPayment(ctx context.Context, db *sql.DB, schemaPrefix string, payment *types.Payment) (*types.CheckoutPayment, error)
+
+	// CreateCommission inserts a new commission record
+	CreateCommission(ctx context.Context, db *sql.DB, schemaPrefix string, commission *types.Commission) (*types.Commission, error)
+
+	// EvaluateCommissionFraud runs fraud rules against a commission before it is created
+	EvaluateCommissionFraud(ctx context.Context, db *sql.DB, schemaPrefix string, commission *types.Commission, ipAddress string, rules *types.FraudRules) ([]types.FraudFlag, error)
+
+	// ApproveCommission approves a pending or under-review commission
+	ApproveCommission(ctx context.Context, db *sql.DB, schemaPrefix string, commissionID string) (*types.Commission, error)
+
+	// ApproveCommissions approves the listed commissions, or up to limit matching filter, in one
+	// transaction and reports the outcome for each
+	ApproveCommissions(ctx context.Context, db *sql.DB, schemaPrefix string, commissionIDs []string, filter *types.CommissionFilter, limit int) (*types.BulkCommissionReport, error)
+
+	// MarkCommissionPaid pays an approved commission outside a batch by putting it in a payout of
+	// its own and recording the payment against it, as MarkPayoutPaid does
+	MarkCommissionPaid(ctx context.Context, db *sql.DB, schemaPrefix string, commissionID string, payment *types.PayoutPayment) (*types.AffiliatePayout, []*types.Commission, error)
+
+	// CancelCommission cancels a commission, appending the reason to existing notes
+	CancelCommission(ctx context.Context, db *sql.DB, schemaPrefix string, commissionID string, reason string) (*types.Commission, error)
+
+	// CreatePayoutBatch creates a payout for every active affiliate whose approved commissions not yet
+	// in a payout reach their payout threshold, attaching the commissions to it
+	CreatePayoutBatch(ctx context.Context, db *sql.DB, schemaPrefix string, createdBy string) (*types.PayoutBatch, error)
+
+	// GetPayoutBatches retrieves the latest payout batches with their payout counts
+	GetPayoutBatches(ctx context.Context, db *sql.DB, schemaPrefix string, limit int) ([]*types.PayoutBatch, error)
+
+	// GetPayoutBatch retrieves a payout batch with its payouts
+	GetPayoutBatch(ctx context.Context, db *sql.DB, schemaPrefix string, batchID string) (*types.PayoutBatch, error)
+
+	// GetPayout retrieves a single payout with its payments
+	GetPayout(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string) (*types.AffiliatePayout, error)
 
-	// ApproveCommission approves a pending commission
-	ApproveCommission(db *sql.DB, schemaPrefix string, commissionID string) (*types.Commission, error)
+	// GetAffiliatePayments retrieves an affiliate's latest payout payments, newest first
+	GetAffiliatePayments(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, limit int) ([]*types.AffiliatePayoutPayment, error)
 
-	// MarkCommissionPaid marks an approved commission as paid
-	MarkCommissionPaid(db *sql.DB, schemaPrefix string, commissionID string) (*types.Commission, error)
+	// GetAffiliatePaymentTotals sums the payout payments made to each affiliate in a calendar year by
+	// payment date, the amounts reported on their 1099s
+	GetAffiliatePaymentTotals(ctx context.Context, db *sql.DB, schemaPrefix string, year int) ([]*types.AffiliatePaymentTotal, error)
 
-	// CancelCommission cancels a commission with a reason
-	CancelCommission(db *sql.DB, schemaPrefix string, commissionID string, reason string) (*types.Commission, error)
+	// GetAffiliateStatement retrieves an affiliate's payout payments of a calendar year by payment
+	// date, oldest first, with their total
+	GetAffiliateStatement(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, year int) (*types.AffiliateStatement, error)
 
-	// GetDiscountCodes retrieves discount codes for a tenant, optionally filtered by affiliate
-	GetDiscountCodes(db *sql.DB, schemaPrefix string, affiliateID *string, activeOnly bool) ([]*types.DiscountCode, error)
+	// StartPayoutTransfer checks a payout against its commissions and marks it PROCESSING
+	StartPayoutTransfer(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string) (*types.AffiliatePayout, error)
+
+	// RecordPayoutFailure records a transfer error; declined transfers fail the payout
+	RecordPayoutFailure(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string, message string, declined bool) error
+
+	// MarkPayoutPaid records a payment against a payout; once it is paid in full the payout and its
+	// commissions are marked PAID atomically and the commissions returned
+	MarkPayoutPaid(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string, payment *types.PayoutPayment) (*types.AffiliatePayout, []*types.Commission, error)
+
+	// CancelPayout cancels an unpaid payout and releases its commissions
+	CancelPayout(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string) (*types.AffiliatePayout, error)
+
+	// GetDiscountCodes retrieves discount codes for a tenant, optionally filtered by affiliate and campaign
+	GetDiscountCodes(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, campaign *string, activeOnly bool) ([]*types.DiscountCode, error)
+
+	// GetDiscountCodePage retrieves one page of discount codes, newest first, with the same filters
+	GetDiscountCodePage(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, campaign *string, activeOnly bool, page types.PageRequest) (*types.DiscountCodePage, error)
 
 	// GetDiscountCodeByID retrieves a specific discount code by ID
-	GetDiscountCodeByID(db *sql.DB, schemaPrefix string, codeID string) (*types.DiscountCode, error)
+	GetDiscountCodeByID(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string) (*types.DiscountCode, error)
 
 	// GetDiscountCodeByCode retrieves a discount code by its code string
-	GetDiscountCodeByCode(db *sql.DB, schemaPrefix string, code string) (*types.DiscountCode, error)
+	GetDiscountCodeByCode(ctx context.Context, db *sql.DB, schemaPrefix string, code string) (*types.DiscountCode, error)
 
 	// CreateDiscountCode creates a new discount code for an affiliate
-	CreateDiscountCode(db *sql.DB, schemaPrefix string, discountCode *types.DiscountCode) (*types.DiscountCode, error)
+	CreateDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, discountCode *types.DiscountCode) (*types.DiscountCode, error)
+
+	// CreateDiscountCodes inserts a batch of discount codes atomically; any existing code fails the batch
+	CreateDiscountCodes(ctx context.Context, db *sql.DB, schemaPrefix string, discountCodes []*types.DiscountCode) ([]*types.DiscountCode, error)
+
+	// GetDiscountCampaignReports summarizes usage per campaign (or for one campaign if set)
+	GetDiscountCampaignReports(ctx context.Context, db *sql.DB, schemaPrefix string, campaign *string) ([]*types.DiscountCampaignReport, error)
 
 	// UpdateDiscountCode updates an existing discount code
-	UpdateDiscountCode(db *sql.DB, schemaPrefix string, codeID string, discountCode *types.DiscountCode) (*types.DiscountCode, error)
+	UpdateDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string, discountCode *types.DiscountCode) (*types.DiscountCode, error)
+
+	// PatchDiscountCode sets only the discount code columns the patch provides
+	PatchDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string, patch *types.DiscountCodePatch) (*types.DiscountCode, error)
 
 	// DeactivateDiscountCode deactivates a discount code
-	DeactivateDiscountCode(db *sql.DB, schemaPrefix string, codeID string) error
+	DeactivateDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string) error
+
+	// GetCampaignMetrics aggregates attribution per campaign key (lower-cased in the result)
+	GetCampaignMetrics(ctx context.Context, db *sql.DB, schemaPrefix string, keys []string) (map[string]*types.CampaignMetrics, error)
+
+	// GetStateFilings retrieves the state returns attached to a filing
+	GetStateFilings(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) ([]*types.StateFiling, error)
+
+	// GetStateFilingByID retrieves a specific state return
+	GetStateFilingByID(ctx context.Context, db *sql.DB, schemaPrefix string, stateFilingID string) (*types.StateFiling, error)
+
+	// CreateStateFiling adds a state return to a filing
+	CreateStateFiling(ctx context.Context, db *sql.DB, schemaPrefix string, stateFiling *types.StateFiling) (*types.StateFiling, error)
+
+	// UpdateStateFiling updates residency, status and fee of a state return
+	UpdateStateFiling(ctx context.Context, db *sql.DB, schemaPrefix string, stateFilingID string, stateFiling *types.StateFiling) (*types.StateFiling, error)
+
+	// DeleteStateFiling removes a state return
+	DeleteStateFiling(ctx context.Context, db *sql.DB, schemaPrefix string, stateFilingID string) error
+
+	// GetStateFilingReport aggregates state return volume (optionally for one tax year)
+	GetStateFilingReport(ctx context.Context, db *sql.DB, schemaPrefix string, year *int) ([]*types.StateFilingReport, error)
+
+	// GetFilingResult retrieves the recorded outcome (AGI, tax, refund/owed) of a filing
+	GetFilingResult(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) (*types.FilingResult, error)
+
+	// UpsertFilingResult records or replaces the outcome of a filing
+	UpsertFilingResult(ctx context.Context, db *sql.DB, schemaPrefix string, result *types.FilingResult) (*types.FilingResult, error)
+
+	// GetRefundTrackings retrieves the per-jurisdiction refund tracking of a filing
+	GetRefundTrackings(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) ([]*types.RefundTracking, error)
+
+	// UpsertRefundTracking records or replaces the refund status for one jurisdiction
+	UpsertRefundTracking(ctx context.Context, db *sql.DB, schemaPrefix string, tracking *types.RefundTracking) (*types.RefundTracking, error)
+
+	// DeleteRefundTracking removes the refund record for one jurisdiction of a filing
+	DeleteRefundTracking(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string, jurisdiction string) error
 
 	// CreateDocument creates a new document record in the tenant's database
-	CreateDocument(db *sql.DB, schemaPrefix string, document *types.Document) (*types.Document, error)
+	CreateDocument(ctx context.Context, db *sql.DB, schemaPrefix string, document *types.Document) (*types.Document, error)
 
 	// GetDocumentByID retrieves a specific document by ID
-	GetDocumentByID(db *sql.DB, schemaPrefix string, documentID string) (*types.Document, error)
+	GetDocumentByID(ctx context.Context, db *sql.DB, schemaPrefix string, documentID string) (*types.Document, error)
 
 	// GetDocumentsByFilingID retrieves all documents associated with a filing
-	GetDocumentsByFilingID(db *sql.DB, schemaPrefix string, filingID string) ([]*types.Document, error)
+	GetDocumentsByFilingID(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) ([]*types.Document, error)
+
+	// GetDocumentsByYear retrieves the documents of every filing of a tax year
+	GetDocumentsByYear(ctx context.Context, db *sql.DB, schemaPrefix string, year int) ([]*types.Document, error)
 
 	// DeleteDocument removes a document record from the tenant's database
-	DeleteDocument(db *sql.DB, schemaPrefix string, documentID string) error
+	DeleteDocument(ctx context.Context, db *sql.DB, schemaPrefix string, documentID string) error
+
+	// StreamDocuments calls fn with each of the tenant's documents, oldest first, stopping when ctx is done
+	StreamDocuments(ctx context.Context, db *sql.DB, schemaPrefix string, fn func(*types.Document) error) error
+
+	// SetDocumentPath points a document record at another file in the tenant's bucket
+	SetDocumentPath(ctx context.Context, db *sql.DB, schemaPrefix string, documentID string, filePath string) error
+
+	// ExportClientRows reads every row belonging to a client, parents before children, for a support export
+	ExportClientRows(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) ([]*types.ExportTable, error)
+
+	// ImportClientRows inserts exported rows in one transaction, skipping rows that already exist;
+	// SSN values are passed through reencrypt
+	ImportClientRows(ctx context.Context, db *sql.DB, schemaPrefix string, tables []*types.ExportTable, reencrypt ReencryptSSN) (int, error)
+
+	// ExpectedSchema returns the tenant tables and columns this adapter reads and writes
+	ExpectedSchema() []types.SchemaTable
+
+	// GetSchemaColumns introspects the tenant schema (table -> column -> data type)
+	GetSchemaColumns(ctx context.Context, db *sql.DB, schemaPrefix string) (map[string]map[string]string, error)
 
 	// GetAdapterType returns the unique identifier for this adapter
 	GetAdapterType() string
 }
 
-// AdapterFactory creates the appropriate adapter based on adapter type
-func NewAdapter(adapterType string) (ClientAdapter, error) {
-	switch adapterType {
+// ReencryptSSN returns a stored SSN value encrypted under the tenant's active data key and
+// whether it changed (see crypto.ReencryptTenantSSN)
+type ReencryptSSN func(value string) (string, bool, error)
+
+// AdapterTypes lists the adapter types ForTenant has an adapter for
+var AdapterTypes = []string{"mywelltax", "drake", types.SmokeAdapterType}
+
+// IsRegistered reports whether ForTenant has an adapter of adapterType. Tenants of any other type
+// are served by the MyWellTax adapter.
+func IsRegistered(adapterType string) bool {
+	for _, t := range AdapterTypes {
+		if t == adapterType {
+			return true
+		}
+	}
+	return false
+}
+
+// ForTenant creates the adapter of a tenant connection. Records it creates get IDs of the
+// tenant's UUID version.
+func ForTenant(tc *types.TenantConnection) (ClientAdapter, error) {
+	ids := idgen.ForVersion(tc.IDVersion)
+	switch tc.AdapterType {
 	case "mywelltax":
-		return &MyWellTaxAdapter{}, nil
+		return &MyWellTaxAdapter{ids: ids}, nil
+	case "drake":
+		return &DrakeAdapter{ids: ids}, nil
+	case types.SmokeAdapterType:
+		return smoke, nil
 	default:
-		// Default to MyWellTax for now
-		return &MyWellTaxAdapter{}, nil
+		// Default to MyWellTax for now; schema checks flag the tenant (see IsRegistered)
+		return &MyWellTaxAdapter{ids: ids}, nil
 	}
 }
@@ -0,0 +1,308 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// clientSearchColumns are the SQL expressions of the client columns a search matches against
+type clientSearchColumns struct {
+	firstName string
+	lastName  string
+	email     string
+	phone     string
+}
+
+// clientSearchRule is one way a client can match a search. Rules are tried in order and the
+// first that holds scores the client. In conditions, $1 is the lowercased search, $2 the search
+// as a contains pattern, $3 as a prefix pattern and $4 the digits as a contains pattern (empty
+// when there are too few digits to search phone numbers).
+type clientSearchRule struct {
+	condition string
+	score     int
+	match     string
+}
+
+// clientSearchSSNScore ranks an SSN last four match between a whole email and a whole name
+const clientSearchSSNScore = 90
+
+// clientSearchMinDigits is the fewest digits searched in phone numbers
+const clientSearchMinDigits = 3
+
+// clientSearchRules returns the ranked ways a row with columns c can match a search
+func clientSearchRules(c clientSearchColumns) []clientSearchRule {
+	fullName := fmt.Sprintf(`TRIM(COALESCE(%s, '') || ' ' || COALESCE(%s, ''))`, c.firstName, c.lastName)
+	return []clientSearchRule{
+		{fmt.Sprintf(`LOWER(%s) = $1`, c.email), 100, types.ClientMatchEmail},
+		{fmt.Sprintf(`LOWER(%s) = $1`, fullName), 80, types.ClientMatchName},
+		{fmt.Sprintf(`(%s ILIKE $3 OR %s ILIKE $3 OR %s ILIKE $3)`, c.firstName, c.lastName, fullName), 60, types.ClientMatchName},
+		{fmt.Sprintf(`%s ILIKE $3`, c.email), 50, types.ClientMatchEmail},
+		{fmt.Sprintf(`($4 <> '' AND regexp_replace(COALESCE(%s, ''), '\D', '', 'g') LIKE $4)`, c.phone), 40, types.ClientMatchPhone},
+		{fmt.Sprintf(`%s ILIKE $2`, fullName), 30, types.ClientMatchName},
+		{fmt.Sprintf(`%s ILIKE $2`, c.email), 20, types.ClientMatchOther},
+	}
+}
+
+// clientSearchSelect returns the score and match expressions of a search over columns c
+func clientSearchSelect(c clientSearchColumns) (score string, match string) {
+	var scores, matches strings.Builder
+	scores.WriteString("CASE")
+	matches.WriteString("CASE")
+	for _, rule := range clientSearchRules(c) {
+		fmt.Fprintf(&scores, " WHEN %s THEN %d", rule.condition, rule.score)
+		fmt.Fprintf(&matches, " WHEN %s THEN '%s'", rule.condition, rule.match)
+	}
+	scores.WriteString(" ELSE 0 END")
+	matches.WriteString(" ELSE '' END")
+	return scores.String(), matches.String()
+}
+
+// clientSearchArgs returns the $1-$4 arguments of clientSearchRules for a search
+func clientSearchArgs(query *types.ClientSearchQuery) []interface{} {
+	escaped := escapeLike(strings.ToLower(query.Text))
+	digits := ""
+	if len(query.Digits) >= clientSearchMinDigits {
+		digits = "%" + query.Digits + "%"
+	}
+	return []interface{}{strings.ToLower(query.Text), "%" + escaped + "%", escaped + "%", digits}
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// searchScanner appends the score and match columns to a client scan
+type searchScanner struct {
+	row   interface{ Scan(...interface{}) error }
+	extra []interface{}
+}
+
+func (s searchScanner) Scan(dest ...interface{}) error {
+	return s.row.Scan(append(dest, s.extra...)...)
+}
+
+// queryClientSearch runs a search query selecting a scanner's client columns followed by the
+// score and match, returning the rows as results
+func queryClientSearch(ctx context.Context, db *sql.DB, scan func(row interface{ Scan(...interface{}) error }) (*types.Client, error), query string, args ...interface{}) ([]*types.ClientSearchResult, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search clients: %w", err)
+	}
+	defer rows.Close()
+
+	results := []*types.ClientSearchResult{}
+	for rows.Next() {
+		result := &types.ClientSearchResult{Filings: []*types.ClientSearchFiling{}}
+		client, err := scan(searchScanner{row: rows, extra: []interface{}{&result.Score, &result.MatchedOn}})
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan client: %w", err)
+		}
+		result.Client = client
+		results = append(results, result)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating clients: %w", err)
+	}
+	return results, nil
+}
+
+// matchSSNLast4 returns the IDs of the rows of (id, encrypted SSN) whose SSN ends in last4.
+// SSNs are encrypted at rest, so each is decrypted and compared here rather than in SQL.
+func matchSSNLast4(rows *sql.Rows, last4 string, limit int) ([]uuid.UUID, error) {
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		var ssn string
+		if err := rows.Scan(&id, &ssn); err != nil {
+			return nil, fmt.Errorf("failed to scan SSN: %w", err)
+		}
+		if strings.HasSuffix(crypto.MaskSSN(ssn), "-"+last4) {
+			ids = append(ids, id)
+			if len(ids) >= limit {
+				break
+			}
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating SSNs: %w", err)
+	}
+	return ids, nil
+}
+
+// mergeClientSearch adds the SSN matches to the text matches, keeping each client's best score,
+// and returns the best limit results. Equal scores are ordered by name.
+func mergeClientSearch(results []*types.ClientSearchResult, ssnMatches []*types.Client, limit int) []*types.ClientSearchResult {
+	byID := map[uuid.UUID]*types.ClientSearchResult{}
+	for _, result := range results {
+		byID[result.Client.ID] = result
+	}
+	for _, client := range ssnMatches {
+		if result, ok := byID[client.ID]; ok {
+			if result.Score < clientSearchSSNScore {
+				result.Score, result.MatchedOn = clientSearchSSNScore, types.ClientMatchSSN
+			}
+			continue
+		}
+		result := &types.ClientSearchResult{Client: client, Score: clientSearchSSNScore, MatchedOn: types.ClientMatchSSN, Filings: []*types.ClientSearchFiling{}}
+		byID[client.ID] = result
+		results = append(results, result)
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return clientSortName(results[i].Client) < clientSortName(results[j].Client)
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// clientSortName is a client's name as "last first", lowercased
+func clientSortName(c *types.Client) string {
+	var first, last string
+	if c.FirstName != nil {
+		first = *c.FirstName
+	}
+	if c.LastName != nil {
+		last = *c.LastName
+	}
+	return strings.ToLower(last + " " + first)
+}
+
+// attachSearchFilings sets the filings of each result from rows of (client ID, filing ID, year,
+// completed), which must be ordered newest year first
+func attachSearchFilings(results []*types.ClientSearchResult, rows *sql.Rows) error {
+	defer rows.Close()
+
+	byID := map[uuid.UUID]*types.ClientSearchResult{}
+	for _, result := range results {
+		byID[result.Client.ID] = result
+	}
+	for rows.Next() {
+		var clientID uuid.UUID
+		filing := &types.ClientSearchFiling{}
+		if err := rows.Scan(&clientID, &filing.FilingID, &filing.Year, &filing.Completed); err != nil {
+			return fmt.Errorf("failed to scan filing: %w", err)
+		}
+		if result, ok := byID[clientID]; ok {
+			result.Filings = append(result.Filings, filing)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating filings: %w", err)
+	}
+	return nil
+}
+
+// searchResultIDs returns the client IDs of results
+func searchResultIDs(results []*types.ClientSearchResult) []string {
+	ids := make([]string, len(results))
+	for i, result := range results {
+		ids[i] = result.Client.ID.String()
+	}
+	return ids
+}
+
+// clientSearchSpec describes where a tax platform keeps what a client search reads
+type clientSearchSpec struct {
+	table        string // Schema-qualified client table
+	columns      string // Client columns read by scan
+	scan         func(row interface{ Scan(...interface{}) error }) (*types.Client, error)
+	search       clientSearchColumns
+	where        string // Condition every client row meets, such as role = 'user'
+	archived     string // Condition of an archived client row
+	ssn          string // Encrypted taxpayer SSN column
+	filingsQuery string // Selects (client ID, filing ID, year, completed) of clients $1, newest year first
+}
+
+// searchClients runs a ranked client search over spec. Names, email and phone are matched in SQL;
+// an SSN last four is matched by decrypting the SSNs of the tenant's clients.
+func searchClients(ctx context.Context, db *sql.DB, spec clientSearchSpec, query *types.ClientSearchQuery) ([]*types.ClientSearchResult, error) {
+	where := spec.where
+	if !query.IncludeArchived {
+		where += fmt.Sprintf(" AND NOT (%s)", spec.archived)
+	}
+
+	results := []*types.ClientSearchResult{}
+	if query.Text != "" {
+		score, match := clientSearchSelect(spec.search)
+		textQuery := fmt.Sprintf(`
+			SELECT * FROM (
+				SELECT %s, %s AS score, %s AS matched_on
+				FROM %s
+				WHERE %s
+			) matches
+			WHERE score > 0
+			ORDER BY score DESC, LOWER(COALESCE(%s, '')), LOWER(COALESCE(%s, ''))
+			LIMIT %d
+		`, spec.columns, score, match, spec.table, where, spec.search.lastName, spec.search.firstName, query.Limit)
+
+		var err error
+		results, err = queryClientSearch(ctx, db, spec.scan, textQuery, clientSearchArgs(query)...)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var ssnMatches []*types.Client
+	if query.SSNLast4 != "" {
+		rows, err := db.QueryContext(ctx, fmt.Sprintf(`SELECT id, %s FROM %s WHERE %s AND %s IS NOT NULL AND %s <> ''`,
+			spec.ssn, spec.table, where, spec.ssn, spec.ssn))
+		if err != nil {
+			return nil, fmt.Errorf("failed to query SSNs: %w", err)
+		}
+		ids, err := matchSSNLast4(rows, query.SSNLast4, query.Limit)
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) > 0 {
+			idStrings := make([]string, len(ids))
+			for i, id := range ids {
+				idStrings[i] = id.String()
+			}
+			rows, err := db.QueryContext(ctx, fmt.Sprintf(`SELECT %s FROM %s WHERE id = ANY($1::uuid[])`, spec.columns, spec.table), pq.Array(idStrings))
+			if err != nil {
+				return nil, fmt.Errorf("failed to query clients: %w", err)
+			}
+			defer rows.Close()
+			for rows.Next() {
+				client, err := spec.scan(rows)
+				if err != nil {
+					return nil, fmt.Errorf("failed to scan client: %w", err)
+				}
+				ssnMatches = append(ssnMatches, client)
+			}
+			if err := rows.Err(); err != nil {
+				return nil, fmt.Errorf("error iterating clients: %w", err)
+			}
+		}
+	}
+
+	results = mergeClientSearch(results, ssnMatches, query.Limit)
+	if len(results) == 0 {
+		return results, nil
+	}
+
+	rows, err := db.QueryContext(ctx, spec.filingsQuery, pq.Array(searchResultIDs(results)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to query filings: %w", err)
+	}
+	if err := attachSearchFilings(results, rows); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
@@ -0,0 +1,386 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"strings"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/idgen"
+	"welltaxpro/src/internal/sqlident"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// DrakeAdapter implements ClientAdapter for tenants whose data is loaded from a Drake Tax export
+// (tenant_connections.adapter_type = 'drake'). One clients row holds the taxpayer and spouse and
+// each tax year is a returns row. Drake has no affiliate program, state return tracking or
+// filing results, so those operations fail with a validation error.
+type DrakeAdapter struct {
+	ids idgen.Generator // IDs of created records; version 7 when nil
+}
+
+// newID returns the ID of a record about to be created
+func (a *DrakeAdapter) newID() uuid.UUID {
+	if a.ids == nil {
+		return idgen.NewV7()
+	}
+	return a.ids()
+}
+
+// drakeUnsupported is returned by operations the Drake schema has no data for
+func drakeUnsupported(operation string) error {
+	return apperr.Validation("%s is not supported by the Drake adapter", operation)
+}
+
+// GetAdapterType returns the unique identifier for this adapter
+func (a *DrakeAdapter) GetAdapterType() string {
+	return "drake"
+}
+
+// drakeClientColumns are read by scanDrakeClient
+const drakeClientColumns = `id, tp_first_name, tp_middle_initial, tp_last_name, COALESCE(tp_email, ''), tp_cell_phone,
+		tp_dob::text, tp_ssn, address, apt, city, state, zip, created_at::text, tp_death_date::text,
+		archived_at::text, archive_reason`
+
+// scanDrakeClient reads drakeClientColumns into a client with its SSN masked
+func scanDrakeClient(row interface{ Scan(...interface{}) error }) (*types.Client, error) {
+	client := &types.Client{Role: "user"}
+	var ssn, zip sql.NullString
+	err := row.Scan(&client.ID, &client.FirstName, &client.MiddleName, &client.LastName, &client.Email, &client.Phone,
+		&client.Dob, &ssn, &client.Address1, &client.Address2, &client.City, &client.State, &zip, &client.CreatedAt,
+		&client.DeathDate, &client.ArchivedAt, &client.ArchiveReason)
+	if err != nil {
+		return nil, err
+	}
+
+	if ssn.Valid && ssn.String != "" {
+		masked := crypto.MaskSSN(ssn.String)
+		client.Ssn = &masked
+	}
+	client.Zipcode = drakeZip(zip.String)
+	return client, nil
+}
+
+// drakeZip converts a Drake ZIP or ZIP+4 ("12345-6789") to the five-digit code clients carry
+func drakeZip(zip string) *int32 {
+	if len(zip) < 5 {
+		return nil
+	}
+	code, err := strconv.ParseInt(zip[:5], 10, 32)
+	if err != nil {
+		return nil
+	}
+	zipcode := int32(code)
+	return &zipcode
+}
+
+// GetClients retrieves all clients from the Drake clients table
+// Archived clients are excluded unless includeArchived is true
+func (a *DrakeAdapter) GetClients(ctx context.Context, db *sql.DB, schemaPrefix string, includeArchived bool) ([]*types.Client, error) {
+	where := "WHERE archived_at IS NULL"
+	if includeArchived {
+		where = ""
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.clients
+		%s
+		ORDER BY created_at DESC
+	`, drakeClientColumns, sqlident.Schema(schemaPrefix), where)
+
+	clients, err := queryDrakeClients(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Drake adapter successfully fetched %d clients", len(clients))
+	return clients, nil
+}
+
+// GetClientPage retrieves one page of clients, newest first, with the total count
+// Archived clients are excluded unless includeArchived is true
+func (a *DrakeAdapter) GetClientPage(ctx context.Context, db *sql.DB, schemaPrefix string, includeArchived bool, page types.PageRequest) (*types.ClientPage, error) {
+	var conditions []string
+	if !includeArchived {
+		conditions = append(conditions, "archived_at IS NULL")
+	}
+	where := ""
+	if len(conditions) > 0 {
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	result := &types.ClientPage{Items: []*types.Client{}}
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s.clients %s`, sqlident.Schema(schemaPrefix), where)
+	if err := db.QueryRowContext(ctx, countQuery).Scan(&result.TotalCount); err != nil {
+		logger.Errorf("Drake adapter failed to count clients: %v", err)
+		return nil, fmt.Errorf("failed to count clients: %w", err)
+	}
+
+	keyset, args := keysetCondition(page, "created_at", "id", nil)
+	if keyset != "" {
+		conditions = append(conditions, keyset)
+		where = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.clients
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d
+	`, drakeClientColumns, sqlident.Schema(schemaPrefix), where, len(args)+1)
+	args = append(args, page.Size()+1)
+
+	clients, err := queryDrakeClients(ctx, db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(clients) > page.Size() {
+		clients = clients[:page.Size()]
+		last := clients[len(clients)-1]
+		result.NextCursor = nextPageCursor(last.CreatedAt, last.ID)
+	}
+	if clients != nil {
+		result.Items = clients
+	}
+
+	logger.Infof("Drake adapter fetched a page of %d of %d clients", len(result.Items), result.TotalCount)
+	return result, nil
+}
+
+// StreamClients calls fn with each client as it is scanned, newest first
+// Archived clients are excluded unless includeArchived is true
+func (a *DrakeAdapter) StreamClients(ctx context.Context, db *sql.DB, schemaPrefix string, includeArchived bool, fn func(*types.Client) error) error {
+	where := "WHERE archived_at IS NULL"
+	if includeArchived {
+		where = ""
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.clients
+		%s
+		ORDER BY created_at DESC, id DESC
+	`, drakeClientColumns, sqlident.Schema(schemaPrefix), where)
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to query clients for streaming: %v", err)
+		return fmt.Errorf("failed to query clients: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		client, err := scanDrakeClient(rows)
+		if err != nil {
+			logger.Errorf("Drake adapter failed to scan client row: %v", err)
+			return fmt.Errorf("failed to scan client: %w", err)
+		}
+		if err := fn(client); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Errorf("Drake adapter error streaming client rows: %v", err)
+		return fmt.Errorf("error iterating clients: %w", err)
+	}
+	return nil
+}
+
+// SearchClients returns the clients best matching a search with their returns, newest year
+// first. Only the taxpayer's name, email, phone and SSN are searched.
+func (a *DrakeAdapter) SearchClients(ctx context.Context, db *sql.DB, schemaPrefix string, query *types.ClientSearchQuery) ([]*types.ClientSearchResult, error) {
+	results, err := searchClients(ctx, db, clientSearchSpec{
+		table:   sqlident.Schema(schemaPrefix) + ".clients",
+		columns: drakeClientColumns,
+		scan:    scanDrakeClient,
+		search: clientSearchColumns{
+			firstName: "tp_first_name",
+			lastName:  "tp_last_name",
+			email:     "tp_email",
+			phone:     "tp_cell_phone",
+		},
+		where:    "TRUE",
+		archived: "archived_at IS NOT NULL",
+		ssn:      "tp_ssn",
+		filingsQuery: fmt.Sprintf(`
+			SELECT client_id, id, tax_year, completed_at IS NOT NULL
+			FROM %s.returns
+			WHERE client_id = ANY($1::uuid[])
+			ORDER BY tax_year DESC
+		`, sqlident.Schema(schemaPrefix)),
+	}, query)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to search clients: %v", err)
+		return nil, err
+	}
+
+	logger.Infof("Drake adapter found %d clients matching search", len(results))
+	return results, nil
+}
+
+// queryDrakeClients runs a client listing query selecting drakeClientColumns
+func queryDrakeClients(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]*types.Client, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to query clients: %v", err)
+		return nil, fmt.Errorf("failed to query clients: %w", err)
+	}
+	defer rows.Close()
+
+	var clients []*types.Client
+	for rows.Next() {
+		client, err := scanDrakeClient(rows)
+		if err != nil {
+			logger.Errorf("Drake adapter failed to scan client row: %v", err)
+			return nil, fmt.Errorf("failed to scan client: %w", err)
+		}
+		clients = append(clients, client)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Errorf("Drake adapter error iterating client rows: %v", err)
+		return nil, fmt.Errorf("error iterating clients: %w", err)
+	}
+
+	return clients, nil
+}
+
+// GetClientByID retrieves a specific client by ID
+func (a *DrakeAdapter) GetClientByID(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (*types.Client, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s.clients WHERE id = $1`, drakeClientColumns, sqlident.Schema(schemaPrefix))
+
+	client, err := scanDrakeClient(db.QueryRowContext(ctx, query, clientID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperr.NotFound("client not found")
+		}
+		logger.Errorf("Drake adapter failed to get client %s: %v", clientID, err)
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	return client, nil
+}
+
+// ArchiveClient marks a client as archived with a reason
+func (a *DrakeAdapter) ArchiveClient(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string, reason string) (*types.Client, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.clients
+		SET archived_at = NOW(), archive_reason = $2
+		WHERE id = $1 AND archived_at IS NULL
+	`, sqlident.Schema(schemaPrefix))
+
+	result, err := db.ExecContext(ctx, query, clientID, reason)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to archive client %s: %v", clientID, err)
+		return nil, fmt.Errorf("failed to archive client: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to archive client: %w", err)
+	} else if n == 0 {
+		return nil, apperr.Conflict("client not found or already archived")
+	}
+
+	return a.GetClientByID(ctx, db, schemaPrefix, clientID)
+}
+
+// UnarchiveClient restores an archived client
+func (a *DrakeAdapter) UnarchiveClient(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (*types.Client, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.clients
+		SET archived_at = NULL, archive_reason = NULL
+		WHERE id = $1 AND archived_at IS NOT NULL
+	`, sqlident.Schema(schemaPrefix))
+
+	result, err := db.ExecContext(ctx, query, clientID)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to unarchive client %s: %v", clientID, err)
+		return nil, fmt.Errorf("failed to unarchive client: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return nil, fmt.Errorf("failed to unarchive client: %w", err)
+	} else if n == 0 {
+		return nil, apperr.Conflict("client not found or not archived")
+	}
+
+	return a.GetClientByID(ctx, db, schemaPrefix, clientID)
+}
+
+// IsClientArchived reports whether a client is archived
+func (a *DrakeAdapter) IsClientArchived(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (bool, error) {
+	query := fmt.Sprintf(`SELECT archived_at IS NOT NULL FROM %s.clients WHERE id = $1`, sqlident.Schema(schemaPrefix))
+
+	var archived bool
+	if err := db.QueryRowContext(ctx, query, clientID).Scan(&archived); err != nil {
+		if err == sql.ErrNoRows {
+			return false, apperr.NotFound("client not found")
+		}
+		logger.Errorf("Drake adapter failed to check archive status for client %s: %v", clientID, err)
+		return false, fmt.Errorf("failed to check archive status: %w", err)
+	}
+	return archived, nil
+}
+
+// MarkClientDeceased records the death date of the taxpayer or spouse on the client row
+func (a *DrakeAdapter) MarkClientDeceased(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string, person string, deathDate string) error {
+	var query string
+	switch person {
+	case types.DeceasedPersonTaxpayer:
+		query = fmt.Sprintf(`UPDATE %s.clients SET tp_death_date = $2 WHERE id = $1`, sqlident.Schema(schemaPrefix))
+	case types.DeceasedPersonSpouse:
+		query = fmt.Sprintf(`UPDATE %s.clients SET sp_death_date = $2 WHERE id = $1 AND sp_first_name IS NOT NULL`, sqlident.Schema(schemaPrefix))
+	default:
+		return apperr.Validation("invalid deceased person: %s", person)
+	}
+
+	result, err := db.ExecContext(ctx, query, clientID, deathDate)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to mark %s of client %s as deceased: %v", person, clientID, err)
+		return fmt.Errorf("failed to mark deceased: %w", err)
+	}
+	if n, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to mark deceased: %w", err)
+	} else if n == 0 {
+		return apperr.NotFound("%s not found for client", person)
+	}
+	return nil
+}
+
+// GetSSNRecords returns every taxpayer and spouse SSN in the tenant for integrity checks
+func (a *DrakeAdapter) GetSSNRecords(ctx context.Context, db *sql.DB, schemaPrefix string) ([]*types.SSNRecord, error) {
+	query := fmt.Sprintf(`
+		SELECT id, 'taxpayer', TRIM(COALESCE(tp_first_name, '') || ' ' || COALESCE(tp_last_name, '')), tp_ssn
+		FROM %s.clients
+		WHERE tp_ssn IS NOT NULL AND tp_ssn <> ''
+		UNION ALL
+		SELECT id, 'spouse', TRIM(COALESCE(sp_first_name, '') || ' ' || COALESCE(sp_last_name, '')), sp_ssn
+		FROM %s.clients
+		WHERE sp_ssn IS NOT NULL AND sp_ssn <> ''
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to query SSN records: %v", err)
+		return nil, fmt.Errorf("failed to query SSN records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*types.SSNRecord
+	for rows.Next() {
+		rec := &types.SSNRecord{}
+		if err := rows.Scan(&rec.ClientID, &rec.Role, &rec.Name, &rec.EncryptedSSN); err != nil {
+			logger.Errorf("Drake adapter failed to scan SSN record: %v", err)
+			return nil, fmt.Errorf("failed to scan SSN record: %w", err)
+		}
+		records = append(records, rec)
+	}
+	return records, rows.Err()
+}
+
+// drakeSpouseID derives a stable spouse ID, since Drake keeps the spouse on the client row
+func drakeSpouseID(clientID uuid.UUID) uuid.UUID {
+	return uuid.NewSHA1(clientID, []byte("spouse"))
+}
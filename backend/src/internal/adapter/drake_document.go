@@ -0,0 +1,204 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/sqlident"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// drakeDocumentColumns are read by scanDrakeDocument
+const drakeDocumentColumns = `id, client_id, return_id, name, file_path, COALESCE(doc_type, ''), created_at::text, updated_at::text`
+
+// scanDrakeDocument reads drakeDocumentColumns into a document
+func scanDrakeDocument(row interface{ Scan(...interface{}) error }) (*types.Document, error) {
+	document := &types.Document{}
+	err := row.Scan(&document.ID, &document.UserID, &document.FilingID, &document.Name, &document.FilePath,
+		&document.Type, &document.CreatedAt, &document.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// CreateDocument creates a new document record in the Drake documents table
+func (a *DrakeAdapter) CreateDocument(ctx context.Context, db *sql.DB, schemaPrefix string, document *types.Document) (*types.Document, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.documents (id, client_id, return_id, name, file_path, doc_type, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING %s
+	`, sqlident.Schema(schemaPrefix), drakeDocumentColumns)
+
+	if document.ID == uuid.Nil {
+		document.ID = a.newID()
+	}
+	createdAt := time.Now().UTC().Format("2006-01-02 15:04:05")
+
+	created, err := scanDrakeDocument(db.QueryRowContext(ctx, query, document.ID, document.UserID, document.FilingID,
+		document.Name, document.FilePath, document.Type, createdAt))
+	if err != nil {
+		logger.Errorf("Drake adapter failed to create document: %v", err)
+		return nil, fmt.Errorf("failed to create document: %w", err)
+	}
+
+	logger.Infof("Drake adapter created document: %s", created.ID)
+	return created, nil
+}
+
+// GetDocumentByID retrieves a specific document by ID
+func (a *DrakeAdapter) GetDocumentByID(ctx context.Context, db *sql.DB, schemaPrefix string, documentID string) (*types.Document, error) {
+	query := fmt.Sprintf(`SELECT %s FROM %s.documents WHERE id = $1`, drakeDocumentColumns, sqlident.Schema(schemaPrefix))
+
+	document, err := scanDrakeDocument(db.QueryRowContext(ctx, query, documentID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperr.NotFound("document not found")
+		}
+		logger.Errorf("Drake adapter failed to fetch document %s: %v", documentID, err)
+		return nil, fmt.Errorf("failed to fetch document: %w", err)
+	}
+	return document, nil
+}
+
+// GetDocumentsByFilingID retrieves all documents attached to a Drake return
+func (a *DrakeAdapter) GetDocumentsByFilingID(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) ([]*types.Document, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.documents
+		WHERE return_id = $1
+		ORDER BY created_at DESC
+	`, drakeDocumentColumns, sqlident.Schema(schemaPrefix))
+
+	rows, err := db.QueryContext(ctx, query, filingID)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to query documents: %v", err)
+		return nil, fmt.Errorf("failed to query documents: %w", err)
+	}
+	defer rows.Close()
+
+	documents := make([]*types.Document, 0)
+	for rows.Next() {
+		document, err := scanDrakeDocument(rows)
+		if err != nil {
+			logger.Errorf("Drake adapter failed to scan document: %v", err)
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		documents = append(documents, document)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Errorf("Drake adapter error iterating documents: %v", err)
+		return nil, fmt.Errorf("error iterating documents: %w", err)
+	}
+	return documents, nil
+}
+
+// GetDocumentsByYear retrieves the documents attached to every Drake return of a tax year
+func (a *DrakeAdapter) GetDocumentsByYear(ctx context.Context, db *sql.DB, schemaPrefix string, year int) ([]*types.Document, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.documents
+		WHERE return_id IN (SELECT id FROM %s.returns WHERE tax_year = $1)
+		ORDER BY created_at
+	`, drakeDocumentColumns, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+
+	rows, err := db.QueryContext(ctx, query, year)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to query documents of %d: %v", year, err)
+		return nil, fmt.Errorf("failed to query documents: %w", err)
+	}
+	defer rows.Close()
+
+	documents := make([]*types.Document, 0)
+	for rows.Next() {
+		document, err := scanDrakeDocument(rows)
+		if err != nil {
+			logger.Errorf("Drake adapter failed to scan document: %v", err)
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		documents = append(documents, document)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Errorf("Drake adapter error iterating documents: %v", err)
+		return nil, fmt.Errorf("error iterating documents: %w", err)
+	}
+	return documents, nil
+}
+
+// DeleteDocument removes a document record from the Drake documents table
+func (a *DrakeAdapter) DeleteDocument(ctx context.Context, db *sql.DB, schemaPrefix string, documentID string) error {
+	query := fmt.Sprintf(`DELETE FROM %s.documents WHERE id = $1`, sqlident.Schema(schemaPrefix))
+
+	result, err := db.ExecContext(ctx, query, documentID)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to delete document %s: %v", documentID, err)
+		return fmt.Errorf("failed to delete document: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return apperr.NotFound("document not found")
+	}
+
+	logger.Infof("Drake adapter deleted document: %s", documentID)
+	return nil
+}
+
+// StreamDocuments calls fn with each document in the Drake documents table, oldest first
+func (a *DrakeAdapter) StreamDocuments(ctx context.Context, db *sql.DB, schemaPrefix string, fn func(*types.Document) error) error {
+	query := fmt.Sprintf(`SELECT %s FROM %s.documents ORDER BY created_at, id`, drakeDocumentColumns, sqlident.Schema(schemaPrefix))
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to query documents for streaming: %v", err)
+		return fmt.Errorf("failed to query documents: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		document, err := scanDrakeDocument(rows)
+		if err != nil {
+			logger.Errorf("Drake adapter failed to scan document: %v", err)
+			return fmt.Errorf("failed to scan document: %w", err)
+		}
+		if err := fn(document); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Errorf("Drake adapter error streaming documents: %v", err)
+		return fmt.Errorf("error iterating documents: %w", err)
+	}
+	return nil
+}
+
+// SetDocumentPath points a Drake document at another file in the tenant's bucket
+func (a *DrakeAdapter) SetDocumentPath(ctx context.Context, db *sql.DB, schemaPrefix string, documentID string, filePath string) error {
+	query := fmt.Sprintf(`UPDATE %s.documents SET file_path = $1, updated_at = NOW() WHERE id = $2`, sqlident.Schema(schemaPrefix))
+
+	result, err := db.ExecContext(ctx, query, filePath, documentID)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to set path of document %s: %v", documentID, err)
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return apperr.NotFound("document not found")
+	}
+	return nil
+}
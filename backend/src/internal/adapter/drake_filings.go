@@ -0,0 +1,377 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/sqlident"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// drakeFilingStatuses maps Drake's filing status codes to marital statuses
+var drakeFilingStatuses = map[int]string{
+	1: "SINGLE",
+	2: "MARRIED_FILING_JOINTLY",
+	3: "MARRIED_FILING_SEPARATELY",
+	4: "HEAD_OF_HOUSEHOLD",
+	5: "QUALIFYING_SURVIVING_SPOUSE",
+}
+
+// GetClientComprehensive retrieves a Drake client with spouse, dependents and returns
+func (a *DrakeAdapter) GetClientComprehensive(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (*types.ClientComprehensive, error) {
+	logger.Infof("Drake adapter fetching comprehensive data for client %s", clientID)
+
+	client, err := a.GetClientByID(ctx, db, schemaPrefix, clientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get client: %w", err)
+	}
+	comprehensive := &types.ClientComprehensive{Client: client}
+
+	spouse, err := a.getSpouse(ctx, db, schemaPrefix, client.ID)
+	if err != nil {
+		logger.Warningf("Failed to get spouse for %s: %v", clientID, err)
+	}
+	comprehensive.Spouse = spouse
+
+	comprehensive.Dependents, err = a.getDependents(ctx, db, schemaPrefix, clientID)
+	if err != nil {
+		logger.Warningf("Failed to get dependents for %s: %v", clientID, err)
+	}
+
+	comprehensive.Filings, err = a.getReturns(ctx, db, schemaPrefix, clientID, spouse)
+	if err != nil {
+		logger.Warningf("Failed to get returns for %s: %v", clientID, err)
+	}
+
+	logger.Infof("Successfully fetched comprehensive data for client %s (%d filings)", clientID, len(comprehensive.Filings))
+	return comprehensive, nil
+}
+
+// getSpouse builds the spouse from the sp_ columns of the client row; nil when there is none
+func (a *DrakeAdapter) getSpouse(ctx context.Context, db *sql.DB, schemaPrefix string, clientID uuid.UUID) (*types.Spouse, error) {
+	query := fmt.Sprintf(`
+		SELECT sp_first_name, sp_middle_initial, COALESCE(sp_last_name, ''), sp_email, sp_cell_phone,
+		       COALESCE(sp_dob::text, ''), COALESCE(sp_ssn, ''), sp_death_date::text, created_at::text
+		FROM %s.clients
+		WHERE id = $1
+	`, sqlident.Schema(schemaPrefix))
+
+	spouse := &types.Spouse{ID: drakeSpouseID(clientID), UserID: clientID}
+	var firstName sql.NullString
+	var ssn string
+	err := db.QueryRowContext(ctx, query, clientID).Scan(&firstName, &spouse.MiddleName, &spouse.LastName, &spouse.Email,
+		&spouse.Phone, &spouse.Dob, &ssn, &spouse.DeathDate, &spouse.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if !firstName.Valid || firstName.String == "" {
+		return nil, nil
+	}
+
+	spouse.FirstName = firstName.String
+	spouse.Ssn = crypto.MaskSSN(ssn)
+	spouse.IsDeath = spouse.DeathDate != nil
+	return spouse, nil
+}
+
+func (a *DrakeAdapter) getDependents(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) ([]*types.Dependent, error) {
+	query := fmt.Sprintf(`
+		SELECT id, client_id, COALESCE(first_name, ''), middle_initial, COALESCE(last_name, ''), COALESCE(dob::text, ''), COALESCE(ssn, ''),
+		       COALESCE(relationship, ''), months_in_home, created_at::text, updated_at::text
+		FROM %s.dependents WHERE client_id = $1
+		ORDER BY dob
+	`, sqlident.Schema(schemaPrefix))
+
+	rows, err := db.QueryContext(ctx, query, clientID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var dependents []*types.Dependent
+	for rows.Next() {
+		dep := &types.Dependent{}
+		var ssn string
+		var months sql.NullInt64
+		if err := rows.Scan(&dep.ID, &dep.UserID, &dep.FirstName, &dep.MiddleName, &dep.LastName, &dep.Dob, &ssn,
+			&dep.Relationship, &months, &dep.CreatedAt, &dep.UpdatedAt); err != nil {
+			return nil, err
+		}
+		dep.Ssn = crypto.MaskSSN(ssn)
+		if months.Valid {
+			dep.TimeWithApplicant = strconv.FormatInt(months.Int64, 10) + " months"
+		}
+		dependents = append(dependents, dep)
+	}
+	return dependents, rows.Err()
+}
+
+// getReturns maps the client's Drake returns to filings with their status and documents
+func (a *DrakeAdapter) getReturns(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string, spouse *types.Spouse) ([]*types.Filing, error) {
+	query := fmt.Sprintf(`
+		SELECT id, tax_year, client_id, filing_status, ROUND(agi)::bigint, COALESCE(return_status, ''),
+		       completed_at IS NOT NULL, created_at::text, updated_at::text
+		FROM %s.returns WHERE client_id = $1 ORDER BY tax_year DESC
+	`, sqlident.Schema(schemaPrefix))
+
+	rows, err := db.QueryContext(ctx, query, clientID)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to query returns: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var filings []*types.Filing
+	for rows.Next() {
+		filing := &types.Filing{Status: &types.FilingStatus{}}
+		var filingStatus sql.NullInt64
+		if err := rows.Scan(&filing.ID, &filing.Year, &filing.UserID, &filingStatus, &filing.Income,
+			&filing.Status.Status, &filing.Status.IsCompleted, &filing.CreatedAt, &filing.UpdatedAt); err != nil {
+			logger.Errorf("Drake adapter failed to scan return row: %v", err)
+			return nil, err
+		}
+		filing.Status.ID = filing.ID
+		filing.Status.FilingID = filing.ID
+
+		if status, ok := drakeFilingStatuses[int(filingStatus.Int64)]; ok {
+			filing.MaritalStatus = &status
+		}
+		// Joint and separate returns name the spouse on the client row
+		if spouse != nil && (filingStatus.Int64 == 2 || filingStatus.Int64 == 3) {
+			filing.SpouseID = &spouse.ID
+		}
+		filings = append(filings, filing)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, filing := range filings {
+		documents, err := a.GetDocumentsByFilingID(ctx, db, schemaPrefix, filing.ID.String())
+		if err != nil {
+			logger.Warningf("Failed to get documents for return %s: %v", filing.ID, err)
+			continue
+		}
+		filing.Documents = documents
+	}
+
+	return filings, nil
+}
+
+// GetClientsByFilings retrieves clients with returns, most recently created return first
+// Archived clients are excluded
+func (a *DrakeAdapter) GetClientsByFilings(ctx context.Context, db *sql.DB, schemaPrefix string, limit int, offset int) ([]*types.ClientComprehensive, error) {
+	query := fmt.Sprintf(`
+		SELECT r.client_id
+		FROM %s.returns r
+		JOIN %s.clients c ON c.id = r.client_id
+		WHERE c.archived_at IS NULL
+		GROUP BY r.client_id
+		ORDER BY MAX(r.created_at) DESC
+		LIMIT $1 OFFSET $2
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+
+	rows, err := db.QueryContext(ctx, query, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query client IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var clientIDs []string
+	for rows.Next() {
+		var clientID string
+		if err := rows.Scan(&clientID); err != nil {
+			return nil, fmt.Errorf("failed to scan client ID: %w", err)
+		}
+		clientIDs = append(clientIDs, clientID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating client IDs: %w", err)
+	}
+
+	result := a.getComprehensiveClients(ctx, db, schemaPrefix, clientIDs)
+
+	logger.Infof("Drake adapter returning %d clients with all their returns", len(result))
+	return result, nil
+}
+
+// GetClientsByFilingsPage retrieves one page of clients with returns, most recently created return first
+// The cursor is the client's most recent return time and ID; archived clients are excluded
+func (a *DrakeAdapter) GetClientsByFilingsPage(ctx context.Context, db *sql.DB, schemaPrefix string, page types.PageRequest) (*types.FilingPage, error) {
+	result := &types.FilingPage{Items: []*types.ClientComprehensive{}}
+	countQuery := fmt.Sprintf(`
+		SELECT COUNT(DISTINCT r.client_id)
+		FROM %s.returns r
+		JOIN %s.clients c ON c.id = r.client_id
+		WHERE c.archived_at IS NULL
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+	if err := db.QueryRowContext(ctx, countQuery).Scan(&result.TotalCount); err != nil {
+		logger.Errorf("Drake adapter failed to count clients with returns: %v", err)
+		return nil, fmt.Errorf("failed to count clients with filings: %w", err)
+	}
+
+	having := ""
+	keyset, args := keysetCondition(page, "MAX(r.created_at)", "r.client_id", nil)
+	if keyset != "" {
+		having = "HAVING " + keyset
+	}
+	query := fmt.Sprintf(`
+		SELECT r.client_id, MAX(r.created_at)
+		FROM %s.returns r
+		JOIN %s.clients c ON c.id = r.client_id
+		WHERE c.archived_at IS NULL
+		GROUP BY r.client_id
+		%s
+		ORDER BY MAX(r.created_at) DESC, r.client_id DESC
+		LIMIT $%d
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix), having, len(args)+1)
+	args = append(args, page.Size()+1)
+
+	clientIDs, lastFiled, err := queryClientsByLastFiling(ctx, db, query, args...)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to page clients with returns: %v", err)
+		return nil, err
+	}
+	if len(clientIDs) > page.Size() {
+		clientIDs = clientIDs[:page.Size()]
+		last := len(clientIDs) - 1
+		result.NextCursor = nextPageCursor(lastFiled[last], clientIDs[last])
+	}
+
+	ids := make([]string, len(clientIDs))
+	for i, clientID := range clientIDs {
+		ids[i] = clientID.String()
+	}
+	result.Items = a.getComprehensiveClients(ctx, db, schemaPrefix, ids)
+
+	logger.Infof("Drake adapter returning a page of %d of %d clients with returns", len(result.Items), result.TotalCount)
+	return result, nil
+}
+
+// StreamClientsByFilings calls fn with each client with returns, most recently created return first
+// Client IDs are read up front so the tenant connection is free for the per-client queries
+func (a *DrakeAdapter) StreamClientsByFilings(ctx context.Context, db *sql.DB, schemaPrefix string, fn func(*types.ClientComprehensive) error) error {
+	query := fmt.Sprintf(`
+		SELECT r.client_id
+		FROM %s.returns r
+		JOIN %s.clients c ON c.id = r.client_id
+		WHERE c.archived_at IS NULL
+		GROUP BY r.client_id
+		ORDER BY MAX(r.created_at) DESC, r.client_id DESC
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+
+	clientIDs, err := queryClientIDs(ctx, db, query)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to list clients with returns for streaming: %v", err)
+		return err
+	}
+
+	logger.Infof("Drake adapter streaming %d clients with returns", len(clientIDs))
+	return streamComprehensiveClients(ctx, clientIDs, func(clientID string) (*types.ClientComprehensive, error) {
+		return a.GetClientComprehensive(ctx, db, schemaPrefix, clientID)
+	}, fn)
+}
+
+// getComprehensiveClients loads comprehensive data (including all returns) for each client,
+// skipping clients that fail to load
+func (a *DrakeAdapter) getComprehensiveClients(ctx context.Context, db *sql.DB, schemaPrefix string, clientIDs []string) []*types.ClientComprehensive {
+	result := make([]*types.ClientComprehensive, 0, len(clientIDs))
+	for _, clientID := range clientIDs {
+		comprehensive, err := a.GetClientComprehensive(ctx, db, schemaPrefix, clientID)
+		if err != nil {
+			logger.Warningf("Failed to get comprehensive data for client %s: %v", clientID, err)
+			continue
+		}
+		result = append(result, comprehensive)
+	}
+	return result
+}
+
+// CountFilings counts the returns for a tax year and how many of them are completed
+func (a *DrakeAdapter) CountFilings(ctx context.Context, db *sql.DB, schemaPrefix string, year int) (int, int, error) {
+	query := fmt.Sprintf(`
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE completed_at IS NOT NULL)
+		FROM %s.returns
+		WHERE tax_year = $1
+	`, sqlident.Schema(schemaPrefix))
+
+	var total, completed int
+	if err := db.QueryRowContext(ctx, query, year).Scan(&total, &completed); err != nil {
+		logger.Errorf("Drake adapter failed to count returns for %d: %v", year, err)
+		return 0, 0, fmt.Errorf("failed to count filings: %w", err)
+	}
+	return total, completed, nil
+}
+
+// CountOpenFilings counts the returns of every tax year that are not completed
+func (a *DrakeAdapter) CountOpenFilings(ctx context.Context, db *sql.DB, schemaPrefix string) (int, error) {
+	query := fmt.Sprintf(`SELECT COUNT(*) FROM %s.returns WHERE completed_at IS NULL`, sqlident.Schema(schemaPrefix))
+
+	var open int
+	if err := db.QueryRowContext(ctx, query).Scan(&open); err != nil {
+		logger.Errorf("Drake adapter failed to count open returns: %v", err)
+		return 0, fmt.Errorf("failed to count open filings: %w", err)
+	}
+	return open, nil
+}
+
+// GetFilingYear returns the tax year of a Drake return
+func (a *DrakeAdapter) GetFilingYear(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) (int, error) {
+	query := fmt.Sprintf(`SELECT tax_year FROM %s.returns WHERE id = $1`, sqlident.Schema(schemaPrefix))
+
+	var year int
+	err := db.QueryRowContext(ctx, query, filingID).Scan(&year)
+	if err == sql.ErrNoRows {
+		return 0, apperr.NotFound("filing not found: %s", filingID)
+	}
+	if err != nil {
+		logger.Errorf("Drake adapter failed to get year of return %s: %v", filingID, err)
+		return 0, fmt.Errorf("failed to get filing year: %w", err)
+	}
+	return year, nil
+}
+
+// GetFilingWorkflow returns the client, tax year and return_status of a Drake return
+func (a *DrakeAdapter) GetFilingWorkflow(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) (*types.FilingWorkflow, error) {
+	query := fmt.Sprintf(`
+		SELECT id, client_id, tax_year, COALESCE(return_status, ''), completed_at IS NOT NULL
+		FROM %s.returns WHERE id = $1
+	`, sqlident.Schema(schemaPrefix))
+
+	workflow := &types.FilingWorkflow{}
+	err := db.QueryRowContext(ctx, query, filingID).Scan(&workflow.FilingID, &workflow.ClientID, &workflow.Year, &workflow.Status, &workflow.IsCompleted)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("filing not found: %s", filingID)
+	}
+	if err != nil {
+		logger.Errorf("Drake adapter failed to get status of return %s: %v", filingID, err)
+		return nil, fmt.Errorf("failed to get filing status: %w", err)
+	}
+	return workflow, nil
+}
+
+// SetFilingStatus moves a Drake return's return_status from one status to another. Accepted
+// returns get a completed_at; returns moved out of it lose theirs.
+func (a *DrakeAdapter) SetFilingStatus(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string, from string, to string) error {
+	query := fmt.Sprintf(`
+		UPDATE %s.returns
+		SET return_status = $2, completed_at = CASE WHEN $3 THEN NOW()::text END
+		WHERE id = $1 AND COALESCE(return_status, '') = $4
+	`, sqlident.Schema(schemaPrefix))
+
+	result, err := db.ExecContext(ctx, query, filingID, to, to == types.FilingStatusAccepted, from)
+	if err != nil {
+		logger.Errorf("Drake adapter failed to set status of return %s: %v", filingID, err)
+		return fmt.Errorf("failed to set filing status: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return apperr.Conflict("filing %s is no longer %s", filingID, from)
+	}
+	return nil
+}
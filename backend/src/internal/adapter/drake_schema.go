@@ -0,0 +1,39 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"welltaxpro/src/internal/types"
+)
+
+// drakeSchema is every table and column the Drake adapter queries.
+// Keep it in step with the adapter's SQL when columns are added.
+var drakeSchema = []types.SchemaTable{
+	schemaTable("clients",
+		"id", kUUID, "tp_first_name", kText, "tp_middle_initial", kText, "tp_last_name", kText,
+		"tp_email", kText, "tp_cell_phone", kText, "tp_dob", kText, "tp_ssn", kText, "tp_death_date", kText,
+		"sp_first_name", kText, "sp_middle_initial", kText, "sp_last_name", kText, "sp_email", kText,
+		"sp_cell_phone", kText, "sp_dob", kText, "sp_ssn", kText, "sp_death_date", kText,
+		"address", kText, "apt", kText, "city", kText, "state", kText, "zip", kText,
+		"created_at", kText, "archived_at", kText, "archive_reason", kText),
+	schemaTable("dependents",
+		"id", kUUID, "client_id", kUUID, "first_name", kText, "middle_initial", kText, "last_name", kText,
+		"dob", kText, "ssn", kText, "relationship", kText, "months_in_home", kInt,
+		"created_at", kText, "updated_at", kText),
+	schemaTable("returns",
+		"id", kUUID, "client_id", kUUID, "tax_year", kInt, "filing_status", kInt, "agi", kNum,
+		"return_status", kText, "completed_at", kText, "created_at", kText, "updated_at", kText),
+	schemaTable("documents",
+		"id", kUUID, "client_id", kUUID, "return_id", kUUID, "name", kText, "file_path", kText,
+		"doc_type", kText, "created_at", kText, "updated_at", kText),
+}
+
+// ExpectedSchema returns the tables and columns the Drake adapter depends on
+func (a *DrakeAdapter) ExpectedSchema() []types.SchemaTable {
+	return drakeSchema
+}
+
+// GetSchemaColumns introspects the tenant schema; the query does not depend on the adapter
+func (a *DrakeAdapter) GetSchemaColumns(ctx context.Context, db *sql.DB, schemaPrefix string) (map[string]map[string]string, error) {
+	return (&MyWellTaxAdapter{}).GetSchemaColumns(ctx, db, schemaPrefix)
+}
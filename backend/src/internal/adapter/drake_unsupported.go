@@ -0,0 +1,228 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"time"
+	"welltaxpro/src/internal/types"
+)
+
+// Operations below have no counterpart in a Drake export
+
+func (a *DrakeAdapter) ExportClientRows(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) ([]*types.ExportTable, error) {
+	return nil, drakeUnsupported("ExportClientRows")
+}
+
+func (a *DrakeAdapter) ImportClientRows(ctx context.Context, db *sql.DB, schemaPrefix string, tables []*types.ExportTable, reencrypt ReencryptSSN) (int, error) {
+	return 0, drakeUnsupported("ImportClientRows")
+}
+
+func (a *DrakeAdapter) ReencryptSSNs(ctx context.Context, db *sql.DB, schemaPrefix string, reencrypt ReencryptSSN) (int, error) {
+	return 0, drakeUnsupported("ReencryptSSNs")
+}
+
+func (a *DrakeAdapter) GetAffiliates(ctx context.Context, db *sql.DB, schemaPrefix string, activeOnly bool) ([]*types.Affiliate, error) {
+	return nil, drakeUnsupported("GetAffiliates")
+}
+
+func (a *DrakeAdapter) GetAffiliateByID(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string) (*types.Affiliate, error) {
+	return nil, drakeUnsupported("GetAffiliateByID")
+}
+
+func (a *DrakeAdapter) CreateAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliate *types.Affiliate) (*types.Affiliate, error) {
+	return nil, drakeUnsupported("CreateAffiliate")
+}
+
+func (a *DrakeAdapter) UpdateAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, affiliate *types.Affiliate) (*types.Affiliate, error) {
+	return nil, drakeUnsupported("UpdateAffiliate")
+}
+
+func (a *DrakeAdapter) PatchAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, patch *types.AffiliatePatch) (*types.Affiliate, error) {
+	return nil, drakeUnsupported("PatchAffiliate")
+}
+
+func (a *DrakeAdapter) GetCommissionsByAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, status *string, commissionIDs []string, limit int) ([]*types.Commission, error) {
+	return nil, drakeUnsupported("GetCommissionsByAffiliate")
+}
+
+func (a *DrakeAdapter) GetCommissionPage(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, status *string, commissionIDs []string, page types.PageRequest) (*types.CommissionPage, error) {
+	return nil, drakeUnsupported("GetCommissionPage")
+}
+
+// GetOverdueCommissions finds nothing; Drake tenants have no commissions
+func (a *DrakeAdapter) GetOverdueCommissions(ctx context.Context, db *sql.DB, schemaPrefix string, createdBefore time.Time, limit int) ([]*types.Commission, int, error) {
+	return nil, 0, nil
+}
+
+func (a *DrakeAdapter) GetAffiliateStats(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string) (*types.AffiliateStats, error) {
+	return nil, drakeUnsupported("GetAffiliateStats")
+}
+
+func (a *DrakeAdapter) RecordAffiliateClick(ctx context.Context, db *sql.DB, schemaPrefix string, click *types.AffiliateClick) error {
+	return drakeUnsupported("RecordAffiliateClick")
+}
+
+// RollupAffiliateClicks has nothing to do; Drake tenants record no clicks
+func (a *DrakeAdapter) RollupAffiliateClicks(ctx context.Context, db *sql.DB, schemaPrefix string, cutoff time.Time) (int, error) {
+	return 0, nil
+}
+
+func (a *DrakeAdapter) RecordCheckoutPayment(ctx context.Context, db *sql.DB, schemaPrefix string, payment *types.Payment) (*types.CheckoutPayment, error) {
+	return nil, drakeUnsupported("RecordCheckoutPayment")
+}
+
+func (a *DrakeAdapter) CreateCommission(ctx context.Context, db *sql.DB, schemaPrefix string, commission *types.Commission) (*types.Commission, error) {
+	return nil, drakeUnsupported("CreateCommission")
+}
+
+func (a *DrakeAdapter) EvaluateCommissionFraud(ctx context.Context, db *sql.DB, schemaPrefix string, commission *types.Commission, ipAddress string, rules *types.FraudRules) ([]types.FraudFlag, error) {
+	return nil, drakeUnsupported("EvaluateCommissionFraud")
+}
+
+func (a *DrakeAdapter) ApproveCommission(ctx context.Context, db *sql.DB, schemaPrefix string, commissionID string) (*types.Commission, error) {
+	return nil, drakeUnsupported("ApproveCommission")
+}
+
+func (a *DrakeAdapter) ApproveCommissions(ctx context.Context, db *sql.DB, schemaPrefix string, commissionIDs []string, filter *types.CommissionFilter, limit int) (*types.BulkCommissionReport, error) {
+	return nil, drakeUnsupported("ApproveCommissions")
+}
+
+func (a *DrakeAdapter) MarkCommissionPaid(ctx context.Context, db *sql.DB, schemaPrefix string, commissionID string, payment *types.PayoutPayment) (*types.AffiliatePayout, []*types.Commission, error) {
+	return nil, nil, drakeUnsupported("MarkCommissionPaid")
+}
+
+func (a *DrakeAdapter) CancelCommission(ctx context.Context, db *sql.DB, schemaPrefix string, commissionID string, reason string) (*types.Commission, error) {
+	return nil, drakeUnsupported("CancelCommission")
+}
+
+func (a *DrakeAdapter) CreatePayoutBatch(ctx context.Context, db *sql.DB, schemaPrefix string, createdBy string) (*types.PayoutBatch, error) {
+	return nil, drakeUnsupported("CreatePayoutBatch")
+}
+
+func (a *DrakeAdapter) GetPayoutBatches(ctx context.Context, db *sql.DB, schemaPrefix string, limit int) ([]*types.PayoutBatch, error) {
+	return nil, drakeUnsupported("GetPayoutBatches")
+}
+
+func (a *DrakeAdapter) GetPayoutBatch(ctx context.Context, db *sql.DB, schemaPrefix string, batchID string) (*types.PayoutBatch, error) {
+	return nil, drakeUnsupported("GetPayoutBatch")
+}
+
+func (a *DrakeAdapter) GetPayout(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string) (*types.AffiliatePayout, error) {
+	return nil, drakeUnsupported("GetPayout")
+}
+
+func (a *DrakeAdapter) GetAffiliatePayments(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, limit int) ([]*types.AffiliatePayoutPayment, error) {
+	return nil, drakeUnsupported("GetAffiliatePayments")
+}
+
+func (a *DrakeAdapter) GetAffiliatePaymentTotals(ctx context.Context, db *sql.DB, schemaPrefix string, year int) ([]*types.AffiliatePaymentTotal, error) {
+	return nil, drakeUnsupported("GetAffiliatePaymentTotals")
+}
+
+func (a *DrakeAdapter) GetAffiliateStatement(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, year int) (*types.AffiliateStatement, error) {
+	return nil, drakeUnsupported("GetAffiliateStatement")
+}
+
+func (a *DrakeAdapter) StartPayoutTransfer(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string) (*types.AffiliatePayout, error) {
+	return nil, drakeUnsupported("StartPayoutTransfer")
+}
+
+func (a *DrakeAdapter) RecordPayoutFailure(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string, message string, declined bool) error {
+	return drakeUnsupported("RecordPayoutFailure")
+}
+
+func (a *DrakeAdapter) MarkPayoutPaid(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string, payment *types.PayoutPayment) (*types.AffiliatePayout, []*types.Commission, error) {
+	return nil, nil, drakeUnsupported("MarkPayoutPaid")
+}
+
+func (a *DrakeAdapter) CancelPayout(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string) (*types.AffiliatePayout, error) {
+	return nil, drakeUnsupported("CancelPayout")
+}
+
+func (a *DrakeAdapter) GetDiscountCodes(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, campaign *string, activeOnly bool) ([]*types.DiscountCode, error) {
+	return nil, drakeUnsupported("GetDiscountCodes")
+}
+
+func (a *DrakeAdapter) GetDiscountCodePage(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, campaign *string, activeOnly bool, page types.PageRequest) (*types.DiscountCodePage, error) {
+	return nil, drakeUnsupported("GetDiscountCodePage")
+}
+
+func (a *DrakeAdapter) GetDiscountCodeByID(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string) (*types.DiscountCode, error) {
+	return nil, drakeUnsupported("GetDiscountCodeByID")
+}
+
+func (a *DrakeAdapter) GetDiscountCodeByCode(ctx context.Context, db *sql.DB, schemaPrefix string, code string) (*types.DiscountCode, error) {
+	return nil, drakeUnsupported("GetDiscountCodeByCode")
+}
+
+func (a *DrakeAdapter) CreateDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, discountCode *types.DiscountCode) (*types.DiscountCode, error) {
+	return nil, drakeUnsupported("CreateDiscountCode")
+}
+
+func (a *DrakeAdapter) CreateDiscountCodes(ctx context.Context, db *sql.DB, schemaPrefix string, discountCodes []*types.DiscountCode) ([]*types.DiscountCode, error) {
+	return nil, drakeUnsupported("CreateDiscountCodes")
+}
+
+func (a *DrakeAdapter) GetDiscountCampaignReports(ctx context.Context, db *sql.DB, schemaPrefix string, campaign *string) ([]*types.DiscountCampaignReport, error) {
+	return nil, drakeUnsupported("GetDiscountCampaignReports")
+}
+
+func (a *DrakeAdapter) UpdateDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string, discountCode *types.DiscountCode) (*types.DiscountCode, error) {
+	return nil, drakeUnsupported("UpdateDiscountCode")
+}
+
+func (a *DrakeAdapter) PatchDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string, patch *types.DiscountCodePatch) (*types.DiscountCode, error) {
+	return nil, drakeUnsupported("PatchDiscountCode")
+}
+
+func (a *DrakeAdapter) DeactivateDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string) error {
+	return drakeUnsupported("DeactivateDiscountCode")
+}
+
+func (a *DrakeAdapter) GetCampaignMetrics(ctx context.Context, db *sql.DB, schemaPrefix string, keys []string) (map[string]*types.CampaignMetrics, error) {
+	return nil, drakeUnsupported("GetCampaignMetrics")
+}
+
+func (a *DrakeAdapter) GetStateFilings(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) ([]*types.StateFiling, error) {
+	return nil, drakeUnsupported("GetStateFilings")
+}
+
+func (a *DrakeAdapter) GetStateFilingByID(ctx context.Context, db *sql.DB, schemaPrefix string, stateFilingID string) (*types.StateFiling, error) {
+	return nil, drakeUnsupported("GetStateFilingByID")
+}
+
+func (a *DrakeAdapter) CreateStateFiling(ctx context.Context, db *sql.DB, schemaPrefix string, stateFiling *types.StateFiling) (*types.StateFiling, error) {
+	return nil, drakeUnsupported("CreateStateFiling")
+}
+
+func (a *DrakeAdapter) UpdateStateFiling(ctx context.Context, db *sql.DB, schemaPrefix string, stateFilingID string, stateFiling *types.StateFiling) (*types.StateFiling, error) {
+	return nil, drakeUnsupported("UpdateStateFiling")
+}
+
+func (a *DrakeAdapter) DeleteStateFiling(ctx context.Context, db *sql.DB, schemaPrefix string, stateFilingID string) error {
+	return drakeUnsupported("DeleteStateFiling")
+}
+
+func (a *DrakeAdapter) GetStateFilingReport(ctx context.Context, db *sql.DB, schemaPrefix string, year *int) ([]*types.StateFilingReport, error) {
+	return nil, drakeUnsupported("GetStateFilingReport")
+}
+
+func (a *DrakeAdapter) GetFilingResult(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) (*types.FilingResult, error) {
+	return nil, drakeUnsupported("GetFilingResult")
+}
+
+func (a *DrakeAdapter) UpsertFilingResult(ctx context.Context, db *sql.DB, schemaPrefix string, result *types.FilingResult) (*types.FilingResult, error) {
+	return nil, drakeUnsupported("UpsertFilingResult")
+}
+
+func (a *DrakeAdapter) GetRefundTrackings(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) ([]*types.RefundTracking, error) {
+	return nil, drakeUnsupported("GetRefundTrackings")
+}
+
+func (a *DrakeAdapter) UpsertRefundTracking(ctx context.Context, db *sql.DB, schemaPrefix string, tracking *types.RefundTracking) (*types.RefundTracking, error) {
+	return nil, drakeUnsupported("UpsertRefundTracking")
+}
+
+func (a *DrakeAdapter) DeleteRefundTracking(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string, jurisdiction string) error {
+	return drakeUnsupported("DeleteRefundTracking")
+}
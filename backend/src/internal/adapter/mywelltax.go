@@ -1,16 +1,31 @@
 package adapter
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"welltaxpro/src/internal/apperr"
 	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/idgen"
+	"welltaxpro/src/internal/sqlident"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
+	"github.com/google/uuid"
 )
 
 // MyWellTaxAdapter implements the ClientAdapter interface for MyWellTax schema
-type MyWellTaxAdapter struct{}
+type MyWellTaxAdapter struct {
+	ids idgen.Generator // IDs of created records; version 7 when nil
+}
+
+// newID returns the ID of a record about to be created
+func (a *MyWellTaxAdapter) newID() uuid.UUID {
+	if a.ids == nil {
+		return idgen.NewV7()
+	}
+	return a.ids()
+}
 
 // GetAdapterType returns the unique identifier for this adapter
 func (a *MyWellTaxAdapter) GetAdapterType() string {
@@ -19,17 +34,80 @@ func (a *MyWellTaxAdapter) GetAdapterType() string {
 
 // GetClients retrieves all clients from MyWellTax database
 // MyWellTax schema: taxes.user table with role='user' for clients
-func (a *MyWellTaxAdapter) GetClients(db *sql.DB, schemaPrefix string) ([]*types.Client, error) {
+// Archived clients are excluded unless includeArchived is true
+func (a *MyWellTaxAdapter) GetClients(ctx context.Context, db *sql.DB, schemaPrefix string, includeArchived bool) ([]*types.Client, error) {
 	query := fmt.Sprintf(`
-		SELECT id, first_name, last_name, email, phone, address1, city, state, zipcode, role, created_at
+		SELECT %s
 		FROM %s.user
 		WHERE role = 'user'
+		%s
 		ORDER BY created_at DESC
-	`, schemaPrefix)
+	`, myWellTaxClientColumns, sqlident.Schema(schemaPrefix), func() string {
+		if includeArchived {
+			return ""
+		}
+		return "AND archived_at IS NULL"
+	}())
 
 	logger.Infof("MyWellTax adapter executing query: %s", query)
 
-	rows, err := db.Query(query)
+	clients, err := queryMyWellTaxClients(ctx, db, query)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("MyWellTax adapter successfully fetched %d clients", len(clients))
+	return clients, nil
+}
+
+// GetClientPage retrieves one page of clients, newest first, with the total count
+// Archived clients are excluded unless includeArchived is true
+func (a *MyWellTaxAdapter) GetClientPage(ctx context.Context, db *sql.DB, schemaPrefix string, includeArchived bool, page types.PageRequest) (*types.ClientPage, error) {
+	where := "WHERE role = 'user'"
+	if !includeArchived {
+		where += " AND archived_at IS NULL"
+	}
+
+	result := &types.ClientPage{Items: []*types.Client{}}
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s.user %s`, sqlident.Schema(schemaPrefix), where)
+	if err := db.QueryRowContext(ctx, countQuery).Scan(&result.TotalCount); err != nil {
+		logger.Errorf("MyWellTax adapter failed to count clients: %v", err)
+		return nil, fmt.Errorf("failed to count clients: %w", err)
+	}
+
+	keyset, args := keysetCondition(page, "created_at", "id", nil)
+	if keyset != "" {
+		where += " AND " + keyset
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.user
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d
+	`, myWellTaxClientColumns, sqlident.Schema(schemaPrefix), where, len(args)+1)
+	args = append(args, page.Size()+1)
+
+	clients, err := queryMyWellTaxClients(ctx, db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(clients) > page.Size() {
+		clients = clients[:page.Size()]
+		last := clients[len(clients)-1]
+		result.NextCursor = nextPageCursor(last.CreatedAt, last.ID)
+	}
+	if clients != nil {
+		result.Items = clients
+	}
+
+	logger.Infof("MyWellTax adapter fetched a page of %d of %d clients", len(result.Items), result.TotalCount)
+	return result, nil
+}
+
+// queryMyWellTaxClients runs a client listing query selecting myWellTaxClientColumns
+func queryMyWellTaxClients(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]*types.Client, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		logger.Errorf("MyWellTax adapter failed to query clients: %v", err)
 		return nil, fmt.Errorf("failed to query clients: %w", err)
@@ -38,20 +116,7 @@ func (a *MyWellTaxAdapter) GetClients(db *sql.DB, schemaPrefix string) ([]*types
 
 	var clients []*types.Client
 	for rows.Next() {
-		client := &types.Client{}
-		err := rows.Scan(
-			&client.ID,
-			&client.FirstName,
-			&client.LastName,
-			&client.Email,
-			&client.Phone,
-			&client.Address1,
-			&client.City,
-			&client.State,
-			&client.Zipcode,
-			&client.Role,
-			&client.CreatedAt,
-		)
+		client, err := scanMyWellTaxClient(rows)
 		if err != nil {
 			logger.Errorf("MyWellTax adapter failed to scan client row: %v", err)
 			return nil, fmt.Errorf("failed to scan client: %w", err)
@@ -64,21 +129,120 @@ func (a *MyWellTaxAdapter) GetClients(db *sql.DB, schemaPrefix string) ([]*types
 		return nil, fmt.Errorf("error iterating clients: %w", err)
 	}
 
-	logger.Infof("MyWellTax adapter successfully fetched %d clients", len(clients))
 	return clients, nil
 }
 
+// myWellTaxClientColumns are the client listing columns read by scanMyWellTaxClient
+const myWellTaxClientColumns = `id, first_name, last_name, email, phone, address1, city, state, zipcode, role, created_at,
+		       archived_at, archive_reason`
+
+// scanMyWellTaxClient reads a row of myWellTaxClientColumns into a client
+func scanMyWellTaxClient(row interface{ Scan(...interface{}) error }) (*types.Client, error) {
+	client := &types.Client{}
+	err := row.Scan(
+		&client.ID,
+		&client.FirstName,
+		&client.LastName,
+		&client.Email,
+		&client.Phone,
+		&client.Address1,
+		&client.City,
+		&client.State,
+		&client.Zipcode,
+		&client.Role,
+		&client.CreatedAt,
+		&client.ArchivedAt,
+		&client.ArchiveReason,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// SearchClients returns the clients best matching a search with their filings, newest year first
+func (a *MyWellTaxAdapter) SearchClients(ctx context.Context, db *sql.DB, schemaPrefix string, query *types.ClientSearchQuery) ([]*types.ClientSearchResult, error) {
+	results, err := searchClients(ctx, db, clientSearchSpec{
+		table:   sqlident.Schema(schemaPrefix) + ".user",
+		columns: myWellTaxClientColumns,
+		scan:    scanMyWellTaxClient,
+		search: clientSearchColumns{
+			firstName: "first_name",
+			lastName:  "last_name",
+			email:     "email",
+			phone:     "phone",
+		},
+		where:    "role = 'user'",
+		archived: "archived_at IS NOT NULL",
+		ssn:      "ssn",
+		filingsQuery: fmt.Sprintf(`
+			SELECT f.user_id, f.id, f.year, COALESCE(fs.is_completed, false)
+			FROM %s.filing f
+			LEFT JOIN %s.filing_status fs ON fs.filing_id = f.id
+			WHERE f.user_id = ANY($1::uuid[])
+			ORDER BY f.year DESC
+		`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix)),
+	}, query)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to search clients: %v", err)
+		return nil, err
+	}
+
+	logger.Infof("MyWellTax adapter found %d clients matching search", len(results))
+	return results, nil
+}
+
+// StreamClients calls fn with each client as it is scanned, newest first
+// Archived clients are excluded unless includeArchived is true
+func (a *MyWellTaxAdapter) StreamClients(ctx context.Context, db *sql.DB, schemaPrefix string, includeArchived bool, fn func(*types.Client) error) error {
+	where := "WHERE role = 'user'"
+	if !includeArchived {
+		where += " AND archived_at IS NULL"
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.user
+		%s
+		ORDER BY created_at DESC, id DESC
+	`, myWellTaxClientColumns, sqlident.Schema(schemaPrefix), where)
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to query clients for streaming: %v", err)
+		return fmt.Errorf("failed to query clients: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		client, err := scanMyWellTaxClient(rows)
+		if err != nil {
+			logger.Errorf("MyWellTax adapter failed to scan client row: %v", err)
+			return fmt.Errorf("failed to scan client: %w", err)
+		}
+		if err := fn(client); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Errorf("MyWellTax adapter error streaming client rows: %v", err)
+		return fmt.Errorf("error iterating clients: %w", err)
+	}
+	return nil
+}
+
 // GetClientByID retrieves a specific client by ID from MyWellTax database
-func (a *MyWellTaxAdapter) GetClientByID(db *sql.DB, schemaPrefix string, clientID string) (*types.Client, error) {
+func (a *MyWellTaxAdapter) GetClientByID(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (*types.Client, error) {
 	query := fmt.Sprintf(`
-		SELECT id, first_name, middle_name, last_name, email, phone, dob, ssn, address1, address2, city, state, zipcode, role, created_at
+		SELECT id, first_name, middle_name, last_name, email, phone, dob, ssn, address1, address2, city, state, zipcode, role, created_at,
+		       death_date, archived_at, archive_reason
 		FROM %s.user
 		WHERE id = $1
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("MyWellTax adapter fetching client %s", clientID)
 
-	row := db.QueryRow(query, clientID)
+	row := db.QueryRowContext(ctx, query, clientID)
 
 	client := &types.Client{}
 	var ssnEncrypted sql.NullString
@@ -98,10 +262,13 @@ func (a *MyWellTaxAdapter) GetClientByID(db *sql.DB, schemaPrefix string, client
 		&client.Zipcode,
 		&client.Role,
 		&client.CreatedAt,
+		&client.DeathDate,
+		&client.ArchivedAt,
+		&client.ArchiveReason,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("client not found")
+			return nil, apperr.NotFound("client not found")
 		}
 		logger.Errorf("MyWellTax adapter failed to get client %s: %v", clientID, err)
 		return nil, fmt.Errorf("failed to get client: %w", err)
@@ -115,3 +282,194 @@ func (a *MyWellTaxAdapter) GetClientByID(db *sql.DB, schemaPrefix string, client
 
 	return client, nil
 }
+
+// ArchiveClient marks a client as archived with a reason
+func (a *MyWellTaxAdapter) ArchiveClient(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string, reason string) (*types.Client, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.user
+		SET archived_at = NOW(), archive_reason = $2
+		WHERE id = $1 AND role = 'user' AND archived_at IS NULL
+	`, sqlident.Schema(schemaPrefix))
+
+	logger.Infof("MyWellTax adapter archiving client %s", clientID)
+
+	result, err := db.ExecContext(ctx, query, clientID, reason)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to archive client %s: %v", clientID, err)
+		return nil, fmt.Errorf("failed to archive client: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to archive client: %w", err)
+	}
+	if rows == 0 {
+		return nil, apperr.Conflict("client not found or already archived")
+	}
+
+	return a.GetClientByID(ctx, db, schemaPrefix, clientID)
+}
+
+// UnarchiveClient restores an archived client
+func (a *MyWellTaxAdapter) UnarchiveClient(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (*types.Client, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.user
+		SET archived_at = NULL, archive_reason = NULL
+		WHERE id = $1 AND role = 'user' AND archived_at IS NOT NULL
+	`, sqlident.Schema(schemaPrefix))
+
+	logger.Infof("MyWellTax adapter unarchiving client %s", clientID)
+
+	result, err := db.ExecContext(ctx, query, clientID)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to unarchive client %s: %v", clientID, err)
+		return nil, fmt.Errorf("failed to unarchive client: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to unarchive client: %w", err)
+	}
+	if rows == 0 {
+		return nil, apperr.Conflict("client not found or not archived")
+	}
+
+	return a.GetClientByID(ctx, db, schemaPrefix, clientID)
+}
+
+// IsClientArchived reports whether a client is archived
+func (a *MyWellTaxAdapter) IsClientArchived(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (bool, error) {
+	query := fmt.Sprintf(`
+		SELECT archived_at IS NOT NULL FROM %s.user WHERE id = $1
+	`, sqlident.Schema(schemaPrefix))
+
+	var archived bool
+	if err := db.QueryRowContext(ctx, query, clientID).Scan(&archived); err != nil {
+		if err == sql.ErrNoRows {
+			return false, apperr.NotFound("client not found")
+		}
+		logger.Errorf("MyWellTax adapter failed to check archive status for client %s: %v", clientID, err)
+		return false, fmt.Errorf("failed to check archive status: %w", err)
+	}
+
+	return archived, nil
+}
+
+// MarkClientDeceased records the death of the taxpayer or their spouse
+func (a *MyWellTaxAdapter) MarkClientDeceased(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string, person string, deathDate string) error {
+	var query string
+	switch person {
+	case types.DeceasedPersonTaxpayer:
+		query = fmt.Sprintf(`UPDATE %s.user SET death_date = $2 WHERE id = $1`, sqlident.Schema(schemaPrefix))
+	case types.DeceasedPersonSpouse:
+		query = fmt.Sprintf(`UPDATE %s.spouse SET is_death = true, death_date = $2 WHERE user_id = $1`, sqlident.Schema(schemaPrefix))
+	default:
+		return apperr.Validation("invalid deceased person: %s", person)
+	}
+
+	logger.Infof("MyWellTax adapter marking %s of client %s as deceased", person, clientID)
+
+	result, err := db.ExecContext(ctx, query, clientID, deathDate)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to mark %s of client %s as deceased: %v", person, clientID, err)
+		return fmt.Errorf("failed to mark deceased: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to mark deceased: %w", err)
+	}
+	if rows == 0 {
+		return apperr.NotFound("%s not found for client", person)
+	}
+
+	return nil
+}
+
+// GetSSNRecords returns every encrypted taxpayer and spouse SSN in the tenant for integrity checks
+func (a *MyWellTaxAdapter) GetSSNRecords(ctx context.Context, db *sql.DB, schemaPrefix string) ([]*types.SSNRecord, error) {
+	query := fmt.Sprintf(`
+		SELECT id, 'taxpayer', TRIM(COALESCE(first_name, '') || ' ' || COALESCE(last_name, '')), ssn
+		FROM %s.user
+		WHERE role = 'user' AND ssn IS NOT NULL AND ssn <> ''
+		UNION ALL
+		SELECT user_id, 'spouse', TRIM(COALESCE(first_name, '') || ' ' || COALESCE(last_name, '')), ssn
+		FROM %s.spouse
+		WHERE ssn IS NOT NULL AND ssn <> ''
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+
+	logger.Infof("MyWellTax adapter fetching SSN records for integrity checks")
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to query SSN records: %v", err)
+		return nil, fmt.Errorf("failed to query SSN records: %w", err)
+	}
+	defer rows.Close()
+
+	var records []*types.SSNRecord
+	for rows.Next() {
+		rec := &types.SSNRecord{}
+		if err := rows.Scan(&rec.ClientID, &rec.Role, &rec.Name, &rec.EncryptedSSN); err != nil {
+			logger.Errorf("MyWellTax adapter failed to scan SSN record: %v", err)
+			return nil, fmt.Errorf("failed to scan SSN record: %w", err)
+		}
+		records = append(records, rec)
+	}
+
+	return records, rows.Err()
+}
+
+// myWellTaxSSNTables are the tables holding SSNs, each in an ssn column keyed by id
+var myWellTaxSSNTables = []string{"user", "spouse", "dependent"}
+
+// ReencryptSSNs rewrites every taxpayer, spouse and dependent SSN that reencrypt changes. Each
+// row is updated only if its SSN is unchanged since it was read, so concurrent edits win.
+func (a *MyWellTaxAdapter) ReencryptSSNs(ctx context.Context, db *sql.DB, schemaPrefix string, reencrypt ReencryptSSN) (int, error) {
+	rewritten := 0
+	for _, table := range myWellTaxSSNTables {
+		rows, err := db.QueryContext(ctx, fmt.Sprintf(`
+			SELECT id, ssn FROM %s.%s WHERE ssn IS NOT NULL AND ssn <> ''
+		`, sqlident.Schema(schemaPrefix), table))
+		if err != nil {
+			logger.Errorf("MyWellTax adapter failed to query %s SSNs: %v", table, err)
+			return rewritten, fmt.Errorf("failed to query %s SSNs: %w", table, err)
+		}
+
+		type storedSSN struct{ id, ssn string }
+		var stored []storedSSN
+		for rows.Next() {
+			var s storedSSN
+			if err := rows.Scan(&s.id, &s.ssn); err != nil {
+				rows.Close()
+				return rewritten, fmt.Errorf("failed to scan %s SSN: %w", table, err)
+			}
+			stored = append(stored, s)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return rewritten, err
+		}
+
+		update := fmt.Sprintf(`UPDATE %s.%s SET ssn = $1 WHERE id = $2 AND ssn = $3`, sqlident.Schema(schemaPrefix), table)
+		for _, s := range stored {
+			value, changed, err := reencrypt(s.ssn)
+			if err != nil {
+				return rewritten, fmt.Errorf("failed to re-encrypt SSN of %s %s: %w", table, s.id, err)
+			}
+			if !changed {
+				continue
+			}
+			result, err := db.ExecContext(ctx, update, value, s.id, s.ssn)
+			if err != nil {
+				logger.Errorf("MyWellTax adapter failed to rewrite SSN of %s %s: %v", table, s.id, err)
+				return rewritten, fmt.Errorf("failed to rewrite SSN of %s %s: %w", table, s.id, err)
+			}
+			if n, err := result.RowsAffected(); err == nil && n > 0 {
+				rewritten++
+			}
+		}
+		logger.Infof("MyWellTax adapter re-encrypted %s SSNs (%d read, %d rewritten so far)", table, len(stored), rewritten)
+	}
+	return rewritten, nil
+}
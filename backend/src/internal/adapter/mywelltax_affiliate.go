@@ -1,17 +1,22 @@
 package adapter
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/sqlident"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // GetAffiliates retrieves all affiliates from MyWellTax database
-func (a *MyWellTaxAdapter) GetAffiliates(db *sql.DB, schemaPrefix string, activeOnly bool) ([]*types.Affiliate, error) {
+func (a *MyWellTaxAdapter) GetAffiliates(ctx context.Context, db *sql.DB, schemaPrefix string, activeOnly bool) ([]*types.Affiliate, error) {
 	query := fmt.Sprintf(`
 		SELECT id, first_name, last_name, email, phone, default_commission_rate,
 		       stripe_connect_account_id, payout_method, payout_threshold,
@@ -19,7 +24,7 @@ func (a *MyWellTaxAdapter) GetAffiliates(db *sql.DB, schemaPrefix string, active
 		FROM %s.affiliates
 		%s
 		ORDER BY created_at DESC
-	`, schemaPrefix, func() string {
+	`, sqlident.Schema(schemaPrefix), func() string {
 		if activeOnly {
 			return "WHERE is_active = true"
 		}
@@ -28,7 +33,7 @@ func (a *MyWellTaxAdapter) GetAffiliates(db *sql.DB, schemaPrefix string, active
 
 	logger.Infof("MyWellTax adapter fetching affiliates (activeOnly=%v)", activeOnly)
 
-	rows, err := db.Query(query)
+	rows, err := db.QueryContext(ctx, query)
 	if err != nil {
 		logger.Errorf("MyWellTax adapter failed to query affiliates: %v", err)
 		return nil, fmt.Errorf("failed to query affiliates: %w", err)
@@ -69,18 +74,18 @@ func (a *MyWellTaxAdapter) GetAffiliates(db *sql.DB, schemaPrefix string, active
 }
 
 // GetAffiliateByID retrieves a specific affiliate by ID
-func (a *MyWellTaxAdapter) GetAffiliateByID(db *sql.DB, schemaPrefix string, affiliateID string) (*types.Affiliate, error) {
+func (a *MyWellTaxAdapter) GetAffiliateByID(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string) (*types.Affiliate, error) {
 	query := fmt.Sprintf(`
 		SELECT id, first_name, last_name, email, phone, default_commission_rate,
 		       stripe_connect_account_id, payout_method, payout_threshold,
 		       is_active, created_at, updated_at
 		FROM %s.affiliates
 		WHERE id = $1
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("MyWellTax adapter fetching affiliate %s", affiliateID)
 
-	row := db.QueryRow(query, affiliateID)
+	row := db.QueryRowContext(ctx, query, affiliateID)
 
 	affiliate := &types.Affiliate{}
 	err := row.Scan(
@@ -99,7 +104,7 @@ func (a *MyWellTaxAdapter) GetAffiliateByID(db *sql.DB, schemaPrefix string, aff
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("affiliate not found")
+			return nil, apperr.NotFound("affiliate not found")
 		}
 		logger.Errorf("MyWellTax adapter failed to get affiliate %s: %v", affiliateID, err)
 		return nil, fmt.Errorf("failed to get affiliate: %w", err)
@@ -109,20 +114,22 @@ func (a *MyWellTaxAdapter) GetAffiliateByID(db *sql.DB, schemaPrefix string, aff
 }
 
 // CreateAffiliate creates a new affiliate
-func (a *MyWellTaxAdapter) CreateAffiliate(db *sql.DB, schemaPrefix string, affiliate *types.Affiliate) (*types.Affiliate, error) {
+func (a *MyWellTaxAdapter) CreateAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliate *types.Affiliate) (*types.Affiliate, error) {
 	query := fmt.Sprintf(`
 		INSERT INTO %s.affiliates (
-			first_name, last_name, email, phone, default_commission_rate,
+			id, first_name, last_name, email, phone, default_commission_rate,
 			payout_method, payout_threshold, is_active
 		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, created_at, updated_at
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("MyWellTax adapter creating affiliate: %s %s (%s)", affiliate.FirstName, affiliate.LastName, affiliate.Email)
 
-	err := db.QueryRow(
+	err := db.QueryRowContext(
+		ctx,
 		query,
+		a.newID(),
 		affiliate.FirstName,
 		affiliate.LastName,
 		affiliate.Email,
@@ -143,7 +150,7 @@ func (a *MyWellTaxAdapter) CreateAffiliate(db *sql.DB, schemaPrefix string, affi
 }
 
 // UpdateAffiliate updates an existing affiliate
-func (a *MyWellTaxAdapter) UpdateAffiliate(db *sql.DB, schemaPrefix string, affiliateID string, affiliate *types.Affiliate) (*types.Affiliate, error) {
+func (a *MyWellTaxAdapter) UpdateAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, affiliate *types.Affiliate) (*types.Affiliate, error) {
 	query := fmt.Sprintf(`
 		UPDATE %s.affiliates
 		SET first_name = $1, last_name = $2, email = $3, phone = $4,
@@ -154,11 +161,12 @@ func (a *MyWellTaxAdapter) UpdateAffiliate(db *sql.DB, schemaPrefix string, affi
 		RETURNING id, first_name, last_name, email, phone, default_commission_rate,
 		          stripe_connect_account_id, payout_method, payout_threshold,
 		          is_active, created_at, updated_at
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("MyWellTax adapter updating affiliate %s", affiliateID)
 
-	row := db.QueryRow(
+	row := db.QueryRowContext(
+		ctx,
 		query,
 		affiliate.FirstName,
 		affiliate.LastName,
@@ -189,7 +197,7 @@ func (a *MyWellTaxAdapter) UpdateAffiliate(db *sql.DB, schemaPrefix string, affi
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("affiliate not found")
+			return nil, apperr.NotFound("affiliate not found")
 		}
 		logger.Errorf("MyWellTax adapter failed to update affiliate %s: %v", affiliateID, err)
 		return nil, fmt.Errorf("failed to update affiliate: %w", err)
@@ -199,40 +207,96 @@ func (a *MyWellTaxAdapter) UpdateAffiliate(db *sql.DB, schemaPrefix string, affi
 	return updated, nil
 }
 
-// GetCommissionsByAffiliate retrieves commissions for a specific affiliate (or all if affiliateID is nil)
-func (a *MyWellTaxAdapter) GetCommissionsByAffiliate(db *sql.DB, schemaPrefix string, affiliateID *string, status *string, limit int) ([]*types.Commission, error) {
-	var whereClause string
-	args := []interface{}{}
-
-	// Build WHERE clause dynamically
-	conditions := []string{}
+// PatchAffiliate sets only the affiliate columns the patch provides
+func (a *MyWellTaxAdapter) PatchAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, patch *types.AffiliatePatch) (*types.Affiliate, error) {
+	var sets []string
+	var args []interface{}
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
 
-	if affiliateID != nil {
-		conditions = append(conditions, fmt.Sprintf("c.affiliate_id = $%d", len(args)+1))
-		args = append(args, *affiliateID)
+	if patch.FirstName != nil {
+		set("first_name", *patch.FirstName)
+	}
+	if patch.LastName != nil {
+		set("last_name", *patch.LastName)
+	}
+	if patch.Email != nil {
+		set("email", *patch.Email)
+	}
+	if patch.Phone != nil {
+		set("phone", sql.NullString{String: *patch.Phone, Valid: *patch.Phone != ""})
+	}
+	if patch.DefaultCommissionRate != nil {
+		set("default_commission_rate", *patch.DefaultCommissionRate)
 	}
+	if patch.PayoutMethod != nil {
+		set("payout_method", *patch.PayoutMethod)
+	}
+	if patch.PayoutThreshold != nil {
+		set("payout_threshold", *patch.PayoutThreshold)
+	}
+	if patch.IsActive != nil {
+		set("is_active", *patch.IsActive)
+	}
+	sets = append(sets, "updated_at = NOW()")
+	args = append(args, affiliateID)
 
-	if status != nil {
-		conditions = append(conditions, fmt.Sprintf("c.status = $%d", len(args)+1))
-		args = append(args, *status)
+	query := fmt.Sprintf(`
+		UPDATE %s.affiliates
+		SET %s
+		WHERE id = $%d
+		RETURNING id, first_name, last_name, email, phone, default_commission_rate,
+		          stripe_connect_account_id, payout_method, payout_threshold,
+		          is_active, created_at, updated_at
+	`, sqlident.Schema(schemaPrefix), strings.Join(sets, ", "), len(args))
+
+	logger.Infof("MyWellTax adapter patching %d fields of affiliate %s", len(sets)-1, affiliateID)
+
+	updated := &types.Affiliate{}
+	err := db.QueryRowContext(ctx, query, args...).Scan(
+		&updated.ID,
+		&updated.FirstName,
+		&updated.LastName,
+		&updated.Email,
+		&updated.Phone,
+		&updated.DefaultCommissionRate,
+		&updated.StripeConnectAccountID,
+		&updated.PayoutMethod,
+		&updated.PayoutThreshold,
+		&updated.IsActive,
+		&updated.CreatedAt,
+		&updated.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperr.NotFound("affiliate not found")
+		}
+		logger.Errorf("MyWellTax adapter failed to patch affiliate %s: %v", affiliateID, err)
+		return nil, fmt.Errorf("failed to update affiliate: %w", err)
 	}
 
+	return updated, nil
+}
+
+// GetCommissionsByAffiliate retrieves commissions for a specific affiliate (or all if affiliateID is nil)
+// A non-nil commissionIDs restricts the result to those commissions
+func (a *MyWellTaxAdapter) GetCommissionsByAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, status *string, commissionIDs []string, limit int) ([]*types.Commission, error) {
+	var whereClause string
+	conditions, args := commissionConditions(affiliateID, status, commissionIDs)
 	if len(conditions) > 0 {
 		whereClause = "WHERE " + strings.Join(conditions, " AND ")
 	}
 
 	query := fmt.Sprintf(`
-		SELECT c.id, c.affiliate_id, c.filing_id, c.user_id, c.discount_code_id,
-		       c.payment_id, c.order_amount, c.discount_amount, c.net_amount,
-		       c.commission_rate, c.commission_amount, c.status,
-		       c.approved_at, c.paid_at, c.notes, c.created_at, c.updated_at,
-		       u.id, u.first_name, u.last_name, u.email
+		SELECT %s
 		FROM %s.commissions c
 		JOIN %s.user u ON c.user_id = u.id
 		%s
 		ORDER BY c.created_at DESC
 		LIMIT $%d
-	`, schemaPrefix, schemaPrefix, whereClause, len(args)+1)
+	`, myWellTaxCommissionColumns, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix), whereClause, len(args)+1)
 
 	args = append(args, limit)
 
@@ -242,7 +306,130 @@ func (a *MyWellTaxAdapter) GetCommissionsByAffiliate(db *sql.DB, schemaPrefix st
 		logger.Infof("MyWellTax adapter fetching all commissions (status=%v, limit=%d)", status, limit)
 	}
 
-	rows, err := db.Query(query, args...)
+	commissions, err := queryMyWellTaxCommissions(ctx, db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("MyWellTax adapter successfully fetched %d commissions", len(commissions))
+	return commissions, nil
+}
+
+// GetCommissionPage retrieves one page of commissions, newest first, with the GetCommissionsByAffiliate filters
+func (a *MyWellTaxAdapter) GetCommissionPage(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, status *string, commissionIDs []string, page types.PageRequest) (*types.CommissionPage, error) {
+	var whereClause string
+	conditions, args := commissionConditions(affiliateID, status, commissionIDs)
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	result := &types.CommissionPage{Items: []*types.Commission{}}
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s.commissions c %s`, sqlident.Schema(schemaPrefix), whereClause)
+	if err := db.QueryRowContext(ctx, countQuery, args...).Scan(&result.TotalCount); err != nil {
+		logger.Errorf("MyWellTax adapter failed to count commissions: %v", err)
+		return nil, fmt.Errorf("failed to count commissions: %w", err)
+	}
+
+	keyset, args := keysetCondition(page, "c.created_at", "c.id", args)
+	if keyset != "" {
+		conditions = append(conditions, keyset)
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.commissions c
+		JOIN %s.user u ON c.user_id = u.id
+		%s
+		ORDER BY c.created_at DESC, c.id DESC
+		LIMIT $%d
+	`, myWellTaxCommissionColumns, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix), whereClause, len(args)+1)
+	args = append(args, page.Size()+1)
+
+	commissions, err := queryMyWellTaxCommissions(ctx, db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(commissions) > page.Size() {
+		commissions = commissions[:page.Size()]
+		last := commissions[len(commissions)-1]
+		result.NextCursor = nextPageCursor(last.CreatedAt.Format(time.RFC3339Nano), last.ID)
+	}
+	if commissions != nil {
+		result.Items = commissions
+	}
+
+	logger.Infof("MyWellTax adapter fetched a page of %d of %d commissions", len(result.Items), result.TotalCount)
+	return result, nil
+}
+
+// GetOverdueCommissions retrieves up to limit PENDING commissions created before createdBefore,
+// oldest first, with how many there are in total
+func (a *MyWellTaxAdapter) GetOverdueCommissions(ctx context.Context, db *sql.DB, schemaPrefix string, createdBefore time.Time, limit int) ([]*types.Commission, int, error) {
+	var total int
+	countQuery := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s.commissions c
+		WHERE c.status = $1 AND c.created_at < $2
+	`, sqlident.Schema(schemaPrefix))
+	if err := db.QueryRowContext(ctx, countQuery, types.CommissionStatusPending, createdBefore).Scan(&total); err != nil {
+		logger.Errorf("MyWellTax adapter failed to count overdue commissions: %v", err)
+		return nil, 0, fmt.Errorf("failed to count overdue commissions: %w", err)
+	}
+	if total == 0 {
+		return nil, 0, nil
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.commissions c
+		JOIN %s.user u ON c.user_id = u.id
+		WHERE c.status = $1 AND c.created_at < $2
+		ORDER BY c.created_at, c.id
+		LIMIT $3
+	`, myWellTaxCommissionColumns, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+
+	commissions, err := queryMyWellTaxCommissions(ctx, db, query, types.CommissionStatusPending, createdBefore, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	logger.Infof("MyWellTax adapter found %d overdue commissions", total)
+	return commissions, total, nil
+}
+
+// myWellTaxCommissionColumns are the commission and customer columns scanned by queryMyWellTaxCommissions
+const myWellTaxCommissionColumns = `c.id, c.affiliate_id, c.filing_id, c.user_id, c.discount_code_id,
+		       c.payment_id, c.order_amount, c.discount_amount, c.net_amount,
+		       c.commission_rate, c.commission_amount, c.status,
+		       c.approved_at, c.paid_at, c.notes, c.created_at, c.updated_at,
+		       u.id, u.first_name, u.last_name, u.email`
+
+// commissionConditions builds the WHERE conditions shared by the commission listings
+func commissionConditions(affiliateID *string, status *string, commissionIDs []string) ([]string, []interface{}) {
+	conditions := []string{}
+	args := []interface{}{}
+
+	if affiliateID != nil {
+		conditions = append(conditions, fmt.Sprintf("c.affiliate_id = $%d", len(args)+1))
+		args = append(args, *affiliateID)
+	}
+
+	if status != nil {
+		conditions = append(conditions, fmt.Sprintf("c.status = $%d", len(args)+1))
+		args = append(args, *status)
+	}
+
+	if commissionIDs != nil {
+		conditions = append(conditions, fmt.Sprintf("c.id = ANY($%d::uuid[])", len(args)+1))
+		args = append(args, pq.Array(commissionIDs))
+	}
+
+	return conditions, args
+}
+
+// queryMyWellTaxCommissions runs a commission query selecting myWellTaxCommissionColumns
+func queryMyWellTaxCommissions(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]*types.Commission, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		logger.Errorf("MyWellTax adapter failed to query commissions: %v", err)
 		return nil, fmt.Errorf("failed to query commissions: %w", err)
@@ -289,16 +476,34 @@ func (a *MyWellTaxAdapter) GetCommissionsByAffiliate(db *sql.DB, schemaPrefix st
 		return nil, fmt.Errorf("error iterating commissions: %w", err)
 	}
 
-	logger.Infof("MyWellTax adapter successfully fetched %d commissions", len(commissions))
 	return commissions, nil
 }
 
+// RecordAffiliateClick stores a visit through an affiliate's tracking link
+func (a *MyWellTaxAdapter) RecordAffiliateClick(ctx context.Context, db *sql.DB, schemaPrefix string, click *types.AffiliateClick) error {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.affiliate_clicks (affiliate_id, ip_address, user_agent, referrer, landing_url, signed,
+		                                 utm_source, utm_medium, utm_campaign, utm_term, utm_content)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	`, sqlident.Schema(schemaPrefix))
+
+	_, err := db.ExecContext(ctx, query, click.AffiliateID, click.IPAddress, click.UserAgent, click.Referrer, click.LandingURL, click.Signed,
+		click.UTMSource, click.UTMMedium, click.UTMCampaign, click.UTMTerm, click.UTMContent)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to record click for affiliate %s: %v", click.AffiliateID, err)
+		return fmt.Errorf("failed to record affiliate click: %w", err)
+	}
+
+	return nil
+}
+
 // GetAffiliateStats calculates aggregate statistics for an affiliate
-func (a *MyWellTaxAdapter) GetAffiliateStats(db *sql.DB, schemaPrefix string, affiliateID string) (*types.AffiliateStats, error) {
+func (a *MyWellTaxAdapter) GetAffiliateStats(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string) (*types.AffiliateStats, error) {
 	query := fmt.Sprintf(`
 		SELECT
-			-- Clicks
-			COALESCE((SELECT COUNT(*) FROM %s.affiliate_clicks WHERE affiliate_id = $1), 0) as total_clicks,
+			-- Clicks (recent raw rows plus older clicks rolled up by day)
+			COALESCE((SELECT COUNT(*) FROM %s.affiliate_clicks WHERE affiliate_id = $1), 0)
+			+ COALESCE((SELECT SUM(clicks) FROM %s.affiliate_click_rollups WHERE affiliate_id = $1), 0) as total_clicks,
 
 			-- Conversions (commissions)
 			COALESCE(COUNT(c.id), 0) as total_conversions,
@@ -316,7 +521,7 @@ func (a *MyWellTaxAdapter) GetAffiliateStats(db *sql.DB, schemaPrefix string, af
 			COALESCE(SUM(c.order_amount), 0) as total_revenue
 		FROM %s.commissions c
 		WHERE c.affiliate_id = $1
-	`, schemaPrefix, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
 
 	logger.Infof("MyWellTax adapter calculating stats for affiliate %s", affiliateID)
 
@@ -324,7 +529,7 @@ func (a *MyWellTaxAdapter) GetAffiliateStats(db *sql.DB, schemaPrefix string, af
 		AffiliateID: uuid.MustParse(affiliateID),
 	}
 
-	err := db.QueryRow(query, affiliateID).Scan(
+	err := db.QueryRowContext(ctx, query, affiliateID).Scan(
 		&stats.TotalClicks,
 		&stats.TotalConversions,
 		&stats.PendingCommissions,
@@ -351,22 +556,69 @@ func (a *MyWellTaxAdapter) GetAffiliateStats(db *sql.DB, schemaPrefix string, af
 	return stats, nil
 }
 
-// ApproveCommission approves a pending commission
-func (a *MyWellTaxAdapter) ApproveCommission(db *sql.DB, schemaPrefix string, commissionID string) (*types.Commission, error) {
+// clickRollupBatchSize bounds the raw clicks moved into rollups per transaction
+const clickRollupBatchSize = 10000
+
+// RollupAffiliateClicks moves clicks made before cutoff into daily per-affiliate, per-campaign
+// buckets in affiliate_click_rollups and deletes them. Each batch is rolled up and deleted in one
+// statement, so click totals are the same before and after; it returns the clicks moved.
+func (a *MyWellTaxAdapter) RollupAffiliateClicks(ctx context.Context, db *sql.DB, schemaPrefix string, cutoff time.Time) (int, error) {
+	query := fmt.Sprintf(`
+		WITH moved AS (
+			DELETE FROM %s.affiliate_clicks
+			WHERE ctid IN (
+				SELECT ctid FROM %s.affiliate_clicks
+				WHERE created_at < $1
+				LIMIT $2
+			)
+			RETURNING affiliate_id, created_at, signed, utm_campaign
+		), rolled AS (
+			INSERT INTO %s.affiliate_click_rollups AS r (affiliate_id, click_date, utm_campaign, clicks, signed_clicks)
+			SELECT affiliate_id, created_at::date, COALESCE(LOWER(utm_campaign), ''),
+			       COUNT(*), COUNT(*) FILTER (WHERE signed)
+			FROM moved
+			GROUP BY 1, 2, 3
+			ON CONFLICT (affiliate_id, click_date, utm_campaign) DO UPDATE
+			SET clicks = r.clicks + EXCLUDED.clicks, signed_clicks = r.signed_clicks + EXCLUDED.signed_clicks
+		)
+		SELECT COUNT(*) FROM moved
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+
+	logger.Infof("MyWellTax adapter rolling up affiliate clicks before %s", cutoff.Format("2006-01-02"))
+
+	total := 0
+	for {
+		var moved int
+		if err := db.QueryRowContext(ctx, query, cutoff, clickRollupBatchSize).Scan(&moved); err != nil {
+			logger.Errorf("MyWellTax adapter failed to roll up affiliate clicks: %v", err)
+			return total, fmt.Errorf("failed to roll up affiliate clicks: %w", err)
+		}
+		total += moved
+		if moved < clickRollupBatchSize {
+			break
+		}
+	}
+
+	logger.Infof("MyWellTax adapter rolled up %d affiliate clicks", total)
+	return total, nil
+}
+
+// ApproveCommission approves a pending or under-review commission
+func (a *MyWellTaxAdapter) ApproveCommission(ctx context.Context, db *sql.DB, schemaPrefix string, commissionID string) (*types.Commission, error) {
 	query := fmt.Sprintf(`
 		UPDATE %s.commissions
 		SET status = 'APPROVED', approved_at = NOW(), updated_at = NOW()
-		WHERE id = $1 AND status = 'PENDING'
+		WHERE id = $1 AND status IN ('PENDING', 'REVIEW')
 		RETURNING id, affiliate_id, filing_id, user_id, discount_code_id, payment_id,
 		          order_amount, discount_amount, net_amount, commission_rate,
 		          commission_amount, status, approved_at, paid_at, notes,
 		          created_at, updated_at
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("MyWellTax adapter approving commission %s", commissionID)
 
 	commission := &types.Commission{}
-	err := db.QueryRow(query, commissionID).Scan(
+	err := db.QueryRowContext(ctx, query, commissionID).Scan(
 		&commission.ID,
 		&commission.AffiliateID,
 		&commission.FilingID,
@@ -388,7 +640,7 @@ func (a *MyWellTaxAdapter) ApproveCommission(db *sql.DB, schemaPrefix string, co
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("commission not found or not pending")
+			return nil, apperr.Conflict("commission not found or not pending review/approval")
 		}
 		logger.Errorf("MyWellTax adapter failed to approve commission %s: %v", commissionID, err)
 		return nil, fmt.Errorf("failed to approve commission: %w", err)
@@ -398,69 +650,196 @@ func (a *MyWellTaxAdapter) ApproveCommission(db *sql.DB, schemaPrefix string, co
 	return commission, nil
 }
 
-// MarkCommissionPaid marks an approved commission as paid
-func (a *MyWellTaxAdapter) MarkCommissionPaid(db *sql.DB, schemaPrefix string, commissionID string) (*types.Commission, error) {
-	query := fmt.Sprintf(`
-		UPDATE %s.commissions
-		SET status = 'PAID', paid_at = NOW(), updated_at = NOW()
-		WHERE id = $1 AND status = 'APPROVED'
-		RETURNING id, affiliate_id, filing_id, user_id, discount_code_id, payment_id,
-		          order_amount, discount_amount, net_amount, commission_rate,
-		          commission_amount, status, approved_at, paid_at, notes,
-		          created_at, updated_at
-	`, schemaPrefix)
+// ApproveCommissions approves the listed commissions, or up to limit matching filter (oldest
+// first), in one transaction. Listed commissions that are missing or not pending review/approval
+// are reported as failures; the rest are approved.
+func (a *MyWellTaxAdapter) ApproveCommissions(ctx context.Context, db *sql.DB, schemaPrefix string, commissionIDs []string, filter *types.CommissionFilter, limit int) (*types.BulkCommissionReport, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
 
-	logger.Infof("MyWellTax adapter marking commission %s as paid", commissionID)
+	report := &types.BulkCommissionReport{Results: []*types.BulkCommissionResult{}}
 
-	commission := &types.Commission{}
-	err := db.QueryRow(query, commissionID).Scan(
-		&commission.ID,
-		&commission.AffiliateID,
-		&commission.FilingID,
-		&commission.UserID,
-		&commission.DiscountCodeID,
-		&commission.PaymentID,
-		&commission.OrderAmount,
-		&commission.DiscountAmount,
-		&commission.NetAmount,
-		&commission.CommissionRate,
-		&commission.CommissionAmount,
-		&commission.Status,
-		&commission.ApprovedAt,
-		&commission.PaidAt,
-		&commission.Notes,
-		&commission.CreatedAt,
-		&commission.UpdatedAt,
-	)
+	// Lock the selected commissions so their status cannot change before they are approved
+	var selectQuery string
+	var args []interface{}
+	if commissionIDs != nil {
+		selectQuery = fmt.Sprintf(`SELECT id, status FROM %s.commissions WHERE id = ANY($1::uuid[]) FOR UPDATE`, sqlident.Schema(schemaPrefix))
+		args = []interface{}{pq.Array(commissionIDs)}
+	} else {
+		status := types.CommissionStatusPending
+		if filter != nil && filter.Status != nil {
+			status = *filter.Status
+		}
+		conditions := []string{"status = $1"}
+		args = []interface{}{status}
+		if filter != nil && filter.AffiliateID != nil {
+			conditions = append(conditions, fmt.Sprintf("affiliate_id = $%d", len(args)+1))
+			args = append(args, *filter.AffiliateID)
+		}
+		if filter != nil && filter.CreatedFrom != nil {
+			conditions = append(conditions, fmt.Sprintf("created_at >= $%d", len(args)+1))
+			args = append(args, *filter.CreatedFrom)
+		}
+		if filter != nil && filter.CreatedTo != nil {
+			conditions = append(conditions, fmt.Sprintf("created_at < $%d", len(args)+1))
+			args = append(args, *filter.CreatedTo)
+		}
+		// One extra row tells whether the filter matched more than limit
+		selectQuery = fmt.Sprintf(`
+			SELECT id, status FROM %s.commissions
+			WHERE %s
+			ORDER BY created_at
+			LIMIT $%d
+			FOR UPDATE
+		`, sqlident.Schema(schemaPrefix), strings.Join(conditions, " AND "), len(args)+1)
+		args = append(args, limit+1)
+	}
 
+	rows, err := tx.QueryContext(ctx, selectQuery, args...)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("commission not found or not approved")
+		logger.Errorf("MyWellTax adapter failed to select commissions for approval: %v", err)
+		return nil, fmt.Errorf("failed to select commissions: %w", err)
+	}
+	statuses := map[string]string{}
+	var matched []string
+	for rows.Next() {
+		var id uuid.UUID
+		var status string
+		if err := rows.Scan(&id, &status); err != nil {
+			rows.Close()
+			logger.Errorf("MyWellTax adapter failed to scan commission: %v", err)
+			return nil, fmt.Errorf("failed to scan commission: %w", err)
 		}
-		logger.Errorf("MyWellTax adapter failed to mark commission %s as paid: %v", commissionID, err)
-		return nil, fmt.Errorf("failed to mark commission as paid: %w", err)
+		statuses[id.String()] = status
+		matched = append(matched, id.String())
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to select commissions: %w", err)
 	}
 
-	logger.Infof("MyWellTax adapter successfully marked commission %s as paid", commissionID)
-	return commission, nil
+	if commissionIDs == nil {
+		if len(matched) > limit {
+			report.HasMore = true
+			matched = matched[:limit]
+		}
+		commissionIDs = matched
+	}
+
+	var eligible []string
+	for _, id := range commissionIDs {
+		result := &types.BulkCommissionResult{CommissionID: id}
+		status, ok := statuses[id]
+		switch {
+		case !ok:
+			msg := "commission not found"
+			result.Error = &msg
+		case status != types.CommissionStatusPending && status != types.CommissionStatusReview:
+			msg := fmt.Sprintf("commission is %s, not pending review/approval", status)
+			result.Error = &msg
+		default:
+			eligible = append(eligible, id)
+		}
+		report.Results = append(report.Results, result)
+	}
+
+	approved := map[string]*types.Commission{}
+	if len(eligible) > 0 {
+		query := fmt.Sprintf(`
+			UPDATE %s.commissions
+			SET status = 'APPROVED', approved_at = NOW(), updated_at = NOW()
+			WHERE id = ANY($1::uuid[]) AND status IN ('PENDING', 'REVIEW')
+			RETURNING id, affiliate_id, filing_id, user_id, discount_code_id, payment_id,
+			          order_amount, discount_amount, net_amount, commission_rate,
+			          commission_amount, status, approved_at, paid_at, notes,
+			          created_at, updated_at
+		`, sqlident.Schema(schemaPrefix))
+
+		rows, err := tx.QueryContext(ctx, query, pq.Array(eligible))
+		if err != nil {
+			logger.Errorf("MyWellTax adapter failed to approve commissions: %v", err)
+			return nil, fmt.Errorf("failed to approve commissions: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			commission := &types.Commission{}
+			err := rows.Scan(
+				&commission.ID,
+				&commission.AffiliateID,
+				&commission.FilingID,
+				&commission.UserID,
+				&commission.DiscountCodeID,
+				&commission.PaymentID,
+				&commission.OrderAmount,
+				&commission.DiscountAmount,
+				&commission.NetAmount,
+				&commission.CommissionRate,
+				&commission.CommissionAmount,
+				&commission.Status,
+				&commission.ApprovedAt,
+				&commission.PaidAt,
+				&commission.Notes,
+				&commission.CreatedAt,
+				&commission.UpdatedAt,
+			)
+			if err != nil {
+				logger.Errorf("MyWellTax adapter failed to scan approved commission: %v", err)
+				return nil, fmt.Errorf("failed to scan commission: %w", err)
+			}
+			approved[commission.ID.String()] = commission
+		}
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("failed to approve commissions: %w", err)
+		}
+	}
+
+	for _, result := range report.Results {
+		if result.Error == nil {
+			if result.Commission = approved[result.CommissionID]; result.Commission != nil {
+				result.Success = true
+			} else {
+				msg := "commission not pending review/approval"
+				result.Error = &msg
+			}
+		}
+		if result.Success {
+			report.Succeeded++
+		} else {
+			report.Failed++
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Errorf("MyWellTax adapter failed to commit bulk commission approval: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Infof("MyWellTax adapter approved %d commissions (%d failed)", report.Succeeded, report.Failed)
+	return report, nil
 }
 
-// CancelCommission cancels a commission with a reason
-func (a *MyWellTaxAdapter) CancelCommission(db *sql.DB, schemaPrefix string, commissionID string, reason string) (*types.Commission, error) {
+// CancelCommission cancels a commission, appending the reason to any existing notes
+func (a *MyWellTaxAdapter) CancelCommission(ctx context.Context, db *sql.DB, schemaPrefix string, commissionID string, reason string) (*types.Commission, error) {
 	query := fmt.Sprintf(`
 		UPDATE %s.commissions
-		SET status = 'CANCELLED', notes = $2, updated_at = NOW()
-		WHERE id = $1 AND status IN ('PENDING', 'APPROVED')
+		SET status = 'CANCELLED',
+		    notes = CASE WHEN notes IS NULL OR notes = '' THEN $2 ELSE notes || E'\n' || $2 END,
+		    updated_at = NOW()
+		WHERE id = $1 AND status IN ('PENDING', 'REVIEW', 'APPROVED') AND payout_id IS NULL
 		RETURNING id, affiliate_id, filing_id, user_id, discount_code_id, payment_id,
 		          order_amount, discount_amount, net_amount, commission_rate,
 		          commission_amount, status, approved_at, paid_at, notes,
 		          created_at, updated_at
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("MyWellTax adapter cancelling commission %s with reason: %s", commissionID, reason)
 
 	commission := &types.Commission{}
-	err := db.QueryRow(query, commissionID, reason).Scan(
+	err := db.QueryRowContext(ctx, query, commissionID, "Cancelled: "+reason).Scan(
 		&commission.ID,
 		&commission.AffiliateID,
 		&commission.FilingID,
@@ -482,7 +861,7 @@ func (a *MyWellTaxAdapter) CancelCommission(db *sql.DB, schemaPrefix string, com
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("commission not found or already paid/cancelled")
+			return nil, apperr.Conflict("commission not found, already paid/cancelled, or in a payout batch")
 		}
 		logger.Errorf("MyWellTax adapter failed to cancel commission %s: %v", commissionID, err)
 		return nil, fmt.Errorf("failed to cancel commission: %w", err)
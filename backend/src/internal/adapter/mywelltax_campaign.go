@@ -0,0 +1,126 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"welltaxpro/src/internal/sqlident"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/lib/pq"
+)
+
+// GetCampaignMetrics aggregates clicks, codes, conversions, revenue and commission cost per campaign key.
+// Keys are matched case-insensitively against discount_codes.campaign and affiliate_clicks.utm_campaign;
+// the result is keyed by lower-cased campaign key and only contains keys with activity.
+func (a *MyWellTaxAdapter) GetCampaignMetrics(ctx context.Context, db *sql.DB, schemaPrefix string, keys []string) (map[string]*types.CampaignMetrics, error) {
+	lowered := make([]string, 0, len(keys))
+	for _, k := range keys {
+		lowered = append(lowered, strings.ToLower(k))
+	}
+
+	logger.Infof("MyWellTax adapter fetching metrics for %d campaigns", len(keys))
+
+	metrics := map[string]*types.CampaignMetrics{}
+	get := func(key string) *types.CampaignMetrics {
+		m, ok := metrics[key]
+		if !ok {
+			m = &types.CampaignMetrics{}
+			metrics[key] = m
+		}
+		return m
+	}
+
+	// Tracking link visits, recent and rolled up (rollups keep the campaign lower-cased)
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT campaign, SUM(clicks)
+		FROM (
+			SELECT LOWER(utm_campaign) AS campaign, COUNT(*) AS clicks
+			FROM %s.affiliate_clicks
+			WHERE LOWER(utm_campaign) = ANY($1)
+			GROUP BY 1
+			UNION ALL
+			SELECT utm_campaign, SUM(clicks)
+			FROM %s.affiliate_click_rollups
+			WHERE utm_campaign = ANY($1)
+			GROUP BY 1
+		) c
+		GROUP BY 1
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix)), pq.Array(lowered))
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to count campaign clicks: %v", err)
+		return nil, fmt.Errorf("failed to count campaign clicks: %w", err)
+	}
+	for rows.Next() {
+		var key string
+		var clicks int
+		if err := rows.Scan(&key, &clicks); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan campaign clicks: %w", err)
+		}
+		get(key).Clicks = clicks
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating campaign clicks: %w", err)
+	}
+
+	// Codes, conversions and revenue through filing discounts, and commission cost
+	rows, err = db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT LOWER(dc.campaign),
+		       COUNT(*),
+		       COALESCE(SUM(fd.conversions), 0),
+		       COALESCE(SUM(fd.revenue), 0),
+		       COALESCE(SUM(fd.discounts), 0),
+		       COALESCE(SUM(cm.cost), 0)
+		FROM %s.discount_codes dc
+		LEFT JOIN (
+			SELECT discount_code_id, COUNT(*) AS conversions,
+			       SUM(final_amount) AS revenue, SUM(discount_amount) AS discounts
+			FROM %s.filing_discounts
+			GROUP BY discount_code_id
+		) fd ON fd.discount_code_id = dc.id
+		LEFT JOIN (
+			SELECT discount_code_id, SUM(commission_amount) AS cost
+			FROM %s.commissions
+			WHERE status <> 'CANCELLED'
+			GROUP BY discount_code_id
+		) cm ON cm.discount_code_id = dc.id
+		WHERE LOWER(dc.campaign) = ANY($1)
+		GROUP BY 1
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix)), pq.Array(lowered))
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to aggregate campaign conversions: %v", err)
+		return nil, fmt.Errorf("failed to aggregate campaign conversions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var key string
+		var codes, conversions int
+		var revenue, discounts string // filing_discounts amounts are in cents
+		var cost types.Cents          // commission amounts are in dollars
+		if err := rows.Scan(&key, &codes, &conversions, &revenue, &discounts, &cost); err != nil {
+			logger.Errorf("MyWellTax adapter failed to scan campaign conversions: %v", err)
+			return nil, fmt.Errorf("failed to scan campaign conversions: %w", err)
+		}
+		m := get(key)
+		m.Codes = codes
+		m.Conversions = conversions
+		if m.Revenue, err = types.ParseMinorUnits(revenue); err != nil {
+			return nil, err
+		}
+		if m.Discounts, err = types.ParseMinorUnits(discounts); err != nil {
+			return nil, err
+		}
+		m.CommissionCost = cost
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating campaign conversions: %w", err)
+	}
+
+	return metrics, nil
+}
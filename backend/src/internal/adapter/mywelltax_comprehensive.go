@@ -1,9 +1,11 @@
 package adapter
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/sqlident"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
@@ -12,41 +14,41 @@ import (
 )
 
 // GetClientComprehensive retrieves all data related to a MyWellTax client
-func (a *MyWellTaxAdapter) GetClientComprehensive(db *sql.DB, schemaPrefix string, clientID string) (*types.ClientComprehensive, error) {
+func (a *MyWellTaxAdapter) GetClientComprehensive(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (*types.ClientComprehensive, error) {
 	logger.Infof("MyWellTax adapter fetching comprehensive data for client %s", clientID)
 
 	comprehensive := &types.ClientComprehensive{}
 
 	// 1. Get basic client info
-	client, err := a.GetClientByID(db, schemaPrefix, clientID)
+	client, err := a.GetClientByID(ctx, db, schemaPrefix, clientID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get client: %w", err)
 	}
 	comprehensive.Client = client
 
 	// 2. Get spouse (optional)
-	spouse, _ := a.getSpouse(db, schemaPrefix, clientID)
+	spouse, _ := a.getSpouse(ctx, db, schemaPrefix, clientID)
 	comprehensive.Spouse = spouse
 
 	// 3. Get dependents (optional)
-	dependents, _ := a.getDependents(db, schemaPrefix, clientID)
+	dependents, _ := a.getDependents(ctx, db, schemaPrefix, clientID)
 	comprehensive.Dependents = dependents
 
 	// 4. Get all filings with related data
-	filings, _ := a.getFilingsWithRelatedData(db, schemaPrefix, clientID)
+	filings, _ := a.getFilingsWithRelatedData(ctx, db, schemaPrefix, clientID)
 	comprehensive.Filings = filings
 
 	logger.Infof("Successfully fetched comprehensive data for client %s (%d filings)", clientID, len(comprehensive.Filings))
 	return comprehensive, nil
 }
 
-func (a *MyWellTaxAdapter) getSpouse(db *sql.DB, schemaPrefix string, clientID string) (*types.Spouse, error) {
+func (a *MyWellTaxAdapter) getSpouse(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (*types.Spouse, error) {
 	query := fmt.Sprintf(`
 		SELECT id, user_id, first_name, middle_name, last_name, email, phone, dob, ssn, is_death, death_date, created_at
 		FROM %s.spouse WHERE user_id = $1 LIMIT 1
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
-	row := db.QueryRow(query, clientID)
+	row := db.QueryRowContext(ctx, query, clientID)
 	spouse := &types.Spouse{}
 	var ssnEncrypted string
 	err := row.Scan(&spouse.ID, &spouse.UserID, &spouse.FirstName, &spouse.MiddleName, &spouse.LastName, &spouse.Email, &spouse.Phone, &spouse.Dob, &ssnEncrypted, &spouse.IsDeath, &spouse.DeathDate, &spouse.CreatedAt)
@@ -62,13 +64,13 @@ func (a *MyWellTaxAdapter) getSpouse(db *sql.DB, schemaPrefix string, clientID s
 	return spouse, nil
 }
 
-func (a *MyWellTaxAdapter) getDependents(db *sql.DB, schemaPrefix string, clientID string) ([]*types.Dependent, error) {
+func (a *MyWellTaxAdapter) getDependents(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) ([]*types.Dependent, error) {
 	query := fmt.Sprintf(`
 		SELECT id, user_id, first_name, middle_name, last_name, dob, ssn, relationship, time_with_applicant, exclusive_claim, created_at, updated_at
 		FROM %s.dependent WHERE user_id = $1
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
-	rows, err := db.Query(query, clientID)
+	rows, err := db.QueryContext(ctx, query, clientID)
 	if err != nil {
 		return nil, err
 	}
@@ -85,7 +87,7 @@ func (a *MyWellTaxAdapter) getDependents(db *sql.DB, schemaPrefix string, client
 		dep.Ssn = crypto.MaskSSN(ssnEncrypted)
 
 		// Fetch required document types for this dependent
-		docs, err := a.getDependentDocuments(db, schemaPrefix, dep.ID)
+		docs, err := a.getDependentDocuments(ctx, db, schemaPrefix, dep.ID)
 		if err != nil {
 			logger.Warningf("Failed to get dependent documents for %s: %v", dep.ID, err)
 		} else {
@@ -98,15 +100,15 @@ func (a *MyWellTaxAdapter) getDependents(db *sql.DB, schemaPrefix string, client
 }
 
 // getDependentDocuments retrieves the list of required document types for a dependent
-func (a *MyWellTaxAdapter) getDependentDocuments(db *sql.DB, schemaPrefix string, dependentID uuid.UUID) ([]string, error) {
+func (a *MyWellTaxAdapter) getDependentDocuments(ctx context.Context, db *sql.DB, schemaPrefix string, dependentID uuid.UUID) ([]string, error) {
 	query := fmt.Sprintf(`
 		SELECT record_name
 		FROM %s.dependent_document_map
 		WHERE dependent_id = $1
 		ORDER BY created_at
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
-	rows, err := db.Query(query, dependentID)
+	rows, err := db.QueryContext(ctx, query, dependentID)
 	if err != nil {
 		return nil, err
 	}
@@ -123,15 +125,15 @@ func (a *MyWellTaxAdapter) getDependentDocuments(db *sql.DB, schemaPrefix string
 	return documents, rows.Err()
 }
 
-func (a *MyWellTaxAdapter) getFilingsWithRelatedData(db *sql.DB, schemaPrefix string, clientID string) ([]*types.Filing, error) {
+func (a *MyWellTaxAdapter) getFilingsWithRelatedData(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) ([]*types.Filing, error) {
 	query := fmt.Sprintf(`
 		SELECT id, year, user_id, marital_status, spouse, source_of_income, deductions, income, marketplace_insurance, created_at, updated_at
 		FROM %s.filing WHERE user_id = $1 ORDER BY year DESC
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("Fetching filings for client %s with query: %s", clientID, query)
 
-	rows, err := db.Query(query, clientID)
+	rows, err := db.QueryContext(ctx, query, clientID)
 	if err != nil {
 		logger.Errorf("Failed to query filings: %v", err)
 		return nil, err
@@ -150,46 +152,61 @@ func (a *MyWellTaxAdapter) getFilingsWithRelatedData(db *sql.DB, schemaPrefix st
 		logger.Infof("Found filing: year=%d, id=%s", filing.Year, filing.ID)
 
 		// Fetch related data with error logging
-		filing.Status, err = a.getFilingStatus(db, schemaPrefix, filing.ID)
+		filing.Status, err = a.getFilingStatus(ctx, db, schemaPrefix, filing.ID)
 		if err != nil {
 			logger.Warningf("Failed to get filing status for %s: %v", filing.ID, err)
 		}
 
-		filing.Documents, err = a.getFilingDocuments(db, schemaPrefix, filing.ID)
+		filing.Documents, err = a.getFilingDocuments(ctx, db, schemaPrefix, filing.ID)
 		if err != nil {
 			logger.Warningf("Failed to get filing documents for %s: %v", filing.ID, err)
 		}
 
-		filing.Properties, err = a.getFilingProperties(db, schemaPrefix, filing.ID)
+		filing.Properties, err = a.getFilingProperties(ctx, db, schemaPrefix, filing.ID)
 		if err != nil {
 			logger.Warningf("Failed to get filing properties for %s: %v", filing.ID, err)
 		}
 
-		filing.IRAContributions, err = a.getFilingIRAContributions(db, schemaPrefix, filing.ID)
+		filing.IRAContributions, err = a.getFilingIRAContributions(ctx, db, schemaPrefix, filing.ID)
 		if err != nil {
 			logger.Warningf("Failed to get IRA contributions for %s: %v", filing.ID, err)
 		}
 
-		filing.Charities, err = a.getFilingCharities(db, schemaPrefix, filing.ID)
+		filing.Charities, err = a.getFilingCharities(ctx, db, schemaPrefix, filing.ID)
 		if err != nil {
 			logger.Warningf("Failed to get charities for %s: %v", filing.ID, err)
 		}
 
-		filing.Childcares, err = a.getFilingChildcares(db, schemaPrefix, filing.ID)
+		filing.Childcares, err = a.getFilingChildcares(ctx, db, schemaPrefix, filing.ID)
 		if err != nil {
 			logger.Warningf("Failed to get childcares for %s: %v", filing.ID, err)
 		}
 
-		filing.Payments, err = a.getFilingPayments(db, schemaPrefix, filing.ID)
+		filing.Payments, err = a.getFilingPayments(ctx, db, schemaPrefix, filing.ID)
 		if err != nil {
 			logger.Warningf("Failed to get payments for %s: %v", filing.ID, err)
 		}
 
-		filing.Discounts, err = a.getFilingDiscounts(db, schemaPrefix, filing.ID)
+		filing.Discounts, err = a.getFilingDiscounts(ctx, db, schemaPrefix, filing.ID)
 		if err != nil {
 			logger.Warningf("Failed to get discounts for %s: %v", filing.ID, err)
 		}
 
+		filing.StateFilings, err = a.GetStateFilings(ctx, db, schemaPrefix, filing.ID.String())
+		if err != nil {
+			logger.Warningf("Failed to get state filings for %s: %v", filing.ID, err)
+		}
+
+		filing.Result, err = a.getFilingResult(ctx, db, schemaPrefix, filing.ID.String())
+		if err != nil {
+			logger.Warningf("Failed to get filing result for %s: %v", filing.ID, err)
+		}
+
+		filing.Refunds, err = a.GetRefundTrackings(ctx, db, schemaPrefix, filing.ID.String())
+		if err != nil {
+			logger.Warningf("Failed to get refund tracking for %s: %v", filing.ID, err)
+		}
+
 		filings = append(filings, filing)
 	}
 
@@ -197,9 +214,9 @@ func (a *MyWellTaxAdapter) getFilingsWithRelatedData(db *sql.DB, schemaPrefix st
 	return filings, rows.Err()
 }
 
-func (a *MyWellTaxAdapter) getFilingStatus(db *sql.DB, schemaPrefix string, filingID uuid.UUID) (*types.FilingStatus, error) {
-	query := fmt.Sprintf(`SELECT id, filing_id, latest_step, is_completed, status FROM %s.filing_status WHERE filing_id = $1`, schemaPrefix)
-	row := db.QueryRow(query, filingID)
+func (a *MyWellTaxAdapter) getFilingStatus(ctx context.Context, db *sql.DB, schemaPrefix string, filingID uuid.UUID) (*types.FilingStatus, error) {
+	query := fmt.Sprintf(`SELECT id, filing_id, latest_step, is_completed, status FROM %s.filing_status WHERE filing_id = $1`, sqlident.Schema(schemaPrefix))
+	row := db.QueryRowContext(ctx, query, filingID)
 	status := &types.FilingStatus{}
 	err := row.Scan(&status.ID, &status.FilingID, &status.LatestStep, &status.IsCompleted, &status.Status)
 	if err == sql.ErrNoRows {
@@ -208,9 +225,9 @@ func (a *MyWellTaxAdapter) getFilingStatus(db *sql.DB, schemaPrefix string, fili
 	return status, err
 }
 
-func (a *MyWellTaxAdapter) getFilingDocuments(db *sql.DB, schemaPrefix string, filingID uuid.UUID) ([]*types.Document, error) {
-	query := fmt.Sprintf(`SELECT id, user_id, filing_id, name, file_path, type, created_at, updated_at FROM %s.document WHERE filing_id = $1`, schemaPrefix)
-	rows, err := db.Query(query, filingID)
+func (a *MyWellTaxAdapter) getFilingDocuments(ctx context.Context, db *sql.DB, schemaPrefix string, filingID uuid.UUID) ([]*types.Document, error) {
+	query := fmt.Sprintf(`SELECT id, user_id, filing_id, name, file_path, type, created_at, updated_at FROM %s.document WHERE filing_id = $1`, sqlident.Schema(schemaPrefix))
+	rows, err := db.QueryContext(ctx, query, filingID)
 	if err != nil {
 		return nil, err
 	}
@@ -227,13 +244,13 @@ func (a *MyWellTaxAdapter) getFilingDocuments(db *sql.DB, schemaPrefix string, f
 	return documents, rows.Err()
 }
 
-func (a *MyWellTaxAdapter) getFilingProperties(db *sql.DB, schemaPrefix string, filingID uuid.UUID) ([]*types.Property, error) {
+func (a *MyWellTaxAdapter) getFilingProperties(ctx context.Context, db *sql.DB, schemaPrefix string, filingID uuid.UUID) ([]*types.Property, error) {
 	query := fmt.Sprintf(`
 		SELECT p.id, p.user_id, p.address1, p.address2, p.state, p.city, p.zipcode, p.purchase_price, p.closing_cost, p.purchase_date, p.rents, p.royalties, p.updated_at, p.created_at
 		FROM %s.property p JOIN %s.filing_property_map fpm ON fpm.property_id = p.id WHERE fpm.filing_id = $1
-	`, schemaPrefix, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
 
-	rows, err := db.Query(query, filingID)
+	rows, err := db.QueryContext(ctx, query, filingID)
 	if err != nil {
 		return nil, err
 	}
@@ -245,15 +262,15 @@ func (a *MyWellTaxAdapter) getFilingProperties(db *sql.DB, schemaPrefix string,
 		if err := rows.Scan(&prop.ID, &prop.UserID, &prop.Address1, &prop.Address2, &prop.State, &prop.City, &prop.Zipcode, &prop.PurchasePrice, &prop.ClosingCost, &prop.PurchaseDate, &prop.Rents, &prop.Royalties, &prop.UpdatedAt, &prop.CreatedAt); err != nil {
 			return nil, err
 		}
-		prop.Expenses, _ = a.getPropertyExpenses(db, schemaPrefix, prop.ID)
+		prop.Expenses, _ = a.getPropertyExpenses(ctx, db, schemaPrefix, prop.ID)
 		properties = append(properties, prop)
 	}
 	return properties, rows.Err()
 }
 
-func (a *MyWellTaxAdapter) getPropertyExpenses(db *sql.DB, schemaPrefix string, propertyID uuid.UUID) ([]*types.Expense, error) {
-	query := fmt.Sprintf(`SELECT id, property_id, name, amount, created_at FROM %s.expense WHERE property_id = $1`, schemaPrefix)
-	rows, err := db.Query(query, propertyID)
+func (a *MyWellTaxAdapter) getPropertyExpenses(ctx context.Context, db *sql.DB, schemaPrefix string, propertyID uuid.UUID) ([]*types.Expense, error) {
+	query := fmt.Sprintf(`SELECT id, property_id, name, amount, created_at FROM %s.expense WHERE property_id = $1`, sqlident.Schema(schemaPrefix))
+	rows, err := db.QueryContext(ctx, query, propertyID)
 	if err != nil {
 		return nil, err
 	}
@@ -270,9 +287,9 @@ func (a *MyWellTaxAdapter) getPropertyExpenses(db *sql.DB, schemaPrefix string,
 	return expenses, rows.Err()
 }
 
-func (a *MyWellTaxAdapter) getFilingIRAContributions(db *sql.DB, schemaPrefix string, filingID uuid.UUID) ([]*types.IRAContribution, error) {
-	query := fmt.Sprintf(`SELECT id, filing_id, account_type, amount FROM %s.ira_contribution WHERE filing_id = $1`, schemaPrefix)
-	rows, err := db.Query(query, filingID)
+func (a *MyWellTaxAdapter) getFilingIRAContributions(ctx context.Context, db *sql.DB, schemaPrefix string, filingID uuid.UUID) ([]*types.IRAContribution, error) {
+	query := fmt.Sprintf(`SELECT id, filing_id, account_type, amount FROM %s.ira_contribution WHERE filing_id = $1`, sqlident.Schema(schemaPrefix))
+	rows, err := db.QueryContext(ctx, query, filingID)
 	if err != nil {
 		return nil, err
 	}
@@ -289,9 +306,9 @@ func (a *MyWellTaxAdapter) getFilingIRAContributions(db *sql.DB, schemaPrefix st
 	return contributions, rows.Err()
 }
 
-func (a *MyWellTaxAdapter) getFilingCharities(db *sql.DB, schemaPrefix string, filingID uuid.UUID) ([]*types.Charity, error) {
-	query := fmt.Sprintf(`SELECT id, user_id, filing_id, name, contribution FROM %s.charity WHERE filing_id = $1`, schemaPrefix)
-	rows, err := db.Query(query, filingID)
+func (a *MyWellTaxAdapter) getFilingCharities(ctx context.Context, db *sql.DB, schemaPrefix string, filingID uuid.UUID) ([]*types.Charity, error) {
+	query := fmt.Sprintf(`SELECT id, user_id, filing_id, name, contribution FROM %s.charity WHERE filing_id = $1`, sqlident.Schema(schemaPrefix))
+	rows, err := db.QueryContext(ctx, query, filingID)
 	if err != nil {
 		return nil, err
 	}
@@ -308,13 +325,13 @@ func (a *MyWellTaxAdapter) getFilingCharities(db *sql.DB, schemaPrefix string, f
 	return charities, rows.Err()
 }
 
-func (a *MyWellTaxAdapter) getFilingChildcares(db *sql.DB, schemaPrefix string, filingID uuid.UUID) ([]*types.Childcare, error) {
+func (a *MyWellTaxAdapter) getFilingChildcares(ctx context.Context, db *sql.DB, schemaPrefix string, filingID uuid.UUID) ([]*types.Childcare, error) {
 	query := fmt.Sprintf(`
 		SELECT c.id, c.user_id, c.name, c.amount, c.tax_id, c.address1, c.address2, c.city, c.state, c.zipcode
 		FROM %s.childcare c JOIN %s.filing_childcare_map fcm ON fcm.childcare_id = c.id WHERE fcm.filing_id = $1
-	`, schemaPrefix, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
 
-	rows, err := db.Query(query, filingID)
+	rows, err := db.QueryContext(ctx, query, filingID)
 	if err != nil {
 		return nil, err
 	}
@@ -331,13 +348,13 @@ func (a *MyWellTaxAdapter) getFilingChildcares(db *sql.DB, schemaPrefix string,
 	return childcares, rows.Err()
 }
 
-func (a *MyWellTaxAdapter) getFilingPayments(db *sql.DB, schemaPrefix string, filingID uuid.UUID) ([]*types.Payment, error) {
+func (a *MyWellTaxAdapter) getFilingPayments(ctx context.Context, db *sql.DB, schemaPrefix string, filingID uuid.UUID) ([]*types.Payment, error) {
 	query := fmt.Sprintf(`
 		SELECT id, filing_id, stripe_session_id, amount, original_amount, discount_amount, discount_code, status, created_at, updated_at
 		FROM %s.payment WHERE filing_id = $1 ORDER BY created_at DESC
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
-	rows, err := db.Query(query, filingID)
+	rows, err := db.QueryContext(ctx, query, filingID)
 	if err != nil {
 		return nil, err
 	}
@@ -346,33 +363,33 @@ func (a *MyWellTaxAdapter) getFilingPayments(db *sql.DB, schemaPrefix string, fi
 	var payments []*types.Payment
 	for rows.Next() {
 		payment := &types.Payment{}
-		var amountCents float64
-		var originalAmountCents, discountAmountCents *float64
+		var amountCents string
+		var originalAmountCents, discountAmountCents *string
 
 		if err := rows.Scan(&payment.ID, &payment.FilingID, &payment.StripeSessionID, &amountCents, &originalAmountCents, &discountAmountCents, &payment.DiscountCode, &payment.Status, &payment.CreatedAt, &payment.UpdatedAt); err != nil {
 			return nil, err
 		}
 
-		// Convert cents to dollars (data is stored as cents but in decimal format)
-		payment.Amount = amountCents / 100.0
-		if originalAmountCents != nil {
-			dollars := *originalAmountCents / 100.0
-			payment.OriginalAmount = &dollars
+		// Amounts are stored as cents but in decimal format
+		if payment.Amount, err = types.ParseMinorUnits(amountCents); err != nil {
+			return nil, err
+		}
+		if payment.OriginalAmount, err = parseOptionalMinorUnits(originalAmountCents); err != nil {
+			return nil, err
 		}
-		if discountAmountCents != nil {
-			dollars := *discountAmountCents / 100.0
-			payment.DiscountAmount = &dollars
+		if payment.DiscountAmount, err = parseOptionalMinorUnits(discountAmountCents); err != nil {
+			return nil, err
 		}
 
-		payment.Items, _ = a.getPaymentItems(db, schemaPrefix, payment.ID)
+		payment.Items, _ = a.getPaymentItems(ctx, db, schemaPrefix, payment.ID)
 		payments = append(payments, payment)
 	}
 	return payments, rows.Err()
 }
 
-func (a *MyWellTaxAdapter) getPaymentItems(db *sql.DB, schemaPrefix string, paymentID uuid.UUID) ([]*types.PaymentItem, error) {
-	query := fmt.Sprintf(`SELECT id, payment_id, price_id, name, quantity, unit_amount FROM %s.payment_item WHERE payment_id = $1`, schemaPrefix)
-	rows, err := db.Query(query, paymentID)
+func (a *MyWellTaxAdapter) getPaymentItems(ctx context.Context, db *sql.DB, schemaPrefix string, paymentID uuid.UUID) ([]*types.PaymentItem, error) {
+	query := fmt.Sprintf(`SELECT id, payment_id, price_id, name, quantity, unit_amount FROM %s.payment_item WHERE payment_id = $1`, sqlident.Schema(schemaPrefix))
+	rows, err := db.QueryContext(ctx, query, paymentID)
 	if err != nil {
 		return nil, err
 	}
@@ -381,24 +398,38 @@ func (a *MyWellTaxAdapter) getPaymentItems(db *sql.DB, schemaPrefix string, paym
 	var items []*types.PaymentItem
 	for rows.Next() {
 		item := &types.PaymentItem{}
-		var unitAmountCents float64
+		var unitAmountCents string
 		if err := rows.Scan(&item.ID, &item.PaymentID, &item.PriceID, &item.Name, &item.Quantity, &unitAmountCents); err != nil {
 			return nil, err
 		}
-		// Convert cents to dollars (data is stored as cents but in decimal format)
-		item.UnitAmount = unitAmountCents / 100.0
+		// Stored as cents but in decimal format
+		if item.UnitAmount, err = types.ParseMinorUnits(unitAmountCents); err != nil {
+			return nil, err
+		}
 		items = append(items, item)
 	}
 	return items, rows.Err()
 }
 
-func (a *MyWellTaxAdapter) getFilingDiscounts(db *sql.DB, schemaPrefix string, filingID uuid.UUID) ([]*types.FilingDiscount, error) {
+// parseOptionalMinorUnits parses a nullable amount stored as cents in decimal format
+func parseOptionalMinorUnits(s *string) (*types.Cents, error) {
+	if s == nil {
+		return nil, nil
+	}
+	amount, err := types.ParseMinorUnits(*s)
+	if err != nil {
+		return nil, err
+	}
+	return &amount, nil
+}
+
+func (a *MyWellTaxAdapter) getFilingDiscounts(ctx context.Context, db *sql.DB, schemaPrefix string, filingID uuid.UUID) ([]*types.FilingDiscount, error) {
 	query := fmt.Sprintf(`
 		SELECT fd.id, fd.filing_id, fd.discount_code_id, fd.original_amount, fd.discount_amount, fd.final_amount, fd.applied_at, dc.code
 		FROM %s.filing_discounts fd LEFT JOIN %s.discount_codes dc ON dc.id = fd.discount_code_id WHERE fd.filing_id = $1
-	`, schemaPrefix, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
 
-	rows, err := db.Query(query, filingID)
+	rows, err := db.QueryContext(ctx, query, filingID)
 	if err != nil {
 		return nil, err
 	}
@@ -411,10 +442,9 @@ func (a *MyWellTaxAdapter) getFilingDiscounts(db *sql.DB, schemaPrefix string, f
 		if err := rows.Scan(&discount.ID, &discount.FilingID, &discount.DiscountCodeID, &originalAmountCents, &discountAmountCents, &finalAmountCents, &discount.AppliedAt, &discount.Code); err != nil {
 			return nil, err
 		}
-		// Convert cents to dollars
-		discount.OriginalAmount = float64(originalAmountCents) / 100.0
-		discount.DiscountAmount = float64(discountAmountCents) / 100.0
-		discount.FinalAmount = float64(finalAmountCents) / 100.0
+		discount.OriginalAmount = types.Cents(originalAmountCents)
+		discount.DiscountAmount = types.Cents(discountAmountCents)
+		discount.FinalAmount = types.Cents(finalAmountCents)
 		discounts = append(discounts, discount)
 	}
 	return discounts, rows.Err()
@@ -1,18 +1,99 @@
 package adapter
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"strings"
 	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/sqlident"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 // GetDiscountCodes retrieves discount codes from MyWellTax database
-func (a *MyWellTaxAdapter) GetDiscountCodes(db *sql.DB, schemaPrefix string, affiliateID *string, activeOnly bool) ([]*types.DiscountCode, error) {
+func (a *MyWellTaxAdapter) GetDiscountCodes(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, campaign *string, activeOnly bool) ([]*types.DiscountCode, error) {
+	whereClause := ""
+	conditions, args := discountCodeConditions(affiliateID, campaign, activeOnly)
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.discount_codes
+		%s
+		ORDER BY created_at DESC
+	`, myWellTaxDiscountCodeColumns, sqlident.Schema(schemaPrefix), whereClause)
+
+	logger.Infof("MyWellTax adapter fetching discount codes (affiliateID=%v, campaign=%v, activeOnly=%v)", affiliateID, campaign, activeOnly)
+
+	codes, err := queryMyWellTaxDiscountCodes(ctx, db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("MyWellTax adapter successfully fetched %d discount codes", len(codes))
+	return codes, nil
+}
+
+// GetDiscountCodePage retrieves one page of discount codes, newest first, with the GetDiscountCodes filters
+func (a *MyWellTaxAdapter) GetDiscountCodePage(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, campaign *string, activeOnly bool, page types.PageRequest) (*types.DiscountCodePage, error) {
+	whereClause := ""
+	conditions, args := discountCodeConditions(affiliateID, campaign, activeOnly)
+	if len(conditions) > 0 {
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	result := &types.DiscountCodePage{Items: []*types.DiscountCode{}}
+	countQuery := fmt.Sprintf(`SELECT COUNT(*) FROM %s.discount_codes %s`, sqlident.Schema(schemaPrefix), whereClause)
+	if err := db.QueryRowContext(ctx, countQuery, args...).Scan(&result.TotalCount); err != nil {
+		logger.Errorf("MyWellTax adapter failed to count discount codes: %v", err)
+		return nil, fmt.Errorf("failed to count discount codes: %w", err)
+	}
+
+	keyset, args := keysetCondition(page, "created_at", "id", args)
+	if keyset != "" {
+		conditions = append(conditions, keyset)
+		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	}
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.discount_codes
+		%s
+		ORDER BY created_at DESC, id DESC
+		LIMIT $%d
+	`, myWellTaxDiscountCodeColumns, sqlident.Schema(schemaPrefix), whereClause, len(args)+1)
+	args = append(args, page.Size()+1)
+
+	codes, err := queryMyWellTaxDiscountCodes(ctx, db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(codes) > page.Size() {
+		codes = codes[:page.Size()]
+		last := codes[len(codes)-1]
+		result.NextCursor = nextPageCursor(last.CreatedAt, last.ID)
+	}
+	if codes != nil {
+		result.Items = codes
+	}
+
+	logger.Infof("MyWellTax adapter fetched a page of %d of %d discount codes", len(result.Items), result.TotalCount)
+	return result, nil
+}
+
+// myWellTaxDiscountCodeColumns are the discount code columns scanned by queryMyWellTaxDiscountCodes
+const myWellTaxDiscountCodeColumns = `id, code, description, discount_type, discount_value,
+		       max_uses, current_uses, valid_from, valid_until, is_active,
+		       is_affiliate_code, affiliate_id, commission_rate, created_at, updated_at, campaign`
+
+// discountCodeConditions builds the WHERE conditions shared by the discount code listings
+func discountCodeConditions(affiliateID *string, campaign *string, activeOnly bool) ([]string, []interface{}) {
 	var conditions []string
 	var args []interface{}
 	argCount := 0
@@ -23,27 +104,22 @@ func (a *MyWellTaxAdapter) GetDiscountCodes(db *sql.DB, schemaPrefix string, aff
 		args = append(args, *affiliateID)
 	}
 
-	if activeOnly {
-		conditions = append(conditions, "is_active = true")
+	if campaign != nil {
+		argCount++
+		conditions = append(conditions, fmt.Sprintf("campaign = $%d", argCount))
+		args = append(args, *campaign)
 	}
 
-	whereClause := ""
-	if len(conditions) > 0 {
-		whereClause = "WHERE " + strings.Join(conditions, " AND ")
+	if activeOnly {
+		conditions = append(conditions, "is_active = true")
 	}
 
-	query := fmt.Sprintf(`
-		SELECT id, code, description, discount_type, discount_value,
-		       max_uses, current_uses, valid_from, valid_until, is_active,
-		       is_affiliate_code, affiliate_id, commission_rate, created_at, updated_at
-		FROM %s.discount_codes
-		%s
-		ORDER BY created_at DESC
-	`, schemaPrefix, whereClause)
-
-	logger.Infof("MyWellTax adapter fetching discount codes (affiliateID=%v, activeOnly=%v)", affiliateID, activeOnly)
+	return conditions, args
+}
 
-	rows, err := db.Query(query, args...)
+// queryMyWellTaxDiscountCodes runs a discount code query selecting myWellTaxDiscountCodeColumns
+func queryMyWellTaxDiscountCodes(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]*types.DiscountCode, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
 	if err != nil {
 		logger.Errorf("MyWellTax adapter failed to query discount codes: %v", err)
 		return nil, fmt.Errorf("failed to query discount codes: %w", err)
@@ -56,7 +132,8 @@ func (a *MyWellTaxAdapter) GetDiscountCodes(db *sql.DB, schemaPrefix string, aff
 		var description, validFrom, validUntil, updatedAt sql.NullString
 		var maxUses sql.NullInt32
 		var affiliateIDScan sql.NullString
-		var commissionRate sql.NullFloat64
+		var commissionRate *types.Rate
+		var campaignScan sql.NullString
 
 		err := rows.Scan(
 			&code.ID,
@@ -74,6 +151,7 @@ func (a *MyWellTaxAdapter) GetDiscountCodes(db *sql.DB, schemaPrefix string, aff
 			&commissionRate,
 			&code.CreatedAt,
 			&updatedAt,
+			&campaignScan,
 		)
 		if err != nil {
 			logger.Errorf("MyWellTax adapter failed to scan discount code row: %v", err)
@@ -100,12 +178,13 @@ func (a *MyWellTaxAdapter) GetDiscountCodes(db *sql.DB, schemaPrefix string, aff
 				code.AffiliateID = &aID
 			}
 		}
-		if commissionRate.Valid {
-			code.CommissionRate = &commissionRate.Float64
-		}
+		code.CommissionRate = commissionRate
 		if updatedAt.Valid {
 			code.UpdatedAt = &updatedAt.String
 		}
+		if campaignScan.Valid {
+			code.Campaign = &campaignScan.String
+		}
 
 		codes = append(codes, code)
 	}
@@ -115,29 +194,29 @@ func (a *MyWellTaxAdapter) GetDiscountCodes(db *sql.DB, schemaPrefix string, aff
 		return nil, fmt.Errorf("error iterating discount codes: %w", err)
 	}
 
-	logger.Infof("MyWellTax adapter successfully fetched %d discount codes", len(codes))
 	return codes, nil
 }
 
 // GetDiscountCodeByID retrieves a specific discount code by ID
-func (a *MyWellTaxAdapter) GetDiscountCodeByID(db *sql.DB, schemaPrefix string, codeID string) (*types.DiscountCode, error) {
+func (a *MyWellTaxAdapter) GetDiscountCodeByID(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string) (*types.DiscountCode, error) {
 	query := fmt.Sprintf(`
 		SELECT id, code, description, discount_type, discount_value,
 		       max_uses, current_uses, valid_from, valid_until, is_active,
-		       is_affiliate_code, affiliate_id, commission_rate, created_at, updated_at
+		       is_affiliate_code, affiliate_id, commission_rate, created_at, updated_at, campaign
 		FROM %s.discount_codes
 		WHERE id = $1
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("MyWellTax adapter fetching discount code %s", codeID)
 
-	row := db.QueryRow(query, codeID)
+	row := db.QueryRowContext(ctx, query, codeID)
 
 	code := &types.DiscountCode{}
 	var description, validFrom, validUntil, updatedAt sql.NullString
 	var maxUses sql.NullInt32
 	var affiliateID sql.NullString
-	var commissionRate sql.NullFloat64
+	var commissionRate *types.Rate
+	var campaign sql.NullString
 
 	err := row.Scan(
 		&code.ID,
@@ -155,11 +234,12 @@ func (a *MyWellTaxAdapter) GetDiscountCodeByID(db *sql.DB, schemaPrefix string,
 		&commissionRate,
 		&code.CreatedAt,
 		&updatedAt,
+		&campaign,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			logger.Warningf("MyWellTax adapter discount code %s not found", codeID)
-			return nil, fmt.Errorf("discount code not found")
+			return nil, apperr.NotFound("discount code not found")
 		}
 		logger.Errorf("MyWellTax adapter failed to scan discount code: %v", err)
 		return nil, fmt.Errorf("failed to scan discount code: %w", err)
@@ -185,36 +265,38 @@ func (a *MyWellTaxAdapter) GetDiscountCodeByID(db *sql.DB, schemaPrefix string,
 			code.AffiliateID = &aID
 		}
 	}
-	if commissionRate.Valid {
-		code.CommissionRate = &commissionRate.Float64
-	}
+	code.CommissionRate = commissionRate
 	if updatedAt.Valid {
 		code.UpdatedAt = &updatedAt.String
 	}
+	if campaign.Valid {
+		code.Campaign = &campaign.String
+	}
 
 	logger.Infof("MyWellTax adapter successfully fetched discount code %s", code.Code)
 	return code, nil
 }
 
 // GetDiscountCodeByCode retrieves a discount code by its code string
-func (a *MyWellTaxAdapter) GetDiscountCodeByCode(db *sql.DB, schemaPrefix string, code string) (*types.DiscountCode, error) {
+func (a *MyWellTaxAdapter) GetDiscountCodeByCode(ctx context.Context, db *sql.DB, schemaPrefix string, code string) (*types.DiscountCode, error) {
 	query := fmt.Sprintf(`
 		SELECT id, code, description, discount_type, discount_value,
 		       max_uses, current_uses, valid_from, valid_until, is_active,
-		       is_affiliate_code, affiliate_id, commission_rate, created_at, updated_at
+		       is_affiliate_code, affiliate_id, commission_rate, created_at, updated_at, campaign
 		FROM %s.discount_codes
 		WHERE UPPER(code) = UPPER($1)
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("MyWellTax adapter fetching discount code by code: %s", code)
 
-	row := db.QueryRow(query, code)
+	row := db.QueryRowContext(ctx, query, code)
 
 	discountCode := &types.DiscountCode{}
 	var description, validFrom, validUntil, updatedAt sql.NullString
 	var maxUses sql.NullInt32
 	var affiliateID sql.NullString
-	var commissionRate sql.NullFloat64
+	var commissionRate *types.Rate
+	var campaign sql.NullString
 
 	err := row.Scan(
 		&discountCode.ID,
@@ -232,11 +314,12 @@ func (a *MyWellTaxAdapter) GetDiscountCodeByCode(db *sql.DB, schemaPrefix string
 		&commissionRate,
 		&discountCode.CreatedAt,
 		&updatedAt,
+		&campaign,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			logger.Warningf("MyWellTax adapter discount code %s not found", code)
-			return nil, fmt.Errorf("discount code not found")
+			return nil, apperr.NotFound("discount code not found")
 		}
 		logger.Errorf("MyWellTax adapter failed to scan discount code: %v", err)
 		return nil, fmt.Errorf("failed to scan discount code: %w", err)
@@ -262,22 +345,23 @@ func (a *MyWellTaxAdapter) GetDiscountCodeByCode(db *sql.DB, schemaPrefix string
 			discountCode.AffiliateID = &aID
 		}
 	}
-	if commissionRate.Valid {
-		discountCode.CommissionRate = &commissionRate.Float64
-	}
+	discountCode.CommissionRate = commissionRate
 	if updatedAt.Valid {
 		discountCode.UpdatedAt = &updatedAt.String
 	}
+	if campaign.Valid {
+		discountCode.Campaign = &campaign.String
+	}
 
 	logger.Infof("MyWellTax adapter successfully fetched discount code %s", discountCode.Code)
 	return discountCode, nil
 }
 
 // CreateDiscountCode creates a new discount code for an affiliate
-func (a *MyWellTaxAdapter) CreateDiscountCode(db *sql.DB, schemaPrefix string, discountCode *types.DiscountCode) (*types.DiscountCode, error) {
+func (a *MyWellTaxAdapter) CreateDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, discountCode *types.DiscountCode) (*types.DiscountCode, error) {
 	// Generate UUID if not provided
 	if discountCode.ID == uuid.Nil {
-		discountCode.ID = uuid.New()
+		discountCode.ID = a.newID()
 	}
 
 	// Set created timestamp
@@ -290,18 +374,19 @@ func (a *MyWellTaxAdapter) CreateDiscountCode(db *sql.DB, schemaPrefix string, d
 	query := fmt.Sprintf(`
 		INSERT INTO %s.discount_codes
 		(id, code, description, discount_type, discount_value, max_uses, current_uses,
-		 valid_from, valid_until, is_active, is_affiliate_code, affiliate_id, commission_rate, created_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		 valid_from, valid_until, is_active, is_affiliate_code, affiliate_id, commission_rate, created_at, campaign)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
 		RETURNING id, code, description, discount_type, discount_value, max_uses, current_uses,
-		          valid_from, valid_until, is_active, is_affiliate_code, affiliate_id, commission_rate, created_at
-	`, schemaPrefix)
+		          valid_from, valid_until, is_active, is_affiliate_code, affiliate_id, commission_rate, created_at, campaign
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("MyWellTax adapter creating discount code: %s", discountCode.Code)
 
 	var description, validFrom, validUntil sql.NullString
 	var maxUses sql.NullInt32
 	var affiliateID sql.NullString
-	var commissionRate sql.NullFloat64
+	commissionRate := discountCode.CommissionRate
+	var campaign sql.NullString
 
 	// Prepare nullable values for insert
 	if discountCode.Description != nil {
@@ -324,12 +409,12 @@ func (a *MyWellTaxAdapter) CreateDiscountCode(db *sql.DB, schemaPrefix string, d
 		affiliateID.String = discountCode.AffiliateID.String()
 		affiliateID.Valid = true
 	}
-	if discountCode.CommissionRate != nil {
-		commissionRate.Float64 = *discountCode.CommissionRate
-		commissionRate.Valid = true
+	if discountCode.Campaign != nil {
+		campaign.String = *discountCode.Campaign
+		campaign.Valid = true
 	}
 
-	row := db.QueryRow(query,
+	row := db.QueryRowContext(ctx, query,
 		discountCode.ID,
 		discountCode.Code,
 		description,
@@ -344,6 +429,7 @@ func (a *MyWellTaxAdapter) CreateDiscountCode(db *sql.DB, schemaPrefix string, d
 		affiliateID,
 		commissionRate,
 		now,
+		campaign,
 	)
 
 	created := &types.DiscountCode{}
@@ -362,6 +448,7 @@ func (a *MyWellTaxAdapter) CreateDiscountCode(db *sql.DB, schemaPrefix string, d
 		&affiliateID,
 		&commissionRate,
 		&created.CreatedAt,
+		&campaign,
 	)
 	if err != nil {
 		logger.Errorf("MyWellTax adapter failed to create discount code: %v", err)
@@ -388,16 +475,160 @@ func (a *MyWellTaxAdapter) CreateDiscountCode(db *sql.DB, schemaPrefix string, d
 			created.AffiliateID = &aID
 		}
 	}
-	if commissionRate.Valid {
-		created.CommissionRate = &commissionRate.Float64
+	created.CommissionRate = commissionRate
+	if campaign.Valid {
+		created.Campaign = &campaign.String
 	}
 
 	logger.Infof("MyWellTax adapter successfully created discount code %s", created.Code)
 	return created, nil
 }
 
+// CreateDiscountCodes inserts a batch of discount codes in one transaction.
+// The batch is rejected with a conflict if any code already exists.
+func (a *MyWellTaxAdapter) CreateDiscountCodes(ctx context.Context, db *sql.DB, schemaPrefix string, discountCodes []*types.DiscountCode) ([]*types.DiscountCode, error) {
+	codes := make([]string, 0, len(discountCodes))
+	for _, dc := range discountCodes {
+		dc.Code = strings.ToUpper(dc.Code)
+		codes = append(codes, dc.Code)
+	}
+
+	logger.Infof("MyWellTax adapter creating %d discount codes", len(discountCodes))
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var existing int
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT COUNT(*) FROM %s.discount_codes WHERE UPPER(code) = ANY($1)
+	`, sqlident.Schema(schemaPrefix)), pq.Array(codes)).Scan(&existing)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to check existing discount codes: %v", err)
+		return nil, fmt.Errorf("failed to check existing discount codes: %w", err)
+	}
+	if existing > 0 {
+		return nil, apperr.Conflict("%d of the generated discount codes already exist", existing)
+	}
+
+	stmt, err := tx.PrepareContext(ctx, fmt.Sprintf(`
+		INSERT INTO %s.discount_codes
+		(id, code, description, discount_type, discount_value, max_uses, current_uses,
+		 valid_from, valid_until, is_active, is_affiliate_code, affiliate_id, commission_rate, created_at, campaign)
+		VALUES ($1, $2, $3, $4, $5, $6, 0, $7, $8, $9, $10, $11, $12, $13, $14)
+	`, sqlident.Schema(schemaPrefix)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare discount code insert: %w", err)
+	}
+	defer stmt.Close()
+
+	now := time.Now().UTC().Format("2006-01-02 15:04:05")
+	for _, dc := range discountCodes {
+		if dc.ID == uuid.Nil {
+			dc.ID = a.newID()
+		}
+		dc.CreatedAt = now
+		dc.CurrentUses = 0
+
+		var affiliateID *string
+		if dc.AffiliateID != nil {
+			id := dc.AffiliateID.String()
+			affiliateID = &id
+		}
+
+		_, err := stmt.ExecContext(ctx, dc.ID, dc.Code, dc.Description, dc.DiscountType, dc.DiscountValue, dc.MaxUses,
+			dc.ValidFrom, dc.ValidUntil, dc.IsActive, dc.IsAffiliateCode, affiliateID, dc.CommissionRate, now, dc.Campaign)
+		if err != nil {
+			logger.Errorf("MyWellTax adapter failed to insert discount code %s: %v", dc.Code, err)
+			return nil, fmt.Errorf("failed to create discount codes: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit discount codes: %w", err)
+	}
+
+	logger.Infof("MyWellTax adapter successfully created %d discount codes", len(discountCodes))
+	return discountCodes, nil
+}
+
+// GetDiscountCampaignReports summarizes code usage and filing discounts per campaign
+func (a *MyWellTaxAdapter) GetDiscountCampaignReports(ctx context.Context, db *sql.DB, schemaPrefix string, campaign *string) ([]*types.DiscountCampaignReport, error) {
+	whereClause := "WHERE dc.campaign IS NOT NULL"
+	var args []interface{}
+	if campaign != nil {
+		whereClause += " AND dc.campaign = $1"
+		args = append(args, *campaign)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT dc.campaign,
+		       COUNT(*),
+		       COUNT(*) FILTER (WHERE dc.is_active),
+		       COUNT(*) FILTER (WHERE dc.current_uses > 0),
+		       COALESCE(SUM(dc.current_uses), 0),
+		       COALESCE(SUM(fd.discount_total), 0),
+		       COALESCE(SUM(fd.final_total), 0)
+		FROM %s.discount_codes dc
+		LEFT JOIN (
+			SELECT discount_code_id, SUM(discount_amount) AS discount_total, SUM(final_amount) AS final_total
+			FROM %s.filing_discounts
+			GROUP BY discount_code_id
+		) fd ON fd.discount_code_id = dc.id
+		%s
+		GROUP BY dc.campaign
+		ORDER BY dc.campaign
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix), whereClause)
+
+	logger.Infof("MyWellTax adapter fetching discount campaign reports (campaign=%v)", campaign)
+
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to query discount campaign reports: %v", err)
+		return nil, fmt.Errorf("failed to query discount campaign reports: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []*types.DiscountCampaignReport{}
+	for rows.Next() {
+		report := &types.DiscountCampaignReport{}
+		var discountTotal, revenueTotal string // filing_discounts amounts are in cents
+		err := rows.Scan(
+			&report.Campaign,
+			&report.Codes,
+			&report.ActiveCodes,
+			&report.RedeemedCodes,
+			&report.TotalUses,
+			&discountTotal,
+			&revenueTotal,
+		)
+		if err != nil {
+			logger.Errorf("MyWellTax adapter failed to scan discount campaign report: %v", err)
+			return nil, fmt.Errorf("failed to scan discount campaign report: %w", err)
+		}
+		if report.DiscountTotal, err = types.ParseMinorUnits(discountTotal); err != nil {
+			return nil, err
+		}
+		if report.RevenueTotal, err = types.ParseMinorUnits(revenueTotal); err != nil {
+			return nil, err
+		}
+		if report.Codes > 0 {
+			report.RedemptionRate = float64(report.RedeemedCodes) / float64(report.Codes) * 100
+		}
+		reports = append(reports, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating discount campaign reports: %w", err)
+	}
+
+	return reports, nil
+}
+
 // UpdateDiscountCode updates an existing discount code
-func (a *MyWellTaxAdapter) UpdateDiscountCode(db *sql.DB, schemaPrefix string, codeID string, discountCode *types.DiscountCode) (*types.DiscountCode, error) {
+func (a *MyWellTaxAdapter) UpdateDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string, discountCode *types.DiscountCode) (*types.DiscountCode, error) {
 	now := time.Now().UTC().Format("2006-01-02 15:04:05")
 	updatedAt := now
 
@@ -413,14 +644,16 @@ func (a *MyWellTaxAdapter) UpdateDiscountCode(db *sql.DB, schemaPrefix string, c
 		    commission_rate = $9, updated_at = $10
 		WHERE id = $11
 		RETURNING id, code, description, discount_type, discount_value, max_uses, current_uses,
-		          valid_from, valid_until, is_active, is_affiliate_code, affiliate_id, commission_rate, created_at, updated_at
-	`, schemaPrefix)
+		          valid_from, valid_until, is_active, is_affiliate_code, affiliate_id, commission_rate, created_at, updated_at,
+		          campaign
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("MyWellTax adapter updating discount code %s", codeID)
 
 	var description, validFrom, validUntil sql.NullString
 	var maxUses sql.NullInt32
-	var commissionRate sql.NullFloat64
+	commissionRate := discountCode.CommissionRate
+	var campaign sql.NullString
 
 	// Prepare nullable values
 	if discountCode.Description != nil {
@@ -439,12 +672,8 @@ func (a *MyWellTaxAdapter) UpdateDiscountCode(db *sql.DB, schemaPrefix string, c
 		validUntil.String = *discountCode.ValidUntil
 		validUntil.Valid = true
 	}
-	if discountCode.CommissionRate != nil {
-		commissionRate.Float64 = *discountCode.CommissionRate
-		commissionRate.Valid = true
-	}
 
-	row := db.QueryRow(query,
+	row := db.QueryRowContext(ctx, query,
 		discountCode.Code,
 		description,
 		discountCode.DiscountType,
@@ -478,11 +707,12 @@ func (a *MyWellTaxAdapter) UpdateDiscountCode(db *sql.DB, schemaPrefix string, c
 		&commissionRate,
 		&updated.CreatedAt,
 		&updatedAtScan,
+		&campaign,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			logger.Warningf("MyWellTax adapter discount code %s not found for update", codeID)
-			return nil, fmt.Errorf("discount code not found")
+			return nil, apperr.NotFound("discount code not found")
 		}
 		logger.Errorf("MyWellTax adapter failed to update discount code: %v", err)
 		return nil, fmt.Errorf("failed to update discount code: %w", err)
@@ -508,30 +738,91 @@ func (a *MyWellTaxAdapter) UpdateDiscountCode(db *sql.DB, schemaPrefix string, c
 			updated.AffiliateID = &aID
 		}
 	}
-	if commissionRate.Valid {
-		updated.CommissionRate = &commissionRate.Float64
-	}
+	updated.CommissionRate = commissionRate
 	if updatedAtScan.Valid {
 		updated.UpdatedAt = &updatedAtScan.String
 	}
+	if campaign.Valid {
+		updated.Campaign = &campaign.String
+	}
 
 	logger.Infof("MyWellTax adapter successfully updated discount code %s", updated.Code)
 	return updated, nil
 }
 
+// PatchDiscountCode sets only the discount code columns the patch provides
+func (a *MyWellTaxAdapter) PatchDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string, patch *types.DiscountCodePatch) (*types.DiscountCode, error) {
+	var sets []string
+	var args []interface{}
+	set := func(column string, value interface{}) {
+		args = append(args, value)
+		sets = append(sets, fmt.Sprintf("%s = $%d", column, len(args)))
+	}
+
+	if patch.Code != nil {
+		set("code", strings.ToUpper(*patch.Code))
+	}
+	if patch.Description != nil {
+		set("description", sql.NullString{String: *patch.Description, Valid: *patch.Description != ""})
+	}
+	if patch.DiscountType != nil {
+		set("discount_type", *patch.DiscountType)
+	}
+	if patch.DiscountValue != nil {
+		set("discount_value", *patch.DiscountValue)
+	}
+	if patch.MaxUses != nil {
+		set("max_uses", *patch.MaxUses)
+	}
+	if patch.ValidFrom != nil {
+		set("valid_from", sql.NullString{String: *patch.ValidFrom, Valid: *patch.ValidFrom != ""})
+	}
+	if patch.ValidUntil != nil {
+		set("valid_until", sql.NullString{String: *patch.ValidUntil, Valid: *patch.ValidUntil != ""})
+	}
+	if patch.IsActive != nil {
+		set("is_active", *patch.IsActive)
+	}
+	if patch.CommissionRate != nil {
+		set("commission_rate", *patch.CommissionRate)
+	}
+	set("updated_at", time.Now().UTC().Format("2006-01-02 15:04:05"))
+	args = append(args, codeID)
+
+	query := fmt.Sprintf(`
+		UPDATE %s.discount_codes
+		SET %s
+		WHERE id = $%d
+		RETURNING %s
+	`, sqlident.Schema(schemaPrefix), strings.Join(sets, ", "), len(args), myWellTaxDiscountCodeColumns)
+
+	logger.Infof("MyWellTax adapter patching %d fields of discount code %s", len(sets)-1, codeID)
+
+	codes, err := queryMyWellTaxDiscountCodes(ctx, db, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	if len(codes) == 0 {
+		logger.Warningf("MyWellTax adapter discount code %s not found for update", codeID)
+		return nil, apperr.NotFound("discount code not found")
+	}
+
+	return codes[0], nil
+}
+
 // DeactivateDiscountCode deactivates a discount code
-func (a *MyWellTaxAdapter) DeactivateDiscountCode(db *sql.DB, schemaPrefix string, codeID string) error {
+func (a *MyWellTaxAdapter) DeactivateDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string) error {
 	now := time.Now().UTC().Format("2006-01-02 15:04:05")
 
 	query := fmt.Sprintf(`
 		UPDATE %s.discount_codes
 		SET is_active = false, updated_at = $1
 		WHERE id = $2
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("MyWellTax adapter deactivating discount code %s", codeID)
 
-	result, err := db.Exec(query, now, codeID)
+	result, err := db.ExecContext(ctx, query, now, codeID)
 	if err != nil {
 		logger.Errorf("MyWellTax adapter failed to deactivate discount code: %v", err)
 		return fmt.Errorf("failed to deactivate discount code: %w", err)
@@ -544,7 +835,7 @@ func (a *MyWellTaxAdapter) DeactivateDiscountCode(db *sql.DB, schemaPrefix strin
 
 	if rowsAffected == 0 {
 		logger.Warningf("MyWellTax adapter discount code %s not found for deactivation", codeID)
-		return fmt.Errorf("discount code not found")
+		return apperr.NotFound("discount code not found")
 	}
 
 	logger.Infof("MyWellTax adapter successfully deactivated discount code %s", codeID)
@@ -1,9 +1,12 @@
 package adapter
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/sqlident"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
@@ -11,18 +14,18 @@ import (
 )
 
 // CreateDocument creates a new document record in the tenant's database
-func (a *MyWellTaxAdapter) CreateDocument(db *sql.DB, schemaPrefix string, document *types.Document) (*types.Document, error) {
+func (a *MyWellTaxAdapter) CreateDocument(ctx context.Context, db *sql.DB, schemaPrefix string, document *types.Document) (*types.Document, error) {
 	query := fmt.Sprintf(`
 		INSERT INTO %s.document (id, user_id, name, file_path, type, filing_id, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 		RETURNING id, user_id, name, file_path, type, filing_id, created_at, updated_at
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("Creating document in %s.document", schemaPrefix)
 
 	// Generate ID if not provided
 	if document.ID == uuid.Nil {
-		document.ID = uuid.New()
+		document.ID = a.newID()
 	}
 
 	now := time.Now().UTC().Format("2006-01-02 15:04:05")
@@ -32,7 +35,8 @@ func (a *MyWellTaxAdapter) CreateDocument(db *sql.DB, schemaPrefix string, docum
 	var createdAt, updatedAt string
 	var updatedAtPtr *string
 
-	err := db.QueryRow(
+	err := db.QueryRowContext(
+		ctx,
 		query,
 		document.ID,
 		document.UserID,
@@ -70,12 +74,12 @@ func (a *MyWellTaxAdapter) CreateDocument(db *sql.DB, schemaPrefix string, docum
 }
 
 // GetDocumentByID retrieves a specific document by ID
-func (a *MyWellTaxAdapter) GetDocumentByID(db *sql.DB, schemaPrefix string, documentID string) (*types.Document, error) {
+func (a *MyWellTaxAdapter) GetDocumentByID(ctx context.Context, db *sql.DB, schemaPrefix string, documentID string) (*types.Document, error) {
 	query := fmt.Sprintf(`
 		SELECT id, user_id, name, file_path, type, filing_id, created_at, updated_at
 		FROM %s.document
 		WHERE id = $1
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("Fetching document %s from %s.document", documentID, schemaPrefix)
 
@@ -83,7 +87,7 @@ func (a *MyWellTaxAdapter) GetDocumentByID(db *sql.DB, schemaPrefix string, docu
 	var filingID *uuid.UUID
 	var updatedAtPtr *string
 
-	err := db.QueryRow(query, documentID).Scan(
+	err := db.QueryRowContext(ctx, query, documentID).Scan(
 		&document.ID,
 		&document.UserID,
 		&document.Name,
@@ -97,7 +101,7 @@ func (a *MyWellTaxAdapter) GetDocumentByID(db *sql.DB, schemaPrefix string, docu
 	if err != nil {
 		if err == sql.ErrNoRows {
 			logger.Errorf("Document not found: %s", documentID)
-			return nil, fmt.Errorf("document not found")
+			return nil, apperr.NotFound("document not found")
 		}
 		logger.Errorf("Failed to fetch document: %v", err)
 		return nil, fmt.Errorf("failed to fetch document: %w", err)
@@ -112,17 +116,17 @@ func (a *MyWellTaxAdapter) GetDocumentByID(db *sql.DB, schemaPrefix string, docu
 }
 
 // GetDocumentsByFilingID retrieves all documents associated with a filing
-func (a *MyWellTaxAdapter) GetDocumentsByFilingID(db *sql.DB, schemaPrefix string, filingID string) ([]*types.Document, error) {
+func (a *MyWellTaxAdapter) GetDocumentsByFilingID(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) ([]*types.Document, error) {
 	query := fmt.Sprintf(`
 		SELECT id, user_id, name, file_path, type, filing_id, created_at, updated_at
 		FROM %s.document
 		WHERE filing_id = $1
 		ORDER BY created_at DESC
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("Fetching documents for filing %s from %s.document", filingID, schemaPrefix)
 
-	rows, err := db.Query(query, filingID)
+	rows, err := db.QueryContext(ctx, query, filingID)
 	if err != nil {
 		logger.Errorf("Failed to query documents: %v", err)
 		return nil, fmt.Errorf("failed to query documents: %w", err)
@@ -166,16 +170,59 @@ func (a *MyWellTaxAdapter) GetDocumentsByFilingID(db *sql.DB, schemaPrefix strin
 	return documents, nil
 }
 
+// GetDocumentsByYear retrieves the documents of every filing of a tax year
+func (a *MyWellTaxAdapter) GetDocumentsByYear(ctx context.Context, db *sql.DB, schemaPrefix string, year int) ([]*types.Document, error) {
+	query := fmt.Sprintf(`
+		SELECT d.id, d.user_id, d.name, d.file_path, d.type, d.filing_id, d.created_at, d.updated_at
+		FROM %s.document d
+		JOIN %s.filing f ON f.id = d.filing_id
+		WHERE f.year = $1
+		ORDER BY d.created_at
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+
+	rows, err := db.QueryContext(ctx, query, year)
+	if err != nil {
+		logger.Errorf("Failed to query documents of %d: %v", year, err)
+		return nil, fmt.Errorf("failed to query documents: %w", err)
+	}
+	defer rows.Close()
+
+	documents := make([]*types.Document, 0)
+	for rows.Next() {
+		var document types.Document
+		if err := rows.Scan(
+			&document.ID,
+			&document.UserID,
+			&document.Name,
+			&document.FilePath,
+			&document.Type,
+			&document.FilingID,
+			&document.CreatedAt,
+			&document.UpdatedAt,
+		); err != nil {
+			logger.Errorf("Failed to scan document: %v", err)
+			return nil, fmt.Errorf("failed to scan document: %w", err)
+		}
+		documents = append(documents, &document)
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Errorf("Error iterating documents: %v", err)
+		return nil, fmt.Errorf("error iterating documents: %w", err)
+	}
+	return documents, nil
+}
+
 // DeleteDocument removes a document record from the tenant's database
-func (a *MyWellTaxAdapter) DeleteDocument(db *sql.DB, schemaPrefix string, documentID string) error {
+func (a *MyWellTaxAdapter) DeleteDocument(ctx context.Context, db *sql.DB, schemaPrefix string, documentID string) error {
 	query := fmt.Sprintf(`
 		DELETE FROM %s.document
 		WHERE id = $1
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("Deleting document %s from %s.document", documentID, schemaPrefix)
 
-	result, err := db.Exec(query, documentID)
+	result, err := db.ExecContext(ctx, query, documentID)
 	if err != nil {
 		logger.Errorf("Failed to delete document: %v", err)
 		return fmt.Errorf("failed to delete document: %w", err)
@@ -189,9 +236,77 @@ func (a *MyWellTaxAdapter) DeleteDocument(db *sql.DB, schemaPrefix string, docum
 
 	if rowsAffected == 0 {
 		logger.Errorf("Document not found: %s", documentID)
-		return fmt.Errorf("document not found")
+		return apperr.NotFound("document not found")
 	}
 
 	logger.Infof("Successfully deleted document: %s", documentID)
 	return nil
 }
+
+// StreamDocuments calls fn with each document of the tenant, oldest first
+func (a *MyWellTaxAdapter) StreamDocuments(ctx context.Context, db *sql.DB, schemaPrefix string, fn func(*types.Document) error) error {
+	query := fmt.Sprintf(`
+		SELECT id, user_id, name, file_path, type, filing_id, created_at, updated_at
+		FROM %s.document
+		ORDER BY created_at, id
+	`, sqlident.Schema(schemaPrefix))
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		logger.Errorf("Failed to query documents for streaming: %v", err)
+		return fmt.Errorf("failed to query documents: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var document types.Document
+		if err := rows.Scan(
+			&document.ID,
+			&document.UserID,
+			&document.Name,
+			&document.FilePath,
+			&document.Type,
+			&document.FilingID,
+			&document.CreatedAt,
+			&document.UpdatedAt,
+		); err != nil {
+			logger.Errorf("Failed to scan document: %v", err)
+			return fmt.Errorf("failed to scan document: %w", err)
+		}
+		if err := fn(&document); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		logger.Errorf("Error streaming documents: %v", err)
+		return fmt.Errorf("error iterating documents: %w", err)
+	}
+	return nil
+}
+
+// SetDocumentPath points a document at another file in the tenant's bucket
+func (a *MyWellTaxAdapter) SetDocumentPath(ctx context.Context, db *sql.DB, schemaPrefix string, documentID string, filePath string) error {
+	query := fmt.Sprintf(`
+		UPDATE %s.document
+		SET file_path = $1, updated_at = NOW()
+		WHERE id = $2
+	`, sqlident.Schema(schemaPrefix))
+
+	result, err := db.ExecContext(ctx, query, filePath, documentID)
+	if err != nil {
+		logger.Errorf("Failed to set path of document %s: %v", documentID, err)
+		return fmt.Errorf("failed to update document: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return apperr.NotFound("document not found")
+	}
+
+	logger.Infof("Pointed document %s at %s", documentID, filePath)
+	return nil
+}
@@ -0,0 +1,217 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"welltaxpro/src/internal/sqlident"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/lib/pq"
+)
+
+// clientExportTable selects a client's rows from one tenant table
+type clientExportTable struct {
+	name  string
+	where string            // Row filter; $1 is the client ID and %[1]s the schema prefix
+	omit  []string          // Columns left out of the export (NULL on import)
+	pii   map[string]string // Column -> types.PII* kind
+}
+
+// Subqueries shared by the row filters
+const (
+	clientFilings    = "SELECT id FROM %[1]s.filing WHERE user_id = $1"
+	clientProperties = "SELECT id FROM %[1]s.property WHERE user_id = $1"
+)
+
+// myWellTaxClientExport lists the tables holding a client's data, parents before children
+// so the rows can be inserted back in order. Affiliate and commission data belongs to the
+// tenant's partners rather than the client and is not exported.
+var myWellTaxClientExport = []clientExportTable{
+	{name: "user", where: "id = $1", pii: map[string]string{
+		"first_name": types.PIIFirstName, "middle_name": types.PIIMiddleName, "last_name": types.PIILastName,
+		"email": types.PIIEmail, "phone": types.PIIPhone, "dob": types.PIIBirthDate, "ssn": types.PIISSN,
+		"address1": types.PIIStreet, "address2": types.PIIStreet, "city": types.PIICity,
+		"zipcode": types.PIIZipcode, "archive_reason": types.PIIText,
+	}},
+	{name: "spouse", where: "user_id = $1", pii: map[string]string{
+		"first_name": types.PIIFirstName, "middle_name": types.PIIMiddleName, "last_name": types.PIILastName,
+		"email": types.PIIEmail, "phone": types.PIIPhone, "dob": types.PIIBirthDate, "ssn": types.PIISSN,
+	}},
+	{name: "dependent", where: "user_id = $1", pii: map[string]string{
+		"first_name": types.PIIFirstName, "middle_name": types.PIIMiddleName, "last_name": types.PIILastName,
+		"dob": types.PIIBirthDate, "ssn": types.PIISSN,
+	}},
+	{name: "dependent_document_map", where: "dependent_id IN (SELECT id FROM %[1]s.dependent WHERE user_id = $1)", pii: map[string]string{
+		"record_name": types.PIIFileName,
+	}},
+	{name: "property", where: "user_id = $1", pii: map[string]string{
+		"address1": types.PIIStreet, "address2": types.PIIStreet, "city": types.PIICity, "zipcode": types.PIIZipcode,
+	}},
+	{name: "expense", where: "property_id IN (" + clientProperties + ")"},
+	{name: "childcare", where: "user_id = $1", pii: map[string]string{
+		"name": types.PIIName, "tax_id": types.PIITaxID,
+		"address1": types.PIIStreet, "address2": types.PIIStreet, "city": types.PIICity, "zipcode": types.PIIZipcode,
+	}},
+	{name: "filing", where: "user_id = $1"},
+	{name: "filing_status", where: "filing_id IN (" + clientFilings + ")"},
+	{name: "filing_property_map", where: "filing_id IN (" + clientFilings + ")"},
+	{name: "filing_childcare_map", where: "filing_id IN (" + clientFilings + ")"},
+	{name: "ira_contribution", where: "filing_id IN (" + clientFilings + ")"},
+	{name: "charity", where: "user_id = $1"},
+	{name: "document", where: "user_id = $1", pii: map[string]string{
+		"name": types.PIIFileName, "file_path": types.PIIFilePath,
+	}},
+	{name: "payment", where: "filing_id IN (" + clientFilings + ")", pii: map[string]string{
+		"stripe_session_id": types.PIIReference, "discount_code": types.PIIReference,
+	}},
+	{name: "payment_item", where: "payment_id IN (SELECT id FROM %[1]s.payment WHERE filing_id IN (" + clientFilings + "))"},
+	{name: "discount_codes", where: "id IN (SELECT discount_code_id FROM %[1]s.filing_discounts WHERE filing_id IN (" + clientFilings + "))",
+		omit: []string{"affiliate_id"}, pii: map[string]string{
+			"code": types.PIIReference, "description": types.PIIText,
+		}},
+	{name: "filing_discounts", where: "filing_id IN (" + clientFilings + ")"},
+	{name: "state_filing", where: "filing_id IN (" + clientFilings + ")"},
+	{name: "filing_result", where: "filing_id IN (" + clientFilings + ")", pii: map[string]string{
+		"recorded_by": types.PIIReference,
+	}},
+	{name: "refund_tracking", where: "filing_id IN (" + clientFilings + ")", pii: map[string]string{
+		"note": types.PIIText, "updated_by": types.PIIReference,
+	}},
+}
+
+// ExportClientRows reads every row belonging to a client, as text, in insert order.
+// Values are not anonymized here; each table lists the PII kinds of its columns for the anonymizer.
+func (a *MyWellTaxAdapter) ExportClientRows(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) ([]*types.ExportTable, error) {
+	columnsByTable := map[string][]types.SchemaColumn{}
+	for _, t := range myWellTaxSchema {
+		columnsByTable[t.Name] = t.Columns
+	}
+
+	logger.Infof("MyWellTax adapter exporting rows of client %s", clientID)
+
+	tables := make([]*types.ExportTable, 0, len(myWellTaxClientExport))
+	for _, spec := range myWellTaxClientExport {
+		table := &types.ExportTable{Name: spec.name, PII: spec.pii, Rows: [][]*string{}}
+		var selects []string
+		for _, c := range columnsByTable[spec.name] {
+			if containsString(spec.omit, c.Name) {
+				continue
+			}
+			table.Columns = append(table.Columns, c.Name)
+			selects = append(selects, pq.QuoteIdentifier(c.Name)+"::text")
+		}
+
+		query := fmt.Sprintf("SELECT %s FROM %s.%s WHERE %s",
+			strings.Join(selects, ", "), sqlident.Schema(schemaPrefix), pq.QuoteIdentifier(spec.name), fmt.Sprintf(spec.where, sqlident.Schema(schemaPrefix)))
+		rows, err := db.QueryContext(ctx, query, clientID)
+		if err != nil {
+			logger.Errorf("MyWellTax adapter failed to export %s rows of client %s: %v", spec.name, clientID, err)
+			return nil, fmt.Errorf("failed to export %s: %w", spec.name, err)
+		}
+
+		for rows.Next() {
+			values := make([]sql.NullString, len(table.Columns))
+			dest := make([]interface{}, len(values))
+			for i := range values {
+				dest[i] = &values[i]
+			}
+			if err := rows.Scan(dest...); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("failed to scan %s row: %w", spec.name, err)
+			}
+
+			row := make([]*string, len(values))
+			for i, v := range values {
+				if v.Valid {
+					s := v.String
+					row[i] = &s
+				}
+			}
+			table.Rows = append(table.Rows, row)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, fmt.Errorf("error iterating %s rows: %w", spec.name, err)
+		}
+
+		tables = append(tables, table)
+	}
+
+	return tables, nil
+}
+
+// ImportClientRows inserts exported rows in one transaction and returns how many were inserted.
+// Rows that already exist are skipped, so an export can be imported again after changes.
+// SSN columns are passed through reencrypt, so they land under the tenant's data key.
+func (a *MyWellTaxAdapter) ImportClientRows(ctx context.Context, db *sql.DB, schemaPrefix string, tables []*types.ExportTable, reencrypt ReencryptSSN) (int, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin import: %w", err)
+	}
+	defer tx.Rollback()
+
+	inserted := 0
+	for _, table := range tables {
+		if len(table.Rows) == 0 {
+			continue
+		}
+
+		columns := make([]string, len(table.Columns))
+		placeholders := make([]string, len(table.Columns))
+		for i, c := range table.Columns {
+			columns[i] = pq.QuoteIdentifier(c)
+			placeholders[i] = fmt.Sprintf("$%d", i+1)
+		}
+		query := fmt.Sprintf("INSERT INTO %s.%s (%s) VALUES (%s) ON CONFLICT DO NOTHING",
+			sqlident.Schema(schemaPrefix), pq.QuoteIdentifier(table.Name), strings.Join(columns, ", "), strings.Join(placeholders, ", "))
+
+		for _, row := range table.Rows {
+			if len(row) != len(table.Columns) {
+				return 0, fmt.Errorf("%s row has %d values for %d columns", table.Name, len(row), len(table.Columns))
+			}
+
+			args := make([]interface{}, len(row))
+			for i, v := range row {
+				if v == nil {
+					continue
+				}
+				value := *v
+				if table.PII[table.Columns[i]] == types.PIISSN {
+					if value, _, err = reencrypt(value); err != nil {
+						return 0, fmt.Errorf("failed to encrypt %s.%s: %w", table.Name, table.Columns[i], err)
+					}
+				}
+				args[i] = value
+			}
+
+			result, err := tx.ExecContext(ctx, query, args...)
+			if err != nil {
+				logger.Errorf("MyWellTax adapter failed to import %s row: %v", table.Name, err)
+				return 0, fmt.Errorf("failed to import %s: %w", table.Name, err)
+			}
+			if n, err := result.RowsAffected(); err == nil {
+				inserted += int(n)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("failed to commit import: %w", err)
+	}
+
+	logger.Infof("MyWellTax adapter imported %d rows", inserted)
+	return inserted, nil
+}
+
+// containsString reports whether values contains s
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/sqlident"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// GetFilingResult retrieves the recorded outcome of a filing
+func (a *MyWellTaxAdapter) GetFilingResult(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) (*types.FilingResult, error) {
+	r, err := a.getFilingResult(ctx, db, schemaPrefix, filingID)
+	if err != nil {
+		return nil, err
+	}
+	if r == nil {
+		return nil, apperr.NotFound("filing result not found")
+	}
+	return r, nil
+}
+
+// getFilingResult returns nil without an error when no result has been recorded
+func (a *MyWellTaxAdapter) getFilingResult(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) (*types.FilingResult, error) {
+	query := fmt.Sprintf(`
+		SELECT filing_id, agi, total_tax, federal_withholding, federal_refund, federal_owed,
+		       state_tax, state_refund, state_owed, recorded_by, created_at, updated_at
+		FROM %s.filing_result
+		WHERE filing_id = $1
+	`, sqlident.Schema(schemaPrefix))
+
+	r := &types.FilingResult{}
+	err := db.QueryRowContext(ctx, query, filingID).Scan(
+		&r.FilingID, &r.AGI, &r.TotalTax, &r.FederalWithholding, &r.FederalRefund, &r.FederalOwed,
+		&r.StateTax, &r.StateRefund, &r.StateOwed, &r.RecordedBy, &r.CreatedAt, &r.UpdatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to get filing result for %s: %v", filingID, err)
+		return nil, fmt.Errorf("failed to get filing result: %w", err)
+	}
+
+	return r, nil
+}
+
+// UpsertFilingResult records or replaces the outcome of a filing
+func (a *MyWellTaxAdapter) UpsertFilingResult(ctx context.Context, db *sql.DB, schemaPrefix string, result *types.FilingResult) (*types.FilingResult, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.filing_result (filing_id, agi, total_tax, federal_withholding, federal_refund, federal_owed,
+		                              state_tax, state_refund, state_owed, recorded_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		ON CONFLICT (filing_id) DO UPDATE
+		SET agi = EXCLUDED.agi,
+		    total_tax = EXCLUDED.total_tax,
+		    federal_withholding = EXCLUDED.federal_withholding,
+		    federal_refund = EXCLUDED.federal_refund,
+		    federal_owed = EXCLUDED.federal_owed,
+		    state_tax = EXCLUDED.state_tax,
+		    state_refund = EXCLUDED.state_refund,
+		    state_owed = EXCLUDED.state_owed,
+		    recorded_by = EXCLUDED.recorded_by,
+		    updated_at = NOW()
+		RETURNING created_at, updated_at
+	`, sqlident.Schema(schemaPrefix))
+
+	logger.Infof("MyWellTax adapter recording result for filing %s", result.FilingID)
+
+	err := db.QueryRowContext(
+		ctx,
+		query,
+		result.FilingID,
+		result.AGI,
+		result.TotalTax,
+		result.FederalWithholding,
+		result.FederalRefund,
+		result.FederalOwed,
+		result.StateTax,
+		result.StateRefund,
+		result.StateOwed,
+		result.RecordedBy,
+	).Scan(&result.CreatedAt, &result.UpdatedAt)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to record filing result: %v", err)
+		return nil, fmt.Errorf("failed to record filing result: %w", err)
+	}
+
+	return result, nil
+}
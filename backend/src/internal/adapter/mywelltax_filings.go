@@ -1,27 +1,34 @@
 package adapter
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/sqlident"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
+	"github.com/google/uuid"
 )
 
 // GetClientsByFilings retrieves all clients with their filings (with pagination)
-func (a *MyWellTaxAdapter) GetClientsByFilings(db *sql.DB, schemaPrefix string, limit int, offset int) ([]*types.ClientComprehensive, error) {
+// Archived clients are excluded
+func (a *MyWellTaxAdapter) GetClientsByFilings(ctx context.Context, db *sql.DB, schemaPrefix string, limit int, offset int) ([]*types.ClientComprehensive, error) {
 	// Build query to find distinct client IDs with filings (ordered by most recent filing)
 	query := fmt.Sprintf(`
 		SELECT DISTINCT ON (f.user_id) f.user_id
 		FROM %s.filing f
+		JOIN %s.user u ON u.id = f.user_id
+		WHERE u.archived_at IS NULL
 		ORDER BY f.user_id, f.created_at DESC
 		LIMIT $1 OFFSET $2
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
 
 	logger.Infof("Querying filings with pagination - limit: %d, offset: %d", limit, offset)
 
 	// Query for client IDs
-	rows, err := db.Query(query, limit, offset)
+	rows, err := db.QueryContext(ctx, query, limit, offset)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query client IDs: %w", err)
 	}
@@ -42,17 +49,222 @@ func (a *MyWellTaxAdapter) GetClientsByFilings(db *sql.DB, schemaPrefix string,
 
 	logger.Infof("Found %d clients with filings", len(clientIDs))
 
-	// For each client, get comprehensive data (includes all their filings)
+	result := a.getComprehensiveClients(ctx, db, schemaPrefix, clientIDs)
+
+	logger.Infof("Returning %d clients with all their filings", len(result))
+	return result, nil
+}
+
+// GetClientsByFilingsPage retrieves one page of clients with filings, most recent filing first
+// The cursor is the client's most recent filing time and ID; archived clients are excluded
+func (a *MyWellTaxAdapter) GetClientsByFilingsPage(ctx context.Context, db *sql.DB, schemaPrefix string, page types.PageRequest) (*types.FilingPage, error) {
+	result := &types.FilingPage{Items: []*types.ClientComprehensive{}}
+	countQuery := fmt.Sprintf(`
+		SELECT COUNT(DISTINCT f.user_id)
+		FROM %s.filing f
+		JOIN %s.user u ON u.id = f.user_id
+		WHERE u.archived_at IS NULL
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+	if err := db.QueryRowContext(ctx, countQuery).Scan(&result.TotalCount); err != nil {
+		logger.Errorf("MyWellTax adapter failed to count clients with filings: %v", err)
+		return nil, fmt.Errorf("failed to count clients with filings: %w", err)
+	}
+
+	having := ""
+	keyset, args := keysetCondition(page, "MAX(f.created_at)", "f.user_id", nil)
+	if keyset != "" {
+		having = "HAVING " + keyset
+	}
+	query := fmt.Sprintf(`
+		SELECT f.user_id, MAX(f.created_at)
+		FROM %s.filing f
+		JOIN %s.user u ON u.id = f.user_id
+		WHERE u.archived_at IS NULL
+		GROUP BY f.user_id
+		%s
+		ORDER BY MAX(f.created_at) DESC, f.user_id DESC
+		LIMIT $%d
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix), having, len(args)+1)
+	args = append(args, page.Size()+1)
+
+	clientIDs, lastFiled, err := queryClientsByLastFiling(ctx, db, query, args...)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to page clients with filings: %v", err)
+		return nil, err
+	}
+	if len(clientIDs) > page.Size() {
+		clientIDs = clientIDs[:page.Size()]
+		last := len(clientIDs) - 1
+		result.NextCursor = nextPageCursor(lastFiled[last], clientIDs[last])
+	}
+
+	ids := make([]string, len(clientIDs))
+	for i, clientID := range clientIDs {
+		ids[i] = clientID.String()
+	}
+	result.Items = a.getComprehensiveClients(ctx, db, schemaPrefix, ids)
+
+	logger.Infof("MyWellTax adapter returning a page of %d of %d clients with filings", len(result.Items), result.TotalCount)
+	return result, nil
+}
+
+// StreamClientsByFilings calls fn with each client with filings, most recent filing first
+// Client IDs are read up front so the tenant connection is free for the per-client queries
+func (a *MyWellTaxAdapter) StreamClientsByFilings(ctx context.Context, db *sql.DB, schemaPrefix string, fn func(*types.ClientComprehensive) error) error {
+	query := fmt.Sprintf(`
+		SELECT f.user_id
+		FROM %s.filing f
+		JOIN %s.user u ON u.id = f.user_id
+		WHERE u.archived_at IS NULL
+		GROUP BY f.user_id
+		ORDER BY MAX(f.created_at) DESC, f.user_id DESC
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+
+	clientIDs, err := queryClientIDs(ctx, db, query)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to list clients with filings for streaming: %v", err)
+		return err
+	}
+
+	logger.Infof("MyWellTax adapter streaming %d clients with filings", len(clientIDs))
+	return streamComprehensiveClients(ctx, clientIDs, func(clientID string) (*types.ClientComprehensive, error) {
+		return a.GetClientComprehensive(ctx, db, schemaPrefix, clientID)
+	}, fn)
+}
+
+// getComprehensiveClients loads comprehensive data (including all filings) for each client,
+// skipping clients that fail to load
+func (a *MyWellTaxAdapter) getComprehensiveClients(ctx context.Context, db *sql.DB, schemaPrefix string, clientIDs []string) []*types.ClientComprehensive {
 	result := make([]*types.ClientComprehensive, 0, len(clientIDs))
 	for _, clientID := range clientIDs {
-		comprehensive, err := a.GetClientComprehensive(db, schemaPrefix, clientID)
+		comprehensive, err := a.GetClientComprehensive(ctx, db, schemaPrefix, clientID)
 		if err != nil {
 			logger.Warningf("Failed to get comprehensive data for client %s: %v", clientID, err)
 			continue
 		}
 		result = append(result, comprehensive)
 	}
+	return result
+}
 
-	logger.Infof("Returning %d clients with all their filings", len(result))
-	return result, nil
+// queryClientsByLastFiling runs a query selecting client IDs with their most recent filing time
+func queryClientsByLastFiling(ctx context.Context, db *sql.DB, query string, args ...interface{}) ([]uuid.UUID, []string, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to query client IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var clientIDs []uuid.UUID
+	var lastFiled []string
+	for rows.Next() {
+		var clientID uuid.UUID
+		var filedAt string
+		if err := rows.Scan(&clientID, &filedAt); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan client ID: %w", err)
+		}
+		clientIDs = append(clientIDs, clientID)
+		lastFiled = append(lastFiled, filedAt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("error iterating client IDs: %w", err)
+	}
+	return clientIDs, lastFiled, nil
+}
+
+// CountFilings counts the filings for a tax year and how many of them are completed
+func (a *MyWellTaxAdapter) CountFilings(ctx context.Context, db *sql.DB, schemaPrefix string, year int) (int, int, error) {
+	query := fmt.Sprintf(`
+		SELECT COUNT(DISTINCT f.id), COUNT(DISTINCT f.id) FILTER (WHERE fs.is_completed)
+		FROM %s.filing f
+		LEFT JOIN %s.filing_status fs ON fs.filing_id = f.id
+		WHERE f.year = $1
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+
+	var total, completed int
+	if err := db.QueryRowContext(ctx, query, year).Scan(&total, &completed); err != nil {
+		logger.Errorf("MyWellTax adapter failed to count filings for %d: %v", year, err)
+		return 0, 0, fmt.Errorf("failed to count filings: %w", err)
+	}
+
+	return total, completed, nil
+}
+
+// CountOpenFilings counts the filings of every tax year that have no completed status
+func (a *MyWellTaxAdapter) CountOpenFilings(ctx context.Context, db *sql.DB, schemaPrefix string) (int, error) {
+	query := fmt.Sprintf(`
+		SELECT COUNT(*)
+		FROM %s.filing f
+		WHERE NOT EXISTS (
+			SELECT 1 FROM %s.filing_status fs WHERE fs.filing_id = f.id AND fs.is_completed
+		)
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+
+	var open int
+	if err := db.QueryRowContext(ctx, query).Scan(&open); err != nil {
+		logger.Errorf("MyWellTax adapter failed to count open filings: %v", err)
+		return 0, fmt.Errorf("failed to count open filings: %w", err)
+	}
+	return open, nil
+}
+
+// GetFilingYear returns the tax year of a filing
+func (a *MyWellTaxAdapter) GetFilingYear(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) (int, error) {
+	query := fmt.Sprintf(`SELECT year FROM %s.filing WHERE id = $1`, sqlident.Schema(schemaPrefix))
+
+	var year int
+	err := db.QueryRowContext(ctx, query, filingID).Scan(&year)
+	if err == sql.ErrNoRows {
+		return 0, apperr.NotFound("filing not found: %s", filingID)
+	}
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to get year of filing %s: %v", filingID, err)
+		return 0, fmt.Errorf("failed to get filing year: %w", err)
+	}
+	return year, nil
+}
+
+// GetFilingWorkflow returns the client, tax year and filing_status of a filing
+func (a *MyWellTaxAdapter) GetFilingWorkflow(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) (*types.FilingWorkflow, error) {
+	query := fmt.Sprintf(`
+		SELECT f.id, f.user_id, f.year, fs.id IS NOT NULL, COALESCE(fs.status, ''), COALESCE(fs.is_completed, false)
+		FROM %s.filing f
+		LEFT JOIN %s.filing_status fs ON fs.filing_id = f.id
+		WHERE f.id = $1
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+
+	workflow := &types.FilingWorkflow{}
+	var hasStatus bool
+	err := db.QueryRowContext(ctx, query, filingID).Scan(&workflow.FilingID, &workflow.ClientID, &workflow.Year, &hasStatus,
+		&workflow.Status, &workflow.IsCompleted)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("filing not found: %s", filingID)
+	}
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to get status of filing %s: %v", filingID, err)
+		return nil, fmt.Errorf("failed to get filing status: %w", err)
+	}
+	if !hasStatus {
+		return nil, apperr.NotFound("filing status not found: %s", filingID)
+	}
+	return workflow, nil
+}
+
+// SetFilingStatus moves a filing's filing_status from one status to another
+func (a *MyWellTaxAdapter) SetFilingStatus(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string, from string, to string) error {
+	query := fmt.Sprintf(`
+		UPDATE %s.filing_status
+		SET status = $2, is_completed = $3
+		WHERE filing_id = $1 AND COALESCE(status, '') = $4
+	`, sqlident.Schema(schemaPrefix))
+
+	result, err := db.ExecContext(ctx, query, filingID, to, to == types.FilingStatusAccepted, from)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to set status of filing %s: %v", filingID, err)
+		return fmt.Errorf("failed to set filing status: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return apperr.Conflict("filing %s is no longer %s", filingID, from)
+	}
+	return nil
 }
@@ -0,0 +1,180 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/sqlident"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// CreateCommission inserts a new commission record
+func (a *MyWellTaxAdapter) CreateCommission(ctx context.Context, db *sql.DB, schemaPrefix string, commission *types.Commission) (*types.Commission, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.commissions (
+			id, affiliate_id, filing_id, user_id, discount_code_id, payment_id,
+			order_amount, discount_amount, net_amount, commission_rate,
+			commission_amount, status, notes
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		RETURNING id, created_at, updated_at
+	`, sqlident.Schema(schemaPrefix))
+
+	logger.Infof("MyWellTax adapter creating commission for affiliate %s (status=%s)", commission.AffiliateID, commission.Status)
+
+	err := db.QueryRowContext(
+		ctx,
+		query,
+		a.newID(),
+		commission.AffiliateID,
+		commission.FilingID,
+		commission.UserID,
+		commission.DiscountCodeID,
+		commission.PaymentID,
+		commission.OrderAmount,
+		commission.DiscountAmount,
+		commission.NetAmount,
+		commission.CommissionRate,
+		commission.CommissionAmount,
+		commission.Status,
+		commission.Notes,
+	).Scan(&commission.ID, &commission.CreatedAt, &commission.UpdatedAt)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to create commission: %v", err)
+		return nil, fmt.Errorf("failed to create commission: %w", err)
+	}
+
+	logger.Infof("MyWellTax adapter successfully created commission %s", commission.ID)
+	return commission, nil
+}
+
+// EvaluateCommissionFraud runs the configured fraud rules against a commission
+// that is about to be created. ipAddress is the customer's IP at purchase time
+// and may be empty, in which case the click IP rule is skipped.
+func (a *MyWellTaxAdapter) EvaluateCommissionFraud(ctx context.Context, db *sql.DB, schemaPrefix string, commission *types.Commission, ipAddress string, rules *types.FraudRules) ([]types.FraudFlag, error) {
+	var flags []types.FraudFlag
+	if rules == nil || !rules.Enabled {
+		return flags, nil
+	}
+
+	var affiliateEmail, customerEmail string
+	var customerSSN sql.NullString
+	query := fmt.Sprintf(`
+		SELECT a.email, u.email, u.ssn
+		FROM %s.affiliates a, %s.user u
+		WHERE a.id = $1 AND u.id = $2
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+	err := db.QueryRowContext(ctx, query, commission.AffiliateID, commission.UserID).Scan(&affiliateEmail, &customerEmail, &customerSSN)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperr.Conflict("affiliate or customer not found")
+		}
+		logger.Errorf("MyWellTax adapter failed to load fraud check data: %v", err)
+		return nil, fmt.Errorf("failed to load fraud check data: %w", err)
+	}
+
+	if rules.CheckSelfReferral && strings.EqualFold(strings.TrimSpace(affiliateEmail), strings.TrimSpace(customerEmail)) {
+		flags = append(flags, types.FraudFlag{
+			Rule:   types.FraudRuleSelfReferral,
+			Reason: "customer email matches affiliate email",
+		})
+	}
+
+	if rules.CheckSameSSN && customerSSN.Valid && customerSSN.String != "" {
+		match, err := a.affiliateSharesSSN(ctx, db, schemaPrefix, affiliateEmail, commission.UserID.String(), customerSSN.String)
+		if err != nil {
+			return nil, err
+		}
+		if match {
+			flags = append(flags, types.FraudFlag{
+				Rule:   types.FraudRuleSameSSN,
+				Reason: "customer SSN matches the affiliate's own client record",
+			})
+		}
+	}
+
+	if rules.CheckClickIP && ipAddress != "" && rules.MaxClicksPerIP > 0 {
+		var clicks int
+		query := fmt.Sprintf(`
+			SELECT COUNT(*) FROM %s.affiliate_clicks
+			WHERE affiliate_id = $1 AND ip_address = $2
+		`, sqlident.Schema(schemaPrefix))
+		if err := db.QueryRowContext(ctx, query, commission.AffiliateID, ipAddress).Scan(&clicks); err != nil {
+			logger.Errorf("MyWellTax adapter failed to count affiliate clicks by IP: %v", err)
+			return nil, fmt.Errorf("failed to count affiliate clicks: %w", err)
+		}
+		if clicks >= rules.MaxClicksPerIP {
+			flags = append(flags, types.FraudFlag{
+				Rule:   types.FraudRuleClickIP,
+				Reason: fmt.Sprintf("purchase IP generated %d affiliate clicks", clicks),
+			})
+		}
+	}
+
+	if rules.VelocityMaxPerCode > 0 && rules.VelocityWindowHours > 0 {
+		var recent int
+		query := fmt.Sprintf(`
+			SELECT COUNT(*) FROM %s.commissions
+			WHERE discount_code_id = $1
+			  AND created_at > NOW() - make_interval(hours => $2)
+		`, sqlident.Schema(schemaPrefix))
+		if err := db.QueryRowContext(ctx, query, commission.DiscountCodeID, rules.VelocityWindowHours).Scan(&recent); err != nil {
+			logger.Errorf("MyWellTax adapter failed to count recent commissions: %v", err)
+			return nil, fmt.Errorf("failed to count recent commissions: %w", err)
+		}
+		if recent >= rules.VelocityMaxPerCode {
+			flags = append(flags, types.FraudFlag{
+				Rule:   types.FraudRuleVelocity,
+				Reason: fmt.Sprintf("discount code used for %d commissions in the last %d hours", recent, rules.VelocityWindowHours),
+			})
+		}
+	}
+
+	logger.Infof("MyWellTax adapter fraud evaluation for affiliate %s produced %d flags", commission.AffiliateID, len(flags))
+	return flags, nil
+}
+
+// affiliateSharesSSN reports whether a client record registered with the
+// affiliate's email has the same SSN as the customer. SSNs are compared after
+// decryption since ciphertexts use random nonces.
+func (a *MyWellTaxAdapter) affiliateSharesSSN(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateEmail string, customerID string, customerSSN string) (bool, error) {
+	query := fmt.Sprintf(`
+		SELECT ssn FROM %s.user
+		WHERE LOWER(email) = LOWER($1) AND id <> $2 AND ssn IS NOT NULL AND ssn <> ''
+	`, sqlident.Schema(schemaPrefix))
+
+	rows, err := db.QueryContext(ctx, query, affiliateEmail, customerID)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to query affiliate client records: %v", err)
+		return false, fmt.Errorf("failed to query affiliate client records: %w", err)
+	}
+	defer rows.Close()
+
+	customerPlain, err := crypto.DecryptSSN(customerSSN)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to decrypt customer SSN for fraud check: %v", err)
+		return false, fmt.Errorf("failed to decrypt customer SSN: %w", err)
+	}
+
+	for rows.Next() {
+		var ssn string
+		if err := rows.Scan(&ssn); err != nil {
+			return false, fmt.Errorf("failed to scan affiliate SSN: %w", err)
+		}
+		plain, err := crypto.DecryptSSN(ssn)
+		if err != nil {
+			logger.Warningf("MyWellTax adapter skipping undecryptable SSN during fraud check: %v", err)
+			continue
+		}
+		if plain == customerPlain {
+			return true, nil
+		}
+	}
+
+	return false, rows.Err()
+}
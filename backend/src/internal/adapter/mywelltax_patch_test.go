@@ -0,0 +1,205 @@
+package adapter
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/uuid"
+)
+
+var setClause = regexp.MustCompile(`(?s)SET\s+(.*?)\s+WHERE id = \$(\d+)`)
+
+// setColumns returns the columns an UPDATE sets and the placeholder of its id
+func setColumns(t *testing.T, query string) ([]string, string) {
+	t.Helper()
+	match := setClause.FindStringSubmatch(query)
+	if match == nil {
+		t.Fatalf("no SET ... WHERE id in query %q", query)
+	}
+	var columns []string
+	for _, assignment := range strings.Split(match[1], ", ") {
+		columns = append(columns, strings.TrimSpace(strings.SplitN(assignment, "=", 2)[0]))
+	}
+	return columns, match[2]
+}
+
+func TestPatchAffiliateSetsOnlyProvidedColumns(t *testing.T) {
+	str := func(s string) *string { return &s }
+	rate := types.Rate(12_50)
+	threshold := types.Cents(75_00)
+	active := false
+
+	tests := []struct {
+		name        string
+		patch       types.AffiliatePatch
+		wantColumns []string
+		wantArgs    []driver.Value // before the affiliate ID
+	}{
+		{
+			name:        "one field",
+			patch:       types.AffiliatePatch{FirstName: str("Augusta")},
+			wantColumns: []string{"first_name", "updated_at"},
+			wantArgs:    []driver.Value{"Augusta"},
+		},
+		{
+			name:        "money fields",
+			patch:       types.AffiliatePatch{DefaultCommissionRate: &rate, PayoutThreshold: &threshold},
+			wantColumns: []string{"default_commission_rate", "payout_threshold", "updated_at"},
+			wantArgs:    []driver.Value{"12.5", "75.00"},
+		},
+		{
+			name:        "phone and active flag",
+			patch:       types.AffiliatePatch{Phone: str("555-0100"), IsActive: &active},
+			wantColumns: []string{"phone", "is_active", "updated_at"},
+			wantArgs:    []driver.Value{"555-0100", false},
+		},
+		{
+			name:        "empty phone clears it",
+			patch:       types.AffiliatePatch{Phone: str("")},
+			wantColumns: []string{"phone", "updated_at"},
+			wantArgs:    []driver.Value{nil},
+		},
+	}
+
+	columns := []string{"id", "first_name", "last_name", "email", "phone", "default_commission_rate",
+		"stripe_connect_account_id", "payout_method", "payout_threshold", "is_active", "created_at", "updated_at"}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.New()
+			now := time.Now()
+			db, conn := newRecordingDB(t, columns, []driver.Value{
+				id.String(), "Ada", "Byron", "ada@example.com", nil, "15", nil, "MANUAL", "50.00", true, now, now,
+			})
+
+			affiliate, err := (&MyWellTaxAdapter{}).PatchAffiliate(context.Background(), db, "taxes", id.String(), &tt.patch)
+			if err != nil {
+				t.Fatalf("PatchAffiliate: %v", err)
+			}
+			if affiliate.ID != id {
+				t.Errorf("ID = %s, want %s", affiliate.ID, id)
+			}
+
+			sent := conn.lastQuery(t)
+			gotColumns, idPlaceholder := setColumns(t, sent.query)
+			if !reflect.DeepEqual(gotColumns, tt.wantColumns) {
+				t.Errorf("SET columns = %v, want %v", gotColumns, tt.wantColumns)
+			}
+			wantArgs := append(tt.wantArgs, id.String())
+			if !reflect.DeepEqual(sent.args, wantArgs) {
+				t.Errorf("args = %#v, want %#v", sent.args, wantArgs)
+			}
+			if want := strconv.Itoa(len(wantArgs)); idPlaceholder != want {
+				t.Errorf("id placeholder = $%s, want $%s", idPlaceholder, want)
+			}
+		})
+	}
+}
+
+func TestPatchAffiliateNotFound(t *testing.T) {
+	db, _ := newRecordingDB(t, []string{"id"})
+	first := "Augusta"
+	_, err := (&MyWellTaxAdapter{}).PatchAffiliate(context.Background(), db, "taxes", uuid.NewString(), &types.AffiliatePatch{FirstName: &first})
+	if !errors.Is(err, apperr.ErrNotFound) {
+		t.Errorf("err = %v, want a not found error", err)
+	}
+}
+
+func TestPatchDiscountCodeSetsOnlyProvidedColumns(t *testing.T) {
+	str := func(s string) *string { return &s }
+	value := 25.0
+	maxUses := 250
+	rate := types.Rate(10_00)
+	active := false
+
+	tests := []struct {
+		name        string
+		patch       types.DiscountCodePatch
+		wantColumns []string
+		wantArgs    []driver.Value // before updated_at and the code ID
+	}{
+		{
+			name:        "one field",
+			patch:       types.DiscountCodePatch{Description: str("Spring sale")},
+			wantColumns: []string{"description", "updated_at"},
+			wantArgs:    []driver.Value{"Spring sale"},
+		},
+		{
+			name:        "code is upper-cased",
+			patch:       types.DiscountCodePatch{Code: str("spring26")},
+			wantColumns: []string{"code", "updated_at"},
+			wantArgs:    []driver.Value{"SPRING26"},
+		},
+		{
+			name:        "value, uses, rate and active flag",
+			patch:       types.DiscountCodePatch{DiscountValue: &value, MaxUses: &maxUses, CommissionRate: &rate, IsActive: &active},
+			wantColumns: []string{"discount_value", "max_uses", "is_active", "commission_rate", "updated_at"},
+			wantArgs:    []driver.Value{25.0, int64(250), false, "10"},
+		},
+		{
+			name:        "empty strings clear the optional fields",
+			patch:       types.DiscountCodePatch{Description: str(""), ValidFrom: str(""), ValidUntil: str("")},
+			wantColumns: []string{"description", "valid_from", "valid_until", "updated_at"},
+			wantArgs:    []driver.Value{nil, nil, nil},
+		},
+		{
+			name:        "expiry",
+			patch:       types.DiscountCodePatch{ValidUntil: str("2026-12-31")},
+			wantColumns: []string{"valid_until", "updated_at"},
+			wantArgs:    []driver.Value{"2026-12-31"},
+		},
+	}
+
+	columns := strings.Split(strings.Join(strings.Fields(myWellTaxDiscountCodeColumns), ""), ",")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.New()
+			db, conn := newRecordingDB(t, columns, []driver.Value{
+				id.String(), "SPRING", nil, "PERCENTAGE", 20.0, nil, int64(0), nil, nil, true, false, nil, nil, "2026-02-01 10:00:00", nil, nil,
+			})
+
+			code, err := (&MyWellTaxAdapter{}).PatchDiscountCode(context.Background(), db, "taxes", id.String(), &tt.patch)
+			if err != nil {
+				t.Fatalf("PatchDiscountCode: %v", err)
+			}
+			if code.ID != id {
+				t.Errorf("ID = %s, want %s", code.ID, id)
+			}
+
+			sent := conn.lastQuery(t)
+			gotColumns, idPlaceholder := setColumns(t, sent.query)
+			if !reflect.DeepEqual(gotColumns, tt.wantColumns) {
+				t.Errorf("SET columns = %v, want %v", gotColumns, tt.wantColumns)
+			}
+			if len(sent.args) != len(tt.wantArgs)+2 {
+				t.Fatalf("got %d args, want %d", len(sent.args), len(tt.wantArgs)+2)
+			}
+			if got := sent.args[:len(tt.wantArgs)]; !reflect.DeepEqual(got, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", got, tt.wantArgs)
+			}
+			if got := sent.args[len(sent.args)-1]; got != id.String() {
+				t.Errorf("id arg = %v, want %s", got, id)
+			}
+			if want := strconv.Itoa(len(sent.args)); idPlaceholder != want {
+				t.Errorf("id placeholder = $%s, want $%s", idPlaceholder, want)
+			}
+		})
+	}
+}
+
+func TestPatchDiscountCodeNotFound(t *testing.T) {
+	db, _ := newRecordingDB(t, []string{"id"})
+	active := false
+	_, err := (&MyWellTaxAdapter{}).PatchDiscountCode(context.Background(), db, "taxes", uuid.NewString(), &types.DiscountCodePatch{IsActive: &active})
+	if !errors.Is(err, apperr.ErrNotFound) {
+		t.Errorf("err = %v, want a not found error", err)
+	}
+}
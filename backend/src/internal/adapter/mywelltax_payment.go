@@ -0,0 +1,91 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/sqlident"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// RecordCheckoutPayment records the payment of a checkout session for a filing, or updates the
+// status of the payment already recorded for the session, and reports any commission created
+// for the payment. Amounts are stored in cents.
+func (a *MyWellTaxAdapter) RecordCheckoutPayment(ctx context.Context, db *sql.DB, schemaPrefix string, payment *types.Payment) (*types.CheckoutPayment, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result := &types.CheckoutPayment{Payment: payment}
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`SELECT user_id FROM %s.filing WHERE id = $1`, sqlident.Schema(schemaPrefix)), payment.FilingID).Scan(&result.UserID)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("filing not found: %s", payment.FilingID)
+	}
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to get filing %s: %v", payment.FilingID, err)
+		return nil, fmt.Errorf("failed to get filing: %w", err)
+	}
+
+	var paymentID uuid.UUID
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT id FROM %s.payment WHERE stripe_session_id = $1 FOR UPDATE
+	`, sqlident.Schema(schemaPrefix)), payment.StripeSessionID).Scan(&paymentID)
+	switch {
+	case err == sql.ErrNoRows:
+		err = tx.QueryRowContext(ctx, fmt.Sprintf(`
+			INSERT INTO %s.payment (id, filing_id, stripe_session_id, amount, original_amount, discount_amount, discount_code, status)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING id, created_at, updated_at
+		`, sqlident.Schema(schemaPrefix)), a.newID(), payment.FilingID, payment.StripeSessionID, int64(payment.Amount),
+			minorUnitsOrNil(payment.OriginalAmount), minorUnitsOrNil(payment.DiscountAmount), payment.DiscountCode,
+			payment.Status).Scan(&payment.ID, &payment.CreatedAt, &payment.UpdatedAt)
+		result.Created = true
+	case err != nil:
+		logger.Errorf("MyWellTax adapter failed to look up payment for session %s: %v", payment.StripeSessionID, err)
+		return nil, fmt.Errorf("failed to look up payment: %w", err)
+	default:
+		err = tx.QueryRowContext(ctx, fmt.Sprintf(`
+			UPDATE %s.payment SET status = $2, updated_at = NOW()
+			WHERE id = $1
+			RETURNING id, created_at, updated_at
+		`, sqlident.Schema(schemaPrefix)), paymentID, payment.Status).Scan(&payment.ID, &payment.CreatedAt, &payment.UpdatedAt)
+	}
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to record payment for session %s: %v", payment.StripeSessionID, err)
+		return nil, fmt.Errorf("failed to record payment: %w", err)
+	}
+
+	var commissionID uuid.UUID
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT id FROM %s.commissions WHERE payment_id = $1 LIMIT 1
+	`, sqlident.Schema(schemaPrefix)), payment.ID).Scan(&commissionID)
+	switch {
+	case err == sql.ErrNoRows:
+	case err != nil:
+		logger.Errorf("MyWellTax adapter failed to look up commission for payment %s: %v", payment.ID, err)
+		return nil, fmt.Errorf("failed to look up commission: %w", err)
+	default:
+		result.CommissionID = &commissionID
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit payment: %w", err)
+	}
+
+	logger.Infof("MyWellTax adapter recorded payment %s for session %s (created=%v)", payment.ID, payment.StripeSessionID, result.Created)
+	return result, nil
+}
+
+// minorUnitsOrNil returns an optional amount as cents for a column storing minor units
+func minorUnitsOrNil(amount *types.Cents) interface{} {
+	if amount == nil {
+		return nil
+	}
+	return int64(*amount)
+}
@@ -0,0 +1,720 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/sqlident"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// payoutColumns are read by scanPayout, with p aliasing affiliate_payouts and a affiliates
+const payoutColumns = `p.id, p.batch_id, p.affiliate_id, a.first_name || ' ' || a.last_name, p.amount,
+		p.commission_count, p.payout_method, p.status, p.attempts, p.transfer_id, p.reference,
+		p.paid_amount, p.error, p.paid_by, p.created_at, p.paid_at, p.updated_at, a.stripe_connect_account_id`
+
+// payoutPaymentColumns are read by scanPayoutPayment from affiliate_payout_payments
+const payoutPaymentColumns = `id, payout_id, affiliate_id, method, reference, amount, paid_by, paid_at`
+
+// payoutBatchColumns are read by scanPayoutBatch, with b aliasing affiliate_payout_batches
+const payoutBatchColumns = `b.id, b.total_amount, b.created_by, b.created_at,
+		COUNT(p.id),
+		COUNT(p.id) FILTER (WHERE p.status IN ('PENDING', 'PROCESSING')),
+		COUNT(p.id) FILTER (WHERE p.status = 'PAID'),
+		COUNT(p.id) FILTER (WHERE p.status = 'FAILED')`
+
+// CreatePayoutBatch creates one payout per active affiliate whose approved commissions not yet in
+// a payout reach their payout threshold, and attaches those commissions to it
+func (a *MyWellTaxAdapter) CreatePayoutBatch(ctx context.Context, db *sql.DB, schemaPrefix string, createdBy string) (*types.PayoutBatch, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Lock the unbatched commissions so a concurrent batch or status change cannot take them
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+		SELECT id FROM %s.commissions
+		WHERE status = 'APPROVED' AND payout_id IS NULL
+		FOR UPDATE
+	`, sqlident.Schema(schemaPrefix)))
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to lock commissions for payout: %v", err)
+		return nil, fmt.Errorf("failed to lock commissions: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+		SELECT c.affiliate_id, a.payout_method, SUM(c.commission_amount), COUNT(*)
+		FROM %s.commissions c
+		JOIN %s.affiliates a ON a.id = c.affiliate_id
+		WHERE c.status = 'APPROVED' AND c.payout_id IS NULL AND a.is_active
+		GROUP BY c.affiliate_id, a.payout_method, a.payout_threshold
+		HAVING SUM(c.commission_amount) > 0 AND SUM(c.commission_amount) >= a.payout_threshold
+		ORDER BY c.affiliate_id
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix)))
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to total commissions for payout: %v", err)
+		return nil, fmt.Errorf("failed to total commissions: %w", err)
+	}
+	var payouts []*types.AffiliatePayout
+	for rows.Next() {
+		p := &types.AffiliatePayout{Status: types.PayoutStatusPending}
+		if err := rows.Scan(&p.AffiliateID, &p.PayoutMethod, &p.Amount, &p.CommissionCount); err != nil {
+			rows.Close()
+			logger.Errorf("MyWellTax adapter failed to scan payout total: %v", err)
+			return nil, fmt.Errorf("failed to scan payout total: %w", err)
+		}
+		payouts = append(payouts, p)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to total commissions: %w", err)
+	}
+	if len(payouts) == 0 {
+		return nil, apperr.Conflict("no affiliate has approved commissions reaching their payout threshold")
+	}
+
+	batch := &types.PayoutBatch{CreatedBy: createdBy, PayoutCount: len(payouts), OpenCount: len(payouts)}
+	for _, p := range payouts {
+		batch.TotalAmount += p.Amount
+	}
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`
+		INSERT INTO %s.affiliate_payout_batches (id, total_amount, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`, sqlident.Schema(schemaPrefix)), a.newID(), batch.TotalAmount, createdBy).Scan(&batch.ID, &batch.CreatedAt)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to create payout batch: %v", err)
+		return nil, fmt.Errorf("failed to create payout batch: %w", err)
+	}
+
+	insertPayout := fmt.Sprintf(`
+		INSERT INTO %s.affiliate_payouts (id, batch_id, affiliate_id, amount, commission_count, payout_method)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, sqlident.Schema(schemaPrefix))
+	attachCommissions := fmt.Sprintf(`
+		UPDATE %s.commissions
+		SET payout_id = $1, updated_at = NOW()
+		WHERE affiliate_id = $2 AND status = 'APPROVED' AND payout_id IS NULL
+	`, sqlident.Schema(schemaPrefix))
+	for _, p := range payouts {
+		p.BatchID = batch.ID
+		err := tx.QueryRowContext(ctx, insertPayout, a.newID(), batch.ID, p.AffiliateID, p.Amount, p.CommissionCount, p.PayoutMethod).Scan(&p.ID, &p.CreatedAt)
+		if err != nil {
+			logger.Errorf("MyWellTax adapter failed to create payout for affiliate %s: %v", p.AffiliateID, err)
+			return nil, fmt.Errorf("failed to create payout: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, attachCommissions, p.ID, p.AffiliateID); err != nil {
+			logger.Errorf("MyWellTax adapter failed to attach commissions to payout %s: %v", p.ID, err)
+			return nil, fmt.Errorf("failed to attach commissions: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Errorf("MyWellTax adapter failed to commit payout batch: %v", err)
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Infof("MyWellTax adapter created payout batch %s with %d payouts totalling $%s", batch.ID, len(payouts), batch.TotalAmount)
+	return a.GetPayoutBatch(ctx, db, schemaPrefix, batch.ID.String())
+}
+
+// GetPayoutBatches retrieves the latest payout batches with their payout counts
+func (a *MyWellTaxAdapter) GetPayoutBatches(ctx context.Context, db *sql.DB, schemaPrefix string, limit int) ([]*types.PayoutBatch, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM %s.affiliate_payout_batches b
+		LEFT JOIN %s.affiliate_payouts p ON p.batch_id = b.id
+		GROUP BY b.id
+		ORDER BY b.created_at DESC
+		LIMIT $1
+	`, payoutBatchColumns, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix)), limit)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to query payout batches: %v", err)
+		return nil, fmt.Errorf("failed to query payout batches: %w", err)
+	}
+	defer rows.Close()
+
+	batches := []*types.PayoutBatch{}
+	for rows.Next() {
+		batch, err := scanPayoutBatch(rows)
+		if err != nil {
+			logger.Errorf("MyWellTax adapter failed to scan payout batch: %v", err)
+			return nil, fmt.Errorf("failed to scan payout batch: %w", err)
+		}
+		batches = append(batches, batch)
+	}
+
+	return batches, rows.Err()
+}
+
+// GetPayoutBatch retrieves a payout batch with its payouts
+func (a *MyWellTaxAdapter) GetPayoutBatch(ctx context.Context, db *sql.DB, schemaPrefix string, batchID string) (*types.PayoutBatch, error) {
+	batch, err := scanPayoutBatch(db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM %s.affiliate_payout_batches b
+		LEFT JOIN %s.affiliate_payouts p ON p.batch_id = b.id
+		WHERE b.id = $1
+		GROUP BY b.id
+	`, payoutBatchColumns, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix)), batchID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperr.NotFound("payout batch not found")
+		}
+		logger.Errorf("MyWellTax adapter failed to get payout batch %s: %v", batchID, err)
+		return nil, fmt.Errorf("failed to get payout batch: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM %s.affiliate_payouts p
+		JOIN %s.affiliates a ON a.id = p.affiliate_id
+		WHERE p.batch_id = $1
+		ORDER BY p.created_at, p.id
+	`, payoutColumns, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix)), batchID)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to query payouts of batch %s: %v", batchID, err)
+		return nil, fmt.Errorf("failed to query payouts: %w", err)
+	}
+	defer rows.Close()
+
+	batch.Payouts = []*types.AffiliatePayout{}
+	for rows.Next() {
+		p, err := scanPayout(rows)
+		if err != nil {
+			logger.Errorf("MyWellTax adapter failed to scan payout: %v", err)
+			return nil, fmt.Errorf("failed to scan payout: %w", err)
+		}
+		batch.Payouts = append(batch.Payouts, p)
+	}
+
+	return batch, rows.Err()
+}
+
+// GetPayout retrieves a single payout with its payments
+func (a *MyWellTaxAdapter) GetPayout(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string) (*types.AffiliatePayout, error) {
+	p, err := scanPayout(db.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM %s.affiliate_payouts p
+		JOIN %s.affiliates a ON a.id = p.affiliate_id
+		WHERE p.id = $1
+	`, payoutColumns, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix)), payoutID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperr.NotFound("payout not found")
+		}
+		logger.Errorf("MyWellTax adapter failed to get payout %s: %v", payoutID, err)
+		return nil, fmt.Errorf("failed to get payout: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM %s.affiliate_payout_payments
+		WHERE payout_id = $1
+		ORDER BY paid_at, id
+	`, payoutPaymentColumns, sqlident.Schema(schemaPrefix)), payoutID)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to query payments of payout %s: %v", payoutID, err)
+		return nil, fmt.Errorf("failed to query payout payments: %w", err)
+	}
+	defer rows.Close()
+
+	p.Payments = []*types.AffiliatePayoutPayment{}
+	for rows.Next() {
+		payment, err := scanPayoutPayment(rows)
+		if err != nil {
+			logger.Errorf("MyWellTax adapter failed to scan payout payment: %v", err)
+			return nil, fmt.Errorf("failed to scan payout payment: %w", err)
+		}
+		p.Payments = append(p.Payments, payment)
+	}
+	return p, rows.Err()
+}
+
+// GetAffiliatePayments retrieves an affiliate's latest payout payments, newest first
+func (a *MyWellTaxAdapter) GetAffiliatePayments(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, limit int) ([]*types.AffiliatePayoutPayment, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM %s.affiliate_payout_payments
+		WHERE affiliate_id = $1
+		ORDER BY paid_at DESC, id
+		LIMIT $2
+	`, payoutPaymentColumns, sqlident.Schema(schemaPrefix)), affiliateID, limit)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to query payments of affiliate %s: %v", affiliateID, err)
+		return nil, fmt.Errorf("failed to query affiliate payments: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []*types.AffiliatePayoutPayment{}
+	for rows.Next() {
+		payment, err := scanPayoutPayment(rows)
+		if err != nil {
+			logger.Errorf("MyWellTax adapter failed to scan payout payment: %v", err)
+			return nil, fmt.Errorf("failed to scan payout payment: %w", err)
+		}
+		payments = append(payments, payment)
+	}
+	return payments, rows.Err()
+}
+
+// GetAffiliatePaymentTotals sums the payout payments made to each affiliate in a calendar year by
+// paid_at, largest first
+func (a *MyWellTaxAdapter) GetAffiliatePaymentTotals(ctx context.Context, db *sql.DB, schemaPrefix string, year int) ([]*types.AffiliatePaymentTotal, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT a.id, a.first_name || ' ' || a.last_name, a.email, COUNT(pp.id), SUM(pp.amount)
+		FROM %s.affiliate_payout_payments pp
+		JOIN %s.affiliates a ON a.id = pp.affiliate_id
+		WHERE pp.paid_at >= make_date($1, 1, 1) AND pp.paid_at < make_date($1 + 1, 1, 1)
+		GROUP BY a.id, a.first_name, a.last_name, a.email
+		ORDER BY SUM(pp.amount) DESC, a.id
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix)), year)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to total affiliate payments of %d: %v", year, err)
+		return nil, fmt.Errorf("failed to total affiliate payments: %w", err)
+	}
+	defer rows.Close()
+
+	totals := []*types.AffiliatePaymentTotal{}
+	for rows.Next() {
+		t := &types.AffiliatePaymentTotal{Year: year}
+		if err := rows.Scan(&t.AffiliateID, &t.AffiliateName, &t.Email, &t.Payments, &t.Total); err != nil {
+			logger.Errorf("MyWellTax adapter failed to scan affiliate payment total: %v", err)
+			return nil, fmt.Errorf("failed to scan affiliate payment total: %w", err)
+		}
+		totals = append(totals, t)
+	}
+	return totals, rows.Err()
+}
+
+// GetAffiliateStatement retrieves an affiliate's payout payments of a calendar year by paid_at,
+// oldest first, with their total
+func (a *MyWellTaxAdapter) GetAffiliateStatement(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, year int) (*types.AffiliateStatement, error) {
+	id, err := uuid.Parse(affiliateID)
+	if err != nil {
+		return nil, apperr.Validation("invalid affiliate ID: %s", affiliateID)
+	}
+
+	rows, err := db.QueryContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM %s.affiliate_payout_payments
+		WHERE affiliate_id = $1 AND paid_at >= make_date($2, 1, 1) AND paid_at < make_date($2 + 1, 1, 1)
+		ORDER BY paid_at, id
+	`, payoutPaymentColumns, sqlident.Schema(schemaPrefix)), affiliateID, year)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to query %d payments of affiliate %s: %v", year, affiliateID, err)
+		return nil, fmt.Errorf("failed to query affiliate statement: %w", err)
+	}
+	defer rows.Close()
+
+	statement := &types.AffiliateStatement{AffiliateID: id, Year: year, Payments: []*types.AffiliatePayoutPayment{}}
+	for rows.Next() {
+		payment, err := scanPayoutPayment(rows)
+		if err != nil {
+			logger.Errorf("MyWellTax adapter failed to scan payout payment: %v", err)
+			return nil, fmt.Errorf("failed to scan payout payment: %w", err)
+		}
+		statement.Payments = append(statement.Payments, payment)
+		statement.Total += payment.Amount
+	}
+	return statement, rows.Err()
+}
+
+// StartPayoutTransfer checks that a payout still matches its commissions and marks it PROCESSING.
+// A PENDING or FAILED payout starts a new transfer attempt; a PROCESSING one retries its current
+// attempt, whose outcome is unknown.
+func (a *MyWellTaxAdapter) StartPayoutTransfer(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string) (*types.AffiliatePayout, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	p, err := scanPayout(tx.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT %s
+		FROM %s.affiliate_payouts p
+		JOIN %s.affiliates a ON a.id = p.affiliate_id
+		WHERE p.id = $1
+		FOR UPDATE OF p
+	`, payoutColumns, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix)), payoutID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperr.NotFound("payout not found")
+		}
+		logger.Errorf("MyWellTax adapter failed to lock payout %s: %v", payoutID, err)
+		return nil, fmt.Errorf("failed to lock payout: %w", err)
+	}
+	if p.PaidAmount > 0 {
+		return nil, apperr.Conflict("payout has been partly paid by hand; record the remaining $%s with mark-paid", p.Amount-p.PaidAmount)
+	}
+	switch p.Status {
+	case types.PayoutStatusPending, types.PayoutStatusFailed:
+		p.Attempts++
+	case types.PayoutStatusProcessing:
+	default:
+		return nil, apperr.Conflict("payout is %s", p.Status)
+	}
+
+	var count, unapproved int
+	var total types.Cents
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT COUNT(*), COALESCE(SUM(commission_amount), 0), COUNT(*) FILTER (WHERE status <> 'APPROVED')
+		FROM %s.commissions
+		WHERE payout_id = $1
+	`, sqlident.Schema(schemaPrefix)), payoutID).Scan(&count, &total, &unapproved)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to total commissions of payout %s: %v", payoutID, err)
+		return nil, fmt.Errorf("failed to total commissions: %w", err)
+	}
+	if count != p.CommissionCount || total != p.Amount || unapproved > 0 {
+		return nil, apperr.Conflict("payout no longer matches its commissions; cancel it and create a new batch")
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE %s.affiliate_payouts
+		SET status = 'PROCESSING', attempts = $2, updated_at = NOW()
+		WHERE id = $1
+	`, sqlident.Schema(schemaPrefix)), payoutID, p.Attempts)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to start payout %s: %v", payoutID, err)
+		return nil, fmt.Errorf("failed to start payout: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	p.Status = types.PayoutStatusProcessing
+	return p, nil
+}
+
+// RecordPayoutFailure records a transfer error on a PROCESSING payout. A declined transfer fails
+// the payout; any other error leaves it PROCESSING so the same attempt is retried.
+func (a *MyWellTaxAdapter) RecordPayoutFailure(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string, message string, declined bool) error {
+	status := types.PayoutStatusProcessing
+	if declined {
+		status = types.PayoutStatusFailed
+	}
+
+	_, err := db.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE %s.affiliate_payouts
+		SET status = $2, error = $3, updated_at = NOW()
+		WHERE id = $1 AND status = 'PROCESSING'
+	`, sqlident.Schema(schemaPrefix)), payoutID, status, message)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to record failure of payout %s: %v", payoutID, err)
+		return fmt.Errorf("failed to record payout failure: %w", err)
+	}
+	return nil
+}
+
+// MarkPayoutPaid records a payment against a payout. Once its payments cover the amount, the
+// payout and all of its commissions are marked PAID in the same transaction and the commissions
+// returned; a partial payment leaves the payout open and returns no commissions.
+func (a *MyWellTaxAdapter) MarkPayoutPaid(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string, payment *types.PayoutPayment) (*types.AffiliatePayout, []*types.Commission, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	commissions, err := a.recordPayoutPayment(ctx, tx, schemaPrefix, payoutID, payment)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Errorf("MyWellTax adapter failed to commit payout %s: %v", payoutID, err)
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	p, err := a.GetPayout(ctx, db, schemaPrefix, payoutID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, commissions, nil
+}
+
+// MarkCommissionPaid pays an approved commission that is not in a payout by hand. The commission
+// is put in a payout of its own, in a batch of one, and the payment recorded against it as
+// MarkPayoutPaid does; the rest of a partial payment is recorded against that payout.
+func (a *MyWellTaxAdapter) MarkCommissionPaid(ctx context.Context, db *sql.DB, schemaPrefix string, commissionID string, payment *types.PayoutPayment) (*types.AffiliatePayout, []*types.Commission, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	logger.Infof("MyWellTax adapter marking commission %s as paid", commissionID)
+
+	var affiliateID uuid.UUID
+	var amount types.Cents
+	var payoutMethod string
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT c.affiliate_id, c.commission_amount, a.payout_method
+		FROM %s.commissions c
+		JOIN %s.affiliates a ON a.id = c.affiliate_id
+		WHERE c.id = $1 AND c.status = 'APPROVED' AND c.payout_id IS NULL
+		FOR UPDATE OF c
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix)), commissionID).Scan(&affiliateID, &amount, &payoutMethod)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil, apperr.Conflict("commission not found, not approved, or in a payout batch")
+		}
+		logger.Errorf("MyWellTax adapter failed to lock commission %s: %v", commissionID, err)
+		return nil, nil, fmt.Errorf("failed to lock commission: %w", err)
+	}
+
+	var batchID, payoutID uuid.UUID
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`
+		INSERT INTO %s.affiliate_payout_batches (id, total_amount, created_by)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, sqlident.Schema(schemaPrefix)), a.newID(), amount, payment.PaidBy).Scan(&batchID)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to create payout batch for commission %s: %v", commissionID, err)
+		return nil, nil, fmt.Errorf("failed to create payout batch: %w", err)
+	}
+	err = tx.QueryRowContext(ctx, fmt.Sprintf(`
+		INSERT INTO %s.affiliate_payouts (id, batch_id, affiliate_id, amount, commission_count, payout_method)
+		VALUES ($1, $2, $3, $4, 1, $5)
+		RETURNING id
+	`, sqlident.Schema(schemaPrefix)), a.newID(), batchID, affiliateID, amount, payoutMethod).Scan(&payoutID)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to create payout for commission %s: %v", commissionID, err)
+		return nil, nil, fmt.Errorf("failed to create payout: %w", err)
+	}
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE %s.commissions SET payout_id = $1, updated_at = NOW() WHERE id = $2
+	`, sqlident.Schema(schemaPrefix)), payoutID, commissionID)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to attach commission %s to payout %s: %v", commissionID, payoutID, err)
+		return nil, nil, fmt.Errorf("failed to attach commission: %w", err)
+	}
+
+	commissions, err := a.recordPayoutPayment(ctx, tx, schemaPrefix, payoutID.String(), payment)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Errorf("MyWellTax adapter failed to commit payment of commission %s: %v", commissionID, err)
+		return nil, nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	p, err := a.GetPayout(ctx, db, schemaPrefix, payoutID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	return p, commissions, nil
+}
+
+// recordPayoutPayment records a payment against a locked payout and, once the payout is paid in
+// full, marks it and its commissions PAID and returns the commissions
+func (a *MyWellTaxAdapter) recordPayoutPayment(ctx context.Context, tx *sql.Tx, schemaPrefix string, payoutID string, payment *types.PayoutPayment) ([]*types.Commission, error) {
+	var affiliateID uuid.UUID
+	var amount, paidAmount types.Cents
+	var status string
+	var commissionCount int
+	err := tx.QueryRowContext(ctx, fmt.Sprintf(`
+		SELECT affiliate_id, amount, paid_amount, status, commission_count
+		FROM %s.affiliate_payouts
+		WHERE id = $1
+		FOR UPDATE
+	`, sqlident.Schema(schemaPrefix)), payoutID).Scan(&affiliateID, &amount, &paidAmount, &status, &commissionCount)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperr.NotFound("payout not found")
+		}
+		logger.Errorf("MyWellTax adapter failed to lock payout %s: %v", payoutID, err)
+		return nil, fmt.Errorf("failed to lock payout: %w", err)
+	}
+
+	method, reference := payment.Method, payment.Reference
+	awaiting := status == types.PayoutStatusPending || status == types.PayoutStatusFailed
+	if payment.TransferID != nil {
+		method, reference = types.PaymentMethodStripe, payment.TransferID
+		awaiting = status == types.PayoutStatusProcessing
+	}
+	if !awaiting {
+		return nil, apperr.Conflict("payout is %s, not awaiting payment", status)
+	}
+
+	owed := amount - paidAmount
+	paying := owed
+	if payment.TransferID == nil && payment.Amount != nil {
+		paying = *payment.Amount
+		if paying <= 0 {
+			return nil, apperr.Validation("amount must be positive")
+		}
+		if paying > owed {
+			return nil, apperr.Validation("amount $%s is more than the $%s still owed on the payout", paying, owed)
+		}
+	}
+
+	if paying > 0 {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+			INSERT INTO %s.affiliate_payout_payments (id, payout_id, affiliate_id, method, reference, amount, paid_by)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+		`, sqlident.Schema(schemaPrefix)), a.newID(), payoutID, affiliateID, method, reference, paying, payment.PaidBy)
+		if err != nil {
+			logger.Errorf("MyWellTax adapter failed to record payment of payout %s: %v", payoutID, err)
+			return nil, fmt.Errorf("failed to record payout payment: %w", err)
+		}
+	}
+	paidAmount += paying
+
+	if paidAmount < amount {
+		_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+			UPDATE %s.affiliate_payouts
+			SET paid_amount = $2, reference = $3, paid_by = $4, updated_at = NOW()
+			WHERE id = $1
+		`, sqlident.Schema(schemaPrefix)), payoutID, paidAmount, reference, payment.PaidBy)
+		if err != nil {
+			logger.Errorf("MyWellTax adapter failed to record partial payment of payout %s: %v", payoutID, err)
+			return nil, fmt.Errorf("failed to record payout payment: %w", err)
+		}
+		logger.Infof("MyWellTax adapter recorded $%s %s payment of payout %s; $%s still owed", paying, method, payoutID, amount-paidAmount)
+		return nil, nil
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE %s.affiliate_payouts
+		SET status = 'PAID', transfer_id = $2, reference = $3, paid_amount = $4, paid_by = $5, error = NULL,
+		    paid_at = NOW(), updated_at = NOW()
+		WHERE id = $1
+	`, sqlident.Schema(schemaPrefix)), payoutID, payment.TransferID, reference, paidAmount, payment.PaidBy)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to mark payout %s paid: %v", payoutID, err)
+		return nil, fmt.Errorf("failed to mark payout paid: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, fmt.Sprintf(`
+		UPDATE %s.commissions
+		SET status = 'PAID', paid_at = NOW(), updated_at = NOW()
+		WHERE payout_id = $1 AND status = 'APPROVED'
+		RETURNING id, affiliate_id, filing_id, user_id, discount_code_id, payment_id,
+		          order_amount, discount_amount, net_amount, commission_rate,
+		          commission_amount, status, approved_at, paid_at, notes,
+		          created_at, updated_at
+	`, sqlident.Schema(schemaPrefix)), payoutID)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to mark commissions of payout %s paid: %v", payoutID, err)
+		return nil, fmt.Errorf("failed to mark commissions paid: %w", err)
+	}
+	var commissions []*types.Commission
+	for rows.Next() {
+		commission := &types.Commission{}
+		err := rows.Scan(
+			&commission.ID,
+			&commission.AffiliateID,
+			&commission.FilingID,
+			&commission.UserID,
+			&commission.DiscountCodeID,
+			&commission.PaymentID,
+			&commission.OrderAmount,
+			&commission.DiscountAmount,
+			&commission.NetAmount,
+			&commission.CommissionRate,
+			&commission.CommissionAmount,
+			&commission.Status,
+			&commission.ApprovedAt,
+			&commission.PaidAt,
+			&commission.Notes,
+			&commission.CreatedAt,
+			&commission.UpdatedAt,
+		)
+		if err != nil {
+			rows.Close()
+			logger.Errorf("MyWellTax adapter failed to scan paid commission: %v", err)
+			return nil, fmt.Errorf("failed to scan commission: %w", err)
+		}
+		commissions = append(commissions, commission)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to mark commissions paid: %w", err)
+	}
+	if len(commissions) != commissionCount {
+		logger.Errorf("MyWellTax adapter found %d payable commissions on payout %s, expected %d", len(commissions), payoutID, commissionCount)
+		return nil, apperr.Conflict("payout no longer matches its commissions")
+	}
+
+	logger.Infof("MyWellTax adapter paid payout %s (%d commissions)", payoutID, len(commissions))
+	return commissions, nil
+}
+
+// CancelPayout cancels a PENDING or FAILED payout with no payments and releases its commissions
+// for the next batch
+func (a *MyWellTaxAdapter) CancelPayout(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string) (*types.AffiliatePayout, error) {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE %s.affiliate_payouts
+		SET status = 'CANCELLED', updated_at = NOW()
+		WHERE id = $1 AND status IN ('PENDING', 'FAILED') AND paid_amount = 0
+	`, sqlident.Schema(schemaPrefix)), payoutID)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to cancel payout %s: %v", payoutID, err)
+		return nil, fmt.Errorf("failed to cancel payout: %w", err)
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return nil, apperr.Conflict("payout not found, partly paid, or already processing, paid or cancelled")
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(`
+		UPDATE %s.commissions SET payout_id = NULL, updated_at = NOW() WHERE payout_id = $1
+	`, sqlident.Schema(schemaPrefix)), payoutID)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to release commissions of payout %s: %v", payoutID, err)
+		return nil, fmt.Errorf("failed to release commissions: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	logger.Infof("MyWellTax adapter cancelled payout %s", payoutID)
+	return a.GetPayout(ctx, db, schemaPrefix, payoutID)
+}
+
+// scanPayoutBatch reads payoutBatchColumns
+func scanPayoutBatch(row interface{ Scan(...interface{}) error }) (*types.PayoutBatch, error) {
+	b := &types.PayoutBatch{}
+	err := row.Scan(&b.ID, &b.TotalAmount, &b.CreatedBy, &b.CreatedAt, &b.PayoutCount, &b.OpenCount, &b.PaidCount, &b.FailedCount)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// scanPayout reads payoutColumns
+func scanPayout(row interface{ Scan(...interface{}) error }) (*types.AffiliatePayout, error) {
+	p := &types.AffiliatePayout{}
+	err := row.Scan(&p.ID, &p.BatchID, &p.AffiliateID, &p.AffiliateName, &p.Amount, &p.CommissionCount,
+		&p.PayoutMethod, &p.Status, &p.Attempts, &p.TransferID, &p.Reference, &p.PaidAmount, &p.Error, &p.PaidBy,
+		&p.CreatedAt, &p.PaidAt, &p.UpdatedAt, &p.StripeAccountID)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// scanPayoutPayment reads payoutPaymentColumns
+func scanPayoutPayment(row interface{ Scan(...interface{}) error }) (*types.AffiliatePayoutPayment, error) {
+	p := &types.AffiliatePayoutPayment{}
+	err := row.Scan(&p.ID, &p.PayoutID, &p.AffiliateID, &p.Method, &p.Reference, &p.Amount, &p.PaidBy, &p.PaidAt)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
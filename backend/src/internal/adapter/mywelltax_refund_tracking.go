@@ -0,0 +1,120 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/sqlident"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// refundTrackingColumns selects a refund record with dates rendered as YYYY-MM-DD
+const refundTrackingColumns = `id, filing_id, jurisdiction, expected_amount, status,
+		       to_char(accepted_date, 'YYYY-MM-DD'), to_char(deposit_window_start, 'YYYY-MM-DD'),
+		       to_char(deposit_window_end, 'YYYY-MM-DD'), source, note, updated_by, created_at, updated_at`
+
+// scanRefundTracking scans a row selected with refundTrackingColumns
+func scanRefundTracking(row interface{ Scan(...interface{}) error }) (*types.RefundTracking, error) {
+	t := &types.RefundTracking{}
+	err := row.Scan(&t.ID, &t.FilingID, &t.Jurisdiction, &t.ExpectedAmount, &t.Status,
+		&t.AcceptedDate, &t.DepositWindowStart, &t.DepositWindowEnd, &t.Source, &t.Note, &t.UpdatedBy,
+		&t.CreatedAt, &t.UpdatedAt)
+	return t, err
+}
+
+// GetRefundTrackings retrieves refund tracking for a filing, federal first
+func (a *MyWellTaxAdapter) GetRefundTrackings(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) ([]*types.RefundTracking, error) {
+	query := fmt.Sprintf(`
+		SELECT %s
+		FROM %s.refund_tracking
+		WHERE filing_id = $1
+		ORDER BY jurisdiction <> 'FEDERAL', jurisdiction
+	`, refundTrackingColumns, sqlident.Schema(schemaPrefix))
+
+	rows, err := db.QueryContext(ctx, query, filingID)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to query refund tracking for %s: %v", filingID, err)
+		return nil, fmt.Errorf("failed to query refund tracking: %w", err)
+	}
+	defer rows.Close()
+
+	var trackings []*types.RefundTracking
+	for rows.Next() {
+		t, err := scanRefundTracking(rows)
+		if err != nil {
+			logger.Errorf("MyWellTax adapter failed to scan refund tracking row: %v", err)
+			return nil, fmt.Errorf("failed to scan refund tracking: %w", err)
+		}
+		trackings = append(trackings, t)
+	}
+
+	return trackings, rows.Err()
+}
+
+// UpsertRefundTracking records or replaces the refund status for one jurisdiction of a filing
+func (a *MyWellTaxAdapter) UpsertRefundTracking(ctx context.Context, db *sql.DB, schemaPrefix string, tracking *types.RefundTracking) (*types.RefundTracking, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.refund_tracking (id, filing_id, jurisdiction, expected_amount, status, accepted_date,
+		                                deposit_window_start, deposit_window_end, source, note, updated_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		ON CONFLICT (filing_id, jurisdiction) DO UPDATE
+		SET expected_amount = EXCLUDED.expected_amount,
+		    status = EXCLUDED.status,
+		    accepted_date = EXCLUDED.accepted_date,
+		    deposit_window_start = EXCLUDED.deposit_window_start,
+		    deposit_window_end = EXCLUDED.deposit_window_end,
+		    source = EXCLUDED.source,
+		    note = EXCLUDED.note,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = NOW()
+		RETURNING %s
+	`, sqlident.Schema(schemaPrefix), refundTrackingColumns)
+
+	logger.Infof("MyWellTax adapter recording %s refund status %s for filing %s", tracking.Jurisdiction, tracking.Status, tracking.FilingID)
+
+	t, err := scanRefundTracking(db.QueryRowContext(
+		ctx,
+		query,
+		a.newID(),
+		tracking.FilingID,
+		tracking.Jurisdiction,
+		tracking.ExpectedAmount,
+		tracking.Status,
+		tracking.AcceptedDate,
+		tracking.DepositWindowStart,
+		tracking.DepositWindowEnd,
+		tracking.Source,
+		tracking.Note,
+		tracking.UpdatedBy,
+	))
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to record refund tracking: %v", err)
+		return nil, fmt.Errorf("failed to record refund tracking: %w", err)
+	}
+
+	return t, nil
+}
+
+// DeleteRefundTracking removes the refund record for one jurisdiction of a filing
+func (a *MyWellTaxAdapter) DeleteRefundTracking(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string, jurisdiction string) error {
+	query := fmt.Sprintf(`DELETE FROM %s.refund_tracking WHERE filing_id = $1 AND jurisdiction = $2`, sqlident.Schema(schemaPrefix))
+
+	result, err := db.ExecContext(ctx, query, filingID, jurisdiction)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to delete %s refund tracking for %s: %v", jurisdiction, filingID, err)
+		return fmt.Errorf("failed to delete refund tracking: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete refund tracking: %w", err)
+	}
+	if rows == 0 {
+		return apperr.NotFound("refund tracking not found")
+	}
+
+	return nil
+}
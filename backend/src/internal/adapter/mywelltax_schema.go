@@ -0,0 +1,157 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// Shorthands for declaring the expected schema
+const (
+	kUUID = types.ColumnKindUUID
+	kText = types.ColumnKindText
+	kInt  = types.ColumnKindInteger
+	kNum  = types.ColumnKindNumeric
+	kBool = types.ColumnKindBoolean
+	kTime = types.ColumnKindTimestamp
+	kArr  = types.ColumnKindArray
+)
+
+// schemaTable builds a SchemaTable from alternating column names and kinds
+func schemaTable(name string, columns ...string) types.SchemaTable {
+	table := types.SchemaTable{Name: name}
+	for i := 0; i+1 < len(columns); i += 2 {
+		table.Columns = append(table.Columns, types.SchemaColumn{Name: columns[i], Kind: columns[i+1]})
+	}
+	return table
+}
+
+// myWellTaxSchema is every table and column the MyWellTax adapter queries.
+// Keep it in step with the adapter's SQL when columns are added.
+var myWellTaxSchema = []types.SchemaTable{
+	schemaTable("user",
+		"id", kUUID, "first_name", kText, "middle_name", kText, "last_name", kText, "email", kText,
+		"phone", kText, "dob", kText, "ssn", kText, "address1", kText, "address2", kText, "city", kText,
+		"state", kText, "zipcode", kInt, "role", kText, "created_at", kText, "death_date", kText,
+		"archived_at", kText, "archive_reason", kText),
+	schemaTable("spouse",
+		"id", kUUID, "user_id", kUUID, "first_name", kText, "middle_name", kText, "last_name", kText,
+		"email", kText, "phone", kText, "dob", kText, "ssn", kText, "is_death", kBool, "death_date", kText,
+		"created_at", kText),
+	schemaTable("dependent",
+		"id", kUUID, "user_id", kUUID, "first_name", kText, "middle_name", kText, "last_name", kText,
+		"dob", kText, "ssn", kText, "relationship", kText, "time_with_applicant", kText,
+		"exclusive_claim", kBool, "created_at", kText, "updated_at", kText),
+	schemaTable("dependent_document_map",
+		"dependent_id", kUUID, "record_name", kText, "created_at", kText),
+	schemaTable("filing",
+		"id", kUUID, "year", kInt, "user_id", kUUID, "marital_status", kText, "spouse", kUUID,
+		"source_of_income", kArr, "deductions", kArr, "income", kInt, "marketplace_insurance", kBool,
+		"created_at", kText, "updated_at", kText),
+	schemaTable("filing_status",
+		"id", kUUID, "filing_id", kUUID, "latest_step", kInt, "is_completed", kBool, "status", kText),
+	schemaTable("document",
+		"id", kUUID, "user_id", kUUID, "filing_id", kUUID, "name", kText, "file_path", kText, "type", kText,
+		"created_at", kText, "updated_at", kText),
+	schemaTable("property",
+		"id", kUUID, "user_id", kUUID, "address1", kText, "address2", kText, "state", kText, "city", kText,
+		"zipcode", kText, "purchase_price", kNum, "closing_cost", kNum, "purchase_date", kText,
+		"rents", kNum, "royalties", kNum, "updated_at", kText, "created_at", kText),
+	schemaTable("filing_property_map",
+		"filing_id", kUUID, "property_id", kUUID),
+	schemaTable("expense",
+		"id", kUUID, "property_id", kUUID, "name", kText, "amount", kNum, "created_at", kText),
+	schemaTable("ira_contribution",
+		"id", kUUID, "filing_id", kUUID, "account_type", kText, "amount", kNum),
+	schemaTable("charity",
+		"id", kUUID, "user_id", kUUID, "filing_id", kUUID, "name", kText, "contribution", kNum),
+	schemaTable("childcare",
+		"id", kUUID, "user_id", kUUID, "name", kText, "amount", kNum, "tax_id", kText, "address1", kText,
+		"address2", kText, "city", kText, "state", kText, "zipcode", kText),
+	schemaTable("filing_childcare_map",
+		"filing_id", kUUID, "childcare_id", kUUID),
+	schemaTable("payment",
+		"id", kUUID, "filing_id", kUUID, "stripe_session_id", kText, "amount", kNum, "original_amount", kNum,
+		"discount_amount", kNum, "discount_code", kText, "status", kText, "created_at", kText, "updated_at", kText),
+	schemaTable("payment_item",
+		"id", kUUID, "payment_id", kUUID, "price_id", kText, "name", kText, "quantity", kInt, "unit_amount", kNum),
+	schemaTable("discount_codes",
+		"id", kUUID, "code", kText, "description", kText, "discount_type", kText, "discount_value", kNum,
+		"max_uses", kInt, "current_uses", kInt, "valid_from", kText, "valid_until", kText, "is_active", kBool,
+		"is_affiliate_code", kBool, "affiliate_id", kUUID, "commission_rate", kNum, "created_at", kText,
+		"updated_at", kText, "campaign", kText),
+	schemaTable("filing_discounts",
+		"id", kUUID, "filing_id", kUUID, "discount_code_id", kUUID, "original_amount", kNum,
+		"discount_amount", kNum, "final_amount", kNum, "applied_at", kText),
+	schemaTable("affiliates",
+		"id", kUUID, "first_name", kText, "last_name", kText, "email", kText, "phone", kText,
+		"default_commission_rate", kNum, "stripe_connect_account_id", kText, "payout_method", kText,
+		"payout_threshold", kNum, "is_active", kBool, "created_at", kTime, "updated_at", kTime),
+	schemaTable("commissions",
+		"id", kUUID, "affiliate_id", kUUID, "filing_id", kUUID, "user_id", kUUID, "discount_code_id", kUUID,
+		"payment_id", kUUID, "order_amount", kNum, "discount_amount", kNum, "net_amount", kNum,
+		"commission_rate", kNum, "commission_amount", kNum, "status", kText, "approved_at", kTime,
+		"paid_at", kTime, "notes", kText, "created_at", kTime, "updated_at", kTime, "payout_id", kUUID),
+	schemaTable("affiliate_clicks",
+		"affiliate_id", kUUID, "ip_address", kText, "user_agent", kText, "referrer", kText,
+		"landing_url", kText, "signed", kBool, "utm_source", kText, "utm_medium", kText, "utm_campaign", kText,
+		"utm_term", kText, "utm_content", kText, "created_at", kTime),
+	schemaTable("affiliate_click_rollups",
+		"affiliate_id", kUUID, "click_date", kTime, "utm_campaign", kText, "clicks", kInt, "signed_clicks", kInt),
+	schemaTable("affiliate_payout_batches",
+		"id", kUUID, "total_amount", kNum, "created_by", kText, "created_at", kTime),
+	schemaTable("affiliate_payouts",
+		"id", kUUID, "batch_id", kUUID, "affiliate_id", kUUID, "amount", kNum, "commission_count", kInt,
+		"payout_method", kText, "status", kText, "attempts", kInt, "transfer_id", kText, "reference", kText,
+		"paid_amount", kNum, "error", kText, "paid_by", kText, "created_at", kTime, "paid_at", kTime, "updated_at", kTime),
+	schemaTable("affiliate_payout_payments",
+		"id", kUUID, "payout_id", kUUID, "affiliate_id", kUUID, "method", kText, "reference", kText,
+		"amount", kNum, "paid_by", kText, "paid_at", kTime),
+	schemaTable("state_filing",
+		"id", kUUID, "filing_id", kUUID, "state", kText, "residency_type", kText, "status", kText,
+		"fee", kNum, "created_at", kText, "updated_at", kText),
+	schemaTable("filing_result",
+		"filing_id", kUUID, "agi", kNum, "total_tax", kNum, "federal_withholding", kNum, "federal_refund", kNum,
+		"federal_owed", kNum, "state_tax", kNum, "state_refund", kNum, "state_owed", kNum,
+		"recorded_by", kText, "created_at", kText, "updated_at", kText),
+	schemaTable("refund_tracking",
+		"id", kUUID, "filing_id", kUUID, "jurisdiction", kText, "expected_amount", kNum, "status", kText,
+		"accepted_date", kTime, "deposit_window_start", kTime, "deposit_window_end", kTime, "source", kText,
+		"note", kText, "updated_by", kText, "created_at", kText, "updated_at", kText),
+}
+
+// ExpectedSchema returns the tables and columns the MyWellTax adapter depends on
+func (a *MyWellTaxAdapter) ExpectedSchema() []types.SchemaTable {
+	return myWellTaxSchema
+}
+
+// GetSchemaColumns introspects the tenant schema, mapping each table to its columns' data types
+func (a *MyWellTaxAdapter) GetSchemaColumns(ctx context.Context, db *sql.DB, schemaPrefix string) (map[string]map[string]string, error) {
+	rows, err := db.QueryContext(ctx, `
+		SELECT table_name, column_name, data_type
+		FROM information_schema.columns
+		WHERE table_schema = $1
+	`, schemaPrefix)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to introspect schema %s: %v", schemaPrefix, err)
+		return nil, fmt.Errorf("failed to introspect schema: %w", err)
+	}
+	defer rows.Close()
+
+	tables := map[string]map[string]string{}
+	for rows.Next() {
+		var table, column, dataType string
+		if err := rows.Scan(&table, &column, &dataType); err != nil {
+			return nil, fmt.Errorf("failed to scan schema column: %w", err)
+		}
+		if tables[table] == nil {
+			tables[table] = map[string]string{}
+		}
+		tables[table][column] = dataType
+	}
+
+	return tables, rows.Err()
+}
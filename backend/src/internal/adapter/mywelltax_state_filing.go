@@ -0,0 +1,179 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/sqlident"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// GetStateFilings retrieves all state returns attached to a filing
+func (a *MyWellTaxAdapter) GetStateFilings(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) ([]*types.StateFiling, error) {
+	query := fmt.Sprintf(`
+		SELECT id, filing_id, state, residency_type, status, fee, created_at, updated_at
+		FROM %s.state_filing
+		WHERE filing_id = $1
+		ORDER BY state
+	`, sqlident.Schema(schemaPrefix))
+
+	rows, err := db.QueryContext(ctx, query, filingID)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to query state filings for %s: %v", filingID, err)
+		return nil, fmt.Errorf("failed to query state filings: %w", err)
+	}
+	defer rows.Close()
+
+	var stateFilings []*types.StateFiling
+	for rows.Next() {
+		sf := &types.StateFiling{}
+		if err := rows.Scan(&sf.ID, &sf.FilingID, &sf.State, &sf.ResidencyType, &sf.Status, &sf.Fee, &sf.CreatedAt, &sf.UpdatedAt); err != nil {
+			logger.Errorf("MyWellTax adapter failed to scan state filing row: %v", err)
+			return nil, fmt.Errorf("failed to scan state filing: %w", err)
+		}
+		stateFilings = append(stateFilings, sf)
+	}
+
+	return stateFilings, rows.Err()
+}
+
+// GetStateFilingByID retrieves a specific state return
+func (a *MyWellTaxAdapter) GetStateFilingByID(ctx context.Context, db *sql.DB, schemaPrefix string, stateFilingID string) (*types.StateFiling, error) {
+	query := fmt.Sprintf(`
+		SELECT id, filing_id, state, residency_type, status, fee, created_at, updated_at
+		FROM %s.state_filing
+		WHERE id = $1
+	`, sqlident.Schema(schemaPrefix))
+
+	sf := &types.StateFiling{}
+	err := db.QueryRowContext(ctx, query, stateFilingID).Scan(&sf.ID, &sf.FilingID, &sf.State, &sf.ResidencyType, &sf.Status, &sf.Fee, &sf.CreatedAt, &sf.UpdatedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperr.NotFound("state filing not found")
+		}
+		logger.Errorf("MyWellTax adapter failed to get state filing %s: %v", stateFilingID, err)
+		return nil, fmt.Errorf("failed to get state filing: %w", err)
+	}
+
+	return sf, nil
+}
+
+// CreateStateFiling adds a state return to a filing
+func (a *MyWellTaxAdapter) CreateStateFiling(ctx context.Context, db *sql.DB, schemaPrefix string, stateFiling *types.StateFiling) (*types.StateFiling, error) {
+	query := fmt.Sprintf(`
+		INSERT INTO %s.state_filing (id, filing_id, state, residency_type, status, fee)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`, sqlident.Schema(schemaPrefix))
+
+	logger.Infof("MyWellTax adapter creating %s state filing for filing %s", stateFiling.State, stateFiling.FilingID)
+
+	err := db.QueryRowContext(
+		ctx,
+		query,
+		a.newID(),
+		stateFiling.FilingID,
+		stateFiling.State,
+		stateFiling.ResidencyType,
+		stateFiling.Status,
+		stateFiling.Fee,
+	).Scan(&stateFiling.ID, &stateFiling.CreatedAt, &stateFiling.UpdatedAt)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to create state filing: %v", err)
+		return nil, fmt.Errorf("failed to create state filing: %w", err)
+	}
+
+	return stateFiling, nil
+}
+
+// UpdateStateFiling updates residency, status and fee of a state return
+func (a *MyWellTaxAdapter) UpdateStateFiling(ctx context.Context, db *sql.DB, schemaPrefix string, stateFilingID string, stateFiling *types.StateFiling) (*types.StateFiling, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.state_filing
+		SET residency_type = $2, status = $3, fee = $4, updated_at = NOW()
+		WHERE id = $1
+		RETURNING id, filing_id, state, residency_type, status, fee, created_at, updated_at
+	`, sqlident.Schema(schemaPrefix))
+
+	logger.Infof("MyWellTax adapter updating state filing %s", stateFilingID)
+
+	sf := &types.StateFiling{}
+	err := db.QueryRowContext(ctx, query, stateFilingID, stateFiling.ResidencyType, stateFiling.Status, stateFiling.Fee).Scan(
+		&sf.ID, &sf.FilingID, &sf.State, &sf.ResidencyType, &sf.Status, &sf.Fee, &sf.CreatedAt, &sf.UpdatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, apperr.NotFound("state filing not found")
+		}
+		logger.Errorf("MyWellTax adapter failed to update state filing %s: %v", stateFilingID, err)
+		return nil, fmt.Errorf("failed to update state filing: %w", err)
+	}
+
+	return sf, nil
+}
+
+// DeleteStateFiling removes a state return from a filing
+func (a *MyWellTaxAdapter) DeleteStateFiling(ctx context.Context, db *sql.DB, schemaPrefix string, stateFilingID string) error {
+	query := fmt.Sprintf(`DELETE FROM %s.state_filing WHERE id = $1`, sqlident.Schema(schemaPrefix))
+
+	result, err := db.ExecContext(ctx, query, stateFilingID)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to delete state filing %s: %v", stateFilingID, err)
+		return fmt.Errorf("failed to delete state filing: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to delete state filing: %w", err)
+	}
+	if rows == 0 {
+		return apperr.NotFound("state filing not found")
+	}
+
+	return nil
+}
+
+// GetStateFilingReport aggregates state return volume, optionally for a single tax year
+func (a *MyWellTaxAdapter) GetStateFilingReport(ctx context.Context, db *sql.DB, schemaPrefix string, year *int) ([]*types.StateFilingReport, error) {
+	query := fmt.Sprintf(`
+		SELECT sf.state, sf.status, COUNT(*), COALESCE(SUM(sf.fee), 0)
+		FROM %s.state_filing sf
+		JOIN %s.filing f ON f.id = sf.filing_id
+		WHERE ($1::int IS NULL OR f.year = $1)
+		GROUP BY sf.state, sf.status
+		ORDER BY sf.state
+	`, sqlident.Schema(schemaPrefix), sqlident.Schema(schemaPrefix))
+
+	rows, err := db.QueryContext(ctx, query, year)
+	if err != nil {
+		logger.Errorf("MyWellTax adapter failed to query state filing report: %v", err)
+		return nil, fmt.Errorf("failed to query state filing report: %w", err)
+	}
+	defer rows.Close()
+
+	var reports []*types.StateFilingReport
+	byState := map[string]*types.StateFilingReport{}
+	for rows.Next() {
+		var state, status string
+		var count int
+		var fees float64
+		if err := rows.Scan(&state, &status, &count, &fees); err != nil {
+			return nil, fmt.Errorf("failed to scan state filing report: %w", err)
+		}
+
+		report, ok := byState[state]
+		if !ok {
+			report = &types.StateFilingReport{State: state, ByStatus: map[string]int{}}
+			byState[state] = report
+			reports = append(reports, report)
+		}
+		report.Total += count
+		report.ByStatus[status] += count
+		report.TotalFees += fees
+	}
+
+	return reports, rows.Err()
+}
@@ -0,0 +1,24 @@
+package adapter
+
+import (
+	"fmt"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// keysetCondition restricts a newest-first listing to the rows after the page cursor,
+// appending the cursor values to args. It returns an empty condition for the first page.
+func keysetCondition(page types.PageRequest, createdAtColumn string, idColumn string, args []interface{}) (string, []interface{}) {
+	if page.After == nil {
+		return "", args
+	}
+	condition := fmt.Sprintf("(%s, %s) < ($%d, $%d)", createdAtColumn, idColumn, len(args)+1, len(args)+2)
+	return condition, append(args, page.After.CreatedAt, page.After.ID)
+}
+
+// nextPageCursor returns the encoded cursor following the last row of a full page
+func nextPageCursor(createdAt string, id uuid.UUID) *string {
+	cursor := types.PageCursor{CreatedAt: createdAt, ID: id.String()}.Encode()
+	return &cursor
+}
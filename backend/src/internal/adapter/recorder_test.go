@@ -0,0 +1,82 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+)
+
+// recordedQuery is one statement a recordingConn received
+type recordedQuery struct {
+	query string
+	args  []driver.Value
+}
+
+// recordingConn records the statements it is sent and answers every query with rows
+type recordingConn struct {
+	mu      sync.Mutex
+	queries []recordedQuery
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (c *recordingConn) Prepare(query string) (driver.Stmt, error) { return nil, driver.ErrSkip }
+func (c *recordingConn) Close() error                              { return nil }
+func (c *recordingConn) Begin() (driver.Tx, error)                 { return nil, driver.ErrSkip }
+
+func (c *recordingConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	values := make([]driver.Value, len(args))
+	for i, arg := range args {
+		values[i] = arg.Value
+	}
+	c.queries = append(c.queries, recordedQuery{query: query, args: values})
+	return &recordedRows{columns: c.columns, rows: c.rows}, nil
+}
+
+// lastQuery returns the most recent statement the connection received
+func (c *recordingConn) lastQuery(t *testing.T) recordedQuery {
+	t.Helper()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.queries) == 0 {
+		t.Fatal("no query was sent")
+	}
+	return c.queries[len(c.queries)-1]
+}
+
+type recordedRows struct {
+	columns []string
+	rows    [][]driver.Value
+}
+
+func (r *recordedRows) Columns() []string { return r.columns }
+func (r *recordedRows) Close() error      { return nil }
+
+func (r *recordedRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.rows[0])
+	r.rows = r.rows[1:]
+	return nil
+}
+
+type recordingConnector struct{ conn *recordingConn }
+
+func (c recordingConnector) Connect(ctx context.Context) (driver.Conn, error) { return c.conn, nil }
+func (c recordingConnector) Driver() driver.Driver                            { return nil }
+
+// newRecordingDB returns a database whose queries are recorded by the returned connection and
+// answered with columns and rows
+func newRecordingDB(t *testing.T, columns []string, rows ...[]driver.Value) (*sql.DB, *recordingConn) {
+	t.Helper()
+	conn := &recordingConn{columns: columns, rows: rows}
+	db := sql.OpenDB(recordingConnector{conn: conn})
+	t.Cleanup(func() { db.Close() })
+	return db, conn
+}
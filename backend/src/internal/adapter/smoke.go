@@ -0,0 +1,501 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+	"sync"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// SmokeAdapter implements ClientAdapter in memory for the built-in smoke tenant (types.SmokeTenantID).
+// It holds one seeded client with one filing and keeps documents in memory, which covers the
+// requests the smoke test makes; every other operation fails with a validation error.
+// The db argument is always nil and ignored.
+type SmokeAdapter struct {
+	mu        sync.Mutex
+	clients   []*types.Client
+	documents map[uuid.UUID]*types.Document
+}
+
+// smoke is shared by every adapter lookup so documents persist between requests
+var smoke = newSmokeAdapter()
+
+// newSmokeAdapter creates a smoke adapter with its seeded client
+func newSmokeAdapter() *SmokeAdapter {
+	firstName, lastName := "Smoke", "Test"
+	return &SmokeAdapter{
+		clients: []*types.Client{{
+			ID:        types.SmokeClientID,
+			Email:     "client@" + types.SmokeEmailDomain,
+			Role:      "user",
+			CreatedAt: "2024-01-01 00:00:00",
+			FirstName: &firstName,
+			LastName:  &lastName,
+		}},
+		documents: map[uuid.UUID]*types.Document{},
+	}
+}
+
+// unsupported is returned by operations the smoke tenant does not implement
+func unsupported(operation string) error {
+	return apperr.Validation("%s is not supported by the smoke tenant", operation)
+}
+
+// GetAdapterType returns the unique identifier for this adapter
+func (a *SmokeAdapter) GetAdapterType() string {
+	return types.SmokeAdapterType
+}
+
+// GetClients returns the seeded client
+func (a *SmokeAdapter) GetClients(ctx context.Context, db *sql.DB, schemaPrefix string, includeArchived bool) ([]*types.Client, error) {
+	return a.clients, nil
+}
+
+// GetClientPage returns the seeded client as a single page
+func (a *SmokeAdapter) GetClientPage(ctx context.Context, db *sql.DB, schemaPrefix string, includeArchived bool, page types.PageRequest) (*types.ClientPage, error) {
+	result := &types.ClientPage{Items: []*types.Client{}, PageInfo: types.PageInfo{TotalCount: len(a.clients)}}
+	if page.After == nil {
+		result.Items = a.clients
+	}
+	return result, nil
+}
+
+// StreamClients passes the seeded client to fn
+func (a *SmokeAdapter) StreamClients(ctx context.Context, db *sql.DB, schemaPrefix string, includeArchived bool, fn func(*types.Client) error) error {
+	for _, client := range a.clients {
+		if err := fn(client); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetClientByID returns the seeded client
+func (a *SmokeAdapter) GetClientByID(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (*types.Client, error) {
+	for _, client := range a.clients {
+		if client.ID.String() == clientID {
+			return client, nil
+		}
+	}
+	return nil, apperr.NotFound("client not found")
+}
+
+// IsClientArchived reports false for the seeded client
+func (a *SmokeAdapter) IsClientArchived(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (bool, error) {
+	if _, err := a.GetClientByID(ctx, db, schemaPrefix, clientID); err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// GetSSNRecords returns no records; the smoke client has no SSN
+func (a *SmokeAdapter) GetSSNRecords(ctx context.Context, db *sql.DB, schemaPrefix string) ([]*types.SSNRecord, error) {
+	return []*types.SSNRecord{}, nil
+}
+
+// ReencryptSSNs rewrites nothing; the smoke client has no SSN
+func (a *SmokeAdapter) ReencryptSSNs(ctx context.Context, db *sql.DB, schemaPrefix string, reencrypt ReencryptSSN) (int, error) {
+	return 0, nil
+}
+
+// CountFilings counts the seeded filing, which is never completed
+func (a *SmokeAdapter) CountFilings(ctx context.Context, db *sql.DB, schemaPrefix string, year int) (int, int, error) {
+	return 1, 0, nil
+}
+
+// CountOpenFilings counts the seeded filing, which is never completed
+func (a *SmokeAdapter) CountOpenFilings(ctx context.Context, db *sql.DB, schemaPrefix string) (int, error) {
+	return 1, nil
+}
+
+// GetFilingYear returns the year of the seeded filing
+func (a *SmokeAdapter) GetFilingYear(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) (int, error) {
+	if filingID != types.SmokeFilingID.String() {
+		return 0, apperr.NotFound("filing not found")
+	}
+	return types.SmokeFilingYear, nil
+}
+
+// CreateDocument stores a document record in memory
+func (a *SmokeAdapter) CreateDocument(ctx context.Context, db *sql.DB, schemaPrefix string, document *types.Document) (*types.Document, error) {
+	if document.FilingID == nil || *document.FilingID != types.SmokeFilingID {
+		return nil, apperr.NotFound("filing not found")
+	}
+	if document.ID == uuid.Nil {
+		document.ID = uuid.New()
+	}
+	document.CreatedAt = time.Now().UTC().Format("2006-01-02 15:04:05")
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stored := *document
+	a.documents[document.ID] = &stored
+	return document, nil
+}
+
+// GetDocumentByID returns a document stored in memory
+func (a *SmokeAdapter) GetDocumentByID(ctx context.Context, db *sql.DB, schemaPrefix string, documentID string) (*types.Document, error) {
+	id, err := uuid.Parse(documentID)
+	if err != nil {
+		return nil, apperr.NotFound("document not found")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	document, ok := a.documents[id]
+	if !ok {
+		return nil, apperr.NotFound("document not found")
+	}
+	found := *document
+	return &found, nil
+}
+
+// GetDocumentsByFilingID returns the documents stored in memory for a filing, oldest first
+func (a *SmokeAdapter) GetDocumentsByFilingID(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) ([]*types.Document, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	documents := []*types.Document{}
+	for _, document := range a.documents {
+		if document.FilingID != nil && document.FilingID.String() == filingID {
+			found := *document
+			documents = append(documents, &found)
+		}
+	}
+	sort.Slice(documents, func(i, j int) bool { return documents[i].CreatedAt < documents[j].CreatedAt })
+	return documents, nil
+}
+
+// GetDocumentsByYear returns the documents stored in memory when year is the seeded filing's
+func (a *SmokeAdapter) GetDocumentsByYear(ctx context.Context, db *sql.DB, schemaPrefix string, year int) ([]*types.Document, error) {
+	if year != types.SmokeFilingYear {
+		return []*types.Document{}, nil
+	}
+	return a.GetDocumentsByFilingID(ctx, db, schemaPrefix, types.SmokeFilingID.String())
+}
+
+// DeleteDocument removes a document stored in memory
+func (a *SmokeAdapter) DeleteDocument(ctx context.Context, db *sql.DB, schemaPrefix string, documentID string) error {
+	id, err := uuid.Parse(documentID)
+	if err != nil {
+		return apperr.NotFound("document not found")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, ok := a.documents[id]; !ok {
+		return apperr.NotFound("document not found")
+	}
+	delete(a.documents, id)
+	return nil
+}
+
+// StreamDocuments calls fn with each document stored in memory, oldest first
+func (a *SmokeAdapter) StreamDocuments(ctx context.Context, db *sql.DB, schemaPrefix string, fn func(*types.Document) error) error {
+	a.mu.Lock()
+	documents := make([]*types.Document, 0, len(a.documents))
+	for _, document := range a.documents {
+		found := *document
+		documents = append(documents, &found)
+	}
+	a.mu.Unlock()
+
+	sort.Slice(documents, func(i, j int) bool { return documents[i].CreatedAt < documents[j].CreatedAt })
+	for _, document := range documents {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(document); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetDocumentPath points a document stored in memory at another file
+func (a *SmokeAdapter) SetDocumentPath(ctx context.Context, db *sql.DB, schemaPrefix string, documentID string, filePath string) error {
+	id, err := uuid.Parse(documentID)
+	if err != nil {
+		return apperr.NotFound("document not found")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	document, ok := a.documents[id]
+	if !ok {
+		return apperr.NotFound("document not found")
+	}
+	document.FilePath = filePath
+	return nil
+}
+
+// ExpectedSchema returns no tables; the smoke tenant has no database
+func (a *SmokeAdapter) ExpectedSchema() []types.SchemaTable {
+	return nil
+}
+
+// GetSchemaColumns returns no columns; the smoke tenant has no database
+func (a *SmokeAdapter) GetSchemaColumns(ctx context.Context, db *sql.DB, schemaPrefix string) (map[string]map[string]string, error) {
+	return map[string]map[string]string{}, nil
+}
+
+// Operations below are not used by the smoke test
+
+func (a *SmokeAdapter) GetFilingWorkflow(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) (*types.FilingWorkflow, error) {
+	return nil, unsupported("GetFilingWorkflow")
+}
+
+func (a *SmokeAdapter) SetFilingStatus(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string, from string, to string) error {
+	return unsupported("SetFilingStatus")
+}
+
+func (a *SmokeAdapter) SearchClients(ctx context.Context, db *sql.DB, schemaPrefix string, query *types.ClientSearchQuery) ([]*types.ClientSearchResult, error) {
+	return nil, unsupported("SearchClients")
+}
+
+func (a *SmokeAdapter) ArchiveClient(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string, reason string) (*types.Client, error) {
+	return nil, unsupported("ArchiveClient")
+}
+
+func (a *SmokeAdapter) UnarchiveClient(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (*types.Client, error) {
+	return nil, unsupported("UnarchiveClient")
+}
+
+func (a *SmokeAdapter) MarkClientDeceased(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string, person string, deathDate string) error {
+	return unsupported("MarkClientDeceased")
+}
+
+func (a *SmokeAdapter) GetClientComprehensive(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) (*types.ClientComprehensive, error) {
+	return nil, unsupported("GetClientComprehensive")
+}
+
+func (a *SmokeAdapter) ExportClientRows(ctx context.Context, db *sql.DB, schemaPrefix string, clientID string) ([]*types.ExportTable, error) {
+	return nil, unsupported("ExportClientRows")
+}
+
+func (a *SmokeAdapter) ImportClientRows(ctx context.Context, db *sql.DB, schemaPrefix string, tables []*types.ExportTable, reencrypt ReencryptSSN) (int, error) {
+	return 0, unsupported("ImportClientRows")
+}
+
+func (a *SmokeAdapter) GetClientsByFilings(ctx context.Context, db *sql.DB, schemaPrefix string, limit int, offset int) ([]*types.ClientComprehensive, error) {
+	return nil, unsupported("GetClientsByFilings")
+}
+
+func (a *SmokeAdapter) GetClientsByFilingsPage(ctx context.Context, db *sql.DB, schemaPrefix string, page types.PageRequest) (*types.FilingPage, error) {
+	return nil, unsupported("GetClientsByFilingsPage")
+}
+
+func (a *SmokeAdapter) StreamClientsByFilings(ctx context.Context, db *sql.DB, schemaPrefix string, fn func(*types.ClientComprehensive) error) error {
+	return unsupported("StreamClientsByFilings")
+}
+
+func (a *SmokeAdapter) GetAffiliates(ctx context.Context, db *sql.DB, schemaPrefix string, activeOnly bool) ([]*types.Affiliate, error) {
+	return nil, unsupported("GetAffiliates")
+}
+
+func (a *SmokeAdapter) GetAffiliateByID(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string) (*types.Affiliate, error) {
+	return nil, unsupported("GetAffiliateByID")
+}
+
+func (a *SmokeAdapter) CreateAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliate *types.Affiliate) (*types.Affiliate, error) {
+	return nil, unsupported("CreateAffiliate")
+}
+
+func (a *SmokeAdapter) UpdateAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, affiliate *types.Affiliate) (*types.Affiliate, error) {
+	return nil, unsupported("UpdateAffiliate")
+}
+
+func (a *SmokeAdapter) PatchAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, patch *types.AffiliatePatch) (*types.Affiliate, error) {
+	return nil, unsupported("PatchAffiliate")
+}
+
+func (a *SmokeAdapter) GetCommissionsByAffiliate(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, status *string, commissionIDs []string, limit int) ([]*types.Commission, error) {
+	return nil, unsupported("GetCommissionsByAffiliate")
+}
+
+func (a *SmokeAdapter) GetCommissionPage(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, status *string, commissionIDs []string, page types.PageRequest) (*types.CommissionPage, error) {
+	return nil, unsupported("GetCommissionPage")
+}
+
+// GetOverdueCommissions finds nothing; the smoke tenant has no commissions
+func (a *SmokeAdapter) GetOverdueCommissions(ctx context.Context, db *sql.DB, schemaPrefix string, createdBefore time.Time, limit int) ([]*types.Commission, int, error) {
+	return nil, 0, nil
+}
+
+func (a *SmokeAdapter) GetAffiliateStats(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string) (*types.AffiliateStats, error) {
+	return nil, unsupported("GetAffiliateStats")
+}
+
+func (a *SmokeAdapter) RecordAffiliateClick(ctx context.Context, db *sql.DB, schemaPrefix string, click *types.AffiliateClick) error {
+	return unsupported("RecordAffiliateClick")
+}
+
+// RollupAffiliateClicks has nothing to do; the smoke tenant records no clicks
+func (a *SmokeAdapter) RollupAffiliateClicks(ctx context.Context, db *sql.DB, schemaPrefix string, cutoff time.Time) (int, error) {
+	return 0, nil
+}
+
+func (a *SmokeAdapter) RecordCheckoutPayment(ctx context.Context, db *sql.DB, schemaPrefix string, payment *types.Payment) (*types.CheckoutPayment, error) {
+	return nil, unsupported("RecordCheckoutPayment")
+}
+
+func (a *SmokeAdapter) CreateCommission(ctx context.Context, db *sql.DB, schemaPrefix string, commission *types.Commission) (*types.Commission, error) {
+	return nil, unsupported("CreateCommission")
+}
+
+func (a *SmokeAdapter) EvaluateCommissionFraud(ctx context.Context, db *sql.DB, schemaPrefix string, commission *types.Commission, ipAddress string, rules *types.FraudRules) ([]types.FraudFlag, error) {
+	return nil, unsupported("EvaluateCommissionFraud")
+}
+
+func (a *SmokeAdapter) ApproveCommission(ctx context.Context, db *sql.DB, schemaPrefix string, commissionID string) (*types.Commission, error) {
+	return nil, unsupported("ApproveCommission")
+}
+
+func (a *SmokeAdapter) ApproveCommissions(ctx context.Context, db *sql.DB, schemaPrefix string, commissionIDs []string, filter *types.CommissionFilter, limit int) (*types.BulkCommissionReport, error) {
+	return nil, unsupported("ApproveCommissions")
+}
+
+func (a *SmokeAdapter) MarkCommissionPaid(ctx context.Context, db *sql.DB, schemaPrefix string, commissionID string, payment *types.PayoutPayment) (*types.AffiliatePayout, []*types.Commission, error) {
+	return nil, nil, unsupported("MarkCommissionPaid")
+}
+
+func (a *SmokeAdapter) CancelCommission(ctx context.Context, db *sql.DB, schemaPrefix string, commissionID string, reason string) (*types.Commission, error) {
+	return nil, unsupported("CancelCommission")
+}
+
+func (a *SmokeAdapter) CreatePayoutBatch(ctx context.Context, db *sql.DB, schemaPrefix string, createdBy string) (*types.PayoutBatch, error) {
+	return nil, unsupported("CreatePayoutBatch")
+}
+
+func (a *SmokeAdapter) GetPayoutBatches(ctx context.Context, db *sql.DB, schemaPrefix string, limit int) ([]*types.PayoutBatch, error) {
+	return nil, unsupported("GetPayoutBatches")
+}
+
+func (a *SmokeAdapter) GetPayoutBatch(ctx context.Context, db *sql.DB, schemaPrefix string, batchID string) (*types.PayoutBatch, error) {
+	return nil, unsupported("GetPayoutBatch")
+}
+
+func (a *SmokeAdapter) GetPayout(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string) (*types.AffiliatePayout, error) {
+	return nil, unsupported("GetPayout")
+}
+
+func (a *SmokeAdapter) GetAffiliatePayments(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, limit int) ([]*types.AffiliatePayoutPayment, error) {
+	return nil, unsupported("GetAffiliatePayments")
+}
+
+func (a *SmokeAdapter) GetAffiliatePaymentTotals(ctx context.Context, db *sql.DB, schemaPrefix string, year int) ([]*types.AffiliatePaymentTotal, error) {
+	return nil, unsupported("GetAffiliatePaymentTotals")
+}
+
+func (a *SmokeAdapter) GetAffiliateStatement(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID string, year int) (*types.AffiliateStatement, error) {
+	return nil, unsupported("GetAffiliateStatement")
+}
+
+func (a *SmokeAdapter) StartPayoutTransfer(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string) (*types.AffiliatePayout, error) {
+	return nil, unsupported("StartPayoutTransfer")
+}
+
+func (a *SmokeAdapter) RecordPayoutFailure(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string, message string, declined bool) error {
+	return unsupported("RecordPayoutFailure")
+}
+
+func (a *SmokeAdapter) MarkPayoutPaid(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string, payment *types.PayoutPayment) (*types.AffiliatePayout, []*types.Commission, error) {
+	return nil, nil, unsupported("MarkPayoutPaid")
+}
+
+func (a *SmokeAdapter) CancelPayout(ctx context.Context, db *sql.DB, schemaPrefix string, payoutID string) (*types.AffiliatePayout, error) {
+	return nil, unsupported("CancelPayout")
+}
+
+func (a *SmokeAdapter) GetDiscountCodes(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, campaign *string, activeOnly bool) ([]*types.DiscountCode, error) {
+	return nil, unsupported("GetDiscountCodes")
+}
+
+func (a *SmokeAdapter) GetDiscountCodePage(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID *string, campaign *string, activeOnly bool, page types.PageRequest) (*types.DiscountCodePage, error) {
+	return nil, unsupported("GetDiscountCodePage")
+}
+
+func (a *SmokeAdapter) GetDiscountCodeByID(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string) (*types.DiscountCode, error) {
+	return nil, unsupported("GetDiscountCodeByID")
+}
+
+func (a *SmokeAdapter) GetDiscountCodeByCode(ctx context.Context, db *sql.DB, schemaPrefix string, code string) (*types.DiscountCode, error) {
+	return nil, unsupported("GetDiscountCodeByCode")
+}
+
+func (a *SmokeAdapter) CreateDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, discountCode *types.DiscountCode) (*types.DiscountCode, error) {
+	return nil, unsupported("CreateDiscountCode")
+}
+
+func (a *SmokeAdapter) CreateDiscountCodes(ctx context.Context, db *sql.DB, schemaPrefix string, discountCodes []*types.DiscountCode) ([]*types.DiscountCode, error) {
+	return nil, unsupported("CreateDiscountCodes")
+}
+
+func (a *SmokeAdapter) GetDiscountCampaignReports(ctx context.Context, db *sql.DB, schemaPrefix string, campaign *string) ([]*types.DiscountCampaignReport, error) {
+	return nil, unsupported("GetDiscountCampaignReports")
+}
+
+func (a *SmokeAdapter) UpdateDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string, discountCode *types.DiscountCode) (*types.DiscountCode, error) {
+	return nil, unsupported("UpdateDiscountCode")
+}
+
+func (a *SmokeAdapter) PatchDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string, patch *types.DiscountCodePatch) (*types.DiscountCode, error) {
+	return nil, unsupported("PatchDiscountCode")
+}
+
+func (a *SmokeAdapter) DeactivateDiscountCode(ctx context.Context, db *sql.DB, schemaPrefix string, codeID string) error {
+	return unsupported("DeactivateDiscountCode")
+}
+
+func (a *SmokeAdapter) GetCampaignMetrics(ctx context.Context, db *sql.DB, schemaPrefix string, keys []string) (map[string]*types.CampaignMetrics, error) {
+	return nil, unsupported("GetCampaignMetrics")
+}
+
+func (a *SmokeAdapter) GetStateFilings(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) ([]*types.StateFiling, error) {
+	return nil, unsupported("GetStateFilings")
+}
+
+func (a *SmokeAdapter) GetStateFilingByID(ctx context.Context, db *sql.DB, schemaPrefix string, stateFilingID string) (*types.StateFiling, error) {
+	return nil, unsupported("GetStateFilingByID")
+}
+
+func (a *SmokeAdapter) CreateStateFiling(ctx context.Context, db *sql.DB, schemaPrefix string, stateFiling *types.StateFiling) (*types.StateFiling, error) {
+	return nil, unsupported("CreateStateFiling")
+}
+
+func (a *SmokeAdapter) UpdateStateFiling(ctx context.Context, db *sql.DB, schemaPrefix string, stateFilingID string, stateFiling *types.StateFiling) (*types.StateFiling, error) {
+	return nil, unsupported("UpdateStateFiling")
+}
+
+func (a *SmokeAdapter) DeleteStateFiling(ctx context.Context, db *sql.DB, schemaPrefix string, stateFilingID string) error {
+	return unsupported("DeleteStateFiling")
+}
+
+func (a *SmokeAdapter) GetStateFilingReport(ctx context.Context, db *sql.DB, schemaPrefix string, year *int) ([]*types.StateFilingReport, error) {
+	return nil, unsupported("GetStateFilingReport")
+}
+
+func (a *SmokeAdapter) GetFilingResult(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) (*types.FilingResult, error) {
+	return nil, unsupported("GetFilingResult")
+}
+
+func (a *SmokeAdapter) UpsertFilingResult(ctx context.Context, db *sql.DB, schemaPrefix string, result *types.FilingResult) (*types.FilingResult, error) {
+	return nil, unsupported("UpsertFilingResult")
+}
+
+func (a *SmokeAdapter) GetRefundTrackings(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string) ([]*types.RefundTracking, error) {
+	return nil, unsupported("GetRefundTrackings")
+}
+
+func (a *SmokeAdapter) UpsertRefundTracking(ctx context.Context, db *sql.DB, schemaPrefix string, tracking *types.RefundTracking) (*types.RefundTracking, error) {
+	return nil, unsupported("UpsertRefundTracking")
+}
+
+func (a *SmokeAdapter) DeleteRefundTracking(ctx context.Context, db *sql.DB, schemaPrefix string, filingID string, jurisdiction string) error {
+	return unsupported("DeleteRefundTracking")
+}
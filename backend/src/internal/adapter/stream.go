@@ -0,0 +1,51 @@
+package adapter
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// queryClientIDs runs a query selecting one client ID per row
+func queryClientIDs(ctx context.Context, db *sql.DB, query string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query client IDs: %w", err)
+	}
+	defer rows.Close()
+
+	var clientIDs []string
+	for rows.Next() {
+		var clientID string
+		if err := rows.Scan(&clientID); err != nil {
+			return nil, fmt.Errorf("failed to scan client ID: %w", err)
+		}
+		clientIDs = append(clientIDs, clientID)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating client IDs: %w", err)
+	}
+	return clientIDs, nil
+}
+
+// streamComprehensiveClients loads each client's comprehensive data in turn and passes it to fn.
+// Clients that fail to load are skipped; the stream stops once ctx is done.
+func streamComprehensiveClients(ctx context.Context, clientIDs []string, load func(clientID string) (*types.ClientComprehensive, error), fn func(*types.ClientComprehensive) error) error {
+	for _, clientID := range clientIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		comprehensive, err := load(clientID)
+		if err != nil {
+			logger.Warningf("Failed to get comprehensive data for client %s: %v", clientID, err)
+			continue
+		}
+		if err := fn(comprehensive); err != nil {
+			return err
+		}
+	}
+	return nil
+}
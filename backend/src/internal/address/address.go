@@ -0,0 +1,90 @@
+package address
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// Validator defines the interface for address standardization providers
+type Validator interface {
+	// Validate standardizes an address and reports whether it is deliverable
+	Validate(ctx context.Context, addr types.Address) (*types.AddressValidation, error)
+
+	// Name returns the provider identifier stored with each validation
+	Name() string
+}
+
+// Config selects and configures the address validation provider
+type Config struct {
+	Provider  string // "smarty" or empty for basic normalization only
+	AuthID    string
+	AuthToken string
+}
+
+// NewValidator creates the configured address validator
+// Falls back to basic normalization when no provider is configured
+func NewValidator(cfg Config) Validator {
+	switch cfg.Provider {
+	case "smarty", "smartystreets":
+		if cfg.AuthID == "" || cfg.AuthToken == "" {
+			logger.Warning("SmartyStreets address validation configured without credentials, using basic normalization")
+			return &BasicValidator{}
+		}
+		return NewSmartyValidator(cfg.AuthID, cfg.AuthToken)
+	default:
+		return &BasicValidator{}
+	}
+}
+
+var zipPattern = regexp.MustCompile(`^(\d{5})(?:-?(\d{4}))?$`)
+
+// BasicValidator normalizes casing, whitespace and ZIP format without an external lookup.
+// It cannot confirm deliverability, so only structurally complete addresses are
+// reported as deliverable.
+type BasicValidator struct{}
+
+// Name returns the provider identifier
+func (v *BasicValidator) Name() string {
+	return "basic"
+}
+
+// Validate normalizes the address components
+func (v *BasicValidator) Validate(ctx context.Context, addr types.Address) (*types.AddressValidation, error) {
+	std := &types.Address{
+		Line1: normalizeLine(addr.Line1),
+		Line2: normalizeLine(addr.Line2),
+		City:  normalizeLine(addr.City),
+		State: strings.ToUpper(strings.TrimSpace(addr.State)),
+	}
+
+	var footnotes []string
+	if m := zipPattern.FindStringSubmatch(strings.TrimSpace(addr.Zipcode)); m != nil {
+		std.Zipcode = m[1]
+		std.Plus4 = m[2]
+	} else {
+		footnotes = append(footnotes, "INVALID_ZIP")
+	}
+	if len(std.State) != 2 {
+		footnotes = append(footnotes, "INVALID_STATE")
+	}
+	if std.Line1 == "" || std.City == "" {
+		footnotes = append(footnotes, "MISSING_COMPONENTS")
+	}
+
+	return &types.AddressValidation{
+		Original:     addr,
+		Standardized: std,
+		Deliverable:  len(footnotes) == 0,
+		Provider:     v.Name(),
+		Footnotes:    footnotes,
+	}, nil
+}
+
+// normalizeLine collapses whitespace and upper-cases an address line (USPS style)
+func normalizeLine(s string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(s), " "))
+}
@@ -0,0 +1,119 @@
+package address
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+const smartyStreetURL = "https://us-street.api.smarty.com/street-address"
+
+// SmartyValidator implements Validator using the SmartyStreets US Street API
+type SmartyValidator struct {
+	authID    string
+	authToken string
+	client    *http.Client
+}
+
+// NewSmartyValidator creates a SmartyStreets validator
+func NewSmartyValidator(authID, authToken string) *SmartyValidator {
+	return &SmartyValidator{
+		authID:    authID,
+		authToken: authToken,
+		client:    &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the provider identifier
+func (v *SmartyValidator) Name() string {
+	return "smarty"
+}
+
+type smartyCandidate struct {
+	DeliveryLine1 string `json:"delivery_line_1"`
+	DeliveryLine2 string `json:"delivery_line_2"`
+	Components    struct {
+		CityName          string `json:"city_name"`
+		StateAbbreviation string `json:"state_abbreviation"`
+		Zipcode           string `json:"zipcode"`
+		Plus4Code         string `json:"plus4_code"`
+	} `json:"components"`
+	Analysis struct {
+		DPVMatchCode string `json:"dpv_match_code"` // Y confirmed, S/D partially confirmed, N not deliverable
+		DPVFootnotes string `json:"dpv_footnotes"`
+		DPVVacant    string `json:"dpv_vacant"`
+	} `json:"analysis"`
+}
+
+// Validate looks up the address and returns the standardized USPS form
+func (v *SmartyValidator) Validate(ctx context.Context, addr types.Address) (*types.AddressValidation, error) {
+	params := url.Values{}
+	params.Set("auth-id", v.authID)
+	params.Set("auth-token", v.authToken)
+	params.Set("street", addr.Line1)
+	params.Set("secondary", addr.Line2)
+	params.Set("city", addr.City)
+	params.Set("state", addr.State)
+	params.Set("zipcode", addr.Zipcode)
+	params.Set("candidates", "1")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, smartyStreetURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build address request: %w", err)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		logger.Errorf("SmartyStreets request failed: %v", err)
+		return nil, fmt.Errorf("address provider request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("address provider returned status %d", resp.StatusCode)
+	}
+
+	var candidates []smartyCandidate
+	if err := json.NewDecoder(resp.Body).Decode(&candidates); err != nil {
+		return nil, fmt.Errorf("failed to decode address response: %w", err)
+	}
+
+	result := &types.AddressValidation{
+		Original: addr,
+		Provider: v.Name(),
+	}
+
+	// No candidates means the address could not be matched at all
+	if len(candidates) == 0 {
+		result.Footnotes = []string{"NO_MATCH"}
+		return result, nil
+	}
+
+	c := candidates[0]
+	result.Standardized = &types.Address{
+		Line1:   c.DeliveryLine1,
+		Line2:   c.DeliveryLine2,
+		City:    c.Components.CityName,
+		State:   c.Components.StateAbbreviation,
+		Zipcode: c.Components.Zipcode,
+		Plus4:   c.Components.Plus4Code,
+	}
+	result.Deliverable = c.Analysis.DPVMatchCode == "Y" || c.Analysis.DPVMatchCode == "S" || c.Analysis.DPVMatchCode == "D"
+
+	// DPV footnotes are two-character codes concatenated together
+	for i := 0; i+2 <= len(c.Analysis.DPVFootnotes); i += 2 {
+		result.Footnotes = append(result.Footnotes, c.Analysis.DPVFootnotes[i:i+2])
+	}
+	if strings.EqualFold(c.Analysis.DPVVacant, "Y") {
+		result.Footnotes = append(result.Footnotes, "VACANT")
+	}
+
+	return result, nil
+}
@@ -0,0 +1,249 @@
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// Anonymizer replaces PII with deterministic fakes. The same value always maps to the same
+// fake under one salt, so a client's name matches across tables and repeated exports, while
+// the salt keeps anyone without it from confirming a guess against the output.
+type Anonymizer struct {
+	key []byte
+}
+
+// New creates an anonymizer keyed by salt
+func New(salt string) *Anonymizer {
+	return &Anonymizer{key: []byte(salt)}
+}
+
+// Export anonymizes every PII column of an export in place
+func (a *Anonymizer) Export(export *types.ClientExport) error {
+	for _, table := range export.Tables {
+		for i, column := range table.Columns {
+			kind, ok := table.PII[column]
+			if !ok {
+				continue
+			}
+			for _, row := range table.Rows {
+				if i >= len(row) || row[i] == nil {
+					continue
+				}
+				value, err := a.Value(kind, *row[i])
+				if err != nil {
+					return fmt.Errorf("failed to anonymize %s.%s: %w", table.Name, column, err)
+				}
+				row[i] = &value
+			}
+		}
+	}
+	return nil
+}
+
+// Value returns the fake for one value of the given PII kind; empty values stay empty
+func (a *Anonymizer) Value(kind string, value string) (string, error) {
+	if strings.TrimSpace(value) == "" {
+		return value, nil
+	}
+
+	switch kind {
+	case types.PIIFirstName, types.PIIMiddleName:
+		return pick(givenNames, a.sum("given", normalize(value))), nil
+	case types.PIILastName:
+		return pick(familyNames, a.sum("family", normalize(value))), nil
+	case types.PIIName:
+		return pick(familyNames, a.sum("business", normalize(value))) + " Services", nil
+	case types.PIIEmail:
+		return "client-" + a.hex("email", normalize(value), 10) + "@example.test", nil
+	case types.PIIPhone:
+		return a.digits("phone", value), nil
+	case types.PIISSN, types.PIITaxID:
+		if crypto.IsEncryptedSSN(value) {
+			plain, err := crypto.DecryptSSN(value)
+			if err != nil {
+				return "", err
+			}
+			value = plain
+		}
+		return a.ssn(value), nil
+	case types.PIIBirthDate:
+		return a.date(value), nil
+	case types.PIIStreet:
+		sum := a.sum("street", normalize(value))
+		return fmt.Sprintf("%d %s", 100+sum%9900, pick(streetNames, sum>>16)), nil
+	case types.PIICity:
+		return pick(cityNames, a.sum("city", normalize(value))), nil
+	case types.PIIZipcode:
+		return a.zipcode(value), nil
+	case types.PIIFileName:
+		return a.fileName(value), nil
+	case types.PIIFilePath:
+		return a.filePath(value), nil
+	case types.PIIReference:
+		return "ref-" + a.hex("reference", value, 16), nil
+	case types.PIIText:
+		return "[redacted]", nil
+	}
+	return "", fmt.Errorf("unknown PII kind %q", kind)
+}
+
+// ssn scrambles an SSN into the never-issued 900-999 area, keeping its separators
+func (a *Anonymizer) ssn(value string) string {
+	digits := onlyDigits(value)
+	if len(digits) != 9 {
+		return a.digits("ssn", value)
+	}
+
+	sum := a.sum("ssn", digits)
+	fake := fmt.Sprintf("%03d%02d%04d", 900+sum%100, 1+(sum>>8)%99, 1+(sum>>16)%9999)
+	return replaceDigits(value, fake)
+}
+
+// digits replaces every digit of value with a derived one, keeping its formatting
+func (a *Anonymizer) digits(label string, value string) string {
+	digits := onlyDigits(value)
+	fake := strings.Builder{}
+	for i := 0; fake.Len() < len(digits); i++ {
+		fake.WriteString(fmt.Sprintf("%020d", a.sum(label, fmt.Sprintf("%s:%d", digits, i))))
+	}
+	return replaceDigits(value, fake.String())
+}
+
+// Layouts birth dates are stored in
+var dateLayouts = []string{"2006-01-02", "01/02/2006", "2006-01-02T15:04:05Z07:00", "2006-01-02 15:04:05"}
+
+// date moves a date to another day of the same year, so ages and tax-year rules still hold.
+// Values that are not a recognized date are dropped.
+func (a *Anonymizer) date(value string) string {
+	for _, layout := range dateLayouts {
+		t, err := time.Parse(layout, strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		start := time.Date(t.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+		days := start.AddDate(1, 0, 0).Sub(start).Hours() / 24
+		return start.AddDate(0, 0, int(a.sum("date", value)%uint64(days))).Format(layout)
+	}
+	return ""
+}
+
+// zipcode keeps the three-digit prefix, which places the client in a region but not a town
+func (a *Anonymizer) zipcode(value string) string {
+	digits := onlyDigits(value)
+	if len(digits) <= 3 {
+		return value
+	}
+	fake := digits[:3] + onlyDigits(a.digits("zipcode", digits[3:]))
+	return replaceDigits(value, fake)
+}
+
+// fileName replaces a file name, keeping its extension so content types still resolve
+func (a *Anonymizer) fileName(value string) string {
+	return "file-" + a.hex("file", normalize(value), 12) + strings.ToLower(path.Ext(value))
+}
+
+// filePath replaces each path segment except IDs, which are kept so paths match exported rows
+func (a *Anonymizer) filePath(value string) string {
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		if segment == "" || isID(segment) {
+			continue
+		}
+		if i == len(segments)-1 {
+			segments[i] = a.fileName(segment)
+		} else {
+			segments[i] = "dir-" + a.hex("dir", segment, 8)
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// sum returns the keyed hash of a labelled value as an integer
+func (a *Anonymizer) sum(label string, value string) uint64 {
+	return binary.BigEndian.Uint64(a.mac(label, value))
+}
+
+// hex returns n hex characters of the keyed hash of a labelled value
+func (a *Anonymizer) hex(label string, value string, n int) string {
+	return hex.EncodeToString(a.mac(label, value))[:n]
+}
+
+func (a *Anonymizer) mac(label string, value string) []byte {
+	h := hmac.New(sha256.New, a.key)
+	h.Write([]byte(label))
+	h.Write([]byte{0})
+	h.Write([]byte(value))
+	return h.Sum(nil)
+}
+
+// normalize folds case and spacing so "Smith" and "SMITH " get the same fake
+func normalize(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
+
+func pick(list []string, sum uint64) string {
+	return list[sum%uint64(len(list))]
+}
+
+func onlyDigits(value string) string {
+	var b strings.Builder
+	for _, ch := range value {
+		if ch >= '0' && ch <= '9' {
+			b.WriteRune(ch)
+		}
+	}
+	return b.String()
+}
+
+// replaceDigits writes the digits of fake over the digits of value, in order
+func replaceDigits(value string, fake string) string {
+	out := []byte(value)
+	j := 0
+	for i := range out {
+		if out[i] >= '0' && out[i] <= '9' && j < len(fake) {
+			out[i] = fake[j]
+			j++
+		}
+	}
+	return string(out)
+}
+
+// isID reports whether a path segment is a UUID or a number, such as a tax year
+func isID(segment string) bool {
+	if _, err := uuid.Parse(segment); err == nil {
+		return true
+	}
+	return onlyDigits(segment) == segment
+}
+
+var givenNames = []string{
+	"Avery", "Blake", "Casey", "Dana", "Elliot", "Finley", "Gray", "Harper", "Indigo", "Jordan",
+	"Kendall", "Logan", "Morgan", "Noel", "Oakley", "Parker", "Quinn", "Reese", "Sage", "Taylor",
+	"Rowan", "Skyler", "Emerson", "Hayden", "Jamie", "Kai", "Lane", "Marlowe", "Peyton", "Remy",
+}
+
+var familyNames = []string{
+	"Abbott", "Barlow", "Carver", "Dalton", "Ellison", "Fairbanks", "Garrison", "Holloway", "Ingram", "Jennings",
+	"Kessler", "Lockhart", "Merritt", "Norwood", "Oakes", "Pemberton", "Radcliffe", "Sterling", "Thornton", "Underwood",
+	"Vance", "Whitaker", "Yardley", "Ashford", "Brennan", "Calloway", "Delaney", "Everett", "Fletcher", "Hartley",
+}
+
+var streetNames = []string{
+	"Maple St", "Oak Ave", "Cedar Ln", "Birch Rd", "Elm Ct", "Willow Way", "Pine Dr", "Aspen Pl",
+	"Chestnut Blvd", "Juniper Ter", "Magnolia Cir", "Sycamore Pkwy", "Hawthorn St", "Laurel Ave",
+}
+
+var cityNames = []string{
+	"Fairview", "Riverton", "Lakeside", "Greenville", "Oakdale", "Milford", "Brookfield", "Ashland",
+	"Clearwater Springs", "Pine Hollow", "Westbrook", "Harborview", "Maplewood", "Stonebridge",
+}
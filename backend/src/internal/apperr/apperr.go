@@ -0,0 +1,70 @@
+package apperr
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Error kinds returned by the store and adapters; test with errors.Is
+var (
+	ErrNotFound   = errors.New("not found")
+	ErrConflict   = errors.New("conflict")
+	ErrPermission = errors.New("permission denied")
+	ErrValidation = errors.New("validation failed")
+)
+
+// Error is an error of a known kind whose message is safe to show to API clients
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// Unwrap lets errors.Is match the error's kind
+func (e *Error) Unwrap() error { return e.Kind }
+
+// NotFound reports a missing record
+func NotFound(format string, args ...interface{}) error {
+	return &Error{Kind: ErrNotFound, Message: fmt.Sprintf(format, args...)}
+}
+
+// Conflict reports a record that is not in a state that allows the operation
+func Conflict(format string, args ...interface{}) error {
+	return &Error{Kind: ErrConflict, Message: fmt.Sprintf(format, args...)}
+}
+
+// Permission reports an operation the caller is not allowed to perform
+func Permission(format string, args ...interface{}) error {
+	return &Error{Kind: ErrPermission, Message: fmt.Sprintf(format, args...)}
+}
+
+// Validation reports invalid input
+func Validation(format string, args ...interface{}) error {
+	return &Error{Kind: ErrValidation, Message: fmt.Sprintf(format, args...)}
+}
+
+// Status maps an error to an HTTP status code; errors of unknown kind are 500
+func Status(err error) int {
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ErrConflict):
+		return http.StatusConflict
+	case errors.Is(err, ErrPermission):
+		return http.StatusForbidden
+	case errors.Is(err, ErrValidation):
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// Message returns the client-safe message of a typed error anywhere in err's chain
+func Message(err error) (string, bool) {
+	var typed *Error
+	if errors.As(err, &typed) {
+		return typed.Message, true
+	}
+	return "", false
+}
@@ -0,0 +1,216 @@
+package auditchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"time"
+	"welltaxpro/src/internal/storage"
+	"welltaxpro/src/internal/store"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// anchorPrefix is where anchors are written in the bucket, by UTC day
+const anchorPrefix = "audit-anchors"
+
+// Config configures anchoring of the audit hash chain
+type Config struct {
+	Provider        string // gcs or s3, or memory for local development; empty disables anchoring
+	Bucket          string // Bucket with a locked retention policy, so anchors cannot be changed or deleted
+	CredentialsPath string // Service account file; empty uses ADC
+}
+
+// Anchorer writes the audit chain head to a WORM bucket and checks the chain against it.
+// Anyone able to rewrite audit_logs could also rewrite audit_anchors, but not the bucket copy.
+type Anchorer struct {
+	store    *store.Store
+	provider storage.StorageProvider // nil when anchoring is disabled
+	bucket   string
+	holder   string
+}
+
+// anchorObject is the JSON written to the bucket for each anchor
+type anchorObject struct {
+	ChainSeq   int64     `json:"chainSeq"`
+	EntryHash  string    `json:"entryHash"`
+	AnchoredBy string    `json:"anchoredBy"`
+	AnchoredAt time.Time `json:"anchoredAt"`
+}
+
+// New creates an anchorer that records anchors as holder
+func New(ctx context.Context, s *store.Store, config Config, holder string) (*Anchorer, error) {
+	a := &Anchorer{store: s, bucket: config.Bucket, holder: holder}
+	if config.Provider == "" {
+		return a, nil
+	}
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("an anchor bucket is required when a provider is set")
+	}
+
+	provider, err := storage.NewStorageProvider(ctx, config.Provider, config.CredentialsPath)
+	if err != nil {
+		return nil, err
+	}
+	a.provider = provider
+	return a, nil
+}
+
+// Enabled reports whether an anchor bucket is configured
+func (a *Anchorer) Enabled() bool {
+	return a.provider != nil
+}
+
+// Anchor writes the current chain head to the bucket and records it. It returns nil when
+// anchoring is disabled or the head was already anchored.
+func (a *Anchorer) Anchor(ctx context.Context) (*types.AuditAnchor, error) {
+	if !a.Enabled() {
+		return nil, nil
+	}
+
+	seq, hash, err := a.store.GetAuditChainHead()
+	if err != nil || seq == 0 {
+		return nil, err
+	}
+	last, err := a.store.GetLastAuditAnchor()
+	if err != nil {
+		return nil, err
+	}
+	if last != nil && last.ChainSeq >= seq {
+		return nil, nil
+	}
+
+	now := time.Now().UTC()
+	object := anchorObject{ChainSeq: seq, EntryHash: hash, AnchoredBy: a.holder, AnchoredAt: now}
+	data, err := json.Marshal(object)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode anchor: %w", err)
+	}
+
+	objectPath := path.Join(anchorPrefix, now.Format("2006/01/02"), fmt.Sprintf("%020d.json", seq))
+	metadata := map[string]string{"chain_seq": fmt.Sprint(seq), "entry_hash": hash}
+	if err := a.provider.Upload(ctx, a.bucket, objectPath, bytes.NewReader(data), metadata); err != nil {
+		return nil, fmt.Errorf("failed to write anchor %d: %w", seq, err)
+	}
+
+	anchor := &types.AuditAnchor{ChainSeq: seq, EntryHash: hash, Bucket: a.bucket, ObjectPath: objectPath, AnchoredBy: a.holder}
+	if err := a.store.RecordAuditAnchor(anchor); err != nil {
+		return nil, err
+	}
+	return anchor, nil
+}
+
+// Verify re-validates the chain for entries created in [from, to). With a bucket configured,
+// the bucket's anchors for the range are also compared with the chain, including anchors
+// whose database rows are gone.
+func (a *Anchorer) Verify(ctx context.Context, from, to time.Time) (*types.AuditChainVerification, error) {
+	v, anchors, err := a.store.VerifyAuditChain(from, to)
+	if err != nil || !a.Enabled() || v.FirstSeq == nil {
+		return v, err
+	}
+
+	// Anchors recorded in the database must have a bucket copy
+	paths := map[string]bool{}
+	for _, anchor := range anchors {
+		paths[anchor.ObjectPath] = true
+	}
+	// Anchors are written after the entries they cover, so list up to a day past the range
+	listed, err := a.listAnchors(ctx, from, to.Add(24*time.Hour))
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range listed {
+		if _, recorded := paths[p]; !recorded {
+			paths[p] = false
+		}
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+
+	for _, objectPath := range sorted {
+		recorded := paths[objectPath]
+		object, err := a.readAnchor(ctx, objectPath)
+		if err != nil {
+			if recorded {
+				v.AddProblem(&types.AuditChainProblem{
+					Problem: types.AuditChainAnchorMissing,
+					Detail:  fmt.Sprintf("%s: %v", objectPath, err),
+				})
+			}
+			continue
+		}
+		if object.ChainSeq < *v.FirstSeq || object.ChainSeq > *v.LastSeq {
+			continue
+		}
+		v.WORMChecked++
+
+		hash, ok, err := a.store.GetAuditEntryHash(object.ChainSeq)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case !ok:
+			v.AddProblem(&types.AuditChainProblem{
+				ChainSeq: object.ChainSeq,
+				Problem:  types.AuditChainAnchorMismatch,
+				Detail:   fmt.Sprintf("entry anchored in %s is missing", objectPath),
+			})
+		case hash != object.EntryHash:
+			v.AddProblem(&types.AuditChainProblem{
+				ChainSeq: object.ChainSeq,
+				Problem:  types.AuditChainAnchorMismatch,
+				Detail:   fmt.Sprintf("entry hash differs from %s", objectPath),
+			})
+		}
+	}
+
+	return v, nil
+}
+
+// listAnchors lists anchor objects written on the UTC days from through to; providers that
+// cannot list only have their recorded anchors checked
+func (a *Anchorer) listAnchors(ctx context.Context, from, to time.Time) ([]string, error) {
+	lister, ok := a.provider.(storage.Lister)
+	if !ok {
+		return nil, nil
+	}
+
+	var paths []string
+	for day := from.UTC().Truncate(24 * time.Hour); !day.After(to.UTC()); day = day.Add(24 * time.Hour) {
+		listed, err := lister.List(ctx, a.bucket, path.Join(anchorPrefix, day.Format("2006/01/02"))+"/")
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, listed...)
+	}
+	return paths, nil
+}
+
+// readAnchor downloads and decodes one anchor object
+func (a *Anchorer) readAnchor(ctx context.Context, objectPath string) (*anchorObject, error) {
+	rc, err := a.provider.Download(ctx, a.bucket, objectPath)
+	if err != nil {
+		return nil, err
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, 1<<16))
+	if err != nil {
+		return nil, err
+	}
+	object := &anchorObject{}
+	if err := json.Unmarshal(data, object); err != nil {
+		logger.Warningf("Unreadable audit anchor %s: %v", objectPath, err)
+		return nil, fmt.Errorf("unreadable anchor: %w", err)
+	}
+	return object, nil
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"welltaxpro/src/internal/tracing"
 
 	"github.com/google/logger"
 
@@ -23,10 +24,13 @@ const (
 	AuthorizationKey contextKey = "Authorization"
 	// EmployeeContextKey stores the authenticated employee in request context
 	EmployeeContextKey contextKey = "Employee"
+	// TenantContextKey stores the tenant context the employee selected in request context
+	TenantContextKey contextKey = "TenantContext"
 )
 
 // Initialize Firebase App and Auth
 type Auth struct {
+	App         *firebase.App // Shared with other Firebase services (e.g. Cloud Messaging)
 	Client      *auth.Client
 	FirebaseKey string
 }
@@ -59,6 +63,7 @@ func InitAuth(firebaseKey, serviceAccountPath string) (*Auth, error) {
 	}
 
 	return &Auth{
+		App:         app,
 		Client:      firebaseAuth,
 		FirebaseKey: firebaseKey,
 	}, nil
@@ -67,6 +72,23 @@ func InitAuth(firebaseKey, serviceAccountPath string) (*Auth, error) {
 // ValidateToken to ensure that token provided is valid and user can
 // access the API, it returns the token UID.
 func (a *Auth) ValidateToken(ctx context.Context, token string) (*string, error) {
+	decodedToken, err := a.VerifyToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	return &decodedToken.UID, nil
+}
+
+// VerifyToken validates an ID token, or a custom token exchanged for one, and returns its claims
+func (a *Auth) VerifyToken(ctx context.Context, token string) (*auth.Token, error) {
+	ctx, span := tracing.StartClient(ctx, "firebase.VerifyToken")
+	decodedToken, err := a.verifyToken(ctx, token)
+	tracing.End(span, err)
+	return decodedToken, err
+}
+
+// verifyToken does the work of VerifyToken
+func (a *Auth) verifyToken(ctx context.Context, token string) (*auth.Token, error) {
 	logger.Info("Verifying token")
 
 	// Remove Bearer prefix if present
@@ -105,7 +127,7 @@ func (a *Auth) ValidateToken(ctx context.Context, token string) (*string, error)
 		logger.Info("Token verified directly as ID token")
 	}
 
-	return &decodedToken.UID, nil
+	return decodedToken, nil
 }
 
 func exchangeCustomTokenForIDToken(customToken, firebaseAPIKey string) (string, error) {
@@ -145,7 +167,10 @@ type AuthUser struct {
 	EmailVerified bool
 }
 
-func (a *Auth) CreateUser(ctx context.Context, email string, password string) (*AuthUser, error) {
+func (a *Auth) CreateUser(ctx context.Context, email string, password string) (_ *AuthUser, err error) {
+	ctx, span := tracing.StartClient(ctx, "firebase.CreateUser")
+	defer func() { tracing.End(span, err) }()
+
 	// Create a new user
 	params := (&auth.UserToCreate{}).
 		Email(email).
@@ -186,9 +211,12 @@ type RefreshTokenResponse struct {
 	TokenType    string `json:"token_type"`
 }
 
-func (a *Auth) DeleteUser(ctx context.Context, uid string) error {
+func (a *Auth) DeleteUser(ctx context.Context, uid string) (err error) {
 	logger.Info("Deleting user")
-	err := a.Client.DeleteUser(ctx, uid)
+	ctx, span := tracing.StartClient(ctx, "firebase.DeleteUser")
+	defer func() { tracing.End(span, err) }()
+
+	err = a.Client.DeleteUser(ctx, uid)
 	if err != nil {
 		logger.Infof("Failed deleting user with uid: %s\n Error: %v", uid, err)
 		return err
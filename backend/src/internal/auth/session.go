@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// sessionTokenPrefix marks refresh tokens issued by the API rather than by Firebase
+const sessionTokenPrefix = "wtpr_"
+
+// NewSessionToken generates a refresh token for an employee session and the hash it is stored under
+func NewSessionToken() (token, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("failed to generate session token: %w", err)
+	}
+	token = sessionTokenPrefix + hex.EncodeToString(raw)
+	return token, HashSessionToken(token), nil
+}
+
+// HashSessionToken returns the SHA-256 hex digest a session refresh token is looked up by
+func HashSessionToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// tenantContextTokenPrefix marks tenant context tokens
+const tenantContextTokenPrefix = "wtpc_"
+
+// NewTenantContextToken generates a tenant context token and the hash it is stored under
+func NewTenantContextToken() (token, hash string, err error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("failed to generate tenant context token: %w", err)
+	}
+	token = tenantContextTokenPrefix + hex.EncodeToString(raw)
+	return token, HashSessionToken(token), nil
+}
@@ -0,0 +1,116 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/tracing"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/google/logger"
+	"google.golang.org/api/iterator"
+)
+
+// FirebaseUser is a Firebase Authentication account
+type FirebaseUser struct {
+	UID          string
+	Email        string
+	Disabled     bool
+	CreatedAt    time.Time
+	LastSignInAt *time.Time
+}
+
+// UserAdmin is the Firebase Admin user lifecycle operations the API and worker use; *Auth
+// implements it. Accounts that no longer exist in Firebase count as already disabled and revoked.
+type UserAdmin interface {
+	DisableUser(ctx context.Context, uid string) error
+	RevokeRefreshTokens(ctx context.Context, uid string) error
+	ListUsers(ctx context.Context) ([]*FirebaseUser, error)
+	EmailSignInLink(ctx context.Context, email, continueURL string) (string, error)
+}
+
+var _ UserAdmin = (*Auth)(nil)
+
+// DisableUser disables a Firebase account, so it can no longer sign in or refresh tokens
+func (a *Auth) DisableUser(ctx context.Context, uid string) (err error) {
+	ctx, span := tracing.StartClient(ctx, "firebase.DisableUser")
+	defer func() { tracing.End(span, err) }()
+
+	_, err = a.Client.UpdateUser(ctx, uid, (&auth.UserToUpdate{}).Disabled(true))
+	if auth.IsUserNotFound(err) {
+		logger.Warningf("Firebase user %s does not exist; nothing to disable", uid)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to disable Firebase user %s: %w", uid, err)
+	}
+	logger.Infof("Disabled Firebase user %s", uid)
+	return nil
+}
+
+// RevokeRefreshTokens invalidates a Firebase account's refresh tokens, so its sign-ins end when
+// their ID tokens expire
+func (a *Auth) RevokeRefreshTokens(ctx context.Context, uid string) (err error) {
+	ctx, span := tracing.StartClient(ctx, "firebase.RevokeRefreshTokens")
+	defer func() { tracing.End(span, err) }()
+
+	err = a.Client.RevokeRefreshTokens(ctx, uid)
+	if auth.IsUserNotFound(err) {
+		logger.Warningf("Firebase user %s does not exist; nothing to revoke", uid)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens of Firebase user %s: %w", uid, err)
+	}
+	logger.Infof("Revoked refresh tokens of Firebase user %s", uid)
+	return nil
+}
+
+// ListUsers returns every account in the Firebase project
+func (a *Auth) ListUsers(ctx context.Context) (_ []*FirebaseUser, err error) {
+	ctx, span := tracing.StartClient(ctx, "firebase.ListUsers")
+	defer func() { tracing.End(span, err) }()
+
+	var users []*FirebaseUser
+	it := a.Client.Users(ctx, "")
+	for {
+		u, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Firebase users: %w", err)
+		}
+
+		user := &FirebaseUser{
+			UID:      u.UID,
+			Email:    u.Email,
+			Disabled: u.Disabled,
+		}
+		if u.UserMetadata != nil {
+			user.CreatedAt = time.UnixMilli(u.UserMetadata.CreationTimestamp)
+			if u.UserMetadata.LastLogInTimestamp > 0 {
+				lastSignIn := time.UnixMilli(u.UserMetadata.LastLogInTimestamp)
+				user.LastSignInAt = &lastSignIn
+			}
+		}
+		users = append(users, user)
+	}
+	return users, nil
+}
+
+// EmailSignInLink creates a passwordless sign-in link for email that lands on continueURL once
+// used. continueURL's domain must be authorized in the Firebase project.
+func (a *Auth) EmailSignInLink(ctx context.Context, email, continueURL string) (_ string, err error) {
+	ctx, span := tracing.StartClient(ctx, "firebase.EmailSignInLink")
+	defer func() { tracing.End(span, err) }()
+
+	link, err := a.Client.EmailSignInLink(ctx, email, &auth.ActionCodeSettings{
+		URL:             continueURL,
+		HandleCodeInApp: true,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create sign-in link for %s: %w", email, err)
+	}
+	return link, nil
+}
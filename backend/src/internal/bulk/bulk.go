@@ -0,0 +1,479 @@
+// Package bulk runs bulk operations: one action applied to many clients or filings of a tenant,
+// queued through the API and processed by the bulk operations worker job.
+package bulk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/auth"
+	"welltaxpro/src/internal/notification"
+	"welltaxpro/src/internal/sms"
+	"welltaxpro/src/internal/store"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	// MaxItems bounds the clients or filings one operation may act on
+	MaxItems = 5000
+)
+
+// errSelectionTooLarge stops a filter that matches more than MaxItems items
+var errSelectionTooLarge = fmt.Errorf("the filter matches more than %d items; narrow it or split the operation", MaxItems)
+
+// Runner processes queued bulk operations
+type Runner struct {
+	store  *store.Store
+	users  auth.UserAdmin
+	texter sms.Provider
+	links  notification.Links
+}
+
+// New creates a runner. s should act as types.ServiceWorker; without users, SEND_PORTAL_LINK
+// items fail, and without a texter portal links are only emailed. Sign-in links land on the
+// portal of links.
+func New(s *store.Store, users auth.UserAdmin, texter sms.Provider, links notification.Links) *Runner {
+	return &Runner{store: s, users: users, texter: texter, links: links}
+}
+
+// itemFunc applies an operation's action to one client or filing and describes what it did
+type itemFunc func(ctx context.Context, itemID uuid.UUID) (string, error)
+
+// RoleStore is the part of the store validation reads; *store.Store implements it
+type RoleStore interface {
+	GetEmployeeTenantRole(employeeID uuid.UUID, tenantID string) (string, error)
+}
+
+// VerificationUnlocker is the part of the store that lifts portal verification lockouts;
+// *store.Store implements it
+type VerificationUnlocker interface {
+	UnlockPortalVerification(tenantID string, clientID uuid.UUID) (bool, error)
+}
+
+// LiftVerificationLock lifts the portal verification lockout of a client who was just sent a
+// portal link, which is how a locked out client gets back in, and adds what it did to result
+func LiftVerificationLock(s VerificationUnlocker, tenantID string, clientID uuid.UUID, result string) string {
+	unlocked, err := s.UnlockPortalVerification(tenantID, clientID)
+	if err != nil {
+		return fmt.Sprintf("%s; verification lockout not lifted: %v", result, err)
+	}
+	if unlocked {
+		result += "; lifted verification lockout"
+	}
+	return result
+}
+
+// Validate checks a bulk operation before it is queued: a known action, a selection of IDs or a
+// filter but not both, and the action's params
+func Validate(s RoleStore, op *types.BulkOperation) error {
+	if _, ok := types.BulkActionTargets[op.Action]; !ok {
+		return apperr.Validation("unknown action: %s", op.Action)
+	}
+
+	sel := op.Selection
+	switch {
+	case sel == nil || (len(sel.IDs) == 0 && sel.Filter == nil):
+		return apperr.Validation("select items with ids or a filter")
+	case len(sel.IDs) > 0 && sel.Filter != nil:
+		return apperr.Validation("select items with ids or a filter, not both")
+	case len(sel.IDs) > MaxItems:
+		return apperr.Validation("at most %d ids may be given", MaxItems)
+	}
+
+	_, err := actionParams(s, op)
+	return err
+}
+
+// actionParams decodes and checks an operation's params
+func actionParams(s RoleStore, op *types.BulkOperation) (interface{}, error) {
+	switch op.Action {
+	case types.BulkActionRequestDocuments:
+		var params types.BulkRequestDocumentsParams
+		if err := decodeParams(op.Params, &params); err != nil {
+			return nil, err
+		}
+		params.DocumentType = strings.TrimSpace(params.DocumentType)
+		params.Description = strings.TrimSpace(params.Description)
+		if params.DocumentType == "" || params.Description == "" {
+			return nil, apperr.Validation("params.documentType and params.description are required")
+		}
+		if params.DueOn != nil {
+			if _, err := time.Parse("2006-01-02", *params.DueOn); err != nil {
+				return nil, apperr.Validation("params.dueOn must be a YYYY-MM-DD date")
+			}
+		}
+		return &params, nil
+
+	case types.BulkActionReassignFilings:
+		var params types.BulkReassignFilingsParams
+		if err := decodeParams(op.Params, &params); err != nil {
+			return nil, err
+		}
+		if params.EmployeeID == uuid.Nil {
+			return nil, apperr.Validation("params.employeeId is required")
+		}
+		if _, err := s.GetEmployeeTenantRole(params.EmployeeID, op.TenantID); err != nil {
+			if errors.Is(err, apperr.ErrNotFound) {
+				return nil, apperr.Validation("employee %s has no access to tenant %s", params.EmployeeID, op.TenantID)
+			}
+			return nil, err
+		}
+		return &params, nil
+	}
+	return nil, nil
+}
+
+// decodeParams reads params into v, rejecting fields the action does not take
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 {
+		params = json.RawMessage("{}")
+	}
+	decoder := json.NewDecoder(bytes.NewReader(params))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return apperr.Validation("invalid params: %v", err)
+	}
+	return nil
+}
+
+// RunQueued processes every queued operation, and resumes any a stopped worker left running,
+// oldest first. It returns how many items were processed.
+func (r *Runner) RunQueued(ctx context.Context) (int, error) {
+	ops, err := r.store.GetUnfinishedBulkOperations()
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, op := range ops {
+		if ctx.Err() != nil {
+			break
+		}
+		n, runErr := r.run(ctx, op)
+		processed += n
+		if runErr != nil && err == nil {
+			err = runErr
+		}
+	}
+	return processed, err
+}
+
+// run resolves an operation's selection into items, unless an earlier run already did, then
+// applies the action to each pending item. An operation whose selection or params are no longer
+// valid fails as a whole; a cancelled ctx leaves it running for the next run to resume.
+func (r *Runner) run(ctx context.Context, op *types.BulkOperation) (int, error) {
+	if err := r.store.StartBulkOperation(op.ID); err != nil {
+		return 0, err
+	}
+	logger.Infof("Running %s bulk operation %s for tenant %s", op.Action, op.ID, op.TenantID)
+
+	if op.SelectedAt == nil {
+		itemIDs, err := r.selectItems(ctx, op)
+		if err != nil {
+			if ctx.Err() != nil {
+				return 0, nil
+			}
+			logger.Warningf("Bulk operation %s failed to select items: %v", op.ID, err)
+			return 0, r.store.FinishBulkOperation(op.ID, err)
+		}
+		if err := r.store.SetBulkOperationItems(op.ID, itemIDs); err != nil {
+			return 0, err
+		}
+	}
+
+	apply, err := r.itemFunc(op)
+	if err != nil {
+		logger.Warningf("Bulk operation %s cannot run: %v", op.ID, err)
+		return 0, r.store.FinishBulkOperation(op.ID, err)
+	}
+
+	pending, err := r.store.GetPendingBulkItems(op.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	processed := 0
+	for _, itemID := range pending {
+		if ctx.Err() != nil {
+			logger.Infof("Bulk operation %s interrupted with %d items left", op.ID, len(pending)-processed)
+			return processed, nil
+		}
+		result, itemErr := apply(ctx, itemID)
+		if itemErr != nil {
+			logger.Warningf("Bulk operation %s failed for %s: %v", op.ID, itemID, itemErr)
+		}
+		if err := r.store.RecordBulkItem(op.ID, itemID, result, itemErr); err != nil {
+			return processed, err
+		}
+		processed++
+	}
+
+	return processed, r.store.FinishBulkOperation(op.ID, nil)
+}
+
+// selectItems returns the IDs an operation acts on: the given IDs, or the clients or filings
+// its filter matches
+func (r *Runner) selectItems(ctx context.Context, op *types.BulkOperation) ([]uuid.UUID, error) {
+	if op.Selection.Filter == nil {
+		seen := make(map[uuid.UUID]bool, len(op.Selection.IDs))
+		ids := make([]uuid.UUID, 0, len(op.Selection.IDs))
+		for _, id := range op.Selection.IDs {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+		return ids, nil
+	}
+
+	filter := op.Selection.Filter
+	var assigned map[uuid.UUID]bool
+	if filter.AssignedTo != nil {
+		assignments, err := r.store.GetFilingAssignments(op.TenantID, filter.AssignedTo)
+		if err != nil {
+			return nil, err
+		}
+		assigned = make(map[uuid.UUID]bool, len(assignments))
+		for _, a := range assignments {
+			assigned[a.FilingID] = true
+		}
+	}
+
+	matches := func(f *types.Filing) bool {
+		switch {
+		case filter.TaxYear != nil && f.Year != *filter.TaxYear:
+			return false
+		case filter.IncompleteOnly && f.Status != nil && f.Status.IsCompleted:
+			return false
+		case assigned != nil && !assigned[f.ID]:
+			return false
+		}
+		return true
+	}
+
+	byFiling := types.BulkActionTargets[op.Action] == types.BulkTargetFiling
+	var ids []uuid.UUID
+	err := r.store.StreamClientsByFilings(ctx, op.TenantID, func(c *types.ClientComprehensive) error {
+		if c.Client == nil || (c.Client.ArchivedAt != nil && !filter.IncludeArchived) {
+			return nil
+		}
+		for _, f := range c.Filings {
+			if !matches(f) {
+				continue
+			}
+			if byFiling {
+				ids = append(ids, f.ID)
+			} else {
+				ids = append(ids, c.Client.ID)
+			}
+			if len(ids) > MaxItems {
+				return errSelectionTooLarge
+			}
+			if !byFiling {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// itemFunc returns the function applying an operation's action to one item
+func (r *Runner) itemFunc(op *types.BulkOperation) (itemFunc, error) {
+	params, err := actionParams(r.store, op)
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Action {
+	case types.BulkActionSendPortalLink:
+		if r.users == nil {
+			return nil, fmt.Errorf("portal links need Firebase, which is not configured on this worker")
+		}
+		tc, err := r.store.GetTenantConfig(op.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		smsSettings, err := r.store.GetSMSSettings(op.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		return func(ctx context.Context, clientID uuid.UUID) (string, error) {
+			result, err := r.sendPortalLink(ctx, tc, smsSettings, clientID)
+			if err != nil {
+				return "", err
+			}
+			return LiftVerificationLock(r.store, tc.TenantID, clientID, result), nil
+		}, nil
+
+	case types.BulkActionRequestDocuments:
+		p := params.(*types.BulkRequestDocumentsParams)
+		return func(ctx context.Context, clientID uuid.UUID) (string, error) {
+			return r.requestDocument(ctx, op, p, clientID)
+		}, nil
+
+	case types.BulkActionReassignFilings:
+		p := params.(*types.BulkReassignFilingsParams)
+		return func(ctx context.Context, filingID uuid.UUID) (string, error) {
+			if _, err := r.store.GetFilingClientID(ctx, op.TenantID, filingID); err != nil {
+				return "", err
+			}
+			if _, err := r.store.AssignFiling(op.TenantID, filingID, p.EmployeeID, &op.CreatedBy); err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("Assigned to employee %s", p.EmployeeID), nil
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown action: %s", op.Action)
+}
+
+// sendPortalLink queues an email with a sign-in link to the tenant's portal for a client, which
+// the email delivery job sends, and texts the link too when the tenant's SMS settings ask for it.
+// The item fails only when the link reached neither channel.
+func (r *Runner) sendPortalLink(ctx context.Context, tc *types.TenantConnection, smsSettings *types.SMSSettings, clientID uuid.UUID) (string, error) {
+	client, err := r.store.GetClientByID(ctx, tc.TenantID, clientID.String())
+	if err != nil {
+		return "", err
+	}
+	if client.ArchivedAt != nil {
+		return "", fmt.Errorf("client is archived")
+	}
+	if client.Email == "" {
+		return "", fmt.Errorf("client has no email address")
+	}
+
+	link, err := r.users.EmailSignInLink(ctx, client.Email, r.links.Portal(tc.TenantID))
+	if err != nil {
+		return "", err
+	}
+
+	name := "Valued Client"
+	if client.FirstName != nil && *client.FirstName != "" {
+		name = *client.FirstName
+	}
+	subject, htmlBody, textBody := notification.GeneratePortalAccessEmail(notification.PortalAccessEmail{
+		ClientName: name,
+		TenantName: tc.TenantName,
+		PortalURL:  link,
+	})
+	email, err := r.store.QueueEmail(&types.OutboxEmail{
+		TenantID: &tc.TenantID,
+		Kind:     types.OutboxEmailPortalLink,
+		Priority: notification.PriorityBulk,
+		ToEmail:  client.Email,
+		ToName:   &name,
+		Subject:  subject,
+		HTMLBody: htmlBody,
+		TextBody: textBody,
+	})
+	if err != nil {
+		return "", err
+	}
+	emailResult := fmt.Sprintf("Queued portal link to %s (email %s)", client.Email, email.ID)
+	var emailErr error
+	if email.Status == types.OutboxEmailSuppressed {
+		emailErr = fmt.Errorf("%s is on the email suppression list", client.Email)
+	}
+	if !smsSettings.PortalLinks {
+		if emailErr != nil {
+			return "", emailErr
+		}
+		return emailResult, nil
+	}
+
+	textResult, textErr := r.textPortalLink(ctx, tc, smsSettings, client, notification.GeneratePortalAccessText(notification.PortalAccessEmail{
+		ClientName: name,
+		TenantName: tc.TenantName,
+		PortalURL:  link,
+	}))
+	switch {
+	case emailErr != nil && textErr != nil:
+		return "", fmt.Errorf("%v; text not sent: %v", emailErr, textErr)
+	case emailErr != nil:
+		return fmt.Sprintf("%s; email not sent: %v", textResult, emailErr), nil
+	case textErr != nil:
+		return fmt.Sprintf("%s; text not sent: %v", emailResult, textErr), nil
+	}
+	return emailResult + "; " + textResult, nil
+}
+
+// textPortalLink texts body to the client's phone on file, within the tenant's daily limit per
+// client, and records the message for delivery tracking
+func (r *Runner) textPortalLink(ctx context.Context, tc *types.TenantConnection, smsSettings *types.SMSSettings, client *types.Client, body string) (string, error) {
+	if !sms.Enabled(r.texter) {
+		return "", fmt.Errorf("text messages are not configured on this worker")
+	}
+	if client.Phone == nil || *client.Phone == "" {
+		return "", fmt.Errorf("client has no phone number")
+	}
+	phone, ok := sms.NormalizePhone(*client.Phone)
+	if !ok {
+		return "", fmt.Errorf("client phone number %q cannot be texted", *client.Phone)
+	}
+
+	message, err := r.store.StartSMS(&types.SMSMessage{
+		TenantID: tc.TenantID,
+		ClientID: client.ID,
+		Kind:     types.SMSKindPortalLink,
+		ToPhone:  phone,
+		Provider: r.texter.Name(),
+	}, smsSettings.MaxPerClientPerDay)
+	if err != nil {
+		return "", err
+	}
+
+	receipt, sendErr := r.texter.Send(ctx, phone, body)
+	var providerID, status string
+	if sendErr == nil {
+		providerID, status = receipt.ProviderID, receipt.Status
+	}
+	if err := r.store.FinishSMS(message.ID, providerID, status, sendErr); err != nil {
+		logger.Errorf("Text message %s to client %s was not recorded as finished: %v", message.ID, client.ID, err)
+	}
+	if sendErr != nil {
+		return "", sendErr
+	}
+	return fmt.Sprintf("Texted portal link to %s (message %s)", phone, message.ID), nil
+}
+
+// requestDocument raises the operation's document request for a client, unless the client
+// already has the same type of document requested
+func (r *Runner) requestDocument(ctx context.Context, op *types.BulkOperation, params *types.BulkRequestDocumentsParams, clientID uuid.UUID) (string, error) {
+	if _, err := r.store.GetClientByID(ctx, op.TenantID, clientID.String()); err != nil {
+		return "", err
+	}
+
+	open, err := r.store.GetClientDocumentRequests(op.TenantID, clientID, true)
+	if err != nil {
+		return "", err
+	}
+	for _, existing := range open {
+		if strings.EqualFold(existing.DocumentType, params.DocumentType) {
+			return fmt.Sprintf("Document request %s was already open", existing.ID), nil
+		}
+	}
+
+	request, err := r.store.CreateDocumentRequest(&types.DocumentRequest{
+		TenantID:     op.TenantID,
+		ClientID:     clientID,
+		DocumentType: params.DocumentType,
+		Description:  params.Description,
+		DueOn:        params.DueOn,
+		RequestedBy:  &op.CreatedBy,
+	})
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("Raised document request %s", request.ID), nil
+}
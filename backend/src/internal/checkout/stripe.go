@@ -0,0 +1,144 @@
+// Package checkout verifies and decodes the Stripe webhook events of filing checkouts
+package checkout
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// SignatureHeader is the header Stripe signs webhook events with
+const SignatureHeader = "Stripe-Signature"
+
+// SignatureTolerance is how old a signed event may be before it is refused as a replay
+const SignatureTolerance = 5 * time.Minute
+
+// ErrInvalidSignature is returned for events no Stripe-Signature v1 signature vouches for
+var ErrInvalidSignature = errors.New("Stripe signature is invalid")
+
+// Checkout session events that carry a payment; other events are acknowledged and ignored
+const (
+	EventSessionCompleted             = "checkout.session.completed"
+	EventSessionAsyncPaymentSucceeded = "checkout.session.async_payment_succeeded"
+)
+
+// VerifySignature checks a Stripe-Signature header ("t=...,v1=...,v1=...") against the raw event
+// body in constant time. Any v1 signature may match, since Stripe signs with every active secret
+// while one is being rolled.
+func VerifySignature(header string, body []byte, secret string, now time.Time) error {
+	if secret == "" {
+		return ErrInvalidSignature
+	}
+
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(header, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || len(signatures) == 0 {
+		return ErrInvalidSignature
+	}
+	if age := now.Sub(time.Unix(seconds, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return fmt.Errorf("%w: timestamp outside tolerance", ErrInvalidSignature)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	expected := []byte(hex.EncodeToString(mac.Sum(nil)))
+
+	for _, signature := range signatures {
+		if hmac.Equal(expected, []byte(signature)) {
+			return nil
+		}
+	}
+	return ErrInvalidSignature
+}
+
+// event is the part of a Stripe event envelope the webhook reads
+type event struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+	Data struct {
+		Object json.RawMessage `json:"object"`
+	} `json:"data"`
+}
+
+// session is the part of a Stripe Checkout Session the webhook reads
+type session struct {
+	ID                string            `json:"id"`
+	ClientReferenceID string            `json:"client_reference_id"`
+	Metadata          map[string]string `json:"metadata"`
+	AmountSubtotal    int64             `json:"amount_subtotal"`
+	AmountTotal       int64             `json:"amount_total"`
+	PaymentStatus     string            `json:"payment_status"`
+	TotalDetails      struct {
+		AmountDiscount int64 `json:"amount_discount"`
+	} `json:"total_details"`
+}
+
+// ParseEvent decodes a checkout session event. It returns nil, and no error, for event types that
+// do not carry a payment. The filing comes from the session's filing_id metadata, or else its
+// client_reference_id; the discount code from its discount_code metadata.
+func ParseEvent(body []byte) (*types.CheckoutSession, error) {
+	var evt event
+	if err := json.Unmarshal(body, &evt); err != nil {
+		return nil, fmt.Errorf("failed to decode Stripe event: %w", err)
+	}
+	if evt.Type != EventSessionCompleted && evt.Type != EventSessionAsyncPaymentSucceeded {
+		return nil, nil
+	}
+
+	var s session
+	if err := json.Unmarshal(evt.Data.Object, &s); err != nil {
+		return nil, fmt.Errorf("failed to decode checkout session: %w", err)
+	}
+	if s.ID == "" {
+		return nil, errors.New("checkout session has no ID")
+	}
+
+	reference := s.Metadata["filing_id"]
+	if reference == "" {
+		reference = s.ClientReferenceID
+	}
+	filingID, err := uuid.Parse(reference)
+	if err != nil {
+		return nil, fmt.Errorf("checkout session %s does not name a filing", s.ID)
+	}
+
+	result := &types.CheckoutSession{
+		EventID:        evt.ID,
+		EventType:      evt.Type,
+		SessionID:      s.ID,
+		FilingID:       filingID,
+		AmountSubtotal: types.Cents(s.AmountSubtotal),
+		AmountDiscount: types.Cents(s.TotalDetails.AmountDiscount),
+		AmountTotal:    types.Cents(s.AmountTotal),
+		PaymentStatus:  s.PaymentStatus,
+	}
+	if code := strings.TrimSpace(s.Metadata["discount_code"]); code != "" {
+		result.DiscountCode = &code
+	}
+	return result, nil
+}
@@ -0,0 +1,396 @@
+package consistency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/scan"
+	"welltaxpro/src/internal/storage"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// Store is the part of the store the checker uses; *store.Store implements it
+type Store interface {
+	DeleteDocument(ctx context.Context, tenantID string, documentID string) error
+	GetActiveTenantIDs() ([]string, error)
+	GetDocumentByID(ctx context.Context, tenantID string, documentID string) (*types.Document, error)
+	GetQuarantinePaths(tenantID string) (map[uuid.UUID]string, error)
+	GetStorageIssue(tenantID string, id uuid.UUID) (*types.StorageIssue, error)
+	GetTenantConfig(tenantID string) (*types.TenantConnection, error)
+	RaiseMissingFileRequest(tenantID string, document *types.Document, description string, requestedBy uuid.UUID) (*types.DocumentRequest, error)
+	ResolveRelinkedStorageIssues(tenantID string, documentID uuid.UUID, path string, resolvedBy uuid.UUID) error
+	ResolveStorageIssue(tenantID string, id uuid.UUID, resolution string, resolvedBy uuid.UUID, requestID *uuid.UUID) (*types.StorageIssue, error)
+	SaveStorageConsistencyCheck(check *types.StorageConsistencyCheck, issues []*types.StorageIssue) error
+	SetDocumentPath(ctx context.Context, tenantID string, documentID uuid.UUID, filePath string) error
+	StreamDocuments(ctx context.Context, tenantID string, fn func(*types.Document) error) error
+}
+
+// Checker compares tenants' document records with the files in their buckets and remediates the
+// differences it finds. Only files under a client's document path, {clientID}/{documentType}/,
+// or its quarantined copy are expected to have a record; other prefixes such as inbound email
+// attachments, identity documents and offboarding exports are left alone.
+type Checker struct {
+	store      Store
+	storageFor func(context.Context, *types.TenantConnection) (storage.StorageProvider, error)
+}
+
+// New creates a checker that opens tenant buckets with storageFor
+func New(s Store, storageFor func(context.Context, *types.TenantConnection) (storage.StorageProvider, error)) *Checker {
+	return &Checker{store: s, storageFor: storageFor}
+}
+
+// Check compares a tenant's document records with its bucket and saves the result. Storage and
+// database failures are saved on the check rather than returned.
+func (c *Checker) Check(ctx context.Context, tenantID string) (*types.StorageConsistencyCheck, error) {
+	tc, err := c.store.GetTenantConfig(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &types.StorageConsistencyCheck{TenantID: tenantID, CheckedAt: time.Now()}
+	issues, err := c.compare(ctx, tc, check)
+	if err != nil {
+		msg := err.Error()
+		check.Error = &msg
+		check.MissingFiles, check.OrphanedFiles = 0, 0
+		issues = nil
+	}
+
+	if err := c.store.SaveStorageConsistencyCheck(check, issues); err != nil {
+		return nil, err
+	}
+
+	if check.Error != nil {
+		logger.Warningf("Storage check for tenant %s could not run: %s", tenantID, *check.Error)
+	} else {
+		logger.Infof("Storage check for tenant %s found %d missing and %d orphaned files among %d documents and %d files",
+			tenantID, check.MissingFiles, check.OrphanedFiles, check.Documents, check.Objects)
+	}
+	return check, nil
+}
+
+// CheckAll checks the storage of every active tenant. Tenants whose check cannot be saved are
+// logged and left out.
+func (c *Checker) CheckAll(ctx context.Context) ([]*types.StorageConsistencyCheck, error) {
+	tenantIDs, err := c.store.GetActiveTenantIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	checks := []*types.StorageConsistencyCheck{}
+	for _, tenantID := range tenantIDs {
+		if ctx.Err() != nil {
+			return checks, ctx.Err()
+		}
+		check, err := c.Check(ctx, tenantID)
+		if err != nil {
+			logger.Errorf("Storage check failed for tenant %s: %v", tenantID, err)
+			continue
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// compare lists the tenant's bucket, then reads its documents. Listing first means a file uploaded
+// during the check is not reported as orphaned, since its document is recorded after the upload;
+// a document whose file was not listed is looked up again before it is reported as missing.
+func (c *Checker) compare(ctx context.Context, tc *types.TenantConnection, check *types.StorageConsistencyCheck) ([]*types.StorageIssue, error) {
+	lister, err := c.lister(ctx, tc)
+	if err != nil {
+		return nil, err
+	}
+
+	paths, err := lister.List(ctx, tc.StorageBucket, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list bucket: %w", err)
+	}
+	check.Objects = len(paths)
+	objects := make(map[string]bool, len(paths))
+	for _, path := range paths {
+		objects[path] = true
+	}
+
+	quarantined, err := c.store.GetQuarantinePaths(tc.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read document scans: %w", err)
+	}
+
+	referenced := map[string]bool{}
+	var missing []*types.Document
+	err = c.store.StreamDocuments(ctx, tc.TenantID, func(document *types.Document) error {
+		check.Documents++
+		referenced[document.FilePath] = true
+		quarantinePath, isQuarantined := quarantined[document.ID]
+		if isQuarantined {
+			referenced[quarantinePath] = true
+		}
+		if !objects[document.FilePath] && !(isQuarantined && objects[quarantinePath]) {
+			missing = append(missing, document)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read documents: %w", err)
+	}
+
+	var issues []*types.StorageIssue
+	// Orphaned files a missing document could be relinked to, by client document folder
+	candidates := map[string][]string{}
+	for _, path := range paths {
+		if referenced[path] {
+			continue
+		}
+		clientID, ok := documentPathClient(path)
+		if !ok {
+			continue
+		}
+		issues = append(issues, &types.StorageIssue{Type: types.StorageIssueOrphanedFile, Path: path, ClientID: &clientID})
+		check.OrphanedFiles++
+		if !strings.HasPrefix(path, scan.QuarantinePrefix) {
+			folder := path[:strings.LastIndex(path, "/")+1]
+			candidates[folder] = append(candidates[folder], path)
+		}
+	}
+
+	for _, document := range missing {
+		if document.FilePath != "" {
+			exists, err := objectExists(ctx, lister, tc.StorageBucket, document.FilePath)
+			if err != nil {
+				return nil, err
+			}
+			if exists {
+				continue
+			}
+		}
+
+		documentID, clientID := document.ID, document.UserID
+		issues = append(issues, &types.StorageIssue{
+			Type:         types.StorageIssueMissingFile,
+			Path:         document.FilePath,
+			DocumentID:   &documentID,
+			ClientID:     &clientID,
+			FilingID:     document.FilingID,
+			DocumentName: document.Name,
+			DocumentType: document.Type,
+			Candidates:   candidates[fmt.Sprintf("%s/%s/", document.UserID, document.Type)],
+		})
+		check.MissingFiles++
+	}
+
+	return issues, nil
+}
+
+// Resolve remediates an open storage issue of a tenant on behalf of employeeID and returns the
+// resolved issue.
+//
+// A missing file is relinked to another file of the same client, or its document is deleted, or
+// the client is asked for the document again and its record deleted. An orphaned file is relinked
+// to a document of its client, or deleted.
+func (c *Checker) Resolve(ctx context.Context, tenantID string, issueID uuid.UUID, remedy *types.StorageRemediation, employeeID uuid.UUID) (*types.StorageIssue, error) {
+	issue, err := c.store.GetStorageIssue(tenantID, issueID)
+	if err != nil {
+		return nil, err
+	}
+	if issue.Status != types.StorageIssueOpen {
+		return nil, apperr.Conflict("storage issue %s is already %s", issueID, issue.Status)
+	}
+
+	switch {
+	case issue.Type == types.StorageIssueMissingFile && remedy.Action == types.StorageRemedyRelink:
+		if err := c.relink(ctx, tenantID, *issue.DocumentID, remedy.Path, employeeID); err != nil {
+			return nil, err
+		}
+
+	case issue.Type == types.StorageIssueMissingFile && remedy.Action == types.StorageRemedyDelete:
+		err := c.store.DeleteDocument(ctx, tenantID, issue.DocumentID.String())
+		if err != nil && !errors.Is(err, apperr.ErrNotFound) {
+			return nil, err
+		}
+		return c.store.ResolveStorageIssue(tenantID, issueID, types.StorageRemedyDelete, employeeID, nil)
+
+	case issue.Type == types.StorageIssueMissingFile && remedy.Action == types.StorageRemedyRerequest:
+		document, err := c.store.GetDocumentByID(ctx, tenantID, issue.DocumentID.String())
+		if errors.Is(err, apperr.ErrNotFound) {
+			return nil, apperr.Conflict("document %s no longer exists; run the check again", issue.DocumentID)
+		}
+		if err != nil {
+			return nil, err
+		}
+		request, err := c.store.RaiseMissingFileRequest(tenantID, document, remedy.Description, employeeID)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.store.DeleteDocument(ctx, tenantID, document.ID.String()); err != nil {
+			return nil, err
+		}
+		return c.store.ResolveStorageIssue(tenantID, issueID, types.StorageRemedyRerequest, employeeID, &request.ID)
+
+	case issue.Type == types.StorageIssueOrphanedFile && remedy.Action == types.StorageRemedyRelink:
+		if remedy.DocumentID == nil {
+			return nil, apperr.Validation("documentId is required to relink an orphaned file")
+		}
+		if err := c.relink(ctx, tenantID, *remedy.DocumentID, issue.Path, employeeID); err != nil {
+			return nil, err
+		}
+
+	case issue.Type == types.StorageIssueOrphanedFile && remedy.Action == types.StorageRemedyDelete:
+		if err := c.deleteOrphan(ctx, tenantID, issue.Path); err != nil {
+			return nil, err
+		}
+		return c.store.ResolveStorageIssue(tenantID, issueID, types.StorageRemedyDelete, employeeID, nil)
+
+	case issue.Type == types.StorageIssueOrphanedFile && remedy.Action == types.StorageRemedyRerequest:
+		return nil, apperr.Validation("only documents with a missing file can be requested from the client again")
+
+	default:
+		return nil, apperr.Validation("action must be one of %s, %s or %s",
+			types.StorageRemedyRelink, types.StorageRemedyDelete, types.StorageRemedyRerequest)
+	}
+
+	return c.store.GetStorageIssue(tenantID, issueID)
+}
+
+// relink points a document at a file of the same client that no other document uses
+func (c *Checker) relink(ctx context.Context, tenantID string, documentID uuid.UUID, path string, employeeID uuid.UUID) error {
+	if path == "" {
+		return apperr.Validation("path is required to relink a document")
+	}
+	if strings.HasPrefix(path, scan.QuarantinePrefix) {
+		return apperr.Validation("documents cannot be relinked to quarantined files")
+	}
+	clientID, ok := documentPathClient(path)
+	if !ok {
+		return apperr.Validation("%s is not a client document path", path)
+	}
+
+	document, err := c.store.GetDocumentByID(ctx, tenantID, documentID.String())
+	if err != nil {
+		return err
+	}
+	if document.UserID != clientID {
+		return apperr.Validation("%s belongs to another client", path)
+	}
+
+	tc, err := c.store.GetTenantConfig(tenantID)
+	if err != nil {
+		return err
+	}
+	lister, err := c.lister(ctx, tc)
+	if err != nil {
+		return err
+	}
+	exists, err := objectExists(ctx, lister, tc.StorageBucket, path)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return apperr.NotFound("file not found: %s", path)
+	}
+	if user, err := c.referencedBy(ctx, tenantID, path); err != nil {
+		return err
+	} else if user != nil && user.ID != documentID {
+		return apperr.Conflict("%s is already the file of document %s", path, user.ID)
+	}
+
+	if err := c.store.SetDocumentPath(ctx, tenantID, documentID, path); err != nil {
+		return err
+	}
+	return c.store.ResolveRelinkedStorageIssues(tenantID, documentID, path, employeeID)
+}
+
+// deleteOrphan deletes a file once it is confirmed that no document has since come to use it
+func (c *Checker) deleteOrphan(ctx context.Context, tenantID, path string) error {
+	if user, err := c.referencedBy(ctx, tenantID, path); err != nil {
+		return err
+	} else if user != nil {
+		return apperr.Conflict("%s is now the file of document %s; run the check again", path, user.ID)
+	}
+
+	tc, err := c.store.GetTenantConfig(tenantID)
+	if err != nil {
+		return err
+	}
+	provider, err := c.storageFor(ctx, tc)
+	if err != nil {
+		return fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	if err := provider.Delete(ctx, tc.StorageBucket, path); err != nil {
+		return fmt.Errorf("failed to delete %s: %w", path, err)
+	}
+
+	logger.Infof("Deleted orphaned file %s of tenant %s", path, tenantID)
+	return nil
+}
+
+// referencedBy returns the document whose file, or quarantined copy, is path, if any
+func (c *Checker) referencedBy(ctx context.Context, tenantID, path string) (*types.Document, error) {
+	quarantined, err := c.store.GetQuarantinePaths(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	var user *types.Document
+	errFound := errors.New("found")
+	err = c.store.StreamDocuments(ctx, tenantID, func(document *types.Document) error {
+		if document.FilePath == path || quarantined[document.ID] == path {
+			user = document
+			return errFound
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errFound) {
+		return nil, err
+	}
+	return user, nil
+}
+
+// lister opens a tenant's bucket for listing
+func (c *Checker) lister(ctx context.Context, tc *types.TenantConnection) (storage.Lister, error) {
+	if tc.StorageBucket == "" {
+		return nil, fmt.Errorf("tenant has no storage bucket")
+	}
+	provider, err := c.storageFor(ctx, tc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	lister, ok := provider.(storage.Lister)
+	if !ok {
+		return nil, fmt.Errorf("storage provider %s cannot list objects", tc.StorageProvider)
+	}
+	return lister, nil
+}
+
+// objectExists reports whether bucket holds a file at exactly path
+func objectExists(ctx context.Context, lister storage.Lister, bucket, path string) (bool, error) {
+	paths, err := lister.List(ctx, bucket, path)
+	if err != nil {
+		return false, fmt.Errorf("failed to look up %s: %w", path, err)
+	}
+	for _, p := range paths {
+		if p == path {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// documentPathClient returns the client of a file under a client document path,
+// {clientID}/{documentType}/{name} or its quarantined copy
+func documentPathClient(path string) (uuid.UUID, bool) {
+	parts := strings.SplitN(strings.TrimPrefix(path, scan.QuarantinePrefix), "/", 3)
+	if len(parts) != 3 || parts[1] == "" || parts[2] == "" {
+		return uuid.Nil, false
+	}
+	clientID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return clientID, true
+}
@@ -0,0 +1,246 @@
+package crypto
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"welltaxpro/src/internal/types"
+)
+
+// activeKeyTTL is how long a tenant's active data key is cached; after a rekey, other processes
+// move to the new key within this time
+const activeKeyTTL = time.Minute
+
+// DataKeySource stores tenant data encryption keys wrapped by the master key
+type DataKeySource interface {
+	// DataKey returns a data key by ID
+	DataKey(keyID string) (*types.TenantDataKey, error)
+	// ActiveDataKey returns the tenant's active data key, creating its first one if it has none
+	ActiveDataKey(tenantID string) (*types.TenantDataKey, error)
+}
+
+// activeKey is a cached active data key of a tenant
+type activeKey struct {
+	id      string
+	expires time.Time
+}
+
+var (
+	keySource  DataKeySource
+	keysMutex  sync.RWMutex
+	dataKeys   = map[string][]byte{}     // Unwrapped data keys by key ID; keys never change
+	activeKeys = map[string]*activeKey{} // Active key of each tenant
+)
+
+// SetDataKeySource sets where tenant data keys are looked up; without one, SSNs can only be
+// read in the master key format
+func SetDataKeySource(src DataKeySource) {
+	keysMutex.Lock()
+	defer keysMutex.Unlock()
+	keySource = src
+}
+
+// NewWrappedDataKey generates a data key and returns it wrapped by the master key
+func NewWrappedDataKey() ([]byte, error) {
+	if encryptionKey == nil {
+		return nil, errors.New("encryption not initialized")
+	}
+	key := make([]byte, AES_KEY_SIZE)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("failed to generate data key: %w", err)
+	}
+	return seal(encryptionKey, key)
+}
+
+// EncryptTenantSSN encrypts an SSN under the tenant's active data key. The ciphertext carries
+// the key ID, so DecryptSSN needs no tenant.
+func EncryptTenantSSN(tenantID, ssn string) (string, error) {
+	if ssn == "" {
+		return "", nil
+	}
+
+	keyID, key, err := tenantActiveKey(tenantID)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := seal(key, []byte(ssn))
+	if err != nil {
+		return "", err
+	}
+	return SSN_ENCRYPTED_PREFIX + keyID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// ReencryptTenantSSN returns value encrypted under the tenant's active data key, and whether
+// it changed. Plaintext values and values under the master key or a retired data key are
+// re-encrypted; empty values are left alone.
+func ReencryptTenantSSN(tenantID, value string) (string, bool, error) {
+	if value == "" {
+		return value, false, nil
+	}
+
+	keyID, _, err := tenantActiveKey(tenantID)
+	if err != nil {
+		return "", false, err
+	}
+	if IsEncryptedSSN(value) && SSNKeyID(value) == keyID {
+		return value, false, nil
+	}
+
+	plain, err := DecryptSSN(value)
+	if err != nil {
+		return "", false, err
+	}
+	encrypted, err := EncryptTenantSSN(tenantID, plain)
+	if err != nil {
+		return "", false, err
+	}
+	return encrypted, true, nil
+}
+
+// SSNKeyID returns the data key ID of an encrypted SSN, or "" for SSNs under the master key
+func SSNKeyID(encryptedSSN string) string {
+	keyID, _, found := strings.Cut(strings.TrimPrefix(encryptedSSN, SSN_ENCRYPTED_PREFIX), ":")
+	if !found {
+		return ""
+	}
+	return keyID
+}
+
+// ForgetActiveDataKey drops the cached active data key of a tenant, so the next SSN written
+// uses the key just rotated in
+func ForgetActiveDataKey(tenantID string) {
+	keysMutex.Lock()
+	defer keysMutex.Unlock()
+	delete(activeKeys, tenantID)
+}
+
+// decryptKeyedSSN decrypts the part of an SSN ciphertext after the prefix in the
+// "<key ID>:<base64>" data key format
+func decryptKeyedSSN(keyID, encoded string) (string, error) {
+	key, err := dataKey(keyID)
+	if err != nil {
+		return "", err
+	}
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode encrypted SSN: %w", err)
+	}
+	plaintext, err := open(key, sealed)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt SSN under data key %s: %w", keyID, err)
+	}
+	return string(plaintext), nil
+}
+
+// tenantActiveKey returns the ID and unwrapped key of the tenant's active data key
+func tenantActiveKey(tenantID string) (string, []byte, error) {
+	keysMutex.RLock()
+	cached, ok := activeKeys[tenantID]
+	src := keySource
+	keysMutex.RUnlock()
+	if ok && time.Now().Before(cached.expires) {
+		key, err := dataKey(cached.id)
+		return cached.id, key, err
+	}
+
+	if src == nil {
+		return "", nil, errors.New("no tenant data key source configured")
+	}
+	dk, err := src.ActiveDataKey(tenantID)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get data key of tenant %s: %w", tenantID, err)
+	}
+	key, err := unwrapDataKey(dk)
+	if err != nil {
+		return "", nil, err
+	}
+
+	keysMutex.Lock()
+	activeKeys[tenantID] = &activeKey{id: dk.ID.String(), expires: time.Now().Add(activeKeyTTL)}
+	keysMutex.Unlock()
+	return dk.ID.String(), key, nil
+}
+
+// dataKey returns an unwrapped data key by ID, loading it from the key source once
+func dataKey(keyID string) ([]byte, error) {
+	keysMutex.RLock()
+	key, ok := dataKeys[keyID]
+	src := keySource
+	keysMutex.RUnlock()
+	if ok {
+		return key, nil
+	}
+
+	if src == nil {
+		return nil, errors.New("no tenant data key source configured")
+	}
+	dk, err := src.DataKey(keyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get data key %s: %w", keyID, err)
+	}
+	return unwrapDataKey(dk)
+}
+
+// unwrapDataKey decrypts a data key with the master key and caches it
+func unwrapDataKey(dk *types.TenantDataKey) ([]byte, error) {
+	if encryptionKey == nil {
+		return nil, errors.New("encryption not initialized")
+	}
+	key, err := open(encryptionKey, dk.WrappedKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unwrap data key %s: %w", dk.ID, err)
+	}
+	if len(key) != AES_KEY_SIZE {
+		return nil, fmt.Errorf("data key %s is %d bytes, expected %d", dk.ID, len(key), AES_KEY_SIZE)
+	}
+
+	keysMutex.Lock()
+	dataKeys[dk.ID.String()] = key
+	keysMutex.Unlock()
+	return key, nil
+}
+
+// seal encrypts plaintext with AES-256-GCM, returning the nonce followed by the ciphertext
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+// open decrypts the output of seal
+func open(key, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	return gcm.Open(nil, nonce, ciphertext, nil)
+}
+
+// newGCM creates an AES-GCM cipher for key
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
@@ -27,7 +27,8 @@ var (
 	encryptionKey []byte
 )
 
-// InitEncryption initializes the encryption system with a key from environment or KMS
+// InitEncryption initializes the encryption system with the master key from the environment.
+// The master key encrypts stored secrets and wraps the tenant SSN data keys (see datakeys.go).
 func InitEncryption() error {
 	// Try to get key from environment variable first (for development)
 	keyStr := os.Getenv("SSN_ENCRYPTION_KEY")
@@ -51,7 +52,8 @@ func InitEncryption() error {
 	return nil
 }
 
-// DecryptSSN decrypts an SSN using AES-256-GCM
+// DecryptSSN decrypts an SSN using AES-256-GCM, with the tenant data key named in the
+// ciphertext or, for SSNs written before tenant keys, the master key
 func DecryptSSN(encryptedSSN string) (string, error) {
 	if encryptedSSN == "" {
 		return "", nil
@@ -70,6 +72,12 @@ func DecryptSSN(encryptedSSN string) (string, error) {
 
 	// Remove prefix and decode
 	encodedData := strings.TrimPrefix(encryptedSSN, SSN_ENCRYPTED_PREFIX)
+
+	// SSNs under a tenant data key name the key; the rest are under the master key
+	if keyID, encoded, found := strings.Cut(encodedData, ":"); found {
+		return decryptKeyedSSN(keyID, encoded)
+	}
+
 	ciphertext, err := base64.StdEncoding.DecodeString(encodedData)
 	if err != nil {
 		return "", fmt.Errorf("failed to decode encrypted SSN: %w", err)
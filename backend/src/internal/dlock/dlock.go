@@ -0,0 +1,101 @@
+package dlock
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"time"
+
+	"github.com/google/logger"
+)
+
+// Recorder persists lock usage so it can be monitored across processes
+type Recorder interface {
+	LockAcquired(name, holder string, pid int) error
+	LockContended(name string) error
+	LockReleased(name string, held time.Duration) error
+}
+
+// Locker takes Postgres advisory locks in the WellTaxPro database so an operation runs in
+// at most one process at a time. Locks belong to a database session, so a crashed holder
+// releases its locks when its connection drops.
+type Locker struct {
+	db       *sql.DB
+	holder   string
+	recorder Recorder // nil records nothing
+}
+
+// New creates a locker that takes locks as holder
+func New(db *sql.DB, holder string, recorder Recorder) *Locker {
+	return &Locker{db: db, holder: holder, recorder: recorder}
+}
+
+// TryRun runs fn while holding the named lock.
+// It returns false without running fn when another process holds the lock.
+func (l *Locker) TryRun(ctx context.Context, name string, fn func() error) (bool, error) {
+	return l.run(ctx, name, false, fn)
+}
+
+// Run waits for the named lock, then runs fn while holding it
+func (l *Locker) Run(ctx context.Context, name string, fn func() error) error {
+	_, err := l.run(ctx, name, true, fn)
+	return err
+}
+
+// run acquires the lock on a dedicated connection, runs fn and releases the lock on the same connection
+func (l *Locker) run(ctx context.Context, name string, wait bool, fn func() error) (bool, error) {
+	conn, err := l.db.Conn(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get connection for lock %s: %w", name, err)
+	}
+	defer conn.Close()
+
+	var acquired bool
+	if wait {
+		_, err = conn.ExecContext(ctx, `SELECT pg_advisory_lock(hashtextextended($1, 0))`, name)
+		acquired = err == nil
+	} else {
+		err = conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock(hashtextextended($1, 0))`, name).Scan(&acquired)
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to acquire lock %s: %w", name, err)
+	}
+	if !acquired {
+		logger.Infof("Lock %s is held by another process, skipping", name)
+		if l.recorder != nil {
+			if err := l.recorder.LockContended(name); err != nil {
+				logger.Warningf("Failed to record contention on lock %s: %v", name, err)
+			}
+		}
+		return false, nil
+	}
+
+	acquiredAt := time.Now()
+	if l.recorder != nil {
+		var pid int
+		if err := conn.QueryRowContext(ctx, `SELECT pg_backend_pid()`).Scan(&pid); err != nil {
+			logger.Warningf("Failed to read backend pid for lock %s: %v", name, err)
+		}
+		if err := l.recorder.LockAcquired(name, l.holder, pid); err != nil {
+			logger.Warningf("Failed to record acquisition of lock %s: %v", name, err)
+		}
+	}
+
+	defer func() {
+		// Record the release while still holding the lock so it cannot clear the next holder
+		if l.recorder != nil {
+			if err := l.recorder.LockReleased(name, time.Since(acquiredAt)); err != nil {
+				logger.Warningf("Failed to record release of lock %s: %v", name, err)
+			}
+		}
+		// Unlock even if ctx was cancelled; a connection that cannot unlock is discarded,
+		// which ends its session and frees the lock
+		if _, err := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock(hashtextextended($1, 0))`, name); err != nil {
+			logger.Errorf("Failed to release lock %s, discarding connection: %v", name, err)
+			conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+		}
+	}()
+
+	return true, fn()
+}
@@ -0,0 +1,851 @@
+// Package fake provides in-memory stand-ins for Firebase, the store and tenant storage, so
+// middleware and handlers can be exercised with httptest without Postgres, Firebase or a bucket.
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/auth"
+	"welltaxpro/src/internal/middleware"
+	"welltaxpro/src/internal/storage"
+	"welltaxpro/src/internal/types"
+
+	firebaseauth "firebase.google.com/go/v4/auth"
+	"github.com/google/uuid"
+)
+
+var (
+	_ middleware.TokenVerifier       = (*Auth)(nil)
+	_ auth.UserAdmin                 = (*Auth)(nil)
+	_ middleware.AuthStore           = (*Store)(nil)
+	_ middleware.TenantUserAuthStore = (*Store)(nil)
+	_ middleware.AuditStore          = (*Store)(nil)
+	_ middleware.DebugCaptureStore   = (*Store)(nil)
+)
+
+// ErrInvalidToken is returned for ID tokens the fake verifier was not given
+var ErrInvalidToken = errors.New("fake: invalid ID token")
+
+// Auth verifies the ID tokens registered with AddToken and holds the Firebase accounts added
+// with AddUser; it implements middleware.TokenVerifier and auth.UserAdmin
+type Auth struct {
+	mu      sync.Mutex
+	tokens  map[string]*firebaseauth.Token
+	users   map[string]*auth.FirebaseUser
+	revoked map[string]bool
+}
+
+// NewAuth creates a verifier that accepts no tokens and has no accounts
+func NewAuth() *Auth {
+	return &Auth{
+		tokens:  map[string]*firebaseauth.Token{},
+		users:   map[string]*auth.FirebaseUser{},
+		revoked: map[string]bool{},
+	}
+}
+
+// AddUser stores a Firebase account
+func (a *Auth) AddUser(user *auth.FirebaseUser) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	stored := *user
+	a.users[user.UID] = &stored
+}
+
+// IsDisabled reports whether DisableUser was called for uid
+func (a *Auth) IsDisabled(uid string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	user, ok := a.users[uid]
+	return ok && user.Disabled
+}
+
+// IsRevoked reports whether RevokeRefreshTokens was called for uid
+func (a *Auth) IsRevoked(uid string) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.revoked[uid]
+}
+
+// DisableUser disables the account stored for uid; unknown accounts are ignored, as in Firebase
+func (a *Auth) DisableUser(ctx context.Context, uid string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if user, ok := a.users[uid]; ok {
+		user.Disabled = true
+	}
+	return nil
+}
+
+// RevokeRefreshTokens records that uid's refresh tokens were revoked
+func (a *Auth) RevokeRefreshTokens(ctx context.Context, uid string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.revoked[uid] = true
+	return nil
+}
+
+// ListUsers returns copies of the stored accounts
+func (a *Auth) ListUsers(ctx context.Context) ([]*auth.FirebaseUser, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	users := make([]*auth.FirebaseUser, 0, len(a.users))
+	for _, user := range a.users {
+		stored := *user
+		users = append(users, &stored)
+	}
+	return users, nil
+}
+
+// EmailSignInLink returns a link that embeds email and continueURL, so callers can check both
+func (a *Auth) EmailSignInLink(ctx context.Context, email, continueURL string) (string, error) {
+	return "https://fake.firebaseapp.com/__/auth/action?mode=signIn&email=" + url.QueryEscape(email) +
+		"&continueUrl=" + url.QueryEscape(continueURL), nil
+}
+
+// AddToken makes idToken verify as a sign-in of uid at authTime
+func (a *Auth) AddToken(idToken, uid string, authTime int64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.tokens[idToken] = &firebaseauth.Token{UID: uid, AuthTime: authTime}
+}
+
+// VerifyToken returns the decoded token registered for idToken
+func (a *Auth) VerifyToken(ctx context.Context, idToken string) (*firebaseauth.Token, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	token, ok := a.tokens[idToken]
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+	decoded := *token
+	return &decoded, nil
+}
+
+// ValidateToken returns the Firebase UID registered for idToken
+func (a *Auth) ValidateToken(ctx context.Context, idToken string) (*string, error) {
+	token, err := a.VerifyToken(ctx, idToken)
+	if err != nil {
+		return nil, err
+	}
+	return &token.UID, nil
+}
+
+// Store holds the records authentication reads, portal verification lockouts, the audit log and
+// tenants' affiliates and discount codes in memory; it implements middleware.AuthStore, middleware.TenantUserAuthStore,
+// middleware.AuditStore and middleware.DebugCaptureStore. Err, when set, is returned by every
+// method.
+type Store struct {
+	mu              sync.Mutex
+	employees       map[string]*types.Employee      // by Firebase UID
+	revokedSessions map[string]map[int64]bool       // auth times by Firebase UID
+	tenantContexts  map[string]*types.TenantContext // by token hash
+	tenantRoles     map[uuid.UUID]map[string]string // tenant roles by employee ID
+	blockedPortals  map[string]bool
+	portalPolicies  map[string]*types.PortalSecurityPolicy // by tenant ID
+	portalSessions  map[string]*types.PortalSession        // by tenant ID, Firebase UID and auth time
+	portalLogins    map[string]time.Time                   // by tenant ID and Firebase UID
+	portalLockouts  map[uuid.UUID]*portalLockout           // by tenant user ID
+	portalFailures  []*types.PortalVerificationFailure
+	auditLogs       []*types.AuditLog
+	affiliates      map[string]map[string]*types.Affiliate    // by tenant ID and affiliate ID
+	discountCodes   map[string]map[string]*types.DiscountCode // by tenant ID and code ID
+	Err             error
+}
+
+// NewStore creates an empty store
+func NewStore() *Store {
+	return &Store{
+		employees:       map[string]*types.Employee{},
+		revokedSessions: map[string]map[int64]bool{},
+		tenantContexts:  map[string]*types.TenantContext{},
+		tenantRoles:     map[uuid.UUID]map[string]string{},
+		blockedPortals:  map[string]bool{},
+		portalPolicies:  map[string]*types.PortalSecurityPolicy{},
+		portalSessions:  map[string]*types.PortalSession{},
+		portalLogins:    map[string]time.Time{},
+		portalLockouts:  map[uuid.UUID]*portalLockout{},
+		affiliates:      map[string]map[string]*types.Affiliate{},
+		discountCodes:   map[string]map[string]*types.DiscountCode{},
+	}
+}
+
+// AddEmployee stores an employee under its Firebase UID
+func (s *Store) AddEmployee(employee *types.Employee) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.employees[employee.FirebaseUID] = employee
+}
+
+// RevokeSession revokes the session of the sign-in of firebaseUID at authTime
+func (s *Store) RevokeSession(firebaseUID string, authTime int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.revokedSessions[firebaseUID] == nil {
+		s.revokedSessions[firebaseUID] = map[int64]bool{}
+	}
+	s.revokedSessions[firebaseUID][authTime] = true
+}
+
+// AddTenantContext stores a tenant context under the hash of its token
+func (s *Store) AddTenantContext(tokenHash string, tc *types.TenantContext) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tenantContexts[tokenHash] = tc
+}
+
+// GrantTenantRole gives an employee active access to a tenant with role
+func (s *Store) GrantTenantRole(employeeID uuid.UUID, tenantID, role string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.tenantRoles[employeeID] == nil {
+		s.tenantRoles[employeeID] = map[string]string{}
+	}
+	s.tenantRoles[employeeID][tenantID] = role
+}
+
+// BlockPortal closes a tenant's portal, as offboarding does
+func (s *Store) BlockPortal(tenantID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.blockedPortals[tenantID] = true
+}
+
+// IsEmployeeSessionRevoked reports whether RevokeSession was called for the sign-in
+func (s *Store) IsEmployeeSessionRevoked(firebaseUID string, authTime int64) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return false, s.Err
+	}
+	return s.revokedSessions[firebaseUID][authTime], nil
+}
+
+// GetEmployeeByFirebaseUID returns a copy of the employee stored for firebaseUID
+func (s *Store) GetEmployeeByFirebaseUID(firebaseUID string) (*types.Employee, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	employee, ok := s.employees[firebaseUID]
+	if !ok {
+		return nil, apperr.NotFound("employee not found")
+	}
+	found := *employee
+	return &found, nil
+}
+
+// GetTenantContext returns a copy of the tenant context stored for tokenHash
+func (s *Store) GetTenantContext(tokenHash string) (*types.TenantContext, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	tc, ok := s.tenantContexts[tokenHash]
+	if !ok {
+		return nil, apperr.NotFound("tenant context not found or expired")
+	}
+	found := *tc
+	return &found, nil
+}
+
+// GetEmployeeTenantRole returns the role GrantTenantRole gave the employee in the tenant
+func (s *Store) GetEmployeeTenantRole(employeeID uuid.UUID, tenantID string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return "", s.Err
+	}
+	role, ok := s.tenantRoles[employeeID][tenantID]
+	if !ok {
+		return "", apperr.NotFound("employee %s has no access to tenant %s", employeeID, tenantID)
+	}
+	return role, nil
+}
+
+// IsTenantPortalBlocked reports whether BlockPortal was called for the tenant
+func (s *Store) IsTenantPortalBlocked(tenantID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return false, s.Err
+	}
+	return s.blockedPortals[tenantID], nil
+}
+
+// SetPortalSecurityPolicy gives a tenant a portal security policy
+func (s *Store) SetPortalSecurityPolicy(tenantID string, policy *types.PortalSecurityPolicy) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *policy
+	s.portalPolicies[tenantID] = &stored
+}
+
+// VerifyPortalSession marks the portal sign-in of firebaseUID at authTime verified with method,
+// recording it if TouchPortalSession has not yet
+func (s *Store) VerifyPortalSession(tenantID, firebaseUID string, authTime int64, method string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	session := s.portalSession(tenantID, firebaseUID, authTime)
+	now := time.Now()
+	session.VerifiedAt, session.VerificationMethod = &now, &method
+}
+
+// GetPortalSecurityPolicy returns the policy SetPortalSecurityPolicy gave the tenant, or the default
+func (s *Store) GetPortalSecurityPolicy(tenantID string) (*types.PortalSecurityPolicy, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	policy, ok := s.portalPolicies[tenantID]
+	if !ok {
+		return types.DefaultPortalSecurityPolicy(), nil
+	}
+	found := *policy
+	return &found, nil
+}
+
+// RecordTenantUserLogin records the portal sign-in of firebaseUID at loginAt unless a later one
+// is recorded; every Firebase UID counts as a tenant user
+func (s *Store) RecordTenantUserLogin(tenantID, firebaseUID string, loginAt time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return false, s.Err
+	}
+	key := tenantID + "/" + firebaseUID
+	if last, ok := s.portalLogins[key]; ok && !last.Before(loginAt) {
+		return false, nil
+	}
+	s.portalLogins[key] = loginAt
+	return true, nil
+}
+
+// LastPortalLogin returns the latest portal sign-in RecordTenantUserLogin recorded for firebaseUID
+func (s *Store) LastPortalLogin(tenantID, firebaseUID string) (time.Time, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	last, ok := s.portalLogins[tenantID+"/"+firebaseUID]
+	return last, ok
+}
+
+// TouchPortalSession returns a copy of the portal sign-in's session, ending it as the store does
+// when it is older than policy allows. New sign-ins reuse verifications within the policy's reuse
+// window and end the user's oldest sessions beyond its concurrent session limit.
+func (s *Store) TouchPortalSession(tenantID, firebaseUID string, authTime int64, policy *types.PortalSecurityPolicy) (*types.PortalSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+
+	now := time.Now()
+	key := portalSessionKey(tenantID, firebaseUID, authTime)
+	session, ok := s.portalSessions[key]
+	if !ok {
+		var active []*types.PortalSession
+		for _, other := range s.portalSessions {
+			if other.TenantID != tenantID || other.FirebaseUID != firebaseUID {
+				continue
+			}
+			if other.EndedAt == nil {
+				active = append(active, other)
+			}
+		}
+
+		session = s.portalSession(tenantID, firebaseUID, authTime)
+		if policy.RequiresVerification() && policy.VerificationReuseMinutes > 0 {
+			window := now.Add(-time.Duration(policy.VerificationReuseMinutes) * time.Minute)
+			for _, other := range s.portalSessions {
+				if other.TenantID == tenantID && other.FirebaseUID == firebaseUID && other.VerifiedAt != nil && other.VerifiedAt.After(window) {
+					session.VerifiedAt, session.VerificationMethod = other.VerifiedAt, other.VerificationMethod
+				}
+			}
+		}
+
+		active = append(active, session)
+		if policy.MaxConcurrentSessions > 0 && len(active) > policy.MaxConcurrentSessions {
+			sort.Slice(active, func(i, j int) bool { return active[i].AuthTime > active[j].AuthTime })
+			reason := types.PortalSessionEndLimit
+			for _, ended := range active[policy.MaxConcurrentSessions:] {
+				ended.EndedAt, ended.EndReason = &now, &reason
+			}
+		}
+	}
+
+	session.ExpiresAt = policy.SessionExpiry(authTime)
+	if session.EndedAt == nil && session.ExpiresAt != nil && now.After(*session.ExpiresAt) {
+		reason := types.PortalSessionEndExpired
+		session.EndedAt, session.EndReason = &now, &reason
+	}
+	session.LastSeenAt = now
+
+	found := *session
+	return &found, nil
+}
+
+// portalSession returns the stored session of a portal sign-in, recording it when it is new
+func (s *Store) portalSession(tenantID, firebaseUID string, authTime int64) *types.PortalSession {
+	key := portalSessionKey(tenantID, firebaseUID, authTime)
+	session, ok := s.portalSessions[key]
+	if !ok {
+		now := time.Now()
+		session = &types.PortalSession{
+			ID:          uuid.New(),
+			TenantID:    tenantID,
+			FirebaseUID: firebaseUID,
+			AuthTime:    authTime,
+			CreatedAt:   now,
+			LastSeenAt:  now,
+		}
+		s.portalSessions[key] = session
+	}
+	return session
+}
+
+func portalSessionKey(tenantID, firebaseUID string, authTime int64) string {
+	return fmt.Sprintf("%s\x00%s\x00%d", tenantID, firebaseUID, authTime)
+}
+
+// portalLockout is a tenant user's count of wrong verification answers and lock
+type portalLockout struct {
+	tenantID string
+	clientID uuid.UUID
+	failures int
+	lockedAt *time.Time
+}
+
+// RecordPortalVerification verifies the session with method, or counts a wrong answer against it
+// and ends it after PortalVerificationMaxAttempts, as the store does
+func (s *Store) RecordPortalVerification(sessionID uuid.UUID, method string, verified bool) (*types.PortalSession, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	for _, session := range s.portalSessions {
+		if session.ID != sessionID || session.EndedAt != nil {
+			continue
+		}
+		now := time.Now()
+		if verified {
+			session.VerifiedAt, session.VerificationMethod = &now, &method
+		} else {
+			session.FailedAttempts++
+			if session.FailedAttempts >= types.PortalVerificationMaxAttempts {
+				reason := types.PortalSessionEndVerificationFailed
+				session.EndedAt, session.EndReason = &now, &reason
+			}
+		}
+		found := *session
+		return &found, nil
+	}
+	return nil, apperr.NotFound("portal session not found or ended: %s", sessionID)
+}
+
+// GetPortalVerificationLock returns when the tenant user was locked out, or nil
+func (s *Store) GetPortalVerificationLock(tenantUserID uuid.UUID) (*time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	if lockout, ok := s.portalLockouts[tenantUserID]; ok {
+		return lockout.lockedAt, nil
+	}
+	return nil, nil
+}
+
+// RecordPortalVerificationFailure counts a wrong answer against the tenant user, locking them out
+// at PortalVerificationLockAttempts, and stores the failure
+func (s *Store) RecordPortalVerificationFailure(failure *types.PortalVerificationFailure) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	lockout, ok := s.portalLockouts[failure.TenantUserID]
+	if !ok {
+		lockout = &portalLockout{tenantID: failure.TenantID, clientID: failure.ClientID}
+		s.portalLockouts[failure.TenantUserID] = lockout
+	}
+	lockout.failures++
+	failure.Failures = lockout.failures
+	failure.Locked = lockout.lockedAt == nil && failure.Failures >= types.PortalVerificationLockAttempts
+	if failure.Locked {
+		now := time.Now()
+		lockout.lockedAt = &now
+	}
+	failure.ID, failure.FailedAt = uuid.New(), time.Now()
+	stored := *failure
+	s.portalFailures = append(s.portalFailures, &stored)
+	return nil
+}
+
+// ResetPortalVerificationFailures clears the tenant user's count of wrong answers
+func (s *Store) ResetPortalVerificationFailures(tenantUserID uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	if lockout, ok := s.portalLockouts[tenantUserID]; ok {
+		lockout.failures = 0
+	}
+	return nil
+}
+
+// UnlockPortalVerification lifts the lockout of the client's portal user and reports whether
+// they were locked out
+func (s *Store) UnlockPortalVerification(tenantID string, clientID uuid.UUID) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return false, s.Err
+	}
+	unlocked := false
+	for _, lockout := range s.portalLockouts {
+		if lockout.tenantID == tenantID && lockout.clientID == clientID && lockout.lockedAt != nil {
+			lockout.failures, lockout.lockedAt = 0, nil
+			unlocked = true
+		}
+	}
+	return unlocked, nil
+}
+
+// GetPortalVerificationFailures returns the client's stored failures, newest first
+func (s *Store) GetPortalVerificationFailures(tenantID string, clientID uuid.UUID, limit int) ([]*types.PortalVerificationFailure, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	var failures []*types.PortalVerificationFailure
+	for i := len(s.portalFailures) - 1; i >= 0 && len(failures) < limit; i-- {
+		if failure := s.portalFailures[i]; failure.TenantID == tenantID && failure.ClientID == clientID {
+			stored := *failure
+			failures = append(failures, &stored)
+		}
+	}
+	return failures, nil
+}
+
+// CreateAuditLog stores an audit log entry; details are not kept
+func (s *Store) CreateAuditLog(employeeID uuid.UUID, tenantID string, clientID *uuid.UUID, action string, resourceType string, resourceID *uuid.UUID, details interface{}, ipAddress *string, userAgent *string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.auditLogs = append(s.auditLogs, &types.AuditLog{
+		ID:           uuid.New(),
+		EmployeeID:   employeeID,
+		TenantID:     tenantID,
+		ClientID:     clientID,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+		CreatedAt:    time.Now(),
+	})
+	return nil
+}
+
+// CreatePortalAuditLog stores a portal user's audit log entry; details are not kept
+func (s *Store) CreatePortalAuditLog(tenantUserID uuid.UUID, tenantID string, clientID *uuid.UUID, action string, resourceType string, resourceID *uuid.UUID, details interface{}, ipAddress *string, userAgent *string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return s.Err
+	}
+	s.auditLogs = append(s.auditLogs, &types.AuditLog{
+		ID:           uuid.New(),
+		TenantUserID: &tenantUserID,
+		TenantID:     tenantID,
+		ClientID:     clientID,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+		CreatedAt:    time.Now(),
+	})
+	return nil
+}
+
+// AuditLogs returns copies of the stored audit log entries, oldest first
+func (s *Store) AuditLogs() []*types.AuditLog {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	logs := make([]*types.AuditLog, 0, len(s.auditLogs))
+	for _, entry := range s.auditLogs {
+		stored := *entry
+		logs = append(logs, &stored)
+	}
+	return logs
+}
+
+// GetActiveDebugCapture finds no capture; no tenant's requests are captured
+func (s *Store) GetActiveDebugCapture(tenantID string) (*types.DebugCapture, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return nil, s.Err
+}
+
+// RecordDebugCaptureEntry discards the entry
+func (s *Store) RecordDebugCaptureEntry(entry *types.DebugCaptureEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.Err
+}
+
+// AddAffiliate stores a copy of an affiliate of a tenant, giving it an ID if it has none
+func (s *Store) AddAffiliate(tenantID string, affiliate *types.Affiliate) *types.Affiliate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *affiliate
+	if stored.ID == uuid.Nil {
+		stored.ID = uuid.New()
+	}
+	if s.affiliates[tenantID] == nil {
+		s.affiliates[tenantID] = map[string]*types.Affiliate{}
+	}
+	s.affiliates[tenantID][stored.ID.String()] = &stored
+	found := stored
+	return &found
+}
+
+// GetAffiliateByID returns a copy of the tenant's affiliate
+func (s *Store) GetAffiliateByID(ctx context.Context, tenantID string, affiliateID string) (*types.Affiliate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	affiliate, ok := s.affiliates[tenantID][affiliateID]
+	if !ok {
+		return nil, apperr.NotFound("affiliate not found")
+	}
+	found := *affiliate
+	return &found, nil
+}
+
+// GetAffiliates returns copies of the tenant's affiliates by name
+func (s *Store) GetAffiliates(ctx context.Context, tenantID string, activeOnly bool) ([]*types.Affiliate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	affiliates := []*types.Affiliate{}
+	for _, affiliate := range s.affiliates[tenantID] {
+		if activeOnly && !affiliate.IsActive {
+			continue
+		}
+		found := *affiliate
+		affiliates = append(affiliates, &found)
+	}
+	sort.Slice(affiliates, func(i, j int) bool {
+		return affiliates[i].LastName+affiliates[i].FirstName < affiliates[j].LastName+affiliates[j].FirstName
+	})
+	return affiliates, nil
+}
+
+// PatchAffiliate changes the fields the patch sets, as the tenant adapters do
+func (s *Store) PatchAffiliate(ctx context.Context, tenantID string, affiliateID string, patch *types.AffiliatePatch) (*types.Affiliate, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	affiliate, ok := s.affiliates[tenantID][affiliateID]
+	if !ok {
+		return nil, apperr.NotFound("affiliate not found")
+	}
+
+	if patch.FirstName != nil {
+		affiliate.FirstName = *patch.FirstName
+	}
+	if patch.LastName != nil {
+		affiliate.LastName = *patch.LastName
+	}
+	if patch.Email != nil {
+		affiliate.Email = *patch.Email
+	}
+	if patch.Phone != nil {
+		affiliate.Phone = nil
+		if *patch.Phone != "" {
+			phone := *patch.Phone
+			affiliate.Phone = &phone
+		}
+	}
+	if patch.DefaultCommissionRate != nil {
+		affiliate.DefaultCommissionRate = *patch.DefaultCommissionRate
+	}
+	if patch.PayoutMethod != nil {
+		affiliate.PayoutMethod = *patch.PayoutMethod
+	}
+	if patch.PayoutThreshold != nil {
+		affiliate.PayoutThreshold = *patch.PayoutThreshold
+	}
+	if patch.IsActive != nil {
+		affiliate.IsActive = *patch.IsActive
+	}
+	now := time.Now()
+	affiliate.UpdatedAt = &now
+
+	found := *affiliate
+	return &found, nil
+}
+
+// AddDiscountCode stores a copy of a discount code of a tenant, giving it an ID if it has none
+func (s *Store) AddDiscountCode(tenantID string, code *types.DiscountCode) *types.DiscountCode {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stored := *code
+	if stored.ID == uuid.Nil {
+		stored.ID = uuid.New()
+	}
+	if s.discountCodes[tenantID] == nil {
+		s.discountCodes[tenantID] = map[string]*types.DiscountCode{}
+	}
+	s.discountCodes[tenantID][stored.ID.String()] = &stored
+	found := stored
+	return &found
+}
+
+// GetDiscountCodeByID returns a copy of the tenant's discount code
+func (s *Store) GetDiscountCodeByID(ctx context.Context, tenantID string, codeID string) (*types.DiscountCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	code, ok := s.discountCodes[tenantID][codeID]
+	if !ok {
+		return nil, apperr.NotFound("discount code not found")
+	}
+	found := *code
+	return &found, nil
+}
+
+// CreateDiscountCode stores a new discount code with its code upper-cased; codes are unique
+// within a tenant
+func (s *Store) CreateDiscountCode(ctx context.Context, tenantID string, discountCode *types.DiscountCode) (*types.DiscountCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	code := strings.ToUpper(discountCode.Code)
+	for _, existing := range s.discountCodes[tenantID] {
+		if existing.Code == code {
+			return nil, apperr.Conflict("discount code %s already exists", code)
+		}
+	}
+
+	stored := *discountCode
+	stored.ID = uuid.New()
+	stored.Code = code
+	stored.CreatedAt = time.Now().UTC().Format("2006-01-02 15:04:05")
+	if s.discountCodes[tenantID] == nil {
+		s.discountCodes[tenantID] = map[string]*types.DiscountCode{}
+	}
+	s.discountCodes[tenantID][stored.ID.String()] = &stored
+	found := stored
+	return &found, nil
+}
+
+// PatchDiscountCode changes the fields the patch sets, as the tenant adapters do
+func (s *Store) PatchDiscountCode(ctx context.Context, tenantID string, codeID string, patch *types.DiscountCodePatch) (*types.DiscountCode, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	code, ok := s.discountCodes[tenantID][codeID]
+	if !ok {
+		return nil, apperr.NotFound("discount code not found")
+	}
+
+	// "" clears the optional text fields
+	clearable := func(value string) *string {
+		if value == "" {
+			return nil
+		}
+		return &value
+	}
+	if patch.Code != nil {
+		code.Code = strings.ToUpper(*patch.Code)
+	}
+	if patch.Description != nil {
+		code.Description = clearable(*patch.Description)
+	}
+	if patch.DiscountType != nil {
+		code.DiscountType = *patch.DiscountType
+	}
+	if patch.DiscountValue != nil {
+		code.DiscountValue = *patch.DiscountValue
+	}
+	if patch.MaxUses != nil {
+		maxUses := *patch.MaxUses
+		code.MaxUses = &maxUses
+	}
+	if patch.ValidFrom != nil {
+		code.ValidFrom = clearable(*patch.ValidFrom)
+	}
+	if patch.ValidUntil != nil {
+		code.ValidUntil = clearable(*patch.ValidUntil)
+	}
+	if patch.IsActive != nil {
+		code.IsActive = *patch.IsActive
+	}
+	if patch.CommissionRate != nil {
+		rate := *patch.CommissionRate
+		code.CommissionRate = &rate
+	}
+	updatedAt := time.Now().UTC().Format("2006-01-02 15:04:05")
+	code.UpdatedAt = &updatedAt
+
+	found := *code
+	return &found, nil
+}
+
+// Storage hands out one memory provider per tenant; its ForTenant method can replace
+// storage.NewStorageProviderForTenant
+type Storage struct {
+	mu        sync.Mutex
+	providers map[string]*storage.MemoryProvider
+	Err       error // returned instead of a provider when set
+}
+
+// NewStorage creates storage with no tenant files
+func NewStorage() *Storage {
+	return &Storage{providers: map[string]*storage.MemoryProvider{}}
+}
+
+// ForTenant returns the tenant's memory provider, creating it on first use
+func (s *Storage) ForTenant(ctx context.Context, tc *types.TenantConnection) (storage.StorageProvider, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.Err != nil {
+		return nil, s.Err
+	}
+	provider, ok := s.providers[tc.TenantID]
+	if !ok {
+		provider = storage.NewMemoryProvider()
+		s.providers[tc.TenantID] = provider
+	}
+	return provider, nil
+}
@@ -0,0 +1,178 @@
+package idcheck
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/logger"
+)
+
+// Extractor defines the interface for text recognition providers used on identity documents
+type Extractor interface {
+	// Extract returns the text printed on a JPEG or PNG image
+	Extract(ctx context.Context, image []byte) (string, error)
+
+	// Name returns the provider identifier stored with each check
+	Name() string
+}
+
+// Config selects and configures the text recognition provider
+type Config struct {
+	Provider string // "vision" or empty to skip text recognition
+	APIKey   string
+}
+
+// NewExtractor creates the configured text extractor
+// Falls back to no recognition when no provider is configured, so every upload goes to review
+func NewExtractor(cfg Config) Extractor {
+	switch cfg.Provider {
+	case "vision", "google":
+		if cfg.APIKey == "" {
+			logger.Warning("Google Vision ID checks configured without an API key, sending all uploads to review")
+			return &NoopExtractor{}
+		}
+		return NewVisionExtractor(cfg.APIKey)
+	default:
+		return &NoopExtractor{}
+	}
+}
+
+// NoopExtractor reads no text; checks on its output always flag the document for review
+type NoopExtractor struct{}
+
+// Name returns the provider identifier
+func (e *NoopExtractor) Name() string {
+	return "none"
+}
+
+// Extract returns no text
+func (e *NoopExtractor) Extract(ctx context.Context, image []byte) (string, error) {
+	return "", nil
+}
+
+// Check flag constants
+const (
+	FlagTextUnreadable   = "TEXT_UNREADABLE"   // No text could be read from the image
+	FlagExpiryUnreadable = "EXPIRY_UNREADABLE" // No expiration date was found
+	FlagExpired          = "EXPIRED"           // The expiration date has passed
+	FlagNameMismatch     = "NAME_MISMATCH"     // The client's name was not found on the document
+	FlagNoClientRecord   = "NO_CLIENT_RECORD"  // There is no client record to compare against yet
+)
+
+// Result is the outcome of the automated checks on one document
+type Result struct {
+	ExpiresOn   *time.Time
+	NameMatched *bool // nil when there was no name to compare
+	Flags       []string
+}
+
+// expiryWindow is how many characters after an expiry label are searched for its date
+const expiryWindow = 40
+
+var (
+	datePattern = regexp.MustCompile(`\b(\d{1,2})[/-](\d{1,2})[/-](\d{4})\b|\b(\d{4})-(\d{2})-(\d{2})\b`)
+	expiryLabel = regexp.MustCompile(`(?i)\bEXP(?:IRES|IRATION|IRY)?(?:\s+DATE)?\b`)
+)
+
+// Check runs the expiry and name checks on text read from an identity document.
+// firstName and lastName are empty when the uploader has no client record yet.
+func Check(text, firstName, lastName string, now time.Time) *Result {
+	result := &Result{}
+	if strings.TrimSpace(text) == "" {
+		result.Flags = append(result.Flags, FlagTextUnreadable)
+		if firstName == "" && lastName == "" {
+			result.Flags = append(result.Flags, FlagNoClientRecord)
+		}
+		return result
+	}
+
+	result.ExpiresOn = findExpiry(text)
+	switch {
+	case result.ExpiresOn == nil:
+		result.Flags = append(result.Flags, FlagExpiryUnreadable)
+	case result.ExpiresOn.Before(now.Truncate(24 * time.Hour)):
+		result.Flags = append(result.Flags, FlagExpired)
+	}
+
+	if firstName == "" && lastName == "" {
+		result.Flags = append(result.Flags, FlagNoClientRecord)
+		return result
+	}
+	matched := nameMatches(text, firstName, lastName)
+	result.NameMatched = &matched
+	if !matched {
+		result.Flags = append(result.Flags, FlagNameMismatch)
+	}
+
+	return result
+}
+
+// findExpiry returns the date following an expiry label, or else the latest date on the document.
+// Licenses print birth, issue and expiry dates, and the expiry is always the latest of them.
+func findExpiry(text string) *time.Time {
+	for _, loc := range expiryLabel.FindAllStringIndex(text, -1) {
+		end := loc[1] + expiryWindow
+		if end > len(text) {
+			end = len(text)
+		}
+		if m := datePattern.FindStringSubmatch(text[loc[1]:end]); m != nil {
+			if d, ok := parseDate(m); ok {
+				return &d
+			}
+		}
+	}
+
+	var latest *time.Time
+	for _, m := range datePattern.FindAllStringSubmatch(text, -1) {
+		if d, ok := parseDate(m); ok && (latest == nil || d.After(*latest)) {
+			latest = &d
+		}
+	}
+	return latest
+}
+
+// parseDate converts a datePattern match (MM/DD/YYYY, MM-DD-YYYY or YYYY-MM-DD) to a date
+func parseDate(m []string) (time.Time, bool) {
+	layout, value := "1/2/2006", m[1]+"/"+m[2]+"/"+m[3]
+	if m[4] != "" {
+		layout, value = "2006-01-02", m[4]+"-"+m[5]+"-"+m[6]
+	}
+	d, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return d, true
+}
+
+// nameMatches reports whether every part of the client's first and last name appears on the document.
+// Single letters (middle initials) are ignored; comparison ignores case and punctuation.
+func nameMatches(text, firstName, lastName string) bool {
+	words := map[string]bool{}
+	for _, w := range nameTokens(text) {
+		words[w] = true
+	}
+
+	parts := nameTokens(firstName + " " + lastName)
+	found := 0
+	for _, p := range parts {
+		if len(p) < 2 {
+			continue
+		}
+		if !words[p] {
+			return false
+		}
+		found++
+	}
+	return found > 0
+}
+
+// nameTokens splits s into upper-cased words of letters, dropping apostrophes so O'NEIL matches ONEIL
+func nameTokens(s string) []string {
+	s = strings.NewReplacer("'", "", "’", "").Replace(strings.ToUpper(s))
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+}
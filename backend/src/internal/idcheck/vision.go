@@ -0,0 +1,104 @@
+package idcheck
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/google/logger"
+)
+
+const visionAnnotateURL = "https://vision.googleapis.com/v1/images:annotate"
+
+// VisionExtractor implements Extractor using the Google Cloud Vision document text detection API
+type VisionExtractor struct {
+	apiKey string
+	client *http.Client
+}
+
+// NewVisionExtractor creates a Google Cloud Vision extractor
+func NewVisionExtractor(apiKey string) *VisionExtractor {
+	return &VisionExtractor{
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 20 * time.Second},
+	}
+}
+
+// Name returns the provider identifier
+func (e *VisionExtractor) Name() string {
+	return "vision"
+}
+
+type visionRequest struct {
+	Requests []visionImageRequest `json:"requests"`
+}
+
+type visionImageRequest struct {
+	Image struct {
+		Content string `json:"content"` // base64 encoded image
+	} `json:"image"`
+	Features []struct {
+		Type string `json:"type"`
+	} `json:"features"`
+}
+
+type visionResponse struct {
+	Responses []struct {
+		FullTextAnnotation struct {
+			Text string `json:"text"`
+		} `json:"fullTextAnnotation"`
+		Error *struct {
+			Message string `json:"message"`
+		} `json:"error"`
+	} `json:"responses"`
+}
+
+// Extract sends the image to Vision and returns the detected text
+func (e *VisionExtractor) Extract(ctx context.Context, image []byte) (string, error) {
+	imageReq := visionImageRequest{}
+	imageReq.Image.Content = base64.StdEncoding.EncodeToString(image)
+	imageReq.Features = []struct {
+		Type string `json:"type"`
+	}{{Type: "DOCUMENT_TEXT_DETECTION"}}
+
+	body, err := json.Marshal(visionRequest{Requests: []visionImageRequest{imageReq}})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode text detection request: %w", err)
+	}
+
+	endpoint := visionAnnotateURL + "?" + url.Values{"key": {e.apiKey}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("failed to build text detection request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		logger.Errorf("Google Vision request failed: %v", err)
+		return "", fmt.Errorf("text detection request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("text detection provider returned status %d", resp.StatusCode)
+	}
+
+	var result visionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode text detection response: %w", err)
+	}
+	if len(result.Responses) == 0 {
+		return "", nil
+	}
+	if result.Responses[0].Error != nil {
+		return "", fmt.Errorf("text detection failed: %s", result.Responses[0].Error.Message)
+	}
+
+	return result.Responses[0].FullTextAnnotation.Text, nil
+}
@@ -0,0 +1,34 @@
+// Package idgen generates the IDs of new tenant records.
+//
+// Version 7 UUIDs begin with a millisecond timestamp, and IDs from one process are strictly
+// increasing, so rows inserted together stay together in primary key indexes and ID ranges
+// follow creation order. Random version 4 UUIDs scatter inserts across the whole index.
+package idgen
+
+import (
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// Generator returns a new record ID
+type Generator func() uuid.UUID
+
+// ForVersion returns the generator of a tenant ID version (types.IDVersionV7 or types.IDVersionV4).
+// Unknown versions get version 7.
+func ForVersion(version string) Generator {
+	if version == types.IDVersionV4 {
+		return uuid.New
+	}
+	return NewV7
+}
+
+// NewV7 returns a time-ordered version 7 UUID. A random version 4 UUID is returned in the
+// unlikely case the random source fails, since an ID out of order is better than no record.
+func NewV7() uuid.UUID {
+	id, err := uuid.NewV7()
+	if err != nil {
+		return uuid.New()
+	}
+	return id
+}
@@ -0,0 +1,377 @@
+package ingest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/scan"
+	"welltaxpro/src/internal/storage"
+	"welltaxpro/src/internal/store"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// maxFileSize is the largest file imported from a drop, the same as a document upload
+const maxFileSize = 10 << 20
+
+// errFileTooLarge marks a delivered file that can never be imported
+var errFileTooLarge = errors.New("file is larger than 10 MB")
+
+// Config configures document drop ingestion
+type Config struct {
+	SFTPRoot string       // Directory the SFTP server stores partner uploads under; empty disables SFTP drops
+	Scanner  scan.Scanner // Malware scanner run on imported files; nil leaves them unscanned
+}
+
+// Ingester imports partner document batches from drop locations into tenant documents
+type Ingester struct {
+	store  *store.Store
+	config Config
+	holder string
+}
+
+// New creates an ingester that takes drop locks as holder
+func New(s *store.Store, config Config, holder string) *Ingester {
+	return &Ingester{store: s, config: config, holder: holder}
+}
+
+// lockName is the distributed lock serializing scans and resolutions of a drop
+func lockName(dropID uuid.UUID) string {
+	return "document_drop:" + dropID.String()
+}
+
+// ValidateDrop checks and normalizes a new drop's settings
+func (in *Ingester) ValidateDrop(d *types.DocumentDrop) error {
+	d.Name = strings.TrimSpace(d.Name)
+	d.Location = strings.Trim(strings.TrimSpace(d.Location), "/")
+	d.Prefix = strings.Trim(strings.TrimSpace(d.Prefix), "/")
+	d.DocumentType = strings.TrimSpace(d.DocumentType)
+
+	if d.Name == "" || d.Location == "" {
+		return apperr.Validation("name and location are required")
+	}
+	switch d.SourceType {
+	case types.DocumentDropSourceGCS:
+	case types.DocumentDropSourceSFTP:
+		if in.config.SFTPRoot == "" {
+			return apperr.Validation("SFTP drops are disabled: ingest.sftpRoot is not configured")
+		}
+		if !isRelativePath(d.Location) {
+			return apperr.Validation("SFTP location must be a directory relative to the SFTP root")
+		}
+	default:
+		return apperr.Validation("sourceType must be %s or %s", types.DocumentDropSourceGCS, types.DocumentDropSourceSFTP)
+	}
+	if d.Prefix != "" {
+		if !isRelativePath(d.Prefix) {
+			return apperr.Validation("invalid prefix %q", d.Prefix)
+		}
+		d.Prefix += "/"
+	}
+	if d.DocumentType == "" {
+		d.DocumentType = "W2"
+	}
+	return nil
+}
+
+// ScanAll scans every active drop, continuing past drops that fail
+func (in *Ingester) ScanAll(ctx context.Context) ([]*types.DocumentDropScan, error) {
+	drops, err := in.store.GetActiveDocumentDrops()
+	if err != nil {
+		return nil, err
+	}
+
+	scans := make([]*types.DocumentDropScan, 0, len(drops))
+	for _, drop := range drops {
+		scan, err := in.Scan(ctx, drop)
+		if err != nil {
+			logger.Warningf("Skipped document drop %s for tenant %s: %v", drop.Name, drop.TenantID, err)
+			continue
+		}
+		scans = append(scans, scan)
+	}
+	return scans, nil
+}
+
+// Scan imports the files delivered to a drop since its last scan. A batch is only read once its
+// manifest.csv has arrived. Files the manifest cannot match to a client and filing are recorded
+// as unmatched for an admin to resolve. Failures that may be temporary leave the file to retry.
+func (in *Ingester) Scan(ctx context.Context, drop *types.DocumentDrop) (*types.DocumentDropScan, error) {
+	result := &types.DocumentDropScan{DropID: drop.ID, TenantID: drop.TenantID, Name: drop.Name, Errors: []string{}}
+
+	ran, err := in.store.Locker(in.holder).TryRun(ctx, lockName(drop.ID), func() error {
+		if err := in.scan(ctx, drop, result); err != nil {
+			result.Errors = append(result.Errors, err.Error())
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ran {
+		return nil, apperr.Conflict("document drop %s is already being scanned", drop.Name)
+	}
+
+	var lastError *string
+	if len(result.Errors) > 0 {
+		joined := strings.Join(result.Errors, "; ")
+		lastError = &joined
+		result.NewError = drop.LastError == nil || *drop.LastError != joined
+	}
+	if err := in.store.MarkDocumentDropScanned(drop.ID, lastError); err != nil {
+		logger.Errorf("Failed to record scan of document drop %s: %v", drop.ID, err)
+	}
+
+	logger.Infof("Scanned document drop %s for tenant %s: %d imported, %d unmatched, %d errors",
+		drop.Name, drop.TenantID, result.Imported, result.Unmatched, len(result.Errors))
+	return result, nil
+}
+
+func (in *Ingester) scan(ctx context.Context, drop *types.DocumentDrop, result *types.DocumentDropScan) error {
+	src, err := in.openSource(ctx, drop)
+	if err != nil {
+		return err
+	}
+	paths, err := src.List(ctx)
+	if err != nil {
+		return err
+	}
+	seen, err := in.store.GetDocumentDropPaths(drop.ID)
+	if err != nil {
+		return err
+	}
+
+	// Group new files by batch directory
+	batches := map[string][]string{}
+	complete := map[string]bool{}
+	for _, p := range paths {
+		batch := path.Dir(p)
+		if path.Base(p) == types.DropManifestName {
+			complete[batch] = true
+		} else if !seen[p] {
+			batches[batch] = append(batches[batch], p)
+		}
+	}
+
+	names := make([]string, 0, len(batches))
+	for batch := range batches {
+		if complete[batch] {
+			names = append(names, batch)
+		}
+	}
+	sort.Strings(names)
+
+	m := &matcher{store: in.store, tenantID: drop.TenantID}
+	for _, batch := range names {
+		entries, err := readManifest(ctx, src, path.Join(batch, types.DropManifestName))
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", batch, err))
+			continue
+		}
+		for _, p := range batches[batch] {
+			in.takeFile(ctx, src, drop, batch, p, entries[path.Base(p)], m, result)
+		}
+	}
+	return nil
+}
+
+// readManifest opens and parses a batch manifest
+func readManifest(ctx context.Context, src source, p string) (map[string]*manifestEntry, error) {
+	rc, err := src.Open(ctx, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open manifest: %w", err)
+	}
+	defer rc.Close()
+	return parseManifest(rc)
+}
+
+// takeFile matches and imports one delivered file and records the outcome
+func (in *Ingester) takeFile(ctx context.Context, src source, drop *types.DocumentDrop, batch, p string, entry *manifestEntry, m *matcher, result *types.DocumentDropScan) {
+	file := &types.DocumentDropFile{
+		DropID:   drop.ID,
+		TenantID: drop.TenantID,
+		Path:     p,
+		Batch:    batch,
+		FileName: path.Base(p),
+		Status:   types.DropFileStatusUnmatched,
+	}
+
+	reason := "not listed in the batch manifest"
+	if entry != nil {
+		clientID, filingID, why, err := m.match(ctx, entry)
+		if err != nil {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", p, err))
+			return
+		}
+		reason = why
+		if reason == "" {
+			documentType := entry.DocumentType
+			if documentType == "" {
+				documentType = drop.DocumentType
+			}
+			document, err := in.importFile(ctx, src, drop.TenantID, p, clientID, filingID, documentType)
+			switch {
+			case errors.Is(err, errFileTooLarge):
+				reason = err.Error()
+			case err != nil:
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", p, err))
+				return
+			default:
+				file.Status = types.DropFileStatusImported
+				file.ClientID, file.FilingID, file.DocumentID = &clientID, &filingID, &document.ID
+			}
+		}
+	}
+	if file.Status == types.DropFileStatusUnmatched {
+		file.Reason = &reason
+	}
+
+	if err := in.store.RecordDocumentDropFile(file); err != nil {
+		// Remove the import so the retry on the next scan does not duplicate it
+		if file.DocumentID != nil {
+			if delErr := in.store.DeleteDocument(ctx, drop.TenantID, file.DocumentID.String()); delErr != nil {
+				logger.Errorf("Failed to remove unrecorded drop import %s: %v", file.DocumentID, delErr)
+			}
+		}
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", p, err))
+		return
+	}
+
+	if file.Status == types.DropFileStatusImported {
+		result.Imported++
+	} else {
+		result.Unmatched++
+		logger.Warningf("Document drop %s file %s is unmatched: %s", drop.Name, p, reason)
+	}
+}
+
+// importFile copies a delivered file into the tenant's document storage and creates its document record
+func (in *Ingester) importFile(ctx context.Context, src source, tenantID, p string, clientID, filingID uuid.UUID, documentType string) (*types.Document, error) {
+	rc, err := src.Open(ctx, p)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	defer rc.Close()
+
+	data, err := io.ReadAll(io.LimitReader(rc, maxFileSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) > maxFileSize {
+		return nil, errFileTooLarge
+	}
+
+	tc, err := in.store.GetTenantConfig(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := storage.NewStorageProviderForTenant(ctx, tc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	// Same layout as uploaded documents: {userId}/{type}/{filename_hash}.ext
+	sum := sha256.Sum256(data)
+	name := path.Base(p)
+	ext := path.Ext(name)
+	storagePath := fmt.Sprintf("%s/%s/%s_%s%s", clientID, documentType, strings.TrimSuffix(name, ext), hex.EncodeToString(sum[:])[:16], ext)
+
+	metadata := map[string]string{
+		"tenant_id":     tenantID,
+		"filing_id":     filingID.String(),
+		"user_id":       clientID.String(),
+		"document_type": documentType,
+		"original_name": name,
+		"source":        "document_drop",
+	}
+	storedPath, scanResult, err := scan.Upload(ctx, in.config.Scanner, provider, tc.StorageBucket, storagePath, data, metadata)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store file: %w", err)
+	}
+
+	document, err := in.store.CreateDocument(ctx, tenantID, &types.Document{
+		ID:       uuid.New(),
+		UserID:   clientID,
+		FilingID: &filingID,
+		Name:     name,
+		FilePath: storedPath,
+		Type:     documentType,
+	})
+	if err != nil {
+		provider.Delete(ctx, tc.StorageBucket, storedPath)
+		return nil, fmt.Errorf("failed to create document record: %w", err)
+	}
+	if scanResult != nil {
+		if _, err := in.store.RecordDocumentScan(tenantID, document.ID, scanResult); err != nil {
+			// An unrecorded scan would leave the document downloadable
+			in.store.DeleteDocument(ctx, tenantID, document.ID.String())
+			provider.Delete(ctx, tc.StorageBucket, storedPath)
+			return nil, fmt.Errorf("failed to record document scan: %w", err)
+		}
+	}
+
+	logger.Infof("Imported drop file %s as document %s for client %s", p, document.ID, clientID)
+	return document, nil
+}
+
+// Resolve imports an unmatched file for the client and filing an admin picked
+func (in *Ingester) Resolve(ctx context.Context, tenantID string, fileID, clientID, filingID uuid.UUID, documentType string, employeeID uuid.UUID) (*types.DocumentDropFile, error) {
+	file, err := in.store.GetDocumentDropFile(tenantID, fileID)
+	if err != nil {
+		return nil, err
+	}
+	if file.Status != types.DropFileStatusUnmatched {
+		return nil, apperr.Conflict("document drop file %s is not waiting for resolution", fileID)
+	}
+	drop, err := in.store.GetDocumentDrop(tenantID, file.DropID)
+	if err != nil {
+		return nil, err
+	}
+	if documentType == "" {
+		documentType = drop.DocumentType
+	}
+
+	m := &matcher{store: in.store, tenantID: tenantID}
+	if reason, err := m.checkFiling(ctx, clientID, filingID); err != nil {
+		return nil, err
+	} else if reason != "" {
+		return nil, apperr.Validation("%s", reason)
+	}
+
+	ran, err := in.store.Locker(in.holder).TryRun(ctx, lockName(drop.ID), func() error {
+		src, err := in.openSource(ctx, drop)
+		if err != nil {
+			return err
+		}
+		document, err := in.importFile(ctx, src, tenantID, file.Path, clientID, filingID, documentType)
+		if err != nil {
+			return err
+		}
+
+		file.Status = types.DropFileStatusResolved
+		file.ClientID, file.FilingID, file.DocumentID = &clientID, &filingID, &document.ID
+		if err := in.store.ResolveDocumentDropFile(file, employeeID); err != nil {
+			if delErr := in.store.DeleteDocument(ctx, tenantID, document.ID.String()); delErr != nil {
+				logger.Errorf("Failed to remove unrecorded drop import %s: %v", document.ID, delErr)
+			}
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !ran {
+		return nil, apperr.Conflict("document drop %s is being scanned; try again shortly", drop.Name)
+	}
+	return file, nil
+}
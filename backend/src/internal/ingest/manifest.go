@@ -0,0 +1,109 @@
+package ingest
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// manifestEntry is one manifest row describing a delivered file
+type manifestEntry struct {
+	ClientID     *uuid.UUID
+	Email        string
+	SSNLast4     string
+	TaxYear      int
+	FilingID     *uuid.UUID
+	DocumentType string
+}
+
+// manifestColumns are the recognised manifest headers; file and one of client_id or email are required
+var manifestColumns = map[string]bool{
+	"file": true, "client_id": true, "email": true, "ssn_last4": true,
+	"tax_year": true, "filing_id": true, "document_type": true,
+}
+
+// parseManifest reads a batch manifest.csv into entries keyed by file name
+func parseManifest(r io.Reader) (map[string]*manifestEntry, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("manifest has no header: %w", err)
+	}
+	index := map[string]int{}
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if !manifestColumns[name] {
+			return nil, fmt.Errorf("manifest has unknown column %q", name)
+		}
+		index[name] = i
+	}
+	if _, ok := index["file"]; !ok {
+		return nil, fmt.Errorf("manifest has no file column")
+	}
+	_, hasClient := index["client_id"]
+	_, hasEmail := index["email"]
+	if !hasClient && !hasEmail {
+		return nil, fmt.Errorf("manifest needs a client_id or email column")
+	}
+
+	entries := map[string]*manifestEntry{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("manifest line %d: %w", line, err)
+		}
+		field := func(name string) string {
+			if i, ok := index[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+
+		file := field("file")
+		if file == "" {
+			return nil, fmt.Errorf("manifest line %d: file is empty", line)
+		}
+		if _, dup := entries[file]; dup {
+			return nil, fmt.Errorf("manifest line %d: %s is listed twice", line, file)
+		}
+
+		entry := &manifestEntry{
+			Email:        strings.ToLower(field("email")),
+			SSNLast4:     field("ssn_last4"),
+			DocumentType: field("document_type"),
+		}
+		if v := field("client_id"); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return nil, fmt.Errorf("manifest line %d: invalid client_id %q", line, v)
+			}
+			entry.ClientID = &id
+		}
+		if v := field("filing_id"); v != "" {
+			id, err := uuid.Parse(v)
+			if err != nil {
+				return nil, fmt.Errorf("manifest line %d: invalid filing_id %q", line, v)
+			}
+			entry.FilingID = &id
+		}
+		if v := field("tax_year"); v != "" {
+			year, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, fmt.Errorf("manifest line %d: invalid tax_year %q", line, v)
+			}
+			entry.TaxYear = year
+		}
+		entries[file] = entry
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,122 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"welltaxpro/src/internal/store"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// matcher resolves manifest rows to a tenant's clients and filings, loading each once per scan
+type matcher struct {
+	store    *store.Store
+	tenantID string
+	clients  []*types.Client
+	filings  map[uuid.UUID][]*types.Filing
+}
+
+// match finds the client and filing for a manifest row. A non-empty reason explains why the row
+// could not be matched; err is a lookup failure worth retrying.
+func (m *matcher) match(ctx context.Context, e *manifestEntry) (clientID, filingID uuid.UUID, reason string, err error) {
+	if m.clients == nil {
+		if m.clients, err = m.store.GetClients(ctx, m.tenantID, false); err != nil {
+			return uuid.Nil, uuid.Nil, "", err
+		}
+	}
+
+	var candidates []*types.Client
+	switch {
+	case e.ClientID != nil:
+		for _, c := range m.clients {
+			if c.ID == *e.ClientID {
+				candidates = append(candidates, c)
+			}
+		}
+		if len(candidates) == 0 {
+			return uuid.Nil, uuid.Nil, fmt.Sprintf("client %s not found or archived", e.ClientID), nil
+		}
+	case e.Email != "":
+		for _, c := range m.clients {
+			if strings.EqualFold(c.Email, e.Email) {
+				candidates = append(candidates, c)
+			}
+		}
+		if len(candidates) == 0 {
+			return uuid.Nil, uuid.Nil, "no client with the manifest email", nil
+		}
+	default:
+		return uuid.Nil, uuid.Nil, "manifest row has no client_id or email", nil
+	}
+
+	if e.SSNLast4 != "" {
+		var matching []*types.Client
+		for _, c := range candidates {
+			if c.Ssn != nil && strings.HasSuffix(*c.Ssn, e.SSNLast4) {
+				matching = append(matching, c)
+			}
+		}
+		if len(matching) == 0 {
+			return uuid.Nil, uuid.Nil, "ssn_last4 does not match the client", nil
+		}
+		candidates = matching
+	}
+	if len(candidates) > 1 {
+		return uuid.Nil, uuid.Nil, fmt.Sprintf("%d clients match the manifest email; add ssn_last4 or client_id", len(candidates)), nil
+	}
+	clientID = candidates[0].ID
+
+	filings, err := m.clientFilings(ctx, clientID)
+	if err != nil {
+		return uuid.Nil, uuid.Nil, "", err
+	}
+	switch {
+	case e.FilingID != nil:
+		reason, err = m.checkFiling(ctx, clientID, *e.FilingID)
+		return clientID, *e.FilingID, reason, err
+	case e.TaxYear != 0:
+		var inYear []*types.Filing
+		for _, f := range filings {
+			if f.Year == e.TaxYear {
+				inYear = append(inYear, f)
+			}
+		}
+		if len(inYear) != 1 {
+			return uuid.Nil, uuid.Nil, fmt.Sprintf("client has %d filings for %d; add filing_id", len(inYear), e.TaxYear), nil
+		}
+		return clientID, inYear[0].ID, "", nil
+	}
+	return uuid.Nil, uuid.Nil, "manifest row has no tax_year or filing_id", nil
+}
+
+// checkFiling explains why a filing cannot receive the client's document, or returns ""
+func (m *matcher) checkFiling(ctx context.Context, clientID, filingID uuid.UUID) (string, error) {
+	filings, err := m.clientFilings(ctx, clientID)
+	if err != nil {
+		return "", err
+	}
+	for _, f := range filings {
+		if f.ID == filingID {
+			return "", nil
+		}
+	}
+	return fmt.Sprintf("filing %s does not belong to client %s", filingID, clientID), nil
+}
+
+// clientFilings loads a client's filings
+func (m *matcher) clientFilings(ctx context.Context, clientID uuid.UUID) ([]*types.Filing, error) {
+	if filings, ok := m.filings[clientID]; ok {
+		return filings, nil
+	}
+	comprehensive, err := m.store.GetClientComprehensive(ctx, m.tenantID, clientID.String())
+	if err != nil {
+		return nil, err
+	}
+	if m.filings == nil {
+		m.filings = map[uuid.UUID][]*types.Filing{}
+	}
+	m.filings[clientID] = comprehensive.Filings
+	return comprehensive.Filings, nil
+}
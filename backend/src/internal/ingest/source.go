@@ -0,0 +1,108 @@
+package ingest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/storage"
+	"welltaxpro/src/internal/types"
+)
+
+// source reads the files delivered to a drop. Paths are slash-separated and relative to the
+// drop's location, and include its prefix.
+type source interface {
+	List(ctx context.Context) ([]string, error)
+	Open(ctx context.Context, path string) (io.ReadCloser, error)
+}
+
+// bucketSource reads a GCS drop with the tenant's storage credentials
+type bucketSource struct {
+	provider storage.StorageProvider
+	lister   storage.Lister
+	bucket   string
+	prefix   string
+}
+
+func (b *bucketSource) List(ctx context.Context) ([]string, error) {
+	return b.lister.List(ctx, b.bucket, b.prefix)
+}
+
+func (b *bucketSource) Open(ctx context.Context, path string) (io.ReadCloser, error) {
+	return b.provider.Download(ctx, b.bucket, path)
+}
+
+// dirSource reads an SFTP drop from the directory the SFTP server writes uploads to
+type dirSource struct {
+	root   string
+	prefix string
+}
+
+func (d *dirSource) List(ctx context.Context) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(d.root, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.Type().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(d.root, p)
+		if err != nil {
+			return err
+		}
+		if rel = filepath.ToSlash(rel); strings.HasPrefix(rel, d.prefix) {
+			paths = append(paths, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", d.root, err)
+	}
+	return paths, nil
+}
+
+func (d *dirSource) Open(ctx context.Context, p string) (io.ReadCloser, error) {
+	if !isRelativePath(p) {
+		return nil, fmt.Errorf("invalid drop path %q", p)
+	}
+	return os.Open(filepath.Join(d.root, filepath.FromSlash(p)))
+}
+
+// isRelativePath reports whether p is a relative slash path that stays inside its root
+func isRelativePath(p string) bool {
+	return p != "" && !path.IsAbs(p) && !strings.HasPrefix(p, "\\") && path.Clean(p) == p &&
+		p != ".." && !strings.HasPrefix(p, "../")
+}
+
+// openSource connects to a drop's location
+func (in *Ingester) openSource(ctx context.Context, drop *types.DocumentDrop) (source, error) {
+	switch drop.SourceType {
+	case types.DocumentDropSourceGCS:
+		tc, err := in.store.GetTenantConfig(drop.TenantID)
+		if err != nil {
+			return nil, err
+		}
+		provider, err := storage.NewStorageProviderForTenant(ctx, tc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize storage: %w", err)
+		}
+		lister, ok := provider.(storage.Lister)
+		if !ok {
+			return nil, fmt.Errorf("storage provider %s cannot list drops", tc.StorageProvider)
+		}
+		return &bucketSource{provider: provider, lister: lister, bucket: drop.Location, prefix: drop.Prefix}, nil
+
+	case types.DocumentDropSourceSFTP:
+		if in.config.SFTPRoot == "" {
+			return nil, apperr.Validation("SFTP drops are disabled: ingest.sftpRoot is not configured")
+		}
+		return &dirSource{root: filepath.Join(in.config.SFTPRoot, filepath.FromSlash(drop.Location)), prefix: drop.Prefix}, nil
+	}
+	return nil, fmt.Errorf("unsupported drop source type: %s", drop.SourceType)
+}
@@ -0,0 +1,93 @@
+package integrity
+
+import (
+	"fmt"
+	"welltaxpro/src/internal/types"
+)
+
+// compatibleTypes lists the information_schema data types each column kind accepts
+var compatibleTypes = map[string][]string{
+	types.ColumnKindUUID:      {"uuid", "text", "character varying", "character"},
+	types.ColumnKindInteger:   {"smallint", "integer", "bigint"},
+	types.ColumnKindNumeric:   {"smallint", "integer", "bigint", "numeric", "real", "double precision"},
+	types.ColumnKindBoolean:   {"boolean"},
+	types.ColumnKindTimestamp: {"timestamp without time zone", "timestamp with time zone", "date"},
+	types.ColumnKindArray:     {"ARRAY"},
+}
+
+// columnDDL is the type suggested when a missing column has to be added
+var columnDDL = map[string]string{
+	types.ColumnKindUUID:      "UUID",
+	types.ColumnKindText:      "TEXT",
+	types.ColumnKindInteger:   "INTEGER",
+	types.ColumnKindNumeric:   "NUMERIC(12,2)",
+	types.ColumnKindBoolean:   "BOOLEAN",
+	types.ColumnKindTimestamp: "TIMESTAMP",
+	types.ColumnKindArray:     "TEXT[]",
+}
+
+// CompareSchema diffs a tenant schema against the tables and columns its adapter expects.
+// actual maps table name to column name to information_schema data type.
+func CompareSchema(schemaPrefix string, expected []types.SchemaTable, actual map[string]map[string]string) []*types.SchemaIssue {
+	if len(actual) == 0 {
+		return []*types.SchemaIssue{{
+			Type: types.SchemaMissingSchema,
+			Fix:  fmt.Sprintf("Schema %s has no tables; check the tenant's schema prefix and database", schemaPrefix),
+		}}
+	}
+
+	var issues []*types.SchemaIssue
+	for _, table := range expected {
+		columns, ok := actual[table.Name]
+		if !ok {
+			issues = append(issues, &types.SchemaIssue{
+				Type:  types.SchemaMissingTable,
+				Table: table.Name,
+				Fix:   fmt.Sprintf("Create %s.%s (see the tenant schema additions in docs/TENANT_SETUP.md)", schemaPrefix, table.Name),
+			})
+			continue
+		}
+
+		for _, col := range table.Columns {
+			dataType, ok := columns[col.Name]
+			if !ok {
+				issues = append(issues, &types.SchemaIssue{
+					Type:     types.SchemaMissingColumn,
+					Table:    table.Name,
+					Column:   col.Name,
+					Expected: col.Kind,
+					Fix:      fmt.Sprintf("ALTER TABLE %s.%s ADD COLUMN %s %s;", schemaPrefix, table.Name, col.Name, columnDDL[col.Kind]),
+				})
+				continue
+			}
+
+			if !kindAccepts(col.Kind, dataType) {
+				issues = append(issues, &types.SchemaIssue{
+					Type:     types.SchemaTypeMismatch,
+					Table:    table.Name,
+					Column:   col.Name,
+					Expected: col.Kind,
+					Actual:   dataType,
+					Fix: fmt.Sprintf("ALTER TABLE %s.%s ALTER COLUMN %s TYPE %s USING %s::%s; -- verify existing values convert first",
+						schemaPrefix, table.Name, col.Name, columnDDL[col.Kind], col.Name, columnDDL[col.Kind]),
+				})
+			}
+		}
+	}
+
+	return issues
+}
+
+// kindAccepts reports whether a database type works for columns of the given kind
+func kindAccepts(kind, dataType string) bool {
+	// Text columns are scanned into strings, which any scalar converts to
+	if kind == types.ColumnKindText {
+		return dataType != "ARRAY"
+	}
+	for _, t := range compatibleTypes[kind] {
+		if t == dataType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,111 @@
+package integrity
+
+import (
+	"fmt"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// CheckDuplicateSSNs flags SSNs that appear on more than one client record within a tenant.
+// SSNs are decrypted in memory only (ciphertexts use random nonces) and never returned.
+func CheckDuplicateSSNs(tenantID string, records []*types.SSNRecord) []*types.IntegrityIssue {
+	groups := map[string][]*types.SSNRecord{}
+	var order []string
+
+	for _, rec := range records {
+		plain, err := crypto.DecryptSSN(rec.EncryptedSSN)
+		if err != nil {
+			logger.Warningf("Skipping undecryptable SSN for client %s during integrity check: %v", rec.ClientID, err)
+			continue
+		}
+		if plain == "" {
+			continue
+		}
+		if _, ok := groups[plain]; !ok {
+			order = append(order, plain)
+		}
+		groups[plain] = append(groups[plain], rec)
+	}
+
+	var issues []*types.IntegrityIssue
+	for _, ssn := range order {
+		group := groups[ssn]
+
+		clients := map[string]bool{}
+		taxpayers, spouses := 0, 0
+		for _, rec := range group {
+			clients[rec.ClientID.String()] = true
+			if rec.Role == types.SSNRoleSpouse {
+				spouses++
+			} else {
+				taxpayers++
+			}
+		}
+
+		// A taxpayer and their own spouse record under the same client is a separate data error,
+		// not a cross-client conflict
+		if len(clients) < 2 {
+			continue
+		}
+
+		issue := &types.IntegrityIssue{
+			MaskedSSN: crypto.MaskSSN(ssn),
+		}
+		switch {
+		case taxpayers > 1:
+			issue.Type = types.IntegrityDuplicateSSN
+			issue.Severity = "HIGH"
+		case taxpayers == 1:
+			issue.Type = types.IntegrityTaxpayerSpouseSSN
+			issue.Severity = "MEDIUM"
+		default:
+			issue.Type = types.IntegritySpouseSSNShared
+			issue.Severity = "MEDIUM"
+		}
+
+		for _, rec := range group {
+			issue.Records = append(issue.Records, &types.IntegrityRecord{
+				ClientID: rec.ClientID,
+				Role:     rec.Role,
+				Name:     rec.Name,
+			})
+			issue.Remediation = append(issue.Remediation, remediationFor(tenantID, rec)...)
+		}
+
+		issues = append(issues, issue)
+	}
+
+	return issues
+}
+
+// remediationFor builds the review, merge and archive links for one record
+func remediationFor(tenantID string, rec *types.SSNRecord) []*types.RemediationLink {
+	clientPage := fmt.Sprintf("/%s/clients/%s", tenantID, rec.ClientID)
+	links := []*types.RemediationLink{
+		{
+			Action:      "REVIEW",
+			Description: fmt.Sprintf("Review %s record for %s", rec.Role, rec.Name),
+			Method:      "GET",
+			Href:        fmt.Sprintf("/api/v1/%s/clients/%s/comprehensive", tenantID, rec.ClientID),
+		},
+		{
+			Action:      "MERGE",
+			Description: "Merge duplicate client records in the tax platform",
+			Method:      "GET",
+			Href:        clientPage,
+		},
+	}
+
+	if rec.Role != types.SSNRoleSpouse {
+		links = append(links, &types.RemediationLink{
+			Action:      "ARCHIVE",
+			Description: fmt.Sprintf("Archive duplicate client record for %s", rec.Name),
+			Method:      "PUT",
+			Href:        fmt.Sprintf("/api/v1/%s/clients/%s/archive", tenantID, rec.ClientID),
+		})
+	}
+
+	return links
+}
@@ -0,0 +1,181 @@
+package mailing
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"time"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+const (
+	lobLettersURL     = "https://api.lob.com/v1/letters"
+	lobMaxDescription = 255
+)
+
+// lobEventStatuses maps Lob letter events to piece statuses; other events are ignored
+var lobEventStatuses = map[string]string{
+	"letter.mailed":                 types.MailStatusMailed,
+	"letter.in_transit":             types.MailStatusInTransit,
+	"letter.in_local_area":          types.MailStatusInTransit,
+	"letter.processed_for_delivery": types.MailStatusInTransit,
+	"letter.re-routed":              types.MailStatusInTransit,
+	"letter.delivered":              types.MailStatusDelivered,
+	"letter.returned_to_sender":     types.MailStatusReturned,
+	"letter.deleted":                types.MailStatusCancelled,
+	"letter.rejected":               types.MailStatusFailed,
+	"letter.failed":                 types.MailStatusFailed,
+}
+
+// LobProvider implements Provider using the Lob Print & Mail letters API
+type LobProvider struct {
+	apiKey        string
+	webhookSecret string
+	costCents     int64
+	client        *http.Client
+}
+
+// NewLobProvider creates a Lob provider that records costCents for each letter
+func NewLobProvider(apiKey, webhookSecret string, costCents int64) *LobProvider {
+	return &LobProvider{
+		apiKey:        apiKey,
+		webhookSecret: webhookSecret,
+		costCents:     costCents,
+		client:        &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Name returns the provider identifier
+func (p *LobProvider) Name() string {
+	return "lob"
+}
+
+// Send creates a Lob letter. Lob adds a blank first page carrying the address, since client
+// documents are not laid out for a window envelope.
+func (p *LobProvider) Send(ctx context.Context, letter *Letter) (*Receipt, error) {
+	description := []rune(letter.Description)
+	if len(description) > lobMaxDescription {
+		description = description[:lobMaxDescription]
+	}
+
+	body := &bytes.Buffer{}
+	form := multipart.NewWriter(body)
+	fields := map[string]string{
+		"description":       string(description),
+		"color":             fmt.Sprintf("%t", letter.Color),
+		"use_type":          "operational",
+		"address_placement": "insert_blank_page",
+	}
+	addRecipient := func(prefix string, r Recipient) {
+		fields[prefix+"[name]"] = r.Name
+		fields[prefix+"[address_line1]"] = r.Address.Line1
+		fields[prefix+"[address_line2]"] = r.Address.Line2
+		fields[prefix+"[address_city]"] = r.Address.City
+		fields[prefix+"[address_state]"] = r.Address.State
+		fields[prefix+"[address_zip]"] = r.Address.Zipcode
+		fields[prefix+"[address_country]"] = "US"
+	}
+	addRecipient("to", letter.To)
+	addRecipient("from", letter.From)
+	for name, value := range fields {
+		if value != "" {
+			form.WriteField(name, value)
+		}
+	}
+	part, err := form.CreateFormFile("file", "letter.pdf")
+	if err != nil {
+		return nil, fmt.Errorf("failed to build letter request: %w", err)
+	}
+	part.Write(letter.File)
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("failed to build letter request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, lobLettersURL, body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build letter request: %w", err)
+	}
+	req.SetBasicAuth(p.apiKey, "")
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if letter.IdempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", letter.IdempotencyKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		logger.Errorf("Lob request failed: %v", err)
+		return nil, fmt.Errorf("mail provider request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		if json.Unmarshal(data, &failure) == nil && failure.Error.Message != "" {
+			return nil, fmt.Errorf("mail provider returned status %d: %s", resp.StatusCode, failure.Error.Message)
+		}
+		return nil, fmt.Errorf("mail provider returned status %d", resp.StatusCode)
+	}
+
+	var created struct {
+		ID                   string `json:"id"`
+		ExpectedDeliveryDate string `json:"expected_delivery_date"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("failed to decode mail provider response: %w", err)
+	}
+	if created.ID == "" {
+		return nil, fmt.Errorf("mail provider response has no letter ID")
+	}
+
+	receipt := &Receipt{ProviderID: created.ID, CostCents: p.costCents}
+	if d, err := time.Parse("2006-01-02", created.ExpectedDeliveryDate); err == nil {
+		receipt.ExpectedDelivery = &d
+	}
+	return receipt, nil
+}
+
+// ParseWebhook checks the Lob-Signature header, an HMAC-SHA256 of "{timestamp}.{body}"
+// keyed with the webhook secret, and reads the letter event
+func (p *LobProvider) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	signature, err := hex.DecodeString(header.Get("Lob-Signature"))
+	if err != nil || len(signature) == 0 {
+		return nil, ErrInvalidSignature
+	}
+	mac := hmac.New(sha256.New, []byte(p.webhookSecret))
+	mac.Write([]byte(header.Get("Lob-Signature-Timestamp") + "."))
+	mac.Write(body)
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrInvalidSignature
+	}
+
+	var event struct {
+		EventType struct {
+			ID string `json:"id"`
+		} `json:"event_type"`
+		Body struct {
+			ID string `json:"id"`
+		} `json:"body"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook: %w", err)
+	}
+	if event.Body.ID == "" {
+		return nil, fmt.Errorf("webhook has no letter ID")
+	}
+
+	return &Event{ProviderID: event.Body.ID, Status: lobEventStatuses[event.EventType.ID]}, nil
+}
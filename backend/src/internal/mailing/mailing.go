@@ -0,0 +1,103 @@
+package mailing
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// Provider defines the interface for print-and-mail services
+type Provider interface {
+	// Send submits one letter for printing and mailing
+	Send(ctx context.Context, letter *Letter) (*Receipt, error)
+
+	// ParseWebhook verifies a status webhook and returns the event it reports
+	ParseWebhook(header http.Header, body []byte) (*Event, error)
+
+	// Name returns the provider identifier stored with each piece
+	Name() string
+}
+
+// Config selects and configures the print-and-mail provider
+type Config struct {
+	Provider           string // "lob" or empty to disable mailing
+	APIKey             string
+	WebhookSecret      string
+	CostPerLetterCents int64 // Price recorded for each letter submitted
+}
+
+// ProviderNone is the name of the provider used when mailing is not configured
+const ProviderNone = "none"
+
+// Errors returned by providers
+var (
+	ErrDisabled         = errors.New("print and mail is not configured")
+	ErrInvalidSignature = errors.New("webhook signature is invalid")
+)
+
+// Recipient is a name and postal address
+type Recipient struct {
+	Name    string
+	Address types.Address
+}
+
+// Letter is a single PDF printed and mailed in one envelope
+type Letter struct {
+	Description    string
+	To             Recipient
+	From           Recipient
+	File           []byte // PDF
+	Color          bool
+	IdempotencyKey string // Resubmitting with the same key does not mail a second letter
+}
+
+// Receipt is the provider's acceptance of a letter
+type Receipt struct {
+	ProviderID       string
+	CostCents        int64
+	ExpectedDelivery *time.Time
+}
+
+// Event is a status change reported by a provider webhook.
+// Status is empty for events that do not change a piece's status.
+type Event struct {
+	ProviderID string
+	Status     string
+}
+
+// NewProvider creates the configured print-and-mail provider
+// Falls back to a disabled provider when none is configured
+func NewProvider(cfg Config) Provider {
+	switch cfg.Provider {
+	case "lob":
+		if cfg.APIKey == "" || cfg.WebhookSecret == "" {
+			logger.Warning("Lob print and mail configured without an API key or webhook secret, disabling mailing")
+			return &DisabledProvider{}
+		}
+		return NewLobProvider(cfg.APIKey, cfg.WebhookSecret, cfg.CostPerLetterCents)
+	default:
+		return &DisabledProvider{}
+	}
+}
+
+// DisabledProvider rejects every letter and webhook
+type DisabledProvider struct{}
+
+// Name returns the provider identifier
+func (p *DisabledProvider) Name() string {
+	return ProviderNone
+}
+
+// Send rejects the letter
+func (p *DisabledProvider) Send(ctx context.Context, letter *Letter) (*Receipt, error) {
+	return nil, ErrDisabled
+}
+
+// ParseWebhook rejects the webhook
+func (p *DisabledProvider) ParseWebhook(header http.Header, body []byte) (*Event, error) {
+	return nil, ErrDisabled
+}
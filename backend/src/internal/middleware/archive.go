@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"welltaxpro/src/internal/apperr"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// ArchiveStore is the part of the store the archive check reads; *store.Store implements it
+type ArchiveStore interface {
+	CheckFilingWritable(ctx context.Context, tenantID string, filingID string) error
+	CheckDocumentWritable(ctx context.Context, tenantID string, documentID string) error
+}
+
+// ArchiveMiddleware keeps writes away from filings and documents of archived tax years
+type ArchiveMiddleware struct {
+	store ArchiveStore
+}
+
+// NewArchiveMiddleware creates a new archive middleware
+func NewArchiveMiddleware(store ArchiveStore) *ArchiveMiddleware {
+	return &ArchiveMiddleware{
+		store: store,
+	}
+}
+
+// RequireWritable rejects the request with 409 Conflict when the filing or document in its
+// {filingId} or {documentId} route variable belongs to an archived tax year. It runs before the
+// handler so nothing, including files in storage, is changed. Unknown filings and documents are
+// left to the handler to report.
+func (m *ArchiveMiddleware) RequireWritable(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		vars := mux.Vars(r)
+		tenantID := vars["tenantId"]
+
+		var err error
+		if filingID, ok := vars["filingId"]; ok {
+			err = m.store.CheckFilingWritable(r.Context(), tenantID, filingID)
+		} else if documentID, ok := vars["documentId"]; ok {
+			err = m.store.CheckDocumentWritable(r.Context(), tenantID, documentID)
+		}
+
+		switch {
+		case err == nil, errors.Is(err, apperr.ErrNotFound):
+			next.ServeHTTP(w, r)
+		case errors.Is(err, apperr.ErrConflict):
+			logger.Infof("Refused %s %s in tenant %s: %v", r.Method, r.URL.Path, tenantID, err)
+			writeArchivedError(w, err)
+		default:
+			logger.Errorf("Failed to check archive of %s in tenant %s: %v", r.URL.Path, tenantID, err)
+			http.Error(w, "Failed to check archived tax years", http.StatusInternalServerError)
+		}
+	})
+}
+
+// writeArchivedError tells the caller the tax year must be unarchived before the change
+func writeArchivedError(w http.ResponseWriter, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	body := map[string]interface{}{
+		"error": err.Error(),
+		"code":  "TAX_YEAR_ARCHIVED",
+	}
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Errorf("Failed to encode archived error response: %v", err)
+	}
+}
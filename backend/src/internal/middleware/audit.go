@@ -4,20 +4,24 @@ import (
 	"net"
 	"net/http"
 	"strings"
-	"welltaxpro/src/internal/store"
 
 	"github.com/google/logger"
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 )
 
+// AuditStore is the part of the store the audit middleware writes to; *store.Store implements it
+type AuditStore interface {
+	CreateAuditLog(employeeID uuid.UUID, tenantID string, clientID *uuid.UUID, action, resourceType string, resourceID *uuid.UUID, details interface{}, ipAddress, userAgent *string) error
+}
+
 // AuditMiddleware logs access for compliance
 type AuditMiddleware struct {
-	store *store.Store
+	store AuditStore
 }
 
 // NewAuditMiddleware creates a new audit middleware
-func NewAuditMiddleware(store *store.Store) *AuditMiddleware {
+func NewAuditMiddleware(store AuditStore) *AuditMiddleware {
 	return &AuditMiddleware{
 		store: store,
 	}
@@ -88,6 +92,11 @@ func (m *AuditMiddleware) LogAccess(action, resourceType string) func(http.Handl
 	}
 }
 
+// ClientIP returns the caller's IP address for handlers that write their own audit entries
+func ClientIP(r *http.Request) string {
+	return getIPAddress(r)
+}
+
 // getIPAddress extracts the real IP address from the request
 func getIPAddress(r *http.Request) string {
 	// Try X-Forwarded-For header first (for requests behind proxy)
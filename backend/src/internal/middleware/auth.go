@@ -2,23 +2,41 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"strings"
+	"welltaxpro/src/internal/apperr"
 	"welltaxpro/src/internal/auth"
-	"welltaxpro/src/internal/store"
 	"welltaxpro/src/internal/types"
 
+	firebaseauth "firebase.google.com/go/v4/auth"
 	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 )
 
+// TokenVerifier verifies Firebase ID tokens; *auth.Auth implements it
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, token string) (*firebaseauth.Token, error)
+	ValidateToken(ctx context.Context, token string) (*string, error)
+}
+
+// AuthStore is the part of the store employee authentication reads; *store.Store implements it
+type AuthStore interface {
+	IsEmployeeSessionRevoked(firebaseUID string, authTime int64) (bool, error)
+	GetEmployeeByFirebaseUID(firebaseUID string) (*types.Employee, error)
+	GetTenantContext(tokenHash string) (*types.TenantContext, error)
+	GetEmployeeTenantRole(employeeID uuid.UUID, tenantID string) (string, error)
+}
+
 // AuthMiddleware validates Firebase token and loads employee context
 type AuthMiddleware struct {
-	auth  *auth.Auth
-	store *store.Store
+	auth  TokenVerifier
+	store AuthStore
 }
 
 // NewAuthMiddleware creates a new auth middleware
-func NewAuthMiddleware(authClient *auth.Auth, store *store.Store) *AuthMiddleware {
+func NewAuthMiddleware(authClient TokenVerifier, store AuthStore) *AuthMiddleware {
 	return &AuthMiddleware{
 		auth:  authClient,
 		store: store,
@@ -40,17 +58,29 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 
 		// Validate token with Firebase
-		firebaseUID, err := m.auth.ValidateToken(r.Context(), token)
+		decodedToken, err := m.auth.VerifyToken(r.Context(), token)
 		if err != nil {
 			logger.Errorf("Token validation failed: %v", err)
 			http.Error(w, "Unauthorized: Invalid token", http.StatusUnauthorized)
 			return
 		}
 
+		// Reject tokens of revoked sessions
+		revoked, err := m.store.IsEmployeeSessionRevoked(decodedToken.UID, decodedToken.AuthTime)
+		if err != nil {
+			http.Error(w, "Failed to check session", http.StatusInternalServerError)
+			return
+		}
+		if revoked {
+			logger.Warningf("Token of revoked session presented for firebase UID %s", decodedToken.UID)
+			http.Error(w, "Unauthorized: Session revoked", http.StatusUnauthorized)
+			return
+		}
+
 		// Load employee from database
-		employee, err := m.store.GetEmployeeByFirebaseUID(*firebaseUID)
+		employee, err := m.store.GetEmployeeByFirebaseUID(decodedToken.UID)
 		if err != nil {
-			logger.Errorf("Failed to load employee for firebase UID %s: %v", *firebaseUID, err)
+			logger.Errorf("Failed to load employee for firebase UID %s: %v", decodedToken.UID, err)
 			http.Error(w, "Unauthorized: Employee not found", http.StatusUnauthorized)
 			return
 		}
@@ -64,6 +94,40 @@ func (m *AuthMiddleware) Authenticate(next http.Handler) http.Handler {
 
 		// Add employee to request context
 		ctx := context.WithValue(r.Context(), auth.EmployeeContextKey, employee)
+
+		// Apply the tenant context the employee selected, if one was sent
+		if token := r.Header.Get(types.TenantContextHeader); token != "" {
+			tc, err := m.store.GetTenantContext(auth.HashSessionToken(token))
+			if err != nil {
+				if errors.Is(err, apperr.ErrNotFound) {
+					http.Error(w, "Unauthorized: Tenant context expired", http.StatusUnauthorized)
+					return
+				}
+				http.Error(w, "Failed to check tenant context", http.StatusInternalServerError)
+				return
+			}
+
+			// Contexts only work with the sign-in they were minted from
+			if tc.EmployeeID != employee.ID || tc.AuthTime != decodedToken.AuthTime {
+				logger.Warningf("Tenant context %s presented by another sign-in of %s", tc.ID, employee.Email)
+				http.Error(w, "Unauthorized: Tenant context expired", http.StatusUnauthorized)
+				return
+			}
+
+			// On tenant routes the context's tenant must match and its role applies
+			if tenantID := mux.Vars(r)["tenantId"]; tenantID != "" {
+				if tenantID != tc.TenantID {
+					logger.Warningf("Employee %s used tenant context for %s on tenant %s", employee.Email, tc.TenantID, tenantID)
+					http.Error(w, "Forbidden: Tenant context is for another tenant", http.StatusForbidden)
+					return
+				}
+				scoped := *employee
+				scoped.Role = tc.Role
+				ctx = context.WithValue(ctx, auth.EmployeeContextKey, &scoped)
+				employee = &scoped
+			}
+			ctx = context.WithValue(ctx, auth.TenantContextKey, tc)
+		}
 		logger.Infof("Authenticated employee: %s (%s)", employee.Email, employee.Role)
 
 		// Call next handler with employee context
@@ -77,6 +141,12 @@ func GetEmployeeFromContext(ctx context.Context) (*types.Employee, bool) {
 	return employee, ok
 }
 
+// GetTenantContextFromContext retrieves the tenant context the request was sent with
+func GetTenantContextFromContext(ctx context.Context) (*types.TenantContext, bool) {
+	tc, ok := ctx.Value(auth.TenantContextKey).(*types.TenantContext)
+	return tc, ok
+}
+
 // RequireRole is a middleware that requires a specific role
 func (m *AuthMiddleware) RequireRole(role string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
@@ -103,3 +173,60 @@ func (m *AuthMiddleware) RequireRole(role string) func(http.Handler) http.Handle
 func (m *AuthMiddleware) RequireAdmin(next http.Handler) http.Handler {
 	return m.RequireRole("admin")(next)
 }
+
+// RequireTenantRole is a middleware that requires the employee's role in the {tenantId} tenant,
+// from their tenant access, to be at least role. Admins act in every tenant.
+func (m *AuthMiddleware) RequireTenantRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			employee, ok := GetEmployeeFromContext(r.Context())
+			if !ok {
+				logger.Error("Employee not found in context")
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			if employee.IsAdmin() {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			tenantID := mux.Vars(r)["tenantId"]
+			tenantRole, err := m.store.GetEmployeeTenantRole(employee.ID, tenantID)
+			if err != nil && !errors.Is(err, apperr.ErrNotFound) {
+				http.Error(w, "Failed to check tenant access", http.StatusInternalServerError)
+				return
+			}
+
+			if !types.TenantRoleSatisfies(tenantRole, role) {
+				logger.Warningf("Employee %s lacks role %s in tenant %s (has %q)", employee.Email, role, tenantID, tenantRole)
+				http.Error(w, "Forbidden: Insufficient permissions for this tenant", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireCapability is a middleware that requires a role granting the capability
+func (m *AuthMiddleware) RequireCapability(capability string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			employee, ok := GetEmployeeFromContext(r.Context())
+			if !ok {
+				logger.Error("Employee not found in context")
+				http.Error(w, "Unauthorized", http.StatusUnauthorized)
+				return
+			}
+
+			if !employee.HasCapability(capability) {
+				logger.Warningf("Employee %s (%s) lacks capability %s", employee.Email, employee.Role, capability)
+				http.Error(w, "Forbidden: Insufficient permissions", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
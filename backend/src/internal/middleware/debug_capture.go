@@ -0,0 +1,243 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/redact"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// debugCaptureCacheTTL is how long a tenant's capture state is reused before it is read again,
+// so captures enabled by another API process start within this time
+const debugCaptureCacheTTL = 30 * time.Second
+
+// debugCaptureRoutePrefix selects the tenant API routes that are captured; admin routes,
+// including those that read captures, never are
+const debugCaptureRoutePrefix = "/api/v1/{tenantId}/"
+
+// DebugCaptureStore is the part of the store debug capture uses; *store.Store implements it
+type DebugCaptureStore interface {
+	GetActiveDebugCapture(tenantID string) (*types.DebugCapture, error)
+	RecordDebugCaptureEntry(entry *types.DebugCaptureEntry) error
+}
+
+// cachedDebugCapture is a tenant's running capture, or nil for none, as of checkedAt
+type cachedDebugCapture struct {
+	capture   *types.DebugCapture
+	checkedAt time.Time
+}
+
+// DebugCaptureMiddleware records the requests and responses of tenants with a running debug
+// capture, with sensitive JSON fields redacted
+type DebugCaptureMiddleware struct {
+	store    DebugCaptureStore
+	redactor *redact.Redactor
+	mu       sync.Mutex
+	cache    map[string]cachedDebugCapture
+}
+
+// NewDebugCaptureMiddleware creates a debug capture middleware that redacts redactFields,
+// or types.DefaultDebugRedactFields when none are given
+func NewDebugCaptureMiddleware(store DebugCaptureStore, redactFields []string) *DebugCaptureMiddleware {
+	if len(redactFields) == 0 {
+		redactFields = types.DefaultDebugRedactFields
+	}
+	return &DebugCaptureMiddleware{
+		store:    store,
+		redactor: redact.New(redactFields),
+		cache:    map[string]cachedDebugCapture{},
+	}
+}
+
+// Forget drops a tenant's cached capture state after it was changed in this process
+func (m *DebugCaptureMiddleware) Forget(tenantID string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.cache, tenantID)
+}
+
+// activeCapture returns the capture running for a tenant, or nil
+func (m *DebugCaptureMiddleware) activeCapture(tenantID string) *types.DebugCapture {
+	now := time.Now()
+
+	m.mu.Lock()
+	cached, ok := m.cache[tenantID]
+	m.mu.Unlock()
+	if !ok || now.Sub(cached.checkedAt) > debugCaptureCacheTTL {
+		capture, err := m.store.GetActiveDebugCapture(tenantID)
+		if err != nil && !errors.Is(err, apperr.ErrNotFound) {
+			// Capture is best effort; failing to check never fails the request
+			return nil
+		}
+		cached = cachedDebugCapture{capture: capture, checkedAt: now}
+		m.mu.Lock()
+		m.cache[tenantID] = cached
+		m.mu.Unlock()
+	}
+
+	if cached.capture == nil || !now.Before(cached.capture.ExpiresAt) {
+		return nil
+	}
+	return cached.capture
+}
+
+// Capture records tenant API requests while the tenant has a running capture. Bodies are kept
+// only when they are JSON of at most types.DebugCaptureMaxBodyBytes, with sensitive fields
+// redacted; headers, including Authorization, are never kept.
+func (m *DebugCaptureMiddleware) Capture(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := mux.CurrentRoute(r)
+		if route == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if template, err := route.GetPathTemplate(); err != nil || !strings.HasPrefix(template, debugCaptureRoutePrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		tenantID := mux.Vars(r)["tenantId"]
+		capture := m.activeCapture(tenantID)
+		if capture == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		// Read the start of the body and put it back for the handler
+		var requestBody []byte
+		requestTruncated := false
+		if r.Body != nil && isJSON(r.Header.Get("Content-Type")) {
+			head, err := io.ReadAll(io.LimitReader(r.Body, types.DebugCaptureMaxBodyBytes+1))
+			if err == nil {
+				requestBody = head
+				requestTruncated = len(head) > types.DebugCaptureMaxBodyBytes
+			}
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(head), r.Body), r.Body}
+		}
+
+		cw := &captureWriter{ResponseWriter: w}
+		start := time.Now()
+		next.ServeHTTP(cw, r)
+
+		entry := &types.DebugCaptureEntry{
+			CaptureID:    capture.ID,
+			TenantID:     tenantID,
+			Method:       r.Method,
+			Path:         r.URL.Path,
+			Status:       cw.statusCode(),
+			DurationMs:   int(time.Since(start).Milliseconds()),
+			RequestBody:  m.describeBody(r.Header.Get("Content-Type"), r.ContentLength, requestBody, requestTruncated),
+			ResponseBody: m.describeBody(w.Header().Get("Content-Type"), int64(cw.written), cw.buf.Bytes(), cw.truncated),
+		}
+		if r.URL.RawQuery != "" {
+			query := m.redactor.Query(r.URL.Query())
+			entry.Query = &query
+		}
+
+		go func() {
+			if err := m.store.RecordDebugCaptureEntry(entry); err != nil {
+				logger.Errorf("Failed to record debug capture of %s %s: %v", entry.Method, entry.Path, err)
+			}
+		}()
+	})
+}
+
+// describeBody returns a captured body with sensitive fields redacted, or a note saying why it
+// was left out
+func (m *DebugCaptureMiddleware) describeBody(contentType string, size int64, body []byte, truncated bool) *string {
+	if size == 0 && len(body) == 0 {
+		return nil
+	}
+
+	var note string
+	switch {
+	case !isJSON(contentType):
+		mediaType, _, _ := mime.ParseMediaType(contentType)
+		if mediaType == "" {
+			mediaType = "untyped"
+		}
+		note = fmt.Sprintf("[%s body omitted]", mediaType)
+	case truncated:
+		note = fmt.Sprintf("[body over %d bytes omitted]", types.DebugCaptureMaxBodyBytes)
+	default:
+		redacted, ok := m.redactor.JSON(body)
+		if !ok {
+			note = "[invalid JSON body omitted]"
+			break
+		}
+		text := string(redacted)
+		return &text
+	}
+	return &note
+}
+
+// isJSON reports whether a Content-Type header names JSON
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
+
+// captureWriter passes a response through while keeping its status and the start of its body
+type captureWriter struct {
+	http.ResponseWriter
+	status    int
+	buf       bytes.Buffer
+	written   int
+	truncated bool
+}
+
+func (cw *captureWriter) WriteHeader(code int) {
+	if cw.status == 0 {
+		cw.status = code
+	}
+	cw.ResponseWriter.WriteHeader(code)
+}
+
+func (cw *captureWriter) Write(p []byte) (int, error) {
+	if cw.status == 0 {
+		cw.status = http.StatusOK
+	}
+	n, err := cw.ResponseWriter.Write(p)
+	cw.written += n
+	if room := types.DebugCaptureMaxBodyBytes - cw.buf.Len(); room > 0 {
+		cw.buf.Write(p[:min(n, room)])
+	}
+	if cw.written > types.DebugCaptureMaxBodyBytes {
+		cw.truncated = true
+	}
+	return n, err
+}
+
+// Flush lets streamed responses reach the client while they are captured
+func (cw *captureWriter) Flush() {
+	if flusher, ok := cw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (cw *captureWriter) Unwrap() http.ResponseWriter {
+	return cw.ResponseWriter
+}
+
+// statusCode returns the response status, which is 200 when the handler never set one
+func (cw *captureWriter) statusCode() int {
+	if cw.status == 0 {
+		return http.StatusOK
+	}
+	return cw.status
+}
@@ -0,0 +1,82 @@
+package middleware
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// LegalStore is the part of the store legal acceptance reads; *store.Store implements it
+type LegalStore interface {
+	GetTenantUserByFirebaseUID(firebaseUID string) (*types.TenantUser, error)
+	GetPendingLegalDocuments(tenantID string, tenantUserID uuid.UUID) ([]*types.LegalDocument, error)
+}
+
+// LegalMiddleware keeps portal users out of their data until they accept the tenant's current legal documents
+type LegalMiddleware struct {
+	store LegalStore
+}
+
+// NewLegalMiddleware creates a new legal acceptance middleware
+func NewLegalMiddleware(store LegalStore) *LegalMiddleware {
+	return &LegalMiddleware{
+		store: store,
+	}
+}
+
+// RequireAcceptance rejects the request with 428 Precondition Required and the pending documents
+// while the tenant user has not accepted every active legal document version.
+// Must run after TenantUserAuthMiddleware.Authenticate.
+func (m *LegalMiddleware) RequireAcceptance(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		firebaseUID, err := GetFirebaseUIDFromContext(r.Context())
+		if err != nil {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		tenantUser, err := m.store.GetTenantUserByFirebaseUID(firebaseUID)
+		if errors.Is(err, apperr.ErrNotFound) {
+			// Unregistered users have no data to protect; the handler reports the missing registration
+			next.ServeHTTP(w, r)
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to get tenant user for legal check: %v", err)
+			http.Error(w, "Failed to check legal acceptance", http.StatusInternalServerError)
+			return
+		}
+
+		pending, err := m.store.GetPendingLegalDocuments(tenantUser.TenantID, tenantUser.ID)
+		if err != nil {
+			http.Error(w, "Failed to check legal acceptance", http.StatusInternalServerError)
+			return
+		}
+		if len(pending) > 0 {
+			logger.Infof("Tenant user %s has %d legal documents to accept, blocking %s", tenantUser.ID, len(pending), r.URL.Path)
+			writeLegalError(w, pending)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeLegalError tells the portal which documents to show before retrying
+func writeLegalError(w http.ResponseWriter, pending []*types.LegalDocument) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusPreconditionRequired)
+	body := map[string]interface{}{
+		"error":   "Accept the current legal documents to continue",
+		"code":    "LEGAL_ACCEPTANCE_REQUIRED",
+		"pending": pending,
+	}
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Errorf("Failed to encode legal error response: %v", err)
+	}
+}
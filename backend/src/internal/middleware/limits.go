@@ -0,0 +1,191 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/google/logger"
+)
+
+// RouteClass groups routes that share body size and timeout limits
+type RouteClass string
+
+// Route classes
+const (
+	RouteClassAPI    RouteClass = "api"    // Authenticated JSON endpoints
+	RouteClassUpload RouteClass = "upload" // Multipart file uploads
+	RouteClassPublic RouteClass = "public" // Unauthenticated endpoints
+	RouteClassStream RouteClass = "stream" // Exports and files written to the client as they are read
+)
+
+// RouteLimit is the largest request body and the longest handler run time allowed for a route class
+type RouteLimit struct {
+	MaxBodyBytes int64
+	Timeout      time.Duration
+}
+
+// RouteLimits maps each route class to its limits
+type RouteLimits map[RouteClass]RouteLimit
+
+// DefaultRouteLimits are used for any class the configuration leaves out
+var DefaultRouteLimits = RouteLimits{
+	RouteClassAPI:    {MaxBodyBytes: 1 << 20, Timeout: 30 * time.Second},
+	RouteClassUpload: {MaxBodyBytes: 12 << 20, Timeout: 120 * time.Second},
+	RouteClassPublic: {MaxBodyBytes: 64 << 10, Timeout: 10 * time.Second},
+	RouteClassStream: {MaxBodyBytes: 64 << 10, Timeout: 30 * time.Minute},
+}
+
+// LimitsMiddleware enforces per-class request body size limits and handler deadlines
+type LimitsMiddleware struct {
+	limits   RouteLimits
+	classify func(r *http.Request) RouteClass
+}
+
+// NewLimitsMiddleware creates a limits middleware; classify picks the class for a request
+func NewLimitsMiddleware(limits RouteLimits, classify func(r *http.Request) RouteClass) *LimitsMiddleware {
+	merged := RouteLimits{}
+	for class, limit := range DefaultRouteLimits {
+		merged[class] = limit
+	}
+	for class, limit := range limits {
+		merged[class] = limit
+	}
+
+	return &LimitsMiddleware{
+		limits:   merged,
+		classify: classify,
+	}
+}
+
+// Enforce caps the request body and cancels the handler when its class deadline passes.
+// Oversized bodies get 413 and timeouts 503, both with a JSON error. Stream routes write
+// straight to the client, so they are cut off by their context rather than a 503.
+func (m *LimitsMiddleware) Enforce(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		class := m.classify(r)
+		limit, ok := m.limits[class]
+		if !ok {
+			limit = m.limits[RouteClassAPI]
+		}
+
+		if r.ContentLength > limit.MaxBodyBytes {
+			logger.Warningf("Rejected %s %s: body of %d bytes exceeds %s limit of %d", r.Method, r.URL.Path, r.ContentLength, class, limit.MaxBodyBytes)
+			writeLimitError(w, http.StatusRequestEntityTooLarge, map[string]interface{}{
+				"error":        "Request body too large",
+				"maxBodyBytes": limit.MaxBodyBytes,
+			})
+			return
+		}
+		r.Body = http.MaxBytesReader(w, r.Body, limit.MaxBodyBytes)
+
+		ctx, cancel := context.WithTimeout(r.Context(), limit.Timeout)
+		defer cancel()
+
+		// Streams are not buffered: the deadline only cancels the handler's context
+		if class == RouteClassStream {
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
+		tw := &timeoutWriter{w: w, h: make(http.Header)}
+		done := make(chan struct{})
+		panicChan := make(chan interface{}, 1)
+		go func() {
+			defer func() {
+				if p := recover(); p != nil {
+					panicChan <- p
+				}
+			}()
+			next.ServeHTTP(tw, r.WithContext(ctx))
+			close(done)
+		}()
+
+		select {
+		case p := <-panicChan:
+			panic(p)
+		case <-done:
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			tw.flush()
+		case <-ctx.Done():
+			tw.mu.Lock()
+			defer tw.mu.Unlock()
+			tw.timedOut = true
+			logger.Warningf("Request %s %s exceeded %s timeout of %s", r.Method, r.URL.Path, class, limit.Timeout)
+			writeLimitError(w, http.StatusServiceUnavailable, map[string]interface{}{
+				"error":          "Request timed out",
+				"timeoutSeconds": limit.Timeout.Seconds(),
+			})
+		}
+	})
+}
+
+// writeLimitError writes a JSON error body for a rejected request
+func writeLimitError(w http.ResponseWriter, status int, body map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Errorf("Failed to encode limit error response: %v", err)
+	}
+}
+
+// timeoutWriter buffers a handler's response so nothing is sent if the deadline passes first
+type timeoutWriter struct {
+	w           http.ResponseWriter
+	h           http.Header
+	buf         bytes.Buffer
+	mu          sync.Mutex
+	code        int
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (tw *timeoutWriter) Header() http.Header { return tw.h }
+
+func (tw *timeoutWriter) Write(p []byte) (int, error) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	if !tw.wroteHeader {
+		tw.writeHeaderLocked(http.StatusOK)
+	}
+	return tw.buf.Write(p)
+}
+
+func (tw *timeoutWriter) WriteHeader(code int) {
+	tw.mu.Lock()
+	defer tw.mu.Unlock()
+	if tw.timedOut || tw.wroteHeader {
+		return
+	}
+	tw.writeHeaderLocked(code)
+}
+
+func (tw *timeoutWriter) writeHeaderLocked(code int) {
+	tw.wroteHeader = true
+	tw.code = code
+}
+
+// flush copies the buffered response to the client; callers hold tw.mu
+func (tw *timeoutWriter) flush() {
+	dst := tw.w.Header()
+	for k, vv := range tw.h {
+		dst[k] = vv
+	}
+	if !tw.wroteHeader {
+		tw.code = http.StatusOK
+	}
+	tw.w.WriteHeader(tw.code)
+	if tw.buf.Len() == 0 {
+		return
+	}
+	if _, err := tw.w.Write(tw.buf.Bytes()); err != nil {
+		logger.Errorf("Failed to write response: %v", err)
+	}
+}
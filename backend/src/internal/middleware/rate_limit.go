@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// rateLimitIdle is how long a key may go unused before its bucket is dropped; by then it
+// has refilled, so dropping it changes nothing
+const rateLimitIdle = 10 * time.Minute
+
+// RateLimit allows PerMinute requests per key with bursts of up to Burst
+type RateLimit struct {
+	PerMinute int
+	Burst     int
+}
+
+// RateLimiter enforces a RateLimit per key with in-memory token buckets, so each API
+// process counts its own requests
+type RateLimiter struct {
+	limit RateLimit
+	key   func(r *http.Request) string
+	now   func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*rateBucket
+	swept   time.Time
+}
+
+// rateBucket holds the tokens left for one key
+type rateBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter creates a rate limiter; key picks the bucket for a request
+func NewRateLimiter(limit RateLimit, key func(r *http.Request) string) *RateLimiter {
+	return &RateLimiter{
+		limit:   limit,
+		key:     key,
+		now:     time.Now,
+		buckets: make(map[string]*rateBucket),
+	}
+}
+
+// TenantKey limits requests per tenant
+func TenantKey(r *http.Request) string {
+	return mux.Vars(r)["tenantId"]
+}
+
+// TenantCallerKey limits requests per tenant and client IP
+func TenantCallerKey(r *http.Request) string {
+	return mux.Vars(r)["tenantId"] + "|" + ClientIP(r)
+}
+
+// Limit rejects requests over the limit with 429, a Retry-After header and a JSON error
+func (l *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := l.key(r)
+		if wait := l.take(key); wait > 0 {
+			retryAfter := int(math.Ceil(wait.Seconds()))
+			logger.Warningf("Rate limited %s %s for %s; retry in %ds", r.Method, r.URL.Path, key, retryAfter)
+			w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+			writeLimitError(w, http.StatusTooManyRequests, map[string]interface{}{
+				"error":             "Too many requests",
+				"retryAfterSeconds": retryAfter,
+			})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// take spends a token for key; it returns 0 when one was available, or how long until one is
+func (l *RateLimiter) take(key string) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	rate := float64(l.limit.PerMinute) / 60
+	burst := float64(l.limit.Burst)
+	if burst < 1 {
+		burst = 1
+	}
+
+	if now.Sub(l.swept) > rateLimitIdle {
+		for k, b := range l.buckets {
+			if now.Sub(b.updated) > rateLimitIdle {
+				delete(l.buckets, k)
+			}
+		}
+		l.swept = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &rateBucket{tokens: burst, updated: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	if b.tokens >= 1 {
+		b.tokens--
+		return 0
+	}
+	if rate <= 0 {
+		return rateLimitIdle
+	}
+	return time.Duration((1 - b.tokens) / rate * float64(time.Second))
+}
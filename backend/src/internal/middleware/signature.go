@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"welltaxpro/src/internal/signing"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/gorilla/mux"
+)
+
+// SigningKeyContextKey stores the key ID of a verified signed request
+const SigningKeyContextKey contextKey = "signingKeyID"
+
+// SignatureStore is the part of the store signature verification reads; *store.Store implements it
+type SignatureStore interface {
+	HasActiveSigningKeys(tenantID string) (bool, error)
+	GetSigningSecret(tenantID, keyID string) (string, error)
+	UseSigningNonce(keyID, nonce string, expiresAt time.Time) (bool, error)
+}
+
+// SignatureMiddleware verifies HMAC-signed public requests and rejects replays
+type SignatureMiddleware struct {
+	store SignatureStore
+}
+
+// NewSignatureMiddleware creates a new signature middleware
+func NewSignatureMiddleware(store SignatureStore) *SignatureMiddleware {
+	return &SignatureMiddleware{
+		store: store,
+	}
+}
+
+// Verify checks the signature headers of requests to a tenant's public endpoints.
+// Tenants without an active signing key accept unsigned requests; once a key exists
+// every request must be signed, fall inside the timestamp window and use a fresh nonce.
+func (m *SignatureMiddleware) Verify(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenantID := mux.Vars(r)["tenantId"]
+		keyID := r.Header.Get(types.SignatureHeaderKeyID)
+
+		if keyID == "" {
+			required, err := m.store.HasActiveSigningKeys(tenantID)
+			if err != nil {
+				http.Error(w, "Failed to verify request", http.StatusInternalServerError)
+				return
+			}
+			if required {
+				logger.Warningf("Unsigned request to %s rejected for tenant %s", r.URL.Path, tenantID)
+				http.Error(w, "Unauthorized: Signature required", http.StatusUnauthorized)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		timestamp := r.Header.Get(types.SignatureHeaderTimestamp)
+		nonce := r.Header.Get(types.SignatureHeaderNonce)
+		signature := r.Header.Get(types.SignatureHeaderSignature)
+		if timestamp == "" || nonce == "" || signature == "" || len(nonce) > 128 {
+			http.Error(w, "Unauthorized: Incomplete signature headers", http.StatusUnauthorized)
+			return
+		}
+
+		unix, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil {
+			http.Error(w, "Unauthorized: Invalid timestamp", http.StatusUnauthorized)
+			return
+		}
+		signedAt := time.Unix(unix, 0)
+		if drift := time.Since(signedAt); drift > types.SignatureWindow || drift < -types.SignatureWindow {
+			logger.Warningf("Signed request with key %s outside the replay window (%s)", keyID, drift)
+			http.Error(w, "Unauthorized: Request expired", http.StatusUnauthorized)
+			return
+		}
+
+		secret, err := m.store.GetSigningSecret(tenantID, keyID)
+		if err != nil {
+			logger.Warningf("Signed request with unknown key %s for tenant %s", keyID, tenantID)
+			http.Error(w, "Unauthorized: Invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		if !signing.Verify(secret, timestamp, nonce, r.Method, r.URL.Path, body, signature) {
+			logger.Warningf("Invalid signature with key %s for tenant %s", keyID, tenantID)
+			http.Error(w, "Unauthorized: Invalid signature", http.StatusUnauthorized)
+			return
+		}
+
+		// Remember the nonce until the request could no longer pass the timestamp check
+		fresh, err := m.store.UseSigningNonce(keyID, nonce, signedAt.Add(types.SignatureWindow))
+		if err != nil {
+			http.Error(w, "Failed to verify request", http.StatusInternalServerError)
+			return
+		}
+		if !fresh {
+			logger.Warningf("Replayed nonce with key %s for tenant %s", keyID, tenantID)
+			http.Error(w, "Unauthorized: Request already processed", http.StatusUnauthorized)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), SigningKeyContextKey, keyID)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetSigningKeyFromContext returns the key ID that signed the request, if it was signed
+func GetSigningKeyFromContext(ctx context.Context) (string, bool) {
+	keyID, ok := ctx.Value(SigningKeyContextKey).(string)
+	return keyID, ok
+}
@@ -2,32 +2,65 @@ package middleware
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"net/http"
 	"strings"
-	"welltaxpro/src/internal/auth"
+	"sync"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
+	"github.com/gorilla/mux"
 )
 
 type contextKey string
 
 const FirebaseUIDContextKey contextKey = "firebaseUID"
 
+// PortalSessionContextKey holds the *types.PortalSession of a request, when the tenant's portal
+// security policy records sessions
+const PortalSessionContextKey contextKey = "portalSession"
+
 // TenantUserAuthMiddleware validates Firebase token for tenant users (clients)
 // Unlike AuthMiddleware, this does not require an employee record
 type TenantUserAuthMiddleware struct {
-	auth *auth.Auth
+	auth   TokenVerifier
+	store  TenantUserAuthStore
+	logins sync.Map // Latest sign-in recorded per tenant and Firebase UID, as a unix auth time
+}
+
+// TenantUserAuthStore is the part of the store tenant user authentication reads; *store.Store implements it
+type TenantUserAuthStore interface {
+	IsTenantPortalBlocked(tenantID string) (bool, error)
+	GetPortalSecurityPolicy(tenantID string) (*types.PortalSecurityPolicy, error)
+	TouchPortalSession(tenantID, firebaseUID string, authTime int64, policy *types.PortalSecurityPolicy) (*types.PortalSession, error)
+	RecordTenantUserLogin(tenantID, firebaseUID string, loginAt time.Time) (bool, error)
 }
 
 // NewTenantUserAuthMiddleware creates a new tenant user auth middleware
-func NewTenantUserAuthMiddleware(authClient *auth.Auth) *TenantUserAuthMiddleware {
+func NewTenantUserAuthMiddleware(authClient TokenVerifier, store TenantUserAuthStore) *TenantUserAuthMiddleware {
 	return &TenantUserAuthMiddleware{
-		auth: authClient,
+		auth:  authClient,
+		store: store,
 	}
 }
 
-// Authenticate validates the Firebase token and stores the Firebase UID in context
+// Authenticate validates the Firebase token and stores the Firebase UID in context.
+// Requests to a tenant whose portal was closed by offboarding are refused, as are sign-ins the
+// tenant's portal security policy has ended or still needs verified.
 func (m *TenantUserAuthMiddleware) Authenticate(next http.Handler) http.Handler {
+	return m.authenticate(next, true)
+}
+
+// AuthenticateUnverified is Authenticate for the routes a sign-in needs before it is verified:
+// registration, legal acceptance and verification itself
+func (m *TenantUserAuthMiddleware) AuthenticateUnverified(next http.Handler) http.Handler {
+	return m.authenticate(next, false)
+}
+
+func (m *TenantUserAuthMiddleware) authenticate(next http.Handler, requireVerified bool) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Get Authorization header
 		authHeader := r.Header.Get("Authorization")
@@ -41,22 +74,99 @@ func (m *TenantUserAuthMiddleware) Authenticate(next http.Handler) http.Handler
 		token := strings.TrimPrefix(authHeader, "Bearer ")
 
 		// Validate token with Firebase
-		firebaseUID, err := m.auth.ValidateToken(r.Context(), token)
+		decodedToken, err := m.auth.VerifyToken(r.Context(), token)
 		if err != nil {
 			logger.Errorf("Token validation failed: %v", err)
 			http.Error(w, "Unauthorized: Invalid token", http.StatusUnauthorized)
 			return
 		}
+		firebaseUID := decodedToken.UID
+		tenantID := mux.Vars(r)["tenantId"]
+
+		blocked, err := m.store.IsTenantPortalBlocked(tenantID)
+		if err != nil {
+			http.Error(w, "Failed to check portal access", http.StatusInternalServerError)
+			return
+		}
+		if blocked {
+			logger.Warningf("Refusing portal request to offboarded tenant %s", tenantID)
+			http.Error(w, "The portal is closed for this firm", http.StatusForbidden)
+			return
+		}
 
 		// Add Firebase UID to request context
-		ctx := context.WithValue(r.Context(), FirebaseUIDContextKey, *firebaseUID)
-		logger.Infof("Authenticated tenant user with Firebase UID: %s", *firebaseUID)
+		ctx := context.WithValue(r.Context(), FirebaseUIDContextKey, firebaseUID)
+		logger.Infof("Authenticated tenant user with Firebase UID: %s", firebaseUID)
+
+		// Unknown tenants have no policy; the handler reports the missing tenant
+		policy, err := m.store.GetPortalSecurityPolicy(tenantID)
+		if errors.Is(err, apperr.ErrNotFound) {
+			policy = types.DefaultPortalSecurityPolicy()
+		} else if err != nil {
+			http.Error(w, "Failed to check portal session", http.StatusInternalServerError)
+			return
+		}
+		if policy.TracksSessions() {
+			session, err := m.store.TouchPortalSession(tenantID, firebaseUID, decodedToken.AuthTime, policy)
+			if err != nil {
+				http.Error(w, "Failed to check portal session", http.StatusInternalServerError)
+				return
+			}
+			if session.EndedAt != nil {
+				logger.Warningf("Token of ended portal session %s presented in tenant %s (%s)", session.ID, tenantID, *session.EndReason)
+				writePortalSessionError(w, http.StatusUnauthorized, "SESSION_ENDED", "Your session has ended; sign in again", map[string]interface{}{"reason": *session.EndReason})
+				return
+			}
+			if requireVerified && policy.RequiresVerification() && session.VerifiedAt == nil {
+				writePortalSessionError(w, http.StatusForbidden, "VERIFICATION_REQUIRED", "Verify your identity to continue", map[string]interface{}{"method": policy.VerificationMethod})
+				return
+			}
+			ctx = context.WithValue(ctx, PortalSessionContextKey, session)
+		}
+		m.recordLogin(tenantID, firebaseUID, decodedToken.AuthTime)
 
 		// Call next handler with Firebase UID in context
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// recordLogin records a portal sign-in as the user's last login the first time one of its tokens
+// is accepted. Failures are logged without refusing the request.
+func (m *TenantUserAuthMiddleware) recordLogin(tenantID, firebaseUID string, authTime int64) {
+	if authTime <= 0 {
+		return
+	}
+	key := tenantID + "/" + firebaseUID
+	if last, ok := m.logins.Load(key); ok && last.(int64) >= authTime {
+		return
+	}
+	// Users not yet registered are retried on their next request
+	recorded, err := m.store.RecordTenantUserLogin(tenantID, firebaseUID, time.Unix(authTime, 0))
+	if err != nil {
+		logger.Warningf("Failed to record portal login in tenant %s: %v", tenantID, err)
+		return
+	}
+	if recorded {
+		m.logins.Store(key, authTime)
+	}
+}
+
+// writePortalSessionError tells the portal to sign in again or to verify the sign-in before retrying
+func writePortalSessionError(w http.ResponseWriter, status int, code, message string, details map[string]interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	body := map[string]interface{}{
+		"error": message,
+		"code":  code,
+	}
+	for k, v := range details {
+		body[k] = v
+	}
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Errorf("Failed to encode portal session error response: %v", err)
+	}
+}
+
 // GetFirebaseUIDFromContext retrieves the Firebase UID from context
 func GetFirebaseUIDFromContext(ctx context.Context) (string, error) {
 	firebaseUID, ok := ctx.Value(FirebaseUIDContextKey).(string)
@@ -65,3 +175,10 @@ func GetFirebaseUIDFromContext(ctx context.Context) (string, error) {
 	}
 	return firebaseUID, nil
 }
+
+// GetPortalSessionFromContext retrieves the portal session from context; it is only there when
+// the tenant's portal security policy records sessions
+func GetPortalSessionFromContext(ctx context.Context) (*types.PortalSession, bool) {
+	session, ok := ctx.Value(PortalSessionContextKey).(*types.PortalSession)
+	return session, ok
+}
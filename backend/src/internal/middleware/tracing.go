@@ -0,0 +1,85 @@
+package middleware
+
+import (
+	"net/http"
+	"welltaxpro/src/internal/tracing"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Trace starts a span for each routed request, named by its method and route template, and
+// continues the caller's trace when the request carries one. Handlers find the span in the
+// request context, so work they pass that context to is traced as part of the request.
+func Trace(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := r.URL.Path
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		attrs := []attribute.KeyValue{
+			tracing.HTTPMethodKey.String(r.Method),
+			tracing.HTTPRouteKey.String(route),
+			attribute.String("url.path", r.URL.Path),
+		}
+		if tenantID := mux.Vars(r)["tenantId"]; tenantID != "" {
+			attrs = append(attrs, tracing.Tenant(tenantID))
+		}
+		ctx, span := tracing.Start(tracing.Extract(r.Context(), r.Header), r.Method+" "+route, attrs...)
+
+		sw := &statusWriter{ResponseWriter: w}
+		defer func() {
+			status := sw.statusCode()
+			span.SetAttributes(tracing.HTTPStatusKey.Int(status))
+			if status >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(status))
+			}
+			span.End()
+		}()
+		next.ServeHTTP(sw, r.WithContext(ctx))
+	})
+}
+
+// statusWriter passes a response through while keeping its status
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sw *statusWriter) WriteHeader(code int) {
+	if sw.status == 0 {
+		sw.status = code
+	}
+	sw.ResponseWriter.WriteHeader(code)
+}
+
+func (sw *statusWriter) Write(p []byte) (int, error) {
+	if sw.status == 0 {
+		sw.status = http.StatusOK
+	}
+	return sw.ResponseWriter.Write(p)
+}
+
+// Flush lets streamed responses reach the client while they are traced
+func (sw *statusWriter) Flush() {
+	if flusher, ok := sw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (sw *statusWriter) Unwrap() http.ResponseWriter {
+	return sw.ResponseWriter
+}
+
+// statusCode returns the response status, which is 200 when the handler never set one
+func (sw *statusWriter) statusCode() int {
+	if sw.status == 0 {
+		return http.StatusOK
+	}
+	return sw.status
+}
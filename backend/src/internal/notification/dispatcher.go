@@ -0,0 +1,295 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// PreferenceStore is the subset of the store the dispatcher needs
+type PreferenceStore interface {
+	GetAllEmployees(includeInactive bool) ([]*types.Employee, error)
+	GetEmployeeByID(employeeID uuid.UUID) (*types.Employee, error)
+	GetTenantEmployees(tenantID string) ([]*types.TenantEmployee, error)
+	GetFilingAssignment(tenantID string, filingID uuid.UUID) (*types.FilingAssignment, error)
+	GetNotificationPreference(employeeID uuid.UUID, category string) (*types.NotificationPreference, error)
+	GetNotificationChannels(employeeID uuid.UUID) (*types.NotificationChannels, error)
+	CreateEmployeeNotification(n *types.EmployeeNotification) error
+	CreateNotificationEvent(event *types.NotificationEvent) error
+	GetPendingDigestEvents() (map[uuid.UUID][]*types.NotificationEvent, error)
+	MarkEventsDigested(eventIDs []uuid.UUID) error
+	RecordJobRun(jobName string, startedAt time.Time, items int, runErr error) error
+}
+
+// Dispatcher routes staff alerts according to each employee's notification preferences
+type Dispatcher struct {
+	store        PreferenceStore
+	emailService *EmailService
+	slack        *SlackService
+}
+
+// NewDispatcher creates a new staff notification dispatcher; slack is nil when Slack is not
+// configured
+func NewDispatcher(store PreferenceStore, emailService *EmailService, slack *SlackService) *Dispatcher {
+	return &Dispatcher{
+		store:        store,
+		emailService: emailService,
+		slack:        slack,
+	}
+}
+
+// Notify delivers an alert to each recipient's staff console inbox and, per their preferences,
+// by email now or in the daily digest and by Slack direct message
+func (d *Dispatcher) Notify(recipients []*types.Employee, category string, tenantID *string, subject, body string) {
+	for _, employee := range recipients {
+		pref, err := d.store.GetNotificationPreference(employee.ID, category)
+		if err != nil {
+			// Fall back to immediate delivery so alerts are not silently lost
+			pref = types.DefaultNotificationPreference(category)
+		}
+		if pref.Mode == types.NotificationModeOff {
+			continue
+		}
+
+		inbox := &types.EmployeeNotification{
+			EmployeeID: employee.ID,
+			TenantID:   tenantID,
+			Category:   category,
+			Subject:    subject,
+			Body:       body,
+		}
+		if err := d.store.CreateEmployeeNotification(inbox); err != nil {
+			logger.Errorf("Failed to add %s notification to the inbox of %s: %v", category, employee.Email, err)
+		}
+
+		if pref.Mode == types.NotificationModeDigest {
+			if !pref.Email {
+				continue
+			}
+			event := &types.NotificationEvent{
+				EmployeeID: employee.ID,
+				TenantID:   tenantID,
+				Category:   category,
+				Subject:    subject,
+				Body:       body,
+			}
+			if err := d.store.CreateNotificationEvent(event); err != nil {
+				logger.Errorf("Failed to queue %s notification for %s: %v", category, employee.Email, err)
+			}
+			continue
+		}
+
+		if pref.Slack {
+			d.sendSlack(employee, category, subject, body)
+		}
+		if !pref.Email {
+			continue
+		}
+		if d.emailService == nil {
+			logger.Warningf("Email service not configured, dropping %s notification for %s", category, employee.Email)
+			continue
+		}
+		emailSubject, htmlBody, textBody := GenerateAlertEmail(AlertEmail{
+			RecipientName: employee.FullName(),
+			Subject:       subject,
+			Body:          body,
+		})
+		email := &Email{
+			To:       employee.Email,
+			ToName:   employee.FullName(),
+			Subject:  emailSubject,
+			HTMLBody: htmlBody,
+			TextBody: textBody,
+		}
+		if tenantID != nil {
+			email.TenantID = *tenantID
+		}
+		if err := d.emailService.Send(email); err != nil {
+			logger.Errorf("Failed to send %s notification to %s: %v", category, employee.Email, err)
+		}
+	}
+}
+
+// sendSlack messages an employee who set a Slack member ID. The message is sent in the
+// background so a slow Slack API does not hold up the caller.
+func (d *Dispatcher) sendSlack(employee *types.Employee, category, subject, body string) {
+	if d.slack == nil {
+		return
+	}
+	channels, err := d.store.GetNotificationChannels(employee.ID)
+	if err != nil || channels.SlackUserID == nil {
+		return
+	}
+
+	slackUserID := *channels.SlackUserID
+	go func() {
+		text := fmt.Sprintf("*%s*\n%s", subject, body)
+		if err := d.slack.SendDirectMessage(context.Background(), slackUserID, text); err != nil {
+			logger.Errorf("Failed to send %s Slack notification to %s: %v", category, employee.Email, err)
+		}
+	}()
+}
+
+// NotifyFilingAssignee delivers an alert to the accountant assigned to a filing, or to the
+// tenant's admins when no active accountant is assigned
+func (d *Dispatcher) NotifyFilingAssignee(category string, tenantID string, filingID uuid.UUID, subject, body string) {
+	assignment, err := d.store.GetFilingAssignment(tenantID, filingID)
+	if err == nil {
+		employee, err := d.store.GetEmployeeByID(assignment.EmployeeID)
+		if err == nil && employee.IsActive {
+			d.Notify([]*types.Employee{employee}, category, &tenantID, subject, body)
+			return
+		}
+		if err != nil {
+			logger.Errorf("Failed to load accountant %s of filing %s: %v", assignment.EmployeeID, filingID, err)
+		}
+	} else if !errors.Is(err, apperr.ErrNotFound) {
+		logger.Errorf("Failed to get assignment of filing %s: %v", filingID, err)
+	}
+
+	d.NotifyTenantAdmins(category, tenantID, subject, body)
+}
+
+// NotifyAdmins delivers an alert to every active admin
+func (d *Dispatcher) NotifyAdmins(category string, tenantID *string, subject, body string) {
+	employees, err := d.store.GetAllEmployees(false)
+	if err != nil {
+		logger.Errorf("Failed to load admins for %s notification: %v", category, err)
+		return
+	}
+
+	var admins []*types.Employee
+	for _, e := range employees {
+		if e.Role == "admin" {
+			admins = append(admins, e)
+		}
+	}
+
+	d.Notify(admins, category, tenantID, subject, body)
+}
+
+// NotifyTenantAdmins delivers an alert to every active admin and to the employees who are admins
+// of the tenant through their tenant access
+func (d *Dispatcher) NotifyTenantAdmins(category string, tenantID string, subject, body string) {
+	employees, err := d.store.GetAllEmployees(false)
+	if err != nil {
+		logger.Errorf("Failed to load admins for %s notification: %v", category, err)
+		return
+	}
+	access, err := d.store.GetTenantEmployees(tenantID)
+	if err != nil {
+		logger.Errorf("Failed to load tenant %s admins for %s notification: %v", tenantID, category, err)
+		return
+	}
+
+	tenantAdmins := map[uuid.UUID]bool{}
+	for _, a := range access {
+		if a.IsActive && a.Role == "admin" {
+			tenantAdmins[a.EmployeeID] = true
+		}
+	}
+
+	var admins []*types.Employee
+	for _, e := range employees {
+		if e.Role == "admin" || tenantAdmins[e.ID] {
+			admins = append(admins, e)
+		}
+	}
+
+	d.Notify(admins, category, &tenantID, subject, body)
+}
+
+// AlertAdmins emails every active admin immediately, ignoring notification preferences.
+// Reserved for security events that must not be batched or muted.
+func (d *Dispatcher) AlertAdmins(subject, body string) {
+	if d.emailService == nil {
+		logger.Warningf("Email service not configured, dropping security alert: %s", subject)
+		return
+	}
+
+	employees, err := d.store.GetAllEmployees(false)
+	if err != nil {
+		logger.Errorf("Failed to load admins for security alert: %v", err)
+		return
+	}
+
+	for _, e := range employees {
+		if e.Role != "admin" {
+			continue
+		}
+		emailSubject, htmlBody, textBody := GenerateAlertEmail(AlertEmail{
+			RecipientName: e.FullName(),
+			Subject:       subject,
+			Body:          body,
+		})
+		if err := d.emailService.SendEmail(e.Email, e.FullName(), emailSubject, htmlBody, textBody); err != nil {
+			logger.Errorf("Failed to send security alert to %s: %v", e.Email, err)
+		}
+	}
+}
+
+// SendDigests emails each employee a single digest of their queued events and returns how many events were sent
+func (d *Dispatcher) SendDigests() (int, error) {
+	if d.emailService == nil {
+		return 0, fmt.Errorf("email service not configured")
+	}
+
+	pending, err := d.store.GetPendingDigestEvents()
+	if err != nil {
+		return 0, fmt.Errorf("failed to load pending digest events: %w", err)
+	}
+
+	sent := 0
+	for employeeID, events := range pending {
+		employee, err := d.store.GetEmployeeByID(employeeID)
+		if err != nil {
+			logger.Errorf("Failed to load employee %s for digest: %v", employeeID, err)
+			continue
+		}
+
+		ids := make([]uuid.UUID, 0, len(events))
+		for _, e := range events {
+			ids = append(ids, e.ID)
+		}
+
+		// Inactive employees should not receive mail; discard their queue
+		if !employee.IsActive {
+			if err := d.store.MarkEventsDigested(ids); err != nil {
+				logger.Errorf("Failed to discard digest events for %s: %v", employeeID, err)
+			}
+			continue
+		}
+
+		subject, htmlBody, textBody := GenerateDigestEmail(DigestEmail{
+			RecipientName: employee.FullName(),
+			Events:        events,
+		})
+		err = d.emailService.Send(&Email{
+			Priority: PriorityBulk,
+			To:       employee.Email,
+			ToName:   employee.FullName(),
+			Subject:  subject,
+			HTMLBody: htmlBody,
+			TextBody: textBody,
+		})
+		if err != nil {
+			logger.Errorf("Failed to send digest to %s: %v", employee.Email, err)
+			continue
+		}
+
+		if err := d.store.MarkEventsDigested(ids); err != nil {
+			logger.Errorf("Failed to mark digest events for %s: %v", employeeID, err)
+		}
+
+		sent += len(events)
+		logger.Infof("Sent digest of %d events to %s", len(events), employee.Email)
+	}
+
+	return sent, nil
+}
@@ -2,68 +2,155 @@ package notification
 
 import (
 	"fmt"
+	"strings"
+	"sync"
+	"time"
+	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
 	"github.com/sendgrid/sendgrid-go"
 	"github.com/sendgrid/sendgrid-go/helpers/mail"
 )
 
-// EmailService handles sending emails via SendGrid
+// maxCapturedEmails bounds the captured smoke test messages kept in memory
+const maxCapturedEmails = 100
+
+// EmailService handles sending emails via SendGrid.
+// Every message passes through a send queue that applies global and per-tenant rate limits
+// and sends transactional mail ahead of bulk mail.
 type EmailService struct {
 	apiKey           string
 	defaultFromEmail string
 	defaultFromName  string
+	queue            *sendQueue
+
+	// Messages to types.SmokeEmailDomain are captured here instead of sent
+	capturedMu sync.Mutex
+	captured   []*CapturedEmail
 }
 
-// NewEmailService creates a new email service instance
-func NewEmailService(apiKey, fromEmail, fromName string) *EmailService {
-	return &EmailService{
+// Email is one outgoing message
+type Email struct {
+	TenantID  string // Tenant the message is sent for; empty for platform mail, which only the global limit applies to
+	Priority  string // PriorityTransactional (default) or PriorityBulk
+	FromEmail string // Defaults to the service's from address
+	FromName  string
+	To        string
+	ToName    string
+	Subject   string
+	HTMLBody  string
+	TextBody  string
+	OutboxID  string // Outbox email the message sends, if any; SendGrid events carry it back as OutboxIDArg
+}
+
+// OutboxIDArg is the SendGrid custom argument naming the outbox email a message sent
+const OutboxIDArg = "outbox_id"
+
+// CapturedEmail is a message to the smoke test domain that was kept in memory instead of sent
+type CapturedEmail struct {
+	To         string    `json:"to"`
+	Subject    string    `json:"subject"`
+	TextBody   string    `json:"textBody"`
+	CapturedAt time.Time `json:"capturedAt"`
+}
+
+// NewEmailService creates a new email service instance and starts its send queue
+func NewEmailService(apiKey, fromEmail, fromName string, queue QueueConfig) *EmailService {
+	s := &EmailService{
 		apiKey:           apiKey,
 		defaultFromEmail: fromEmail,
 		defaultFromName:  fromName,
 	}
+	s.queue = newSendQueue(queue, s.deliver)
+	return s
 }
 
-// SendEmail sends an email using SendGrid
+// SendEmail sends a transactional platform email and waits for the result
 func (s *EmailService) SendEmail(to, toName, subject, htmlBody, textBody string) error {
-	from := mail.NewEmail(s.defaultFromName, s.defaultFromEmail)
-	recipient := mail.NewEmail(toName, to)
-	message := mail.NewSingleEmail(from, subject, recipient, textBody, htmlBody)
+	return s.Send(&Email{To: to, ToName: toName, Subject: subject, HTMLBody: htmlBody, TextBody: textBody})
+}
 
-	client := sendgrid.NewSendClient(s.apiKey)
-	response, err := client.Send(message)
-	if err != nil {
-		logger.Errorf("Failed to send email to %s: %v", to, err)
-		return fmt.Errorf("failed to send email: %w", err)
+// SendWithCustomFrom sends a transactional platform email with a custom from address
+func (s *EmailService) SendWithCustomFrom(fromEmail, fromName, to, toName, subject, htmlBody, textBody string) error {
+	return s.Send(&Email{FromEmail: fromEmail, FromName: fromName, To: to, ToName: toName, Subject: subject, HTMLBody: htmlBody, TextBody: textBody})
+}
+
+// Send queues an email and waits until it is sent.
+// It returns ErrQueueFull without waiting when the message's priority class is full.
+func (s *EmailService) Send(email *Email) error {
+	if s.capture(email.To, email.Subject, email.TextBody) {
+		return nil
 	}
 
-	if response.StatusCode >= 400 {
-		logger.Errorf("SendGrid error %d for %s: %s", response.StatusCode, to, response.Body)
-		return fmt.Errorf("sendgrid error: %d - %s", response.StatusCode, response.Body)
+	result, err := s.queue.enqueue(email)
+	if err != nil {
+		return err
 	}
+	return <-result
+}
 
-	logger.Infof("Email sent successfully to %s (status: %d)", to, response.StatusCode)
-	return nil
+// QueueStats reports the send queue's depth and counters
+func (s *EmailService) QueueStats() *QueueStats {
+	return s.queue.stats()
 }
 
-// SendWithCustomFrom sends an email with a custom from address
-func (s *EmailService) SendWithCustomFrom(fromEmail, fromName, to, toName, subject, htmlBody, textBody string) error {
+// deliver sends one message through SendGrid
+func (s *EmailService) deliver(email *Email) error {
+	fromEmail, fromName := email.FromEmail, email.FromName
+	if fromEmail == "" {
+		fromEmail, fromName = s.defaultFromEmail, s.defaultFromName
+	}
+
 	from := mail.NewEmail(fromName, fromEmail)
-	recipient := mail.NewEmail(toName, to)
-	message := mail.NewSingleEmail(from, subject, recipient, textBody, htmlBody)
+	recipient := mail.NewEmail(email.ToName, email.To)
+	message := mail.NewSingleEmail(from, email.Subject, recipient, email.TextBody, email.HTMLBody)
+	if email.OutboxID != "" {
+		message.SetCustomArg(OutboxIDArg, email.OutboxID)
+	}
 
 	client := sendgrid.NewSendClient(s.apiKey)
 	response, err := client.Send(message)
 	if err != nil {
-		logger.Errorf("Failed to send email to %s: %v", to, err)
+		logger.Errorf("Failed to send email to %s: %v", email.To, err)
 		return fmt.Errorf("failed to send email: %w", err)
 	}
 
 	if response.StatusCode >= 400 {
-		logger.Errorf("SendGrid error %d for %s: %s", response.StatusCode, to, response.Body)
+		logger.Errorf("SendGrid error %d for %s: %s", response.StatusCode, email.To, response.Body)
 		return fmt.Errorf("sendgrid error: %d - %s", response.StatusCode, response.Body)
 	}
 
-	logger.Infof("Email sent successfully to %s from %s (status: %d)", to, fromEmail, response.StatusCode)
+	logger.Infof("Email sent successfully to %s from %s (status: %d)", email.To, fromEmail, response.StatusCode)
 	return nil
 }
+
+// capture keeps a message to the smoke test domain in memory and reports whether it did
+func (s *EmailService) capture(to, subject, textBody string) bool {
+	if !strings.HasSuffix(strings.ToLower(to), "@"+types.SmokeEmailDomain) {
+		return false
+	}
+
+	s.capturedMu.Lock()
+	defer s.capturedMu.Unlock()
+	s.captured = append(s.captured, &CapturedEmail{To: to, Subject: subject, TextBody: textBody, CapturedAt: time.Now()})
+	if len(s.captured) > maxCapturedEmails {
+		s.captured = s.captured[len(s.captured)-maxCapturedEmails:]
+	}
+
+	logger.Infof("Captured email to %s instead of sending", to)
+	return true
+}
+
+// CapturedEmails returns the captured messages addressed to to, oldest first
+func (s *EmailService) CapturedEmails(to string) []*CapturedEmail {
+	s.capturedMu.Lock()
+	defer s.capturedMu.Unlock()
+
+	var emails []*CapturedEmail
+	for _, email := range s.captured {
+		if strings.EqualFold(email.To, to) {
+			emails = append(emails, email)
+		}
+	}
+	return emails
+}
@@ -0,0 +1,44 @@
+package notification
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DefaultAppURL is the web app emailed links point to when the deployment configures none
+const DefaultAppURL = "https://app.welltaxpro.com"
+
+// Links builds the web app URLs sent to clients. The zero value points at DefaultAppURL.
+type Links struct {
+	appURL string
+}
+
+// NewLinks roots links at appURL, an absolute http or https URL; empty uses DefaultAppURL
+func NewLinks(appURL string) (Links, error) {
+	if appURL == "" {
+		return Links{}, nil
+	}
+	parsed, err := url.Parse(appURL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return Links{}, fmt.Errorf("app URL %q must be an absolute http or https URL", appURL)
+	}
+	return Links{appURL: strings.TrimSuffix(appURL, "/")}, nil
+}
+
+func (l Links) base() string {
+	if l.appURL == "" {
+		return DefaultAppURL
+	}
+	return l.appURL
+}
+
+// Login is where clients of a tenant sign in
+func (l Links) Login(tenantID string) string {
+	return fmt.Sprintf("%s/%s/clients", l.base(), tenantID)
+}
+
+// Portal is where portal sign-in links of a tenant land
+func (l Links) Portal(tenantID string) string {
+	return fmt.Sprintf("%s/%s/portal", l.base(), tenantID)
+}
@@ -0,0 +1,409 @@
+package notification
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/types"
+)
+
+// Template variable kinds
+const (
+	VariableString  = "string"
+	VariableInteger = "integer"
+	VariableBoolean = "boolean"
+	VariableURL     = "url"  // Absolute http or https URL
+	VariableList    = "list" // List of strings
+)
+
+// TemplateVariable describes one variable of an email template
+type TemplateVariable struct {
+	Name        string      `json:"name"`
+	Kind        string      `json:"kind"`
+	Required    bool        `json:"required"` // Must not be empty when given
+	Sample      interface{} `json:"sample"`   // Used when the variable is not given
+	Description string      `json:"description"`
+}
+
+// TemplateInfo describes an email template that can be previewed and test-sent
+type TemplateInfo struct {
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Variables   []TemplateVariable `json:"variables"`
+}
+
+// RenderError explains why a template could not be rendered
+type RenderError struct {
+	Variable string `json:"variable,omitempty"`
+	Message  string `json:"message"`
+}
+
+// RenderedEmail is a template rendered with resolved variables
+type RenderedEmail struct {
+	Template  string                 `json:"template"`
+	Variables map[string]interface{} `json:"variables"` // Every variable, given or sample
+	Subject   string                 `json:"subject"`
+	HTMLBody  string                 `json:"htmlBody"`
+	TextBody  string                 `json:"textBody"`
+}
+
+// templateValues holds resolved variables by name, typed by kind
+type templateValues map[string]interface{}
+
+func (v templateValues) str(name string) string {
+	s, _ := v[name].(string)
+	return s
+}
+
+func (v templateValues) integer(name string) int {
+	n, _ := v[name].(int)
+	return n
+}
+
+func (v templateValues) boolean(name string) bool {
+	b, _ := v[name].(bool)
+	return b
+}
+
+func (v templateValues) list(name string) []string {
+	l, _ := v[name].([]string)
+	return l
+}
+
+// emailTemplate is a previewable template and how to render it from variables
+type emailTemplate struct {
+	info   TemplateInfo
+	render func(v templateValues) (subject, htmlBody, textBody string)
+}
+
+// emailTemplates lists every email template, keyed by name.
+// Add new Generate*Email templates here so admins can preview them.
+var emailTemplates = map[string]*emailTemplate{
+	"filing_completed": {
+		info: TemplateInfo{
+			Description: "Sent to a client when their filing is marked completed",
+			Variables: []TemplateVariable{
+				{Name: "clientName", Kind: VariableString, Required: true, Sample: "Jordan Avery", Description: "Client's full name"},
+				{Name: "taxYear", Kind: VariableInteger, Required: true, Sample: time.Now().Year() - 1, Description: "Tax year of the filing"},
+				{Name: "filingType", Kind: VariableString, Required: true, Sample: "Tax Return", Description: "Kind of return"},
+				{Name: "tenantName", Kind: VariableString, Required: true, Description: "Firm name (defaults to the tenant's)"},
+				{Name: "loginUrl", Kind: VariableURL, Required: true, Description: "Link to the client list (defaults to the tenant's)"},
+				{Name: "deceased", Kind: VariableBoolean, Sample: false, Description: "Address the personal representative of a deceased taxpayer"},
+			},
+		},
+		render: func(v templateValues) (string, string, string) {
+			return GenerateFilingCompletedEmail(FilingCompletedEmail{
+				ClientName: v.str("clientName"),
+				TaxYear:    v.integer("taxYear"),
+				FilingType: v.str("filingType"),
+				TenantName: v.str("tenantName"),
+				LoginURL:   v.str("loginUrl"),
+				Deceased:   v.boolean("deceased"),
+			})
+		},
+	},
+	"portal_access": {
+		info: TemplateInfo{
+			Description: "Magic link giving a client access to the document portal",
+			Variables: []TemplateVariable{
+				{Name: "clientName", Kind: VariableString, Required: true, Sample: "Jordan Avery", Description: "Client's full name"},
+				{Name: "tenantName", Kind: VariableString, Required: true, Description: "Firm name (defaults to the tenant's)"},
+				{Name: "portalUrl", Kind: VariableURL, Required: true, Sample: "https://portal.example.com/access?token=sample", Description: "Magic link into the portal"},
+			},
+		},
+		render: func(v templateValues) (string, string, string) {
+			return GeneratePortalAccessEmail(PortalAccessEmail{
+				ClientName: v.str("clientName"),
+				TenantName: v.str("tenantName"),
+				PortalURL:  v.str("portalUrl"),
+			})
+		},
+	},
+	"portal_verification": {
+		info: TemplateInfo{
+			Description: "One-time code a client enters to verify a portal sign-in, when the firm's portal security policy asks for one",
+			Variables: []TemplateVariable{
+				{Name: "clientName", Kind: VariableString, Required: true, Sample: "Jordan Avery", Description: "Client's full name"},
+				{Name: "tenantName", Kind: VariableString, Required: true, Description: "Firm name (defaults to the tenant's)"},
+				{Name: "code", Kind: VariableString, Required: true, Sample: "482913", Description: "Six-digit verification code"},
+				{Name: "minutes", Kind: VariableInteger, Required: true, Sample: int(types.PortalOTPLifetime.Minutes()), Description: "Minutes until the code expires"},
+			},
+		},
+		render: func(v templateValues) (string, string, string) {
+			return GeneratePortalVerificationEmail(PortalVerificationEmail{
+				ClientName: v.str("clientName"),
+				TenantName: v.str("tenantName"),
+				Code:       v.str("code"),
+				Minutes:    v.integer("minutes"),
+			})
+		},
+	},
+	"staff_alert": {
+		info: TemplateInfo{
+			Description: "Immediate staff alert for a notification category",
+			Variables: []TemplateVariable{
+				{Name: "recipientName", Kind: VariableString, Required: true, Description: "Staff member's name (defaults to yours)"},
+				{Name: "subject", Kind: VariableString, Required: true, Sample: "New document uploaded", Description: "Alert subject"},
+				{Name: "body", Kind: VariableString, Required: true, Sample: "Jordan Avery uploaded W-2.pdf to their 2024 filing.", Description: "Alert text"},
+			},
+		},
+		render: func(v templateValues) (string, string, string) {
+			return GenerateAlertEmail(AlertEmail{
+				RecipientName: v.str("recipientName"),
+				Subject:       v.str("subject"),
+				Body:          v.str("body"),
+			})
+		},
+	},
+	"daily_digest": {
+		info: TemplateInfo{
+			Description: "Daily digest of staff notifications; previews list one sample event per category",
+			Variables: []TemplateVariable{
+				{Name: "recipientName", Kind: VariableString, Required: true, Description: "Staff member's name (defaults to yours)"},
+			},
+		},
+		render: func(v templateValues) (string, string, string) {
+			events := make([]*types.NotificationEvent, len(types.NotificationCategories))
+			for i, category := range types.NotificationCategories {
+				events[i] = &types.NotificationEvent{
+					Category: category,
+					Subject:  fmt.Sprintf("Sample %s notification", strings.ToLower(category)),
+					Body:     "This event was generated for a template preview.",
+				}
+			}
+			return GenerateDigestEmail(DigestEmail{RecipientName: v.str("recipientName"), Events: events})
+		},
+	},
+	"inbound_ack": {
+		info: TemplateInfo{
+			Description: "Acknowledgment sent to a client who emailed documents to the firm",
+			Variables: []TemplateVariable{
+				{Name: "clientName", Kind: VariableString, Required: true, Sample: "Jordan", Description: "Client's first name"},
+				{Name: "tenantName", Kind: VariableString, Required: true, Description: "Firm name (defaults to the tenant's)"},
+				{Name: "files", Kind: VariableList, Required: true, Sample: []string{"W-2.pdf", "1099-INT.pdf"}, Description: "Names of the received files"},
+			},
+		},
+		render: func(v templateValues) (string, string, string) {
+			return GenerateInboundAckEmail(InboundAckEmail{
+				ClientName: v.str("clientName"),
+				TenantName: v.str("tenantName"),
+				Files:      v.list("files"),
+			})
+		},
+	},
+	"document_request": {
+		info: TemplateInfo{
+			Description: "Reminder sent to a client with open document requests, such as replacements for expiring IDs",
+			Variables: []TemplateVariable{
+				{Name: "clientName", Kind: VariableString, Required: true, Sample: "Jordan", Description: "Client's first name"},
+				{Name: "tenantName", Kind: VariableString, Required: true, Description: "Firm name (defaults to the tenant's)"},
+				{Name: "requests", Kind: VariableList, Required: true, Sample: []string{"Please upload a new photo ID (drivers license) to replace license.jpg, which expires on 2025-03-01"}, Description: "Descriptions of the open requests"},
+			},
+		},
+		render: func(v templateValues) (string, string, string) {
+			return GenerateDocumentRequestEmail(DocumentRequestEmail{
+				ClientName: v.str("clientName"),
+				TenantName: v.str("tenantName"),
+				Requests:   v.list("requests"),
+			})
+		},
+	},
+	"filing_status": {
+		info: TemplateInfo{
+			Description: "Sent to a client when their filing moves to FILED or ACCEPTED",
+			Variables: []TemplateVariable{
+				{Name: "clientName", Kind: VariableString, Required: true, Sample: "Jordan", Description: "Client's first name"},
+				{Name: "tenantName", Kind: VariableString, Required: true, Description: "Firm name (defaults to the tenant's)"},
+				{Name: "taxYear", Kind: VariableInteger, Required: true, Sample: time.Now().Year() - 1, Description: "Tax year of the filing"},
+				{Name: "status", Kind: VariableString, Required: true, Sample: types.FilingStatusFiled, Description: "FILED or ACCEPTED"},
+				{Name: "loginUrl", Kind: VariableURL, Required: true, Description: "Link to the client list (defaults to the tenant's)"},
+			},
+		},
+		render: func(v templateValues) (string, string, string) {
+			return GenerateFilingStatusEmail(FilingStatusEmail{
+				ClientName: v.str("clientName"),
+				TenantName: v.str("tenantName"),
+				TaxYear:    v.integer("taxYear"),
+				Status:     v.str("status"),
+				LoginURL:   v.str("loginUrl"),
+			})
+		},
+	},
+	"affiliate_commission": {
+		info: TemplateInfo{
+			Description: "Batched update sent to an affiliate when their commissions are created, approved, paid or cancelled; previews list one sample event of each kind",
+			Variables: []TemplateVariable{
+				{Name: "affiliateName", Kind: VariableString, Required: true, Sample: "Riley", Description: "Affiliate's first name"},
+				{Name: "tenantName", Kind: VariableString, Required: true, Description: "Firm name (defaults to the tenant's)"},
+			},
+		},
+		render: func(v templateValues) (string, string, string) {
+			events := make([]*types.AffiliateNotification, len(types.AffiliateNotificationEvents))
+			for i, event := range types.AffiliateNotificationEvents {
+				events[i] = &types.AffiliateNotification{
+					Event:            event,
+					CommissionAmount: types.Cents(2500),
+					CreatedAt:        time.Now(),
+				}
+			}
+			return GenerateAffiliateCommissionEmail(AffiliateCommissionEmail{
+				AffiliateName: v.str("affiliateName"),
+				TenantName:    v.str("tenantName"),
+				Events:        events,
+			})
+		},
+	},
+}
+
+// Templates lists the email templates that can be previewed, sorted by name
+func Templates() []TemplateInfo {
+	infos := make([]TemplateInfo, 0, len(emailTemplates))
+	for name, t := range emailTemplates {
+		info := t.info
+		info.Name = name
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Name < infos[j].Name })
+	return infos
+}
+
+// RenderTemplate renders a template with the given variables (decoded JSON values).
+// Variables left out take defaults, then samples. Unknown variables, values of the wrong
+// kind and empty required values are reported as render errors instead of rendering.
+func RenderTemplate(name string, variables, defaults map[string]interface{}) (rendered *RenderedEmail, renderErrors []RenderError) {
+	t, ok := emailTemplates[name]
+	if !ok {
+		return nil, []RenderError{{Message: fmt.Sprintf("unknown template %q", name)}}
+	}
+
+	known := map[string]bool{}
+	values := templateValues{}
+	for _, variable := range t.info.Variables {
+		known[variable.Name] = true
+
+		raw, given := variables[variable.Name]
+		if !given || raw == nil {
+			if raw, given = defaults[variable.Name]; !given {
+				raw = variable.Sample
+			}
+		}
+
+		value, err := resolveVariable(variable, raw)
+		if err != nil {
+			renderErrors = append(renderErrors, RenderError{Variable: variable.Name, Message: err.Error()})
+			continue
+		}
+		values[variable.Name] = value
+	}
+	for name := range variables {
+		if !known[name] {
+			renderErrors = append(renderErrors, RenderError{Variable: name, Message: "not a variable of this template"})
+		}
+	}
+	if len(renderErrors) > 0 {
+		sort.Slice(renderErrors, func(i, j int) bool { return renderErrors[i].Variable < renderErrors[j].Variable })
+		return nil, renderErrors
+	}
+
+	// A template that panics on unexpected input is reported rather than failing the request
+	defer func() {
+		if p := recover(); p != nil {
+			rendered, renderErrors = nil, []RenderError{{Message: fmt.Sprintf("template failed to render: %v", p)}}
+		}
+	}()
+
+	rendered = &RenderedEmail{Template: name, Variables: values}
+	rendered.Subject, rendered.HTMLBody, rendered.TextBody = t.render(values)
+	return rendered, nil
+}
+
+// resolveVariable converts a decoded JSON value to the variable's kind
+func resolveVariable(variable TemplateVariable, raw interface{}) (interface{}, error) {
+	switch variable.Kind {
+	case VariableString, VariableURL:
+		s, ok := raw.(string)
+		if !ok {
+			if raw != nil {
+				return nil, fmt.Errorf("must be a string")
+			}
+			s = ""
+		}
+		s = strings.TrimSpace(s)
+		if s == "" {
+			if variable.Required {
+				return nil, fmt.Errorf("is required")
+			}
+			return s, nil
+		}
+		if variable.Kind == VariableURL {
+			u, err := url.Parse(s)
+			if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+				return nil, fmt.Errorf("must be an absolute http or https URL")
+			}
+		}
+		return s, nil
+
+	case VariableInteger:
+		switch n := raw.(type) {
+		case int:
+			return n, nil
+		case float64:
+			if n != math.Trunc(n) || math.Abs(n) > math.MaxInt32 {
+				return nil, fmt.Errorf("must be a whole number")
+			}
+			return int(n), nil
+		case string:
+			i, err := strconv.Atoi(strings.TrimSpace(n))
+			if err != nil {
+				return nil, fmt.Errorf("must be a whole number")
+			}
+			return i, nil
+		}
+		if variable.Required {
+			return nil, fmt.Errorf("is required")
+		}
+		return 0, nil
+
+	case VariableBoolean:
+		switch b := raw.(type) {
+		case bool:
+			return b, nil
+		case string:
+			parsed, err := strconv.ParseBool(strings.TrimSpace(b))
+			if err != nil {
+				return nil, fmt.Errorf("must be true or false")
+			}
+			return parsed, nil
+		case nil:
+			return false, nil
+		}
+		return nil, fmt.Errorf("must be true or false")
+
+	case VariableList:
+		var items []string
+		switch l := raw.(type) {
+		case []string:
+			items = l
+		case []interface{}:
+			for _, item := range l {
+				s, ok := item.(string)
+				if !ok {
+					return nil, fmt.Errorf("must be a list of strings")
+				}
+				items = append(items, s)
+			}
+		case nil:
+		default:
+			return nil, fmt.Errorf("must be a list of strings")
+		}
+		if len(items) == 0 && variable.Required {
+			return nil, fmt.Errorf("is required")
+		}
+		return items, nil
+	}
+	return nil, fmt.Errorf("has unknown kind %q", variable.Kind)
+}
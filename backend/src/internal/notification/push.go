@@ -0,0 +1,113 @@
+package notification
+
+import (
+	"context"
+	"fmt"
+	"welltaxpro/src/internal/types"
+
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/messaging"
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// PushStore is the subset of the store the push service needs
+type PushStore interface {
+	GetPushTargets(tenantID string, clientID uuid.UUID) ([]string, error)
+	DeleteDeviceTokens(tokens []string) error
+}
+
+// PushService sends portal push notifications through Firebase Cloud Messaging.
+// APNs delivery for iOS devices is handled by FCM.
+type PushService struct {
+	client *messaging.Client
+	store  PushStore
+}
+
+// NewPushService creates the push service; push is disabled if FCM cannot be initialized
+func NewPushService(ctx context.Context, app *firebase.App, store PushStore) *PushService {
+	p := &PushService{store: store}
+	if app == nil {
+		logger.Warning("Firebase app not available, push notifications disabled")
+		return p
+	}
+
+	client, err := app.Messaging(ctx)
+	if err != nil {
+		logger.Errorf("Failed to initialize Firebase Cloud Messaging, push notifications disabled: %v", err)
+		return p
+	}
+
+	p.client = client
+	return p
+}
+
+// NotifyClient pushes a notification to every opted-in device of a client's portal users.
+// Tokens FCM reports as no longer valid are removed.
+func (p *PushService) NotifyClient(ctx context.Context, tenantID string, clientID uuid.UUID, category, title, body string, data map[string]string) {
+	if p == nil || p.client == nil {
+		return
+	}
+
+	tokens, err := p.store.GetPushTargets(tenantID, clientID)
+	if err != nil || len(tokens) == 0 {
+		return
+	}
+
+	payload := map[string]string{
+		"category": category,
+		"tenantId": tenantID,
+	}
+	for k, v := range data {
+		payload[k] = v
+	}
+
+	var stale []string
+	// FCM accepts at most 500 tokens per multicast
+	for start := 0; start < len(tokens); start += 500 {
+		end := start + 500
+		if end > len(tokens) {
+			end = len(tokens)
+		}
+		batch := tokens[start:end]
+
+		resp, err := p.client.SendEachForMulticast(ctx, &messaging.MulticastMessage{
+			Tokens:       batch,
+			Data:         payload,
+			Notification: &messaging.Notification{Title: title, Body: body},
+		})
+		if err != nil {
+			logger.Errorf("Failed to send %s push for client %s: %v", category, clientID, err)
+			continue
+		}
+
+		for i, r := range resp.Responses {
+			if r.Success {
+				continue
+			}
+			if messaging.IsUnregistered(r.Error) || messaging.IsInvalidArgument(r.Error) {
+				stale = append(stale, batch[i])
+			}
+		}
+		logger.Infof("Sent %s push for client %s: %d delivered, %d failed", category, clientID, resp.SuccessCount, resp.FailureCount)
+	}
+
+	if len(stale) > 0 {
+		if err := p.store.DeleteDeviceTokens(stale); err != nil {
+			logger.Errorf("Failed to remove stale device tokens: %v", err)
+		}
+	}
+}
+
+// FilingCompletedPush returns the push title and body for a completed filing
+func FilingCompletedPush(taxYear int) (title, body string) {
+	return "Your tax return is complete", fmt.Sprintf("Your %d tax return is ready for review.", taxYear)
+}
+
+// FilingStatusPush returns the push title and body for a filing that was filed or accepted
+func FilingStatusPush(taxYear int, status string) (title, body string) {
+	if status == types.FilingStatusAccepted {
+		return "Your tax return was accepted", fmt.Sprintf("The IRS accepted your %d tax return.", taxYear)
+	}
+	return "Your tax return was filed", fmt.Sprintf("Your %d tax return was filed with the IRS.", taxYear)
+}
@@ -0,0 +1,365 @@
+package notification
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/logger"
+)
+
+// Email priority classes
+const (
+	PriorityTransactional = "transactional" // Triggered by a person or event and expected promptly
+	PriorityBulk          = "bulk"          // Digests and blasts that can wait for quieter periods
+)
+
+// ErrQueueFull is returned when a priority class already holds QueueConfig.MaxDepth messages
+var ErrQueueFull = errors.New("email send queue is full")
+
+// QueueConfig limits how fast queued email is handed to SendGrid.
+// Zero values fall back to DefaultQueueConfig.
+type QueueConfig struct {
+	GlobalPerSecond    float64 `json:"globalPerSecond"`    // Sends per second across all tenants
+	TenantPerMinute    int     `json:"tenantPerMinute"`    // Sends per minute for any one tenant
+	MaxDepth           int     `json:"maxDepth"`           // Queued messages per priority class before new sends are rejected
+	BulkDeferPerMinute int     `json:"bulkDeferPerMinute"` // Transactional sends per minute at which bulk sends pause
+	Workers            int     `json:"workers"`            // Concurrent SendGrid requests
+}
+
+// DefaultQueueConfig is used for any value the configuration leaves out
+var DefaultQueueConfig = QueueConfig{
+	GlobalPerSecond:    10,
+	TenantPerMinute:    120,
+	MaxDepth:           5000,
+	BulkDeferPerMinute: 60,
+	Workers:            4,
+}
+
+// QueueStats is a point-in-time view of the send queue for monitoring
+type QueueStats struct {
+	Transactional     int            `json:"transactional"`     // Transactional messages waiting
+	Bulk              int            `json:"bulk"`              // Bulk messages waiting
+	InFlight          int            `json:"inFlight"`          // Messages being sent to SendGrid
+	BulkDeferred      bool           `json:"bulkDeferred"`      // Bulk sends are paused for transactional volume
+	OldestWaitSeconds float64        `json:"oldestWaitSeconds"` // Age of the oldest queued message
+	TenantDepth       map[string]int `json:"tenantDepth"`       // Queued messages per tenant ("" is platform mail)
+	RecentPerMinute   int            `json:"recentPerMinute"`   // Transactional messages queued in the last minute
+
+	// Totals since the process started
+	Sent     int64 `json:"sent"`
+	Failed   int64 `json:"failed"`
+	Rejected int64 `json:"rejected"`
+
+	Config QueueConfig `json:"config"`
+}
+
+// queuedEmail is a message waiting for its turn, with the channel its sender waits on
+type queuedEmail struct {
+	email    *Email
+	queuedAt time.Time
+	result   chan error
+}
+
+// tokenBucket allows rate sends per second with bursts of up to burst
+type tokenBucket struct {
+	rate    float64
+	burst   float64
+	tokens  float64
+	updated time.Time
+}
+
+func newTokenBucket(rate, burst float64, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, updated: now}
+}
+
+// refill adds the tokens earned since the last update
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.updated).Seconds()*b.rate)
+	b.updated = now
+}
+
+// wait is how long until a token is available; 0 means one is available now
+func (b *tokenBucket) wait() time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+}
+
+// sendQueue orders email by priority and releases it to workers within the global and
+// per-tenant rate limits. Bulk mail waits while transactional mail is queued or arriving
+// faster than BulkDeferPerMinute, so a blast never delays a password reset or alert.
+type sendQueue struct {
+	config QueueConfig
+	send   func(*Email) error
+
+	mu       sync.Mutex
+	queues   map[string][]*queuedEmail // by priority
+	global   *tokenBucket
+	tenants  map[string]*tokenBucket
+	recent   []time.Time // transactional enqueue times within the last minute
+	inFlight int
+	sent     int64
+	failed   int64
+	rejected int64
+	deferred bool
+
+	wake  chan struct{}
+	ready chan *queuedEmail
+}
+
+// newSendQueue starts a queue that delivers messages with send
+func newSendQueue(config QueueConfig, send func(*Email) error) *sendQueue {
+	if config.GlobalPerSecond <= 0 {
+		config.GlobalPerSecond = DefaultQueueConfig.GlobalPerSecond
+	}
+	if config.TenantPerMinute <= 0 {
+		config.TenantPerMinute = DefaultQueueConfig.TenantPerMinute
+	}
+	if config.MaxDepth <= 0 {
+		config.MaxDepth = DefaultQueueConfig.MaxDepth
+	}
+	if config.BulkDeferPerMinute <= 0 {
+		config.BulkDeferPerMinute = DefaultQueueConfig.BulkDeferPerMinute
+	}
+	if config.Workers <= 0 {
+		config.Workers = DefaultQueueConfig.Workers
+	}
+
+	q := &sendQueue{
+		config:  config,
+		send:    send,
+		queues:  map[string][]*queuedEmail{},
+		global:  newTokenBucket(config.GlobalPerSecond, math.Max(1, math.Ceil(config.GlobalPerSecond)), time.Now()),
+		tenants: map[string]*tokenBucket{},
+		wake:    make(chan struct{}, 1),
+		ready:   make(chan *queuedEmail),
+	}
+
+	go q.dispatch()
+	for i := 0; i < config.Workers; i++ {
+		go q.work()
+	}
+	return q
+}
+
+// enqueue adds a message and returns the channel that receives its send result
+func (q *sendQueue) enqueue(email *Email) (<-chan error, error) {
+	priority := email.Priority
+	if priority != PriorityBulk {
+		priority = PriorityTransactional
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.queues[priority]) >= q.config.MaxDepth {
+		q.rejected++
+		logger.Warningf("Email queue full: rejected %s message to %s (tenant %q, depth %d)", priority, email.To, email.TenantID, len(q.queues[priority]))
+		return nil, ErrQueueFull
+	}
+
+	now := time.Now()
+	item := &queuedEmail{email: email, queuedAt: now, result: make(chan error, 1)}
+	q.queues[priority] = append(q.queues[priority], item)
+	if priority == PriorityTransactional {
+		q.recent = append(q.recent, now)
+	}
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return item.result, nil
+}
+
+// dispatch hands each message to a worker as soon as the rate limits allow
+func (q *sendQueue) dispatch() {
+	pruned := time.Now()
+	for {
+		item, wait := q.next()
+		if item != nil {
+			q.ready <- item
+			continue
+		}
+
+		if time.Since(pruned) > time.Minute {
+			q.pruneTenants()
+			pruned = time.Now()
+		}
+
+		if wait > 0 {
+			timer := time.NewTimer(wait)
+			select {
+			case <-q.wake:
+			case <-timer.C:
+			}
+			timer.Stop()
+		} else {
+			<-q.wake
+		}
+	}
+}
+
+// next removes the next sendable message, or reports how long to wait before one may be
+// (0 when nothing is queued). Within a class messages go in order, except that a tenant
+// over its limit is skipped so it cannot hold up other tenants.
+func (q *sendQueue) next() (*queuedEmail, time.Duration) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	q.global.refill(now)
+	q.trimRecent(now)
+
+	deferred := q.bulkDeferred()
+	if deferred != q.deferred {
+		q.deferred = deferred
+		if deferred {
+			logger.Infof("Deferring %d bulk emails for transactional volume (%d queued, %d in the last minute)", len(q.queues[PriorityBulk]), len(q.queues[PriorityTransactional]), len(q.recent))
+		} else {
+			logger.Infof("Resuming bulk email sends (%d queued)", len(q.queues[PriorityBulk]))
+		}
+	}
+
+	var wait time.Duration
+	for _, priority := range []string{PriorityTransactional, PriorityBulk} {
+		if priority == PriorityBulk && deferred {
+			// Recheck once enough recent transactional sends age out of the window
+			if len(q.queues[PriorityTransactional]) == 0 && len(q.recent) >= q.config.BulkDeferPerMinute {
+				oldest := q.recent[len(q.recent)-q.config.BulkDeferPerMinute]
+				wait = minWait(wait, oldest.Add(time.Minute).Sub(now))
+			}
+			continue
+		}
+
+		items := q.queues[priority]
+		if len(items) == 0 {
+			continue
+		}
+		if w := q.global.wait(); w > 0 {
+			return nil, minWait(wait, w)
+		}
+
+		for i, item := range items {
+			bucket := q.tenantBucket(item.email.TenantID, now)
+			if bucket != nil {
+				if w := bucket.wait(); w > 0 {
+					wait = minWait(wait, w)
+					continue
+				}
+				bucket.tokens--
+			}
+			q.global.tokens--
+			q.queues[priority] = append(items[:i:i], items[i+1:]...)
+			q.inFlight++
+			return item, 0
+		}
+	}
+	return nil, wait
+}
+
+// bulkDeferred reports whether queued bulk mail must wait for transactional volume to drop
+func (q *sendQueue) bulkDeferred() bool {
+	return len(q.queues[PriorityBulk]) > 0 &&
+		(len(q.queues[PriorityTransactional]) > 0 || len(q.recent) >= q.config.BulkDeferPerMinute)
+}
+
+// tenantBucket returns the refilled bucket for a tenant; platform mail has none
+func (q *sendQueue) tenantBucket(tenantID string, now time.Time) *tokenBucket {
+	if tenantID == "" {
+		return nil
+	}
+	bucket, ok := q.tenants[tenantID]
+	if !ok {
+		rate := float64(q.config.TenantPerMinute) / 60
+		bucket = newTokenBucket(rate, math.Max(1, math.Ceil(float64(q.config.TenantPerMinute)/6)), now)
+		q.tenants[tenantID] = bucket
+	}
+	bucket.refill(now)
+	return bucket
+}
+
+// pruneTenants drops idle tenant buckets that have refilled completely
+func (q *sendQueue) pruneTenants() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	for tenantID, bucket := range q.tenants {
+		bucket.refill(now)
+		if bucket.tokens >= bucket.burst {
+			delete(q.tenants, tenantID)
+		}
+	}
+}
+
+// trimRecent drops transactional enqueue times older than a minute
+func (q *sendQueue) trimRecent(now time.Time) {
+	cutoff := now.Add(-time.Minute)
+	i := sort.Search(len(q.recent), func(i int) bool { return q.recent[i].After(cutoff) })
+	q.recent = q.recent[i:]
+}
+
+// work sends messages released by dispatch
+func (q *sendQueue) work() {
+	for item := range q.ready {
+		err := q.send(item.email)
+
+		q.mu.Lock()
+		q.inFlight--
+		if err != nil {
+			q.failed++
+		} else {
+			q.sent++
+		}
+		q.mu.Unlock()
+
+		item.result <- err
+	}
+}
+
+// stats reports the queue's depth and counters
+func (q *sendQueue) stats() *QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	now := time.Now()
+	q.trimRecent(now)
+
+	stats := &QueueStats{
+		Transactional:   len(q.queues[PriorityTransactional]),
+		Bulk:            len(q.queues[PriorityBulk]),
+		InFlight:        q.inFlight,
+		BulkDeferred:    q.bulkDeferred(),
+		TenantDepth:     map[string]int{},
+		RecentPerMinute: len(q.recent),
+		Sent:            q.sent,
+		Failed:          q.failed,
+		Rejected:        q.rejected,
+		Config:          q.config,
+	}
+	for _, items := range q.queues {
+		for _, item := range items {
+			stats.TenantDepth[item.email.TenantID]++
+			if wait := now.Sub(item.queuedAt).Seconds(); wait > stats.OldestWaitSeconds {
+				stats.OldestWaitSeconds = wait
+			}
+		}
+	}
+	return stats
+}
+
+// minWait returns the shorter of two waits, treating 0 as no wait yet
+func minWait(current, candidate time.Duration) time.Duration {
+	if candidate <= 0 {
+		candidate = time.Millisecond
+	}
+	if current == 0 || candidate < current {
+		return candidate
+	}
+	return current
+}
@@ -0,0 +1,72 @@
+package notification
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/logger"
+)
+
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// SlackService sends staff notifications as Slack direct messages from a bot
+type SlackService struct {
+	botToken string
+	client   *http.Client
+}
+
+// NewSlackService creates the Slack service; it returns nil, which sends nothing, without a bot token
+func NewSlackService(botToken string) *SlackService {
+	if botToken == "" {
+		return nil
+	}
+	return &SlackService{
+		botToken: botToken,
+		client:   &http.Client{Timeout: 15 * time.Second},
+	}
+}
+
+// SendDirectMessage messages a Slack member by ID. Slack opens the bot's direct message channel
+// with the member when the channel is a user ID.
+func (s *SlackService) SendDirectMessage(ctx context.Context, slackUserID, text string) error {
+	payload, err := json.Marshal(map[string]string{"channel": slackUserID, "text": text})
+	if err != nil {
+		return fmt.Errorf("failed to encode Slack message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, slackPostMessageURL, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build Slack request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+s.botToken)
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		logger.Errorf("Slack request failed: %v", err)
+		return fmt.Errorf("slack request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	// Slack answers API errors with 200 and ok=false
+	var result struct {
+		OK    bool   `json:"ok"`
+		Error string `json:"error"`
+	}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack returned status %d", resp.StatusCode)
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("failed to decode Slack response: %w", err)
+	}
+	if !result.OK {
+		return fmt.Errorf("slack rejected the message: %s", result.Error)
+	}
+	return nil
+}
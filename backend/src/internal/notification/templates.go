@@ -2,7 +2,9 @@ package notification
 
 import (
 	"fmt"
+	"html"
 	"strings"
+	"welltaxpro/src/internal/types"
 )
 
 // FilingCompletedEmail generates the email content for when a filing is completed
@@ -12,6 +14,7 @@ type FilingCompletedEmail struct {
 	FilingType  string
 	TenantName  string
 	LoginURL    string
+	Deceased    bool // Taxpayer is deceased; address the personal representative
 }
 
 // PortalAccessEmail generates the email content for portal magic link
@@ -25,6 +28,16 @@ type PortalAccessEmail struct {
 func GenerateFilingCompletedEmail(data FilingCompletedEmail) (subject, htmlBody, textBody string) {
 	subject = fmt.Sprintf("Your %d Tax Return is Complete", data.TaxYear)
 
+	greeting := data.ClientName
+	intro := fmt.Sprintf("Great news! Your <strong>%d %s</strong> tax return has been completed and is ready for your review.", data.TaxYear, data.FilingType)
+	introText := fmt.Sprintf("Great news! Your %d %s tax return has been completed and is ready for your review.", data.TaxYear, data.FilingType)
+	if data.Deceased {
+		subject = fmt.Sprintf("Final %d Tax Return for %s is Complete", data.TaxYear, data.ClientName)
+		greeting = fmt.Sprintf("Personal Representative of %s", data.ClientName)
+		intro = fmt.Sprintf("The final <strong>%d %s</strong> tax return for %s has been completed and is ready for your review. Please accept our condolences.", data.TaxYear, data.FilingType, data.ClientName)
+		introText = fmt.Sprintf("The final %d %s tax return for %s has been completed and is ready for your review. Please accept our condolences.", data.TaxYear, data.FilingType, data.ClientName)
+	}
+
 	// HTML version
 	htmlBody = fmt.Sprintf(`
 <!DOCTYPE html>
@@ -54,7 +67,7 @@ func GenerateFilingCompletedEmail(data FilingCompletedEmail) (subject, htmlBody,
                             </p>
 
                             <p style="margin: 0 0 20px 0; font-size: 16px; line-height: 24px; color: #333333;">
-                                Great news! Your <strong>%d %s</strong> tax return has been completed and is ready for your review.
+                                %s
                             </p>
 
                             <p style="margin: 0 0 20px 0; font-size: 16px; line-height: 24px; color: #333333;">
@@ -94,13 +107,13 @@ func GenerateFilingCompletedEmail(data FilingCompletedEmail) (subject, htmlBody,
     </table>
 </body>
 </html>
-`, subject, data.ClientName, data.TaxYear, data.FilingType, data.LoginURL, data.TenantName)
+`, subject, greeting, intro, data.LoginURL, data.TenantName)
 
 	// Text version (for email clients that don't support HTML)
 	textBody = fmt.Sprintf(`
 Dear %s,
 
-Great news! Your %d %s tax return has been completed and is ready for your review.
+%s
 
 You can access your tax documents and review all details by logging into your account:
 %s
@@ -112,7 +125,7 @@ Best regards,
 
 ---
 This is an automated message. Please do not reply to this email.
-`, data.ClientName, data.TaxYear, data.FilingType, data.LoginURL, data.TenantName)
+`, greeting, introText, data.LoginURL, data.TenantName)
 
 	// Clean up whitespace
 	htmlBody = strings.TrimSpace(htmlBody)
@@ -231,3 +244,395 @@ This is an automated message. Please do not reply to this email.
 
 	return subject, htmlBody, textBody
 }
+
+// GeneratePortalAccessText creates the text message carrying a portal magic link
+func GeneratePortalAccessText(data PortalAccessEmail) string {
+	return fmt.Sprintf("%s: Hi %s, sign in to your secure tax documents portal: %s\nThis link expires soon. Reply STOP to opt out.",
+		data.TenantName, data.ClientName, data.PortalURL)
+}
+
+// AlertEmail generates the email content for a single staff alert
+type AlertEmail struct {
+	RecipientName string
+	Subject       string
+	Body          string
+}
+
+// DigestEmail generates the email content for a staff member's daily digest
+type DigestEmail struct {
+	RecipientName string
+	Events        []*types.NotificationEvent
+}
+
+// GenerateAlertEmail creates HTML and text versions of an immediate staff alert
+func GenerateAlertEmail(data AlertEmail) (subject, htmlBody, textBody string) {
+	subject = data.Subject
+
+	htmlBody = fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+</head>
+<body style="margin: 0; padding: 20px; font-family: Arial, sans-serif; color: #333333;">
+    <p style="font-size: 16px;">Hi %s,</p>
+    <p style="font-size: 16px; line-height: 24px;">%s</p>
+    <p style="font-size: 12px; color: #999999;">You can change how you receive these alerts in your notification preferences.</p>
+</body>
+</html>
+`, html.EscapeString(subject), html.EscapeString(data.RecipientName), html.EscapeString(data.Body))
+
+	textBody = fmt.Sprintf(`
+Hi %s,
+
+%s
+
+---
+You can change how you receive these alerts in your notification preferences.
+`, data.RecipientName, data.Body)
+
+	htmlBody = strings.TrimSpace(htmlBody)
+	textBody = strings.TrimSpace(textBody)
+
+	return subject, htmlBody, textBody
+}
+
+// GenerateDigestEmail creates HTML and text versions of the daily digest, grouping events by category
+func GenerateDigestEmail(data DigestEmail) (subject, htmlBody, textBody string) {
+	subject = fmt.Sprintf("Daily digest: %d new notifications", len(data.Events))
+
+	byCategory := map[string][]*types.NotificationEvent{}
+	for _, e := range data.Events {
+		byCategory[e.Category] = append(byCategory[e.Category], e)
+	}
+
+	var htmlSections, textSections strings.Builder
+	for _, category := range types.NotificationCategories {
+		events := byCategory[category]
+		if len(events) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&htmlSections, `<h3 style="margin: 20px 0 10px 0; font-size: 16px;">%s (%d)</h3><ul>`, category, len(events))
+		fmt.Fprintf(&textSections, "%s (%d)\n", category, len(events))
+		for _, e := range events {
+			fmt.Fprintf(&htmlSections, `<li style="margin-bottom: 8px;"><strong>%s</strong><br>%s</li>`,
+				html.EscapeString(e.Subject), html.EscapeString(e.Body))
+			fmt.Fprintf(&textSections, "- %s: %s\n", e.Subject, e.Body)
+		}
+		htmlSections.WriteString("</ul>")
+		textSections.WriteString("\n")
+	}
+
+	htmlBody = fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+</head>
+<body style="margin: 0; padding: 20px; font-family: Arial, sans-serif; color: #333333;">
+    <p style="font-size: 16px;">Hi %s,</p>
+    <p style="font-size: 16px;">Here is what happened since your last digest:</p>
+    %s
+    <p style="font-size: 12px; color: #999999;">You can change how you receive these alerts in your notification preferences.</p>
+</body>
+</html>
+`, html.EscapeString(subject), html.EscapeString(data.RecipientName), htmlSections.String())
+
+	textBody = fmt.Sprintf(`
+Hi %s,
+
+Here is what happened since your last digest:
+
+%s
+---
+You can change how you receive these alerts in your notification preferences.
+`, data.RecipientName, textSections.String())
+
+	htmlBody = strings.TrimSpace(htmlBody)
+	textBody = strings.TrimSpace(textBody)
+
+	return subject, htmlBody, textBody
+}
+
+// InboundAckEmail generates the acknowledgment sent to a client who emailed documents to the firm
+type InboundAckEmail struct {
+	ClientName string
+	TenantName string
+	Files      []string
+}
+
+// GenerateInboundAckEmail creates HTML and text versions of an emailed-documents acknowledgment
+func GenerateInboundAckEmail(data InboundAckEmail) (subject, htmlBody, textBody string) {
+	subject = fmt.Sprintf("%s received your documents", data.TenantName)
+
+	var htmlFiles, textFiles strings.Builder
+	for _, f := range data.Files {
+		fmt.Fprintf(&htmlFiles, `<li style="margin-bottom: 4px;">%s</li>`, html.EscapeString(f))
+		fmt.Fprintf(&textFiles, "- %s\n", f)
+	}
+
+	htmlBody = fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+</head>
+<body style="margin: 0; padding: 20px; font-family: Arial, sans-serif; color: #333333;">
+    <p style="font-size: 16px;">Hi %s,</p>
+    <p style="font-size: 16px; line-height: 24px;">Thank you for sending your documents to %s. We received:</p>
+    <ul>%s</ul>
+    <p style="font-size: 16px; line-height: 24px;">Our team will review them and add them to your file. You do not need to send them again.</p>
+    <p style="font-size: 12px; color: #999999;">This is an automated message.</p>
+</body>
+</html>
+`, html.EscapeString(subject), html.EscapeString(data.ClientName), html.EscapeString(data.TenantName), htmlFiles.String())
+
+	textBody = fmt.Sprintf(`
+Hi %s,
+
+Thank you for sending your documents to %s. We received:
+
+%s
+Our team will review them and add them to your file. You do not need to send them again.
+
+---
+This is an automated message.
+`, data.ClientName, data.TenantName, textFiles.String())
+
+	htmlBody = strings.TrimSpace(htmlBody)
+	textBody = strings.TrimSpace(textBody)
+
+	return subject, htmlBody, textBody
+}
+
+// DocumentRequestEmail generates the reminder sent to a client with open document requests
+type DocumentRequestEmail struct {
+	ClientName string
+	TenantName string
+	Requests   []string // Request descriptions
+}
+
+// GenerateDocumentRequestEmail creates HTML and text versions of a document request reminder
+func GenerateDocumentRequestEmail(data DocumentRequestEmail) (subject, htmlBody, textBody string) {
+	subject = fmt.Sprintf("%s needs documents from you", data.TenantName)
+
+	var htmlRequests, textRequests strings.Builder
+	for _, r := range data.Requests {
+		fmt.Fprintf(&htmlRequests, `<li style="margin-bottom: 4px;">%s</li>`, html.EscapeString(r))
+		fmt.Fprintf(&textRequests, "- %s\n", r)
+	}
+
+	htmlBody = fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+</head>
+<body style="margin: 0; padding: 20px; font-family: Arial, sans-serif; color: #333333;">
+    <p style="font-size: 16px;">Hi %s,</p>
+    <p style="font-size: 16px; line-height: 24px;">%s is waiting for the following documents:</p>
+    <ul>%s</ul>
+    <p style="font-size: 16px; line-height: 24px;">Please upload them through your client portal. Documents you have already uploaded are checked off automatically.</p>
+    <p style="font-size: 12px; color: #999999;">This is an automated message.</p>
+</body>
+</html>
+`, html.EscapeString(subject), html.EscapeString(data.ClientName), html.EscapeString(data.TenantName), htmlRequests.String())
+
+	textBody = fmt.Sprintf(`
+Hi %s,
+
+%s is waiting for the following documents:
+
+%s
+Please upload them through your client portal. Documents you have already uploaded are checked off automatically.
+
+---
+This is an automated message.
+`, data.ClientName, data.TenantName, textRequests.String())
+
+	htmlBody = strings.TrimSpace(htmlBody)
+	textBody = strings.TrimSpace(textBody)
+
+	return subject, htmlBody, textBody
+}
+
+// DocumentRequestPush returns the push title and body for open document requests
+func DocumentRequestPush(count int) (title, body string) {
+	if count == 1 {
+		return "A document is needed", "Your tax preparer is waiting for a document. Open the portal to upload it."
+	}
+	return "Documents are needed", fmt.Sprintf("Your tax preparer is waiting for %d documents. Open the portal to upload them.", count)
+}
+
+// FilingStatusEmail generates the email telling a client their return was filed or accepted
+type FilingStatusEmail struct {
+	ClientName string
+	TenantName string
+	TaxYear    int
+	Status     string // FILED or ACCEPTED
+	LoginURL   string
+}
+
+// GenerateFilingStatusEmail creates HTML and text versions of a filing status update
+func GenerateFilingStatusEmail(data FilingStatusEmail) (subject, htmlBody, textBody string) {
+	subject = fmt.Sprintf("Your %d tax return was filed", data.TaxYear)
+	message := fmt.Sprintf("%s filed your %d tax return with the IRS. We will let you know when it is accepted.", data.TenantName, data.TaxYear)
+	if data.Status == types.FilingStatusAccepted {
+		subject = fmt.Sprintf("Your %d tax return was accepted", data.TaxYear)
+		message = fmt.Sprintf("The IRS accepted your %d tax return, filed by %s. There is nothing more you need to do.", data.TaxYear, data.TenantName)
+	}
+
+	htmlBody = fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+</head>
+<body style="margin: 0; padding: 20px; font-family: Arial, sans-serif; color: #333333;">
+    <p style="font-size: 16px;">Hi %s,</p>
+    <p style="font-size: 16px; line-height: 24px;">%s</p>
+    <p style="font-size: 16px; line-height: 24px;">You can see your return and its documents in your <a href="%s">client portal</a>.</p>
+    <p style="font-size: 12px; color: #999999;">This is an automated message.</p>
+</body>
+</html>
+`, html.EscapeString(subject), html.EscapeString(data.ClientName), html.EscapeString(message), html.EscapeString(data.LoginURL))
+
+	textBody = fmt.Sprintf(`
+Hi %s,
+
+%s
+
+You can see your return and its documents in your client portal:
+%s
+
+---
+This is an automated message.
+`, data.ClientName, message, data.LoginURL)
+
+	htmlBody = strings.TrimSpace(htmlBody)
+	textBody = strings.TrimSpace(textBody)
+
+	return subject, htmlBody, textBody
+}
+
+// AffiliateCommissionEmail generates the email telling an affiliate about changes to their commissions
+type AffiliateCommissionEmail struct {
+	AffiliateName string
+	TenantName    string
+	Events        []*types.AffiliateNotification // Oldest first
+}
+
+// affiliateEventLabels describe affiliate notification events in emails
+var affiliateEventLabels = map[string]string{
+	types.AffiliateEventCommissionCreated:   "New commission",
+	types.AffiliateEventCommissionApproved:  "Approved",
+	types.AffiliateEventCommissionPaid:      "Paid",
+	types.AffiliateEventCommissionCancelled: "Cancelled",
+}
+
+// GenerateAffiliateCommissionEmail creates HTML and text versions of an affiliate's commission
+// update, listing every event of the batch in one email
+func GenerateAffiliateCommissionEmail(data AffiliateCommissionEmail) (subject, htmlBody, textBody string) {
+	if len(data.Events) == 1 {
+		e := data.Events[0]
+		subject = fmt.Sprintf("%s: $%s commission from %s", affiliateEventLabels[e.Event], e.CommissionAmount, data.TenantName)
+	} else {
+		subject = fmt.Sprintf("%d commission updates from %s", len(data.Events), data.TenantName)
+	}
+
+	var htmlEvents, textEvents strings.Builder
+	for _, e := range data.Events {
+		date := e.CreatedAt.UTC().Format("Jan 2, 2006")
+		fmt.Fprintf(&htmlEvents, `<tr><td style="padding: 4px 12px 4px 0;">%s</td><td style="padding: 4px 12px 4px 0;">%s</td><td style="padding: 4px 0; text-align: right;">$%s</td></tr>`,
+			html.EscapeString(date), html.EscapeString(affiliateEventLabels[e.Event]), e.CommissionAmount)
+		fmt.Fprintf(&textEvents, "- %s  %s  $%s\n", date, affiliateEventLabels[e.Event], e.CommissionAmount)
+	}
+
+	htmlBody = fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+</head>
+<body style="margin: 0; padding: 20px; font-family: Arial, sans-serif; color: #333333;">
+    <p style="font-size: 16px;">Hi %s,</p>
+    <p style="font-size: 16px; line-height: 24px;">Here is what changed with your %s commissions:</p>
+    <table style="font-size: 14px; border-collapse: collapse;">%s</table>
+    <p style="font-size: 16px; line-height: 24px;">Your affiliate dashboard has the details and your current balance.</p>
+    <p style="font-size: 12px; color: #999999;">You can choose which commission updates are emailed to you in your affiliate dashboard.</p>
+</body>
+</html>
+`, html.EscapeString(subject), html.EscapeString(data.AffiliateName), html.EscapeString(data.TenantName), htmlEvents.String())
+
+	textBody = fmt.Sprintf(`
+Hi %s,
+
+Here is what changed with your %s commissions:
+
+%s
+Your affiliate dashboard has the details and your current balance.
+
+---
+You can choose which commission updates are emailed to you in your affiliate dashboard.
+`, data.AffiliateName, data.TenantName, textEvents.String())
+
+	htmlBody = strings.TrimSpace(htmlBody)
+	textBody = strings.TrimSpace(textBody)
+
+	return subject, htmlBody, textBody
+}
+
+// PortalVerificationEmail generates the one-time code a client enters to verify a portal sign-in
+type PortalVerificationEmail struct {
+	ClientName string
+	TenantName string
+	Code       string
+	Minutes    int // How long the code can be used
+}
+
+// GeneratePortalVerificationEmail creates HTML and text versions of a portal verification code email
+func GeneratePortalVerificationEmail(data PortalVerificationEmail) (subject, htmlBody, textBody string) {
+	subject = fmt.Sprintf("Your %s portal verification code", data.TenantName)
+
+	htmlBody = fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <title>%s</title>
+</head>
+<body style="margin: 0; padding: 20px; font-family: Arial, sans-serif; color: #333333;">
+    <p style="font-size: 16px;">Hi %s,</p>
+    <p style="font-size: 16px; line-height: 24px;">Enter this code to finish signing in to the %s client portal:</p>
+    <p style="font-size: 28px; font-weight: bold; letter-spacing: 6px;">%s</p>
+    <p style="font-size: 16px; line-height: 24px;">The code expires in %d minutes. If you did not try to sign in, you can ignore this email.</p>
+    <p style="font-size: 12px; color: #999999;">This is an automated message.</p>
+</body>
+</html>
+`, html.EscapeString(subject), html.EscapeString(data.ClientName), html.EscapeString(data.TenantName), html.EscapeString(data.Code), data.Minutes)
+
+	textBody = fmt.Sprintf(`
+Hi %s,
+
+Enter this code to finish signing in to the %s client portal:
+
+%s
+
+The code expires in %d minutes. If you did not try to sign in, you can ignore this email.
+
+---
+This is an automated message.
+`, data.ClientName, data.TenantName, data.Code, data.Minutes)
+
+	htmlBody = strings.TrimSpace(htmlBody)
+	textBody = strings.TrimSpace(textBody)
+
+	return subject, htmlBody, textBody
+}
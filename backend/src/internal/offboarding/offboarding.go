@@ -0,0 +1,254 @@
+package offboarding
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/secrets"
+	"welltaxpro/src/internal/storage"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	// exportPrefix is where data exports are written in the tenant bucket
+	exportPrefix = "offboarding"
+	// DefaultRetentionDays is how long the tenant bucket is kept when set_retention gives no period
+	DefaultRetentionDays = 90
+	// MaxRetentionDays bounds the retention period
+	MaxRetentionDays = 3650
+	// maxExportConfigChanges bounds the configuration history copied into an export
+	maxExportConfigChanges = 100000
+)
+
+// Store is the part of the store offboarding uses; *store.Store implements it
+type Store interface {
+	BlockTenantPortal(tenantID string, performedBy uuid.UUID) (*types.TenantOffboarding, error)
+	ChangeTenantConfig(tenantID, action string, employeeID *uuid.UUID, change func(tx *sql.Tx) error) error
+	CompleteTenantOffboarding(tenantID string, performedBy uuid.UUID) (*types.TenantOffboarding, error)
+	EvictTenantConnection(tenantID string) bool
+	ForEachTenantAuditLog(tenantID string, fn func(*types.AuditLog) error) error
+	GetDueOffboardingExports() ([]*types.TenantOffboarding, error)
+	GetOffboardingsPastRetention() ([]*types.TenantOffboarding, error)
+	GetTenantConfig(tenantID string) (*types.TenantConnection, error)
+	GetTenantConfigHistory(tenantID string, limit int) ([]*types.TenantConfigHistoryEntry, error)
+	GetTenantOffboarding(tenantID string) (*types.TenantOffboarding, error)
+	MarkOffboardingRetentionAlerted(id uuid.UUID) error
+	MarkTenantCachesPurged(tenantID string, performedBy uuid.UUID, details interface{}) (*types.TenantOffboarding, error)
+	RecordOffboardingExport(id uuid.UUID, exportPath string, exportErr error) (firstFailure bool, err error)
+	RevokeTenantAccess(tenantID string, performedBy uuid.UUID) (*types.TenantOffboarding, error)
+	ScheduleTenantExport(tenantID string, performedBy uuid.UUID) (*types.TenantOffboarding, error)
+	SetTenantRetention(tenantID string, performedBy uuid.UUID, retentionUntil time.Time) (*types.TenantOffboarding, error)
+}
+
+// Offboarder runs tenant offboarding steps and writes the data exports they schedule
+type Offboarder struct {
+	store Store
+}
+
+// New creates an offboarder
+func New(s Store) *Offboarder {
+	return &Offboarder{store: s}
+}
+
+// Options are inputs taken by some steps
+type Options struct {
+	RetentionDays int // set_retention; 0 uses DefaultRetentionDays
+}
+
+// Advance runs step of the tenant's offboarding in progress for employeeID. Steps must be run
+// in order; running any other step is a conflict.
+func (o *Offboarder) Advance(ctx context.Context, tenantID, step string, employeeID uuid.UUID, options Options) (*types.TenantOffboarding, error) {
+	switch step {
+	case types.OffboardingStepBlockPortal:
+		return o.store.BlockTenantPortal(tenantID, employeeID)
+	case types.OffboardingStepScheduleExport:
+		return o.store.ScheduleTenantExport(tenantID, employeeID)
+	case types.OffboardingStepRevokeAccess:
+		return o.store.RevokeTenantAccess(tenantID, employeeID)
+	case types.OffboardingStepPurgeCaches:
+		return o.purgeCaches(ctx, tenantID, employeeID)
+	case types.OffboardingStepSetRetention:
+		days := options.RetentionDays
+		if days == 0 {
+			days = DefaultRetentionDays
+		}
+		if days < 1 || days > MaxRetentionDays {
+			return nil, apperr.Validation("retentionDays must be between 1 and %d", MaxRetentionDays)
+		}
+		return o.store.SetTenantRetention(tenantID, employeeID, time.Now().UTC().AddDate(0, 0, days))
+	case types.OffboardingStepComplete:
+		return o.store.CompleteTenantOffboarding(tenantID, employeeID)
+	}
+	return nil, apperr.Validation("unknown offboarding step: %s", step)
+}
+
+// purgeCaches deactivates the tenant, then drops this process's pooled connection and cached
+// secrets for it. Other processes drop their pooled connection once idle, and cannot open a new
+// one or read the tenant's secret paths once it is inactive.
+func (o *Offboarder) purgeCaches(ctx context.Context, tenantID string, employeeID uuid.UUID) (*types.TenantOffboarding, error) {
+	current, err := o.store.GetTenantOffboarding(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if current.NextStep == nil || *current.NextStep != types.OffboardingStepPurgeCaches {
+		return nil, apperr.Conflict("offboarding of tenant %s is %s; the next step is not %s", tenantID, current.Status, types.OffboardingStepPurgeCaches)
+	}
+	if current.ExportCompletedAt == nil {
+		return nil, apperr.Conflict("the data export of tenant %s has not been written yet", tenantID)
+	}
+
+	// Inactive tenants have no readable config, e.g. when an earlier attempt already deactivated it
+	tc, err := o.store.GetTenantConfig(tenantID)
+	if err != nil && !errors.Is(err, apperr.ErrNotFound) {
+		return nil, err
+	}
+
+	err = o.store.ChangeTenantConfig(tenantID, types.TenantConfigActionDeactivate, &employeeID, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`UPDATE tenant_connections SET is_active = false, updated_at = NOW() WHERE tenant_id = $1`, tenantID)
+		return err
+	})
+	if err != nil {
+		logger.Errorf("Failed to deactivate tenant %s for offboarding: %v", tenantID, err)
+		return nil, err
+	}
+
+	details := map[string]interface{}{
+		"tenantDeactivated": true,
+		"connectionEvicted": o.store.EvictTenantConnection(tenantID),
+		"secretsCleared":    clearSecrets(ctx, tc),
+	}
+	return o.store.MarkTenantCachesPurged(tenantID, employeeID, details)
+}
+
+// clearSecrets drops the tenant's secrets from the Secret Manager cache and returns how many
+// paths were cleared. Without the tenant's config the whole cache is cleared.
+func clearSecrets(ctx context.Context, tc *types.TenantConnection) int {
+	manager, err := secrets.GetSecretManager(ctx)
+	if err != nil {
+		// Nothing can have been cached without a working Secret Manager
+		logger.Infof("Secret Manager unavailable, no cached secrets to clear: %v", err)
+		return 0
+	}
+	if tc == nil {
+		manager.ClearAllCache()
+		return 0
+	}
+
+	cleared := 0
+	for _, secretPath := range []string{
+		tc.StorageCredentialsSecret,
+		tc.StorageUploadCredentialsSecret,
+		tc.StorageReadCredentialsSecret,
+		tc.StorageDeleteCredentialsSecret,
+		tc.DocuSignPrivateKeySecret,
+	} {
+		if secretPath != "" {
+			manager.ClearCache(secretPath)
+			cleared++
+		}
+	}
+	return cleared
+}
+
+// ExportResult is the outcome of one scheduled data export
+type ExportResult struct {
+	Offboarding  *types.TenantOffboarding
+	Path         string
+	Err          error
+	FirstFailure bool // Err is the first failure since the export was scheduled or last succeeded
+}
+
+// RunExports writes every scheduled data export that has not been written yet. Failed exports
+// are retried on the next run.
+func (o *Offboarder) RunExports(ctx context.Context) ([]*ExportResult, error) {
+	due, err := o.store.GetDueOffboardingExports()
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*ExportResult, 0, len(due))
+	for _, ob := range due {
+		result := &ExportResult{Offboarding: ob}
+		result.Path, result.Err = o.export(ctx, ob)
+		if result.Err != nil {
+			logger.Errorf("Data export of tenant %s failed: %v", ob.TenantID, result.Err)
+		} else {
+			logger.Infof("Wrote data export of tenant %s to %s", ob.TenantID, result.Path)
+		}
+
+		result.FirstFailure, err = o.store.RecordOffboardingExport(ob.ID, result.Path, result.Err)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// exportLine is one record of a data export, written as a line of JSON
+type exportLine struct {
+	Kind string      `json:"kind"` // tenant, config_change or audit_log
+	Data interface{} `json:"data"`
+}
+
+// export writes the platform's records of the tenant (its settings without secrets, its
+// configuration history and its audit log) to the tenant bucket as JSON lines
+func (o *Offboarder) export(ctx context.Context, ob *types.TenantOffboarding) (string, error) {
+	tc, err := o.store.GetTenantConfig(ob.TenantID)
+	if err != nil {
+		return "", err
+	}
+	history, err := o.store.GetTenantConfigHistory(ob.TenantID, maxExportConfigChanges)
+	if err != nil {
+		return "", err
+	}
+	provider, err := storage.NewStorageProviderForTenant(ctx, tc)
+	if err != nil {
+		return "", fmt.Errorf("failed to create storage provider: %w", err)
+	}
+
+	// Audit logs are streamed from the database into the upload rather than held in memory
+	reader, writer := io.Pipe()
+	go func() {
+		encoder := json.NewEncoder(writer)
+		err := encoder.Encode(exportLine{Kind: "tenant", Data: tc})
+		for i := len(history) - 1; i >= 0 && err == nil; i-- {
+			err = encoder.Encode(exportLine{Kind: "config_change", Data: history[i]})
+		}
+		if err == nil {
+			err = o.store.ForEachTenantAuditLog(ob.TenantID, func(l *types.AuditLog) error {
+				return encoder.Encode(exportLine{Kind: "audit_log", Data: l})
+			})
+		}
+		writer.CloseWithError(err)
+	}()
+
+	objectPath := path.Join(exportPrefix, ob.ID.String(), "export.jsonl")
+	metadata := map[string]string{"offboarding_id": ob.ID.String(), "tenant_id": ob.TenantID}
+	err = provider.Upload(ctx, tc.StorageBucket, objectPath, reader, metadata)
+	reader.CloseWithError(err)
+	if err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", objectPath, err)
+	}
+	return objectPath, nil
+}
+
+// PastRetention lists offboardings whose bucket retention period has ended and not been
+// reported; call MarkRetentionAlerted once admins are told
+func (o *Offboarder) PastRetention() ([]*types.TenantOffboarding, error) {
+	return o.store.GetOffboardingsPastRetention()
+}
+
+// MarkRetentionAlerted records that admins were told an offboarding's retention period ended
+func (o *Offboarder) MarkRetentionAlerted(ob *types.TenantOffboarding) error {
+	return o.store.MarkOffboardingRetentionAlerted(ob.ID)
+}
@@ -0,0 +1,81 @@
+package payout
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/logger"
+)
+
+// Provider defines the interface for services that move money to affiliates
+type Provider interface {
+	// Transfer sends amountCents to a connected account
+	Transfer(ctx context.Context, transfer *Transfer) (*Receipt, error)
+
+	// Name returns the provider identifier
+	Name() string
+}
+
+// Config selects and configures the payout provider
+type Config struct {
+	Provider  string // "stripe" or empty to disable transfers
+	SecretKey string
+}
+
+// ProviderNone is the name of the provider used when transfers are not configured
+const ProviderNone = "none"
+
+// ErrDisabled is returned by the disabled provider
+var ErrDisabled = errors.New("payout transfers are not configured")
+
+// DeclinedError is a transfer the provider refused. Unlike other errors, the transfer is known
+// not to have happened, so it may be retried under a new idempotency key.
+type DeclinedError struct {
+	Message string
+}
+
+func (e *DeclinedError) Error() string {
+	return "transfer declined: " + e.Message
+}
+
+// Transfer is one payment to a connected account
+type Transfer struct {
+	Destination    string // Connected account ID
+	AmountCents    int64
+	Description    string
+	Metadata       map[string]string
+	IdempotencyKey string // Resubmitting with the same key does not pay twice
+}
+
+// Receipt is the provider's acceptance of a transfer
+type Receipt struct {
+	TransferID string
+}
+
+// NewProvider creates the configured payout provider
+// Falls back to a disabled provider when none is configured
+func NewProvider(cfg Config) Provider {
+	switch cfg.Provider {
+	case "stripe":
+		if cfg.SecretKey == "" {
+			logger.Warning("Stripe payouts configured without a secret key, disabling transfers")
+			return &DisabledProvider{}
+		}
+		return NewStripeProvider(cfg.SecretKey)
+	default:
+		return &DisabledProvider{}
+	}
+}
+
+// DisabledProvider rejects every transfer
+type DisabledProvider struct{}
+
+// Name returns the provider identifier
+func (p *DisabledProvider) Name() string {
+	return ProviderNone
+}
+
+// Transfer rejects the transfer
+func (p *DisabledProvider) Transfer(ctx context.Context, transfer *Transfer) (*Receipt, error) {
+	return nil, ErrDisabled
+}
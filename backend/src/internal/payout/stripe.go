@@ -0,0 +1,98 @@
+package payout
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/logger"
+)
+
+const stripeTransfersURL = "https://api.stripe.com/v1/transfers"
+
+// StripeProvider implements Provider with Stripe Connect transfers from the platform balance
+type StripeProvider struct {
+	secretKey string
+	client    *http.Client
+}
+
+// NewStripeProvider creates a Stripe provider
+func NewStripeProvider(secretKey string) *StripeProvider {
+	return &StripeProvider{
+		secretKey: secretKey,
+		client:    &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name returns the provider identifier
+func (p *StripeProvider) Name() string {
+	return "stripe"
+}
+
+// Transfer creates a USD transfer to a connected account
+func (p *StripeProvider) Transfer(ctx context.Context, transfer *Transfer) (*Receipt, error) {
+	form := url.Values{}
+	form.Set("amount", strconv.FormatInt(transfer.AmountCents, 10))
+	form.Set("currency", "usd")
+	form.Set("destination", transfer.Destination)
+	if transfer.Description != "" {
+		form.Set("description", transfer.Description)
+	}
+	for key, value := range transfer.Metadata {
+		form.Set("metadata["+key+"]", value)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, stripeTransfersURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build transfer request: %w", err)
+	}
+	req.SetBasicAuth(p.secretKey, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if transfer.IdempotencyKey != "" {
+		req.Header.Set("Idempotency-Key", transfer.IdempotencyKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		logger.Errorf("Stripe transfer request failed: %v", err)
+		return nil, fmt.Errorf("payout provider request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+		message := fmt.Sprintf("status %d", resp.StatusCode)
+		if json.Unmarshal(data, &failure) == nil && failure.Error.Message != "" {
+			message = failure.Error.Message
+		}
+		// Conflicts, rate limits and server errors leave the outcome unknown
+		if resp.StatusCode >= 400 && resp.StatusCode < 500 &&
+			resp.StatusCode != http.StatusConflict && resp.StatusCode != http.StatusTooManyRequests {
+			return nil, &DeclinedError{Message: message}
+		}
+		return nil, fmt.Errorf("payout provider returned %s", message)
+	}
+
+	var created struct {
+		ID string `json:"id"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		return nil, fmt.Errorf("failed to decode payout provider response: %w", err)
+	}
+	if created.ID == "" {
+		return nil, fmt.Errorf("payout provider response has no transfer ID")
+	}
+
+	return &Receipt{TransferID: created.ID}, nil
+}
@@ -0,0 +1,271 @@
+package pdfform
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+// object is an indirect object; dict is nil when the object is not a dictionary
+type object struct {
+	gen    int
+	pos    int // Offset of the defining object (or its object stream), used to keep the newest revision
+	dict   dict
+	stream []byte
+}
+
+// document indexes the objects of a PDF well enough to rewrite form fields in an incremental update
+type document struct {
+	data      []byte
+	objects   map[int]*object
+	trailer   dict
+	startxref int
+}
+
+var (
+	objHeaderPattern = regexp.MustCompile(`(\d+)\s+(\d+)\s+obj\b`)
+	startxrefPattern = regexp.MustCompile(`startxref\s+(\d+)`)
+)
+
+// parseDocument indexes every object in the file, including those packed in object streams
+func parseDocument(data []byte) (*document, error) {
+	if !bytes.HasPrefix(bytes.TrimLeft(data, "\x00\t\n\f\r "), []byte("%PDF-")) {
+		return nil, fmt.Errorf("not a PDF file")
+	}
+
+	doc := &document{data: data, objects: map[int]*object{}}
+
+	var xrefStream *object
+	for pos := 0; pos < len(data); {
+		loc := objHeaderPattern.FindSubmatchIndex(data[pos:])
+		if loc == nil {
+			break
+		}
+		start := pos + loc[0]
+		num, _ := strconv.Atoi(string(data[pos+loc[2] : pos+loc[3]]))
+		gen, _ := strconv.Atoi(string(data[pos+loc[4] : pos+loc[5]]))
+		next := pos + loc[1]
+
+		obj := &object{gen: gen, pos: start}
+		i := skipSpace(data, next)
+		if bytes.HasPrefix(data[i:], []byte("<<")) {
+			d, end, err := parseDict(data, i)
+			if err == nil {
+				obj.dict = d
+				obj.stream, next = readStream(data, d, end)
+			}
+		}
+
+		if prev, ok := doc.objects[num]; !ok || prev.pos < obj.pos {
+			doc.objects[num] = obj
+		}
+		if obj.dict != nil && parseName(obj.dict.get("Type")) == "XRef" {
+			xrefStream = obj
+		}
+		pos = next
+	}
+
+	for _, obj := range doc.objectStreams() {
+		if err := doc.unpackObjectStream(obj); err != nil {
+			return nil, err
+		}
+	}
+
+	// The last trailer wins: a classic trailer keyword or a cross-reference stream
+	trailerPos := bytes.LastIndex(data, []byte("trailer"))
+	switch {
+	case trailerPos >= 0 && (xrefStream == nil || trailerPos > xrefStream.pos):
+		d, _, err := parseDict(data, trailerPos+len("trailer"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid trailer: %w", err)
+		}
+		doc.trailer = d
+	case xrefStream != nil:
+		doc.trailer = xrefStream.dict
+	default:
+		return nil, fmt.Errorf("no trailer found")
+	}
+
+	matches := startxrefPattern.FindAllSubmatch(data, -1)
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("no startxref found")
+	}
+	doc.startxref, _ = strconv.Atoi(string(matches[len(matches)-1][1]))
+
+	if doc.trailer.get("Encrypt") != nil {
+		return nil, fmt.Errorf("encrypted PDFs are not supported")
+	}
+
+	return doc, nil
+}
+
+// readStream returns the stream data following a dictionary (if any) and the offset after it
+func readStream(data []byte, d dict, end int) ([]byte, int) {
+	i := skipSpace(data, end)
+	if !bytes.HasPrefix(data[i:], []byte("stream")) {
+		return nil, end
+	}
+	i += len("stream")
+	if i < len(data) && data[i] == '\r' {
+		i++
+	}
+	if i < len(data) && data[i] == '\n' {
+		i++
+	}
+
+	if length, ok := parseInt(d.get("Length")); ok && i+length <= len(data) {
+		after := skipSpace(data, i+length)
+		if bytes.HasPrefix(data[after:], []byte("endstream")) {
+			return data[i : i+length], after + len("endstream")
+		}
+	}
+
+	// Indirect or wrong /Length: fall back to scanning for the keyword
+	e := bytes.Index(data[i:], []byte("endstream"))
+	if e < 0 {
+		return data[i:], len(data)
+	}
+	return bytes.TrimRight(data[i:i+e], "\r\n"), i + e + len("endstream")
+}
+
+func (doc *document) objectStreams() []*object {
+	var streams []*object
+	for _, obj := range doc.objects {
+		if obj.dict != nil && parseName(obj.dict.get("Type")) == "ObjStm" {
+			streams = append(streams, obj)
+		}
+	}
+	return streams
+}
+
+// unpackObjectStream adds the dictionaries packed in an object stream to the index
+func (doc *document) unpackObjectStream(container *object) error {
+	decoded, err := decodeStream(container)
+	if err != nil {
+		return fmt.Errorf("failed to decode object stream: %w", err)
+	}
+
+	n, _ := parseInt(container.dict.get("N"))
+	first, ok := parseInt(container.dict.get("First"))
+	if !ok || first > len(decoded) {
+		return fmt.Errorf("invalid object stream header")
+	}
+
+	header := bytes.Fields(decoded[:first])
+	if len(header) < 2*n {
+		return fmt.Errorf("truncated object stream header")
+	}
+
+	for k := 0; k < n; k++ {
+		num, _ := strconv.Atoi(string(header[2*k]))
+		off, _ := strconv.Atoi(string(header[2*k+1]))
+		start := first + off
+		if start >= len(decoded) {
+			continue
+		}
+
+		if prev, ok := doc.objects[num]; ok && prev.pos >= container.pos {
+			continue
+		}
+
+		obj := &object{pos: container.pos}
+		if i := skipSpace(decoded, start); bytes.HasPrefix(decoded[i:], []byte("<<")) {
+			if d, _, err := parseDict(decoded, i); err == nil {
+				obj.dict = d
+			}
+		}
+		doc.objects[num] = obj
+	}
+
+	return nil
+}
+
+// decodeStream inflates a stream; only unfiltered and FlateDecode streams without predictors are supported
+func decodeStream(obj *object) ([]byte, error) {
+	filter := obj.dict.get("Filter")
+	if filter == nil {
+		return obj.stream, nil
+	}
+
+	name := parseName(filter)
+	if items := parseArray(filter); len(items) == 1 {
+		name = parseName(items[0])
+	}
+	if name != "FlateDecode" {
+		return nil, fmt.Errorf("unsupported stream filter %s", filter)
+	}
+	if obj.dict.get("DecodeParms") != nil {
+		return nil, fmt.Errorf("stream predictors are not supported")
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(obj.stream))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
+
+// resolve returns the dictionary a value holds directly or by reference
+func (doc *document) resolve(val []byte) dict {
+	if num, ok := parseRef(val); ok {
+		if obj, ok := doc.objects[num]; ok {
+			return obj.dict
+		}
+		return nil
+	}
+	if d, _, err := parseDict(val, 0); err == nil {
+		return d
+	}
+	return nil
+}
+
+// appendUpdate writes the rewritten objects as an incremental update after the original bytes.
+// A classic xref section is used even after a cross-reference stream; mainstream readers accept it.
+func (doc *document) appendUpdate(updated map[int]dict) []byte {
+	var out bytes.Buffer
+	out.Write(doc.data)
+	if !bytes.HasSuffix(doc.data, []byte("\n")) {
+		out.WriteByte('\n')
+	}
+
+	nums := make([]int, 0, len(updated))
+	for num := range updated {
+		nums = append(nums, num)
+	}
+	sort.Ints(nums)
+
+	size, _ := parseInt(doc.trailer.get("Size"))
+	offsets := make(map[int]int, len(nums))
+	for _, num := range nums {
+		offsets[num] = out.Len()
+		fmt.Fprintf(&out, "%d %d obj\n%s\nendobj\n", num, doc.objects[num].gen, updated[num].bytes())
+		if num >= size {
+			size = num + 1
+		}
+	}
+
+	xref := out.Len()
+	out.WriteString("xref\n")
+	for _, num := range nums {
+		fmt.Fprintf(&out, "%d 1\n%010d %05d n \n", num, offsets[num], doc.objects[num].gen)
+	}
+
+	trailer := dict{
+		{key: "Size", val: []byte(strconv.Itoa(size))},
+		{key: "Root", val: doc.trailer.get("Root")},
+		{key: "Prev", val: []byte(strconv.Itoa(doc.startxref))},
+	}
+	for _, key := range []string{"Info", "ID"} {
+		if val := doc.trailer.get(key); val != nil {
+			trailer = append(trailer, dictEntry{key: key, val: val})
+		}
+	}
+	fmt.Fprintf(&out, "trailer\n%s\nstartxref\n%d\n%%%%EOF\n", trailer.bytes(), xref)
+
+	return out.Bytes()
+}
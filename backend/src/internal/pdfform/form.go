@@ -0,0 +1,223 @@
+// Package pdfform fills AcroForm fields in existing PDF templates without external dependencies.
+// Values are written as an incremental update, so the original template bytes are never modified.
+package pdfform
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Field types as reported by Fields (the PDF /FT codes)
+const (
+	FieldTypeText      = "Tx"
+	FieldTypeButton    = "Btn"
+	FieldTypeChoice    = "Ch"
+	FieldTypeSignature = "Sig"
+)
+
+// Field describes a fillable field in a template
+type Field struct {
+	Name string `json:"name"` // Fully qualified name, e.g. "topmostSubform[0].Page1[0].f1_01[0]"
+	Type string `json:"type"` // Tx, Btn, Ch or Sig
+}
+
+// formField is a terminal field node and the widgets that display it
+type formField struct {
+	name    string
+	partial string
+	ftype   string
+	node    int
+	widgets []int
+}
+
+// Fields lists the fillable fields of a PDF template, sorted by name
+func Fields(template []byte) ([]Field, error) {
+	doc, err := parseDocument(template)
+	if err != nil {
+		return nil, err
+	}
+
+	formFields, _, err := doc.formFields()
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]Field, 0, len(formFields))
+	for _, f := range formFields {
+		fields = append(fields, Field{Name: f.name, Type: f.ftype})
+	}
+	sort.Slice(fields, func(i, j int) bool { return fields[i].Name < fields[j].Name })
+	return fields, nil
+}
+
+// Fill sets field values and returns the filled PDF. Keys match either a field's fully
+// qualified name or its last name component. Signature fields and unknown keys are ignored.
+// Viewers are asked to regenerate appearances, and any XFA form is dropped so the AcroForm
+// values are the ones displayed.
+func Fill(template []byte, values map[string]string) ([]byte, error) {
+	doc, err := parseDocument(template)
+	if err != nil {
+		return nil, err
+	}
+
+	formFields, acroForm, err := doc.formFields()
+	if err != nil {
+		return nil, err
+	}
+
+	updated := map[int]dict{}
+	edit := func(num int) dict {
+		if d, ok := updated[num]; ok {
+			return d
+		}
+		return doc.objects[num].dict.clone()
+	}
+
+	for _, f := range formFields {
+		value, ok := values[f.name]
+		if !ok {
+			value, ok = values[f.partial]
+		}
+		if !ok {
+			continue
+		}
+
+		switch f.ftype {
+		case FieldTypeSignature:
+			continue
+		case FieldTypeButton:
+			state := value
+			if state == "" {
+				state = "Off"
+			}
+			updated[f.node] = edit(f.node).set("V", encodeName(state))
+			for _, w := range f.widgets {
+				appearance := state
+				if states := doc.appearanceStates(edit(w)); states != nil && !states[state] {
+					appearance = "Off"
+				}
+				updated[w] = edit(w).set("AS", encodeName(appearance))
+			}
+		default:
+			updated[f.node] = edit(f.node).set("V", encodeString(value))
+			// Stale appearance streams would still show the template's value
+			for _, w := range f.widgets {
+				updated[w] = edit(w).del("AP")
+			}
+		}
+	}
+
+	rootNum, ok := parseRef(doc.trailer.get("Root"))
+	if !ok {
+		return nil, fmt.Errorf("document catalog is not an indirect object")
+	}
+	setAppearanceFlags := func(d dict) dict {
+		return d.set("NeedAppearances", []byte("true")).del("XFA")
+	}
+	if num, ok := parseRef(acroForm); ok {
+		updated[num] = setAppearanceFlags(edit(num))
+	} else {
+		catalog := edit(rootNum)
+		updated[rootNum] = catalog.set("AcroForm", setAppearanceFlags(doc.resolve(acroForm).clone()).bytes())
+	}
+
+	return doc.appendUpdate(updated), nil
+}
+
+// formFields walks the AcroForm field tree and returns its terminal fields and the raw /AcroForm value
+func (doc *document) formFields() ([]formField, []byte, error) {
+	catalog := doc.resolve(doc.trailer.get("Root"))
+	if catalog == nil {
+		return nil, nil, fmt.Errorf("document catalog not found")
+	}
+
+	acroForm := catalog.get("AcroForm")
+	form := doc.resolve(acroForm)
+	if form == nil {
+		return nil, nil, fmt.Errorf("PDF has no fillable form")
+	}
+
+	var fields []formField
+	visited := map[int]bool{}
+
+	var visit func(num int, parentName, inheritedType string)
+	visit = func(num int, parentName, inheritedType string) {
+		obj, ok := doc.objects[num]
+		if !ok || obj.dict == nil || visited[num] {
+			return
+		}
+		visited[num] = true
+
+		d := obj.dict
+		partial := decodeString(d.get("T"))
+		name := parentName
+		if partial != "" {
+			if name != "" {
+				name += "."
+			}
+			name += partial
+		}
+
+		ftype := parseName(d.get("FT"))
+		if ftype == "" {
+			ftype = inheritedType
+		}
+
+		// Kids carrying their own /T are child fields; the rest are widgets of this field
+		var childFields, widgets []int
+		for _, kid := range parseArray(d.get("Kids")) {
+			kidNum, ok := parseRef(kid)
+			if !ok {
+				continue
+			}
+			if kidObj, ok := doc.objects[kidNum]; ok && kidObj.dict != nil && kidObj.dict.get("T") != nil {
+				childFields = append(childFields, kidNum)
+			} else {
+				widgets = append(widgets, kidNum)
+			}
+		}
+
+		for _, child := range childFields {
+			visit(child, name, ftype)
+		}
+
+		if len(childFields) == 0 && partial != "" {
+			if len(widgets) == 0 {
+				widgets = []int{num}
+			}
+			fields = append(fields, formField{
+				name:    name,
+				partial: partial,
+				ftype:   ftype,
+				node:    num,
+				widgets: widgets,
+			})
+		}
+	}
+
+	for _, ref := range parseArray(form.get("Fields")) {
+		if num, ok := parseRef(ref); ok {
+			visit(num, "", "")
+		}
+	}
+
+	return fields, acroForm, nil
+}
+
+// appearanceStates returns the "on" and "off" state names a checkbox or radio widget defines
+func (doc *document) appearanceStates(widget dict) map[string]bool {
+	ap := doc.resolve(widget.get("AP"))
+	if ap == nil {
+		return nil
+	}
+	normal := doc.resolve(ap.get("N"))
+	if normal == nil {
+		return nil
+	}
+
+	states := make(map[string]bool, len(normal))
+	for _, e := range normal {
+		states[e.key] = true
+	}
+	return states
+}
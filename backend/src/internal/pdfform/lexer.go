@@ -0,0 +1,409 @@
+package pdfform
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"unicode/utf16"
+)
+
+// dictEntry is one key/value pair of a PDF dictionary; val holds the raw value bytes
+type dictEntry struct {
+	key string
+	val []byte
+}
+
+// dict is a PDF dictionary that preserves entry order so rewritten objects stay close to the original
+type dict []dictEntry
+
+func (d dict) get(key string) []byte {
+	for _, e := range d {
+		if e.key == key {
+			return e.val
+		}
+	}
+	return nil
+}
+
+func (d dict) set(key string, val []byte) dict {
+	for i, e := range d {
+		if e.key == key {
+			d[i].val = val
+			return d
+		}
+	}
+	return append(d, dictEntry{key: key, val: val})
+}
+
+func (d dict) del(key string) dict {
+	out := d[:0]
+	for _, e := range d {
+		if e.key != key {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+func (d dict) clone() dict {
+	return append(dict(nil), d...)
+}
+
+func (d dict) bytes() []byte {
+	var b bytes.Buffer
+	b.WriteString("<<")
+	for _, e := range d {
+		b.WriteString(" /")
+		b.WriteString(e.key)
+		b.WriteByte(' ')
+		b.Write(e.val)
+	}
+	b.WriteString(" >>")
+	return b.Bytes()
+}
+
+func isWhite(c byte) bool {
+	return c == 0 || c == '\t' || c == '\n' || c == '\f' || c == '\r' || c == ' '
+}
+
+func isDelim(c byte) bool {
+	return bytes.IndexByte([]byte("()<>[]{}/%"), c) >= 0
+}
+
+// skipSpace skips whitespace and comments
+func skipSpace(b []byte, i int) int {
+	for i < len(b) {
+		switch {
+		case isWhite(b[i]):
+			i++
+		case b[i] == '%':
+			for i < len(b) && b[i] != '\n' && b[i] != '\r' {
+				i++
+			}
+		default:
+			return i
+		}
+	}
+	return i
+}
+
+// skipValue returns the index just past the value starting at (or after whitespace from) i
+func skipValue(b []byte, i int) (int, error) {
+	i = skipSpace(b, i)
+	if i >= len(b) {
+		return i, fmt.Errorf("unexpected end of data")
+	}
+
+	switch c := b[i]; {
+	case c == '(':
+		depth := 0
+		for ; i < len(b); i++ {
+			switch b[i] {
+			case '\\':
+				i++
+			case '(':
+				depth++
+			case ')':
+				depth--
+				if depth == 0 {
+					return i + 1, nil
+				}
+			}
+		}
+		return i, fmt.Errorf("unterminated string")
+	case c == '<' && i+1 < len(b) && b[i+1] == '<':
+		i += 2
+		for {
+			i = skipSpace(b, i)
+			if i+1 < len(b) && b[i] == '>' && b[i+1] == '>' {
+				return i + 2, nil
+			}
+			next, err := skipValue(b, i)
+			if err != nil {
+				return next, err
+			}
+			i = next
+		}
+	case c == '<':
+		end := bytes.IndexByte(b[i:], '>')
+		if end < 0 {
+			return i, fmt.Errorf("unterminated hex string")
+		}
+		return i + end + 1, nil
+	case c == '[':
+		i++
+		for {
+			i = skipSpace(b, i)
+			if i < len(b) && b[i] == ']' {
+				return i + 1, nil
+			}
+			next, err := skipValue(b, i)
+			if err != nil {
+				return next, err
+			}
+			i = next
+		}
+	case c == '/':
+		i++
+		for i < len(b) && !isWhite(b[i]) && !isDelim(b[i]) {
+			i++
+		}
+		return i, nil
+	default:
+		end := i
+		for end < len(b) && !isWhite(b[end]) && !isDelim(b[end]) {
+			end++
+		}
+		if end == i {
+			return i, fmt.Errorf("unexpected %q at offset %d", c, i)
+		}
+
+		// An integer may start an indirect reference: "12 0 R"
+		if isInteger(b[i:end]) {
+			j := skipSpace(b, end)
+			k := j
+			for k < len(b) && b[k] >= '0' && b[k] <= '9' {
+				k++
+			}
+			if k > j {
+				r := skipSpace(b, k)
+				if r < len(b) && b[r] == 'R' && (r+1 == len(b) || isWhite(b[r+1]) || isDelim(b[r+1])) {
+					return r + 1, nil
+				}
+			}
+		}
+		return end, nil
+	}
+}
+
+func isInteger(b []byte) bool {
+	if len(b) == 0 {
+		return false
+	}
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// parseDict parses the dictionary starting at (or after whitespace from) i
+func parseDict(b []byte, i int) (dict, int, error) {
+	i = skipSpace(b, i)
+	if !bytes.HasPrefix(b[i:], []byte("<<")) {
+		return nil, i, fmt.Errorf("expected dictionary at offset %d", i)
+	}
+	i += 2
+
+	var d dict
+	for {
+		i = skipSpace(b, i)
+		if i+1 < len(b) && b[i] == '>' && b[i+1] == '>' {
+			return d, i + 2, nil
+		}
+		if i >= len(b) || b[i] != '/' {
+			return nil, i, fmt.Errorf("expected name at offset %d", i)
+		}
+
+		keyEnd, err := skipValue(b, i)
+		if err != nil {
+			return nil, keyEnd, err
+		}
+		valStart := skipSpace(b, keyEnd)
+		valEnd, err := skipValue(b, valStart)
+		if err != nil {
+			return nil, valEnd, err
+		}
+
+		d = append(d, dictEntry{key: string(b[i+1 : keyEnd]), val: b[valStart:valEnd]})
+		i = valEnd
+	}
+}
+
+// parseArray splits an array value into its raw elements
+func parseArray(b []byte) [][]byte {
+	i := skipSpace(b, 0)
+	if i >= len(b) || b[i] != '[' {
+		return nil
+	}
+	i++
+
+	var items [][]byte
+	for {
+		i = skipSpace(b, i)
+		if i >= len(b) || b[i] == ']' {
+			return items
+		}
+		end, err := skipValue(b, i)
+		if err != nil {
+			return items
+		}
+		items = append(items, b[i:end])
+		i = end
+	}
+}
+
+var refPattern = regexp.MustCompile(`^\s*(\d+)\s+(\d+)\s+R\s*$`)
+
+// parseRef returns the object number of an indirect reference
+func parseRef(b []byte) (int, bool) {
+	m := refPattern.FindSubmatch(b)
+	if m == nil {
+		return 0, false
+	}
+	n, err := strconv.Atoi(string(m[1]))
+	return n, err == nil
+}
+
+func parseInt(b []byte) (int, bool) {
+	n, err := strconv.Atoi(string(bytes.TrimSpace(b)))
+	return n, err == nil
+}
+
+// parseName returns a name value without its leading slash
+func parseName(b []byte) string {
+	b = bytes.TrimSpace(b)
+	if len(b) == 0 || b[0] != '/' {
+		return ""
+	}
+	return string(b[1:])
+}
+
+// decodeString decodes a literal or hex string, including UTF-16BE text strings
+func decodeString(b []byte) string {
+	b = bytes.TrimSpace(b)
+	if len(b) < 2 {
+		return ""
+	}
+
+	var raw []byte
+	switch b[0] {
+	case '(':
+		raw = unescapeLiteral(b[1 : len(b)-1])
+	case '<':
+		hex := bytes.Map(func(r rune) rune {
+			if isWhite(byte(r)) {
+				return -1
+			}
+			return r
+		}, b[1:len(b)-1])
+		if len(hex)%2 == 1 {
+			hex = append(hex, '0')
+		}
+		for i := 0; i+1 < len(hex); i += 2 {
+			v, err := strconv.ParseUint(string(hex[i:i+2]), 16, 8)
+			if err != nil {
+				return ""
+			}
+			raw = append(raw, byte(v))
+		}
+	default:
+		return ""
+	}
+
+	if len(raw) >= 2 && raw[0] == 0xFE && raw[1] == 0xFF {
+		units := make([]uint16, 0, len(raw)/2)
+		for i := 2; i+1 < len(raw); i += 2 {
+			units = append(units, uint16(raw[i])<<8|uint16(raw[i+1]))
+		}
+		return string(utf16.Decode(units))
+	}
+
+	// PDFDocEncoding matches Latin-1 for the characters used in field names
+	runes := make([]rune, len(raw))
+	for i, c := range raw {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+func unescapeLiteral(b []byte) []byte {
+	var out []byte
+	for i := 0; i < len(b); i++ {
+		if b[i] != '\\' || i+1 == len(b) {
+			out = append(out, b[i])
+			continue
+		}
+		i++
+		switch c := b[i]; c {
+		case 'n':
+			out = append(out, '\n')
+		case 'r':
+			out = append(out, '\r')
+		case 't':
+			out = append(out, '\t')
+		case 'b':
+			out = append(out, '\b')
+		case 'f':
+			out = append(out, '\f')
+		case '\r':
+			if i+1 < len(b) && b[i+1] == '\n' {
+				i++
+			}
+		case '\n':
+			// Line continuation
+		default:
+			if c >= '0' && c <= '7' {
+				v := 0
+				j := i
+				for ; j < len(b) && j < i+3 && b[j] >= '0' && b[j] <= '7'; j++ {
+					v = v*8 + int(b[j]-'0')
+				}
+				out = append(out, byte(v))
+				i = j - 1
+			} else {
+				out = append(out, c)
+			}
+		}
+	}
+	return out
+}
+
+// encodeString encodes text as a PDF string, using UTF-16BE when it is not plain ASCII
+func encodeString(s string) []byte {
+	ascii := true
+	for _, r := range s {
+		if r < 32 || r > 126 {
+			ascii = false
+			break
+		}
+	}
+
+	if ascii {
+		var b bytes.Buffer
+		b.WriteByte('(')
+		for i := 0; i < len(s); i++ {
+			if s[i] == '(' || s[i] == ')' || s[i] == '\\' {
+				b.WriteByte('\\')
+			}
+			b.WriteByte(s[i])
+		}
+		b.WriteByte(')')
+		return b.Bytes()
+	}
+
+	var b bytes.Buffer
+	b.WriteString("<FEFF")
+	for _, u := range utf16.Encode([]rune(s)) {
+		fmt.Fprintf(&b, "%04X", u)
+	}
+	b.WriteByte('>')
+	return b.Bytes()
+}
+
+// encodeName encodes a name value, escaping delimiters and non-printable bytes
+func encodeName(s string) []byte {
+	var b bytes.Buffer
+	b.WriteByte('/')
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c < 33 || c > 126 || c == '#' || isDelim(c) {
+			fmt.Fprintf(&b, "#%02X", c)
+		} else {
+			b.WriteByte(c)
+		}
+	}
+	return b.Bytes()
+}
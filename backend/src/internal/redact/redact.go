@@ -0,0 +1,101 @@
+// Package redact removes sensitive fields from JSON bodies and query strings before they are logged
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"unicode"
+)
+
+// Mask replaces the value of every redacted field
+const Mask = "[REDACTED]"
+
+// Redactor masks fields whose names contain any of its field names. Names are compared without
+// case or separators, so "ssn" also masks "spouseSsn" and "SPOUSE_SSN", and "token" masks
+// "idToken" and "refresh_token".
+type Redactor struct {
+	fields []string
+}
+
+// New creates a redactor for the given field names
+func New(fields []string) *Redactor {
+	normalized := make([]string, 0, len(fields))
+	for _, field := range fields {
+		if field = normalize(field); field != "" {
+			normalized = append(normalized, field)
+		}
+	}
+	return &Redactor{fields: normalized}
+}
+
+// Sensitive reports whether values of a field named name are masked
+func (r *Redactor) Sensitive(name string) bool {
+	name = normalize(name)
+	for _, field := range r.fields {
+		if strings.Contains(name, field) {
+			return true
+		}
+	}
+	return false
+}
+
+// JSON returns body with every sensitive field masked at any depth. ok is false when body is
+// not JSON, in which case nothing is returned.
+func (r *Redactor) JSON(body []byte) (redacted []byte, ok bool) {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, false
+	}
+
+	redacted, err := json.Marshal(r.value(value))
+	if err != nil {
+		return nil, false
+	}
+	return redacted, true
+}
+
+// Query returns a query string with the values of sensitive parameters masked
+func (r *Redactor) Query(values url.Values) string {
+	masked := url.Values{}
+	for name, vals := range values {
+		if r.Sensitive(name) {
+			masked[name] = []string{Mask}
+			continue
+		}
+		masked[name] = vals
+	}
+	return masked.Encode()
+}
+
+// value masks the sensitive fields of a decoded JSON value
+func (r *Redactor) value(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if r.Sensitive(key) {
+				v[key] = Mask
+				continue
+			}
+			v[key] = r.value(field)
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.value(item)
+		}
+	}
+	return value
+}
+
+// normalize lowercases a field name and drops everything but letters and digits
+func normalize(name string) string {
+	return strings.Map(func(c rune) rune {
+		if unicode.IsLetter(c) || unicode.IsDigit(c) {
+			return unicode.ToLower(c)
+		}
+		return -1
+	}, name)
+}
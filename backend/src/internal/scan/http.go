@@ -0,0 +1,74 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// HTTPScanner implements Scanner with a scanning API that takes the file as the request body and
+// answers with {"infected": bool, "threat": "signature name"}. Cloud and third-party scanners are
+// put behind such an endpoint, such as a Cloud Run service wrapping their client library.
+type HTTPScanner struct {
+	url    string
+	apiKey string
+	client *http.Client
+}
+
+// NewHTTPScanner creates a scanner for the endpoint at url
+func NewHTTPScanner(url, apiKey string) *HTTPScanner {
+	return &HTTPScanner{
+		url:    url,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// Name returns the provider identifier
+func (s *HTTPScanner) Name() string {
+	return "http"
+}
+
+type httpScanResponse struct {
+	Infected *bool  `json:"infected"`
+	Threat   string `json:"threat"`
+}
+
+// Scan posts the file to the endpoint and reads its verdict
+func (s *HTTPScanner) Scan(ctx context.Context, data []byte) (*Verdict, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build scan request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+	if s.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+s.apiKey)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("scan request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scan response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("scanner returned status %d: %s", resp.StatusCode, string(body))
+	}
+
+	var result httpScanResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode scan response: %w", err)
+	}
+	if result.Infected == nil {
+		return nil, fmt.Errorf("scan response has no verdict")
+	}
+	return &Verdict{Infected: *result.Infected, Threat: result.Threat}, nil
+}
@@ -0,0 +1,163 @@
+package scan
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"welltaxpro/src/internal/storage"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// Scanner defines the interface for malware scanning services run on uploaded documents
+type Scanner interface {
+	// Scan checks a whole file; an error means no verdict could be given
+	Scan(ctx context.Context, data []byte) (*Verdict, error)
+
+	// Name returns the provider identifier stored with each scan
+	Name() string
+}
+
+// Config selects and configures the malware scanner
+type Config struct {
+	Provider string // "clamav", "http" or empty to disable scanning
+	Address  string // clamav: clamd address, host:port or unix:/path/to/clamd.sock
+	URL      string // http: scanning endpoint
+	APIKey   string // http: sent as a bearer token
+}
+
+// ProviderNone is the name of the scanner used when scanning is not configured
+const ProviderNone = "none"
+
+// QuarantinePrefix is the bucket prefix infected files are stored under, away from the
+// paths documents are normally served from
+const QuarantinePrefix = "quarantine/"
+
+// ErrDisabled is returned by the disabled scanner
+var ErrDisabled = errors.New("malware scanning is not configured")
+
+// Verdict is a scanner's finding on one file
+type Verdict struct {
+	Infected bool
+	Threat   string // Signature name reported for an infected file
+}
+
+// NewScanner creates the configured malware scanner
+// Falls back to a disabled scanner when none is configured, leaving uploads unscanned
+func NewScanner(cfg Config) Scanner {
+	switch cfg.Provider {
+	case "clamav":
+		if cfg.Address == "" {
+			logger.Warning("ClamAV scanning configured without a clamd address, disabling scanning")
+			return &DisabledScanner{}
+		}
+		return NewClamAVScanner(cfg.Address)
+	case "http":
+		if cfg.URL == "" {
+			logger.Warning("HTTP scanning configured without a URL, disabling scanning")
+			return &DisabledScanner{}
+		}
+		return NewHTTPScanner(cfg.URL, cfg.APIKey)
+	default:
+		return &DisabledScanner{}
+	}
+}
+
+// Enabled reports whether uploads are scanned
+func Enabled(scanner Scanner) bool {
+	return scanner != nil && scanner.Name() != ProviderNone
+}
+
+// DisabledScanner is used when no scanner is configured
+type DisabledScanner struct{}
+
+// Name returns the provider identifier
+func (s *DisabledScanner) Name() string {
+	return ProviderNone
+}
+
+// Scan always fails
+func (s *DisabledScanner) Scan(ctx context.Context, data []byte) (*Verdict, error) {
+	return nil, ErrDisabled
+}
+
+// Check scans a file and returns the scan to record. A scanner failure gives a PENDING scan,
+// retried later by the document_scan_retry job.
+func Check(ctx context.Context, scanner Scanner, data []byte) *types.DocumentScan {
+	result := &types.DocumentScan{Scanner: scanner.Name(), Attempts: 1}
+
+	verdict, err := scanner.Scan(ctx, data)
+	if err != nil {
+		logger.Warningf("%s scan failed, document left pending: %v", scanner.Name(), err)
+		msg := err.Error()
+		result.Status = types.DocumentScanPending
+		result.Error = &msg
+		return result
+	}
+
+	now := time.Now()
+	result.ScannedAt = &now
+	result.Status = types.DocumentScanClean
+	if verdict.Infected {
+		result.Status = types.DocumentScanInfected
+		result.Threat = &verdict.Threat
+	}
+	return result
+}
+
+// Upload scans a file and stores it in a tenant bucket. Clean files, and files the scanner
+// could not check, are stored at path; infected files are stored under QuarantinePrefix. It
+// returns where the file was stored and the scan to record once the document exists, which is
+// nil when scanning is disabled.
+func Upload(ctx context.Context, scanner Scanner, provider storage.StorageProvider, bucket, path string, data []byte, metadata map[string]string) (string, *types.DocumentScan, error) {
+	var result *types.DocumentScan
+	stored := path
+	if Enabled(scanner) {
+		result = Check(ctx, scanner, data)
+		if result.Status == types.DocumentScanInfected {
+			stored = QuarantinePrefix + path
+			result.QuarantinePath = &stored
+			logger.Warningf("Quarantined infected upload %s as %s: %s", path, stored, *result.Threat)
+		}
+	}
+
+	if err := provider.Upload(ctx, bucket, stored, bytes.NewReader(data), metadata); err != nil {
+		return "", nil, err
+	}
+	return stored, result, nil
+}
+
+// Rescan scans a stored file again and, when it is infected, moves it under QuarantinePrefix.
+// It returns the scan to record.
+func Rescan(ctx context.Context, scanner Scanner, provider storage.StorageProvider, bucket, path string) (*types.DocumentScan, error) {
+	reader, err := provider.Download(ctx, bucket, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download %s: %w", path, err)
+	}
+	data, err := io.ReadAll(reader)
+	reader.Close()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	result := Check(ctx, scanner, data)
+	if result.Status != types.DocumentScanInfected {
+		return result, nil
+	}
+
+	quarantined := QuarantinePrefix + path
+	if err := provider.Upload(ctx, bucket, quarantined, bytes.NewReader(data), map[string]string{"quarantined_from": path}); err != nil {
+		return nil, fmt.Errorf("failed to quarantine %s: %w", path, err)
+	}
+	if err := provider.Delete(ctx, bucket, path); err != nil {
+		// The document is blocked either way; the copy left behind is never served
+		logger.Errorf("Failed to remove infected file %s after quarantining it: %v", path, err)
+	}
+	result.QuarantinePath = &quarantined
+	logger.Warningf("Quarantined infected file %s as %s: %s", path, quarantined, *result.Threat)
+	return result, nil
+}
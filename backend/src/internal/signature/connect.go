@@ -0,0 +1,88 @@
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"welltaxpro/src/internal/types"
+)
+
+// ErrInvalidConnectSignature is returned for Connect events no HMAC signature header vouches for
+var ErrInvalidConnectSignature = errors.New("DocuSign Connect signature is invalid")
+
+// connectSignatureHeader prefixes the numbered headers DocuSign Connect signs events with,
+// one per HMAC key on the Connect configuration (X-DocuSign-Signature-1, -2, ...)
+const connectSignatureHeader = "X-DocuSign-Signature-"
+
+// maxConnectSignatures is the most HMAC keys a Connect configuration can have
+const maxConnectSignatures = 100
+
+// connectEventStatuses maps the envelope events that change a request's status; other events,
+// including all recipient events, are ignored
+var connectEventStatuses = map[string]string{
+	"envelope-sent":      types.SignatureStatusSent,
+	"envelope-delivered": types.SignatureStatusDelivered,
+	"envelope-completed": types.SignatureStatusCompleted,
+	"envelope-declined":  types.SignatureStatusDeclined,
+	"envelope-voided":    types.SignatureStatusVoided,
+}
+
+// ConnectEvent is an envelope status change reported by DocuSign Connect
+type ConnectEvent struct {
+	Event      string // Connect event name, such as envelope-completed
+	EnvelopeID string
+	Status     string // types.SignatureStatus*; empty for events that do not change the status
+}
+
+// connectMessage is the JSON (SIM) message format of DocuSign Connect
+type connectMessage struct {
+	Event string `json:"event"`
+	Data  struct {
+		EnvelopeID string `json:"envelopeId"`
+	} `json:"data"`
+}
+
+// VerifyConnectSignature checks the HMAC signature headers of a Connect event in constant time.
+// Connect sends one header per HMAC key, so any match is accepted.
+func VerifyConnectSignature(header http.Header, body []byte, secret string) error {
+	if secret == "" {
+		return ErrInvalidConnectSignature
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	expected := []byte(base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+
+	for i := 1; i <= maxConnectSignatures; i++ {
+		signature := header.Get(fmt.Sprintf("%s%d", connectSignatureHeader, i))
+		if signature == "" {
+			break
+		}
+		if hmac.Equal(expected, []byte(strings.TrimSpace(signature))) {
+			return nil
+		}
+	}
+	return ErrInvalidConnectSignature
+}
+
+// ParseConnectEvent decodes a Connect message in the JSON (SIM) format
+func ParseConnectEvent(body []byte) (*ConnectEvent, error) {
+	var msg connectMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("failed to decode Connect message: %w", err)
+	}
+	if msg.Event == "" || msg.Data.EnvelopeID == "" {
+		return nil, errors.New("Connect message is missing its event or envelope ID")
+	}
+
+	return &ConnectEvent{
+		Event:      msg.Event,
+		EnvelopeID: msg.Data.EnvelopeID,
+		Status:     connectEventStatuses[msg.Event],
+	}, nil
+}
@@ -11,11 +11,15 @@ import (
 	"strings"
 	"time"
 	"welltaxpro/src/internal/secrets"
+	"welltaxpro/src/internal/tracing"
 
 	"github.com/golang-jwt/jwt"
 	"github.com/google/logger"
 )
 
+// docuSignClient sends DocuSign requests, each traced as a client span
+var docuSignClient = &http.Client{Transport: tracing.Transport(nil)}
+
 type AccessToken struct {
 	Token  string `json:"access_token"`
 	Type   string `json:"token_type"`
@@ -104,11 +108,16 @@ func makeDSToken(ctx context.Context, integrationKey, clientId, privateKeySecret
 	}
 
 	// Submit the JWT to the account server and request access token
-	resp, err := http.PostForm("https://account.docusign.com/oauth/token",
-		url.Values{
-			"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
-			"assertion":  {tokenString},
-		})
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {tokenString},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://account.docusign.com/oauth/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create auth request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := docuSignClient.Do(req)
 	if err != nil {
 		logger.Errorf("Request Failed: %v", err)
 		return "", fmt.Errorf("auth request failed: %w", err)
@@ -143,18 +152,17 @@ func makeDSToken(ctx context.Context, integrationKey, clientId, privateKeySecret
 }
 
 // getAPIAccId retrieves the API account ID GUID used to make all subsequent API calls
-func getAPIAccId(DSAccessToken string) (string, error) {
-	client := &http.Client{}
-	// Use http.NewRequest in order to set custom headers
-	req, err := http.NewRequest("GET", "https://account.docusign.com/oauth/userinfo", nil)
+func getAPIAccId(ctx context.Context, DSAccessToken string) (string, error) {
+	// Use http.NewRequestWithContext in order to set custom headers
+	req, err := http.NewRequestWithContext(ctx, "GET", "https://account.docusign.com/oauth/userinfo", nil)
 	if err != nil {
 		logger.Errorf("Request Failed: %v", err)
 		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 	req.Header.Set("Authorization", "Bearer "+DSAccessToken)
 
-	// Since http.NewRequest is being used, client.Do is needed to execute the request
-	res, err := client.Do(req)
+	// Since a request is being built, Do is needed to execute it
+	res, err := docuSignClient.Do(req)
 	if err != nil {
 		logger.Errorf("Failed connecting to client: %v", err)
 		return "", fmt.Errorf("failed to get user info: %w", err)
@@ -8,9 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"strconv"
-	"strings"
 	"time"
 	"welltaxpro/src/internal/storage"
 	"welltaxpro/src/internal/types"
@@ -47,7 +45,7 @@ type Signer struct {
 type Tabs struct {
 	SignHereTabs   []SignHere   `json:"signHereTabs"`
 	DateSignedTabs []DateSigned `json:"dateSignedTabs"`
-	TextTabs       []Text       `json:"textTabs"`
+	TextTabs       []Text       `json:"textTabs,omitempty"`
 }
 
 type SignHere struct {
@@ -84,79 +82,25 @@ type EnvelopeID struct {
 // encodePDFToBase64 reads a PDF file and encodes it to a Base64 string
 // Handles both local file paths and GCS URLs
 func encodePDFToBase64(ctx context.Context, tc *types.TenantConnection, filePath string) (string, error) {
-	var pdfBytes []byte
-	var err error
-
-	// Check if it's a GCS URL
-	if strings.HasPrefix(filePath, "https://storage.googleapis.com/") || strings.HasPrefix(filePath, "gs://") {
-		// Parse GCS URL to extract bucket and path
-		bucket, path := parseGCSURL(filePath)
-		if bucket == "" || path == "" {
-			return "", fmt.Errorf("invalid GCS URL format: %s", filePath)
-		}
-
-		logger.Infof("Downloading PDF from GCS: gs://%s/%s", bucket, path)
-
-		// Create storage provider
-		storageProvider, err := storage.NewStorageProviderForTenant(ctx, tc)
-		if err != nil {
-			return "", fmt.Errorf("failed to create storage provider: %w", err)
-		}
-
-		// Download file from GCS
-		reader, err := storageProvider.Download(ctx, bucket, path)
-		if err != nil {
-			return "", fmt.Errorf("failed to download from GCS: %w", err)
-		}
-		defer reader.Close()
-
-		// Read all bytes
-		pdfBytes, err = io.ReadAll(reader)
-		if err != nil {
-			return "", fmt.Errorf("failed to read GCS file: %w", err)
-		}
-
-		logger.Infof("Successfully downloaded PDF from GCS (%d bytes)", len(pdfBytes))
-	} else {
-		// Read from local file
-		logger.Infof("Reading PDF from local file: %s", filePath)
-		pdfBytes, err = os.ReadFile(filePath)
-		if err != nil {
-			return "", fmt.Errorf("error reading PDF file: %w", err)
-		}
+	pdfBytes, err := storage.ReadFile(ctx, tc, filePath)
+	if err != nil {
+		return "", err
 	}
 
-	// Encode to Base64
-	base64Str := base64.StdEncoding.EncodeToString(pdfBytes)
-
-	return base64Str, nil
+	return base64.StdEncoding.EncodeToString(pdfBytes), nil
 }
 
-// parseGCSURL extracts bucket and path from GCS URL
-// Supports both https://storage.googleapis.com/{bucket}/{path} and gs://{bucket}/{path}
-func parseGCSURL(url string) (bucket, path string) {
-	if strings.HasPrefix(url, "gs://") {
-		// gs://bucket/path/to/file.pdf
-		parts := strings.SplitN(strings.TrimPrefix(url, "gs://"), "/", 2)
-		if len(parts) == 2 {
-			return parts[0], parts[1]
-		}
-	} else if strings.HasPrefix(url, "https://storage.googleapis.com/") {
-		// https://storage.googleapis.com/bucket/path/to/file.pdf
-		parts := strings.SplitN(strings.TrimPrefix(url, "https://storage.googleapis.com/"), "/", 2)
-		if len(parts) == 2 {
-			return parts[0], parts[1]
-		}
+func sendEnvelope(ctx context.Context, accessToken, apiURL string, tc *types.TenantConnection, pdfPath string, s *Signature) (string, error) {
+	// A pre-filled document already carries names and amounts in its form fields
+	if s.Document != nil {
+		return postEnvelope(ctx, accessToken, apiURL, base64.StdEncoding.EncodeToString(s.Document), s, nil)
 	}
-	return "", ""
-}
 
-func sendEnvelope(ctx context.Context, accessToken, apiURL string, tc *types.TenantConnection, pdfPath string, s *Signature) error {
 	// Convert the PDF file to Base64
 	docBase64, err := encodePDFToBase64(ctx, tc, pdfPath)
 	if err != nil {
 		logger.Errorf("Error encoding PDF: %v", err)
-		return fmt.Errorf("failed to encode PDF: %w", err)
+		return "", fmt.Errorf("failed to encode PDF: %w", err)
 	}
 
 	gi := strconv.FormatFloat(s.GrossIncome, 'f', 2, 64)
@@ -225,6 +169,13 @@ func sendEnvelope(ctx context.Context, accessToken, apiURL string, tc *types.Ten
 		},
 	}
 
+	return postEnvelope(ctx, accessToken, apiURL, docBase64, s, taxPayerTabs)
+}
+
+// postEnvelope sends the document to the taxpayer (and spouse, when required) for signature.
+// textTabs overlay values at fixed positions; they are nil for pre-filled documents.
+// It returns the ID DocuSign assigned to the envelope.
+func postEnvelope(ctx context.Context, accessToken, apiURL, docBase64 string, s *Signature, taxPayerTabs []Text) (string, error) {
 	// Taxpayer Signer
 	taxpayerSigner := Signer{
 		Email:       s.TaxPayerEmail,
@@ -305,14 +256,14 @@ func sendEnvelope(ctx context.Context, accessToken, apiURL string, tc *types.Ten
 	jsonData, err := json.Marshal(envelope)
 	if err != nil {
 		logger.Errorf("Error encoding JSON: %v", err)
-		return fmt.Errorf("failed to encode envelope: %w", err)
+		return "", fmt.Errorf("failed to encode envelope: %w", err)
 	}
 
 	// Create HTTP request
-	req, err := http.NewRequest("POST", apiURL, bytes.NewBuffer(jsonData))
+	req, err := http.NewRequestWithContext(ctx, "POST", apiURL, bytes.NewBuffer(jsonData))
 	if err != nil {
 		logger.Errorf("Error creating request: %v", err)
-		return fmt.Errorf("failed to create request: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
 	}
 
 	// Set headers
@@ -320,11 +271,10 @@ func sendEnvelope(ctx context.Context, accessToken, apiURL string, tc *types.Ten
 	req.Header.Set("Content-Type", "application/json")
 
 	// Execute request
-	client := &http.Client{}
-	resp, err := client.Do(req)
+	resp, err := docuSignClient.Do(req)
 	if err != nil {
 		logger.Errorf("Error sending request: %v", err)
-		return fmt.Errorf("failed to send envelope: %w", err)
+		return "", fmt.Errorf("failed to send envelope: %w", err)
 	}
 	defer resp.Body.Close()
 
@@ -334,14 +284,50 @@ func sendEnvelope(ctx context.Context, accessToken, apiURL string, tc *types.Ten
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		logger.Errorf("Error reading response: %v", err)
-		return fmt.Errorf("failed to read response: %w", err)
+		return "", fmt.Errorf("failed to read response: %w", err)
 	}
 
 	logger.Infof("Response: %s", string(body))
 
 	if resp.StatusCode >= 400 {
-		return fmt.Errorf("DocuSign API error (status %d): %s", resp.StatusCode, string(body))
+		return "", fmt.Errorf("DocuSign API error (status %d): %s", resp.StatusCode, string(body))
+	}
+
+	var created EnvelopeID
+	if err := json.Unmarshal(body, &created); err != nil || created.EnvelopeID == "" {
+		return "", fmt.Errorf("DocuSign response has no envelope ID: %s", string(body))
+	}
+
+	return created.EnvelopeID, nil
+}
+
+// getCombinedDocument downloads an envelope's documents as a single PDF
+func getCombinedDocument(ctx context.Context, accessToken, apiURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/pdf")
+
+	client := &http.Client{Transport: docuSignClient.Transport, Timeout: 60 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to request documents: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("DocuSign API error (status %d): %s", resp.StatusCode, string(body))
+	}
+	if !bytes.HasPrefix(body, []byte("%PDF-")) {
+		return nil, fmt.Errorf("DocuSign returned %s instead of a PDF", resp.Header.Get("Content-Type"))
 	}
 
-	return nil
+	return body, nil
 }
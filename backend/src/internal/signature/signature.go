@@ -3,6 +3,8 @@ package signature
 import (
 	"context"
 	"fmt"
+	"net/url"
+	"welltaxpro/src/internal/tracing"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
@@ -23,47 +25,96 @@ type Signature struct {
 	Owed               float64
 	SignatureDate      *string
 	SpouseSignature    bool
+
+	// Document is a pre-filled Form 8879; when set it is sent instead of the PDF at pdfPath
+	// and no positioned text tabs are added
+	Document []byte
 }
 
 // SignDocument requests a signature from DocuSign using tenant configuration
 // pdfPath is the path to the Form 8879 PDF file to sign
-func SignDocument(ctx context.Context, tc *types.TenantConnection, pdfPath string, s *Signature) error {
+// Returns the ID of the envelope DocuSign created
+func SignDocument(ctx context.Context, tc *types.TenantConnection, pdfPath string, s *Signature) (envelopeID string, err error) {
 	logger.Info("Starting Signature Request")
+	ctx, span := tracing.StartClient(ctx, "docusign.SignDocument", tracing.Tenant(tc.TenantID))
+	defer func() { tracing.End(span, err) }()
+
+	dSAccessToken, dSAccountId, err := authenticate(ctx, tc)
+	if err != nil {
+		return "", err
+	}
+
+	// Build envelope API URL
+	apiURL := fmt.Sprintf("%s/v2.1/accounts/%s/envelopes", tc.DocuSignAPIURL, dSAccountId)
+
+	// Send envelope for signature
+	envelopeID, err = sendEnvelope(ctx, dSAccessToken, apiURL, tc, pdfPath, s)
+	if err != nil {
+		logger.Errorf("Failed to request signature: %v", err)
+		return "", fmt.Errorf("failed to send envelope: %w", err)
+	}
+
+	logger.Infof("Signature request sent successfully as envelope %s", envelopeID)
+	return envelopeID, nil
+}
+
+// DownloadSignedDocument fetches the documents of a completed envelope from DocuSign as one
+// PDF, including the certificate of completion
+func DownloadSignedDocument(ctx context.Context, tc *types.TenantConnection, envelopeID string) (document []byte, err error) {
+	logger.Infof("Downloading signed documents of envelope %s", envelopeID)
+	ctx, span := tracing.StartClient(ctx, "docusign.DownloadSignedDocument", tracing.Tenant(tc.TenantID))
+	defer func() { tracing.End(span, err) }()
+
+	dSAccessToken, dSAccountId, err := authenticate(ctx, tc)
+	if err != nil {
+		return nil, err
+	}
 
+	apiURL := fmt.Sprintf("%s/v2.1/accounts/%s/envelopes/%s/documents/combined?certificate=true",
+		tc.DocuSignAPIURL, dSAccountId, url.PathEscape(envelopeID))
+
+	document, err = getCombinedDocument(ctx, dSAccessToken, apiURL)
+	if err != nil {
+		logger.Errorf("Failed to download envelope %s: %v", envelopeID, err)
+		return nil, fmt.Errorf("failed to download envelope documents: %w", err)
+	}
+
+	logger.Infof("Downloaded signed documents of envelope %s (%d bytes)", envelopeID, len(document))
+	return document, nil
+}
+
+// CheckCredentials authenticates with a tenant's DocuSign configuration without sending anything
+func CheckCredentials(ctx context.Context, tc *types.TenantConnection) error {
+	ctx, span := tracing.StartClient(ctx, "docusign.CheckCredentials", tracing.Tenant(tc.TenantID))
+	_, _, err := authenticate(ctx, tc)
+	tracing.End(span, err)
+	return err
+}
+
+// authenticate gets a DocuSign access token and the account ID for a tenant
+func authenticate(ctx context.Context, tc *types.TenantConnection) (string, string, error) {
 	// Validate tenant has DocuSign configured
 	if tc.DocuSignIntegrationKey == "" || tc.DocuSignClientID == "" || tc.DocuSignPrivateKeySecret == "" {
-		return fmt.Errorf("tenant %s does not have DocuSign configured", tc.TenantID)
+		return "", "", fmt.Errorf("tenant %s does not have DocuSign configured", tc.TenantID)
 	}
 
 	// Get DocuSign access token using JWT
 	dSAccessToken, err := makeDSToken(ctx, tc.DocuSignIntegrationKey, tc.DocuSignClientID, tc.DocuSignPrivateKeySecret)
 	if err != nil {
 		logger.Errorf("Failed to retrieve token: %v", err)
-		return fmt.Errorf("failed to get DocuSign token: %w", err)
+		return "", "", fmt.Errorf("failed to get DocuSign token: %w", err)
 	}
 
 	maskedToken := fmt.Sprintf("%s...%s", dSAccessToken[:3], dSAccessToken[len(dSAccessToken)-3:])
 	logger.Infof("Getting account with token: %s", maskedToken)
 
 	// Get DocuSign account ID
-	dSAccountId, err := getAPIAccId(dSAccessToken)
+	dSAccountId, err := getAPIAccId(ctx, dSAccessToken)
 	if err != nil {
 		logger.Errorf("Failed to get API Account ID: %v", err)
-		return fmt.Errorf("failed to get account ID: %w", err)
+		return "", "", fmt.Errorf("failed to get account ID: %w", err)
 	}
 
 	logger.Info("Signature auth completed")
-
-	// Build envelope API URL
-	apiURL := fmt.Sprintf("%s/v2.1/accounts/%s/envelopes", tc.DocuSignAPIURL, dSAccountId)
-
-	// Send envelope for signature
-	err = sendEnvelope(ctx, dSAccessToken, apiURL, tc, pdfPath, s)
-	if err != nil {
-		logger.Errorf("Failed to request signature: %v", err)
-		return fmt.Errorf("failed to send envelope: %w", err)
-	}
-
-	logger.Info("Signature request sent successfully")
-	return nil
+	return dSAccessToken, dSAccountId, nil
 }
@@ -0,0 +1,46 @@
+// Package signing implements the HMAC scheme official tracking snippets use to sign requests.
+//
+// The signature is the hex HMAC-SHA256, keyed with the tenant secret, of
+//
+//	timestamp + "\n" + nonce + "\n" + METHOD + "\n" + path + "\n" + hex(sha256(body))
+//
+// where timestamp is Unix seconds and path excludes the query string.
+package signing
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Sign computes the request signature
+func Sign(secret, timestamp, nonce, method, path string, body []byte) string {
+	bodyHash := sha256.Sum256(body)
+	payload := strings.Join([]string{timestamp, nonce, strings.ToUpper(method), path, hex.EncodeToString(bodyHash[:])}, "\n")
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a request signature in constant time
+func Verify(secret, timestamp, nonce, method, path string, body []byte, signature string) bool {
+	expected := Sign(secret, timestamp, nonce, method, path, body)
+	return hmac.Equal([]byte(expected), []byte(strings.ToLower(signature)))
+}
+
+// NewKey generates a public key ID and a secret for a new signing key
+func NewKey() (keyID, secret string, err error) {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return "", "", fmt.Errorf("failed to generate key ID: %w", err)
+	}
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return "wtpk_" + hex.EncodeToString(id), hex.EncodeToString(raw), nil
+}
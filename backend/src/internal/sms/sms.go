@@ -0,0 +1,117 @@
+package sms
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/logger"
+)
+
+// Provider defines the interface for text message services
+type Provider interface {
+	// Send texts body to a phone number in E.164 format
+	Send(ctx context.Context, to, body string) (*Receipt, error)
+
+	// ParseStatusCallback verifies a delivery status callback and returns the event it reports
+	ParseStatusCallback(header http.Header, form url.Values) (*Event, error)
+
+	// Name returns the provider identifier stored with each message
+	Name() string
+}
+
+// Config selects and configures the text message provider
+type Config struct {
+	Provider          string // "twilio" or empty to disable text messages
+	AccountSID        string
+	AuthToken         string
+	FromNumber        string // E.164 number messages are sent from
+	StatusCallbackURL string // Public URL of the status callback, exactly as the provider will post to it
+}
+
+// ProviderNone is the name of the provider used when text messages are not configured
+const ProviderNone = "none"
+
+// Errors returned by providers
+var (
+	ErrDisabled         = errors.New("text messages are not configured")
+	ErrInvalidSignature = errors.New("status callback signature is invalid")
+)
+
+// Receipt is the provider's acceptance of a message
+type Receipt struct {
+	ProviderID string
+	Status     string // types.SMSStatus value
+}
+
+// Event is a delivery status change reported by a provider callback
+type Event struct {
+	ProviderID string
+	Status     string // types.SMSStatus value; empty for statuses that do not change a message
+	Error      string // Provider error code, for failed and undelivered messages
+}
+
+// NewProvider creates the configured text message provider
+// Falls back to a disabled provider when none is configured
+func NewProvider(cfg Config) Provider {
+	switch cfg.Provider {
+	case "twilio":
+		if cfg.AccountSID == "" || cfg.AuthToken == "" || cfg.FromNumber == "" {
+			logger.Warning("Twilio configured without an account SID, auth token or from number, disabling text messages")
+			return &DisabledProvider{}
+		}
+		return NewTwilioProvider(cfg.AccountSID, cfg.AuthToken, cfg.FromNumber, cfg.StatusCallbackURL)
+	default:
+		return &DisabledProvider{}
+	}
+}
+
+// Enabled reports whether p sends text messages
+func Enabled(p Provider) bool {
+	return p != nil && p.Name() != ProviderNone
+}
+
+// NormalizePhone returns a phone number in E.164 format. Numbers without a country code are
+// taken as US numbers. ok is false when phone cannot be a valid number.
+func NormalizePhone(phone string) (normalized string, ok bool) {
+	phone = strings.TrimSpace(phone)
+	international := strings.HasPrefix(phone, "+")
+
+	var digits strings.Builder
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits.WriteRune(r)
+		}
+	}
+	d := digits.String()
+
+	switch {
+	case international && len(d) >= 8 && len(d) <= 15 && d[0] != '0':
+		return "+" + d, true
+	case !international && len(d) == 10 && d[0] >= '2':
+		return "+1" + d, true
+	case !international && len(d) == 11 && d[0] == '1' && d[1] >= '2':
+		return "+" + d, true
+	}
+	return "", false
+}
+
+// DisabledProvider rejects every message and callback
+type DisabledProvider struct{}
+
+// Name returns the provider identifier
+func (p *DisabledProvider) Name() string {
+	return ProviderNone
+}
+
+// Send rejects the message
+func (p *DisabledProvider) Send(ctx context.Context, to, body string) (*Receipt, error) {
+	return nil, ErrDisabled
+}
+
+// ParseStatusCallback rejects the callback
+func (p *DisabledProvider) ParseStatusCallback(header http.Header, form url.Values) (*Event, error) {
+	return nil, ErrDisabled
+}
@@ -0,0 +1,150 @@
+package sms
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+const twilioMessagesURL = "https://api.twilio.com/2010-04-01/Accounts/%s/Messages.json"
+
+// twilioStatuses maps Twilio message statuses to message statuses; other statuses are ignored
+var twilioStatuses = map[string]string{
+	"accepted":    types.SMSStatusSent,
+	"scheduled":   types.SMSStatusSent,
+	"queued":      types.SMSStatusSent,
+	"sending":     types.SMSStatusSent,
+	"sent":        types.SMSStatusSent,
+	"delivered":   types.SMSStatusDelivered,
+	"undelivered": types.SMSStatusUndelivered,
+	"failed":      types.SMSStatusFailed,
+	"canceled":    types.SMSStatusFailed,
+}
+
+// TwilioProvider implements Provider using the Twilio Programmable Messaging API
+type TwilioProvider struct {
+	accountSID        string
+	authToken         string
+	fromNumber        string
+	statusCallbackURL string
+	client            *http.Client
+}
+
+// NewTwilioProvider creates a Twilio provider sending from fromNumber. Without a
+// statusCallbackURL messages stay SENT, since Twilio has nowhere to report delivery.
+func NewTwilioProvider(accountSID, authToken, fromNumber, statusCallbackURL string) *TwilioProvider {
+	return &TwilioProvider{
+		accountSID:        accountSID,
+		authToken:         authToken,
+		fromNumber:        fromNumber,
+		statusCallbackURL: statusCallbackURL,
+		client:            &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Name returns the provider identifier
+func (p *TwilioProvider) Name() string {
+	return "twilio"
+}
+
+// Send creates a Twilio message
+func (p *TwilioProvider) Send(ctx context.Context, to, body string) (*Receipt, error) {
+	form := url.Values{}
+	form.Set("To", to)
+	form.Set("From", p.fromNumber)
+	form.Set("Body", body)
+	if p.statusCallbackURL != "" {
+		form.Set("StatusCallback", p.statusCallbackURL)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(twilioMessagesURL, p.accountSID),
+		strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build message request: %w", err)
+	}
+	req.SetBasicAuth(p.accountSID, p.authToken)
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		logger.Errorf("Twilio request failed: %v", err)
+		return nil, fmt.Errorf("text message provider request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusOK {
+		var failure struct {
+			Code    int    `json:"code"`
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(data, &failure) == nil && failure.Message != "" {
+			return nil, fmt.Errorf("text message provider returned status %d: %d %s", resp.StatusCode, failure.Code, failure.Message)
+		}
+		return nil, fmt.Errorf("text message provider returned status %d", resp.StatusCode)
+	}
+
+	var created struct {
+		SID    string `json:"sid"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(data, &created); err != nil {
+		return nil, fmt.Errorf("failed to decode text message provider response: %w", err)
+	}
+	if created.SID == "" {
+		return nil, fmt.Errorf("text message provider response has no message SID")
+	}
+
+	status := twilioStatuses[created.Status]
+	if status == "" {
+		status = types.SMSStatusSent
+	}
+	return &Receipt{ProviderID: created.SID, Status: status}, nil
+}
+
+// ParseStatusCallback checks the X-Twilio-Signature header, a base64 HMAC-SHA1 keyed with the
+// auth token of the callback URL followed by each posted parameter's name and value in name
+// order, and reads the message status
+func (p *TwilioProvider) ParseStatusCallback(header http.Header, form url.Values) (*Event, error) {
+	if p.statusCallbackURL == "" {
+		return nil, ErrDisabled
+	}
+	signature, err := base64.StdEncoding.DecodeString(header.Get("X-Twilio-Signature"))
+	if err != nil || len(signature) == 0 {
+		return nil, ErrInvalidSignature
+	}
+
+	names := make([]string, 0, len(form))
+	for name := range form {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	mac := hmac.New(sha1.New, []byte(p.authToken))
+	mac.Write([]byte(p.statusCallbackURL))
+	for _, name := range names {
+		for _, value := range form[name] {
+			mac.Write([]byte(name + value))
+		}
+	}
+	if !hmac.Equal(signature, mac.Sum(nil)) {
+		return nil, ErrInvalidSignature
+	}
+
+	sid := form.Get("MessageSid")
+	if sid == "" {
+		return nil, fmt.Errorf("status callback has no message SID")
+	}
+	return &Event{ProviderID: sid, Status: twilioStatuses[form.Get("MessageStatus")], Error: form.Get("ErrorCode")}, nil
+}
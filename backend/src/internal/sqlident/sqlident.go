@@ -0,0 +1,29 @@
+// Package sqlident checks and quotes SQL identifiers that come from configuration rather than
+// code, such as tenant schema prefixes, before they are put into queries.
+package sqlident
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/lib/pq"
+)
+
+// schemaPattern is what a schema prefix may look like: a lower-case name Postgres would not
+// need quoted, no longer than its 63-byte identifier limit
+var schemaPattern = regexp.MustCompile(`^[a-z_][a-z0-9_]{0,62}$`)
+
+// ValidateSchema checks a schema prefix from a tenant's configuration
+func ValidateSchema(name string) error {
+	if !schemaPattern.MatchString(name) {
+		return fmt.Errorf("schema prefix %q must be lower-case letters, digits and underscores, not starting with a digit, at most 63 characters", name)
+	}
+	return nil
+}
+
+// Schema returns a schema name quoted for use in a query. Valid names mean the same quoted or
+// not; anything else stays a single identifier, so a query built with it fails instead of
+// running what the name holds.
+func Schema(name string) string {
+	return pq.QuoteIdentifier(name)
+}
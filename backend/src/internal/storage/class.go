@@ -0,0 +1,103 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"welltaxpro/src/internal/types"
+)
+
+// Storage classes archived documents are moved to. Both keep objects readable at once, at a
+// lower storage price and a per-read retrieval fee, so archived documents still download.
+const (
+	GCSArchiveClass  = "COLDLINE"
+	GCSStandardClass = "STANDARD"
+	S3ArchiveClass   = "GLACIER_IR"
+	S3StandardClass  = "STANDARD"
+)
+
+// Archiver is implemented by providers that can move objects to a cheaper storage class and back
+type Archiver interface {
+	SetArchived(ctx context.Context, bucket, path string, archived bool) error
+}
+
+// SetArchived moves an object to the provider's archive storage class, or back to its standard
+// class when archived is false
+func SetArchived(ctx context.Context, provider StorageProvider, bucket, path string, archived bool) error {
+	archiver, ok := provider.(Archiver)
+	if !ok {
+		return fmt.Errorf("storage provider cannot change storage classes")
+	}
+	return archiver.SetArchived(ctx, bucket, path, archived)
+}
+
+// SetArchived rewrites a GCS object in place with the new storage class; its metadata is kept
+func (g *GCSProvider) SetArchived(ctx context.Context, bucket, path string, archived bool) error {
+	class := GCSStandardClass
+	if archived {
+		class = GCSArchiveClass
+	}
+
+	object := g.client.Bucket(bucket).Object(path)
+	copier := object.CopierFrom(object)
+	copier.StorageClass = class
+	if _, err := copier.Run(ctx); err != nil {
+		return fmt.Errorf("failed to move gs://%s/%s to %s: %w", bucket, path, class, err)
+	}
+	return nil
+}
+
+// SetArchived copies an S3 object onto itself with the new storage class; its metadata is kept
+func (p *S3Provider) SetArchived(ctx context.Context, bucket, path string, archived bool) error {
+	class := S3StandardClass
+	if archived {
+		class = S3ArchiveClass
+	}
+
+	header := http.Header{}
+	header.Set("X-Amz-Copy-Source", "/"+bucket+"/"+s3EscapePath(path))
+	header.Set("X-Amz-Storage-Class", class)
+	header.Set("X-Amz-Metadata-Directive", "COPY")
+	resp, err := p.do(ctx, http.MethodPut, bucket, path, nil, header, nil)
+	if err != nil {
+		return fmt.Errorf("failed to move s3://%s/%s to %s: %w", bucket, path, class, err)
+	}
+	resp.Body.Close()
+	return nil
+}
+
+// SetArchived records the object as archived; memory has no storage classes
+func (m *MemoryProvider) SetArchived(ctx context.Context, bucket, path string, archived bool) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.objects[bucket+"/"+path]; !ok {
+		return fmt.Errorf("object memory://%s/%s not found", bucket, path)
+	}
+	if archived {
+		m.archived[bucket+"/"+path] = true
+	} else {
+		delete(m.archived, bucket+"/"+path)
+	}
+	return nil
+}
+
+// IsArchived reports whether an object was moved to the archive class
+func (m *MemoryProvider) IsArchived(bucket, path string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.archived[bucket+"/"+path]
+}
+
+// SetArchived rewrites objects with the upload credentials, since it writes a new copy
+func (p *purposeProvider) SetArchived(ctx context.Context, bucket, path string, archived bool) error {
+	provider, err := p.provider(ctx, types.StoragePurposeUpload)
+	if err != nil {
+		return err
+	}
+	return SetArchived(ctx, provider, bucket, path, archived)
+}
+
+// SetArchived rewrites an object in the bucket it is already in, so residency is not checked again
+func (p *residencyProvider) SetArchived(ctx context.Context, bucket, path string, archived bool) error {
+	return SetArchived(ctx, p.StorageProvider, bucket, path, archived)
+}
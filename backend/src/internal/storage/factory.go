@@ -3,31 +3,79 @@ package storage
 import (
 	"context"
 	"fmt"
+	"io"
 	"os"
+	"sync"
+	"time"
 	"welltaxpro/src/internal/secrets"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
 )
 
-// NewStorageProviderForTenant creates a storage provider for a tenant with priority cascade:
-// 1. Try StorageCredentialsSecret (fetch from Secret Manager)
-// 2. Fallback to StorageCredentialsPath (read from file - local dev)
-// 3. Fallback to ADC (Application Default Credentials)
+// NewStorageProviderForTenant creates a storage provider for a tenant. When the tenant has
+// per-purpose credentials, the provider picks the upload, read or delete credentials for each
+// operation, so a leaked upload credential cannot read or delete documents. Credentials are
+// resolved with priority cascade:
+// 1. Try the credentials secret (fetch from Secret Manager)
+// 2. Fallback to the credentials path (read from file - local dev)
+// 3. Fallback to ADC (Application Default Credentials), or the AWS environment variables for S3
+// The smoke tenant's "memory" provider is served from process memory. When the tenant has a
+// storage region, uploads to a bucket outside it are refused. Operations are traced.
 func NewStorageProviderForTenant(ctx context.Context, tc *types.TenantConnection) (StorageProvider, error) {
-	if tc.StorageProvider != "gcs" {
+	switch tc.StorageProvider {
+	case types.SmokeStorageProvider:
+		return Memory, nil
+	case types.StorageProviderGCS, types.StorageProviderS3:
+	default:
 		return nil, fmt.Errorf("unsupported storage provider: %s", tc.StorageProvider)
 	}
 
+	if tc.HasPurposeStorageCredentials() {
+		provider := withResidency(&purposeProvider{tc: tc, providers: map[string]StorageProvider{}}, tc)
+		return withTracing(provider, tc.StorageProvider, tc.TenantID), nil
+	}
+	provider, err := newTenantProvider(ctx, tc, tc.StorageCredentialsSecret, tc.StorageCredentialsPath)
+	if err != nil {
+		return nil, err
+	}
+	return withTracing(withResidency(provider, tc), tc.StorageProvider, tc.TenantID), nil
+}
+
+// newTenantProvider creates a provider of the tenant's kind from the given credentials
+func newTenantProvider(ctx context.Context, tc *types.TenantConnection, credentialsSecret, credentialsPath string) (StorageProvider, error) {
+	if tc.StorageProvider == types.StorageProviderS3 {
+		provider, err := newTenantS3Provider(ctx, tc.TenantID, credentialsSecret, credentialsPath)
+		if err != nil {
+			return nil, err
+		}
+		// AWS only serves a bucket to requests signed for its region, so credentials for another
+		// region cannot reach a bucket in the tenant's
+		if tc.StorageRegion != "" && provider.creds.Endpoint == "" && !types.SameRegion(provider.creds.Region, tc.StorageRegion) {
+			return nil, fmt.Errorf("S3 credentials are for region %s, but tenant %s data must stay in %s", provider.creds.Region, tc.TenantID, tc.StorageRegion)
+		}
+		return provider, nil
+	}
+
+	provider, err := newTenantGCSProvider(ctx, tc.TenantID, credentialsSecret, credentialsPath)
+	if err != nil {
+		return nil, err
+	}
+	return provider, nil
+}
+
+// newTenantGCSProvider creates a GCS provider for a tenant from a credentials secret, then a
+// credentials file, then ADC
+func newTenantGCSProvider(ctx context.Context, tenantID, credentialsSecret, credentialsPath string) (*GCSProvider, error) {
 	// Priority 1: Try Secret Manager (production)
-	if tc.StorageCredentialsSecret != "" {
-		logger.Infof("Attempting to create GCS provider from Secret Manager: %s", tc.StorageCredentialsSecret)
+	if credentialsSecret != "" {
+		logger.Infof("Attempting to create GCS provider from Secret Manager: %s", credentialsSecret)
 
 		secretManager, err := secrets.GetSecretManager(ctx)
 		if err != nil {
 			logger.Warningf("Failed to initialize Secret Manager, falling back: %v", err)
 		} else {
-			secretData, err := secretManager.GetSecret(ctx, tc.StorageCredentialsSecret)
+			secretData, err := secretManager.GetSecret(ctx, credentialsSecret)
 			if err != nil {
 				logger.Warningf("Failed to fetch secret from Secret Manager, falling back: %v", err)
 			} else {
@@ -35,7 +83,7 @@ func NewStorageProviderForTenant(ctx context.Context, tc *types.TenantConnection
 				if err != nil {
 					logger.Warningf("Failed to create GCS client from secret JSON, falling back: %v", err)
 				} else {
-					logger.Infof("Successfully created GCS provider from Secret Manager for tenant %s", tc.TenantID)
+					logger.Infof("Successfully created GCS provider from Secret Manager for tenant %s", tenantID)
 					return provider, nil
 				}
 			}
@@ -43,30 +91,194 @@ func NewStorageProviderForTenant(ctx context.Context, tc *types.TenantConnection
 	}
 
 	// Priority 2: Try file path (local development)
-	if tc.StorageCredentialsPath != "" {
-		logger.Infof("Attempting to create GCS provider from file: %s", tc.StorageCredentialsPath)
+	if credentialsPath != "" {
+		logger.Infof("Attempting to create GCS provider from file: %s", credentialsPath)
 
 		// Check if file exists
-		if _, err := os.Stat(tc.StorageCredentialsPath); err == nil {
-			provider, err := NewGCSProviderFromFile(ctx, tc.StorageCredentialsPath)
+		if _, err := os.Stat(credentialsPath); err == nil {
+			provider, err := NewGCSProviderFromFile(ctx, credentialsPath)
 			if err != nil {
 				logger.Warningf("Failed to create GCS client from file, falling back to ADC: %v", err)
 			} else {
-				logger.Infof("Successfully created GCS provider from file for tenant %s", tc.TenantID)
+				logger.Infof("Successfully created GCS provider from file for tenant %s", tenantID)
 				return provider, nil
 			}
 		} else {
-			logger.Infof("Credentials file not found at %s, falling back to ADC", tc.StorageCredentialsPath)
+			logger.Infof("Credentials file not found at %s, falling back to ADC", credentialsPath)
 		}
 	}
 
 	// Priority 3: Use Application Default Credentials (ADC)
-	logger.Infof("Attempting to create GCS provider using ADC for tenant %s", tc.TenantID)
+	logger.Infof("Attempting to create GCS provider using ADC for tenant %s", tenantID)
 	provider, err := NewGCSProvider(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GCS provider with ADC: %w", err)
 	}
 
-	logger.Infof("Successfully created GCS provider using ADC for tenant %s", tc.TenantID)
+	logger.Infof("Successfully created GCS provider using ADC for tenant %s", tenantID)
+	return provider, nil
+}
+
+// purposeProvider serves each operation with the tenant's credentials for its purpose. Clients
+// are created on first use, so a purpose that is never exercised needs no working credentials,
+// and purposes that resolve to the same credentials share a client.
+type purposeProvider struct {
+	tc        *types.TenantConnection
+	mu        sync.Mutex
+	providers map[string]StorageProvider // By credentials secret and path
+}
+
+// provider returns the client for a purpose's credentials, creating it on first use
+func (p *purposeProvider) provider(ctx context.Context, purpose string) (StorageProvider, error) {
+	secret, path := p.tc.StorageCredentials(purpose)
+	key := secret + "\x00" + path
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if provider, ok := p.providers[key]; ok {
+		return provider, nil
+	}
+
+	logger.Infof("Creating %s storage provider for tenant %s", purpose, p.tc.TenantID)
+	provider, err := newTenantProvider(ctx, p.tc, secret, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s storage provider: %w", purpose, err)
+	}
+	p.providers[key] = provider
+	return provider, nil
+}
+
+func (p *purposeProvider) Upload(ctx context.Context, bucket, path string, file io.Reader, metadata map[string]string) error {
+	provider, err := p.provider(ctx, types.StoragePurposeUpload)
+	if err != nil {
+		return err
+	}
+	return provider.Upload(ctx, bucket, path, file, metadata)
+}
+
+func (p *purposeProvider) Download(ctx context.Context, bucket, path string) (io.ReadCloser, error) {
+	provider, err := p.provider(ctx, types.StoragePurposeRead)
+	if err != nil {
+		return nil, err
+	}
+	return provider.Download(ctx, bucket, path)
+}
+
+func (p *purposeProvider) Delete(ctx context.Context, bucket, path string) error {
+	provider, err := p.provider(ctx, types.StoragePurposeDelete)
+	if err != nil {
+		return err
+	}
+	return provider.Delete(ctx, bucket, path)
+}
+
+// GetSignedURL signs with the read credentials, since the URL grants read access
+func (p *purposeProvider) GetSignedURL(ctx context.Context, bucket, path string, expiration time.Duration) (string, error) {
+	provider, err := p.provider(ctx, types.StoragePurposeRead)
+	if err != nil {
+		return "", err
+	}
+	return provider.GetSignedURL(ctx, bucket, path, expiration)
+}
+
+func (p *purposeProvider) List(ctx context.Context, bucket, prefix string) ([]string, error) {
+	provider, err := p.provider(ctx, types.StoragePurposeRead)
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := provider.(Lister)
+	if !ok {
+		return nil, fmt.Errorf("storage provider %s cannot list objects", p.tc.StorageProvider)
+	}
+	return lister.List(ctx, bucket, prefix)
+}
+
+// NewStorageProvider creates a provider for a platform bucket that belongs to no tenant, such as
+// the audit anchor bucket: "memory", "gcs" with a credentials file or, without one, ADC, or "s3"
+// with a credentials file or, without one, the AWS environment variables
+func NewStorageProvider(ctx context.Context, provider, credentialsPath string) (StorageProvider, error) {
+	switch provider {
+	case types.SmokeStorageProvider:
+		return Memory, nil
+	case types.StorageProviderGCS:
+		var gcsProvider *GCSProvider
+		var err error
+		if credentialsPath != "" {
+			gcsProvider, err = NewGCSProviderFromFile(ctx, credentialsPath)
+		} else {
+			gcsProvider, err = NewGCSProvider(ctx)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return withTracing(gcsProvider, provider, ""), nil
+	case types.StorageProviderS3:
+		s3Provider, err := newS3ProviderFromFileOrEnv(credentialsPath)
+		if err != nil {
+			return nil, err
+		}
+		return withTracing(s3Provider, provider, ""), nil
+	}
+	return nil, fmt.Errorf("unsupported storage provider: %s", provider)
+}
+
+// newTenantS3Provider creates an S3 provider for a tenant from a credentials secret, then a
+// credentials file, then the AWS environment variables
+func newTenantS3Provider(ctx context.Context, tenantID, credentialsSecret, credentialsPath string) (*S3Provider, error) {
+	// Priority 1: Try Secret Manager (production)
+	if credentialsSecret != "" {
+		logger.Infof("Attempting to create S3 provider from Secret Manager: %s", credentialsSecret)
+
+		secretManager, err := secrets.GetSecretManager(ctx)
+		if err != nil {
+			logger.Warningf("Failed to initialize Secret Manager, falling back: %v", err)
+		} else {
+			secretData, err := secretManager.GetSecret(ctx, credentialsSecret)
+			if err != nil {
+				logger.Warningf("Failed to fetch secret from Secret Manager, falling back: %v", err)
+			} else {
+				provider, err := NewS3ProviderFromJSON(secretData)
+				if err != nil {
+					logger.Warningf("Failed to create S3 client from secret JSON, falling back: %v", err)
+				} else {
+					logger.Infof("Successfully created S3 provider from Secret Manager for tenant %s", tenantID)
+					return provider, nil
+				}
+			}
+		}
+	}
+
+	// Priority 2 and 3: file path (local development), then the environment
+	provider, err := newS3ProviderFromFileOrEnv(credentialsPath)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("Successfully created S3 provider for tenant %s", tenantID)
+	return provider, nil
+}
+
+// newS3ProviderFromFileOrEnv creates an S3 provider from a credentials file when it exists,
+// otherwise from the AWS environment variables
+func newS3ProviderFromFileOrEnv(credentialsPath string) (*S3Provider, error) {
+	if credentialsPath != "" {
+		logger.Infof("Attempting to create S3 provider from file: %s", credentialsPath)
+
+		if _, err := os.Stat(credentialsPath); err == nil {
+			provider, err := NewS3ProviderFromFile(credentialsPath)
+			if err != nil {
+				logger.Warningf("Failed to create S3 client from file, falling back to environment: %v", err)
+			} else {
+				return provider, nil
+			}
+		} else {
+			logger.Infof("Credentials file not found at %s, falling back to environment", credentialsPath)
+		}
+	}
+
+	provider, err := NewS3Provider(S3CredentialsFromEnv())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 provider from environment: %w", err)
+	}
+	logger.Info("Created S3 client from environment credentials")
 	return provider, nil
 }
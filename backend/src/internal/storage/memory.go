@@ -0,0 +1,89 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryProvider implements StorageProvider in process memory.
+// It backs the smoke tenant, so smoke tests exercise the storage path without a real bucket.
+type MemoryProvider struct {
+	mu       sync.Mutex
+	objects  map[string][]byte
+	archived map[string]bool // Objects moved to the archive storage class
+}
+
+// Memory is the process-wide memory provider returned for the "memory" storage provider
+var Memory = NewMemoryProvider()
+
+// NewMemoryProvider creates an empty memory provider, separate from Memory
+func NewMemoryProvider() *MemoryProvider {
+	return &MemoryProvider{objects: map[string][]byte{}, archived: map[string]bool{}}
+}
+
+// Upload stores a file in memory; metadata is discarded
+func (m *MemoryProvider) Upload(ctx context.Context, bucket, path string, file io.Reader, metadata map[string]string) error {
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.objects[bucket+"/"+path] = data
+	return nil
+}
+
+// Download returns a copy of a stored file
+func (m *MemoryProvider) Download(ctx context.Context, bucket, path string) (io.ReadCloser, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	data, ok := m.objects[bucket+"/"+path]
+	if !ok {
+		return nil, fmt.Errorf("object memory://%s/%s not found", bucket, path)
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Delete removes a stored file
+func (m *MemoryProvider) Delete(ctx context.Context, bucket, path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.objects[bucket+"/"+path]; !ok {
+		return fmt.Errorf("object memory://%s/%s not found", bucket, path)
+	}
+	delete(m.objects, bucket+"/"+path)
+	delete(m.archived, bucket+"/"+path)
+	return nil
+}
+
+// GetSignedURL returns a memory:// URL; it is only meaningful to this process
+func (m *MemoryProvider) GetSignedURL(ctx context.Context, bucket, path string, expiration time.Duration) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.objects[bucket+"/"+path]; !ok {
+		return "", fmt.Errorf("object memory://%s/%s not found", bucket, path)
+	}
+	return fmt.Sprintf("memory://%s/%s", bucket, path), nil
+}
+
+// List returns the paths of stored files in bucket whose path starts with prefix, sorted
+func (m *MemoryProvider) List(ctx context.Context, bucket, prefix string) ([]string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var paths []string
+	for key := range m.objects {
+		if path, ok := strings.CutPrefix(key, bucket+"/"); ok && strings.HasPrefix(path, prefix) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
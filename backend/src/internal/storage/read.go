@@ -0,0 +1,92 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// ReadFile loads a whole file from a tenant's bucket or, for local development, from disk.
+// GCS locations may be given as gs://{bucket}/{path} or https://storage.googleapis.com/{bucket}/{path},
+// and S3 locations as s3://{bucket}/{path}.
+func ReadFile(ctx context.Context, tc *types.TenantConnection, location string) ([]byte, error) {
+	if !IsStorageURL(location) {
+		logger.Infof("Reading file from local path: %s", location)
+		data, err := os.ReadFile(location)
+		if err != nil {
+			return nil, fmt.Errorf("error reading file: %w", err)
+		}
+		return data, nil
+	}
+
+	bucket, path := ParseStorageURL(location)
+	if bucket == "" || path == "" {
+		return nil, fmt.Errorf("invalid storage URL format: %s", location)
+	}
+
+	provider, err := NewStorageProviderForTenant(ctx, tc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create storage provider: %w", err)
+	}
+
+	reader, err := provider.Download(ctx, bucket, path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download from storage: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read storage file: %w", err)
+	}
+
+	logger.Infof("Read %s/%s from %s storage (%d bytes)", bucket, path, tc.StorageProvider, len(data))
+	return data, nil
+}
+
+// IsStorageURL reports whether a location refers to a bucket rather than a local file
+func IsStorageURL(location string) bool {
+	return IsGCSURL(location) || strings.HasPrefix(location, "s3://")
+}
+
+// ParseStorageURL extracts bucket and path from a GCS or s3://{bucket}/{path} URL
+func ParseStorageURL(location string) (bucket, path string) {
+	if !strings.HasPrefix(location, "s3://") {
+		return ParseGCSURL(location)
+	}
+	parts := strings.SplitN(strings.TrimPrefix(location, "s3://"), "/", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return "", ""
+}
+
+// IsGCSURL reports whether a location refers to Google Cloud Storage
+func IsGCSURL(location string) bool {
+	return strings.HasPrefix(location, "https://storage.googleapis.com/") || strings.HasPrefix(location, "gs://")
+}
+
+// ParseGCSURL extracts bucket and path from GCS URL
+// Supports both https://storage.googleapis.com/{bucket}/{path} and gs://{bucket}/{path}
+func ParseGCSURL(url string) (bucket, path string) {
+	var rest string
+	switch {
+	case strings.HasPrefix(url, "gs://"):
+		rest = strings.TrimPrefix(url, "gs://")
+	case strings.HasPrefix(url, "https://storage.googleapis.com/"):
+		rest = strings.TrimPrefix(url, "https://storage.googleapis.com/")
+	default:
+		return "", ""
+	}
+
+	parts := strings.SplitN(rest, "/", 2)
+	if len(parts) == 2 {
+		return parts[0], parts[1]
+	}
+	return "", ""
+}
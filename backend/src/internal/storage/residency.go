@@ -0,0 +1,127 @@
+package storage
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// Locator is implemented by providers that can report where a bucket keeps its data
+type Locator interface {
+	BucketLocation(ctx context.Context, bucket string) (string, error)
+}
+
+// BucketLocation returns the region or multi-region of a bucket as its provider reports it
+func BucketLocation(ctx context.Context, provider StorageProvider, bucket string) (string, error) {
+	locator, ok := provider.(Locator)
+	if !ok {
+		return "", fmt.Errorf("storage provider cannot report bucket locations")
+	}
+	return locator.BucketLocation(ctx, bucket)
+}
+
+// BucketLocation returns the location of a GCS bucket, such as US-EAST1 or EU
+func (g *GCSProvider) BucketLocation(ctx context.Context, bucket string) (string, error) {
+	attrs, err := g.client.Bucket(bucket).Attrs(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get location of gs://%s: %w", bucket, err)
+	}
+	return attrs.Location, nil
+}
+
+// BucketLocation returns the region of an S3 bucket. S3 reports buckets in us-east-1 with an
+// empty location.
+func (p *S3Provider) BucketLocation(ctx context.Context, bucket string) (string, error) {
+	resp, err := p.do(ctx, http.MethodGet, bucket, "", url.Values{"location": {""}}, nil, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to get location of s3://%s: %w", bucket, err)
+	}
+	defer resp.Body.Close()
+
+	var location struct {
+		Region string `xml:",chardata"`
+	}
+	if err := xml.NewDecoder(resp.Body).Decode(&location); err != nil {
+		return "", fmt.Errorf("failed to parse location of s3://%s: %w", bucket, err)
+	}
+	if region := strings.TrimSpace(location.Region); region != "" {
+		return region, nil
+	}
+	return "us-east-1", nil
+}
+
+// BucketLocation asks with the read credentials, which are the likeliest to see bucket metadata
+func (p *purposeProvider) BucketLocation(ctx context.Context, bucket string) (string, error) {
+	provider, err := p.provider(ctx, types.StoragePurposeRead)
+	if err != nil {
+		return "", err
+	}
+	return BucketLocation(ctx, provider, bucket)
+}
+
+// bucketLocations caches bucket locations by tenant, provider and bucket; a bucket cannot move
+// once created, so entries never expire
+var bucketLocations sync.Map
+
+// residencyProvider refuses uploads to a bucket outside the tenant's storage region, so new
+// documents cannot land elsewhere. Reads and deletes are left alone, so data already in the
+// wrong place can still be moved out. The bucket's location is looked up on the first upload;
+// when it cannot be, uploads are refused rather than risk it.
+type residencyProvider struct {
+	StorageProvider
+	tc *types.TenantConnection
+}
+
+// withResidency wraps a tenant's provider in a residencyProvider when the tenant has a storage region
+func withResidency(provider StorageProvider, tc *types.TenantConnection) StorageProvider {
+	if tc.StorageRegion == "" {
+		return provider
+	}
+	return &residencyProvider{StorageProvider: provider, tc: tc}
+}
+
+func (p *residencyProvider) Upload(ctx context.Context, bucket, path string, file io.Reader, metadata map[string]string) error {
+	location, err := p.BucketLocation(ctx, bucket)
+	if err != nil {
+		return fmt.Errorf("cannot verify that bucket %s is in region %s: %w", bucket, p.tc.StorageRegion, err)
+	}
+	if !types.SameRegion(location, p.tc.StorageRegion) {
+		logger.Errorf("Refused upload of tenant %s to bucket %s in %s; its data must stay in %s", p.tc.TenantID, bucket, location, p.tc.StorageRegion)
+		return fmt.Errorf("bucket %s is in %s, but tenant %s data must stay in %s", bucket, location, p.tc.TenantID, p.tc.StorageRegion)
+	}
+	return p.StorageProvider.Upload(ctx, bucket, path, file, metadata)
+}
+
+// BucketLocation returns the bucket's location, looking it up once
+func (p *residencyProvider) BucketLocation(ctx context.Context, bucket string) (string, error) {
+	key := p.tc.TenantID + "\x00" + p.tc.StorageProvider + "\x00" + bucket
+	if location, ok := bucketLocations.Load(key); ok {
+		return location.(string), nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+	location, err := BucketLocation(ctx, p.StorageProvider, bucket)
+	if err != nil {
+		return "", err
+	}
+	bucketLocations.Store(key, location)
+	return location, nil
+}
+
+func (p *residencyProvider) List(ctx context.Context, bucket, prefix string) ([]string, error) {
+	lister, ok := p.StorageProvider.(Lister)
+	if !ok {
+		return nil, fmt.Errorf("storage provider %s cannot list objects", p.tc.StorageProvider)
+	}
+	return lister.List(ctx, bucket, prefix)
+}
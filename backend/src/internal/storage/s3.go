@@ -0,0 +1,427 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/logger"
+)
+
+const (
+	// s3MaxPresignExpiry is the longest a SigV4 presigned URL may stay valid
+	s3MaxPresignExpiry = 7 * 24 * time.Hour
+	// s3UnsignedPayload is signed in place of the body hash for presigned URLs
+	s3UnsignedPayload = "UNSIGNED-PAYLOAD"
+	// s3MaxErrorBody is how much of an error response is read for its code and message
+	s3MaxErrorBody = 64 << 10
+)
+
+// S3Credentials are the access keys and location of an S3-compatible bucket, as stored in a
+// tenant's credentials secret or file:
+//
+//	{"accessKeyId": "...", "secretAccessKey": "...", "region": "us-east-1"}
+//
+// Endpoint and UsePathStyle are only needed for S3-compatible services other than AWS.
+type S3Credentials struct {
+	AccessKeyID     string `json:"accessKeyId"`
+	SecretAccessKey string `json:"secretAccessKey"`
+	SessionToken    string `json:"sessionToken,omitempty"` // Temporary credentials only
+	Region          string `json:"region"`
+	Endpoint        string `json:"endpoint,omitempty"`     // e.g. https://ACCOUNT.r2.cloudflarestorage.com; AWS when empty
+	UsePathStyle    bool   `json:"usePathStyle,omitempty"` // Address buckets as {endpoint}/{bucket} instead of {bucket}.{host}
+}
+
+// S3CredentialsFromEnv reads credentials from the standard AWS environment variables
+func S3CredentialsFromEnv() *S3Credentials {
+	creds := &S3Credentials{
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+		Region:          os.Getenv("AWS_REGION"),
+		Endpoint:        os.Getenv("AWS_ENDPOINT_URL_S3"),
+	}
+	if creds.Region == "" {
+		creds.Region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	if creds.Endpoint == "" {
+		creds.Endpoint = os.Getenv("AWS_ENDPOINT_URL")
+	}
+	creds.UsePathStyle, _ = strconv.ParseBool(os.Getenv("AWS_S3_USE_PATH_STYLE"))
+	return creds
+}
+
+// S3Provider implements StorageProvider for Amazon S3 and S3-compatible services, signing
+// requests with AWS Signature Version 4
+type S3Provider struct {
+	creds    S3Credentials
+	endpoint *url.URL
+	client   *http.Client
+}
+
+// NewS3Provider creates an S3 storage provider from credentials
+func NewS3Provider(creds *S3Credentials) (*S3Provider, error) {
+	if creds.AccessKeyID == "" || creds.SecretAccessKey == "" {
+		return nil, fmt.Errorf("S3 credentials need an accessKeyId and secretAccessKey")
+	}
+	if creds.Region == "" {
+		return nil, fmt.Errorf("S3 credentials need a region")
+	}
+
+	raw := creds.Endpoint
+	if raw == "" {
+		raw = fmt.Sprintf("https://s3.%s.amazonaws.com", creds.Region)
+	}
+	endpoint, err := url.Parse(raw)
+	if err != nil || endpoint.Host == "" || (endpoint.Scheme != "https" && endpoint.Scheme != "http") {
+		return nil, fmt.Errorf("invalid S3 endpoint: %s", raw)
+	}
+
+	return &S3Provider{
+		creds:    *creds,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: 5 * time.Minute},
+	}, nil
+}
+
+// NewS3ProviderFromJSON creates an S3 storage provider from S3Credentials JSON
+func NewS3ProviderFromJSON(jsonData []byte) (*S3Provider, error) {
+	var creds S3Credentials
+	if err := json.Unmarshal(jsonData, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse S3 credentials: %w", err)
+	}
+	if creds.Region == "" {
+		creds.Region = S3CredentialsFromEnv().Region
+	}
+
+	provider, err := NewS3Provider(&creds)
+	if err != nil {
+		return nil, err
+	}
+	logger.Info("Created S3 client from JSON credentials")
+	return provider, nil
+}
+
+// NewS3ProviderFromFile creates an S3 storage provider from an S3Credentials JSON file
+func NewS3ProviderFromFile(credentialsPath string) (*S3Provider, error) {
+	data, err := os.ReadFile(credentialsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read S3 credentials file: %w", err)
+	}
+
+	provider, err := NewS3ProviderFromJSON(data)
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("Created S3 client from file: %s", credentialsPath)
+	return provider, nil
+}
+
+// Upload uploads a file to S3. The file is buffered so its length and hash can be signed.
+func (p *S3Provider) Upload(ctx context.Context, bucket, path string, file io.Reader, metadata map[string]string) error {
+	logger.Infof("Uploading file to s3://%s/%s", bucket, path)
+
+	body, err := io.ReadAll(file)
+	if err != nil {
+		return fmt.Errorf("failed to read upload: %w", err)
+	}
+
+	header := http.Header{}
+	header.Set("Content-Type", "application/octet-stream")
+	for key, value := range metadata {
+		header.Set("X-Amz-Meta-"+key, value)
+	}
+
+	resp, err := p.do(ctx, http.MethodPut, bucket, path, nil, header, body)
+	if err != nil {
+		return fmt.Errorf("failed to write to S3: %w", err)
+	}
+	resp.Body.Close()
+
+	logger.Infof("Successfully uploaded file to s3://%s/%s", bucket, path)
+	return nil
+}
+
+// Download retrieves a file from S3
+func (p *S3Provider) Download(ctx context.Context, bucket, path string) (io.ReadCloser, error) {
+	logger.Infof("Downloading file from s3://%s/%s", bucket, path)
+
+	resp, err := p.do(ctx, http.MethodGet, bucket, path, nil, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read from S3: %w", err)
+	}
+	return resp.Body, nil
+}
+
+// Delete removes a file from S3
+func (p *S3Provider) Delete(ctx context.Context, bucket, path string) error {
+	logger.Infof("Deleting file from s3://%s/%s", bucket, path)
+
+	resp, err := p.do(ctx, http.MethodDelete, bucket, path, nil, nil, nil)
+	if err != nil {
+		return fmt.Errorf("failed to delete from S3: %w", err)
+	}
+	resp.Body.Close()
+
+	logger.Infof("Successfully deleted file from s3://%s/%s", bucket, path)
+	return nil
+}
+
+// s3ListResult is the part of a ListObjectsV2 response the provider reads
+type s3ListResult struct {
+	Contents []struct {
+		Key  string `xml:"Key"`
+		Size int64  `xml:"Size"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List returns the paths of every object in bucket whose path starts with prefix
+func (p *S3Provider) List(ctx context.Context, bucket, prefix string) ([]string, error) {
+	var paths []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		page, err := p.listPage(ctx, bucket, prefix, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			paths = append(paths, object.Key)
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return paths, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// listPage requests one page of a ListObjectsV2 listing
+func (p *S3Provider) listPage(ctx context.Context, bucket, prefix string, query url.Values) (*s3ListResult, error) {
+	resp, err := p.do(ctx, http.MethodGet, bucket, "", query, nil, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list s3://%s/%s: %w", bucket, prefix, err)
+	}
+	defer resp.Body.Close()
+
+	page := &s3ListResult{}
+	if err := xml.NewDecoder(resp.Body).Decode(page); err != nil {
+		return nil, fmt.Errorf("failed to parse listing of s3://%s/%s: %w", bucket, prefix, err)
+	}
+	return page, nil
+}
+
+// GetSignedURL generates a presigned URL for temporary access to a file
+func (p *S3Provider) GetSignedURL(ctx context.Context, bucket, path string, expiration time.Duration) (string, error) {
+	logger.Infof("Generating signed URL for s3://%s/%s (expires in %v)", bucket, path, expiration)
+
+	if expiration <= 0 || expiration > s3MaxPresignExpiry {
+		return "", fmt.Errorf("S3 signed URLs must expire within %v", s3MaxPresignExpiry)
+	}
+
+	now := time.Now().UTC()
+	target := p.objectURL(bucket, path)
+	query := url.Values{
+		"X-Amz-Algorithm":     {"AWS4-HMAC-SHA256"},
+		"X-Amz-Credential":    {p.creds.AccessKeyID + "/" + p.scope(now)},
+		"X-Amz-Date":          {now.Format("20060102T150405Z")},
+		"X-Amz-Expires":       {strconv.Itoa(int(expiration.Seconds()))},
+		"X-Amz-SignedHeaders": {"host"},
+	}
+	if p.creds.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", p.creds.SessionToken)
+	}
+	target.RawQuery = s3CanonicalQuery(query)
+
+	header := http.Header{}
+	header.Set("Host", target.Host)
+	signature := p.signature(now, http.MethodGet, target, header, []string{"host"}, s3UnsignedPayload)
+	target.RawQuery += "&X-Amz-Signature=" + signature
+
+	return target.String(), nil
+}
+
+// do sends a signed request and returns the response, turning any status outside 2xx into an
+// error carrying S3's error code and message
+func (p *S3Provider) do(ctx context.Context, method, bucket, path string, query url.Values, header http.Header, body []byte) (*http.Response, error) {
+	now := time.Now().UTC()
+	target := p.objectURL(bucket, path)
+	target.RawQuery = s3CanonicalQuery(query)
+
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target.String(), reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request: %w", err)
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Host", target.Host)
+	req.Header.Set("X-Amz-Date", now.Format("20060102T150405Z"))
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if p.creds.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", p.creds.SessionToken)
+	}
+	var signed []string
+	for key := range req.Header {
+		signed = append(signed, strings.ToLower(key))
+	}
+	sort.Strings(signed)
+	signature := p.signature(now, method, target, req.Header, signed, payloadHash)
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.creds.AccessKeyID, p.scope(now), strings.Join(signed, ";"), signature))
+	req.Header.Del("Host") // net/http sends req.Host
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer resp.Body.Close()
+		var s3Err struct {
+			Code    string `xml:"Code"`
+			Message string `xml:"Message"`
+		}
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, s3MaxErrorBody))
+		if xml.Unmarshal(raw, &s3Err) == nil && s3Err.Code != "" {
+			return nil, fmt.Errorf("S3 returned %d %s: %s", resp.StatusCode, s3Err.Code, s3Err.Message)
+		}
+		return nil, fmt.Errorf("S3 returned %d", resp.StatusCode)
+	}
+	return resp, nil
+}
+
+// objectURL addresses an object, or the bucket itself when path is empty
+func (p *S3Provider) objectURL(bucket, path string) *url.URL {
+	target := &url.URL{Scheme: p.endpoint.Scheme, Host: p.endpoint.Host}
+	basePath := strings.TrimSuffix(p.endpoint.Path, "/")
+
+	// Buckets with dots break the TLS wildcard certificate of virtual-hosted addresses
+	if p.creds.UsePathStyle || strings.Contains(bucket, ".") {
+		basePath += "/" + bucket
+	} else {
+		target.Host = bucket + "." + target.Host
+	}
+
+	if path == "" && basePath != "" {
+		target.Path = basePath
+		target.RawPath = s3EscapePath(basePath)
+		return target
+	}
+	target.Path = basePath + "/" + path
+	target.RawPath = s3EscapePath(basePath) + "/" + s3EscapePath(path)
+	return target
+}
+
+// scope is the credential scope of a request signed at t
+func (p *S3Provider) scope(t time.Time) string {
+	return t.Format("20060102") + "/" + p.creds.Region + "/s3/aws4_request"
+}
+
+// signature computes the SigV4 signature of a request whose query is already canonical
+func (p *S3Provider) signature(t time.Time, method string, target *url.URL, header http.Header, signedHeaders []string, payloadHash string) string {
+	var canonicalHeaders strings.Builder
+	for _, name := range signedHeaders {
+		value := header.Get(name)
+		if name == "host" {
+			value = target.Host
+		}
+		canonicalHeaders.WriteString(name + ":" + strings.Join(strings.Fields(value), " ") + "\n")
+	}
+
+	canonicalRequest := strings.Join([]string{
+		method,
+		target.EscapedPath(),
+		target.RawQuery,
+		canonicalHeaders.String(),
+		strings.Join(signedHeaders, ";"),
+		payloadHash,
+	}, "\n")
+	requestHash := sha256.Sum256([]byte(canonicalRequest))
+
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		t.Format("20060102T150405Z"),
+		p.scope(t),
+		hex.EncodeToString(requestHash[:]),
+	}, "\n")
+
+	key := s3HMAC([]byte("AWS4"+p.creds.SecretAccessKey), t.Format("20060102"))
+	key = s3HMAC(key, p.creds.Region)
+	key = s3HMAC(key, "s3")
+	key = s3HMAC(key, "aws4_request")
+	return hex.EncodeToString(s3HMAC(key, stringToSign))
+}
+
+func s3HMAC(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Escape percent-encodes everything but the characters SigV4 leaves unreserved
+func s3Escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('A' <= c && c <= 'Z') || ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') ||
+			c == '-' || c == '_' || c == '.' || c == '~' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+	return b.String()
+}
+
+// s3EscapePath escapes each segment of an object path, keeping the slashes
+func s3EscapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = s3Escape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// s3CanonicalQuery encodes a query sorted by key, as SigV4 signs it
+func s3CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var pairs []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			pairs = append(pairs, s3Escape(key)+"="+s3Escape(value))
+		}
+	}
+	return strings.Join(pairs, "&")
+}
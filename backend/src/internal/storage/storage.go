@@ -8,6 +8,7 @@ import (
 
 	"cloud.google.com/go/storage"
 	"github.com/google/logger"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
@@ -19,6 +20,11 @@ type StorageProvider interface {
 	GetSignedURL(ctx context.Context, bucket, path string, expiration time.Duration) (string, error)
 }
 
+// Lister is implemented by providers that can enumerate a bucket, such as for document drops
+type Lister interface {
+	List(ctx context.Context, bucket, prefix string) ([]string, error)
+}
+
 // GCSProvider implements StorageProvider for Google Cloud Storage
 type GCSProvider struct {
 	client *storage.Client
@@ -105,6 +111,24 @@ func (g *GCSProvider) Delete(ctx context.Context, bucket, path string) error {
 	return nil
 }
 
+// List returns the paths of every object in bucket whose path starts with prefix
+func (g *GCSProvider) List(ctx context.Context, bucket, prefix string) ([]string, error) {
+	it := g.client.Bucket(bucket).Objects(ctx, &storage.Query{Prefix: prefix})
+
+	var paths []string
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list gs://%s/%s: %w", bucket, prefix, err)
+		}
+		paths = append(paths, attrs.Name)
+	}
+	return paths, nil
+}
+
 // GetSignedURL generates a signed URL for temporary access to a file
 func (g *GCSProvider) GetSignedURL(ctx context.Context, bucket, path string, expiration time.Duration) (string, error) {
 	logger.Infof("Generating signed URL for gs://%s/%s (expires in %v)", bucket, path, expiration)
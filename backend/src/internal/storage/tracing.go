@@ -0,0 +1,98 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+	"welltaxpro/src/internal/tracing"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracedProvider traces a provider's operations with the bucket they use. Object paths are left
+// out, since they can hold client names.
+type tracedProvider struct {
+	StorageProvider
+	kind     string // types.StorageProvider*
+	tenantID string // Empty for platform buckets
+}
+
+// withTracing wraps a provider in a tracedProvider
+func withTracing(provider StorageProvider, kind, tenantID string) StorageProvider {
+	return &tracedProvider{StorageProvider: provider, kind: kind, tenantID: tenantID}
+}
+
+// start starts the span of an operation on bucket
+func (p *tracedProvider) start(ctx context.Context, operation, bucket string) (context.Context, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("storage.provider", p.kind),
+		attribute.String("storage.bucket", bucket),
+	}
+	if p.tenantID != "" {
+		attrs = append(attrs, tracing.Tenant(p.tenantID))
+	}
+	return tracing.StartClient(ctx, "storage."+operation, attrs...)
+}
+
+func (p *tracedProvider) Upload(ctx context.Context, bucket, path string, file io.Reader, metadata map[string]string) error {
+	ctx, span := p.start(ctx, "Upload", bucket)
+	err := p.StorageProvider.Upload(ctx, bucket, path, file, metadata)
+	tracing.End(span, err)
+	return err
+}
+
+// Download traces the request for an object; reading its body is left to the caller
+func (p *tracedProvider) Download(ctx context.Context, bucket, path string) (io.ReadCloser, error) {
+	ctx, span := p.start(ctx, "Download", bucket)
+	rc, err := p.StorageProvider.Download(ctx, bucket, path)
+	tracing.End(span, err)
+	return rc, err
+}
+
+func (p *tracedProvider) Delete(ctx context.Context, bucket, path string) error {
+	ctx, span := p.start(ctx, "Delete", bucket)
+	err := p.StorageProvider.Delete(ctx, bucket, path)
+	tracing.End(span, err)
+	return err
+}
+
+func (p *tracedProvider) GetSignedURL(ctx context.Context, bucket, path string, expiration time.Duration) (string, error) {
+	ctx, span := p.start(ctx, "GetSignedURL", bucket)
+	signedURL, err := p.StorageProvider.GetSignedURL(ctx, bucket, path, expiration)
+	tracing.End(span, err)
+	return signedURL, err
+}
+
+func (p *tracedProvider) List(ctx context.Context, bucket, prefix string) ([]string, error) {
+	lister, ok := p.StorageProvider.(Lister)
+	if !ok {
+		return nil, fmt.Errorf("storage provider %s cannot list objects", p.kind)
+	}
+	ctx, span := p.start(ctx, "List", bucket)
+	paths, err := lister.List(ctx, bucket, prefix)
+	tracing.End(span, err)
+	return paths, err
+}
+
+func (p *tracedProvider) BucketLocation(ctx context.Context, bucket string) (string, error) {
+	ctx, span := p.start(ctx, "BucketLocation", bucket)
+	location, err := BucketLocation(ctx, p.StorageProvider, bucket)
+	tracing.End(span, err)
+	return location, err
+}
+
+func (p *tracedProvider) SetArchived(ctx context.Context, bucket, path string, archived bool) error {
+	ctx, span := p.start(ctx, "SetArchived", bucket)
+	err := SetArchived(ctx, p.StorageProvider, bucket, path, archived)
+	tracing.End(span, err)
+	return err
+}
+
+func (p *tracedProvider) Usage(ctx context.Context, bucket, prefix string) (*Usage, error) {
+	ctx, span := p.start(ctx, "Usage", bucket)
+	usage, err := BucketUsage(ctx, p.StorageProvider, bucket, prefix)
+	tracing.End(span, err)
+	return usage, err
+}
@@ -0,0 +1,108 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"welltaxpro/src/internal/types"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/iterator"
+)
+
+// Usage is how much a bucket, or the part of it under a prefix, holds
+type Usage struct {
+	Objects int
+	Bytes   int64
+}
+
+// Measurer is implemented by providers that can total the objects in a bucket
+type Measurer interface {
+	Usage(ctx context.Context, bucket, prefix string) (*Usage, error)
+}
+
+// BucketUsage totals the objects in bucket whose path starts with prefix
+func BucketUsage(ctx context.Context, provider StorageProvider, bucket, prefix string) (*Usage, error) {
+	measurer, ok := provider.(Measurer)
+	if !ok {
+		return nil, fmt.Errorf("storage provider cannot measure buckets")
+	}
+	return measurer.Usage(ctx, bucket, prefix)
+}
+
+// Usage totals a GCS bucket's objects from its listing
+func (g *GCSProvider) Usage(ctx context.Context, bucket, prefix string) (*Usage, error) {
+	query := &storage.Query{Prefix: prefix}
+	if err := query.SetAttrSelection([]string{"Size"}); err != nil {
+		return nil, err
+	}
+	it := g.client.Bucket(bucket).Objects(ctx, query)
+
+	usage := &Usage{}
+	for {
+		attrs, err := it.Next()
+		if err == iterator.Done {
+			return usage, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to list gs://%s/%s: %w", bucket, prefix, err)
+		}
+		usage.Objects++
+		usage.Bytes += attrs.Size
+	}
+}
+
+// Usage totals an S3 bucket's objects from its listing
+func (p *S3Provider) Usage(ctx context.Context, bucket, prefix string) (*Usage, error) {
+	usage := &Usage{}
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		page, err := p.listPage(ctx, bucket, prefix, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, object := range page.Contents {
+			usage.Objects++
+			usage.Bytes += object.Size
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return usage, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// Usage totals the stored files in bucket
+func (m *MemoryProvider) Usage(ctx context.Context, bucket, prefix string) (*Usage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	usage := &Usage{}
+	for key, data := range m.objects {
+		if path, ok := strings.CutPrefix(key, bucket+"/"); ok && strings.HasPrefix(path, prefix) {
+			usage.Objects++
+			usage.Bytes += int64(len(data))
+		}
+	}
+	return usage, nil
+}
+
+// Usage lists with the read credentials
+func (p *purposeProvider) Usage(ctx context.Context, bucket, prefix string) (*Usage, error) {
+	provider, err := p.provider(ctx, types.StoragePurposeRead)
+	if err != nil {
+		return nil, err
+	}
+	return BucketUsage(ctx, provider, bucket, prefix)
+}
+
+// Usage only reads the bucket, so residency is not checked
+func (p *residencyProvider) Usage(ctx context.Context, bucket, prefix string) (*Usage, error) {
+	return BucketUsage(ctx, p.StorageProvider, bucket, prefix)
+}
@@ -0,0 +1,128 @@
+package store
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// GetAccessMatrix returns every employee with their tenant grants and last activity per tenant
+func (s *Store) GetAccessMatrix(filter types.AccessMatrixFilter) (*types.AccessMatrix, error) {
+	var conditions []string
+	var args []interface{}
+
+	addArg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if !filter.IncludeInactive {
+		conditions = append(conditions, "e.is_active = true", "(eta.id IS NULL OR eta.is_active = true)")
+	}
+	if filter.Search != "" {
+		p := addArg("%" + filter.Search + "%")
+		conditions = append(conditions, fmt.Sprintf("(e.email ILIKE %s OR e.first_name ILIKE %s OR e.last_name ILIKE %s)", p, p, p))
+	}
+	if filter.TenantID != "" {
+		conditions = append(conditions, "eta.tenant_id = "+addArg(filter.TenantID))
+	}
+	if filter.Role != "" {
+		conditions = append(conditions, "eta.role = "+addArg(filter.Role))
+	}
+	if filter.StaleDays > 0 {
+		conditions = append(conditions, fmt.Sprintf(
+			"eta.id IS NOT NULL AND (activity.last_at IS NULL OR activity.last_at < NOW() - make_interval(days => %s))",
+			addArg(filter.StaleDays),
+		))
+	}
+
+	query := `
+		SELECT e.id, e.firebase_uid, e.email, e.first_name, e.last_name, e.role, e.is_active, e.created_at, e.updated_at,
+		       eta.tenant_id, tc.tenant_name, eta.role, eta.is_active, eta.created_at, activity.last_at
+		FROM employees e
+		LEFT JOIN employee_tenant_access eta ON eta.employee_id = e.id
+		LEFT JOIN tenant_connections tc ON tc.tenant_id = eta.tenant_id
+		LEFT JOIN LATERAL (
+			SELECT MAX(al.created_at) AS last_at
+			FROM audit_logs al
+			WHERE al.employee_id = e.id AND al.tenant_id = eta.tenant_id
+		) activity ON true
+	`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY e.email, tc.tenant_name"
+
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		logger.Errorf("Failed to query access matrix: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	matrix := &types.AccessMatrix{
+		Tenants:     []types.AccessMatrixTenant{},
+		Employees:   []*types.AccessMatrixRow{},
+		GeneratedAt: time.Now(),
+	}
+	byEmployee := map[uuid.UUID]*types.AccessMatrixRow{}
+	seenTenants := map[string]bool{}
+
+	for rows.Next() {
+		var e types.Employee
+		var tenantID, tenantName, role *string
+		var grantActive *bool
+		var grantedAt, lastActivity *time.Time
+
+		err := rows.Scan(
+			&e.ID, &e.FirebaseUID, &e.Email, &e.FirstName, &e.LastName, &e.Role, &e.IsActive, &e.CreatedAt, &e.UpdatedAt,
+			&tenantID, &tenantName, &role, &grantActive, &grantedAt, &lastActivity,
+		)
+		if err != nil {
+			logger.Errorf("Failed to scan access matrix row: %v", err)
+			return nil, err
+		}
+
+		row, ok := byEmployee[e.ID]
+		if !ok {
+			row = &types.AccessMatrixRow{Employee: e, Capabilities: types.RoleCapabilities(e.Role), Grants: []*types.AccessMatrixGrant{}}
+			byEmployee[e.ID] = row
+			matrix.Employees = append(matrix.Employees, row)
+		}
+
+		// Employees without any tenant grant come back with a NULL grant
+		if tenantID == nil {
+			continue
+		}
+
+		grant := &types.AccessMatrixGrant{
+			TenantID:       *tenantID,
+			Role:           *role,
+			Capabilities:   types.RoleCapabilities(*role),
+			IsActive:       *grantActive,
+			GrantedAt:      *grantedAt,
+			LastActivityAt: lastActivity,
+		}
+		if tenantName != nil {
+			grant.TenantName = *tenantName
+		}
+		row.Grants = append(row.Grants, grant)
+
+		if !seenTenants[grant.TenantID] {
+			seenTenants[grant.TenantID] = true
+			matrix.Tenants = append(matrix.Tenants, types.AccessMatrixTenant{
+				TenantID:   grant.TenantID,
+				TenantName: grant.TenantName,
+			})
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return matrix, nil
+}
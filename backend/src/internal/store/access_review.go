@@ -0,0 +1,249 @@
+package store
+
+import (
+	"database/sql"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// CreateAccessReview starts a review campaign with one line item per active tenant grant
+func (s *Store) CreateAccessReview(name string, createdBy uuid.UUID) (*types.AccessReview, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	review := &types.AccessReview{
+		Name:      name,
+		Status:    types.AccessReviewStatusOpen,
+		CreatedBy: createdBy,
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO access_reviews (name, created_by)
+		VALUES ($1, $2)
+		RETURNING id, created_at
+	`, name, createdBy).Scan(&review.ID, &review.CreatedAt)
+	if err != nil {
+		logger.Errorf("Failed to create access review: %v", err)
+		return nil, err
+	}
+
+	// Snapshot every active grant of every active employee
+	result, err := tx.Exec(`
+		INSERT INTO access_review_items (review_id, access_id, employee_id, employee_email, tenant_id, role)
+		SELECT $1, eta.id, eta.employee_id, e.email, eta.tenant_id, eta.role
+		FROM employee_tenant_access eta
+		JOIN employees e ON e.id = eta.employee_id
+		WHERE eta.is_active = true AND e.is_active = true
+	`, review.ID)
+	if err != nil {
+		logger.Errorf("Failed to generate access review items: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	count, _ := result.RowsAffected()
+	review.Summary = types.AccessReviewCounts{Total: int(count), Pending: int(count)}
+
+	logger.Infof("Started access review %s with %d line items", review.ID, count)
+	return review, nil
+}
+
+// GetAccessReviews lists review campaigns with decision counts, newest first
+func (s *Store) GetAccessReviews() ([]*types.AccessReview, error) {
+	query := `
+		SELECT r.id, r.name, r.status, r.created_by, r.created_at, r.completed_at,
+		       COUNT(i.id),
+		       COUNT(i.id) FILTER (WHERE i.decision = 'PENDING'),
+		       COUNT(i.id) FILTER (WHERE i.decision = 'ATTESTED'),
+		       COUNT(i.id) FILTER (WHERE i.decision = 'REVOKED')
+		FROM access_reviews r
+		LEFT JOIN access_review_items i ON i.review_id = r.id
+		GROUP BY r.id
+		ORDER BY r.created_at DESC
+	`
+
+	rows, err := s.DB.Query(query)
+	if err != nil {
+		logger.Errorf("Failed to query access reviews: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*types.AccessReview
+	for rows.Next() {
+		r := &types.AccessReview{}
+		err := rows.Scan(
+			&r.ID, &r.Name, &r.Status, &r.CreatedBy, &r.CreatedAt, &r.CompletedAt,
+			&r.Summary.Total, &r.Summary.Pending, &r.Summary.Attested, &r.Summary.Revoked,
+		)
+		if err != nil {
+			logger.Errorf("Failed to scan access review: %v", err)
+			return nil, err
+		}
+		reviews = append(reviews, r)
+	}
+
+	return reviews, rows.Err()
+}
+
+// GetAccessReview retrieves a review campaign with all of its line items
+func (s *Store) GetAccessReview(reviewID uuid.UUID) (*types.AccessReview, error) {
+	review := &types.AccessReview{}
+	err := s.DB.QueryRow(`
+		SELECT id, name, status, created_by, created_at, completed_at
+		FROM access_reviews
+		WHERE id = $1
+	`, reviewID).Scan(&review.ID, &review.Name, &review.Status, &review.CreatedBy, &review.CreatedAt, &review.CompletedAt)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("access review not found with ID: %s", reviewID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get access review %s: %v", reviewID, err)
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT id, review_id, access_id, employee_id, employee_email, tenant_id, role,
+		       decision, reviewed_by, reviewed_at, comment
+		FROM access_review_items
+		WHERE review_id = $1
+		ORDER BY employee_email, tenant_id
+	`, reviewID)
+	if err != nil {
+		logger.Errorf("Failed to query access review items for %s: %v", reviewID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	review.Items = []*types.AccessReviewItem{}
+	for rows.Next() {
+		item := &types.AccessReviewItem{}
+		err := rows.Scan(
+			&item.ID, &item.ReviewID, &item.AccessID, &item.EmployeeID, &item.EmployeeEmail, &item.TenantID, &item.Role,
+			&item.Decision, &item.ReviewedBy, &item.ReviewedAt, &item.Comment,
+		)
+		if err != nil {
+			logger.Errorf("Failed to scan access review item: %v", err)
+			return nil, err
+		}
+		review.Items = append(review.Items, item)
+
+		review.Summary.Total++
+		switch item.Decision {
+		case types.AccessReviewDecisionAttested:
+			review.Summary.Attested++
+		case types.AccessReviewDecisionRevoked:
+			review.Summary.Revoked++
+		default:
+			review.Summary.Pending++
+		}
+	}
+
+	return review, rows.Err()
+}
+
+// DecideAccessReviewItem records a reviewer's decision on a pending line item of an open review.
+// The item and its review are locked while their status is checked, and a REVOKED decision
+// deactivates the underlying tenant grant in the same transaction. Items already decided are
+// a conflict, so a revocation cannot be turned into an attestation.
+func (s *Store) DecideAccessReviewItem(reviewID, itemID uuid.UUID, decision string, reviewerID uuid.UUID, comment *string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var (
+		accessID     *uuid.UUID
+		employeeID   uuid.UUID
+		current      string
+		reviewStatus string
+	)
+	err = tx.QueryRow(`
+		SELECT i.access_id, i.employee_id, i.decision, r.status
+		FROM access_review_items i
+		JOIN access_reviews r ON r.id = i.review_id
+		WHERE i.id = $1 AND i.review_id = $2
+		FOR UPDATE
+	`, itemID, reviewID).Scan(&accessID, &employeeID, &current, &reviewStatus)
+	if err == sql.ErrNoRows {
+		return apperr.NotFound("access review item not found with ID: %s", itemID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to lock access review item %s: %v", itemID, err)
+		return err
+	}
+
+	if reviewStatus != types.AccessReviewStatusOpen {
+		return apperr.Conflict("access review is already completed")
+	}
+	if current != types.AccessReviewDecisionPending {
+		return apperr.Conflict("access review item is already %s", current)
+	}
+	// Segregation of duties: nobody attests their own access
+	if employeeID == reviewerID {
+		return apperr.Permission("you cannot review your own access")
+	}
+
+	_, err = tx.Exec(`
+		UPDATE access_review_items
+		SET decision = $2, reviewed_by = $3, reviewed_at = NOW(), comment = $4
+		WHERE id = $1
+	`, itemID, decision, reviewerID, comment)
+	if err != nil {
+		logger.Errorf("Failed to record decision for access review item %s: %v", itemID, err)
+		return err
+	}
+
+	if decision == types.AccessReviewDecisionRevoked && accessID != nil {
+		_, err := tx.Exec(`
+			UPDATE employee_tenant_access
+			SET is_active = false, updated_at = NOW()
+			WHERE id = $1
+		`, *accessID)
+		if err != nil {
+			logger.Errorf("Failed to revoke tenant access %s: %v", *accessID, err)
+			return err
+		}
+		logger.Infof("Revoked tenant access %s via access review item %s", *accessID, itemID)
+	}
+
+	return tx.Commit()
+}
+
+// CompleteAccessReview closes a campaign once every line item has a decision
+func (s *Store) CompleteAccessReview(reviewID uuid.UUID) error {
+	result, err := s.DB.Exec(`
+		UPDATE access_reviews
+		SET status = 'COMPLETED', completed_at = NOW()
+		WHERE id = $1 AND status = 'OPEN'
+		  AND NOT EXISTS (
+		      SELECT 1 FROM access_review_items
+		      WHERE review_id = $1 AND decision = 'PENDING'
+		  )
+	`, reviewID)
+	if err != nil {
+		logger.Errorf("Failed to complete access review %s: %v", reviewID, err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return apperr.Conflict("access review %s is not open or has pending items", reviewID)
+	}
+
+	logger.Infof("Completed access review %s", reviewID)
+	return nil
+}
@@ -0,0 +1,154 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// SaveAddressValidation records the original and standardized address for an entity
+func (s *Store) SaveAddressValidation(v *types.AddressValidation) error {
+	original, err := json.Marshal(v.Original)
+	if err != nil {
+		return fmt.Errorf("failed to encode original address: %w", err)
+	}
+
+	var standardized interface{}
+	if v.Standardized != nil {
+		data, err := json.Marshal(v.Standardized)
+		if err != nil {
+			return fmt.Errorf("failed to encode standardized address: %w", err)
+		}
+		standardized = string(data)
+	}
+
+	var entityType interface{}
+	if v.EntityType != "" {
+		entityType = v.EntityType
+	}
+
+	query := `
+		INSERT INTO address_validations (
+			tenant_id, entity_type, entity_id, original, standardized,
+			deliverable, provider, footnotes, validated_by
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`
+
+	err = s.DB.QueryRow(
+		query,
+		v.TenantID,
+		entityType,
+		v.EntityID,
+		string(original),
+		standardized,
+		v.Deliverable,
+		v.Provider,
+		pq.Array(v.Footnotes),
+		v.ValidatedBy,
+	).Scan(&v.ID, &v.CreatedAt)
+	if err != nil {
+		logger.Errorf("Failed to save address validation for tenant %s: %v", v.TenantID, err)
+		return err
+	}
+
+	return nil
+}
+
+// GetUndeliverableAddresses returns the latest validation per entity where the address was flagged undeliverable
+func (s *Store) GetUndeliverableAddresses(tenantID string) ([]*types.AddressValidation, error) {
+	query := `
+		SELECT id, tenant_id, COALESCE(entity_type, ''), entity_id, original, standardized,
+		       deliverable, provider, footnotes, validated_by, created_at
+		FROM (
+			SELECT DISTINCT ON (entity_type, entity_id) *
+			FROM address_validations
+			WHERE tenant_id = $1 AND entity_id IS NOT NULL
+			ORDER BY entity_type, entity_id, created_at DESC
+		) latest
+		WHERE deliverable = false
+		ORDER BY created_at DESC
+	`
+
+	rows, err := s.DB.Query(query, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to query undeliverable addresses for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var validations []*types.AddressValidation
+	for rows.Next() {
+		v, err := scanAddressValidation(rows)
+		if err != nil {
+			return nil, err
+		}
+		validations = append(validations, v)
+	}
+
+	return validations, rows.Err()
+}
+
+// GetLatestAddressValidation returns the most recent validation of an entity's address
+func (s *Store) GetLatestAddressValidation(tenantID, entityType string, entityID uuid.UUID) (*types.AddressValidation, error) {
+	v, err := scanAddressValidation(s.DB.QueryRow(`
+		SELECT id, tenant_id, COALESCE(entity_type, ''), entity_id, original, standardized,
+		       deliverable, provider, footnotes, validated_by, created_at
+		FROM address_validations
+		WHERE tenant_id = $1 AND entity_type = $2 AND entity_id = $3
+		ORDER BY created_at DESC
+		LIMIT 1
+	`, tenantID, entityType, entityID))
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("%s %s has no validated address", strings.ToLower(entityType), entityID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// scanAddressValidation reads an address_validations row and decodes its addresses
+func scanAddressValidation(row interface{ Scan(...interface{}) error }) (*types.AddressValidation, error) {
+	v := &types.AddressValidation{}
+	var original []byte
+	var standardized []byte
+	err := row.Scan(
+		&v.ID,
+		&v.TenantID,
+		&v.EntityType,
+		&v.EntityID,
+		&original,
+		&standardized,
+		&v.Deliverable,
+		&v.Provider,
+		pq.Array(&v.Footnotes),
+		&v.ValidatedBy,
+		&v.CreatedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		logger.Errorf("Failed to scan address validation: %v", err)
+		return nil, err
+	}
+	if err := json.Unmarshal(original, &v.Original); err != nil {
+		return nil, fmt.Errorf("failed to decode original address: %w", err)
+	}
+	if len(standardized) > 0 {
+		v.Standardized = &types.Address{}
+		if err := json.Unmarshal(standardized, v.Standardized); err != nil {
+			return nil, fmt.Errorf("failed to decode standardized address: %w", err)
+		}
+	}
+	return v, nil
+}
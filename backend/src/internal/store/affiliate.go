@@ -1,9 +1,12 @@
 package store
 
 import (
+	"context"
 	"fmt"
 	"time"
 	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/idgen"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
@@ -11,15 +14,18 @@ import (
 )
 
 // GetAffiliates retrieves all affiliates for a specific tenant using the appropriate adapter
-func (s *Store) GetAffiliates(tenantID string, activeOnly bool) ([]*types.Affiliate, error) {
+func (s *Store) GetAffiliates(ctx context.Context, tenantID string, activeOnly bool) ([]*types.Affiliate, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	affiliateAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	affiliateAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -28,19 +34,37 @@ func (s *Store) GetAffiliates(tenantID string, activeOnly bool) ([]*types.Affili
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to fetch affiliates
-	return affiliateAdapter.GetAffiliates(db, tc.SchemaPrefix, activeOnly)
+	affiliates, err := affiliateAdapter.GetAffiliates(ctx, db, tc.SchemaPrefix, activeOnly)
+	if err != nil || db == nil {
+		return affiliates, err
+	}
+
+	// Dashboard tokens are kept by the platform rather than the adapter
+	access, err := GetAffiliateDashboardAccess(ctx, db, tc.SchemaPrefix)
+	if err != nil {
+		return nil, err
+	}
+	for _, affiliate := range affiliates {
+		if lastUsedAt, ok := access[affiliate.ID]; ok {
+			affiliate.LastDashboardAccessAt = &lastUsedAt
+		}
+	}
+	return affiliates, nil
 }
 
 // GetAffiliateByID retrieves a specific affiliate by ID for a tenant using the appropriate adapter
-func (s *Store) GetAffiliateByID(tenantID string, affiliateID string) (*types.Affiliate, error) {
+func (s *Store) GetAffiliateByID(ctx context.Context, tenantID string, affiliateID string) (*types.Affiliate, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	affiliateAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	affiliateAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -49,19 +73,45 @@ func (s *Store) GetAffiliateByID(tenantID string, affiliateID string) (*types.Af
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to fetch affiliate
-	return affiliateAdapter.GetAffiliateByID(db, tc.SchemaPrefix, affiliateID)
+	return affiliateAdapter.GetAffiliateByID(ctx, db, tc.SchemaPrefix, affiliateID)
+}
+
+// RecordAffiliateClick stores a tracking link visit for a tenant using the appropriate adapter
+func (s *Store) RecordAffiliateClick(ctx context.Context, tenantID string, click *types.AffiliateClick) error {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	affiliateAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	return affiliateAdapter.RecordAffiliateClick(ctx, db, tc.SchemaPrefix, click)
 }
 
 // CreateAffiliate creates a new affiliate for a tenant using the appropriate adapter
-func (s *Store) CreateAffiliate(tenantID string, affiliate *types.Affiliate) (*types.Affiliate, error) {
+func (s *Store) CreateAffiliate(ctx context.Context, tenantID string, affiliate *types.Affiliate) (*types.Affiliate, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	affiliateAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	affiliateAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -70,19 +120,22 @@ func (s *Store) CreateAffiliate(tenantID string, affiliate *types.Affiliate) (*t
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to create affiliate
-	return affiliateAdapter.CreateAffiliate(db, tc.SchemaPrefix, affiliate)
+	return affiliateAdapter.CreateAffiliate(ctx, db, tc.SchemaPrefix, affiliate)
 }
 
 // UpdateAffiliate updates an existing affiliate for a tenant using the appropriate adapter
-func (s *Store) UpdateAffiliate(tenantID string, affiliateID string, affiliate *types.Affiliate) (*types.Affiliate, error) {
+func (s *Store) UpdateAffiliate(ctx context.Context, tenantID string, affiliateID string, affiliate *types.Affiliate) (*types.Affiliate, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	affiliateAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	affiliateAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -91,19 +144,51 @@ func (s *Store) UpdateAffiliate(tenantID string, affiliateID string, affiliate *
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to update affiliate
-	return affiliateAdapter.UpdateAffiliate(db, tc.SchemaPrefix, affiliateID, affiliate)
+	return affiliateAdapter.UpdateAffiliate(ctx, db, tc.SchemaPrefix, affiliateID, affiliate)
+}
+
+// PatchAffiliate changes only the affiliate fields the patch sets
+func (s *Store) PatchAffiliate(ctx context.Context, tenantID string, affiliateID string, patch *types.AffiliatePatch) (*types.Affiliate, error) {
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	affiliateAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	return affiliateAdapter.PatchAffiliate(ctx, db, tc.SchemaPrefix, affiliateID, patch)
 }
 
 // GetCommissionsByAffiliate retrieves commissions for a specific affiliate (or all if affiliateID is nil)
-func (s *Store) GetCommissionsByAffiliate(tenantID string, affiliateID *string, status *string, limit int) ([]*types.Commission, error) {
+// When tag is set only commissions carrying that admin tag are returned
+func (s *Store) GetCommissionsByAffiliate(ctx context.Context, tenantID string, affiliateID *string, status *string, tag *string, limit int) ([]*types.Commission, error) {
+	var commissionIDs []string
+	if tag != nil {
+		ids, err := s.getTaggedCommissionIDs(tenantID, *tag)
+		if err != nil {
+			return nil, err
+		}
+		commissionIDs = ids
+	}
+
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	affiliateAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	affiliateAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -112,19 +197,50 @@ func (s *Store) GetCommissionsByAffiliate(tenantID string, affiliateID *string,
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to fetch commissions
-	return affiliateAdapter.GetCommissionsByAffiliate(db, tc.SchemaPrefix, affiliateID, status, limit)
+	return affiliateAdapter.GetCommissionsByAffiliate(ctx, db, tc.SchemaPrefix, affiliateID, status, commissionIDs, limit)
+}
+
+// GetCommissionPage retrieves one page of commissions, newest first, with the GetCommissionsByAffiliate filters
+func (s *Store) GetCommissionPage(ctx context.Context, tenantID string, affiliateID *string, status *string, tag *string, page types.PageRequest) (*types.CommissionPage, error) {
+	var commissionIDs []string
+	if tag != nil {
+		ids, err := s.getTaggedCommissionIDs(tenantID, *tag)
+		if err != nil {
+			return nil, err
+		}
+		commissionIDs = ids
+	}
+
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	affiliateAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	return affiliateAdapter.GetCommissionPage(ctx, db, tc.SchemaPrefix, affiliateID, status, commissionIDs, page)
 }
 
 // GetAffiliateStats retrieves aggregate statistics for an affiliate
-func (s *Store) GetAffiliateStats(tenantID string, affiliateID string) (*types.AffiliateStats, error) {
+func (s *Store) GetAffiliateStats(ctx context.Context, tenantID string, affiliateID string) (*types.AffiliateStats, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	affiliateAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	affiliateAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -133,19 +249,46 @@ func (s *Store) GetAffiliateStats(tenantID string, affiliateID string) (*types.A
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to fetch stats
-	return affiliateAdapter.GetAffiliateStats(db, tc.SchemaPrefix, affiliateID)
+	return affiliateAdapter.GetAffiliateStats(ctx, db, tc.SchemaPrefix, affiliateID)
+}
+
+// RollupAffiliateClicks aggregates a tenant's clicks from before the last retentionDays full UTC
+// days into daily buckets and deletes the raw rows, returning the number of clicks rolled up
+func (s *Store) RollupAffiliateClicks(ctx context.Context, tenantID string, retentionDays int) (int, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	affiliateAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return 0, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	// Cut at midnight so each day is rolled up whole
+	cutoff := time.Now().UTC().Truncate(24*time.Hour).AddDate(0, 0, -retentionDays)
+	return affiliateAdapter.RollupAffiliateClicks(ctx, db, tc.SchemaPrefix, cutoff)
 }
 
 // ApproveCommission approves a pending commission
-func (s *Store) ApproveCommission(tenantID string, commissionID string) (*types.Commission, error) {
+func (s *Store) ApproveCommission(ctx context.Context, tenantID string, commissionID string) (*types.Commission, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	affiliateAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	affiliateAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -154,19 +297,30 @@ func (s *Store) ApproveCommission(tenantID string, commissionID string) (*types.
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to approve commission
-	return affiliateAdapter.ApproveCommission(db, tc.SchemaPrefix, commissionID)
+	commission, err := affiliateAdapter.ApproveCommission(ctx, db, tc.SchemaPrefix, commissionID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyAffiliate(tenantID, commission, types.AffiliateEventCommissionApproved)
+	s.enqueueWebhookEvent(tenantID, types.WebhookEventCommissionApproved, commission)
+	return commission, nil
 }
 
-// MarkCommissionPaid marks an approved commission as paid
-func (s *Store) MarkCommissionPaid(tenantID string, commissionID string) (*types.Commission, error) {
+// ApproveCommissions approves the listed commissions, or those matching filter when commissionIDs
+// is nil, and reports the outcome for each
+func (s *Store) ApproveCommissions(ctx context.Context, tenantID string, commissionIDs []string, filter *types.CommissionFilter) (*types.BulkCommissionReport, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	affiliateAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	affiliateAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -174,20 +328,34 @@ func (s *Store) MarkCommissionPaid(tenantID string, commissionID string) (*types
 
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
-	// Use adapter to mark commission as paid
-	return affiliateAdapter.MarkCommissionPaid(db, tc.SchemaPrefix, commissionID)
+	report, err := affiliateAdapter.ApproveCommissions(ctx, db, tc.SchemaPrefix, commissionIDs, filter, types.MaxBulkCommissions)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range report.Results {
+		if result.Success {
+			s.notifyAffiliate(tenantID, result.Commission, types.AffiliateEventCommissionApproved)
+			s.enqueueWebhookEvent(tenantID, types.WebhookEventCommissionApproved, result.Commission)
+		}
+	}
+	return report, nil
 }
 
-// CancelCommission cancels a commission with a reason
-func (s *Store) CancelCommission(tenantID string, commissionID string, reason string) (*types.Commission, error) {
+// CancelCommission cancels a commission with a reason.
+// The reason is appended to the commission's notes and recorded as a note by the cancelling employee.
+func (s *Store) CancelCommission(ctx context.Context, tenantID string, commissionID string, reason string, cancelledBy uuid.UUID) (*types.Commission, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	affiliateAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	affiliateAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -196,61 +364,121 @@ func (s *Store) CancelCommission(tenantID string, commissionID string, reason st
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to cancel commission
-	return affiliateAdapter.CancelCommission(db, tc.SchemaPrefix, commissionID, reason)
+	commission, err := affiliateAdapter.CancelCommission(ctx, db, tc.SchemaPrefix, commissionID, reason)
+	if err != nil {
+		return nil, err
+	}
+
+	note := &types.CommissionNote{
+		TenantID:     tenantID,
+		CommissionID: commission.ID,
+		AuthorID:     &cancelledBy,
+		Body:         "Cancelled: " + reason,
+	}
+	if err := s.AddCommissionNote(note); err != nil {
+		// The commission is already cancelled and the reason is on its notes column
+		logger.Errorf("Failed to record cancellation note for commission %s: %v", commissionID, err)
+	}
+	s.notifyAffiliate(tenantID, commission, types.AffiliateEventCommissionCancelled)
+	s.enqueueWebhookEvent(tenantID, types.WebhookEventCommissionCancelled, commission)
+
+	return commission, nil
 }
 
 // GenerateAffiliateToken generates a new access token for an affiliate
-func (s *Store) GenerateAffiliateToken(tenantID string, affiliateID uuid.UUID, expiresAt *time.Time, notes *string) (string, *types.AffiliateToken, error) {
+func (s *Store) GenerateAffiliateToken(ctx context.Context, tenantID string, affiliateID uuid.UUID, expiresAt *time.Time, notes *string) (string, *types.AffiliateToken, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantSQLDB(ctx, tenantID)
 	if err != nil {
 		return "", nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	logger.Infof("Generating token for affiliate %s in tenant %s", affiliateID, tenantID)
 
 	// Call the store function directly (not adapter-specific)
-	return GenerateAffiliateToken(db, tc.SchemaPrefix, affiliateID, expiresAt, notes)
+	return GenerateAffiliateToken(ctx, db, tc.SchemaPrefix, idgen.ForVersion(tc.IDVersion)(), affiliateID, expiresAt, notes)
 }
 
 // GetAffiliateTokens retrieves all tokens for a specific affiliate
-func (s *Store) GetAffiliateTokens(tenantID string, affiliateID uuid.UUID, activeOnly bool) ([]*types.AffiliateToken, error) {
+func (s *Store) GetAffiliateTokens(ctx context.Context, tenantID string, affiliateID uuid.UUID, activeOnly bool) ([]*types.AffiliateToken, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantSQLDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	logger.Infof("Fetching tokens for affiliate %s in tenant %s (activeOnly=%v)", affiliateID, tenantID, activeOnly)
 
 	// Call the store function directly (not adapter-specific)
-	return GetAffiliateTokens(db, tc.SchemaPrefix, affiliateID, activeOnly)
+	return GetAffiliateTokens(ctx, db, tc.SchemaPrefix, affiliateID, activeOnly)
 }
 
 // RevokeAffiliateToken revokes (deactivates) a token
-func (s *Store) RevokeAffiliateToken(tenantID string, tokenID uuid.UUID) error {
+func (s *Store) RevokeAffiliateToken(ctx context.Context, tenantID string, tokenID uuid.UUID) error {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantSQLDB(ctx, tenantID)
 	if err != nil {
 		return err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	logger.Infof("Revoking token %s in tenant %s", tokenID, tenantID)
 
 	// Call the store function directly (not adapter-specific)
-	return RevokeAffiliateToken(db, tc.SchemaPrefix, tokenID)
+	return RevokeAffiliateToken(ctx, db, tc.SchemaPrefix, tokenID)
 }
 
 // ValidateAffiliateToken validates a token and returns the affiliate ID
-func (s *Store) ValidateAffiliateToken(tenantID string, plainToken string) (uuid.UUID, error) {
+func (s *Store) ValidateAffiliateToken(ctx context.Context, tenantID string, plainToken string) (uuid.UUID, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantSQLDB(ctx, tenantID)
 	if err != nil {
 		return uuid.Nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	logger.Infof("Validating affiliate token for tenant %s", tenantID)
 
 	// Call the store function directly (not adapter-specific)
-	return ValidateAffiliateToken(db, tc.SchemaPrefix, plainToken)
+	return ValidateAffiliateToken(ctx, db, tc.SchemaPrefix, plainToken)
+}
+
+// GetCommission retrieves a single commission by ID
+func (s *Store) GetCommission(ctx context.Context, tenantID string, commissionID string) (*types.Commission, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	affiliateAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	commissions, err := affiliateAdapter.GetCommissionsByAffiliate(ctx, db, tc.SchemaPrefix, nil, nil, []string{commissionID}, 1)
+	if err != nil {
+		return nil, err
+	}
+	if len(commissions) == 0 {
+		return nil, apperr.NotFound("commission not found: %s", commissionID)
+	}
+	return commissions[0], nil
 }
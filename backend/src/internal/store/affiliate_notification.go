@@ -0,0 +1,230 @@
+package store
+
+import (
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const affiliateNotificationColumns = `id, tenant_id, affiliate_id, commission_id, event, commission_amount, created_at, read_at`
+
+// NotifyAffiliate records a commission event for the commission's affiliate. It is emailed with
+// the affiliate's next batch unless they turned the event off.
+func (s *Store) NotifyAffiliate(tenantID string, commission *types.Commission, event string) error {
+	_, err := s.DB.Exec(`
+		INSERT INTO affiliate_notifications (tenant_id, affiliate_id, commission_id, event, commission_amount, email_pending)
+		VALUES ($1, $2, $3, $4, $5, COALESCE(
+			(SELECT email FROM affiliate_notification_preferences WHERE tenant_id = $1 AND affiliate_id = $2 AND event = $4),
+			true
+		))
+	`, tenantID, commission.AffiliateID, commission.ID, event, commission.CommissionAmount)
+	if err != nil {
+		logger.Errorf("Failed to record %s notification for affiliate %s: %v", event, commission.AffiliateID, err)
+		return err
+	}
+	return nil
+}
+
+// notifyAffiliate records a commission event after the commission has changed; a failure only
+// costs the affiliate a notification, so it is logged rather than returned
+func (s *Store) notifyAffiliate(tenantID string, commission *types.Commission, event string) {
+	if err := s.NotifyAffiliate(tenantID, commission, event); err != nil {
+		logger.Errorf("Commission %s changed without notifying its affiliate: %v", commission.ID, err)
+	}
+}
+
+// GetAffiliateNotifications returns an affiliate's latest notifications, newest first, and how
+// many are unread
+func (s *Store) GetAffiliateNotifications(tenantID string, affiliateID uuid.UUID, limit int) ([]*types.AffiliateNotification, int, error) {
+	rows, err := s.DB.Query(`
+		SELECT `+affiliateNotificationColumns+`
+		FROM affiliate_notifications
+		WHERE tenant_id = $1 AND affiliate_id = $2
+		ORDER BY created_at DESC, id
+		LIMIT $3
+	`, tenantID, affiliateID, limit)
+	if err != nil {
+		logger.Errorf("Failed to query notifications for affiliate %s: %v", affiliateID, err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	notifications := []*types.AffiliateNotification{}
+	for rows.Next() {
+		n, err := scanAffiliateNotification(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan affiliate notification: %v", err)
+			return nil, 0, err
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var unread int
+	err = s.DB.QueryRow(`
+		SELECT COUNT(*) FROM affiliate_notifications
+		WHERE tenant_id = $1 AND affiliate_id = $2 AND read_at IS NULL
+	`, tenantID, affiliateID).Scan(&unread)
+	if err != nil {
+		logger.Errorf("Failed to count unread notifications for affiliate %s: %v", affiliateID, err)
+		return nil, 0, err
+	}
+
+	return notifications, unread, nil
+}
+
+// MarkAffiliateNotificationsRead marks every unread notification of an affiliate read
+func (s *Store) MarkAffiliateNotificationsRead(tenantID string, affiliateID uuid.UUID) (int64, error) {
+	result, err := s.DB.Exec(`
+		UPDATE affiliate_notifications SET read_at = NOW()
+		WHERE tenant_id = $1 AND affiliate_id = $2 AND read_at IS NULL
+	`, tenantID, affiliateID)
+	if err != nil {
+		logger.Errorf("Failed to mark notifications read for affiliate %s: %v", affiliateID, err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetAffiliateNotificationPreferences returns an affiliate's preference for every event
+// Events without a stored row are emailed
+func (s *Store) GetAffiliateNotificationPreferences(tenantID string, affiliateID uuid.UUID) ([]*types.AffiliateNotificationPreference, error) {
+	rows, err := s.DB.Query(`
+		SELECT event, email
+		FROM affiliate_notification_preferences
+		WHERE tenant_id = $1 AND affiliate_id = $2
+	`, tenantID, affiliateID)
+	if err != nil {
+		logger.Errorf("Failed to query notification preferences for affiliate %s: %v", affiliateID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	stored := map[string]bool{}
+	for rows.Next() {
+		var event string
+		var email bool
+		if err := rows.Scan(&event, &email); err != nil {
+			logger.Errorf("Failed to scan affiliate notification preference: %v", err)
+			return nil, err
+		}
+		stored[event] = email
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	prefs := make([]*types.AffiliateNotificationPreference, 0, len(types.AffiliateNotificationEvents))
+	for _, event := range types.AffiliateNotificationEvents {
+		email, ok := stored[event]
+		if !ok {
+			email = true
+		}
+		prefs = append(prefs, &types.AffiliateNotificationPreference{Event: event, Email: email})
+	}
+
+	return prefs, nil
+}
+
+// SetAffiliateNotificationPreferences upserts an affiliate's preferences
+func (s *Store) SetAffiliateNotificationPreferences(tenantID string, affiliateID uuid.UUID, prefs []*types.AffiliateNotificationPreference) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO affiliate_notification_preferences (tenant_id, affiliate_id, event, email, updated_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (tenant_id, affiliate_id, event)
+		DO UPDATE SET email = EXCLUDED.email, updated_at = NOW()
+	`
+
+	for _, p := range prefs {
+		if _, err := tx.Exec(query, tenantID, affiliateID, p.Event, p.Email); err != nil {
+			logger.Errorf("Failed to save notification preference %s for affiliate %s: %v", p.Event, affiliateID, err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetPendingAffiliateEmails returns notifications waiting to be emailed, oldest first, for
+// affiliates with no new notification in the last quietSeconds (so a bulk change is sent as one
+// email) or with one waiting longer than maxWaitSeconds
+func (s *Store) GetPendingAffiliateEmails(quietSeconds, maxWaitSeconds int) ([]*types.AffiliateNotification, error) {
+	if err := s.requireScope(types.ScopeAffiliateEmails); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT `+affiliateNotificationColumns+`
+		FROM affiliate_notifications
+		WHERE email_pending AND (tenant_id, affiliate_id) IN (
+			SELECT tenant_id, affiliate_id
+			FROM affiliate_notifications
+			WHERE email_pending
+			GROUP BY tenant_id, affiliate_id
+			HAVING MAX(created_at) < NOW() - make_interval(secs => $1)
+			    OR MIN(created_at) < NOW() - make_interval(secs => $2)
+		)
+		ORDER BY tenant_id, affiliate_id, created_at, id
+	`, quietSeconds, maxWaitSeconds)
+	if err != nil {
+		logger.Errorf("Failed to query pending affiliate emails: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	notifications := []*types.AffiliateNotification{}
+	for rows.Next() {
+		n, err := scanAffiliateNotification(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan affiliate notification: %v", err)
+			return nil, err
+		}
+		notifications = append(notifications, n)
+	}
+
+	return notifications, rows.Err()
+}
+
+// MarkAffiliateNotificationsEmailed records that notifications were emailed (or will not be)
+func (s *Store) MarkAffiliateNotificationsEmailed(notificationIDs []uuid.UUID) error {
+	if err := s.requireScope(types.ScopeAffiliateEmails); err != nil {
+		return err
+	}
+
+	ids := make([]string, 0, len(notificationIDs))
+	for _, id := range notificationIDs {
+		ids = append(ids, id.String())
+	}
+
+	_, err := s.DB.Exec(`
+		UPDATE affiliate_notifications
+		SET email_pending = false, emailed_at = NOW()
+		WHERE id = ANY($1::uuid[])
+	`, pq.Array(ids))
+	if err != nil {
+		logger.Errorf("Failed to mark %d affiliate notifications emailed: %v", len(ids), err)
+		return err
+	}
+
+	return nil
+}
+
+// scanAffiliateNotification reads affiliateNotificationColumns
+func scanAffiliateNotification(row interface{ Scan(...interface{}) error }) (*types.AffiliateNotification, error) {
+	n := &types.AffiliateNotification{}
+	err := row.Scan(&n.ID, &n.TenantID, &n.AffiliateID, &n.CommissionID, &n.Event, &n.CommissionAmount, &n.CreatedAt, &n.ReadAt)
+	if err != nil {
+		return nil, err
+	}
+	return n, nil
+}
@@ -0,0 +1,196 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// payoutBatchListLimit caps the batches returned by GetPayoutBatches
+const payoutBatchListLimit = 100
+
+// tenantAdapter returns a tenant's database connection, configuration and adapter
+func (s *Store) tenantAdapter(ctx context.Context, tenantID string) (*sql.DB, *types.TenantConnection, adapter.ClientAdapter, error) {
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	tenantAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, nil, nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	return db, tc, tenantAdapter, nil
+}
+
+// CreatePayoutBatch groups the tenant's approved commissions into one payout per affiliate whose
+// total reaches their payout threshold
+func (s *Store) CreatePayoutBatch(ctx context.Context, tenantID string, createdBy string) (*types.PayoutBatch, error) {
+	db, tc, payoutAdapter, err := s.tenantAdapter(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+	return payoutAdapter.CreatePayoutBatch(ctx, db, tc.SchemaPrefix, createdBy)
+}
+
+// GetPayoutBatches retrieves the tenant's latest payout batches
+func (s *Store) GetPayoutBatches(ctx context.Context, tenantID string) ([]*types.PayoutBatch, error) {
+	db, tc, payoutAdapter, err := s.tenantAdapter(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+	return payoutAdapter.GetPayoutBatches(ctx, db, tc.SchemaPrefix, payoutBatchListLimit)
+}
+
+// GetPayoutBatch retrieves a payout batch with its payouts
+func (s *Store) GetPayoutBatch(ctx context.Context, tenantID string, batchID string) (*types.PayoutBatch, error) {
+	db, tc, payoutAdapter, err := s.tenantAdapter(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+	return payoutAdapter.GetPayoutBatch(ctx, db, tc.SchemaPrefix, batchID)
+}
+
+// GetPayout retrieves a single payout with its payments
+func (s *Store) GetPayout(ctx context.Context, tenantID string, payoutID string) (*types.AffiliatePayout, error) {
+	db, tc, payoutAdapter, err := s.tenantAdapter(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+	return payoutAdapter.GetPayout(ctx, db, tc.SchemaPrefix, payoutID)
+}
+
+// StartPayoutTransfer marks a payout PROCESSING before its transfer is sent
+func (s *Store) StartPayoutTransfer(ctx context.Context, tenantID string, payoutID string) (*types.AffiliatePayout, error) {
+	db, tc, payoutAdapter, err := s.tenantAdapter(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+	return payoutAdapter.StartPayoutTransfer(ctx, db, tc.SchemaPrefix, payoutID)
+}
+
+// RecordPayoutFailure records a failed transfer; declined transfers fail the payout
+func (s *Store) RecordPayoutFailure(ctx context.Context, tenantID string, payoutID string, message string, declined bool) error {
+	db, tc, payoutAdapter, err := s.tenantAdapter(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+	return payoutAdapter.RecordPayoutFailure(ctx, db, tc.SchemaPrefix, payoutID, message, declined)
+}
+
+// MarkPayoutPaid records a payment against a payout. Once the payout is paid in full it and its
+// commissions are marked PAID and the affiliate is notified of each.
+func (s *Store) MarkPayoutPaid(ctx context.Context, tenantID string, payoutID string, payment *types.PayoutPayment) (*types.AffiliatePayout, error) {
+	db, tc, payoutAdapter, err := s.tenantAdapter(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	payout, commissions, err := payoutAdapter.MarkPayoutPaid(ctx, db, tc.SchemaPrefix, payoutID, payment)
+	if err != nil {
+		return nil, err
+	}
+
+	s.notifyCommissionsPaid(tenantID, commissions)
+	return payout, nil
+}
+
+// MarkCommissionPaid records a manual payment of an approved commission that is not in a payout,
+// in a payout of its own. The commission is returned with that payout; it stays APPROVED until
+// the payout is paid in full.
+func (s *Store) MarkCommissionPaid(ctx context.Context, tenantID string, commissionID string, payment *types.PayoutPayment) (*types.Commission, error) {
+	db, tc, payoutAdapter, err := s.tenantAdapter(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	payout, commissions, err := payoutAdapter.MarkCommissionPaid(ctx, db, tc.SchemaPrefix, commissionID, payment)
+	if err != nil {
+		return nil, err
+	}
+	s.notifyCommissionsPaid(tenantID, commissions)
+
+	var commission *types.Commission
+	if len(commissions) > 0 {
+		commission = commissions[0]
+	} else if commission, err = s.GetCommission(ctx, tenantID, commissionID); err != nil {
+		return nil, err
+	}
+	commission.Payout = payout
+	return commission, nil
+}
+
+// notifyCommissionsPaid notifies affiliates and webhooks of commissions a payment marked PAID
+func (s *Store) notifyCommissionsPaid(tenantID string, commissions []*types.Commission) {
+	for _, commission := range commissions {
+		s.notifyAffiliate(tenantID, commission, types.AffiliateEventCommissionPaid)
+		s.enqueueWebhookEvent(tenantID, types.WebhookEventCommissionPaid, commission)
+	}
+}
+
+// GetAffiliatePayments retrieves an affiliate's latest payout payments, newest first
+func (s *Store) GetAffiliatePayments(ctx context.Context, tenantID string, affiliateID string, limit int) ([]*types.AffiliatePayoutPayment, error) {
+	db, tc, payoutAdapter, err := s.tenantAdapter(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+	return payoutAdapter.GetAffiliatePayments(ctx, db, tc.SchemaPrefix, affiliateID, limit)
+}
+
+// GetAffiliatePaymentTotals sums what the tenant paid each affiliate in a calendar year by payment
+// date, for their 1099s
+func (s *Store) GetAffiliatePaymentTotals(ctx context.Context, tenantID string, year int) ([]*types.AffiliatePaymentTotal, error) {
+	db, tc, payoutAdapter, err := s.tenantAdapter(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+	return payoutAdapter.GetAffiliatePaymentTotals(ctx, db, tc.SchemaPrefix, year)
+}
+
+// GetAffiliateStatement retrieves an affiliate's payout payments of a calendar year with their total
+func (s *Store) GetAffiliateStatement(ctx context.Context, tenantID string, affiliateID string, year int) (*types.AffiliateStatement, error) {
+	db, tc, payoutAdapter, err := s.tenantAdapter(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+	return payoutAdapter.GetAffiliateStatement(ctx, db, tc.SchemaPrefix, affiliateID, year)
+}
+
+// CancelPayout cancels an unpaid payout; its commissions go into the next batch
+func (s *Store) CancelPayout(ctx context.Context, tenantID string, payoutID string) (*types.AffiliatePayout, error) {
+	db, tc, payoutAdapter, err := s.tenantAdapter(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+	return payoutAdapter.CancelPayout(ctx, db, tc.SchemaPrefix, payoutID)
+}
@@ -1,12 +1,15 @@
 package store
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
 	"fmt"
 	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/sqlident"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
@@ -14,8 +17,8 @@ import (
 )
 
 // GenerateAffiliateToken creates a new access token for an affiliate
-// Returns the plain token (to be shared with affiliate) and stores the hash
-func GenerateAffiliateToken(db *sql.DB, schemaPrefix string, affiliateID uuid.UUID, expiresAt *time.Time, notes *string) (string, *types.AffiliateToken, error) {
+// Returns the plain token (to be shared with affiliate) and stores the hash under tokenID
+func GenerateAffiliateToken(ctx context.Context, db *sql.DB, schemaPrefix string, tokenID uuid.UUID, affiliateID uuid.UUID, expiresAt *time.Time, notes *string) (string, *types.AffiliateToken, error) {
 	// Generate a secure random token (32 bytes = 64 hex chars)
 	tokenBytes := make([]byte, 32)
 	if _, err := rand.Read(tokenBytes); err != nil {
@@ -29,17 +32,19 @@ func GenerateAffiliateToken(db *sql.DB, schemaPrefix string, affiliateID uuid.UU
 
 	query := fmt.Sprintf(`
 		INSERT INTO %s.affiliate_tokens (
-			affiliate_id, token_hash, expires_at, notes, is_active
+			id, affiliate_id, token_hash, expires_at, notes, is_active
 		)
-		VALUES ($1, $2, $3, $4, $5)
+		VALUES ($1, $2, $3, $4, $5, $6)
 		RETURNING id, affiliate_id, token_hash, expires_at, last_used_at, is_active, notes, created_at, updated_at
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("Generating affiliate token for affiliate %s", affiliateID)
 
 	token := &types.AffiliateToken{}
-	err := db.QueryRow(
+	err := db.QueryRowContext(
+		ctx,
 		query,
+		tokenID,
 		affiliateID,
 		tokenHash,
 		expiresAt,
@@ -68,7 +73,7 @@ func GenerateAffiliateToken(db *sql.DB, schemaPrefix string, affiliateID uuid.UU
 
 // ValidateAffiliateToken validates a token and returns the affiliate ID
 // Also updates the last_used_at timestamp
-func ValidateAffiliateToken(db *sql.DB, schemaPrefix string, plainToken string) (uuid.UUID, error) {
+func ValidateAffiliateToken(ctx context.Context, db *sql.DB, schemaPrefix string, plainToken string) (uuid.UUID, error) {
 	// Hash the provided token
 	hash := sha256.Sum256([]byte(plainToken))
 	tokenHash := hex.EncodeToString(hash[:])
@@ -80,17 +85,17 @@ func ValidateAffiliateToken(db *sql.DB, schemaPrefix string, plainToken string)
 		  AND is_active = true
 		  AND (expires_at IS NULL OR expires_at > NOW())
 		RETURNING affiliate_id
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("Validating affiliate token")
 
 	var affiliateID uuid.UUID
-	err := db.QueryRow(query, tokenHash).Scan(&affiliateID)
+	err := db.QueryRowContext(ctx, query, tokenHash).Scan(&affiliateID)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			logger.Warning("Invalid or expired affiliate token")
-			return uuid.Nil, fmt.Errorf("invalid or expired token")
+			return uuid.Nil, apperr.Permission("invalid or expired token")
 		}
 		logger.Errorf("Failed to validate affiliate token: %v", err)
 		return uuid.Nil, fmt.Errorf("failed to validate token: %w", err)
@@ -101,7 +106,7 @@ func ValidateAffiliateToken(db *sql.DB, schemaPrefix string, plainToken string)
 }
 
 // GetAffiliateTokens retrieves all tokens for a specific affiliate
-func GetAffiliateTokens(db *sql.DB, schemaPrefix string, affiliateID uuid.UUID, activeOnly bool) ([]*types.AffiliateToken, error) {
+func GetAffiliateTokens(ctx context.Context, db *sql.DB, schemaPrefix string, affiliateID uuid.UUID, activeOnly bool) ([]*types.AffiliateToken, error) {
 	whereClause := "WHERE affiliate_id = $1"
 	if activeOnly {
 		whereClause += " AND is_active = true"
@@ -112,11 +117,11 @@ func GetAffiliateTokens(db *sql.DB, schemaPrefix string, affiliateID uuid.UUID,
 		FROM %s.affiliate_tokens
 		%s
 		ORDER BY created_at DESC
-	`, schemaPrefix, whereClause)
+	`, sqlident.Schema(schemaPrefix), whereClause)
 
 	logger.Infof("Fetching tokens for affiliate %s (activeOnly=%v)", affiliateID, activeOnly)
 
-	rows, err := db.Query(query, affiliateID)
+	rows, err := db.QueryContext(ctx, query, affiliateID)
 	if err != nil {
 		logger.Errorf("Failed to query affiliate tokens: %v", err)
 		return nil, fmt.Errorf("failed to query tokens: %w", err)
@@ -154,16 +159,16 @@ func GetAffiliateTokens(db *sql.DB, schemaPrefix string, affiliateID uuid.UUID,
 }
 
 // RevokeAffiliateToken revokes (deactivates) a token
-func RevokeAffiliateToken(db *sql.DB, schemaPrefix string, tokenID uuid.UUID) error {
+func RevokeAffiliateToken(ctx context.Context, db *sql.DB, schemaPrefix string, tokenID uuid.UUID) error {
 	query := fmt.Sprintf(`
 		UPDATE %s.affiliate_tokens
 		SET is_active = false, updated_at = NOW()
 		WHERE id = $1
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Infof("Revoking affiliate token %s", tokenID)
 
-	result, err := db.Exec(query, tokenID)
+	result, err := db.ExecContext(ctx, query, tokenID)
 	if err != nil {
 		logger.Errorf("Failed to revoke token: %v", err)
 		return fmt.Errorf("failed to revoke token: %w", err)
@@ -171,7 +176,7 @@ func RevokeAffiliateToken(db *sql.DB, schemaPrefix string, tokenID uuid.UUID) er
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("token not found")
+		return apperr.NotFound("token not found")
 	}
 
 	logger.Infof("Successfully revoked token %s", tokenID)
@@ -180,15 +185,15 @@ func RevokeAffiliateToken(db *sql.DB, schemaPrefix string, tokenID uuid.UUID) er
 
 // DeleteExpiredTokens removes expired tokens from the database
 // This is a maintenance function that should be run periodically
-func DeleteExpiredTokens(db *sql.DB, schemaPrefix string) (int64, error) {
+func DeleteExpiredTokens(ctx context.Context, db *sql.DB, schemaPrefix string) (int64, error) {
 	query := fmt.Sprintf(`
 		DELETE FROM %s.affiliate_tokens
 		WHERE expires_at IS NOT NULL AND expires_at < NOW()
-	`, schemaPrefix)
+	`, sqlident.Schema(schemaPrefix))
 
 	logger.Info("Deleting expired affiliate tokens")
 
-	result, err := db.Exec(query)
+	result, err := db.ExecContext(ctx, query)
 	if err != nil {
 		logger.Errorf("Failed to delete expired tokens: %v", err)
 		return 0, fmt.Errorf("failed to delete expired tokens: %w", err)
@@ -198,3 +203,57 @@ func DeleteExpiredTokens(db *sql.DB, schemaPrefix string) (int64, error) {
 	logger.Infof("Successfully deleted %d expired tokens", rowsAffected)
 	return rowsAffected, nil
 }
+
+// GetAffiliateDashboardAccess returns when each affiliate last used any of their tokens,
+// leaving out affiliates whose tokens were never used
+func GetAffiliateDashboardAccess(ctx context.Context, db *sql.DB, schemaPrefix string) (map[uuid.UUID]time.Time, error) {
+	query := fmt.Sprintf(`
+		SELECT affiliate_id, MAX(last_used_at)
+		FROM %s.affiliate_tokens
+		WHERE last_used_at IS NOT NULL
+		GROUP BY affiliate_id
+	`, sqlident.Schema(schemaPrefix))
+
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		logger.Errorf("Failed to query affiliate dashboard access: %v", err)
+		return nil, fmt.Errorf("failed to query dashboard access: %w", err)
+	}
+	defer rows.Close()
+
+	access := map[uuid.UUID]time.Time{}
+	for rows.Next() {
+		var affiliateID uuid.UUID
+		var lastUsedAt time.Time
+		if err := rows.Scan(&affiliateID, &lastUsedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan dashboard access: %w", err)
+		}
+		access[affiliateID] = lastUsedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating dashboard access: %w", err)
+	}
+	return access, nil
+}
+
+// DeactivateDormantTokens deactivates active tokens last used before cutoff, or created before
+// it when never used, and returns how many it deactivated
+func DeactivateDormantTokens(ctx context.Context, db *sql.DB, schemaPrefix string, cutoff time.Time) (int64, error) {
+	query := fmt.Sprintf(`
+		UPDATE %s.affiliate_tokens
+		SET is_active = false, updated_at = NOW()
+		WHERE is_active = true AND COALESCE(last_used_at, created_at) < $1
+	`, sqlident.Schema(schemaPrefix))
+
+	result, err := db.ExecContext(ctx, query, cutoff.UTC())
+	if err != nil {
+		logger.Errorf("Failed to deactivate dormant tokens: %v", err)
+		return 0, fmt.Errorf("failed to deactivate dormant tokens: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected > 0 {
+		logger.Infof("Deactivated %d affiliate tokens unused since %s", rowsAffected, cutoff.Format(time.RFC3339))
+	}
+	return rowsAffected, nil
+}
@@ -0,0 +1,93 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// GetAffiliateTokenPolicy returns the effective affiliate token policy for a tenant
+func (s *Store) GetAffiliateTokenPolicy(tenantID string) (*types.AffiliateTokenPolicy, error) {
+	var data sql.NullString
+	err := s.DB.QueryRow(`
+		SELECT affiliate_token_policy::text FROM tenant_connections
+		WHERE tenant_id = $1 AND is_active = true
+	`, tenantID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("tenant not found: %s", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get affiliate token policy of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	if !data.Valid {
+		return types.DefaultAffiliateTokenPolicy(), nil
+	}
+	policy := &types.AffiliateTokenPolicy{}
+	if err := json.Unmarshal([]byte(data.String), policy); err != nil {
+		logger.Errorf("Invalid affiliate token policy for tenant %s, using defaults: %v", tenantID, err)
+		return types.DefaultAffiliateTokenPolicy(), nil
+	}
+	return policy, nil
+}
+
+// UpdateAffiliateTokenPolicy replaces the affiliate token policy for a tenant and records the
+// change in the tenant's configuration history
+func (s *Store) UpdateAffiliateTokenPolicy(tenantID string, policy *types.AffiliateTokenPolicy, employeeID uuid.UUID) error {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("failed to encode affiliate token policy: %w", err)
+	}
+
+	query := `
+		UPDATE tenant_connections
+		SET affiliate_token_policy = $1, updated_at = NOW()
+		WHERE tenant_id = $2
+	`
+
+	err = s.ChangeTenantConfig(tenantID, types.TenantConfigActionUpdate, &employeeID, func(tx *sql.Tx) error {
+		_, err := tx.Exec(query, string(data), tenantID)
+		return err
+	})
+	if err != nil {
+		logger.Errorf("Failed to update affiliate token policy for tenant %s: %v", tenantID, err)
+		return err
+	}
+
+	logger.Infof("Updated affiliate token policy for tenant %s", tenantID)
+	return nil
+}
+
+// DeactivateDormantAffiliateTokens deactivates a tenant's affiliate tokens that have gone unused
+// longer than its policy allows and returns how many it deactivated. Tenants whose policy keeps
+// every token are left alone.
+func (s *Store) DeactivateDormantAffiliateTokens(ctx context.Context, tenantID string, now time.Time) (int, error) {
+	policy, err := s.GetAffiliateTokenPolicy(tenantID)
+	if err != nil {
+		return 0, err
+	}
+	cutoff, ok := policy.Cutoff(now)
+	if !ok {
+		return 0, nil
+	}
+
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantSQLDB(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	n, err := DeactivateDormantTokens(ctx, db, tc.SchemaPrefix, cutoff)
+	return int(n), err
+}
@@ -0,0 +1,244 @@
+package store
+
+import (
+	"database/sql"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const announcementColumns = `a.id, a.message, a.severity, a.starts_at, a.ends_at, a.tenant_ids, a.created_by, a.created_at, a.updated_at`
+
+// scanAnnouncement reads announcementColumns followed by extra columns into an announcement
+func scanAnnouncement(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*types.Announcement, error) {
+	a := &types.Announcement{}
+	var tenantIDs pq.StringArray
+	dest := []interface{}{&a.ID, &a.Message, &a.Severity, &a.StartsAt, &a.EndsAt, &tenantIDs, &a.CreatedBy, &a.CreatedAt, &a.UpdatedAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	a.TenantIDs = tenantIDs
+	return a, nil
+}
+
+// checkAnnouncementTenants returns a validation error naming the first of tenantIDs that does not exist
+func (s *Store) checkAnnouncementTenants(tenantIDs []string) error {
+	if len(tenantIDs) == 0 {
+		return nil
+	}
+
+	rows, err := s.DB.Query(`SELECT tenant_id FROM tenant_connections WHERE tenant_id = ANY($1)`, pq.Array(tenantIDs))
+	if err != nil {
+		logger.Errorf("Failed to check announcement tenants: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	known := map[string]bool{}
+	for rows.Next() {
+		var tenantID string
+		if err := rows.Scan(&tenantID); err != nil {
+			return err
+		}
+		known[tenantID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	for _, tenantID := range tenantIDs {
+		if !known[tenantID] {
+			return apperr.Validation("unknown tenant %s", tenantID)
+		}
+	}
+	return nil
+}
+
+// CreateAnnouncement creates an announcement for the tenants it names, or for every tenant
+func (s *Store) CreateAnnouncement(a *types.Announcement) (*types.Announcement, error) {
+	if err := s.checkAnnouncementTenants(a.TenantIDs); err != nil {
+		return nil, err
+	}
+
+	created, err := scanAnnouncement(s.DB.QueryRow(`
+		INSERT INTO announcements AS a (message, severity, starts_at, ends_at, tenant_ids, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING `+announcementColumns,
+		a.Message, a.Severity, a.StartsAt, a.EndsAt, pq.Array(a.TenantIDs), a.CreatedBy))
+	if err != nil {
+		logger.Errorf("Failed to create announcement: %v", err)
+		return nil, err
+	}
+
+	logger.Infof("Created %s announcement %s for %d tenants (0 is all)", created.Severity, created.ID, len(created.TenantIDs))
+	return created, nil
+}
+
+// UpdateAnnouncement replaces the message, severity, time window and tenants of an announcement.
+// Acknowledgments are kept.
+func (s *Store) UpdateAnnouncement(announcementID uuid.UUID, a *types.Announcement) (*types.Announcement, error) {
+	if err := s.checkAnnouncementTenants(a.TenantIDs); err != nil {
+		return nil, err
+	}
+
+	var acknowledgments int
+	updated, err := scanAnnouncement(s.DB.QueryRow(`
+		UPDATE announcements AS a
+		SET message = $2, severity = $3, starts_at = $4, ends_at = $5, tenant_ids = $6, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+announcementColumns+`,
+			(SELECT COUNT(*) FROM announcement_acknowledgments k WHERE k.announcement_id = a.id)`,
+		announcementID, a.Message, a.Severity, a.StartsAt, a.EndsAt, pq.Array(a.TenantIDs)), &acknowledgments)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("announcement not found: %s", announcementID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to update announcement %s: %v", announcementID, err)
+		return nil, err
+	}
+	updated.Acknowledgments = acknowledgments
+	return updated, nil
+}
+
+// DeleteAnnouncement removes an announcement and its acknowledgments
+func (s *Store) DeleteAnnouncement(announcementID uuid.UUID) error {
+	result, err := s.DB.Exec(`DELETE FROM announcements WHERE id = $1`, announcementID)
+	if err != nil {
+		logger.Errorf("Failed to delete announcement %s: %v", announcementID, err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return apperr.NotFound("announcement not found: %s", announcementID)
+	}
+	return nil
+}
+
+// GetAnnouncements lists every announcement with how many employees acknowledged it, latest
+// start first. Ended announcements are left out unless includeEnded is true.
+func (s *Store) GetAnnouncements(includeEnded bool) ([]*types.Announcement, error) {
+	rows, err := s.DB.Query(`
+		SELECT `+announcementColumns+`, COUNT(k.employee_id)
+		FROM announcements a
+		LEFT JOIN announcement_acknowledgments k ON k.announcement_id = a.id
+		WHERE $1 OR a.ends_at > NOW()
+		GROUP BY a.id
+		ORDER BY a.starts_at DESC
+	`, includeEnded)
+	if err != nil {
+		logger.Errorf("Failed to get announcements: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	announcements := []*types.Announcement{}
+	for rows.Next() {
+		var acknowledgments int
+		a, err := scanAnnouncement(rows, &acknowledgments)
+		if err != nil {
+			logger.Errorf("Failed to scan announcement: %v", err)
+			return nil, err
+		}
+		a.Acknowledgments = acknowledgments
+		announcements = append(announcements, a)
+	}
+	return announcements, rows.Err()
+}
+
+// GetActiveAnnouncements returns the announcements showing now to an employee, most severe and
+// then latest first, each marked with whether the employee acknowledged it. Platform admins see
+// every announcement; other employees see those for tenants they are an active admin of.
+func (s *Store) GetActiveAnnouncements(employee *types.Employee) ([]*types.Announcement, error) {
+	rows, err := s.DB.Query(`
+		SELECT `+announcementColumns+`, k.employee_id IS NOT NULL
+		FROM announcements a
+		LEFT JOIN announcement_acknowledgments k ON k.announcement_id = a.id AND k.employee_id = $1
+		WHERE a.starts_at <= NOW() AND a.ends_at > NOW()
+		  AND ($2 OR EXISTS (
+			SELECT 1 FROM employee_tenant_access eta
+			WHERE eta.employee_id = $1 AND eta.is_active AND eta.role = 'admin'
+			  AND (cardinality(a.tenant_ids) = 0 OR eta.tenant_id = ANY(a.tenant_ids))
+		  ))
+		ORDER BY CASE a.severity WHEN 'CRITICAL' THEN 0 WHEN 'WARNING' THEN 1 ELSE 2 END, a.starts_at DESC
+	`, employee.ID, employee.Role == "admin")
+	if err != nil {
+		logger.Errorf("Failed to get active announcements for %s: %v", employee.Email, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	announcements := []*types.Announcement{}
+	for rows.Next() {
+		var acknowledged bool
+		a, err := scanAnnouncement(rows, &acknowledged)
+		if err != nil {
+			logger.Errorf("Failed to scan announcement: %v", err)
+			return nil, err
+		}
+		a.Acknowledged = acknowledged
+		announcements = append(announcements, a)
+	}
+	return announcements, rows.Err()
+}
+
+// AcknowledgeAnnouncement records that an employee dismissed an announcement. Acknowledging it
+// again keeps the first acknowledgment.
+func (s *Store) AcknowledgeAnnouncement(announcementID uuid.UUID, employee *types.Employee) (*types.AnnouncementAcknowledgment, error) {
+	ack := &types.AnnouncementAcknowledgment{AnnouncementID: announcementID, EmployeeID: employee.ID, EmployeeEmail: employee.Email}
+	err := s.DB.QueryRow(`
+		WITH inserted AS (
+			INSERT INTO announcement_acknowledgments (announcement_id, employee_id)
+			SELECT id, $2 FROM announcements WHERE id = $1
+			ON CONFLICT (announcement_id, employee_id) DO NOTHING
+			RETURNING acknowledged_at
+		)
+		SELECT acknowledged_at FROM inserted
+		UNION ALL
+		SELECT acknowledged_at FROM announcement_acknowledgments WHERE announcement_id = $1 AND employee_id = $2
+	`, announcementID, employee.ID).Scan(&ack.AcknowledgedAt)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("announcement not found: %s", announcementID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to acknowledge announcement %s for %s: %v", announcementID, employee.Email, err)
+		return nil, err
+	}
+	return ack, nil
+}
+
+// GetAnnouncementAcknowledgments lists who acknowledged an announcement, earliest first
+func (s *Store) GetAnnouncementAcknowledgments(announcementID uuid.UUID) ([]*types.AnnouncementAcknowledgment, error) {
+	var exists bool
+	if err := s.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM announcements WHERE id = $1)`, announcementID).Scan(&exists); err != nil {
+		logger.Errorf("Failed to get announcement %s: %v", announcementID, err)
+		return nil, err
+	}
+	if !exists {
+		return nil, apperr.NotFound("announcement not found: %s", announcementID)
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT k.announcement_id, k.employee_id, e.email, k.acknowledged_at
+		FROM announcement_acknowledgments k
+		JOIN employees e ON e.id = k.employee_id
+		WHERE k.announcement_id = $1
+		ORDER BY k.acknowledged_at
+	`, announcementID)
+	if err != nil {
+		logger.Errorf("Failed to get acknowledgments of announcement %s: %v", announcementID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	acks := []*types.AnnouncementAcknowledgment{}
+	for rows.Next() {
+		ack := &types.AnnouncementAcknowledgment{}
+		if err := rows.Scan(&ack.AnnouncementID, &ack.EmployeeID, &ack.EmployeeEmail, &ack.AcknowledgedAt); err != nil {
+			logger.Errorf("Failed to scan announcement acknowledgment: %v", err)
+			return nil, err
+		}
+		acks = append(acks, ack)
+	}
+	return acks, rows.Err()
+}
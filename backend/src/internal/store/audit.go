@@ -1,23 +1,53 @@
 package store
 
 import (
+	"database/sql"
 	"encoding/json"
+	"time"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
 	"github.com/google/uuid"
 )
 
-// LogAudit creates an audit log entry
+// auditChainLock serializes audit inserts so every entry links to the one before it
+const auditChainLock = "audit_chain"
+
+// LogAudit appends an audit log entry to the hash chain
 func (s *Store) LogAudit(log *types.AuditLog) error {
-	query := `
-		INSERT INTO audit_logs (
-			employee_id, tenant_id, client_id, action, resource_type,
-			resource_id, details, ip_address, user_agent
-		)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
-		RETURNING id, created_at
-	`
+	tx, err := s.DB.Begin()
+	if err != nil {
+		logger.Errorf("Failed to create audit log: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock(hashtextextended($1, 0))`, auditChainLock); err != nil {
+		logger.Errorf("Failed to lock audit chain: %v", err)
+		return err
+	}
+
+	seq, prevHash := int64(1), types.AuditChainGenesis
+	err = tx.QueryRow(`
+		SELECT chain_seq + 1, entry_hash FROM audit_logs
+		WHERE chain_seq IS NOT NULL
+		ORDER BY chain_seq DESC
+		LIMIT 1
+	`).Scan(&seq, &prevHash)
+	if err != nil && err != sql.ErrNoRows {
+		logger.Errorf("Failed to read audit chain head: %v", err)
+		return err
+	}
+
+	// The ID and timestamp are part of the hash, so they are fixed here rather than by column
+	// defaults. LOCALTIMESTAMP is what the created_at default would have stored.
+	log.ID = uuid.New()
+	if err := tx.QueryRow(`SELECT LOCALTIMESTAMP`).Scan(&log.CreatedAt); err != nil {
+		logger.Errorf("Failed to read audit timestamp: %v", err)
+		return err
+	}
+	log.CreatedAt = log.CreatedAt.Truncate(time.Microsecond)
+	entryHash := log.ChainHash(seq, prevHash)
 
 	// Convert json.RawMessage to string for lib/pq JSONB compatibility
 	// lib/pq expects JSONB to be passed as string, not []byte
@@ -26,9 +56,23 @@ func (s *Store) LogAudit(log *types.AuditLog) error {
 		detailsValue = string(log.Details)
 	}
 
-	err := s.DB.QueryRow(
-		query,
-		log.EmployeeID,
+	// Portal user events have no employee; the zero ID is stored as NULL
+	var employeeID *uuid.UUID
+	if log.EmployeeID != uuid.Nil {
+		employeeID = &log.EmployeeID
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO audit_logs (
+			id, employee_id, tenant_user_id, tenant_id, client_id, action, resource_type,
+			resource_id, details, ip_address, user_agent, created_at,
+			chain_seq, prev_hash, entry_hash
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15)
+	`,
+		log.ID,
+		employeeID,
+		log.TenantUserID,
 		log.TenantID,
 		log.ClientID,
 		log.Action,
@@ -37,13 +81,22 @@ func (s *Store) LogAudit(log *types.AuditLog) error {
 		detailsValue,
 		log.IPAddress,
 		log.UserAgent,
-	).Scan(&log.ID, &log.CreatedAt)
-
+		log.CreatedAt,
+		seq,
+		prevHash,
+		entryHash,
+	)
 	if err != nil {
 		logger.Errorf("Failed to create audit log: %v", err)
 		return err
 	}
 
+	if err := tx.Commit(); err != nil {
+		logger.Errorf("Failed to create audit log: %v", err)
+		return err
+	}
+
+	log.ChainSeq, log.PrevHash, log.EntryHash = &seq, &prevHash, &entryHash
 	return nil
 }
 
@@ -84,11 +137,50 @@ func (s *Store) CreateAuditLog(
 	return s.LogAudit(auditLog)
 }
 
+// CreatePortalAuditLog records a portal user's security event, such as a wrong verification
+// answer, in the same hash chain as employee actions
+func (s *Store) CreatePortalAuditLog(
+	tenantUserID uuid.UUID,
+	tenantID string,
+	clientID *uuid.UUID,
+	action string,
+	resourceType string,
+	resourceID *uuid.UUID,
+	details interface{},
+	ipAddress *string,
+	userAgent *string,
+) error {
+	var detailsJSON json.RawMessage
+	if details != nil {
+		jsonData, err := json.Marshal(details)
+		if err != nil {
+			logger.Errorf("Failed to marshal audit details: %v", err)
+			return err
+		}
+		detailsJSON = jsonData
+	}
+
+	auditLog := &types.AuditLog{
+		TenantUserID: &tenantUserID,
+		TenantID:     tenantID,
+		ClientID:     clientID,
+		Action:       action,
+		ResourceType: resourceType,
+		ResourceID:   resourceID,
+		Details:      detailsJSON,
+		IPAddress:    ipAddress,
+		UserAgent:    userAgent,
+	}
+
+	return s.LogAudit(auditLog)
+}
+
 // GetAuditLogsByEmployee retrieves audit logs for a specific employee
 func (s *Store) GetAuditLogsByEmployee(employeeID uuid.UUID, limit int) ([]*types.AuditLog, error) {
 	query := `
-		SELECT id, employee_id, tenant_id, client_id, action, resource_type,
-		       resource_id, details, ip_address, user_agent, created_at
+		SELECT id, employee_id, tenant_user_id, tenant_id, client_id, action, resource_type,
+		       resource_id, details, ip_address, user_agent, created_at,
+		       chain_seq, prev_hash, entry_hash
 		FROM audit_logs
 		WHERE employee_id = $1
 		ORDER BY created_at DESC
@@ -101,8 +193,9 @@ func (s *Store) GetAuditLogsByEmployee(employeeID uuid.UUID, limit int) ([]*type
 // GetAuditLogsByClient retrieves audit logs for a specific client
 func (s *Store) GetAuditLogsByClient(tenantID string, clientID uuid.UUID, limit int) ([]*types.AuditLog, error) {
 	query := `
-		SELECT id, employee_id, tenant_id, client_id, action, resource_type,
-		       resource_id, details, ip_address, user_agent, created_at
+		SELECT id, employee_id, tenant_user_id, tenant_id, client_id, action, resource_type,
+		       resource_id, details, ip_address, user_agent, created_at,
+		       chain_seq, prev_hash, entry_hash
 		FROM audit_logs
 		WHERE tenant_id = $1 AND client_id = $2
 		ORDER BY created_at DESC
@@ -115,8 +208,9 @@ func (s *Store) GetAuditLogsByClient(tenantID string, clientID uuid.UUID, limit
 // GetAuditLogsByTenant retrieves audit logs for a specific tenant
 func (s *Store) GetAuditLogsByTenant(tenantID string, limit int) ([]*types.AuditLog, error) {
 	query := `
-		SELECT id, employee_id, tenant_id, client_id, action, resource_type,
-		       resource_id, details, ip_address, user_agent, created_at
+		SELECT id, employee_id, tenant_user_id, tenant_id, client_id, action, resource_type,
+		       resource_id, details, ip_address, user_agent, created_at,
+		       chain_seq, prev_hash, entry_hash
 		FROM audit_logs
 		WHERE tenant_id = $1
 		ORDER BY created_at DESC
@@ -126,6 +220,36 @@ func (s *Store) GetAuditLogsByTenant(tenantID string, limit int) ([]*types.Audit
 	return s.queryAuditLogs(query, tenantID, limit)
 }
 
+// ForEachTenantAuditLog calls fn with every audit log of a tenant, oldest first, stopping at the
+// first error
+func (s *Store) ForEachTenantAuditLog(tenantID string, fn func(*types.AuditLog) error) error {
+	rows, err := s.DB.Query(`
+		SELECT id, employee_id, tenant_user_id, tenant_id, client_id, action, resource_type,
+		       resource_id, details, ip_address, user_agent, created_at,
+		       chain_seq, prev_hash, entry_hash
+		FROM audit_logs
+		WHERE tenant_id = $1
+		ORDER BY created_at, chain_seq
+	`, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to query audit logs of tenant %s: %v", tenantID, err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		log, err := scanAuditLog(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan audit log: %v", err)
+			return err
+		}
+		if err := fn(log); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
 // queryAuditLogs is a helper function to query audit logs
 func (s *Store) queryAuditLogs(query string, args ...interface{}) ([]*types.AuditLog, error) {
 	rows, err := s.DB.Query(query, args...)
@@ -137,20 +261,7 @@ func (s *Store) queryAuditLogs(query string, args ...interface{}) ([]*types.Audi
 
 	var logs []*types.AuditLog
 	for rows.Next() {
-		log := &types.AuditLog{}
-		err := rows.Scan(
-			&log.ID,
-			&log.EmployeeID,
-			&log.TenantID,
-			&log.ClientID,
-			&log.Action,
-			&log.ResourceType,
-			&log.ResourceID,
-			&log.Details,
-			&log.IPAddress,
-			&log.UserAgent,
-			&log.CreatedAt,
-		)
+		log, err := scanAuditLog(rows)
 		if err != nil {
 			logger.Errorf("Failed to scan audit log: %v", err)
 			return nil, err
@@ -160,3 +271,29 @@ func (s *Store) queryAuditLogs(query string, args ...interface{}) ([]*types.Audi
 
 	return logs, rows.Err()
 }
+
+// scanAuditLog scans an audit_logs row selected with the chain columns
+func scanAuditLog(row interface{ Scan(...interface{}) error }) (*types.AuditLog, error) {
+	log := &types.AuditLog{}
+	err := row.Scan(
+		&log.ID,
+		&log.EmployeeID,
+		&log.TenantUserID,
+		&log.TenantID,
+		&log.ClientID,
+		&log.Action,
+		&log.ResourceType,
+		&log.ResourceID,
+		&log.Details,
+		&log.IPAddress,
+		&log.UserAgent,
+		&log.CreatedAt,
+		&log.ChainSeq,
+		&log.PrevHash,
+		&log.EntryHash,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return log, nil
+}
@@ -0,0 +1,246 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// GetAuditChainHead returns the sequence number and hash of the newest chained audit entry,
+// or 0 and an empty hash when nothing has been chained yet
+func (s *Store) GetAuditChainHead() (int64, string, error) {
+	var seq int64
+	var hash string
+	err := s.DB.QueryRow(`
+		SELECT chain_seq, entry_hash FROM audit_logs
+		WHERE chain_seq IS NOT NULL
+		ORDER BY chain_seq DESC
+		LIMIT 1
+	`).Scan(&seq, &hash)
+	if err == sql.ErrNoRows {
+		return 0, "", nil
+	}
+	if err != nil {
+		logger.Errorf("Failed to read audit chain head: %v", err)
+		return 0, "", err
+	}
+	return seq, hash, nil
+}
+
+// GetLastAuditAnchor returns the most recent anchor, or nil when none has been written
+func (s *Store) GetLastAuditAnchor() (*types.AuditAnchor, error) {
+	anchors, err := s.queryAuditAnchors(`
+		SELECT chain_seq, entry_hash, bucket, object_path, anchored_by, anchored_at
+		FROM audit_anchors
+		ORDER BY chain_seq DESC
+		LIMIT 1
+	`)
+	if err != nil || len(anchors) == 0 {
+		return nil, err
+	}
+	return anchors[0], nil
+}
+
+// RecordAuditAnchor records a chain head that was written to the anchor bucket
+func (s *Store) RecordAuditAnchor(anchor *types.AuditAnchor) error {
+	if err := s.requireScope(types.ScopeAuditAnchor); err != nil {
+		return err
+	}
+
+	err := s.DB.QueryRow(`
+		INSERT INTO audit_anchors (chain_seq, entry_hash, bucket, object_path, anchored_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING anchored_at
+	`, anchor.ChainSeq, anchor.EntryHash, anchor.Bucket, anchor.ObjectPath, anchor.AnchoredBy).Scan(&anchor.AnchoredAt)
+	if err != nil {
+		logger.Errorf("Failed to record audit anchor at %d: %v", anchor.ChainSeq, err)
+		return err
+	}
+
+	logger.Infof("Anchored audit chain at entry %d in %s/%s", anchor.ChainSeq, anchor.Bucket, anchor.ObjectPath)
+	return nil
+}
+
+// VerifyAuditChain re-computes the hash of every chained audit entry created in [from, to) and
+// checks that each links to the entry before it, that no sequence numbers are missing, and that
+// anchors within the range still match the chain. It returns the anchors it compared so their
+// WORM copies can be checked too.
+func (s *Store) VerifyAuditChain(from, to time.Time) (*types.AuditChainVerification, []*types.AuditAnchor, error) {
+	v := &types.AuditChainVerification{From: from, To: to, Valid: true, Problems: []*types.AuditChainProblem{}}
+
+	err := s.DB.QueryRow(`
+		SELECT COUNT(*) FROM audit_logs
+		WHERE chain_seq IS NULL AND created_at >= $1 AND created_at < $2
+	`, from, to).Scan(&v.Unchained)
+	if err != nil {
+		logger.Errorf("Failed to count unchained audit entries: %v", err)
+		return nil, nil, err
+	}
+
+	var first, last sql.NullInt64
+	err = s.DB.QueryRow(`
+		SELECT MIN(chain_seq), MAX(chain_seq) FROM audit_logs
+		WHERE chain_seq IS NOT NULL AND created_at >= $1 AND created_at < $2
+	`, from, to).Scan(&first, &last)
+	if err != nil {
+		logger.Errorf("Failed to find audit chain range: %v", err)
+		return nil, nil, err
+	}
+	if !first.Valid {
+		return v, []*types.AuditAnchor{}, nil
+	}
+	v.FirstSeq, v.LastSeq = &first.Int64, &last.Int64
+
+	// The first entry in range links to the one before it, which may fall outside the range
+	expected := types.AuditChainGenesis
+	if first.Int64 > 1 {
+		err := s.DB.QueryRow(`SELECT entry_hash FROM audit_logs WHERE chain_seq = $1`, first.Int64-1).Scan(&expected)
+		if err == sql.ErrNoRows {
+			expected = ""
+			v.AddProblem(&types.AuditChainProblem{
+				ChainSeq: first.Int64 - 1,
+				Problem:  types.AuditChainGap,
+				Detail:   "the entry before the range is missing",
+			})
+		} else if err != nil {
+			logger.Errorf("Failed to read audit entry %d: %v", first.Int64-1, err)
+			return nil, nil, err
+		}
+	}
+
+	anchors, err := s.queryAuditAnchors(`
+		SELECT chain_seq, entry_hash, bucket, object_path, anchored_by, anchored_at
+		FROM audit_anchors
+		WHERE chain_seq BETWEEN $1 AND $2
+		ORDER BY chain_seq
+	`, first.Int64, last.Int64)
+	if err != nil {
+		return nil, nil, err
+	}
+	anchored := map[int64]*types.AuditAnchor{}
+	for _, a := range anchors {
+		anchored[a.ChainSeq] = a
+	}
+
+	// Walk by sequence rather than time, so entries whose timestamps were moved are still checked
+	entries, err := s.DB.Query(`
+		SELECT id, employee_id, tenant_user_id, tenant_id, client_id, action, resource_type,
+		       resource_id, details, ip_address, user_agent, created_at,
+		       chain_seq, prev_hash, entry_hash
+		FROM audit_logs
+		WHERE chain_seq BETWEEN $1 AND $2
+		ORDER BY chain_seq
+	`, first.Int64, last.Int64)
+	if err != nil {
+		logger.Errorf("Failed to read audit chain: %v", err)
+		return nil, nil, err
+	}
+	defer entries.Close()
+
+	previous := first.Int64 - 1
+	for entries.Next() {
+		entry, err := scanAuditLog(entries)
+		if err != nil {
+			logger.Errorf("Failed to scan audit log: %v", err)
+			return nil, nil, err
+		}
+		seq := *entry.ChainSeq
+		v.EntriesChecked++
+
+		if seq != previous+1 {
+			v.AddProblem(&types.AuditChainProblem{
+				ChainSeq: previous + 1,
+				Problem:  types.AuditChainGap,
+				Detail:   fmt.Sprintf("entries %d to %d are missing", previous+1, seq-1),
+			})
+			expected = ""
+		}
+		if expected != "" && *entry.PrevHash != expected {
+			v.AddProblem(&types.AuditChainProblem{
+				ChainSeq: seq,
+				EntryID:  &entry.ID,
+				Problem:  types.AuditChainLinkBroken,
+				Detail:   "prev_hash does not match the previous entry",
+			})
+		}
+		if entry.ChainHash(seq, *entry.PrevHash) != *entry.EntryHash {
+			v.AddProblem(&types.AuditChainProblem{
+				ChainSeq: seq,
+				EntryID:  &entry.ID,
+				Problem:  types.AuditChainHashMismatch,
+				Detail:   "entry was modified after it was written",
+			})
+		}
+		if a, ok := anchored[seq]; ok {
+			if a.EntryHash != *entry.EntryHash {
+				v.AddProblem(&types.AuditChainProblem{
+					ChainSeq: seq,
+					EntryID:  &entry.ID,
+					Problem:  types.AuditChainAnchorMismatch,
+					Detail:   fmt.Sprintf("entry hash differs from the anchor written %s", a.AnchoredAt.UTC().Format(time.RFC3339)),
+				})
+			}
+			delete(anchored, seq)
+			v.AnchorsChecked++
+		}
+
+		previous = seq
+		expected = *entry.EntryHash
+	}
+	if err := entries.Err(); err != nil {
+		logger.Errorf("Failed to read audit chain: %v", err)
+		return nil, nil, err
+	}
+
+	// Anchors left over point at entries that no longer exist
+	for _, a := range anchors {
+		if _, missing := anchored[a.ChainSeq]; missing {
+			v.AddProblem(&types.AuditChainProblem{
+				ChainSeq: a.ChainSeq,
+				Problem:  types.AuditChainAnchorMismatch,
+				Detail:   "anchored entry is missing",
+			})
+			v.AnchorsChecked++
+		}
+	}
+
+	logger.Infof("Verified audit chain %d-%d: %d entries, %d anchors, valid=%t", first.Int64, last.Int64, v.EntriesChecked, v.AnchorsChecked, v.Valid)
+	return v, anchors, nil
+}
+
+// queryAuditAnchors runs a query selecting audit anchor columns
+func (s *Store) queryAuditAnchors(query string, args ...interface{}) ([]*types.AuditAnchor, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		logger.Errorf("Failed to query audit anchors: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	anchors := []*types.AuditAnchor{}
+	for rows.Next() {
+		a := &types.AuditAnchor{}
+		if err := rows.Scan(&a.ChainSeq, &a.EntryHash, &a.Bucket, &a.ObjectPath, &a.AnchoredBy, &a.AnchoredAt); err != nil {
+			logger.Errorf("Failed to scan audit anchor: %v", err)
+			return nil, err
+		}
+		anchors = append(anchors, a)
+	}
+	return anchors, rows.Err()
+}
+
+// GetAuditEntryHash returns the stored hash of the chained entry at seq; ok is false when it is missing
+func (s *Store) GetAuditEntryHash(seq int64) (hash string, ok bool, err error) {
+	err = s.DB.QueryRow(`SELECT entry_hash FROM audit_logs WHERE chain_seq = $1`, seq).Scan(&hash)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		logger.Errorf("Failed to read audit entry %d: %v", seq, err)
+		return "", false, err
+	}
+	return hash, true, nil
+}
@@ -0,0 +1,212 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// CreateBreakGlassGrant elevates an employee to tenant admin until the grant expires.
+// An existing inactive access row is reactivated; its prior role is restored on revoke.
+func (s *Store) CreateBreakGlassGrant(employeeID uuid.UUID, tenantID, reason string, duration time.Duration) (*types.BreakGlassGrant, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var accessID uuid.UUID
+	var previousRole *string
+	var isActive bool
+	createdAccess := false
+
+	err = tx.QueryRow(`
+		SELECT id, role, is_active
+		FROM employee_tenant_access
+		WHERE employee_id = $1 AND tenant_id = $2
+		FOR UPDATE
+	`, employeeID, tenantID).Scan(&accessID, &previousRole, &isActive)
+
+	switch {
+	case err == sql.ErrNoRows:
+		err = tx.QueryRow(`
+			INSERT INTO employee_tenant_access (employee_id, tenant_id, role, created_by)
+			VALUES ($1, $2, 'admin', $1)
+			RETURNING id
+		`, employeeID, tenantID).Scan(&accessID)
+		if err != nil {
+			logger.Errorf("Failed to create break-glass access for employee %s on tenant %s: %v", employeeID, tenantID, err)
+			return nil, err
+		}
+		createdAccess = true
+		previousRole = nil
+	case err != nil:
+		logger.Errorf("Failed to check tenant access for employee %s: %v", employeeID, err)
+		return nil, err
+	case isActive:
+		return nil, apperr.Conflict("employee already has access to tenant %s", tenantID)
+	default:
+		_, err = tx.Exec(`
+			UPDATE employee_tenant_access
+			SET role = 'admin', is_active = true, updated_at = NOW()
+			WHERE id = $1
+		`, accessID)
+		if err != nil {
+			logger.Errorf("Failed to reactivate access %s for break-glass: %v", accessID, err)
+			return nil, err
+		}
+	}
+
+	grant := &types.BreakGlassGrant{
+		EmployeeID: employeeID,
+		TenantID:   tenantID,
+		Reason:     reason,
+	}
+	err = tx.QueryRow(`
+		INSERT INTO break_glass_grants (employee_id, tenant_id, reason, access_id, created_access, previous_role, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW() + make_interval(secs => $7))
+		RETURNING id, granted_at, expires_at
+	`, employeeID, tenantID, reason, accessID, createdAccess, previousRole, duration.Seconds()).Scan(&grant.ID, &grant.GrantedAt, &grant.ExpiresAt)
+	if err != nil {
+		logger.Errorf("Failed to record break-glass grant: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Warningf("BREAK-GLASS: employee %s elevated to admin on tenant %s until %s", employeeID, tenantID, grant.ExpiresAt.Format(time.RFC3339))
+	return grant, nil
+}
+
+// GetBreakGlassGrants lists break-glass grants, newest first
+func (s *Store) GetBreakGlassGrants(activeOnly bool) ([]*types.BreakGlassGrant, error) {
+	query := `
+		SELECT g.id, g.employee_id, e.email, g.tenant_id, g.reason, g.granted_at, g.expires_at, g.revoked_at, g.revoked_by
+		FROM break_glass_grants g
+		JOIN employees e ON e.id = g.employee_id
+	`
+	if activeOnly {
+		query += " WHERE g.revoked_at IS NULL AND g.expires_at > NOW()"
+	}
+	query += " ORDER BY g.granted_at DESC"
+
+	rows, err := s.DB.Query(query)
+	if err != nil {
+		logger.Errorf("Failed to query break-glass grants: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []*types.BreakGlassGrant
+	for rows.Next() {
+		g := &types.BreakGlassGrant{}
+		err := rows.Scan(&g.ID, &g.EmployeeID, &g.EmployeeEmail, &g.TenantID, &g.Reason, &g.GrantedAt, &g.ExpiresAt, &g.RevokedAt, &g.RevokedBy)
+		if err != nil {
+			logger.Errorf("Failed to scan break-glass grant: %v", err)
+			return nil, err
+		}
+		grants = append(grants, g)
+	}
+
+	return grants, rows.Err()
+}
+
+// RevokeBreakGlassGrant ends a grant and restores the employee's prior tenant access.
+// revokedBy is nil when the grant is being expired automatically.
+func (s *Store) RevokeBreakGlassGrant(grantID uuid.UUID, revokedBy *uuid.UUID) (*types.BreakGlassGrant, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	g := &types.BreakGlassGrant{}
+	var accessID *uuid.UUID
+	var createdAccess bool
+	var previousRole *string
+
+	err = tx.QueryRow(`
+		UPDATE break_glass_grants
+		SET revoked_at = NOW(), revoked_by = $2
+		WHERE id = $1 AND revoked_at IS NULL
+		RETURNING id, employee_id, tenant_id, reason, granted_at, expires_at, revoked_at, revoked_by,
+		          access_id, created_access, previous_role
+	`, grantID, revokedBy).Scan(
+		&g.ID, &g.EmployeeID, &g.TenantID, &g.Reason, &g.GrantedAt, &g.ExpiresAt, &g.RevokedAt, &g.RevokedBy,
+		&accessID, &createdAccess, &previousRole,
+	)
+	if err == sql.ErrNoRows {
+		return nil, apperr.Conflict("break-glass grant not found or already revoked: %s", grantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to revoke break-glass grant %s: %v", grantID, err)
+		return nil, err
+	}
+
+	if accessID != nil {
+		if createdAccess {
+			_, err = tx.Exec(`DELETE FROM employee_tenant_access WHERE id = $1`, *accessID)
+		} else {
+			_, err = tx.Exec(`
+				UPDATE employee_tenant_access
+				SET role = COALESCE($2, role), is_active = false, updated_at = NOW()
+				WHERE id = $1
+			`, *accessID, previousRole)
+		}
+		if err != nil {
+			logger.Errorf("Failed to restore tenant access %s after break-glass: %v", *accessID, err)
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Warningf("BREAK-GLASS: grant %s for employee %s on tenant %s revoked", g.ID, g.EmployeeID, g.TenantID)
+	return g, nil
+}
+
+// ExpireBreakGlassGrants revokes every grant past its expiry and returns them
+func (s *Store) ExpireBreakGlassGrants() ([]*types.BreakGlassGrant, error) {
+	rows, err := s.DB.Query(`
+		SELECT id FROM break_glass_grants
+		WHERE revoked_at IS NULL AND expires_at <= NOW()
+	`)
+	if err != nil {
+		logger.Errorf("Failed to query expired break-glass grants: %v", err)
+		return nil, err
+	}
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var expired []*types.BreakGlassGrant
+	for _, id := range ids {
+		g, err := s.RevokeBreakGlassGrant(id, nil)
+		if err != nil {
+			logger.Errorf("Failed to expire break-glass grant %s: %v", id, err)
+			continue
+		}
+		expired = append(expired, g)
+	}
+
+	return expired, nil
+}
@@ -0,0 +1,297 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const bulkOperationColumns = `id, tenant_id, action, status, selection, params, total_items, succeeded_items,
+	failed_items, error, created_by, created_at, selected_at, started_at, finished_at`
+
+// scanBulkOperation reads a row of bulkOperationColumns
+func scanBulkOperation(row interface{ Scan(...interface{}) error }) (*types.BulkOperation, error) {
+	op := &types.BulkOperation{}
+	var selection, params []byte
+	err := row.Scan(&op.ID, &op.TenantID, &op.Action, &op.Status, &selection, &params, &op.TotalItems,
+		&op.SucceededItems, &op.FailedItems, &op.Error, &op.CreatedBy, &op.CreatedAt, &op.SelectedAt,
+		&op.StartedAt, &op.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	op.Selection = &types.BulkSelection{}
+	if err := json.Unmarshal(selection, op.Selection); err != nil {
+		return nil, err
+	}
+	op.Params = json.RawMessage(params)
+	return op, nil
+}
+
+// CreateBulkOperation queues a bulk operation for the worker
+func (s *Store) CreateBulkOperation(op *types.BulkOperation) (*types.BulkOperation, error) {
+	selection, err := json.Marshal(op.Selection)
+	if err != nil {
+		return nil, err
+	}
+	params := []byte(op.Params)
+	if len(params) == 0 {
+		params = []byte("{}")
+	}
+
+	created, err := scanBulkOperation(s.DB.QueryRow(`
+		INSERT INTO bulk_operations (tenant_id, action, selection, params, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+bulkOperationColumns,
+		op.TenantID, op.Action, selection, params, op.CreatedBy))
+	if err != nil {
+		logger.Errorf("Failed to create %s bulk operation for tenant %s: %v", op.Action, op.TenantID, err)
+		return nil, err
+	}
+
+	logger.Infof("Queued %s bulk operation %s for tenant %s", created.Action, created.ID, created.TenantID)
+	return created, nil
+}
+
+// GetBulkOperation returns a tenant's bulk operation with its items
+func (s *Store) GetBulkOperation(tenantID string, id uuid.UUID) (*types.BulkOperation, error) {
+	op, err := scanBulkOperation(s.DB.QueryRow(`
+		SELECT `+bulkOperationColumns+`
+		FROM bulk_operations
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("bulk operation %s not found", id)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get bulk operation %s: %v", id, err)
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT item_id, status, result, error, processed_at
+		FROM bulk_operation_items
+		WHERE operation_id = $1
+		ORDER BY processed_at NULLS LAST, item_id
+	`, id)
+	if err != nil {
+		logger.Errorf("Failed to get items of bulk operation %s: %v", id, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	op.Items = []*types.BulkOperationItem{}
+	for rows.Next() {
+		item := &types.BulkOperationItem{}
+		if err := rows.Scan(&item.ItemID, &item.Status, &item.Result, &item.Error, &item.ProcessedAt); err != nil {
+			logger.Errorf("Failed to scan bulk operation item: %v", err)
+			return nil, err
+		}
+		op.Items = append(op.Items, item)
+	}
+	return op, rows.Err()
+}
+
+// GetBulkOperations returns a tenant's latest bulk operations, newest first, without their items
+func (s *Store) GetBulkOperations(tenantID string, limit int) ([]*types.BulkOperation, error) {
+	return s.queryBulkOperations(`
+		SELECT `+bulkOperationColumns+`
+		FROM bulk_operations
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, tenantID, limit)
+}
+
+// GetUnfinishedBulkOperations returns the queued and interrupted bulk operations, oldest first
+func (s *Store) GetUnfinishedBulkOperations() ([]*types.BulkOperation, error) {
+	return s.queryBulkOperations(`
+		SELECT ` + bulkOperationColumns + `
+		FROM bulk_operations
+		WHERE status IN ('QUEUED', 'RUNNING')
+		ORDER BY created_at
+	`)
+}
+
+// StartBulkOperation marks a bulk operation running; an interrupted run keeps its start time
+func (s *Store) StartBulkOperation(id uuid.UUID) error {
+	if err := s.requireScope(types.ScopeBulkOperations); err != nil {
+		return err
+	}
+	_, err := s.DB.Exec(`
+		UPDATE bulk_operations
+		SET status = 'RUNNING', started_at = COALESCE(started_at, NOW())
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		logger.Errorf("Failed to start bulk operation %s: %v", id, err)
+	}
+	return err
+}
+
+// SetBulkOperationItems records the items a bulk operation's selection resolved to, all pending
+func (s *Store) SetBulkOperationItems(id uuid.UUID, itemIDs []uuid.UUID) error {
+	if err := s.requireScope(types.ScopeBulkOperations); err != nil {
+		return err
+	}
+
+	ids := make([]string, len(itemIDs))
+	for i, itemID := range itemIDs {
+		ids[i] = itemID.String()
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		logger.Errorf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`
+		INSERT INTO bulk_operation_items (operation_id, item_id)
+		SELECT $1, unnest($2::uuid[])
+		ON CONFLICT DO NOTHING
+	`, id, pq.Array(ids)); err != nil {
+		logger.Errorf("Failed to record items of bulk operation %s: %v", id, err)
+		return err
+	}
+	if _, err := tx.Exec(`
+		UPDATE bulk_operations
+		SET total_items = (SELECT COUNT(*) FROM bulk_operation_items WHERE operation_id = $1), selected_at = NOW()
+		WHERE id = $1
+	`, id); err != nil {
+		logger.Errorf("Failed to record selection of bulk operation %s: %v", id, err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Errorf("Failed to commit items of bulk operation %s: %v", id, err)
+		return err
+	}
+	return nil
+}
+
+// GetPendingBulkItems returns the items of a bulk operation not yet processed
+func (s *Store) GetPendingBulkItems(id uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := s.DB.Query(`
+		SELECT item_id FROM bulk_operation_items
+		WHERE operation_id = $1 AND status = 'PENDING'
+		ORDER BY item_id
+	`, id)
+	if err != nil {
+		logger.Errorf("Failed to get pending items of bulk operation %s: %v", id, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []uuid.UUID
+	for rows.Next() {
+		var itemID uuid.UUID
+		if err := rows.Scan(&itemID); err != nil {
+			logger.Errorf("Failed to scan bulk operation item: %v", err)
+			return nil, err
+		}
+		items = append(items, itemID)
+	}
+	return items, rows.Err()
+}
+
+// RecordBulkItem records the outcome of a pending item and counts it on its operation
+func (s *Store) RecordBulkItem(id, itemID uuid.UUID, result string, itemErr error) error {
+	if err := s.requireScope(types.ScopeBulkOperations); err != nil {
+		return err
+	}
+
+	status, counter := types.BulkItemSucceeded, "succeeded_items"
+	var resultMsg, errMsg *string
+	if itemErr != nil {
+		status, counter = types.BulkItemFailed, "failed_items"
+		msg := itemErr.Error()
+		errMsg = &msg
+	} else if result != "" {
+		resultMsg = &result
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		logger.Errorf("Failed to begin transaction: %v", err)
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE bulk_operation_items
+		SET status = $3, result = $4, error = $5, processed_at = NOW()
+		WHERE operation_id = $1 AND item_id = $2 AND status = 'PENDING'
+	`, id, itemID, status, resultMsg, errMsg)
+	if err != nil {
+		logger.Errorf("Failed to record item %s of bulk operation %s: %v", itemID, id, err)
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil || n == 0 {
+		return err
+	}
+	if _, err := tx.Exec(`UPDATE bulk_operations SET `+counter+` = `+counter+` + 1 WHERE id = $1`, id); err != nil {
+		logger.Errorf("Failed to count item %s of bulk operation %s: %v", itemID, id, err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Errorf("Failed to commit item %s of bulk operation %s: %v", itemID, id, err)
+		return err
+	}
+	return nil
+}
+
+// FinishBulkOperation marks a bulk operation COMPLETED, or FAILED with the reason when opErr is set
+func (s *Store) FinishBulkOperation(id uuid.UUID, opErr error) error {
+	if err := s.requireScope(types.ScopeBulkOperations); err != nil {
+		return err
+	}
+
+	status := types.BulkOperationCompleted
+	var errMsg *string
+	if opErr != nil {
+		status = types.BulkOperationFailed
+		msg := opErr.Error()
+		errMsg = &msg
+	}
+
+	_, err := s.DB.Exec(`
+		UPDATE bulk_operations
+		SET status = $2, error = $3, finished_at = NOW()
+		WHERE id = $1
+	`, id, status, errMsg)
+	if err != nil {
+		logger.Errorf("Failed to finish bulk operation %s: %v", id, err)
+		return err
+	}
+
+	logger.Infof("Bulk operation %s finished: %s", id, status)
+	return nil
+}
+
+// queryBulkOperations runs a query selecting bulkOperationColumns
+func (s *Store) queryBulkOperations(query string, args ...interface{}) ([]*types.BulkOperation, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		logger.Errorf("Failed to query bulk operations: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ops := []*types.BulkOperation{}
+	for rows.Next() {
+		op, err := scanBulkOperation(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan bulk operation: %v", err)
+			return nil, err
+		}
+		ops = append(ops, op)
+	}
+	return ops, rows.Err()
+}
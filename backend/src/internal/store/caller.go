@@ -0,0 +1,48 @@
+package store
+
+import (
+	"context"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// serviceKey is the context key of the calling service identity
+type serviceKey struct{}
+
+// WithService returns a context whose store calls act as the given service identity
+func WithService(ctx context.Context, id *types.ServiceIdentity) context.Context {
+	return context.WithValue(ctx, serviceKey{}, id)
+}
+
+// ServiceFromContext returns the service identity a context acts as, if any
+func ServiceFromContext(ctx context.Context) (*types.ServiceIdentity, bool) {
+	id, ok := ctx.Value(serviceKey{}).(*types.ServiceIdentity)
+	return id, ok && id != nil
+}
+
+// ForService returns a view of the store that acts as a service identity.
+// The view shares connections and caches with s; close s, not the view.
+func (s *Store) ForService(id *types.ServiceIdentity) *Store {
+	scoped := *s
+	scoped.ctx = WithService(s.ctx, id)
+	return &scoped
+}
+
+// hasScope reports whether the caller may use scope.
+// Stores without a service identity serve API requests, which are authorized by the HTTP middleware.
+func (s *Store) hasScope(scope string) bool {
+	id, ok := ServiceFromContext(s.ctx)
+	return !ok || id.HasScope(scope)
+}
+
+// requireScope returns a permission error when the calling service lacks scope
+func (s *Store) requireScope(scope string) error {
+	if s.hasScope(scope) {
+		return nil
+	}
+	id, _ := ServiceFromContext(s.ctx)
+	logger.Warningf("Service %s denied %s", id.Name, scope)
+	return apperr.Permission("service %s is not permitted to use %s", id.Name, scope)
+}
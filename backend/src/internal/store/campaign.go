@@ -0,0 +1,206 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const campaignColumns = `id, tenant_id, key, name, channel, description, starts_on::text, ends_on::text,
+	is_active, created_by, created_at, updated_at`
+
+// CreateCampaign adds a marketing campaign; keys are unique per tenant regardless of case
+func (s *Store) CreateCampaign(c *types.Campaign) error {
+	err := s.DB.QueryRow(`
+		INSERT INTO campaigns (tenant_id, key, name, channel, description, starts_on, ends_on, is_active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, created_at
+	`, c.TenantID, c.Key, c.Name, c.Channel, c.Description, c.StartsOn, c.EndsOn, c.IsActive, c.CreatedBy).Scan(&c.ID, &c.CreatedAt)
+	if isUniqueViolation(err) {
+		return apperr.Conflict("campaign key already exists: %s", c.Key)
+	}
+	if err != nil {
+		logger.Errorf("Failed to create campaign %s for tenant %s: %v", c.Key, c.TenantID, err)
+		return err
+	}
+
+	logger.Infof("Created campaign %s (%s) for tenant %s", c.Key, c.Channel, c.TenantID)
+	return nil
+}
+
+// UpdateCampaign replaces the editable fields of a campaign
+func (s *Store) UpdateCampaign(c *types.Campaign) error {
+	err := s.DB.QueryRow(`
+		UPDATE campaigns
+		SET key = $3, name = $4, channel = $5, description = $6, starts_on = $7, ends_on = $8,
+		    is_active = $9, updated_at = NOW()
+		WHERE id = $1 AND tenant_id = $2
+		RETURNING created_by, created_at, updated_at
+	`, c.ID, c.TenantID, c.Key, c.Name, c.Channel, c.Description, c.StartsOn, c.EndsOn, c.IsActive).Scan(&c.CreatedBy, &c.CreatedAt, &c.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return apperr.NotFound("campaign not found: %s", c.ID)
+	}
+	if isUniqueViolation(err) {
+		return apperr.Conflict("campaign key already exists: %s", c.Key)
+	}
+	if err != nil {
+		logger.Errorf("Failed to update campaign %s: %v", c.ID, err)
+		return err
+	}
+
+	logger.Infof("Updated campaign %s for tenant %s", c.ID, c.TenantID)
+	return nil
+}
+
+// GetCampaign returns one campaign of a tenant
+func (s *Store) GetCampaign(tenantID string, campaignID uuid.UUID) (*types.Campaign, error) {
+	row := s.DB.QueryRow(`
+		SELECT `+campaignColumns+`
+		FROM campaigns
+		WHERE id = $1 AND tenant_id = $2
+	`, campaignID, tenantID)
+
+	c, err := scanCampaign(row)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("campaign not found: %s", campaignID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get campaign %s: %v", campaignID, err)
+		return nil, err
+	}
+	return c, nil
+}
+
+// GetCampaigns lists a tenant's campaigns by channel and name, optionally only active ones
+func (s *Store) GetCampaigns(tenantID string, activeOnly bool) ([]*types.Campaign, error) {
+	query := `
+		SELECT ` + campaignColumns + `
+		FROM campaigns
+		WHERE tenant_id = $1
+	`
+	if activeOnly {
+		query += " AND is_active = true"
+	}
+	query += " ORDER BY channel, name"
+
+	rows, err := s.DB.Query(query, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to query campaigns for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	campaigns := []*types.Campaign{}
+	for rows.Next() {
+		c, err := scanCampaign(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan campaign: %v", err)
+			return nil, err
+		}
+		campaigns = append(campaigns, c)
+	}
+
+	return campaigns, rows.Err()
+}
+
+// GetCampaignReport compares a tenant's campaigns and rolls them up per channel.
+// Campaign definitions come from the main database; attribution comes from the tenant database.
+func (s *Store) GetCampaignReport(ctx context.Context, tenantID string, activeOnly bool) (*types.CampaignReport, error) {
+	campaigns, err := s.GetCampaigns(tenantID, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.CampaignReport{
+		Campaigns:   []*types.CampaignPerformance{},
+		Channels:    []*types.ChannelPerformance{},
+		GeneratedAt: time.Now(),
+	}
+	if len(campaigns) == 0 {
+		return report, nil
+	}
+
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	campaignAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	keys := make([]string, 0, len(campaigns))
+	for _, c := range campaigns {
+		keys = append(keys, c.Key)
+	}
+
+	metrics, err := campaignAdapter.GetCampaignMetrics(ctx, db, tc.SchemaPrefix, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := map[string]*types.ChannelPerformance{}
+	for _, c := range campaigns {
+		perf := &types.CampaignPerformance{Campaign: c}
+		if m, ok := metrics[strings.ToLower(c.Key)]; ok {
+			perf.Metrics = *m
+		}
+		perf.Metrics.Finalize()
+		report.Campaigns = append(report.Campaigns, perf)
+
+		channel, ok := channels[c.Channel]
+		if !ok {
+			channel = &types.ChannelPerformance{Channel: c.Channel}
+			channels[c.Channel] = channel
+			report.Channels = append(report.Channels, channel)
+		}
+		channel.Campaigns++
+		channel.Metrics.Add(&perf.Metrics)
+	}
+
+	for _, channel := range report.Channels {
+		channel.Metrics.Finalize()
+	}
+	sort.Slice(report.Channels, func(i, j int) bool {
+		return report.Channels[i].Metrics.NetRevenue > report.Channels[j].Metrics.NetRevenue
+	})
+
+	return report, nil
+}
+
+// scanCampaign scans a campaigns row selected with campaignColumns
+func scanCampaign(row interface{ Scan(...interface{}) error }) (*types.Campaign, error) {
+	c := &types.Campaign{}
+	err := row.Scan(&c.ID, &c.TenantID, &c.Key, &c.Name, &c.Channel, &c.Description, &c.StartsOn, &c.EndsOn,
+		&c.IsActive, &c.CreatedBy, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// isUniqueViolation reports whether err is a PostgreSQL unique constraint violation
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
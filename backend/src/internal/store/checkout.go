@@ -0,0 +1,159 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// GetStripeWebhookSecret returns a tenant's decrypted Stripe webhook signing secret, or "" when none is set
+func (s *Store) GetStripeWebhookSecret(tenantID string) (string, error) {
+	if err := s.requireScope(types.ScopeSecretDecrypt); err != nil {
+		return "", err
+	}
+
+	var encrypted sql.NullString
+	err := s.DB.QueryRow(`
+		SELECT stripe_webhook_secret FROM tenant_connections WHERE tenant_id = $1 AND is_active = true
+	`, tenantID).Scan(&encrypted)
+	if err == sql.ErrNoRows {
+		return "", apperr.NotFound("tenant not found: %s", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get Stripe webhook secret for tenant %s: %v", tenantID, err)
+		return "", err
+	}
+	if !encrypted.Valid || encrypted.String == "" {
+		return "", nil
+	}
+	return crypto.DecryptPassword(encrypted.String)
+}
+
+// UpdateStripeWebhookSecret stores a tenant's Stripe webhook signing secret encrypted, or clears it
+// when secret is empty, and records the change in the tenant's configuration history
+func (s *Store) UpdateStripeWebhookSecret(tenantID, secret string, employeeID uuid.UUID) error {
+	var encrypted *string
+	if secret != "" {
+		value, err := crypto.EncryptPassword(secret)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt Stripe webhook secret: %w", err)
+		}
+		encrypted = &value
+	}
+
+	err := s.ChangeTenantConfig(tenantID, types.TenantConfigActionUpdate, &employeeID, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE tenant_connections
+			SET stripe_webhook_secret = $1, updated_at = NOW()
+			WHERE tenant_id = $2
+		`, encrypted, tenantID)
+		return err
+	})
+	if err != nil {
+		logger.Errorf("Failed to update Stripe webhook secret for tenant %s: %v", tenantID, err)
+		return err
+	}
+
+	logger.Infof("Updated Stripe webhook secret for tenant %s", tenantID)
+	return nil
+}
+
+// RecordCheckoutSession records the payment of a checkout session and, once it is paid with an
+// affiliate's discount code, creates the affiliate's commission through CreateCommission, so fraud
+// rules apply. The rate is the code's commission rate, or else the affiliate's default rate.
+// Replayed sessions update the payment's status, and a payment never gets a second commission.
+// The commission is nil when none was created.
+func (s *Store) RecordCheckoutSession(ctx context.Context, tenantID string, session *types.CheckoutSession) (*types.CheckoutPayment, *types.Commission, error) {
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	checkoutAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	subtotal, discount := session.AmountSubtotal, session.AmountDiscount
+	recorded, err := checkoutAdapter.RecordCheckoutPayment(ctx, db, tc.SchemaPrefix, &types.Payment{
+		FilingID:        session.FilingID,
+		StripeSessionID: session.SessionID,
+		Amount:          session.AmountTotal,
+		OriginalAmount:  &subtotal,
+		DiscountAmount:  &discount,
+		DiscountCode:    session.DiscountCode,
+		Status:          session.PaymentStatus,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if session.DiscountCode == nil || !session.Paid() || recorded.CommissionID != nil {
+		return recorded, nil, nil
+	}
+	netAmount := subtotal - discount
+	if netAmount <= 0 {
+		return recorded, nil, nil
+	}
+
+	code, err := checkoutAdapter.GetDiscountCodeByCode(ctx, db, tc.SchemaPrefix, *session.DiscountCode)
+	if errors.Is(err, apperr.ErrNotFound) {
+		logger.Warningf("Checkout session %s in tenant %s used unknown discount code %q; no commission", session.SessionID, tenantID, *session.DiscountCode)
+		return recorded, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if !code.IsAffiliateCode || code.AffiliateID == nil {
+		return recorded, nil, nil
+	}
+
+	affiliate, err := checkoutAdapter.GetAffiliateByID(ctx, db, tc.SchemaPrefix, code.AffiliateID.String())
+	if err != nil {
+		return nil, nil, err
+	}
+	if !affiliate.IsActive {
+		logger.Warningf("Checkout session %s in tenant %s used code %s of inactive affiliate %s; no commission", session.SessionID, tenantID, code.Code, affiliate.ID)
+		return recorded, nil, nil
+	}
+
+	rate := affiliate.DefaultCommissionRate
+	if code.CommissionRate != nil {
+		rate = *code.CommissionRate
+	}
+
+	note := fmt.Sprintf("Created from Stripe checkout session %s", session.SessionID)
+	commission, err := s.CreateCommission(ctx, tenantID, &types.Commission{
+		AffiliateID:      affiliate.ID,
+		FilingID:         session.FilingID,
+		UserID:           recorded.UserID,
+		DiscountCodeID:   code.ID,
+		PaymentID:        &recorded.Payment.ID,
+		OrderAmount:      subtotal,
+		DiscountAmount:   discount,
+		NetAmount:        netAmount,
+		CommissionRate:   rate,
+		CommissionAmount: netAmount.Percent(rate),
+		Notes:            &note,
+	}, "")
+	if err != nil {
+		return nil, nil, err
+	}
+	recorded.CommissionID = &commission.ID
+
+	logger.Infof("Commission %s (%s) created for affiliate %s from checkout session %s in tenant %s",
+		commission.ID, commission.Status, affiliate.ID, session.SessionID, tenantID)
+	return recorded, commission, nil
+}
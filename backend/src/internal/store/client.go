@@ -1,29 +1,38 @@
 package store
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/sqlident"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
+	"github.com/google/uuid"
 )
 
 // GetClients retrieves all clients for a specific tenant using the appropriate adapter
-func (s *Store) GetClients(tenantID string) ([]*types.Client, error) {
+// Archived clients are excluded unless includeArchived is true
+func (s *Store) GetClients(ctx context.Context, tenantID string, includeArchived bool) ([]*types.Client, error) {
 	logger.Infof("[Store.GetClients] Step 1: Getting tenant DB connection - TenantID: %s", tenantID)
 
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		logger.Errorf("[Store.GetClients] FAILED at Step 1 - TenantID: %s, Error: %v", tenantID, err)
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	logger.Infof("[Store.GetClients] Step 2: Creating adapter - TenantID: %s, AdapterType: %s, SchemaPrefix: %s, DBHost: %s",
 		tenantID, tc.AdapterType, tc.SchemaPrefix, tc.DBHost)
 
 	// Get the appropriate adapter for this tenant
-	clientAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	clientAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("[Store.GetClients] FAILED at Step 2 - TenantID: %s, AdapterType: %s, Error: %v",
 			tenantID, tc.AdapterType, err)
@@ -33,7 +42,7 @@ func (s *Store) GetClients(tenantID string) ([]*types.Client, error) {
 	logger.Infof("[Store.GetClients] Step 3: Fetching clients from adapter - TenantID: %s", tenantID)
 
 	// Use adapter to fetch clients
-	clients, err := clientAdapter.GetClients(db, tc.SchemaPrefix)
+	clients, err := clientAdapter.GetClients(ctx, db, tc.SchemaPrefix, includeArchived)
 	if err != nil {
 		logger.Errorf("[Store.GetClients] FAILED at Step 3 - TenantID: %s, Error: %v", tenantID, err)
 		return nil, err
@@ -43,16 +52,79 @@ func (s *Store) GetClients(tenantID string) ([]*types.Client, error) {
 	return clients, nil
 }
 
+// GetClientPage retrieves one page of clients for a tenant, newest first
+func (s *Store) GetClientPage(ctx context.Context, tenantID string, includeArchived bool, page types.PageRequest) (*types.ClientPage, error) {
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	clientAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter to fetch a page of clients for tenant %s (limit: %d)", tc.AdapterType, tenantID, page.Size())
+
+	return clientAdapter.GetClientPage(ctx, db, tc.SchemaPrefix, includeArchived, page)
+}
+
+// SearchClients returns a tenant's clients best matching a search, each with a summary of its filings
+func (s *Store) SearchClients(ctx context.Context, tenantID string, query *types.ClientSearchQuery) ([]*types.ClientSearchResult, error) {
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	clientAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter to search clients for tenant %s (limit: %d)", tc.AdapterType, tenantID, query.Limit)
+
+	return clientAdapter.SearchClients(ctx, db, tc.SchemaPrefix, query)
+}
+
+// StreamClients calls fn with each of a tenant's clients as it is read, stopping when ctx is done
+func (s *Store) StreamClients(ctx context.Context, tenantID string, includeArchived bool, fn func(*types.Client) error) error {
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	clientAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter to stream clients for tenant %s", tc.AdapterType, tenantID)
+
+	return clientAdapter.StreamClients(ctx, db, tc.SchemaPrefix, includeArchived, fn)
+}
+
 // GetClientByID retrieves a specific client by ID for a tenant using the appropriate adapter
-func (s *Store) GetClientByID(tenantID string, clientID string) (*types.Client, error) {
+func (s *Store) GetClientByID(ctx context.Context, tenantID string, clientID string) (*types.Client, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	clientAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	clientAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -61,19 +133,22 @@ func (s *Store) GetClientByID(tenantID string, clientID string) (*types.Client,
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to fetch client
-	return clientAdapter.GetClientByID(db, tc.SchemaPrefix, clientID)
+	return clientAdapter.GetClientByID(ctx, db, tc.SchemaPrefix, clientID)
 }
 
 // GetClientComprehensive retrieves all data for a client including filings, dependents, etc.
-func (s *Store) GetClientComprehensive(tenantID string, clientID string) (*types.ClientComprehensive, error) {
+func (s *Store) GetClientComprehensive(ctx context.Context, tenantID string, clientID string) (*types.ClientComprehensive, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	clientAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	clientAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -82,19 +157,52 @@ func (s *Store) GetClientComprehensive(tenantID string, clientID string) (*types
 	logger.Infof("Using %s adapter to fetch comprehensive data for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to fetch comprehensive client data
-	return clientAdapter.GetClientComprehensive(db, tc.SchemaPrefix, clientID)
+	comprehensive, err := clientAdapter.GetClientComprehensive(ctx, db, tc.SchemaPrefix, clientID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Derive final-return and deceased-spouse flags independently of the adapter
+	comprehensive.ApplyDeceasedFlags()
+	return comprehensive, nil
+}
+
+// MarkClientDeceased records the death of a client's taxpayer or spouse
+func (s *Store) MarkClientDeceased(ctx context.Context, tenantID string, clientID string, person string, deathDate string) error {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	clientAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	return clientAdapter.MarkClientDeceased(ctx, db, tc.SchemaPrefix, clientID, person, deathDate)
 }
 
 // GetClientsByFilings retrieves clients with their filings (paginated)
-func (s *Store) GetClientsByFilings(tenantID string, limit int, offset int) ([]*types.ClientComprehensive, error) {
+func (s *Store) GetClientsByFilings(ctx context.Context, tenantID string, limit int, offset int) ([]*types.ClientComprehensive, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	clientAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	clientAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -103,5 +211,133 @@ func (s *Store) GetClientsByFilings(tenantID string, limit int, offset int) ([]*
 	logger.Infof("Using %s adapter to fetch clients by filings for tenant %s (limit: %d, offset: %d)", tc.AdapterType, tenantID, limit, offset)
 
 	// Use adapter to fetch clients with filings (paginated)
-	return clientAdapter.GetClientsByFilings(db, tc.SchemaPrefix, limit, offset)
+	return clientAdapter.GetClientsByFilings(ctx, db, tc.SchemaPrefix, limit, offset)
+}
+
+// GetClientsByFilingsPage retrieves one page of clients with filings, most recent filing first
+func (s *Store) GetClientsByFilingsPage(ctx context.Context, tenantID string, page types.PageRequest) (*types.FilingPage, error) {
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	clientAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter to fetch a page of clients by filings for tenant %s (limit: %d)", tc.AdapterType, tenantID, page.Size())
+
+	return clientAdapter.GetClientsByFilingsPage(ctx, db, tc.SchemaPrefix, page)
+}
+
+// StreamClientsByFilings calls fn with each client with filings in turn, stopping when ctx is done
+func (s *Store) StreamClientsByFilings(ctx context.Context, tenantID string, fn func(*types.ClientComprehensive) error) error {
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	clientAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter to stream clients by filings for tenant %s", tc.AdapterType, tenantID)
+
+	return clientAdapter.StreamClientsByFilings(ctx, db, tc.SchemaPrefix, fn)
+}
+
+// ArchiveClient archives a client so they are hidden from default listings and portal access
+func (s *Store) ArchiveClient(ctx context.Context, tenantID string, clientID string, reason string) (*types.Client, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	clientAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	return clientAdapter.ArchiveClient(ctx, db, tc.SchemaPrefix, clientID, reason)
+}
+
+// UnarchiveClient restores an archived client
+func (s *Store) UnarchiveClient(ctx context.Context, tenantID string, clientID string) (*types.Client, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	clientAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	return clientAdapter.UnarchiveClient(ctx, db, tc.SchemaPrefix, clientID)
+}
+
+// IsClientArchived reports whether a client is archived for a tenant
+func (s *Store) IsClientArchived(ctx context.Context, tenantID string, clientID string) (bool, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	clientAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return false, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	return clientAdapter.IsClientArchived(ctx, db, tc.SchemaPrefix, clientID)
+}
+
+// FindClientIDByEmail returns the client of a tax platform tenant with the given email
+func (s *Store) FindClientIDByEmail(ctx context.Context, tenantID string, email string) (uuid.UUID, error) {
+	db, tc, err := s.GetTenantSQLDB(ctx, tenantID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	var clientID uuid.UUID
+	err = db.QueryRowContext(ctx, `SELECT id FROM `+sqlident.Schema(tc.SchemaPrefix)+`.user WHERE email = $1 LIMIT 1`, email).Scan(&clientID)
+	if err == sql.ErrNoRows {
+		return uuid.Nil, apperr.NotFound("client not found with email: %s", email)
+	}
+	if err != nil {
+		logger.Errorf("Failed to find client by email in tenant %s: %v", tenantID, err)
+		return uuid.Nil, err
+	}
+	return clientID, nil
 }
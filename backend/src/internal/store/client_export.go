@@ -0,0 +1,126 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/anonymize"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// ExportClient copies one client's tenant rows with PII anonymized, for reproducing a bug locally.
+// The export is attributed to the employee and recorded in the audit log.
+func (s *Store) ExportClient(ctx context.Context, tenantID string, clientID string, employee *types.Employee, anonymizer *anonymize.Anonymizer) (*types.ClientExport, error) {
+	if err := s.requireScope(types.ScopeClientExport); err != nil {
+		return nil, err
+	}
+	// Encrypted SSNs are decrypted before they are scrambled
+	if err := s.requireScope(types.ScopeSSNDecrypt); err != nil {
+		return nil, err
+	}
+	if !employee.HasCapability(types.CapabilityClientData) {
+		return nil, apperr.Permission("employee %s may not access client data", employee.Email)
+	}
+
+	clientUUID, err := uuid.Parse(clientID)
+	if err != nil {
+		return nil, apperr.Validation("invalid client ID: %s", clientID)
+	}
+
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	exportAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	tables, err := exportAdapter.ExportClientRows(ctx, db, tc.SchemaPrefix, clientID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tables) == 0 || len(tables[0].Rows) == 0 {
+		return nil, apperr.NotFound("client not found: %s", clientID)
+	}
+
+	export := &types.ClientExport{
+		Version:      types.ClientExportVersion,
+		AdapterType:  tc.AdapterType,
+		SourceTenant: tenantID,
+		ClientID:     clientUUID,
+		ExportedAt:   time.Now().UTC(),
+		ExportedBy:   employee.Email,
+		Tables:       tables,
+	}
+	if err := anonymizer.Export(export); err != nil {
+		logger.Errorf("Failed to anonymize export of client %s in tenant %s: %v", clientID, tenantID, err)
+		return nil, err
+	}
+
+	rows := 0
+	for _, table := range tables {
+		rows += len(table.Rows)
+	}
+	details := map[string]interface{}{
+		"purpose": "support_reproduction",
+		"tables":  len(tables),
+		"rows":    rows,
+	}
+	if err := s.CreateAuditLog(employee.ID, tenantID, &clientUUID, types.AuditActionExport, types.AuditResourceClient, &clientUUID, details, nil, nil); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Exported client %s of tenant %s for %s (%d rows)", clientID, tenantID, employee.Email, rows)
+	return export, nil
+}
+
+// ImportClient inserts an anonymized export into a tenant and returns the number of rows inserted.
+// Rows that already exist are skipped, so the same export can be imported again.
+func (s *Store) ImportClient(ctx context.Context, tenantID string, export *types.ClientExport) (int, error) {
+	if err := s.requireScope(types.ScopeClientExport); err != nil {
+		return 0, err
+	}
+	if export.Version != types.ClientExportVersion {
+		return 0, apperr.Validation("unsupported client export version %d (expected %d)", export.Version, types.ClientExportVersion)
+	}
+
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+	if tc.AdapterType != export.AdapterType {
+		return 0, apperr.Validation("export is from a %s tenant but tenant %s uses %s", export.AdapterType, tenantID, tc.AdapterType)
+	}
+
+	importAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return 0, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	// SSNs are stored under this tenant's data key, whatever key the source tenant used
+	inserted, err := importAdapter.ImportClientRows(ctx, db, tc.SchemaPrefix, export.Tables, func(value string) (string, bool, error) {
+		return crypto.ReencryptTenantSSN(tenantID, value)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	logger.Infof("Imported client %s from tenant %s into tenant %s (%d rows)", export.ClientID, export.SourceTenant, tenantID, inserted)
+	return inserted, nil
+}
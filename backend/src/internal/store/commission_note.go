@@ -0,0 +1,157 @@
+package store
+
+import (
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// AddCommissionNote appends a note to a commission; notes are never edited or removed
+func (s *Store) AddCommissionNote(note *types.CommissionNote) error {
+	err := s.DB.QueryRow(`
+		INSERT INTO commission_notes (tenant_id, commission_id, author_id, body)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, note.TenantID, note.CommissionID, note.AuthorID, note.Body).Scan(&note.ID, &note.CreatedAt)
+	if err != nil {
+		logger.Errorf("Failed to add note to commission %s: %v", note.CommissionID, err)
+		return err
+	}
+
+	logger.Infof("Added note %s to commission %s in tenant %s", note.ID, note.CommissionID, note.TenantID)
+	return nil
+}
+
+// GetCommissionNotes lists the notes of a commission, oldest first
+func (s *Store) GetCommissionNotes(tenantID string, commissionID uuid.UUID) ([]*types.CommissionNote, error) {
+	rows, err := s.DB.Query(`
+		SELECT n.id, n.tenant_id, n.commission_id, n.author_id, e.email, n.body, n.created_at
+		FROM commission_notes n
+		LEFT JOIN employees e ON e.id = n.author_id
+		WHERE n.tenant_id = $1 AND n.commission_id = $2
+		ORDER BY n.created_at, n.id
+	`, tenantID, commissionID)
+	if err != nil {
+		logger.Errorf("Failed to query notes for commission %s: %v", commissionID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	notes := []*types.CommissionNote{}
+	for rows.Next() {
+		n := &types.CommissionNote{}
+		if err := rows.Scan(&n.ID, &n.TenantID, &n.CommissionID, &n.AuthorID, &n.AuthorEmail, &n.Body, &n.CreatedAt); err != nil {
+			logger.Errorf("Failed to scan commission note: %v", err)
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+
+	return notes, rows.Err()
+}
+
+// AddCommissionTag tags a commission; adding a tag it already carries is a no-op
+func (s *Store) AddCommissionTag(tenantID string, commissionID uuid.UUID, tag string, createdBy uuid.UUID) error {
+	_, err := s.DB.Exec(`
+		INSERT INTO commission_tags (tenant_id, commission_id, tag, created_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, commission_id, tag) DO NOTHING
+	`, tenantID, commissionID, tag, createdBy)
+	if err != nil {
+		logger.Errorf("Failed to tag commission %s with %s: %v", commissionID, tag, err)
+		return err
+	}
+
+	logger.Infof("Tagged commission %s in tenant %s with %s", commissionID, tenantID, tag)
+	return nil
+}
+
+// RemoveCommissionTag removes a tag from a commission
+func (s *Store) RemoveCommissionTag(tenantID string, commissionID uuid.UUID, tag string) error {
+	result, err := s.DB.Exec(`
+		DELETE FROM commission_tags
+		WHERE tenant_id = $1 AND commission_id = $2 AND tag = $3
+	`, tenantID, commissionID, tag)
+	if err != nil {
+		logger.Errorf("Failed to remove tag %s from commission %s: %v", tag, commissionID, err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return apperr.NotFound("commission %s is not tagged %s", commissionID, tag)
+	}
+
+	logger.Infof("Removed tag %s from commission %s in tenant %s", tag, commissionID, tenantID)
+	return nil
+}
+
+// AttachCommissionTags populates the Tags of the given commissions
+func (s *Store) AttachCommissionTags(tenantID string, commissions []*types.Commission) error {
+	if len(commissions) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(commissions))
+	byID := make(map[uuid.UUID]*types.Commission, len(commissions))
+	for _, c := range commissions {
+		ids = append(ids, c.ID.String())
+		byID[c.ID] = c
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT commission_id, tag
+		FROM commission_tags
+		WHERE tenant_id = $1 AND commission_id = ANY($2::uuid[])
+		ORDER BY tag
+	`, tenantID, pq.Array(ids))
+	if err != nil {
+		logger.Errorf("Failed to query commission tags for tenant %s: %v", tenantID, err)
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var commissionID uuid.UUID
+		var tag string
+		if err := rows.Scan(&commissionID, &tag); err != nil {
+			logger.Errorf("Failed to scan commission tag: %v", err)
+			return err
+		}
+		if c, ok := byID[commissionID]; ok {
+			c.Tags = append(c.Tags, tag)
+		}
+	}
+
+	return rows.Err()
+}
+
+// getTaggedCommissionIDs returns the IDs of a tenant's commissions carrying a tag
+func (s *Store) getTaggedCommissionIDs(tenantID string, tag string) ([]string, error) {
+	rows, err := s.DB.Query(`
+		SELECT commission_id FROM commission_tags
+		WHERE tenant_id = $1 AND tag = $2
+	`, tenantID, tag)
+	if err != nil {
+		logger.Errorf("Failed to query commissions tagged %s for tenant %s: %v", tag, tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// GetCommissionSLA returns the effective commission approval SLA for a tenant
+func (s *Store) GetCommissionSLA(tenantID string) (*types.CommissionSLA, error) {
+	tc, err := s.getTenantConnection(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tc.CommissionSLA == nil {
+		return types.DefaultCommissionSLA(), nil
+	}
+	return tc.CommissionSLA, nil
+}
+
+// UpdateCommissionSLA replaces the commission approval SLA for a tenant and records the change
+// in the tenant's configuration history
+func (s *Store) UpdateCommissionSLA(tenantID string, sla *types.CommissionSLA, employeeID uuid.UUID) error {
+	data, err := json.Marshal(sla)
+	if err != nil {
+		return fmt.Errorf("failed to encode commission SLA: %w", err)
+	}
+
+	query := `
+		UPDATE tenant_connections
+		SET commission_sla = $1, updated_at = NOW()
+		WHERE tenant_id = $2
+	`
+
+	err = s.ChangeTenantConfig(tenantID, types.TenantConfigActionUpdate, &employeeID, func(tx *sql.Tx) error {
+		_, err := tx.Exec(query, string(data), tenantID)
+		return err
+	})
+	if err != nil {
+		logger.Errorf("Failed to update commission SLA for tenant %s: %v", tenantID, err)
+		return err
+	}
+
+	logger.Infof("Updated commission SLA for tenant %s", tenantID)
+	return nil
+}
+
+// GetOverdueCommissions returns up to limit of a tenant's PENDING commissions older than its
+// approval SLA, oldest first. The SLA is applied even when its alerts are disabled.
+func (s *Store) GetOverdueCommissions(ctx context.Context, tenantID string, limit int) (*types.OverdueCommissionReport, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	affiliateAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	sla := tc.CommissionSLA
+	if sla == nil {
+		sla = types.DefaultCommissionSLA()
+	}
+
+	now := time.Now()
+	commissions, total, err := affiliateAdapter.GetOverdueCommissions(ctx, db, tc.SchemaPrefix, now.AddDate(0, 0, -sla.PendingDays), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &types.OverdueCommissionReport{
+		PendingDays: sla.PendingDays,
+		TotalCount:  total,
+		Commissions: make([]*types.OverdueCommission, 0, len(commissions)),
+	}
+	for _, c := range commissions {
+		report.Commissions = append(report.Commissions, &types.OverdueCommission{
+			Commission:  c,
+			DaysPending: int(now.Sub(c.CreatedAt).Hours() / 24),
+		})
+	}
+	return report, nil
+}
+
+// RecordCommissionSLAAlert tracks which of a tenant's commissions are overdue and reports whether
+// admins should be alerted: some are newly overdue, or reminderDays have passed since the last
+// alert about one of them (0 never reminds). When it returns true every listed commission is
+// recorded as alerted now. Commissions no longer listed are forgotten, so they alert again if
+// they become overdue again.
+func (s *Store) RecordCommissionSLAAlert(tenantID string, commissionIDs []uuid.UUID, reminderDays int) (bool, error) {
+	ids := make([]string, len(commissionIDs))
+	for i, id := range commissionIDs {
+		ids[i] = id.String()
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		logger.Errorf("Failed to begin commission SLA alert for tenant %s: %v", tenantID, err)
+		return false, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		DELETE FROM commission_sla_alerts
+		WHERE tenant_id = $1 AND NOT (commission_id = ANY($2::uuid[]))
+	`, tenantID, pq.Array(ids))
+	if err != nil {
+		logger.Errorf("Failed to clear resolved commission SLA alerts for tenant %s: %v", tenantID, err)
+		return false, err
+	}
+
+	var due bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1
+			FROM unnest($2::uuid[]) AS overdue(commission_id)
+			LEFT JOIN commission_sla_alerts a
+			       ON a.tenant_id = $1 AND a.commission_id = overdue.commission_id
+			WHERE a.commission_id IS NULL
+			   OR ($3 > 0 AND a.last_alerted_at <= NOW() - $3 * INTERVAL '1 day')
+		)
+	`, tenantID, pq.Array(ids), reminderDays).Scan(&due)
+	if err != nil {
+		logger.Errorf("Failed to check commission SLA alerts for tenant %s: %v", tenantID, err)
+		return false, err
+	}
+	if !due {
+		return false, tx.Commit()
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO commission_sla_alerts (tenant_id, commission_id)
+		SELECT $1, commission_id FROM unnest($2::uuid[]) AS overdue(commission_id)
+		ON CONFLICT (tenant_id, commission_id) DO UPDATE SET last_alerted_at = NOW()
+	`, tenantID, pq.Array(ids))
+	if err != nil {
+		logger.Errorf("Failed to record commission SLA alert for tenant %s: %v", tenantID, err)
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Errorf("Failed to commit commission SLA alert for tenant %s: %v", tenantID, err)
+		return false, err
+	}
+	return true, nil
+}
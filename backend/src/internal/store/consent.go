@@ -0,0 +1,237 @@
+package store
+
+import (
+	"database/sql"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// CreateConsentTemplate publishes a new version of a tenant's consent wording for a purpose.
+// The previously active version is retired; existing grants stay valid.
+func (s *Store) CreateConsentTemplate(t *types.ConsentTemplate) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE consent_templates SET is_active = false
+		WHERE tenant_id = $1 AND purpose = $2 AND is_active = true
+	`, t.TenantID, t.Purpose)
+	if err != nil {
+		logger.Errorf("Failed to retire consent templates for %s/%s: %v", t.TenantID, t.Purpose, err)
+		return err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO consent_templates (tenant_id, purpose, version, title, body, created_by)
+		SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5
+		FROM consent_templates
+		WHERE tenant_id = $1 AND purpose = $2
+		RETURNING id, version, is_active, created_at
+	`, t.TenantID, t.Purpose, t.Title, t.Body, t.CreatedBy).Scan(&t.ID, &t.Version, &t.IsActive, &t.CreatedAt)
+	if err != nil {
+		logger.Errorf("Failed to create consent template: %v", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logger.Infof("Published %s consent template v%d for tenant %s", t.Purpose, t.Version, t.TenantID)
+	return nil
+}
+
+// GetConsentTemplates lists a tenant's consent templates, optionally only the active versions
+func (s *Store) GetConsentTemplates(tenantID string, activeOnly bool) ([]*types.ConsentTemplate, error) {
+	query := `
+		SELECT id, tenant_id, purpose, version, title, body, is_active, created_by, created_at
+		FROM consent_templates
+		WHERE tenant_id = $1
+	`
+	if activeOnly {
+		query += " AND is_active = true"
+	}
+	query += " ORDER BY purpose, version DESC"
+
+	rows, err := s.DB.Query(query, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to query consent templates for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []*types.ConsentTemplate
+	for rows.Next() {
+		t := &types.ConsentTemplate{}
+		if err := rows.Scan(&t.ID, &t.TenantID, &t.Purpose, &t.Version, &t.Title, &t.Body, &t.IsActive, &t.CreatedBy, &t.CreatedAt); err != nil {
+			logger.Errorf("Failed to scan consent template: %v", err)
+			return nil, err
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, rows.Err()
+}
+
+// GrantConsent records a taxpayer's consent against an active template.
+// A prior active consent for the same purpose is superseded.
+func (s *Store) GrantConsent(tenantID string, clientID, templateID uuid.UUID, tenantUserID *uuid.UUID, ipAddress, userAgent *string) (*types.ClientConsent, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	consent := &types.ClientConsent{
+		TenantID:     tenantID,
+		ClientID:     clientID,
+		TemplateID:   templateID,
+		TenantUserID: tenantUserID,
+		GrantedIP:    ipAddress,
+	}
+
+	err = tx.QueryRow(`
+		SELECT purpose, version FROM consent_templates
+		WHERE id = $1 AND tenant_id = $2 AND is_active = true
+	`, templateID, tenantID).Scan(&consent.Purpose, &consent.Version)
+	if err == sql.ErrNoRows {
+		return nil, apperr.Conflict("consent template not found or no longer active: %s", templateID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to load consent template %s: %v", templateID, err)
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE client_consents SET revoked_at = NOW(), revoked_ip = $4
+		WHERE tenant_id = $1 AND client_id = $2 AND purpose = $3 AND revoked_at IS NULL
+	`, tenantID, clientID, consent.Purpose, ipAddress)
+	if err != nil {
+		logger.Errorf("Failed to supersede consent for client %s: %v", clientID, err)
+		return nil, err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO client_consents (tenant_id, client_id, template_id, purpose, tenant_user_id, granted_ip, granted_user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, granted_at
+	`, tenantID, clientID, templateID, consent.Purpose, tenantUserID, ipAddress, userAgent).Scan(&consent.ID, &consent.GrantedAt)
+	if err != nil {
+		logger.Errorf("Failed to record consent for client %s: %v", clientID, err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Client %s granted %s consent (template v%d) in tenant %s", clientID, consent.Purpose, consent.Version, tenantID)
+	return consent, nil
+}
+
+// RevokeConsent withdraws a taxpayer's active consent for a purpose
+func (s *Store) RevokeConsent(tenantID string, clientID uuid.UUID, purpose string, ipAddress *string) error {
+	result, err := s.DB.Exec(`
+		UPDATE client_consents SET revoked_at = NOW(), revoked_ip = $4
+		WHERE tenant_id = $1 AND client_id = $2 AND purpose = $3 AND revoked_at IS NULL
+	`, tenantID, clientID, purpose, ipAddress)
+	if err != nil {
+		logger.Errorf("Failed to revoke %s consent for client %s: %v", purpose, clientID, err)
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return apperr.NotFound("no active %s consent for client %s", purpose, clientID)
+	}
+
+	logger.Infof("Client %s revoked %s consent in tenant %s", clientID, purpose, tenantID)
+	return nil
+}
+
+// GetClientConsents returns a client's full consent history, newest first
+func (s *Store) GetClientConsents(tenantID string, clientID uuid.UUID) ([]*types.ClientConsent, error) {
+	rows, err := s.DB.Query(`
+		SELECT c.id, c.tenant_id, c.client_id, c.template_id, t.version, c.purpose, c.tenant_user_id,
+		       c.granted_at, host(c.granted_ip), c.revoked_at, host(c.revoked_ip)
+		FROM client_consents c
+		JOIN consent_templates t ON t.id = c.template_id
+		WHERE c.tenant_id = $1 AND c.client_id = $2
+		ORDER BY c.granted_at DESC
+	`, tenantID, clientID)
+	if err != nil {
+		logger.Errorf("Failed to query consents for client %s: %v", clientID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var consents []*types.ClientConsent
+	for rows.Next() {
+		c := &types.ClientConsent{}
+		err := rows.Scan(&c.ID, &c.TenantID, &c.ClientID, &c.TemplateID, &c.Version, &c.Purpose, &c.TenantUserID,
+			&c.GrantedAt, &c.GrantedIP, &c.RevokedAt, &c.RevokedIP)
+		if err != nil {
+			logger.Errorf("Failed to scan consent: %v", err)
+			return nil, err
+		}
+		consents = append(consents, c)
+	}
+
+	return consents, rows.Err()
+}
+
+// HasActiveConsent reports whether a client currently consents to a purpose
+func (s *Store) HasActiveConsent(tenantID string, clientID uuid.UUID, purpose string) (bool, error) {
+	var exists bool
+	err := s.DB.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM client_consents
+			WHERE tenant_id = $1 AND client_id = $2 AND purpose = $3 AND revoked_at IS NULL
+		)
+	`, tenantID, clientID, purpose).Scan(&exists)
+	if err != nil {
+		logger.Errorf("Failed to check %s consent for client %s: %v", purpose, clientID, err)
+		return false, err
+	}
+	return exists, nil
+}
+
+// GetConsentingClients returns which of the given clients currently consent to a purpose
+func (s *Store) GetConsentingClients(tenantID, purpose string, clientIDs []uuid.UUID) (map[uuid.UUID]bool, error) {
+	consenting := map[uuid.UUID]bool{}
+	if len(clientIDs) == 0 {
+		return consenting, nil
+	}
+
+	ids := make([]string, 0, len(clientIDs))
+	for _, id := range clientIDs {
+		ids = append(ids, id.String())
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT client_id FROM client_consents
+		WHERE tenant_id = $1 AND purpose = $2 AND revoked_at IS NULL AND client_id = ANY($3::uuid[])
+	`, tenantID, purpose, pq.Array(ids))
+	if err != nil {
+		logger.Errorf("Failed to query %s consents: %v", purpose, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		consenting[id] = true
+	}
+
+	return consenting, rows.Err()
+}
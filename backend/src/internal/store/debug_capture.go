@@ -0,0 +1,204 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+const debugCaptureColumns = `id, tenant_id, reason, enabled_by, enabled_at, expires_at, disabled_at, disabled_by`
+
+// scanDebugCapture scans a row selected with debugCaptureColumns
+func scanDebugCapture(row interface{ Scan(...interface{}) error }) (*types.DebugCapture, error) {
+	capture := &types.DebugCapture{}
+	err := row.Scan(&capture.ID, &capture.TenantID, &capture.Reason, &capture.EnabledBy, &capture.EnabledAt,
+		&capture.ExpiresAt, &capture.DisabledAt, &capture.DisabledBy)
+	if err != nil {
+		return nil, err
+	}
+	return capture, nil
+}
+
+// EnableDebugCapture starts capturing a tenant's requests for duration, replacing any capture
+// already running
+func (s *Store) EnableDebugCapture(tenantID, reason string, duration time.Duration, employeeID uuid.UUID) (*types.DebugCapture, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE debug_captures
+		SET disabled_at = NOW(), disabled_by = $2
+		WHERE tenant_id = $1 AND disabled_at IS NULL AND expires_at > NOW()
+	`, tenantID, employeeID)
+	if err != nil {
+		logger.Errorf("Failed to end debug captures of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	capture, err := scanDebugCapture(tx.QueryRow(`
+		INSERT INTO debug_captures (tenant_id, reason, enabled_by, expires_at)
+		VALUES ($1, $2, $3, NOW() + make_interval(secs => $4))
+		RETURNING `+debugCaptureColumns,
+		tenantID, reason, employeeID, duration.Seconds()))
+	if err != nil {
+		logger.Errorf("Failed to enable debug capture for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Debug capture %s enabled for tenant %s until %s by %s", capture.ID, tenantID, capture.ExpiresAt, employeeID)
+	return capture, nil
+}
+
+// GetActiveDebugCapture returns the capture running for a tenant
+func (s *Store) GetActiveDebugCapture(tenantID string) (*types.DebugCapture, error) {
+	capture, err := scanDebugCapture(s.DB.QueryRow(`
+		SELECT `+debugCaptureColumns+`
+		FROM debug_captures
+		WHERE tenant_id = $1 AND disabled_at IS NULL AND expires_at > NOW()
+		ORDER BY enabled_at DESC
+		LIMIT 1
+	`, tenantID))
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("no debug capture is running for tenant %s", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get debug capture for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	return capture, nil
+}
+
+// DisableDebugCapture stops the capture running for a tenant before it expires
+func (s *Store) DisableDebugCapture(tenantID string, employeeID uuid.UUID) (*types.DebugCapture, error) {
+	capture, err := scanDebugCapture(s.DB.QueryRow(`
+		UPDATE debug_captures
+		SET disabled_at = NOW(), disabled_by = $2
+		WHERE tenant_id = $1 AND disabled_at IS NULL AND expires_at > NOW()
+		RETURNING `+debugCaptureColumns,
+		tenantID, employeeID))
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("no debug capture is running for tenant %s", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to disable debug capture for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	logger.Infof("Debug capture %s disabled for tenant %s by %s", capture.ID, tenantID, employeeID)
+	return capture, nil
+}
+
+// encryptOptional encrypts a value that may be absent
+func encryptOptional(value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	encrypted, err := crypto.EncryptPassword(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &encrypted, nil
+}
+
+// decryptOptional decrypts a value that may be absent
+func decryptOptional(value *string) (*string, error) {
+	if value == nil {
+		return nil, nil
+	}
+	decrypted, err := crypto.DecryptPassword(*value)
+	if err != nil {
+		return nil, err
+	}
+	return &decrypted, nil
+}
+
+// RecordDebugCaptureEntry stores a captured request. Bodies should already be redacted; they
+// are encrypted at rest.
+func (s *Store) RecordDebugCaptureEntry(entry *types.DebugCaptureEntry) error {
+	requestBody, err := encryptOptional(entry.RequestBody)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt captured request body: %w", err)
+	}
+	responseBody, err := encryptOptional(entry.ResponseBody)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt captured response body: %w", err)
+	}
+
+	err = s.DB.QueryRow(`
+		INSERT INTO debug_capture_entries (capture_id, tenant_id, method, path, query, status, duration_ms, request_body, response_body)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, captured_at
+	`, entry.CaptureID, entry.TenantID, entry.Method, entry.Path, entry.Query, entry.Status, entry.DurationMs,
+		requestBody, responseBody).Scan(&entry.ID, &entry.CapturedAt)
+	if err != nil {
+		logger.Errorf("Failed to record debug capture entry for tenant %s: %v", entry.TenantID, err)
+		return err
+	}
+	return nil
+}
+
+// GetDebugCaptureEntries lists a tenant's captured requests with decrypted bodies, newest first
+func (s *Store) GetDebugCaptureEntries(tenantID string, limit int) ([]*types.DebugCaptureEntry, error) {
+	if err := s.requireScope(types.ScopeDebugCaptureRead); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT id, capture_id, tenant_id, method, path, query, status, duration_ms, request_body, response_body, captured_at
+		FROM debug_capture_entries
+		WHERE tenant_id = $1
+		ORDER BY captured_at DESC
+		LIMIT $2
+	`, tenantID, limit)
+	if err != nil {
+		logger.Errorf("Failed to get debug capture entries for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*types.DebugCaptureEntry{}
+	for rows.Next() {
+		entry := &types.DebugCaptureEntry{}
+		var requestBody, responseBody *string
+		err := rows.Scan(&entry.ID, &entry.CaptureID, &entry.TenantID, &entry.Method, &entry.Path, &entry.Query,
+			&entry.Status, &entry.DurationMs, &requestBody, &responseBody, &entry.CapturedAt)
+		if err != nil {
+			logger.Errorf("Failed to scan debug capture entry: %v", err)
+			return nil, err
+		}
+		if entry.RequestBody, err = decryptOptional(requestBody); err != nil {
+			return nil, fmt.Errorf("failed to decrypt captured request body %s: %w", entry.ID, err)
+		}
+		if entry.ResponseBody, err = decryptOptional(responseBody); err != nil {
+			return nil, fmt.Errorf("failed to decrypt captured response body %s: %w", entry.ID, err)
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
+
+// PurgeExpiredDebugCaptureEntries deletes captured requests older than the retention period
+func (s *Store) PurgeExpiredDebugCaptureEntries() (int64, error) {
+	result, err := s.DB.Exec(`
+		DELETE FROM debug_capture_entries WHERE captured_at < NOW() - make_interval(secs => $1)
+	`, types.DebugCaptureRetention.Seconds())
+	if err != nil {
+		logger.Errorf("Failed to purge debug capture entries: %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
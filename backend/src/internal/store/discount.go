@@ -1,23 +1,30 @@
 package store
 
 import (
+	"context"
+	"crypto/rand"
+	"errors"
 	"fmt"
 	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/apperr"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
 )
 
-// GetDiscountCodes retrieves discount codes for a tenant, optionally filtered by affiliate
-func (s *Store) GetDiscountCodes(tenantID string, affiliateID *string, activeOnly bool) ([]*types.DiscountCode, error) {
+// GetDiscountCodes retrieves discount codes for a tenant, optionally filtered by affiliate and campaign
+func (s *Store) GetDiscountCodes(ctx context.Context, tenantID string, affiliateID *string, campaign *string, activeOnly bool) ([]*types.DiscountCode, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	adpt, err := adapter.NewAdapter(tc.AdapterType)
+	adpt, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -26,19 +33,41 @@ func (s *Store) GetDiscountCodes(tenantID string, affiliateID *string, activeOnl
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to fetch discount codes
-	return adpt.GetDiscountCodes(db, tc.SchemaPrefix, affiliateID, activeOnly)
+	return adpt.GetDiscountCodes(ctx, db, tc.SchemaPrefix, affiliateID, campaign, activeOnly)
+}
+
+// GetDiscountCodePage retrieves one page of discount codes, newest first, with the GetDiscountCodes filters
+func (s *Store) GetDiscountCodePage(ctx context.Context, tenantID string, affiliateID *string, campaign *string, activeOnly bool, page types.PageRequest) (*types.DiscountCodePage, error) {
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	adpt, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	return adpt.GetDiscountCodePage(ctx, db, tc.SchemaPrefix, affiliateID, campaign, activeOnly, page)
 }
 
 // GetDiscountCodeByID retrieves a specific discount code by ID
-func (s *Store) GetDiscountCodeByID(tenantID string, codeID string) (*types.DiscountCode, error) {
+func (s *Store) GetDiscountCodeByID(ctx context.Context, tenantID string, codeID string) (*types.DiscountCode, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	adpt, err := adapter.NewAdapter(tc.AdapterType)
+	adpt, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -47,19 +76,22 @@ func (s *Store) GetDiscountCodeByID(tenantID string, codeID string) (*types.Disc
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to fetch discount code
-	return adpt.GetDiscountCodeByID(db, tc.SchemaPrefix, codeID)
+	return adpt.GetDiscountCodeByID(ctx, db, tc.SchemaPrefix, codeID)
 }
 
 // GetDiscountCodeByCode retrieves a discount code by its code string
-func (s *Store) GetDiscountCodeByCode(tenantID string, code string) (*types.DiscountCode, error) {
+func (s *Store) GetDiscountCodeByCode(ctx context.Context, tenantID string, code string) (*types.DiscountCode, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	adpt, err := adapter.NewAdapter(tc.AdapterType)
+	adpt, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -68,19 +100,22 @@ func (s *Store) GetDiscountCodeByCode(tenantID string, code string) (*types.Disc
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to fetch discount code
-	return adpt.GetDiscountCodeByCode(db, tc.SchemaPrefix, code)
+	return adpt.GetDiscountCodeByCode(ctx, db, tc.SchemaPrefix, code)
 }
 
 // CreateDiscountCode creates a new discount code for an affiliate
-func (s *Store) CreateDiscountCode(tenantID string, discountCode *types.DiscountCode) (*types.DiscountCode, error) {
+func (s *Store) CreateDiscountCode(ctx context.Context, tenantID string, discountCode *types.DiscountCode) (*types.DiscountCode, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	adpt, err := adapter.NewAdapter(tc.AdapterType)
+	adpt, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -89,19 +124,22 @@ func (s *Store) CreateDiscountCode(tenantID string, discountCode *types.Discount
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to create discount code
-	return adpt.CreateDiscountCode(db, tc.SchemaPrefix, discountCode)
+	return adpt.CreateDiscountCode(ctx, db, tc.SchemaPrefix, discountCode)
 }
 
 // UpdateDiscountCode updates an existing discount code
-func (s *Store) UpdateDiscountCode(tenantID string, codeID string, discountCode *types.DiscountCode) (*types.DiscountCode, error) {
+func (s *Store) UpdateDiscountCode(ctx context.Context, tenantID string, codeID string, discountCode *types.DiscountCode) (*types.DiscountCode, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	adpt, err := adapter.NewAdapter(tc.AdapterType)
+	adpt, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -110,19 +148,41 @@ func (s *Store) UpdateDiscountCode(tenantID string, codeID string, discountCode
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to update discount code
-	return adpt.UpdateDiscountCode(db, tc.SchemaPrefix, codeID, discountCode)
+	return adpt.UpdateDiscountCode(ctx, db, tc.SchemaPrefix, codeID, discountCode)
+}
+
+// PatchDiscountCode changes only the discount code fields the patch sets
+func (s *Store) PatchDiscountCode(ctx context.Context, tenantID string, codeID string, patch *types.DiscountCodePatch) (*types.DiscountCode, error) {
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	adpt, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	return adpt.PatchDiscountCode(ctx, db, tc.SchemaPrefix, codeID, patch)
 }
 
 // DeactivateDiscountCode deactivates a discount code
-func (s *Store) DeactivateDiscountCode(tenantID string, codeID string) error {
+func (s *Store) DeactivateDiscountCode(ctx context.Context, tenantID string, codeID string) error {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	adpt, err := adapter.NewAdapter(tc.AdapterType)
+	adpt, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return fmt.Errorf("failed to create adapter: %w", err)
@@ -131,5 +191,110 @@ func (s *Store) DeactivateDiscountCode(tenantID string, codeID string) error {
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to deactivate discount code
-	return adpt.DeactivateDiscountCode(db, tc.SchemaPrefix, codeID)
+	return adpt.DeactivateDiscountCode(ctx, db, tc.SchemaPrefix, codeID)
+}
+
+// discountSuffixAlphabet omits characters that are easily confused on printed mailers (0/O, 1/I/L)
+const discountSuffixAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// generateAttempts bounds how often a batch is regenerated when a code collides with an existing one
+const generateAttempts = 3
+
+// GenerateDiscountCodes creates count unique codes of the form prefix + random suffix.
+// Every code copies the template's discount terms and campaign; the batch is created atomically.
+func (s *Store) GenerateDiscountCodes(ctx context.Context, tenantID string, template *types.DiscountCode, prefix string, suffixLength int, count int) ([]*types.DiscountCode, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	adpt, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	for attempt := 1; ; attempt++ {
+		codes, err := randomDiscountCodes(prefix, suffixLength, count)
+		if err != nil {
+			return nil, err
+		}
+
+		batch := make([]*types.DiscountCode, 0, count)
+		for _, code := range codes {
+			dc := *template
+			dc.Code = code
+			batch = append(batch, &dc)
+		}
+
+		created, err := adpt.CreateDiscountCodes(ctx, db, tc.SchemaPrefix, batch)
+		if err == nil {
+			return created, nil
+		}
+		if !errors.Is(err, apperr.ErrConflict) || attempt == generateAttempts {
+			return nil, err
+		}
+		logger.Warningf("Generated discount codes for tenant %s collided with existing codes, retrying (attempt %d)", tenantID, attempt)
+	}
+}
+
+// randomDiscountCodes returns count distinct codes of prefix + suffixLength random characters
+func randomDiscountCodes(prefix string, suffixLength int, count int) ([]string, error) {
+	// Bytes at or above this bound are discarded so every character is equally likely
+	bound := 256 - 256%len(discountSuffixAlphabet)
+
+	seen := make(map[string]bool, count)
+	codes := make([]string, 0, count)
+	buf := make([]byte, 32)
+
+	for len(codes) < count {
+		suffix := make([]byte, 0, suffixLength)
+		for len(suffix) < suffixLength {
+			if _, err := rand.Read(buf); err != nil {
+				return nil, fmt.Errorf("failed to generate discount code: %w", err)
+			}
+			for _, b := range buf {
+				if int(b) < bound && len(suffix) < suffixLength {
+					suffix = append(suffix, discountSuffixAlphabet[int(b)%len(discountSuffixAlphabet)])
+				}
+			}
+		}
+		code := prefix + string(suffix)
+		if !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+
+	return codes, nil
+}
+
+// GetDiscountCampaignReports summarizes discount code usage per campaign (or for one campaign)
+func (s *Store) GetDiscountCampaignReports(ctx context.Context, tenantID string, campaign *string) ([]*types.DiscountCampaignReport, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	adpt, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	return adpt.GetDiscountCampaignReports(ctx, db, tc.SchemaPrefix, campaign)
 }
@@ -1,23 +1,31 @@
 package store
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/sqlident"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
+	"github.com/google/uuid"
 )
 
 // CreateDocument creates a new document record in the tenant's database
-func (s *Store) CreateDocument(tenantID string, document *types.Document) (*types.Document, error) {
+func (s *Store) CreateDocument(ctx context.Context, tenantID string, document *types.Document) (*types.Document, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	documentAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	documentAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -25,20 +33,37 @@ func (s *Store) CreateDocument(tenantID string, document *types.Document) (*type
 
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
+	// Filings of archived tax years take no new documents
+	if document.FilingID != nil {
+		if err := s.checkFilingWritable(ctx, tenantID, db, tc, documentAdapter, document.FilingID.String()); err != nil {
+			return nil, err
+		}
+	}
+
 	// Use adapter to create document
-	return documentAdapter.CreateDocument(db, tc.SchemaPrefix, document)
+	created, err := documentAdapter.CreateDocument(ctx, db, tc.SchemaPrefix, document)
+	if err != nil {
+		return nil, err
+	}
+
+	// A document of a requested type fulfills the client's open requests for it
+	s.fulfillDocumentRequests(tenantID, created.UserID, []string{created.Type}, created.ID)
+	return created, nil
 }
 
 // GetDocumentByID retrieves a specific document by ID
-func (s *Store) GetDocumentByID(tenantID string, documentID string) (*types.Document, error) {
+func (s *Store) GetDocumentByID(ctx context.Context, tenantID string, documentID string) (*types.Document, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	documentAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	documentAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -47,19 +72,29 @@ func (s *Store) GetDocumentByID(tenantID string, documentID string) (*types.Docu
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to fetch document
-	return documentAdapter.GetDocumentByID(db, tc.SchemaPrefix, documentID)
+	document, err := documentAdapter.GetDocumentByID(ctx, db, tc.SchemaPrefix, documentID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachScanStatuses(tenantID, []*types.Document{document}); err != nil {
+		return nil, err
+	}
+	return document, nil
 }
 
 // GetDocumentsByFilingID retrieves all documents associated with a filing
-func (s *Store) GetDocumentsByFilingID(tenantID string, filingID string) ([]*types.Document, error) {
+func (s *Store) GetDocumentsByFilingID(ctx context.Context, tenantID string, filingID string) ([]*types.Document, error) {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return nil, err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	documentAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	documentAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return nil, fmt.Errorf("failed to create adapter: %w", err)
@@ -68,19 +103,29 @@ func (s *Store) GetDocumentsByFilingID(tenantID string, filingID string) ([]*typ
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
 	// Use adapter to fetch documents
-	return documentAdapter.GetDocumentsByFilingID(db, tc.SchemaPrefix, filingID)
+	documents, err := documentAdapter.GetDocumentsByFilingID(ctx, db, tc.SchemaPrefix, filingID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.attachScanStatuses(tenantID, documents); err != nil {
+		return nil, err
+	}
+	return documents, nil
 }
 
 // DeleteDocument removes a document record from the tenant's database
-func (s *Store) DeleteDocument(tenantID string, documentID string) error {
+func (s *Store) DeleteDocument(ctx context.Context, tenantID string, documentID string) error {
 	// Get tenant database connection and config
-	db, tc, err := s.GetTenantDB(tenantID)
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
 	if err != nil {
 		return err
 	}
 
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
 	// Get the appropriate adapter for this tenant
-	documentAdapter, err := adapter.NewAdapter(tc.AdapterType)
+	documentAdapter, err := adapter.ForTenant(tc)
 	if err != nil {
 		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
 		return fmt.Errorf("failed to create adapter: %w", err)
@@ -88,6 +133,96 @@ func (s *Store) DeleteDocument(tenantID string, documentID string) error {
 
 	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
 
+	if err := s.checkDocumentWritable(ctx, tenantID, db, tc, documentAdapter, documentID); err != nil {
+		return err
+	}
+
 	// Use adapter to delete document
-	return documentAdapter.DeleteDocument(db, tc.SchemaPrefix, documentID)
+	if err := documentAdapter.DeleteDocument(ctx, db, tc.SchemaPrefix, documentID); err != nil {
+		return err
+	}
+
+	// The expiry date goes with the document
+	if _, err := s.DB.Exec(`DELETE FROM document_expirations WHERE tenant_id = $1 AND document_id::text = $2`, tenantID, documentID); err != nil {
+		logger.Errorf("Failed to clear expiry of deleted document %s: %v", documentID, err)
+	}
+	// So does its malware scan
+	if _, err := s.DB.Exec(`DELETE FROM document_scans WHERE tenant_id = $1 AND document_id::text = $2`, tenantID, documentID); err != nil {
+		logger.Errorf("Failed to clear scan of deleted document %s: %v", documentID, err)
+	}
+	// And its place in the archive storage class
+	if _, err := s.DB.Exec(`DELETE FROM archived_documents WHERE tenant_id = $1 AND document_id::text = $2`, tenantID, documentID); err != nil {
+		logger.Errorf("Failed to clear archive record of deleted document %s: %v", documentID, err)
+	}
+	return nil
+}
+
+// StreamDocuments calls fn with each of the tenant's documents, oldest first. Like other streams
+// it is bounded by ctx rather than the tenant's statement timeout.
+func (s *Store) StreamDocuments(ctx context.Context, tenantID string, fn func(*types.Document) error) error {
+	db, tc, documentAdapter, err := s.tenantAdapter(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	logger.Infof("Using %s adapter to stream documents for tenant %s", tc.AdapterType, tenantID)
+
+	return documentAdapter.StreamDocuments(ctx, db, tc.SchemaPrefix, fn)
+}
+
+// SetDocumentPath points a document at another file in the tenant's bucket. The file has not been
+// scanned as this document's, so a document that was scanned goes back to PENDING until the scan
+// retry job checks the new file, and one in the archive storage class is moved again by the
+// archive job.
+func (s *Store) SetDocumentPath(ctx context.Context, tenantID string, documentID uuid.UUID, filePath string) error {
+	db, tc, documentAdapter, err := s.tenantAdapter(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	if err := s.checkDocumentWritable(ctx, tenantID, db, tc, documentAdapter, documentID.String()); err != nil {
+		return err
+	}
+	if err := documentAdapter.SetDocumentPath(ctx, db, tc.SchemaPrefix, documentID.String(), filePath); err != nil {
+		return err
+	}
+
+	if _, err := s.DB.Exec(`
+		UPDATE document_scans
+		SET status = 'PENDING', threat = NULL, error = NULL, quarantine_path = NULL, attempts = 0, updated_at = NOW()
+		WHERE tenant_id = $1 AND document_id = $2
+	`, tenantID, documentID); err != nil {
+		logger.Errorf("Failed to reset scan of relinked document %s: %v", documentID, err)
+	}
+	if _, err := s.DB.Exec(`DELETE FROM archived_documents WHERE tenant_id = $1 AND document_id = $2`, tenantID, documentID); err != nil {
+		logger.Errorf("Failed to clear archive record of relinked document %s: %v", documentID, err)
+	}
+
+	logger.Infof("Relinked document %s of tenant %s to %s", documentID, tenantID, filePath)
+	return nil
+}
+
+// GetFilingClientID returns the client a filing belongs to, for attaching documents to it
+func (s *Store) GetFilingClientID(ctx context.Context, tenantID string, filingID uuid.UUID) (uuid.UUID, error) {
+	db, tc, err := s.GetTenantSQLDB(ctx, tenantID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	var clientID uuid.UUID
+	err = db.QueryRowContext(ctx, `SELECT user_id FROM `+sqlident.Schema(tc.SchemaPrefix)+`.filing WHERE id = $1`, filingID).Scan(&clientID)
+	if err == sql.ErrNoRows {
+		return uuid.Nil, apperr.NotFound("filing not found: %s", filingID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get client of filing %s: %v", filingID, err)
+		return uuid.Nil, err
+	}
+	return clientID, nil
 }
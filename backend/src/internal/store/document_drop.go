@@ -0,0 +1,236 @@
+package store
+
+import (
+	"database/sql"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+const documentDropColumns = `id, tenant_id, name, source_type, location, prefix, document_type,
+	is_active, last_scanned_at, last_error, created_by, created_at`
+
+// scanDocumentDrop reads a row selected with documentDropColumns
+func scanDocumentDrop(row interface{ Scan(...interface{}) error }) (*types.DocumentDrop, error) {
+	d := &types.DocumentDrop{}
+	err := row.Scan(&d.ID, &d.TenantID, &d.Name, &d.SourceType, &d.Location, &d.Prefix, &d.DocumentType,
+		&d.IsActive, &d.LastScannedAt, &d.LastError, &d.CreatedBy, &d.CreatedAt)
+	return d, err
+}
+
+// CreateDocumentDrop registers a drop location for a tenant
+func (s *Store) CreateDocumentDrop(d *types.DocumentDrop) error {
+	err := s.DB.QueryRow(`
+		INSERT INTO document_drops (tenant_id, name, source_type, location, prefix, document_type, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, is_active, created_at
+	`, d.TenantID, d.Name, d.SourceType, d.Location, d.Prefix, d.DocumentType, d.CreatedBy).Scan(&d.ID, &d.IsActive, &d.CreatedAt)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return apperr.Conflict("document drop %q already exists", d.Name)
+		}
+		logger.Errorf("Failed to create document drop %s for tenant %s: %v", d.Name, d.TenantID, err)
+		return err
+	}
+
+	logger.Infof("Created %s document drop %s for tenant %s", d.SourceType, d.Name, d.TenantID)
+	return nil
+}
+
+// GetDocumentDrops lists a tenant's drops
+func (s *Store) GetDocumentDrops(tenantID string) ([]*types.DocumentDrop, error) {
+	return s.queryDocumentDrops(`SELECT `+documentDropColumns+` FROM document_drops WHERE tenant_id = $1 ORDER BY name`, tenantID)
+}
+
+// GetActiveDocumentDrops lists the active drops of every active tenant
+func (s *Store) GetActiveDocumentDrops() ([]*types.DocumentDrop, error) {
+	return s.queryDocumentDrops(`
+		SELECT ` + documentDropColumns + ` FROM document_drops
+		WHERE is_active = true
+		  AND tenant_id IN (SELECT tenant_id FROM tenant_connections WHERE is_active = true)
+		ORDER BY tenant_id, name
+	`)
+}
+
+func (s *Store) queryDocumentDrops(query string, args ...interface{}) ([]*types.DocumentDrop, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		logger.Errorf("Failed to query document drops: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	drops := []*types.DocumentDrop{}
+	for rows.Next() {
+		d, err := scanDocumentDrop(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan document drop: %v", err)
+			return nil, err
+		}
+		drops = append(drops, d)
+	}
+
+	return drops, rows.Err()
+}
+
+// GetDocumentDrop returns one of a tenant's drops
+func (s *Store) GetDocumentDrop(tenantID string, dropID uuid.UUID) (*types.DocumentDrop, error) {
+	d, err := scanDocumentDrop(s.DB.QueryRow(`
+		SELECT `+documentDropColumns+` FROM document_drops WHERE tenant_id = $1 AND id = $2
+	`, tenantID, dropID))
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("document drop not found: %s", dropID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get document drop %s: %v", dropID, err)
+		return nil, err
+	}
+	return d, nil
+}
+
+// DeactivateDocumentDrop stops scanning a drop; files already taken from it are kept
+func (s *Store) DeactivateDocumentDrop(tenantID string, dropID uuid.UUID) error {
+	result, err := s.DB.Exec(`UPDATE document_drops SET is_active = false WHERE tenant_id = $1 AND id = $2`, tenantID, dropID)
+	if err != nil {
+		logger.Errorf("Failed to deactivate document drop %s: %v", dropID, err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return apperr.NotFound("document drop not found: %s", dropID)
+	}
+
+	logger.Infof("Deactivated document drop %s for tenant %s", dropID, tenantID)
+	return nil
+}
+
+// MarkDocumentDropScanned records a finished scan and its error, if any
+func (s *Store) MarkDocumentDropScanned(dropID uuid.UUID, scanErr *string) error {
+	if err := s.requireScope(types.ScopeDocumentsIngest); err != nil {
+		return err
+	}
+
+	_, err := s.DB.Exec(`UPDATE document_drops SET last_scanned_at = NOW(), last_error = $2 WHERE id = $1`, dropID, scanErr)
+	if err != nil {
+		logger.Errorf("Failed to record scan of document drop %s: %v", dropID, err)
+		return err
+	}
+	return nil
+}
+
+// GetDocumentDropPaths returns the paths already taken from a drop
+func (s *Store) GetDocumentDropPaths(dropID uuid.UUID) (map[string]bool, error) {
+	rows, err := s.DB.Query(`SELECT path FROM document_drop_files WHERE drop_id = $1`, dropID)
+	if err != nil {
+		logger.Errorf("Failed to get paths of document drop %s: %v", dropID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	paths := map[string]bool{}
+	for rows.Next() {
+		var path string
+		if err := rows.Scan(&path); err != nil {
+			logger.Errorf("Failed to scan document drop path: %v", err)
+			return nil, err
+		}
+		paths[path] = true
+	}
+
+	return paths, rows.Err()
+}
+
+// RecordDocumentDropFile records a file taken from a drop
+func (s *Store) RecordDocumentDropFile(f *types.DocumentDropFile) error {
+	if err := s.requireScope(types.ScopeDocumentsIngest); err != nil {
+		return err
+	}
+
+	err := s.DB.QueryRow(`
+		INSERT INTO document_drop_files (drop_id, tenant_id, path, batch, file_name, status, reason, client_id, filing_id, document_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, received_at
+	`, f.DropID, f.TenantID, f.Path, f.Batch, f.FileName, f.Status, f.Reason, f.ClientID, f.FilingID, f.DocumentID).Scan(&f.ID, &f.ReceivedAt)
+	if err != nil {
+		logger.Errorf("Failed to record document drop file %s: %v", f.Path, err)
+		return err
+	}
+	return nil
+}
+
+const documentDropFileColumns = `id, drop_id, tenant_id, path, batch, file_name, status, reason,
+	client_id, filing_id, document_id, received_at, resolved_by, resolved_at`
+
+// scanDocumentDropFile reads a row selected with documentDropFileColumns
+func scanDocumentDropFile(row interface{ Scan(...interface{}) error }) (*types.DocumentDropFile, error) {
+	f := &types.DocumentDropFile{}
+	err := row.Scan(&f.ID, &f.DropID, &f.TenantID, &f.Path, &f.Batch, &f.FileName, &f.Status, &f.Reason,
+		&f.ClientID, &f.FilingID, &f.DocumentID, &f.ReceivedAt, &f.ResolvedBy, &f.ResolvedAt)
+	return f, err
+}
+
+// GetDocumentDropFiles lists a tenant's drop files, newest first, optionally with one status
+func (s *Store) GetDocumentDropFiles(tenantID, status string, limit int) ([]*types.DocumentDropFile, error) {
+	query := `SELECT ` + documentDropFileColumns + ` FROM document_drop_files WHERE tenant_id = $1`
+	args := []interface{}{tenantID, limit}
+	if status != "" {
+		query += " AND status = $3"
+		args = append(args, status)
+	}
+	query += " ORDER BY received_at DESC LIMIT $2"
+
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		logger.Errorf("Failed to get document drop files for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	files := []*types.DocumentDropFile{}
+	for rows.Next() {
+		f, err := scanDocumentDropFile(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan document drop file: %v", err)
+			return nil, err
+		}
+		files = append(files, f)
+	}
+
+	return files, rows.Err()
+}
+
+// GetDocumentDropFile returns one of a tenant's drop files
+func (s *Store) GetDocumentDropFile(tenantID string, fileID uuid.UUID) (*types.DocumentDropFile, error) {
+	f, err := scanDocumentDropFile(s.DB.QueryRow(`
+		SELECT `+documentDropFileColumns+` FROM document_drop_files WHERE tenant_id = $1 AND id = $2
+	`, tenantID, fileID))
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("document drop file not found: %s", fileID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get document drop file %s: %v", fileID, err)
+		return nil, err
+	}
+	return f, nil
+}
+
+// ResolveDocumentDropFile closes an unmatched file as RESOLVED (with the imported document) or IGNORED
+func (s *Store) ResolveDocumentDropFile(f *types.DocumentDropFile, employeeID uuid.UUID) error {
+	err := s.DB.QueryRow(`
+		UPDATE document_drop_files
+		SET status = $3, client_id = $4, filing_id = $5, document_id = $6, resolved_by = $7, resolved_at = NOW()
+		WHERE tenant_id = $1 AND id = $2 AND status = 'UNMATCHED'
+		RETURNING resolved_by, resolved_at
+	`, f.TenantID, f.ID, f.Status, f.ClientID, f.FilingID, f.DocumentID, employeeID).Scan(&f.ResolvedBy, &f.ResolvedAt)
+	if err == sql.ErrNoRows {
+		return apperr.Conflict("document drop file %s is not waiting for resolution", f.ID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to resolve document drop file %s: %v", f.ID, err)
+		return err
+	}
+
+	logger.Infof("Document drop file %s marked %s by %s", f.ID, f.Status, employeeID)
+	return nil
+}
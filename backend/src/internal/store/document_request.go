@@ -0,0 +1,387 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const documentRequestColumns = `id, tenant_id, client_id, filing_id, document_type, description, reason, source_document_id,
+	due_on::text, status, requested_by, reminder_count, last_reminded_at, fulfilled_document_id, closed_by, closed_at, created_at`
+
+// expiringDocumentsQuery selects documents of active tenants expiring within $1 days, with the
+// replacement request raised for each. Staff set expiry dates on filing documents; identity
+// documents use the date read from the client's latest verified ID.
+const expiringDocumentsQuery = `
+	WITH expiring AS (
+		SELECT tenant_id, document_id, client_id, 'DOCUMENT' AS source, document_type, document_name, expires_on, set_by
+		FROM document_expirations
+		UNION ALL
+		SELECT d.tenant_id, d.id, COALESCE(d.client_id, tu.client_id), 'IDENTITY_DOCUMENT', d.kind, d.file_name, d.expires_on, NULL::uuid
+		FROM identity_documents d
+		JOIN tenant_users tu ON tu.id = d.tenant_user_id
+		WHERE d.status = 'VERIFIED' AND d.expires_on IS NOT NULL
+		  AND NOT EXISTS (
+		      SELECT 1 FROM identity_documents n
+		      WHERE n.tenant_user_id = d.tenant_user_id AND n.status = 'VERIFIED' AND n.created_at > d.created_at
+		  )
+	)
+	SELECT e.tenant_id, e.document_id, e.client_id, e.source, e.document_type, e.document_name, e.expires_on::text, e.set_by, r.id
+	FROM expiring e
+	JOIN tenant_connections t ON t.tenant_id = e.tenant_id AND t.is_active = true
+	LEFT JOIN document_requests r ON r.tenant_id = e.tenant_id AND r.source_document_id = e.document_id
+	WHERE e.client_id IS NOT NULL AND e.expires_on <= CURRENT_DATE + $1::int
+`
+
+// SetDocumentExpiry sets or, with a nil date, clears the expiry date of a filing document
+func (s *Store) SetDocumentExpiry(tenantID string, document *types.Document, expiresOn *string, setBy uuid.UUID) (*types.DocumentExpiration, error) {
+	if expiresOn == nil {
+		if _, err := s.DB.Exec(`DELETE FROM document_expirations WHERE tenant_id = $1 AND document_id = $2`, tenantID, document.ID); err != nil {
+			logger.Errorf("Failed to clear expiry of document %s: %v", document.ID, err)
+			return nil, err
+		}
+		logger.Infof("Cleared expiry of document %s in tenant %s", document.ID, tenantID)
+		return nil, nil
+	}
+
+	if _, err := time.Parse("2006-01-02", *expiresOn); err != nil {
+		return nil, apperr.Validation("expiresOn must be a YYYY-MM-DD date")
+	}
+
+	expiration := &types.DocumentExpiration{
+		TenantID:     tenantID,
+		DocumentID:   document.ID,
+		ClientID:     document.UserID,
+		Source:       types.DocumentSourceDocument,
+		DocumentType: document.Type,
+		DocumentName: document.Name,
+		ExpiresOn:    *expiresOn,
+		SetBy:        &setBy,
+	}
+	_, err := s.DB.Exec(`
+		INSERT INTO document_expirations (tenant_id, document_id, client_id, document_type, document_name, expires_on, set_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id, document_id) DO UPDATE
+		SET expires_on = EXCLUDED.expires_on, set_by = EXCLUDED.set_by, updated_at = NOW()
+	`, tenantID, document.ID, document.UserID, document.Type, document.Name, *expiresOn, setBy)
+	if err != nil {
+		logger.Errorf("Failed to set expiry of document %s: %v", document.ID, err)
+		return nil, err
+	}
+
+	logger.Infof("Document %s in tenant %s expires on %s", document.ID, tenantID, *expiresOn)
+	return expiration, nil
+}
+
+// GetExpiringDocuments lists a tenant's documents expiring within days (including expired ones), soonest first
+func (s *Store) GetExpiringDocuments(tenantID string, days int) ([]*types.DocumentExpiration, error) {
+	return s.queryExpiringDocuments(expiringDocumentsQuery+` AND e.tenant_id = $2 ORDER BY e.expires_on, e.document_id`, days, tenantID)
+}
+
+// RaiseExpiryRequests raises a replacement request for every document expiring within days
+// that does not have one yet, and returns the new requests
+func (s *Store) RaiseExpiryRequests(days int) ([]*types.DocumentRequest, error) {
+	if err := s.requireScope(types.ScopeDocumentRequests); err != nil {
+		return nil, err
+	}
+
+	expiring, err := s.queryExpiringDocuments(expiringDocumentsQuery+` AND r.id IS NULL ORDER BY e.expires_on`, days)
+	if err != nil {
+		return nil, err
+	}
+
+	var raised []*types.DocumentRequest
+	for _, e := range expiring {
+		documentID := e.DocumentID
+		expiresOn := e.ExpiresOn
+		request := &types.DocumentRequest{
+			TenantID:         e.TenantID,
+			ClientID:         e.ClientID,
+			DocumentType:     e.DocumentType,
+			Description:      expiryRequestDescription(e),
+			Reason:           types.DocumentRequestExpiring,
+			SourceDocumentID: &documentID,
+			DueOn:            &expiresOn,
+		}
+		created, err := s.insertDocumentRequest(request)
+		if err != nil {
+			return raised, err
+		}
+		if created != nil {
+			raised = append(raised, created)
+		}
+	}
+
+	if len(raised) > 0 {
+		logger.Infof("Raised %d document requests for expiring documents", len(raised))
+	}
+	return raised, nil
+}
+
+// expiryRequestDescription tells the client which document to replace
+func expiryRequestDescription(e *types.DocumentExpiration) string {
+	label := strings.ToLower(strings.ReplaceAll(e.DocumentType, "_", " "))
+	if e.Source == types.DocumentSourceIdentityDocument {
+		label = "photo ID (" + label + ")"
+	}
+
+	verb := "expires"
+	if expires, err := time.Parse("2006-01-02", e.ExpiresOn); err == nil && expires.Before(time.Now().UTC().Truncate(24*time.Hour)) {
+		verb = "expired"
+	}
+	return fmt.Sprintf("Please upload a new %s to replace %s, which %s on %s", label, e.DocumentName, verb, e.ExpiresOn)
+}
+
+// CreateDocumentRequest raises a request from staff for a document from a client
+func (s *Store) CreateDocumentRequest(request *types.DocumentRequest) (*types.DocumentRequest, error) {
+	request.Reason = types.DocumentRequestManual
+	request.SourceDocumentID = nil
+	created, err := s.insertDocumentRequest(request)
+	if err != nil {
+		return nil, err
+	}
+	return created, nil
+}
+
+// RaiseMissingFileRequest asks a client to upload a document again whose file was lost from
+// storage. When the document already has a request, such as for its expiry, that one is returned.
+func (s *Store) RaiseMissingFileRequest(tenantID string, document *types.Document, description string, requestedBy uuid.UUID) (*types.DocumentRequest, error) {
+	if description == "" {
+		description = fmt.Sprintf("Please upload %s again; we could not find the file you sent", document.Name)
+	}
+	documentID := document.ID
+	created, err := s.insertDocumentRequest(&types.DocumentRequest{
+		TenantID:         tenantID,
+		ClientID:         document.UserID,
+		FilingID:         document.FilingID,
+		DocumentType:     document.Type,
+		Description:      description,
+		Reason:           types.DocumentRequestMissing,
+		SourceDocumentID: &documentID,
+		RequestedBy:      &requestedBy,
+	})
+	if err != nil || created != nil {
+		return created, err
+	}
+
+	row := s.DB.QueryRow(`
+		SELECT `+documentRequestColumns+`
+		FROM document_requests
+		WHERE tenant_id = $1 AND source_document_id = $2
+	`, tenantID, documentID)
+	existing, err := scanDocumentRequest(row)
+	if err != nil {
+		logger.Errorf("Failed to get request for document %s: %v", documentID, err)
+		return nil, err
+	}
+	return existing, nil
+}
+
+// insertDocumentRequest adds an open request; it returns nil when the source document already has one
+func (s *Store) insertDocumentRequest(r *types.DocumentRequest) (*types.DocumentRequest, error) {
+	row := s.DB.QueryRow(`
+		INSERT INTO document_requests (tenant_id, client_id, filing_id, document_type, description, reason, source_document_id, due_on, requested_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (tenant_id, source_document_id) WHERE source_document_id IS NOT NULL DO NOTHING
+		RETURNING `+documentRequestColumns,
+		r.TenantID, r.ClientID, r.FilingID, r.DocumentType, r.Description, r.Reason, r.SourceDocumentID, r.DueOn, r.RequestedBy)
+
+	created, err := scanDocumentRequest(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Errorf("Failed to create document request for client %s in tenant %s: %v", r.ClientID, r.TenantID, err)
+		return nil, err
+	}
+
+	logger.Infof("Raised %s document request %s (%s) for client %s in tenant %s", created.Reason, created.ID, created.DocumentType, created.ClientID, created.TenantID)
+	return created, nil
+}
+
+// GetDocumentRequest returns one document request of a tenant
+func (s *Store) GetDocumentRequest(tenantID string, id uuid.UUID) (*types.DocumentRequest, error) {
+	row := s.DB.QueryRow(`
+		SELECT `+documentRequestColumns+`
+		FROM document_requests
+		WHERE id = $1 AND tenant_id = $2
+	`, id, tenantID)
+
+	r, err := scanDocumentRequest(row)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("document request not found: %s", id)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get document request %s: %v", id, err)
+		return nil, err
+	}
+	return r, nil
+}
+
+// GetClientDocumentRequests lists a client's document requests, newest first
+func (s *Store) GetClientDocumentRequests(tenantID string, clientID uuid.UUID, openOnly bool) ([]*types.DocumentRequest, error) {
+	query := `
+		SELECT ` + documentRequestColumns + `
+		FROM document_requests
+		WHERE tenant_id = $1 AND client_id = $2
+	`
+	if openOnly {
+		query += " AND status = 'OPEN'"
+	}
+	query += " ORDER BY created_at DESC"
+
+	return s.queryDocumentRequests(query, tenantID, clientID)
+}
+
+// CloseDocumentRequest marks an open request fulfilled or cancelled by an employee
+func (s *Store) CloseDocumentRequest(tenantID string, id uuid.UUID, status string, documentID *uuid.UUID, closedBy uuid.UUID) (*types.DocumentRequest, error) {
+	row := s.DB.QueryRow(`
+		UPDATE document_requests
+		SET status = $3, fulfilled_document_id = $4, closed_by = $5, closed_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status = 'OPEN'
+		RETURNING `+documentRequestColumns,
+		id, tenantID, status, documentID, closedBy)
+
+	r, err := scanDocumentRequest(row)
+	if err == sql.ErrNoRows {
+		existing, getErr := s.GetDocumentRequest(tenantID, id)
+		if getErr != nil {
+			return nil, getErr
+		}
+		return nil, apperr.Conflict("document request %s is already %s", id, strings.ToLower(existing.Status))
+	}
+	if err != nil {
+		logger.Errorf("Failed to close document request %s: %v", id, err)
+		return nil, err
+	}
+
+	logger.Infof("Document request %s marked %s by %s", id, status, closedBy)
+	return r, nil
+}
+
+// fulfillDocumentRequests closes a client's open requests for any of documentTypes with an
+// uploaded document. Failures are logged; the upload itself has already succeeded.
+func (s *Store) fulfillDocumentRequests(tenantID string, clientID uuid.UUID, documentTypes []string, documentID uuid.UUID) {
+	result, err := s.DB.Exec(`
+		UPDATE document_requests
+		SET status = 'FULFILLED', fulfilled_document_id = $4, closed_at = NOW()
+		WHERE tenant_id = $1 AND client_id = $2 AND status = 'OPEN'
+		  AND LOWER(document_type) = ANY($3)
+		  AND (source_document_id IS NULL OR source_document_id <> $4)
+	`, tenantID, clientID, pq.Array(lowerAll(documentTypes)), documentID)
+	if err != nil {
+		logger.Errorf("Failed to fulfill document requests of client %s with %s: %v", clientID, documentID, err)
+		return
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		logger.Infof("Document %s fulfilled %d requests of client %s in tenant %s", documentID, n, clientID, tenantID)
+	}
+}
+
+// GetDocumentRequestsDueReminder lists open requests whose client has not been reminded within
+// interval, skipping requests already reminded maxReminders times
+func (s *Store) GetDocumentRequestsDueReminder(interval time.Duration, maxReminders int) ([]*types.DocumentRequest, error) {
+	if err := s.requireScope(types.ScopeDocumentRequests); err != nil {
+		return nil, err
+	}
+
+	return s.queryDocumentRequests(`
+		SELECT `+documentRequestColumns+`
+		FROM document_requests
+		WHERE status = 'OPEN'
+		  AND reminder_count < $1
+		  AND (last_reminded_at IS NULL OR last_reminded_at <= NOW() - make_interval(secs => $2))
+		  AND tenant_id IN (SELECT tenant_id FROM tenant_connections WHERE is_active = true)
+		ORDER BY last_reminded_at NULLS FIRST, created_at
+	`, maxReminders, interval.Seconds())
+}
+
+// MarkDocumentRequestReminded records that the client was reminded of a request
+func (s *Store) MarkDocumentRequestReminded(id uuid.UUID) error {
+	if err := s.requireScope(types.ScopeDocumentRequests); err != nil {
+		return err
+	}
+
+	_, err := s.DB.Exec(`
+		UPDATE document_requests
+		SET reminder_count = reminder_count + 1, last_reminded_at = NOW()
+		WHERE id = $1
+	`, id)
+	if err != nil {
+		logger.Errorf("Failed to record reminder for document request %s: %v", id, err)
+		return err
+	}
+	return nil
+}
+
+// queryExpiringDocuments runs a query built on expiringDocumentsQuery
+func (s *Store) queryExpiringDocuments(query string, args ...interface{}) ([]*types.DocumentExpiration, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		logger.Errorf("Failed to query expiring documents: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	expiring := []*types.DocumentExpiration{}
+	for rows.Next() {
+		e := &types.DocumentExpiration{}
+		if err := rows.Scan(&e.TenantID, &e.DocumentID, &e.ClientID, &e.Source, &e.DocumentType, &e.DocumentName,
+			&e.ExpiresOn, &e.SetBy, &e.RequestID); err != nil {
+			logger.Errorf("Failed to scan expiring document: %v", err)
+			return nil, err
+		}
+		expiring = append(expiring, e)
+	}
+
+	return expiring, rows.Err()
+}
+
+// queryDocumentRequests runs a query selecting documentRequestColumns
+func (s *Store) queryDocumentRequests(query string, args ...interface{}) ([]*types.DocumentRequest, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		logger.Errorf("Failed to query document requests: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []*types.DocumentRequest{}
+	for rows.Next() {
+		r, err := scanDocumentRequest(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan document request: %v", err)
+			return nil, err
+		}
+		requests = append(requests, r)
+	}
+
+	return requests, rows.Err()
+}
+
+// scanDocumentRequest scans a document_requests row selected with documentRequestColumns
+func scanDocumentRequest(row interface{ Scan(...interface{}) error }) (*types.DocumentRequest, error) {
+	r := &types.DocumentRequest{}
+	err := row.Scan(&r.ID, &r.TenantID, &r.ClientID, &r.FilingID, &r.DocumentType, &r.Description, &r.Reason, &r.SourceDocumentID,
+		&r.DueOn, &r.Status, &r.RequestedBy, &r.ReminderCount, &r.LastRemindedAt, &r.FulfilledDocumentID,
+		&r.ClosedBy, &r.ClosedAt, &r.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+func lowerAll(values []string) []string {
+	lowered := make([]string, len(values))
+	for i, v := range values {
+		lowered[i] = strings.ToLower(v)
+	}
+	return lowered
+}
@@ -0,0 +1,210 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const documentScanColumns = `tenant_id, document_id, status, scanner, threat, error, quarantine_path, attempts, scanned_at, created_at, updated_at`
+
+// RecordDocumentScan records the scan of a document taken at upload
+func (s *Store) RecordDocumentScan(tenantID string, documentID uuid.UUID, scan *types.DocumentScan) (*types.DocumentScan, error) {
+	if err := s.requireScope(types.ScopeDocumentScans); err != nil {
+		return nil, err
+	}
+
+	recorded, err := scanDocumentScan(s.DB.QueryRow(`
+		INSERT INTO document_scans (tenant_id, document_id, status, scanner, threat, error, quarantine_path, scanned_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING `+documentScanColumns,
+		tenantID, documentID, scan.Status, scan.Scanner, scan.Threat, scan.Error, scan.QuarantinePath, scan.ScannedAt))
+	if err != nil {
+		logger.Errorf("Failed to record scan of document %s: %v", documentID, err)
+		return nil, err
+	}
+	return recorded, nil
+}
+
+// UpdateDocumentScan records another scan attempt of a pending document
+func (s *Store) UpdateDocumentScan(scan *types.DocumentScan) error {
+	if err := s.requireScope(types.ScopeDocumentScans); err != nil {
+		return err
+	}
+
+	_, err := s.DB.Exec(`
+		UPDATE document_scans
+		SET status = $3, scanner = $4, threat = $5, error = $6, quarantine_path = $7, scanned_at = $8,
+			attempts = attempts + 1, updated_at = NOW()
+		WHERE tenant_id = $1 AND document_id = $2
+	`, scan.TenantID, scan.DocumentID, scan.Status, scan.Scanner, scan.Threat, scan.Error, scan.QuarantinePath, scan.ScannedAt)
+	if err != nil {
+		logger.Errorf("Failed to update scan of document %s: %v", scan.DocumentID, err)
+		return err
+	}
+	return nil
+}
+
+// GetDocumentScan returns the scan of a document
+func (s *Store) GetDocumentScan(tenantID string, documentID string) (*types.DocumentScan, error) {
+	id, err := uuid.Parse(documentID)
+	if err != nil {
+		return nil, apperr.Validation("invalid document ID %q", documentID)
+	}
+
+	scan, err := scanDocumentScan(s.DB.QueryRow(`
+		SELECT `+documentScanColumns+`
+		FROM document_scans
+		WHERE tenant_id = $1 AND document_id = $2
+	`, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("document %s was not scanned", documentID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get scan of document %s: %v", documentID, err)
+		return nil, err
+	}
+	return scan, nil
+}
+
+// CheckDocumentScan returns a conflict error when a document cannot be downloaded because it is
+// infected or not scanned yet. Documents never scanned are allowed.
+func (s *Store) CheckDocumentScan(tenantID string, documentID string) error {
+	scan, err := s.GetDocumentScan(tenantID, documentID)
+	if err != nil {
+		if errors.Is(err, apperr.ErrNotFound) {
+			return nil
+		}
+		return err
+	}
+
+	switch scan.Status {
+	case types.DocumentScanInfected:
+		return apperr.Conflict("document %s is quarantined: malware was found in it", documentID)
+	case types.DocumentScanPending:
+		return apperr.Conflict("document %s has not been scanned for malware yet; try again later", documentID)
+	}
+	return nil
+}
+
+// GetPendingDocumentScans returns the pending scans tried fewer than maxAttempts times, least
+// recently tried first
+func (s *Store) GetPendingDocumentScans(maxAttempts int, limit int) ([]*types.DocumentScan, error) {
+	rows, err := s.DB.Query(`
+		SELECT `+documentScanColumns+`
+		FROM document_scans
+		WHERE status = 'PENDING' AND attempts < $1
+		ORDER BY updated_at
+		LIMIT $2
+	`, maxAttempts, limit)
+	if err != nil {
+		logger.Errorf("Failed to get pending document scans: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	scans := []*types.DocumentScan{}
+	for rows.Next() {
+		scan, err := scanDocumentScan(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan document scan: %v", err)
+			return nil, err
+		}
+		scans = append(scans, scan)
+	}
+	return scans, rows.Err()
+}
+
+// DeleteDocumentScan removes the scan of a document that no longer exists
+func (s *Store) DeleteDocumentScan(tenantID string, documentID uuid.UUID) error {
+	if _, err := s.DB.Exec(`DELETE FROM document_scans WHERE tenant_id = $1 AND document_id = $2`, tenantID, documentID); err != nil {
+		logger.Errorf("Failed to delete scan of document %s: %v", documentID, err)
+		return err
+	}
+	return nil
+}
+
+// GetQuarantinePaths returns where each of a tenant's quarantined documents is stored
+func (s *Store) GetQuarantinePaths(tenantID string) (map[uuid.UUID]string, error) {
+	rows, err := s.DB.Query(`
+		SELECT document_id, quarantine_path
+		FROM document_scans
+		WHERE tenant_id = $1 AND quarantine_path IS NOT NULL
+	`, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get quarantine paths of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	paths := map[uuid.UUID]string{}
+	for rows.Next() {
+		var id uuid.UUID
+		var path string
+		if err := rows.Scan(&id, &path); err != nil {
+			logger.Errorf("Failed to scan quarantine path: %v", err)
+			return nil, err
+		}
+		paths[id] = path
+	}
+	return paths, rows.Err()
+}
+
+// attachScanStatuses sets the scan status of each document that was scanned
+func (s *Store) attachScanStatuses(tenantID string, documents []*types.Document) error {
+	if len(documents) == 0 {
+		return nil
+	}
+	ids := make([]string, len(documents))
+	for i, d := range documents {
+		ids[i] = d.ID.String()
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT document_id, status
+		FROM document_scans
+		WHERE tenant_id = $1 AND document_id = ANY($2::uuid[])
+	`, tenantID, pq.Array(ids))
+	if err != nil {
+		logger.Errorf("Failed to get document scan statuses: %v", err)
+		return err
+	}
+	defer rows.Close()
+
+	statuses := map[uuid.UUID]string{}
+	for rows.Next() {
+		var id uuid.UUID
+		var status string
+		if err := rows.Scan(&id, &status); err != nil {
+			logger.Errorf("Failed to scan document scan status: %v", err)
+			return err
+		}
+		statuses[id] = status
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, d := range documents {
+		if status, ok := statuses[d.ID]; ok {
+			d.ScanStatus = &status
+		}
+	}
+	return nil
+}
+
+// scanDocumentScan reads a row of documentScanColumns
+func scanDocumentScan(row interface{ Scan(...interface{}) error }) (*types.DocumentScan, error) {
+	scan := &types.DocumentScan{}
+	err := row.Scan(&scan.TenantID, &scan.DocumentID, &scan.Status, &scan.Scanner, &scan.Threat, &scan.Error,
+		&scan.QuarantinePath, &scan.Attempts, &scan.ScannedAt, &scan.CreatedAt, &scan.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return scan, nil
+}
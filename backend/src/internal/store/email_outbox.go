@@ -0,0 +1,236 @@
+package store
+
+import (
+	"strings"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+const outboxEmailColumns = `o.id, o.tenant_id, o.kind, o.priority, o.to_email, o.to_name, o.subject, o.status, o.attempts, o.next_attempt_at, o.last_error, o.created_at, o.sent_at`
+
+// scanOutboxEmail reads outboxEmailColumns followed by extra columns into an outbox email
+func scanOutboxEmail(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*types.OutboxEmail, error) {
+	e := &types.OutboxEmail{}
+	dest := []interface{}{&e.ID, &e.TenantID, &e.Kind, &e.Priority, &e.ToEmail, &e.ToName, &e.Subject,
+		&e.Status, &e.Attempts, &e.NextAttemptAt, &e.LastError, &e.CreatedAt, &e.SentAt}
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// QueueEmail stores an email for the email delivery job to send. An email to a suppressed
+// address is stored as SUPPRESSED and never sent.
+func (s *Store) QueueEmail(email *types.OutboxEmail) (*types.OutboxEmail, error) {
+	queued, err := scanOutboxEmail(s.DB.QueryRow(`
+		WITH suppressed AS (
+			SELECT EXISTS (SELECT 1 FROM email_suppressions WHERE email = LOWER($4)) AS yes
+		)
+		INSERT INTO email_outbox AS o (tenant_id, kind, priority, to_email, to_name, subject, html_body, text_body, status, next_attempt_at)
+		SELECT $1, $2, $3, $4, $5, $6, $7, $8,
+			CASE WHEN suppressed.yes THEN 'SUPPRESSED' ELSE 'PENDING' END,
+			CASE WHEN suppressed.yes THEN NULL ELSE NOW() END
+		FROM suppressed
+		RETURNING `+outboxEmailColumns,
+		email.TenantID, email.Kind, email.Priority, email.ToEmail, email.ToName, email.Subject, email.HTMLBody, email.TextBody))
+	if err != nil {
+		logger.Errorf("Failed to queue %s email to %s: %v", email.Kind, email.ToEmail, err)
+		return nil, err
+	}
+
+	if queued.Status == types.OutboxEmailSuppressed {
+		logger.Warningf("Not sending %s email %s: %s is suppressed", queued.Kind, queued.ID, queued.ToEmail)
+	}
+	return queued, nil
+}
+
+// GetOutboxEmails returns the latest outbox emails matching filter, newest first
+func (s *Store) GetOutboxEmails(filter *types.OutboxEmailFilter) ([]*types.OutboxEmail, error) {
+	rows, err := s.DB.Query(`
+		SELECT `+outboxEmailColumns+`
+		FROM email_outbox o
+		WHERE ($1::text IS NULL OR o.tenant_id = $1)
+		  AND ($2::text IS NULL OR o.kind = $2)
+		  AND ($3::text IS NULL OR o.status = $3)
+		  AND ($4::text IS NULL OR LOWER(o.to_email) = LOWER($4))
+		ORDER BY o.created_at DESC, o.id
+		LIMIT $5
+	`, filter.TenantID, filter.Kind, filter.Status, filter.ToEmail, filter.Limit)
+	if err != nil {
+		logger.Errorf("Failed to get outbox emails: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	emails := []*types.OutboxEmail{}
+	for rows.Next() {
+		email, err := scanOutboxEmail(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan outbox email: %v", err)
+			return nil, err
+		}
+		emails = append(emails, email)
+	}
+	return emails, rows.Err()
+}
+
+// GetDueEmails returns up to limit pending emails whose next attempt is due, oldest first, with
+// their bodies. Pending emails whose recipient has since been suppressed are marked SUPPRESSED
+// instead of returned.
+func (s *Store) GetDueEmails(limit int) ([]*types.OutboxEmail, error) {
+	if err := s.requireScope(types.ScopeEmailsSend); err != nil {
+		return nil, err
+	}
+
+	result, err := s.DB.Exec(`
+		UPDATE email_outbox o
+		SET status = 'SUPPRESSED', next_attempt_at = NULL
+		FROM email_suppressions x
+		WHERE o.status = 'PENDING' AND x.email = LOWER(o.to_email)
+	`)
+	if err != nil {
+		logger.Errorf("Failed to suppress queued emails: %v", err)
+		return nil, err
+	}
+	if n, _ := result.RowsAffected(); n > 0 {
+		logger.Warningf("Suppressed %d queued emails to addresses on the suppression list", n)
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT `+outboxEmailColumns+`, o.html_body, o.text_body
+		FROM email_outbox o
+		WHERE o.status = 'PENDING' AND o.next_attempt_at <= NOW()
+		ORDER BY o.next_attempt_at, o.id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		logger.Errorf("Failed to query due emails: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	emails := []*types.OutboxEmail{}
+	for rows.Next() {
+		var htmlBody, textBody string
+		email, err := scanOutboxEmail(rows, &htmlBody, &textBody)
+		if err != nil {
+			logger.Errorf("Failed to scan due email: %v", err)
+			return nil, err
+		}
+		email.HTMLBody, email.TextBody = htmlBody, textBody
+		emails = append(emails, email)
+	}
+	return emails, rows.Err()
+}
+
+// RecordEmailAttempt records the outcome of sending an outbox email. A failed attempt is retried
+// after retryAfter; once the email has been attempted types.MaxEmailAttempts times it is marked
+// failed instead.
+func (s *Store) RecordEmailAttempt(emailID uuid.UUID, sendErr error, retryAfter time.Duration) error {
+	if err := s.requireScope(types.ScopeEmailsSend); err != nil {
+		return err
+	}
+
+	var err error
+	if sendErr == nil {
+		_, err = s.DB.Exec(`
+			UPDATE email_outbox
+			SET status = 'SENT', attempts = attempts + 1, next_attempt_at = NULL, last_error = NULL, sent_at = NOW()
+			WHERE id = $1
+		`, emailID)
+	} else {
+		_, err = s.DB.Exec(`
+			UPDATE email_outbox
+			SET attempts = attempts + 1, last_error = $2,
+			    status = CASE WHEN attempts + 1 >= $3 THEN 'FAILED' ELSE 'PENDING' END,
+			    next_attempt_at = CASE WHEN attempts + 1 >= $3 THEN NULL ELSE NOW() + $4 * INTERVAL '1 second' END
+			WHERE id = $1
+		`, emailID, sendErr.Error(), types.MaxEmailAttempts, int(retryAfter.Seconds()))
+	}
+	if err != nil {
+		logger.Errorf("Failed to record attempt of outbox email %s: %v", emailID, err)
+		return err
+	}
+	return nil
+}
+
+// MarkEmailBounced marks a sent outbox email bounced; emails in any other status are left alone
+func (s *Store) MarkEmailBounced(emailID uuid.UUID, detail string) error {
+	_, err := s.DB.Exec(`
+		UPDATE email_outbox SET status = 'BOUNCED', last_error = $2
+		WHERE id = $1 AND status = 'SENT'
+	`, emailID, detail)
+	if err != nil {
+		logger.Errorf("Failed to mark outbox email %s bounced: %v", emailID, err)
+		return err
+	}
+	return nil
+}
+
+// AddEmailSuppression adds an address to the suppression list. Adding a suppressed address again
+// keeps the first suppression.
+func (s *Store) AddEmailSuppression(suppression *types.EmailSuppression) (*types.EmailSuppression, error) {
+	added := &types.EmailSuppression{}
+	err := s.DB.QueryRow(`
+		WITH inserted AS (
+			INSERT INTO email_suppressions (email, reason, detail, created_by)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (email) DO NOTHING
+			RETURNING email, reason, detail, created_by, created_at
+		)
+		SELECT email, reason, detail, created_by, created_at FROM inserted
+		UNION ALL
+		SELECT email, reason, detail, created_by, created_at FROM email_suppressions WHERE email = $1
+	`, strings.ToLower(suppression.Email), suppression.Reason, suppression.Detail, suppression.CreatedBy).Scan(
+		&added.Email, &added.Reason, &added.Detail, &added.CreatedBy, &added.CreatedAt)
+	if err != nil {
+		logger.Errorf("Failed to suppress %s: %v", suppression.Email, err)
+		return nil, err
+	}
+
+	logger.Infof("Suppressed email to %s (%s)", added.Email, added.Reason)
+	return added, nil
+}
+
+// GetEmailSuppressions lists suppressed addresses, most recently suppressed first
+func (s *Store) GetEmailSuppressions() ([]*types.EmailSuppression, error) {
+	rows, err := s.DB.Query(`
+		SELECT email, reason, detail, created_by, created_at
+		FROM email_suppressions
+		ORDER BY created_at DESC, email
+	`)
+	if err != nil {
+		logger.Errorf("Failed to get email suppressions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	suppressions := []*types.EmailSuppression{}
+	for rows.Next() {
+		x := &types.EmailSuppression{}
+		if err := rows.Scan(&x.Email, &x.Reason, &x.Detail, &x.CreatedBy, &x.CreatedAt); err != nil {
+			logger.Errorf("Failed to scan email suppression: %v", err)
+			return nil, err
+		}
+		suppressions = append(suppressions, x)
+	}
+	return suppressions, rows.Err()
+}
+
+// DeleteEmailSuppression removes an address from the suppression list. Emails already marked
+// SUPPRESSED are not requeued.
+func (s *Store) DeleteEmailSuppression(email string) error {
+	result, err := s.DB.Exec(`DELETE FROM email_suppressions WHERE email = LOWER($1)`, email)
+	if err != nil {
+		logger.Errorf("Failed to remove suppression of %s: %v", email, err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return apperr.NotFound("email is not suppressed: %s", email)
+	}
+	return nil
+}
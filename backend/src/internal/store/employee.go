@@ -2,7 +2,8 @@ package store
 
 import (
 	"database/sql"
-	"fmt"
+	"time"
+	"welltaxpro/src/internal/apperr"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
@@ -33,7 +34,7 @@ func (s *Store) GetEmployeeByFirebaseUID(firebaseUID string) (*types.Employee, e
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("employee not found for firebase UID: %s", firebaseUID)
+		return nil, apperr.NotFound("employee not found for firebase UID: %s", firebaseUID)
 	}
 	if err != nil {
 		logger.Errorf("Failed to get employee by firebase UID: %v", err)
@@ -67,7 +68,7 @@ func (s *Store) GetEmployeeByID(employeeID uuid.UUID) (*types.Employee, error) {
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("employee not found with ID: %s", employeeID)
+		return nil, apperr.NotFound("employee not found with ID: %s", employeeID)
 	}
 	if err != nil {
 		logger.Errorf("Failed to get employee by ID: %v", err)
@@ -77,6 +78,40 @@ func (s *Store) GetEmployeeByID(employeeID uuid.UUID) (*types.Employee, error) {
 	return employee, nil
 }
 
+// GetEmployeeByEmail retrieves an active employee by email address
+func (s *Store) GetEmployeeByEmail(email string) (*types.Employee, error) {
+	query := `
+		SELECT id, firebase_uid, email, first_name, last_name, role, is_active, created_at, updated_at
+		FROM employees
+		WHERE LOWER(email) = LOWER($1) AND is_active = true
+	`
+
+	row := s.DB.QueryRow(query, email)
+
+	employee := &types.Employee{}
+	err := row.Scan(
+		&employee.ID,
+		&employee.FirebaseUID,
+		&employee.Email,
+		&employee.FirstName,
+		&employee.LastName,
+		&employee.Role,
+		&employee.IsActive,
+		&employee.CreatedAt,
+		&employee.UpdatedAt,
+	)
+
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("active employee not found with email: %s", email)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get employee by email: %v", err)
+		return nil, err
+	}
+
+	return employee, nil
+}
+
 // CreateEmployee creates a new employee record
 func (s *Store) CreateEmployee(firebaseUID, email string, firstName, lastName *string, role string) (*types.Employee, error) {
 	query := `
@@ -107,8 +142,30 @@ func (s *Store) CreateEmployee(firebaseUID, email string, firstName, lastName *s
 	return employee, nil
 }
 
-// UpdateEmployee updates an employee's information
-func (s *Store) UpdateEmployee(employeeID uuid.UUID, firstName, lastName *string, role string) (*types.Employee, error) {
+// UpdateEmployee updates an employee's name and role. expectedUpdatedAt is the updated_at the
+// caller read; the update is refused with a conflict when the employee changed since. A role
+// change ends the employee's open tenant contexts, which carry the role they were opened with.
+func (s *Store) UpdateEmployee(employeeID uuid.UUID, firstName, lastName *string, role string, expectedUpdatedAt time.Time) (*types.Employee, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var currentRole string
+	var updatedAt time.Time
+	err = tx.QueryRow(`SELECT role, updated_at FROM employees WHERE id = $1 FOR UPDATE`, employeeID).Scan(&currentRole, &updatedAt)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("employee not found with ID: %s", employeeID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to lock employee %s: %v", employeeID, err)
+		return nil, err
+	}
+	if !updatedAt.Equal(expectedUpdatedAt) {
+		return nil, apperr.Conflict("employee %s was changed at %s; reload it and try again", employeeID, updatedAt.Format(time.RFC3339Nano))
+	}
+
 	query := `
 		UPDATE employees
 		SET first_name = $1, last_name = $2, role = $3, updated_at = CURRENT_TIMESTAMP
@@ -117,7 +174,7 @@ func (s *Store) UpdateEmployee(employeeID uuid.UUID, firstName, lastName *string
 	`
 
 	employee := &types.Employee{}
-	err := s.DB.QueryRow(query, firstName, lastName, role, employeeID).Scan(
+	err = tx.QueryRow(query, firstName, lastName, role, employeeID).Scan(
 		&employee.ID,
 		&employee.FirebaseUID,
 		&employee.Email,
@@ -134,6 +191,22 @@ func (s *Store) UpdateEmployee(employeeID uuid.UUID, firstName, lastName *string
 		return nil, err
 	}
 
+	if role != currentRole {
+		_, err = tx.Exec(`
+			UPDATE employee_tenant_contexts
+			SET revoked_at = NOW()
+			WHERE employee_id = $1 AND revoked_at IS NULL
+		`, employeeID)
+		if err != nil {
+			logger.Errorf("Failed to end tenant contexts of employee %s: %v", employeeID, err)
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	logger.Infof("Updated employee: %s", employee.ID)
 	return employee, nil
 }
@@ -154,7 +227,7 @@ func (s *Store) DeactivateEmployee(employeeID uuid.UUID) error {
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("employee not found: %s", employeeID)
+		return apperr.NotFound("employee not found: %s", employeeID)
 	}
 
 	logger.Infof("Deactivated employee: %s", employeeID)
@@ -204,3 +277,21 @@ func (s *Store) GetAllEmployees(includeInactive bool) ([]*types.Employee, error)
 
 	return employees, rows.Err()
 }
+
+// GetEmployeeTenantRole returns an employee's role in a tenant from their active tenant access
+func (s *Store) GetEmployeeTenantRole(employeeID uuid.UUID, tenantID string) (string, error) {
+	var role string
+	err := s.DB.QueryRow(`
+		SELECT role
+		FROM employee_tenant_access
+		WHERE employee_id = $1 AND tenant_id = $2 AND is_active = true
+	`, employeeID, tenantID).Scan(&role)
+	if err == sql.ErrNoRows {
+		return "", apperr.NotFound("employee %s has no access to tenant %s", employeeID, tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get role of employee %s in tenant %s: %v", employeeID, tenantID, err)
+		return "", err
+	}
+	return role, nil
+}
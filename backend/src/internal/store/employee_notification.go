@@ -0,0 +1,120 @@
+package store
+
+import (
+	"database/sql"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const employeeNotificationColumns = `id, employee_id, tenant_id, category, subject, body, created_at, read_at`
+
+// CreateEmployeeNotification adds a notification to an employee's staff console inbox
+func (s *Store) CreateEmployeeNotification(n *types.EmployeeNotification) error {
+	err := s.DB.QueryRow(`
+		INSERT INTO employee_notifications (employee_id, tenant_id, category, subject, body)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, n.EmployeeID, n.TenantID, n.Category, n.Subject, n.Body).Scan(&n.ID, &n.CreatedAt)
+	if err != nil {
+		logger.Errorf("Failed to record %s notification for employee %s: %v", n.Category, n.EmployeeID, err)
+		return err
+	}
+	return nil
+}
+
+// GetEmployeeNotifications returns an employee's latest notifications, newest first, only unread
+// ones when unreadOnly is set, and how many are unread
+func (s *Store) GetEmployeeNotifications(employeeID uuid.UUID, unreadOnly bool, limit int) ([]*types.EmployeeNotification, int, error) {
+	rows, err := s.DB.Query(`
+		SELECT `+employeeNotificationColumns+`
+		FROM employee_notifications
+		WHERE employee_id = $1 AND (NOT $2 OR read_at IS NULL)
+		ORDER BY created_at DESC, id
+		LIMIT $3
+	`, employeeID, unreadOnly, limit)
+	if err != nil {
+		logger.Errorf("Failed to query notifications for employee %s: %v", employeeID, err)
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	notifications := []*types.EmployeeNotification{}
+	for rows.Next() {
+		n := &types.EmployeeNotification{}
+		if err := rows.Scan(&n.ID, &n.EmployeeID, &n.TenantID, &n.Category, &n.Subject, &n.Body, &n.CreatedAt, &n.ReadAt); err != nil {
+			logger.Errorf("Failed to scan employee notification: %v", err)
+			return nil, 0, err
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var unread int
+	err = s.DB.QueryRow(`
+		SELECT COUNT(*) FROM employee_notifications
+		WHERE employee_id = $1 AND read_at IS NULL
+	`, employeeID).Scan(&unread)
+	if err != nil {
+		logger.Errorf("Failed to count unread notifications for employee %s: %v", employeeID, err)
+		return nil, 0, err
+	}
+
+	return notifications, unread, nil
+}
+
+// MarkEmployeeNotificationsRead marks an employee's unread notifications read: those in ids, or
+// all of them when ids is empty
+func (s *Store) MarkEmployeeNotificationsRead(employeeID uuid.UUID, ids []uuid.UUID) (int64, error) {
+	idStrs := make([]string, 0, len(ids))
+	for _, id := range ids {
+		idStrs = append(idStrs, id.String())
+	}
+
+	result, err := s.DB.Exec(`
+		UPDATE employee_notifications SET read_at = NOW()
+		WHERE employee_id = $1 AND read_at IS NULL
+		  AND (cardinality($2::uuid[]) = 0 OR id = ANY($2::uuid[]))
+	`, employeeID, pq.Array(idStrs))
+	if err != nil {
+		logger.Errorf("Failed to mark notifications read for employee %s: %v", employeeID, err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// GetNotificationChannels returns an employee's addresses on channels other than email
+func (s *Store) GetNotificationChannels(employeeID uuid.UUID) (*types.NotificationChannels, error) {
+	channels := &types.NotificationChannels{}
+	err := s.DB.QueryRow(`
+		SELECT slack_user_id FROM employee_slack_accounts WHERE employee_id = $1
+	`, employeeID).Scan(&channels.SlackUserID)
+	if err != nil && err != sql.ErrNoRows {
+		logger.Errorf("Failed to get notification channels for employee %s: %v", employeeID, err)
+		return nil, err
+	}
+	return channels, nil
+}
+
+// SetNotificationChannels replaces an employee's addresses on channels other than email
+func (s *Store) SetNotificationChannels(employeeID uuid.UUID, channels *types.NotificationChannels) error {
+	var err error
+	if channels.SlackUserID == nil {
+		_, err = s.DB.Exec(`DELETE FROM employee_slack_accounts WHERE employee_id = $1`, employeeID)
+	} else {
+		_, err = s.DB.Exec(`
+			INSERT INTO employee_slack_accounts (employee_id, slack_user_id)
+			VALUES ($1, $2)
+			ON CONFLICT (employee_id) DO UPDATE SET slack_user_id = EXCLUDED.slack_user_id, updated_at = NOW()
+		`, employeeID, *channels.SlackUserID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to set notification channels for employee %s: %v", employeeID, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,299 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+const employeeSessionColumns = `s.id, s.employee_id, e.email, s.firebase_uid, s.auth_time, s.ip_address, s.user_agent,
+	s.created_at, s.last_refreshed_at, s.expires_at, s.revoked_at, s.revoked_by, s.revoke_reason`
+
+// CreateEmployeeSession records a sign-in and the first refresh token issued for it. The
+// session's ID, CreatedAt and ExpiresAt are filled in.
+func (s *Store) CreateEmployeeSession(session *types.EmployeeSession, firebaseRefreshToken, tokenHash string) error {
+	encrypted, err := crypto.EncryptPassword(firebaseRefreshToken)
+	if err != nil {
+		return fmt.Errorf("failed to encrypt refresh token: %w", err)
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO employee_sessions (employee_id, firebase_uid, auth_time, firebase_refresh_token, ip_address, user_agent, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NOW() + make_interval(secs => $7))
+		RETURNING id, created_at, expires_at
+	`, session.EmployeeID, session.FirebaseUID, session.AuthTime, encrypted, session.IPAddress, session.UserAgent,
+		types.EmployeeSessionLifetime.Seconds()).Scan(&session.ID, &session.CreatedAt, &session.ExpiresAt)
+	if err != nil {
+		logger.Errorf("Failed to create session for employee %s: %v", session.EmployeeID, err)
+		return err
+	}
+
+	if _, err := tx.Exec(`INSERT INTO employee_session_tokens (token_hash, session_id) VALUES ($1, $2)`, tokenHash, session.ID); err != nil {
+		logger.Errorf("Failed to record refresh token of session %s: %v", session.ID, err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logger.Infof("Session %s started for employee %s", session.ID, session.EmployeeID)
+	return nil
+}
+
+// RotateEmployeeSession exchanges a session's current refresh token for newTokenHash. refresh is
+// given the session's Firebase refresh token and returns the one to keep. Presenting a token that
+// was already rotated revokes the session, since the token has been copied.
+func (s *Store) RotateEmployeeSession(tokenHash, newTokenHash string, refresh func(firebaseRefreshToken string) (string, error)) (*types.EmployeeSession, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var sessionID uuid.UUID
+	var rotatedAt *time.Time
+	err = tx.QueryRow(`
+		SELECT session_id, rotated_at FROM employee_session_tokens
+		WHERE token_hash = $1
+		FOR UPDATE
+	`, tokenHash).Scan(&sessionID, &rotatedAt)
+	if err == sql.ErrNoRows {
+		return nil, apperr.Permission("invalid refresh token")
+	}
+	if err != nil {
+		logger.Errorf("Failed to look up refresh token: %v", err)
+		return nil, err
+	}
+
+	var encrypted *string
+	session, err := scanEmployeeSession(tx.QueryRow(`
+		SELECT `+employeeSessionColumns+`, s.firebase_refresh_token
+		FROM employee_sessions s
+		JOIN employees e ON e.id = s.employee_id
+		WHERE s.id = $1
+		FOR UPDATE OF s
+	`, sessionID), &encrypted)
+	if err != nil {
+		logger.Errorf("Failed to load session %s: %v", sessionID, err)
+		return nil, err
+	}
+
+	if rotatedAt != nil {
+		if session.RevokedAt == nil {
+			if _, err := revokeEmployeeSession(tx, session.ID, nil, types.SessionRevokeTokenReuse); err != nil {
+				return nil, err
+			}
+			if err := tx.Commit(); err != nil {
+				return nil, err
+			}
+			logger.Warningf("Refresh token of session %s (employee %s) was reused; session revoked", session.ID, session.EmployeeID)
+		}
+		return nil, apperr.Permission("refresh token was already used; the session has been revoked")
+	}
+	if session.RevokedAt != nil {
+		return nil, apperr.Permission("session has been revoked")
+	}
+	if !session.IsActive() || encrypted == nil {
+		return nil, apperr.Permission("session has expired")
+	}
+
+	firebaseRefreshToken, err := crypto.DecryptPassword(*encrypted)
+	if err != nil {
+		logger.Errorf("Failed to decrypt refresh token of session %s: %v", session.ID, err)
+		return nil, err
+	}
+	firebaseRefreshToken, err = refresh(firebaseRefreshToken)
+	if err != nil {
+		logger.Errorf("Failed to refresh session %s: %v", session.ID, err)
+		return nil, apperr.Permission("session could not be refreshed")
+	}
+	if *encrypted, err = crypto.EncryptPassword(firebaseRefreshToken); err != nil {
+		return nil, fmt.Errorf("failed to encrypt refresh token: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE employee_session_tokens SET rotated_at = NOW() WHERE token_hash = $1`, tokenHash); err != nil {
+		logger.Errorf("Failed to rotate refresh token of session %s: %v", session.ID, err)
+		return nil, err
+	}
+	if _, err := tx.Exec(`INSERT INTO employee_session_tokens (token_hash, session_id) VALUES ($1, $2)`, newTokenHash, session.ID); err != nil {
+		logger.Errorf("Failed to record refresh token of session %s: %v", session.ID, err)
+		return nil, err
+	}
+	err = tx.QueryRow(`
+		UPDATE employee_sessions
+		SET firebase_refresh_token = $2, last_refreshed_at = NOW()
+		WHERE id = $1
+		RETURNING last_refreshed_at
+	`, session.ID, *encrypted).Scan(&session.LastRefreshedAt)
+	if err != nil {
+		logger.Errorf("Failed to update session %s: %v", session.ID, err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetEmployeeSessions lists sessions, newest first. employeeID narrows them to one employee;
+// activeOnly leaves out revoked and expired sessions.
+func (s *Store) GetEmployeeSessions(employeeID *uuid.UUID, activeOnly bool) ([]*types.EmployeeSession, error) {
+	query := `
+		SELECT ` + employeeSessionColumns + `
+		FROM employee_sessions s
+		JOIN employees e ON e.id = s.employee_id
+		WHERE ($1::uuid IS NULL OR s.employee_id = $1)
+	`
+	if activeOnly {
+		query += " AND s.revoked_at IS NULL AND s.expires_at > NOW()"
+	}
+	query += " ORDER BY s.created_at DESC"
+
+	rows, err := s.DB.Query(query, employeeID)
+	if err != nil {
+		logger.Errorf("Failed to query employee sessions: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*types.EmployeeSession{}
+	for rows.Next() {
+		session, err := scanEmployeeSession(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan employee session: %v", err)
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, rows.Err()
+}
+
+// RevokeEmployeeSession ends a session. revokedBy is the admin revoking it, or nil when the
+// employee logs out.
+func (s *Store) RevokeEmployeeSession(sessionID uuid.UUID, revokedBy *uuid.UUID, reason string) (*types.EmployeeSession, error) {
+	session, err := revokeEmployeeSession(s.DB, sessionID, revokedBy, reason)
+	if err == sql.ErrNoRows {
+		return nil, apperr.Conflict("session not found or already revoked: %s", sessionID)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Session %s of employee %s revoked (%s)", session.ID, session.EmployeeID, reason)
+	return session, nil
+}
+
+// RevokeEmployeeSessionByToken ends the session whose current refresh token hashes to tokenHash
+func (s *Store) RevokeEmployeeSessionByToken(tokenHash, reason string) (*types.EmployeeSession, error) {
+	var sessionID uuid.UUID
+	err := s.DB.QueryRow(`
+		SELECT session_id FROM employee_session_tokens
+		WHERE token_hash = $1 AND rotated_at IS NULL
+	`, tokenHash).Scan(&sessionID)
+	if err == sql.ErrNoRows {
+		return nil, apperr.Permission("invalid refresh token")
+	}
+	if err != nil {
+		logger.Errorf("Failed to look up refresh token: %v", err)
+		return nil, err
+	}
+
+	return s.RevokeEmployeeSession(sessionID, nil, reason)
+}
+
+// RevokeEmployeeSessions ends every session of an employee that is not already revoked and
+// returns them
+func (s *Store) RevokeEmployeeSessions(employeeID uuid.UUID, revokedBy *uuid.UUID, reason string) ([]*types.EmployeeSession, error) {
+	rows, err := s.DB.Query(`
+		UPDATE employee_sessions s
+		SET revoked_at = NOW(), revoked_by = $2, revoke_reason = $3, firebase_refresh_token = NULL
+		FROM employees e
+		WHERE e.id = s.employee_id AND s.employee_id = $1 AND s.revoked_at IS NULL
+		RETURNING `+employeeSessionColumns,
+		employeeID, revokedBy, reason)
+	if err != nil {
+		logger.Errorf("Failed to revoke sessions of employee %s: %v", employeeID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []*types.EmployeeSession{}
+	for rows.Next() {
+		session, err := scanEmployeeSession(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan employee session: %v", err)
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Revoked %d sessions of employee %s (%s)", len(sessions), employeeID, reason)
+	return sessions, nil
+}
+
+// IsEmployeeSessionRevoked reports whether ID tokens from the sign-in at authTime belong to a
+// revoked session. Sign-ins made outside the API have no session and are not revoked.
+func (s *Store) IsEmployeeSessionRevoked(firebaseUID string, authTime int64) (bool, error) {
+	var revoked bool
+	err := s.DB.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM employee_sessions
+			WHERE firebase_uid = $1 AND auth_time = $2 AND revoked_at IS NOT NULL
+		)
+	`, firebaseUID, authTime).Scan(&revoked)
+	if err != nil {
+		logger.Errorf("Failed to check session revocation for firebase UID %s: %v", firebaseUID, err)
+		return false, err
+	}
+	return revoked, nil
+}
+
+// revokeEmployeeSession marks a session revoked and drops its Firebase refresh token; it returns
+// sql.ErrNoRows when the session is missing or already revoked
+func revokeEmployeeSession(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, sessionID uuid.UUID, revokedBy *uuid.UUID, reason string) (*types.EmployeeSession, error) {
+	session, err := scanEmployeeSession(q.QueryRow(`
+		UPDATE employee_sessions s
+		SET revoked_at = NOW(), revoked_by = $2, revoke_reason = $3, firebase_refresh_token = NULL
+		FROM employees e
+		WHERE e.id = s.employee_id AND s.id = $1 AND s.revoked_at IS NULL
+		RETURNING `+employeeSessionColumns,
+		sessionID, revokedBy, reason))
+	if err != nil && err != sql.ErrNoRows {
+		logger.Errorf("Failed to revoke session %s: %v", sessionID, err)
+	}
+	return session, err
+}
+
+// scanEmployeeSession reads employeeSessionColumns followed by any extra columns
+func scanEmployeeSession(row interface{ Scan(...interface{}) error }, extra ...interface{}) (*types.EmployeeSession, error) {
+	session := &types.EmployeeSession{}
+	dest := append([]interface{}{
+		&session.ID, &session.EmployeeID, &session.EmployeeEmail, &session.FirebaseUID, &session.AuthTime,
+		&session.IPAddress, &session.UserAgent, &session.CreatedAt, &session.LastRefreshedAt, &session.ExpiresAt,
+		&session.RevokedAt, &session.RevokedBy, &session.RevokeReason,
+	}, extra...)
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
@@ -0,0 +1,240 @@
+package store
+
+import (
+	"database/sql"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+const employeeTenantAccessColumns = `id, employee_id, tenant_id, role, is_active, created_at, updated_at, created_by`
+
+// scanEmployeeTenantAccess scans a row selected with employeeTenantAccessColumns
+func scanEmployeeTenantAccess(row interface{ Scan(...interface{}) error }) (*types.EmployeeTenantAssociation, error) {
+	access := &types.EmployeeTenantAssociation{}
+	err := row.Scan(&access.ID, &access.EmployeeID, &access.TenantID, &access.Role, &access.IsActive,
+		&access.CreatedAt, &access.UpdatedAt, &access.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+	return access, nil
+}
+
+// checkNoOpenBreakGlass refuses access changes while a break-glass grant holds the access; the
+// grant restores the prior role when it ends and would undo them
+func checkNoOpenBreakGlass(tx *sql.Tx, employeeID uuid.UUID, tenantID string) error {
+	var open bool
+	err := tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM break_glass_grants
+			WHERE employee_id = $1 AND tenant_id = $2 AND revoked_at IS NULL
+		)
+	`, employeeID, tenantID).Scan(&open)
+	if err != nil {
+		logger.Errorf("Failed to check break-glass grants of employee %s on tenant %s: %v", employeeID, tenantID, err)
+		return err
+	}
+	if open {
+		return apperr.Conflict("employee has an open break-glass grant on tenant %s; revoke it first", tenantID)
+	}
+	return nil
+}
+
+// AssignEmployeeToTenant gives an employee access to a tenant with role. Existing access, active
+// or not, is reactivated with the new role. It reports whether the access is new.
+func (s *Store) AssignEmployeeToTenant(employeeID uuid.UUID, tenantID, role string, assignedBy uuid.UUID) (*types.EmployeeTenantAssociation, bool, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, false, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM employees WHERE id = $1)`, employeeID).Scan(&exists); err != nil {
+		logger.Errorf("Failed to check employee %s: %v", employeeID, err)
+		return nil, false, err
+	}
+	if !exists {
+		return nil, false, apperr.NotFound("employee not found with ID: %s", employeeID)
+	}
+	if err := tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM tenant_connections WHERE tenant_id = $1)`, tenantID).Scan(&exists); err != nil {
+		logger.Errorf("Failed to check tenant %s: %v", tenantID, err)
+		return nil, false, err
+	}
+	if !exists {
+		return nil, false, apperr.NotFound("tenant not found: %s", tenantID)
+	}
+
+	if err := checkNoOpenBreakGlass(tx, employeeID, tenantID); err != nil {
+		return nil, false, err
+	}
+
+	var accessID uuid.UUID
+	err = tx.QueryRow(`
+		SELECT id FROM employee_tenant_access
+		WHERE employee_id = $1 AND tenant_id = $2
+		FOR UPDATE
+	`, employeeID, tenantID).Scan(&accessID)
+
+	var access *types.EmployeeTenantAssociation
+	created := false
+	switch {
+	case err == sql.ErrNoRows:
+		access, err = scanEmployeeTenantAccess(tx.QueryRow(`
+			INSERT INTO employee_tenant_access (employee_id, tenant_id, role, created_by)
+			VALUES ($1, $2, $3, $4)
+			RETURNING `+employeeTenantAccessColumns,
+			employeeID, tenantID, role, assignedBy))
+		created = true
+	case err != nil:
+		logger.Errorf("Failed to check tenant access for employee %s: %v", employeeID, err)
+		return nil, false, err
+	default:
+		access, err = scanEmployeeTenantAccess(tx.QueryRow(`
+			UPDATE employee_tenant_access
+			SET role = $2, is_active = true, updated_at = NOW()
+			WHERE id = $1
+			RETURNING `+employeeTenantAccessColumns,
+			accessID, role))
+	}
+	if err != nil {
+		logger.Errorf("Failed to assign employee %s to tenant %s: %v", employeeID, tenantID, err)
+		return nil, false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, false, err
+	}
+
+	logger.Infof("Employee %s assigned to tenant %s as %s by %s", employeeID, tenantID, role, assignedBy)
+	return access, created, nil
+}
+
+// RemoveEmployeeFromTenant deletes an employee's access to a tenant and ends their open tenant
+// contexts for it
+func (s *Store) RemoveEmployeeFromTenant(employeeID uuid.UUID, tenantID string) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkNoOpenBreakGlass(tx, employeeID, tenantID); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		DELETE FROM employee_tenant_access WHERE employee_id = $1 AND tenant_id = $2
+	`, employeeID, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to remove employee %s from tenant %s: %v", employeeID, tenantID, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return apperr.NotFound("employee %s has no access to tenant %s", employeeID, tenantID)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE employee_tenant_contexts
+		SET revoked_at = NOW()
+		WHERE employee_id = $1 AND tenant_id = $2 AND revoked_at IS NULL
+	`, employeeID, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to end tenant contexts of employee %s on tenant %s: %v", employeeID, tenantID, err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logger.Infof("Employee %s removed from tenant %s", employeeID, tenantID)
+	return nil
+}
+
+// GetTenantEmployees lists the employees with access to a tenant, active or not, by email
+func (s *Store) GetTenantEmployees(tenantID string) ([]*types.TenantEmployee, error) {
+	rows, err := s.DB.Query(`
+		SELECT eta.id, eta.employee_id, eta.tenant_id, eta.role, eta.is_active, eta.created_at, eta.updated_at, eta.created_by,
+		       e.email, e.first_name, e.last_name, e.role
+		FROM employee_tenant_access eta
+		JOIN employees e ON e.id = eta.employee_id
+		WHERE eta.tenant_id = $1
+		ORDER BY e.email
+	`, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get employees of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	employees := []*types.TenantEmployee{}
+	for rows.Next() {
+		employee := &types.TenantEmployee{}
+		err := rows.Scan(&employee.ID, &employee.EmployeeID, &employee.TenantID, &employee.Role, &employee.IsActive,
+			&employee.CreatedAt, &employee.UpdatedAt, &employee.CreatedBy,
+			&employee.Email, &employee.FirstName, &employee.LastName, &employee.EmployeeRole)
+		if err != nil {
+			logger.Errorf("Failed to scan tenant employee: %v", err)
+			return nil, err
+		}
+		employees = append(employees, employee)
+	}
+
+	return employees, rows.Err()
+}
+
+// GetEmployeeTenantIDs lists the tenants an employee has access to, active or not
+func (s *Store) GetEmployeeTenantIDs(employeeID uuid.UUID) ([]string, error) {
+	rows, err := s.DB.Query(`
+		SELECT tenant_id FROM employee_tenant_access WHERE employee_id = $1 ORDER BY tenant_id
+	`, employeeID)
+	if err != nil {
+		logger.Errorf("Failed to get tenants of employee %s: %v", employeeID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenantIDs := []string{}
+	for rows.Next() {
+		var tenantID string
+		if err := rows.Scan(&tenantID); err != nil {
+			logger.Errorf("Failed to scan employee tenant: %v", err)
+			return nil, err
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+
+	return tenantIDs, rows.Err()
+}
+
+// GetEmployeeTenantAccess lists the tenants an employee has access to, active or not, with the
+// tenant names, by tenant name
+func (s *Store) GetEmployeeTenantAccess(employeeID uuid.UUID) ([]*types.TenantAccess, error) {
+	rows, err := s.DB.Query(`
+		SELECT eta.tenant_id, tc.tenant_name, eta.role, eta.is_active
+		FROM employee_tenant_access eta
+		JOIN tenant_connections tc ON eta.tenant_id = tc.tenant_id
+		WHERE eta.employee_id = $1
+		ORDER BY tc.tenant_name
+	`, employeeID)
+	if err != nil {
+		logger.Errorf("Failed to get tenant access of employee %s: %v", employeeID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	access := []*types.TenantAccess{}
+	for rows.Next() {
+		tenant := &types.TenantAccess{}
+		if err := rows.Scan(&tenant.TenantID, &tenant.TenantName, &tenant.Role, &tenant.IsActive); err != nil {
+			logger.Errorf("Failed to scan tenant access: %v", err)
+			return nil, err
+		}
+		access = append(access, tenant)
+	}
+
+	return access, rows.Err()
+}
@@ -0,0 +1,73 @@
+package store
+
+import (
+	"database/sql"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// AssignFiling makes employeeID the accountant working a filing, replacing any earlier assignment
+func (s *Store) AssignFiling(tenantID string, filingID, employeeID uuid.UUID, assignedBy *uuid.UUID) (*types.FilingAssignment, error) {
+	a := &types.FilingAssignment{}
+	err := s.DB.QueryRow(`
+		INSERT INTO filing_assignments (tenant_id, filing_id, employee_id, assigned_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, filing_id) DO UPDATE
+		SET employee_id = EXCLUDED.employee_id, assigned_by = EXCLUDED.assigned_by, assigned_at = NOW()
+		RETURNING tenant_id, filing_id, employee_id, assigned_by, assigned_at
+	`, tenantID, filingID, employeeID, assignedBy).Scan(&a.TenantID, &a.FilingID, &a.EmployeeID, &a.AssignedBy, &a.AssignedAt)
+	if err != nil {
+		logger.Errorf("Failed to assign filing %s of tenant %s to employee %s: %v", filingID, tenantID, employeeID, err)
+		return nil, err
+	}
+
+	logger.Infof("Assigned filing %s of tenant %s to employee %s", filingID, tenantID, employeeID)
+	return a, nil
+}
+
+// GetFilingAssignments returns a tenant's filing assignments, only employeeID's when it is given
+func (s *Store) GetFilingAssignments(tenantID string, employeeID *uuid.UUID) ([]*types.FilingAssignment, error) {
+	rows, err := s.DB.Query(`
+		SELECT tenant_id, filing_id, employee_id, assigned_by, assigned_at
+		FROM filing_assignments
+		WHERE tenant_id = $1 AND ($2::uuid IS NULL OR employee_id = $2)
+		ORDER BY assigned_at DESC
+	`, tenantID, employeeID)
+	if err != nil {
+		logger.Errorf("Failed to get filing assignments of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	assignments := []*types.FilingAssignment{}
+	for rows.Next() {
+		a := &types.FilingAssignment{}
+		if err := rows.Scan(&a.TenantID, &a.FilingID, &a.EmployeeID, &a.AssignedBy, &a.AssignedAt); err != nil {
+			logger.Errorf("Failed to scan filing assignment: %v", err)
+			return nil, err
+		}
+		assignments = append(assignments, a)
+	}
+	return assignments, rows.Err()
+}
+
+// GetFilingAssignment returns the accountant assignment of a filing
+func (s *Store) GetFilingAssignment(tenantID string, filingID uuid.UUID) (*types.FilingAssignment, error) {
+	a := &types.FilingAssignment{}
+	err := s.DB.QueryRow(`
+		SELECT tenant_id, filing_id, employee_id, assigned_by, assigned_at
+		FROM filing_assignments
+		WHERE tenant_id = $1 AND filing_id = $2
+	`, tenantID, filingID).Scan(&a.TenantID, &a.FilingID, &a.EmployeeID, &a.AssignedBy, &a.AssignedAt)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("filing %s is not assigned", filingID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get assignment of filing %s of tenant %s: %v", filingID, tenantID, err)
+		return nil, err
+	}
+	return a, nil
+}
@@ -0,0 +1,47 @@
+package store
+
+import (
+	"context"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/uuid"
+)
+
+// GetFilingChecklist returns the documents a filing needs and which of them were uploaded
+func (s *Store) GetFilingChecklist(ctx context.Context, tenantID string, filingID uuid.UUID) (*types.FilingChecklist, error) {
+	clientID, err := s.GetFilingClientID(ctx, tenantID, filingID)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.GetClientComprehensive(ctx, tenantID, clientID.String())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, filing := range client.Filings {
+		if filing.ID != filingID {
+			continue
+		}
+		if err := s.attachScanStatuses(tenantID, filing.Documents); err != nil {
+			return nil, err
+		}
+		return types.NewFilingChecklist(filing, client.Dependents), nil
+	}
+	return nil, apperr.NotFound("filing not found: %s", filingID)
+}
+
+// ApplyFilingChecklists sets the checklist of each of a client's filings. Scan statuses are read
+// for all of the client's documents at once, so infected uploads are not counted.
+func (s *Store) ApplyFilingChecklists(tenantID string, client *types.ClientComprehensive) error {
+	var documents []*types.Document
+	for _, filing := range client.Filings {
+		documents = append(documents, filing.Documents...)
+	}
+	if err := s.attachScanStatuses(tenantID, documents); err != nil {
+		return err
+	}
+	client.ApplyFilingChecklists()
+	return nil
+}
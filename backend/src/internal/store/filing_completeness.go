@@ -0,0 +1,123 @@
+package store
+
+import (
+	"database/sql"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/lib/pq"
+)
+
+// requestCounts are the open and fulfilled document requests of a filing or client
+type requestCounts struct {
+	open, fulfilled int
+}
+
+// ApplyFilingCompleteness scores every filing of the given clients. Document requests and
+// signature requests are read for all of them at once, in one query each. Requests for a filing
+// count toward that filing only; requests about the client as a whole, such as a replacement
+// photo ID, count toward the client's latest filing.
+func (s *Store) ApplyFilingCompleteness(tenantID string, clients []*types.ClientComprehensive) error {
+	var clientIDs, filingIDs []string
+	for _, client := range clients {
+		if client.Client == nil {
+			continue
+		}
+		clientIDs = append(clientIDs, client.Client.ID.String())
+		for _, filing := range client.Filings {
+			filingIDs = append(filingIDs, filing.ID.String())
+		}
+	}
+	if len(filingIDs) == 0 {
+		return nil
+	}
+
+	filingRequests := map[string]requestCounts{} // by filing ID
+	clientRequests := map[string]requestCounts{} // by client ID, for requests without a filing
+	rows, err := s.DB.Query(`
+		SELECT client_id, filing_id,
+		       COUNT(*) FILTER (WHERE status = 'OPEN'),
+		       COUNT(*) FILTER (WHERE status = 'FULFILLED')
+		FROM document_requests
+		WHERE tenant_id = $1 AND client_id = ANY($2::uuid[])
+		GROUP BY client_id, filing_id
+	`, tenantID, pq.Array(clientIDs))
+	if err != nil {
+		logger.Errorf("Failed to count document requests for tenant %s: %v", tenantID, err)
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var clientID string
+		var filingID sql.NullString
+		var counts requestCounts
+		if err := rows.Scan(&clientID, &filingID, &counts.open, &counts.fulfilled); err != nil {
+			logger.Errorf("Failed to scan document request counts: %v", err)
+			return err
+		}
+		if filingID.Valid {
+			filingRequests[filingID.String] = counts
+		} else {
+			clientRequests[clientID] = counts
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	signatures := map[string]string{}
+	sigRows, err := s.DB.Query(`
+		SELECT DISTINCT ON (filing_id) filing_id, status
+		FROM signature_requests
+		WHERE tenant_id = $1 AND filing_id = ANY($2::uuid[])
+		ORDER BY filing_id, sent_at DESC
+	`, tenantID, pq.Array(filingIDs))
+	if err != nil {
+		logger.Errorf("Failed to get signature statuses for tenant %s: %v", tenantID, err)
+		return err
+	}
+	defer sigRows.Close()
+	for sigRows.Next() {
+		var filingID, status string
+		if err := sigRows.Scan(&filingID, &status); err != nil {
+			logger.Errorf("Failed to scan signature status: %v", err)
+			return err
+		}
+		signatures[filingID] = status
+	}
+	if err := sigRows.Err(); err != nil {
+		return err
+	}
+
+	for _, client := range clients {
+		if client.Client == nil {
+			continue
+		}
+		latest := latestFiling(client.Filings)
+		for _, filing := range client.Filings {
+			status, ok := signatures[filing.ID.String()]
+			if !ok {
+				status = types.SignatureStatusNone
+			}
+			counts := filingRequests[filing.ID.String()]
+			if filing == latest {
+				unattached := clientRequests[client.Client.ID.String()]
+				counts.open += unattached.open
+				counts.fulfilled += unattached.fulfilled
+			}
+			filing.Completeness = types.NewFilingCompleteness(filing, counts.open, counts.fulfilled, status)
+		}
+	}
+	return nil
+}
+
+// latestFiling returns the filing for the most recent tax year, or nil when there are none
+func latestFiling(filings []*types.Filing) *types.Filing {
+	var latest *types.Filing
+	for _, filing := range filings {
+		if latest == nil || filing.Year > latest.Year {
+			latest = filing
+		}
+	}
+	return latest
+}
@@ -0,0 +1,60 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// GetFilingResult retrieves the recorded outcome of a filing using the appropriate adapter
+func (s *Store) GetFilingResult(ctx context.Context, tenantID string, filingID string) (*types.FilingResult, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	resultAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	return resultAdapter.GetFilingResult(ctx, db, tc.SchemaPrefix, filingID)
+}
+
+// UpsertFilingResult records the outcome of a filing using the appropriate adapter
+func (s *Store) UpsertFilingResult(ctx context.Context, tenantID string, result *types.FilingResult) (*types.FilingResult, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	resultAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	if err := s.checkFilingWritable(ctx, tenantID, db, tc, resultAdapter, result.FilingID.String()); err != nil {
+		return nil, err
+	}
+
+	return resultAdapter.UpsertFilingResult(ctx, db, tc.SchemaPrefix, result)
+}
@@ -0,0 +1,70 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// GetFilingStatusTexts returns a tenant's own filing status texts, without the built-in ones
+// they override
+func (s *Store) GetFilingStatusTexts(tenantID string) (types.FilingStatusTexts, error) {
+	var data sql.NullString
+	err := s.DB.QueryRow(`
+		SELECT filing_status_texts::text FROM tenant_connections
+		WHERE tenant_id = $1 AND is_active = true
+	`, tenantID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("tenant not found: %s", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get filing status texts of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	texts := types.FilingStatusTexts{}
+	if !data.Valid {
+		return texts, nil
+	}
+	if err := json.Unmarshal([]byte(data.String), &texts); err != nil {
+		logger.Errorf("Invalid filing status texts for tenant %s, using the built-in texts: %v", tenantID, err)
+		return types.FilingStatusTexts{}, nil
+	}
+	return texts, nil
+}
+
+// UpdateFilingStatusTexts replaces a tenant's filing status texts and records the change in the
+// tenant's configuration history. Empty texts go back to the built-in ones.
+func (s *Store) UpdateFilingStatusTexts(tenantID string, texts types.FilingStatusTexts, employeeID uuid.UUID) error {
+	var data interface{}
+	if len(texts) > 0 {
+		encoded, err := json.Marshal(texts)
+		if err != nil {
+			return fmt.Errorf("failed to encode filing status texts: %w", err)
+		}
+		data = string(encoded)
+	}
+
+	query := `
+		UPDATE tenant_connections
+		SET filing_status_texts = $1, updated_at = NOW()
+		WHERE tenant_id = $2
+	`
+
+	err := s.ChangeTenantConfig(tenantID, types.TenantConfigActionUpdate, &employeeID, func(tx *sql.Tx) error {
+		_, err := tx.Exec(query, data, tenantID)
+		return err
+	})
+	if err != nil {
+		logger.Errorf("Failed to update filing status texts for tenant %s: %v", tenantID, err)
+		return err
+	}
+
+	logger.Infof("Updated filing status texts for tenant %s", tenantID)
+	return nil
+}
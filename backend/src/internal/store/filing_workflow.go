@@ -0,0 +1,194 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/sqlident"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// GetFilingWorkflow returns the status of a filing and the statuses it may move to
+func (s *Store) GetFilingWorkflow(ctx context.Context, tenantID string, filingID string) (*types.FilingWorkflow, error) {
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	filingAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	workflow, err := filingAdapter.GetFilingWorkflow(ctx, db, tc.SchemaPrefix, filingID)
+	if err != nil {
+		return nil, err
+	}
+	workflow.Next = types.FilingStatusTransitions(workflow.Status)
+	return workflow, nil
+}
+
+// ChangeFilingStatus moves a filing to a new workflow status and records who moved it. Moves the
+// workflow does not allow from the filing's current status fail with a conflict.
+func (s *Store) ChangeFilingStatus(ctx context.Context, tenantID string, filingID string, update *types.FilingStatusUpdate, employee *types.Employee) (*types.FilingStatusChange, error) {
+	if !types.IsValidFilingStatus(update.Status) {
+		return nil, apperr.Validation("invalid filing status %q", update.Status)
+	}
+
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	filingAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	if err := s.checkFilingWritable(ctx, tenantID, db, tc, filingAdapter, filingID); err != nil {
+		return nil, err
+	}
+
+	workflow, err := filingAdapter.GetFilingWorkflow(ctx, db, tc.SchemaPrefix, filingID)
+	if err != nil {
+		return nil, err
+	}
+	if !types.CanTransitionFilingStatus(workflow.Status, update.Status) {
+		return nil, apperr.Conflict("filing %s cannot move from %s to %s", filingID,
+			types.WorkflowFilingStatus(workflow.Status), update.Status)
+	}
+
+	// The history row is written first so a status change is never left unrecorded
+	change := &types.FilingStatusChange{
+		TenantID:       tenantID,
+		FilingID:       workflow.FilingID,
+		ClientID:       workflow.ClientID,
+		TaxYear:        workflow.Year,
+		FromStatus:     workflow.Status,
+		ToStatus:       update.Status,
+		Note:           update.Note,
+		ChangedBy:      &employee.ID,
+		ChangedByEmail: employee.Email,
+	}
+	err = s.DB.QueryRow(`
+		INSERT INTO filing_status_history (tenant_id, filing_id, client_id, tax_year, from_status, to_status, note, changed_by, changed_by_email)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, changed_at
+	`, tenantID, change.FilingID, change.ClientID, change.TaxYear, change.FromStatus, change.ToStatus, change.Note,
+		employee.ID, employee.Email).Scan(&change.ID, &change.ChangedAt)
+	if err != nil {
+		logger.Errorf("Failed to record status change of filing %s: %v", filingID, err)
+		return nil, err
+	}
+
+	if err := filingAdapter.SetFilingStatus(ctx, db, tc.SchemaPrefix, filingID, workflow.Status, update.Status); err != nil {
+		if _, delErr := s.DB.Exec(`DELETE FROM filing_status_history WHERE id = $1`, change.ID); delErr != nil {
+			logger.Errorf("Failed to remove status change %s of filing %s: %v", change.ID, filingID, delErr)
+		}
+		return nil, err
+	}
+
+	logger.Infof("Employee %s moved filing %s of tenant %s from %q to %s", employee.Email, filingID, tenantID, workflow.Status, update.Status)
+	return change, nil
+}
+
+// GetFilingStatusHistory returns the status changes of a filing, oldest first
+func (s *Store) GetFilingStatusHistory(tenantID string, filingID string) ([]*types.FilingStatusChange, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, tenant_id, filing_id, client_id, tax_year, from_status, to_status, note, changed_by, changed_by_email, changed_at
+		FROM filing_status_history
+		WHERE tenant_id = $1 AND filing_id = $2
+		ORDER BY changed_at, id
+	`, tenantID, filingID)
+	if err != nil {
+		logger.Errorf("Failed to get status history of filing %s: %v", filingID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []*types.FilingStatusChange{}
+	for rows.Next() {
+		c := &types.FilingStatusChange{}
+		if err := rows.Scan(&c.ID, &c.TenantID, &c.FilingID, &c.ClientID, &c.TaxYear, &c.FromStatus, &c.ToStatus,
+			&c.Note, &c.ChangedBy, &c.ChangedByEmail, &c.ChangedAt); err != nil {
+			logger.Errorf("Failed to scan filing status change: %v", err)
+			return nil, err
+		}
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+// MarkFilingCompleted marks a filing of a tax platform tenant COMPLETED
+func (s *Store) MarkFilingCompleted(ctx context.Context, tenantID string, filingID string) error {
+	db, tc, err := s.GetTenantSQLDB(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	result, err := db.ExecContext(ctx, `
+		UPDATE `+sqlident.Schema(tc.SchemaPrefix)+`.filing_status
+		SET is_completed = true, status = 'COMPLETED'
+		WHERE filing_id = $1
+	`, filingID)
+	if err != nil {
+		logger.Errorf("Failed to mark filing %s completed: %v", filingID, err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return apperr.NotFound("filing status not found")
+	}
+	return nil
+}
+
+// GetFilingContact returns the client of a filing of a tax platform tenant and the filing's tax year
+func (s *Store) GetFilingContact(ctx context.Context, tenantID string, filingID string) (*types.FilingContact, error) {
+	db, tc, err := s.GetTenantSQLDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	schema := sqlident.Schema(tc.SchemaPrefix)
+	contact := &types.FilingContact{}
+	err = db.QueryRowContext(ctx, `
+		SELECT u.id, u.email, COALESCE(u.first_name, ''), COALESCE(u.last_name, ''), f.year, u.death_date IS NOT NULL
+		FROM `+schema+`.filing f
+		JOIN `+schema+`.user u ON f.user_id = u.id
+		WHERE f.id = $1
+	`, filingID).Scan(&contact.ClientID, &contact.Email, &contact.FirstName, &contact.LastName, &contact.TaxYear, &contact.Deceased)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("filing not found: %s", filingID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get client of filing %s: %v", filingID, err)
+		return nil, err
+	}
+	return contact, nil
+}
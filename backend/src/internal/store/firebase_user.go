@@ -0,0 +1,107 @@
+package store
+
+import (
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/lib/pq"
+)
+
+// GetKnownFirebaseUIDs returns the Firebase UIDs of every employee and tenant user, active or not
+func (s *Store) GetKnownFirebaseUIDs() (map[string]bool, error) {
+	rows, err := s.DB.Query(`
+		SELECT firebase_uid FROM employees
+		UNION
+		SELECT firebase_uid FROM tenant_users
+	`)
+	if err != nil {
+		logger.Errorf("Failed to get known Firebase UIDs: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	uids := map[string]bool{}
+	for rows.Next() {
+		var uid string
+		if err := rows.Scan(&uid); err != nil {
+			logger.Errorf("Failed to scan Firebase UID: %v", err)
+			return nil, err
+		}
+		uids[uid] = true
+	}
+	return uids, rows.Err()
+}
+
+// ReplaceFirebaseOrphans records the orphaned Firebase accounts found by a reconciliation run,
+// dropping the ones no longer orphaned. It returns the accounts reported for the first time.
+func (s *Store) ReplaceFirebaseOrphans(orphans []*types.FirebaseOrphanUser) ([]*types.FirebaseOrphanUser, error) {
+	if err := s.requireScope(types.ScopeJobsWrite); err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		logger.Errorf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var added []*types.FirebaseOrphanUser
+	uids := make([]string, 0, len(orphans))
+	for _, o := range orphans {
+		var inserted bool // xmax is 0 only on a row this statement inserted
+		err := tx.QueryRow(`
+			INSERT INTO firebase_orphan_users (uid, email, disabled, created_at, last_sign_in_at)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (uid) DO UPDATE
+			SET email = EXCLUDED.email, disabled = EXCLUDED.disabled,
+			    last_sign_in_at = EXCLUDED.last_sign_in_at, last_seen_at = NOW()
+			RETURNING first_seen_at, last_seen_at, (xmax = 0)
+		`, o.UID, o.Email, o.Disabled, o.CreatedAt, o.LastSignInAt).Scan(&o.FirstSeenAt, &o.LastSeenAt, &inserted)
+		if err != nil {
+			logger.Errorf("Failed to record orphaned Firebase user %s: %v", o.UID, err)
+			return nil, err
+		}
+		if inserted {
+			added = append(added, o)
+		}
+		uids = append(uids, o.UID)
+	}
+
+	if _, err := tx.Exec(`DELETE FROM firebase_orphan_users WHERE NOT (uid = ANY($1))`, pq.Array(uids)); err != nil {
+		logger.Errorf("Failed to clear reconciled Firebase users: %v", err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Errorf("Failed to commit Firebase orphans: %v", err)
+		return nil, err
+	}
+	return added, nil
+}
+
+// GetFirebaseOrphans returns the orphaned Firebase accounts found by the last reconciliation run,
+// oldest reported first
+func (s *Store) GetFirebaseOrphans() ([]*types.FirebaseOrphanUser, error) {
+	rows, err := s.DB.Query(`
+		SELECT uid, email, disabled, created_at, last_sign_in_at, first_seen_at, last_seen_at
+		FROM firebase_orphan_users
+		ORDER BY first_seen_at, uid
+	`)
+	if err != nil {
+		logger.Errorf("Failed to get orphaned Firebase users: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	orphans := []*types.FirebaseOrphanUser{}
+	for rows.Next() {
+		o := &types.FirebaseOrphanUser{}
+		if err := rows.Scan(&o.UID, &o.Email, &o.Disabled, &o.CreatedAt, &o.LastSignInAt, &o.FirstSeenAt, &o.LastSeenAt); err != nil {
+			logger.Errorf("Failed to scan orphaned Firebase user: %v", err)
+			return nil, err
+		}
+		orphans = append(orphans, o)
+	}
+	return orphans, rows.Err()
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// UpsertFormTemplate creates or replaces a tenant's template for a form kind
+func (s *Store) UpsertFormTemplate(t *types.FormTemplate) error {
+	fieldMap, err := json.Marshal(t.FieldMap)
+	if err != nil {
+		return fmt.Errorf("failed to encode field map: %w", err)
+	}
+
+	err = s.DB.QueryRow(`
+		INSERT INTO form_templates (tenant_id, kind, template_path, field_map, updated_by)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, kind) DO UPDATE
+		SET template_path = EXCLUDED.template_path,
+		    field_map = EXCLUDED.field_map,
+		    updated_by = EXCLUDED.updated_by,
+		    updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`, t.TenantID, t.Kind, t.TemplatePath, string(fieldMap), t.UpdatedBy).Scan(&t.ID, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		logger.Errorf("Failed to save %s form template for tenant %s: %v", t.Kind, t.TenantID, err)
+		return err
+	}
+
+	logger.Infof("Saved %s form template for tenant %s", t.Kind, t.TenantID)
+	return nil
+}
+
+// GetFormTemplate returns a tenant's template for a form kind
+func (s *Store) GetFormTemplate(tenantID, kind string) (*types.FormTemplate, error) {
+	t := &types.FormTemplate{}
+	var fieldMap []byte
+
+	err := s.DB.QueryRow(`
+		SELECT id, tenant_id, kind, template_path, field_map, updated_by, created_at, updated_at
+		FROM form_templates
+		WHERE tenant_id = $1 AND kind = $2
+	`, tenantID, kind).Scan(&t.ID, &t.TenantID, &t.Kind, &t.TemplatePath, &fieldMap, &t.UpdatedBy, &t.CreatedAt, &t.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("form template not found: %s", kind)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get %s form template for tenant %s: %v", kind, tenantID, err)
+		return nil, err
+	}
+
+	if err := json.Unmarshal(fieldMap, &t.FieldMap); err != nil {
+		return nil, fmt.Errorf("failed to decode field map: %w", err)
+	}
+	return t, nil
+}
+
+// GetFormTemplates lists a tenant's form templates
+func (s *Store) GetFormTemplates(tenantID string) ([]*types.FormTemplate, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, tenant_id, kind, template_path, field_map, updated_by, created_at, updated_at
+		FROM form_templates
+		WHERE tenant_id = $1
+		ORDER BY kind
+	`, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to query form templates for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var templates []*types.FormTemplate
+	for rows.Next() {
+		t := &types.FormTemplate{}
+		var fieldMap []byte
+		if err := rows.Scan(&t.ID, &t.TenantID, &t.Kind, &t.TemplatePath, &fieldMap, &t.UpdatedBy, &t.CreatedAt, &t.UpdatedAt); err != nil {
+			logger.Errorf("Failed to scan form template: %v", err)
+			return nil, err
+		}
+		if err := json.Unmarshal(fieldMap, &t.FieldMap); err != nil {
+			return nil, fmt.Errorf("failed to decode field map: %w", err)
+		}
+		templates = append(templates, t)
+	}
+
+	return templates, rows.Err()
+}
+
+// DeleteFormTemplate removes a tenant's template for a form kind
+func (s *Store) DeleteFormTemplate(tenantID, kind string) error {
+	result, err := s.DB.Exec(`DELETE FROM form_templates WHERE tenant_id = $1 AND kind = $2`, tenantID, kind)
+	if err != nil {
+		logger.Errorf("Failed to delete %s form template for tenant %s: %v", kind, tenantID, err)
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return apperr.NotFound("form template not found: %s", kind)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// CreateCommission evaluates the tenant's fraud rules and creates the commission.
+// Commissions matching any rule are created in REVIEW status with the reasons
+// recorded in notes so they land in the admin review queue instead of PENDING.
+func (s *Store) CreateCommission(ctx context.Context, tenantID string, commission *types.Commission, ipAddress string) (*types.Commission, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	commissionAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	rules := tc.FraudRules
+	if rules == nil {
+		rules = types.DefaultFraudRules()
+	}
+	if rules.CheckSameSSN {
+		if err := s.requireScope(types.ScopeSSNDecrypt); err != nil {
+			return nil, err
+		}
+	}
+
+	flags, err := commissionAdapter.EvaluateCommissionFraud(ctx, db, tc.SchemaPrefix, commission, ipAddress, rules)
+	if err != nil {
+		logger.Errorf("Fraud evaluation failed for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to evaluate fraud rules: %w", err)
+	}
+
+	commission.Status = types.CommissionStatusPending
+	if len(flags) > 0 {
+		reasons := make([]string, 0, len(flags))
+		for _, f := range flags {
+			reasons = append(reasons, fmt.Sprintf("%s: %s", f.Rule, f.Reason))
+		}
+		note := "Flagged for review - " + strings.Join(reasons, "; ")
+		if commission.Notes != nil && *commission.Notes != "" {
+			note = *commission.Notes + "\n" + note
+		}
+		commission.Notes = &note
+		commission.Status = types.CommissionStatusReview
+		logger.Warningf("Commission for affiliate %s in tenant %s flagged for review: %s", commission.AffiliateID, tenantID, strings.Join(reasons, "; "))
+	}
+
+	created, err := commissionAdapter.CreateCommission(ctx, db, tc.SchemaPrefix, commission)
+	if err != nil {
+		return nil, err
+	}
+	created.FraudFlags = flags
+
+	// Affiliates hear about flagged commissions only once they are approved
+	if created.Status == types.CommissionStatusPending {
+		s.notifyAffiliate(tenantID, created, types.AffiliateEventCommissionCreated)
+	}
+
+	return created, nil
+}
+
+// GetFraudRules returns the effective fraud rules for a tenant
+func (s *Store) GetFraudRules(tenantID string) (*types.FraudRules, error) {
+	tc, err := s.getTenantConnection(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	if tc.FraudRules == nil {
+		return types.DefaultFraudRules(), nil
+	}
+	return tc.FraudRules, nil
+}
+
+// UpdateFraudRules replaces the fraud rule configuration for a tenant and records the change
+// in the tenant's configuration history
+func (s *Store) UpdateFraudRules(tenantID string, rules *types.FraudRules, employeeID uuid.UUID) error {
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return fmt.Errorf("failed to encode fraud rules: %w", err)
+	}
+
+	query := `
+		UPDATE tenant_connections
+		SET fraud_rules = $1, updated_at = NOW()
+		WHERE tenant_id = $2
+	`
+
+	err = s.ChangeTenantConfig(tenantID, types.TenantConfigActionUpdate, &employeeID, func(tx *sql.Tx) error {
+		_, err := tx.Exec(query, string(data), tenantID)
+		return err
+	})
+	if err != nil {
+		logger.Errorf("Failed to update fraud rules for tenant %s: %v", tenantID, err)
+		return err
+	}
+
+	logger.Infof("Updated fraud rules for tenant %s", tenantID)
+	return nil
+}
@@ -0,0 +1,179 @@
+package store
+
+import (
+	"database/sql"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const identityDocumentColumns = `id, tenant_id, tenant_user_id, client_id, kind, file_name, content_type, size_bytes,
+	storage_path, status, expires_on::text, name_matched, flags, provider, reviewed_by, reviewed_at, review_note, created_at`
+
+// CreateIdentityDocument records an uploaded identity document and its automated check results
+func (s *Store) CreateIdentityDocument(d *types.IdentityDocument) error {
+	if d.Flags == nil {
+		d.Flags = []string{}
+	}
+
+	err := s.DB.QueryRow(`
+		INSERT INTO identity_documents (
+			id, tenant_id, tenant_user_id, client_id, kind, file_name, content_type, size_bytes,
+			storage_path, status, expires_on, name_matched, flags, provider
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		RETURNING created_at
+	`, d.ID, d.TenantID, d.TenantUserID, d.ClientID, d.Kind, d.FileName, d.ContentType, d.SizeBytes,
+		d.StoragePath, d.Status, d.ExpiresOn, d.NameMatched, pq.Array(d.Flags), d.Provider).Scan(&d.CreatedAt)
+	if err != nil {
+		logger.Errorf("Failed to create identity document for tenant user %s: %v", d.TenantUserID, err)
+		return err
+	}
+
+	logger.Infof("Recorded identity document %s (%s) for tenant %s", d.ID, d.Status, d.TenantID)
+	if d.Status == types.IdentityStatusVerified {
+		s.fulfillIdentityDocumentRequests(d)
+	}
+	return nil
+}
+
+// GetIdentityDocument returns one identity document of a tenant
+func (s *Store) GetIdentityDocument(tenantID string, id uuid.UUID) (*types.IdentityDocument, error) {
+	row := s.DB.QueryRow(`
+		SELECT `+identityDocumentColumns+`
+		FROM identity_documents
+		WHERE id = $1 AND tenant_id = $2
+	`, id, tenantID)
+
+	d, err := scanIdentityDocument(row)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("identity document not found: %s", id)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get identity document %s: %v", id, err)
+		return nil, err
+	}
+	return d, nil
+}
+
+// GetIdentityDocuments lists a tenant's identity documents, newest first, optionally by status
+func (s *Store) GetIdentityDocuments(tenantID string, status *string) ([]*types.IdentityDocument, error) {
+	query := `
+		SELECT ` + identityDocumentColumns + `
+		FROM identity_documents
+		WHERE tenant_id = $1
+	`
+	args := []interface{}{tenantID}
+	if status != nil {
+		query += " AND status = $2"
+		args = append(args, *status)
+	}
+	query += " ORDER BY created_at DESC"
+
+	return s.queryIdentityDocuments(query, args...)
+}
+
+// GetClientIdentityDocuments lists a client's identity documents, newest first.
+// Documents uploaded before the client record existed are found through the client's portal user.
+func (s *Store) GetClientIdentityDocuments(tenantID string, clientID uuid.UUID) ([]*types.IdentityDocument, error) {
+	return s.queryIdentityDocuments(`
+		SELECT `+identityDocumentColumns+`
+		FROM identity_documents
+		WHERE tenant_id = $1
+		  AND (client_id = $2 OR tenant_user_id IN (
+		      SELECT id FROM tenant_users WHERE tenant_id = $1 AND client_id = $2
+		  ))
+		ORDER BY created_at DESC
+	`, tenantID, clientID)
+}
+
+// GetTenantUserIdentityDocuments lists the identity documents a portal user uploaded, newest first
+func (s *Store) GetTenantUserIdentityDocuments(tenantUserID uuid.UUID) ([]*types.IdentityDocument, error) {
+	return s.queryIdentityDocuments(`
+		SELECT `+identityDocumentColumns+`
+		FROM identity_documents
+		WHERE tenant_user_id = $1
+		ORDER BY created_at DESC
+	`, tenantUserID)
+}
+
+// ReviewIdentityDocument records an admin decision on an identity document.
+// Rejected documents are final; the client uploads a new one instead.
+func (s *Store) ReviewIdentityDocument(tenantID string, id uuid.UUID, status string, note *string, reviewedBy uuid.UUID) (*types.IdentityDocument, error) {
+	row := s.DB.QueryRow(`
+		UPDATE identity_documents
+		SET status = $3, review_note = $4, reviewed_by = $5, reviewed_at = NOW()
+		WHERE id = $1 AND tenant_id = $2 AND status <> 'REJECTED'
+		RETURNING `+identityDocumentColumns,
+		id, tenantID, status, note, reviewedBy)
+
+	d, err := scanIdentityDocument(row)
+	if err == sql.ErrNoRows {
+		if _, getErr := s.GetIdentityDocument(tenantID, id); getErr != nil {
+			return nil, getErr
+		}
+		return nil, apperr.Conflict("identity document %s was already rejected", id)
+	}
+	if err != nil {
+		logger.Errorf("Failed to review identity document %s: %v", id, err)
+		return nil, err
+	}
+
+	logger.Infof("Identity document %s marked %s by %s", id, status, reviewedBy)
+	if d.Status == types.IdentityStatusVerified {
+		s.fulfillIdentityDocumentRequests(d)
+	}
+	return d, nil
+}
+
+// fulfillIdentityDocumentRequests closes the client's open photo ID requests with a verified
+// identity document. Any kind of ID replaces an expiring one.
+func (s *Store) fulfillIdentityDocumentRequests(d *types.IdentityDocument) {
+	clientID := d.ClientID
+	if clientID == nil {
+		if err := s.DB.QueryRow(`SELECT client_id FROM tenant_users WHERE id = $1`, d.TenantUserID).Scan(&clientID); err != nil {
+			logger.Errorf("Failed to find client of identity document %s: %v", d.ID, err)
+			return
+		}
+	}
+
+	kinds := []string{types.IdentityDocumentDriversLicense, types.IdentityDocumentStateID, types.IdentityDocumentPassport}
+	s.fulfillDocumentRequests(d.TenantID, *clientID, kinds, d.ID)
+}
+
+// queryIdentityDocuments runs a query selecting identityDocumentColumns
+func (s *Store) queryIdentityDocuments(query string, args ...interface{}) ([]*types.IdentityDocument, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		logger.Errorf("Failed to query identity documents: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []*types.IdentityDocument{}
+	for rows.Next() {
+		d, err := scanIdentityDocument(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan identity document: %v", err)
+			return nil, err
+		}
+		documents = append(documents, d)
+	}
+
+	return documents, rows.Err()
+}
+
+// scanIdentityDocument scans an identity_documents row selected with identityDocumentColumns
+func scanIdentityDocument(row interface{ Scan(...interface{}) error }) (*types.IdentityDocument, error) {
+	d := &types.IdentityDocument{}
+	err := row.Scan(&d.ID, &d.TenantID, &d.TenantUserID, &d.ClientID, &d.Kind, &d.FileName, &d.ContentType, &d.SizeBytes,
+		&d.StoragePath, &d.Status, &d.ExpiresOn, &d.NameMatched, pq.Array(&d.Flags), &d.Provider,
+		&d.ReviewedBy, &d.ReviewedAt, &d.ReviewNote, &d.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return d, nil
+}
@@ -0,0 +1,194 @@
+package store
+
+import (
+	"database/sql"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// GetInboundAddress returns a tenant's inbound email address local part
+func (s *Store) GetInboundAddress(tenantID string) (*types.InboundAddress, error) {
+	a := &types.InboundAddress{}
+	err := s.DB.QueryRow(`
+		SELECT tenant_id, local_part, created_by, created_at
+		FROM inbound_email_addresses WHERE tenant_id = $1
+	`, tenantID).Scan(&a.TenantID, &a.LocalPart, &a.CreatedBy, &a.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("tenant %s has no inbound email address", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get inbound address for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	return a, nil
+}
+
+// SetInboundAddress gives a tenant a new inbound local part; mail to the previous one is dropped
+func (s *Store) SetInboundAddress(tenantID, localPart string, createdBy uuid.UUID) (*types.InboundAddress, error) {
+	a := &types.InboundAddress{TenantID: tenantID, LocalPart: localPart}
+	err := s.DB.QueryRow(`
+		INSERT INTO inbound_email_addresses (tenant_id, local_part, created_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET local_part = EXCLUDED.local_part, created_by = EXCLUDED.created_by, created_at = NOW()
+		RETURNING created_by, created_at
+	`, tenantID, localPart, createdBy).Scan(&a.CreatedBy, &a.CreatedAt)
+	if err != nil {
+		logger.Errorf("Failed to set inbound address for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	logger.Infof("Tenant %s inbound address set to %s", tenantID, localPart)
+	return a, nil
+}
+
+// GetTenantByInboundLocalPart returns the active tenant an inbound local part belongs to
+func (s *Store) GetTenantByInboundLocalPart(localPart string) (string, error) {
+	var tenantID string
+	err := s.DB.QueryRow(`
+		SELECT a.tenant_id FROM inbound_email_addresses a
+		JOIN tenant_connections t ON t.tenant_id = a.tenant_id
+		WHERE a.local_part = $1 AND t.is_active = true
+	`, localPart).Scan(&tenantID)
+	if err == sql.ErrNoRows {
+		return "", apperr.NotFound("no tenant for inbound address %s", localPart)
+	}
+	if err != nil {
+		logger.Errorf("Failed to look up inbound address %s: %v", localPart, err)
+		return "", err
+	}
+	return tenantID, nil
+}
+
+// CreateInboundEmail records a received email and its stored attachments.
+// e.ID must be set, since attachments are stored under it before the email is recorded.
+func (s *Store) CreateInboundEmail(e *types.InboundEmail) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO inbound_emails (id, tenant_id, from_email, from_name, subject, client_id, skipped_attachments)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING received_at
+	`, e.ID, e.TenantID, e.FromEmail, e.FromName, e.Subject, e.ClientID, e.SkippedAttachments).Scan(&e.ReceivedAt)
+	if err != nil {
+		logger.Errorf("Failed to record inbound email for tenant %s: %v", e.TenantID, err)
+		return err
+	}
+
+	for _, a := range e.Attachments {
+		a.EmailID, a.TenantID, a.ClientID = e.ID, e.TenantID, e.ClientID
+		a.FromEmail, a.Subject, a.ReceivedAt = e.FromEmail, e.Subject, e.ReceivedAt
+		err = tx.QueryRow(`
+			INSERT INTO inbound_email_attachments (email_id, tenant_id, file_name, content_type, size_bytes, storage_path, client_id)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, status
+		`, a.EmailID, a.TenantID, a.FileName, a.ContentType, a.SizeBytes, a.StoragePath, a.ClientID).Scan(&a.ID, &a.Status)
+		if err != nil {
+			logger.Errorf("Failed to record inbound attachment %s: %v", a.FileName, err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logger.Infof("Recorded inbound email %s for tenant %s with %d attachments", e.ID, e.TenantID, len(e.Attachments))
+	return nil
+}
+
+// MarkInboundEmailAcknowledged records that the sender was sent an acknowledgment
+func (s *Store) MarkInboundEmailAcknowledged(emailID uuid.UUID) error {
+	if _, err := s.DB.Exec(`UPDATE inbound_emails SET acknowledged_at = NOW() WHERE id = $1`, emailID); err != nil {
+		logger.Errorf("Failed to mark inbound email %s acknowledged: %v", emailID, err)
+		return err
+	}
+	return nil
+}
+
+const inboundAttachmentQuery = `
+	SELECT a.id, a.email_id, a.tenant_id, a.file_name, a.content_type, a.size_bytes, a.storage_path,
+	       a.status, a.client_id, a.filing_id, a.document_id, a.reviewed_by, a.reviewed_at,
+	       e.from_email, e.subject, e.received_at
+	FROM inbound_email_attachments a
+	JOIN inbound_emails e ON e.id = a.email_id
+`
+
+// scanInboundAttachment reads a row selected with inboundAttachmentQuery
+func scanInboundAttachment(row interface{ Scan(...interface{}) error }) (*types.InboundAttachment, error) {
+	a := &types.InboundAttachment{}
+	err := row.Scan(&a.ID, &a.EmailID, &a.TenantID, &a.FileName, &a.ContentType, &a.SizeBytes, &a.StoragePath,
+		&a.Status, &a.ClientID, &a.FilingID, &a.DocumentID, &a.ReviewedBy, &a.ReviewedAt,
+		&a.FromEmail, &a.Subject, &a.ReceivedAt)
+	return a, err
+}
+
+// GetInboundAttachments lists a tenant's emailed attachments, newest first, optionally with one status
+func (s *Store) GetInboundAttachments(tenantID, status string, limit int) ([]*types.InboundAttachment, error) {
+	query := inboundAttachmentQuery + ` WHERE a.tenant_id = $1`
+	args := []interface{}{tenantID, limit}
+	if status != "" {
+		query += " AND a.status = $3"
+		args = append(args, status)
+	}
+	query += " ORDER BY e.received_at DESC, a.file_name LIMIT $2"
+
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		logger.Errorf("Failed to get inbound attachments for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	attachments := []*types.InboundAttachment{}
+	for rows.Next() {
+		a, err := scanInboundAttachment(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan inbound attachment: %v", err)
+			return nil, err
+		}
+		attachments = append(attachments, a)
+	}
+
+	return attachments, rows.Err()
+}
+
+// GetInboundAttachment returns one of a tenant's emailed attachments
+func (s *Store) GetInboundAttachment(tenantID string, attachmentID uuid.UUID) (*types.InboundAttachment, error) {
+	a, err := scanInboundAttachment(s.DB.QueryRow(inboundAttachmentQuery+` WHERE a.tenant_id = $1 AND a.id = $2`, tenantID, attachmentID))
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("inbound attachment not found: %s", attachmentID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get inbound attachment %s: %v", attachmentID, err)
+		return nil, err
+	}
+	return a, nil
+}
+
+// ReviewInboundAttachment closes a pending attachment as CONFIRMED (with its document) or REJECTED
+func (s *Store) ReviewInboundAttachment(a *types.InboundAttachment, employeeID uuid.UUID) error {
+	err := s.DB.QueryRow(`
+		UPDATE inbound_email_attachments
+		SET status = $3, client_id = $4, filing_id = $5, document_id = $6, reviewed_by = $7, reviewed_at = NOW()
+		WHERE tenant_id = $1 AND id = $2 AND status = 'PENDING'
+		RETURNING reviewed_by, reviewed_at
+	`, a.TenantID, a.ID, a.Status, a.ClientID, a.FilingID, a.DocumentID, employeeID).Scan(&a.ReviewedBy, &a.ReviewedAt)
+	if err == sql.ErrNoRows {
+		return apperr.Conflict("inbound attachment %s has already been reviewed", a.ID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to review inbound attachment %s: %v", a.ID, err)
+		return err
+	}
+
+	logger.Infof("Inbound attachment %s marked %s by %s", a.ID, a.Status, employeeID)
+	return nil
+}
@@ -0,0 +1,45 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/integrity"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// RunIntegrityChecks runs the tenant-wide duplicate SSN checks
+func (s *Store) RunIntegrityChecks(ctx context.Context, tenantID string) ([]*types.IntegrityIssue, error) {
+	if err := s.requireScope(types.ScopeSSNDecrypt); err != nil {
+		return nil, err
+	}
+
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	checkAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	records, err := checkAdapter.GetSSNRecords(ctx, db, tc.SchemaPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := integrity.CheckDuplicateSSNs(tenantID, records)
+	logger.Infof("Integrity checks for tenant %s found %d issues across %d SSN records", tenantID, len(issues), len(records))
+	return issues, nil
+}
@@ -0,0 +1,69 @@
+package store
+
+import (
+	"database/sql"
+	"time"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// ClaimJob takes or renews holder's lease on a job for ttl and reports whether holder now owns it.
+// A lease held by another process is only taken over once it has expired.
+func (s *Store) ClaimJob(jobName, holder string, ttl time.Duration) (bool, error) {
+	if err := s.requireScope(types.ScopeJobsWrite); err != nil {
+		return false, err
+	}
+
+	var claimedBy string
+	err := s.DB.QueryRow(`
+		INSERT INTO job_leases (job_name, holder, acquired_at, expires_at)
+		VALUES ($1, $2, NOW(), NOW() + make_interval(secs => $3))
+		ON CONFLICT (job_name) DO UPDATE
+		SET holder = EXCLUDED.holder,
+		    acquired_at = CASE WHEN job_leases.holder = EXCLUDED.holder THEN job_leases.acquired_at ELSE NOW() END,
+		    expires_at = EXCLUDED.expires_at
+		WHERE job_leases.holder = EXCLUDED.holder OR job_leases.expires_at < NOW()
+		RETURNING holder
+	`, jobName, holder, ttl.Seconds()).Scan(&claimedBy)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		logger.Errorf("Failed to claim job %s for %s: %v", jobName, holder, err)
+		return false, err
+	}
+	return true, nil
+}
+
+// ReleaseJobs drops every lease held by holder so other processes can take its jobs over immediately
+func (s *Store) ReleaseJobs(holder string) error {
+	if err := s.requireScope(types.ScopeJobsWrite); err != nil {
+		return err
+	}
+
+	result, err := s.DB.Exec(`DELETE FROM job_leases WHERE holder = $1`, holder)
+	if err != nil {
+		logger.Errorf("Failed to release job leases of %s: %v", holder, err)
+		return err
+	}
+
+	if released, _ := result.RowsAffected(); released > 0 {
+		logger.Infof("Released %d job leases held by %s", released, holder)
+	}
+	return nil
+}
+
+// GetLastJobRun returns when a job last started, or nil if it has no recorded runs
+func (s *Store) GetLastJobRun(jobName string) (*time.Time, error) {
+	var last sql.NullTime
+	err := s.DB.QueryRow(`SELECT MAX(started_at) FROM job_runs WHERE job_name = $1`, jobName).Scan(&last)
+	if err != nil {
+		logger.Errorf("Failed to get last %s run: %v", jobName, err)
+		return nil, err
+	}
+	if !last.Valid {
+		return nil, nil
+	}
+	return &last.Time, nil
+}
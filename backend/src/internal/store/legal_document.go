@@ -0,0 +1,182 @@
+package store
+
+import (
+	"database/sql"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// CreateLegalDocument publishes a new version of a tenant's legal document of a kind.
+// The previously active version is retired, so every portal user must accept the new one.
+func (s *Store) CreateLegalDocument(d *types.LegalDocument) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE legal_documents SET is_active = false
+		WHERE tenant_id = $1 AND kind = $2 AND is_active = true
+	`, d.TenantID, d.Kind)
+	if err != nil {
+		logger.Errorf("Failed to retire legal documents for %s/%s: %v", d.TenantID, d.Kind, err)
+		return err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO legal_documents (tenant_id, kind, version, title, body, created_by)
+		SELECT $1, $2, COALESCE(MAX(version), 0) + 1, $3, $4, $5
+		FROM legal_documents
+		WHERE tenant_id = $1 AND kind = $2
+		RETURNING id, version, is_active, created_at
+	`, d.TenantID, d.Kind, d.Title, d.Body, d.CreatedBy).Scan(&d.ID, &d.Version, &d.IsActive, &d.CreatedAt)
+	if err != nil {
+		logger.Errorf("Failed to create legal document: %v", err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logger.Infof("Published %s v%d for tenant %s", d.Kind, d.Version, d.TenantID)
+	return nil
+}
+
+// GetLegalDocuments lists a tenant's legal documents, optionally only the active versions
+func (s *Store) GetLegalDocuments(tenantID string, activeOnly bool) ([]*types.LegalDocument, error) {
+	query := `
+		SELECT id, tenant_id, kind, version, title, body, is_active, created_by, created_at
+		FROM legal_documents
+		WHERE tenant_id = $1
+	`
+	if activeOnly {
+		query += " AND is_active = true"
+	}
+	query += " ORDER BY kind, version DESC"
+
+	rows, err := s.DB.Query(query, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to query legal documents for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var documents []*types.LegalDocument
+	for rows.Next() {
+		d := &types.LegalDocument{}
+		if err := rows.Scan(&d.ID, &d.TenantID, &d.Kind, &d.Version, &d.Title, &d.Body, &d.IsActive, &d.CreatedBy, &d.CreatedAt); err != nil {
+			logger.Errorf("Failed to scan legal document: %v", err)
+			return nil, err
+		}
+		documents = append(documents, d)
+	}
+
+	return documents, rows.Err()
+}
+
+// GetLegalAcceptances returns a portal user's acceptances, newest first
+func (s *Store) GetLegalAcceptances(tenantID string, tenantUserID uuid.UUID) ([]*types.LegalAcceptance, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, tenant_id, tenant_user_id, document_id, kind, version, accepted_at, accepted_ip
+		FROM legal_acceptances
+		WHERE tenant_id = $1 AND tenant_user_id = $2
+		ORDER BY accepted_at DESC
+	`, tenantID, tenantUserID)
+	if err != nil {
+		logger.Errorf("Failed to query legal acceptances for tenant user %s: %v", tenantUserID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var acceptances []*types.LegalAcceptance
+	for rows.Next() {
+		a := &types.LegalAcceptance{}
+		if err := rows.Scan(&a.ID, &a.TenantID, &a.TenantUserID, &a.DocumentID, &a.Kind, &a.Version, &a.AcceptedAt, &a.AcceptedIP); err != nil {
+			logger.Errorf("Failed to scan legal acceptance: %v", err)
+			return nil, err
+		}
+		acceptances = append(acceptances, a)
+	}
+
+	return acceptances, rows.Err()
+}
+
+// GetPendingLegalDocuments returns the active legal documents a portal user has not accepted yet
+func (s *Store) GetPendingLegalDocuments(tenantID string, tenantUserID uuid.UUID) ([]*types.LegalDocument, error) {
+	rows, err := s.DB.Query(`
+		SELECT d.id, d.tenant_id, d.kind, d.version, d.title, d.body, d.is_active, d.created_by, d.created_at
+		FROM legal_documents d
+		WHERE d.tenant_id = $1 AND d.is_active = true
+		  AND NOT EXISTS (
+			SELECT 1 FROM legal_acceptances a
+			WHERE a.document_id = d.id AND a.tenant_user_id = $2
+		  )
+		ORDER BY d.kind
+	`, tenantID, tenantUserID)
+	if err != nil {
+		logger.Errorf("Failed to query pending legal documents for tenant user %s: %v", tenantUserID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var documents []*types.LegalDocument
+	for rows.Next() {
+		d := &types.LegalDocument{}
+		if err := rows.Scan(&d.ID, &d.TenantID, &d.Kind, &d.Version, &d.Title, &d.Body, &d.IsActive, &d.CreatedBy, &d.CreatedAt); err != nil {
+			logger.Errorf("Failed to scan pending legal document: %v", err)
+			return nil, err
+		}
+		documents = append(documents, d)
+	}
+
+	return documents, rows.Err()
+}
+
+// AcceptLegalDocument records a portal user's acceptance of an active legal document.
+// Accepting a version that was already accepted returns the original acceptance.
+func (s *Store) AcceptLegalDocument(tenantID string, tenantUserID, documentID uuid.UUID, ipAddress, userAgent *string) (*types.LegalAcceptance, error) {
+	acceptance := &types.LegalAcceptance{
+		TenantID:     tenantID,
+		TenantUserID: tenantUserID,
+		DocumentID:   documentID,
+	}
+
+	err := s.DB.QueryRow(`
+		SELECT kind, version FROM legal_documents
+		WHERE id = $1 AND tenant_id = $2 AND is_active = true
+	`, documentID, tenantID).Scan(&acceptance.Kind, &acceptance.Version)
+	if err == sql.ErrNoRows {
+		return nil, apperr.Conflict("legal document not found or no longer current: %s", documentID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to load legal document %s: %v", documentID, err)
+		return nil, err
+	}
+
+	_, err = s.DB.Exec(`
+		INSERT INTO legal_acceptances (tenant_id, tenant_user_id, document_id, kind, version, accepted_ip, accepted_user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_user_id, document_id) DO NOTHING
+	`, tenantID, tenantUserID, documentID, acceptance.Kind, acceptance.Version, ipAddress, userAgent)
+	if err != nil {
+		logger.Errorf("Failed to record legal acceptance for tenant user %s: %v", tenantUserID, err)
+		return nil, err
+	}
+
+	err = s.DB.QueryRow(`
+		SELECT id, accepted_at, accepted_ip FROM legal_acceptances
+		WHERE tenant_user_id = $1 AND document_id = $2
+	`, tenantUserID, documentID).Scan(&acceptance.ID, &acceptance.AcceptedAt, &acceptance.AcceptedIP)
+	if err != nil {
+		logger.Errorf("Failed to load legal acceptance for tenant user %s: %v", tenantUserID, err)
+		return nil, err
+	}
+
+	logger.Infof("Tenant user %s accepted %s v%d in tenant %s", tenantUserID, acceptance.Kind, acceptance.Version, tenantID)
+	return acceptance, nil
+}
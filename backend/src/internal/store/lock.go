@@ -0,0 +1,143 @@
+package store
+
+import (
+	"time"
+	"welltaxpro/src/internal/dlock"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// Locker returns a distributed locker that takes locks as holder and records their usage
+func (s *Store) Locker(holder string) *dlock.Locker {
+	return dlock.New(s.DB, holder, s)
+}
+
+// LockAcquired records that holder took a lock on the database session with backend pid
+func (s *Store) LockAcquired(name, holder string, pid int) error {
+	if err := s.requireScope(types.ScopeJobsWrite); err != nil {
+		return err
+	}
+
+	_, err := s.DB.Exec(`
+		INSERT INTO distributed_locks (name, holder, holder_pid, acquired_at, acquisitions)
+		VALUES ($1, $2, $3, NOW(), 1)
+		ON CONFLICT (name) DO UPDATE
+		SET holder = EXCLUDED.holder,
+		    holder_pid = EXCLUDED.holder_pid,
+		    acquired_at = EXCLUDED.acquired_at,
+		    acquisitions = distributed_locks.acquisitions + 1,
+		    stuck_alerted_at = NULL
+	`, name, holder, pid)
+	if err != nil {
+		logger.Errorf("Failed to record acquisition of lock %s: %v", name, err)
+		return err
+	}
+	return nil
+}
+
+// LockContended records an attempt that skipped its work because the lock was held elsewhere
+func (s *Store) LockContended(name string) error {
+	if err := s.requireScope(types.ScopeJobsWrite); err != nil {
+		return err
+	}
+
+	_, err := s.DB.Exec(`
+		INSERT INTO distributed_locks (name, contentions)
+		VALUES ($1, 1)
+		ON CONFLICT (name) DO UPDATE
+		SET contentions = distributed_locks.contentions + 1
+	`, name)
+	if err != nil {
+		logger.Errorf("Failed to record contention on lock %s: %v", name, err)
+		return err
+	}
+	return nil
+}
+
+// LockReleased clears a lock's holder and adds the hold to its statistics
+func (s *Store) LockReleased(name string, held time.Duration) error {
+	if err := s.requireScope(types.ScopeJobsWrite); err != nil {
+		return err
+	}
+
+	_, err := s.DB.Exec(`
+		UPDATE distributed_locks
+		SET holder = NULL,
+		    holder_pid = NULL,
+		    acquired_at = NULL,
+		    total_hold_ms = total_hold_ms + $2,
+		    max_hold_ms = GREATEST(max_hold_ms, $2),
+		    last_released_at = NOW(),
+		    stuck_alerted_at = NULL
+		WHERE name = $1
+	`, name, held.Milliseconds())
+	if err != nil {
+		logger.Errorf("Failed to record release of lock %s: %v", name, err)
+		return err
+	}
+	return nil
+}
+
+// GetDistributedLocks returns every known lock with its usage. A lock only counts as held while
+// its holder's database session still has the advisory lock granted, so holders that crashed
+// before recording a release show as free. Holds longer than stuckAfter are marked stuck.
+func (s *Store) GetDistributedLocks(stuckAfter time.Duration) ([]*types.DistributedLock, error) {
+	rows, err := s.DB.Query(`
+		SELECT d.name, d.acquisitions, d.contentions,
+		       CASE WHEN d.acquisitions > 0 THEN d.total_hold_ms / d.acquisitions ELSE 0 END,
+		       d.max_hold_ms, d.last_released_at, d.stuck_alerted_at,
+		       CASE WHEN held THEN d.holder END,
+		       CASE WHEN held THEN d.acquired_at END
+		FROM distributed_locks d
+		CROSS JOIN LATERAL (
+			SELECT EXISTS (
+				SELECT 1 FROM pg_locks p
+				WHERE p.locktype = 'advisory' AND p.granted AND p.objsubid = 1
+				  AND p.pid = d.holder_pid
+				  AND p.classid::bigint = (hashtextextended(d.name, 0) >> 32) & 4294967295
+				  AND p.objid::bigint = hashtextextended(d.name, 0) & 4294967295
+			) AS held
+		) h
+		ORDER BY d.name
+	`)
+	if err != nil {
+		logger.Errorf("Failed to get distributed locks: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	now := time.Now()
+	locks := []*types.DistributedLock{}
+	for rows.Next() {
+		l := &types.DistributedLock{}
+		if err := rows.Scan(&l.Name, &l.Acquisitions, &l.Contentions, &l.AvgHoldMs, &l.MaxHoldMs,
+			&l.LastReleasedAt, &l.StuckAlertedAt, &l.Holder, &l.AcquiredAt); err != nil {
+			logger.Errorf("Failed to scan distributed lock: %v", err)
+			return nil, err
+		}
+		if l.AcquiredAt != nil {
+			held := now.Sub(*l.AcquiredAt)
+			l.HeldSeconds = int64(held.Seconds())
+			l.Stuck = held > stuckAfter
+		} else {
+			l.StuckAlertedAt = nil
+		}
+		locks = append(locks, l)
+	}
+
+	return locks, rows.Err()
+}
+
+// MarkLockStuckAlerted records that admins were alerted about a lock's current hold
+func (s *Store) MarkLockStuckAlerted(name string) error {
+	if err := s.requireScope(types.ScopeJobsWrite); err != nil {
+		return err
+	}
+
+	if _, err := s.DB.Exec(`UPDATE distributed_locks SET stuck_alerted_at = NOW() WHERE name = $1`, name); err != nil {
+		logger.Errorf("Failed to mark lock %s as alerted: %v", name, err)
+		return err
+	}
+	return nil
+}
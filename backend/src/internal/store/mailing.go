@@ -0,0 +1,259 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// GetMailSettings returns the return address printed on a tenant's mailings
+func (s *Store) GetMailSettings(tenantID string) (*types.MailSettings, error) {
+	m := &types.MailSettings{TenantID: tenantID}
+	var address []byte
+	err := s.DB.QueryRow(`
+		SELECT return_name, return_address, updated_by, updated_at
+		FROM tenant_mail_settings WHERE tenant_id = $1
+	`, tenantID).Scan(&m.ReturnName, &address, &m.UpdatedBy, &m.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("tenant %s has no mailing return address", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get mail settings for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	if err := json.Unmarshal(address, &m.ReturnAddress); err != nil {
+		return nil, fmt.Errorf("failed to decode return address: %w", err)
+	}
+	return m, nil
+}
+
+// SetMailSettings creates or replaces a tenant's mailing return address
+func (s *Store) SetMailSettings(m *types.MailSettings) error {
+	address, err := json.Marshal(m.ReturnAddress)
+	if err != nil {
+		return fmt.Errorf("failed to encode return address: %w", err)
+	}
+
+	err = s.DB.QueryRow(`
+		INSERT INTO tenant_mail_settings (tenant_id, return_name, return_address, updated_by)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id) DO UPDATE
+		SET return_name = EXCLUDED.return_name, return_address = EXCLUDED.return_address,
+		    updated_by = EXCLUDED.updated_by, updated_at = NOW()
+		RETURNING updated_at
+	`, m.TenantID, m.ReturnName, string(address), m.UpdatedBy).Scan(&m.UpdatedAt)
+	if err != nil {
+		logger.Errorf("Failed to set mail settings for tenant %s: %v", m.TenantID, err)
+		return err
+	}
+	return nil
+}
+
+// CreateMailing records a mailing and its pieces as PENDING before they are sent to the provider
+func (s *Store) CreateMailing(m *types.Mailing) error {
+	address, err := json.Marshal(m.Address)
+	if err != nil {
+		return fmt.Errorf("failed to encode mailing address: %w", err)
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO mailings (tenant_id, client_id, recipient_name, address, description, color, requested_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, m.TenantID, m.ClientID, m.RecipientName, string(address), m.Description, m.Color, m.RequestedBy).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		logger.Errorf("Failed to create mailing for client %s: %v", m.ClientID, err)
+		return err
+	}
+
+	for _, p := range m.Pieces {
+		p.MailingID, p.TenantID = m.ID, m.TenantID
+		err = tx.QueryRow(`
+			INSERT INTO mail_pieces (mailing_id, tenant_id, document_id, document_name, provider)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id, status, updated_at
+		`, p.MailingID, p.TenantID, p.DocumentID, p.DocumentName, p.Provider).Scan(&p.ID, &p.Status, &p.UpdatedAt)
+		if err != nil {
+			logger.Errorf("Failed to create mail piece for document %s: %v", p.DocumentID, err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	logger.Infof("Created mailing %s for client %s with %d pieces", m.ID, m.ClientID, len(m.Pieces))
+	return nil
+}
+
+// RecordMailPieceSent stores the provider's acceptance of a pending piece, or the reason it failed
+func (s *Store) RecordMailPieceSent(p *types.MailPiece) error {
+	err := s.DB.QueryRow(`
+		UPDATE mail_pieces
+		SET status = $2, provider_id = $3, cost_cents = $4, expected_delivery = $5, error = $6, updated_at = NOW()
+		WHERE id = $1 AND status = 'PENDING'
+		RETURNING updated_at
+	`, p.ID, p.Status, p.ProviderID, p.CostCents, p.ExpectedDelivery, p.Error).Scan(&p.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return apperr.Conflict("mail piece %s has already been sent", p.ID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to record sending of mail piece %s: %v", p.ID, err)
+		return err
+	}
+	return nil
+}
+
+const mailPieceColumns = `
+	id, mailing_id, tenant_id, document_id, document_name, provider, provider_id,
+	status, cost_cents, expected_delivery, error, updated_at
+`
+
+// scanMailPiece reads a row selected with mailPieceColumns
+func scanMailPiece(row interface{ Scan(...interface{}) error }) (*types.MailPiece, error) {
+	p := &types.MailPiece{}
+	err := row.Scan(&p.ID, &p.MailingID, &p.TenantID, &p.DocumentID, &p.DocumentName, &p.Provider, &p.ProviderID,
+		&p.Status, &p.CostCents, &p.ExpectedDelivery, &p.Error, &p.UpdatedAt)
+	return p, err
+}
+
+// GetMailings returns a tenant's mailings with their pieces, newest first, optionally for one client
+func (s *Store) GetMailings(tenantID string, clientID *uuid.UUID, limit int) ([]*types.Mailing, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, tenant_id, client_id, recipient_name, address, description, color, requested_by, created_at
+		FROM mailings
+		WHERE tenant_id = $1 AND ($2::uuid IS NULL OR client_id = $2)
+		ORDER BY created_at DESC
+		LIMIT $3
+	`, tenantID, clientID, limit)
+	if err != nil {
+		logger.Errorf("Failed to get mailings for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	mailings := []*types.Mailing{}
+	byID := map[uuid.UUID]*types.Mailing{}
+	var ids []string
+	for rows.Next() {
+		m := &types.Mailing{Pieces: []*types.MailPiece{}}
+		var address []byte
+		if err := rows.Scan(&m.ID, &m.TenantID, &m.ClientID, &m.RecipientName, &address, &m.Description, &m.Color, &m.RequestedBy, &m.CreatedAt); err != nil {
+			logger.Errorf("Failed to scan mailing: %v", err)
+			return nil, err
+		}
+		if err := json.Unmarshal(address, &m.Address); err != nil {
+			return nil, fmt.Errorf("failed to decode mailing address: %w", err)
+		}
+		mailings = append(mailings, m)
+		byID[m.ID] = m
+		ids = append(ids, m.ID.String())
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return mailings, nil
+	}
+
+	pieceRows, err := s.DB.Query(`SELECT `+mailPieceColumns+` FROM mail_pieces WHERE mailing_id = ANY($1::uuid[]) ORDER BY document_name`, pq.Array(ids))
+	if err != nil {
+		logger.Errorf("Failed to get mail pieces for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer pieceRows.Close()
+
+	for pieceRows.Next() {
+		p, err := scanMailPiece(pieceRows)
+		if err != nil {
+			logger.Errorf("Failed to scan mail piece: %v", err)
+			return nil, err
+		}
+		m := byID[p.MailingID]
+		m.Pieces = append(m.Pieces, p)
+		m.CostCents += p.CostCents
+	}
+
+	return mailings, pieceRows.Err()
+}
+
+// GetMailPieceByProviderID finds the piece a provider webhook refers to
+func (s *Store) GetMailPieceByProviderID(provider, providerID string) (*types.MailPiece, error) {
+	p, err := scanMailPiece(s.DB.QueryRow(`SELECT `+mailPieceColumns+` FROM mail_pieces WHERE provider = $1 AND provider_id = $2`, provider, providerID))
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("no mail piece with %s ID %s", provider, providerID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get mail piece %s: %v", providerID, err)
+		return nil, err
+	}
+	return p, nil
+}
+
+// AdvanceMailPiece moves a piece from its current status to status. It returns false when the
+// piece's status changed since it was read.
+func (s *Store) AdvanceMailPiece(p *types.MailPiece, status string) (bool, error) {
+	result, err := s.DB.Exec(`
+		UPDATE mail_pieces SET status = $3, updated_at = NOW()
+		WHERE id = $1 AND status = $2
+	`, p.ID, p.Status, status)
+	if err != nil {
+		logger.Errorf("Failed to update mail piece %s to %s: %v", p.ID, status, err)
+		return false, err
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if n == 1 {
+		logger.Infof("Mail piece %s moved from %s to %s", p.ID, p.Status, status)
+		p.Status = status
+	}
+	return n == 1, nil
+}
+
+// GetMailCosts totals a tenant's mailing costs per month for pieces accepted by the provider
+// between from (inclusive) and to (exclusive)
+func (s *Store) GetMailCosts(tenantID string, from, to time.Time) ([]*types.MailCostSummary, error) {
+	rows, err := s.DB.Query(`
+		SELECT to_char(date_trunc('month', m.created_at), 'YYYY-MM'),
+		       COUNT(DISTINCT m.id), COUNT(p.id), COALESCE(SUM(p.cost_cents), 0)
+		FROM mailings m
+		JOIN mail_pieces p ON p.mailing_id = m.id
+		WHERE m.tenant_id = $1 AND m.created_at >= $2 AND m.created_at < $3
+		  AND p.provider_id IS NOT NULL
+		GROUP BY 1
+		ORDER BY 1
+	`, tenantID, from, to)
+	if err != nil {
+		logger.Errorf("Failed to get mail costs for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	summaries := []*types.MailCostSummary{}
+	for rows.Next() {
+		c := &types.MailCostSummary{}
+		if err := rows.Scan(&c.Month, &c.Mailings, &c.Pieces, &c.CostCents); err != nil {
+			logger.Errorf("Failed to scan mail cost summary: %v", err)
+			return nil, err
+		}
+		summaries = append(summaries, c)
+	}
+
+	return summaries, rows.Err()
+}
@@ -0,0 +1,165 @@
+package store
+
+import (
+	"database/sql"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// GetNotificationPreferences returns an employee's preference for every category
+// Categories without a stored row default to immediate email (see types.DefaultNotificationPreference)
+func (s *Store) GetNotificationPreferences(employeeID uuid.UUID) ([]*types.NotificationPreference, error) {
+	query := `
+		SELECT category, mode, email, slack
+		FROM notification_preferences
+		WHERE employee_id = $1
+	`
+
+	rows, err := s.DB.Query(query, employeeID)
+	if err != nil {
+		logger.Errorf("Failed to query notification preferences for employee %s: %v", employeeID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	stored := map[string]*types.NotificationPreference{}
+	for rows.Next() {
+		p := &types.NotificationPreference{}
+		if err := rows.Scan(&p.Category, &p.Mode, &p.Email, &p.Slack); err != nil {
+			logger.Errorf("Failed to scan notification preference: %v", err)
+			return nil, err
+		}
+		stored[p.Category] = p
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	prefs := make([]*types.NotificationPreference, 0, len(types.NotificationCategories))
+	for _, category := range types.NotificationCategories {
+		p, ok := stored[category]
+		if !ok {
+			p = types.DefaultNotificationPreference(category)
+		}
+		prefs = append(prefs, p)
+	}
+
+	return prefs, nil
+}
+
+// SetNotificationPreferences upserts an employee's preferences
+func (s *Store) SetNotificationPreferences(employeeID uuid.UUID, prefs []*types.NotificationPreference) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO notification_preferences (employee_id, category, mode, email, slack, updated_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+		ON CONFLICT (employee_id, category)
+		DO UPDATE SET mode = EXCLUDED.mode, email = EXCLUDED.email, slack = EXCLUDED.slack, updated_at = NOW()
+	`
+
+	for _, p := range prefs {
+		if _, err := tx.Exec(query, employeeID, p.Category, p.Mode, p.Email, p.Slack); err != nil {
+			logger.Errorf("Failed to save notification preference %s for employee %s: %v", p.Category, employeeID, err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetNotificationPreference returns an employee's preference for a single category
+func (s *Store) GetNotificationPreference(employeeID uuid.UUID, category string) (*types.NotificationPreference, error) {
+	query := `
+		SELECT mode, email, slack
+		FROM notification_preferences
+		WHERE employee_id = $1 AND category = $2
+	`
+
+	p := &types.NotificationPreference{Category: category}
+	err := s.DB.QueryRow(query, employeeID, category).Scan(&p.Mode, &p.Email, &p.Slack)
+	if err == sql.ErrNoRows {
+		return types.DefaultNotificationPreference(category), nil
+	}
+	if err != nil {
+		logger.Errorf("Failed to get notification preference for employee %s: %v", employeeID, err)
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// CreateNotificationEvent queues an event for an employee's next digest
+func (s *Store) CreateNotificationEvent(event *types.NotificationEvent) error {
+	query := `
+		INSERT INTO notification_events (employee_id, tenant_id, category, subject, body)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := s.DB.QueryRow(query, event.EmployeeID, event.TenantID, event.Category, event.Subject, event.Body).
+		Scan(&event.ID, &event.CreatedAt)
+	if err != nil {
+		logger.Errorf("Failed to queue notification event for employee %s: %v", event.EmployeeID, err)
+		return err
+	}
+
+	return nil
+}
+
+// GetPendingDigestEvents returns undigested events grouped by employee
+func (s *Store) GetPendingDigestEvents() (map[uuid.UUID][]*types.NotificationEvent, error) {
+	query := `
+		SELECT id, employee_id, tenant_id, category, subject, body, created_at
+		FROM notification_events
+		WHERE digested_at IS NULL
+		ORDER BY employee_id, created_at
+	`
+
+	rows, err := s.DB.Query(query)
+	if err != nil {
+		logger.Errorf("Failed to query pending digest events: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	events := map[uuid.UUID][]*types.NotificationEvent{}
+	for rows.Next() {
+		e := &types.NotificationEvent{}
+		if err := rows.Scan(&e.ID, &e.EmployeeID, &e.TenantID, &e.Category, &e.Subject, &e.Body, &e.CreatedAt); err != nil {
+			logger.Errorf("Failed to scan notification event: %v", err)
+			return nil, err
+		}
+		events[e.EmployeeID] = append(events[e.EmployeeID], e)
+	}
+
+	return events, rows.Err()
+}
+
+// MarkEventsDigested records that events were included in a digest email
+func (s *Store) MarkEventsDigested(eventIDs []uuid.UUID) error {
+	ids := make([]string, 0, len(eventIDs))
+	for _, id := range eventIDs {
+		ids = append(ids, id.String())
+	}
+
+	query := `
+		UPDATE notification_events
+		SET digested_at = NOW()
+		WHERE id = ANY($1::uuid[])
+	`
+
+	if _, err := s.DB.Exec(query, pq.Array(ids)); err != nil {
+		logger.Errorf("Failed to mark %d events digested: %v", len(ids), err)
+		return err
+	}
+
+	return nil
+}
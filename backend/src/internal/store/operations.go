@@ -0,0 +1,269 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// jobRunRetention is how long job history is kept
+const jobRunRetention = 7 * 24 * time.Hour
+
+// RecordJobRun stores the outcome of a background job run and prunes that job's expired history
+func (s *Store) RecordJobRun(jobName string, startedAt time.Time, items int, runErr error) error {
+	if err := s.requireScope(types.ScopeJobsWrite); err != nil {
+		return err
+	}
+
+	status := types.JobRunSucceeded
+	var errMsg *string
+	if runErr != nil {
+		status = types.JobRunFailed
+		msg := runErr.Error()
+		errMsg = &msg
+	}
+
+	_, err := s.DB.Exec(`
+		INSERT INTO job_runs (job_name, started_at, status, items, error)
+		VALUES ($1, $2, $3, $4, $5)
+	`, jobName, startedAt, status, items, errMsg)
+	if err != nil {
+		logger.Errorf("Failed to record %s job run: %v", jobName, err)
+		return err
+	}
+
+	if _, err := s.DB.Exec(`DELETE FROM job_runs WHERE job_name = $1 AND started_at < $2`, jobName, time.Now().Add(-jobRunRetention)); err != nil {
+		logger.Errorf("Failed to prune %s job history: %v", jobName, err)
+	}
+	return nil
+}
+
+// GetJobSummaries aggregates each job's runs since a time, with the latest run's outcome
+// and the process currently holding the job's lease
+func (s *Store) GetJobSummaries(since time.Time) ([]*types.JobSummary, error) {
+	rows, err := s.DB.Query(`
+		SELECT r.*, l.holder
+		FROM (
+			SELECT DISTINCT ON (job_name)
+			       job_name, started_at, status, error,
+			       COUNT(*) FILTER (WHERE started_at >= $1) OVER w,
+			       COUNT(*) FILTER (WHERE started_at >= $1 AND status = 'FAILED') OVER w,
+			       COALESCE(SUM(items) FILTER (WHERE started_at >= $1) OVER w, 0)
+			FROM job_runs
+			WINDOW w AS (PARTITION BY job_name)
+			ORDER BY job_name, started_at DESC
+		) r
+		LEFT JOIN job_leases l ON l.job_name = r.job_name AND l.expires_at > NOW()
+		ORDER BY r.job_name
+	`, since)
+	if err != nil {
+		logger.Errorf("Failed to get job summaries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var summaries []*types.JobSummary
+	for rows.Next() {
+		j := &types.JobSummary{}
+		var lastRunAt time.Time
+		if err := rows.Scan(&j.JobName, &lastRunAt, &j.LastStatus, &j.LastError, &j.Runs, &j.Failures, &j.ItemsProcessed, &j.LeaseHolder); err != nil {
+			logger.Errorf("Failed to scan job summary: %v", err)
+			return nil, err
+		}
+		j.LastRunAt = &lastRunAt
+		summaries = append(summaries, j)
+	}
+
+	return summaries, rows.Err()
+}
+
+// GetJobBacklog counts work still waiting for background jobs
+func (s *Store) GetJobBacklog() (*types.JobBacklog, error) {
+	backlog := &types.JobBacklog{}
+
+	err := s.DB.QueryRow(`
+		SELECT COUNT(*), MIN(created_at)
+		FROM notification_events
+		WHERE digested_at IS NULL
+	`).Scan(&backlog.PendingDigestEvents, &backlog.OldestPendingDigestAt)
+	if err != nil {
+		logger.Errorf("Failed to count pending digest events: %v", err)
+		return nil, err
+	}
+
+	err = s.DB.QueryRow(`SELECT COUNT(*) FROM request_nonces WHERE expires_at < NOW()`).Scan(&backlog.ExpiredNonces)
+	if err != nil {
+		logger.Errorf("Failed to count expired nonces: %v", err)
+		return nil, err
+	}
+
+	return backlog, nil
+}
+
+// RecordSignatureEnvelope stores the outcome of a signature request
+func (s *Store) RecordSignatureEnvelope(envelope *types.SignatureEnvelope) error {
+	err := s.DB.QueryRow(`
+		INSERT INTO signature_envelopes (tenant_id, filing_id, status, error, sent_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, envelope.TenantID, envelope.FilingID, envelope.Status, envelope.Error, envelope.SentBy).Scan(&envelope.ID, &envelope.CreatedAt)
+	if err != nil {
+		logger.Errorf("Failed to record signature envelope for tenant %s: %v", envelope.TenantID, err)
+		return err
+	}
+	return nil
+}
+
+// GetEnvelopeSummary counts signature requests since a time and returns the most recent failures
+func (s *Store) GetEnvelopeSummary(since time.Time, failureLimit int) (*types.OverviewEnvelopes, error) {
+	summary := &types.OverviewEnvelopes{Since: since, RecentFailures: []*types.SignatureEnvelope{}}
+
+	err := s.DB.QueryRow(`
+		SELECT COUNT(*) FILTER (WHERE status = 'SENT'), COUNT(*) FILTER (WHERE status = 'FAILED')
+		FROM signature_envelopes
+		WHERE created_at >= $1
+	`, since).Scan(&summary.Sent, &summary.Failed)
+	if err != nil {
+		logger.Errorf("Failed to count signature envelopes: %v", err)
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT id, tenant_id, filing_id, status, error, sent_by, created_at
+		FROM signature_envelopes
+		WHERE status = 'FAILED' AND created_at >= $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, since, failureLimit)
+	if err != nil {
+		logger.Errorf("Failed to get failed signature envelopes: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		e := &types.SignatureEnvelope{}
+		if err := rows.Scan(&e.ID, &e.TenantID, &e.FilingID, &e.Status, &e.Error, &e.SentBy, &e.CreatedAt); err != nil {
+			logger.Errorf("Failed to scan signature envelope: %v", err)
+			return nil, err
+		}
+		summary.RecentFailures = append(summary.RecentFailures, e)
+	}
+
+	return summary, rows.Err()
+}
+
+// GetTenantCounts counts configured tenants by active state
+func (s *Store) GetTenantCounts() (*types.OverviewTenants, error) {
+	counts := &types.OverviewTenants{}
+	err := s.DB.QueryRow(`
+		SELECT COUNT(*), COUNT(*) FILTER (WHERE is_active)
+		FROM tenant_connections
+	`).Scan(&counts.Total, &counts.Active)
+	if err != nil {
+		logger.Errorf("Failed to count tenants: %v", err)
+		return nil, err
+	}
+	counts.Inactive = counts.Total - counts.Active
+	return counts, nil
+}
+
+// GetActiveTenantIDs lists the IDs of active tenants
+func (s *Store) GetActiveTenantIDs() ([]string, error) {
+	rows, err := s.DB.Query(`SELECT tenant_id FROM tenant_connections WHERE is_active = true ORDER BY tenant_id`)
+	if err != nil {
+		logger.Errorf("Failed to list active tenants: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// CheckTenantConnection pings a tenant database and records the result in the health cache
+func (s *Store) CheckTenantConnection(ctx context.Context, tenantID string) *types.TenantConnectionHealth {
+	start := time.Now()
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err == nil && db != nil { // the smoke tenant has no database to ping
+		ctx, cancel := tenantQueryContext(ctx, tc)
+		defer cancel()
+		err = db.PingContext(ctx)
+	}
+	return s.recordConnectionHealth(tenantID, start, err)
+}
+
+// GetConnectionHealth returns the cached health of a tenant connection, if it has been checked
+func (s *Store) GetConnectionHealth(tenantID string) (*types.TenantConnectionHealth, bool) {
+	s.healthMutex.RLock()
+	defer s.healthMutex.RUnlock()
+	h, ok := s.connHealth[tenantID]
+	if !ok {
+		return nil, false
+	}
+	copied := *h
+	return &copied, true
+}
+
+// recordConnectionHealth caches the outcome of a tenant connection attempt that started at start
+func (s *Store) recordConnectionHealth(tenantID string, start time.Time, err error) *types.TenantConnectionHealth {
+	h := &types.TenantConnectionHealth{
+		TenantID:  tenantID,
+		Healthy:   err == nil,
+		LatencyMs: time.Since(start).Milliseconds(),
+		CheckedAt: time.Now(),
+	}
+	if err != nil {
+		h.Error = err.Error()
+	}
+
+	s.healthMutex.Lock()
+	s.connHealth[tenantID] = h
+	s.healthMutex.Unlock()
+
+	copied := *h
+	return &copied
+}
+
+// CountFilings counts a tenant's filings for a tax year using the appropriate adapter
+func (s *Store) CountFilings(ctx context.Context, tenantID string, year int) (total int, completed int, err error) {
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	filingAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return 0, 0, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	return filingAdapter.CountFilings(ctx, db, tc.SchemaPrefix, year)
+}
+
+// CountOpenFilings counts a tenant's filings of every tax year that are not completed
+func (s *Store) CountOpenFilings(ctx context.Context, tenantID string) (int, error) {
+	db, tc, filingAdapter, err := s.tenantAdapter(ctx, tenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	return filingAdapter.CountOpenFilings(ctx, db, tc.SchemaPrefix)
+}
@@ -0,0 +1,399 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+const portalSessionColumns = `id, tenant_id, firebase_uid, auth_time, created_at, last_seen_at,
+	verified_at, verification_method, failed_attempts, ended_at, end_reason`
+
+// portalSessionTouchInterval limits how often last_seen_at is written for a busy session
+const portalSessionTouchInterval = time.Minute
+
+func scanPortalSession(row interface{ Scan(...interface{}) error }) (*types.PortalSession, error) {
+	session := &types.PortalSession{}
+	err := row.Scan(&session.ID, &session.TenantID, &session.FirebaseUID, &session.AuthTime, &session.CreatedAt,
+		&session.LastSeenAt, &session.VerifiedAt, &session.VerificationMethod, &session.FailedAttempts,
+		&session.EndedAt, &session.EndReason)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetPortalSecurityPolicy returns the effective portal security policy for a tenant
+func (s *Store) GetPortalSecurityPolicy(tenantID string) (*types.PortalSecurityPolicy, error) {
+	var data sql.NullString
+	err := s.DB.QueryRow(`
+		SELECT portal_security_policy::text FROM tenant_connections
+		WHERE tenant_id = $1 AND is_active = true
+	`, tenantID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("tenant not found: %s", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get portal security policy of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	if !data.Valid {
+		return types.DefaultPortalSecurityPolicy(), nil
+	}
+	policy := &types.PortalSecurityPolicy{}
+	if err := json.Unmarshal([]byte(data.String), policy); err != nil {
+		logger.Errorf("Invalid portal security policy for tenant %s, using defaults: %v", tenantID, err)
+		return types.DefaultPortalSecurityPolicy(), nil
+	}
+	return policy, nil
+}
+
+// UpdatePortalSecurityPolicy replaces the portal security policy for a tenant and records the
+// change in the tenant's configuration history. Existing sessions are held to the new policy
+// from their next request.
+func (s *Store) UpdatePortalSecurityPolicy(tenantID string, policy *types.PortalSecurityPolicy, employeeID uuid.UUID) error {
+	data, err := json.Marshal(policy)
+	if err != nil {
+		return fmt.Errorf("failed to encode portal security policy: %w", err)
+	}
+
+	query := `
+		UPDATE tenant_connections
+		SET portal_security_policy = $1, updated_at = NOW()
+		WHERE tenant_id = $2
+	`
+
+	err = s.ChangeTenantConfig(tenantID, types.TenantConfigActionUpdate, &employeeID, func(tx *sql.Tx) error {
+		_, err := tx.Exec(query, string(data), tenantID)
+		return err
+	})
+	if err != nil {
+		logger.Errorf("Failed to update portal security policy for tenant %s: %v", tenantID, err)
+		return err
+	}
+
+	logger.Infof("Updated portal security policy for tenant %s", tenantID)
+	return nil
+}
+
+// TouchPortalSession returns the session of the portal sign-in of firebaseUID at authTime,
+// recording it on first use and ending it once it is older than policy allows. A new sign-in is
+// verified when the user verified another within the policy's reuse window, and ends the user's
+// oldest sessions beyond the concurrent session limit. The returned session's EndedAt is set
+// when the sign-in may no longer be used.
+func (s *Store) TouchPortalSession(tenantID, firebaseUID string, authTime int64, policy *types.PortalSecurityPolicy) (*types.PortalSession, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	session, err := scanPortalSession(tx.QueryRow(`
+		SELECT `+portalSessionColumns+` FROM portal_sessions
+		WHERE tenant_id = $1 AND firebase_uid = $2 AND auth_time = $3
+		FOR UPDATE
+	`, tenantID, firebaseUID, authTime))
+	if err == sql.ErrNoRows {
+		session, err = startPortalSession(tx, tenantID, firebaseUID, authTime, policy)
+	}
+	if err != nil {
+		logger.Errorf("Failed to load portal session of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	session.ExpiresAt = policy.SessionExpiry(authTime)
+	now := time.Now()
+	switch {
+	case session.EndedAt != nil:
+	case session.ExpiresAt != nil && now.After(*session.ExpiresAt):
+		reason := types.PortalSessionEndExpired
+		_, err = tx.Exec(`UPDATE portal_sessions SET ended_at = NOW(), end_reason = $1 WHERE id = $2`, reason, session.ID)
+		session.EndedAt, session.EndReason = &now, &reason
+	case now.Sub(session.LastSeenAt) >= portalSessionTouchInterval:
+		_, err = tx.Exec(`UPDATE portal_sessions SET last_seen_at = NOW() WHERE id = $1`, session.ID)
+		session.LastSeenAt = now
+	}
+	if err != nil {
+		logger.Errorf("Failed to update portal session %s: %v", session.ID, err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// startPortalSession records a new portal sign-in and applies the policy's verification reuse
+// window and concurrent session limit
+func startPortalSession(tx *sql.Tx, tenantID, firebaseUID string, authTime int64, policy *types.PortalSecurityPolicy) (*types.PortalSession, error) {
+	var verifiedAt *time.Time
+	var verificationMethod *string
+	if policy.RequiresVerification() && policy.VerificationReuseMinutes > 0 {
+		err := tx.QueryRow(`
+			SELECT verified_at, verification_method FROM portal_sessions
+			WHERE tenant_id = $1 AND firebase_uid = $2
+			  AND verified_at > NOW() - make_interval(mins => $3)
+			ORDER BY verified_at DESC
+			LIMIT 1
+		`, tenantID, firebaseUID, policy.VerificationReuseMinutes).Scan(&verifiedAt, &verificationMethod)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, err
+		}
+	}
+
+	// A concurrent first request may have recorded the sign-in already
+	_, err := tx.Exec(`
+		INSERT INTO portal_sessions (tenant_id, firebase_uid, auth_time, verified_at, verification_method)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (tenant_id, firebase_uid, auth_time) DO NOTHING
+	`, tenantID, firebaseUID, authTime, verifiedAt, verificationMethod)
+	if err != nil {
+		return nil, err
+	}
+
+	if policy.MaxConcurrentSessions > 0 {
+		_, err := tx.Exec(`
+			UPDATE portal_sessions SET ended_at = NOW(), end_reason = $1
+			WHERE tenant_id = $2 AND firebase_uid = $3 AND ended_at IS NULL
+			  AND id NOT IN (
+				SELECT id FROM portal_sessions
+				WHERE tenant_id = $2 AND firebase_uid = $3 AND ended_at IS NULL
+				ORDER BY auth_time DESC
+				LIMIT $4
+			  )
+		`, types.PortalSessionEndLimit, tenantID, firebaseUID, policy.MaxConcurrentSessions)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	session, err := scanPortalSession(tx.QueryRow(`
+		SELECT `+portalSessionColumns+` FROM portal_sessions
+		WHERE tenant_id = $1 AND firebase_uid = $2 AND auth_time = $3
+		FOR UPDATE
+	`, tenantID, firebaseUID, authTime))
+	if err != nil {
+		return nil, err
+	}
+	logger.Infof("Portal session %s started in tenant %s", session.ID, tenantID)
+	return session, nil
+}
+
+// SetPortalSessionOTP stores the hash of a one-time code emailed to verify a portal session,
+// replacing any code sent before
+func (s *Store) SetPortalSessionOTP(sessionID uuid.UUID, codeHash string, expiresAt time.Time) error {
+	result, err := s.DB.Exec(`
+		UPDATE portal_sessions SET otp_hash = $1, otp_expires_at = $2
+		WHERE id = $3 AND ended_at IS NULL
+	`, codeHash, expiresAt, sessionID)
+	if err != nil {
+		logger.Errorf("Failed to store verification code of portal session %s: %v", sessionID, err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return apperr.NotFound("portal session not found or ended: %s", sessionID)
+	}
+	return nil
+}
+
+// GetPortalSessionOTP returns the hash of the unexpired one-time code of a portal session, or ""
+// when none was sent or it has expired
+func (s *Store) GetPortalSessionOTP(sessionID uuid.UUID) (string, error) {
+	var codeHash sql.NullString
+	err := s.DB.QueryRow(`
+		SELECT otp_hash FROM portal_sessions
+		WHERE id = $1 AND otp_expires_at > NOW()
+	`, sessionID).Scan(&codeHash)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	if err != nil {
+		logger.Errorf("Failed to get verification code of portal session %s: %v", sessionID, err)
+		return "", err
+	}
+	return codeHash.String, nil
+}
+
+// RecordPortalVerification records an answer to a portal session's verification. A correct
+// answer verifies the session with method; after PortalVerificationMaxAttempts wrong answers the
+// session ends. The session is returned as updated.
+func (s *Store) RecordPortalVerification(sessionID uuid.UUID, method string, verified bool) (*types.PortalSession, error) {
+	var row *sql.Row
+	if verified {
+		row = s.DB.QueryRow(`
+			UPDATE portal_sessions
+			SET verified_at = NOW(), verification_method = $1, otp_hash = NULL, otp_expires_at = NULL
+			WHERE id = $2 AND ended_at IS NULL
+			RETURNING `+portalSessionColumns, method, sessionID)
+	} else {
+		row = s.DB.QueryRow(`
+			UPDATE portal_sessions
+			SET failed_attempts = failed_attempts + 1,
+			    ended_at = CASE WHEN failed_attempts + 1 >= $1 THEN NOW() END,
+			    end_reason = CASE WHEN failed_attempts + 1 >= $1 THEN $2 END
+			WHERE id = $3 AND ended_at IS NULL
+			RETURNING `+portalSessionColumns, types.PortalVerificationMaxAttempts, types.PortalSessionEndVerificationFailed, sessionID)
+	}
+
+	session, err := scanPortalSession(row)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("portal session not found or ended: %s", sessionID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to record verification of portal session %s: %v", sessionID, err)
+		return nil, err
+	}
+	return session, nil
+}
+
+// GetPortalVerificationLock returns when a tenant user was locked out of verification, or nil
+// when they are not locked out
+func (s *Store) GetPortalVerificationLock(tenantUserID uuid.UUID) (*time.Time, error) {
+	var lockedAt *time.Time
+	err := s.DB.QueryRow(`
+		SELECT verification_locked_at FROM tenant_users WHERE id = $1
+	`, tenantUserID).Scan(&lockedAt)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("tenant user not found: %s", tenantUserID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get verification lock of tenant user %s: %v", tenantUserID, err)
+		return nil, err
+	}
+	return lockedAt, nil
+}
+
+// RecordPortalVerificationFailure counts a wrong verification answer against the tenant user
+// and records it. The answer that brings the count to PortalVerificationLockAttempts locks the
+// user out, which failure.Locked reports.
+func (s *Store) RecordPortalVerificationFailure(failure *types.PortalVerificationFailure) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		logger.Errorf("Failed to record verification failure of tenant user %s: %v", failure.TenantUserID, err)
+		return err
+	}
+	defer tx.Rollback()
+
+	var failures int
+	var lockedAt *time.Time
+	err = tx.QueryRow(`
+		SELECT verification_failures, verification_locked_at FROM tenant_users WHERE id = $1 FOR UPDATE
+	`, failure.TenantUserID).Scan(&failures, &lockedAt)
+	if err == sql.ErrNoRows {
+		return apperr.NotFound("tenant user not found: %s", failure.TenantUserID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to record verification failure of tenant user %s: %v", failure.TenantUserID, err)
+		return err
+	}
+
+	failure.Failures = failures + 1
+	failure.Locked = lockedAt == nil && failure.Failures >= types.PortalVerificationLockAttempts
+	_, err = tx.Exec(`
+		UPDATE tenant_users
+		SET verification_failures = $2,
+		    verification_locked_at = CASE WHEN $3 THEN NOW() ELSE verification_locked_at END
+		WHERE id = $1
+	`, failure.TenantUserID, failure.Failures, failure.Locked)
+	if err != nil {
+		logger.Errorf("Failed to record verification failure of tenant user %s: %v", failure.TenantUserID, err)
+		return err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO portal_verification_failures (tenant_id, tenant_user_id, client_id, session_id, method, failures, locked, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, failed_at
+	`, failure.TenantID, failure.TenantUserID, failure.ClientID, failure.SessionID, failure.Method, failure.Failures,
+		failure.Locked, failure.IPAddress, failure.UserAgent).Scan(&failure.ID, &failure.FailedAt)
+	if err != nil {
+		logger.Errorf("Failed to record verification failure of tenant user %s: %v", failure.TenantUserID, err)
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Errorf("Failed to record verification failure of tenant user %s: %v", failure.TenantUserID, err)
+		return err
+	}
+	return nil
+}
+
+// ResetPortalVerificationFailures clears a tenant user's count of wrong answers after a correct one
+func (s *Store) ResetPortalVerificationFailures(tenantUserID uuid.UUID) error {
+	_, err := s.DB.Exec(`
+		UPDATE tenant_users SET verification_failures = 0
+		WHERE id = $1 AND verification_failures > 0
+	`, tenantUserID)
+	if err != nil {
+		logger.Errorf("Failed to reset verification failures of tenant user %s: %v", tenantUserID, err)
+		return err
+	}
+	return nil
+}
+
+// UnlockPortalVerification lifts the verification lockout of a client's portal user, as sending
+// them a new portal link does. It reports whether the user was locked out.
+func (s *Store) UnlockPortalVerification(tenantID string, clientID uuid.UUID) (bool, error) {
+	result, err := s.DB.Exec(`
+		UPDATE tenant_users SET verification_failures = 0, verification_locked_at = NULL, updated_at = NOW()
+		WHERE tenant_id = $1 AND client_id = $2 AND verification_locked_at IS NOT NULL
+	`, tenantID, clientID)
+	if err != nil {
+		logger.Errorf("Failed to unlock portal verification of client %s: %v", clientID, err)
+		return false, err
+	}
+	n, _ := result.RowsAffected()
+	if n > 0 {
+		logger.Infof("Unlocked portal verification of client %s in tenant %s", clientID, tenantID)
+	}
+	return n > 0, nil
+}
+
+// GetPortalVerificationFailures returns a client's wrong portal verification answers, newest first
+func (s *Store) GetPortalVerificationFailures(tenantID string, clientID uuid.UUID, limit int) ([]*types.PortalVerificationFailure, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, tenant_id, tenant_user_id, client_id, session_id, method, failures, locked, ip_address, user_agent, failed_at
+		FROM portal_verification_failures
+		WHERE tenant_id = $1 AND client_id = $2
+		ORDER BY failed_at DESC
+		LIMIT $3
+	`, tenantID, clientID, limit)
+	if err != nil {
+		logger.Errorf("Failed to get portal verification failures for client %s: %v", clientID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	failures := []*types.PortalVerificationFailure{}
+	for rows.Next() {
+		f := &types.PortalVerificationFailure{}
+		if err := rows.Scan(&f.ID, &f.TenantID, &f.TenantUserID, &f.ClientID, &f.SessionID, &f.Method, &f.Failures,
+			&f.Locked, &f.IPAddress, &f.UserAgent, &f.FailedAt); err != nil {
+			logger.Errorf("Failed to scan portal verification failure: %v", err)
+			return nil, err
+		}
+		failures = append(failures, f)
+	}
+
+	return failures, rows.Err()
+}
+
+// PurgeOldPortalSessions deletes portal sessions unused for longer than the retention period
+func (s *Store) PurgeOldPortalSessions() (int64, error) {
+	result, err := s.DB.Exec(`
+		DELETE FROM portal_sessions WHERE last_seen_at < NOW() - make_interval(secs => $1)
+	`, types.PortalSessionRetention.Seconds())
+	if err != nil {
+		logger.Errorf("Failed to purge portal sessions: %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
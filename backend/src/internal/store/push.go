@@ -0,0 +1,135 @@
+package store
+
+import (
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// RegisterTenantUserDevice stores a push token for a portal user.
+// A token already registered to another user is moved to this one (device changed hands).
+func (s *Store) RegisterTenantUserDevice(device *types.TenantUserDevice) error {
+	query := `
+		INSERT INTO tenant_user_devices (tenant_user_id, token, platform)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (token)
+		DO UPDATE SET tenant_user_id = EXCLUDED.tenant_user_id, platform = EXCLUDED.platform, updated_at = NOW()
+		RETURNING id, created_at, updated_at
+	`
+
+	err := s.DB.QueryRow(query, device.TenantUserID, device.Token, device.Platform).
+		Scan(&device.ID, &device.CreatedAt, &device.UpdatedAt)
+	if err != nil {
+		logger.Errorf("Failed to register device for tenant user %s: %v", device.TenantUserID, err)
+		return err
+	}
+
+	return nil
+}
+
+// DeleteTenantUserDevice removes one of a portal user's devices
+func (s *Store) DeleteTenantUserDevice(tenantUserID, deviceID uuid.UUID) error {
+	result, err := s.DB.Exec(`DELETE FROM tenant_user_devices WHERE id = $1 AND tenant_user_id = $2`, deviceID, tenantUserID)
+	if err != nil {
+		logger.Errorf("Failed to delete device %s: %v", deviceID, err)
+		return err
+	}
+
+	rows, _ := result.RowsAffected()
+	if rows == 0 {
+		return apperr.NotFound("device not found: %s", deviceID)
+	}
+
+	return nil
+}
+
+// GetTenantUserDevices lists a portal user's registered devices
+func (s *Store) GetTenantUserDevices(tenantUserID uuid.UUID) ([]*types.TenantUserDevice, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, tenant_user_id, token, platform, created_at, updated_at
+		FROM tenant_user_devices
+		WHERE tenant_user_id = $1
+		ORDER BY updated_at DESC
+	`, tenantUserID)
+	if err != nil {
+		logger.Errorf("Failed to query devices for tenant user %s: %v", tenantUserID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var devices []*types.TenantUserDevice
+	for rows.Next() {
+		d := &types.TenantUserDevice{}
+		if err := rows.Scan(&d.ID, &d.TenantUserID, &d.Token, &d.Platform, &d.CreatedAt, &d.UpdatedAt); err != nil {
+			logger.Errorf("Failed to scan device: %v", err)
+			return nil, err
+		}
+		devices = append(devices, d)
+	}
+
+	return devices, rows.Err()
+}
+
+// GetPushTargets returns the device tokens of every push-enabled portal user linked to a client
+func (s *Store) GetPushTargets(tenantID string, clientID uuid.UUID) ([]string, error) {
+	rows, err := s.DB.Query(`
+		SELECT d.token
+		FROM tenant_user_devices d
+		JOIN tenant_users tu ON tu.id = d.tenant_user_id
+		WHERE tu.tenant_id = $1 AND tu.client_id = $2 AND tu.is_active = true AND tu.push_enabled = true
+	`, tenantID, clientID)
+	if err != nil {
+		logger.Errorf("Failed to query push targets for client %s: %v", clientID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []string
+	for rows.Next() {
+		var token string
+		if err := rows.Scan(&token); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, token)
+	}
+
+	return tokens, rows.Err()
+}
+
+// DeleteDeviceTokens removes tokens FCM reported as unregistered or invalid
+func (s *Store) DeleteDeviceTokens(tokens []string) error {
+	if len(tokens) == 0 {
+		return nil
+	}
+
+	if _, err := s.DB.Exec(`DELETE FROM tenant_user_devices WHERE token = ANY($1)`, pq.Array(tokens)); err != nil {
+		logger.Errorf("Failed to delete %d stale device tokens: %v", len(tokens), err)
+		return err
+	}
+
+	logger.Infof("Removed %d stale device tokens", len(tokens))
+	return nil
+}
+
+// GetTenantUserPushEnabled reports whether a portal user receives push notifications
+func (s *Store) GetTenantUserPushEnabled(tenantUserID uuid.UUID) (bool, error) {
+	var enabled bool
+	if err := s.DB.QueryRow(`SELECT push_enabled FROM tenant_users WHERE id = $1`, tenantUserID).Scan(&enabled); err != nil {
+		logger.Errorf("Failed to get push preference for tenant user %s: %v", tenantUserID, err)
+		return false, err
+	}
+	return enabled, nil
+}
+
+// SetTenantUserPushEnabled opts a portal user in or out of push notifications
+func (s *Store) SetTenantUserPushEnabled(tenantUserID uuid.UUID, enabled bool) error {
+	_, err := s.DB.Exec(`UPDATE tenant_users SET push_enabled = $2, updated_at = NOW() WHERE id = $1`, tenantUserID, enabled)
+	if err != nil {
+		logger.Errorf("Failed to update push preference for tenant user %s: %v", tenantUserID, err)
+		return err
+	}
+	return nil
+}
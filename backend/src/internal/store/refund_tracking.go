@@ -0,0 +1,87 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// GetRefundTrackings retrieves refund tracking for a filing using the appropriate adapter
+func (s *Store) GetRefundTrackings(ctx context.Context, tenantID string, filingID string) ([]*types.RefundTracking, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	refundAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	return refundAdapter.GetRefundTrackings(ctx, db, tc.SchemaPrefix, filingID)
+}
+
+// UpsertRefundTracking records a jurisdiction's refund status using the appropriate adapter
+func (s *Store) UpsertRefundTracking(ctx context.Context, tenantID string, tracking *types.RefundTracking) (*types.RefundTracking, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	refundAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	if err := s.checkFilingWritable(ctx, tenantID, db, tc, refundAdapter, tracking.FilingID.String()); err != nil {
+		return nil, err
+	}
+
+	return refundAdapter.UpsertRefundTracking(ctx, db, tc.SchemaPrefix, tracking)
+}
+
+// DeleteRefundTracking removes a jurisdiction's refund record using the appropriate adapter
+func (s *Store) DeleteRefundTracking(ctx context.Context, tenantID string, filingID string, jurisdiction string) error {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	refundAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	if err := s.checkFilingWritable(ctx, tenantID, db, tc, refundAdapter, filingID); err != nil {
+		return err
+	}
+
+	return refundAdapter.DeleteRefundTracking(ctx, db, tc.SchemaPrefix, filingID, jurisdiction)
+}
@@ -0,0 +1,177 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/signing"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// CreateSigningKey generates a new HMAC key for a tenant; the returned key carries the plaintext secret
+func (s *Store) CreateSigningKey(tenantID, label string, createdBy uuid.UUID) (*types.SigningKey, error) {
+	keyID, secret, err := signing.NewKey()
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := crypto.EncryptPassword(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt signing secret: %w", err)
+	}
+
+	key := &types.SigningKey{
+		TenantID:  tenantID,
+		KeyID:     keyID,
+		Secret:    secret,
+		Label:     label,
+		CreatedBy: &createdBy,
+	}
+	err = s.DB.QueryRow(`
+		INSERT INTO request_signing_keys (tenant_id, key_id, secret, label, created_by)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, tenantID, keyID, encrypted, label, createdBy).Scan(&key.ID, &key.CreatedAt)
+	if err != nil {
+		logger.Errorf("Failed to create signing key for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	logger.Infof("Created signing key %s for tenant %s", keyID, tenantID)
+	return key, nil
+}
+
+// GetSigningKeys lists a tenant's signing keys (secrets omitted), newest first
+func (s *Store) GetSigningKeys(tenantID string) ([]*types.SigningKey, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, tenant_id, key_id, label, created_by, created_at, last_used_at, revoked_at
+		FROM request_signing_keys
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+	`, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get signing keys for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []*types.SigningKey
+	for rows.Next() {
+		k := &types.SigningKey{}
+		if err := rows.Scan(&k.ID, &k.TenantID, &k.KeyID, &k.Label, &k.CreatedBy, &k.CreatedAt, &k.LastUsedAt, &k.RevokedAt); err != nil {
+			logger.Errorf("Failed to scan signing key: %v", err)
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+// RevokeSigningKey stops a tenant's key from verifying requests
+func (s *Store) RevokeSigningKey(tenantID, keyID string) error {
+	result, err := s.DB.Exec(`
+		UPDATE request_signing_keys
+		SET revoked_at = NOW()
+		WHERE tenant_id = $1 AND key_id = $2 AND revoked_at IS NULL
+	`, tenantID, keyID)
+	if err != nil {
+		logger.Errorf("Failed to revoke signing key %s: %v", keyID, err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return apperr.NotFound("signing key not found: %s", keyID)
+	}
+
+	logger.Infof("Revoked signing key %s for tenant %s", keyID, tenantID)
+	return nil
+}
+
+// HasActiveSigningKeys reports whether a tenant requires signed public requests
+func (s *Store) HasActiveSigningKeys(tenantID string) (bool, error) {
+	var exists bool
+	err := s.DB.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM request_signing_keys WHERE tenant_id = $1 AND revoked_at IS NULL)
+	`, tenantID).Scan(&exists)
+	if err != nil {
+		logger.Errorf("Failed to check signing keys for tenant %s: %v", tenantID, err)
+		return false, err
+	}
+	return exists, nil
+}
+
+// GetSigningSecret returns the decrypted secret of an active key belonging to the tenant
+func (s *Store) GetSigningSecret(tenantID, keyID string) (string, error) {
+	if err := s.requireScope(types.ScopeSecretDecrypt); err != nil {
+		return "", err
+	}
+
+	var encrypted string
+	err := s.DB.QueryRow(`
+		SELECT secret FROM request_signing_keys
+		WHERE tenant_id = $1 AND key_id = $2 AND revoked_at IS NULL
+	`, tenantID, keyID).Scan(&encrypted)
+	if err == sql.ErrNoRows {
+		return "", apperr.NotFound("signing key not found: %s", keyID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get signing key %s: %v", keyID, err)
+		return "", err
+	}
+
+	return crypto.DecryptPassword(encrypted)
+}
+
+// UseSigningNonce records a nonce for a key; it returns false when the nonce was already used
+func (s *Store) UseSigningNonce(keyID, nonce string, expiresAt time.Time) (bool, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO request_nonces (key_id, nonce, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (key_id, nonce) DO NOTHING
+	`, keyID, nonce, expiresAt)
+	if err != nil {
+		logger.Errorf("Failed to record nonce for signing key %s: %v", keyID, err)
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	if rows == 0 {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(`UPDATE request_signing_keys SET last_used_at = NOW() WHERE key_id = $1`, keyID); err != nil {
+		logger.Errorf("Failed to update last use of signing key %s: %v", keyID, err)
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// PurgeExpiredSigningNonces deletes nonces that are outside every replay window
+func (s *Store) PurgeExpiredSigningNonces() (int64, error) {
+	result, err := s.DB.Exec(`DELETE FROM request_nonces WHERE expires_at < NOW()`)
+	if err != nil {
+		logger.Errorf("Failed to purge expired nonces: %v", err)
+		return 0, err
+	}
+	return result.RowsAffected()
+}
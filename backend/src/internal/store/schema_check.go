@@ -0,0 +1,197 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/integrity"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// RunSchemaCheck compares a tenant schema against its adapter's expectations and saves the result.
+// Connection and introspection failures are saved on the check rather than returned.
+func (s *Store) RunSchemaCheck(ctx context.Context, tenantID string) (*types.SchemaCheck, error) {
+	tc, err := s.GetTenantConfig(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	check := &types.SchemaCheck{
+		TenantID:     tenantID,
+		AdapterType:  tc.AdapterType,
+		SchemaPrefix: tc.SchemaPrefix,
+		Issues:       []*types.SchemaIssue{},
+		CheckedAt:    time.Now(),
+	}
+
+	// An unknown adapter type silently falls back to the MyWellTax adapter, so flag it and still
+	// compare the schema against the adapter the tenant is actually served with
+	if !adapter.IsRegistered(tc.AdapterType) {
+		check.Issues = append(check.Issues, &types.SchemaIssue{
+			Type:   types.SchemaUnknownAdapter,
+			Actual: tc.AdapterType,
+			Fix:    fmt.Sprintf("Set the tenant's adapter type to one of: %s", strings.Join(adapter.AdapterTypes, ", ")),
+		})
+	}
+
+	if issues, err := s.CompareTenantSchema(ctx, tenantID); err != nil {
+		msg := err.Error()
+		check.Error = &msg
+	} else {
+		check.Issues = append(check.Issues, issues...)
+	}
+	check.Healthy = check.Error == nil && len(check.Issues) == 0
+
+	if err := s.saveSchemaCheck(check); err != nil {
+		return nil, err
+	}
+
+	logger.Infof("Schema check for tenant %s found %d issues", tenantID, len(check.Issues))
+	return check, nil
+}
+
+// RunSchemaChecks runs the schema check of every active tenant, failing tenants first. Tenants
+// whose check cannot be saved are logged and left out.
+func (s *Store) RunSchemaChecks(ctx context.Context) ([]*types.SchemaCheck, error) {
+	tenantIDs, err := s.GetActiveTenantIDs()
+	if err != nil {
+		return nil, err
+	}
+
+	checks := []*types.SchemaCheck{}
+	for _, tenantID := range tenantIDs {
+		check, err := s.RunSchemaCheck(ctx, tenantID)
+		if err != nil {
+			logger.Errorf("Schema check failed for tenant %s: %v", tenantID, err)
+			continue
+		}
+		checks = append(checks, check)
+	}
+
+	sort.SliceStable(checks, func(i, j int) bool {
+		return !checks[i].Healthy && checks[j].Healthy
+	})
+	return checks, nil
+}
+
+// CompareTenantSchema introspects a tenant schema and diffs it against the appropriate adapter
+func (s *Store) CompareTenantSchema(ctx context.Context, tenantID string) ([]*types.SchemaIssue, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	schemaAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	actual, err := schemaAdapter.GetSchemaColumns(ctx, db, tc.SchemaPrefix)
+	if err != nil {
+		return nil, err
+	}
+
+	return integrity.CompareSchema(tc.SchemaPrefix, schemaAdapter.ExpectedSchema(), actual), nil
+}
+
+// saveSchemaCheck replaces the stored result for the check's tenant
+func (s *Store) saveSchemaCheck(check *types.SchemaCheck) error {
+	issues, err := json.Marshal(check.Issues)
+	if err != nil {
+		return fmt.Errorf("failed to encode schema issues: %w", err)
+	}
+
+	_, err = s.DB.Exec(`
+		INSERT INTO tenant_schema_checks (tenant_id, adapter_type, schema_prefix, healthy, issues, error, checked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			adapter_type = EXCLUDED.adapter_type,
+			schema_prefix = EXCLUDED.schema_prefix,
+			healthy = EXCLUDED.healthy,
+			issues = EXCLUDED.issues,
+			error = EXCLUDED.error,
+			checked_at = EXCLUDED.checked_at
+	`, check.TenantID, check.AdapterType, check.SchemaPrefix, check.Healthy, string(issues), check.Error, check.CheckedAt)
+	if err != nil {
+		logger.Errorf("Failed to save schema check for tenant %s: %v", check.TenantID, err)
+		return err
+	}
+	return nil
+}
+
+// GetSchemaCheck returns the latest saved schema check for a tenant
+func (s *Store) GetSchemaCheck(tenantID string) (*types.SchemaCheck, error) {
+	row := s.DB.QueryRow(`
+		SELECT tenant_id, adapter_type, schema_prefix, healthy, issues, error, checked_at
+		FROM tenant_schema_checks
+		WHERE tenant_id = $1
+	`, tenantID)
+
+	check, err := scanSchemaCheck(row)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("schema check not found for tenant: %s", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get schema check for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	return check, nil
+}
+
+// GetSchemaChecks returns the latest saved schema check of every tenant, failing tenants first
+func (s *Store) GetSchemaChecks() ([]*types.SchemaCheck, error) {
+	rows, err := s.DB.Query(`
+		SELECT tenant_id, adapter_type, schema_prefix, healthy, issues, error, checked_at
+		FROM tenant_schema_checks
+		ORDER BY healthy, tenant_id
+	`)
+	if err != nil {
+		logger.Errorf("Failed to get schema checks: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var checks []*types.SchemaCheck
+	for rows.Next() {
+		check, err := scanSchemaCheck(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan schema check: %v", err)
+			return nil, err
+		}
+		checks = append(checks, check)
+	}
+
+	return checks, rows.Err()
+}
+
+// scanSchemaCheck scans a tenant_schema_checks row
+func scanSchemaCheck(row interface{ Scan(...interface{}) error }) (*types.SchemaCheck, error) {
+	check := &types.SchemaCheck{}
+	var issues []byte
+	if err := row.Scan(&check.TenantID, &check.AdapterType, &check.SchemaPrefix, &check.Healthy, &issues, &check.Error, &check.CheckedAt); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(issues, &check.Issues); err != nil {
+		return nil, fmt.Errorf("failed to decode schema issues: %w", err)
+	}
+	if check.Issues == nil {
+		check.Issues = []*types.SchemaIssue{}
+	}
+	return check, nil
+}
@@ -0,0 +1,223 @@
+package store
+
+import (
+	"database/sql"
+	"fmt"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// signatureStatusColumns are the columns recording when a request first reached each status
+var signatureStatusColumns = map[string]string{
+	types.SignatureStatusDelivered: "delivered_at",
+	types.SignatureStatusCompleted: "completed_at",
+	types.SignatureStatusDeclined:  "declined_at",
+	types.SignatureStatusVoided:    "voided_at",
+}
+
+const signatureRequestColumns = `
+	id, tenant_id, envelope_id, filing_id, taxpayer_name, taxpayer_email, spouse_signature,
+	status, sent_by, sent_at, delivered_at, completed_at, declined_at, voided_at,
+	document_id, archived_at, archive_error, updated_at
+`
+
+// scanSignatureRequest scans a row selected with signatureRequestColumns
+func scanSignatureRequest(row interface{ Scan(...interface{}) error }) (*types.SignatureRequest, error) {
+	req := &types.SignatureRequest{}
+	err := row.Scan(&req.ID, &req.TenantID, &req.EnvelopeID, &req.FilingID, &req.TaxpayerName, &req.TaxpayerEmail,
+		&req.SpouseSignature, &req.Status, &req.SentBy, &req.SentAt, &req.DeliveredAt, &req.CompletedAt,
+		&req.DeclinedAt, &req.VoidedAt, &req.DocumentID, &req.ArchivedAt, &req.ArchiveError, &req.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// CreateSignatureRequest records an envelope sent to DocuSign as SENT
+func (s *Store) CreateSignatureRequest(req *types.SignatureRequest) error {
+	req.Status = types.SignatureStatusSent
+	err := s.DB.QueryRow(`
+		INSERT INTO signature_requests (tenant_id, envelope_id, filing_id, taxpayer_name, taxpayer_email, spouse_signature, status, sent_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, sent_at, updated_at
+	`, req.TenantID, req.EnvelopeID, req.FilingID, req.TaxpayerName, req.TaxpayerEmail, req.SpouseSignature,
+		req.Status, req.SentBy).Scan(&req.ID, &req.SentAt, &req.UpdatedAt)
+	if err != nil {
+		logger.Errorf("Failed to record signature request for envelope %s: %v", req.EnvelopeID, err)
+		return err
+	}
+	return nil
+}
+
+// GetSignatureRequestByEnvelope returns the request a tenant's DocuSign envelope was sent for
+func (s *Store) GetSignatureRequestByEnvelope(tenantID, envelopeID string) (*types.SignatureRequest, error) {
+	req, err := scanSignatureRequest(s.DB.QueryRow(`
+		SELECT `+signatureRequestColumns+`
+		FROM signature_requests
+		WHERE tenant_id = $1 AND envelope_id = $2
+	`, tenantID, envelopeID))
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("signature request not found for envelope %s", envelopeID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get signature request for envelope %s: %v", envelopeID, err)
+		return nil, err
+	}
+	return req, nil
+}
+
+// GetSignatureRequest returns one of a tenant's signature requests
+func (s *Store) GetSignatureRequest(tenantID string, id uuid.UUID) (*types.SignatureRequest, error) {
+	req, err := scanSignatureRequest(s.DB.QueryRow(`
+		SELECT `+signatureRequestColumns+`
+		FROM signature_requests
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, id))
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("signature request not found: %s", id)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get signature request %s: %v", id, err)
+		return nil, err
+	}
+	return req, nil
+}
+
+// AdvanceSignatureRequest moves a request from its current status to status, stamping the
+// time it got there. It returns false when another event changed the request first.
+func (s *Store) AdvanceSignatureRequest(req *types.SignatureRequest, status string) (bool, error) {
+	column, ok := signatureStatusColumns[status]
+	if !ok {
+		return false, fmt.Errorf("signature requests cannot move to %s", status)
+	}
+
+	updated, err := scanSignatureRequest(s.DB.QueryRow(`
+		UPDATE signature_requests
+		SET status = $3, `+column+` = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = $2
+		RETURNING `+signatureRequestColumns,
+		req.ID, req.Status, status))
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		logger.Errorf("Failed to move signature request %s to %s: %v", req.ID, status, err)
+		return false, err
+	}
+	*req = *updated
+
+	logger.Infof("Signature request %s for envelope %s moved to %s", req.ID, req.EnvelopeID, status)
+	return true, nil
+}
+
+// RecordSignatureArchive records the outcome of archiving a request's signed PDF: the document
+// it was stored as, or why it failed. A failure never replaces an archived document.
+func (s *Store) RecordSignatureArchive(req *types.SignatureRequest, documentID *uuid.UUID, archiveErr error) error {
+	var msg *string
+	if archiveErr != nil {
+		text := archiveErr.Error()
+		msg = &text
+	}
+
+	updated, err := scanSignatureRequest(s.DB.QueryRow(`
+		UPDATE signature_requests
+		SET document_id = COALESCE($2, document_id),
+		    archived_at = CASE WHEN $2::uuid IS NULL THEN archived_at ELSE NOW() END,
+		    archive_error = CASE WHEN document_id IS NULL OR $2::uuid IS NOT NULL THEN $3 ELSE archive_error END,
+		    updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+signatureRequestColumns,
+		req.ID, documentID, msg))
+	if err != nil {
+		logger.Errorf("Failed to record archive of signature request %s: %v", req.ID, err)
+		return err
+	}
+	*req = *updated
+	return nil
+}
+
+// GetSignatureRequests lists a tenant's signature requests in any of statuses (all when empty),
+// newest first
+func (s *Store) GetSignatureRequests(tenantID string, statuses []string, limit int) ([]*types.SignatureRequest, error) {
+	rows, err := s.DB.Query(`
+		SELECT `+signatureRequestColumns+`
+		FROM signature_requests
+		WHERE tenant_id = $1 AND (cardinality($2::text[]) = 0 OR status = ANY($2::text[]))
+		ORDER BY sent_at DESC
+		LIMIT $3
+	`, tenantID, pq.Array(statuses), limit)
+	if err != nil {
+		logger.Errorf("Failed to get signature requests for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	requests := []*types.SignatureRequest{}
+	for rows.Next() {
+		req, err := scanSignatureRequest(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan signature request: %v", err)
+			return nil, err
+		}
+		requests = append(requests, req)
+	}
+
+	return requests, rows.Err()
+}
+
+// GetDocuSignConnectSecret returns a tenant's decrypted DocuSign Connect HMAC key, or "" when none is set
+func (s *Store) GetDocuSignConnectSecret(tenantID string) (string, error) {
+	if err := s.requireScope(types.ScopeSecretDecrypt); err != nil {
+		return "", err
+	}
+
+	var encrypted sql.NullString
+	err := s.DB.QueryRow(`
+		SELECT docusign_connect_secret FROM tenant_connections WHERE tenant_id = $1 AND is_active = true
+	`, tenantID).Scan(&encrypted)
+	if err == sql.ErrNoRows {
+		return "", apperr.NotFound("tenant not found: %s", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get DocuSign Connect secret for tenant %s: %v", tenantID, err)
+		return "", err
+	}
+	if !encrypted.Valid || encrypted.String == "" {
+		return "", nil
+	}
+	return crypto.DecryptPassword(encrypted.String)
+}
+
+// UpdateDocuSignConnectSecret stores a tenant's DocuSign Connect HMAC key encrypted, or clears it
+// when secret is empty, and records the change in the tenant's configuration history
+func (s *Store) UpdateDocuSignConnectSecret(tenantID, secret string, employeeID uuid.UUID) error {
+	var encrypted *string
+	if secret != "" {
+		value, err := crypto.EncryptPassword(secret)
+		if err != nil {
+			return fmt.Errorf("failed to encrypt DocuSign Connect secret: %w", err)
+		}
+		encrypted = &value
+	}
+
+	err := s.ChangeTenantConfig(tenantID, types.TenantConfigActionUpdate, &employeeID, func(tx *sql.Tx) error {
+		_, err := tx.Exec(`
+			UPDATE tenant_connections
+			SET docusign_connect_secret = $1, updated_at = NOW()
+			WHERE tenant_id = $2
+		`, encrypted, tenantID)
+		return err
+	})
+	if err != nil {
+		logger.Errorf("Failed to update DocuSign Connect secret for tenant %s: %v", tenantID, err)
+		return err
+	}
+
+	logger.Infof("Updated DocuSign Connect secret for tenant %s", tenantID)
+	return nil
+}
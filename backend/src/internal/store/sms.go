@@ -0,0 +1,186 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+const smsMessageColumns = `id, tenant_id, client_id, kind, to_phone, provider, provider_id, status, error, created_at, updated_at`
+
+// scanSMSMessage reads smsMessageColumns into a text message
+func scanSMSMessage(row interface{ Scan(...interface{}) error }) (*types.SMSMessage, error) {
+	m := &types.SMSMessage{}
+	err := row.Scan(&m.ID, &m.TenantID, &m.ClientID, &m.Kind, &m.ToPhone, &m.Provider, &m.ProviderID,
+		&m.Status, &m.Error, &m.CreatedAt, &m.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// GetSMSSettings returns the effective SMS settings for a tenant
+func (s *Store) GetSMSSettings(tenantID string) (*types.SMSSettings, error) {
+	var data sql.NullString
+	err := s.DB.QueryRow(`
+		SELECT sms_settings::text FROM tenant_connections
+		WHERE tenant_id = $1 AND is_active = true
+	`, tenantID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("tenant not found: %s", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get SMS settings of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	if !data.Valid {
+		return types.DefaultSMSSettings(), nil
+	}
+	settings := &types.SMSSettings{}
+	if err := json.Unmarshal([]byte(data.String), settings); err != nil {
+		logger.Errorf("Invalid SMS settings for tenant %s, using defaults: %v", tenantID, err)
+		return types.DefaultSMSSettings(), nil
+	}
+	return settings, nil
+}
+
+// UpdateSMSSettings replaces the SMS settings for a tenant and records the change in the
+// tenant's configuration history
+func (s *Store) UpdateSMSSettings(tenantID string, settings *types.SMSSettings, employeeID uuid.UUID) error {
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return fmt.Errorf("failed to encode SMS settings: %w", err)
+	}
+
+	query := `
+		UPDATE tenant_connections
+		SET sms_settings = $1, updated_at = NOW()
+		WHERE tenant_id = $2
+	`
+
+	err = s.ChangeTenantConfig(tenantID, types.TenantConfigActionUpdate, &employeeID, func(tx *sql.Tx) error {
+		_, err := tx.Exec(query, string(data), tenantID)
+		return err
+	})
+	if err != nil {
+		logger.Errorf("Failed to update SMS settings for tenant %s: %v", tenantID, err)
+		return err
+	}
+
+	logger.Infof("Updated SMS settings for tenant %s", tenantID)
+	return nil
+}
+
+// StartSMS records a text message as PENDING before it is handed to the provider. A client who
+// was already sent maxPerDay messages in the last 24 hours is not texted again: the message is
+// not recorded and a conflict is returned.
+func (s *Store) StartSMS(m *types.SMSMessage, maxPerDay int) (*types.SMSMessage, error) {
+	if err := s.requireScope(types.ScopeSMSSend); err != nil {
+		return nil, err
+	}
+
+	started, err := scanSMSMessage(s.DB.QueryRow(`
+		INSERT INTO sms_messages (tenant_id, client_id, kind, to_phone, provider)
+		SELECT $1, $2, $3, $4, $5
+		WHERE (
+			SELECT COUNT(*) FROM sms_messages
+			WHERE tenant_id = $1 AND client_id = $2 AND created_at > NOW() - INTERVAL '24 hours'
+		) < $6
+		RETURNING `+smsMessageColumns,
+		m.TenantID, m.ClientID, m.Kind, m.ToPhone, m.Provider, maxPerDay))
+	if err == sql.ErrNoRows {
+		return nil, apperr.Conflict("client %s was already sent %d text messages in the last 24 hours", m.ClientID, maxPerDay)
+	}
+	if err != nil {
+		logger.Errorf("Failed to record text message to client %s: %v", m.ClientID, err)
+		return nil, err
+	}
+	return started, nil
+}
+
+// FinishSMS records the provider's answer to a started message: its ID and status, or the
+// error that kept it from being sent
+func (s *Store) FinishSMS(messageID uuid.UUID, providerID, status string, sendErr error) error {
+	if err := s.requireScope(types.ScopeSMSSend); err != nil {
+		return err
+	}
+
+	var err error
+	if sendErr == nil {
+		_, err = s.DB.Exec(`
+			UPDATE sms_messages SET provider_id = $2, status = $3, updated_at = NOW()
+			WHERE id = $1
+		`, messageID, providerID, status)
+	} else {
+		_, err = s.DB.Exec(`
+			UPDATE sms_messages SET status = 'FAILED', error = $2, updated_at = NOW()
+			WHERE id = $1
+		`, messageID, sendErr.Error())
+	}
+	if err != nil {
+		logger.Errorf("Failed to record outcome of text message %s: %v", messageID, err)
+		return err
+	}
+	return nil
+}
+
+// ApplySMSEvent moves a message to the status a provider callback reports. Callbacks can arrive
+// out of order, so DELIVERED, UNDELIVERED and FAILED messages keep their status.
+func (s *Store) ApplySMSEvent(provider, providerID, status, errorCode string) error {
+	var code *string
+	if errorCode != "" {
+		code = &errorCode
+	}
+
+	var messageID uuid.UUID
+	err := s.DB.QueryRow(`
+		UPDATE sms_messages
+		SET status = CASE WHEN status IN ('DELIVERED', 'UNDELIVERED', 'FAILED') THEN status ELSE $3 END,
+		    error = COALESCE($4, error), updated_at = NOW()
+		WHERE provider = $1 AND provider_id = $2
+		RETURNING id
+	`, provider, providerID, status, code).Scan(&messageID)
+	if err == sql.ErrNoRows {
+		return apperr.NotFound("text message not found: %s", providerID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to apply status of text message %s: %v", providerID, err)
+		return err
+	}
+	return nil
+}
+
+// GetSMSMessages returns a tenant's latest text messages matching filter, newest first
+func (s *Store) GetSMSMessages(tenantID string, filter *types.SMSMessageFilter) ([]*types.SMSMessage, error) {
+	rows, err := s.DB.Query(`
+		SELECT `+smsMessageColumns+`
+		FROM sms_messages
+		WHERE tenant_id = $1
+		  AND ($2::uuid IS NULL OR client_id = $2)
+		  AND ($3::text IS NULL OR status = $3)
+		ORDER BY created_at DESC, id
+		LIMIT $4
+	`, tenantID, filter.ClientID, filter.Status, filter.Limit)
+	if err != nil {
+		logger.Errorf("Failed to get text messages of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	messages := []*types.SMSMessage{}
+	for rows.Next() {
+		m, err := scanSMSMessage(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan text message: %v", err)
+			return nil, err
+		}
+		messages = append(messages, m)
+	}
+	return messages, rows.Err()
+}
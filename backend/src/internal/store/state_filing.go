@@ -0,0 +1,176 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// GetStateFilings retrieves the state returns for a filing using the appropriate adapter
+func (s *Store) GetStateFilings(ctx context.Context, tenantID string, filingID string) ([]*types.StateFiling, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	stateAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	return stateAdapter.GetStateFilings(ctx, db, tc.SchemaPrefix, filingID)
+}
+
+// GetStateFilingByID retrieves a specific state return using the appropriate adapter
+func (s *Store) GetStateFilingByID(ctx context.Context, tenantID string, stateFilingID string) (*types.StateFiling, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	stateAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	return stateAdapter.GetStateFilingByID(ctx, db, tc.SchemaPrefix, stateFilingID)
+}
+
+// CreateStateFiling adds a state return to a filing using the appropriate adapter
+func (s *Store) CreateStateFiling(ctx context.Context, tenantID string, stateFiling *types.StateFiling) (*types.StateFiling, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	stateAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	if err := s.checkFilingWritable(ctx, tenantID, db, tc, stateAdapter, stateFiling.FilingID.String()); err != nil {
+		return nil, err
+	}
+
+	return stateAdapter.CreateStateFiling(ctx, db, tc.SchemaPrefix, stateFiling)
+}
+
+// UpdateStateFiling updates a state return using the appropriate adapter
+func (s *Store) UpdateStateFiling(ctx context.Context, tenantID string, stateFilingID string, stateFiling *types.StateFiling) (*types.StateFiling, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	stateAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	if err := s.checkStateFilingWritable(ctx, tenantID, db, tc, stateAdapter, stateFilingID); err != nil {
+		return nil, err
+	}
+
+	return stateAdapter.UpdateStateFiling(ctx, db, tc.SchemaPrefix, stateFilingID, stateFiling)
+}
+
+// DeleteStateFiling removes a state return using the appropriate adapter
+func (s *Store) DeleteStateFiling(ctx context.Context, tenantID string, stateFilingID string) error {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	stateAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	if err := s.checkStateFilingWritable(ctx, tenantID, db, tc, stateAdapter, stateFilingID); err != nil {
+		return err
+	}
+
+	return stateAdapter.DeleteStateFiling(ctx, db, tc.SchemaPrefix, stateFilingID)
+}
+
+// GetStateFilingReport returns state return volume for a tenant using the appropriate adapter
+func (s *Store) GetStateFilingReport(ctx context.Context, tenantID string, year *int) ([]*types.StateFilingReport, error) {
+	// Get tenant database connection and config
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+
+	// Get the appropriate adapter for this tenant
+	stateAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", tenantID, err)
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	logger.Infof("Using %s adapter for tenant %s", tc.AdapterType, tenantID)
+
+	return stateAdapter.GetStateFilingReport(ctx, db, tc.SchemaPrefix, year)
+}
+
+// checkStateFilingWritable returns a conflict error when a state return belongs to a filing of an
+// archived tax year
+func (s *Store) checkStateFilingWritable(ctx context.Context, tenantID string, db *sql.DB, tc *types.TenantConnection, stateAdapter adapter.ClientAdapter, stateFilingID string) error {
+	years, err := s.archivedYearSet(tenantID)
+	if err != nil || len(years) == 0 {
+		return err
+	}
+
+	stateFiling, err := stateAdapter.GetStateFilingByID(ctx, db, tc.SchemaPrefix, stateFilingID)
+	if err != nil {
+		return err
+	}
+	return s.checkFilingWritable(ctx, tenantID, db, tc, stateAdapter, stateFiling.FilingID.String())
+}
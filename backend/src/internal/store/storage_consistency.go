@@ -0,0 +1,250 @@
+package store
+
+import (
+	"database/sql"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const storageIssueColumns = `id, tenant_id, type, path, document_id, client_id, filing_id, COALESCE(document_name, ''),
+	COALESCE(document_type, ''), candidates, status, first_seen_at, last_seen_at, resolution, resolved_by, resolved_at, document_request_id`
+
+// SaveStorageConsistencyCheck replaces a tenant's latest storage check and records the issues it
+// found. Issues still open keep their first sighting, and open issues the check no longer found
+// are CLEARED. A check that could not run leaves the tenant's issues as they were.
+func (s *Store) SaveStorageConsistencyCheck(check *types.StorageConsistencyCheck, issues []*types.StorageIssue) error {
+	if err := s.requireScope(types.ScopeStorageChecks); err != nil {
+		return err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO tenant_storage_checks (tenant_id, documents, objects, missing_files, orphaned_files, error, checked_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (tenant_id) DO UPDATE SET
+			documents = EXCLUDED.documents,
+			objects = EXCLUDED.objects,
+			missing_files = EXCLUDED.missing_files,
+			orphaned_files = EXCLUDED.orphaned_files,
+			error = EXCLUDED.error,
+			checked_at = EXCLUDED.checked_at
+	`, check.TenantID, check.Documents, check.Objects, check.MissingFiles, check.OrphanedFiles, check.Error, check.CheckedAt)
+	if err != nil {
+		logger.Errorf("Failed to save storage check for tenant %s: %v", check.TenantID, err)
+		return err
+	}
+
+	if check.Error == nil {
+		seen := make([]string, 0, len(issues))
+		for _, issue := range issues {
+			id, err := upsertStorageIssue(tx, check, issue)
+			if err != nil {
+				logger.Errorf("Failed to save storage issue %s of tenant %s: %v", issue.Path, check.TenantID, err)
+				return err
+			}
+			seen = append(seen, id.String())
+		}
+
+		_, err = tx.Exec(`
+			UPDATE storage_issues SET status = 'CLEARED'
+			WHERE tenant_id = $1 AND status = 'OPEN' AND NOT (id = ANY($2::uuid[]))
+		`, check.TenantID, pq.Array(seen))
+		if err != nil {
+			logger.Errorf("Failed to clear storage issues of tenant %s: %v", check.TenantID, err)
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// upsertStorageIssue records an issue found by check, or marks the matching open issue seen again
+func upsertStorageIssue(tx *sql.Tx, check *types.StorageConsistencyCheck, issue *types.StorageIssue) (uuid.UUID, error) {
+	// Open missing files are unique per document and open orphaned files per path
+	conflict := `(tenant_id, path) WHERE type = 'ORPHANED_FILE' AND status = 'OPEN'`
+	if issue.Type == types.StorageIssueMissingFile {
+		conflict = `(tenant_id, document_id) WHERE type = 'MISSING_FILE' AND status = 'OPEN'`
+	}
+
+	candidates := issue.Candidates
+	if candidates == nil {
+		candidates = []string{}
+	}
+
+	var id uuid.UUID
+	err := tx.QueryRow(`
+		INSERT INTO storage_issues (tenant_id, type, path, document_id, client_id, filing_id, document_name, document_type,
+			candidates, first_seen_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''), $9, $10, $10)
+		ON CONFLICT `+conflict+` DO UPDATE SET
+			path = EXCLUDED.path,
+			client_id = EXCLUDED.client_id,
+			filing_id = EXCLUDED.filing_id,
+			document_name = EXCLUDED.document_name,
+			document_type = EXCLUDED.document_type,
+			candidates = EXCLUDED.candidates,
+			last_seen_at = EXCLUDED.last_seen_at
+		RETURNING id
+	`, check.TenantID, issue.Type, issue.Path, issue.DocumentID, issue.ClientID, issue.FilingID, issue.DocumentName,
+		issue.DocumentType, pq.Array(candidates), check.CheckedAt).Scan(&id)
+	return id, err
+}
+
+// GetStorageConsistencyReport returns a tenant's latest storage check with its open issues,
+// missing files first
+func (s *Store) GetStorageConsistencyReport(tenantID string) (*types.StorageConsistencyReport, error) {
+	row := s.DB.QueryRow(`
+		SELECT tenant_id, documents, objects, missing_files, orphaned_files, error, checked_at
+		FROM tenant_storage_checks
+		WHERE tenant_id = $1
+	`, tenantID)
+
+	check, err := scanStorageConsistencyCheck(row)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("storage check not found for tenant: %s", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get storage check for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT `+storageIssueColumns+`
+		FROM storage_issues
+		WHERE tenant_id = $1 AND status = 'OPEN'
+		ORDER BY type, first_seen_at, path
+	`, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get storage issues for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &types.StorageConsistencyReport{Check: check, Issues: []*types.StorageIssue{}}
+	for rows.Next() {
+		issue, err := scanStorageIssue(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan storage issue: %v", err)
+			return nil, err
+		}
+		report.Issues = append(report.Issues, issue)
+	}
+	return report, rows.Err()
+}
+
+// GetStorageConsistencyChecks returns the latest storage check of every tenant, tenants with
+// issues or failed checks first
+func (s *Store) GetStorageConsistencyChecks() ([]*types.StorageConsistencyCheck, error) {
+	rows, err := s.DB.Query(`
+		SELECT tenant_id, documents, objects, missing_files, orphaned_files, error, checked_at
+		FROM tenant_storage_checks
+		ORDER BY (error IS NULL AND missing_files = 0 AND orphaned_files = 0), tenant_id
+	`)
+	if err != nil {
+		logger.Errorf("Failed to get storage checks: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := []*types.StorageConsistencyCheck{}
+	for rows.Next() {
+		check, err := scanStorageConsistencyCheck(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan storage check: %v", err)
+			return nil, err
+		}
+		checks = append(checks, check)
+	}
+	return checks, rows.Err()
+}
+
+// GetStorageIssue returns one storage issue of a tenant
+func (s *Store) GetStorageIssue(tenantID string, id uuid.UUID) (*types.StorageIssue, error) {
+	row := s.DB.QueryRow(`
+		SELECT `+storageIssueColumns+`
+		FROM storage_issues
+		WHERE id = $1 AND tenant_id = $2
+	`, id, tenantID)
+
+	issue, err := scanStorageIssue(row)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("storage issue not found: %s", id)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get storage issue %s: %v", id, err)
+		return nil, err
+	}
+	return issue, nil
+}
+
+// ResolveStorageIssue records how an admin resolved an open storage issue
+func (s *Store) ResolveStorageIssue(tenantID string, id uuid.UUID, resolution string, resolvedBy uuid.UUID, requestID *uuid.UUID) (*types.StorageIssue, error) {
+	row := s.DB.QueryRow(`
+		UPDATE storage_issues
+		SET status = 'RESOLVED', resolution = $3, resolved_by = $4, resolved_at = NOW(), document_request_id = $5
+		WHERE id = $1 AND tenant_id = $2 AND status = 'OPEN'
+		RETURNING `+storageIssueColumns,
+		id, tenantID, resolution, resolvedBy, requestID)
+
+	issue, err := scanStorageIssue(row)
+	if err == sql.ErrNoRows {
+		return nil, apperr.Conflict("storage issue %s is not open", id)
+	}
+	if err != nil {
+		logger.Errorf("Failed to resolve storage issue %s: %v", id, err)
+		return nil, err
+	}
+
+	logger.Infof("Storage issue %s of tenant %s resolved with %s by %s", id, tenantID, resolution, resolvedBy)
+	return issue, nil
+}
+
+// ResolveRelinkedStorageIssues resolves the open issues a relink settles: the document's missing
+// file and the file it now points at being orphaned
+func (s *Store) ResolveRelinkedStorageIssues(tenantID string, documentID uuid.UUID, path string, resolvedBy uuid.UUID) error {
+	_, err := s.DB.Exec(`
+		UPDATE storage_issues
+		SET status = 'RESOLVED', resolution = 'RELINK', resolved_by = $4, resolved_at = NOW()
+		WHERE tenant_id = $1 AND status = 'OPEN'
+		  AND ((type = 'MISSING_FILE' AND document_id = $2) OR (type = 'ORPHANED_FILE' AND path = $3))
+	`, tenantID, documentID, path, resolvedBy)
+	if err != nil {
+		logger.Errorf("Failed to resolve relinked storage issues of document %s: %v", documentID, err)
+		return err
+	}
+	return nil
+}
+
+// scanStorageConsistencyCheck scans a tenant_storage_checks row
+func scanStorageConsistencyCheck(row interface{ Scan(...interface{}) error }) (*types.StorageConsistencyCheck, error) {
+	check := &types.StorageConsistencyCheck{}
+	err := row.Scan(&check.TenantID, &check.Documents, &check.Objects, &check.MissingFiles, &check.OrphanedFiles, &check.Error, &check.CheckedAt)
+	if err != nil {
+		return nil, err
+	}
+	return check, nil
+}
+
+// scanStorageIssue scans a storage_issues row
+func scanStorageIssue(row interface{ Scan(...interface{}) error }) (*types.StorageIssue, error) {
+	issue := &types.StorageIssue{}
+	err := row.Scan(&issue.ID, &issue.TenantID, &issue.Type, &issue.Path, &issue.DocumentID, &issue.ClientID, &issue.FilingID,
+		&issue.DocumentName, &issue.DocumentType, pq.Array(&issue.Candidates), &issue.Status, &issue.FirstSeenAt, &issue.LastSeenAt,
+		&issue.Resolution, &issue.ResolvedBy, &issue.ResolvedAt, &issue.DocumentRequestID)
+	if err != nil {
+		return nil, err
+	}
+	if issue.Candidates == nil {
+		issue.Candidates = []string{}
+	}
+	return issue, nil
+}
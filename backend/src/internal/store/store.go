@@ -3,15 +3,22 @@ package store
 import (
 	"context"
 	"database/sql"
+	"sort"
 	"sync"
 	"time"
+	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
 )
 
-// tenantConnection holds a database connection and its last access time
+// DefaultTenantPoolIdleTimeout is how long a tenant connection pool may go unused before it is
+// closed, unless NewStore is given another timeout
+const DefaultTenantPoolIdleTimeout = 5 * time.Minute
+
+// tenantConnection holds a database connection with the time it was opened and last used
 type tenantConnection struct {
 	db         *sql.DB
+	openedAt   time.Time
 	lastAccess time.Time
 }
 
@@ -20,17 +27,31 @@ type Store struct {
 	ctx              context.Context
 	DB               *sql.DB // WellTaxPro's own database
 	tenantConns      map[string]*tenantConnection
-	tenantConnsMutex sync.RWMutex
+	tenantConnsMutex *sync.RWMutex
+	tenantDials      map[string]chan struct{} // One pool is opened per tenant at a time; guarded by tenantConnsMutex
 	stopEviction     chan struct{}
+	poolIdleTimeout  time.Duration                            // Tenant pools unused for longer are closed
+	connHealth       map[string]*types.TenantConnectionHealth // Latest connection attempt per tenant
+	healthMutex      *sync.RWMutex
 }
 
-// NewStore creates a new Store instance and starts the connection eviction goroutine
-func NewStore(ctx context.Context, db *sql.DB) *Store {
+// NewStore creates a new Store instance and starts the connection eviction goroutine, which
+// closes tenant pools unused for poolIdleTimeout (DefaultTenantPoolIdleTimeout when 0)
+func NewStore(ctx context.Context, db *sql.DB, poolIdleTimeout time.Duration) *Store {
+	if poolIdleTimeout <= 0 {
+		poolIdleTimeout = DefaultTenantPoolIdleTimeout
+	}
+
 	s := &Store{
-		ctx:          ctx,
-		DB:           db,
-		tenantConns:  make(map[string]*tenantConnection),
-		stopEviction: make(chan struct{}),
+		ctx:              ctx,
+		DB:               db,
+		tenantConns:      make(map[string]*tenantConnection),
+		tenantConnsMutex: &sync.RWMutex{},
+		tenantDials:      make(map[string]chan struct{}),
+		stopEviction:     make(chan struct{}),
+		poolIdleTimeout:  poolIdleTimeout,
+		connHealth:       make(map[string]*types.TenantConnectionHealth),
+		healthMutex:      &sync.RWMutex{},
 	}
 
 	// Start background goroutine to evict idle connections
@@ -48,24 +69,79 @@ func (s *Store) Close() error {
 	defer s.tenantConnsMutex.Unlock()
 
 	// Close all tenant connections
+	closed := len(s.tenantConns)
 	for tenantID, conn := range s.tenantConns {
 		if err := conn.db.Close(); err != nil {
 			logger.Errorf("Error closing connection for tenant %s: %v", tenantID, err)
 		}
 		delete(s.tenantConns, tenantID)
 	}
+	logger.Infof("Closed %d tenant connection pools", closed)
 
 	// Close main database
 	return s.DB.Close()
 }
 
-// evictIdleConnections runs in background and closes connections idle for > 5 minutes
+// Ping checks that WellTaxPro's own database answers within ctx
+func (s *Store) Ping(ctx context.Context) error {
+	return s.DB.PingContext(ctx)
+}
+
+// EvictTenantConnection closes the tenant's pooled database connection, if this process has one
+func (s *Store) EvictTenantConnection(tenantID string) bool {
+	s.tenantConnsMutex.Lock()
+	defer s.tenantConnsMutex.Unlock()
+
+	conn, exists := s.tenantConns[tenantID]
+	if !exists {
+		return false
+	}
+	if err := conn.db.Close(); err != nil {
+		logger.Errorf("Error closing connection for tenant %s: %v", tenantID, err)
+	}
+	delete(s.tenantConns, tenantID)
+	logger.Infof("Evicted connection for tenant %s", tenantID)
+	return true
+}
+
+// TenantPoolIdleTimeout returns how long a tenant connection pool may go unused before it is closed
+func (s *Store) TenantPoolIdleTimeout() time.Duration {
+	return s.poolIdleTimeout
+}
+
+// GetTenantPoolStats reports the tenant connection pools this process has open, by tenant
+func (s *Store) GetTenantPoolStats() []*types.TenantPoolStats {
+	s.tenantConnsMutex.RLock()
+	defer s.tenantConnsMutex.RUnlock()
+
+	now := time.Now()
+	pools := make([]*types.TenantPoolStats, 0, len(s.tenantConns))
+	for tenantID, conn := range s.tenantConns {
+		stats := conn.db.Stats()
+		pools = append(pools, &types.TenantPoolStats{
+			TenantID:           tenantID,
+			MaxOpenConnections: stats.MaxOpenConnections,
+			OpenConnections:    stats.OpenConnections,
+			InUse:              stats.InUse,
+			Idle:               stats.Idle,
+			WaitCount:          stats.WaitCount,
+			WaitDurationMs:     stats.WaitDuration.Milliseconds(),
+			MaxIdleClosed:      stats.MaxIdleClosed,
+			MaxLifetimeClosed:  stats.MaxLifetimeClosed,
+			OpenedAt:           conn.openedAt,
+			LastAccessAt:       conn.lastAccess,
+			IdleSeconds:        int64(now.Sub(conn.lastAccess).Seconds()),
+		})
+	}
+	sort.Slice(pools, func(i, j int) bool { return pools[i].TenantID < pools[j].TenantID })
+	return pools
+}
+
+// evictIdleConnections runs in background and closes pools unused for longer than the idle timeout
 func (s *Store) evictIdleConnections() {
 	ticker := time.NewTicker(1 * time.Minute) // Check every minute
 	defer ticker.Stop()
 
-	idleTimeout := 5 * time.Minute
-
 	for {
 		select {
 		case <-s.stopEviction:
@@ -75,7 +151,7 @@ func (s *Store) evictIdleConnections() {
 			now := time.Now()
 
 			for tenantID, conn := range s.tenantConns {
-				if now.Sub(conn.lastAccess) > idleTimeout {
+				if now.Sub(conn.lastAccess) > s.poolIdleTimeout {
 					logger.Infof("Evicting idle connection for tenant %s (idle for %v)", tenantID, now.Sub(conn.lastAccess))
 					if err := conn.db.Close(); err != nil {
 						logger.Errorf("Error closing idle connection for tenant %s: %v", tenantID, err)
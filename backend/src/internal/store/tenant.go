@@ -1,10 +1,14 @@
 package store
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"time"
+	"welltaxpro/src/internal/apperr"
 	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/sqlident"
 	"welltaxpro/src/internal/types"
 
 	"github.com/Masterminds/squirrel"
@@ -13,6 +17,13 @@ import (
 
 // GetTenantConnection retrieves tenant connection details from welltaxpro database
 func (s *Store) getTenantConnection(tenantID string) (*types.TenantConnection, error) {
+	if err := s.requireScope(types.ScopeTenantConfigRead); err != nil {
+		return nil, err
+	}
+	if tenantID == types.SmokeTenantID {
+		return types.SmokeTenantConnection(), nil
+	}
+
 	// query := `
 	// 	SELECT id, tenant_id, tenant_name, db_host, db_port, db_user,
 	// 	       db_password, db_name, db_sslmode, schema_prefix, adapter_type,
@@ -31,19 +42,35 @@ func (s *Store) getTenantConnection(tenantID string) (*types.TenantConnection, e
 		"db_host",
 		"db_port",
 		"db_user",
-		"db_password",
+		"COALESCE(db_password, '')",
 		"db_name",
 		"db_sslmode",
+		"db_auth_type",
+		"COALESCE(db_instance_connection_name, '')",
 		"schema_prefix",
 		"adapter_type",
+		"id_version",
 		"COALESCE(storage_provider, 'gcs')",
 		"COALESCE(storage_bucket, '')",
+		"COALESCE(storage_region, '')",
+		"COALESCE(db_region, '')",
+		"COALESCE(data_residency, '')",
 		"COALESCE(storage_credentials_secret, '')",
 		"COALESCE(storage_credentials_path, '')",
+		"COALESCE(storage_upload_credentials_secret, '')",
+		"COALESCE(storage_upload_credentials_path, '')",
+		"COALESCE(storage_read_credentials_secret, '')",
+		"COALESCE(storage_read_credentials_path, '')",
+		"COALESCE(storage_delete_credentials_secret, '')",
+		"COALESCE(storage_delete_credentials_path, '')",
 		"COALESCE(docusign_integration_key, '')",
 		"COALESCE(docusign_client_id, '')",
 		"COALESCE(docusign_private_key_secret, '')",
 		"COALESCE(docusign_api_url, '')",
+		"COALESCE(fraud_rules::text, '')",
+		"COALESCE(commission_sla::text, '')",
+		"COALESCE(portal_security_policy::text, '')",
+		"statement_timeout_ms",
 		"is_active",
 		"created_at",
 		"updated_at",
@@ -62,6 +89,7 @@ func (s *Store) getTenantConnection(tenantID string) (*types.TenantConnection, e
 	row := s.DB.QueryRow(query, args...)
 
 	tc := &types.TenantConnection{}
+	var fraudRules, commissionSLA, portalSecurityPolicy string
 	err = row.Scan(
 		&tc.ID,
 		&tc.TenantID,
@@ -72,16 +100,32 @@ func (s *Store) getTenantConnection(tenantID string) (*types.TenantConnection, e
 		&tc.DBPassword,
 		&tc.DBName,
 		&tc.DBSslMode,
+		&tc.DBAuthType,
+		&tc.DBInstanceConnectionName,
 		&tc.SchemaPrefix,
 		&tc.AdapterType,
+		&tc.IDVersion,
 		&tc.StorageProvider,
 		&tc.StorageBucket,
+		&tc.StorageRegion,
+		&tc.DBRegion,
+		&tc.DataResidency,
 		&tc.StorageCredentialsSecret,
 		&tc.StorageCredentialsPath,
+		&tc.StorageUploadCredentialsSecret,
+		&tc.StorageUploadCredentialsPath,
+		&tc.StorageReadCredentialsSecret,
+		&tc.StorageReadCredentialsPath,
+		&tc.StorageDeleteCredentialsSecret,
+		&tc.StorageDeleteCredentialsPath,
 		&tc.DocuSignIntegrationKey,
 		&tc.DocuSignClientID,
 		&tc.DocuSignPrivateKeySecret,
 		&tc.DocuSignAPIURL,
+		&fraudRules,
+		&commissionSLA,
+		&portalSecurityPolicy,
+		&tc.StatementTimeoutMs,
 		&tc.IsActive,
 		&tc.CreatedAt,
 		&tc.UpdatedAt,
@@ -90,12 +134,42 @@ func (s *Store) getTenantConnection(tenantID string) (*types.TenantConnection, e
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("tenant not found: %s", tenantID)
+			return nil, apperr.NotFound("tenant not found: %s", tenantID)
 		}
 		logger.Errorf("Failed to get tenant connection for %s: %v", tenantID, err)
 		return nil, err
 	}
 
+	if fraudRules != "" {
+		tc.FraudRules = &types.FraudRules{}
+		if err := json.Unmarshal([]byte(fraudRules), tc.FraudRules); err != nil {
+			logger.Errorf("Invalid fraud rules for tenant %s, using defaults: %v", tenantID, err)
+			tc.FraudRules = nil
+		}
+	}
+
+	if commissionSLA != "" {
+		tc.CommissionSLA = &types.CommissionSLA{}
+		if err := json.Unmarshal([]byte(commissionSLA), tc.CommissionSLA); err != nil {
+			logger.Errorf("Invalid commission SLA for tenant %s, using defaults: %v", tenantID, err)
+			tc.CommissionSLA = nil
+		}
+	}
+
+	if portalSecurityPolicy != "" {
+		tc.PortalSecurityPolicy = &types.PortalSecurityPolicy{}
+		if err := json.Unmarshal([]byte(portalSecurityPolicy), tc.PortalSecurityPolicy); err != nil {
+			logger.Errorf("Invalid portal security policy for tenant %s, using defaults: %v", tenantID, err)
+			tc.PortalSecurityPolicy = nil
+		}
+	}
+
+	// Services that cannot connect to tenant databases never see the password
+	if !s.hasScope(types.ScopeTenantDBConnect) {
+		tc.DBPassword = ""
+		return tc, nil
+	}
+
 	// Decrypt password if it's encrypted
 	if crypto.IsEncryptedPassword(tc.DBPassword) {
 		decrypted, err := crypto.DecryptPassword(tc.DBPassword)
@@ -114,59 +188,149 @@ func (s *Store) GetTenantConfig(tenantID string) (*types.TenantConnection, error
 	return s.getTenantConnection(tenantID)
 }
 
-// GetTenantDB gets or creates a database connection for a tenant
-func (s *Store) GetTenantDB(tenantID string) (*sql.DB, *types.TenantConnection, error) {
-	logger.Infof("[GetTenantDB] Starting - TenantID: %s", tenantID)
-
-	// Check if connection already exists
-	s.tenantConnsMutex.RLock()
-	if conn, exists := s.tenantConns[tenantID]; exists {
-		s.tenantConnsMutex.RUnlock()
-		logger.Infof("[GetTenantDB] Reusing existing connection - TenantID: %s", tenantID)
+// GetTenantConnections lists every tenant connection, active or not, newest first. Secrets are
+// not selected.
+func (s *Store) GetTenantConnections() ([]types.TenantConnection, error) {
+	if err := s.requireScope(types.ScopeTenantConfigRead); err != nil {
+		return nil, err
+	}
 
-		// Update last access time
-		s.tenantConnsMutex.Lock()
-		conn.lastAccess = time.Now()
-		s.tenantConnsMutex.Unlock()
+	rows, err := s.DB.Query(`
+		SELECT id, tenant_id, tenant_name, db_host, db_port, db_user,
+		       db_name, db_sslmode, db_auth_type, COALESCE(db_instance_connection_name, ''),
+		       schema_prefix, adapter_type, id_version,
+		       COALESCE(storage_provider, ''), COALESCE(storage_bucket, ''),
+		       COALESCE(storage_region, ''), COALESCE(db_region, ''), COALESCE(data_residency, ''),
+		       COALESCE(docusign_integration_key, ''), COALESCE(docusign_client_id, ''),
+		       COALESCE(docusign_api_url, ''), statement_timeout_ms,
+		       is_active, created_at, updated_at, created_by, notes
+		FROM tenant_connections
+		ORDER BY created_at DESC
+	`)
+	if err != nil {
+		logger.Errorf("Failed to get tenant connections: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
 
-		// Get tenant config for schema info
-		tc, err := s.getTenantConnection(tenantID)
+	tenants := []types.TenantConnection{}
+	for rows.Next() {
+		var tc types.TenantConnection
+		err := rows.Scan(
+			&tc.ID,
+			&tc.TenantID,
+			&tc.TenantName,
+			&tc.DBHost,
+			&tc.DBPort,
+			&tc.DBUser,
+			&tc.DBName,
+			&tc.DBSslMode,
+			&tc.DBAuthType,
+			&tc.DBInstanceConnectionName,
+			&tc.SchemaPrefix,
+			&tc.AdapterType,
+			&tc.IDVersion,
+			&tc.StorageProvider,
+			&tc.StorageBucket,
+			&tc.StorageRegion,
+			&tc.DBRegion,
+			&tc.DataResidency,
+			&tc.DocuSignIntegrationKey,
+			&tc.DocuSignClientID,
+			&tc.DocuSignAPIURL,
+			&tc.StatementTimeoutMs,
+			&tc.IsActive,
+			&tc.CreatedAt,
+			&tc.UpdatedAt,
+			&tc.CreatedBy,
+			&tc.Notes,
+		)
 		if err != nil {
-			logger.Errorf("[GetTenantDB] Failed to get tenant config - TenantID: %s, Error: %v", tenantID, err)
-			return nil, nil, err
+			logger.Errorf("Failed to scan tenant connection: %v", err)
+			return nil, err
 		}
-		return conn.db, tc, nil
+		tenants = append(tenants, tc)
 	}
-	s.tenantConnsMutex.RUnlock()
 
-	logger.Infof("[GetTenantDB] No existing connection, fetching config - TenantID: %s", tenantID)
+	return tenants, rows.Err()
+}
 
-	// Get tenant connection details
+// getQueryableTenantConnection is getTenantConnection for callers about to query the tenant
+// database. The schema prefix is put into every tenant query, so a tenant whose prefix fails
+// validation is refused rather than served.
+func (s *Store) getQueryableTenantConnection(tenantID string) (*types.TenantConnection, error) {
 	tc, err := s.getTenantConnection(tenantID)
 	if err != nil {
-		logger.Errorf("[GetTenantDB] Failed to get tenant connection - TenantID: %s, Error: %v", tenantID, err)
+		return nil, err
+	}
+	if err := sqlident.ValidateSchema(tc.SchemaPrefix); err != nil {
+		logger.Errorf("Refusing queries to tenant %s: %v", tenantID, err)
+		return nil, apperr.Validation("tenant %s has an invalid schema prefix; fix its configuration", tenantID)
+	}
+	return tc, nil
+}
+
+// tenantQueryContext bounds a call to a tenant database by the tenant's statement timeout.
+// Cancelling the context cancels the statement running on the tenant database.
+func tenantQueryContext(ctx context.Context, tc *types.TenantConnection) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(ctx, tc.StatementTimeout())
+}
+
+// GetTenantSQLDB is GetTenantDB for callers that query the tenant database directly instead of
+// going through its adapter. Tenants without a database, such as the smoke tenant, are rejected.
+func (s *Store) GetTenantSQLDB(ctx context.Context, tenantID string) (*sql.DB, *types.TenantConnection, error) {
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
 		return nil, nil, err
 	}
+	if db == nil {
+		return nil, nil, apperr.Validation("tenant %s has no database for this operation", tenantID)
+	}
+	return db, tc, nil
+}
 
-	logger.Infof("[GetTenantDB] Config fetched - TenantID: %s, DBHost: %s, DBPort: %d, DBName: %s, SSLMode: %s",
-		tenantID, tc.DBHost, tc.DBPort, tc.DBName, tc.DBSslMode)
+// GetTenantDB gets or creates a database connection for a tenant. A new pool is opened and
+// pinged outside the lock on every tenant's pools and bounded by the tenant's statement timeout,
+// so an unreachable tenant database only holds up requests to that tenant.
+func (s *Store) GetTenantDB(ctx context.Context, tenantID string) (*sql.DB, *types.TenantConnection, error) {
+	if err := s.requireScope(types.ScopeTenantDBConnect); err != nil {
+		return nil, nil, err
+	}
 
-	// Create new connection
-	s.tenantConnsMutex.Lock()
-	defer s.tenantConnsMutex.Unlock()
+	// The smoke tenant's adapter works in memory and has no database
+	if tenantID == types.SmokeTenantID {
+		return nil, types.SmokeTenantConnection(), nil
+	}
+
+	// Get tenant config for schema info
+	tc, err := s.getQueryableTenantConnection(tenantID)
+	if err != nil {
+		logger.Errorf("[GetTenantDB] Failed to get tenant connection - TenantID: %s, Error: %v", tenantID, err)
+		return nil, nil, err
+	}
+
+	if db := s.openTenantPool(tenantID); db != nil {
+		return db, tc, nil
+	}
 
-	// Double-check if connection was created while waiting for lock
-	if conn, exists := s.tenantConns[tenantID]; exists {
-		logger.Infof("[GetTenantDB] Connection created while waiting for lock - TenantID: %s", tenantID)
-		conn.lastAccess = time.Now()
-		return conn.db, tc, nil
+	// Wait for this tenant's dial slot; a dial already in progress may open the pool for us
+	dial := s.tenantDial(tenantID)
+	select {
+	case dial <- struct{}{}:
+		defer func() { <-dial }()
+	case <-ctx.Done():
+		return nil, nil, fmt.Errorf("failed to connect to tenant database: %w", ctx.Err())
+	}
+	if db := s.openTenantPool(tenantID); db != nil {
+		logger.Infof("[GetTenantDB] Connection created while waiting to dial - TenantID: %s", tenantID)
+		return db, tc, nil
 	}
 
-	logger.Infof("[GetTenantDB] Opening new database connection - TenantID: %s", tenantID)
+	logger.Infof("[GetTenantDB] Opening new database connection - TenantID: %s, DBHost: %s, DBPort: %d, DBName: %s, SSLMode: %s, AuthType: %s",
+		tenantID, tc.DBHost, tc.DBPort, tc.DBName, tc.DBSslMode, tc.DBAuthType)
 
 	// Open database connection (DO NOT log connection string - contains password)
-	connStr := tc.GetConnectionString()
-	db, err := sql.Open("postgres", connStr)
+	db, err := openTenantDB(tc)
 	if err != nil {
 		logger.Errorf("[GetTenantDB] Failed to open connection - TenantID: %s, DBHost: %s, Error: %v",
 			tenantID, tc.DBHost, err)
@@ -178,10 +342,13 @@ func (s *Store) GetTenantDB(tenantID string) (*sql.DB, *types.TenantConnection,
 	db.SetMaxIdleConns(2)
 	db.SetConnMaxLifetime(30 * time.Second)
 
-	logger.Infof("[GetTenantDB] Testing connection with ping - TenantID: %s", tenantID)
-
 	// Test connection
-	if err := db.Ping(); err != nil {
+	pingCtx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+	pingStart := time.Now()
+	err = db.PingContext(pingCtx)
+	s.recordConnectionHealth(tenantID, pingStart, err)
+	if err != nil {
 		db.Close()
 		logger.Errorf("[GetTenantDB] FAILED - Ping failed - TenantID: %s, DBHost: %s, DBPort: %d, Error: %v",
 			tenantID, tc.DBHost, tc.DBPort, err)
@@ -189,11 +356,39 @@ func (s *Store) GetTenantDB(tenantID string) (*sql.DB, *types.TenantConnection,
 	}
 
 	// Store connection with current timestamp
+	now := time.Now()
+	s.tenantConnsMutex.Lock()
 	s.tenantConns[tenantID] = &tenantConnection{
 		db:         db,
-		lastAccess: time.Now(),
+		openedAt:   now,
+		lastAccess: now,
 	}
+	s.tenantConnsMutex.Unlock()
 	logger.Infof("[GetTenantDB] SUCCESS - Connection established - TenantID: %s, DBHost: %s", tenantID, tc.DBHost)
 
 	return db, tc, nil
 }
+
+// openTenantPool returns the tenant's open pool, marking it used, or nil when it has none
+func (s *Store) openTenantPool(tenantID string) *sql.DB {
+	s.tenantConnsMutex.Lock()
+	defer s.tenantConnsMutex.Unlock()
+	conn, exists := s.tenantConns[tenantID]
+	if !exists {
+		return nil
+	}
+	conn.lastAccess = time.Now()
+	return conn.db
+}
+
+// tenantDial returns the slot a caller holds while opening the tenant's pool
+func (s *Store) tenantDial(tenantID string) chan struct{} {
+	s.tenantConnsMutex.Lock()
+	defer s.tenantConnsMutex.Unlock()
+	dial, exists := s.tenantDials[tenantID]
+	if !exists {
+		dial = make(chan struct{}, 1)
+		s.tenantDials[tenantID] = dial
+	}
+	return dial
+}
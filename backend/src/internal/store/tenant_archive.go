@@ -0,0 +1,322 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// GetArchivedYears returns a tenant's archived tax years, newest first, with how many of their
+// documents were moved to the archive storage class
+func (s *Store) GetArchivedYears(tenantID string) ([]*types.ArchivedYear, error) {
+	rows, err := s.DB.Query(`
+		SELECT y.tenant_id, y.tax_year, y.archived_at, y.archived_by, COUNT(d.document_id)
+		FROM tenant_archived_years y
+		LEFT JOIN archived_documents d ON d.tenant_id = y.tenant_id AND d.tax_year = y.tax_year
+		WHERE y.tenant_id = $1
+		GROUP BY y.tenant_id, y.tax_year
+		ORDER BY y.tax_year DESC
+	`, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get archived years of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	years := []*types.ArchivedYear{}
+	for rows.Next() {
+		year := &types.ArchivedYear{}
+		if err := rows.Scan(&year.TenantID, &year.Year, &year.ArchivedAt, &year.ArchivedBy, &year.Documents); err != nil {
+			logger.Errorf("Failed to scan archived year: %v", err)
+			return nil, err
+		}
+		years = append(years, year)
+	}
+	return years, rows.Err()
+}
+
+// ArchiveYear makes a tenant's filings of a tax year and their documents read-only. The worker
+// moves the year's documents to the archive storage class afterwards.
+func (s *Store) ArchiveYear(tenantID string, year int, employeeID uuid.UUID) (*types.ArchivedYear, error) {
+	if year < 1900 || year > time.Now().Year() {
+		return nil, apperr.Validation("invalid tax year %d", year)
+	}
+
+	archived := &types.ArchivedYear{TenantID: tenantID, Year: year, ArchivedBy: &employeeID}
+	err := s.DB.QueryRow(`
+		INSERT INTO tenant_archived_years (tenant_id, tax_year, archived_by)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (tenant_id, tax_year) DO NOTHING
+		RETURNING archived_at
+	`, tenantID, year, employeeID).Scan(&archived.ArchivedAt)
+	if err == sql.ErrNoRows {
+		return nil, apperr.Conflict("tax year %d is already archived", year)
+	}
+	if err != nil {
+		logger.Errorf("Failed to archive tax year %d of tenant %s: %v", year, tenantID, err)
+		return nil, err
+	}
+
+	logger.Infof("Employee %s archived tax year %d of tenant %s", employeeID, year, tenantID)
+	return archived, nil
+}
+
+// UnarchiveYear makes a tenant's filings of a tax year writable again. The worker moves the
+// year's documents back to the standard storage class afterwards.
+func (s *Store) UnarchiveYear(tenantID string, year int, employeeID uuid.UUID) error {
+	result, err := s.DB.Exec(`DELETE FROM tenant_archived_years WHERE tenant_id = $1 AND tax_year = $2`, tenantID, year)
+	if err != nil {
+		logger.Errorf("Failed to unarchive tax year %d of tenant %s: %v", year, tenantID, err)
+		return err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return apperr.NotFound("tax year %d is not archived", year)
+	}
+
+	logger.Infof("Employee %s unarchived tax year %d of tenant %s", employeeID, year, tenantID)
+	return nil
+}
+
+// archivedYearSet returns the archived tax years of a tenant
+func (s *Store) archivedYearSet(tenantID string) (map[int]bool, error) {
+	rows, err := s.DB.Query(`SELECT tax_year FROM tenant_archived_years WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get archived years of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	years := map[int]bool{}
+	for rows.Next() {
+		var year int
+		if err := rows.Scan(&year); err != nil {
+			return nil, err
+		}
+		years[year] = true
+	}
+	return years, rows.Err()
+}
+
+// checkFilingWritable returns a conflict error when a filing belongs to an archived tax year.
+// Tenants without archived years are not looked up in their database.
+func (s *Store) checkFilingWritable(ctx context.Context, tenantID string, db *sql.DB, tc *types.TenantConnection, clientAdapter adapter.ClientAdapter, filingID string) error {
+	years, err := s.archivedYearSet(tenantID)
+	if err != nil || len(years) == 0 {
+		return err
+	}
+
+	year, err := clientAdapter.GetFilingYear(ctx, db, tc.SchemaPrefix, filingID)
+	if err != nil {
+		return err
+	}
+	if years[year] {
+		return apperr.Conflict("filing %s is in archived tax year %d; unarchive the year to change it", filingID, year)
+	}
+	return nil
+}
+
+// CheckFilingWritable returns a conflict error when a filing belongs to an archived tax year
+func (s *Store) CheckFilingWritable(ctx context.Context, tenantID string, filingID string) error {
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+	clientAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		return fmt.Errorf("failed to create adapter: %w", err)
+	}
+	return s.checkFilingWritable(ctx, tenantID, db, tc, clientAdapter, filingID)
+}
+
+// CheckDocumentWritable returns a conflict error when a document belongs to a filing of an
+// archived tax year. Documents without a filing are always writable.
+func (s *Store) CheckDocumentWritable(ctx context.Context, tenantID string, documentID string) error {
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+	clientAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		return fmt.Errorf("failed to create adapter: %w", err)
+	}
+	return s.checkDocumentWritable(ctx, tenantID, db, tc, clientAdapter, documentID)
+}
+
+// checkDocumentWritable is CheckDocumentWritable with the tenant's database already open
+func (s *Store) checkDocumentWritable(ctx context.Context, tenantID string, db *sql.DB, tc *types.TenantConnection, clientAdapter adapter.ClientAdapter, documentID string) error {
+	years, err := s.archivedYearSet(tenantID)
+	if err != nil || len(years) == 0 {
+		return err
+	}
+
+	document, err := clientAdapter.GetDocumentByID(ctx, db, tc.SchemaPrefix, documentID)
+	if err != nil {
+		return err
+	}
+	if document.FilingID == nil {
+		return nil
+	}
+	return s.checkFilingWritable(ctx, tenantID, db, tc, clientAdapter, document.FilingID.String())
+}
+
+// GetArchiveTenants returns the tenants with archived years or with documents still in the
+// archive storage class, whose files the archive_storage job may need to move
+func (s *Store) GetArchiveTenants() ([]string, error) {
+	rows, err := s.DB.Query(`
+		SELECT tenant_id FROM tenant_archived_years
+		UNION
+		SELECT tenant_id FROM archived_documents
+	`)
+	if err != nil {
+		logger.Errorf("Failed to get tenants with archives: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	tenantIDs := []string{}
+	for rows.Next() {
+		var tenantID string
+		if err := rows.Scan(&tenantID); err != nil {
+			return nil, err
+		}
+		tenantIDs = append(tenantIDs, tenantID)
+	}
+	return tenantIDs, rows.Err()
+}
+
+// GetDocumentsToArchive returns up to limit documents of a tenant's archived years not yet moved
+// to the archive storage class, skipping infected ones
+func (s *Store) GetDocumentsToArchive(ctx context.Context, tenantID string, limit int) ([]*types.ArchivedDocument, error) {
+	years, err := s.archivedYearSet(tenantID)
+	if err != nil || len(years) == 0 {
+		return nil, err
+	}
+
+	db, tc, err := s.GetTenantDB(ctx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := tenantQueryContext(ctx, tc)
+	defer cancel()
+	clientAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	moved := map[uuid.UUID]bool{}
+	rows, err := s.DB.Query(`SELECT document_id FROM archived_documents WHERE tenant_id = $1`, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get archived documents of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var documentID uuid.UUID
+		if err := rows.Scan(&documentID); err != nil {
+			return nil, err
+		}
+		moved[documentID] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	pending := []*types.ArchivedDocument{}
+	for year := range years {
+		documents, err := clientAdapter.GetDocumentsByYear(ctx, db, tc.SchemaPrefix, year)
+		if err != nil {
+			return nil, err
+		}
+		if err := s.attachScanStatuses(tenantID, documents); err != nil {
+			return nil, err
+		}
+		for _, document := range documents {
+			// Infected files were moved to quarantine and are not kept
+			if moved[document.ID] || (document.ScanStatus != nil && *document.ScanStatus == types.DocumentScanInfected) {
+				continue
+			}
+			pending = append(pending, &types.ArchivedDocument{TenantID: tenantID, DocumentID: document.ID, Year: year, FilePath: document.FilePath})
+			if len(pending) >= limit {
+				return pending, nil
+			}
+		}
+	}
+	return pending, nil
+}
+
+// GetDocumentsToRestore returns up to limit documents in the archive storage class whose tax
+// year is no longer archived
+func (s *Store) GetDocumentsToRestore(tenantID string, limit int) ([]*types.ArchivedDocument, error) {
+	rows, err := s.DB.Query(`
+		SELECT d.tenant_id, d.document_id, d.tax_year, d.file_path, d.archived_at
+		FROM archived_documents d
+		WHERE d.tenant_id = $1
+		  AND NOT EXISTS (
+			SELECT 1 FROM tenant_archived_years y
+			WHERE y.tenant_id = d.tenant_id AND y.tax_year = d.tax_year
+		  )
+		ORDER BY d.archived_at
+		LIMIT $2
+	`, tenantID, limit)
+	if err != nil {
+		logger.Errorf("Failed to get documents to restore for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	documents := []*types.ArchivedDocument{}
+	for rows.Next() {
+		document := &types.ArchivedDocument{}
+		if err := rows.Scan(&document.TenantID, &document.DocumentID, &document.Year, &document.FilePath, &document.ArchivedAt); err != nil {
+			logger.Errorf("Failed to scan archived document: %v", err)
+			return nil, err
+		}
+		documents = append(documents, document)
+	}
+	return documents, rows.Err()
+}
+
+// RecordArchivedDocument records that a document's file was moved to the archive storage class
+func (s *Store) RecordArchivedDocument(document *types.ArchivedDocument) error {
+	if err := s.requireScope(types.ScopeDocumentArchive); err != nil {
+		return err
+	}
+
+	_, err := s.DB.Exec(`
+		INSERT INTO archived_documents (tenant_id, document_id, tax_year, file_path)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (tenant_id, document_id) DO NOTHING
+	`, document.TenantID, document.DocumentID, document.Year, document.FilePath)
+	if err != nil {
+		logger.Errorf("Failed to record archived document %s: %v", document.DocumentID, err)
+		return err
+	}
+	return nil
+}
+
+// DeleteArchivedDocument forgets a document moved back to the standard storage class
+func (s *Store) DeleteArchivedDocument(tenantID string, documentID uuid.UUID) error {
+	if err := s.requireScope(types.ScopeDocumentArchive); err != nil {
+		return err
+	}
+
+	if _, err := s.DB.Exec(`DELETE FROM archived_documents WHERE tenant_id = $1 AND document_id = $2`, tenantID, documentID); err != nil {
+		logger.Errorf("Failed to delete archived document %s: %v", documentID, err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"database/sql"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+const tenantContextColumns = `id, employee_id, tenant_id, role, auth_time, created_at, expires_at`
+
+// scanTenantContext scans a row selected with tenantContextColumns
+func scanTenantContext(row interface{ Scan(...interface{}) error }) (*types.TenantContext, error) {
+	tc := &types.TenantContext{}
+	if err := row.Scan(&tc.ID, &tc.EmployeeID, &tc.TenantID, &tc.Role, &tc.AuthTime, &tc.CreatedAt, &tc.ExpiresAt); err != nil {
+		return nil, err
+	}
+	return tc, nil
+}
+
+// CreateTenantContext mints a tenant context for an employee's sign-in at authTime. Admins may
+// select any active tenant; other employees need active access to it. The context's role comes
+// from the employee's tenant access when that narrows their role.
+func (s *Store) CreateTenantContext(employee *types.Employee, tenantID string, authTime int64, tokenHash string) (*types.TenantContext, error) {
+	var tenantRole sql.NullString
+	var hasAccess bool
+	err := s.DB.QueryRow(`
+		SELECT eta.role, eta.id IS NOT NULL
+		FROM tenant_connections tc
+		LEFT JOIN employee_tenant_access eta
+		       ON eta.tenant_id = tc.tenant_id AND eta.employee_id = $2 AND eta.is_active
+		WHERE tc.tenant_id = $1 AND tc.is_active
+	`, tenantID, employee.ID).Scan(&tenantRole, &hasAccess)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("tenant not found: %s", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to check access of employee %s to tenant %s: %v", employee.ID, tenantID, err)
+		return nil, err
+	}
+	if !hasAccess && !employee.IsAdmin() {
+		return nil, apperr.Permission("employee %s has no access to tenant %s", employee.Email, tenantID)
+	}
+
+	tc, err := scanTenantContext(s.DB.QueryRow(`
+		INSERT INTO employee_tenant_contexts (token_hash, employee_id, tenant_id, role, auth_time, expires_at)
+		VALUES ($1, $2, $3, $4, $5, NOW() + make_interval(secs => $6))
+		RETURNING `+tenantContextColumns,
+		tokenHash, employee.ID, tenantID, types.TenantContextRole(employee.Role, tenantRole.String), authTime,
+		types.TenantContextLifetime.Seconds()))
+	if err != nil {
+		logger.Errorf("Failed to create tenant context for employee %s: %v", employee.ID, err)
+		return nil, err
+	}
+
+	logger.Infof("Tenant context %s opened for employee %s in tenant %s as %s", tc.ID, employee.ID, tenantID, tc.Role)
+	return tc, nil
+}
+
+// GetTenantContext returns the unexpired, unrevoked tenant context a token hash belongs to
+func (s *Store) GetTenantContext(tokenHash string) (*types.TenantContext, error) {
+	tc, err := scanTenantContext(s.DB.QueryRow(`
+		SELECT `+tenantContextColumns+`
+		FROM employee_tenant_contexts
+		WHERE token_hash = $1 AND revoked_at IS NULL AND expires_at > NOW()
+	`, tokenHash))
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("tenant context not found or expired")
+	}
+	if err != nil {
+		logger.Errorf("Failed to get tenant context: %v", err)
+		return nil, err
+	}
+	return tc, nil
+}
+
+// RevokeTenantContext ends one of an employee's tenant contexts before it expires
+func (s *Store) RevokeTenantContext(employeeID uuid.UUID, tokenHash string) error {
+	result, err := s.DB.Exec(`
+		UPDATE employee_tenant_contexts
+		SET revoked_at = NOW()
+		WHERE token_hash = $1 AND employee_id = $2 AND revoked_at IS NULL
+	`, tokenHash, employeeID)
+	if err != nil {
+		logger.Errorf("Failed to revoke tenant context of employee %s: %v", employeeID, err)
+		return err
+	}
+	if n, err := result.RowsAffected(); err == nil && n == 0 {
+		return apperr.NotFound("tenant context not found")
+	}
+	return nil
+}
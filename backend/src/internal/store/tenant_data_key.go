@@ -0,0 +1,301 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"welltaxpro/src/internal/adapter"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+var _ crypto.DataKeySource = (*Store)(nil)
+
+const ssnRekeyColumns = `id, tenant_id, key_id, status, reencrypted, error, requested_by, created_at, started_at, finished_at`
+
+// DataKey returns a tenant data key by ID, active or retired
+func (s *Store) DataKey(keyID string) (*types.TenantDataKey, error) {
+	id, err := uuid.Parse(keyID)
+	if err != nil {
+		return nil, apperr.Validation("invalid data key ID %q", keyID)
+	}
+
+	dk := &types.TenantDataKey{}
+	err = s.DB.QueryRow(`
+		SELECT id, tenant_id, wrapped_key, status, created_at, retired_at
+		FROM tenant_data_keys
+		WHERE id = $1
+	`, id).Scan(&dk.ID, &dk.TenantID, &dk.WrappedKey, &dk.Status, &dk.CreatedAt, &dk.RetiredAt)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("data key %s not found", keyID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get data key %s: %v", keyID, err)
+		return nil, err
+	}
+	return dk, nil
+}
+
+// ActiveDataKey returns a tenant's active data key, creating its first one if it has none
+func (s *Store) ActiveDataKey(tenantID string) (*types.TenantDataKey, error) {
+	dk, err := s.activeDataKey(s.DB, tenantID)
+	if err == nil || !errors.Is(err, apperr.ErrNotFound) {
+		return dk, err
+	}
+
+	wrapped, err := crypto.NewWrappedDataKey()
+	if err != nil {
+		return nil, err
+	}
+	// Another process may create the first key at the same time; both then use the one stored
+	if _, err := s.DB.Exec(`
+		INSERT INTO tenant_data_keys (tenant_id, wrapped_key)
+		VALUES ($1, $2)
+		ON CONFLICT (tenant_id) WHERE status = 'ACTIVE' DO NOTHING
+	`, tenantID, wrapped); err != nil {
+		logger.Errorf("Failed to create data key of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	logger.Infof("Created the first SSN data key of tenant %s", tenantID)
+	return s.activeDataKey(s.DB, tenantID)
+}
+
+// activeDataKey reads a tenant's active data key
+func (s *Store) activeDataKey(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, tenantID string) (*types.TenantDataKey, error) {
+	dk := &types.TenantDataKey{}
+	err := q.QueryRow(`
+		SELECT id, tenant_id, wrapped_key, status, created_at, retired_at
+		FROM tenant_data_keys
+		WHERE tenant_id = $1 AND status = 'ACTIVE'
+	`, tenantID).Scan(&dk.ID, &dk.TenantID, &dk.WrappedKey, &dk.Status, &dk.CreatedAt, &dk.RetiredAt)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("tenant %s has no active data key", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get active data key of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	return dk, nil
+}
+
+// GetTenantDataKeys returns a tenant's data keys, newest first, without the keys themselves
+func (s *Store) GetTenantDataKeys(tenantID string) ([]*types.TenantDataKey, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, tenant_id, status, created_at, retired_at
+		FROM tenant_data_keys
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+	`, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get data keys of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*types.TenantDataKey{}
+	for rows.Next() {
+		dk := &types.TenantDataKey{}
+		if err := rows.Scan(&dk.ID, &dk.TenantID, &dk.Status, &dk.CreatedAt, &dk.RetiredAt); err != nil {
+			logger.Errorf("Failed to scan data key: %v", err)
+			return nil, err
+		}
+		keys = append(keys, dk)
+	}
+	return keys, rows.Err()
+}
+
+// RotateTenantDataKey retires a tenant's active data key, makes a new one active and queues a
+// rekey that re-encrypts the tenant's SSNs under it. Only one rekey of a tenant runs at a time.
+func (s *Store) RotateTenantDataKey(tenantID string, requestedBy uuid.UUID) (*types.SSNRekey, error) {
+	wrapped, err := crypto.NewWrappedDataKey()
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		logger.Errorf("Failed to begin transaction: %v", err)
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var unfinished bool
+	if err := tx.QueryRow(`
+		SELECT EXISTS (SELECT 1 FROM tenant_ssn_rekeys WHERE tenant_id = $1 AND status IN ('QUEUED', 'RUNNING'))
+	`, tenantID).Scan(&unfinished); err != nil {
+		logger.Errorf("Failed to check rekeys of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	if unfinished {
+		return nil, apperr.Conflict("a rekey of tenant %s is already in progress", tenantID)
+	}
+
+	if _, err := tx.Exec(`
+		UPDATE tenant_data_keys SET status = 'RETIRED', retired_at = NOW()
+		WHERE tenant_id = $1 AND status = 'ACTIVE'
+	`, tenantID); err != nil {
+		logger.Errorf("Failed to retire data key of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	var keyID uuid.UUID
+	if err := tx.QueryRow(`
+		INSERT INTO tenant_data_keys (tenant_id, wrapped_key) VALUES ($1, $2) RETURNING id
+	`, tenantID, wrapped).Scan(&keyID); err != nil {
+		logger.Errorf("Failed to create data key of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	rekey, err := scanSSNRekey(tx.QueryRow(`
+		INSERT INTO tenant_ssn_rekeys (tenant_id, key_id, requested_by)
+		VALUES ($1, $2, $3)
+		RETURNING `+ssnRekeyColumns,
+		tenantID, keyID, requestedBy))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, apperr.Conflict("a rekey of tenant %s is already in progress", tenantID)
+		}
+		logger.Errorf("Failed to queue rekey of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Errorf("Failed to commit data key rotation of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	crypto.ForgetActiveDataKey(tenantID)
+
+	logger.Infof("Rotated SSN data key of tenant %s to %s; rekey %s queued", tenantID, keyID, rekey.ID)
+	return rekey, nil
+}
+
+// GetSSNRekeys returns a tenant's latest rekeys, newest first
+func (s *Store) GetSSNRekeys(tenantID string, limit int) ([]*types.SSNRekey, error) {
+	return s.querySSNRekeys(`
+		SELECT `+ssnRekeyColumns+`
+		FROM tenant_ssn_rekeys
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+		LIMIT $2
+	`, tenantID, limit)
+}
+
+// GetUnfinishedSSNRekeys returns the queued and interrupted rekeys, oldest first
+func (s *Store) GetUnfinishedSSNRekeys() ([]*types.SSNRekey, error) {
+	return s.querySSNRekeys(`
+		SELECT ` + ssnRekeyColumns + `
+		FROM tenant_ssn_rekeys
+		WHERE status IN ('QUEUED', 'RUNNING')
+		ORDER BY created_at
+	`)
+}
+
+// RunSSNRekey re-encrypts every SSN of the rekey's tenant under its active data key and records
+// the outcome. Running it again after an interruption only rewrites what is left.
+func (s *Store) RunSSNRekey(ctx context.Context, rekey *types.SSNRekey) (int, error) {
+	if err := s.requireScope(types.ScopeSSNRekey); err != nil {
+		return 0, err
+	}
+
+	if _, err := s.DB.Exec(`
+		UPDATE tenant_ssn_rekeys SET status = 'RUNNING', started_at = COALESCE(started_at, NOW())
+		WHERE id = $1
+	`, rekey.ID); err != nil {
+		logger.Errorf("Failed to start rekey %s: %v", rekey.ID, err)
+		return 0, err
+	}
+
+	rewritten, runErr := s.reencryptTenantSSNs(ctx, rekey)
+	if runErr != nil && ctx.Err() != nil {
+		// Stopped by shutdown; the next run resumes
+		return rewritten, nil
+	}
+
+	status := types.SSNRekeyCompleted
+	var errMsg *string
+	if runErr != nil {
+		status = types.SSNRekeyFailed
+		msg := runErr.Error()
+		errMsg = &msg
+	}
+	if _, err := s.DB.Exec(`
+		UPDATE tenant_ssn_rekeys
+		SET status = $2, reencrypted = reencrypted + $3, error = $4, finished_at = NOW()
+		WHERE id = $1
+	`, rekey.ID, status, rewritten, errMsg); err != nil {
+		logger.Errorf("Failed to finish rekey %s: %v", rekey.ID, err)
+		return rewritten, err
+	}
+
+	logger.Infof("Rekey %s of tenant %s finished: %s, %d SSNs re-encrypted", rekey.ID, rekey.TenantID, status, rewritten)
+	return rewritten, runErr
+}
+
+// reencryptTenantSSNs rewrites the tenant's SSNs not yet under the rekey's data key
+func (s *Store) reencryptTenantSSNs(ctx context.Context, rekey *types.SSNRekey) (int, error) {
+	db, tc, err := s.GetTenantDB(ctx, rekey.TenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	rekeyAdapter, err := adapter.ForTenant(tc)
+	if err != nil {
+		logger.Errorf("Failed to create adapter for tenant %s: %v", rekey.TenantID, err)
+		return 0, fmt.Errorf("failed to create adapter: %w", err)
+	}
+
+	// A rotation in another process may still be cached here
+	crypto.ForgetActiveDataKey(rekey.TenantID)
+	active, err := s.activeDataKey(s.DB, rekey.TenantID)
+	if err != nil {
+		return 0, err
+	}
+	if active.ID != rekey.KeyID {
+		return 0, fmt.Errorf("data key %s is no longer active; rotate again", rekey.KeyID)
+	}
+
+	logger.Infof("Using %s adapter to re-encrypt SSNs of tenant %s under data key %s", tc.AdapterType, rekey.TenantID, rekey.KeyID)
+
+	return rekeyAdapter.ReencryptSSNs(ctx, db, tc.SchemaPrefix, func(value string) (string, bool, error) {
+		return crypto.ReencryptTenantSSN(rekey.TenantID, value)
+	})
+}
+
+// scanSSNRekey reads a row of ssnRekeyColumns
+func scanSSNRekey(row interface{ Scan(...interface{}) error }) (*types.SSNRekey, error) {
+	r := &types.SSNRekey{}
+	err := row.Scan(&r.ID, &r.TenantID, &r.KeyID, &r.Status, &r.Reencrypted, &r.Error, &r.RequestedBy,
+		&r.CreatedAt, &r.StartedAt, &r.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// querySSNRekeys runs a query selecting ssnRekeyColumns
+func (s *Store) querySSNRekeys(query string, args ...interface{}) ([]*types.SSNRekey, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		logger.Errorf("Failed to query SSN rekeys: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	rekeys := []*types.SSNRekey{}
+	for rows.Next() {
+		r, err := scanSSNRekey(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan SSN rekey: %v", err)
+			return nil, err
+		}
+		rekeys = append(rekeys, r)
+	}
+	return rekeys, rows.Err()
+}
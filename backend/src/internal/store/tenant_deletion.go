@@ -0,0 +1,182 @@
+package store
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// CountTenantDeletionImpact counts what deactivating an active tenant affects. Open filings are
+// counted in the tenant database; when it cannot be reached they are left out with the reason.
+// Storage is measured by the caller, which has the tenant's storage provider.
+func (s *Store) CountTenantDeletionImpact(ctx context.Context, tenantID string) (*types.TenantDeletionImpact, error) {
+	impact := &types.TenantDeletionImpact{}
+	err := s.DB.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM employee_tenant_access eta
+			 JOIN employees e ON e.id = eta.employee_id
+			 WHERE eta.tenant_id = tc.tenant_id AND eta.is_active = true AND e.is_active = true),
+			(SELECT COUNT(*) FROM tenant_users tu WHERE tu.tenant_id = tc.tenant_id AND tu.is_active = true),
+			(SELECT COUNT(*) FROM portal_sessions ps WHERE ps.tenant_id = tc.tenant_id AND ps.ended_at IS NULL)
+		FROM tenant_connections tc
+		WHERE tc.tenant_id = $1 AND tc.is_active = true
+	`, tenantID).Scan(&impact.ActiveEmployees, &impact.ActivePortalUsers, &impact.ActivePortalSessions)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("active tenant not found: %s", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to count deletion impact of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	open, err := s.CountOpenFilings(ctx, tenantID)
+	if err != nil {
+		logger.Warningf("Failed to count open filings of tenant %s for deletion: %v", tenantID, err)
+		impact.OpenFilingsError = "failed to count open filings"
+	} else {
+		impact.OpenFilings = &open
+	}
+
+	return impact, nil
+}
+
+// CreateTenantDeletionRequest records a request by requestedBy to deactivate a tenant and returns
+// it with its confirmation token. The tenant's earlier unconfirmed requests can no longer be used.
+func (s *Store) CreateTenantDeletionRequest(tenantID string, impact *types.TenantDeletionImpact, requestedBy uuid.UUID) (*types.TenantDeletionRequest, error) {
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return nil, fmt.Errorf("failed to generate confirmation token: %w", err)
+	}
+	token := hex.EncodeToString(tokenBytes)
+
+	data, err := json.Marshal(impact)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tenant deletion impact: %w", err)
+	}
+
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE tenant_deletion_requests SET expires_at = NOW()
+		WHERE tenant_id = $1 AND confirmed_at IS NULL AND expires_at > NOW()
+	`, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to supersede deletion requests of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	request := &types.TenantDeletionRequest{
+		TenantID:    tenantID,
+		Token:       token,
+		Impact:      *impact,
+		RequestedBy: requestedBy,
+	}
+	err = tx.QueryRow(`
+		INSERT INTO tenant_deletion_requests (tenant_id, token_hash, impact, requested_by, expires_at)
+		VALUES ($1, $2, $3, $4, NOW() + make_interval(secs => $5))
+		RETURNING id, requested_at, expires_at
+	`, tenantID, hashDeletionToken(token), string(data), requestedBy, types.TenantDeletionWindow.Seconds()).Scan(
+		&request.ID, &request.RequestedAt, &request.ExpiresAt)
+	if err != nil {
+		logger.Errorf("Failed to record deletion request of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Warningf("Deactivation of tenant %s requested by %s", tenantID, requestedBy)
+	return request, nil
+}
+
+// DeactivateTenant deactivates a tenant with the confirmation token of its latest deletion
+// request, which must have been made by employeeID and not have expired. The tenant's open
+// portal sessions are ended and this process's pooled connection is closed. Other processes
+// stop using their pooled connection at once, since inactive tenants have no readable config,
+// and close it once it is idle.
+func (s *Store) DeactivateTenant(tenantID, token string, employeeID uuid.UUID) (*types.TenantDeactivation, error) {
+	deactivation := &types.TenantDeactivation{TenantID: tenantID}
+
+	err := s.ChangeTenantConfig(tenantID, types.TenantConfigActionDeactivate, &employeeID, func(tx *sql.Tx) error {
+		var requestID, requestedBy uuid.UUID
+		var expired bool
+		var confirmedAt *time.Time
+		err := tx.QueryRow(`
+			SELECT id, requested_by, expires_at <= NOW(), confirmed_at
+			FROM tenant_deletion_requests
+			WHERE tenant_id = $1 AND token_hash = $2
+			FOR UPDATE
+		`, tenantID, hashDeletionToken(token)).Scan(&requestID, &requestedBy, &expired, &confirmedAt)
+		switch {
+		case err == sql.ErrNoRows:
+			return apperr.Permission("invalid confirmation token for tenant %s", tenantID)
+		case err != nil:
+			return err
+		case requestedBy != employeeID:
+			return apperr.Permission("the confirmation token was issued to another admin")
+		case confirmedAt != nil:
+			return apperr.Conflict("the confirmation token has already been used")
+		case expired:
+			return apperr.Conflict("the confirmation token has expired; request a new one")
+		}
+
+		if _, err := tx.Exec(`UPDATE tenant_deletion_requests SET confirmed_at = NOW() WHERE id = $1`, requestID); err != nil {
+			return err
+		}
+
+		err = tx.QueryRow(`
+			UPDATE tenant_connections SET is_active = false, updated_at = NOW()
+			WHERE tenant_id = $1 AND is_active = true
+			RETURNING updated_at
+		`, tenantID).Scan(&deactivation.DeactivatedAt)
+		if err == sql.ErrNoRows {
+			return apperr.Conflict("tenant %s is already inactive", tenantID)
+		}
+		if err != nil {
+			return err
+		}
+
+		result, err := tx.Exec(`
+			UPDATE portal_sessions SET ended_at = NOW(), end_reason = $1
+			WHERE tenant_id = $2 AND ended_at IS NULL
+		`, types.PortalSessionEndTenantDeactivated, tenantID)
+		if err != nil {
+			return err
+		}
+		ended, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		deactivation.PortalSessionsEnded = int(ended)
+		return nil
+	})
+	if err != nil {
+		logger.Errorf("Failed to deactivate tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	deactivation.ConnectionEvicted = s.EvictTenantConnection(tenantID)
+	logger.Warningf("Tenant %s deactivated by %s; %d portal sessions ended", tenantID, employeeID, deactivation.PortalSessionsEnded)
+	return deactivation, nil
+}
+
+// hashDeletionToken returns the stored form of a deletion confirmation token
+func hashDeletionToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,91 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync"
+	"welltaxpro/src/internal/tracing"
+	"welltaxpro/src/internal/types"
+
+	"github.com/lib/pq"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// tenantDBAuthConstraint requires the credentials each tenant database auth type needs
+const tenantDBAuthConstraint = "chk_tenant_connections_db_auth"
+
+// tenantResidencyConstraint keeps a tenant with a data residency in its storage and database regions
+const tenantResidencyConstraint = "chk_tenant_connections_residency"
+
+// cloudSQLLoginScope is the OAuth scope Cloud SQL accepts for IAM database logins
+const cloudSQLLoginScope = "https://www.googleapis.com/auth/sqlservice.login"
+
+// Cloud SQL login tokens for the process's service account (ADC), created on first use and
+// shared by every IAM tenant; the token source refreshes them before they expire
+var (
+	cloudSQLTokensOnce sync.Once
+	cloudSQLTokens     oauth2.TokenSource
+	cloudSQLTokensErr  error
+)
+
+func cloudSQLTokenSource() (oauth2.TokenSource, error) {
+	cloudSQLTokensOnce.Do(func() {
+		cloudSQLTokens, cloudSQLTokensErr = google.DefaultTokenSource(context.Background(), cloudSQLLoginScope)
+	})
+	return cloudSQLTokens, cloudSQLTokensErr
+}
+
+// openTenantDB opens a connection pool for a tenant database with its auth type. Its statements
+// are traced with the tenant's ID.
+func openTenantDB(tc *types.TenantConnection) (*sql.DB, error) {
+	if !tc.UsesIAMAuth() {
+		connector, err := pq.NewConnector(tc.GetConnectionString())
+		if err != nil {
+			return nil, err
+		}
+		return sql.OpenDB(tracing.WrapConnector(connector, tracing.Tenant(tc.TenantID))), nil
+	}
+
+	tokens, err := cloudSQLTokenSource()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load credentials for Cloud SQL IAM login: %w", err)
+	}
+	connector := &iamConnector{dsn: tc.GetConnectionString(), tokens: tokens}
+	return sql.OpenDB(tracing.WrapConnector(connector, tracing.Tenant(tc.TenantID))), nil
+}
+
+// iamConnector dials a tenant database with a current IAM token as the password. Tokens last
+// an hour, so each new connection gets its own rather than the pool keeping the first.
+type iamConnector struct {
+	dsn    string // Connection string without a password
+	tokens oauth2.TokenSource
+}
+
+// Connect implements driver.Connector
+func (c *iamConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	token, err := c.tokens.Token()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Cloud SQL IAM token: %w", err)
+	}
+	// Access tokens only hold URL-safe characters, so they need no escaping
+	connector, err := pq.NewConnector(c.dsn + " password='" + token.AccessToken + "'")
+	if err != nil {
+		return nil, err
+	}
+	return connector.Connect(ctx)
+}
+
+// Driver implements driver.Connector
+func (c *iamConnector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// isConstraintViolation reports whether err is a violation of the named check constraint
+func isConstraintViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23514" && pqErr.Constraint == constraint
+}
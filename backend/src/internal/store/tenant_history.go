@@ -0,0 +1,208 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+// tenantConfigField is a tenant_connections column tracked in configuration history
+type tenantConfigField struct {
+	field  string // TenantConnection JSON field name
+	column string
+	secret bool // Hidden from TenantConnection JSON; history records that it changed, not its values
+}
+
+// tenantConfigFields are the tracked columns, in the order changes are listed
+var tenantConfigFields = []tenantConfigField{
+	{field: "tenantName", column: "tenant_name"},
+	{field: "isActive", column: "is_active"},
+	{field: "dbHost", column: "db_host"},
+	{field: "dbPort", column: "db_port"},
+	{field: "dbUser", column: "db_user"},
+	{field: "dbPassword", column: "db_password", secret: true},
+	{field: "dbName", column: "db_name"},
+	{field: "dbSslMode", column: "db_sslmode"},
+	{field: "dbAuthType", column: "db_auth_type"},
+	{field: "dbInstanceConnectionName", column: "db_instance_connection_name"},
+	{field: "statementTimeoutMs", column: "statement_timeout_ms"},
+	{field: "schemaPrefix", column: "schema_prefix"},
+	{field: "adapterType", column: "adapter_type"},
+	{field: "idVersion", column: "id_version"},
+	{field: "storageProvider", column: "storage_provider"},
+	{field: "storageBucket", column: "storage_bucket"},
+	{field: "storageRegion", column: "storage_region"},
+	{field: "dbRegion", column: "db_region"},
+	{field: "dataResidency", column: "data_residency"},
+	{field: "storageCredentialsSecret", column: "storage_credentials_secret", secret: true},
+	{field: "storageCredentialsPath", column: "storage_credentials_path", secret: true},
+	{field: "storageUploadCredentialsSecret", column: "storage_upload_credentials_secret", secret: true},
+	{field: "storageUploadCredentialsPath", column: "storage_upload_credentials_path", secret: true},
+	{field: "storageReadCredentialsSecret", column: "storage_read_credentials_secret", secret: true},
+	{field: "storageReadCredentialsPath", column: "storage_read_credentials_path", secret: true},
+	{field: "storageDeleteCredentialsSecret", column: "storage_delete_credentials_secret", secret: true},
+	{field: "storageDeleteCredentialsPath", column: "storage_delete_credentials_path", secret: true},
+	{field: "docusignIntegrationKey", column: "docusign_integration_key"},
+	{field: "docusignClientId", column: "docusign_client_id"},
+	{field: "docusignPrivateKeySecret", column: "docusign_private_key_secret", secret: true},
+	{field: "docusignApiUrl", column: "docusign_api_url"},
+	{field: "docusignConnectSecret", column: "docusign_connect_secret", secret: true},
+	{field: "stripeWebhookSecret", column: "stripe_webhook_secret", secret: true},
+	{field: "fraudRules", column: "fraud_rules"},
+	{field: "commissionSla", column: "commission_sla"},
+	{field: "portalSecurityPolicy", column: "portal_security_policy"},
+	{field: "smsSettings", column: "sms_settings"},
+	{field: "filingStatusTexts", column: "filing_status_texts"},
+	{field: "affiliateTokenPolicy", column: "affiliate_token_policy"},
+	{field: "notes", column: "notes"},
+}
+
+// ChangeTenantConfig runs change against tenant_connections in a transaction and records the
+// fields it changed in the tenant's configuration history, attributed to employeeID.
+// For TenantConfigActionCreate the tenant must not exist yet; otherwise a missing tenant is NotFound.
+func (s *Store) ChangeTenantConfig(tenantID, action string, employeeID *uuid.UUID, change func(tx *sql.Tx) error) error {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		logger.Errorf("Failed to begin tenant config change for %s: %v", tenantID, err)
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := tenantConfigSnapshot(tx, tenantID)
+	if err != nil {
+		return err
+	}
+	if before == nil && action != types.TenantConfigActionCreate {
+		return apperr.NotFound("tenant not found: %s", tenantID)
+	}
+
+	if err := change(tx); err != nil {
+		if isConstraintViolation(err, tenantDBAuthConstraint) {
+			return apperr.Validation("dbPassword is required for password auth, and dbInstanceConnectionName for connector auth")
+		}
+		if isConstraintViolation(err, tenantResidencyConstraint) {
+			return apperr.Validation("dataResidency needs a storageRegion and dbRegion, and dbInstanceConnectionName must be in dbRegion")
+		}
+		return err
+	}
+
+	after, err := tenantConfigSnapshot(tx, tenantID)
+	if err != nil {
+		return err
+	}
+
+	changes := diffTenantConfig(before, after)
+	if len(changes) > 0 {
+		data, err := json.Marshal(changes)
+		if err != nil {
+			return fmt.Errorf("failed to encode tenant config changes: %w", err)
+		}
+		_, err = tx.Exec(`
+			INSERT INTO tenant_config_history (tenant_id, action, changes, changed_by)
+			VALUES ($1, $2, $3, $4)
+		`, tenantID, action, string(data), employeeID)
+		if err != nil {
+			logger.Errorf("Failed to record tenant config change for %s: %v", tenantID, err)
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		logger.Errorf("Failed to commit tenant config change for %s: %v", tenantID, err)
+		return err
+	}
+
+	logger.Infof("Recorded %s of tenant %s config (%d fields changed)", action, tenantID, len(changes))
+	return nil
+}
+
+// tenantConfigSnapshot reads a tenant's tracked columns as text, locking the row until tx ends.
+// It returns nil when the tenant does not exist.
+func tenantConfigSnapshot(tx *sql.Tx, tenantID string) ([]sql.NullString, error) {
+	columns := make([]string, len(tenantConfigFields))
+	for i, f := range tenantConfigFields {
+		columns[i] = f.column + "::text"
+	}
+
+	values := make([]sql.NullString, len(tenantConfigFields))
+	dest := make([]interface{}, len(values))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	query := fmt.Sprintf(`SELECT %s FROM tenant_connections WHERE tenant_id = $1 FOR UPDATE`, strings.Join(columns, ", "))
+	err := tx.QueryRow(query, tenantID).Scan(dest...)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		logger.Errorf("Failed to read tenant %s config: %v", tenantID, err)
+		return nil, err
+	}
+	return values, nil
+}
+
+// diffTenantConfig lists the tracked fields that differ between two snapshots; nil means no row
+func diffTenantConfig(before, after []sql.NullString) []types.TenantConfigChange {
+	value := func(snapshot []sql.NullString, i int) *string {
+		if snapshot == nil || !snapshot[i].Valid {
+			return nil
+		}
+		return &snapshot[i].String
+	}
+
+	changes := []types.TenantConfigChange{}
+	for i, f := range tenantConfigFields {
+		b, a := value(before, i), value(after, i)
+		if (b == nil && a == nil) || (b != nil && a != nil && *b == *a) {
+			continue
+		}
+
+		change := types.TenantConfigChange{Field: f.field, Before: b, After: a}
+		if f.secret {
+			change = types.TenantConfigChange{Field: f.field, Redacted: true}
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
+
+// GetTenantConfigHistory returns a tenant's configuration changes, newest first
+func (s *Store) GetTenantConfigHistory(tenantID string, limit int) ([]*types.TenantConfigHistoryEntry, error) {
+	rows, err := s.DB.Query(`
+		SELECT h.id, h.tenant_id, h.action, h.changes, h.changed_by, e.email, h.changed_at
+		FROM tenant_config_history h
+		LEFT JOIN employees e ON e.id = h.changed_by
+		WHERE h.tenant_id = $1
+		ORDER BY h.changed_at DESC
+		LIMIT $2
+	`, tenantID, limit)
+	if err != nil {
+		logger.Errorf("Failed to get config history for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*types.TenantConfigHistoryEntry{}
+	for rows.Next() {
+		entry := &types.TenantConfigHistoryEntry{}
+		var changes []byte
+		if err := rows.Scan(&entry.ID, &entry.TenantID, &entry.Action, &changes, &entry.ChangedBy, &entry.ChangedByEmail, &entry.ChangedAt); err != nil {
+			logger.Errorf("Failed to scan tenant config history: %v", err)
+			return nil, err
+		}
+		if err := json.Unmarshal(changes, &entry.Changes); err != nil {
+			logger.Errorf("Failed to decode tenant config history %s: %v", entry.ID, err)
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, rows.Err()
+}
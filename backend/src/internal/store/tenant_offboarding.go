@@ -0,0 +1,485 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+const tenantOffboardingColumns = `id, tenant_id, status, reason, started_by, started_at, export_requested_at,
+	export_completed_at, export_path, export_error, retention_until, updated_at`
+
+// StartTenantOffboarding opens an offboarding for an active tenant. Nothing changes for the
+// tenant until its steps are run.
+func (s *Store) StartTenantOffboarding(tenantID string, reason *string, startedBy uuid.UUID) (*types.TenantOffboarding, error) {
+	var active bool
+	err := s.DB.QueryRow(`SELECT is_active FROM tenant_connections WHERE tenant_id = $1`, tenantID).Scan(&active)
+	if err == sql.ErrNoRows || (err == nil && !active) {
+		return nil, apperr.NotFound("active tenant not found: %s", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to check tenant %s before offboarding: %v", tenantID, err)
+		return nil, err
+	}
+
+	row := s.DB.QueryRow(`
+		INSERT INTO tenant_offboardings (tenant_id, reason, started_by)
+		VALUES ($1, $2, $3)
+		RETURNING `+tenantOffboardingColumns,
+		tenantID, reason, startedBy)
+	o, err := scanTenantOffboarding(row)
+	if isUniqueViolation(err) {
+		return nil, apperr.Conflict("tenant %s is already being offboarded", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to start offboarding of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	o.Steps = []*types.TenantOffboardingStep{}
+	logger.Warningf("Offboarding of tenant %s started by %s", tenantID, startedBy)
+	return o, nil
+}
+
+// GetTenantOffboarding returns the tenant's most recent offboarding with its steps
+func (s *Store) GetTenantOffboarding(tenantID string) (*types.TenantOffboarding, error) {
+	row := s.DB.QueryRow(`
+		SELECT `+tenantOffboardingColumns+`
+		FROM tenant_offboardings
+		WHERE tenant_id = $1
+		ORDER BY started_at DESC
+		LIMIT 1
+	`, tenantID)
+	o, err := scanTenantOffboarding(row)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("tenant %s has no offboarding", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get offboarding of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	if o.Steps, err = s.getTenantOffboardingSteps(s.DB, o.ID); err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// GetTenantOffboardingReport returns the report of the tenant's most recent completed offboarding
+func (s *Store) GetTenantOffboardingReport(tenantID string) (*types.TenantOffboardingReport, error) {
+	var data []byte
+	err := s.DB.QueryRow(`
+		SELECT report FROM tenant_offboardings
+		WHERE tenant_id = $1 AND status = 'COMPLETED'
+		ORDER BY started_at DESC
+		LIMIT 1
+	`, tenantID).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("tenant %s has no completed offboarding", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get offboarding report of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	report := &types.TenantOffboardingReport{}
+	if err := json.Unmarshal(data, report); err != nil {
+		logger.Errorf("Failed to decode offboarding report of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	return report, nil
+}
+
+// IsTenantPortalBlocked reports whether an offboarding or deactivation has closed the tenant's
+// portal. Unknown tenants are not blocked; their requests fail on the missing tenant.
+func (s *Store) IsTenantPortalBlocked(tenantID string) (bool, error) {
+	var blocked bool
+	err := s.DB.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM tenant_offboardings
+			WHERE tenant_id = $1 AND status NOT IN ('STARTED', 'CANCELLED')
+		) OR EXISTS (
+			SELECT 1 FROM tenant_connections
+			WHERE tenant_id = $1 AND is_active = false
+		)
+	`, tenantID).Scan(&blocked)
+	if err != nil {
+		logger.Errorf("Failed to check portal block of tenant %s: %v", tenantID, err)
+		return false, err
+	}
+	return blocked, nil
+}
+
+// BlockTenantPortal runs the block_portal step; portal users are refused from then on
+func (s *Store) BlockTenantPortal(tenantID string, performedBy uuid.UUID) (*types.TenantOffboarding, error) {
+	return s.advanceTenantOffboarding(tenantID, types.OffboardingStepBlockPortal, performedBy, func(tx *sql.Tx, o *types.TenantOffboarding) (interface{}, error) {
+		var portalUsers int
+		err := tx.QueryRow(`SELECT COUNT(*) FROM tenant_users WHERE tenant_id = $1 AND is_active = true`, tenantID).Scan(&portalUsers)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"portalUsers": portalUsers}, nil
+	})
+}
+
+// ScheduleTenantExport runs the schedule_export step; the offboarding job writes the export
+func (s *Store) ScheduleTenantExport(tenantID string, performedBy uuid.UUID) (*types.TenantOffboarding, error) {
+	return s.advanceTenantOffboarding(tenantID, types.OffboardingStepScheduleExport, performedBy, func(tx *sql.Tx, o *types.TenantOffboarding) (interface{}, error) {
+		_, err := tx.Exec(`UPDATE tenant_offboardings SET export_requested_at = NOW() WHERE id = $1`, o.ID)
+		return nil, err
+	})
+}
+
+// RevokeTenantAccess runs the revoke_access step: every employee's access to the tenant is
+// deactivated and open break-glass grants are ended
+func (s *Store) RevokeTenantAccess(tenantID string, performedBy uuid.UUID) (*types.TenantOffboarding, error) {
+	return s.advanceTenantOffboarding(tenantID, types.OffboardingStepRevokeAccess, performedBy, func(tx *sql.Tx, o *types.TenantOffboarding) (interface{}, error) {
+		rows, err := tx.Query(`
+			UPDATE employee_tenant_access eta
+			SET is_active = false, updated_at = NOW()
+			FROM employees e
+			WHERE e.id = eta.employee_id AND eta.tenant_id = $1 AND eta.is_active = true
+			RETURNING e.email
+		`, tenantID)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		revoked := []string{}
+		for rows.Next() {
+			var email string
+			if err := rows.Scan(&email); err != nil {
+				return nil, err
+			}
+			revoked = append(revoked, email)
+		}
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		result, err := tx.Exec(`
+			UPDATE break_glass_grants
+			SET revoked_at = NOW(), revoked_by = $2
+			WHERE tenant_id = $1 AND revoked_at IS NULL
+		`, tenantID, performedBy)
+		if err != nil {
+			return nil, err
+		}
+		grants, _ := result.RowsAffected()
+
+		return map[string]interface{}{"revokedAccess": revoked, "breakGlassGrantsRevoked": grants}, nil
+	})
+}
+
+// MarkTenantCachesPurged runs the purge_caches step once the caller has deactivated the tenant
+// and dropped what this process cached for it. The data export must have been written.
+func (s *Store) MarkTenantCachesPurged(tenantID string, performedBy uuid.UUID, details interface{}) (*types.TenantOffboarding, error) {
+	return s.advanceTenantOffboarding(tenantID, types.OffboardingStepPurgeCaches, performedBy, func(tx *sql.Tx, o *types.TenantOffboarding) (interface{}, error) {
+		if o.ExportCompletedAt == nil {
+			return nil, apperr.Conflict("the data export of tenant %s has not been written yet", tenantID)
+		}
+		return details, nil
+	})
+}
+
+// SetTenantRetention runs the set_retention step: the tenant bucket is kept until retentionUntil
+func (s *Store) SetTenantRetention(tenantID string, performedBy uuid.UUID, retentionUntil time.Time) (*types.TenantOffboarding, error) {
+	return s.advanceTenantOffboarding(tenantID, types.OffboardingStepSetRetention, performedBy, func(tx *sql.Tx, o *types.TenantOffboarding) (interface{}, error) {
+		_, err := tx.Exec(`UPDATE tenant_offboardings SET retention_until = $2 WHERE id = $1`, o.ID, retentionUntil)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]interface{}{"retentionUntil": retentionUntil}, nil
+	})
+}
+
+// CompleteTenantOffboarding runs the complete step and stores the offboarding report
+func (s *Store) CompleteTenantOffboarding(tenantID string, performedBy uuid.UUID) (*types.TenantOffboarding, error) {
+	return s.advanceTenantOffboarding(tenantID, types.OffboardingStepComplete, performedBy, func(tx *sql.Tx, o *types.TenantOffboarding) (interface{}, error) {
+		// The tenant is inactive by now, so its name is read directly
+		var tenantName string
+		if err := tx.QueryRow(`SELECT tenant_name FROM tenant_connections WHERE tenant_id = $1`, tenantID).Scan(&tenantName); err != nil {
+			return nil, err
+		}
+		report := &types.TenantOffboardingReport{
+			OffboardingID:     o.ID,
+			TenantID:          o.TenantID,
+			TenantName:        tenantName,
+			Reason:            o.Reason,
+			StartedBy:         o.StartedBy,
+			StartedAt:         o.StartedAt,
+			CompletedAt:       time.Now().UTC(),
+			ExportPath:        o.ExportPath,
+			ExportCompletedAt: o.ExportCompletedAt,
+			RetentionUntil:    o.RetentionUntil,
+			Steps:             o.Steps,
+		}
+		data, err := json.Marshal(report)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := tx.Exec(`UPDATE tenant_offboardings SET report = $2 WHERE id = $1`, o.ID, data); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	})
+}
+
+// CancelTenantOffboarding calls off an offboarding that has not yet revoked employee access,
+// which reopens the portal
+func (s *Store) CancelTenantOffboarding(tenantID string, performedBy uuid.UUID) (*types.TenantOffboarding, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	o, err := lockOpenTenantOffboarding(tx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	if !o.Cancellable() {
+		return nil, apperr.Conflict("offboarding of tenant %s is past %s and can no longer be cancelled", tenantID, types.OffboardingStepScheduleExport)
+	}
+
+	if err := recordTenantOffboardingStep(tx, o.ID, types.OffboardingStepCancel, performedBy, types.OffboardingStatusCancelled, map[string]interface{}{"previousStatus": o.Status}); err != nil {
+		logger.Errorf("Failed to cancel offboarding of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Warningf("Offboarding of tenant %s cancelled by %s", tenantID, performedBy)
+	return s.GetTenantOffboarding(tenantID)
+}
+
+// advanceTenantOffboarding runs step against the tenant's open offboarding. The step must be
+// the next one for its status; apply makes the step's changes in the same transaction and
+// returns the details recorded with it.
+func (s *Store) advanceTenantOffboarding(tenantID, step string, performedBy uuid.UUID, apply func(tx *sql.Tx, o *types.TenantOffboarding) (interface{}, error)) (*types.TenantOffboarding, error) {
+	tx, err := s.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	o, err := lockOpenTenantOffboarding(tx, tenantID)
+	if err != nil {
+		return nil, err
+	}
+	next, status, _ := types.NextOffboardingStep(o.Status)
+	if next != step {
+		return nil, apperr.Conflict("offboarding of tenant %s is %s; the next step is %s", tenantID, o.Status, next)
+	}
+	if o.Steps, err = s.getTenantOffboardingSteps(tx, o.ID); err != nil {
+		return nil, err
+	}
+
+	details, err := apply(tx, o)
+	if err != nil {
+		logger.Errorf("Offboarding step %s failed for tenant %s: %v", step, tenantID, err)
+		return nil, err
+	}
+	if err := recordTenantOffboardingStep(tx, o.ID, step, performedBy, status, details); err != nil {
+		logger.Errorf("Failed to record offboarding step %s for tenant %s: %v", step, tenantID, err)
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	logger.Warningf("Offboarding of tenant %s: %s done by %s", tenantID, step, performedBy)
+	return s.GetTenantOffboarding(tenantID)
+}
+
+// lockOpenTenantOffboarding locks the tenant's offboarding in progress
+func lockOpenTenantOffboarding(tx *sql.Tx, tenantID string) (*types.TenantOffboarding, error) {
+	row := tx.QueryRow(`
+		SELECT `+tenantOffboardingColumns+`
+		FROM tenant_offboardings
+		WHERE tenant_id = $1 AND status NOT IN ('COMPLETED', 'CANCELLED')
+		FOR UPDATE
+	`, tenantID)
+	o, err := scanTenantOffboarding(row)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("tenant %s has no offboarding in progress", tenantID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to lock offboarding of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	return o, nil
+}
+
+// recordTenantOffboardingStep records a step and moves the offboarding to status
+func recordTenantOffboardingStep(tx *sql.Tx, offboardingID uuid.UUID, step string, performedBy uuid.UUID, status string, details interface{}) error {
+	var detailsJSON []byte
+	if details != nil {
+		data, err := json.Marshal(details)
+		if err != nil {
+			return fmt.Errorf("failed to encode step details: %w", err)
+		}
+		detailsJSON = data
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO tenant_offboarding_steps (offboarding_id, step, performed_by, details)
+		VALUES ($1, $2, $3, $4)
+	`, offboardingID, step, performedBy, detailsJSON)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(`UPDATE tenant_offboardings SET status = $2, updated_at = NOW() WHERE id = $1`, offboardingID, status)
+	return err
+}
+
+// GetDueOffboardingExports lists offboardings whose data export is scheduled but not yet written
+func (s *Store) GetDueOffboardingExports() ([]*types.TenantOffboarding, error) {
+	if err := s.requireScope(types.ScopeOffboarding); err != nil {
+		return nil, err
+	}
+
+	return s.queryTenantOffboardings(`
+		SELECT ` + tenantOffboardingColumns + `
+		FROM tenant_offboardings
+		WHERE export_requested_at IS NOT NULL AND export_completed_at IS NULL AND status <> 'CANCELLED'
+		ORDER BY export_requested_at
+	`)
+}
+
+// RecordOffboardingExport records a data export attempt: the object written, or the error.
+// firstFailure is true when exportErr is the first failure since the last success.
+func (s *Store) RecordOffboardingExport(id uuid.UUID, exportPath string, exportErr error) (firstFailure bool, err error) {
+	if err := s.requireScope(types.ScopeOffboarding); err != nil {
+		return false, err
+	}
+
+	if exportErr == nil {
+		_, err = s.DB.Exec(`
+			UPDATE tenant_offboardings
+			SET export_completed_at = NOW(), export_path = $2, export_error = NULL, updated_at = NOW()
+			WHERE id = $1
+		`, id, exportPath)
+		if err != nil {
+			logger.Errorf("Failed to record export of offboarding %s: %v", id, err)
+		}
+		return false, err
+	}
+
+	// Only the exclusive offboarding job records exports, so the read and write cannot race
+	var previous sql.NullString
+	if err := s.DB.QueryRow(`SELECT export_error FROM tenant_offboardings WHERE id = $1`, id).Scan(&previous); err != nil {
+		logger.Errorf("Failed to read export state of offboarding %s: %v", id, err)
+		return false, err
+	}
+	_, err = s.DB.Exec(`
+		UPDATE tenant_offboardings SET export_error = $2, updated_at = NOW() WHERE id = $1
+	`, id, exportErr.Error())
+	if err != nil {
+		logger.Errorf("Failed to record export failure of offboarding %s: %v", id, err)
+		return false, err
+	}
+	return !previous.Valid, nil
+}
+
+// GetOffboardingsPastRetention lists offboardings whose bucket retention ended and whose admins
+// have not been told yet
+func (s *Store) GetOffboardingsPastRetention() ([]*types.TenantOffboarding, error) {
+	if err := s.requireScope(types.ScopeOffboarding); err != nil {
+		return nil, err
+	}
+
+	return s.queryTenantOffboardings(`
+		SELECT ` + tenantOffboardingColumns + `
+		FROM tenant_offboardings
+		WHERE retention_until <= NOW() AND retention_alerted_at IS NULL AND status <> 'CANCELLED'
+		ORDER BY retention_until
+	`)
+}
+
+// MarkOffboardingRetentionAlerted records that admins were told the retention period ended
+func (s *Store) MarkOffboardingRetentionAlerted(id uuid.UUID) error {
+	if err := s.requireScope(types.ScopeOffboarding); err != nil {
+		return err
+	}
+
+	if _, err := s.DB.Exec(`UPDATE tenant_offboardings SET retention_alerted_at = NOW() WHERE id = $1`, id); err != nil {
+		logger.Errorf("Failed to mark retention alert of offboarding %s: %v", id, err)
+		return err
+	}
+	return nil
+}
+
+// getTenantOffboardingSteps lists an offboarding's steps in the order they ran
+func (s *Store) getTenantOffboardingSteps(q interface {
+	Query(string, ...interface{}) (*sql.Rows, error)
+}, offboardingID uuid.UUID) ([]*types.TenantOffboardingStep, error) {
+	rows, err := q.Query(`
+		SELECT step, performed_by, performed_at, details
+		FROM tenant_offboarding_steps
+		WHERE offboarding_id = $1
+		ORDER BY performed_at, id
+	`, offboardingID)
+	if err != nil {
+		logger.Errorf("Failed to get steps of offboarding %s: %v", offboardingID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	steps := []*types.TenantOffboardingStep{}
+	for rows.Next() {
+		step := &types.TenantOffboardingStep{}
+		var details []byte
+		if err := rows.Scan(&step.Step, &step.PerformedBy, &step.PerformedAt, &details); err != nil {
+			logger.Errorf("Failed to scan offboarding step: %v", err)
+			return nil, err
+		}
+		if len(details) > 0 {
+			step.Details = json.RawMessage(details)
+		}
+		steps = append(steps, step)
+	}
+	return steps, rows.Err()
+}
+
+// queryTenantOffboardings runs a query selecting tenantOffboardingColumns
+func (s *Store) queryTenantOffboardings(query string, args ...interface{}) ([]*types.TenantOffboarding, error) {
+	rows, err := s.DB.Query(query, args...)
+	if err != nil {
+		logger.Errorf("Failed to query tenant offboardings: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	offboardings := []*types.TenantOffboarding{}
+	for rows.Next() {
+		o, err := scanTenantOffboarding(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan tenant offboarding: %v", err)
+			return nil, err
+		}
+		offboardings = append(offboardings, o)
+	}
+	return offboardings, rows.Err()
+}
+
+func scanTenantOffboarding(row interface{ Scan(...interface{}) error }) (*types.TenantOffboarding, error) {
+	o := &types.TenantOffboarding{}
+	err := row.Scan(&o.ID, &o.TenantID, &o.Status, &o.Reason, &o.StartedBy, &o.StartedAt, &o.ExportRequestedAt,
+		&o.ExportCompletedAt, &o.ExportPath, &o.ExportError, &o.RetentionUntil, &o.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	if step, _, ok := types.NextOffboardingStep(o.Status); ok {
+		o.NextStep = &step
+	}
+	return o, nil
+}
@@ -0,0 +1,136 @@
+package store
+
+import (
+	"time"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// tenantProbeRetention is how long tenant database probe history is kept
+const tenantProbeRetention = 30 * 24 * time.Hour
+
+// tenantUptimeWindows are the periods uptime is reported over
+var tenantUptimeWindows = []struct {
+	name   string
+	period time.Duration
+}{
+	{"24h", 24 * time.Hour},
+	{"7d", 7 * 24 * time.Hour},
+	{"30d", tenantProbeRetention},
+}
+
+// RecordTenantProbe stores the outcome of a scheduled tenant database ping and prunes the
+// tenant's expired probe history. It returns how many probes in a row had failed before this one.
+func (s *Store) RecordTenantProbe(health *types.TenantConnectionHealth) (int, error) {
+	if err := s.requireScope(types.ScopeJobsWrite); err != nil {
+		return 0, err
+	}
+
+	previousFailures, err := s.countTenantProbeFailures(health.TenantID)
+	if err != nil {
+		return 0, err
+	}
+
+	var errMsg *string
+	if !health.Healthy {
+		errMsg = &health.Error
+	}
+	_, err = s.DB.Exec(`
+		INSERT INTO tenant_connection_probes (tenant_id, checked_at, healthy, latency_ms, error)
+		VALUES ($1, $2, $3, $4, $5)
+	`, health.TenantID, health.CheckedAt, health.Healthy, health.LatencyMs, errMsg)
+	if err != nil {
+		logger.Errorf("Failed to record probe of tenant %s: %v", health.TenantID, err)
+		return 0, err
+	}
+
+	if _, err := s.DB.Exec(`DELETE FROM tenant_connection_probes WHERE tenant_id = $1 AND checked_at < $2`,
+		health.TenantID, time.Now().Add(-tenantProbeRetention)); err != nil {
+		logger.Errorf("Failed to prune probe history of tenant %s: %v", health.TenantID, err)
+	}
+	return previousFailures, nil
+}
+
+// countTenantProbeFailures counts a tenant's failed probes since its last healthy one
+func (s *Store) countTenantProbeFailures(tenantID string) (int, error) {
+	var failures int
+	err := s.DB.QueryRow(`
+		SELECT COUNT(*)
+		FROM tenant_connection_probes
+		WHERE tenant_id = $1 AND NOT healthy
+		  AND checked_at > COALESCE(
+		      (SELECT MAX(checked_at) FROM tenant_connection_probes WHERE tenant_id = $1 AND healthy),
+		      '-infinity')
+	`, tenantID).Scan(&failures)
+	if err != nil {
+		logger.Errorf("Failed to count failed probes of tenant %s: %v", tenantID, err)
+		return 0, err
+	}
+	return failures, nil
+}
+
+// GetTenantUptime summarizes a tenant's probe history with up to recent of its latest probes
+func (s *Store) GetTenantUptime(tenantID string, recent int) (*types.TenantUptime, error) {
+	uptime := &types.TenantUptime{
+		Windows: make([]*types.TenantUptimeWindow, 0, len(tenantUptimeWindows)),
+		Recent:  []*types.TenantConnectionProbe{},
+	}
+
+	now := time.Now()
+	for _, w := range tenantUptimeWindows {
+		window := &types.TenantUptimeWindow{Window: w.name}
+		err := s.DB.QueryRow(`
+			SELECT COUNT(*), COUNT(*) FILTER (WHERE healthy)
+			FROM tenant_connection_probes
+			WHERE tenant_id = $1 AND checked_at >= $2
+		`, tenantID, now.Add(-w.period)).Scan(&window.Probes, &window.HealthyProbes)
+		if err != nil {
+			logger.Errorf("Failed to get %s uptime of tenant %s: %v", w.name, tenantID, err)
+			return nil, err
+		}
+		if window.Probes > 0 {
+			pct := float64(window.HealthyProbes) * 100 / float64(window.Probes)
+			window.UptimePct = &pct
+		}
+		uptime.Windows = append(uptime.Windows, window)
+	}
+
+	failures, err := s.countTenantProbeFailures(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	uptime.ConsecutiveFailures = failures
+
+	err = s.DB.QueryRow(`
+		SELECT MAX(checked_at) FROM tenant_connection_probes WHERE tenant_id = $1 AND healthy
+	`, tenantID).Scan(&uptime.LastHealthyAt)
+	if err != nil {
+		logger.Errorf("Failed to get last healthy probe of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT checked_at, healthy, latency_ms, error
+		FROM tenant_connection_probes
+		WHERE tenant_id = $1
+		ORDER BY checked_at DESC
+		LIMIT $2
+	`, tenantID, recent)
+	if err != nil {
+		logger.Errorf("Failed to get recent probes of tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		probe := &types.TenantConnectionProbe{}
+		if err := rows.Scan(&probe.CheckedAt, &probe.Healthy, &probe.LatencyMs, &probe.Error); err != nil {
+			logger.Errorf("Failed to scan tenant probe: %v", err)
+			return nil, err
+		}
+		uptime.Recent = append(uptime.Recent, probe)
+	}
+
+	return uptime, rows.Err()
+}
@@ -0,0 +1,145 @@
+package store
+
+import (
+	"context"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/types"
+
+	"github.com/google/logger"
+)
+
+// CreateMaintenanceWindow schedules a maintenance window for a tenant
+func (s *Store) CreateMaintenanceWindow(m *types.MaintenanceWindow) error {
+	err := s.DB.QueryRow(`
+		INSERT INTO tenant_maintenance_windows (tenant_id, starts_at, ends_at, message, affects_portal, affects_uploads, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`, m.TenantID, m.StartsAt, m.EndsAt, m.Message, m.AffectsPortal, m.AffectsUploads, m.CreatedBy).Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		logger.Errorf("Failed to create maintenance window for tenant %s: %v", m.TenantID, err)
+		return err
+	}
+
+	logger.Infof("Scheduled maintenance for tenant %s from %s to %s", m.TenantID, m.StartsAt, m.EndsAt)
+	return nil
+}
+
+// GetMaintenanceWindows lists a tenant's maintenance windows by start time. With upcomingOnly
+// set, windows that have already ended are left out.
+func (s *Store) GetMaintenanceWindows(tenantID string, upcomingOnly bool) ([]*types.MaintenanceWindow, error) {
+	query := `
+		SELECT id, tenant_id, starts_at, ends_at, message, affects_portal, affects_uploads, created_by, created_at
+		FROM tenant_maintenance_windows
+		WHERE tenant_id = $1
+	`
+	if upcomingOnly {
+		query += " AND ends_at > NOW()"
+	}
+	query += " ORDER BY starts_at"
+
+	rows, err := s.DB.Query(query, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to query maintenance windows for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	var windows []*types.MaintenanceWindow
+	for rows.Next() {
+		m := &types.MaintenanceWindow{}
+		if err := rows.Scan(&m.ID, &m.TenantID, &m.StartsAt, &m.EndsAt, &m.Message, &m.AffectsPortal, &m.AffectsUploads, &m.CreatedBy, &m.CreatedAt); err != nil {
+			logger.Errorf("Failed to scan maintenance window: %v", err)
+			return nil, err
+		}
+		windows = append(windows, m)
+	}
+
+	return windows, rows.Err()
+}
+
+// DeleteMaintenanceWindow removes one of a tenant's maintenance windows
+func (s *Store) DeleteMaintenanceWindow(tenantID string, windowID string) error {
+	result, err := s.DB.Exec(`DELETE FROM tenant_maintenance_windows WHERE id = $1 AND tenant_id = $2`, windowID, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to delete maintenance window %s: %v", windowID, err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return apperr.NotFound("maintenance window not found")
+	}
+	return nil
+}
+
+// GetTenantStatus works out the public status of a tenant's portal and document uploads.
+// The portal is unavailable once an offboarding blocks it or while the tenant database is
+// unreachable, using this process's connection health cache (the database is pinged when the
+// tenant has not been checked yet). Uploads also need a storage bucket. A current maintenance
+// window puts the services it affects in maintenance.
+func (s *Store) GetTenantStatus(ctx context.Context, tenantID string) (*types.TenantStatus, error) {
+	tc, err := s.GetTenantConfig(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	windows, err := s.GetMaintenanceWindows(tenantID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	blocked, err := s.IsTenantPortalBlocked(tenantID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	status := &types.TenantStatus{
+		TenantID:        tc.TenantID,
+		TenantName:      tc.TenantName,
+		Portal:          types.ServiceAvailability{Status: types.ServiceOperational},
+		DocumentUploads: types.ServiceAvailability{Status: types.ServiceOperational},
+		Maintenance:     []*types.MaintenanceWindow{},
+		CheckedAt:       now,
+	}
+
+	health, ok := s.GetConnectionHealth(tenantID)
+	if !ok {
+		health = s.CheckTenantConnection(ctx, tenantID)
+	}
+	if blocked || !health.Healthy {
+		status.Portal.Status = types.ServiceUnavailable
+	}
+	if tc.StorageBucket == "" {
+		status.DocumentUploads.Status = types.ServiceUnavailable
+	}
+
+	for _, m := range windows {
+		// Who scheduled the window stays internal
+		public := *m
+		public.CreatedBy = nil
+		status.Maintenance = append(status.Maintenance, &public)
+
+		if !m.IsActive(now) {
+			continue
+		}
+		message := m.Message
+		if m.AffectsPortal && status.Portal.Status == types.ServiceOperational {
+			status.Portal = types.ServiceAvailability{Status: types.ServiceMaintenance, Message: &message}
+		}
+		if m.AffectsUploads && status.DocumentUploads.Status == types.ServiceOperational {
+			status.DocumentUploads = types.ServiceAvailability{Status: types.ServiceMaintenance, Message: &message}
+		}
+	}
+
+	// Clients upload through the portal, so uploads are never up while it is down
+	if status.Portal.Status != types.ServiceOperational && status.DocumentUploads.Status == types.ServiceOperational {
+		status.DocumentUploads = status.Portal
+	}
+
+	return status, nil
+}
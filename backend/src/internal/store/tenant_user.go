@@ -3,6 +3,8 @@ package store
 import (
 	"database/sql"
 	"fmt"
+	"time"
+	"welltaxpro/src/internal/apperr"
 	"welltaxpro/src/internal/types"
 
 	"github.com/google/logger"
@@ -31,7 +33,7 @@ func (s *Store) GetTenantUserByFirebaseUID(firebaseUID string) (*types.TenantUse
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("tenant user not found for firebase uid: %s", firebaseUID)
+			return nil, apperr.NotFound("tenant user not found for firebase uid: %s", firebaseUID)
 		}
 		logger.Errorf("Failed to get tenant user by firebase uid %s: %v", firebaseUID, err)
 		return nil, err
@@ -64,7 +66,7 @@ func (s *Store) GetTenantUser(id uuid.UUID) (*types.TenantUser, error) {
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("tenant user not found: %s", id.String())
+			return nil, apperr.NotFound("tenant user not found: %s", id.String())
 		}
 		logger.Errorf("Failed to get tenant user %s: %v", id.String(), err)
 		return nil, err
@@ -106,16 +108,24 @@ func (s *Store) CreateTenantUser(tu *types.TenantUser) error {
 	return nil
 }
 
-// GetTenantUsersByTenant retrieves all tenant users for a specific tenant
-func (s *Store) GetTenantUsersByTenant(tenantID string) ([]*types.TenantUser, error) {
+// GetTenantUsersByTenant retrieves a tenant's users matching filter, newest first
+func (s *Store) GetTenantUsersByTenant(tenantID string, filter *types.TenantUserFilter) ([]*types.TenantUser, error) {
 	query := `
-		SELECT id, tenant_id, client_id, firebase_uid, email, is_active, created_at, updated_at
+		SELECT id, tenant_id, client_id, firebase_uid, email, is_active, last_login_at, created_at, updated_at
 		FROM tenant_users
 		WHERE tenant_id = $1
-		ORDER BY created_at DESC
 	`
+	args := []interface{}{tenantID}
+	if filter.ActiveOnly {
+		query += ` AND is_active = true`
+	}
+	if filter.DormantSince != nil {
+		args = append(args, filter.DormantSince.UTC())
+		query += fmt.Sprintf(` AND COALESCE(last_login_at, created_at) < $%d`, len(args))
+	}
+	query += ` ORDER BY created_at DESC`
 
-	rows, err := s.DB.Query(query, tenantID)
+	rows, err := s.DB.Query(query, args...)
 	if err != nil {
 		logger.Errorf("Failed to get tenant users for tenant %s: %v", tenantID, err)
 		return nil, err
@@ -132,6 +142,7 @@ func (s *Store) GetTenantUsersByTenant(tenantID string) ([]*types.TenantUser, er
 			&tu.FirebaseUID,
 			&tu.Email,
 			&tu.IsActive,
+			&tu.LastLoginAt,
 			&tu.CreatedAt,
 			&tu.UpdatedAt,
 		)
@@ -161,9 +172,87 @@ func (s *Store) DeactivateTenantUser(id uuid.UUID) error {
 
 	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return fmt.Errorf("tenant user not found: %s", id.String())
+		return apperr.NotFound("tenant user not found: %s", id.String())
 	}
 
 	logger.Infof("Deactivated tenant user %s", id.String())
 	return nil
 }
+
+// RecordTenantUserLogin records that the tenant user with firebaseUID signed in to the portal
+// at loginAt. It reports false when there is no such user or a later sign-in is already recorded.
+func (s *Store) RecordTenantUserLogin(tenantID, firebaseUID string, loginAt time.Time) (bool, error) {
+	result, err := s.DB.Exec(`
+		UPDATE tenant_users SET last_login_at = $3
+		WHERE tenant_id = $1 AND firebase_uid = $2 AND (last_login_at IS NULL OR last_login_at < $3)
+	`, tenantID, firebaseUID, loginAt.UTC())
+	if err != nil {
+		logger.Errorf("Failed to record portal login of %s in tenant %s: %v", firebaseUID, tenantID, err)
+		return false, err
+	}
+	rowsAffected, _ := result.RowsAffected()
+	return rowsAffected > 0, nil
+}
+
+// GetTenantUserLastSeen returns when the tenant user last loaded their full profile
+func (s *Store) GetTenantUserLastSeen(id uuid.UUID) (*time.Time, error) {
+	var lastSeen *time.Time
+	err := s.DB.QueryRow(`SELECT last_seen_at FROM tenant_users WHERE id = $1`, id).Scan(&lastSeen)
+	if err != nil {
+		logger.Errorf("Failed to get last seen for tenant user %s: %v", id.String(), err)
+		return nil, err
+	}
+	return lastSeen, nil
+}
+
+// MarkTenantUserSeen records that the tenant user has viewed their full profile
+func (s *Store) MarkTenantUserSeen(id uuid.UUID) error {
+	_, err := s.DB.Exec(`UPDATE tenant_users SET last_seen_at = NOW() WHERE id = $1`, id)
+	if err != nil {
+		logger.Errorf("Failed to mark tenant user %s seen: %v", id.String(), err)
+		return err
+	}
+	return nil
+}
+
+// RecordPortalDocumentView records a tenant user viewing one of their documents
+func (s *Store) RecordPortalDocumentView(view *types.PortalDocumentView) error {
+	err := s.DB.QueryRow(`
+		INSERT INTO portal_document_views (tenant_id, tenant_user_id, client_id, document_id, content_type, ip_address, user_agent)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, viewed_at
+	`, view.TenantID, view.TenantUserID, view.ClientID, view.DocumentID, view.ContentType, view.IPAddress, view.UserAgent).Scan(&view.ID, &view.ViewedAt)
+	if err != nil {
+		logger.Errorf("Failed to record view of document %s by tenant user %s: %v", view.DocumentID, view.TenantUserID, err)
+		return err
+	}
+	return nil
+}
+
+// GetPortalDocumentViews returns the documents a client viewed in the portal, newest first
+func (s *Store) GetPortalDocumentViews(tenantID string, clientID uuid.UUID, limit int) ([]*types.PortalDocumentView, error) {
+	rows, err := s.DB.Query(`
+		SELECT id, tenant_id, tenant_user_id, client_id, document_id, content_type, ip_address, user_agent, viewed_at
+		FROM portal_document_views
+		WHERE tenant_id = $1 AND client_id = $2
+		ORDER BY viewed_at DESC
+		LIMIT $3
+	`, tenantID, clientID, limit)
+	if err != nil {
+		logger.Errorf("Failed to get portal document views for client %s: %v", clientID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	views := []*types.PortalDocumentView{}
+	for rows.Next() {
+		v := &types.PortalDocumentView{}
+		if err := rows.Scan(&v.ID, &v.TenantID, &v.TenantUserID, &v.ClientID, &v.DocumentID, &v.ContentType, &v.IPAddress, &v.UserAgent, &v.ViewedAt); err != nil {
+			logger.Errorf("Failed to scan portal document view: %v", err)
+			return nil, err
+		}
+		views = append(views, v)
+	}
+
+	return views, rows.Err()
+}
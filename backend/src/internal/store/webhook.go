@@ -0,0 +1,339 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/crypto"
+	"welltaxpro/src/internal/types"
+	"welltaxpro/src/internal/webhook"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+const webhookColumns = `id, tenant_id, url, description, events, active, created_by, created_at, updated_at`
+
+const webhookDeliveryColumns = `d.id, d.webhook_id, d.tenant_id, d.event, d.payload, d.status, d.attempts, d.next_attempt_at,
+	d.last_status_code, d.last_error, d.created_at, d.delivered_at`
+
+// CreateWebhook registers a webhook for a tenant; the returned webhook carries the plaintext secret
+func (s *Store) CreateWebhook(hook *types.Webhook) (*types.Webhook, error) {
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := crypto.EncryptPassword(secret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encrypt webhook secret: %w", err)
+	}
+
+	created, err := scanWebhook(s.DB.QueryRow(`
+		INSERT INTO webhooks (tenant_id, url, description, events, secret, active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+webhookColumns,
+		hook.TenantID, hook.URL, hook.Description, pq.Array(hook.Events), encrypted, hook.Active, hook.CreatedBy))
+	if err != nil {
+		logger.Errorf("Failed to create webhook for tenant %s: %v", hook.TenantID, err)
+		return nil, err
+	}
+	created.Secret = secret
+
+	logger.Infof("Created webhook %s for tenant %s", created.ID, created.TenantID)
+	return created, nil
+}
+
+// GetWebhooks lists a tenant's webhooks (secrets omitted), newest first
+func (s *Store) GetWebhooks(tenantID string) ([]*types.Webhook, error) {
+	rows, err := s.DB.Query(`
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		WHERE tenant_id = $1
+		ORDER BY created_at DESC
+	`, tenantID)
+	if err != nil {
+		logger.Errorf("Failed to get webhooks for tenant %s: %v", tenantID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	hooks := []*types.Webhook{}
+	for rows.Next() {
+		hook, err := scanWebhook(rows)
+		if err != nil {
+			logger.Errorf("Failed to scan webhook: %v", err)
+			return nil, err
+		}
+		hooks = append(hooks, hook)
+	}
+
+	return hooks, rows.Err()
+}
+
+// GetWebhook returns one of a tenant's webhooks (secret omitted)
+func (s *Store) GetWebhook(tenantID string, webhookID uuid.UUID) (*types.Webhook, error) {
+	hook, err := scanWebhook(s.DB.QueryRow(`
+		SELECT `+webhookColumns+`
+		FROM webhooks
+		WHERE tenant_id = $1 AND id = $2
+	`, tenantID, webhookID))
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("webhook not found: %s", webhookID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to get webhook %s: %v", webhookID, err)
+		return nil, err
+	}
+	return hook, nil
+}
+
+// UpdateWebhook replaces a webhook's URL, description, events and active flag
+func (s *Store) UpdateWebhook(hook *types.Webhook) (*types.Webhook, error) {
+	updated, err := scanWebhook(s.DB.QueryRow(`
+		UPDATE webhooks
+		SET url = $3, description = $4, events = $5, active = $6, updated_at = NOW()
+		WHERE tenant_id = $1 AND id = $2
+		RETURNING `+webhookColumns,
+		hook.TenantID, hook.ID, hook.URL, hook.Description, pq.Array(hook.Events), hook.Active))
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("webhook not found: %s", hook.ID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to update webhook %s: %v", hook.ID, err)
+		return nil, err
+	}
+	return updated, nil
+}
+
+// DeleteWebhook removes a webhook and its delivery log
+func (s *Store) DeleteWebhook(tenantID string, webhookID uuid.UUID) error {
+	result, err := s.DB.Exec(`DELETE FROM webhooks WHERE tenant_id = $1 AND id = $2`, tenantID, webhookID)
+	if err != nil {
+		logger.Errorf("Failed to delete webhook %s: %v", webhookID, err)
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return apperr.NotFound("webhook not found: %s", webhookID)
+	}
+
+	logger.Infof("Deleted webhook %s for tenant %s", webhookID, tenantID)
+	return nil
+}
+
+// scanWebhook reads webhookColumns into a webhook
+func scanWebhook(row interface{ Scan(...interface{}) error }) (*types.Webhook, error) {
+	hook := &types.Webhook{}
+	var events pq.StringArray
+	err := row.Scan(&hook.ID, &hook.TenantID, &hook.URL, &hook.Description, &events, &hook.Active,
+		&hook.CreatedBy, &hook.CreatedAt, &hook.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+	hook.Events = events
+	return hook, nil
+}
+
+// EnqueueWebhookEvent queues a delivery of the event to every active webhook of the tenant
+// subscribed to it. data becomes the payload's data field.
+func (s *Store) EnqueueWebhookEvent(tenantID string, event string, data interface{}) error {
+	payload, err := json.Marshal(&types.WebhookPayload{
+		Event:      event,
+		TenantID:   tenantID,
+		OccurredAt: time.Now().UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode %s webhook payload: %w", event, err)
+	}
+
+	_, err = s.DB.Exec(`
+		INSERT INTO webhook_deliveries (webhook_id, tenant_id, event, payload)
+		SELECT id, tenant_id, $2, $3
+		FROM webhooks
+		WHERE tenant_id = $1 AND active AND $2 = ANY(events)
+	`, tenantID, event, payload)
+	if err != nil {
+		logger.Errorf("Failed to queue %s webhooks for tenant %s: %v", event, tenantID, err)
+		return err
+	}
+	return nil
+}
+
+// enqueueWebhookEvent queues webhooks for a change that has already happened; a failure only
+// costs the tenant a webhook, so it is logged rather than returned
+func (s *Store) enqueueWebhookEvent(tenantID string, event string, data interface{}) {
+	if err := s.EnqueueWebhookEvent(tenantID, event, data); err != nil {
+		logger.Errorf("%s for tenant %s was not sent to its webhooks: %v", event, tenantID, err)
+	}
+}
+
+// GetWebhookDeliveries returns a webhook's latest deliveries, newest first, optionally only
+// those with status
+func (s *Store) GetWebhookDeliveries(tenantID string, webhookID uuid.UUID, status *string, limit int) ([]*types.WebhookDelivery, error) {
+	rows, err := s.DB.Query(`
+		SELECT `+webhookDeliveryColumns+`
+		FROM webhook_deliveries d
+		WHERE d.tenant_id = $1 AND d.webhook_id = $2 AND ($3::text IS NULL OR d.status = $3)
+		ORDER BY d.created_at DESC, d.id
+		LIMIT $4
+	`, tenantID, webhookID, status, limit)
+	if err != nil {
+		logger.Errorf("Failed to get deliveries for webhook %s: %v", webhookID, err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	deliveries := []*types.WebhookDelivery{}
+	for rows.Next() {
+		delivery := &types.WebhookDelivery{}
+		if err := scanWebhookDelivery(rows, delivery); err != nil {
+			logger.Errorf("Failed to scan webhook delivery: %v", err)
+			return nil, err
+		}
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// RetryWebhookDelivery queues a delivered or failed delivery to be sent again now
+func (s *Store) RetryWebhookDelivery(tenantID string, webhookID uuid.UUID, deliveryID uuid.UUID) (*types.WebhookDelivery, error) {
+	delivery := &types.WebhookDelivery{}
+	err := scanWebhookDelivery(s.DB.QueryRow(`
+		UPDATE webhook_deliveries d
+		SET status = 'PENDING', attempts = 0, next_attempt_at = NOW(), delivered_at = NULL
+		WHERE d.tenant_id = $1 AND d.webhook_id = $2 AND d.id = $3
+		RETURNING `+webhookDeliveryColumns,
+		tenantID, webhookID, deliveryID), delivery)
+	if err == sql.ErrNoRows {
+		return nil, apperr.NotFound("webhook delivery not found: %s", deliveryID)
+	}
+	if err != nil {
+		logger.Errorf("Failed to retry webhook delivery %s: %v", deliveryID, err)
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// scanWebhookDelivery reads webhookDeliveryColumns into delivery
+func scanWebhookDelivery(row interface{ Scan(...interface{}) error }, delivery *types.WebhookDelivery) error {
+	var payload []byte
+	err := row.Scan(&delivery.ID, &delivery.WebhookID, &delivery.TenantID, &delivery.Event, &payload,
+		&delivery.Status, &delivery.Attempts, &delivery.NextAttemptAt, &delivery.LastStatusCode,
+		&delivery.LastError, &delivery.CreatedAt, &delivery.DeliveredAt)
+	if err != nil {
+		return err
+	}
+	delivery.Payload = json.RawMessage(payload)
+	return nil
+}
+
+// GetDueWebhookDeliveries returns up to limit pending deliveries whose next attempt is due,
+// oldest first, with their webhook's URL and decrypted secret. Deliveries of webhooks that have
+// since been deactivated are left pending.
+func (s *Store) GetDueWebhookDeliveries(limit int) ([]*types.DueWebhookDelivery, error) {
+	if err := s.requireScope(types.ScopeWebhooksDeliver); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.DB.Query(`
+		SELECT `+webhookDeliveryColumns+`, w.url, w.secret
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.status = 'PENDING' AND d.next_attempt_at <= NOW() AND w.active
+		ORDER BY d.next_attempt_at, d.id
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		logger.Errorf("Failed to query due webhook deliveries: %v", err)
+		return nil, err
+	}
+	defer rows.Close()
+
+	// Secrets are decrypted after the rows are read; each webhook is decrypted once
+	type encryptedDelivery struct {
+		delivery *types.DueWebhookDelivery
+		secret   string
+	}
+	var pending []encryptedDelivery
+	for rows.Next() {
+		due := &types.DueWebhookDelivery{}
+		var payload []byte
+		var secret string
+		err := rows.Scan(&due.ID, &due.WebhookID, &due.TenantID, &due.Event, &payload,
+			&due.Status, &due.Attempts, &due.NextAttemptAt, &due.LastStatusCode,
+			&due.LastError, &due.CreatedAt, &due.DeliveredAt, &due.URL, &secret)
+		if err != nil {
+			logger.Errorf("Failed to scan due webhook delivery: %v", err)
+			return nil, err
+		}
+		due.Payload = json.RawMessage(payload)
+		pending = append(pending, encryptedDelivery{delivery: due, secret: secret})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	secrets := map[uuid.UUID]string{}
+	deliveries := make([]*types.DueWebhookDelivery, 0, len(pending))
+	for _, p := range pending {
+		secret, ok := secrets[p.delivery.WebhookID]
+		if !ok {
+			secret, err = crypto.DecryptPassword(p.secret)
+			if err != nil {
+				logger.Errorf("Failed to decrypt secret of webhook %s: %v", p.delivery.WebhookID, err)
+				return nil, err
+			}
+			secrets[p.delivery.WebhookID] = secret
+		}
+		p.delivery.Secret = secret
+		deliveries = append(deliveries, p.delivery)
+	}
+
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt records the outcome of sending a delivery. A failed attempt is retried
+// after retryAfter; once the delivery has been attempted types.MaxWebhookAttempts times it is marked
+// failed instead. statusCode is 0 when no response arrived.
+func (s *Store) RecordWebhookAttempt(deliveryID uuid.UUID, statusCode int, sendErr error, retryAfter time.Duration) error {
+	if err := s.requireScope(types.ScopeWebhooksDeliver); err != nil {
+		return err
+	}
+
+	var code *int
+	if statusCode != 0 {
+		code = &statusCode
+	}
+
+	var err error
+	if sendErr == nil {
+		_, err = s.DB.Exec(`
+			UPDATE webhook_deliveries
+			SET status = 'DELIVERED', attempts = attempts + 1, next_attempt_at = NULL,
+			    last_status_code = $2, last_error = NULL, delivered_at = NOW()
+			WHERE id = $1
+		`, deliveryID, code)
+	} else {
+		_, err = s.DB.Exec(`
+			UPDATE webhook_deliveries
+			SET attempts = attempts + 1, last_status_code = $2, last_error = $3,
+			    status = CASE WHEN attempts + 1 >= $4 THEN 'FAILED' ELSE 'PENDING' END,
+			    next_attempt_at = CASE WHEN attempts + 1 >= $4 THEN NULL ELSE NOW() + $5 * INTERVAL '1 second' END
+			WHERE id = $1
+		`, deliveryID, code, sendErr.Error(), types.MaxWebhookAttempts, int(retryAfter.Seconds()))
+	}
+	if err != nil {
+		logger.Errorf("Failed to record attempt of webhook delivery %s: %v", deliveryID, err)
+		return err
+	}
+	return nil
+}
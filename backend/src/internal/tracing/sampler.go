@@ -0,0 +1,44 @@
+package tracing
+
+import (
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// routeSampler decides whether to keep a new trace by the route of the request that starts it.
+// Slow statements recorded outside a trace are always kept; everything else uses fallback.
+type routeSampler struct {
+	fallback sdktrace.Sampler
+	routes   map[string]sdktrace.Sampler // By "METHOD /route/template" or "/route/template"
+}
+
+// ShouldSample implements sdktrace.Sampler
+func (s routeSampler) ShouldSample(p sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	var method, route string
+	for _, attr := range p.Attributes {
+		switch attr.Key {
+		case slowStatementKey:
+			if attr.Value.AsBool() {
+				return sdktrace.AlwaysSample().ShouldSample(p)
+			}
+		case HTTPMethodKey:
+			method = attr.Value.AsString()
+		case HTTPRouteKey:
+			route = attr.Value.AsString()
+		}
+	}
+
+	if route != "" {
+		if sampler, ok := s.routes[method+" "+route]; ok {
+			return sampler.ShouldSample(p)
+		}
+		if sampler, ok := s.routes[route]; ok {
+			return sampler.ShouldSample(p)
+		}
+	}
+	return s.fallback.ShouldSample(p)
+}
+
+// Description implements sdktrace.Sampler
+func (s routeSampler) Description() string {
+	return "RouteSampler{" + s.fallback.Description() + "}"
+}
@@ -0,0 +1,193 @@
+package tracing
+
+import (
+	"context"
+	"database/sql/driver"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// maxStatementLength is the most of a statement's text kept on its span
+const maxStatementLength = 2048
+
+// slowStatementKey marks statements kept for being slow rather than for being in a sampled trace
+const slowStatementKey = attribute.Key("welltaxpro.db.slow")
+
+// slowQuery is the configured slow statement threshold in nanoseconds; 0 keeps no statements
+// outside sampled traces
+var slowQuery atomic.Int64
+
+// WrapConnector traces the statements run on a connector's connections. Statements run with
+// the context of a sampled trace get a child span. Other statements, including every one run
+// without a context, are timed and recorded as traces of their own when they reach the slow
+// query threshold. attrs, such as the tenant, are set on every span; statement arguments never are.
+func WrapConnector(c driver.Connector, attrs ...attribute.KeyValue) driver.Connector {
+	return &connector{
+		Connector: c,
+		attrs:     append([]attribute.KeyValue{attribute.String("db.system", "postgresql")}, attrs...),
+	}
+}
+
+type connector struct {
+	driver.Connector
+	attrs []attribute.KeyValue
+}
+
+// Connect implements driver.Connector
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	dc, err := c.Connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &conn{Conn: dc, attrs: c.attrs}, nil
+}
+
+// conn traces the statements of a driver connection. Statements the driver cannot run directly
+// are skipped, so database/sql prepares them instead and they go untraced.
+type conn struct {
+	driver.Conn
+	attrs []attribute.KeyValue
+}
+
+func (c *conn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	queryer, ok := c.Conn.(driver.QueryerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	var rows driver.Rows
+	err := c.trace(ctx, query, func(ctx context.Context) error {
+		var err error
+		rows, err = queryer.QueryContext(ctx, query, args)
+		return err
+	})
+	return rows, err
+}
+
+func (c *conn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	execer, ok := c.Conn.(driver.ExecerContext)
+	if !ok {
+		return nil, driver.ErrSkip
+	}
+	var result driver.Result
+	err := c.trace(ctx, query, func(ctx context.Context) error {
+		var err error
+		result, err = execer.ExecContext(ctx, query, args)
+		return err
+	})
+	return result, err
+}
+
+func (c *conn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	if preparer, ok := c.Conn.(driver.ConnPrepareContext); ok {
+		return preparer.PrepareContext(ctx, query)
+	}
+	return c.Conn.Prepare(query)
+}
+
+func (c *conn) BeginTx(ctx context.Context, opts driver.TxOptions) (driver.Tx, error) {
+	if beginner, ok := c.Conn.(driver.ConnBeginTx); ok {
+		return beginner.BeginTx(ctx, opts)
+	}
+	return c.Conn.Begin()
+}
+
+func (c *conn) Ping(ctx context.Context) error {
+	if pinger, ok := c.Conn.(driver.Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	return nil
+}
+
+func (c *conn) ResetSession(ctx context.Context) error {
+	if resetter, ok := c.Conn.(driver.SessionResetter); ok {
+		return resetter.ResetSession(ctx)
+	}
+	return nil
+}
+
+func (c *conn) IsValid() bool {
+	if validator, ok := c.Conn.(driver.Validator); ok {
+		return validator.IsValid()
+	}
+	return true
+}
+
+// trace runs a statement in a child span of a sampled trace, or times it and records it as a
+// trace of its own when it is slow
+func (c *conn) trace(ctx context.Context, query string, run func(context.Context) error) error {
+	tracer := otel.Tracer(tracerName)
+	parent := trace.SpanContextFromContext(ctx)
+	if parent.IsSampled() {
+		name, attrs := c.statementAttrs(query)
+		ctx, span := tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+		err := run(ctx)
+		End(span, statementError(err))
+		return err
+	}
+
+	threshold := time.Duration(slowQuery.Load())
+	if threshold <= 0 {
+		return run(ctx)
+	}
+	start := time.Now()
+	err := run(ctx)
+	end := time.Now()
+	if end.Sub(start) < threshold {
+		return err
+	}
+
+	// Link the statement to the unsampled trace it ran in, if any
+	name, attrs := c.statementAttrs(query)
+	opts := []trace.SpanStartOption{
+		trace.WithNewRoot(),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithTimestamp(start),
+		trace.WithAttributes(append(attrs, slowStatementKey.Bool(true))...),
+	}
+	if parent.IsValid() {
+		opts = append(opts, trace.WithLinks(trace.Link{SpanContext: parent}))
+	}
+	_, span := tracer.Start(ctx, name, opts...)
+	if err := statementError(err); err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End(trace.WithTimestamp(end))
+	return err
+}
+
+// statementAttrs returns a statement's span name, its operation such as SELECT, and its attributes
+func (c *conn) statementAttrs(query string) (string, []attribute.KeyValue) {
+	statement := strings.TrimSpace(query)
+	operation := statement
+	if i := strings.IndexAny(operation, " \t\r\n("); i > 0 {
+		operation = operation[:i]
+	}
+	operation = strings.ToUpper(operation)
+	if len(statement) > maxStatementLength {
+		statement = statement[:maxStatementLength]
+	}
+
+	attrs := make([]attribute.KeyValue, 0, len(c.attrs)+2)
+	attrs = append(attrs, c.attrs...)
+	attrs = append(attrs,
+		attribute.String("db.operation.name", operation),
+		attribute.String("db.query.text", statement),
+	)
+	return "db " + operation, attrs
+}
+
+// statementError drops the driver's signal to fall back to a prepared statement, which is not
+// a failure
+func statementError(err error) error {
+	if err == driver.ErrSkip {
+		return nil
+	}
+	return err
+}
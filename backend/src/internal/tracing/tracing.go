@@ -0,0 +1,145 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName names the instrumentation in exported spans
+const tracerName = "welltaxpro"
+
+// Span attribute keys shared by the instrumentation
+const (
+	TenantKey     = attribute.Key("welltaxpro.tenant_id")
+	HTTPRouteKey  = attribute.Key("http.route")
+	HTTPMethodKey = attribute.Key("http.request.method")
+	HTTPStatusKey = attribute.Key("http.response.status_code")
+)
+
+// Config selects where traces are exported and how many are kept
+type Config struct {
+	Endpoint          string             // OTLP/HTTP collector URL, such as http://otel-collector:4318; empty disables tracing
+	Headers           map[string]string  // Sent with every export, such as a collector API key
+	SampleRatio       float64            // Share of new traces kept (0 to 1)
+	RouteSampleRatios map[string]float64 // Share kept by "METHOD /route/template" or "/route/template", overriding SampleRatio
+	SlowQuery         time.Duration      // Statements at least this slow are kept even outside a sampled trace; 0 keeps none
+}
+
+// Validate checks an endpoint and sample ratios
+func (c Config) Validate() error {
+	if c.Endpoint != "" {
+		u, err := url.Parse(c.Endpoint)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("endpoint must be an http or https URL, such as http://otel-collector:4318")
+		}
+	}
+	if c.SampleRatio < 0 || c.SampleRatio > 1 {
+		return fmt.Errorf("sampleRatio must be between 0 and 1")
+	}
+	for route, ratio := range c.RouteSampleRatios {
+		if ratio < 0 || ratio > 1 {
+			return fmt.Errorf("routeSampleRatios.%s must be between 0 and 1", route)
+		}
+	}
+	if c.SlowQuery < 0 {
+		return fmt.Errorf("slow query threshold cannot be negative")
+	}
+	return nil
+}
+
+// Setup exports the process's traces as serviceName and returns a function that flushes and
+// stops the exporter. Trace context is propagated with the W3C traceparent and baggage headers.
+// Without an endpoint nothing is exported, and spans are not recorded.
+func Setup(ctx context.Context, serviceName string, cfg Config) (func(context.Context) error, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+	if cfg.Endpoint == "" {
+		return func(context.Context) error { return nil }, nil
+	}
+
+	exporter, err := otlptracehttp.New(ctx,
+		otlptracehttp.WithEndpointURL(cfg.Endpoint),
+		otlptracehttp.WithHeaders(cfg.Headers),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace exporter: %w", err)
+	}
+
+	routes := map[string]sdktrace.Sampler{}
+	for route, ratio := range cfg.RouteSampleRatios {
+		routes[route] = sdktrace.TraceIDRatioBased(ratio)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", serviceName))),
+		sdktrace.WithSampler(sdktrace.ParentBased(routeSampler{
+			fallback: sdktrace.TraceIDRatioBased(cfg.SampleRatio),
+			routes:   routes,
+		})),
+	)
+	otel.SetTracerProvider(provider)
+	slowQuery.Store(int64(cfg.SlowQuery))
+
+	return provider.Shutdown, nil
+}
+
+// Tenant is the span attribute of the tenant a span works for
+func Tenant(tenantID string) attribute.KeyValue {
+	return TenantKey.String(tenantID)
+}
+
+// Start starts a server span for an incoming request, continuing the trace of its caller.
+// The route and method are set from the start, so per-route sampling can see them.
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+}
+
+// StartClient starts a span for a call to an external service, such as storage or DocuSign
+func StartClient(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+}
+
+// StartJob starts the span of one run of a background job
+func StartJob(ctx context.Context, job string) (context.Context, trace.Span) {
+	return otel.Tracer(tracerName).Start(ctx, "job "+job, trace.WithSpanKind(trace.SpanKindInternal))
+}
+
+// End marks span failed when err is set and ends it
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Extract returns ctx with the trace context a caller sent in headers
+func Extract(ctx context.Context, headers http.Header) context.Context {
+	return otel.GetTextMapPropagator().Extract(ctx, propagation.HeaderCarrier(headers))
+}
+
+// Transport traces requests sent through base, or http.DefaultTransport when nil, as client
+// spans, and passes the trace context on in their headers
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base, otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+		return r.Method + " " + r.URL.Host
+	}))
+}
@@ -0,0 +1,43 @@
+package types
+
+import "time"
+
+// AccessMatrixFilter narrows the employee × tenant access matrix
+type AccessMatrixFilter struct {
+	Search          string // Matches email, first or last name
+	TenantID        string // Only employees with a grant on this tenant
+	Role            string // Only grants with this tenant role
+	IncludeInactive bool   // Include deactivated employees and revoked grants
+	StaleDays       int    // Only grants with no activity in this many days (0 = no filter)
+}
+
+// AccessMatrixGrant is one employee's access to one tenant
+type AccessMatrixGrant struct {
+	TenantID       string     `json:"tenantId"`
+	TenantName     string     `json:"tenantName"`
+	Role           string     `json:"role"`
+	Capabilities   []string   `json:"capabilities"` // Endpoint groups the grant's role allows
+	IsActive       bool       `json:"isActive"`
+	GrantedAt      time.Time  `json:"grantedAt"`
+	LastActivityAt *time.Time `json:"lastActivityAt,omitempty"` // Most recent audit log entry for the tenant
+}
+
+// AccessMatrixRow is an employee and every tenant they can access
+type AccessMatrixRow struct {
+	Employee
+	Capabilities []string             `json:"capabilities"` // Endpoint groups the employee's role allows
+	Grants       []*AccessMatrixGrant `json:"grants"`
+}
+
+// AccessMatrixTenant is a tenant column in the access matrix
+type AccessMatrixTenant struct {
+	TenantID   string `json:"tenantId"`
+	TenantName string `json:"tenantName"`
+}
+
+// AccessMatrix is the employee × tenant access overview used for access reviews
+type AccessMatrix struct {
+	Tenants     []AccessMatrixTenant `json:"tenants"`
+	Employees   []*AccessMatrixRow   `json:"employees"`
+	GeneratedAt time.Time            `json:"generatedAt"`
+}
@@ -0,0 +1,55 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AccessReview is a periodic review campaign over employee tenant access
+type AccessReview struct {
+	ID          uuid.UUID           `json:"id"`
+	Name        string              `json:"name"`
+	Status      string              `json:"status"` // OPEN, COMPLETED
+	CreatedBy   uuid.UUID           `json:"createdBy"`
+	CreatedAt   time.Time           `json:"createdAt"`
+	CompletedAt *time.Time          `json:"completedAt,omitempty"`
+	Summary     AccessReviewCounts  `json:"summary"`
+	Items       []*AccessReviewItem `json:"items,omitempty"`
+}
+
+// AccessReviewCounts tallies line item decisions
+type AccessReviewCounts struct {
+	Total    int `json:"total"`
+	Pending  int `json:"pending"`
+	Attested int `json:"attested"`
+	Revoked  int `json:"revoked"`
+}
+
+// AccessReviewItem is a single employee × tenant grant under review
+type AccessReviewItem struct {
+	ID            uuid.UUID  `json:"id"`
+	ReviewID      uuid.UUID  `json:"reviewId"`
+	AccessID      *uuid.UUID `json:"accessId,omitempty"`
+	EmployeeID    uuid.UUID  `json:"employeeId"`
+	EmployeeEmail string     `json:"employeeEmail"`
+	TenantID      string     `json:"tenantId"`
+	Role          string     `json:"role"`
+	Decision      string     `json:"decision"` // PENDING, ATTESTED, REVOKED
+	ReviewedBy    *uuid.UUID `json:"reviewedBy,omitempty"`
+	ReviewedAt    *time.Time `json:"reviewedAt,omitempty"`
+	Comment       *string    `json:"comment,omitempty"`
+}
+
+// Access review statuses
+const (
+	AccessReviewStatusOpen      = "OPEN"
+	AccessReviewStatusCompleted = "COMPLETED"
+)
+
+// Access review item decisions
+const (
+	AccessReviewDecisionPending  = "PENDING"
+	AccessReviewDecisionAttested = "ATTESTED"
+	AccessReviewDecisionRevoked  = "REVOKED"
+)
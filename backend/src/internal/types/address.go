@@ -0,0 +1,39 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Address is a postal address split into components
+type Address struct {
+	Line1   string `json:"line1"`
+	Line2   string `json:"line2,omitempty"`
+	City    string `json:"city"`
+	State   string `json:"state"`
+	Zipcode string `json:"zipcode"`
+	Plus4   string `json:"plus4,omitempty"`
+}
+
+// AddressValidation is the outcome of validating an address with a provider.
+// Both the original input and the standardized components are kept.
+type AddressValidation struct {
+	ID           uuid.UUID  `json:"id"`
+	TenantID     string     `json:"tenantId"`
+	EntityType   string     `json:"entityType,omitempty"` // CLIENT or PROPERTY
+	EntityID     *uuid.UUID `json:"entityId,omitempty"`
+	Original     Address    `json:"original"`
+	Standardized *Address   `json:"standardized,omitempty"` // nil when the provider found no match
+	Deliverable  bool       `json:"deliverable"`
+	Provider     string     `json:"provider"`
+	Footnotes    []string   `json:"footnotes,omitempty"` // Provider-specific diagnostic codes
+	ValidatedBy  *uuid.UUID `json:"validatedBy,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// Address entity type constants
+const (
+	AddressEntityClient   = "CLIENT"
+	AddressEntityProperty = "PROPERTY"
+)
@@ -0,0 +1,196 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Background job names recorded in job history
+const (
+	JobDailyDigest         = "daily_digest"
+	JobBreakGlassExpiry    = "break_glass_expiry"
+	JobSigningNonceCleanup = "signing_nonce_cleanup"
+	JobTenantHealthCheck   = "tenant_health_check"
+	JobSchemaCheck         = "tenant_schema_check"
+	JobStuckLockCheck      = "stuck_lock_check"
+	JobDocumentDropScan    = "document_drop_scan"
+	JobDocumentExpiryCheck = "document_expiry_check"
+	JobAuditAnchor         = "audit_anchor"
+	JobTenantOffboarding   = "tenant_offboarding"
+	JobClickRollup         = "affiliate_click_rollup"
+	JobAffiliateEmails     = "affiliate_notification_emails"
+	JobWebhookDelivery     = "webhook_delivery"
+	JobCommissionSLA       = "commission_sla_check"
+	JobDebugCaptureCleanup = "debug_capture_cleanup"
+	JobTenantProbe         = "tenant_connection_probe"
+	JobFirebaseReconcile   = "firebase_user_reconciliation"
+	JobBulkOperations      = "bulk_operations"
+	JobSSNRekey            = "ssn_rekey"
+	JobDocumentScanRetry   = "document_scan_retry"
+	JobPortalSessionPurge  = "portal_session_cleanup"
+	JobArchiveStorage      = "archive_storage"
+	JobEmailDelivery       = "email_delivery"
+	JobTokenDormancy       = "affiliate_token_dormancy"
+	JobStorageConsistency  = "storage_consistency_check"
+)
+
+// Job run status constants
+const (
+	JobRunSucceeded = "SUCCEEDED"
+	JobRunFailed    = "FAILED"
+)
+
+// JobSummary aggregates a background job's recent runs
+type JobSummary struct {
+	JobName        string     `json:"jobName"`
+	LastRunAt      *time.Time `json:"lastRunAt,omitempty"`
+	LastStatus     string     `json:"lastStatus,omitempty"`
+	LastError      *string    `json:"lastError,omitempty"`
+	Runs           int        `json:"runs"`
+	Failures       int        `json:"failures"`
+	ItemsProcessed int        `json:"itemsProcessed"`
+	LeaseHolder    *string    `json:"leaseHolder,omitempty"` // Process running the job; empty for per-process jobs
+}
+
+// TenantConnectionHealth is the latest result of connecting to a tenant database
+type TenantConnectionHealth struct {
+	TenantID  string    `json:"tenantId"`
+	Healthy   bool      `json:"healthy"`
+	Error     string    `json:"error,omitempty"`
+	LatencyMs int64     `json:"latencyMs"`
+	CheckedAt time.Time `json:"checkedAt"`
+}
+
+// TenantPoolStats describes a tenant database connection pool held open by one API process
+type TenantPoolStats struct {
+	TenantID           string    `json:"tenantId"`
+	MaxOpenConnections int       `json:"maxOpenConnections"`
+	OpenConnections    int       `json:"openConnections"` // In use plus idle
+	InUse              int       `json:"inUse"`
+	Idle               int       `json:"idle"`
+	WaitCount          int64     `json:"waitCount"`      // Requests that waited for a free connection
+	WaitDurationMs     int64     `json:"waitDurationMs"` // Total time spent waiting
+	MaxIdleClosed      int64     `json:"maxIdleClosed"`
+	MaxLifetimeClosed  int64     `json:"maxLifetimeClosed"`
+	OpenedAt           time.Time `json:"openedAt"`
+	LastAccessAt       time.Time `json:"lastAccessAt"`
+	IdleSeconds        int64     `json:"idleSeconds"` // Since the pool was last used; closed after the idle timeout
+}
+
+// TenantConnectionProbe is one scheduled ping of a tenant database
+type TenantConnectionProbe struct {
+	CheckedAt time.Time `json:"checkedAt"`
+	Healthy   bool      `json:"healthy"`
+	LatencyMs int64     `json:"latencyMs"`
+	Error     *string   `json:"error,omitempty"`
+}
+
+// TenantUptimeWindow is the share of a tenant's probes that succeeded over a period
+type TenantUptimeWindow struct {
+	Window        string   `json:"window"` // 24h, 7d or 30d
+	Probes        int      `json:"probes"`
+	HealthyProbes int      `json:"healthyProbes"`
+	UptimePct     *float64 `json:"uptimePct"` // nil when there were no probes
+}
+
+// TenantUptime is a tenant database's probe history
+type TenantUptime struct {
+	Windows             []*TenantUptimeWindow    `json:"windows"`
+	ConsecutiveFailures int                      `json:"consecutiveFailures"` // Failed probes since the last healthy one
+	LastHealthyAt       *time.Time               `json:"lastHealthyAt,omitempty"`
+	Recent              []*TenantConnectionProbe `json:"recent"` // Newest first
+}
+
+// Signature envelope status constants
+const (
+	EnvelopeStatusSent   = "SENT"
+	EnvelopeStatusFailed = "FAILED"
+)
+
+// SignatureEnvelope records the outcome of one signature request sent to DocuSign
+type SignatureEnvelope struct {
+	ID        uuid.UUID  `json:"id"`
+	TenantID  string     `json:"tenantId"`
+	FilingID  *uuid.UUID `json:"filingId,omitempty"`
+	Status    string     `json:"status"`
+	Error     *string    `json:"error,omitempty"`
+	SentBy    *uuid.UUID `json:"sentBy,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// AdminOverview is the cross-tenant dashboard for platform operators.
+// Each section carries the time its data was gathered.
+type AdminOverview struct {
+	Tenants     OverviewTenants     `json:"tenants"`
+	Connections OverviewConnections `json:"connections"`
+	Filings     OverviewFilings     `json:"filings"`
+	Envelopes   OverviewEnvelopes   `json:"envelopes"`
+	Jobs        OverviewJobs        `json:"jobs"`
+	Locks       OverviewLocks       `json:"locks"`
+}
+
+// OverviewTenants counts configured tenants
+type OverviewTenants struct {
+	Total       int       `json:"total"`
+	Active      int       `json:"active"`
+	Inactive    int       `json:"inactive"`
+	RefreshedAt time.Time `json:"refreshedAt"`
+}
+
+// OverviewConnections summarizes the connection health cache for active tenants
+type OverviewConnections struct {
+	Healthy        int                       `json:"healthy"`
+	Failing        int                       `json:"failing"`
+	Unchecked      int                       `json:"unchecked"`
+	FailingTenants []*TenantConnectionHealth `json:"failingTenants"`
+	RefreshedAt    *time.Time                `json:"refreshedAt,omitempty"` // Oldest check included; nil when nothing was checked
+}
+
+// OverviewFilings counts filings for a tax season across active tenants
+type OverviewFilings struct {
+	Year        int                  `json:"year"`
+	Total       int                  `json:"total"`
+	Completed   int                  `json:"completed"`
+	ByTenant    []*TenantFilingCount `json:"byTenant"`
+	RefreshedAt time.Time            `json:"refreshedAt"`
+}
+
+// TenantFilingCount is one tenant's filings for a tax year
+type TenantFilingCount struct {
+	TenantID  string `json:"tenantId"`
+	Total     int    `json:"total"`
+	Completed int    `json:"completed"`
+	Error     string `json:"error,omitempty"` // Set when the tenant could not be counted
+}
+
+// OverviewEnvelopes counts signature requests over a recent window
+type OverviewEnvelopes struct {
+	Since          time.Time            `json:"since"`
+	Sent           int                  `json:"sent"`
+	Failed         int                  `json:"failed"`
+	RecentFailures []*SignatureEnvelope `json:"recentFailures"`
+	RefreshedAt    time.Time            `json:"refreshedAt"`
+}
+
+// OverviewJobs summarizes background job history and work still queued
+type OverviewJobs struct {
+	Since       time.Time     `json:"since"`
+	Jobs        []*JobSummary `json:"jobs"`
+	Backlog     JobBacklog    `json:"backlog"`
+	RefreshedAt time.Time     `json:"refreshedAt"`
+}
+
+// OverviewLocks lists distributed locks with their current holders and usage
+type OverviewLocks struct {
+	Locks       []*DistributedLock `json:"locks"`
+	Stuck       int                `json:"stuck"`
+	RefreshedAt time.Time          `json:"refreshedAt"`
+}
+
+// JobBacklog counts work waiting for a background job
+type JobBacklog struct {
+	PendingDigestEvents   int        `json:"pendingDigestEvents"`
+	OldestPendingDigestAt *time.Time `json:"oldestPendingDigestAt,omitempty"`
+	ExpiredNonces         int        `json:"expiredNonces"`
+}
@@ -1,6 +1,8 @@
 package types
 
 import (
+	"net/url"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -28,13 +30,58 @@ type Affiliate struct {
 	LastName               string     `json:"lastName"`
 	Email                  string     `json:"email"`
 	Phone                  *string    `json:"phone,omitempty"`
-	DefaultCommissionRate  float64    `json:"defaultCommissionRate"` // Percentage (0-100)
+	DefaultCommissionRate  Rate       `json:"defaultCommissionRate"` // Percentage (0-100)
 	StripeConnectAccountID *string    `json:"stripeConnectAccountId,omitempty"`
 	PayoutMethod           string     `json:"payoutMethod"` // MANUAL, STRIPE, PAYPAL
-	PayoutThreshold        float64    `json:"payoutThreshold"`
+	PayoutThreshold        Cents      `json:"payoutThreshold"`
 	IsActive               bool       `json:"isActive"`
 	CreatedAt              time.Time  `json:"createdAt"`
 	UpdatedAt              *time.Time `json:"updatedAt,omitempty"`
+	LastDashboardAccessAt  *time.Time `json:"lastDashboardAccessAt,omitempty"` // Latest use of any of the affiliate's dashboard tokens; not a column, filled in by the store
+}
+
+// IsDormant reports whether the affiliate has not used the dashboard since cutoff. Affiliates
+// who never used it count from when they were created.
+func (a *Affiliate) IsDormant(cutoff time.Time) bool {
+	if a.LastDashboardAccessAt != nil {
+		return a.LastDashboardAccessAt.Before(cutoff)
+	}
+	return a.CreatedAt.Before(cutoff)
+}
+
+// AffiliatePatch is a partial affiliate update: nil fields keep their stored value
+type AffiliatePatch struct {
+	FirstName             *string `json:"firstName"`
+	LastName              *string `json:"lastName"`
+	Email                 *string `json:"email"`
+	Phone                 *string `json:"phone"` // "" clears the phone number
+	DefaultCommissionRate *Rate   `json:"defaultCommissionRate"`
+	PayoutMethod          *string `json:"payoutMethod"`
+	PayoutThreshold       *Cents  `json:"payoutThreshold"`
+	IsActive              *bool   `json:"isActive"`
+}
+
+// IsEmpty reports whether the patch changes nothing
+func (p *AffiliatePatch) IsEmpty() bool {
+	return p.FirstName == nil && p.LastName == nil && p.Email == nil && p.Phone == nil &&
+		p.DefaultCommissionRate == nil && p.PayoutMethod == nil && p.PayoutThreshold == nil && p.IsActive == nil
+}
+
+// Validate checks the fields an affiliate patch sets
+func (p *AffiliatePatch) Validate() string {
+	switch {
+	case p.FirstName != nil && *p.FirstName == "", p.LastName != nil && *p.LastName == "":
+		return "firstName and lastName cannot be empty"
+	case p.Email != nil && !strings.Contains(*p.Email, "@"):
+		return "email is invalid"
+	case p.DefaultCommissionRate != nil && (*p.DefaultCommissionRate < 0 || *p.DefaultCommissionRate > MaxRate):
+		return "defaultCommissionRate must be between 0 and 100"
+	case p.PayoutMethod != nil && *p.PayoutMethod != PayoutMethodManual && *p.PayoutMethod != PayoutMethodStripe && *p.PayoutMethod != PayoutMethodPayPal:
+		return "payoutMethod must be MANUAL, STRIPE or PAYPAL"
+	case p.PayoutThreshold != nil && *p.PayoutThreshold < 0:
+		return "payoutThreshold cannot be negative"
+	}
+	return ""
 }
 
 // Commission represents a commission earned by an affiliate
@@ -47,22 +94,31 @@ type Commission struct {
 	UserID           uuid.UUID  `json:"userId"`
 	DiscountCodeID   uuid.UUID  `json:"discountCodeId"`
 	PaymentID        *uuid.UUID `json:"paymentId,omitempty"`
-	OrderAmount      float64    `json:"orderAmount"`      // Original order amount
-	DiscountAmount   float64    `json:"discountAmount"`   // Discount applied
-	NetAmount        float64    `json:"netAmount"`        // Amount after discount
-	CommissionRate   float64    `json:"commissionRate"`   // Rate applied (0-100)
-	CommissionAmount float64    `json:"commissionAmount"` // Affiliate's earning
-	Status           string     `json:"status"`           // PENDING, APPROVED, PAID, CANCELLED
+	OrderAmount      Cents      `json:"orderAmount"`      // Original order amount
+	DiscountAmount   Cents      `json:"discountAmount"`   // Discount applied
+	NetAmount        Cents      `json:"netAmount"`        // Amount after discount
+	CommissionRate   Rate       `json:"commissionRate"`   // Rate applied (0-100)
+	CommissionAmount Cents      `json:"commissionAmount"` // Affiliate's earning
+	Status           string     `json:"status"`           // PENDING, REVIEW, APPROVED, PAID, CANCELLED
 	ApprovedAt       *time.Time `json:"approvedAt,omitempty"`
 	PaidAt           *time.Time `json:"paidAt,omitempty"`
 	Notes            *string    `json:"notes,omitempty"`
 	CreatedAt        time.Time  `json:"createdAt"`
 	UpdatedAt        *time.Time `json:"updatedAt,omitempty"`
 
+	// FraudFlags lists the fraud rules that matched at creation (not persisted)
+	FraudFlags []FraudFlag `json:"fraudFlags,omitempty"`
+
+	// Tags are admin labels kept in the main database (populated on admin listings)
+	Tags []string `json:"tags,omitempty"`
+
 	// Related entities (optional, populated based on query)
 	Affiliate *Affiliate     `json:"affiliate,omitempty"`
 	Customer  *CustomerInfo  `json:"customer,omitempty"`
 	Filing    *FilingSummary `json:"filing,omitempty"`
+
+	// Payout is populated when the commission is paid by hand
+	Payout *AffiliatePayout `json:"payout,omitempty"`
 }
 
 // CustomerInfo holds basic customer information for commission display
@@ -80,6 +136,33 @@ type FilingSummary struct {
 	Status string    `json:"status"`
 }
 
+// CommissionFilter selects commissions for a bulk operation; unset fields match everything
+type CommissionFilter struct {
+	Status      *string    // Defaults to PENDING for bulk approval
+	AffiliateID *string
+	CreatedFrom *time.Time // Inclusive
+	CreatedTo   *time.Time // Exclusive
+}
+
+// BulkCommissionResult is the outcome of a bulk operation for one commission
+type BulkCommissionResult struct {
+	CommissionID string      `json:"commissionId"`
+	Success      bool        `json:"success"`
+	Error        *string     `json:"error,omitempty"`
+	Commission   *Commission `json:"commission,omitempty"`
+}
+
+// BulkCommissionReport summarizes a bulk operation on commissions
+type BulkCommissionReport struct {
+	Succeeded int                     `json:"succeeded"`
+	Failed    int                     `json:"failed"`
+	HasMore   bool                    `json:"hasMore"` // The filter matched more commissions than one request handles
+	Results   []*BulkCommissionResult `json:"results"`
+}
+
+// MaxBulkCommissions caps the commissions one bulk request changes
+const MaxBulkCommissions = 1000
+
 // AffiliateToken represents a secure access token for an affiliate
 type AffiliateToken struct {
 	ID         uuid.UUID  `json:"id"`
@@ -99,13 +182,13 @@ type AffiliateStats struct {
 	TotalClicks             int       `json:"totalClicks"`
 	TotalConversions        int       `json:"totalConversions"`
 	ConversionRate          float64   `json:"conversionRate"` // Percentage
-	TotalCommissionsEarned  float64   `json:"totalCommissionsEarned"`
-	PendingCommissions      float64   `json:"pendingCommissions"`
-	ApprovedCommissions     float64   `json:"approvedCommissions"`
-	PaidCommissions         float64   `json:"paidCommissions"`
-	CancelledCommissions    float64   `json:"cancelledCommissions"`
+	TotalCommissionsEarned  Cents     `json:"totalCommissionsEarned"`
+	PendingCommissions      Cents     `json:"pendingCommissions"`
+	ApprovedCommissions     Cents     `json:"approvedCommissions"`
+	PaidCommissions         Cents     `json:"paidCommissions"`
+	CancelledCommissions    Cents     `json:"cancelledCommissions"`
 	TotalOrders             int       `json:"totalOrders"`
-	TotalRevenue            float64   `json:"totalRevenue"` // Total order amounts
+	TotalRevenue            Cents     `json:"totalRevenue"` // Total order amounts
 }
 
 // DiscountCode represents a discount code in the system
@@ -124,11 +207,61 @@ type DiscountCode struct {
 	IsActive        bool       `json:"isActive"`
 	IsAffiliateCode bool       `json:"isAffiliateCode"`         // True if affiliate code
 	AffiliateID     *uuid.UUID `json:"affiliateId,omitempty"`   // References affiliate
-	CommissionRate  *float64   `json:"commissionRate,omitempty"` // Commission rate for this code
+	CommissionRate  *Rate      `json:"commissionRate,omitempty"` // Commission rate for this code
+	Campaign        *string    `json:"campaign,omitempty"`       // Marketing campaign label (bulk-generated codes)
 	CreatedAt       string     `json:"createdAt"`
 	UpdatedAt       *string    `json:"updatedAt,omitempty"`
 }
 
+// DiscountCodePatch is a partial discount code update: nil fields keep their stored value. The
+// affiliate, campaign and use count of a code cannot be changed.
+type DiscountCodePatch struct {
+	Code           *string  `json:"code"`
+	Description    *string  `json:"description"` // "" clears the description
+	DiscountType   *string  `json:"discountType"`
+	DiscountValue  *float64 `json:"discountValue"`
+	MaxUses        *int     `json:"maxUses"`
+	ValidFrom      *string  `json:"validFrom"`  // "" removes the start date
+	ValidUntil     *string  `json:"validUntil"` // "" removes the end date
+	IsActive       *bool    `json:"isActive"`
+	CommissionRate *Rate    `json:"commissionRate"`
+}
+
+// IsEmpty reports whether the patch changes nothing
+func (p *DiscountCodePatch) IsEmpty() bool {
+	return p.Code == nil && p.Description == nil && p.DiscountType == nil && p.DiscountValue == nil &&
+		p.MaxUses == nil && p.ValidFrom == nil && p.ValidUntil == nil && p.IsActive == nil && p.CommissionRate == nil
+}
+
+// Validate checks the fields a discount code patch sets
+func (p *DiscountCodePatch) Validate() string {
+	switch {
+	case p.Code != nil && strings.TrimSpace(*p.Code) == "":
+		return "code cannot be empty"
+	case p.DiscountType != nil && *p.DiscountType != DiscountTypePercentage && *p.DiscountType != DiscountTypeFixedAmount:
+		return "discountType must be PERCENTAGE or FIXED_AMOUNT"
+	case p.DiscountValue != nil && *p.DiscountValue <= 0:
+		return "discountValue must be positive"
+	case p.MaxUses != nil && *p.MaxUses < 1:
+		return "maxUses must be at least 1"
+	case p.CommissionRate != nil && (*p.CommissionRate < 0 || *p.CommissionRate > MaxRate):
+		return "commissionRate must be between 0 and 100"
+	}
+	return ""
+}
+
+// DiscountCampaignReport summarizes usage of the discount codes generated for one campaign
+type DiscountCampaignReport struct {
+	Campaign       string  `json:"campaign"`
+	Codes          int     `json:"codes"`
+	ActiveCodes    int     `json:"activeCodes"`
+	RedeemedCodes  int     `json:"redeemedCodes"`  // Codes used at least once
+	TotalUses      int     `json:"totalUses"`
+	RedemptionRate float64 `json:"redemptionRate"` // Percentage of codes redeemed (0-100)
+	DiscountTotal  Cents   `json:"discountTotal"`  // Discounts applied to filings
+	RevenueTotal   Cents   `json:"revenueTotal"`   // Amounts charged after discount
+}
+
 // IsValid checks if the discount code is valid for use
 func (dc *DiscountCode) IsValid() bool {
 	if !dc.IsActive {
@@ -163,6 +296,7 @@ func (dc *DiscountCode) IsValid() bool {
 // Commission status constants
 const (
 	CommissionStatusPending   = "PENDING"
+	CommissionStatusReview    = "REVIEW" // Flagged by fraud rules, awaiting admin review
 	CommissionStatusApproved  = "APPROVED"
 	CommissionStatusPaid      = "PAID"
 	CommissionStatusCancelled = "CANCELLED"
@@ -180,3 +314,66 @@ const (
 	DiscountTypePercentage  = "PERCENTAGE"
 	DiscountTypeFixedAmount = "FIXED_AMOUNT"
 )
+
+// AffiliateClick is a visit through an affiliate's tracking link
+// Field Mapping (MyWellTax adapter):
+//   taxes.affiliate_clicks.* → AffiliateClick fields
+type AffiliateClick struct {
+	AffiliateID uuid.UUID `json:"affiliateId"`
+	IPAddress   *string   `json:"-"`
+	UserAgent   *string   `json:"-"`
+	Referrer    *string   `json:"referrer,omitempty"`
+	LandingURL  *string   `json:"landingUrl,omitempty"`
+	Signed      bool      `json:"-"` // Request carried a verified signature
+
+	// UTM parameters of the visit; taken from the landing URL when not sent explicitly
+	UTMSource   *string `json:"utmSource,omitempty"`
+	UTMMedium   *string `json:"utmMedium,omitempty"`
+	UTMCampaign *string `json:"utmCampaign,omitempty"`
+	UTMTerm     *string `json:"utmTerm,omitempty"`
+	UTMContent  *string `json:"utmContent,omitempty"`
+}
+
+// maxUTMLength bounds stored UTM values
+const maxUTMLength = 255
+
+// FillUTMFromLandingURL sets UTM fields missing from the request from the landing URL's query string
+// and trims every UTM value to a storable length
+func (c *AffiliateClick) FillUTMFromLandingURL() {
+	var query url.Values
+	if c.LandingURL != nil {
+		if parsed, err := url.Parse(*c.LandingURL); err == nil {
+			query = parsed.Query()
+		}
+	}
+
+	fields := []struct {
+		param string
+		value **string
+	}{
+		{"utm_source", &c.UTMSource},
+		{"utm_medium", &c.UTMMedium},
+		{"utm_campaign", &c.UTMCampaign},
+		{"utm_term", &c.UTMTerm},
+		{"utm_content", &c.UTMContent},
+	}
+	for _, f := range fields {
+		if *f.value == nil {
+			if v := query.Get(f.param); v != "" {
+				*f.value = &v
+			}
+		}
+		if *f.value == nil {
+			continue
+		}
+		v := strings.TrimSpace(**f.value)
+		if len(v) > maxUTMLength {
+			v = v[:maxUTMLength]
+		}
+		if v == "" {
+			*f.value = nil
+		} else {
+			*f.value = &v
+		}
+	}
+}
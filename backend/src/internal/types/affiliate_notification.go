@@ -0,0 +1,52 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AffiliateNotification is a commission event shown on an affiliate's dashboard. Events the
+// affiliate has not turned off are emailed in one batch per affiliate.
+type AffiliateNotification struct {
+	ID               uuid.UUID  `json:"id"`
+	TenantID         string     `json:"-"`
+	AffiliateID      uuid.UUID  `json:"affiliateId"`
+	CommissionID     uuid.UUID  `json:"commissionId"`
+	Event            string     `json:"event"`
+	CommissionAmount Cents      `json:"commissionAmount"`
+	CreatedAt        time.Time  `json:"createdAt"`
+	ReadAt           *time.Time `json:"readAt,omitempty"`
+}
+
+// AffiliateNotificationPreference is whether an affiliate is emailed about one event
+type AffiliateNotificationPreference struct {
+	Event string `json:"event"`
+	Email bool   `json:"email"`
+}
+
+// Affiliate notification events
+const (
+	AffiliateEventCommissionCreated   = "COMMISSION_CREATED" // Only for commissions not held for fraud review
+	AffiliateEventCommissionApproved  = "COMMISSION_APPROVED"
+	AffiliateEventCommissionPaid      = "COMMISSION_PAID"
+	AffiliateEventCommissionCancelled = "COMMISSION_CANCELLED"
+)
+
+// AffiliateNotificationEvents lists every event an affiliate can configure
+var AffiliateNotificationEvents = []string{
+	AffiliateEventCommissionCreated,
+	AffiliateEventCommissionApproved,
+	AffiliateEventCommissionPaid,
+	AffiliateEventCommissionCancelled,
+}
+
+// IsValidAffiliateNotificationEvent checks an event value
+func IsValidAffiliateNotificationEvent(e string) bool {
+	for _, event := range AffiliateNotificationEvents {
+		if event == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,150 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PayoutBatch groups the affiliate payouts created together from approved commissions
+// Field Mapping (MyWellTax adapter):
+//
+//	taxes.affiliate_payout_batches.* → PayoutBatch fields
+type PayoutBatch struct {
+	ID          uuid.UUID `json:"id"`
+	TotalAmount Cents     `json:"totalAmount"`
+	CreatedBy   string    `json:"createdBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+
+	// Payout counts by status (computed)
+	PayoutCount int `json:"payoutCount"`
+	OpenCount   int `json:"openCount"` // PENDING or PROCESSING
+	PaidCount   int `json:"paidCount"`
+	FailedCount int `json:"failedCount"`
+
+	// Payouts are populated when a single batch is read
+	Payouts []*AffiliatePayout `json:"payouts,omitempty"`
+}
+
+// AffiliatePayout pays one affiliate every commission attached to it
+// Field Mapping (MyWellTax adapter):
+//
+//	taxes.affiliate_payouts.* → AffiliatePayout fields
+type AffiliatePayout struct {
+	ID              uuid.UUID  `json:"id"`
+	BatchID         uuid.UUID  `json:"batchId"`
+	AffiliateID     uuid.UUID  `json:"affiliateId"`
+	AffiliateName   string     `json:"affiliateName"`
+	Amount          Cents      `json:"amount"`
+	CommissionCount int        `json:"commissionCount"`
+	PayoutMethod    string     `json:"payoutMethod"` // Affiliate's method when the batch was created
+	Status          string     `json:"status"`       // PENDING, PROCESSING, PAID, FAILED, CANCELLED
+	Attempts        int        `json:"attempts"`     // Stripe transfers attempted
+	TransferID      *string    `json:"transferId,omitempty"`
+	Reference       *string    `json:"reference,omitempty"` // Latest manual payment reference (check number, PayPal ID)
+	PaidAmount      Cents      `json:"paidAmount"`          // Paid so far; below Amount while partly paid
+	Error           *string    `json:"error,omitempty"`     // Last transfer failure
+	PaidBy          *string    `json:"paidBy,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	PaidAt          *time.Time `json:"paidAt,omitempty"`
+	UpdatedAt       *time.Time `json:"updatedAt,omitempty"`
+
+	// Payments are populated when a single payout is read
+	Payments []*AffiliatePayoutPayment `json:"payments,omitempty"`
+
+	// StripeAccountID is the affiliate's current Stripe Connect account (not persisted on the payout)
+	StripeAccountID *string `json:"-"`
+}
+
+// AffiliatePayoutPayment is one payment made against a payout: a Stripe transfer or money sent
+// by hand. A payout is PAID once its payments add up to its amount.
+// Field Mapping (MyWellTax adapter):
+//
+//	taxes.affiliate_payout_payments.* → AffiliatePayoutPayment fields
+type AffiliatePayoutPayment struct {
+	ID          uuid.UUID `json:"id"`
+	PayoutID    uuid.UUID `json:"payoutId"`
+	AffiliateID uuid.UUID `json:"affiliateId"`
+	Method      string    `json:"method"`              // STRIPE or a manual payment method
+	Reference   *string   `json:"reference,omitempty"` // Stripe transfer ID, check number, Zelle confirmation
+	Amount      Cents     `json:"amount"`
+	PaidBy      string    `json:"paidBy,omitempty"` // Withheld from affiliates
+	PaidAt      time.Time `json:"paidAt"`
+}
+
+// AffiliatePaymentTotal is what a tenant paid an affiliate in a calendar year by payment date,
+// the amount reported on the affiliate's 1099
+type AffiliatePaymentTotal struct {
+	AffiliateID   uuid.UUID `json:"affiliateId"`
+	AffiliateName string    `json:"affiliateName"`
+	Email         string    `json:"email"`
+	Year          int       `json:"year"`
+	Payments      int       `json:"payments"` // Number of payments
+	Total         Cents     `json:"total"`
+}
+
+// AffiliateStatement lists the payments a tenant made to an affiliate in a calendar year
+type AffiliateStatement struct {
+	AffiliateID uuid.UUID                 `json:"affiliateId"`
+	Year        int                       `json:"year"`
+	Total       Cents                     `json:"total"` // The affiliate's 1099 amount for the year
+	Payments    []*AffiliatePayoutPayment `json:"payments"`
+}
+
+// TransferKey is the idempotency key of the payout's current transfer attempt. Retrying an
+// attempt whose outcome is unknown reuses it, so Stripe never pays the same attempt twice.
+func (p *AffiliatePayout) TransferKey() string {
+	return fmt.Sprintf("payout-%s-%d", p.ID, p.Attempts)
+}
+
+// PayoutPayment records how a payout was paid
+type PayoutPayment struct {
+	TransferID *string // Stripe transfer of the whole amount; the payout must be PROCESSING
+	Method     string  // Manual payment method; the payout must be PENDING or FAILED
+	Reference  *string // Manual payment reference
+	Amount     *Cents  // Manual payment amount; nil pays what is still owed
+	PaidBy     string
+}
+
+// Payout status constants
+const (
+	PayoutStatusPending    = "PENDING"
+	PayoutStatusProcessing = "PROCESSING"
+	PayoutStatusPaid       = "PAID"
+	PayoutStatusFailed     = "FAILED"
+	PayoutStatusCancelled  = "CANCELLED"
+)
+
+// Payout payment method constants
+const (
+	PaymentMethodStripe = "STRIPE" // Recorded for Stripe transfers; not accepted for manual payments
+	PaymentMethodZelle  = "ZELLE"
+	PaymentMethodCheck  = "CHECK"
+	PaymentMethodACH    = "ACH"
+	PaymentMethodWire   = "WIRE"
+	PaymentMethodPayPal = "PAYPAL"
+	PaymentMethodCash   = "CASH"
+	PaymentMethodOther  = "OTHER"
+)
+
+// ManualPaymentMethods are the methods an admin can record a payment with
+var ManualPaymentMethods = []string{
+	PaymentMethodZelle,
+	PaymentMethodCheck,
+	PaymentMethodACH,
+	PaymentMethodWire,
+	PaymentMethodPayPal,
+	PaymentMethodCash,
+	PaymentMethodOther,
+}
+
+// IsValidManualPaymentMethod checks a manual payment method
+func IsValidManualPaymentMethod(method string) bool {
+	for _, m := range ManualPaymentMethods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,38 @@
+package types
+
+import "time"
+
+// Bounds of AffiliateTokenPolicy.DeactivateAfterDays when it is set
+const (
+	MinAffiliateTokenDormancyDays = 30
+	MaxAffiliateTokenDormancyDays = 730
+)
+
+// AffiliateTokenPolicy deactivates affiliate dashboard tokens that have gone unused.
+// Stored per tenant in tenant_connections.affiliate_token_policy (JSONB); tenants without a
+// policy fall back to DefaultAffiliateTokenPolicy, which deactivates none.
+type AffiliateTokenPolicy struct {
+	DeactivateAfterDays int `json:"deactivateAfterDays"` // Days a token may go unused, counted from its creation if never used, before it is deactivated (0 keeps every token)
+}
+
+// DefaultAffiliateTokenPolicy returns the policy applied when a tenant has none
+func DefaultAffiliateTokenPolicy() *AffiliateTokenPolicy {
+	return &AffiliateTokenPolicy{DeactivateAfterDays: 0}
+}
+
+// Validate checks an affiliate token policy
+func (p *AffiliateTokenPolicy) Validate() string {
+	if p.DeactivateAfterDays != 0 && (p.DeactivateAfterDays < MinAffiliateTokenDormancyDays || p.DeactivateAfterDays > MaxAffiliateTokenDormancyDays) {
+		return "deactivateAfterDays must be 0 or between 30 and 730"
+	}
+	return ""
+}
+
+// Cutoff returns the time before which a token last used (or created) is deactivated at now,
+// and false when the policy deactivates nothing
+func (p *AffiliateTokenPolicy) Cutoff(now time.Time) (time.Time, bool) {
+	if p.DeactivateAfterDays <= 0 {
+		return time.Time{}, false
+	}
+	return now.AddDate(0, 0, -p.DeactivateAfterDays), true
+}
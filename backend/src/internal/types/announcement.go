@@ -0,0 +1,70 @@
+package types
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Announcement severities
+const (
+	AnnouncementInfo     = "INFO"
+	AnnouncementWarning  = "WARNING"
+	AnnouncementCritical = "CRITICAL"
+)
+
+// MaxAnnouncementLength is the longest announcement message, in characters
+const MaxAnnouncementLength = 1000
+
+// Announcement is a banner platform operators show to tenant admins, such as notice of maintenance
+type Announcement struct {
+	ID        uuid.UUID  `json:"id"`
+	Message   string     `json:"message"`
+	Severity  string     `json:"severity"`
+	StartsAt  time.Time  `json:"startsAt"`
+	EndsAt    time.Time  `json:"endsAt"`
+	TenantIDs []string   `json:"tenantIds"` // Admins of these tenants see it; empty for every tenant
+	CreatedBy *uuid.UUID `json:"createdBy,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+
+	// How many employees acknowledged it; set in the operator listing
+	Acknowledgments int `json:"acknowledgments"`
+	// Whether the calling employee acknowledged it; set in the banner listing
+	Acknowledged bool `json:"acknowledged"`
+}
+
+// Validate checks the message, severity and time window of an announcement
+func (a *Announcement) Validate() string {
+	a.Message = strings.TrimSpace(a.Message)
+	if a.Message == "" {
+		return "message is required"
+	}
+	if len([]rune(a.Message)) > MaxAnnouncementLength {
+		return "message is too long"
+	}
+	switch a.Severity {
+	case AnnouncementInfo, AnnouncementWarning, AnnouncementCritical:
+	default:
+		return "severity must be INFO, WARNING or CRITICAL"
+	}
+	if a.StartsAt.IsZero() || a.EndsAt.IsZero() {
+		return "startsAt and endsAt are required"
+	}
+	if !a.EndsAt.After(a.StartsAt) {
+		return "endsAt must be after startsAt"
+	}
+	if a.TenantIDs == nil {
+		a.TenantIDs = []string{}
+	}
+	return ""
+}
+
+// AnnouncementAcknowledgment records that an employee dismissed an announcement
+type AnnouncementAcknowledgment struct {
+	AnnouncementID uuid.UUID `json:"announcementId"`
+	EmployeeID     uuid.UUID `json:"employeeId"`
+	EmployeeEmail  string    `json:"employeeEmail"`
+	AcknowledgedAt time.Time `json:"acknowledgedAt"`
+}
@@ -10,7 +10,8 @@ import (
 // AuditLog represents an access record for compliance
 type AuditLog struct {
 	ID           uuid.UUID       `json:"id"`
-	EmployeeID   uuid.UUID       `json:"employeeId"`
+	EmployeeID   uuid.UUID       `json:"employeeId"` // uuid.Nil for portal user events
+	TenantUserID *uuid.UUID      `json:"tenantUserId,omitempty"` // The portal user behind a portal event
 	TenantID     string          `json:"tenantId"`
 	ClientID     *uuid.UUID      `json:"clientId,omitempty"`
 	Action       string          `json:"action"` // VIEW, EDIT, DELETE, DOWNLOAD, CREATE, EXPORT
@@ -20,25 +21,43 @@ type AuditLog struct {
 	IPAddress    *string         `json:"ipAddress,omitempty"`
 	UserAgent    *string         `json:"userAgent,omitempty"`
 	CreatedAt    time.Time       `json:"createdAt"`
+	ChainSeq     *int64          `json:"chainSeq,omitempty"`  // nil for entries written before the hash chain
+	PrevHash     *string         `json:"prevHash,omitempty"`
+	EntryHash    *string         `json:"entryHash,omitempty"`
 }
 
 // Audit action constants
 const (
-	AuditActionView     = "VIEW"
-	AuditActionEdit     = "EDIT"
-	AuditActionDelete   = "DELETE"
-	AuditActionDownload = "DOWNLOAD"
-	AuditActionUpload   = "UPLOAD"
-	AuditActionCreate   = "CREATE"
-	AuditActionExport   = "EXPORT"
+	AuditActionView         = "VIEW"
+	AuditActionEdit         = "EDIT"
+	AuditActionDelete       = "DELETE"
+	AuditActionDownload     = "DOWNLOAD"
+	AuditActionUpload       = "UPLOAD"
+	AuditActionCreate       = "CREATE"
+	AuditActionExport       = "EXPORT"
+	AuditActionElevate      = "ELEVATE"
+	AuditActionRevoke       = "REVOKE"
+	AuditActionVerifyFailed = "VERIFY_FAILED"
+	AuditActionLock         = "LOCK"
 )
 
 // Audit resource type constants
 const (
-	AuditResourceClient    = "CLIENT"
-	AuditResourceFiling    = "FILING"
-	AuditResourceDocument  = "DOCUMENT"
-	AuditResourceSSN       = "SSN"
-	AuditResourceSpouse    = "SPOUSE"
-	AuditResourceDependent = "DEPENDENT"
+	AuditResourceClient           = "CLIENT"
+	AuditResourceFiling           = "FILING"
+	AuditResourceDocument         = "DOCUMENT"
+	AuditResourceSSN              = "SSN"
+	AuditResourceSpouse           = "SPOUSE"
+	AuditResourceDependent        = "DEPENDENT"
+	AuditResourceBreakGlass       = "BREAK_GLASS"
+	AuditResourceSigningKey       = "SIGNING_KEY"
+	AuditResourceIdentityDocument = "IDENTITY_DOCUMENT"
+	AuditResourceDocumentRequest  = "DOCUMENT_REQUEST"
+	AuditResourceOffboarding      = "TENANT_OFFBOARDING"
+	AuditResourceWebhook          = "WEBHOOK"
+	AuditResourceDebugCapture     = "DEBUG_CAPTURE"
+	AuditResourceEmployee         = "EMPLOYEE"
+	AuditResourcePortalUser       = "PORTAL_USER"
+	AuditResourceBulkOperation    = "BULK_OPERATION"
+	AuditResourceSSNDataKey       = "SSN_DATA_KEY"
 )
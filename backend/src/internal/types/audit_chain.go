@@ -0,0 +1,138 @@
+package types
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// AuditChainGenesis is the prev_hash of the first entry in the audit hash chain
+const AuditChainGenesis = "0000000000000000000000000000000000000000000000000000000000000000"
+
+// auditChainEntry is the canonical form of an audit entry that is hashed. Field order is fixed
+// by the struct, details are re-encoded with sorted keys, and the IP address and timestamp are
+// normalized, so the hash is the same for the entry as written and as read back from Postgres.
+type auditChainEntry struct {
+	Seq          int64       `json:"seq"`
+	ID           string      `json:"id"`
+	EmployeeID   string      `json:"employeeId"`
+	TenantUserID string      `json:"tenantUserId,omitempty"` // Omitted for employee entries, keeping their hashes
+	TenantID     string      `json:"tenantId"`
+	ClientID     string      `json:"clientId"`
+	Action       string      `json:"action"`
+	ResourceType string      `json:"resourceType"`
+	ResourceID   string      `json:"resourceId"`
+	Details      interface{} `json:"details"`
+	IPAddress    string      `json:"ipAddress"`
+	UserAgent    string      `json:"userAgent"`
+	CreatedAt    int64       `json:"createdAt"` // Unix microseconds, the precision Postgres stores
+}
+
+// ChainHash returns the hex SHA-256 of prevHash and the entry's canonical fields at position seq
+func (l *AuditLog) ChainHash(seq int64, prevHash string) string {
+	entry := auditChainEntry{
+		Seq:          seq,
+		ID:           l.ID.String(),
+		EmployeeID:   l.EmployeeID.String(),
+		TenantUserID: optionalUUID(l.TenantUserID),
+		TenantID:     l.TenantID,
+		ClientID:     optionalUUID(l.ClientID),
+		Action:       l.Action,
+		ResourceType: l.ResourceType,
+		ResourceID:   optionalUUID(l.ResourceID),
+		CreatedAt:    l.CreatedAt.UnixMicro(),
+	}
+	if len(l.Details) > 0 {
+		// JSONB reorders keys and drops whitespace; decoding and re-encoding undoes both
+		if err := json.Unmarshal(l.Details, &entry.Details); err != nil {
+			entry.Details = string(l.Details)
+		}
+	}
+	if l.IPAddress != nil {
+		entry.IPAddress = canonicalIP(*l.IPAddress)
+	}
+	if l.UserAgent != nil {
+		entry.UserAgent = *l.UserAgent
+	}
+
+	data, _ := json.Marshal(entry)
+	sum := sha256.Sum256(append([]byte(prevHash+"\n"), data...))
+	return hex.EncodeToString(sum[:])
+}
+
+func optionalUUID(id *uuid.UUID) string {
+	if id == nil {
+		return ""
+	}
+	return id.String()
+}
+
+// canonicalIP formats an address the same way whether it was given by a request or read from
+// an INET column, which appends /32 or /128 to some hosts
+func canonicalIP(address string) string {
+	host := strings.TrimSuffix(strings.TrimSuffix(address, "/32"), "/128")
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String()
+	}
+	return address
+}
+
+// AuditAnchor is a chain head written to the WORM anchor bucket
+type AuditAnchor struct {
+	ChainSeq   int64     `json:"chainSeq"`
+	EntryHash  string    `json:"entryHash"`
+	Bucket     string    `json:"bucket"`
+	ObjectPath string    `json:"objectPath"`
+	AnchoredBy string    `json:"anchoredBy"`
+	AnchoredAt time.Time `json:"anchoredAt"`
+}
+
+// AuditChainVerification is the result of re-validating the audit hash chain over a date range
+type AuditChainVerification struct {
+	From           time.Time            `json:"from"`
+	To             time.Time            `json:"to"`
+	Valid          bool                 `json:"valid"`
+	EntriesChecked int                  `json:"entriesChecked"`
+	FirstSeq       *int64               `json:"firstSeq,omitempty"`
+	LastSeq        *int64               `json:"lastSeq,omitempty"`
+	Unchained      int                  `json:"unchained"`          // Entries in range written before the chain existed
+	AnchorsChecked int                  `json:"anchorsChecked"`     // Anchors recorded in the database
+	WORMChecked    int                  `json:"wormAnchorsChecked"` // Anchor copies read back from the WORM bucket
+	Problems       []*AuditChainProblem `json:"problems"`
+	Truncated      bool                 `json:"truncated"` // More problems were found than are listed
+}
+
+// AuditChainProblem is one integrity failure found while verifying the chain
+type AuditChainProblem struct {
+	ChainSeq int64      `json:"chainSeq"`
+	EntryID  *uuid.UUID `json:"entryId,omitempty"`
+	Problem  string     `json:"problem"` // AuditChain* problem constant
+	Detail   string     `json:"detail"`
+}
+
+// Audit chain problems
+const (
+	AuditChainHashMismatch   = "HASH_MISMATCH"   // Entry fields no longer match its hash: the row was altered
+	AuditChainLinkBroken     = "LINK_BROKEN"     // prev_hash does not match the previous entry's hash
+	AuditChainGap            = "GAP"             // Sequence numbers are missing: entries were removed
+	AuditChainAnchorMismatch = "ANCHOR_MISMATCH" // The chain no longer matches an anchored head
+	AuditChainAnchorMissing  = "ANCHOR_MISSING"  // The WORM copy of an anchor is missing or unreadable
+)
+
+// MaxAuditChainProblems caps the problems listed by one verification
+const MaxAuditChainProblems = 100
+
+// AddProblem records a problem, keeping at most MaxAuditChainProblems
+func (v *AuditChainVerification) AddProblem(p *AuditChainProblem) {
+	v.Valid = false
+	if len(v.Problems) >= MaxAuditChainProblems {
+		v.Truncated = true
+		return
+	}
+	v.Problems = append(v.Problems, p)
+}
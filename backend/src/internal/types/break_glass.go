@@ -0,0 +1,31 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BreakGlassGrant is a time-boxed emergency elevation to tenant admin
+type BreakGlassGrant struct {
+	ID            uuid.UUID  `json:"id"`
+	EmployeeID    uuid.UUID  `json:"employeeId"`
+	EmployeeEmail string     `json:"employeeEmail,omitempty"`
+	TenantID      string     `json:"tenantId"`
+	Reason        string     `json:"reason"`
+	GrantedAt     time.Time  `json:"grantedAt"`
+	ExpiresAt     time.Time  `json:"expiresAt"`
+	RevokedAt     *time.Time `json:"revokedAt,omitempty"`
+	RevokedBy     *uuid.UUID `json:"revokedBy,omitempty"` // nil when the grant expired automatically
+}
+
+// IsActive reports whether the grant currently provides access
+func (g *BreakGlassGrant) IsActive() bool {
+	return g.RevokedAt == nil && time.Now().Before(g.ExpiresAt)
+}
+
+// Break-glass duration limits
+const (
+	BreakGlassDefaultDuration = time.Hour
+	BreakGlassMaxDuration     = 4 * time.Hour
+)
@@ -0,0 +1,100 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BulkOperation applies one action to many clients or filings of a tenant. Staff queue it with
+// the IDs to act on or a filter that selects them; the bulk operations worker job resolves the
+// selection into items and records the outcome of each.
+type BulkOperation struct {
+	ID             uuid.UUID            `json:"id"`
+	TenantID       string               `json:"tenantId"`
+	Action         string               `json:"action"` // BulkAction*
+	Status         string               `json:"status"` // BulkOperation*
+	Selection      *BulkSelection       `json:"selection"`
+	Params         json.RawMessage      `json:"params"`
+	TotalItems     int                  `json:"totalItems"`
+	SucceededItems int                  `json:"succeededItems"`
+	FailedItems    int                  `json:"failedItems"`
+	Error          *string              `json:"error,omitempty"` // Why the operation failed as a whole
+	CreatedBy      uuid.UUID            `json:"createdBy"`
+	CreatedAt      time.Time            `json:"createdAt"`
+	SelectedAt     *time.Time           `json:"selectedAt,omitempty"`
+	StartedAt      *time.Time           `json:"startedAt,omitempty"`
+	FinishedAt     *time.Time           `json:"finishedAt,omitempty"`
+	Items          []*BulkOperationItem `json:"items,omitempty"` // Loaded for a single operation
+}
+
+// BulkSelection chooses the items of a bulk operation: the given IDs, or every item matching Filter
+type BulkSelection struct {
+	IDs    []uuid.UUID `json:"ids,omitempty"`
+	Filter *BulkFilter `json:"filter,omitempty"`
+}
+
+// BulkFilter selects filings, or the clients with a matching filing. Unset fields match everything.
+type BulkFilter struct {
+	TaxYear         *int       `json:"taxYear,omitempty"`
+	IncompleteOnly  bool       `json:"incompleteOnly,omitempty"`  // Filings not yet completed
+	AssignedTo      *uuid.UUID `json:"assignedTo,omitempty"`      // Filings assigned to this employee
+	IncludeArchived bool       `json:"includeArchived,omitempty"` // Archived clients are left out by default
+}
+
+// BulkOperationItem is the outcome of a bulk operation for one client or filing
+type BulkOperationItem struct {
+	ItemID      uuid.UUID  `json:"itemId"` // Client or filing ID, per BulkActionTargets
+	Status      string     `json:"status"` // BulkItem*
+	Result      *string    `json:"result,omitempty"`
+	Error       *string    `json:"error,omitempty"`
+	ProcessedAt *time.Time `json:"processedAt,omitempty"`
+}
+
+// Bulk actions
+const (
+	BulkActionSendPortalLink   = "SEND_PORTAL_LINK"  // Email each client a sign-in link to the portal
+	BulkActionRequestDocuments = "REQUEST_DOCUMENTS" // Raise the same document request for each client
+	BulkActionReassignFilings  = "REASSIGN_FILINGS"  // Assign each filing to another accountant
+)
+
+// Bulk action targets
+const (
+	BulkTargetClient = "CLIENT"
+	BulkTargetFiling = "FILING"
+)
+
+// BulkActionTargets says whether each bulk action works on clients or filings
+var BulkActionTargets = map[string]string{
+	BulkActionSendPortalLink:   BulkTargetClient,
+	BulkActionRequestDocuments: BulkTargetClient,
+	BulkActionReassignFilings:  BulkTargetFiling,
+}
+
+// Bulk operation statuses
+const (
+	BulkOperationQueued    = "QUEUED"
+	BulkOperationRunning   = "RUNNING"
+	BulkOperationCompleted = "COMPLETED" // Every item was processed; some may have failed
+	BulkOperationFailed    = "FAILED"    // The selection could not be resolved
+)
+
+// Bulk operation item statuses
+const (
+	BulkItemPending   = "PENDING"
+	BulkItemSucceeded = "SUCCEEDED"
+	BulkItemFailed    = "FAILED"
+)
+
+// BulkRequestDocumentsParams are the inputs of REQUEST_DOCUMENTS
+type BulkRequestDocumentsParams struct {
+	DocumentType string  `json:"documentType"`
+	Description  string  `json:"description"`
+	DueOn        *string `json:"dueOn,omitempty"` // YYYY-MM-DD
+}
+
+// BulkReassignFilingsParams are the inputs of REASSIGN_FILINGS
+type BulkReassignFilingsParams struct {
+	EmployeeID uuid.UUID `json:"employeeId"` // The accountant the filings are assigned to
+}
@@ -0,0 +1,113 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Campaign groups the discount codes and tracking links of one marketing effort.
+// Key is the label on bulk-generated discount codes and the utm_campaign of tracking links.
+type Campaign struct {
+	ID          uuid.UUID  `json:"id"`
+	TenantID    string     `json:"tenantId"`
+	Key         string     `json:"key"`
+	Name        string     `json:"name"`
+	Channel     string     `json:"channel"`
+	Description *string    `json:"description,omitempty"`
+	StartsOn    *string    `json:"startsOn,omitempty"` // YYYY-MM-DD
+	EndsOn      *string    `json:"endsOn,omitempty"`   // YYYY-MM-DD
+	IsActive    bool       `json:"isActive"`
+	CreatedBy   *uuid.UUID `json:"createdBy,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+}
+
+// Campaign channel constants
+const (
+	CampaignChannelEmail      = "EMAIL"
+	CampaignChannelDirectMail = "DIRECT_MAIL"
+	CampaignChannelSocial     = "SOCIAL"
+	CampaignChannelPaidSearch = "PAID_SEARCH"
+	CampaignChannelAffiliate  = "AFFILIATE"
+	CampaignChannelEvent      = "EVENT"
+	CampaignChannelOther      = "OTHER"
+)
+
+// ValidCampaignChannels lists the accepted campaign channels
+var ValidCampaignChannels = []string{
+	CampaignChannelEmail,
+	CampaignChannelDirectMail,
+	CampaignChannelSocial,
+	CampaignChannelPaidSearch,
+	CampaignChannelAffiliate,
+	CampaignChannelEvent,
+	CampaignChannelOther,
+}
+
+// IsValidCampaignChannel reports whether channel is a known campaign channel
+func IsValidCampaignChannel(channel string) bool {
+	for _, c := range ValidCampaignChannels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// CampaignMetrics aggregates attribution for one campaign (or channel) from the tenant database.
+// Conversions are filings that used one of the campaign's discount codes.
+type CampaignMetrics struct {
+	Clicks            int     `json:"clicks"`            // Tracking link visits with the campaign's utm_campaign
+	Codes             int     `json:"codes"`             // Discount codes labelled with the campaign
+	Conversions       int     `json:"conversions"`       // Filings discounted with a campaign code
+	Revenue           Cents   `json:"revenue"`           // Amounts charged after discount
+	Discounts         Cents   `json:"discounts"`         // Discounts given
+	CommissionCost    Cents   `json:"commissionCost"`    // Non-cancelled commissions on campaign codes
+	NetRevenue        Cents   `json:"netRevenue"`        // Revenue minus commission cost
+	ConversionRate    float64 `json:"conversionRate"`    // Conversions per click (0-100), 0 without clicks
+	CostPerConversion Cents   `json:"costPerConversion"` // Discounts plus commissions per conversion
+}
+
+// Add accumulates other into m; derived rates must be recomputed with Finalize
+func (m *CampaignMetrics) Add(other *CampaignMetrics) {
+	m.Clicks += other.Clicks
+	m.Codes += other.Codes
+	m.Conversions += other.Conversions
+	m.Revenue += other.Revenue
+	m.Discounts += other.Discounts
+	m.CommissionCost += other.CommissionCost
+}
+
+// Finalize computes the derived fields from the raw counts and totals
+func (m *CampaignMetrics) Finalize() {
+	m.NetRevenue = m.Revenue - m.CommissionCost
+	m.ConversionRate = 0
+	if m.Clicks > 0 {
+		m.ConversionRate = float64(m.Conversions) / float64(m.Clicks) * 100
+	}
+	m.CostPerConversion = 0
+	if m.Conversions > 0 {
+		m.CostPerConversion = (m.Discounts + m.CommissionCost).Div(m.Conversions)
+	}
+}
+
+// CampaignPerformance is a campaign with its attribution metrics
+type CampaignPerformance struct {
+	Campaign *Campaign       `json:"campaign"`
+	Metrics  CampaignMetrics `json:"metrics"`
+}
+
+// ChannelPerformance rolls up the metrics of all campaigns in one channel
+type ChannelPerformance struct {
+	Channel   string          `json:"channel"`
+	Campaigns int             `json:"campaigns"`
+	Metrics   CampaignMetrics `json:"metrics"`
+}
+
+// CampaignReport compares campaigns and channels for a tenant
+type CampaignReport struct {
+	Campaigns   []*CampaignPerformance `json:"campaigns"`
+	Channels    []*ChannelPerformance  `json:"channels"`
+	GeneratedAt time.Time              `json:"generatedAt"`
+}
@@ -0,0 +1,73 @@
+package types
+
+// Employee roles
+const (
+	RoleAdmin            = "admin"
+	RoleAccountant       = "accountant"
+	RoleViewer           = "viewer"
+	RoleSupport          = "support"
+	RoleAffiliateManager = "affiliate_manager" // Marketing staff: affiliates and promotions, no client data
+)
+
+// Capabilities group the staff endpoints a role may call
+const (
+	CapabilityClientData  = "client_data" // Clients, filings, documents, addresses and tax forms
+	CapabilityAffiliates  = "affiliates"
+	CapabilityCommissions = "commissions"
+	CapabilityDiscounts   = "discounts"
+	CapabilityCampaigns   = "campaigns"
+)
+
+// Capabilities lists every capability; admins hold all of them
+var Capabilities = []string{
+	CapabilityClientData,
+	CapabilityAffiliates,
+	CapabilityCommissions,
+	CapabilityDiscounts,
+	CapabilityCampaigns,
+}
+
+// roleCapabilities maps each non-admin role to its capabilities. Endpoints that need no
+// capability and are not admin-only, such as the employee's own profile, are open to every role.
+var roleCapabilities = map[string][]string{
+	RoleAccountant:       {CapabilityClientData},
+	RoleViewer:           {CapabilityClientData},
+	RoleSupport:          {CapabilityClientData},
+	RoleAffiliateManager: {CapabilityAffiliates, CapabilityCommissions, CapabilityDiscounts, CapabilityCampaigns},
+}
+
+// RoleCapabilities returns the capabilities a role grants; unknown roles grant none
+func RoleCapabilities(role string) []string {
+	if role == RoleAdmin {
+		return Capabilities
+	}
+	if capabilities, ok := roleCapabilities[role]; ok {
+		return capabilities
+	}
+	return []string{}
+}
+
+// RoleHasCapability reports whether a role grants a capability
+func RoleHasCapability(role, capability string) bool {
+	for _, c := range RoleCapabilities(role) {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+// tenantRoleLevels ranks the roles of employee_tenant_access: viewers read a tenant's records,
+// accountants and affiliate managers also change them, and admins may do anything
+var tenantRoleLevels = map[string]int{
+	RoleViewer:           1,
+	RoleAccountant:       2,
+	RoleAffiliateManager: 2,
+	RoleAdmin:            3,
+}
+
+// TenantRoleSatisfies reports whether an employee's role in a tenant is at least required
+func TenantRoleSatisfies(role, required string) bool {
+	level, ok := tenantRoleLevels[role]
+	return ok && level >= tenantRoleLevels[required]
+}
@@ -0,0 +1,31 @@
+package types
+
+import (
+	"github.com/google/uuid"
+)
+
+// CheckoutSession is a Stripe Checkout session paid for a filing, as reported by a Stripe webhook
+type CheckoutSession struct {
+	EventID        string
+	EventType      string
+	SessionID      string
+	FilingID       uuid.UUID
+	DiscountCode   *string
+	AmountSubtotal Cents  // Order amount before the discount
+	AmountDiscount Cents  // Discount applied by Stripe
+	AmountTotal    Cents  // Amount charged
+	PaymentStatus  string // paid, unpaid or no_payment_required
+}
+
+// Paid reports whether the session's money has been collected
+func (s *CheckoutSession) Paid() bool {
+	return s.PaymentStatus == "paid"
+}
+
+// CheckoutPayment is the payment recorded for a checkout session
+type CheckoutPayment struct {
+	Payment      *Payment   `json:"payment"`
+	UserID       uuid.UUID  `json:"userId"`                 // Client the filing belongs to
+	Created      bool       `json:"created"`                // False when the session was recorded before
+	CommissionID *uuid.UUID `json:"commissionId,omitempty"` // Commission already created for the payment
+}
@@ -32,6 +32,9 @@ import "github.com/google/uuid"
 //   taxes.user.zipcode → Zipcode
 //   taxes.user.role → Role
 //   taxes.user.created_at → CreatedAt
+//   taxes.user.death_date → DeathDate
+//   taxes.user.archived_at → ArchivedAt
+//   taxes.user.archive_reason → ArchiveReason
 type Client struct {
 	// REQUIRED FIELDS
 	ID        uuid.UUID `json:"id"`        // Unique client identifier
@@ -51,4 +54,16 @@ type Client struct {
 	City       *string `json:"city,omitempty"`       // City
 	State      *string `json:"state,omitempty"`      // State/province
 	Zipcode    *int32  `json:"zipcode,omitempty"`    // Postal code
+
+	// Set when the taxpayer is deceased (YYYY-MM-DD)
+	DeathDate *string `json:"deathDate,omitempty"`
+
+	// Archival (archived clients are hidden from default listings and the portal)
+	ArchivedAt    *string `json:"archivedAt,omitempty"`    // When the client was archived
+	ArchiveReason *string `json:"archiveReason,omitempty"` // Why the client was archived (moved firms, deceased, etc.)
+}
+
+// IsArchived reports whether the client has been archived
+func (c *Client) IsArchived() bool {
+	return c.ArchivedAt != nil
 }
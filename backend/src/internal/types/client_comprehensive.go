@@ -66,6 +66,10 @@ type Filing struct {
 	CreatedAt             string     `json:"createdAt"`
 	UpdatedAt             *string    `json:"updatedAt"`
 
+	// Death-of-taxpayer flags (derived, see ApplyDeceasedFlags)
+	IsFinalReturn  bool `json:"isFinalReturn,omitempty"`  // Filing year is the taxpayer's year of death
+	SpouseDeceased bool `json:"spouseDeceased,omitempty"` // Spouse died in or before the filing year
+
 	// Related data
 	Status            *FilingStatus       `json:"status,omitempty"`
 	Documents         []*Document         `json:"documents,omitempty"`
@@ -75,6 +79,15 @@ type Filing struct {
 	Childcares        []*Childcare        `json:"childcares,omitempty"`
 	Payments          []*Payment          `json:"payments,omitempty"`
 	Discounts         []*FilingDiscount   `json:"discounts,omitempty"`
+	StateFilings      []*StateFiling      `json:"stateFilings,omitempty"`
+	Result            *FilingResult       `json:"result,omitempty"` // Recorded return outcome (nil until prepared)
+	Refunds           []*RefundTracking   `json:"refunds,omitempty"`
+
+	// How close the filing is to ready; set in filing lists
+	Completeness *FilingCompleteness `json:"completeness,omitempty"`
+
+	// Documents the filing still needs; set in the portal profile
+	Checklist *FilingChecklist `json:"checklist,omitempty"`
 }
 
 // FilingStatus tracks the progress of a filing
@@ -88,14 +101,15 @@ type FilingStatus struct {
 
 // Document represents an uploaded document
 type Document struct {
-	ID        uuid.UUID  `json:"id"`
-	UserID    uuid.UUID  `json:"userId"`
-	FilingID  *uuid.UUID `json:"filingId"`
-	Name      string     `json:"name"`
-	FilePath  string     `json:"filePath"`
-	Type      string     `json:"type"`
-	CreatedAt string     `json:"createdAt"`
-	UpdatedAt *string    `json:"updatedAt"`
+	ID         uuid.UUID  `json:"id"`
+	UserID     uuid.UUID  `json:"userId"`
+	FilingID   *uuid.UUID `json:"filingId"`
+	Name       string     `json:"name"`
+	FilePath   string     `json:"filePath"`
+	Type       string     `json:"type"`
+	CreatedAt  string     `json:"createdAt"`
+	UpdatedAt  *string    `json:"updatedAt"`
+	ScanStatus *string    `json:"scanStatus,omitempty"` // DocumentScan*; nil when the document was never scanned
 }
 
 // Property represents rental property
@@ -162,9 +176,9 @@ type Payment struct {
 	ID               uuid.UUID      `json:"id"`
 	FilingID         uuid.UUID      `json:"filingId"`
 	StripeSessionID  string         `json:"stripeSessionId"`
-	Amount           float64        `json:"amount"`
-	OriginalAmount   *float64       `json:"originalAmount"`
-	DiscountAmount   *float64       `json:"discountAmount"`
+	Amount           Cents          `json:"amount"`
+	OriginalAmount   *Cents         `json:"originalAmount"`
+	DiscountAmount   *Cents         `json:"discountAmount"`
 	DiscountCode     *string        `json:"discountCode"`
 	Status           string         `json:"status"`
 	CreatedAt        string         `json:"createdAt"`
@@ -179,7 +193,7 @@ type PaymentItem struct {
 	PriceID    string    `json:"priceId"`
 	Name       string    `json:"name"`
 	Quantity   int       `json:"quantity"`
-	UnitAmount float64   `json:"unitAmount"`
+	UnitAmount Cents     `json:"unitAmount"`
 }
 
 // FilingDiscount represents discount applied to a filing
@@ -187,9 +201,9 @@ type FilingDiscount struct {
 	ID             uuid.UUID `json:"id"`
 	FilingID       uuid.UUID `json:"filingId"`
 	DiscountCodeID uuid.UUID `json:"discountCodeId"`
-	OriginalAmount float64   `json:"originalAmount"`
-	DiscountAmount float64   `json:"discountAmount"`
-	FinalAmount    float64   `json:"finalAmount"`
+	OriginalAmount Cents     `json:"originalAmount"`
+	DiscountAmount Cents     `json:"discountAmount"`
+	FinalAmount    Cents     `json:"finalAmount"`
 	AppliedAt      string    `json:"appliedAt"`
 	Code           *string   `json:"code,omitempty"` // Joined from discount_codes
 }
@@ -0,0 +1,51 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ClientExportVersion is the current ClientExport file format
+const ClientExportVersion = 1
+
+// ClientExport is one client's tenant database rows with PII anonymized, written by the
+// clientexport command so support can reproduce a tenant bug against a local tenant
+type ClientExport struct {
+	Version      int            `json:"version"`
+	AdapterType  string         `json:"adapterType"`
+	SourceTenant string         `json:"sourceTenant"`
+	ClientID     uuid.UUID      `json:"clientId"` // Row IDs are kept so logs can be matched to the export
+	ExportedAt   time.Time      `json:"exportedAt"`
+	ExportedBy   string         `json:"exportedBy"`
+	Tables       []*ExportTable `json:"tables"` // In insert order: parents before children
+}
+
+// ExportTable holds the exported rows of one tenant table.
+// Values are the columns' text form; nil is NULL.
+type ExportTable struct {
+	Name    string            `json:"name"`
+	Columns []string          `json:"columns"`
+	PII     map[string]string `json:"pii,omitempty"` // Column -> PII kind applied by the anonymizer
+	Rows    [][]*string       `json:"rows"`
+}
+
+// PII kinds of exported columns
+const (
+	PIIFirstName  = "first_name"
+	PIIMiddleName = "middle_name"
+	PIILastName   = "last_name"
+	PIIName       = "name" // Name of a person or business, such as a childcare provider
+	PIIEmail      = "email"
+	PIIPhone      = "phone"
+	PIISSN        = "ssn"    // Plain or encrypted SSN
+	PIITaxID      = "tax_id" // EIN or SSN of a third party
+	PIIBirthDate  = "dob"
+	PIIStreet     = "street" // Street address line
+	PIICity       = "city"
+	PIIZipcode    = "zipcode"
+	PIIFileName   = "filename"  // Uploaded file name, which often contains the client's name
+	PIIFilePath   = "filepath"  // Storage object path
+	PIIReference  = "reference" // Payment session, discount code or staff identifier
+	PIIText       = "text"      // Free text notes and reasons
+)
@@ -0,0 +1,82 @@
+package types
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// Client search result limits
+const (
+	DefaultClientSearchLimit = 25
+	MaxClientSearchLimit     = 100
+)
+
+// What a client search result matched, best first
+const (
+	ClientMatchEmail = "email" // The whole email address
+	ClientMatchSSN   = "ssn"   // The last four digits of the SSN
+	ClientMatchName  = "name"  // The full name, or the start of the first or last name
+	ClientMatchPhone = "phone" // Digits of the phone number
+	ClientMatchOther = "partial"
+)
+
+// ssnLast4Pattern accepts a bare last four or a masked SSN such as ***-**-1234 or xxx-xx-1234
+var ssnLast4Pattern = regexp.MustCompile(`^(?:[*xX]{3}-?[*xX]{2}-?)?(\d{4})$`)
+
+// ClientSearchQuery is a parsed client search
+type ClientSearchQuery struct {
+	Text            string // Matched against names, email and phone; empty for a masked SSN
+	Digits          string // Digits of Text, matched against phone numbers when there are at least 3
+	SSNLast4        string // Set when the search is four digits or a masked SSN
+	IncludeArchived bool
+	Limit           int
+}
+
+// MinClientSearchLength is the fewest characters a client search may have
+const MinClientSearchLength = 2
+
+// NewClientSearchQuery parses the q parameter of a client search. Four digits are searched as
+// both an SSN last four and part of a phone number.
+func NewClientSearchQuery(q string, includeArchived bool, limit int) *ClientSearchQuery {
+	q = strings.TrimSpace(q)
+	if limit <= 0 {
+		limit = DefaultClientSearchLimit
+	}
+	if limit > MaxClientSearchLimit {
+		limit = MaxClientSearchLimit
+	}
+
+	query := &ClientSearchQuery{IncludeArchived: includeArchived, Limit: limit}
+	if m := ssnLast4Pattern.FindStringSubmatch(q); m != nil {
+		query.SSNLast4 = m[1]
+		if m[1] != q {
+			return query // Masked SSNs are not names or phone numbers
+		}
+	}
+	query.Text = q
+	query.Digits = strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, q)
+	return query
+}
+
+// ClientSearchResult is a client matching a search, with a summary of its filings
+type ClientSearchResult struct {
+	Client    *Client               `json:"client"`
+	Score     int                   `json:"score"`     // Higher is a better match
+	MatchedOn string                `json:"matchedOn"` // ClientMatch*
+	Filings   []*ClientSearchFiling `json:"filings"`   // Newest tax year first
+}
+
+// ClientSearchFiling summarizes one filing of a search result
+type ClientSearchFiling struct {
+	FilingID  uuid.UUID `json:"filingId"`
+	Year      int       `json:"year"`
+	Completed bool      `json:"completed"`
+}
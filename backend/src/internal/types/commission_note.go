@@ -0,0 +1,34 @@
+package types
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CommissionNote is an append-only admin annotation on a commission
+type CommissionNote struct {
+	ID           uuid.UUID  `json:"id"`
+	TenantID     string     `json:"tenantId"`
+	CommissionID uuid.UUID  `json:"commissionId"`
+	AuthorID     *uuid.UUID `json:"authorId,omitempty"`
+	AuthorEmail  *string    `json:"authorEmail,omitempty"`
+	Body         string     `json:"body"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// Common commission tags; any tag matching commissionTagPattern is accepted
+const (
+	CommissionTagHold = "HOLD" // Hold pending chargeback window or investigation
+	CommissionTagVIP  = "VIP"
+)
+
+var commissionTagPattern = regexp.MustCompile(`^[A-Z0-9][A-Z0-9_-]{0,49}$`)
+
+// NormalizeCommissionTag upper-cases a tag and reports whether it is valid
+func NormalizeCommissionTag(tag string) (string, bool) {
+	tag = strings.ToUpper(strings.TrimSpace(tag))
+	return tag, commissionTagPattern.MatchString(tag)
+}
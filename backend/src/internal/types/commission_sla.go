@@ -0,0 +1,32 @@
+package types
+
+// CommissionSLA configures alerts about commissions left waiting for approval.
+// Stored per tenant in tenant_connections.commission_sla (JSONB); tenants without
+// a configuration fall back to DefaultCommissionSLA.
+type CommissionSLA struct {
+	Enabled      bool `json:"enabled"`
+	PendingDays  int  `json:"pendingDays"`  // Days a commission may stay PENDING before it is overdue
+	ReminderDays int  `json:"reminderDays"` // Days before admins are alerted again about the same overdue commissions (0 alerts once)
+}
+
+// DefaultCommissionSLA returns the SLA applied when a tenant has no configuration
+func DefaultCommissionSLA() *CommissionSLA {
+	return &CommissionSLA{
+		Enabled:      true,
+		PendingDays:  14,
+		ReminderDays: 7,
+	}
+}
+
+// OverdueCommission is a PENDING commission past the tenant's approval SLA
+type OverdueCommission struct {
+	*Commission
+	DaysPending int `json:"daysPending"`
+}
+
+// OverdueCommissionReport lists a tenant's overdue commissions, oldest first
+type OverdueCommissionReport struct {
+	PendingDays int                  `json:"pendingDays"` // SLA the commissions were checked against
+	TotalCount  int                  `json:"totalCount"`  // Overdue commissions, including any beyond the listed ones
+	Commissions []*OverdueCommission `json:"commissions"`
+}
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ConsentTemplate is the versioned consent wording shown to taxpayers for one purpose
+type ConsentTemplate struct {
+	ID        uuid.UUID  `json:"id"`
+	TenantID  string     `json:"tenantId"`
+	Purpose   string     `json:"purpose"`
+	Version   int        `json:"version"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	IsActive  bool       `json:"isActive"`
+	CreatedBy *uuid.UUID `json:"createdBy,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// ClientConsent is a taxpayer's grant (and possible revocation) of a consent
+type ClientConsent struct {
+	ID           uuid.UUID  `json:"id"`
+	TenantID     string     `json:"tenantId"`
+	ClientID     uuid.UUID  `json:"clientId"`
+	TemplateID   uuid.UUID  `json:"templateId"`
+	Version      int        `json:"version"`
+	Purpose      string     `json:"purpose"`
+	TenantUserID *uuid.UUID `json:"tenantUserId,omitempty"`
+	GrantedAt    time.Time  `json:"grantedAt"`
+	GrantedIP    *string    `json:"grantedIp,omitempty"`
+	RevokedAt    *time.Time `json:"revokedAt,omitempty"`
+	RevokedIP    *string    `json:"revokedIp,omitempty"`
+}
+
+// IsActive reports whether the consent is currently in force
+func (c *ClientConsent) IsActive() bool {
+	return c.RevokedAt == nil
+}
+
+// ConsentStatus pairs an active template with the taxpayer's current consent, if any
+type ConsentStatus struct {
+	Template *ConsentTemplate `json:"template"`
+	Consent  *ClientConsent   `json:"consent,omitempty"`
+	Granted  bool             `json:"granted"`
+}
+
+// Consent purposes requiring a signed 7216 consent
+const (
+	ConsentPurposeAffiliateDisclosure = "AFFILIATE_DISCLOSURE" // Share identity with the referring affiliate
+	ConsentPurposeMarketing           = "MARKETING"            // Use return information to offer other services
+	ConsentPurposeThirdPartyOffers    = "THIRD_PARTY_OFFERS"   // Disclose to third parties for their offers
+)
+
+// ConsentPurposes lists every supported purpose
+var ConsentPurposes = []string{
+	ConsentPurposeAffiliateDisclosure,
+	ConsentPurposeMarketing,
+	ConsentPurposeThirdPartyOffers,
+}
+
+// IsValidConsentPurpose checks a purpose value
+func IsValidConsentPurpose(p string) bool {
+	for _, purpose := range ConsentPurposes {
+		if purpose == p {
+			return true
+		}
+	}
+	return false
+}
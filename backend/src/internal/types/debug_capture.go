@@ -0,0 +1,49 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DebugCapture is a period during which a tenant's API requests and responses are captured
+type DebugCapture struct {
+	ID         uuid.UUID  `json:"id"`
+	TenantID   string     `json:"tenantId"`
+	Reason     string     `json:"reason"`
+	EnabledBy  uuid.UUID  `json:"enabledBy"`
+	EnabledAt  time.Time  `json:"enabledAt"`
+	ExpiresAt  time.Time  `json:"expiresAt"`
+	DisabledAt *time.Time `json:"disabledAt,omitempty"`
+	DisabledBy *uuid.UUID `json:"disabledBy,omitempty"`
+}
+
+// DebugCaptureEntry is one captured request with its response. Bodies are JSON with the
+// configured fields redacted; other bodies are replaced by a note of their content type.
+type DebugCaptureEntry struct {
+	ID           uuid.UUID `json:"id"`
+	CaptureID    uuid.UUID `json:"captureId"`
+	TenantID     string    `json:"tenantId"`
+	Method       string    `json:"method"`
+	Path         string    `json:"path"`
+	Query        *string   `json:"query,omitempty"`
+	Status       int       `json:"status"`
+	DurationMs   int       `json:"durationMs"`
+	RequestBody  *string   `json:"requestBody,omitempty"`
+	ResponseBody *string   `json:"responseBody,omitempty"`
+	CapturedAt   time.Time `json:"capturedAt"`
+}
+
+const (
+	// DebugCaptureDefaultDuration is how long a capture runs when no duration is given
+	DebugCaptureDefaultDuration = time.Hour
+	// DebugCaptureMaxDuration caps how long a capture runs
+	DebugCaptureMaxDuration = 24 * time.Hour
+	// DebugCaptureRetention is how long captured entries are kept
+	DebugCaptureRetention = 7 * 24 * time.Hour
+	// DebugCaptureMaxBodyBytes caps each captured body; longer bodies are not captured
+	DebugCaptureMaxBodyBytes = 64 << 10
+)
+
+// DefaultDebugRedactFields are redacted from captures when the configuration names none
+var DefaultDebugRedactFields = []string{"ssn", "dob", "dateOfBirth", "password", "token", "secret"}
@@ -0,0 +1,186 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Deceased person identifiers
+const (
+	DeceasedPersonTaxpayer = "taxpayer"
+	DeceasedPersonSpouse   = "spouse"
+)
+
+// Document types required when a taxpayer or spouse is deceased
+const (
+	DocumentTypeDeathCertificate    = "death_certificate"
+	DocumentTypeForm1310            = "form_1310"            // Statement of Person Claiming Refund Due a Deceased Taxpayer
+	DocumentTypeLettersTestamentary = "letters_testamentary" // Court appointment of the personal representative
+)
+
+// ChecklistItem is a single required document in a workflow checklist
+type ChecklistItem struct {
+	DocumentType string `json:"documentType"`
+	Description  string `json:"description"`
+	Required     bool   `json:"required"`
+	Provided     bool   `json:"provided"`
+}
+
+// DeceasedStatus summarizes the death-of-taxpayer workflow for a client
+type DeceasedStatus struct {
+	Person          string           `json:"person"` // taxpayer or spouse
+	DeathDate       string           `json:"deathDate"`
+	FinalReturnYear int              `json:"finalReturnYear"` // Tax year of the final (or last joint) return
+	Checklist       []*ChecklistItem `json:"checklist"`
+	Complete        bool             `json:"complete"` // All required documents provided
+}
+
+// RolloverCheck is the result of validating a subsequent-year filing rollover
+type RolloverCheck struct {
+	Year        int      `json:"year"`
+	Allowed     bool     `json:"allowed"`
+	Reason      string   `json:"reason,omitempty"`
+	Adjustments []string `json:"adjustments,omitempty"`
+}
+
+// ParseDeathDate parses a YYYY-MM-DD (or timestamp-prefixed) death date
+func ParseDeathDate(s string) (time.Time, error) {
+	if len(s) >= 10 {
+		s = s[:10]
+	}
+	return time.Parse("2006-01-02", s)
+}
+
+// deathYear returns the year of a death date, or 0 if unset or invalid
+func deathYear(date *string) int {
+	if date == nil || *date == "" {
+		return 0
+	}
+	t, err := ParseDeathDate(*date)
+	if err != nil {
+		return 0
+	}
+	return t.Year()
+}
+
+// ApplyDeceasedFlags marks filings affected by a taxpayer or spouse death.
+// This is adapter independent and runs on the universal comprehensive type.
+func (c *ClientComprehensive) ApplyDeceasedFlags() {
+	if c == nil || c.Client == nil {
+		return
+	}
+
+	taxpayerYear := deathYear(c.Client.DeathDate)
+	spouseYear := 0
+	if c.Spouse != nil && c.Spouse.IsDeath {
+		spouseYear = deathYear(c.Spouse.DeathDate)
+	}
+
+	for _, f := range c.Filings {
+		if taxpayerYear > 0 && f.Year == taxpayerYear {
+			f.IsFinalReturn = true
+		}
+		if spouseYear > 0 && f.Year >= spouseYear {
+			f.SpouseDeceased = true
+		}
+	}
+}
+
+// DeceasedStatuses returns the workflow status for each deceased person on the client
+func (c *ClientComprehensive) DeceasedStatuses() []*DeceasedStatus {
+	var statuses []*DeceasedStatus
+	if c == nil || c.Client == nil {
+		return statuses
+	}
+
+	provided := map[string]bool{}
+	for _, f := range c.Filings {
+		for _, d := range f.Documents {
+			provided[strings.ToLower(d.Type)] = true
+		}
+	}
+
+	if c.Client.DeathDate != nil && *c.Client.DeathDate != "" {
+		statuses = append(statuses, newDeceasedStatus(DeceasedPersonTaxpayer, *c.Client.DeathDate, provided))
+	}
+	if c.Spouse != nil && c.Spouse.IsDeath && c.Spouse.DeathDate != nil {
+		statuses = append(statuses, newDeceasedStatus(DeceasedPersonSpouse, *c.Spouse.DeathDate, provided))
+	}
+
+	return statuses
+}
+
+func newDeceasedStatus(person, deathDate string, provided map[string]bool) *DeceasedStatus {
+	status := &DeceasedStatus{
+		Person:          person,
+		DeathDate:       deathDate,
+		FinalReturnYear: deathYear(&deathDate),
+	}
+
+	status.Checklist = append(status.Checklist, &ChecklistItem{
+		DocumentType: DocumentTypeDeathCertificate,
+		Description:  "Certified copy of the death certificate",
+		Required:     true,
+	})
+	if person == DeceasedPersonTaxpayer {
+		status.Checklist = append(status.Checklist,
+			&ChecklistItem{
+				DocumentType: DocumentTypeLettersTestamentary,
+				Description:  "Court appointment of the personal representative (letters testamentary)",
+				Required:     true,
+			},
+			&ChecklistItem{
+				DocumentType: DocumentTypeForm1310,
+				Description:  "Form 1310 if a refund is claimed by someone other than a surviving spouse",
+				Required:     false,
+			},
+		)
+	}
+
+	status.Complete = true
+	for _, item := range status.Checklist {
+		item.Provided = provided[item.DocumentType]
+		if item.Required && !item.Provided {
+			status.Complete = false
+		}
+	}
+
+	return status
+}
+
+// CheckRollover validates whether a filing can be rolled over into the given year.
+// Rollovers after a taxpayer's final return are blocked; rollovers after a spouse's
+// death are allowed with adjustments to filing status and spouse data.
+func (c *ClientComprehensive) CheckRollover(year int) *RolloverCheck {
+	check := &RolloverCheck{Year: year, Allowed: true}
+	if c == nil || c.Client == nil {
+		return check
+	}
+
+	if taxpayerYear := deathYear(c.Client.DeathDate); taxpayerYear > 0 && year > taxpayerYear {
+		check.Allowed = false
+		check.Reason = fmt.Sprintf("taxpayer deceased; %d was the final return year", taxpayerYear)
+		return check
+	}
+
+	if c.Spouse != nil && c.Spouse.IsDeath {
+		spouseYear := deathYear(c.Spouse.DeathDate)
+		switch {
+		case spouseYear == 0:
+			check.Adjustments = append(check.Adjustments, "spouse marked deceased without a death date; confirm filing status")
+		case year == spouseYear:
+			check.Adjustments = append(check.Adjustments, "joint return may still be filed for the year of the spouse's death")
+		case year > spouseYear && year <= spouseYear+2:
+			check.Adjustments = append(check.Adjustments,
+				"remove deceased spouse from the return",
+				"qualifying surviving spouse status may apply if a dependent child lives with the taxpayer")
+		case year > spouseYear+2:
+			check.Adjustments = append(check.Adjustments,
+				"remove deceased spouse from the return",
+				"filing status must be single or head of household")
+		}
+	}
+
+	return check
+}
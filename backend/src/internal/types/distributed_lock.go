@@ -0,0 +1,21 @@
+package types
+
+import "time"
+
+// LockStuckAfter is how long a distributed lock may be held before it is reported as stuck
+const LockStuckAfter = 30 * time.Minute
+
+// DistributedLock is the usage of one Postgres advisory lock shared by API and worker processes
+type DistributedLock struct {
+	Name           string     `json:"name"`
+	Holder         *string    `json:"holder,omitempty"`      // Process holding the lock; nil when free
+	AcquiredAt     *time.Time `json:"acquiredAt,omitempty"`  // Start of the current hold
+	HeldSeconds    int64      `json:"heldSeconds,omitempty"` // Length of the current hold
+	Stuck          bool       `json:"stuck"`                 // Held for longer than LockStuckAfter
+	StuckAlertedAt *time.Time `json:"stuckAlertedAt,omitempty"`
+	Acquisitions   int64      `json:"acquisitions"`
+	Contentions    int64      `json:"contentions"` // Attempts skipped because another process held the lock
+	AvgHoldMs      int64      `json:"avgHoldMs"`
+	MaxHoldMs      int64      `json:"maxHoldMs"`
+	LastReleasedAt *time.Time `json:"lastReleasedAt,omitempty"`
+}
@@ -0,0 +1,71 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DocumentDrop is a location a partner such as a payroll provider delivers document batches to.
+// Each batch is a directory under Prefix holding the documents and a manifest.csv that maps
+// every file to a client.
+type DocumentDrop struct {
+	ID            uuid.UUID  `json:"id"`
+	TenantID      string     `json:"tenantId"`
+	Name          string     `json:"name"`
+	SourceType    string     `json:"sourceType"`
+	Location      string     `json:"location"` // GCS bucket, or SFTP directory under the configured SFTP root
+	Prefix        string     `json:"prefix"`
+	DocumentType  string     `json:"documentType"` // Used when the manifest gives no document_type
+	IsActive      bool       `json:"isActive"`
+	LastScannedAt *time.Time `json:"lastScannedAt,omitempty"`
+	LastError     *string    `json:"lastError,omitempty"`
+	CreatedBy     *uuid.UUID `json:"createdBy,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// DocumentDropFile is one file taken from a drop and the outcome of matching it to a client
+type DocumentDropFile struct {
+	ID         uuid.UUID  `json:"id"`
+	DropID     uuid.UUID  `json:"dropId"`
+	TenantID   string     `json:"tenantId"`
+	Path       string     `json:"path"`
+	Batch      string     `json:"batch"`
+	FileName   string     `json:"fileName"`
+	Status     string     `json:"status"`
+	Reason     *string    `json:"reason,omitempty"` // Why the file could not be matched
+	ClientID   *uuid.UUID `json:"clientId,omitempty"`
+	FilingID   *uuid.UUID `json:"filingId,omitempty"`
+	DocumentID *uuid.UUID `json:"documentId,omitempty"`
+	ReceivedAt time.Time  `json:"receivedAt"`
+	ResolvedBy *uuid.UUID `json:"resolvedBy,omitempty"`
+	ResolvedAt *time.Time `json:"resolvedAt,omitempty"`
+}
+
+// DocumentDropScan summarizes one scan of a drop
+type DocumentDropScan struct {
+	DropID    uuid.UUID `json:"dropId"`
+	TenantID  string    `json:"tenantId"`
+	Name      string    `json:"name"`
+	Imported  int       `json:"imported"`
+	Unmatched int       `json:"unmatched"`
+	Errors    []string  `json:"errors"`   // Problems that leave files in place to retry on the next scan
+	NewError  bool      `json:"newError"` // Errors differ from the previous scan's, so nobody was alerted yet
+}
+
+// Document drop source types
+const (
+	DocumentDropSourceGCS  = "GCS"
+	DocumentDropSourceSFTP = "SFTP"
+)
+
+// Document drop file statuses
+const (
+	DropFileStatusImported  = "IMPORTED"  // Matched from the manifest and imported
+	DropFileStatusUnmatched = "UNMATCHED" // Waiting for an admin to pick the client
+	DropFileStatusResolved  = "RESOLVED"  // Imported after an admin picked the client
+	DropFileStatusIgnored   = "IGNORED"   // Dismissed by an admin without importing
+)
+
+// DropManifestName is the file that marks a batch as complete and maps its files to clients
+const DropManifestName = "manifest.csv"
@@ -0,0 +1,95 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DocumentExpiration is the expiry date set on a client document, such as an ID or POA
+type DocumentExpiration struct {
+	TenantID     string     `json:"tenantId"`
+	DocumentID   uuid.UUID  `json:"documentId"`
+	ClientID     uuid.UUID  `json:"clientId"`
+	Source       string     `json:"source"` // DocumentSource*
+	DocumentType string     `json:"documentType"`
+	DocumentName string     `json:"documentName"`
+	ExpiresOn    string     `json:"expiresOn"` // YYYY-MM-DD
+	SetBy        *uuid.UUID `json:"setBy,omitempty"`
+	RequestID    *uuid.UUID `json:"requestId,omitempty"` // Replacement request raised for this document
+}
+
+// Expiring document sources
+const (
+	DocumentSourceDocument         = "DOCUMENT"          // Filing document; expiry set by staff
+	DocumentSourceIdentityDocument = "IDENTITY_DOCUMENT" // Portal photo ID; expiry read from the ID
+)
+
+// DocumentRequest asks a client to upload a document. Requests are raised by staff or,
+// for documents about to expire, by the document expiry job, and are fulfilled when a
+// document of the requested type is added for the client.
+type DocumentRequest struct {
+	ID                  uuid.UUID  `json:"id"`
+	TenantID            string     `json:"tenantId"`
+	ClientID            uuid.UUID  `json:"clientId"`
+	FilingID            *uuid.UUID `json:"filingId,omitempty"` // Filing the document is for; nil for the client as a whole
+	DocumentType        string     `json:"documentType"`
+	Description         string     `json:"description"`
+	Reason              string     `json:"reason"`
+	SourceDocumentID    *uuid.UUID `json:"sourceDocumentId,omitempty"`
+	DueOn               *string    `json:"dueOn,omitempty"` // YYYY-MM-DD; the source document's expiry date
+	Status              string     `json:"status"`
+	RequestedBy         *uuid.UUID `json:"requestedBy,omitempty"`
+	ReminderCount       int        `json:"reminderCount"`
+	LastRemindedAt      *time.Time `json:"lastRemindedAt,omitempty"`
+	FulfilledDocumentID *uuid.UUID `json:"fulfilledDocumentId,omitempty"`
+	ClosedBy            *uuid.UUID `json:"closedBy,omitempty"`
+	ClosedAt            *time.Time `json:"closedAt,omitempty"`
+	CreatedAt           time.Time  `json:"createdAt"`
+}
+
+// Document request reasons
+const (
+	DocumentRequestExpiring = "EXPIRING"     // Raised by the document expiry job
+	DocumentRequestManual   = "MANUAL"       // Raised by staff
+	DocumentRequestMissing  = "MISSING_FILE" // Raised when a document's file was lost from storage
+)
+
+// Document request statuses
+const (
+	DocumentRequestOpen      = "OPEN"
+	DocumentRequestFulfilled = "FULFILLED"
+	DocumentRequestCancelled = "CANCELLED"
+)
+
+// ChecklistItem presents the request as an item of the client's document checklist
+func (r *DocumentRequest) ChecklistItem() *ChecklistItem {
+	return &ChecklistItem{
+		DocumentType: r.DocumentType,
+		Description:  r.Description,
+		Required:     true,
+		Provided:     r.Status == DocumentRequestFulfilled,
+	}
+}
+
+// DocumentChecklist is a client's document requests and the checklist built from them
+type DocumentChecklist struct {
+	Requests  []*DocumentRequest `json:"requests"`
+	Checklist []*ChecklistItem   `json:"checklist"`
+	Complete  bool               `json:"complete"` // No request is open
+}
+
+// NewDocumentChecklist builds the checklist of a client's requests; cancelled requests are left out
+func NewDocumentChecklist(requests []*DocumentRequest) *DocumentChecklist {
+	checklist := &DocumentChecklist{Requests: requests, Checklist: []*ChecklistItem{}, Complete: true}
+	for _, r := range requests {
+		if r.Status == DocumentRequestCancelled {
+			continue
+		}
+		checklist.Checklist = append(checklist.Checklist, r.ChecklistItem())
+		if r.Status == DocumentRequestOpen {
+			checklist.Complete = false
+		}
+	}
+	return checklist
+}
@@ -0,0 +1,29 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DocumentScan is the malware scan of a document in a tenant database
+type DocumentScan struct {
+	TenantID       string     `json:"tenantId"`
+	DocumentID     uuid.UUID  `json:"documentId"`
+	Status         string     `json:"status"`  // DocumentScan*
+	Scanner        string     `json:"scanner"` // Provider that gave (or failed to give) the verdict
+	Threat         *string    `json:"threat,omitempty"`
+	Error          *string    `json:"error,omitempty"` // Why the last scan gave no verdict
+	QuarantinePath *string    `json:"quarantinePath,omitempty"`
+	Attempts       int        `json:"attempts"`
+	ScannedAt      *time.Time `json:"scannedAt,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+	UpdatedAt      time.Time  `json:"updatedAt"`
+}
+
+// Document scan statuses. Only CLEAN documents, and documents never scanned, can be downloaded.
+const (
+	DocumentScanPending  = "PENDING"
+	DocumentScanClean    = "CLEAN"
+	DocumentScanInfected = "INFECTED"
+)
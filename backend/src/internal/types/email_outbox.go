@@ -0,0 +1,81 @@
+package types
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Outbox email kinds
+const (
+	OutboxEmailPortalLink      = "PORTAL_LINK"      // Portal sign-in link from a SEND_PORTAL_LINK bulk operation
+	OutboxEmailFilingCompleted = "FILING_COMPLETED" // Sent when a filing is marked completed
+	OutboxEmailFilingStatus    = "FILING_STATUS"    // Sent when a filing is filed or accepted
+)
+
+// Outbox email statuses
+const (
+	OutboxEmailPending    = "PENDING"
+	OutboxEmailSent       = "SENT"
+	OutboxEmailFailed     = "FAILED"     // Gave up after MaxEmailAttempts
+	OutboxEmailSuppressed = "SUPPRESSED" // The recipient is on the suppression list, so it was not sent
+	OutboxEmailBounced    = "BOUNCED"    // Sent, then bounced by the recipient's mail server
+)
+
+// MaxEmailAttempts is how many times an outbox email is attempted before it is marked failed
+const MaxEmailAttempts = 6
+
+// OutboxEmail is one email queued for the worker to send
+type OutboxEmail struct {
+	ID            uuid.UUID  `json:"id"`
+	TenantID      *string    `json:"tenantId,omitempty"` // Nil for platform mail
+	Kind          string     `json:"kind"`
+	Priority      string     `json:"priority"` // notification.PriorityTransactional or PriorityBulk
+	ToEmail       string     `json:"toEmail"`
+	ToName        *string    `json:"toName,omitempty"`
+	Subject       string     `json:"subject"`
+	HTMLBody      string     `json:"-"` // Bodies can hold sign-in links, so they are never listed
+	TextBody      string     `json:"-"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt *time.Time `json:"nextAttemptAt,omitempty"`
+	LastError     *string    `json:"lastError,omitempty"`
+	CreatedAt     time.Time  `json:"createdAt"`
+	SentAt        *time.Time `json:"sentAt,omitempty"`
+}
+
+// OutboxEmailFilter narrows the admin listing of outbox emails; nil fields match every email
+type OutboxEmailFilter struct {
+	TenantID *string
+	Kind     *string
+	Status   *string
+	ToEmail  *string // Exact address, case-insensitive
+	Limit    int
+}
+
+// Email suppression reasons
+const (
+	EmailSuppressionBounce     = "BOUNCE"      // SendGrid reported a hard bounce
+	EmailSuppressionSpamReport = "SPAM_REPORT" // The recipient marked an email as spam
+	EmailSuppressionManual     = "MANUAL"      // Added by an admin
+)
+
+// EmailSuppression is an address queued email is not sent to
+type EmailSuppression struct {
+	Email     string     `json:"email"`
+	Reason    string     `json:"reason"`
+	Detail    *string    `json:"detail,omitempty"`
+	CreatedBy *uuid.UUID `json:"createdBy,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// Validate checks an admin-added suppression and normalizes its address
+func (e *EmailSuppression) Validate() string {
+	e.Email = strings.ToLower(strings.TrimSpace(e.Email))
+	if e.Email == "" || !strings.Contains(e.Email, "@") {
+		return "a valid email is required"
+	}
+	e.Reason = EmailSuppressionManual
+	return ""
+}
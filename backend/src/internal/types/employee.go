@@ -13,7 +13,7 @@ type Employee struct {
 	Email       string    `json:"email"`
 	FirstName   *string   `json:"firstName,omitempty"`
 	LastName    *string   `json:"lastName,omitempty"`
-	Role        string    `json:"role"` // 'admin', 'accountant', 'support'
+	Role        string    `json:"role"` // 'admin', 'accountant', 'viewer', 'support', 'affiliate_manager'
 	IsActive    bool      `json:"isActive"`
 	CreatedAt   time.Time `json:"createdAt"`
 	UpdatedAt   time.Time `json:"updatedAt"`
@@ -38,6 +38,11 @@ func (e *Employee) IsAdmin() bool {
 	return e.Role == "admin"
 }
 
+// HasCapability checks if the employee's role grants a capability
+func (e *Employee) HasCapability(capability string) bool {
+	return RoleHasCapability(e.Role, capability)
+}
+
 // CanAccessTenant checks if employee can access a specific tenant
 // In the future, this could check against employee-tenant associations
 func (e *Employee) CanAccessTenant(tenantID string) bool {
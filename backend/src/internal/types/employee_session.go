@@ -0,0 +1,40 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EmployeeSession is an employee sign-in made through the API. Its refresh token is rotated on
+// every refresh, and revoking it rejects the ID tokens issued from it.
+type EmployeeSession struct {
+	ID              uuid.UUID  `json:"id"`
+	EmployeeID      uuid.UUID  `json:"employeeId"`
+	EmployeeEmail   string     `json:"employeeEmail,omitempty"`
+	IPAddress       *string    `json:"ipAddress,omitempty"`
+	UserAgent       *string    `json:"userAgent,omitempty"`
+	CreatedAt       time.Time  `json:"createdAt"`
+	LastRefreshedAt *time.Time `json:"lastRefreshedAt,omitempty"`
+	ExpiresAt       time.Time  `json:"expiresAt"`
+	RevokedAt       *time.Time `json:"revokedAt,omitempty"`
+	RevokedBy       *uuid.UUID `json:"revokedBy,omitempty"` // nil unless an admin revoked it
+	RevokeReason    *string    `json:"revokeReason,omitempty"`
+	FirebaseUID     string     `json:"-"`
+	AuthTime        int64      `json:"-"` // auth_time claim of the sign-in
+}
+
+// IsActive reports whether the session can still be refreshed
+func (s *EmployeeSession) IsActive() bool {
+	return s.RevokedAt == nil && time.Now().Before(s.ExpiresAt)
+}
+
+// EmployeeSessionLifetime is how long a session can be refreshed before signing in again
+const EmployeeSessionLifetime = 14 * 24 * time.Hour
+
+// Session revoke reasons
+const (
+	SessionRevokeLogout     = "LOGOUT"
+	SessionRevokeAdmin      = "ADMIN"
+	SessionRevokeTokenReuse = "TOKEN_REUSE" // A rotated refresh token was presented again
+)
@@ -8,14 +8,14 @@ import (
 
 // EmployeeTenantAssociation represents the relationship between an employee and a tenant
 type EmployeeTenantAssociation struct {
-	ID         uuid.UUID `json:"id"`
-	EmployeeID uuid.UUID `json:"employeeId"`
-	TenantID   string    `json:"tenantId"`
-	Role       string    `json:"role"`     // Role within this tenant: 'admin', 'accountant', 'viewer'
-	IsActive   bool      `json:"isActive"` // Can this employee access this tenant?
-	CreatedAt  time.Time `json:"createdAt"`
-	UpdatedAt  time.Time `json:"updatedAt"`
-	CreatedBy  uuid.UUID `json:"createdBy"` // Who granted this access
+	ID         uuid.UUID  `json:"id"`
+	EmployeeID uuid.UUID  `json:"employeeId"`
+	TenantID   string     `json:"tenantId"`
+	Role       string     `json:"role"`     // Role within this tenant: 'admin', 'accountant', 'viewer', 'affiliate_manager'
+	IsActive   bool       `json:"isActive"` // Can this employee access this tenant?
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
+	CreatedBy  *uuid.UUID `json:"createdBy,omitempty"` // Who granted this access
 }
 
 // EmployeeWithTenants represents an employee with their tenant associations
@@ -31,3 +31,12 @@ type TenantAccess struct {
 	Role       string `json:"role"`
 	IsActive   bool   `json:"isActive"`
 }
+
+// TenantEmployee is an employee with access to a tenant, as listed for the tenant's admins
+type TenantEmployee struct {
+	EmployeeTenantAssociation
+	Email        string  `json:"email"`
+	FirstName    *string `json:"firstName,omitempty"`
+	LastName     *string `json:"lastName,omitempty"`
+	EmployeeRole string  `json:"employeeRole"` // The employee's own role, which bounds what they can call
+}
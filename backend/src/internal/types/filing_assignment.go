@@ -0,0 +1,16 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FilingAssignment is the accountant working a filing
+type FilingAssignment struct {
+	TenantID   string     `json:"tenantId"`
+	FilingID   uuid.UUID  `json:"filingId"`
+	EmployeeID uuid.UUID  `json:"employeeId"`
+	AssignedBy *uuid.UUID `json:"assignedBy,omitempty"`
+	AssignedAt time.Time  `json:"assignedAt"`
+}
@@ -0,0 +1,242 @@
+package types
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+// Document types a filing's checklist asks for. Uploaded documents match when their type is the
+// same ignoring case and punctuation, so W-2 and w2 both provide W2.
+const (
+	DocumentTypeW2             = "W2"
+	DocumentType1099NEC        = "1099-NEC"
+	DocumentType1099MISC       = "1099-MISC"
+	DocumentType1099INT        = "1099-INT"
+	DocumentType1099DIV        = "1099-DIV"
+	DocumentType1099B          = "1099-B"
+	DocumentType1099R          = "1099-R"
+	DocumentType1099G          = "1099-G"
+	DocumentType1099K          = "1099-K"
+	DocumentTypeSSA1099        = "SSA-1099"
+	DocumentTypeW2G            = "W2-G"
+	DocumentTypeK1             = "K-1"
+	DocumentType1098           = "1098"
+	DocumentType1098E          = "1098-E"
+	DocumentType1098T          = "1098-T"
+	DocumentType1099SA         = "1099-SA"
+	DocumentType1095A          = "1095-A"
+	DocumentTypeCharityReceipt = "charity_receipts"
+	DocumentTypeChildcare      = "childcare_statement"
+	DocumentTypePropertyTax    = "property_tax_statement"
+	DocumentTypeMedical        = "medical_receipts"
+)
+
+// Checklist item sources: the part of the filing that needs the document
+const (
+	ChecklistSourceIncome      = "sourceOfIncome"
+	ChecklistSourceDeduction   = "deduction"
+	ChecklistSourceDependent   = "dependent"
+	ChecklistSourceMarketplace = "marketplaceInsurance"
+)
+
+// checklistDocument is a document a reported income source or deduction needs
+type checklistDocument struct {
+	documentType string
+	description  string
+}
+
+// incomeDocuments maps income sources, as normalizeChecklistKey leaves them, to their documents
+var incomeDocuments = map[string]checklistDocument{
+	"w2":             {DocumentTypeW2, "Form W-2 from each employer"},
+	"wages":          {DocumentTypeW2, "Form W-2 from each employer"},
+	"salary":         {DocumentTypeW2, "Form W-2 from each employer"},
+	"employment":     {DocumentTypeW2, "Form W-2 from each employer"},
+	"1099nec":        {DocumentType1099NEC, "Form 1099-NEC from each client you worked for"},
+	"selfemployment": {DocumentType1099NEC, "Form 1099-NEC from each client you worked for"},
+	"selfemployed":   {DocumentType1099NEC, "Form 1099-NEC from each client you worked for"},
+	"freelance":      {DocumentType1099NEC, "Form 1099-NEC from each client you worked for"},
+	"contractor":     {DocumentType1099NEC, "Form 1099-NEC from each client you worked for"},
+	"business":       {DocumentType1099NEC, "Form 1099-NEC from each client you worked for"},
+	"1099k":          {DocumentType1099K, "Form 1099-K from each payment platform"},
+	"gig":            {DocumentType1099K, "Form 1099-K from each payment platform"},
+	"1099misc":       {DocumentType1099MISC, "Form 1099-MISC for rents, royalties or other income"},
+	"rental":         {DocumentType1099MISC, "Form 1099-MISC for rents, royalties or other income"},
+	"rentalincome":   {DocumentType1099MISC, "Form 1099-MISC for rents, royalties or other income"},
+	"royalties":      {DocumentType1099MISC, "Form 1099-MISC for rents, royalties or other income"},
+	"1099int":        {DocumentType1099INT, "Form 1099-INT from each bank"},
+	"interest":       {DocumentType1099INT, "Form 1099-INT from each bank"},
+	"1099div":        {DocumentType1099DIV, "Form 1099-DIV from each brokerage or fund"},
+	"dividends":      {DocumentType1099DIV, "Form 1099-DIV from each brokerage or fund"},
+	"1099b":          {DocumentType1099B, "Form 1099-B for investments sold"},
+	"investments":    {DocumentType1099B, "Form 1099-B for investments sold"},
+	"stocks":         {DocumentType1099B, "Form 1099-B for investments sold"},
+	"capitalgains":   {DocumentType1099B, "Form 1099-B for investments sold"},
+	"crypto":         {DocumentType1099B, "Form 1099-B for investments sold"},
+	"1099r":          {DocumentType1099R, "Form 1099-R for pension, annuity or IRA distributions"},
+	"retirement":     {DocumentType1099R, "Form 1099-R for pension, annuity or IRA distributions"},
+	"pension":        {DocumentType1099R, "Form 1099-R for pension, annuity or IRA distributions"},
+	"ira":            {DocumentType1099R, "Form 1099-R for pension, annuity or IRA distributions"},
+	"ssa1099":        {DocumentTypeSSA1099, "Form SSA-1099 for Social Security benefits"},
+	"socialsecurity": {DocumentTypeSSA1099, "Form SSA-1099 for Social Security benefits"},
+	"1099g":          {DocumentType1099G, "Form 1099-G for unemployment compensation"},
+	"unemployment":   {DocumentType1099G, "Form 1099-G for unemployment compensation"},
+	"w2g":            {DocumentTypeW2G, "Form W-2G for gambling winnings"},
+	"gambling":       {DocumentTypeW2G, "Form W-2G for gambling winnings"},
+	"k1":             {DocumentTypeK1, "Schedule K-1 from each partnership, S corporation or trust"},
+	"partnership":    {DocumentTypeK1, "Schedule K-1 from each partnership, S corporation or trust"},
+	"scorporation":   {DocumentTypeK1, "Schedule K-1 from each partnership, S corporation or trust"},
+	"trust":          {DocumentTypeK1, "Schedule K-1 from each partnership, S corporation or trust"},
+}
+
+// deductionDocuments maps deductions, as normalizeChecklistKey leaves them, to their documents
+var deductionDocuments = map[string]checklistDocument{
+	"1098":                {DocumentType1098, "Form 1098 mortgage interest statement"},
+	"mortgage":            {DocumentType1098, "Form 1098 mortgage interest statement"},
+	"mortgageinterest":    {DocumentType1098, "Form 1098 mortgage interest statement"},
+	"1098e":               {DocumentType1098E, "Form 1098-E student loan interest statement"},
+	"studentloan":         {DocumentType1098E, "Form 1098-E student loan interest statement"},
+	"studentloaninterest": {DocumentType1098E, "Form 1098-E student loan interest statement"},
+	"1098t":               {DocumentType1098T, "Form 1098-T tuition statement"},
+	"tuition":             {DocumentType1098T, "Form 1098-T tuition statement"},
+	"education":           {DocumentType1098T, "Form 1098-T tuition statement"},
+	"hsa":                 {DocumentType1099SA, "Form 1099-SA for health savings account distributions"},
+	"charity":             {DocumentTypeCharityReceipt, "Receipts for charitable contributions"},
+	"charitable":          {DocumentTypeCharityReceipt, "Receipts for charitable contributions"},
+	"donations":           {DocumentTypeCharityReceipt, "Receipts for charitable contributions"},
+	"childcare":           {DocumentTypeChildcare, "Childcare provider statement with the provider's tax ID"},
+	"dependentcare":       {DocumentTypeChildcare, "Childcare provider statement with the provider's tax ID"},
+	"propertytax":         {DocumentTypePropertyTax, "Property tax statement"},
+	"propertytaxes":       {DocumentTypePropertyTax, "Property tax statement"},
+	"realestatetax":       {DocumentTypePropertyTax, "Property tax statement"},
+	"medical":             {DocumentTypeMedical, "Receipts for medical and dental expenses"},
+	"medicalexpenses":     {DocumentTypeMedical, "Receipts for medical and dental expenses"},
+}
+
+// FilingChecklistItem is a document the filing needs and what it is needed for
+type FilingChecklistItem struct {
+	ChecklistItem
+	Source      string     `json:"source"`                // ChecklistSource*
+	Reason      string     `json:"reason"`                // The income source, deduction or dependent that needs it
+	DependentID *uuid.UUID `json:"dependentId,omitempty"` // Set for dependent documents
+}
+
+// FilingChecklist is the documents a filing needs, worked out from the income sources, deductions,
+// dependents and marketplace insurance the client reported, against the documents uploaded to it
+type FilingChecklist struct {
+	FilingID     uuid.UUID              `json:"filingId"`
+	Year         int                    `json:"year"`
+	Items        []*FilingChecklistItem `json:"items"`
+	Missing      int                    `json:"missing"`                // Required items not provided
+	Complete     bool                   `json:"complete"`               // Every required item is provided
+	Unrecognized []string               `json:"unrecognized,omitempty"` // Reported income sources and deductions with no known document
+}
+
+// normalizeChecklistKey lowercases s and drops everything but letters and digits, so "1099-INT",
+// "Self Employment" and "self_employment" compare as 1099int and selfemployment
+func normalizeChecklistKey(s string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// NewFilingChecklist builds a filing's checklist from its loaded documents and the client's
+// dependents. Each dependent's documents are listed separately, so two dependents needing a birth
+// certificate need two uploaded. Documents that failed their malware scan provide nothing.
+func NewFilingChecklist(f *Filing, dependents []*Dependent) *FilingChecklist {
+	checklist := &FilingChecklist{FilingID: f.ID, Year: f.Year, Items: []*FilingChecklistItem{}}
+
+	// Income sources and deductions needing the same form share one item
+	byType := map[string]*FilingChecklistItem{}
+	add := func(source, reason string, doc checklistDocument) {
+		key := normalizeChecklistKey(doc.documentType)
+		if item, ok := byType[key]; ok {
+			item.Reason += ", " + reason
+			return
+		}
+		item := &FilingChecklistItem{
+			ChecklistItem: ChecklistItem{DocumentType: doc.documentType, Description: doc.description, Required: true},
+			Source:        source,
+			Reason:        reason,
+		}
+		byType[key] = item
+		checklist.Items = append(checklist.Items, item)
+	}
+
+	for _, income := range f.SourceOfIncome {
+		if doc, ok := incomeDocuments[normalizeChecklistKey(income)]; ok {
+			add(ChecklistSourceIncome, income, doc)
+		} else if strings.TrimSpace(income) != "" {
+			checklist.Unrecognized = append(checklist.Unrecognized, income)
+		}
+	}
+	for _, deduction := range f.Deductions {
+		if doc, ok := deductionDocuments[normalizeChecklistKey(deduction)]; ok {
+			add(ChecklistSourceDeduction, deduction, doc)
+		} else if strings.TrimSpace(deduction) != "" {
+			checklist.Unrecognized = append(checklist.Unrecognized, deduction)
+		}
+	}
+	if f.MarketplaceInsurance != nil && *f.MarketplaceInsurance {
+		add(ChecklistSourceMarketplace, "Health insurance from the marketplace",
+			checklistDocument{DocumentType1095A, "Form 1095-A health insurance marketplace statement"})
+	}
+
+	for _, dep := range dependents {
+		dependentID := dep.ID
+		name := strings.TrimSpace(dep.FirstName + " " + dep.LastName)
+		for _, documentType := range dep.Documents {
+			checklist.Items = append(checklist.Items, &FilingChecklistItem{
+				ChecklistItem: ChecklistItem{
+					DocumentType: documentType,
+					Description:  fmt.Sprintf("%s for %s", documentType, name),
+					Required:     true,
+				},
+				Source:      ChecklistSourceDependent,
+				Reason:      name,
+				DependentID: &dependentID,
+			})
+		}
+	}
+
+	// Each uploaded document provides one item of its type
+	available := map[string]int{}
+	for _, d := range f.Documents {
+		if d.ScanStatus != nil && *d.ScanStatus == DocumentScanInfected {
+			continue
+		}
+		available[normalizeChecklistKey(d.Type)]++
+	}
+
+	checklist.Complete = true
+	for _, item := range checklist.Items {
+		key := normalizeChecklistKey(item.DocumentType)
+		if available[key] > 0 {
+			available[key]--
+			item.Provided = true
+		} else if item.Required {
+			checklist.Missing++
+			checklist.Complete = false
+		}
+	}
+
+	sort.Strings(checklist.Unrecognized)
+	return checklist
+}
+
+// ApplyFilingChecklists sets the checklist of each of the client's filings
+func (c *ClientComprehensive) ApplyFilingChecklists() {
+	if c == nil {
+		return
+	}
+	for _, f := range c.Filings {
+		f.Checklist = NewFilingChecklist(f, c.Dependents)
+	}
+}
@@ -0,0 +1,90 @@
+package types
+
+import (
+	"math"
+	"sort"
+)
+
+// FilingCompleteness scores how close a filing is to ready to file. Documents, payment and
+// signature each count for a third of the score.
+type FilingCompleteness struct {
+	Score             int    `json:"score"`             // 0-100
+	DocumentCount     int    `json:"documentCount"`     // Documents uploaded to the filing
+	OpenRequests      int    `json:"openRequests"`      // Document requests for the filing still open
+	FulfilledRequests int    `json:"fulfilledRequests"` // Document requests for the filing fulfilled
+	PaymentComplete   bool   `json:"paymentComplete"`   // Paid in full
+	SignatureStatus   string `json:"signatureStatus"`   // Latest signature request status, or NONE
+}
+
+// SignatureStatusNone is the signature status of a filing never sent for signature
+const SignatureStatusNone = "NONE"
+
+// NewFilingCompleteness scores a filing from its loaded documents and payments, the counts of
+// document requests for it and the status of its latest signature request
+func NewFilingCompleteness(f *Filing, openRequests, fulfilledRequests int, signatureStatus string) *FilingCompleteness {
+	c := &FilingCompleteness{
+		DocumentCount:     len(f.Documents),
+		OpenRequests:      openRequests,
+		FulfilledRequests: fulfilledRequests,
+		SignatureStatus:   signatureStatus,
+	}
+
+	// Requests say which documents are needed; without any, one uploaded document will do
+	documents := 0.0
+	if requested := openRequests + fulfilledRequests; requested > 0 {
+		documents = float64(fulfilledRequests) / float64(requested)
+	} else if len(f.Documents) > 0 {
+		documents = 1
+	}
+
+	payment := 0.0
+	if len(f.Payments) > 0 {
+		c.PaymentComplete = true
+		for _, p := range f.Payments {
+			if !isPaidStatus(p.Status) {
+				c.PaymentComplete = false
+				break
+			}
+		}
+	}
+	if c.PaymentComplete {
+		payment = 1
+	}
+
+	signature := 0.0
+	switch signatureStatus {
+	case SignatureStatusCompleted:
+		signature = 1
+	case SignatureStatusSent, SignatureStatusDelivered:
+		signature = 0.5
+	}
+
+	c.Score = int(math.Round((documents + payment + signature) / 3 * 100))
+	return c
+}
+
+// completenessRank orders filings for triage: open filings by score, then completed or unscored ones
+func completenessRank(f *Filing) int {
+	if f.Completeness == nil || (f.Status != nil && f.Status.IsCompleted) {
+		return -1
+	}
+	return f.Completeness.Score
+}
+
+// SortByCompleteness orders each client's filings, and the clients by their best filing, so the
+// open filings closest to ready come first. Completed filings go last.
+func SortByCompleteness(clients []*ClientComprehensive) {
+	best := make(map[*ClientComprehensive]int, len(clients))
+	for _, client := range clients {
+		sort.SliceStable(client.Filings, func(i, j int) bool {
+			return completenessRank(client.Filings[i]) > completenessRank(client.Filings[j])
+		})
+		best[client] = -1
+		if len(client.Filings) > 0 {
+			best[client] = completenessRank(client.Filings[0])
+		}
+	}
+	sort.SliceStable(clients, func(i, j int) bool {
+		return best[clients[i]] > best[clients[j]]
+	})
+}
@@ -0,0 +1,92 @@
+package types
+
+import (
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// FilingResult holds the computed outcome of a prepared return, recorded by the accountant
+// Field Mapping (MyWellTax adapter):
+//
+//	taxes.filing_result.* → FilingResult fields
+type FilingResult struct {
+	FilingID           uuid.UUID `json:"filingId"`
+	AGI                float64   `json:"agi"`                // Adjusted gross income
+	TotalTax           float64   `json:"totalTax"`           // Federal total tax
+	FederalWithholding float64   `json:"federalWithholding"` // Federal tax withheld and estimated payments
+	FederalRefund      float64   `json:"federalRefund"`
+	FederalOwed        float64   `json:"federalOwed"`
+	StateTax           float64   `json:"stateTax"` // Totals across all state returns
+	StateRefund        float64   `json:"stateRefund"`
+	StateOwed          float64   `json:"stateOwed"`
+	RecordedBy         *string   `json:"recordedBy,omitempty"` // Email of the employee who recorded the result
+	CreatedAt          string    `json:"createdAt"`
+	UpdatedAt          *string   `json:"updatedAt,omitempty"`
+}
+
+// NetRefund is the combined federal and state refund less amounts owed (negative when the client owes)
+func (r *FilingResult) NetRefund() float64 {
+	return r.FederalRefund + r.StateRefund - r.FederalOwed - r.StateOwed
+}
+
+// Validate checks amounts are non-negative and no jurisdiction both refunds and owes
+func (r *FilingResult) Validate() string {
+	for _, v := range []float64{r.AGI, r.TotalTax, r.FederalWithholding, r.FederalRefund, r.FederalOwed, r.StateTax, r.StateRefund, r.StateOwed} {
+		if v < 0 {
+			return "Amounts cannot be negative"
+		}
+	}
+	if r.FederalRefund > 0 && r.FederalOwed > 0 {
+		return "federalRefund and federalOwed cannot both be set"
+	}
+	if r.StateRefund > 0 && r.StateOwed > 0 {
+		return "stateRefund and stateOwed cannot both be set"
+	}
+	return ""
+}
+
+// FilingYearComparison is one year's result alongside the change from the prior filed year
+type FilingYearComparison struct {
+	Year      int           `json:"year"`
+	FilingID  uuid.UUID     `json:"filingId"`
+	Result    *FilingResult `json:"result,omitempty"`
+	NetRefund *float64      `json:"netRefund,omitempty"`
+
+	// Changes from the previous year that has a result (nil when there is nothing to compare)
+	AGIChange       *float64 `json:"agiChange,omitempty"`
+	TotalTaxChange  *float64 `json:"totalTaxChange,omitempty"`
+	NetRefundChange *float64 `json:"netRefundChange,omitempty"`
+}
+
+// YearOverYear compares filing results across years, most recent first
+func (c *ClientComprehensive) YearOverYear() []*FilingYearComparison {
+	filings := append([]*Filing(nil), c.Filings...)
+	sort.Slice(filings, func(i, j int) bool { return filings[i].Year < filings[j].Year })
+
+	comparisons := make([]*FilingYearComparison, 0, len(filings))
+	var previous *FilingResult
+	for _, f := range filings {
+		cmp := &FilingYearComparison{Year: f.Year, FilingID: f.ID, Result: f.Result}
+		if f.Result != nil {
+			net := f.Result.NetRefund()
+			cmp.NetRefund = &net
+
+			if previous != nil {
+				agi := f.Result.AGI - previous.AGI
+				tax := f.Result.TotalTax - previous.TotalTax
+				netChange := net - previous.NetRefund()
+				cmp.AGIChange = &agi
+				cmp.TotalTaxChange = &tax
+				cmp.NetRefundChange = &netChange
+			}
+			previous = f.Result
+		}
+		comparisons = append(comparisons, cmp)
+	}
+
+	for i, j := 0, len(comparisons)-1; i < j; i, j = i+1, j-1 {
+		comparisons[i], comparisons[j] = comparisons[j], comparisons[i]
+	}
+	return comparisons
+}
@@ -0,0 +1,326 @@
+package types
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultFilingStatusLocale is the locale used when a client asks for none the tenant has texts for
+const DefaultFilingStatusLocale = "en"
+
+// Steps of a filing status page
+const (
+	FilingStatusStepDone     = "DONE"
+	FilingStatusStepCurrent  = "CURRENT"
+	FilingStatusStepUpcoming = "UPCOMING"
+)
+
+// FilingStatusText describes one workflow status to clients in plain language
+type FilingStatusText struct {
+	Title        string `json:"title"`
+	Description  string `json:"description"`
+	NextSteps    string `json:"nextSteps"`    // What happens next, and anything the client must do
+	ExpectedDays int    `json:"expectedDays"` // Usual days until the filing moves on; 0 shows no expected date
+}
+
+// FilingStatusTexts holds plain-language status descriptions by locale, then workflow status.
+// Stored per tenant in tenant_connections.filing_status_texts (JSONB) as overrides of
+// DefaultFilingStatusTexts: a tenant's entry replaces the built-in one for that locale and status,
+// and tenants may add locales of their own.
+type FilingStatusTexts map[string]map[string]*FilingStatusText
+
+// filingStatusTextStatuses are the statuses clients can see a text for
+var filingStatusTextStatuses = []string{
+	FilingStatusInProgress,
+	FilingStatusReview,
+	FilingStatusSigned,
+	FilingStatusFiled,
+	FilingStatusAccepted,
+	FilingStatusRejected,
+	FilingStatusCompleted,
+}
+
+// DefaultFilingStatusTexts returns the built-in descriptions in English and Spanish
+func DefaultFilingStatusTexts() FilingStatusTexts {
+	return FilingStatusTexts{
+		"en": {
+			FilingStatusInProgress: {
+				Title:       "We're preparing your return",
+				Description: "Your tax professional is working on your return with the information and documents you provided.",
+				NextSteps:   "Upload any documents we've asked for so we can finish your return.",
+			},
+			FilingStatusReview: {
+				Title:        "Your return is being reviewed",
+				Description:  "Your return is prepared and a reviewer is checking it for accuracy.",
+				NextSteps:    "Nothing is needed from you right now. We'll let you know when it's ready to sign.",
+				ExpectedDays: 3,
+			},
+			FilingStatusSigned: {
+				Title:        "Your return is signed",
+				Description:  "Your return has been approved and signed.",
+				NextSteps:    "We'll submit your return to the IRS shortly.",
+				ExpectedDays: 2,
+			},
+			FilingStatusFiled: {
+				Title:        "Your return was sent to the IRS",
+				Description:  "We filed your return electronically and are waiting for the IRS to accept it.",
+				NextSteps:    "Nothing is needed from you. The IRS usually responds within a few days.",
+				ExpectedDays: 3,
+			},
+			FilingStatusAccepted: {
+				Title:       "The IRS accepted your return",
+				Description: "Your return has been accepted. You're all set for this year.",
+				NextSteps:   "If you're due a refund, you can track it with the IRS \"Where's My Refund?\" tool.",
+			},
+			FilingStatusRejected: {
+				Title:       "Your return needs a correction",
+				Description: "The IRS sent your return back. This is usually a small issue we can fix quickly.",
+				NextSteps:   "We're correcting your return and will contact you if we need anything.",
+			},
+			FilingStatusCompleted: {
+				Title:       "Your return is complete",
+				Description: "Your return has been filed and everything is finished for this year.",
+				NextSteps:   "Your documents stay available in the portal.",
+			},
+		},
+		"es": {
+			FilingStatusInProgress: {
+				Title:       "Estamos preparando su declaración",
+				Description: "Su profesional de impuestos está trabajando en su declaración con la información y los documentos que nos dio.",
+				NextSteps:   "Suba los documentos que le pedimos para que podamos terminar su declaración.",
+			},
+			FilingStatusReview: {
+				Title:        "Su declaración está en revisión",
+				Description:  "Su declaración está preparada y un revisor está verificando que sea correcta.",
+				NextSteps:    "Por ahora no necesita hacer nada. Le avisaremos cuando esté lista para firmar.",
+				ExpectedDays: 3,
+			},
+			FilingStatusSigned: {
+				Title:        "Su declaración está firmada",
+				Description:  "Su declaración fue aprobada y firmada.",
+				NextSteps:    "Pronto enviaremos su declaración al IRS.",
+				ExpectedDays: 2,
+			},
+			FilingStatusFiled: {
+				Title:        "Enviamos su declaración al IRS",
+				Description:  "Presentamos su declaración electrónicamente y esperamos que el IRS la acepte.",
+				NextSteps:    "No necesita hacer nada. El IRS suele responder en unos días.",
+				ExpectedDays: 3,
+			},
+			FilingStatusAccepted: {
+				Title:       "El IRS aceptó su declaración",
+				Description: "Su declaración fue aceptada. Ya terminó por este año.",
+				NextSteps:   "Si le corresponde un reembolso, puede seguirlo con la herramienta \"¿Dónde está mi reembolso?\" del IRS.",
+			},
+			FilingStatusRejected: {
+				Title:       "Su declaración necesita una corrección",
+				Description: "El IRS devolvió su declaración. Por lo general es un problema pequeño que podemos corregir rápido.",
+				NextSteps:   "Estamos corrigiendo su declaración y le contactaremos si necesitamos algo.",
+			},
+			FilingStatusCompleted: {
+				Title:       "Su declaración está completa",
+				Description: "Su declaración fue presentada y todo está terminado por este año.",
+				NextSteps:   "Sus documentos siguen disponibles en el portal.",
+			},
+		},
+	}
+}
+
+// Validate checks a tenant's status text overrides
+func (t FilingStatusTexts) Validate() string {
+	for locale, texts := range t {
+		if !isFilingStatusLocale(locale) {
+			return "locales must be language codes such as en, es or pt-BR"
+		}
+		for status, text := range texts {
+			if !isFilingStatusTextStatus(status) {
+				return "statuses must be IN_PROGRESS, REVIEW, SIGNED, FILED, ACCEPTED, REJECTED or COMPLETED"
+			}
+			if text == nil || strings.TrimSpace(text.Title) == "" {
+				return "every status text needs a title"
+			}
+			if len(text.Title) > 200 || len(text.Description) > 2000 || len(text.NextSteps) > 2000 {
+				return "titles may have 200 characters and descriptions and next steps 2000"
+			}
+			if text.ExpectedDays < 0 || text.ExpectedDays > 365 {
+				return "expectedDays must be between 0 and 365"
+			}
+		}
+	}
+	return ""
+}
+
+// Merge returns the built-in texts with the tenant's overrides applied
+func (t FilingStatusTexts) Merge() FilingStatusTexts {
+	merged := DefaultFilingStatusTexts()
+	for locale, texts := range t {
+		if merged[locale] == nil {
+			merged[locale] = map[string]*FilingStatusText{}
+		}
+		for status, text := range texts {
+			merged[locale][status] = text
+		}
+	}
+	return merged
+}
+
+// Locale returns the first of the requested locales there are texts for, matching a regional
+// locale such as es-MX to its language when only the language has texts
+func (t FilingStatusTexts) Locale(requested []string) string {
+	for _, locale := range requested {
+		if _, ok := t[locale]; ok {
+			return locale
+		}
+		if i := strings.Index(locale, "-"); i > 0 {
+			if _, ok := t[locale[:i]]; ok {
+				return locale[:i]
+			}
+		}
+	}
+	return DefaultFilingStatusLocale
+}
+
+// Text returns the text of a status in locale, falling back to the default locale for statuses
+// a tenant's own locale leaves out
+func (t FilingStatusTexts) Text(locale, status string) *FilingStatusText {
+	if text := t[locale][status]; text != nil {
+		return text
+	}
+	return t[DefaultFilingStatusLocale][status]
+}
+
+// isFilingStatusLocale checks a language code with an optional region, such as en or pt-BR
+func isFilingStatusLocale(locale string) bool {
+	language, region, hasRegion := strings.Cut(locale, "-")
+	if len(language) < 2 || len(language) > 3 || strings.ToLower(language) != language {
+		return false
+	}
+	for _, r := range language {
+		if r < 'a' || r > 'z' {
+			return false
+		}
+	}
+	if !hasRegion {
+		return true
+	}
+	if len(region) != 2 {
+		return false
+	}
+	for _, r := range region {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
+
+// isFilingStatusTextStatus checks a status a text can be given for
+func isFilingStatusTextStatus(status string) bool {
+	for _, s := range filingStatusTextStatuses {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// FilingStatusPath returns the workflow statuses a filing passes through when nothing sends it
+// back, in order. It follows the workflow from IN_PROGRESS to the first status not yet on the
+// path, so it changes with the workflow.
+func FilingStatusPath() []string {
+	path := []string{FilingStatusInProgress}
+	onPath := map[string]bool{FilingStatusInProgress: true}
+	for status := FilingStatusInProgress; ; {
+		next := ""
+		for _, candidate := range filingStatusTransitions[status] {
+			if !onPath[candidate] {
+				next = candidate
+				break
+			}
+		}
+		if next == "" {
+			return path
+		}
+		path = append(path, next)
+		onPath[next] = true
+		status = next
+	}
+}
+
+// FilingStatusPage tells a client where their filing is in plain language
+type FilingStatusPage struct {
+	FilingID    uuid.UUID               `json:"filingId"`
+	Year        int                     `json:"year"`
+	Status      string                  `json:"status"` // Workflow status, for styling; clients are shown the title
+	Locale      string                  `json:"locale"`
+	Title       string                  `json:"title"`
+	Description string                  `json:"description"`
+	NextSteps   string                  `json:"nextSteps"`
+	Since       *time.Time              `json:"since,omitempty"`      // When the filing reached its status, if it was moved there in the workflow
+	ExpectedBy  *time.Time              `json:"expectedBy,omitempty"` // When the filing usually moves on
+	Steps       []*FilingStatusPageStep `json:"steps"`
+}
+
+// FilingStatusPageStep is one step on a filing's path through the workflow
+type FilingStatusPageStep struct {
+	Status string `json:"status"`
+	Title  string `json:"title"`
+	State  string `json:"state"` // DONE, CURRENT or UPCOMING
+}
+
+// BuildFilingStatusPage describes a filing's workflow status in locale. since is when the
+// filing reached the status, or nil when it is unknown.
+func BuildFilingStatusPage(workflow *FilingWorkflow, texts FilingStatusTexts, locale string, since *time.Time) *FilingStatusPage {
+	status := WorkflowFilingStatus(workflow.Status)
+	page := &FilingStatusPage{
+		FilingID: workflow.FilingID,
+		Year:     workflow.Year,
+		Status:   status,
+		Locale:   locale,
+		Since:    since,
+		Steps:    []*FilingStatusPageStep{},
+	}
+	if text := texts.Text(locale, status); text != nil {
+		page.Title, page.Description, page.NextSteps = text.Title, text.Description, text.NextSteps
+		if since != nil && text.ExpectedDays > 0 {
+			expectedBy := since.AddDate(0, 0, text.ExpectedDays)
+			page.ExpectedBy = &expectedBy
+		}
+	}
+
+	// A completed filing has finished the path; a rejected one was filed and is waiting to go back
+	path := FilingStatusPath()
+	current := -1
+	for i, s := range path {
+		if s == status {
+			current = i
+		}
+	}
+	switch status {
+	case FilingStatusCompleted:
+		current = len(path)
+	case FilingStatusRejected:
+		for i, s := range path {
+			if s == FilingStatusFiled {
+				current = i + 1
+			}
+		}
+	}
+
+	for i, s := range path {
+		step := &FilingStatusPageStep{Status: s, State: FilingStatusStepUpcoming}
+		if text := texts.Text(locale, s); text != nil {
+			step.Title = text.Title
+		}
+		switch {
+		case i < current:
+			step.State = FilingStatusStepDone
+		case i == current && status != FilingStatusRejected:
+			step.State = FilingStatusStepCurrent
+		}
+		page.Steps = append(page.Steps, step)
+	}
+	return page
+}
@@ -0,0 +1,108 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Filing workflow statuses
+const (
+	FilingStatusInProgress = "IN_PROGRESS"
+	FilingStatusReview     = "REVIEW"
+	FilingStatusSigned     = "SIGNED"
+	FilingStatusFiled      = "FILED"
+	FilingStatusAccepted   = "ACCEPTED"
+	FilingStatusRejected   = "REJECTED"
+	FilingStatusCompleted  = "COMPLETED" // Set by marking the filing completed; final like ACCEPTED
+)
+
+// filingStatusTransitions lists the statuses each workflow status may move to. A return sent
+// back from review or rejected by the IRS goes back to IN_PROGRESS to be fixed.
+var filingStatusTransitions = map[string][]string{
+	FilingStatusInProgress: {FilingStatusReview},
+	FilingStatusReview:     {FilingStatusInProgress, FilingStatusSigned},
+	FilingStatusSigned:     {FilingStatusInProgress, FilingStatusFiled},
+	FilingStatusFiled:      {FilingStatusAccepted, FilingStatusRejected},
+	FilingStatusRejected:   {FilingStatusInProgress},
+	FilingStatusAccepted:   {},
+	FilingStatusCompleted:  {},
+}
+
+// IsValidFilingStatus checks a filing workflow status value
+func IsValidFilingStatus(s string) bool {
+	_, ok := filingStatusTransitions[s]
+	return ok && s != FilingStatusCompleted
+}
+
+// WorkflowFilingStatus returns the workflow status of a filing's stored status. Statuses the
+// tax platform sets outside the workflow, or none, count as IN_PROGRESS.
+func WorkflowFilingStatus(s string) string {
+	if _, ok := filingStatusTransitions[s]; ok {
+		return s
+	}
+	return FilingStatusInProgress
+}
+
+// FilingStatusTransitions returns the statuses a filing with the stored status may move to
+func FilingStatusTransitions(from string) []string {
+	return filingStatusTransitions[WorkflowFilingStatus(from)]
+}
+
+// CanTransitionFilingStatus reports whether a filing with the stored status may move to status to
+func CanTransitionFilingStatus(from, to string) bool {
+	for _, next := range FilingStatusTransitions(from) {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// FilingWorkflow is the current workflow state of a filing
+// Field Mapping (MyWellTax adapter):
+//
+//	taxes.filing.id, user_id, year + taxes.filing_status.status, is_completed → FilingWorkflow fields
+//
+// Field Mapping (Drake adapter):
+//
+//	returns.id, client_id, tax_year, return_status, completed_at IS NOT NULL → FilingWorkflow fields
+type FilingWorkflow struct {
+	FilingID    uuid.UUID `json:"filingId"`
+	ClientID    uuid.UUID `json:"clientId"`
+	Year        int       `json:"year"`
+	Status      string    `json:"status"`      // As stored by the tax platform
+	IsCompleted bool      `json:"isCompleted"` // Set with ACCEPTED
+	Next        []string  `json:"next"`        // Statuses the filing may move to
+}
+
+// FilingStatusUpdate is the body of a filing status change
+type FilingStatusUpdate struct {
+	Status string  `json:"status"`
+	Note   *string `json:"note,omitempty"`
+}
+
+// FilingStatusChange records one move of a filing through the workflow
+type FilingStatusChange struct {
+	ID             uuid.UUID  `json:"id"`
+	TenantID       string     `json:"tenantId"`
+	FilingID       uuid.UUID  `json:"filingId"`
+	ClientID       uuid.UUID  `json:"clientId"`
+	TaxYear        int        `json:"taxYear"`
+	FromStatus     string     `json:"fromStatus"` // As stored before the change
+	ToStatus       string     `json:"toStatus"`
+	Note           *string    `json:"note,omitempty"`
+	ChangedBy      *uuid.UUID `json:"changedBy,omitempty"` // Nil once the employee is deleted
+	ChangedByEmail string     `json:"changedByEmail"`
+	ChangedAt      time.Time  `json:"changedAt"`
+}
+
+// FilingContact is the client of a filing, for notifying them about it
+type FilingContact struct {
+	ClientID  uuid.UUID
+	Email     string
+	FirstName string
+	LastName  string
+	TaxYear   int
+	Deceased  bool
+}
@@ -0,0 +1,14 @@
+package types
+
+import "time"
+
+// FirebaseOrphanUser is a Firebase account whose UID matches no employee or tenant user
+type FirebaseOrphanUser struct {
+	UID          string     `json:"uid"`
+	Email        *string    `json:"email,omitempty"`
+	Disabled     bool       `json:"disabled"`
+	CreatedAt    *time.Time `json:"createdAt,omitempty"` // When the account was created in Firebase
+	LastSignInAt *time.Time `json:"lastSignInAt,omitempty"`
+	FirstSeenAt  time.Time  `json:"firstSeenAt"` // The reconciliation run that first reported it
+	LastSeenAt   time.Time  `json:"lastSeenAt"`
+}
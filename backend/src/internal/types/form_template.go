@@ -0,0 +1,165 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// FormTemplate is a tenant's fillable PDF for one kind of generated document
+type FormTemplate struct {
+	ID           uuid.UUID         `json:"id"`
+	TenantID     string            `json:"tenantId"`
+	Kind         string            `json:"kind"`
+	TemplatePath string            `json:"templatePath"` // gs:// or s3:// URL of the fillable PDF
+	FieldMap     map[string]string `json:"fieldMap"`     // PDF field name → form data key
+	UpdatedBy    *uuid.UUID        `json:"updatedBy,omitempty"`
+	CreatedAt    time.Time         `json:"createdAt"`
+	UpdatedAt    time.Time         `json:"updatedAt"`
+}
+
+// Form template kinds
+const (
+	FormKindCoverSheet = "COVER_SHEET"
+	FormKindOrganizer  = "ORGANIZER"
+	FormKind8879       = "FORM_8879"
+)
+
+// FormKinds lists every supported template kind
+var FormKinds = []string{FormKindCoverSheet, FormKindOrganizer, FormKind8879}
+
+// IsValidFormKind checks a template kind
+func IsValidFormKind(kind string) bool {
+	for _, k := range FormKinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Form data keys a template's field map can reference
+const (
+	FormKeyTaxpayerFirstName = "taxpayer.firstName"
+	FormKeyTaxpayerLastName  = "taxpayer.lastName"
+	FormKeyTaxpayerFullName  = "taxpayer.fullName"
+	FormKeyTaxpayerSSN       = "taxpayer.ssn"
+	FormKeyTaxpayerEmail     = "taxpayer.email"
+	FormKeyTaxpayerPhone     = "taxpayer.phone"
+	FormKeyTaxpayerDob       = "taxpayer.dob"
+	FormKeyAddressStreet     = "address.street"
+	FormKeyAddressCity       = "address.city"
+	FormKeyAddressState      = "address.state"
+	FormKeyAddressZip        = "address.zip"
+	FormKeyAddressCityLine   = "address.cityStateZip"
+	FormKeySpouseFullName    = "spouse.fullName"
+	FormKeySpouseSSN         = "spouse.ssn"
+	FormKeySpouseEmail       = "spouse.email"
+	FormKeyFilingYear        = "filing.year"
+	FormKeyMaritalStatus     = "filing.maritalStatus"
+	FormKeyDependentCount    = "filing.dependentCount"
+	FormKeyGrossIncome       = "amounts.grossIncome"
+	FormKeyTotalTax          = "amounts.totalTax"
+	FormKeyTaxWithheld       = "amounts.taxWithheld"
+	FormKeyRefund            = "amounts.refund"
+	FormKeyOwed              = "amounts.owed"
+	FormKeyPreparedDate      = "date.prepared"
+)
+
+// FormDataKeys lists every key a field map may use
+var FormDataKeys = []string{
+	FormKeyTaxpayerFirstName, FormKeyTaxpayerLastName, FormKeyTaxpayerFullName, FormKeyTaxpayerSSN,
+	FormKeyTaxpayerEmail, FormKeyTaxpayerPhone, FormKeyTaxpayerDob,
+	FormKeyAddressStreet, FormKeyAddressCity, FormKeyAddressState, FormKeyAddressZip, FormKeyAddressCityLine,
+	FormKeySpouseFullName, FormKeySpouseSSN, FormKeySpouseEmail,
+	FormKeyFilingYear, FormKeyMaritalStatus, FormKeyDependentCount,
+	FormKeyGrossIncome, FormKeyTotalTax, FormKeyTaxWithheld, FormKeyRefund, FormKeyOwed,
+	FormKeyPreparedDate,
+}
+
+// IsValidFormDataKey checks a field map value
+func IsValidFormDataKey(key string) bool {
+	for _, k := range FormDataKeys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatFormAmount renders a dollar amount the way IRS forms expect (no symbol, two decimals)
+func FormatFormAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// FormData builds form data for a filing from the client's record.
+// SSNs are masked in client data, so full SSNs must be supplied by the caller; return amounts
+// come from the filing's recorded result when there is one.
+func (c *ClientComprehensive) FormData(filing *Filing) map[string]string {
+	data := map[string]string{
+		FormKeyPreparedDate: time.Now().Format("01/02/2006"),
+	}
+	set := func(key string, value *string) {
+		if value != nil && *value != "" {
+			data[key] = *value
+		}
+	}
+
+	if client := c.Client; client != nil {
+		set(FormKeyTaxpayerFirstName, client.FirstName)
+		set(FormKeyTaxpayerLastName, client.LastName)
+		data[FormKeyTaxpayerEmail] = client.Email
+		set(FormKeyTaxpayerPhone, client.Phone)
+		set(FormKeyTaxpayerDob, client.Dob)
+		if name := strings.TrimSpace(data[FormKeyTaxpayerFirstName] + " " + data[FormKeyTaxpayerLastName]); name != "" {
+			data[FormKeyTaxpayerFullName] = name
+		}
+
+		street := ""
+		if client.Address1 != nil {
+			street = *client.Address1
+		}
+		if client.Address2 != nil && *client.Address2 != "" {
+			street += " " + *client.Address2
+		}
+		if street != "" {
+			data[FormKeyAddressStreet] = street
+		}
+		set(FormKeyAddressCity, client.City)
+		set(FormKeyAddressState, client.State)
+		if client.Zipcode != nil {
+			data[FormKeyAddressZip] = fmt.Sprintf("%05d", *client.Zipcode)
+		}
+		if data[FormKeyAddressCity] != "" {
+			data[FormKeyAddressCityLine] = strings.TrimSpace(fmt.Sprintf("%s, %s %s",
+				data[FormKeyAddressCity], data[FormKeyAddressState], data[FormKeyAddressZip]))
+		}
+	}
+
+	if spouse := c.Spouse; spouse != nil {
+		data[FormKeySpouseFullName] = strings.TrimSpace(spouse.FirstName + " " + spouse.LastName)
+		set(FormKeySpouseEmail, spouse.Email)
+	}
+
+	data[FormKeyDependentCount] = strconv.Itoa(len(c.Dependents))
+
+	if filing != nil {
+		data[FormKeyFilingYear] = strconv.Itoa(filing.Year)
+		set(FormKeyMaritalStatus, filing.MaritalStatus)
+		if filing.Income != nil {
+			data[FormKeyGrossIncome] = FormatFormAmount(float64(*filing.Income))
+		}
+		if r := filing.Result; r != nil {
+			data[FormKeyGrossIncome] = FormatFormAmount(r.AGI)
+			data[FormKeyTotalTax] = FormatFormAmount(r.TotalTax)
+			data[FormKeyTaxWithheld] = FormatFormAmount(r.FederalWithholding)
+			data[FormKeyRefund] = FormatFormAmount(r.FederalRefund)
+			data[FormKeyOwed] = FormatFormAmount(r.FederalOwed)
+		}
+	}
+
+	return data
+}
@@ -0,0 +1,41 @@
+package types
+
+// FraudRules configures the checks evaluated when a commission is created.
+// Stored per tenant in tenant_connections.fraud_rules (JSONB); tenants without
+// a configuration fall back to DefaultFraudRules.
+type FraudRules struct {
+	Enabled             bool `json:"enabled"`
+	CheckSelfReferral   bool `json:"checkSelfReferral"`   // Customer email matches the affiliate's email
+	CheckSameSSN        bool `json:"checkSameSsn"`        // Customer SSN matches the SSN on the affiliate's own client record
+	CheckClickIP        bool `json:"checkClickIp"`        // Purchase IP generated an unusual number of the affiliate's clicks
+	MaxClicksPerIP      int  `json:"maxClicksPerIp"`      // Clicks from one IP before it is considered suspicious
+	VelocityMaxPerCode  int  `json:"velocityMaxPerCode"`  // Max commissions per discount code within the window (0 disables)
+	VelocityWindowHours int  `json:"velocityWindowHours"` // Window for the velocity check
+}
+
+// DefaultFraudRules returns the rules applied when a tenant has no configuration
+func DefaultFraudRules() *FraudRules {
+	return &FraudRules{
+		Enabled:             true,
+		CheckSelfReferral:   true,
+		CheckSameSSN:        true,
+		CheckClickIP:        true,
+		MaxClicksPerIP:      10,
+		VelocityMaxPerCode:  5,
+		VelocityWindowHours: 24,
+	}
+}
+
+// FraudFlag describes a single fraud rule that matched a commission
+type FraudFlag struct {
+	Rule   string `json:"rule"`
+	Reason string `json:"reason"`
+}
+
+// Fraud rule identifiers
+const (
+	FraudRuleSelfReferral = "SELF_REFERRAL"
+	FraudRuleSameSSN      = "SAME_SSN"
+	FraudRuleClickIP      = "CLICK_IP"
+	FraudRuleVelocity     = "VELOCITY"
+)
@@ -0,0 +1,63 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// IdentityDocument is a photo ID uploaded by a client during portal onboarding.
+// The file lives in tenant storage under a restricted path and is never listed with
+// filing documents; only admins can download it.
+type IdentityDocument struct {
+	ID           uuid.UUID  `json:"id"`
+	TenantID     string     `json:"tenantId"`
+	TenantUserID uuid.UUID  `json:"tenantUserId"`
+	ClientID     *uuid.UUID `json:"clientId,omitempty"` // nil when uploaded before the client record existed
+	Kind         string     `json:"kind"`
+	FileName     string     `json:"fileName"`
+	ContentType  string     `json:"contentType"`
+	SizeBytes    int64      `json:"sizeBytes"`
+	StoragePath  string     `json:"-"`
+	Status       string     `json:"status"`
+	ExpiresOn    *string    `json:"expiresOn,omitempty"`   // YYYY-MM-DD as read from the document
+	NameMatched  *bool      `json:"nameMatched,omitempty"` // nil when there was no client name to compare
+	Flags        []string   `json:"flags"`                 // Automated check findings, see idcheck.Flag*
+	Provider     string     `json:"provider"`              // Text recognition provider
+	ReviewedBy   *uuid.UUID `json:"reviewedBy,omitempty"`
+	ReviewedAt   *time.Time `json:"reviewedAt,omitempty"`
+	ReviewNote   *string    `json:"reviewNote,omitempty"`
+	CreatedAt    time.Time  `json:"createdAt"`
+}
+
+// Identity document kind constants
+const (
+	IdentityDocumentDriversLicense = "DRIVERS_LICENSE"
+	IdentityDocumentStateID        = "STATE_ID"
+	IdentityDocumentPassport       = "PASSPORT"
+)
+
+// IsValidIdentityDocumentKind checks a kind value
+func IsValidIdentityDocumentKind(kind string) bool {
+	switch kind {
+	case IdentityDocumentDriversLicense, IdentityDocumentStateID, IdentityDocumentPassport:
+		return true
+	}
+	return false
+}
+
+// Identity document status constants
+const (
+	IdentityStatusVerified    = "VERIFIED"     // Passed the automated checks or approved by an admin
+	IdentityStatusNeedsReview = "NEEDS_REVIEW" // Flagged by the automated checks
+	IdentityStatusRejected    = "REJECTED"     // Rejected by an admin; the client must upload again
+)
+
+// IsValidIdentityStatus checks a status value
+func IsValidIdentityStatus(status string) bool {
+	switch status {
+	case IdentityStatusVerified, IdentityStatusNeedsReview, IdentityStatusRejected:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InboundAddress is the address a tenant's clients email documents to
+type InboundAddress struct {
+	TenantID  string     `json:"tenantId"`
+	LocalPart string     `json:"localPart"`
+	Address   string     `json:"address"` // LocalPart at the configured inbound domain
+	CreatedBy *uuid.UUID `json:"createdBy,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// InboundEmail is one email received at a tenant's inbound address
+type InboundEmail struct {
+	ID                 uuid.UUID            `json:"id"`
+	TenantID           string               `json:"tenantId"`
+	FromEmail          string               `json:"fromEmail"`
+	FromName           *string              `json:"fromName,omitempty"`
+	Subject            *string              `json:"subject,omitempty"`
+	ClientID           *uuid.UUID           `json:"clientId,omitempty"` // Set when exactly one client has the sender's email
+	SkippedAttachments int                  `json:"skippedAttachments"` // Attachments of unsupported type or size
+	AcknowledgedAt     *time.Time           `json:"acknowledgedAt,omitempty"`
+	ReceivedAt         time.Time            `json:"receivedAt"`
+	Attachments        []*InboundAttachment `json:"attachments,omitempty"`
+}
+
+// InboundAttachment is an emailed file waiting for an admin to confirm it as a document
+type InboundAttachment struct {
+	ID          uuid.UUID  `json:"id"`
+	EmailID     uuid.UUID  `json:"emailId"`
+	TenantID    string     `json:"tenantId"`
+	FileName    string     `json:"fileName"`
+	ContentType string     `json:"contentType"`
+	SizeBytes   int64      `json:"sizeBytes"`
+	StoragePath string     `json:"-"`
+	Status      string     `json:"status"`
+	ClientID    *uuid.UUID `json:"clientId,omitempty"` // Matched client until confirmed, then the confirmed client
+	FilingID    *uuid.UUID `json:"filingId,omitempty"`
+	DocumentID  *uuid.UUID `json:"documentId,omitempty"`
+	ReviewedBy  *uuid.UUID `json:"reviewedBy,omitempty"`
+	ReviewedAt  *time.Time `json:"reviewedAt,omitempty"`
+
+	// From the email, for the review queue
+	FromEmail  string    `json:"fromEmail"`
+	Subject    *string   `json:"subject,omitempty"`
+	ReceivedAt time.Time `json:"receivedAt"`
+}
+
+// Inbound attachment statuses
+const (
+	InboundAttachmentPending   = "PENDING"
+	InboundAttachmentConfirmed = "CONFIRMED"
+	InboundAttachmentRejected  = "REJECTED"
+)
+
+// InboundAttachmentTypes are the attachment content types kept from inbound email
+var InboundAttachmentTypes = map[string]bool{
+	"application/pdf": true,
+	"image/jpeg":      true,
+	"image/png":       true,
+}
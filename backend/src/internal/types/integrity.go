@@ -0,0 +1,49 @@
+package types
+
+import "github.com/google/uuid"
+
+// SSNRecord is an encrypted SSN reference used by integrity checks
+// The SSN is never serialized
+type SSNRecord struct {
+	ClientID     uuid.UUID `json:"clientId"`
+	Role         string    `json:"role"` // taxpayer or spouse
+	Name         string    `json:"name"`
+	EncryptedSSN string    `json:"-"`
+}
+
+// IntegrityRecord identifies a client record involved in an integrity issue
+type IntegrityRecord struct {
+	ClientID uuid.UUID `json:"clientId"`
+	Role     string    `json:"role"` // taxpayer or spouse
+	Name     string    `json:"name"`
+}
+
+// RemediationLink points to an action that resolves an integrity issue
+type RemediationLink struct {
+	Action      string `json:"action"` // REVIEW, MERGE, ARCHIVE
+	Description string `json:"description"`
+	Method      string `json:"method"`
+	Href        string `json:"href"`
+}
+
+// IntegrityIssue is a single finding from a tenant integrity check
+type IntegrityIssue struct {
+	Type        string             `json:"type"`     // DUPLICATE_SSN, TAXPAYER_SPOUSE_SSN, SPOUSE_SSN_SHARED
+	Severity    string             `json:"severity"` // HIGH, MEDIUM
+	MaskedSSN   string             `json:"maskedSsn"`
+	Records     []*IntegrityRecord `json:"records"`
+	Remediation []*RemediationLink `json:"remediation"`
+}
+
+// SSN record role constants
+const (
+	SSNRoleTaxpayer = "taxpayer"
+	SSNRoleSpouse   = "spouse"
+)
+
+// Integrity issue type constants
+const (
+	IntegrityDuplicateSSN      = "DUPLICATE_SSN"       // Same SSN on multiple client (taxpayer) records
+	IntegrityTaxpayerSpouseSSN = "TAXPAYER_SPOUSE_SSN" // SSN is a taxpayer on one client and a spouse on another
+	IntegritySpouseSSNShared   = "SPOUSE_SSN_SHARED"   // SSN is the spouse on several clients and a taxpayer on none
+)
@@ -0,0 +1,62 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LegalDocument is a versioned terms of service or privacy policy that portal users must accept
+type LegalDocument struct {
+	ID        uuid.UUID  `json:"id"`
+	TenantID  string     `json:"tenantId"`
+	Kind      string     `json:"kind"`
+	Version   int        `json:"version"`
+	Title     string     `json:"title"`
+	Body      string     `json:"body"`
+	IsActive  bool       `json:"isActive"`
+	CreatedBy *uuid.UUID `json:"createdBy,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// LegalAcceptance records a portal user accepting one version of a legal document
+type LegalAcceptance struct {
+	ID           uuid.UUID `json:"id"`
+	TenantID     string    `json:"tenantId"`
+	TenantUserID uuid.UUID `json:"tenantUserId"`
+	DocumentID   uuid.UUID `json:"documentId"`
+	Kind         string    `json:"kind"`
+	Version      int       `json:"version"`
+	AcceptedAt   time.Time `json:"acceptedAt"`
+	AcceptedIP   *string   `json:"acceptedIp,omitempty"`
+}
+
+// LegalDocumentStatus pairs an active document with the user's most recent acceptance of its kind.
+// Accepted is false when the user has not accepted the current version, including after a version bump.
+type LegalDocumentStatus struct {
+	Document   *LegalDocument   `json:"document"`
+	Acceptance *LegalAcceptance `json:"acceptance,omitempty"`
+	Accepted   bool             `json:"accepted"`
+}
+
+// Legal document kinds
+const (
+	LegalDocumentKindTerms   = "TERMS_OF_SERVICE"
+	LegalDocumentKindPrivacy = "PRIVACY_POLICY"
+)
+
+// LegalDocumentKinds lists every supported kind
+var LegalDocumentKinds = []string{
+	LegalDocumentKindTerms,
+	LegalDocumentKindPrivacy,
+}
+
+// IsValidLegalDocumentKind checks a kind value
+func IsValidLegalDocumentKind(k string) bool {
+	for _, kind := range LegalDocumentKinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,86 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MailSettings holds the return address printed on a tenant's mailings
+type MailSettings struct {
+	TenantID      string     `json:"tenantId"`
+	ReturnName    string     `json:"returnName"`
+	ReturnAddress Address    `json:"returnAddress"`
+	UpdatedBy     *uuid.UUID `json:"updatedBy,omitempty"`
+	UpdatedAt     time.Time  `json:"updatedAt"`
+}
+
+// Mailing is a request to print and mail a set of a client's documents
+type Mailing struct {
+	ID            uuid.UUID    `json:"id"`
+	TenantID      string       `json:"tenantId"`
+	ClientID      uuid.UUID    `json:"clientId"`
+	RecipientName string       `json:"recipientName"`
+	Address       Address      `json:"address"`
+	Description   *string      `json:"description,omitempty"`
+	Color         bool         `json:"color"`
+	RequestedBy   *uuid.UUID   `json:"requestedBy,omitempty"`
+	CreatedAt     time.Time    `json:"createdAt"`
+	CostCents     int64        `json:"costCents"` // Sum of the pieces' costs
+	Pieces        []*MailPiece `json:"pieces"`
+}
+
+// MailPiece is one letter of a mailing, carrying a single document
+type MailPiece struct {
+	ID               uuid.UUID  `json:"id"`
+	MailingID        uuid.UUID  `json:"mailingId"`
+	TenantID         string     `json:"tenantId"`
+	DocumentID       uuid.UUID  `json:"documentId"`
+	DocumentName     string     `json:"documentName"`
+	Provider         string     `json:"provider"`
+	ProviderID       *string    `json:"providerId,omitempty"`
+	Status           string     `json:"status"`
+	CostCents        int64      `json:"costCents"`
+	ExpectedDelivery *time.Time `json:"expectedDelivery,omitempty"`
+	Error            *string    `json:"error,omitempty"`
+	UpdatedAt        time.Time  `json:"updatedAt"`
+}
+
+// Mail piece statuses
+const (
+	MailStatusPending   = "PENDING"   // Recorded, not yet accepted by the provider
+	MailStatusSubmitted = "SUBMITTED" // Accepted by the provider
+	MailStatusMailed    = "MAILED"
+	MailStatusInTransit = "IN_TRANSIT"
+	MailStatusDelivered = "DELIVERED"
+	MailStatusReturned  = "RETURNED"
+	MailStatusCancelled = "CANCELLED"
+	MailStatusFailed    = "FAILED"
+)
+
+// mailStatusOrder ranks statuses so late or repeated provider events cannot move a piece backwards
+var mailStatusOrder = map[string]int{
+	MailStatusPending:   0,
+	MailStatusSubmitted: 1,
+	MailStatusMailed:    2,
+	MailStatusInTransit: 3,
+	MailStatusDelivered: 4,
+	MailStatusReturned:  4,
+	MailStatusCancelled: 4,
+	MailStatusFailed:    4,
+}
+
+// MailStatusAdvances reports whether a piece in status from may move to status to
+func MailStatusAdvances(from, to string) bool {
+	rankFrom, okFrom := mailStatusOrder[from]
+	rankTo, okTo := mailStatusOrder[to]
+	return okFrom && okTo && rankTo > rankFrom
+}
+
+// MailCostSummary totals a tenant's mailing costs for one month
+type MailCostSummary struct {
+	Month     string `json:"month"` // YYYY-MM
+	Mailings  int    `json:"mailings"`
+	Pieces    int    `json:"pieces"`
+	CostCents int64  `json:"costCents"`
+}
@@ -0,0 +1,308 @@
+package types
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Cents is a currency amount in integer minor units (US cents).
+// Commission, payment and payout math is done on Cents so totals never drift by a
+// fraction of a cent. It reads and writes NUMERIC dollar columns exactly and marshals
+// to JSON as a dollar amount with two decimals, so API payloads keep their shape.
+type Cents int64
+
+// Rate is a percentage in hundredths of a percent: 12.5% is 1250.
+// It marshals to JSON as the percentage (12.5).
+type Rate int64
+
+// MaxRate is 100%
+const MaxRate Rate = 100 * 100
+
+// ParseCents parses a dollar amount such as "12.34" or "-0.5" without going through
+// floating point. Digits beyond the cent are rounded half away from zero.
+func ParseCents(s string) (Cents, error) {
+	v, err := parseFixed(s, 2)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+	return Cents(v), nil
+}
+
+// ParseMinorUnits parses an amount already stored in cents, such as "4999" or "4999.00"
+// from a NUMERIC column, rounding any fraction of a cent half away from zero
+func ParseMinorUnits(s string) (Cents, error) {
+	v, err := parseFixed(s, 0)
+	if err != nil {
+		return 0, fmt.Errorf("invalid amount %q: %w", s, err)
+	}
+	return Cents(v), nil
+}
+
+// ParseRate parses a percentage such as "15" or "12.5"; digits beyond a hundredth of a
+// percent are rounded half away from zero
+func ParseRate(s string) (Rate, error) {
+	v, err := parseFixed(s, 2)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate %q: %w", s, err)
+	}
+	return Rate(v), nil
+}
+
+// Percent returns r of c, rounded half away from zero to the cent
+func (c Cents) Percent(r Rate) Cents {
+	return Cents(divRound(int64(c)*int64(r), int64(MaxRate)))
+}
+
+// Div splits c into n equal parts, rounded half away from zero to the cent; 0 for n <= 0
+func (c Cents) Div(n int) Cents {
+	if n <= 0 {
+		return 0
+	}
+	return Cents(divRound(int64(c), int64(n)))
+}
+
+// Dollars returns c as a float for display only; never do arithmetic on the result
+func (c Cents) Dollars() float64 {
+	return float64(c) / 100
+}
+
+// String formats c as dollars with two decimals, e.g. "12.34" or "-0.05"
+func (c Cents) String() string {
+	return formatFixed(int64(c), 2, false)
+}
+
+// MarshalJSON writes c as a JSON number of dollars
+func (c Cents) MarshalJSON() ([]byte, error) {
+	return []byte(c.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number or numeric string of dollars
+func (c *Cents) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		return nil
+	}
+	v, err := ParseCents(s)
+	if err != nil {
+		return err
+	}
+	*c = v
+	return nil
+}
+
+// Scan reads a dollar amount from a NUMERIC, integer or float column
+func (c *Cents) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return c.scanText(string(v))
+	case string:
+		return c.scanText(v)
+	case int64:
+		*c = Cents(v * 100)
+		return nil
+	case float64:
+		*c = Cents(math.Round(v * 100))
+		return nil
+	case nil:
+		*c = 0
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into Cents", src)
+}
+
+func (c *Cents) scanText(s string) error {
+	v, err := ParseCents(s)
+	if err != nil {
+		return err
+	}
+	*c = v
+	return nil
+}
+
+// Value writes c as an exact dollar amount for a NUMERIC column
+func (c Cents) Value() (driver.Value, error) {
+	return c.String(), nil
+}
+
+// Percent returns the rate as a float percentage for display and comparison only
+func (r Rate) Percent() float64 {
+	return float64(r) / 100
+}
+
+// Valid reports whether r is between 0% and 100%
+func (r Rate) Valid() bool {
+	return r >= 0 && r <= MaxRate
+}
+
+// String formats r as a percentage without trailing zeros, e.g. "15" or "12.5"
+func (r Rate) String() string {
+	return formatFixed(int64(r), 2, true)
+}
+
+// MarshalJSON writes r as a JSON number percentage
+func (r Rate) MarshalJSON() ([]byte, error) {
+	return []byte(r.String()), nil
+}
+
+// UnmarshalJSON reads a JSON number or numeric string percentage
+func (r *Rate) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		return nil
+	}
+	v, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// Scan reads a percentage from a NUMERIC, integer or float column
+func (r *Rate) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return r.scanText(string(v))
+	case string:
+		return r.scanText(v)
+	case int64:
+		*r = Rate(v * 100)
+		return nil
+	case float64:
+		*r = Rate(math.Round(v * 100))
+		return nil
+	case nil:
+		*r = 0
+		return nil
+	}
+	return fmt.Errorf("cannot scan %T into Rate", src)
+}
+
+func (r *Rate) scanText(s string) error {
+	v, err := ParseRate(s)
+	if err != nil {
+		return err
+	}
+	*r = v
+	return nil
+}
+
+// Value writes r as an exact percentage for a NUMERIC column
+func (r Rate) Value() (driver.Value, error) {
+	return r.String(), nil
+}
+
+// parseFixed parses a decimal string into an integer scaled by 10^places, rounding
+// extra digits half away from zero. Exponents are accepted for JSON numbers like 1e2.
+func parseFixed(s string, places int) (int64, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, fmt.Errorf("empty number")
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	exponent := 0
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		e, err := strconv.Atoi(s[i+1:])
+		if err != nil || e > 18 || e < -18 {
+			return 0, fmt.Errorf("bad exponent")
+		}
+		exponent = e
+		s = s[:i]
+	}
+
+	whole, frac := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		whole, frac = s[:i], s[i+1:]
+	}
+	if whole == "" && frac == "" {
+		return 0, fmt.Errorf("no digits")
+	}
+	digits := whole + frac
+	for _, ch := range digits {
+		if ch < '0' || ch > '9' {
+			return 0, fmt.Errorf("not a number")
+		}
+	}
+
+	// Position of the decimal point within digits once scaled
+	point := len(whole) + exponent + places
+	if point < 0 {
+		digits = strings.Repeat("0", -point) + digits
+		point = 0
+	}
+	for len(digits) < point {
+		digits += "0"
+	}
+
+	kept, dropped := strings.TrimLeft(digits[:point], "0"), digits[point:]
+	if len(kept) > 18 {
+		return 0, fmt.Errorf("out of range")
+	}
+
+	var v int64
+	if kept != "" {
+		parsed, err := strconv.ParseInt(kept, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("out of range")
+		}
+		v = parsed
+	}
+	if dropped != "" && dropped[0] >= '5' {
+		v++
+	}
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
+
+// formatFixed formats v scaled by 10^places as a decimal, optionally trimming trailing zeros
+func formatFixed(v int64, places int, trim bool) string {
+	sign := ""
+	u := uint64(v)
+	if v < 0 {
+		sign = "-"
+		u = uint64(-v)
+	}
+
+	s := strconv.FormatUint(u, 10)
+	if len(s) <= places {
+		s = strings.Repeat("0", places-len(s)+1) + s
+	}
+	whole, frac := s[:len(s)-places], s[len(s)-places:]
+	if trim {
+		frac = strings.TrimRight(frac, "0")
+	}
+	if frac == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + frac
+}
+
+// divRound divides a by b (b > 0), rounding half away from zero
+func divRound(a, b int64) int64 {
+	q, r := a/b, a%b
+	if r < 0 {
+		r = -r
+	}
+	if 2*r >= b {
+		if a < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
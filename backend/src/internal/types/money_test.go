@@ -0,0 +1,401 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseCents(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Cents
+		wantErr bool
+	}{
+		{in: "12.34", want: 1234},
+		{in: "12", want: 1200},
+		{in: ".5", want: 50},
+		{in: "+3.10", want: 310},
+		{in: " 7.01 ", want: 701},
+		{in: "-0.5", want: -50},
+		{in: "-12.34", want: -1234},
+		{in: "12.345", want: 1235},
+		{in: "12.344", want: 1234},
+		{in: "-12.345", want: -1235},
+		{in: "0.005", want: 1},
+		{in: "-0.005", want: -1},
+		{in: "0.0049999", want: 0},
+		{in: "1e3", want: 100000},
+		{in: "1.5E-1", want: 15},
+		{in: "-2e0", want: -200},
+		{in: "9999999999999999.99", want: 999999999999999999},
+		{in: "99999999999999999", wantErr: true},
+		{in: "92233720368547758.08", wantErr: true},
+		{in: "1e19", wantErr: true},
+		{in: "1e", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "-", wantErr: true},
+		{in: ".", wantErr: true},
+		{in: "1.2.3", wantErr: true},
+		{in: "$5", wantErr: true},
+		{in: "--5", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseCents(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseCents(%q) = %d, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseCents(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseCents(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseMinorUnits(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Cents
+	}{
+		{"4999", 4999},
+		{"4999.00", 4999},
+		{"4999.5", 5000},
+		{"-4999.5", -5000},
+		{"4999.49", 4999},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMinorUnits(tt.in)
+		if err != nil {
+			t.Errorf("ParseMinorUnits(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMinorUnits(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rate
+		wantErr bool
+	}{
+		{in: "15", want: 1500},
+		{in: "12.5", want: 1250},
+		{in: "0.01", want: 1},
+		{in: "12.345", want: 1235},
+		{in: "12.344", want: 1234},
+		{in: "-12.345", want: -1235},
+		{in: "-5", want: -500},
+		{in: "1e2", want: 10000},
+		{in: "2.5e1", want: 2500},
+		{in: "100000000000000000", wantErr: true},
+		{in: "12%", wantErr: true},
+		{in: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRate(tt.in)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ParseRate(%q) = %d, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ParseRate(%q) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseRate(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRateValid(t *testing.T) {
+	tests := []struct {
+		rate Rate
+		want bool
+	}{
+		{0, true},
+		{1250, true},
+		{MaxRate, true},
+		{MaxRate + 1, false},
+		{-1, false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rate.Valid(); got != tt.want {
+			t.Errorf("Rate(%d).Valid() = %v, want %v", tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestCentsPercent(t *testing.T) {
+	tests := []struct {
+		cents Cents
+		rate  Rate
+		want  Cents
+	}{
+		{12345, 1250, 1543},   // 1543.125
+		{-12345, 1250, -1543}, // -1543.125
+		{12345, 1000, 1235},   // 1234.5 rounds up
+		{-12345, 1000, -1235}, // -1234.5 rounds away from zero
+		{1, 5000, 1},          // 0.5
+		{-1, 5000, -1},        // -0.5
+		{3, 5000, 2},          // 1.5
+		{-3, 5000, -2},        // -1.5
+		{1, 4999, 0},          // 0.4999
+		{10000, MaxRate, 10000},
+		{10000, 0, 0},
+		{0, 1250, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.cents.Percent(tt.rate); got != tt.want {
+			t.Errorf("Cents(%d).Percent(%d) = %d, want %d", tt.cents, tt.rate, got, tt.want)
+		}
+	}
+}
+
+func TestCentsDiv(t *testing.T) {
+	tests := []struct {
+		cents Cents
+		n     int
+		want  Cents
+	}{
+		{100, 4, 25},
+		{5, 2, 3},   // 2.5
+		{-5, 2, -3}, // -2.5
+		{10, 3, 3},
+		{-10, 3, -3},
+		{20, 3, 7},   // 6.67
+		{-20, 3, -7}, // -6.67
+		{100, 0, 0},
+		{100, -2, 0},
+	}
+
+	for _, tt := range tests {
+		if got := tt.cents.Div(tt.n); got != tt.want {
+			t.Errorf("Cents(%d).Div(%d) = %d, want %d", tt.cents, tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestDivRound(t *testing.T) {
+	tests := []struct {
+		a, b, want int64
+	}{
+		{7, 2, 4},
+		{-7, 2, -4},
+		{1, 3, 0},
+		{-1, 3, 0},
+		{2, 3, 1},
+		{-2, 3, -1},
+		{6, 3, 2},
+		{-6, 3, -2},
+		{0, 5, 0},
+	}
+
+	for _, tt := range tests {
+		if got := divRound(tt.a, tt.b); got != tt.want {
+			t.Errorf("divRound(%d, %d) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestCentsJSON(t *testing.T) {
+	tests := []struct {
+		cents Cents
+		json  string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{-5, "-0.05"},
+		{1234, "12.34"},
+		{-123456, "-1234.56"},
+		{100000, "1000.00"},
+	}
+
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.cents)
+		if err != nil {
+			t.Fatalf("Marshal(%d): %v", tt.cents, err)
+		}
+		if string(data) != tt.json {
+			t.Errorf("Marshal(%d) = %s, want %s", tt.cents, data, tt.json)
+		}
+
+		var back Cents
+		if err := json.Unmarshal(data, &back); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+		if back != tt.cents {
+			t.Errorf("round trip of %d = %d", tt.cents, back)
+		}
+	}
+}
+
+func TestCentsUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Cents
+		wantErr bool
+	}{
+		{in: `12.34`, want: 1234},
+		{in: `"12.34"`, want: 1234},
+		{in: `"1000.0000"`, want: 100000},
+		{in: `1e2`, want: 10000},
+		{in: `12.345`, want: 1235},
+		{in: `null`, want: 77}, // left unchanged
+		{in: `"abc"`, wantErr: true},
+		{in: `true`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got := Cents(77)
+		err := json.Unmarshal([]byte(tt.in), &got)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Unmarshal(%s) = %d, want error", tt.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Unmarshal(%s) error: %v", tt.in, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRateJSON(t *testing.T) {
+	tests := []struct {
+		rate Rate
+		json string
+	}{
+		{0, "0"},
+		{1, "0.01"},
+		{-50, "-0.5"},
+		{1250, "12.5"},
+		{1500, "15"},
+		{MaxRate, "100"},
+	}
+
+	for _, tt := range tests {
+		data, err := json.Marshal(tt.rate)
+		if err != nil {
+			t.Fatalf("Marshal(%d): %v", tt.rate, err)
+		}
+		if string(data) != tt.json {
+			t.Errorf("Marshal(%d) = %s, want %s", tt.rate, data, tt.json)
+		}
+
+		var back Rate
+		if err := json.Unmarshal(data, &back); err != nil {
+			t.Fatalf("Unmarshal(%s): %v", data, err)
+		}
+		if back != tt.rate {
+			t.Errorf("round trip of %d = %d", tt.rate, back)
+		}
+	}
+
+	var quoted Rate
+	if err := json.Unmarshal([]byte(`"12.5"`), &quoted); err != nil || quoted != 1250 {
+		t.Errorf(`Unmarshal("12.5") = %d, %v; want 1250`, quoted, err)
+	}
+}
+
+func TestCentsScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    Cents
+		wantErr bool
+	}{
+		{name: "numeric bytes", src: []byte("12.34"), want: 1234},
+		{name: "numeric bytes with scale 4", src: []byte("1000.0000"), want: 100000},
+		{name: "negative numeric", src: []byte("-0.05"), want: -5},
+		{name: "numeric string", src: "49.99", want: 4999},
+		{name: "sub-cent numeric", src: "0.125", want: 13},
+		{name: "integer", src: int64(12), want: 1200},
+		{name: "float", src: 0.1 + 0.2, want: 30},
+		{name: "null", src: nil, want: 0},
+		{name: "bad text", src: []byte("n/a"), wantErr: true},
+		{name: "unsupported type", src: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Cents(77)
+			err := got.Scan(tt.src)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Scan(%v) = %d, want error", tt.src, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan(%v) error: %v", tt.src, err)
+			}
+			if got != tt.want {
+				t.Errorf("Scan(%v) = %d, want %d", tt.src, got, tt.want)
+			}
+
+			// Value writes what Scan reads back
+			value, err := got.Value()
+			if err != nil {
+				t.Fatalf("Value: %v", err)
+			}
+			var back Cents
+			if err := back.Scan(value); err != nil || back != got {
+				t.Errorf("Scan(Value()) = %d, %v; want %d", back, err, got)
+			}
+		})
+	}
+}
+
+func TestRateScan(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     interface{}
+		want    Rate
+		wantErr bool
+	}{
+		{name: "numeric bytes", src: []byte("12.5000"), want: 1250},
+		{name: "numeric string", src: "15", want: 1500},
+		{name: "integer", src: int64(20), want: 2000},
+		{name: "float", src: 12.345, want: 1235},
+		{name: "null", src: nil, want: 0},
+		{name: "unsupported type", src: 1.5i, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got Rate
+			err := got.Scan(tt.src)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("Scan(%v) = %d, want error", tt.src, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Scan(%v) error: %v", tt.src, err)
+			}
+			if got != tt.want {
+				t.Errorf("Scan(%v) = %d, want %d", tt.src, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationPreference is how an employee is told about one event category. Every category
+// that is not off shows up in the staff console; Email and Slack choose the other channels.
+type NotificationPreference struct {
+	Category string `json:"category"`
+	Mode     string `json:"mode"`  // immediate, digest, off
+	Email    bool   `json:"email"` // Email immediately or in the digest, per mode
+	Slack    bool   `json:"slack"` // Slack direct message for immediate notifications
+}
+
+// DefaultNotificationPreference returns the preference of a category the employee never set
+func DefaultNotificationPreference(category string) *NotificationPreference {
+	return &NotificationPreference{Category: category, Mode: NotificationModeImmediate, Email: true}
+}
+
+// EmployeeNotification is a notification shown in an employee's staff console inbox
+type EmployeeNotification struct {
+	ID         uuid.UUID  `json:"id"`
+	EmployeeID uuid.UUID  `json:"employeeId"`
+	TenantID   *string    `json:"tenantId,omitempty"`
+	Category   string     `json:"category"`
+	Subject    string     `json:"subject"`
+	Body       string     `json:"body"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	ReadAt     *time.Time `json:"readAt,omitempty"`
+}
+
+// NotificationChannels holds an employee's addresses on channels other than email
+type NotificationChannels struct {
+	SlackUserID *string `json:"slackUserId"` // Slack member ID, e.g. U024BE7LH; nil sends no Slack messages
+}
+
+// Validate checks notification channels
+func (c *NotificationChannels) Validate() string {
+	if c.SlackUserID == nil {
+		return ""
+	}
+	id := *c.SlackUserID
+	if len(id) < 9 || len(id) > 20 || (id[0] != 'U' && id[0] != 'W') {
+		return "slackUserId must be a Slack member ID starting with U or W"
+	}
+	for _, r := range id {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') {
+			return "slackUserId must be a Slack member ID starting with U or W"
+		}
+	}
+	return ""
+}
+
+// NotificationEvent is an alert held for an employee's daily digest
+type NotificationEvent struct {
+	ID         uuid.UUID  `json:"id"`
+	EmployeeID uuid.UUID  `json:"employeeId"`
+	TenantID   *string    `json:"tenantId,omitempty"`
+	Category   string     `json:"category"`
+	Subject    string     `json:"subject"`
+	Body       string     `json:"body"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	DigestedAt *time.Time `json:"digestedAt,omitempty"`
+}
+
+// Notification event categories
+const (
+	NotificationCategoryDispute    = "DISPUTE"
+	NotificationCategoryUpload     = "UPLOAD"
+	NotificationCategoryAnomaly    = "ANOMALY"
+	NotificationCategoryCommission = "COMMISSION"
+	NotificationCategoryMailing    = "MAILING"
+	NotificationCategoryConnection = "CONNECTION"
+	NotificationCategoryFiling     = "FILING"
+	NotificationCategorySecurity   = "SECURITY"
+)
+
+// Notification delivery modes
+const (
+	NotificationModeImmediate = "immediate"
+	NotificationModeDigest    = "digest"
+	NotificationModeOff       = "off"
+)
+
+// NotificationCategories lists every category an employee can configure
+var NotificationCategories = []string{
+	NotificationCategoryDispute,
+	NotificationCategoryUpload,
+	NotificationCategoryAnomaly,
+	NotificationCategoryCommission,
+	NotificationCategoryMailing,
+	NotificationCategoryConnection,
+	NotificationCategoryFiling,
+	NotificationCategorySecurity,
+}
+
+// IsValidNotificationCategory checks a category value
+func IsValidNotificationCategory(c string) bool {
+	for _, category := range NotificationCategories {
+		if category == c {
+			return true
+		}
+	}
+	return false
+}
+
+// IsValidNotificationMode checks a delivery mode value
+func IsValidNotificationMode(m string) bool {
+	return m == NotificationModeImmediate || m == NotificationModeDigest || m == NotificationModeOff
+}
@@ -0,0 +1,93 @@
+package types
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/google/uuid"
+)
+
+// Page sizes for cursor-paginated listings
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 500
+)
+
+// PageCursor is the position of the last row of a page. Paginated listings are ordered
+// newest first by (created_at, id), and the next page starts strictly after the cursor.
+type PageCursor struct {
+	CreatedAt string `json:"c"` // Timestamp of the last row as read from the database
+	ID        string `json:"i"`
+}
+
+// Encode returns the cursor as the opaque string handed to API clients
+func (c PageCursor) Encode() string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+// DecodePageCursor parses a cursor produced by Encode, returning false when it is malformed
+func DecodePageCursor(encoded string) (*PageCursor, bool) {
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, false
+	}
+	var cursor PageCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, false
+	}
+	if cursor.CreatedAt == "" {
+		return nil, false
+	}
+	if _, err := uuid.Parse(cursor.ID); err != nil {
+		return nil, false
+	}
+	return &cursor, true
+}
+
+// PageRequest selects one page of a listing
+type PageRequest struct {
+	Limit int         // Page size; DefaultPageSize when zero, capped at MaxPageSize
+	After *PageCursor // Nil for the first page
+}
+
+// Size returns the page size after applying the default and the cap
+func (p PageRequest) Size() int {
+	if p.Limit <= 0 {
+		return DefaultPageSize
+	}
+	if p.Limit > MaxPageSize {
+		return MaxPageSize
+	}
+	return p.Limit
+}
+
+// PageInfo describes where a page sits in the full listing
+type PageInfo struct {
+	NextCursor *string `json:"nextCursor"` // Nil on the last page
+	TotalCount int     `json:"totalCount"` // Rows matching the filters across all pages
+}
+
+// ClientPage is one page of clients
+type ClientPage struct {
+	Items []*Client `json:"items"`
+	PageInfo
+}
+
+// FilingPage is one page of clients with filings, ordered by their most recent filing
+type FilingPage struct {
+	Items []*ClientComprehensive `json:"items"`
+	PageInfo
+}
+
+// CommissionPage is one page of commissions
+type CommissionPage struct {
+	Items []*Commission `json:"items"`
+	PageInfo
+}
+
+// DiscountCodePage is one page of discount codes
+type DiscountCodePage struct {
+	Items []*DiscountCode `json:"items"`
+	PageInfo
+}
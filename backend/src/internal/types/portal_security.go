@@ -0,0 +1,127 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Ways a portal sign-in proves it belongs to the client
+const (
+	PortalVerifyNone     = "NONE"      // The Firebase sign-in is enough (default)
+	PortalVerifySSNLast4 = "SSN_LAST4" // Last 4 digits of the client's SSN
+	PortalVerifyDOB      = "DOB"       // The client's date of birth
+	PortalVerifyOTP      = "OTP"       // A one-time code emailed to the portal user
+)
+
+// IsValidPortalVerificationMethod checks a portal verification method
+func IsValidPortalVerificationMethod(method string) bool {
+	switch method {
+	case PortalVerifyNone, PortalVerifySSNLast4, PortalVerifyDOB, PortalVerifyOTP:
+		return true
+	}
+	return false
+}
+
+// PortalSecurityPolicy configures how long portal sign-ins last and how they are verified.
+// Stored per tenant in tenant_connections.portal_security_policy (JSONB); tenants without a
+// policy fall back to DefaultPortalSecurityPolicy, which leaves sign-ins to Firebase alone.
+type PortalSecurityPolicy struct {
+	SessionMinutes           int    `json:"sessionMinutes"`           // How long a sign-in lasts from when it was made (0 lasts until Firebase ends it)
+	VerificationMethod       string `json:"verificationMethod"`       // What a sign-in must confirm before reaching client data
+	VerificationReuseMinutes int    `json:"verificationReuseMinutes"` // New sign-ins within this long of a verified one are verified too (0 verifies every sign-in)
+	MaxConcurrentSessions    int    `json:"maxConcurrentSessions"`    // Active sign-ins a user may have; the oldest end when exceeded (0 is unlimited)
+}
+
+// DefaultPortalSecurityPolicy returns the policy applied when a tenant has none
+func DefaultPortalSecurityPolicy() *PortalSecurityPolicy {
+	return &PortalSecurityPolicy{VerificationMethod: PortalVerifyNone}
+}
+
+// TracksSessions reports whether the policy needs portal sign-ins recorded; the default policy does not
+func (p *PortalSecurityPolicy) TracksSessions() bool {
+	return p.SessionMinutes > 0 || p.RequiresVerification() || p.MaxConcurrentSessions > 0
+}
+
+// RequiresVerification reports whether sign-ins must be verified
+func (p *PortalSecurityPolicy) RequiresVerification() bool {
+	return p.VerificationMethod != "" && p.VerificationMethod != PortalVerifyNone
+}
+
+// SessionExpiry returns when a sign-in made at authTime (Unix seconds) ends, or nil when the
+// policy does not limit sessions
+func (p *PortalSecurityPolicy) SessionExpiry(authTime int64) *time.Time {
+	if p.SessionMinutes <= 0 {
+		return nil
+	}
+	expiry := time.Unix(authTime, 0).Add(time.Duration(p.SessionMinutes) * time.Minute)
+	return &expiry
+}
+
+// Why portal sessions end
+const (
+	PortalSessionEndExpired            = "EXPIRED"             // Older than the policy's session length
+	PortalSessionEndLimit              = "SESSION_LIMIT"       // Newer sign-ins exceeded the concurrent session limit
+	PortalSessionEndVerificationFailed = "VERIFICATION_FAILED" // Too many wrong verification answers
+	PortalSessionEndTenantDeactivated  = "TENANT_DEACTIVATED"  // The tenant was deactivated
+)
+
+// PortalVerificationMaxAttempts is how many wrong answers end a portal session
+const PortalVerificationMaxAttempts = 5
+
+// PortalVerificationLockAttempts is how many wrong answers in a row, across every sign-in, lock a
+// portal user out of verification until the firm sends the client a new portal link
+const PortalVerificationLockAttempts = 10
+
+// PortalOTPLifetime is how long an emailed portal verification code can be used
+const PortalOTPLifetime = 10 * time.Minute
+
+// PortalSessionRetention is how long portal sessions are kept after they were last used
+const PortalSessionRetention = 30 * 24 * time.Hour
+
+// PortalSession is a portal sign-in, identified by the Firebase UID and the auth_time claim every
+// ID token refreshed from it carries. Sessions are only recorded while the tenant's policy needs them.
+type PortalSession struct {
+	ID                 uuid.UUID  `json:"id"`
+	TenantID           string     `json:"tenantId"`
+	FirebaseUID        string     `json:"-"`
+	AuthTime           int64      `json:"authTime"`
+	CreatedAt          time.Time  `json:"createdAt"`
+	LastSeenAt         time.Time  `json:"lastSeenAt"`
+	ExpiresAt          *time.Time `json:"expiresAt,omitempty"` // From the current policy
+	VerifiedAt         *time.Time `json:"verifiedAt,omitempty"`
+	VerificationMethod *string    `json:"verificationMethod,omitempty"` // Method the session was verified with
+	FailedAttempts     int        `json:"failedAttempts"`
+	EndedAt            *time.Time `json:"endedAt,omitempty"`
+	EndReason          *string    `json:"endReason,omitempty"`
+}
+
+// PortalSessionStatus is what the portal needs to know about its sign-in
+type PortalSessionStatus struct {
+	Session            *PortalSession `json:"session,omitempty"` // Nil when the tenant's policy does not record sessions
+	VerificationMethod string         `json:"verificationMethod"`
+	Verified           bool           `json:"verified"` // No verification is needed, or it was given
+	SessionMinutes     int            `json:"sessionMinutes"`
+}
+
+// PortalVerificationChallenge tells the portal what to ask the client for
+type PortalVerificationChallenge struct {
+	Method    string     `json:"method"`              // May be OTP when the client has no SSN or date of birth on record
+	SentTo    string     `json:"sentTo,omitempty"`    // Masked address the code was emailed to
+	ExpiresAt *time.Time `json:"expiresAt,omitempty"` // When the emailed code stops working
+}
+
+// PortalVerificationFailure records a wrong answer to a portal sign-in's verification
+type PortalVerificationFailure struct {
+	ID           uuid.UUID  `json:"id"`
+	TenantID     string     `json:"tenantId"`
+	TenantUserID uuid.UUID  `json:"tenantUserId"`
+	ClientID     uuid.UUID  `json:"clientId"`
+	SessionID    *uuid.UUID `json:"sessionId,omitempty"` // Nil once the session was purged
+	Method       string     `json:"method"`
+	Failures     int        `json:"failures"` // Wrong answers in a row, counting this one
+	Locked       bool       `json:"locked"`   // This answer locked the user out
+	IPAddress    *string    `json:"ipAddress,omitempty"`
+	UserAgent    *string    `json:"userAgent,omitempty"`
+	FailedAt     time.Time  `json:"failedAt"`
+}
@@ -0,0 +1,176 @@
+package types
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Portal next actions, in the order the portal should prompt for them
+const (
+	PortalActionStartFiling     = "START_FILING"
+	PortalActionContinueFiling  = "CONTINUE_FILING"
+	PortalActionMakePayment     = "MAKE_PAYMENT"
+	PortalActionReviewDocuments = "REVIEW_DOCUMENTS"
+	PortalActionNone            = "NONE"
+)
+
+// PortalSummary is the lightweight home-screen payload for the mobile portal
+type PortalSummary struct {
+	ClientID      uuid.UUID            `json:"clientId"`
+	FirstName     string               `json:"firstName,omitempty"`
+	CurrentFiling *PortalFilingSummary `json:"currentFiling,omitempty"`
+	NextAction    string               `json:"nextAction"`
+	BalanceDue    Cents                `json:"balanceDue"`
+	Unread        UnreadCounts         `json:"unread"`
+}
+
+// UnreadCounts counts items added since the portal user last opened their profile
+type UnreadCounts struct {
+	Documents int `json:"documents"`
+}
+
+// PortalFilingSummary is a compact view of one filing for the mobile portal
+type PortalFilingSummary struct {
+	ID            uuid.UUID          `json:"id"`
+	Year          int                `json:"year"`
+	Status        string             `json:"status"`
+	LatestStep    int                `json:"latestStep"`
+	IsCompleted   bool               `json:"isCompleted"`
+	IsFinalReturn bool               `json:"isFinalReturn,omitempty"`
+	DocumentCount int                `json:"documentCount"`
+	AmountPaid    Cents              `json:"amountPaid"`
+	BalanceDue    Cents              `json:"balanceDue"`
+	StateFilings  []StateFilingBrief `json:"stateFilings,omitempty"`
+	NextAction    string             `json:"nextAction"`
+
+	// Return outcome, present once the accountant records it
+	FederalRefund *float64 `json:"federalRefund,omitempty"`
+	FederalOwed   *float64 `json:"federalOwed,omitempty"`
+	StateRefund   *float64 `json:"stateRefund,omitempty"`
+	StateOwed     *float64 `json:"stateOwed,omitempty"`
+	NetRefund     *float64 `json:"netRefund,omitempty"`
+
+	// Where each refund stands once the return is filed
+	Refunds []PortalRefundStatus `json:"refunds,omitempty"`
+}
+
+// StateFilingBrief is the state and status of a state return
+type StateFilingBrief struct {
+	State  string `json:"state"`
+	Status string `json:"status"`
+}
+
+// isPaidStatus reports whether a Stripe checkout/payment status represents settled money
+func isPaidStatus(status string) bool {
+	switch strings.ToLower(status) {
+	case "paid", "complete", "completed", "succeeded":
+		return true
+	}
+	return false
+}
+
+// Summary builds the compact view of a filing; documents created after lastSeen count as unread
+func (f *Filing) Summary(lastSeen *time.Time) (*PortalFilingSummary, int) {
+	s := &PortalFilingSummary{
+		ID:            f.ID,
+		Year:          f.Year,
+		IsFinalReturn: f.IsFinalReturn,
+		DocumentCount: len(f.Documents),
+		Status:        "NOT_STARTED",
+	}
+	if f.Status != nil {
+		s.Status = f.Status.Status
+		s.LatestStep = f.Status.LatestStep
+		s.IsCompleted = f.Status.IsCompleted
+	}
+
+	for _, p := range f.Payments {
+		if isPaidStatus(p.Status) {
+			s.AmountPaid += p.Amount
+		} else {
+			s.BalanceDue += p.Amount
+		}
+	}
+
+	for _, sf := range f.StateFilings {
+		s.StateFilings = append(s.StateFilings, StateFilingBrief{State: sf.State, Status: sf.Status})
+	}
+
+	if r := f.Result; r != nil {
+		net := r.NetRefund()
+		s.FederalRefund = &r.FederalRefund
+		s.FederalOwed = &r.FederalOwed
+		s.StateRefund = &r.StateRefund
+		s.StateOwed = &r.StateOwed
+		s.NetRefund = &net
+	}
+
+	for _, rt := range f.Refunds {
+		s.Refunds = append(s.Refunds, rt.PortalStatus())
+	}
+
+	unread := 0
+	if lastSeen != nil {
+		for _, d := range f.Documents {
+			created, err := time.Parse(time.RFC3339Nano, d.CreatedAt)
+			if err == nil && created.After(*lastSeen) {
+				unread++
+			}
+		}
+	} else {
+		unread = len(f.Documents)
+	}
+
+	switch {
+	case !s.IsCompleted:
+		s.NextAction = PortalActionContinueFiling
+	case s.BalanceDue > 0:
+		s.NextAction = PortalActionMakePayment
+	case unread > 0:
+		s.NextAction = PortalActionReviewDocuments
+	default:
+		s.NextAction = PortalActionNone
+	}
+
+	return s, unread
+}
+
+// PortalSummary builds the home-screen summary from the most recent filing and overall balance
+func (c *ClientComprehensive) PortalSummary(lastSeen *time.Time) *PortalSummary {
+	summary := &PortalSummary{NextAction: PortalActionStartFiling}
+	if c == nil || c.Client == nil {
+		return summary
+	}
+
+	summary.ClientID = c.Client.ID
+	if c.Client.FirstName != nil {
+		summary.FirstName = *c.Client.FirstName
+	}
+
+	var current *Filing
+	for _, f := range c.Filings {
+		fs, unread := f.Summary(lastSeen)
+		summary.BalanceDue += fs.BalanceDue
+		summary.Unread.Documents += unread
+
+		if current == nil || f.Year > current.Year {
+			current = f
+			summary.CurrentFiling = fs
+		}
+	}
+
+	if summary.CurrentFiling != nil {
+		summary.NextAction = summary.CurrentFiling.NextAction
+		// An older filing can still carry an outstanding balance
+		if summary.NextAction == PortalActionNone && summary.BalanceDue > 0 {
+			summary.NextAction = PortalActionMakePayment
+		}
+		if summary.NextAction == PortalActionNone && summary.Unread.Documents > 0 {
+			summary.NextAction = PortalActionReviewDocuments
+		}
+	}
+
+	return summary
+}
@@ -0,0 +1,36 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TenantUserDevice is a registered push notification target for a portal user
+type TenantUserDevice struct {
+	ID           uuid.UUID `json:"id"`
+	TenantUserID uuid.UUID `json:"tenantUserId"`
+	Token        string    `json:"-"` // FCM registration token; never echoed back
+	Platform     string    `json:"platform"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// Device platforms
+const (
+	DevicePlatformIOS     = "ios"
+	DevicePlatformAndroid = "android"
+	DevicePlatformWeb     = "web"
+)
+
+// IsValidDevicePlatform checks a platform value
+func IsValidDevicePlatform(p string) bool {
+	return p == DevicePlatformIOS || p == DevicePlatformAndroid || p == DevicePlatformWeb
+}
+
+// Push notification categories for portal users
+const (
+	PushCategoryFilingMilestone = "FILING_MILESTONE"
+	PushCategoryMessage         = "MESSAGE"
+	PushCategoryDocumentRequest = "DOCUMENT_REQUEST"
+)
@@ -0,0 +1,172 @@
+package types
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// RefundTracking follows one jurisdiction's refund for a filing from acceptance to deposit
+// Field Mapping (MyWellTax adapter):
+//
+//	taxes.refund_tracking.* → RefundTracking fields
+type RefundTracking struct {
+	ID                 uuid.UUID `json:"id"`
+	FilingID           uuid.UUID `json:"filingId"`
+	Jurisdiction       string    `json:"jurisdiction"` // FEDERAL or a two-letter state code
+	ExpectedAmount     float64   `json:"expectedAmount"`
+	Status             string    `json:"status"`
+	AcceptedDate       *string   `json:"acceptedDate,omitempty"`       // YYYY-MM-DD the return was accepted
+	DepositWindowStart *string   `json:"depositWindowStart,omitempty"` // YYYY-MM-DD
+	DepositWindowEnd   *string   `json:"depositWindowEnd,omitempty"`   // YYYY-MM-DD
+	Source             string    `json:"source"`                       // MANUAL, or the integration that reported the status
+	Note               *string   `json:"note,omitempty"`
+	UpdatedBy          *string   `json:"updatedBy,omitempty"` // Email of the employee, or the integration name
+	CreatedAt          string    `json:"createdAt"`
+	UpdatedAt          *string   `json:"updatedAt,omitempty"`
+}
+
+// RefundJurisdictionFederal identifies the IRS refund
+const RefundJurisdictionFederal = "FEDERAL"
+
+// RefundSourceManual marks statuses entered by staff
+const RefundSourceManual = "MANUAL"
+
+// Refund status constants, in the order a refund normally moves through them
+const (
+	RefundStatusAwaitingAcceptance = "AWAITING_ACCEPTANCE"
+	RefundStatusAccepted           = "ACCEPTED"
+	RefundStatusApproved           = "APPROVED"
+	RefundStatusSent               = "SENT"
+	RefundStatusDeposited          = "DEPOSITED"
+	RefundStatusDelayed            = "DELAYED"
+	RefundStatusOffset             = "OFFSET"
+	RefundStatusRejected           = "REJECTED"
+)
+
+// Federal e-filed refunds are usually issued within 21 days of acceptance
+const (
+	federalDepositWindowStartDays = 10
+	federalDepositWindowEndDays   = 21
+)
+
+// IsValidRefundStatus checks a refund status value
+func IsValidRefundStatus(s string) bool {
+	switch s {
+	case RefundStatusAwaitingAcceptance, RefundStatusAccepted, RefundStatusApproved, RefundStatusSent,
+		RefundStatusDeposited, RefundStatusDelayed, RefundStatusOffset, RefundStatusRejected:
+		return true
+	}
+	return false
+}
+
+// Validate checks the refund record; dates must be YYYY-MM-DD and the deposit window in order
+func (t *RefundTracking) Validate() string {
+	if t.Jurisdiction != RefundJurisdictionFederal && len(t.Jurisdiction) != 2 {
+		return "jurisdiction must be FEDERAL or a two-letter state code"
+	}
+	if !IsValidRefundStatus(t.Status) {
+		return "Invalid refund status"
+	}
+	if t.ExpectedAmount < 0 {
+		return "expectedAmount cannot be negative"
+	}
+	for _, d := range []*string{t.AcceptedDate, t.DepositWindowStart, t.DepositWindowEnd} {
+		if d == nil {
+			continue
+		}
+		if _, err := time.Parse("2006-01-02", *d); err != nil {
+			return "Dates must be in YYYY-MM-DD format"
+		}
+	}
+	if (t.DepositWindowStart == nil) != (t.DepositWindowEnd == nil) {
+		return "depositWindowStart and depositWindowEnd must be set together"
+	}
+	if t.DepositWindowStart != nil && *t.DepositWindowStart > *t.DepositWindowEnd {
+		return "depositWindowStart must not be after depositWindowEnd"
+	}
+	return ""
+}
+
+// EstimateDepositWindow fills in the IRS's typical deposit window for an accepted federal refund
+// when none was entered. State timelines vary too much to estimate.
+func (t *RefundTracking) EstimateDepositWindow() {
+	if t.Jurisdiction != RefundJurisdictionFederal || t.AcceptedDate == nil || t.DepositWindowStart != nil {
+		return
+	}
+	accepted, err := time.Parse("2006-01-02", *t.AcceptedDate)
+	if err != nil {
+		return
+	}
+	start := accepted.AddDate(0, 0, federalDepositWindowStartDays).Format("2006-01-02")
+	end := accepted.AddDate(0, 0, federalDepositWindowEndDays).Format("2006-01-02")
+	t.DepositWindowStart = &start
+	t.DepositWindowEnd = &end
+}
+
+// PortalRefundStatus is a refund as shown to the client, with copy explaining where it stands
+type PortalRefundStatus struct {
+	Jurisdiction       string  `json:"jurisdiction"`
+	Status             string  `json:"status"`
+	ExpectedAmount     float64 `json:"expectedAmount"`
+	AcceptedDate       *string `json:"acceptedDate,omitempty"`
+	DepositWindowStart *string `json:"depositWindowStart,omitempty"`
+	DepositWindowEnd   *string `json:"depositWindowEnd,omitempty"`
+	Message            string  `json:"message"`
+}
+
+// PortalStatus builds the client-facing view of a refund
+func (t *RefundTracking) PortalStatus() PortalRefundStatus {
+	return PortalRefundStatus{
+		Jurisdiction:       t.Jurisdiction,
+		Status:             t.Status,
+		ExpectedAmount:     t.ExpectedAmount,
+		AcceptedDate:       t.AcceptedDate,
+		DepositWindowStart: t.DepositWindowStart,
+		DepositWindowEnd:   t.DepositWindowEnd,
+		Message:            t.Message(),
+	}
+}
+
+// Message explains the refund's status in plain language for the portal
+func (t *RefundTracking) Message() string {
+	agency := "The IRS"
+	if t.Jurisdiction != RefundJurisdictionFederal {
+		agency = fmt.Sprintf("The %s tax agency", t.Jurisdiction)
+	}
+	window := ""
+	if t.DepositWindowStart != nil && t.DepositWindowEnd != nil {
+		window = fmt.Sprintf(" Most refunds like yours arrive between %s and %s.",
+			formatPortalDate(*t.DepositWindowStart), formatPortalDate(*t.DepositWindowEnd))
+	}
+
+	switch t.Status {
+	case RefundStatusAwaitingAcceptance:
+		return fmt.Sprintf("Your return has been submitted. %s usually confirms acceptance within 48 hours.", agency)
+	case RefundStatusAccepted:
+		return fmt.Sprintf("%s accepted your return and is processing your refund.%s", agency, window)
+	case RefundStatusApproved:
+		return fmt.Sprintf("%s approved your refund and is preparing to send it.%s", agency, window)
+	case RefundStatusSent:
+		return "Your refund has been sent. Direct deposits can take up to 5 business days to appear; mailed checks take longer."
+	case RefundStatusDeposited:
+		return "Your refund has been deposited."
+	case RefundStatusDelayed:
+		return fmt.Sprintf("%s is taking longer than usual to process your refund. This is common and does not require action unless they contact you; we will update you as soon as we hear more.", agency)
+	case RefundStatusOffset:
+		return fmt.Sprintf("%s applied some or all of your refund to an outstanding debt. You should receive a notice by mail explaining the adjustment.", agency)
+	case RefundStatusRejected:
+		return "Your return was rejected and needs a correction before it can be resubmitted. Your preparer will contact you."
+	}
+	return ""
+}
+
+// formatPortalDate renders a YYYY-MM-DD date for display, falling back to the raw value
+func formatPortalDate(date string) string {
+	t, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return date
+	}
+	return t.Format("January 2")
+}
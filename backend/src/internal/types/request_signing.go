@@ -0,0 +1,32 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SigningKey is a tenant's HMAC secret for signing public requests from official snippets
+type SigningKey struct {
+	ID         uuid.UUID  `json:"id"`
+	TenantID   string     `json:"tenantId"`
+	KeyID      string     `json:"keyId"`
+	Secret     string     `json:"secret,omitempty"` // Only returned once, when the key is created
+	Label      string     `json:"label"`
+	CreatedBy  *uuid.UUID `json:"createdBy,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	RevokedAt  *time.Time `json:"revokedAt,omitempty"`
+}
+
+// Signed request headers
+const (
+	SignatureHeaderKeyID     = "X-WTP-Key-Id"
+	SignatureHeaderTimestamp = "X-WTP-Timestamp" // Unix seconds
+	SignatureHeaderNonce     = "X-WTP-Nonce"
+	SignatureHeaderSignature = "X-WTP-Signature" // Hex HMAC-SHA256
+)
+
+// SignatureWindow is how far a signed request's timestamp may drift from server time;
+// nonces are remembered until their timestamp leaves the window
+const SignatureWindow = 5 * time.Minute
@@ -0,0 +1,56 @@
+package types
+
+import "time"
+
+// Column kinds an adapter expects; each accepts the database types its scans and writes work with
+const (
+	ColumnKindUUID      = "uuid"      // uuid, or text holding UUIDs
+	ColumnKindText      = "text"      // Scanned into a string; any scalar type works
+	ColumnKindInteger   = "integer"   // smallint, integer, bigint
+	ColumnKindNumeric   = "numeric"   // Any integer, numeric or floating point type
+	ColumnKindBoolean   = "boolean"   // boolean
+	ColumnKindTimestamp = "timestamp" // timestamp (with or without time zone) or date
+	ColumnKindArray     = "array"     // Postgres array
+)
+
+// SchemaColumn is a column an adapter reads or writes
+type SchemaColumn struct {
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+}
+
+// SchemaTable is a tenant table an adapter depends on
+type SchemaTable struct {
+	Name    string         `json:"name"`
+	Columns []SchemaColumn `json:"columns"`
+}
+
+// Schema issue type constants
+const (
+	SchemaMissingSchema  = "MISSING_SCHEMA"
+	SchemaMissingTable   = "MISSING_TABLE"
+	SchemaMissingColumn  = "MISSING_COLUMN"
+	SchemaTypeMismatch   = "TYPE_MISMATCH"
+	SchemaUnknownAdapter = "UNKNOWN_ADAPTER" // adapter_type has no adapter; the tenant is read with the MyWellTax adapter
+)
+
+// SchemaIssue is one difference between a tenant schema and what its adapter expects
+type SchemaIssue struct {
+	Type     string `json:"type"`
+	Table    string `json:"table,omitempty"`
+	Column   string `json:"column,omitempty"`
+	Expected string `json:"expected,omitempty"` // Expected column kind
+	Actual   string `json:"actual,omitempty"`   // Database type found
+	Fix      string `json:"fix"`                // Suggested remediation, usually DDL to review before running
+}
+
+// SchemaCheck is the result of validating a tenant schema against its adapter
+type SchemaCheck struct {
+	TenantID     string         `json:"tenantId"`
+	AdapterType  string         `json:"adapterType"`
+	SchemaPrefix string         `json:"schemaPrefix"`
+	Healthy      bool           `json:"healthy"`
+	Issues       []*SchemaIssue `json:"issues"`
+	Error        *string        `json:"error,omitempty"` // Set when the check itself could not run
+	CheckedAt    time.Time      `json:"checkedAt"`
+}
@@ -0,0 +1,63 @@
+package types
+
+// ServiceIdentity is an internal, non-human caller of the store such as a background job.
+// Store operations outside its scopes fail with a permission error.
+type ServiceIdentity struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+}
+
+// HasScope reports whether the identity was granted scope
+func (id *ServiceIdentity) HasScope(scope string) bool {
+	for _, s := range id.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Service scope constants
+const (
+	ScopeTenantConfigRead = "tenant_config:read"       // Read tenant connection settings (database password withheld)
+	ScopeTenantDBConnect  = "tenant_db:connect"        // Open tenant database connections
+	ScopeSSNDecrypt       = "ssn:decrypt"              // Decrypt taxpayer and spouse SSNs
+	ScopeSecretDecrypt    = "secret:decrypt"           // Decrypt request signing, DocuSign Connect and Stripe webhook secrets
+	ScopeJobsWrite        = "jobs:write"               // Record background job runs, lock usage, tenant database probes and Firebase orphan reports
+	ScopeDocumentsIngest  = "documents:ingest"         // Record files imported from partner document drops
+	ScopeClientExport     = "clients:export"           // Export and import anonymized client data for support
+	ScopeDocumentRequests = "document_requests:write"  // Raise document requests and record client reminders
+	ScopeAuditAnchor      = "audit:anchor"             // Record audit chain heads written to WORM storage
+	ScopeOffboarding      = "tenant_offboarding:write" // Record offboarding data exports and retention alerts
+	ScopeAffiliateEmails  = "affiliate_emails:send"    // List and record affiliate notification emails
+	ScopeWebhooksDeliver  = "webhooks:deliver"         // Read due webhook deliveries with their secrets and record attempts
+	ScopeDebugCaptureRead = "debug_capture:read"       // Decrypt captured debug request and response bodies
+	ScopeBulkOperations   = "bulk_operations:run"      // Run queued bulk operations and record their item results
+	ScopeSSNRekey         = "ssn:rekey"                // Re-encrypt tenant SSNs under a rotated data key
+	ScopeDocumentScans    = "document_scans:write"     // Record malware scans of uploaded and imported documents
+	ScopeDocumentArchive  = "document_archive:write"   // Record documents moved to and from the archive storage class
+	ScopeEmailsSend       = "emails:send"              // Read due outbox emails and record send attempts
+	ScopeSMSSend          = "sms:send"                 // Record text messages sent to clients
+	ScopeStorageChecks    = "storage_checks:write"     // Record storage consistency checks and the issues they find
+)
+
+// Built-in service identities
+var (
+	// ServiceWorker runs the scheduled background jobs (see worker.Jobs)
+	ServiceWorker = &ServiceIdentity{
+		Name:   "worker",
+		Scopes: []string{ScopeTenantConfigRead, ScopeTenantDBConnect, ScopeJobsWrite, ScopeDocumentsIngest, ScopeDocumentRequests, ScopeAuditAnchor, ScopeOffboarding, ScopeAffiliateEmails, ScopeWebhooksDeliver, ScopeBulkOperations, ScopeSSNRekey, ScopeDocumentScans, ScopeDocumentArchive, ScopeEmailsSend, ScopeSMSSend, ScopeStorageChecks},
+	}
+
+	// ServiceNotifier delivers staff alerts and the daily digest
+	ServiceNotifier = &ServiceIdentity{
+		Name:   "notifier",
+		Scopes: []string{ScopeJobsWrite},
+	}
+
+	// ServiceSupport runs the clientexport command that copies one client, anonymized, to a local tenant
+	ServiceSupport = &ServiceIdentity{
+		Name:   "support",
+		Scopes: []string{ScopeTenantConfigRead, ScopeTenantDBConnect, ScopeSSNDecrypt, ScopeClientExport},
+	}
+)
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SignatureRequest is a DocuSign envelope sent to a taxpayer for signature, with the latest
+// status DocuSign Connect reported for it
+type SignatureRequest struct {
+	ID              uuid.UUID  `json:"id"`
+	TenantID        string     `json:"tenantId"`
+	EnvelopeID      string     `json:"envelopeId"`
+	FilingID        *uuid.UUID `json:"filingId,omitempty"`
+	TaxpayerName    string     `json:"taxpayerName"`
+	TaxpayerEmail   string     `json:"taxpayerEmail"`
+	SpouseSignature bool       `json:"spouseSignature"`
+	Status          string     `json:"status"`
+	SentBy          *uuid.UUID `json:"sentBy,omitempty"`
+	SentAt          time.Time  `json:"sentAt"`
+	DeliveredAt     *time.Time `json:"deliveredAt,omitempty"` // First viewed by a recipient
+	CompletedAt     *time.Time `json:"completedAt,omitempty"`
+	DeclinedAt      *time.Time `json:"declinedAt,omitempty"`
+	VoidedAt        *time.Time `json:"voidedAt,omitempty"`
+	DocumentID      *uuid.UUID `json:"documentId,omitempty"` // Archived signed PDF in the tenant database
+	ArchivedAt      *time.Time `json:"archivedAt,omitempty"`
+	ArchiveError    *string    `json:"archiveError,omitempty"` // Why the last archive attempt failed
+	UpdatedAt       time.Time  `json:"updatedAt"`
+}
+
+// Signature request statuses
+const (
+	SignatureStatusSent      = "SENT"
+	SignatureStatusDelivered = "DELIVERED"
+	SignatureStatusCompleted = "COMPLETED"
+	SignatureStatusDeclined  = "DECLINED"
+	SignatureStatusVoided    = "VOIDED"
+)
+
+// DocumentTypeSignedForm8879 is the document type of signed PDFs archived from DocuSign
+const DocumentTypeSignedForm8879 = "signed_form_8879"
+
+// SignatureStatusesUnsigned are the statuses of envelopes still waiting on a signer
+var SignatureStatusesUnsigned = []string{SignatureStatusSent, SignatureStatusDelivered}
+
+// signatureStatusOrder ranks statuses so late or repeated Connect events cannot move a request backwards
+var signatureStatusOrder = map[string]int{
+	SignatureStatusSent:      0,
+	SignatureStatusDelivered: 1,
+	SignatureStatusCompleted: 2,
+	SignatureStatusDeclined:  2,
+	SignatureStatusVoided:    2,
+}
+
+// IsValidSignatureStatus reports whether status is a known signature request status
+func IsValidSignatureStatus(status string) bool {
+	_, ok := signatureStatusOrder[status]
+	return ok
+}
+
+// SignatureStatusAdvances reports whether a request in status from may move to status to
+func SignatureStatusAdvances(from, to string) bool {
+	rankFrom, okFrom := signatureStatusOrder[from]
+	rankTo, okTo := signatureStatusOrder[to]
+	return okFrom && okTo && rankTo > rankFrom
+}
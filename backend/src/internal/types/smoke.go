@@ -0,0 +1,58 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Built-in smoke tenant used for post-deploy verification. It is served by an in-memory
+// adapter and storage provider, so smoke tests never touch a tenant database or bucket.
+const (
+	SmokeTenantID        = "smoke"
+	SmokeAdapterType     = "smoke"
+	SmokeStorageProvider = "memory"
+	SmokeStorageBucket   = "smoke"
+	// SmokeEmailDomain receives captured email; messages to it are never handed to SendGrid
+	SmokeEmailDomain = "smoke.welltaxpro.invalid"
+)
+
+// Records seeded in the smoke tenant
+var (
+	SmokeClientID = uuid.MustParse("5a0e0000-0000-4000-8000-000000000001")
+	SmokeFilingID = uuid.MustParse("5a0e0000-0000-4000-8000-000000000002")
+)
+
+// SmokeFilingYear is the tax year of the seeded filing
+const SmokeFilingYear = 2024
+
+// SmokeTenantConnection returns the connection settings of the smoke tenant.
+// They are built in rather than read from tenant_connections, whose smoke row stays inactive
+// so cross-tenant jobs and reports skip it.
+func SmokeTenantConnection() *TenantConnection {
+	return &TenantConnection{
+		TenantID:        SmokeTenantID,
+		TenantName:      "Smoke Test",
+		SchemaPrefix:    SmokeTenantID,
+		AdapterType:     SmokeAdapterType,
+		StorageProvider: SmokeStorageProvider,
+		StorageBucket:   SmokeStorageBucket,
+		IsActive:        true,
+	}
+}
+
+// SmokeTestResult is the outcome of one smoke test run
+type SmokeTestResult struct {
+	Passed     bool         `json:"passed"`
+	StartedAt  time.Time    `json:"startedAt"`
+	DurationMs int64        `json:"durationMs"`
+	Steps      []*SmokeStep `json:"steps"`
+}
+
+// SmokeStep is one checked stage of the request path
+type SmokeStep struct {
+	Name       string `json:"name"`
+	Passed     bool   `json:"passed"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"durationMs"`
+}
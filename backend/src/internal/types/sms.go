@@ -0,0 +1,65 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Text message kinds
+const (
+	SMSKindPortalLink = "PORTAL_LINK" // Portal sign-in link from a SEND_PORTAL_LINK bulk operation
+)
+
+// Text message statuses
+const (
+	SMSStatusPending     = "PENDING"     // Recorded and being handed to the provider
+	SMSStatusSent        = "SENT"        // Accepted by the provider
+	SMSStatusDelivered   = "DELIVERED"   // The carrier confirmed delivery
+	SMSStatusUndelivered = "UNDELIVERED" // The carrier could not deliver it
+	SMSStatusFailed      = "FAILED"      // The provider rejected it or could not be reached
+)
+
+// SMSSettings configures text messages to a tenant's clients.
+// Stored per tenant in tenant_connections.sms_settings (JSONB); tenants without settings fall
+// back to DefaultSMSSettings, which texts nothing.
+type SMSSettings struct {
+	PortalLinks        bool `json:"portalLinks"`        // Text SEND_PORTAL_LINK sign-in links to clients' phones as well as emailing them
+	MaxPerClientPerDay int  `json:"maxPerClientPerDay"` // Texts a client may be sent in any 24 hours
+}
+
+// DefaultSMSSettings returns the settings applied when a tenant has none
+func DefaultSMSSettings() *SMSSettings {
+	return &SMSSettings{PortalLinks: false, MaxPerClientPerDay: 3}
+}
+
+// Validate checks SMS settings
+func (s *SMSSettings) Validate() string {
+	if s.MaxPerClientPerDay < 1 || s.MaxPerClientPerDay > 20 {
+		return "maxPerClientPerDay must be between 1 and 20"
+	}
+	return ""
+}
+
+// SMSMessage is a text message sent (or being sent) to a client. The body is not kept, since
+// it can hold a sign-in link.
+type SMSMessage struct {
+	ID         uuid.UUID `json:"id"`
+	TenantID   string    `json:"tenantId"`
+	ClientID   uuid.UUID `json:"clientId"`
+	Kind       string    `json:"kind"`
+	ToPhone    string    `json:"toPhone"` // E.164
+	Provider   string    `json:"provider"`
+	ProviderID *string   `json:"providerId,omitempty"`
+	Status     string    `json:"status"`
+	Error      *string   `json:"error,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// SMSMessageFilter narrows the listing of a tenant's text messages; nil fields match every message
+type SMSMessageFilter struct {
+	ClientID *uuid.UUID
+	Status   *string
+	Limit    int
+}
@@ -0,0 +1,61 @@
+package types
+
+import "github.com/google/uuid"
+
+// StateFiling tracks a state return attached to a federal filing year
+// Field Mapping (MyWellTax adapter):
+//
+//	taxes.state_filing.* → StateFiling fields
+type StateFiling struct {
+	ID            uuid.UUID `json:"id"`
+	FilingID      uuid.UUID `json:"filingId"`
+	State         string    `json:"state"`         // Two-letter state code
+	ResidencyType string    `json:"residencyType"` // RESIDENT, PART_YEAR, NONRESIDENT
+	Status        string    `json:"status"`        // NOT_STARTED, IN_PROGRESS, FILED, ACCEPTED, REJECTED
+	Fee           float64   `json:"fee"`
+	CreatedAt     string    `json:"createdAt"`
+	UpdatedAt     *string   `json:"updatedAt,omitempty"`
+}
+
+// StateFilingReport aggregates state return volume for a tenant
+type StateFilingReport struct {
+	State     string         `json:"state"`
+	Total     int            `json:"total"`
+	ByStatus  map[string]int `json:"byStatus"`
+	TotalFees float64        `json:"totalFees"`
+}
+
+// State residency type constants
+const (
+	ResidencyResident    = "RESIDENT"
+	ResidencyPartYear    = "PART_YEAR"
+	ResidencyNonresident = "NONRESIDENT"
+)
+
+// State filing status constants
+const (
+	StateFilingStatusNotStarted = "NOT_STARTED"
+	StateFilingStatusInProgress = "IN_PROGRESS"
+	StateFilingStatusFiled      = "FILED"
+	StateFilingStatusAccepted   = "ACCEPTED"
+	StateFilingStatusRejected   = "REJECTED"
+)
+
+// IsValidResidencyType checks a residency type value
+func IsValidResidencyType(r string) bool {
+	switch r {
+	case ResidencyResident, ResidencyPartYear, ResidencyNonresident:
+		return true
+	}
+	return false
+}
+
+// IsValidStateFilingStatus checks a state filing status value
+func IsValidStateFilingStatus(s string) bool {
+	switch s {
+	case StateFilingStatusNotStarted, StateFilingStatusInProgress, StateFilingStatusFiled,
+		StateFilingStatusAccepted, StateFilingStatusRejected:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,73 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Storage issue types
+const (
+	StorageIssueMissingFile  = "MISSING_FILE"  // A document record whose file is not in the bucket
+	StorageIssueOrphanedFile = "ORPHANED_FILE" // A file under a client's document path that no document record points at
+)
+
+// Storage issue statuses
+const (
+	StorageIssueOpen     = "OPEN"
+	StorageIssueResolved = "RESOLVED" // Remediated by an admin
+	StorageIssueCleared  = "CLEARED"  // No longer found by a later check
+)
+
+// Storage issue remedies
+const (
+	StorageRemedyRelink    = "RELINK"    // Point the document at another file of the same client
+	StorageRemedyDelete    = "DELETE"    // Delete the document record, or the orphaned file
+	StorageRemedyRerequest = "REREQUEST" // Ask the client to upload the document again, then delete its record
+)
+
+// StorageIssue is a difference between a tenant's document records and the files in its bucket
+type StorageIssue struct {
+	ID                uuid.UUID  `json:"id"`
+	TenantID          string     `json:"tenantId"`
+	Type              string     `json:"type"`                 // StorageIssue*
+	Path              string     `json:"path"`                 // The missing file, or the orphaned file
+	DocumentID        *uuid.UUID `json:"documentId,omitempty"` // Set on missing files
+	ClientID          *uuid.UUID `json:"clientId,omitempty"`
+	FilingID          *uuid.UUID `json:"filingId,omitempty"`
+	DocumentName      string     `json:"documentName,omitempty"`
+	DocumentType      string     `json:"documentType,omitempty"`
+	Candidates        []string   `json:"candidates"` // Orphaned files of the same client and document type a missing file could be relinked to
+	Status            string     `json:"status"`     // StorageIssue{Open,Resolved,Cleared}
+	FirstSeenAt       time.Time  `json:"firstSeenAt"`
+	LastSeenAt        time.Time  `json:"lastSeenAt"`
+	Resolution        *string    `json:"resolution,omitempty"` // StorageRemedy*
+	ResolvedBy        *uuid.UUID `json:"resolvedBy,omitempty"`
+	ResolvedAt        *time.Time `json:"resolvedAt,omitempty"`
+	DocumentRequestID *uuid.UUID `json:"documentRequestId,omitempty"` // Raised by REREQUEST
+}
+
+// StorageConsistencyCheck is the summary of the latest consistency check of a tenant's bucket
+type StorageConsistencyCheck struct {
+	TenantID      string    `json:"tenantId"`
+	Documents     int       `json:"documents"` // Document records checked
+	Objects       int       `json:"objects"`   // Files in the bucket
+	MissingFiles  int       `json:"missingFiles"`
+	OrphanedFiles int       `json:"orphanedFiles"`
+	Error         *string   `json:"error,omitempty"` // Set when the check could not run
+	CheckedAt     time.Time `json:"checkedAt"`
+}
+
+// StorageConsistencyReport is a tenant's latest check with its open issues
+type StorageConsistencyReport struct {
+	Check  *StorageConsistencyCheck `json:"check"`
+	Issues []*StorageIssue          `json:"issues"`
+}
+
+// StorageRemediation resolves a storage issue
+type StorageRemediation struct {
+	Action      string     `json:"action"`                // StorageRemedy*
+	Path        string     `json:"path,omitempty"`        // RELINK of a missing file: the file to point the document at
+	DocumentID  *uuid.UUID `json:"documentId,omitempty"`  // RELINK of an orphaned file: the document to point at it
+	Description string     `json:"description,omitempty"` // REREQUEST: what the client is asked for; defaults to the document's name
+}
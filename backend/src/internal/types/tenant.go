@@ -2,6 +2,10 @@ package types
 
 import (
 	"fmt"
+	"path"
+	"regexp"
+	"strings"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -17,16 +21,32 @@ type TenantConnection struct {
 	DBPassword   string    `json:"-"` // Never expose in JSON
 	DBName       string    `json:"dbName"`
 	DBSslMode    string    `json:"dbSslMode"`
+	DBAuthType               string  `json:"dbAuthType"` // password, iam or connector
+	DBInstanceConnectionName string  `json:"dbInstanceConnectionName,omitempty"` // Cloud SQL PROJECT:REGION:INSTANCE for the connector auth type
 	SchemaPrefix             string  `json:"schemaPrefix"`
 	AdapterType              string  `json:"adapterType"` // Adapter to use (mywelltax, drake, lacerte, etc.)
-	StorageProvider          string  `json:"storageProvider"` // Storage provider (gcs, s3, azure)
+	IDVersion                string  `json:"idVersion"` // UUID version of new tenant records (v7 or v4)
+	StorageProvider          string  `json:"storageProvider"` // Storage provider (gcs or s3)
 	StorageBucket            string  `json:"storageBucket"` // Bucket/container name for document storage
+	StorageRegion            string  `json:"storageRegion,omitempty"` // Region or multi-region the bucket must be in; uploads elsewhere are refused
+	DBRegion                 string  `json:"dbRegion,omitempty"` // Region the tenant database must be in
+	DataResidency            string  `json:"dataResidency,omitempty"` // Residency requirement for compliance documentation, such as EU
 	StorageCredentialsSecret string  `json:"-"` // GCP Secret Manager path (e.g., "projects/PROJECT/secrets/NAME/versions/VERSION")
 	StorageCredentialsPath   string  `json:"-"` // Fallback: Path to service account JSON file (never exposed in JSON)
+	StorageUploadCredentialsSecret string `json:"-"` // Optional Secret Manager path of upload-only credentials
+	StorageUploadCredentialsPath   string `json:"-"` // Optional path of upload-only credentials (local dev)
+	StorageReadCredentialsSecret   string `json:"-"` // Optional Secret Manager path of read-only credentials
+	StorageReadCredentialsPath     string `json:"-"` // Optional path of read-only credentials (local dev)
+	StorageDeleteCredentialsSecret string `json:"-"` // Optional Secret Manager path of delete-only credentials
+	StorageDeleteCredentialsPath   string `json:"-"` // Optional path of delete-only credentials (local dev)
 	DocuSignIntegrationKey   string  `json:"docusignIntegrationKey"` // DocuSign Integration Key
 	DocuSignClientID         string  `json:"docusignClientId"` // DocuSign Client ID / User ID for JWT auth
 	DocuSignPrivateKeySecret string  `json:"-"` // GCP Secret Manager path to DocuSign RSA private key (never exposed in JSON)
 	DocuSignAPIURL           string  `json:"docusignApiUrl"` // DocuSign API base URL (demo or production)
+	FraudRules               *FraudRules `json:"fraudRules,omitempty"` // Commission fraud rules (nil means defaults)
+	CommissionSLA            *CommissionSLA `json:"commissionSla,omitempty"` // Commission approval SLA (nil means defaults)
+	PortalSecurityPolicy     *PortalSecurityPolicy `json:"portalSecurityPolicy,omitempty"` // Portal session and verification policy (nil means defaults)
+	StatementTimeoutMs       *int    `json:"statementTimeoutMs,omitempty"` // How long a call to the tenant database may run before it is cancelled (nil means DefaultStatementTimeout)
 	IsActive                 bool    `json:"isActive"`
 	CreatedAt              string  `json:"createdAt"`
 	UpdatedAt              string  `json:"updatedAt"`
@@ -34,8 +54,151 @@ type TenantConnection struct {
 	Notes                  *string `json:"notes"`
 }
 
-// GetConnectionString returns a PostgreSQL connection string for this tenant
+// TenantDetail is a tenant connection with its database probe history
+type TenantDetail struct {
+	*TenantConnection
+	ConnectionUptime *TenantUptime `json:"connectionUptime,omitempty"`
+}
+
+// Tenant database auth types
+const (
+	DBAuthPassword  = "password"  // db_password over TCP
+	DBAuthIAM       = "iam"       // Cloud SQL IAM token over TCP
+	DBAuthConnector = "connector" // Cloud SQL IAM token through the instance socket
+)
+
+// UUID versions of the records adapters create in a tenant database
+const (
+	IDVersionV7 = "v7" // Time-ordered, so new rows stay together in primary key indexes (default)
+	IDVersionV4 = "v4" // Random, for tenant applications that expect it
+)
+
+// Tenant statement timeouts
+const (
+	DefaultStatementTimeout = 30 * time.Second
+	MinStatementTimeoutMs   = 1000
+	MaxStatementTimeoutMs   = 600000
+)
+
+// StatementTimeout returns how long a call to the tenant database may run before it is cancelled
+func (tc *TenantConnection) StatementTimeout() time.Duration {
+	if tc.StatementTimeoutMs == nil || *tc.StatementTimeoutMs <= 0 {
+		return DefaultStatementTimeout
+	}
+	return time.Duration(*tc.StatementTimeoutMs) * time.Millisecond
+}
+
+// IsValidStatementTimeoutMs checks a tenant statement timeout
+func IsValidStatementTimeoutMs(ms int) bool {
+	return ms >= MinStatementTimeoutMs && ms <= MaxStatementTimeoutMs
+}
+
+// IsValidIDVersion checks a tenant ID version
+func IsValidIDVersion(version string) bool {
+	return version == IDVersionV7 || version == IDVersionV4
+}
+
+// CloudSQLSocketDir is where Cloud Run and the Cloud SQL Auth Proxy (--unix-socket) expose
+// instance sockets
+const CloudSQLSocketDir = "/cloudsql"
+
+// IsValidDBAuthType checks a tenant database auth type
+func IsValidDBAuthType(authType string) bool {
+	switch authType {
+	case DBAuthPassword, DBAuthIAM, DBAuthConnector:
+		return true
+	}
+	return false
+}
+
+// UsesIAMAuth reports whether the tenant database takes a Cloud SQL IAM token instead of a password
+func (tc *TenantConnection) UsesIAMAuth() bool {
+	return tc.DBAuthType == DBAuthIAM || tc.DBAuthType == DBAuthConnector
+}
+
+// GetConnectionString returns a PostgreSQL connection string for this tenant. IAM auth types
+// leave out the password; a fresh token is added each time a connection is dialed.
 func (tc *TenantConnection) GetConnectionString() string {
-	return fmt.Sprintf("host=%s port=%d user=%s password=%s dbname=%s sslmode=%s binary_parameters=yes",
-		tc.DBHost, tc.DBPort, tc.DBUser, tc.DBPassword, tc.DBName, tc.DBSslMode)
+	host, port, sslMode := tc.DBHost, tc.DBPort, tc.DBSslMode
+	if tc.DBAuthType == DBAuthConnector {
+		// The connector encrypts the socket's traffic itself
+		host, port, sslMode = path.Join(CloudSQLSocketDir, tc.DBInstanceConnectionName), 5432, "disable"
+	}
+
+	params := []string{
+		"host=" + dsnValue(host),
+		fmt.Sprintf("port=%d", port),
+		"user=" + dsnValue(tc.DBUser),
+	}
+	if !tc.UsesIAMAuth() {
+		params = append(params, "password="+dsnValue(tc.DBPassword))
+	}
+	params = append(params, "dbname="+dsnValue(tc.DBName), "sslmode="+dsnValue(sslMode), "binary_parameters=yes")
+	return strings.Join(params, " ")
+}
+
+// dsnValue quotes a connection string value, so passwords may contain spaces and quotes
+func dsnValue(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// DBInstanceRegion returns the region in the Cloud SQL instance connection name, or "" when
+// there is none
+func (tc *TenantConnection) DBInstanceRegion() string {
+	parts := strings.Split(tc.DBInstanceConnectionName, ":")
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[1]
+}
+
+// regionPattern matches region names of GCS, AWS and Cloud SQL, such as us-east1, EU or eu-west-1
+var regionPattern = regexp.MustCompile(`^[A-Za-z0-9]+(-[A-Za-z0-9]+)*$`)
+
+// IsValidRegion checks a storage or database region name
+func IsValidRegion(region string) bool {
+	return len(region) <= 50 && regionPattern.MatchString(region)
+}
+
+// SameRegion reports whether two region names are the same; providers differ in case, so GCS
+// reports US-EAST1 for us-east1
+func SameRegion(a, b string) bool {
+	return strings.EqualFold(a, b)
+}
+
+// Tenant storage providers (the smoke tenant uses SmokeStorageProvider)
+const (
+	StorageProviderGCS = "gcs" // Google Cloud Storage, the default
+	StorageProviderS3  = "s3"  // Amazon S3 or an S3-compatible service
+)
+
+// Storage purposes, each of which may use its own credentials
+const (
+	StoragePurposeUpload = "upload" // Upload
+	StoragePurposeRead   = "read"   // Download, List and GetSignedURL
+	StoragePurposeDelete = "delete" // Delete
+)
+
+// StorageCredentials returns the Secret Manager path and file path of the credentials for a
+// storage purpose, falling back to the shared storage credentials when the purpose has none
+func (tc *TenantConnection) StorageCredentials(purpose string) (secret, path string) {
+	switch purpose {
+	case StoragePurposeUpload:
+		secret, path = tc.StorageUploadCredentialsSecret, tc.StorageUploadCredentialsPath
+	case StoragePurposeRead:
+		secret, path = tc.StorageReadCredentialsSecret, tc.StorageReadCredentialsPath
+	case StoragePurposeDelete:
+		secret, path = tc.StorageDeleteCredentialsSecret, tc.StorageDeleteCredentialsPath
+	}
+	if secret == "" && path == "" {
+		return tc.StorageCredentialsSecret, tc.StorageCredentialsPath
+	}
+	return secret, path
+}
+
+// HasPurposeStorageCredentials reports whether any storage purpose has its own credentials
+func (tc *TenantConnection) HasPurposeStorageCredentials() bool {
+	return tc.StorageUploadCredentialsSecret != "" || tc.StorageUploadCredentialsPath != "" ||
+		tc.StorageReadCredentialsSecret != "" || tc.StorageReadCredentialsPath != "" ||
+		tc.StorageDeleteCredentialsSecret != "" || tc.StorageDeleteCredentialsPath != ""
 }
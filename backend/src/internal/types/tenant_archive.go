@@ -0,0 +1,31 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArchivedYear is a tax year a tenant has closed. Its filings and their documents are read-only
+// until the year is unarchived, and its documents move to a cheaper storage class.
+type ArchivedYear struct {
+	TenantID   string     `json:"tenantId"`
+	Year       int        `json:"year"`
+	ArchivedAt time.Time  `json:"archivedAt"`
+	ArchivedBy *uuid.UUID `json:"archivedBy,omitempty"` // Employee who archived the year
+	Documents  int        `json:"documents"`            // Documents moved to the archive storage class so far
+}
+
+// ArchivedDocument is a document whose file was moved to the archive storage class
+type ArchivedDocument struct {
+	TenantID   string    `json:"tenantId"`
+	DocumentID uuid.UUID `json:"documentId"`
+	Year       int       `json:"year"`
+	FilePath   string    `json:"filePath"`
+	ArchivedAt time.Time `json:"archivedAt"`
+}
+
+// ArchiveYearRequest archives or unarchives a tax year
+type ArchiveYearRequest struct {
+	Year int `json:"year"`
+}
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TenantContext is a tenant an employee selected for their current sign-in. Its token is sent
+// in TenantContextHeader alongside the ID token; tenant-scoped routes then run with the
+// context's role, and routes under TenantContextPathPrefix run against the context's tenant.
+type TenantContext struct {
+	ID         uuid.UUID `json:"id"`
+	EmployeeID uuid.UUID `json:"employeeId"`
+	TenantID   string    `json:"tenantId"`
+	Role       string    `json:"role"`
+	CreatedAt  time.Time `json:"createdAt"`
+	ExpiresAt  time.Time `json:"expiresAt"`
+	AuthTime   int64     `json:"-"` // auth_time claim of the sign-in it was minted from
+}
+
+const (
+	// TenantContextHeader carries a tenant context token
+	TenantContextHeader = "X-WTP-Tenant-Context"
+	// TenantContextPathPrefix stands in for /api/v1/{tenantId}/ on requests with a tenant context
+	TenantContextPathPrefix = "/api/v1/current/"
+	// TenantContextLifetime is how long a tenant context token is accepted
+	TenantContextLifetime = 30 * time.Minute
+)
+
+// TenantContextRole returns the role an employee holds in a tenant context. A tenant access role
+// only applies when it narrows the employee's role; it never grants capabilities or admin rights
+// the employee does not already have.
+func TenantContextRole(employeeRole, tenantRole string) string {
+	if tenantRole == "" || tenantRole == employeeRole || tenantRole == RoleAdmin {
+		return employeeRole
+	}
+	for _, capability := range RoleCapabilities(tenantRole) {
+		if !RoleHasCapability(employeeRole, capability) {
+			return employeeRole
+		}
+	}
+	return tenantRole
+}
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TenantDataKey is a tenant's SSN data encryption key. The key itself is stored wrapped
+// (encrypted) by the master key and is never serialized.
+type TenantDataKey struct {
+	ID         uuid.UUID  `json:"id"`
+	TenantID   string     `json:"tenantId"`
+	WrappedKey []byte     `json:"-"`
+	Status     string     `json:"status"` // DataKey*
+	CreatedAt  time.Time  `json:"createdAt"`
+	RetiredAt  *time.Time `json:"retiredAt,omitempty"`
+}
+
+// Data key statuses. Retired keys still decrypt the SSNs written under them until a rekey
+// re-encrypts those under the active key.
+const (
+	DataKeyActive  = "ACTIVE"
+	DataKeyRetired = "RETIRED"
+)
+
+// SSNRekey is an admin-triggered run that re-encrypts every SSN of a tenant under its active data key
+type SSNRekey struct {
+	ID          uuid.UUID  `json:"id"`
+	TenantID    string     `json:"tenantId"`
+	KeyID       uuid.UUID  `json:"keyId"`  // The data key SSNs are re-encrypted under
+	Status      string     `json:"status"` // SSNRekey*
+	Reencrypted int        `json:"reencrypted"`
+	Error       *string    `json:"error,omitempty"`
+	RequestedBy uuid.UUID  `json:"requestedBy"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	StartedAt   *time.Time `json:"startedAt,omitempty"`
+	FinishedAt  *time.Time `json:"finishedAt,omitempty"`
+}
+
+// SSN rekey statuses
+const (
+	SSNRekeyQueued    = "QUEUED"
+	SSNRekeyRunning   = "RUNNING"
+	SSNRekeyCompleted = "COMPLETED"
+	SSNRekeyFailed    = "FAILED"
+)
@@ -0,0 +1,45 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TenantDeletionWindow is how long a tenant deletion confirmation token can be used
+const TenantDeletionWindow = 15 * time.Minute
+
+// TenantDeletionRequest is the first step of deactivating a tenant. It summarizes what the
+// deactivation affects and holds the token that confirms it; only the admin who requested it
+// can confirm it, once, within TenantDeletionWindow.
+type TenantDeletionRequest struct {
+	ID          uuid.UUID            `json:"id"`
+	TenantID    string               `json:"tenantId"`
+	Token       string               `json:"token,omitempty"` // Only returned when requested; stored as a hash
+	Impact      TenantDeletionImpact `json:"impact"`
+	RequestedBy uuid.UUID            `json:"requestedBy"`
+	RequestedAt time.Time            `json:"requestedAt"`
+	ExpiresAt   time.Time            `json:"expiresAt"`
+	ConfirmedAt *time.Time           `json:"confirmedAt,omitempty"`
+}
+
+// TenantDeletionImpact is what deactivating a tenant affects when it is requested. Counts the
+// tenant database or bucket could not provide are left out, with the reason in their error.
+type TenantDeletionImpact struct {
+	ActiveEmployees      int    `json:"activeEmployees"`      // Employees with active access to the tenant
+	ActivePortalUsers    int    `json:"activePortalUsers"`    // Portal users who can sign in
+	ActivePortalSessions int    `json:"activePortalSessions"` // Portal sessions that will be ended
+	OpenFilings          *int   `json:"openFilings,omitempty"`
+	OpenFilingsError     string `json:"openFilingsError,omitempty"`
+	StorageObjects       *int   `json:"storageObjects,omitempty"`
+	StorageBytes         *int64 `json:"storageBytes,omitempty"`
+	StorageError         string `json:"storageError,omitempty"`
+}
+
+// TenantDeactivation is the result of a confirmed tenant deletion
+type TenantDeactivation struct {
+	TenantID            string    `json:"tenantId"`
+	DeactivatedAt       time.Time `json:"deactivatedAt"`
+	PortalSessionsEnded int       `json:"portalSessionsEnded"`
+	ConnectionEvicted   bool      `json:"connectionEvicted"` // This process's pooled connection was closed
+}
@@ -0,0 +1,39 @@
+package types
+
+import "time"
+
+// Tenant connection diagnostic checks, in the order they run
+const (
+	DiagnosticDatabase  = "database"  // Connect to and ping the tenant database
+	DiagnosticSchema    = "schema"    // The schema prefix exists and has tables
+	DiagnosticTables    = "tables"    // The tables and columns the adapter expects are present
+	DiagnosticStorage   = "storage"   // Write, read back and delete a test object in the storage bucket
+	DiagnosticResidency = "residency" // The bucket and database are in the tenant's designated regions
+	DiagnosticDocuSign  = "docusign"  // Get a DocuSign access token and account with the tenant's credentials
+)
+
+// Diagnostic check status constants
+const (
+	DiagnosticPassed  = "PASSED"
+	DiagnosticFailed  = "FAILED"
+	DiagnosticSkipped = "SKIPPED" // Not configured, or a check it depends on failed
+)
+
+// TenantDiagnosticCheck is the outcome of one tenant connection check
+type TenantDiagnosticCheck struct {
+	Name       string         `json:"name"`
+	Status     string         `json:"status"`
+	Detail     string         `json:"detail,omitempty"` // Why the check failed or was skipped
+	Issues     []*SchemaIssue `json:"issues,omitempty"` // Differences found by the tables check
+	DurationMs int64          `json:"durationMs"`
+}
+
+// TenantDiagnostics is the report of a tenant connection test
+type TenantDiagnostics struct {
+	TenantID    string                   `json:"tenantId"`
+	AdapterType string                   `json:"adapterType"`
+	Passed      bool                     `json:"passed"` // No check failed
+	Checks      []*TenantDiagnosticCheck `json:"checks"`
+	CheckedAt   time.Time                `json:"checkedAt"`
+	DurationMs  int64                    `json:"durationMs"`
+}
@@ -0,0 +1,35 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tenant configuration change actions
+const (
+	TenantConfigActionCreate     = "CREATE"
+	TenantConfigActionUpdate     = "UPDATE"
+	TenantConfigActionDeactivate = "DEACTIVATE"
+)
+
+// TenantConfigChange is one field of a tenant configuration change.
+// Fields hidden from TenantConnection JSON (credentials and secret paths) are redacted:
+// the change is recorded without its values.
+type TenantConfigChange struct {
+	Field    string  `json:"field"` // TenantConnection JSON field name
+	Before   *string `json:"before,omitempty"`
+	After    *string `json:"after,omitempty"`
+	Redacted bool    `json:"redacted,omitempty"`
+}
+
+// TenantConfigHistoryEntry records one change to a tenant's configuration
+type TenantConfigHistoryEntry struct {
+	ID             uuid.UUID            `json:"id"`
+	TenantID       string               `json:"tenantId"`
+	Action         string               `json:"action"`
+	Changes        []TenantConfigChange `json:"changes"`
+	ChangedBy      *uuid.UUID           `json:"changedBy,omitempty"`
+	ChangedByEmail *string              `json:"changedByEmail,omitempty"`
+	ChangedAt      time.Time            `json:"changedAt"`
+}
@@ -0,0 +1,119 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TenantOffboarding winds down a departing tenant. Admins drive it one step at a time, in the
+// order of OffboardingSteps; Status is the last step completed.
+type TenantOffboarding struct {
+	ID                uuid.UUID                `json:"id"`
+	TenantID          string                   `json:"tenantId"`
+	Status            string                   `json:"status"`
+	NextStep          *string                  `json:"nextStep,omitempty"` // nil once completed or cancelled
+	Reason            *string                  `json:"reason,omitempty"`
+	StartedBy         *uuid.UUID               `json:"startedBy,omitempty"`
+	StartedAt         time.Time                `json:"startedAt"`
+	ExportRequestedAt *time.Time               `json:"exportRequestedAt,omitempty"`
+	ExportCompletedAt *time.Time               `json:"exportCompletedAt,omitempty"`
+	ExportPath        *string                  `json:"exportPath,omitempty"`
+	ExportError       *string                  `json:"exportError,omitempty"`
+	RetentionUntil    *time.Time               `json:"retentionUntil,omitempty"`
+	UpdatedAt         time.Time                `json:"updatedAt"`
+	Steps             []*TenantOffboardingStep `json:"steps"`
+}
+
+// TenantOffboardingStep records who ran an offboarding step and what it changed
+type TenantOffboardingStep struct {
+	Step        string          `json:"step"`
+	PerformedBy *uuid.UUID      `json:"performedBy,omitempty"`
+	PerformedAt time.Time       `json:"performedAt"`
+	Details     json.RawMessage `json:"details,omitempty"`
+}
+
+// TenantOffboardingReport is produced by the complete step and kept with the offboarding
+type TenantOffboardingReport struct {
+	OffboardingID     uuid.UUID                `json:"offboardingId"`
+	TenantID          string                   `json:"tenantId"`
+	TenantName        string                   `json:"tenantName"`
+	Reason            *string                  `json:"reason,omitempty"`
+	StartedBy         *uuid.UUID               `json:"startedBy,omitempty"`
+	StartedAt         time.Time                `json:"startedAt"`
+	CompletedAt       time.Time                `json:"completedAt"`
+	ExportPath        *string                  `json:"exportPath,omitempty"`
+	ExportCompletedAt *time.Time               `json:"exportCompletedAt,omitempty"`
+	RetentionUntil    *time.Time               `json:"retentionUntil,omitempty"`
+	Steps             []*TenantOffboardingStep `json:"steps"`
+}
+
+// Tenant offboarding statuses
+const (
+	OffboardingStatusStarted         = "STARTED"
+	OffboardingStatusPortalBlocked   = "PORTAL_BLOCKED"
+	OffboardingStatusExportScheduled = "EXPORT_SCHEDULED"
+	OffboardingStatusAccessRevoked   = "ACCESS_REVOKED"
+	OffboardingStatusCachesPurged    = "CACHES_PURGED"
+	OffboardingStatusRetentionSet    = "RETENTION_SET"
+	OffboardingStatusCompleted       = "COMPLETED"
+	OffboardingStatusCancelled       = "CANCELLED"
+)
+
+// Tenant offboarding steps
+const (
+	OffboardingStepBlockPortal    = "block_portal"    // Refuse portal requests and registrations
+	OffboardingStepScheduleExport = "schedule_export" // Queue the platform data export to the tenant bucket
+	OffboardingStepRevokeAccess   = "revoke_access"   // Deactivate employee access rows and break-glass grants
+	OffboardingStepPurgeCaches    = "purge_caches"    // Deactivate the tenant and drop its pooled connection and cached secrets
+	OffboardingStepSetRetention   = "set_retention"   // Start the countdown until the bucket may be purged
+	OffboardingStepComplete       = "complete"        // Produce the offboarding report
+	OffboardingStepCancel         = "cancel"
+)
+
+// OffboardingSteps lists the steps in order with the status each one leaves the offboarding in
+var OffboardingSteps = []struct {
+	Step   string
+	Status string
+}{
+	{OffboardingStepBlockPortal, OffboardingStatusPortalBlocked},
+	{OffboardingStepScheduleExport, OffboardingStatusExportScheduled},
+	{OffboardingStepRevokeAccess, OffboardingStatusAccessRevoked},
+	{OffboardingStepPurgeCaches, OffboardingStatusCachesPurged},
+	{OffboardingStepSetRetention, OffboardingStatusRetentionSet},
+	{OffboardingStepComplete, OffboardingStatusCompleted},
+}
+
+// NextOffboardingStep returns the step that follows status, and the status it leads to;
+// ok is false once the offboarding is completed or cancelled
+func NextOffboardingStep(status string) (step, next string, ok bool) {
+	previous := OffboardingStatusStarted
+	for _, s := range OffboardingSteps {
+		if previous == status {
+			return s.Step, s.Status, true
+		}
+		previous = s.Status
+	}
+	return "", "", false
+}
+
+// IsValidOffboardingStep checks a step name
+func IsValidOffboardingStep(step string) bool {
+	for _, s := range OffboardingSteps {
+		if s.Step == step {
+			return true
+		}
+	}
+	return false
+}
+
+// Cancellable reports whether the offboarding can still be called off. Once employee access
+// has been revoked, undoing it is a manual restore.
+func (o *TenantOffboarding) Cancellable() bool {
+	switch o.Status {
+	case OffboardingStatusStarted, OffboardingStatusPortalBlocked, OffboardingStatusExportScheduled:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package types
+
+import "time"
+
+// TenantResidency documents where a tenant's data is kept, comparing the regions it must stay
+// in with where its bucket and database actually are
+type TenantResidency struct {
+	TenantID         string    `json:"tenantId"`
+	DataResidency    string    `json:"dataResidency,omitempty"`
+	StorageProvider  string    `json:"storageProvider"`
+	StorageBucket    string    `json:"storageBucket"`
+	StorageRegion    string    `json:"storageRegion,omitempty"`  // Where the bucket must be
+	BucketLocation   string    `json:"bucketLocation,omitempty"` // Where the provider reports it is
+	DBAuthType       string    `json:"dbAuthType"`
+	DBRegion         string    `json:"dbRegion,omitempty"`         // Where the database must be
+	DBInstanceRegion string    `json:"dbInstanceRegion,omitempty"` // From the Cloud SQL instance connection name
+	DBRegionVerified bool      `json:"dbRegionVerified"`           // Checked against the instance; the region of a database reached by host is taken as given
+	Enforced         bool      `json:"enforced"`                   // A storage or database region is set
+	Compliant        bool      `json:"compliant"`                  // No issues; always true when no region is set
+	Issues           []string  `json:"issues,omitempty"`           // Why the tenant is not compliant, or what could not be checked
+	CheckedAt        time.Time `json:"checkedAt"`
+}
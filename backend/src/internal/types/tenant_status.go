@@ -0,0 +1,52 @@
+package types
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaintenanceWindow is planned downtime of a tenant's portal and/or document uploads
+type MaintenanceWindow struct {
+	ID             uuid.UUID  `json:"id"`
+	TenantID       string     `json:"tenantId"`
+	StartsAt       time.Time  `json:"startsAt"`
+	EndsAt         time.Time  `json:"endsAt"`
+	Message        string     `json:"message"`
+	AffectsPortal  bool       `json:"affectsPortal"`
+	AffectsUploads bool       `json:"affectsUploads"`
+	CreatedBy      *uuid.UUID `json:"createdBy,omitempty"`
+	CreatedAt      time.Time  `json:"createdAt"`
+}
+
+// IsActive reports whether the window covers at
+func (m *MaintenanceWindow) IsActive(at time.Time) bool {
+	return !at.Before(m.StartsAt) && at.Before(m.EndsAt)
+}
+
+// MaxMaintenanceMessageLength bounds the message shown on the status page
+const MaxMaintenanceMessageLength = 500
+
+// Service availability on the tenant status page
+const (
+	ServiceOperational = "OPERATIONAL"
+	ServiceMaintenance = "MAINTENANCE" // Down for a maintenance window
+	ServiceUnavailable = "UNAVAILABLE"
+)
+
+// ServiceAvailability is the public state of one tenant service
+type ServiceAvailability struct {
+	Status  string  `json:"status"`
+	Message *string `json:"message,omitempty"` // Maintenance message while in a window
+}
+
+// TenantStatus is the public status page of a tenant. It carries no internal details:
+// connection errors and who scheduled maintenance are left out.
+type TenantStatus struct {
+	TenantID        string               `json:"tenantId"`
+	TenantName      string               `json:"tenantName"`
+	Portal          ServiceAvailability  `json:"portal"`
+	DocumentUploads ServiceAvailability  `json:"documentUploads"`
+	Maintenance     []*MaintenanceWindow `json:"maintenance"` // Current and upcoming windows
+	CheckedAt       time.Time            `json:"checkedAt"`
+}
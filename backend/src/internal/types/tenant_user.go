@@ -9,17 +9,37 @@ import (
 // TenantUser represents a user who can access their own data in the tenant portal
 // These are clients who have registered to view their filings, documents, and profile (read-only)
 type TenantUser struct {
-	ID          uuid.UUID `json:"id"`
-	TenantID    string    `json:"tenantId"`    // Reference to tenant_connections.tenant_id
-	ClientID    uuid.UUID `json:"clientId"`    // Reference to the client record in tenant's database
-	FirebaseUID string    `json:"firebaseUid"` // Firebase UID for authentication (Google/Phone)
-	Email       string    `json:"email"`
-	IsActive    bool      `json:"isActive"`
-	CreatedAt   time.Time `json:"createdAt"`
-	UpdatedAt   time.Time `json:"updatedAt"`
+	ID          uuid.UUID  `json:"id"`
+	TenantID    string     `json:"tenantId"`    // Reference to tenant_connections.tenant_id
+	ClientID    uuid.UUID  `json:"clientId"`    // Reference to the client record in tenant's database
+	FirebaseUID string     `json:"firebaseUid"` // Firebase UID for authentication (Google/Phone)
+	Email       string     `json:"email"`
+	IsActive    bool       `json:"isActive"`
+	LastLoginAt *time.Time `json:"lastLoginAt,omitempty"` // Latest portal sign-in; nil if none was recorded
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// TenantUserFilter narrows the listing of a tenant's portal users; zero fields match every user
+type TenantUserFilter struct {
+	ActiveOnly   bool
+	DormantSince *time.Time // Users who have not signed in since, counting from registration if they never did
 }
 
 // CanAccess checks if this tenant user can access specific data
 func (tu *TenantUser) CanAccess(tenantID string, clientID string) bool {
 	return tu.IsActive && tu.TenantID == tenantID && tu.ClientID.String() == clientID
 }
+
+// PortalDocumentView records a tenant user opening one of their documents inline in the portal
+type PortalDocumentView struct {
+	ID           uuid.UUID `json:"id"`
+	TenantID     string    `json:"tenantId"`
+	TenantUserID uuid.UUID `json:"tenantUserId"`
+	ClientID     uuid.UUID `json:"clientId"`
+	DocumentID   uuid.UUID `json:"documentId"`
+	ContentType  string    `json:"contentType"`
+	IPAddress    *string   `json:"ipAddress,omitempty"`
+	UserAgent    *string   `json:"userAgent,omitempty"`
+	ViewedAt     time.Time `json:"viewedAt"`
+}
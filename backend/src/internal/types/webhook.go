@@ -0,0 +1,106 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Webhook is a tenant endpoint that receives signed POSTs for the events it subscribes to
+type Webhook struct {
+	ID          uuid.UUID  `json:"id"`
+	TenantID    string     `json:"tenantId"`
+	URL         string     `json:"url"`
+	Description *string    `json:"description,omitempty"`
+	Events      []string   `json:"events"`
+	Secret      string     `json:"secret,omitempty"` // Only returned once, when the webhook is created
+	Active      bool       `json:"active"`
+	CreatedBy   *uuid.UUID `json:"createdBy,omitempty"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// Webhook events
+const (
+	WebhookEventCommissionApproved  = "COMMISSION_APPROVED"
+	WebhookEventCommissionPaid      = "COMMISSION_PAID"
+	WebhookEventCommissionCancelled = "COMMISSION_CANCELLED"
+	WebhookEventFilingCompleted     = "FILING_COMPLETED"
+	WebhookEventFilingStatus        = "FILING_STATUS_CHANGED"
+)
+
+// WebhookEvents lists every event a webhook can subscribe to
+var WebhookEvents = []string{
+	WebhookEventCommissionApproved,
+	WebhookEventCommissionPaid,
+	WebhookEventCommissionCancelled,
+	WebhookEventFilingCompleted,
+	WebhookEventFilingStatus,
+}
+
+// IsValidWebhookEvent reports whether event is one a webhook can subscribe to
+func IsValidWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookPayload is the JSON body POSTed to a webhook
+type WebhookPayload struct {
+	Event      string      `json:"event"`
+	TenantID   string      `json:"tenantId"`
+	OccurredAt time.Time   `json:"occurredAt"`
+	Data       interface{} `json:"data"` // The commission, a WebhookFilingCompleted or a FilingStatusChange
+}
+
+// WebhookFilingCompleted is the data of a FILING_COMPLETED event
+type WebhookFilingCompleted struct {
+	FilingID string     `json:"filingId"`
+	ClientID *uuid.UUID `json:"clientId,omitempty"`
+	TaxYear  int        `json:"taxYear,omitempty"`
+}
+
+// WebhookDelivery is one event sent (or to be sent) to a webhook
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	WebhookID      uuid.UUID       `json:"webhookId"`
+	TenantID       string          `json:"tenantId"`
+	Event          string          `json:"event"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"` // Nil once delivered or failed
+	LastStatusCode *int            `json:"lastStatusCode,omitempty"`
+	LastError      *string         `json:"lastError,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+}
+
+// Webhook delivery statuses
+const (
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliveryDelivered = "DELIVERED"
+	WebhookDeliveryFailed    = "FAILED" // Gave up after MaxWebhookAttempts
+)
+
+// MaxWebhookAttempts is how many times a delivery is attempted before it is marked failed
+const MaxWebhookAttempts = 8
+
+// DueWebhookDelivery is a pending delivery with what the worker needs to send it
+type DueWebhookDelivery struct {
+	WebhookDelivery
+	URL    string
+	Secret string // Decrypted HMAC secret
+}
+
+// Webhook request headers
+const (
+	WebhookHeaderEvent     = "X-WTP-Event"
+	WebhookHeaderDelivery  = "X-WTP-Delivery"  // Delivery ID, the same on every retry
+	WebhookHeaderTimestamp = "X-WTP-Timestamp" // Unix seconds
+	WebhookHeaderSignature = "X-WTP-Signature" // Hex HMAC-SHA256 of timestamp + "." + body
+)
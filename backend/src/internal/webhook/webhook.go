@@ -0,0 +1,106 @@
+// Package webhook signs and sends tenant webhook deliveries.
+//
+// Each delivery is POSTed as JSON with the event, delivery ID, timestamp and signature in
+// headers (see types.WebhookHeaderSignature). The signature is the hex HMAC-SHA256, keyed
+// with the webhook secret, of
+//
+//	timestamp + "." + body
+//
+// where timestamp is Unix seconds. Receivers should reject stale timestamps and use the
+// delivery ID to ignore retries of a delivery they already processed.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+	"welltaxpro/src/internal/types"
+)
+
+const (
+	// firstRetryDelay is the wait before the second attempt; each later wait doubles
+	firstRetryDelay = time.Minute
+	// maxRetryDelay caps the wait between attempts
+	maxRetryDelay = 6 * time.Hour
+	// maxErrorBody is how much of a failed response is kept in the delivery log
+	maxErrorBody = 500
+)
+
+// NewSecret generates a webhook signing secret
+func NewSecret() (string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", fmt.Errorf("failed to generate secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(raw), nil
+}
+
+// Sign computes the signature of a delivery body
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// RetryDelay returns how long to wait after a delivery's attempt number attempts failed
+func RetryDelay(attempts int) time.Duration {
+	delay := firstRetryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxRetryDelay {
+		return maxRetryDelay
+	}
+	return delay
+}
+
+// Sender POSTs deliveries to webhook endpoints
+type Sender struct {
+	client *http.Client
+}
+
+// NewSender creates a sender
+func NewSender() *Sender {
+	return &Sender{
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Send posts one delivery. It returns the response status when a response arrived; any
+// status outside 2xx is an error.
+func (s *Sender) Send(ctx context.Context, delivery *types.DueWebhookDelivery) (int, error) {
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "WellTaxPro-Webhooks/1.0")
+	req.Header.Set(types.WebhookHeaderEvent, delivery.Event)
+	req.Header.Set(types.WebhookHeaderDelivery, delivery.ID.String())
+	req.Header.Set(types.WebhookHeaderTimestamp, timestamp)
+	req.Header.Set(types.WebhookHeaderSignature, Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, fmt.Errorf("endpoint returned %d: %s", resp.StatusCode, bytes.TrimSpace(body))
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,1398 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"welltaxpro/src/internal/apperr"
+	"welltaxpro/src/internal/auditchain"
+	"welltaxpro/src/internal/auth"
+	"welltaxpro/src/internal/bulk"
+	"welltaxpro/src/internal/consistency"
+	"welltaxpro/src/internal/ingest"
+	"welltaxpro/src/internal/notification"
+	"welltaxpro/src/internal/offboarding"
+	"welltaxpro/src/internal/scan"
+	"welltaxpro/src/internal/sms"
+	"welltaxpro/src/internal/storage"
+	"welltaxpro/src/internal/store"
+	"welltaxpro/src/internal/types"
+	"welltaxpro/src/internal/webhook"
+
+	"github.com/google/logger"
+	"github.com/google/uuid"
+)
+
+const (
+	// tenantHealthInterval is how often every active tenant's database is pinged
+	tenantHealthInterval = 5 * time.Minute
+	// tenantProbeInterval is how often every active tenant's database is probed for uptime history
+	tenantProbeInterval = time.Minute
+	// tenantProbeAlertFailures is how many probes in a row must fail before admins are alerted
+	tenantProbeAlertFailures = 3
+	// schemaCheckHourUTC is the hour the nightly schema checks run
+	schemaCheckHourUTC = 6
+	// storageConsistencyHourUTC is the hour tenants' document records are checked against their
+	// buckets
+	storageConsistencyHourUTC = 5
+	// stuckLockInterval is how often distributed locks are checked for stuck holders
+	stuckLockInterval = 5 * time.Minute
+	// documentDropInterval is how often partner document drops are scanned for new batches
+	documentDropInterval = 15 * time.Minute
+	// documentExpiryHourUTC is the hour expiring documents are checked and clients reminded
+	documentExpiryHourUTC = 14
+	// offboardingInterval is how often scheduled offboarding exports are written and ended
+	// retention periods reported
+	offboardingInterval = 15 * time.Minute
+	// clickRollupHourUTC is the hour old affiliate clicks are rolled up into daily buckets
+	clickRollupHourUTC = 7
+	// tokenDormancyHourUTC is the hour affiliate tokens unused past their tenant's policy are
+	// deactivated
+	tokenDormancyHourUTC = 7
+	// affiliateEmailInterval is how often pending affiliate notifications are emailed
+	affiliateEmailInterval = 5 * time.Minute
+	// affiliateEmailQuietSeconds is how long an affiliate's notifications must stop arriving
+	// before they are emailed, so a bulk approval becomes one email
+	affiliateEmailQuietSeconds = 60
+	// affiliateEmailMaxWaitSeconds bounds how long a notification waits for its batch to go quiet
+	affiliateEmailMaxWaitSeconds = 3600
+	// webhookDeliveryInterval is how often due webhook deliveries are sent
+	webhookDeliveryInterval = time.Minute
+	// webhookDeliveryBatch caps the deliveries sent per run
+	webhookDeliveryBatch = 200
+	// commissionSLAHourUTC is the hour tenants' pending commissions are checked against their SLA
+	commissionSLAHourUTC = 15
+	// commissionSLAAlertLimit caps the overdue commissions tracked per tenant
+	commissionSLAAlertLimit = 1000
+	// firebaseReconcileHourUTC is the hour Firebase accounts are matched against employees and
+	// portal users
+	firebaseReconcileHourUTC = 8
+	// bulkOperationInterval is how often queued bulk operations are picked up
+	bulkOperationInterval = 30 * time.Second
+	// ssnRekeyInterval is how often queued SSN rekeys are picked up
+	ssnRekeyInterval = time.Minute
+	// documentScanRetryInterval is how often documents the scanner could not check are retried
+	documentScanRetryInterval = 5 * time.Minute
+	// documentScanRetryBatch caps the documents rescanned per run
+	documentScanRetryBatch = 100
+	// documentScanMaxAttempts is how many scans a document gets before it is left to admins
+	documentScanMaxAttempts = 10
+	// archiveStorageInterval is how often documents of archived and unarchived tax years are
+	// moved between storage classes
+	archiveStorageInterval = time.Hour
+	// archiveStorageBatch caps the documents moved per tenant and run
+	archiveStorageBatch = 500
+	// emailDeliveryInterval is how often due outbox emails are sent
+	emailDeliveryInterval = 30 * time.Second
+	// emailDeliveryBatch caps the outbox emails sent per run
+	emailDeliveryBatch = 200
+)
+
+// ExpiryConfig controls the document expiry check
+type ExpiryConfig struct {
+	WarningDays      int           // Request a replacement this many days before a document expires
+	ReminderInterval time.Duration // Time between reminders about the same open request
+	MaxReminders     int           // Reminders sent per request before the client is left to staff
+}
+
+// Jobs builds every background job. s should act as types.ServiceWorker; notifier, emailService,
+// push, users and texter may be nil. Emailed links point at the web app of links.
+func Jobs(s *store.Store, notifier *notification.Dispatcher, digestHourUTC int, ingestConfig ingest.Config,
+	expiryConfig ExpiryConfig, emailService *notification.EmailService, push *notification.PushService,
+	anchorer *auditchain.Anchorer, anchorInterval time.Duration, clickRetentionDays int, users auth.UserAdmin,
+	scanner scan.Scanner, texter sms.Provider, links notification.Links) []*Job {
+	ingester := ingest.New(s, ingestConfig, InstanceName())
+	offboarder := offboarding.New(s)
+	sender := webhook.NewSender()
+	bulkRunner := bulk.New(s, users, texter, links)
+
+	return []*Job{
+		{
+			// Refreshes this process's connection health cache, so every API process runs its own
+			Name:       types.JobTenantHealthCheck,
+			Interval:   tenantHealthInterval,
+			RunAtStart: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				checkTenantConnections(ctx, s, startedAt)
+			},
+		},
+		{
+			Name:      types.JobTenantProbe,
+			Interval:  tenantProbeInterval,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				probeTenantConnections(ctx, s, notifier, startedAt)
+			},
+		},
+		{
+			Name:      types.JobSchemaCheck,
+			Interval:  time.Hour,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				if dueDaily(s, types.JobSchemaCheck, schemaCheckHourUTC, startedAt) {
+					checkTenantSchemas(ctx, s, startedAt.UTC())
+				}
+			},
+		},
+		{
+			Name:      types.JobBreakGlassExpiry,
+			Interval:  time.Minute,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				expireBreakGlassGrants(s, notifier, startedAt)
+			},
+		},
+		{
+			Name:      types.JobSigningNonceCleanup,
+			Interval:  types.SignatureWindow,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				purgeSigningNonces(s, startedAt)
+			},
+		},
+		{
+			Name:      types.JobDailyDigest,
+			Interval:  time.Hour,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				if notifier != nil && dueDaily(s, types.JobDailyDigest, digestHourUTC, startedAt) {
+					sendDailyDigest(s, notifier, startedAt.UTC())
+				}
+			},
+		},
+		{
+			Name:      types.JobStuckLockCheck,
+			Interval:  stuckLockInterval,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				alertStuckLocks(s, notifier, startedAt)
+			},
+		},
+		{
+			Name:      types.JobDocumentDropScan,
+			Interval:  documentDropInterval,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				scanDocumentDrops(ctx, s, ingester, notifier, startedAt)
+			},
+		},
+		{
+			Name:      types.JobDocumentExpiryCheck,
+			Interval:  time.Hour,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				if dueDaily(s, types.JobDocumentExpiryCheck, documentExpiryHourUTC, startedAt) {
+					checkDocumentExpiry(ctx, s, expiryConfig, emailService, push, startedAt)
+				}
+			},
+		},
+		{
+			Name:      types.JobAuditAnchor,
+			Interval:  anchorInterval,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				if anchorer.Enabled() {
+					anchorAuditChain(ctx, s, anchorer, notifier, startedAt)
+				}
+			},
+		},
+		{
+			Name:      types.JobTenantOffboarding,
+			Interval:  offboardingInterval,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				runTenantOffboarding(ctx, s, offboarder, notifier, startedAt)
+			},
+		},
+		{
+			Name:      types.JobClickRollup,
+			Interval:  time.Hour,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				if dueDaily(s, types.JobClickRollup, clickRollupHourUTC, startedAt) {
+					rollupAffiliateClicks(ctx, s, clickRetentionDays, startedAt)
+				}
+			},
+		},
+		{
+			Name:      types.JobAffiliateEmails,
+			Interval:  affiliateEmailInterval,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				if emailService != nil {
+					sendAffiliateEmails(ctx, s, emailService, startedAt)
+				}
+			},
+		},
+		{
+			Name:      types.JobWebhookDelivery,
+			Interval:  webhookDeliveryInterval,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				deliverWebhooks(ctx, s, sender, startedAt)
+			},
+		},
+		{
+			Name:      types.JobCommissionSLA,
+			Interval:  time.Hour,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				if notifier != nil && dueDaily(s, types.JobCommissionSLA, commissionSLAHourUTC, startedAt) {
+					checkCommissionSLAs(ctx, s, notifier, startedAt)
+				}
+			},
+		},
+		{
+			Name:      types.JobDebugCaptureCleanup,
+			Interval:  time.Hour,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				purgeDebugCaptureEntries(s, startedAt)
+			},
+		},
+		{
+			Name:      types.JobFirebaseReconcile,
+			Interval:  time.Hour,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				if users != nil && dueDaily(s, types.JobFirebaseReconcile, firebaseReconcileHourUTC, startedAt) {
+					reconcileFirebaseUsers(ctx, s, users, notifier, startedAt)
+				}
+			},
+		},
+		{
+			Name:      types.JobBulkOperations,
+			Interval:  bulkOperationInterval,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				runBulkOperations(ctx, s, bulkRunner, startedAt)
+			},
+		},
+		{
+			Name:      types.JobSSNRekey,
+			Interval:  ssnRekeyInterval,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				runSSNRekeys(ctx, s, startedAt)
+			},
+		},
+		{
+			Name:      types.JobDocumentScanRetry,
+			Interval:  documentScanRetryInterval,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				if scan.Enabled(scanner) {
+					retryDocumentScans(ctx, s, scanner, startedAt)
+				}
+			},
+		},
+		{
+			Name:      types.JobPortalSessionPurge,
+			Interval:  time.Hour,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				purgePortalSessions(s, startedAt)
+			},
+		},
+		{
+			Name:      types.JobArchiveStorage,
+			Interval:  archiveStorageInterval,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				moveArchivedDocuments(ctx, s, startedAt)
+			},
+		},
+		{
+			Name:      types.JobEmailDelivery,
+			Interval:  emailDeliveryInterval,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				if emailService != nil {
+					deliverEmails(ctx, s, emailService, startedAt)
+				}
+			},
+		},
+		{
+			Name:      types.JobTokenDormancy,
+			Interval:  time.Hour,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				if dueDaily(s, types.JobTokenDormancy, tokenDormancyHourUTC, startedAt) {
+					deactivateDormantTokens(ctx, s, startedAt)
+				}
+			},
+		},
+		{
+			Name:      types.JobStorageConsistency,
+			Interval:  time.Hour,
+			Exclusive: true,
+			Run: func(ctx context.Context, startedAt time.Time) {
+				if dueDaily(s, types.JobStorageConsistency, storageConsistencyHourUTC, startedAt) {
+					checkStorageConsistency(ctx, s, startedAt)
+				}
+			},
+		},
+	}
+}
+
+// Select returns the jobs named in names; nil selects every job and an empty list none
+func Select(jobs []*Job, names *[]string) ([]*Job, error) {
+	if names == nil {
+		return jobs, nil
+	}
+
+	byName := map[string]*Job{}
+	known := make([]string, 0, len(jobs))
+	for _, job := range jobs {
+		byName[job.Name] = job
+		known = append(known, job.Name)
+	}
+
+	selected := make([]*Job, 0, len(*names))
+	for _, name := range *names {
+		job, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown job %q (known jobs: %s)", name, strings.Join(known, ", "))
+		}
+		selected = append(selected, job)
+	}
+	return selected, nil
+}
+
+// dueDaily reports whether a daily job should run now: it is the job's UTC hour and the job
+// has not already run today in any process
+func dueDaily(s *store.Store, jobName string, hourUTC int, now time.Time) bool {
+	now = now.UTC()
+	if now.Hour() != hourUTC {
+		return false
+	}
+
+	last, err := s.GetLastJobRun(jobName)
+	if err != nil {
+		logger.Errorf("Skipping %s: %v", jobName, err)
+		return false
+	}
+	return last == nil || last.UTC().Format("2006-01-02") != now.Format("2006-01-02")
+}
+
+// checkTenantConnections runs one round of tenant health checks and records it in job history
+func checkTenantConnections(ctx context.Context, s *store.Store, startedAt time.Time) {
+	tenantIDs, err := s.GetActiveTenantIDs()
+	if err != nil {
+		logger.Errorf("Tenant health check failed: %v", err)
+	}
+
+	failing := 0
+	for _, tenantID := range tenantIDs {
+		if health := s.CheckTenantConnection(ctx, tenantID); !health.Healthy {
+			failing++
+			logger.Warningf("Tenant %s database is unreachable: %s", tenantID, health.Error)
+		}
+	}
+	if failing > 0 {
+		logger.Warningf("%d of %d tenant databases failed health checks", failing, len(tenantIDs))
+	}
+
+	if err := s.RecordJobRun(types.JobTenantHealthCheck, startedAt, len(tenantIDs), err); err != nil {
+		logger.Errorf("Failed to record tenant health check run: %v", err)
+	}
+}
+
+// probeTenantConnections pings every active tenant database, records the results in probe
+// history and alerts the tenant's admins when a database has failed tenantProbeAlertFailures
+// probes in a row, and again when it recovers
+func probeTenantConnections(ctx context.Context, s *store.Store, notifier *notification.Dispatcher, startedAt time.Time) {
+	tenantIDs, err := s.GetActiveTenantIDs()
+	if err != nil {
+		logger.Errorf("Tenant connection probe failed: %v", err)
+	}
+
+	failing := 0
+	for _, tenantID := range tenantIDs {
+		health := s.CheckTenantConnection(ctx, tenantID)
+		if !health.Healthy {
+			failing++
+		}
+
+		previousFailures, recErr := s.RecordTenantProbe(health)
+		if recErr != nil {
+			if err == nil {
+				err = recErr
+			}
+			continue
+		}
+		if notifier == nil {
+			continue
+		}
+
+		switch {
+		case !health.Healthy && previousFailures+1 == tenantProbeAlertFailures:
+			logger.Warningf("Tenant %s database has failed %d probes in a row: %s", tenantID, tenantProbeAlertFailures, health.Error)
+			notifier.NotifyTenantAdmins(types.NotificationCategoryConnection, tenantID,
+				fmt.Sprintf("Database for %s is unreachable", tenantID),
+				fmt.Sprintf("The database for %s has failed %d connection checks in a row, the latest at %s: %s. Staff cannot work with its clients until it is reachable again; check for a rotated password or a firewall change.",
+					tenantID, tenantProbeAlertFailures, health.CheckedAt.UTC().Format(time.RFC3339), health.Error),
+			)
+		case health.Healthy && previousFailures >= tenantProbeAlertFailures:
+			logger.Infof("Tenant %s database recovered after %d failed probes", tenantID, previousFailures)
+			notifier.NotifyTenantAdmins(types.NotificationCategoryConnection, tenantID,
+				fmt.Sprintf("Database for %s is reachable again", tenantID),
+				fmt.Sprintf("The database for %s passed a connection check at %s after %d failed checks.",
+					tenantID, health.CheckedAt.UTC().Format(time.RFC3339), previousFailures),
+			)
+		}
+	}
+	if failing > 0 {
+		logger.Warningf("%d of %d tenant databases failed probes", failing, len(tenantIDs))
+	}
+
+	if recErr := s.RecordJobRun(types.JobTenantProbe, startedAt, len(tenantIDs), err); recErr != nil {
+		logger.Errorf("Failed to record tenant connection probe run: %v", recErr)
+	}
+}
+
+// checkTenantSchemas runs the schema check for every active tenant and records it in job history
+func checkTenantSchemas(ctx context.Context, s *store.Store, startedAt time.Time) {
+	checks, err := s.RunSchemaChecks(ctx)
+	if err != nil {
+		logger.Errorf("Nightly schema check failed: %v", err)
+	}
+
+	drifted := 0
+	for _, check := range checks {
+		if !check.Healthy {
+			drifted++
+			logger.Warningf("Tenant %s schema does not match the %s adapter: %d issues", check.TenantID, check.AdapterType, len(check.Issues))
+		}
+	}
+	logger.Infof("Nightly schema check: %d of %d tenants need attention", drifted, len(checks))
+
+	if err := s.RecordJobRun(types.JobSchemaCheck, startedAt, len(checks), err); err != nil {
+		logger.Errorf("Failed to record schema check run: %v", err)
+	}
+}
+
+// checkStorageConsistency compares every active tenant's document records with its bucket and
+// records how many tenants it checked. Tenants whose check could not run fail the run.
+func checkStorageConsistency(ctx context.Context, s *store.Store, startedAt time.Time) {
+	checks, err := consistency.New(s, storage.NewStorageProviderForTenant).CheckAll(ctx)
+	if err != nil {
+		logger.Errorf("Nightly storage check failed: %v", err)
+	}
+
+	affected := 0
+	var errs []string
+	for _, check := range checks {
+		if check.Error != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", check.TenantID, *check.Error))
+			continue
+		}
+		if check.MissingFiles > 0 || check.OrphanedFiles > 0 {
+			affected++
+			logger.Warningf("Tenant %s has %d documents with missing files and %d orphaned files", check.TenantID, check.MissingFiles, check.OrphanedFiles)
+		}
+	}
+	logger.Infof("Nightly storage check: %d of %d tenants have storage issues", affected, len(checks))
+
+	if err == nil && len(errs) > 0 {
+		err = fmt.Errorf("%d tenants could not be checked: %s", len(errs), strings.Join(errs, "; "))
+	}
+	if recErr := s.RecordJobRun(types.JobStorageConsistency, startedAt, len(checks), err); recErr != nil {
+		logger.Errorf("Failed to record storage check run: %v", recErr)
+	}
+}
+
+// expireBreakGlassGrants revokes expired break-glass grants, audits each one and alerts admins
+func expireBreakGlassGrants(s *store.Store, notifier *notification.Dispatcher, startedAt time.Time) {
+	expired, err := s.ExpireBreakGlassGrants()
+	if recErr := s.RecordJobRun(types.JobBreakGlassExpiry, startedAt, len(expired), err); recErr != nil {
+		logger.Errorf("Failed to record break-glass expiry run: %v", recErr)
+	}
+	if err != nil {
+		logger.Errorf("Break-glass expiry failed: %v", err)
+		return
+	}
+
+	for _, grant := range expired {
+		details := map[string]interface{}{"expired": true}
+		if err := s.CreateAuditLog(grant.EmployeeID, grant.TenantID, nil, types.AuditActionRevoke, types.AuditResourceBreakGlass, &grant.ID, details, nil, nil); err != nil {
+			logger.Errorf("Failed to audit break-glass expiry %s: %v", grant.ID, err)
+		}
+
+		if notifier != nil {
+			notifier.AlertAdmins(
+				fmt.Sprintf("Break-glass access expired on %s", grant.TenantID),
+				fmt.Sprintf("Break-glass grant %s for employee %s on tenant %s expired and access was revoked.", grant.ID, grant.EmployeeID, grant.TenantID),
+			)
+		}
+	}
+}
+
+// purgeSigningNonces deletes nonces outside the replay window
+func purgeSigningNonces(s *store.Store, startedAt time.Time) {
+	purged, err := s.PurgeExpiredSigningNonces()
+	if recErr := s.RecordJobRun(types.JobSigningNonceCleanup, startedAt, int(purged), err); recErr != nil {
+		logger.Errorf("Failed to record signing nonce cleanup run: %v", recErr)
+	}
+	if err != nil {
+		logger.Errorf("Signing nonce cleanup failed: %v", err)
+		return
+	}
+	if purged > 0 {
+		logger.Infof("Purged %d expired signing nonces", purged)
+	}
+}
+
+// purgeDebugCaptureEntries deletes captured requests past their retention period
+func purgeDebugCaptureEntries(s *store.Store, startedAt time.Time) {
+	purged, err := s.PurgeExpiredDebugCaptureEntries()
+	if recErr := s.RecordJobRun(types.JobDebugCaptureCleanup, startedAt, int(purged), err); recErr != nil {
+		logger.Errorf("Failed to record debug capture cleanup run: %v", recErr)
+	}
+	if err != nil {
+		logger.Errorf("Debug capture cleanup failed: %v", err)
+		return
+	}
+	if purged > 0 {
+		logger.Infof("Purged %d expired debug capture entries", purged)
+	}
+}
+
+// purgePortalSessions deletes portal sessions unused past their retention period
+func purgePortalSessions(s *store.Store, startedAt time.Time) {
+	purged, err := s.PurgeOldPortalSessions()
+	if recErr := s.RecordJobRun(types.JobPortalSessionPurge, startedAt, int(purged), err); recErr != nil {
+		logger.Errorf("Failed to record portal session cleanup run: %v", recErr)
+	}
+	if err != nil {
+		logger.Errorf("Portal session cleanup failed: %v", err)
+		return
+	}
+	if purged > 0 {
+		logger.Infof("Purged %d old portal sessions", purged)
+	}
+}
+
+// sendDailyDigest emails every employee their queued notification digest
+func sendDailyDigest(s *store.Store, notifier *notification.Dispatcher, startedAt time.Time) {
+	logger.Info("Sending daily notification digests")
+	sent, err := notifier.SendDigests()
+	if err != nil {
+		logger.Errorf("Daily digest failed: %v", err)
+	}
+	if err := s.RecordJobRun(types.JobDailyDigest, startedAt, sent, err); err != nil {
+		logger.Errorf("Failed to record daily digest run: %v", err)
+	}
+}
+
+// alertStuckLocks alerts admins once about each lock held longer than types.LockStuckAfter
+func alertStuckLocks(s *store.Store, notifier *notification.Dispatcher, startedAt time.Time) {
+	locks, err := s.GetDistributedLocks(types.LockStuckAfter)
+
+	alerted := 0
+	for _, lock := range locks {
+		if !lock.Stuck || lock.StuckAlertedAt != nil {
+			continue
+		}
+		logger.Warningf("Lock %s has been held by %s for %ds", lock.Name, *lock.Holder, lock.HeldSeconds)
+
+		if notifier != nil {
+			notifier.AlertAdmins(
+				fmt.Sprintf("Lock %s appears stuck", lock.Name),
+				fmt.Sprintf("Lock %s has been held by %s since %s. Work guarded by it is paused until the holder finishes or its database session ends.",
+					lock.Name, *lock.Holder, lock.AcquiredAt.UTC().Format(time.RFC3339)),
+			)
+		}
+		if err := s.MarkLockStuckAlerted(lock.Name); err != nil {
+			logger.Errorf("Failed to mark lock %s as alerted: %v", lock.Name, err)
+		}
+		alerted++
+	}
+
+	if recErr := s.RecordJobRun(types.JobStuckLockCheck, startedAt, alerted, err); recErr != nil {
+		logger.Errorf("Failed to record stuck lock check run: %v", recErr)
+	}
+	if err != nil {
+		logger.Errorf("Stuck lock check failed: %v", err)
+	}
+}
+
+// scanDocumentDrops imports new partner batches and alerts admins about files that need a client
+// picked by hand and about drops that started failing
+func scanDocumentDrops(ctx context.Context, s *store.Store, ingester *ingest.Ingester, notifier *notification.Dispatcher, startedAt time.Time) {
+	scans, err := ingester.ScanAll(ctx)
+
+	imported := 0
+	for _, scan := range scans {
+		imported += scan.Imported
+		if notifier == nil {
+			continue
+		}
+		tenantID := scan.TenantID
+		if scan.Unmatched > 0 {
+			notifier.NotifyAdmins(types.NotificationCategoryUpload, &tenantID,
+				fmt.Sprintf("%d unmatched files in document drop %s", scan.Unmatched, scan.Name),
+				fmt.Sprintf("%d files delivered to document drop %s on %s could not be matched to a client. Review them under the tenant's unmatched drop files.",
+					scan.Unmatched, scan.Name, scan.TenantID),
+			)
+		}
+		if scan.NewError {
+			notifier.NotifyAdmins(types.NotificationCategoryUpload, &tenantID,
+				fmt.Sprintf("Document drop %s is failing", scan.Name),
+				fmt.Sprintf("Scanning document drop %s on %s failed; affected files will be retried on the next scan: %s",
+					scan.Name, scan.TenantID, strings.Join(scan.Errors, "; ")),
+			)
+		}
+	}
+
+	if recErr := s.RecordJobRun(types.JobDocumentDropScan, startedAt, imported, err); recErr != nil {
+		logger.Errorf("Failed to record document drop scan run: %v", recErr)
+	}
+	if err != nil {
+		logger.Errorf("Document drop scan failed: %v", err)
+	}
+}
+
+// checkDocumentExpiry requests replacements for documents about to expire, then reminds clients
+// of their open document requests by portal push and email
+func checkDocumentExpiry(ctx context.Context, s *store.Store, config ExpiryConfig, emailService *notification.EmailService, push *notification.PushService, startedAt time.Time) {
+	raised, err := s.RaiseExpiryRequests(config.WarningDays)
+	if err != nil {
+		logger.Errorf("Document expiry check failed to raise requests: %v", err)
+	}
+
+	reminded := 0
+	due, dueErr := s.GetDocumentRequestsDueReminder(config.ReminderInterval, config.MaxReminders)
+	if dueErr != nil {
+		logger.Errorf("Document expiry check failed to list due reminders: %v", dueErr)
+		if err == nil {
+			err = dueErr
+		}
+	}
+
+	// One reminder per client covers all of their due requests
+	type clientKey struct {
+		tenantID string
+		clientID string
+	}
+	byClient := map[clientKey][]*types.DocumentRequest{}
+	var order []clientKey
+	for _, r := range due {
+		key := clientKey{r.TenantID, r.ClientID.String()}
+		if _, ok := byClient[key]; !ok {
+			order = append(order, key)
+		}
+		byClient[key] = append(byClient[key], r)
+	}
+
+	for _, key := range order {
+		requests := byClient[key]
+		if !remindClient(ctx, s, emailService, push, key.tenantID, requests) {
+			continue
+		}
+		for _, r := range requests {
+			if markErr := s.MarkDocumentRequestReminded(r.ID); markErr != nil {
+				logger.Errorf("Failed to record reminder for document request %s: %v", r.ID, markErr)
+			}
+		}
+		reminded++
+	}
+	logger.Infof("Document expiry check: %d requests raised, %d clients reminded", len(raised), reminded)
+
+	if recErr := s.RecordJobRun(types.JobDocumentExpiryCheck, startedAt, len(raised)+reminded, err); recErr != nil {
+		logger.Errorf("Failed to record document expiry check run: %v", recErr)
+	}
+}
+
+// remindClient sends one client a reminder of their open document requests and reports
+// whether it was sent. The push goes to the client's portal devices; the email is what
+// reaches clients without the app.
+func remindClient(ctx context.Context, s *store.Store, emailService *notification.EmailService, push *notification.PushService, tenantID string, requests []*types.DocumentRequest) bool {
+	clientID := requests[0].ClientID
+
+	title, body := notification.DocumentRequestPush(len(requests))
+	push.NotifyClient(ctx, tenantID, clientID, types.PushCategoryDocumentRequest, title, body, map[string]string{
+		"requestId": requests[0].ID.String(),
+	})
+
+	if emailService == nil {
+		return true
+	}
+
+	tc, err := s.GetTenantConfig(tenantID)
+	if err != nil {
+		logger.Errorf("Skipping document request reminder for client %s: %v", clientID, err)
+		return false
+	}
+	client, err := s.GetClientByID(ctx, tenantID, clientID.String())
+	if err != nil {
+		logger.Errorf("Skipping document request reminder for client %s: %v", clientID, err)
+		return false
+	}
+
+	name := "there"
+	if client.FirstName != nil && *client.FirstName != "" {
+		name = *client.FirstName
+	}
+	descriptions := make([]string, len(requests))
+	for i, r := range requests {
+		descriptions[i] = r.Description
+	}
+
+	subject, htmlBody, textBody := notification.GenerateDocumentRequestEmail(notification.DocumentRequestEmail{
+		ClientName: name,
+		TenantName: tc.TenantName,
+		Requests:   descriptions,
+	})
+	err = emailService.Send(&notification.Email{
+		TenantID: tenantID,
+		Priority: notification.PriorityBulk,
+		To:       client.Email,
+		ToName:   name,
+		Subject:  subject,
+		HTMLBody: htmlBody,
+		TextBody: textBody,
+	})
+	if err != nil {
+		logger.Errorf("Failed to email document request reminder to client %s: %v", clientID, err)
+		return false
+	}
+	return true
+}
+
+// anchorAuditChain writes the audit chain head to the WORM bucket. A failed anchor leaves
+// entries since the last one unprotected, so admins are alerted.
+func anchorAuditChain(ctx context.Context, s *store.Store, anchorer *auditchain.Anchorer, notifier *notification.Dispatcher, startedAt time.Time) {
+	anchor, err := anchorer.Anchor(ctx)
+
+	anchored := 0
+	if anchor != nil {
+		anchored = 1
+	}
+	if recErr := s.RecordJobRun(types.JobAuditAnchor, startedAt, anchored, err); recErr != nil {
+		logger.Errorf("Failed to record audit anchor run: %v", recErr)
+	}
+	if err != nil {
+		logger.Errorf("Audit chain anchoring failed: %v", err)
+		if notifier != nil {
+			notifier.AlertAdmins("Audit log anchoring failed", fmt.Sprintf("The audit log chain head could not be written to WORM storage: %v", err))
+		}
+	}
+}
+
+// runTenantOffboarding writes scheduled offboarding data exports and alerts admins about exports
+// that started failing and about offboarded tenants whose bucket retention period has ended
+func runTenantOffboarding(ctx context.Context, s *store.Store, offboarder *offboarding.Offboarder, notifier *notification.Dispatcher, startedAt time.Time) {
+	results, err := offboarder.RunExports(ctx)
+
+	exported := 0
+	for _, result := range results {
+		if result.Err == nil {
+			exported++
+			continue
+		}
+		if result.FirstFailure && notifier != nil {
+			notifier.AlertAdmins(
+				fmt.Sprintf("Offboarding export of %s failed", result.Offboarding.TenantID),
+				fmt.Sprintf("The data export of offboarded tenant %s could not be written and will be retried: %v", result.Offboarding.TenantID, result.Err),
+			)
+		}
+	}
+
+	if err == nil {
+		var ended []*types.TenantOffboarding
+		ended, err = offboarder.PastRetention()
+		for _, ob := range ended {
+			if notifier != nil {
+				notifier.AlertAdmins(
+					fmt.Sprintf("Retention period ended for %s", ob.TenantID),
+					fmt.Sprintf("The storage retention period of offboarded tenant %s ended on %s. Its bucket may now be purged.",
+						ob.TenantID, ob.RetentionUntil.UTC().Format("2006-01-02")),
+				)
+			}
+			if markErr := offboarder.MarkRetentionAlerted(ob); markErr != nil {
+				logger.Errorf("Failed to mark retention alert for tenant %s: %v", ob.TenantID, markErr)
+			}
+		}
+	}
+
+	if recErr := s.RecordJobRun(types.JobTenantOffboarding, startedAt, exported, err); recErr != nil {
+		logger.Errorf("Failed to record tenant offboarding run: %v", recErr)
+	}
+	if err != nil {
+		logger.Errorf("Tenant offboarding run failed: %v", err)
+	}
+}
+
+// rollupAffiliateClicks rolls up every active tenant's clicks older than retentionDays into
+// daily buckets and records the run in job history
+func rollupAffiliateClicks(ctx context.Context, s *store.Store, retentionDays int, startedAt time.Time) {
+	tenantIDs, err := s.GetActiveTenantIDs()
+	if err != nil {
+		logger.Errorf("Affiliate click rollup failed: %v", err)
+	}
+
+	rolled := 0
+	for _, tenantID := range tenantIDs {
+		n, rollupErr := s.RollupAffiliateClicks(ctx, tenantID, retentionDays)
+		rolled += n
+		if rollupErr != nil {
+			logger.Errorf("Affiliate click rollup failed for tenant %s: %v", tenantID, rollupErr)
+			if err == nil {
+				err = rollupErr
+			}
+		}
+	}
+	logger.Infof("Affiliate click rollup: %d clicks rolled up across %d tenants", rolled, len(tenantIDs))
+
+	if recErr := s.RecordJobRun(types.JobClickRollup, startedAt, rolled, err); recErr != nil {
+		logger.Errorf("Failed to record affiliate click rollup run: %v", recErr)
+	}
+}
+
+// deactivateDormantTokens deactivates the affiliate tokens every active tenant's policy says have
+// gone unused too long and records the run in job history
+func deactivateDormantTokens(ctx context.Context, s *store.Store, startedAt time.Time) {
+	tenantIDs, err := s.GetActiveTenantIDs()
+	if err != nil {
+		logger.Errorf("Affiliate token dormancy check failed: %v", err)
+	}
+
+	deactivated := 0
+	for _, tenantID := range tenantIDs {
+		n, tenantErr := s.DeactivateDormantAffiliateTokens(ctx, tenantID, startedAt)
+		deactivated += n
+		if tenantErr != nil {
+			logger.Errorf("Affiliate token dormancy check failed for tenant %s: %v", tenantID, tenantErr)
+			if err == nil {
+				err = tenantErr
+			}
+		}
+	}
+	logger.Infof("Affiliate token dormancy check: %d tokens deactivated across %d tenants", deactivated, len(tenantIDs))
+
+	if recErr := s.RecordJobRun(types.JobTokenDormancy, startedAt, deactivated, err); recErr != nil {
+		logger.Errorf("Failed to record affiliate token dormancy run: %v", recErr)
+	}
+}
+
+// sendAffiliateEmails emails each affiliate one batch of their pending commission notifications
+func sendAffiliateEmails(ctx context.Context, s *store.Store, emailService *notification.EmailService, startedAt time.Time) {
+	pending, err := s.GetPendingAffiliateEmails(affiliateEmailQuietSeconds, affiliateEmailMaxWaitSeconds)
+	if err != nil {
+		logger.Errorf("Affiliate notification emails failed: %v", err)
+	}
+
+	// Notifications arrive ordered by tenant and affiliate
+	var batches [][]*types.AffiliateNotification
+	for i, n := range pending {
+		if i == 0 || n.TenantID != pending[i-1].TenantID || n.AffiliateID != pending[i-1].AffiliateID {
+			batches = append(batches, nil)
+		}
+		batches[len(batches)-1] = append(batches[len(batches)-1], n)
+	}
+
+	sent := 0
+	for _, batch := range batches {
+		if !emailAffiliate(ctx, s, emailService, batch) {
+			continue
+		}
+		ids := make([]uuid.UUID, len(batch))
+		for i, n := range batch {
+			ids[i] = n.ID
+		}
+		if markErr := s.MarkAffiliateNotificationsEmailed(ids); markErr != nil {
+			logger.Errorf("Failed to record notification email for affiliate %s: %v", batch[0].AffiliateID, markErr)
+			if err == nil {
+				err = markErr
+			}
+			continue
+		}
+		sent++
+	}
+	if len(batches) > 0 {
+		logger.Infof("Affiliate notification emails: %d of %d affiliates emailed", sent, len(batches))
+	}
+
+	if recErr := s.RecordJobRun(types.JobAffiliateEmails, startedAt, sent, err); recErr != nil {
+		logger.Errorf("Failed to record affiliate notification email run: %v", recErr)
+	}
+}
+
+// emailAffiliate sends one affiliate's batch of notifications and reports whether the batch is
+// done with. Inactive affiliates are not emailed, but their batch is done so it is not retried.
+func emailAffiliate(ctx context.Context, s *store.Store, emailService *notification.EmailService, batch []*types.AffiliateNotification) bool {
+	tenantID, affiliateID := batch[0].TenantID, batch[0].AffiliateID
+
+	affiliate, err := s.GetAffiliateByID(ctx, tenantID, affiliateID.String())
+	if err != nil {
+		logger.Errorf("Skipping notification email for affiliate %s: %v", affiliateID, err)
+		return false
+	}
+	if !affiliate.IsActive {
+		return true
+	}
+	tc, err := s.GetTenantConfig(tenantID)
+	if err != nil {
+		logger.Errorf("Skipping notification email for affiliate %s: %v", affiliateID, err)
+		return false
+	}
+
+	subject, htmlBody, textBody := notification.GenerateAffiliateCommissionEmail(notification.AffiliateCommissionEmail{
+		AffiliateName: affiliate.FirstName,
+		TenantName:    tc.TenantName,
+		Events:        batch,
+	})
+	err = emailService.Send(&notification.Email{
+		TenantID: tenantID,
+		Priority: notification.PriorityBulk,
+		To:       affiliate.Email,
+		ToName:   affiliate.FirstName,
+		Subject:  subject,
+		HTMLBody: htmlBody,
+		TextBody: textBody,
+	})
+	if err != nil {
+		logger.Errorf("Failed to email notifications to affiliate %s: %v", affiliateID, err)
+		return false
+	}
+	return true
+}
+
+// deliverWebhooks sends due webhook deliveries, scheduling a retry with backoff for each that
+// fails until it runs out of attempts
+func deliverWebhooks(ctx context.Context, s *store.Store, sender *webhook.Sender, startedAt time.Time) {
+	due, err := s.GetDueWebhookDeliveries(webhookDeliveryBatch)
+	if err != nil {
+		logger.Errorf("Webhook delivery failed: %v", err)
+	}
+
+	delivered := 0
+	for _, delivery := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		statusCode, sendErr := sender.Send(ctx, delivery)
+		if sendErr != nil {
+			logger.Warningf("Webhook delivery %s (%s) to webhook %s failed on attempt %d: %v",
+				delivery.ID, delivery.Event, delivery.WebhookID, delivery.Attempts+1, sendErr)
+		} else {
+			delivered++
+		}
+
+		retryAfter := webhook.RetryDelay(delivery.Attempts + 1)
+		if recErr := s.RecordWebhookAttempt(delivery.ID, statusCode, sendErr, retryAfter); recErr != nil {
+			logger.Errorf("Failed to record attempt of webhook delivery %s: %v", delivery.ID, recErr)
+			if err == nil {
+				err = recErr
+			}
+		}
+	}
+	if len(due) > 0 {
+		logger.Infof("Webhook delivery: %d of %d deliveries succeeded", delivered, len(due))
+	}
+
+	if recErr := s.RecordJobRun(types.JobWebhookDelivery, startedAt, delivered, err); recErr != nil {
+		logger.Errorf("Failed to record webhook delivery run: %v", recErr)
+	}
+}
+
+// deliverEmails sends due outbox emails, scheduling a retry with the webhook backoff for each
+// that fails until it runs out of attempts
+func deliverEmails(ctx context.Context, s *store.Store, emailService *notification.EmailService, startedAt time.Time) {
+	due, err := s.GetDueEmails(emailDeliveryBatch)
+	if err != nil {
+		logger.Errorf("Email delivery failed: %v", err)
+	}
+
+	sent := 0
+	for _, email := range due {
+		if ctx.Err() != nil {
+			break
+		}
+		message := &notification.Email{
+			Priority: email.Priority,
+			To:       email.ToEmail,
+			Subject:  email.Subject,
+			HTMLBody: email.HTMLBody,
+			TextBody: email.TextBody,
+			OutboxID: email.ID.String(),
+		}
+		if email.TenantID != nil {
+			message.TenantID = *email.TenantID
+		}
+		if email.ToName != nil {
+			message.ToName = *email.ToName
+		}
+		sendErr := emailService.Send(message)
+		if sendErr != nil {
+			logger.Warningf("Outbox email %s (%s) to %s failed on attempt %d: %v",
+				email.ID, email.Kind, email.ToEmail, email.Attempts+1, sendErr)
+		} else {
+			sent++
+		}
+
+		retryAfter := webhook.RetryDelay(email.Attempts + 1)
+		if recErr := s.RecordEmailAttempt(email.ID, sendErr, retryAfter); recErr != nil {
+			logger.Errorf("Failed to record attempt of outbox email %s: %v", email.ID, recErr)
+			if err == nil {
+				err = recErr
+			}
+		}
+	}
+	if len(due) > 0 {
+		logger.Infof("Email delivery: %d of %d emails sent", sent, len(due))
+	}
+
+	if recErr := s.RecordJobRun(types.JobEmailDelivery, startedAt, sent, err); recErr != nil {
+		logger.Errorf("Failed to record email delivery run: %v", recErr)
+	}
+}
+
+// checkCommissionSLAs alerts admins about each tenant's commissions left PENDING past its
+// approval SLA, once when they become overdue and again every reminder period
+func checkCommissionSLAs(ctx context.Context, s *store.Store, notifier *notification.Dispatcher, startedAt time.Time) {
+	tenantIDs, err := s.GetActiveTenantIDs()
+	if err != nil {
+		logger.Errorf("Commission SLA check failed: %v", err)
+	}
+
+	alerted := 0
+	for _, tenantID := range tenantIDs {
+		sent, checkErr := checkCommissionSLA(ctx, s, notifier, tenantID)
+		if checkErr != nil {
+			logger.Errorf("Commission SLA check failed for tenant %s: %v", tenantID, checkErr)
+			if err == nil {
+				err = checkErr
+			}
+		}
+		if sent {
+			alerted++
+		}
+	}
+	logger.Infof("Commission SLA check: admins alerted for %d of %d tenants", alerted, len(tenantIDs))
+
+	if recErr := s.RecordJobRun(types.JobCommissionSLA, startedAt, alerted, err); recErr != nil {
+		logger.Errorf("Failed to record commission SLA check run: %v", recErr)
+	}
+}
+
+// checkCommissionSLA checks one tenant's pending commissions against its SLA and reports whether
+// admins were alerted
+func checkCommissionSLA(ctx context.Context, s *store.Store, notifier *notification.Dispatcher, tenantID string) (bool, error) {
+	sla, err := s.GetCommissionSLA(tenantID)
+	if err != nil {
+		return false, err
+	}
+	if !sla.Enabled {
+		return false, nil
+	}
+
+	report, err := s.GetOverdueCommissions(ctx, tenantID, commissionSLAAlertLimit)
+	if err != nil {
+		return false, err
+	}
+	ids := make([]uuid.UUID, len(report.Commissions))
+	for i, c := range report.Commissions {
+		ids[i] = c.ID
+	}
+
+	due, err := s.RecordCommissionSLAAlert(tenantID, ids, sla.ReminderDays)
+	if err != nil || !due {
+		return false, err
+	}
+
+	oldest := report.Commissions[0]
+	logger.Warningf("Tenant %s has %d commissions pending for more than %d days", tenantID, report.TotalCount, report.PendingDays)
+	notifier.NotifyAdmins(types.NotificationCategoryCommission, &tenantID,
+		fmt.Sprintf("%d commissions overdue for approval in %s", report.TotalCount, tenantID),
+		fmt.Sprintf("%d commissions in %s have been pending for more than %d days; the oldest, for $%s, has waited %d days. Affiliates are not paid until their commissions are approved or cancelled.",
+			report.TotalCount, tenantID, report.PendingDays, oldest.CommissionAmount, oldest.DaysPending),
+	)
+	return true, nil
+}
+
+// reconcileFirebaseUsers records the Firebase accounts that match no employee or portal user and
+// alerts admins about the ones found since the last run. Accounts are only reported, never
+// deleted: an orphan may be a sign-up still in progress or a login an admin created by hand.
+func reconcileFirebaseUsers(ctx context.Context, s *store.Store, users auth.UserAdmin, notifier *notification.Dispatcher, startedAt time.Time) {
+	orphans, err := findFirebaseOrphans(ctx, s, users)
+	if err != nil {
+		logger.Errorf("Firebase user reconciliation failed: %v", err)
+	}
+
+	var added []*types.FirebaseOrphanUser
+	if err == nil {
+		added, err = s.ReplaceFirebaseOrphans(orphans)
+	}
+	if err == nil {
+		logger.Infof("Firebase user reconciliation: %d orphaned accounts, %d new", len(orphans), len(added))
+	}
+
+	if notifier != nil && len(added) > 0 {
+		lines := make([]string, len(added))
+		for i, o := range added {
+			email := "no email"
+			if o.Email != nil {
+				email = *o.Email
+			}
+			lines[i] = fmt.Sprintf("%s (%s)", o.UID, email)
+		}
+		notifier.NotifyAdmins(types.NotificationCategoryAnomaly, nil,
+			fmt.Sprintf("%d Firebase accounts have no WellTaxPro user", len(added)),
+			fmt.Sprintf("These Firebase accounts match no employee or client portal user and can still sign in unless disabled: %s. Review them in Firebase and delete the ones nobody should be using.",
+				strings.Join(lines, ", ")),
+		)
+	}
+
+	if recErr := s.RecordJobRun(types.JobFirebaseReconcile, startedAt, len(orphans), err); recErr != nil {
+		logger.Errorf("Failed to record Firebase user reconciliation run: %v", recErr)
+	}
+}
+
+// findFirebaseOrphans lists the Firebase accounts whose UID matches no employee or tenant user
+func findFirebaseOrphans(ctx context.Context, s *store.Store, users auth.UserAdmin) ([]*types.FirebaseOrphanUser, error) {
+	known, err := s.GetKnownFirebaseUIDs()
+	if err != nil {
+		return nil, err
+	}
+	accounts, err := users.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := []*types.FirebaseOrphanUser{}
+	for _, u := range accounts {
+		if known[u.UID] {
+			continue
+		}
+		o := &types.FirebaseOrphanUser{
+			UID:          u.UID,
+			Disabled:     u.Disabled,
+			LastSignInAt: u.LastSignInAt,
+		}
+		if u.Email != "" {
+			o.Email = &u.Email
+		}
+		if !u.CreatedAt.IsZero() {
+			o.CreatedAt = &u.CreatedAt
+		}
+		orphans = append(orphans, o)
+	}
+	return orphans, nil
+}
+
+// runBulkOperations processes the queued bulk operations and records how many items were done
+func runBulkOperations(ctx context.Context, s *store.Store, runner *bulk.Runner, startedAt time.Time) {
+	processed, err := runner.RunQueued(ctx)
+	if err != nil {
+		logger.Errorf("Bulk operations run failed: %v", err)
+	}
+	if processed > 0 {
+		logger.Infof("Bulk operations: processed %d items", processed)
+	}
+
+	if recErr := s.RecordJobRun(types.JobBulkOperations, startedAt, processed, err); recErr != nil {
+		logger.Errorf("Failed to record bulk operations run: %v", recErr)
+	}
+}
+
+// runSSNRekeys re-encrypts the SSNs of tenants whose data key was rotated and records how many
+// were rewritten. A failed rekey is recorded on the rekey itself and the others still run.
+func runSSNRekeys(ctx context.Context, s *store.Store, startedAt time.Time) {
+	rekeys, err := s.GetUnfinishedSSNRekeys()
+	if err != nil {
+		if recErr := s.RecordJobRun(types.JobSSNRekey, startedAt, 0, err); recErr != nil {
+			logger.Errorf("Failed to record SSN rekey run: %v", recErr)
+		}
+		return
+	}
+	if len(rekeys) == 0 {
+		return
+	}
+
+	reencrypted := 0
+	var failed []string
+	for _, rekey := range rekeys {
+		if ctx.Err() != nil {
+			break
+		}
+		count, err := s.RunSSNRekey(ctx, rekey)
+		reencrypted += count
+		if err != nil {
+			logger.Errorf("SSN rekey %s of tenant %s failed: %v", rekey.ID, rekey.TenantID, err)
+			failed = append(failed, rekey.TenantID)
+		}
+	}
+
+	var runErr error
+	if len(failed) > 0 {
+		runErr = fmt.Errorf("SSN rekey failed for tenants: %s", strings.Join(failed, ", "))
+	}
+	if recErr := s.RecordJobRun(types.JobSSNRekey, startedAt, reencrypted, runErr); recErr != nil {
+		logger.Errorf("Failed to record SSN rekey run: %v", recErr)
+	}
+}
+
+// retryDocumentScans scans again the documents the scanner could not check at upload, moving
+// infected files to quarantine, and records how many got a verdict
+func retryDocumentScans(ctx context.Context, s *store.Store, scanner scan.Scanner, startedAt time.Time) {
+	pending, err := s.GetPendingDocumentScans(documentScanMaxAttempts, documentScanRetryBatch)
+	if err != nil {
+		if recErr := s.RecordJobRun(types.JobDocumentScanRetry, startedAt, 0, err); recErr != nil {
+			logger.Errorf("Failed to record document scan retry run: %v", recErr)
+		}
+		return
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	scanned := 0
+	for _, p := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+
+		result, err := rescanDocument(ctx, s, scanner, p)
+		if errors.Is(err, apperr.ErrNotFound) {
+			// The document was removed from the tenant database
+			s.DeleteDocumentScan(p.TenantID, p.DocumentID)
+			continue
+		}
+		if err != nil {
+			logger.Errorf("Failed to rescan document %s of tenant %s: %v", p.DocumentID, p.TenantID, err)
+			msg := err.Error()
+			result = &types.DocumentScan{Status: types.DocumentScanPending, Scanner: scanner.Name(), Error: &msg}
+		}
+
+		result.TenantID, result.DocumentID = p.TenantID, p.DocumentID
+		if err := s.UpdateDocumentScan(result); err != nil {
+			continue
+		}
+		if result.Status != types.DocumentScanPending {
+			scanned++
+		} else if p.Attempts+1 >= documentScanMaxAttempts {
+			logger.Warningf("Document %s of tenant %s is still unscanned after %d attempts; it stays blocked", p.DocumentID, p.TenantID, p.Attempts+1)
+		}
+	}
+
+	if scanned > 0 {
+		logger.Infof("Document scan retry: %d of %d pending documents scanned", scanned, len(pending))
+	}
+	if recErr := s.RecordJobRun(types.JobDocumentScanRetry, startedAt, scanned, nil); recErr != nil {
+		logger.Errorf("Failed to record document scan retry run: %v", recErr)
+	}
+}
+
+// rescanDocument scans a pending document's file again
+func rescanDocument(ctx context.Context, s *store.Store, scanner scan.Scanner, pending *types.DocumentScan) (*types.DocumentScan, error) {
+	document, err := s.GetDocumentByID(ctx, pending.TenantID, pending.DocumentID.String())
+	if err != nil {
+		return nil, err
+	}
+	tc, err := s.GetTenantConfig(pending.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := storage.NewStorageProviderForTenant(ctx, tc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+	return scan.Rescan(ctx, scanner, provider, tc.StorageBucket, document.FilePath)
+}
+
+// moveArchivedDocuments moves the documents of archived tax years to the archive storage class
+// and those of unarchived years back, and records how many it moved. A document that fails is
+// tried again on the next run.
+func moveArchivedDocuments(ctx context.Context, s *store.Store, startedAt time.Time) {
+	tenantIDs, err := s.GetArchiveTenants()
+	if err != nil {
+		if recErr := s.RecordJobRun(types.JobArchiveStorage, startedAt, 0, err); recErr != nil {
+			logger.Errorf("Failed to record archive storage run: %v", recErr)
+		}
+		return
+	}
+	if len(tenantIDs) == 0 {
+		return
+	}
+
+	moved := 0
+	var errs []string
+	for _, tenantID := range tenantIDs {
+		if ctx.Err() != nil {
+			break
+		}
+		n, err := moveTenantArchivedDocuments(ctx, s, tenantID)
+		moved += n
+		if err != nil {
+			logger.Errorf("Failed to move archived documents of tenant %s: %v", tenantID, err)
+			errs = append(errs, fmt.Sprintf("%s: %v", tenantID, err))
+		}
+	}
+
+	if moved > 0 {
+		logger.Infof("Archive storage: moved %d documents between storage classes", moved)
+	}
+	var runErr error
+	if len(errs) > 0 {
+		runErr = fmt.Errorf("%d tenants failed: %s", len(errs), strings.Join(errs, "; "))
+	}
+	if recErr := s.RecordJobRun(types.JobArchiveStorage, startedAt, moved, runErr); recErr != nil {
+		logger.Errorf("Failed to record archive storage run: %v", recErr)
+	}
+}
+
+// moveTenantArchivedDocuments moves up to a batch of one tenant's documents each way. Documents
+// of unarchived years are moved back first, so an unarchived year is not left in the archive
+// class while another year is archived.
+func moveTenantArchivedDocuments(ctx context.Context, s *store.Store, tenantID string) (int, error) {
+	tc, err := s.GetTenantConfig(tenantID)
+	if err != nil {
+		return 0, err
+	}
+	provider, err := storage.NewStorageProviderForTenant(ctx, tc)
+	if err != nil {
+		return 0, fmt.Errorf("failed to initialize storage: %w", err)
+	}
+
+	restore, err := s.GetDocumentsToRestore(tenantID, archiveStorageBatch)
+	if err != nil {
+		return 0, err
+	}
+	moved := 0
+	for _, document := range restore {
+		if ctx.Err() != nil {
+			return moved, ctx.Err()
+		}
+		if err := storage.SetArchived(ctx, provider, tc.StorageBucket, document.FilePath, false); err != nil {
+			logger.Errorf("Failed to restore document %s of tenant %s from archive storage: %v", document.DocumentID, tenantID, err)
+			continue
+		}
+		if err := s.DeleteArchivedDocument(tenantID, document.DocumentID); err != nil {
+			continue
+		}
+		moved++
+	}
+
+	archive, err := s.GetDocumentsToArchive(ctx, tenantID, archiveStorageBatch)
+	if err != nil {
+		return moved, err
+	}
+	for _, document := range archive {
+		if ctx.Err() != nil {
+			return moved, ctx.Err()
+		}
+		if err := storage.SetArchived(ctx, provider, tc.StorageBucket, document.FilePath, true); err != nil {
+			logger.Errorf("Failed to move document %s of tenant %s to archive storage: %v", document.DocumentID, tenantID, err)
+			continue
+		}
+		if err := s.RecordArchivedDocument(document); err != nil {
+			continue
+		}
+		moved++
+	}
+	return moved, nil
+}
@@ -0,0 +1,117 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+	"welltaxpro/src/internal/dlock"
+	"welltaxpro/src/internal/store"
+	"welltaxpro/src/internal/tracing"
+
+	"github.com/google/logger"
+)
+
+// Job is a periodic background task
+type Job struct {
+	Name       string        // Job history name (types.Job*)
+	Interval   time.Duration // Time between runs
+	RunAtStart bool          // Run once as soon as the runner starts instead of after a full interval
+	Exclusive  bool          // Claim each run so only one process in the deployment runs the job
+	Run        func(ctx context.Context, startedAt time.Time)
+}
+
+// Runner runs background jobs on their intervals. Exclusive jobs are claimed through a lease
+// and run under a distributed lock, so any number of API and worker processes can share a job
+// list safely.
+type Runner struct {
+	store  *store.Store
+	locker *dlock.Locker
+	holder string
+}
+
+// NewRunner creates a runner that claims jobs as holder
+func NewRunner(s *store.Store, holder string) *Runner {
+	return &Runner{store: s, locker: s.Locker(holder), holder: holder}
+}
+
+// InstanceName identifies this process in job leases
+func InstanceName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
+// Run runs jobs until ctx is cancelled, then releases this process's leases
+func (r *Runner) Run(ctx context.Context, jobs []*Job) {
+	logger.Infof("Running %d background jobs as %s", len(jobs), r.holder)
+
+	var wg sync.WaitGroup
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job *Job) {
+			defer wg.Done()
+			r.loop(ctx, job)
+		}(job)
+	}
+	wg.Wait()
+
+	if err := r.store.ReleaseJobs(r.holder); err != nil {
+		logger.Errorf("Failed to release job leases on shutdown: %v", err)
+	}
+}
+
+// loop runs one job on its interval until ctx is cancelled
+func (r *Runner) loop(ctx context.Context, job *Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	if job.RunAtStart {
+		r.runOnce(ctx, job, time.Now())
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case startedAt := <-ticker.C:
+			r.runOnce(ctx, job, startedAt)
+		}
+	}
+}
+
+// runOnce runs a job if this process holds, or can take, its lease.
+// Leases outlive two intervals so a healthy holder keeps the job and a dead one is replaced quickly.
+// A run that outlasts its lease still holds the job's lock, so the next holder skips instead of overlapping.
+func (r *Runner) runOnce(ctx context.Context, job *Job, startedAt time.Time) {
+	if !job.Exclusive {
+		run(ctx, job, startedAt)
+		return
+	}
+
+	claimed, err := r.store.ClaimJob(job.Name, r.holder, 2*job.Interval)
+	if err != nil {
+		logger.Errorf("Skipping %s run: %v", job.Name, err)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	_, err = r.locker.TryRun(ctx, "job:"+job.Name, func() error {
+		run(ctx, job, startedAt)
+		return nil
+	})
+	if err != nil {
+		logger.Errorf("Skipping %s run: %v", job.Name, err)
+	}
+}
+
+// run runs a job in a trace of its own, which the work it passes ctx to joins
+func run(ctx context.Context, job *Job, startedAt time.Time) {
+	ctx, span := tracing.StartJob(ctx, job.Name)
+	defer span.End()
+	job.Run(ctx, startedAt)
+}
@@ -27,8 +27,15 @@ interface Commission {
     lastName: string | null
     email: string
   }
+  payout?: {
+    id: string
+    amount: number
+    paidAmount: number
+  }
 }
 
+const PAYMENT_METHODS = ['ZELLE', 'CHECK', 'ACH', 'WIRE', 'PAYPAL', 'CASH', 'OTHER']
+
 interface Affiliate {
   id: string
   firstName: string
@@ -209,42 +216,71 @@ function CommissionsContent() {
     )
   }
 
-  const markCommissionPaid = async (commissionId: string) => {
-    if (!user) return
+  const [paidModalOpen, setPaidModalOpen] = useState(false)
+  const [commissionToPay, setCommissionToPay] = useState<Commission | null>(null)
+  const [paymentMethod, setPaymentMethod] = useState('ZELLE')
+  const [paymentReference, setPaymentReference] = useState('')
+  const [paymentAmount, setPaymentAmount] = useState('')
+
+  const openPaidModal = (commission: Commission) => {
+    setCommissionToPay(commission)
+    setPaymentMethod('ZELLE')
+    setPaymentReference('')
+    setPaymentAmount('')
+    setPaidModalOpen(true)
+  }
 
-    showConfirm(
-      'Mark Commission as Paid',
-      'Are you sure you want to mark this commission as PAID?',
-      async () => {
-        try {
-          const idToken = await user.getIdToken()
-          const response = await fetch(
-            `${apiUrl}/api/v1/${tenantId}/commissions/${commissionId}/mark-paid`,
-            {
-              method: 'PUT',
-              headers: {
-                'Authorization': `Bearer ${idToken}`,
-                'Content-Type': 'application/json',
-              },
-            }
-          )
+  const closePaidModal = () => {
+    setPaidModalOpen(false)
+    setCommissionToPay(null)
+  }
 
-          if (!response.ok) throw new Error('Failed to mark commission as paid')
+  const markCommissionPaid = async () => {
+    if (!user || !commissionToPay || !paymentReference.trim()) {
+      showAlert('Validation Error', 'Payment reference is required', 'warning')
+      return
+    }
 
-          const updatedCommission = await response.json()
+    try {
+      const idToken = await user.getIdToken()
+      const response = await fetch(
+        `${apiUrl}/api/v1/${tenantId}/commissions/${commissionToPay.id}/mark-paid`,
+        {
+          method: 'PUT',
+          headers: {
+            'Authorization': `Bearer ${idToken}`,
+            'Content-Type': 'application/json',
+          },
+          body: JSON.stringify({
+            method: paymentMethod,
+            reference: paymentReference.trim(),
+            ...(paymentAmount.trim() ? { amount: Number(paymentAmount) } : {}),
+          }),
+        }
+      )
 
-          // Update local state
-          setCommissions(
-            commissions.map((c) => (c.id === commissionId ? updatedCommission : c))
-          )
+      if (!response.ok) throw new Error((await response.text()) || 'Failed to mark commission as paid')
 
-          showAlert('Success', 'Commission marked as paid', 'success')
-        } catch (err) {
-          showAlert('Failed', err instanceof Error ? err.message : 'Failed to mark commission as paid', 'error')
-        }
-      },
-      'confirm'
-    )
+      const updatedCommission: Commission = await response.json()
+
+      // Update local state
+      setCommissions(
+        commissions.map((c) => (c.id === commissionToPay.id ? updatedCommission : c))
+      )
+
+      closePaidModal()
+      if (updatedCommission.status === 'PAID') {
+        showAlert('Success', 'Commission marked as paid', 'success')
+      } else if (updatedCommission.payout) {
+        showAlert(
+          'Partial Payment Recorded',
+          `$${updatedCommission.payout.paidAmount.toFixed(2)} of $${updatedCommission.payout.amount.toFixed(2)} paid. Record the rest on payout ${updatedCommission.payout.id}.`,
+          'success'
+        )
+      }
+    } catch (err) {
+      showAlert('Failed', err instanceof Error ? err.message : 'Failed to mark commission as paid', 'error')
+    }
   }
 
   const [cancelModalOpen, setCancelModalOpen] = useState(false)
@@ -556,7 +592,7 @@ function CommissionsContent() {
                           {commission.status === 'APPROVED' && (
                             <>
                               <button
-                                onClick={() => markCommissionPaid(commission.id)}
+                                onClick={() => openPaidModal(commission)}
                                 className="text-green-600 hover:text-green-900 font-medium"
                               >
                                 Mark Paid
@@ -591,6 +627,71 @@ function CommissionsContent() {
         </div>
       </main>
 
+      {/* Mark Paid Modal */}
+      {paidModalOpen && commissionToPay && (
+        <div className="fixed inset-0 bg-gray-600 bg-opacity-50 overflow-y-auto h-full w-full z-50">
+          <div className="relative top-20 mx-auto p-5 border w-96 shadow-lg rounded-md bg-white">
+            <div className="mt-3">
+              <h3 className="text-lg font-medium text-gray-900 mb-4">Record Payment</h3>
+              <div className="mb-4">
+                <label className="block text-sm font-medium text-gray-700 mb-2">
+                  Payment Method *
+                </label>
+                <select
+                  value={paymentMethod}
+                  onChange={(e) => setPaymentMethod(e.target.value)}
+                  className="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-blue-500 focus:border-blue-500"
+                >
+                  {PAYMENT_METHODS.map((m) => (
+                    <option key={m} value={m}>{m}</option>
+                  ))}
+                </select>
+              </div>
+              <div className="mb-4">
+                <label className="block text-sm font-medium text-gray-700 mb-2">
+                  Reference *
+                </label>
+                <input
+                  type="text"
+                  value={paymentReference}
+                  onChange={(e) => setPaymentReference(e.target.value)}
+                  className="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-blue-500 focus:border-blue-500"
+                  placeholder="Check number or confirmation code"
+                />
+              </div>
+              <div className="mb-4">
+                <label className="block text-sm font-medium text-gray-700 mb-2">
+                  Amount Paid
+                </label>
+                <input
+                  type="number"
+                  min="0.01"
+                  step="0.01"
+                  value={paymentAmount}
+                  onChange={(e) => setPaymentAmount(e.target.value)}
+                  className="w-full px-3 py-2 border border-gray-300 rounded-md focus:outline-none focus:ring-blue-500 focus:border-blue-500"
+                  placeholder={`${commissionToPay.commissionAmount.toFixed(2)} (full amount)`}
+                />
+              </div>
+              <div className="flex gap-3">
+                <button
+                  onClick={markCommissionPaid}
+                  className="flex-1 bg-green-600 text-white px-4 py-2 rounded-md hover:bg-green-700"
+                >
+                  Record Payment
+                </button>
+                <button
+                  onClick={closePaidModal}
+                  className="flex-1 bg-gray-200 text-gray-700 px-4 py-2 rounded-md hover:bg-gray-300"
+                >
+                  Close
+                </button>
+              </div>
+            </div>
+          </div>
+        </div>
+      )}
+
       {/* Cancel Commission Modal */}
       {cancelModalOpen && (
         <div className="fixed inset-0 bg-gray-600 bg-opacity-50 overflow-y-auto h-full w-full z-50">
@@ -63,24 +63,52 @@ function TenantsContent() {
   }, [user])
 
   const handleDeactivate = async (tenantId: string) => {
-    if (!confirm('Are you sure you want to deactivate this tenant?')) {
-      return
-    }
-
     try {
       const apiUrl = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8081'
       const idToken = await user?.getIdToken()
+      const headers = {
+        'Authorization': `Bearer ${idToken}`,
+        'Content-Type': 'application/json',
+      }
+
+      // Deactivation is requested first, which reports its impact and returns a confirmation token
+      const requestResponse = await fetch(`${apiUrl}/api/v1/admin/tenants/${tenantId}/deletion-request`, {
+        method: 'POST',
+        headers,
+      })
+      if (!requestResponse.ok) {
+        throw new Error('Failed to request tenant deactivation')
+      }
+      const request = await requestResponse.json()
+      const impact = request.impact
+
+      const storage = impact.storageBytes !== undefined
+        ? `${impact.storageObjects} files (${(impact.storageBytes / (1024 * 1024)).toFixed(1)} MB)`
+        : `unknown (${impact.storageError})`
+      const openFilings = impact.openFilings !== undefined ? impact.openFilings : `unknown (${impact.openFilingsError})`
+      const summary = [
+        `Deactivate tenant ${tenantId}?`,
+        '',
+        `Employees with access: ${impact.activeEmployees}`,
+        `Portal users: ${impact.activePortalUsers}`,
+        `Open portal sessions (will be ended): ${impact.activePortalSessions}`,
+        `Open filings: ${openFilings}`,
+        `Storage: ${storage}`,
+        '',
+        `Confirm before ${new Date(request.expiresAt).toLocaleTimeString()}.`,
+      ].join('\n')
+      if (!confirm(summary)) {
+        return
+      }
 
       const response = await fetch(`${apiUrl}/api/v1/admin/tenants/${tenantId}`, {
         method: 'DELETE',
-        headers: {
-          'Authorization': `Bearer ${idToken}`,
-          'Content-Type': 'application/json',
-        },
+        headers,
+        body: JSON.stringify({ confirmationToken: request.token }),
       })
 
       if (!response.ok) {
-        throw new Error('Failed to deactivate tenant')
+        throw new Error(`Failed to deactivate tenant: ${await response.text()}`)
       }
 
       // Refresh the list